@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/delivery"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetAnonymization returns the sensitive field rules
+func GetAnonymization(c *gin.Context) {
+	c.JSON(http.StatusOK, delivery.Anonymization.Config())
+}
+
+// UpdateAnonymization replaces the sensitive field rules
+func UpdateAnonymization(c *gin.Context) {
+	var cfg models.AnonymizationConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := checkAnonymizationRules(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	if err := delivery.Anonymization.SetConfig(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveAnonymization()
+
+	c.JSON(http.StatusOK, delivery.Anonymization.Config())
+}
+
+// checkAnonymizationRules checks that each rule's event type and event ID
+// exist
+func checkAnonymizationRules(cfg models.AnonymizationConfig) error {
+	for i, r := range cfg.Rules {
+		if r.EventType == "" {
+			continue
+		}
+		gen, ok := generators.GetGenerator(r.EventType)
+		if !ok {
+			return fmt.Errorf("rule %d: unknown event type %q", i, r.EventType)
+		}
+		if r.EventID != "" && !hasTemplateEventID(gen, r.EventID) {
+			return fmt.Errorf("rule %d: event type %s has no template with event ID %q", i, r.EventType, r.EventID)
+		}
+	}
+	return nil
+}
+
+// hasTemplateEventID reports whether one of the generator's templates
+// produces events with the given event ID
+func hasTemplateEventID(gen generators.Generator, eventID string) bool {
+	for _, t := range gen.GetTemplates() {
+		if t.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// PreviewAnonymization generates one event and shows it before and after the
+// rules are applied, whether or not anonymization is enabled
+func PreviewAnonymization(c *gin.Context) {
+	var req models.PreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	gen, ok := generators.GetGenerator(req.EventType)
+	if !ok {
+		respondError(c, models.CodeEventTypeNotFound, "Event type not found")
+		return
+	}
+
+	templateID, err := generators.ResolveTemplateID(gen, req.EventID)
+	if err != nil {
+		respondError(c, models.CodeTemplateNotFound, err.Error())
+		return
+	}
+
+	if !checkOverrides(c, gen, templateID, req.Overrides, req.StrictOverrides) {
+		return
+	}
+
+	event, err := generators.Unseeded.Generate(gen, templateID, req.Overrides)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+
+	anon, fields := delivery.Anonymization.Apply(event)
+	if fields == nil {
+		fields = []string{}
+	}
+	c.JSON(http.StatusOK, models.AnonymizationPreview{
+		Original:   event,
+		Anonymized: anon,
+		Fields:     fields,
+	})
+}
+
+// Detokenize returns the original value behind a token from the tokenize action
+func Detokenize(c *gin.Context) {
+	value, ok := delivery.Anonymization.Detokenize(c.Param("token"))
+	if !ok {
+		respondError(c, models.CodeNotFound, "Token not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token": c.Param("token"),
+		"value": value,
+	})
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// campaignRef matches a reference to a campaign variable
+var campaignRef = regexp.MustCompile(`\$\{campaign\.([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// campaignVariableName is the form of a variable name
+var campaignVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CampaignStore provides thread-safe storage for campaigns
+type CampaignStore struct {
+	mu        sync.RWMutex
+	campaigns map[string]*models.Campaign
+}
+
+// NewCampaignStore creates a new campaign store
+func NewCampaignStore() *CampaignStore {
+	return &CampaignStore{
+		campaigns: make(map[string]*models.Campaign),
+	}
+}
+
+// Get retrieves a copy of a campaign by ID
+func (s *CampaignStore) Get(id string) (models.Campaign, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	campaign, ok := s.campaigns[id]
+	if !ok {
+		return models.Campaign{}, false
+	}
+	return *campaign, true
+}
+
+// Active returns a copy of the active campaign, if any
+func (s *CampaignStore) Active() (models.Campaign, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, campaign := range s.campaigns {
+		if campaign.Active {
+			return *campaign, true
+		}
+	}
+	return models.Campaign{}, false
+}
+
+// List returns copies of all campaigns ordered by name
+func (s *CampaignStore) List() []models.Campaign {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	campaigns := make([]models.Campaign, 0, len(s.campaigns))
+	for _, campaign := range s.campaigns {
+		campaigns = append(campaigns, *campaign)
+	}
+	sort.Slice(campaigns, func(i, j int) bool { return campaigns[i].Name < campaigns[j].Name })
+	return campaigns
+}
+
+// Set adds or replaces a campaign. An active campaign deactivates the
+// others.
+func (s *CampaignStore) Set(campaign models.Campaign) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if campaign.Active {
+		for _, other := range s.campaigns {
+			other.Active, other.ActivatedAt = false, nil
+		}
+	}
+	s.campaigns[campaign.ID] = &campaign
+}
+
+// Delete removes a campaign
+func (s *CampaignStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.campaigns[id]; !ok {
+		return false
+	}
+	delete(s.campaigns, id)
+	return true
+}
+
+// Global campaign store
+var campaignStore = NewCampaignStore()
+
+// substituteCampaign replaces references to campaign variables in the
+// strings of v, recursing into maps and lists. Variables vars does not set
+// are added to missing and left as they are.
+func substituteCampaign(v interface{}, vars map[string]string, missing map[string]bool) interface{} {
+	switch v := v.(type) {
+	case string:
+		if !strings.Contains(v, "${campaign.") {
+			return v
+		}
+		return campaignRef.ReplaceAllStringFunc(v, func(ref string) string {
+			name := campaignRef.FindStringSubmatch(ref)[1]
+			value, ok := vars[name]
+			if !ok {
+				missing[name] = true
+				return ref
+			}
+			return value
+		})
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = substituteCampaign(item, vars, missing)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = substituteCampaign(item, vars, missing)
+		}
+		return out
+	}
+	return v
+}
+
+// expandCampaign substitutes the active campaign's variables into v. It
+// fails if v refers to a variable the campaign does not set, or to any
+// while no campaign is active.
+func expandCampaign(v interface{}) (interface{}, error) {
+	campaign, active := campaignStore.Active()
+	missing := make(map[string]bool)
+	v = substituteCampaign(v, campaign.Variables, missing)
+	if len(missing) == 0 {
+		return v, nil
+	}
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, "${campaign."+name+"}")
+	}
+	sort.Strings(names)
+	if !active {
+		return nil, fmt.Errorf("%s used but no campaign is active", strings.Join(names, ", "))
+	}
+	return nil, fmt.Errorf("campaign %s does not set %s", campaign.Name, strings.Join(names, ", "))
+}
+
+// expandCampaignOverrides substitutes the active campaign's variables into
+// overrides
+func expandCampaignOverrides(overrides map[string]interface{}) (map[string]interface{}, error) {
+	if len(overrides) == 0 {
+		return overrides, nil
+	}
+	expanded, err := expandCampaign(overrides)
+	if err != nil {
+		return nil, err
+	}
+	return expanded.(map[string]interface{}), nil
+}
+
+// applyCampaign substitutes the active campaign's variables into a
+// request's overrides, responding with 400 if a variable is not set. It
+// returns false if a response was written.
+func applyCampaign(c *gin.Context, overrides *map[string]interface{}) bool {
+	expanded, err := expandCampaignOverrides(*overrides)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return false
+	}
+	*overrides = expanded
+	return true
+}
+
+// applyCampaignFields substitutes the active campaign's variables into
+// request fields, responding with 400 if a variable is not set. It returns
+// false if a response was written.
+func applyCampaignFields(c *gin.Context, fields ...*string) bool {
+	for _, field := range fields {
+		expanded, err := expandCampaign(*field)
+		if err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return false
+		}
+		*field = expanded.(string)
+	}
+	return true
+}
+
+// campaignScenario returns a scenario with the active campaign's variables
+// substituted into its dimension filters
+func campaignScenario(s models.Scenario) (models.Scenario, error) {
+	if len(s.Match) == 0 {
+		return s, nil
+	}
+	match := make(map[string]string, len(s.Match))
+	for k, v := range s.Match {
+		expanded, err := expandCampaign(v)
+		if err != nil {
+			return s, fmt.Errorf("match %s: %w", k, err)
+		}
+		match[k] = expanded.(string)
+	}
+	s.Match = match
+	return s, nil
+}
+
+// runningScenario is campaignScenario for scenarios started earlier, which
+// keep their filters as written when a variable is no longer set
+func runningScenario(s models.Scenario) models.Scenario {
+	resolved, err := campaignScenario(s)
+	if err != nil {
+		return s
+	}
+	return resolved
+}
+
+// checkCampaign checks a campaign's variable names
+func checkCampaign(campaign *models.Campaign) error {
+	if len(campaign.Variables) == 0 {
+		return fmt.Errorf("a campaign needs at least one variable")
+	}
+	for name := range campaign.Variables {
+		if !campaignVariableName.MatchString(name) {
+			return fmt.Errorf("variable %q: names are letters, digits and underscores, not starting with a digit", name)
+		}
+	}
+	return nil
+}
+
+// ListCampaigns returns the campaigns, paged as paginate describes
+func ListCampaigns(c *gin.Context) {
+	campaigns, page, ok := paginate(c, campaignStore.List(), 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"campaigns": campaigns,
+		"count":     len(campaigns),
+	}))
+}
+
+// GetCampaign returns a specific campaign
+func GetCampaign(c *gin.Context) {
+	campaign, ok := campaignStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeCampaignNotFound, "Campaign not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// GetActiveCampaign returns the active campaign
+func GetActiveCampaign(c *gin.Context) {
+	campaign, ok := campaignStore.Active()
+	if !ok {
+		respondError(c, models.CodeCampaignNotFound, "No campaign is active")
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// CreateCampaign adds a campaign, activating it if it asks to be
+func CreateCampaign(c *gin.Context) {
+	var campaign models.Campaign
+	if err := c.ShouldBindJSON(&campaign); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := checkCampaign(&campaign); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	now := time.Now()
+	campaign.ID = uuid.New().String()
+	campaign.CreatedAt = now
+	campaign.UpdatedAt = now
+	campaign.ActivatedAt = nil
+	if campaign.Active {
+		campaign.ActivatedAt = &now
+	}
+	campaignStore.Set(campaign)
+	SaveCampaigns()
+
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// UpdateCampaign replaces a campaign's name, description and variables.
+// Streams and scenarios already started keep the values they started with.
+func UpdateCampaign(c *gin.Context) {
+	id := c.Param("id")
+
+	existing, ok := campaignStore.Get(id)
+	if !ok {
+		respondError(c, models.CodeCampaignNotFound, "Campaign not found")
+		return
+	}
+
+	var campaign models.Campaign
+	if err := c.ShouldBindJSON(&campaign); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := checkCampaign(&campaign); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	campaign.ID = id
+	campaign.Active, campaign.ActivatedAt = existing.Active, existing.ActivatedAt
+	campaign.CreatedAt = existing.CreatedAt
+	campaign.UpdatedAt = time.Now()
+	campaignStore.Set(campaign)
+	SaveCampaigns()
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// DeleteCampaign removes a campaign
+func DeleteCampaign(c *gin.Context) {
+	if !campaignStore.Delete(c.Param("id")) {
+		respondError(c, models.CodeCampaignNotFound, "Campaign not found")
+		return
+	}
+	SaveCampaigns()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Campaign deleted",
+	})
+}
+
+// ActivateCampaign makes a campaign the active one, deactivating any other
+func ActivateCampaign(c *gin.Context) {
+	setCampaignActive(c, true)
+}
+
+// DeactivateCampaign ends a campaign; references to its variables fail
+// until another campaign is activated
+func DeactivateCampaign(c *gin.Context) {
+	setCampaignActive(c, false)
+}
+
+func setCampaignActive(c *gin.Context, active bool) {
+	campaign, ok := campaignStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeCampaignNotFound, "Campaign not found")
+		return
+	}
+
+	campaign.Active, campaign.ActivatedAt = active, nil
+	if active {
+		now := time.Now()
+		campaign.ActivatedAt = &now
+	}
+	campaignStore.Set(campaign)
+	SaveCampaigns()
+
+	c.JSON(http.StatusOK, campaign)
+}
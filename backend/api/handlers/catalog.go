@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetCatalog searches builtin and custom templates. ?q= matches every
+// word against names, descriptions, vendors, techniques and data models;
+// ?category=, ?format=, ?vendor=, ?technique= and ?datamodel= filter by
+// facet and may be repeated or comma-separated; ?source= picks builtin or
+// custom templates. Facet counts cover every match, not just the page.
+func GetCatalog(c *gin.Context) {
+	query := models.CatalogQuery{
+		Text:       c.Query("q"),
+		Categories: queryList(c, "category"),
+		Formats:    queryList(c, "format"),
+		Vendors:    queryList(c, "vendor"),
+		Techniques: queryList(c, "technique"),
+		Datamodels: queryList(c, "datamodel"),
+		Source:     c.Query("source"),
+	}
+	for name, dst := range map[string]*int{"offset": &query.Offset, "limit": &query.Limit} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			respondError(c, models.CodeValidationFailed, name+" must be a non-negative integer")
+			return
+		}
+		*dst = n
+	}
+
+	entries := generators.CatalogEntries()
+	for _, tmpl := range templateStore.List() {
+		entries = append(entries, generators.CustomCatalogEntry(*tmpl))
+	}
+
+	c.JSON(http.StatusOK, generators.SearchCatalog(entries, query))
+}
+
+// queryList returns the values of a query parameter that may be repeated
+// or comma-separated
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, v := range c.QueryArray(name) {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
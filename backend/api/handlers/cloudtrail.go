@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetCloudTrailConfig returns the CloudTrail error rate
+func GetCloudTrailConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, generators.CloudTrailConfig())
+}
+
+// UpdateCloudTrailConfig sets the CloudTrail error rate
+func UpdateCloudTrailConfig(c *gin.Context) {
+	var cfg models.CloudTrailConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.SetCloudTrailConfig(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveCloudTrailConfig()
+
+	c.JSON(http.StatusOK, cfg)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/cluster"
+	"siem-event-generator/delivery"
+	"siem-event-generator/models"
+	"siem-event-generator/noise"
+)
+
+var (
+	clusterConfig = cluster.Config{Mode: cluster.ModeStandalone}
+	// coordinator is set in coordinator mode; noise runs are then split
+	// across the workers
+	coordinator *cluster.Coordinator
+	// clusterWorker is set in worker mode
+	clusterWorker *cluster.Worker
+)
+
+// StartCluster joins this instance to a cluster as a coordinator or worker.
+// Standalone instances generate on their own, as before.
+func StartCluster(cfg cluster.Config) {
+	clusterConfig = cfg
+	if cfg.Mode != cluster.ModeStandalone && cfg.Token == "" {
+		log.Printf("WARNING: CLUSTER_TOKEN is not set; cluster requests are not authenticated")
+	}
+
+	switch cfg.Mode {
+	case cluster.ModeCoordinator:
+		coordinator = cluster.NewCoordinator(cfg)
+		go coordinator.Run(context.Background())
+		log.Printf("Cluster coordinator mode: noise runs are split across registered workers")
+	case cluster.ModeWorker:
+		clusterWorker = cluster.NewWorker(cfg)
+		// Routing rules of a shard's destinations forward to destinations
+		// the coordinator sent along with it
+		delivery.ResolveDestination = func(id string) (*models.Destination, bool) {
+			if d, ok := clusterWorker.RouteTarget(id); ok {
+				return d, true
+			}
+			return destinationStore.Get(id)
+		}
+		go clusterWorker.Run(context.Background())
+	}
+}
+
+// noiseStatus returns the status of the local run, or of the distributed
+// run with every shard's statistics added up on a coordinator
+func noiseStatus() models.NoiseStatus {
+	if coordinator != nil {
+		return coordinator.Status()
+	}
+	return noise.GetInstance().GetStatus()
+}
+
+// RequireClusterToken rejects cluster requests without the shared token
+func RequireClusterToken(c *gin.Context) {
+	if !clusterConfig.Authorized(c.Request) {
+		c.AbortWithStatusJSON(apiError(models.CodeUnauthorized, "Invalid cluster token"))
+		return
+	}
+	c.Next()
+}
+
+// GetCluster returns this instance's cluster mode and, on a coordinator,
+// the registered workers
+func GetCluster(c *gin.Context) {
+	status := models.ClusterStatus{Mode: clusterConfig.Mode}
+	switch {
+	case coordinator != nil:
+		status.RunID = coordinator.RunID()
+		status.Workers = coordinator.Workers()
+	case clusterWorker != nil:
+		status.WorkerID = clusterConfig.WorkerID
+		status.CoordinatorURL = clusterConfig.CoordinatorURL
+		status.RunID = clusterWorker.RunID()
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// RegisterWorker handles a worker's heartbeat
+func RegisterWorker(c *gin.Context) {
+	if coordinator == nil {
+		respondError(c, models.CodeClusterRole, "This instance is not a cluster coordinator")
+		return
+	}
+	var hb models.WorkerHeartbeat
+	if err := c.ShouldBindJSON(&hb); err != nil {
+		bindError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, coordinator.Heartbeat(hb))
+}
+
+// requireWorker responds with 409 unless this instance is a worker. It
+// returns false if a response was written.
+func requireWorker(c *gin.Context) bool {
+	if clusterWorker == nil {
+		respondError(c, models.CodeClusterRole, "This instance is not a cluster worker")
+		return false
+	}
+	return true
+}
+
+// StartShard starts this worker's share of a distributed run
+func StartShard(c *gin.Context) {
+	if !requireWorker(c) {
+		return
+	}
+	var req models.ShardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	noiseStats.check()
+	if err := clusterWorker.StartShard(&req); err != nil {
+		noiseError(c, err)
+		return
+	}
+	noiseStats.check()
+	c.JSON(http.StatusOK, noise.GetInstance().GetStatus())
+}
+
+// UpdateShard changes the rate or sources of this worker's shard
+func UpdateShard(c *gin.Context) {
+	if !requireWorker(c) {
+		return
+	}
+	var req models.NoiseUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := clusterWorker.UpdateShard(c.Param("run_id"), &req); err != nil {
+		noiseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, noise.GetInstance().GetStatus())
+}
+
+// StopShard stops this worker's shard and returns its final status
+func StopShard(c *gin.Context) {
+	if !requireWorker(c) {
+		return
+	}
+	if err := clusterWorker.StopShard(c.Param("run_id")); err != nil {
+		respondError(c, models.CodeNotFound, err.Error())
+		return
+	}
+	noiseStats.check()
+	c.JSON(http.StatusOK, noise.GetInstance().GetStatus())
+}
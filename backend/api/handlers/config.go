@@ -0,0 +1,1123 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	"siem-event-generator/delivery"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+	"siem-event-generator/secrets"
+)
+
+// configBundleVersion is the bundle format written by ExportConfig
+const configBundleVersion = 1
+
+// maxConfigBundle caps the size of an imported bundle
+const maxConfigBundle = 16 << 20
+
+// configBundle is everything the API configures, in a form meant to be kept
+// in git: runtime state such as timestamps and counters is left out. On
+// import a missing section is left alone; an empty one is emptied by prune.
+type configBundle struct {
+	Version       int                         `json:"version"`
+	Destinations  []bundleDestination         `json:"destinations"`
+	Templates     []models.EventTemplate      `json:"templates"`
+	Scenarios     []bundleScenario            `json:"scenarios"`
+	IOCFeeds      []bundleIOCFeed             `json:"ioc_feeds"`
+	Indicators    []bundleIndicator           `json:"indicators"`
+	IOCConfig     *models.IOCConfig           `json:"ioc_config,omitempty"`
+	GeoPolicy     *generators.GeoPolicy       `json:"geo_policy,omitempty"`
+	Theme         *models.Theme               `json:"theme,omitempty"`
+	NameProfile   *generators.NameProfile     `json:"name_profile,omitempty"`
+	Anonymization *models.AnonymizationConfig `json:"anonymization,omitempty"`
+	Performance   *models.PerformanceSettings `json:"performance,omitempty"`
+	CloudTrail    *models.CloudTrailConfig    `json:"cloudtrail,omitempty"`
+	PKI           *models.PKIConfig           `json:"pki,omitempty"`
+	Processes     *models.ProcessConfig       `json:"processes,omitempty"`
+	Web           *models.WebConfig           `json:"web,omitempty"`
+	Limits        *models.ServerLimits        `json:"limits,omitempty"`
+}
+
+type bundleDestination struct {
+	ID          string                   `json:"id,omitempty"`
+	Name        string                   `json:"name"`
+	Type        models.DestinationType   `json:"type"`
+	Description string                   `json:"description,omitempty"`
+	Config      models.DestinationConfig `json:"config"`
+}
+
+type bundleScenario struct {
+	ID             string              `json:"id,omitempty"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description,omitempty"`
+	Metric         string              `json:"metric"`
+	Match          map[string]string   `json:"match,omitempty"`
+	Mode           models.ScenarioMode `json:"mode,omitempty"`
+	Target         float64             `json:"target"`
+	RampSeconds    int                 `json:"ramp_seconds"`
+	HoldSeconds    int                 `json:"hold_seconds"`
+	RecoverSeconds int                 `json:"recover_seconds"`
+}
+
+type bundleIOCFeed struct {
+	ID              string `json:"id,omitempty"`
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	Format          string `json:"format,omitempty"`
+	Username        string `json:"username,omitempty"`
+	Password        string `json:"password,omitempty"`
+	IntervalMinutes int    `json:"interval_minutes,omitempty"`
+}
+
+type bundleIndicator struct {
+	Type   models.IOCType `json:"type,omitempty"`
+	Value  string         `json:"value"`
+	Source string         `json:"source,omitempty"`
+	Tags   []string       `json:"tags,omitempty"`
+}
+
+// configChanges lists what an import changed in one section, by name
+type configChanges struct {
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+	Deleted   []string `json:"deleted"`
+}
+
+func newConfigChanges() *configChanges {
+	return &configChanges{
+		Created:   make([]string, 0),
+		Updated:   make([]string, 0),
+		Unchanged: make([]string, 0),
+		Deleted:   make([]string, 0),
+	}
+}
+
+// configMu serializes imports so two bundles are not applied at once
+var configMu sync.Mutex
+
+// ExportConfig returns the whole configuration as a YAML bundle, or JSON
+// with ?format=json. Secrets are masked unless ?secrets=encrypted, which
+// exports them sealed with this deployment's key; references are kept.
+func ExportConfig(c *gin.Context) {
+	mode := c.DefaultQuery("secrets", "masked")
+	if mode != "masked" && mode != "encrypted" {
+		respondError(c, models.CodeValidationFailed, "secrets must be masked or encrypted")
+		return
+	}
+
+	bundle, err := exportConfigBundle(mode == "encrypted")
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	if c.Query("format") == "json" {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+		return
+	}
+	out, err := jsonToYAML(data)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="make-some-noise.yaml"`)
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", out)
+}
+
+// exportConfigBundle collects the configuration, ordered by name so that
+// exports of the same configuration are identical
+func exportConfigBundle(sealSecrets bool) (*configBundle, error) {
+	protect := func(v string) (string, error) {
+		if v == "" || secrets.IsReference(v) {
+			return v, nil
+		}
+		if sealSecrets {
+			return secrets.Seal(v)
+		}
+		return secrets.Mask, nil
+	}
+
+	bundle := &configBundle{
+		Version:      configBundleVersion,
+		Destinations: make([]bundleDestination, 0),
+		Templates:    make([]models.EventTemplate, 0),
+		Scenarios:    make([]bundleScenario, 0),
+		IOCFeeds:     make([]bundleIOCFeed, 0),
+		Indicators:   make([]bundleIndicator, 0),
+	}
+
+	for _, d := range destinationStore.List() {
+		cfg := secrets.RedactConfig(d.Config)
+		if sealSecrets {
+			var err error
+			if cfg, err = secrets.SealConfig(d.Config); err != nil {
+				return nil, fmt.Errorf("destination %s: %w", d.Name, err)
+			}
+		}
+		bundle.Destinations = append(bundle.Destinations, toBundleDestination(d, cfg))
+	}
+	sort.Slice(bundle.Destinations, func(i, j int) bool {
+		return bundleLess(bundle.Destinations[i].Name, bundle.Destinations[i].ID, bundle.Destinations[j].Name, bundle.Destinations[j].ID)
+	})
+
+	for _, t := range templateStore.List() {
+		bundle.Templates = append(bundle.Templates, *t)
+	}
+	sort.Slice(bundle.Templates, func(i, j int) bool {
+		return bundleLess(bundle.Templates[i].Name, bundle.Templates[i].ID, bundle.Templates[j].Name, bundle.Templates[j].ID)
+	})
+
+	for _, s := range scenarioStore.List() {
+		bundle.Scenarios = append(bundle.Scenarios, toBundleScenario(s))
+	}
+	sort.Slice(bundle.Scenarios, func(i, j int) bool {
+		return bundleLess(bundle.Scenarios[i].Name, bundle.Scenarios[i].ID, bundle.Scenarios[j].Name, bundle.Scenarios[j].ID)
+	})
+
+	for _, f := range iocFeedStore.List() {
+		feed := toBundleIOCFeed(f)
+		password, err := protect(feed.Password)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: %w", f.Name, err)
+		}
+		feed.Password = password
+		bundle.IOCFeeds = append(bundle.IOCFeeds, feed)
+	}
+
+	for _, ioc := range generators.IOCs.List("", "") {
+		if !strings.HasPrefix(ioc.Source, "feed:") {
+			bundle.Indicators = append(bundle.Indicators, toBundleIndicator(ioc))
+		}
+	}
+
+	iocConfig := generators.IOCs.Config()
+	bundle.IOCConfig = &iocConfig
+	geo := generators.Geo.Policy()
+	bundle.GeoPolicy = &geo
+	theme := generators.Theme()
+	bundle.Theme = &theme
+	names := generators.Names.Profile()
+	bundle.NameProfile = &names
+	anon := delivery.Anonymization.Config()
+	bundle.Anonymization = &anon
+	bundle.Performance = &models.PerformanceSettings{PerformanceMode: generators.PerformanceMode()}
+	cloudTrail := generators.CloudTrailConfig()
+	bundle.CloudTrail = &cloudTrail
+	pki := generators.PKIConfig()
+	bundle.PKI = &pki
+	processes := generators.ProcessConfig()
+	bundle.Processes = &processes
+	web := generators.WebConfig()
+	bundle.Web = &web
+	guardrails := serverLimits()
+	bundle.Limits = &guardrails
+	return bundle, nil
+}
+
+// bundleLess orders bundle items by name, then ID
+func bundleLess(nameA, idA, nameB, idB string) bool {
+	if nameA != nameB {
+		return nameA < nameB
+	}
+	return idA < idB
+}
+
+func toBundleDestination(d *models.Destination, cfg models.DestinationConfig) bundleDestination {
+	return bundleDestination{ID: d.ID, Name: d.Name, Type: d.Type, Description: d.Description, Config: cfg}
+}
+
+func toBundleScenario(s *models.Scenario) bundleScenario {
+	return bundleScenario{
+		ID:             s.ID,
+		Name:           s.Name,
+		Description:    s.Description,
+		Metric:         s.Metric,
+		Match:          s.Match,
+		Mode:           s.Mode,
+		Target:         s.Target,
+		RampSeconds:    s.RampSeconds,
+		HoldSeconds:    s.HoldSeconds,
+		RecoverSeconds: s.RecoverSeconds,
+	}
+}
+
+func toBundleIOCFeed(f models.IOCFeed) bundleIOCFeed {
+	return bundleIOCFeed{
+		ID:              f.ID,
+		Name:            f.Name,
+		URL:             f.URL,
+		Format:          f.Format,
+		Username:        f.Username,
+		Password:        f.Password,
+		IntervalMinutes: f.IntervalMinutes,
+	}
+}
+
+func toBundleIndicator(ioc models.IOC) bundleIndicator {
+	return bundleIndicator{Type: ioc.Type, Value: ioc.Value, Source: ioc.Source, Tags: ioc.Tags}
+}
+
+// ImportConfig applies a YAML or JSON bundle. Items are matched to existing
+// ones by ID, else by name, so applying the same bundle twice changes
+// nothing. ?prune=true deletes items missing from the sections the bundle
+// has; ?dry_run=true reports the changes without making them.
+func ImportConfig(c *gin.Context) {
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxConfigBundle))
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	bundle, err := parseConfigBundle(data)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	plan, err := planConfigImport(bundle, c.Query("prune") == "true")
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	if !dryRun {
+		plan.apply()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": dryRun,
+		"changes": plan.changes,
+	})
+}
+
+// parseConfigBundle reads a bundle. YAML is a superset of JSON, so both
+// are read as YAML and then decoded strictly to catch misspelled keys.
+func parseConfigBundle(data []byte) (*configBundle, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("bundle is empty")
+	}
+	asJSON, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	var bundle configBundle
+	dec := json.NewDecoder(bytes.NewReader(asJSON))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if bundle.Version > configBundleVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than this server supports (%d)", bundle.Version, configBundleVersion)
+	}
+	return &bundle, nil
+}
+
+// configPlan is a validated import, ready to apply
+type configPlan struct {
+	changes map[string]*configChanges
+
+	destinations       []*models.Destination
+	deleteDestinations []string
+	templates          []*models.EventTemplate
+	deleteTemplates    []string
+	scenarios          []*models.Scenario
+	deleteScenarios    []string
+	feeds              []*models.IOCFeed
+	deleteFeeds        []string
+	indicators         map[string][]models.IOC // source -> indicators
+	iocConfig          *models.IOCConfig
+	geoPolicy          *generators.GeoPolicy
+	theme              *models.Theme
+	nameProfile        *generators.NameProfile
+	anonymization      *models.AnonymizationConfig
+	performance        *models.PerformanceSettings
+	cloudTrail         *models.CloudTrailConfig
+	pki                *models.PKIConfig
+	processes          *models.ProcessConfig
+	web                *models.WebConfig
+	limits             *models.ServerLimits
+}
+
+// planConfigImport validates bundle against the current configuration and
+// works out what applying it changes
+func planConfigImport(bundle *configBundle, prune bool) (*configPlan, error) {
+	p := &configPlan{changes: make(map[string]*configChanges)}
+	now := time.Now()
+
+	if bundle.Destinations != nil {
+		if err := p.planDestinations(bundle.Destinations, prune, now); err != nil {
+			return nil, err
+		}
+	}
+	if bundle.Templates != nil {
+		if err := p.planTemplates(bundle.Templates, prune); err != nil {
+			return nil, err
+		}
+	}
+	if bundle.Scenarios != nil {
+		if err := p.planScenarios(bundle.Scenarios, prune, now); err != nil {
+			return nil, err
+		}
+	}
+	if bundle.IOCFeeds != nil {
+		if err := p.planIOCFeeds(bundle.IOCFeeds, prune, now); err != nil {
+			return nil, err
+		}
+	}
+	if bundle.Indicators != nil {
+		if err := p.planIndicators(bundle.Indicators, prune, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.planSettings(bundle); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// sameJSON reports whether a and b encode to the same JSON, which treats
+// nil and empty maps and slices alike
+func sameJSON(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+func (p *configPlan) planDestinations(items []bundleDestination, prune bool, now time.Time) error {
+	changes := newConfigChanges()
+	p.changes["destinations"] = changes
+
+	current := destinationStore.List()
+	byID := make(map[string]*models.Destination, len(current))
+	byName := make(map[string]*models.Destination, len(current))
+	final := make(map[string]*models.Destination, len(current))
+	for _, d := range current {
+		byID[d.ID] = d
+		byName[d.Name] = d
+		final[d.ID] = d
+	}
+
+	seen := make(map[string]bool)
+	for i, item := range items {
+		if item.Name == "" || item.Type == "" {
+			return fmt.Errorf("destinations[%d]: name and type are required", i)
+		}
+		existing := byID[item.ID]
+		if item.ID == "" {
+			existing = byName[item.Name]
+		}
+		id := item.ID
+		if existing != nil {
+			id = existing.ID
+		} else if id == "" {
+			id = uuid.New().String()
+		}
+		if seen[id] {
+			return fmt.Errorf("destination %s appears twice", item.Name)
+		}
+		seen[id] = true
+
+		cfg := item.Config
+		if existing != nil {
+			cfg = secrets.RestoreMasked(cfg, existing.Config)
+		}
+		if secrets.IsMasked(cfg) {
+			return fmt.Errorf("destination %s: secrets are masked in this bundle; fill them in, use references, or export with ?secrets=encrypted", item.Name)
+		}
+		cfg, err := secrets.OpenConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("destination %s: %w", item.Name, err)
+		}
+
+		dest := &models.Destination{
+			ID:          id,
+			Name:        item.Name,
+			Type:        item.Type,
+			Description: item.Description,
+			Config:      cfg,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		switch {
+		case existing == nil:
+			changes.Created = append(changes.Created, dest.Name)
+		case sameJSON(toBundleDestination(existing, existing.Config), toBundleDestination(dest, dest.Config)):
+			changes.Unchanged = append(changes.Unchanged, dest.Name)
+			continue
+		default:
+			dest.CreatedAt = existing.CreatedAt
+			dest.LastUsed = existing.LastUsed
+			dest.EventsSent = existing.EventsSent
+			changes.Updated = append(changes.Updated, dest.Name)
+		}
+		p.destinations = append(p.destinations, dest)
+		final[id] = dest
+	}
+
+	if prune {
+		for _, d := range current {
+			if !seen[d.ID] {
+				p.deleteDestinations = append(p.deleteDestinations, d.ID)
+				changes.Deleted = append(changes.Deleted, d.Name)
+				delete(final, d.ID)
+			}
+		}
+	}
+
+	// Routes may point at destinations created by this bundle but not at
+	// ones it deletes
+	for _, d := range final {
+		if err := delivery.ValidateRoutes(d.Config.Routes, d.ID); err != nil {
+			return fmt.Errorf("destination %s: %w", d.Name, err)
+		}
+		if d.Config.Reconcile != nil {
+			if err := d.Config.Reconcile.Validate(d.Type); err != nil {
+				return fmt.Errorf("destination %s: %w", d.Name, err)
+			}
+		}
+		if err := secrets.CheckConfig(d.Config); err != nil {
+			return fmt.Errorf("destination %s: %w", d.Name, err)
+		}
+		for _, rule := range d.Config.Routes {
+			if _, ok := final[rule.DestinationID]; rule.DestinationID != "" && !ok {
+				return fmt.Errorf("destination %s: route target destination not found: %s", d.Name, rule.DestinationID)
+			}
+		}
+	}
+	return nil
+}
+
+// isBuiltinTemplate reports whether id names a generator's template
+func isBuiltinTemplate(id string) bool {
+	for _, gen := range generators.Generators() {
+		for _, tmpl := range gen.GetTemplates() {
+			if tmpl.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *configPlan) planTemplates(items []models.EventTemplate, prune bool) error {
+	changes := newConfigChanges()
+	p.changes["templates"] = changes
+
+	current := templateStore.List()
+	byName := make(map[string]*models.EventTemplate, len(current))
+	for _, t := range current {
+		byName[t.Name] = t
+	}
+
+	seen := make(map[string]bool)
+	for i := range items {
+		tmpl := items[i]
+		if tmpl.Name == "" {
+			return fmt.Errorf("templates[%d]: name is required", i)
+		}
+		if tmpl.ID != "" && isBuiltinTemplate(tmpl.ID) {
+			return fmt.Errorf("template %s: %s is a builtin template ID", tmpl.Name, tmpl.ID)
+		}
+		existing, _ := templateStore.Get(tmpl.ID)
+		if tmpl.ID == "" {
+			existing = byName[tmpl.Name]
+		}
+		if existing != nil {
+			tmpl.ID = existing.ID
+		} else if tmpl.ID == "" {
+			tmpl.ID = "custom-" + uuid.New().String()
+		}
+		if seen[tmpl.ID] {
+			return fmt.Errorf("template %s appears twice", tmpl.Name)
+		}
+		seen[tmpl.ID] = true
+
+		switch {
+		case existing == nil:
+			changes.Created = append(changes.Created, tmpl.Name)
+		case sameJSON(existing, tmpl):
+			changes.Unchanged = append(changes.Unchanged, tmpl.Name)
+			continue
+		default:
+			changes.Updated = append(changes.Updated, tmpl.Name)
+		}
+		p.templates = append(p.templates, &tmpl)
+	}
+
+	if prune {
+		for _, t := range current {
+			if !seen[t.ID] {
+				p.deleteTemplates = append(p.deleteTemplates, t.ID)
+				changes.Deleted = append(changes.Deleted, t.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *configPlan) planScenarios(items []bundleScenario, prune bool, now time.Time) error {
+	changes := newConfigChanges()
+	p.changes["scenarios"] = changes
+
+	current := scenarioStore.List()
+	byName := make(map[string]*models.Scenario, len(current))
+	for _, s := range current {
+		byName[s.Name] = s
+	}
+
+	seen := make(map[string]bool)
+	for i, item := range items {
+		if item.Name == "" || item.Metric == "" {
+			return fmt.Errorf("scenarios[%d]: name and metric are required", i)
+		}
+		scenario := &models.Scenario{
+			ID:             item.ID,
+			Name:           item.Name,
+			Description:    item.Description,
+			Metric:         item.Metric,
+			Match:          item.Match,
+			Mode:           item.Mode,
+			Target:         item.Target,
+			RampSeconds:    item.RampSeconds,
+			HoldSeconds:    item.HoldSeconds,
+			RecoverSeconds: item.RecoverSeconds,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := generators.ValidateScenario(scenario); err != nil {
+			return fmt.Errorf("scenario %s: %w", item.Name, err)
+		}
+		existing, _ := scenarioStore.Get(item.ID)
+		if item.ID == "" {
+			existing = byName[item.Name]
+		}
+		if existing != nil {
+			scenario.ID = existing.ID
+		} else if scenario.ID == "" {
+			scenario.ID = uuid.New().String()
+		}
+		if seen[scenario.ID] {
+			return fmt.Errorf("scenario %s appears twice", item.Name)
+		}
+		seen[scenario.ID] = true
+
+		switch {
+		case existing == nil:
+			changes.Created = append(changes.Created, scenario.Name)
+		case sameJSON(toBundleScenario(existing), toBundleScenario(scenario)):
+			changes.Unchanged = append(changes.Unchanged, scenario.Name)
+			continue
+		default:
+			scenario.CreatedAt = existing.CreatedAt
+			changes.Updated = append(changes.Updated, scenario.Name)
+		}
+		p.scenarios = append(p.scenarios, scenario)
+	}
+
+	if prune {
+		for _, s := range current {
+			if !seen[s.ID] {
+				p.deleteScenarios = append(p.deleteScenarios, s.ID)
+				changes.Deleted = append(changes.Deleted, s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *configPlan) planIOCFeeds(items []bundleIOCFeed, prune bool, now time.Time) error {
+	changes := newConfigChanges()
+	p.changes["ioc_feeds"] = changes
+
+	current := iocFeedStore.List()
+	byName := make(map[string]models.IOCFeed, len(current))
+	for _, f := range current {
+		byName[f.Name] = f
+	}
+
+	seen := make(map[string]bool)
+	for i, item := range items {
+		if item.Name == "" {
+			return fmt.Errorf("ioc_feeds[%d]: name is required", i)
+		}
+		existing, ok := iocFeedStore.Get(item.ID)
+		if item.ID == "" {
+			existing, ok = byName[item.Name]
+		}
+
+		feed := models.IOCFeed{
+			ID:              item.ID,
+			Name:            item.Name,
+			URL:             item.URL,
+			Format:          item.Format,
+			Username:        item.Username,
+			Password:        item.Password,
+			IntervalMinutes: item.IntervalMinutes,
+			CreatedAt:       now,
+		}
+		if feed.Password == secrets.Mask {
+			if !ok {
+				return fmt.Errorf("feed %s: password is masked in this bundle; fill it in or export with ?secrets=encrypted", item.Name)
+			}
+			feed.Password = existing.Password
+		}
+		password, err := secrets.Open(feed.Password)
+		if err != nil {
+			return fmt.Errorf("feed %s: %w", item.Name, err)
+		}
+		feed.Password = password
+		if err := normalizeIOCFeed(&feed); err != nil {
+			return fmt.Errorf("feed %s: %w", item.Name, err)
+		}
+		if ok {
+			feed.ID = existing.ID
+		} else if feed.ID == "" {
+			feed.ID = uuid.New().String()
+		}
+		if seen[feed.ID] {
+			return fmt.Errorf("feed %s appears twice", item.Name)
+		}
+		seen[feed.ID] = true
+
+		switch {
+		case !ok:
+			changes.Created = append(changes.Created, feed.Name)
+		case sameJSON(toBundleIOCFeed(existing), toBundleIOCFeed(feed)):
+			changes.Unchanged = append(changes.Unchanged, feed.Name)
+			continue
+		default:
+			// A changed feed is polled again, like a new one
+			feed.CreatedAt = existing.CreatedAt
+			changes.Updated = append(changes.Updated, feed.Name)
+		}
+		p.feeds = append(p.feeds, &feed)
+	}
+
+	if prune {
+		for _, f := range current {
+			if !seen[f.ID] {
+				p.deleteFeeds = append(p.deleteFeeds, f.ID)
+				changes.Deleted = append(changes.Deleted, f.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// planIndicators works out the hand-added indicators of each source. Feed
+// indicators come from their feeds and are not part of the bundle.
+func (p *configPlan) planIndicators(items []bundleIndicator, prune bool, now time.Time) error {
+	changes := newConfigChanges()
+	p.changes["indicators"] = changes
+
+	iocs := make([]models.IOC, 0, len(items))
+	for i, item := range items {
+		if strings.HasPrefix(item.Source, "feed:") {
+			return fmt.Errorf("indicators[%d]: feed indicators cannot be imported", i)
+		}
+		source := item.Source
+		if source == "" {
+			source = "manual"
+		}
+		iocs = append(iocs, models.IOC{Type: item.Type, Value: item.Value, Source: source, Tags: item.Tags, AddedAt: now})
+	}
+	if err := generators.ValidateIOCs(iocs); err != nil {
+		return fmt.Errorf("indicators: %w", err)
+	}
+
+	current := make(map[string]models.IOC)
+	for _, ioc := range generators.IOCs.List("", "") {
+		if !strings.HasPrefix(ioc.Source, "feed:") {
+			current[string(ioc.Type)+"|"+ioc.Value] = ioc
+		}
+	}
+
+	// Start from the current indicators so that, without prune, sources the
+	// bundle does not mention keep theirs
+	final := make(map[string]models.IOC, len(current))
+	if !prune {
+		for key, ioc := range current {
+			final[key] = ioc
+		}
+	}
+	touched := make(map[string]bool)
+	for _, ioc := range iocs {
+		key := string(ioc.Type) + "|" + ioc.Value
+		name := string(ioc.Type) + ":" + ioc.Value
+		if _, dup := touched[key]; dup {
+			return fmt.Errorf("indicator %s appears twice", name)
+		}
+		touched[key] = true
+
+		existing, ok := current[key]
+		switch {
+		case !ok:
+			changes.Created = append(changes.Created, name)
+		case existing.Source == ioc.Source && sameJSON(existing.Tags, ioc.Tags):
+			changes.Unchanged = append(changes.Unchanged, name)
+			ioc.AddedAt = existing.AddedAt
+		default:
+			changes.Updated = append(changes.Updated, name)
+			ioc.AddedAt = existing.AddedAt
+		}
+		final[key] = ioc
+	}
+	for key, ioc := range current {
+		if _, ok := final[key]; !ok {
+			changes.Deleted = append(changes.Deleted, string(ioc.Type)+":"+ioc.Value)
+		}
+	}
+	sort.Strings(changes.Deleted)
+
+	if len(changes.Created)+len(changes.Updated)+len(changes.Deleted) == 0 {
+		return nil
+	}
+	p.indicators = make(map[string][]models.IOC)
+	for _, ioc := range current {
+		p.indicators[ioc.Source] = nil
+	}
+	for _, ioc := range final {
+		p.indicators[ioc.Source] = append(p.indicators[ioc.Source], ioc)
+	}
+	return nil
+}
+
+// planSettings checks the bundle's settings sections without applying them
+func (p *configPlan) planSettings(bundle *configBundle) error {
+	if bundle.IOCConfig == nil && bundle.GeoPolicy == nil && bundle.Theme == nil && bundle.NameProfile == nil && bundle.Anonymization == nil && bundle.Performance == nil && bundle.CloudTrail == nil && bundle.PKI == nil && bundle.Processes == nil && bundle.Web == nil && bundle.Limits == nil {
+		return nil
+	}
+	changes := newConfigChanges()
+	p.changes["settings"] = changes
+	record := func(name string, changed bool) {
+		if changed {
+			changes.Updated = append(changes.Updated, name)
+		} else {
+			changes.Unchanged = append(changes.Unchanged, name)
+		}
+	}
+
+	if cfg := bundle.IOCConfig; cfg != nil {
+		if err := generators.NewIOCPool().SetConfig(*cfg); err != nil {
+			return fmt.Errorf("ioc_config: %w", err)
+		}
+		changed := !sameJSON(generators.IOCs.Config(), cfg)
+		record("ioc_config", changed)
+		if changed {
+			p.iocConfig = cfg
+		}
+	}
+	if policy := bundle.GeoPolicy; policy != nil {
+		if err := generators.Geo.ValidatePolicy(*policy); err != nil {
+			return fmt.Errorf("geo_policy: %w", err)
+		}
+		changed := !sameJSON(generators.Geo.Policy(), policy)
+		record("geo_policy", changed)
+		if changed {
+			p.geoPolicy = policy
+		}
+	}
+	if theme := bundle.Theme; theme != nil {
+		if err := theme.Validate(); err != nil {
+			return fmt.Errorf("theme: %w", err)
+		}
+		changed := *theme != generators.Theme()
+		record("theme", changed)
+		if changed {
+			p.theme = theme
+		}
+	}
+	if profile := bundle.NameProfile; profile != nil {
+		if err := generators.Names.ValidateProfile(profile); err != nil {
+			return fmt.Errorf("name_profile: %w", err)
+		}
+		changed := !sameJSON(generators.Names.Profile(), profile)
+		record("name_profile", changed)
+		if changed {
+			p.nameProfile = profile
+		}
+	}
+	if cfg := bundle.Anonymization; cfg != nil {
+		current := delivery.Anonymization.Config()
+		if cfg.Salt == "" {
+			// Keep the current salt so tokens and hashes stay stable
+			cfg.Salt = current.Salt
+		}
+		if err := checkAnonymizationRules(*cfg); err != nil {
+			return fmt.Errorf("anonymization: %w", err)
+		}
+		scratch := delivery.NewAnonymizer()
+		if err := scratch.SetConfig(*cfg); err != nil {
+			return fmt.Errorf("anonymization: %w", err)
+		}
+		normalized := scratch.Config()
+		changed := !sameJSON(current, normalized)
+		record("anonymization", changed)
+		if changed {
+			p.anonymization = &normalized
+		}
+	}
+	if settings := bundle.Performance; settings != nil {
+		changed := settings.PerformanceMode != generators.PerformanceMode()
+		record("performance", changed)
+		if changed {
+			p.performance = settings
+		}
+	}
+	if cfg := bundle.CloudTrail; cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("cloudtrail: %w", err)
+		}
+		changed := *cfg != generators.CloudTrailConfig()
+		record("cloudtrail", changed)
+		if changed {
+			p.cloudTrail = cfg
+		}
+	}
+	if cfg := bundle.PKI; cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("pki: %w", err)
+		}
+		changed := *cfg != generators.PKIConfig()
+		record("pki", changed)
+		if changed {
+			p.pki = cfg
+		}
+	}
+	if cfg := bundle.Processes; cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("processes: %w", err)
+		}
+		changed := *cfg != generators.ProcessConfig()
+		record("processes", changed)
+		if changed {
+			p.processes = cfg
+		}
+	}
+	if cfg := bundle.Web; cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("web: %w", err)
+		}
+		changed := *cfg != generators.WebConfig()
+		record("web", changed)
+		if changed {
+			p.web = cfg
+		}
+	}
+	if cfg := bundle.Limits; cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("limits: %w", err)
+		}
+		changed := *cfg != serverLimits()
+		record("limits", changed)
+		if changed {
+			p.limits = cfg
+		}
+	}
+	return nil
+}
+
+// apply makes the planned changes and saves the sections that changed
+func (p *configPlan) apply() {
+	if len(p.destinations)+len(p.deleteDestinations) > 0 {
+		for _, d := range p.destinations {
+			if !destinationStore.Update(d) {
+				destinationStore.Create(d)
+			}
+		}
+		for _, id := range p.deleteDestinations {
+			destinationStore.Delete(id)
+			delivery.BatchMetrics.Reset(id)
+		}
+		SaveDestinations()
+	}
+
+	if len(p.templates)+len(p.deleteTemplates) > 0 {
+		for _, t := range p.templates {
+			if !templateStore.Update(t) {
+				templateStore.Create(t)
+			}
+		}
+		for _, id := range p.deleteTemplates {
+			templateStore.Delete(id)
+		}
+		SaveTemplates()
+	}
+
+	if len(p.scenarios)+len(p.deleteScenarios) > 0 {
+		for _, s := range p.scenarios {
+			if scenarioStore.Update(s) {
+				generators.Scenarios.Update(runningScenario(*s))
+			} else {
+				scenarioStore.Create(s)
+			}
+		}
+		for _, id := range p.deleteScenarios {
+			scenarioStore.Delete(id)
+			generators.Scenarios.Stop(id)
+			cancelDetectionCheck(id)
+		}
+		SaveScenarios()
+	}
+
+	iocsChanged := false
+	for _, f := range p.feeds {
+		// The poller fetches new and changed feeds on its next pass
+		iocFeedStore.Delete(f.ID)
+		iocFeedStore.Create(f)
+		iocsChanged = true
+	}
+	for _, id := range p.deleteFeeds {
+		iocFeedStore.Delete(id)
+		generators.IOCs.Remove(feedSource(id))
+		iocsChanged = true
+	}
+	for source, iocs := range p.indicators {
+		generators.IOCs.Replace(source, iocs)
+		iocsChanged = true
+	}
+	if p.iocConfig != nil {
+		generators.IOCs.SetConfig(*p.iocConfig)
+		iocsChanged = true
+	}
+	if iocsChanged {
+		SaveIOCs()
+	}
+
+	if p.geoPolicy != nil {
+		generators.Geo.SetPolicy(*p.geoPolicy)
+		SaveGeoPolicy()
+	}
+	if p.theme != nil {
+		generators.SetTheme(*p.theme)
+		SaveTheme()
+	}
+	if p.nameProfile != nil {
+		generators.Names.SetProfile(*p.nameProfile)
+		SaveNameProfile()
+	}
+	if p.anonymization != nil {
+		delivery.Anonymization.SetConfig(*p.anonymization)
+		SaveAnonymization()
+	}
+	if p.performance != nil {
+		generators.SetPerformanceMode(p.performance.PerformanceMode)
+		SavePerformance()
+	}
+	if p.cloudTrail != nil {
+		generators.SetCloudTrailConfig(*p.cloudTrail)
+		SaveCloudTrailConfig()
+	}
+	if p.pki != nil {
+		generators.SetPKIConfig(*p.pki)
+		SavePKIConfig()
+	}
+	if p.processes != nil {
+		generators.SetProcessConfig(*p.processes)
+		SaveProcessConfig()
+	}
+	if p.web != nil {
+		generators.SetWebConfig(*p.web)
+		SaveWebConfig()
+	}
+	if p.limits != nil {
+		setServerLimits(*p.limits)
+		SaveServerLimits()
+	}
+}
+
+// jsonToYAML converts JSON to block-style YAML, keeping object keys in the
+// order they were encoded rather than sorting them
+func jsonToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := yamlNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlNode reads the next JSON value from dec as a YAML node
+func yamlNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if v == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := yamlNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return node, nil
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		if strings.Contains(v, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
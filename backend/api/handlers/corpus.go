@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+	"siem-event-generator/storage"
+)
+
+// SnapshotCorpus snapshots every template's output with a fixed seed and
+// stores it as the corpus later output is diffed against
+func SnapshotCorpus(c *gin.Context) {
+	var req models.CorpusSnapshotRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+	if store == nil {
+		respondError(c, models.CodeStorageUnavailable, "Storage is not configured")
+		return
+	}
+	if req.Seed == 0 {
+		req.Seed = generators.DefaultCorpusSeed
+	}
+
+	corpus, err := generators.SnapshotCorpus(c.Request.Context(), req.Seed, req.Samples)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	data, err := json.Marshal(corpus)
+	if err == nil {
+		err = store.SetMeta(c.Request.Context(), storage.MetaCorpus, string(data))
+	}
+	if err != nil {
+		respondError(c, models.CodeInternal, "failed to save corpus: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, corpus)
+}
+
+// GetCorpus returns the stored corpus snapshot
+func GetCorpus(c *gin.Context) {
+	corpus, ok := loadCorpus(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, corpus)
+}
+
+// DiffCorpus compares current output with the corpus in the request, or
+// the stored one, and reports added, removed, renamed and retyped fields
+func DiffCorpus(c *gin.Context) {
+	var req models.CorpusDiffRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	corpus := req.Corpus
+	if corpus == nil {
+		var ok bool
+		if corpus, ok = loadCorpus(c); !ok {
+			return
+		}
+	}
+
+	diff, err := generators.DiffCorpus(c.Request.Context(), corpus)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// loadCorpus reads the stored corpus, responding with an error if there
+// is none
+func loadCorpus(c *gin.Context) (*models.Corpus, bool) {
+	if store == nil {
+		respondError(c, models.CodeStorageUnavailable, "Storage is not configured")
+		return nil, false
+	}
+	value, ok, err := store.Meta(c.Request.Context(), storage.MetaCorpus)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return nil, false
+	}
+	if !ok {
+		respondError(c, models.CodeNotFound, "No corpus snapshot; create one with POST /api/corpus/snapshot")
+		return nil, false
+	}
+
+	var corpus models.Corpus
+	if err := json.Unmarshal([]byte(value), &corpus); err != nil {
+		respondError(c, models.CodeInternal, "invalid stored corpus: "+err.Error())
+		return nil, false
+	}
+	return &corpus, true
+}
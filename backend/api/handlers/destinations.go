@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
@@ -10,6 +12,7 @@ import (
 
 	"siem-event-generator/delivery"
 	"siem-event-generator/models"
+	"siem-event-generator/secrets"
 )
 
 // DestinationStore provides thread-safe destination storage
@@ -76,14 +79,63 @@ func (s *DestinationStore) Delete(id string) bool {
 // Global destination store (in production, use a database)
 var destinationStore = NewDestinationStore()
 
+func init() {
+	// Routing rules forward events to other saved destinations
+	delivery.ResolveDestination = destinationStore.Get
+}
+
+// checkDestination validates a destination's routing rules, reconcile
+// settings and secret references and responds with 400 when they are invalid. It returns false
+// if a response was written.
+func checkDestination(c *gin.Context, dest *models.Destination) bool {
+	err := delivery.ValidateRoutes(dest.Config.Routes, dest.ID)
+	if err == nil {
+		for _, rule := range dest.Config.Routes {
+			if rule.DestinationID == "" {
+				continue
+			}
+			if _, ok := destinationStore.Get(rule.DestinationID); !ok {
+				err = fmt.Errorf("route target destination not found: %s", rule.DestinationID)
+				break
+			}
+		}
+	}
+	if err == nil && dest.Config.Reconcile != nil {
+		err = dest.Config.Reconcile.Validate(dest.Type)
+	}
+	if err == nil {
+		err = secrets.CheckConfig(dest.Config)
+	}
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return false
+	}
+	return true
+}
+
 
-// ListDestinations returns all destinations
+// redacted returns a copy of dest with its credentials masked for a response
+func redacted(dest *models.Destination) *models.Destination {
+	copied := *dest
+	copied.Config = secrets.RedactConfig(dest.Config)
+	return &copied
+}
+
+// ListDestinations returns the destinations, paged, filtered and sorted
+// as paginate describes
 func ListDestinations(c *gin.Context) {
 	destinations := destinationStore.List()
-	c.JSON(http.StatusOK, gin.H{
+	for i, d := range destinations {
+		destinations[i] = redacted(d)
+	}
+	destinations, page, ok := paginate(c, destinations, 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
 		"destinations": destinations,
 		"count":        len(destinations),
-	})
+	}))
 }
 
 // GetDestination returns a specific destination
@@ -92,33 +144,32 @@ func GetDestination(c *gin.Context) {
 
 	dest, ok := destinationStore.Get(id)
 	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Destination not found",
-		})
+		respondError(c, models.CodeDestinationNotFound, "Destination not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, dest)
+	c.JSON(http.StatusOK, redacted(dest))
 }
 
 // CreateDestination creates a new destination
 func CreateDestination(c *gin.Context) {
 	var dest models.Destination
 	if err := c.ShouldBindJSON(&dest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
 	dest.ID = uuid.New().String()
+	if !checkDestination(c, &dest) {
+		return
+	}
 	dest.CreatedAt = time.Now()
 	dest.UpdatedAt = time.Now()
 
 	destinationStore.Create(&dest)
 	SaveDestinations()
 
-	c.JSON(http.StatusCreated, dest)
+	c.JSON(http.StatusCreated, redacted(&dest))
 }
 
 // UpdateDestination updates an existing destination
@@ -127,21 +178,21 @@ func UpdateDestination(c *gin.Context) {
 
 	existing, ok := destinationStore.Get(id)
 	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Destination not found",
-		})
+		respondError(c, models.CodeDestinationNotFound, "Destination not found")
 		return
 	}
 
 	var dest models.Destination
 	if err := c.ShouldBindJSON(&dest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
 	dest.ID = id
+	dest.Config = secrets.RestoreMasked(dest.Config, existing.Config)
+	if !checkDestination(c, &dest) {
+		return
+	}
 	dest.CreatedAt = existing.CreatedAt
 	dest.UpdatedAt = time.Now()
 	dest.EventsSent = existing.EventsSent
@@ -149,7 +200,7 @@ func UpdateDestination(c *gin.Context) {
 	destinationStore.Update(&dest)
 	SaveDestinations()
 
-	c.JSON(http.StatusOK, dest)
+	c.JSON(http.StatusOK, redacted(&dest))
 }
 
 // DeleteDestination removes a destination
@@ -157,31 +208,42 @@ func DeleteDestination(c *gin.Context) {
 	id := c.Param("id")
 
 	if !destinationStore.Delete(id) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Destination not found",
-		})
+		respondError(c, models.CodeDestinationNotFound, "Destination not found")
 		return
 	}
 	SaveDestinations()
+	delivery.BatchMetrics.Reset(id)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Destination deleted",
 	})
 }
 
+// GetDestinationStats returns the batching metrics of an HTTP destination:
+// achieved batch sizes, flush triggers and compression ratio
+func GetDestinationStats(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, ok := destinationStore.Get(id); !ok {
+		respondError(c, models.CodeDestinationNotFound, "Destination not found")
+		return
+	}
+
+	stats, _ := delivery.BatchMetrics.Get(id)
+	c.JSON(http.StatusOK, stats)
+}
+
 // TestDestination tests a saved destination connection
 func TestDestination(c *gin.Context) {
 	id := c.Param("id")
 
 	dest, ok := destinationStore.Get(id)
 	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Destination not found",
-		})
+		respondError(c, models.CodeDestinationNotFound, "Destination not found")
 		return
 	}
 
-	response := testDestinationConnection(dest)
+	response := testDestinationConnection(c.Request.Context(), dest)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -189,9 +251,7 @@ func TestDestination(c *gin.Context) {
 func TestDestinationConfig(c *gin.Context) {
 	var req models.TestConnectionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
@@ -200,37 +260,37 @@ func TestDestinationConfig(c *gin.Context) {
 		Config: req.Config,
 	}
 
-	response := testDestinationConnection(dest)
+	response := testDestinationConnection(c.Request.Context(), dest)
 	c.JSON(http.StatusOK, response)
 }
 
-// testDestinationConnection performs the actual connection test
-func testDestinationConnection(dest *models.Destination) models.TestConnectionResponse {
+// testDestinationConnection runs the destination's diagnostics; the first
+// failed step is reported as the error
+func testDestinationConnection(ctx context.Context, dest *models.Destination) models.TestConnectionResponse {
 	startTime := time.Now()
+	steps := delivery.Diagnose(ctx, dest)
 
-	sender, err := delivery.GetSender(dest)
-	if err != nil {
-		return models.TestConnectionResponse{
-			Success: false,
-			Message: "Failed to create sender",
-			Error:   err.Error(),
-		}
-	}
-	defer sender.Close()
-
-	// Test the connection
-	if err := sender.Test(); err != nil {
-		return models.TestConnectionResponse{
-			Success:   false,
-			Message:   "Connection test failed",
-			Error:     err.Error(),
-			LatencyMs: time.Since(startTime).Milliseconds(),
-		}
-	}
-
-	return models.TestConnectionResponse{
+	response := models.TestConnectionResponse{
 		Success:   true,
 		Message:   "Connection successful",
 		LatencyMs: time.Since(startTime).Milliseconds(),
+		Steps:     steps,
+	}
+	for _, step := range steps {
+		if step.Status == models.DiagnosticFailed {
+			response.Success = false
+			response.Message = "Connection test failed at " + step.Name
+			response.Error = step.Error
+			switch step.Name {
+			case "config":
+				response.Code = models.CodeValidationFailed
+			case "auth":
+				response.Code = models.CodeDestinationAuthFailed
+			default:
+				response.Code = models.CodeDestinationUnreachable
+			}
+			break
+		}
 	}
+	return response
 }
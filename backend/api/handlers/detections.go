@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/detections"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+	"siem-event-generator/notify"
+	"siem-event-generator/secrets"
+	"siem-event-generator/storage"
+)
+
+// detectionBackendsSetting is the settings document of the SIEM backends
+const detectionBackendsSetting = "detections"
+
+// statsKindDetections is the kind of the samples holding detection reports,
+// keyed by scenario ID; the newest one counts
+const statsKindDetections = "detections"
+
+// Detection check timing
+const (
+	defaultDetectionWait  = 5 * time.Minute
+	detectionCheckTimeout = 5 * time.Minute
+)
+
+var detectionBackends = struct {
+	sync.RWMutex
+	models.DetectionBackends
+}{}
+
+// getDetectionBackends returns the SIEM backends with their secrets
+func getDetectionBackends() models.DetectionBackends {
+	detectionBackends.RLock()
+	defer detectionBackends.RUnlock()
+	return detectionBackends.DetectionBackends
+}
+
+// setDetectionBackends validates and sets the SIEM backends
+func setDetectionBackends(cfg models.DetectionBackends) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	detectionBackends.Lock()
+	detectionBackends.DetectionBackends = cfg
+	detectionBackends.Unlock()
+	return nil
+}
+
+// backendSecrets calls fn with each secret the backends hold. The backends
+// are copied before they are changed.
+func backendSecrets(cfg *models.DetectionBackends, fn func(v *string) error) error {
+	if cfg.Splunk != nil {
+		splunk := *cfg.Splunk
+		if err := fn(&splunk.Token); err != nil {
+			return err
+		}
+		if err := fn(&splunk.Password); err != nil {
+			return err
+		}
+		cfg.Splunk = &splunk
+	}
+	if cfg.Sentinel != nil {
+		sentinel := *cfg.Sentinel
+		if err := fn(&sentinel.ClientSecret); err != nil {
+			return err
+		}
+		cfg.Sentinel = &sentinel
+	}
+	return nil
+}
+
+// maskBackends returns the backends with their secrets masked for responses
+func maskBackends(cfg models.DetectionBackends) models.DetectionBackends {
+	backendSecrets(&cfg, func(v *string) error {
+		if *v != "" {
+			*v = secrets.Mask
+		}
+		return nil
+	})
+	return cfg
+}
+
+// restoreMaskedBackends keeps the existing secret wherever an update sent
+// back the mask
+func restoreMaskedBackends(cfg, existing models.DetectionBackends) models.DetectionBackends {
+	if cfg.Splunk != nil && existing.Splunk != nil {
+		splunk := *cfg.Splunk
+		if splunk.Token == secrets.Mask {
+			splunk.Token = existing.Splunk.Token
+		}
+		if splunk.Password == secrets.Mask {
+			splunk.Password = existing.Splunk.Password
+		}
+		cfg.Splunk = &splunk
+	}
+	if cfg.Sentinel != nil && existing.Sentinel != nil && cfg.Sentinel.ClientSecret == secrets.Mask {
+		sentinel := *cfg.Sentinel
+		sentinel.ClientSecret = existing.Sentinel.ClientSecret
+		cfg.Sentinel = &sentinel
+	}
+	return cfg
+}
+
+// GetDetectionBackends returns the SIEM backends with secrets masked
+func GetDetectionBackends(c *gin.Context) {
+	c.JSON(http.StatusOK, maskBackends(getDetectionBackends()))
+}
+
+// UpdateDetectionBackends sets the SIEM backends expected detections are
+// checked against
+func UpdateDetectionBackends(c *gin.Context) {
+	var cfg models.DetectionBackends
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		bindError(c, err)
+		return
+	}
+	cfg = restoreMaskedBackends(cfg, getDetectionBackends())
+	if err := setDetectionBackends(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveDetectionBackends()
+
+	c.JSON(http.StatusOK, maskBackends(cfg))
+}
+
+// pendingDetectionChecks are the checks scheduled for triggered scenarios,
+// by scenario ID
+var pendingDetectionChecks = struct {
+	sync.Mutex
+	timers map[string]*time.Timer
+}{timers: make(map[string]*time.Timer)}
+
+// scheduleDetectionCheck checks a scenario's expected detections once it
+// has played out and the SIEM has had time to raise them, replacing any
+// check pending for an earlier trigger
+func scheduleDetectionCheck(scenario *models.Scenario, status models.ScenarioStatus) {
+	cancelDetectionCheck(scenario.ID)
+	if len(scenario.ExpectedDetections) == 0 || status.TriggeredAt == nil || status.EndsAt == nil {
+		return
+	}
+	wait := time.Duration(scenario.DetectionWaitSeconds) * time.Second
+	if wait == 0 {
+		wait = defaultDetectionWait
+	}
+	from := *status.TriggeredAt
+	id := scenario.ID
+
+	pendingDetectionChecks.Lock()
+	defer pendingDetectionChecks.Unlock()
+	pendingDetectionChecks.timers[id] = time.AfterFunc(time.Until(status.EndsAt.Add(wait)), func() {
+		pendingDetectionChecks.Lock()
+		delete(pendingDetectionChecks.timers, id)
+		pendingDetectionChecks.Unlock()
+
+		current, ok := scenarioStore.Get(id)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), detectionCheckTimeout)
+		defer cancel()
+		report := checkDetections(ctx, current, from, time.Now())
+		if err := recordDetectionReport(report); err != nil {
+			log.Printf("WARNING: failed to record detection report of scenario %s: %v", current.Name, err)
+		}
+	})
+}
+
+// cancelDetectionCheck drops a scenario's pending check, if it has one
+func cancelDetectionCheck(id string) {
+	pendingDetectionChecks.Lock()
+	defer pendingDetectionChecks.Unlock()
+	if timer, ok := pendingDetectionChecks.timers[id]; ok {
+		timer.Stop()
+		delete(pendingDetectionChecks.timers, id)
+	}
+}
+
+// checkDetections asks the SIEM which of a scenario's expected detections
+// fired between from and to, logging and notifying any that did not
+func checkDetections(ctx context.Context, scenario *models.Scenario, from, to time.Time) models.DetectionReport {
+	results := detections.Check(ctx, getDetectionBackends(), scenario.ExpectedDetections, from, to)
+	report := detections.Report(scenario, results, from, to)
+	if !report.Passed {
+		log.Printf("WARNING: scenario %s raised %d of %d expected detections", scenario.Name, report.Fired, len(results))
+		notifyDetectionsMissing(report)
+	}
+	return report
+}
+
+// notifyDetectionsMissing tells the notification channels that a scenario's
+// expected detections did not all fire
+func notifyDetectionsMissing(report models.DetectionReport) {
+	missing := make([]string, 0, report.Missing+report.Errors)
+	for _, r := range report.Results {
+		if r.Status != models.DetectionFired {
+			missing = append(missing, r.Label())
+		}
+	}
+	notify.Publish(models.Notification{
+		Event:   models.NotifyDetectionsMissing,
+		Summary: fmt.Sprintf("Scenario %s raised %d of %d expected detections", report.Scenario, report.Fired, len(report.Results)),
+		Details: map[string]interface{}{
+			"scenario_id": report.ScenarioID,
+			"fired":       report.Fired,
+			"missing":     missing,
+			"errors":      report.Errors,
+		},
+		Time: report.CheckedAt,
+	})
+}
+
+// recordDetectionReport stores a scenario's latest detection report
+func recordDetectionReport(report models.DetectionReport) error {
+	if store == nil {
+		return nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	return store.RecordStats(ctx, storage.Sample{Kind: statsKindDetections, Key: report.ScenarioID, RecordedAt: report.CheckedAt, Data: data})
+}
+
+// CheckScenarioDetections asks the SIEM now which of a scenario's expected
+// detections fired since it was last triggered
+func CheckScenarioDetections(c *gin.Context) {
+	scenario, ok := scenarioStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeScenarioNotFound, "Scenario not found")
+		return
+	}
+	if len(scenario.ExpectedDetections) == 0 {
+		respondError(c, models.CodeValidationFailed, "expected_detections is empty; add the alerts the scenario should raise")
+		return
+	}
+	status := generators.Scenarios.Status(scenario.ID, time.Now())
+	if status.TriggeredAt == nil {
+		respondError(c, models.CodeScenarioNotRunning, "Scenario has not been triggered")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), detectionCheckTimeout)
+	defer cancel()
+	report := checkDetections(ctx, scenario, *status.TriggeredAt, time.Now())
+	if err := recordDetectionReport(report); err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetScenarioDetections returns a scenario's latest detection report
+func GetScenarioDetections(c *gin.Context) {
+	scenario, ok := scenarioStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeScenarioNotFound, "Scenario not found")
+		return
+	}
+	if store == nil {
+		respondError(c, models.CodeStorageUnavailable, "Storage is not configured")
+		return
+	}
+	samples, err := store.Stats(c.Request.Context(), statsKindDetections, time.Now().Add(-statsRetention), maxRunScan)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	for _, sample := range samples {
+		if sample.Key != scenario.ID {
+			continue
+		}
+		var report models.DetectionReport
+		if err := json.Unmarshal(sample.Data, &report); err != nil {
+			respondError(c, models.CodeInternal, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	respondError(c, models.CodeNotFound, "Scenario's detections have not been checked yet")
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"siem-event-generator/cluster"
+	"siem-event-generator/delivery"
+	"siem-event-generator/models"
+	"siem-event-generator/noise"
+)
+
+func init() {
+	// Name fields in binding errors as the JSON does
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// messageField picks out the request field a message starts with, as in
+// "count must be between 1 and 10000"
+var messageField = regexp.MustCompile(`^([a-z][a-z0-9_]*(?:\.[a-z0-9_]+)*) (?:must|cannot|needs|requires|is|are)\b`)
+
+// apiError builds the response for code with its catalog status and hint
+func apiError(code, message string) (int, models.APIError) {
+	entry := models.LookupErrorCode(code)
+	resp := models.APIError{
+		Code:    code,
+		Message: message,
+		Error:   message,
+		Hint:    entry.Hint,
+	}
+	if m := messageField.FindStringSubmatch(message); m != nil {
+		resp.Field = m[1]
+	}
+	return entry.Status, resp
+}
+
+// respondError answers the request with an error of code
+func respondError(c *gin.Context, code, message string) {
+	c.JSON(apiError(code, message))
+}
+
+// bindError answers a request whose body or query could not be bound,
+// naming the field at fault when the binding does
+func bindError(c *gin.Context, err error) {
+	code, message, field := models.CodeInvalidRequest, err.Error(), ""
+	var typeErr *json.UnmarshalTypeError
+	var fieldErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &typeErr):
+		field = typeErr.Field
+		message = field + " must be " + typeErr.Type.String() + ", not " + typeErr.Value
+	case errors.As(err, &fieldErrs) && len(fieldErrs) > 0:
+		// The namespace starts with the request's type name
+		field = fieldErrs[0].Namespace()
+		if _, f, ok := strings.Cut(field, "."); ok {
+			field = f
+		}
+		if fieldErrs[0].Tag() == "required" {
+			code, message = models.CodeValidationFailed, field+" is required"
+		}
+	}
+	status, resp := apiError(code, message)
+	if field != "" {
+		resp.Field = field
+	}
+	c.JSON(status, resp)
+}
+
+// noiseError answers a noise run request the generator or coordinator
+// refused
+func noiseError(c *gin.Context, err error) {
+	code := models.CodeValidationFailed
+	switch {
+	case errors.Is(err, noise.ErrRunning):
+		code = models.CodeNoiseRunning
+	case errors.Is(err, noise.ErrNotRunning):
+		code = models.CodeNoiseNotRunning
+	case errors.Is(err, cluster.ErrNoWorkers):
+		code = models.CodeNoClusterWorkers
+	case errors.Is(err, delivery.ErrAuthFailed):
+		code = models.CodeDestinationAuthFailed
+	case errors.Is(err, noise.ErrSender):
+		code = models.CodeDestinationUnreachable
+	}
+	respondError(c, code, err.Error())
+}
+
+// sendErrorCode is the code of a generate result or connection test whose
+// sends failed with err
+func sendErrorCode(err error) string {
+	if errors.Is(err, delivery.ErrAuthFailed) {
+		return models.CodeDestinationAuthFailed
+	}
+	return models.CodeDestinationUnreachable
+}
+
+// NoRoute answers requests for paths the API does not have
+func NoRoute(c *gin.Context) {
+	respondError(c, models.CodeNotFound, "No route for "+c.Request.Method+" "+c.Request.URL.Path)
+}
+
+// GetErrorCatalog lists the error codes the API answers with
+func GetErrorCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"codes": models.ErrorCatalog,
+	})
+}
@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -13,11 +20,14 @@ import (
 
 // ListEventTypes returns all available event types
 func ListEventTypes(c *gin.Context) {
-	eventTypes := generators.GetAllEventTypes()
-	c.JSON(http.StatusOK, gin.H{
+	eventTypes, page, ok := paginate(c, generators.GetAllEventTypes(), 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
 		"event_types": eventTypes,
 		"count":       len(eventTypes),
-	})
+	}))
 }
 
 // GetEventTypeSchema returns the schema for a specific event type
@@ -26,9 +36,7 @@ func GetEventTypeSchema(c *gin.Context) {
 
 	gen, ok := generators.GetGenerator(eventType)
 	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Event type not found",
-		})
+		respondError(c, models.CodeEventTypeNotFound, "Event type not found")
 		return
 	}
 
@@ -44,75 +52,191 @@ func GetEventTypeSchema(c *gin.Context) {
 func GenerateEvents(c *gin.Context) {
 	var req models.GenerateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
+	generateEvents(c, &req, "")
+}
+
+// generateEvents generates and sends the events of req and records the
+// batch in the run history; replayOf names the run it replays, if any
+func generateEvents(c *gin.Context, req *models.GenerateRequest, replayOf string) {
 
 	gen, ok := generators.GetGenerator(req.EventType)
 	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Event type not found",
-		})
+		respondError(c, models.CodeEventTypeNotFound, "Event type not found")
+		return
+	}
+
+	templateID, err := generators.ResolveTemplateID(gen, req.EventID)
+	if err != nil {
+		respondError(c, models.CodeTemplateNotFound, err.Error())
+		return
+	}
+
+	if !checkSchemaVersion(c, gen, templateID, req.SchemaVersion) {
+		return
+	}
+	if !applyPreset(c, req.EventType, templateID, req.Preset, &req.Overrides) {
+		return
+	}
+	if !applyCampaign(c, &req.Overrides) {
+		return
+	}
+	if !checkOverrides(c, gen, templateID, req.Overrides, req.StrictOverrides) {
+		return
+	}
+	if err := generators.ValidateOutput(req.Output); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	var fuzzer *generators.TimestampFuzzer
+	if req.TimestampFuzz != nil {
+		if fuzzer, err = generators.NewTimestampFuzzer(*req.TimestampFuzz); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	var skew *generators.ClockSkew
+	if req.ClockSkew != nil {
+		if skew, err = generators.NewClockSkew(*req.ClockSkew); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	var chaos *generators.Chaos
+	if req.Chaos != nil {
+		if chaos, err = generators.NewChaos(*req.Chaos); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	var renderer *generators.Renderer
+	if req.Render != nil {
+		if renderer, err = generators.NewRenderer(*req.Render); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+
+	if !checkGenerateSize(c, req) {
+		return
+	}
+	if req.ReturnEvents && len(requestDestinations(req)) > 0 {
+		respondError(c, models.CodeValidationFailed, "return_events is for requests without a destination")
+		return
+	}
+	if req.DryRun {
+		estimateGenerate(c, req, gen, templateID)
+		return
+	}
+
+	// Only a request that passed every check counts as use or takes a slot
+	recordUse(c, []models.TemplateRef{{EventType: req.EventType, TemplateID: templateID}},
+		append([]string{req.DestinationID}, req.DestinationIDs...))
+	rate, release, err := admitBatch(requestDestinations(req), float64(req.RatePerSecond))
+	if err != nil {
+		respondError(c, models.CodeServerLimitReached, err.Error())
+		return
+	}
+	defer release()
+	var stream *generators.RandomStream
+	if req.Seed != 0 {
+		stream = generators.NewRandomStream(req.Seed)
+	}
+	if req.Budget != nil {
+		generateWithBudget(c, req, gen, stream, templateID, fuzzer, skew, renderer, chaos, rate, replayOf)
 		return
 	}
 
 	// Generate events
+	startedAt := time.Now()
 	events := make([]*models.GeneratedEvent, 0, req.Count)
 	errors := make([]string, 0)
 
+	// Workers claim event slots until Count events are generated
 	var wg sync.WaitGroup
 	var mu sync.Mutex
-	semaphore := make(chan struct{}, 10) // Limit concurrent generation
+	var claimed int64
 
-	for i := 0; i < req.Count; i++ {
+	workers := runtime.NumCPU()
+	if req.Seed != 0 {
+		workers = 1 // Draw from the seeded stream in a fixed order
+	}
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			templateID := req.EventID
-			if templateID == "" {
-				// Use first available template if not specified
-				templates := gen.GetTemplates()
-				if len(templates) > 0 {
-					templateID = templates[0].ID
+			for atomic.AddInt64(&claimed, 1) <= int64(req.Count) {
+				event, err := stream.Generate(gen, templateID, req.Overrides)
+				if err == nil {
+					event, _ = chaos.Apply(renderer.Apply(fuzzer.Apply(skew.Apply(event))))
 				}
-			}
 
-			event, err := gen.Generate(templateID, req.Overrides)
-			if err != nil {
 				mu.Lock()
-				errors = append(errors, err.Error())
+				if err != nil {
+					errors = append(errors, err.Error())
+				} else {
+					events = append(events, event)
+				}
 				mu.Unlock()
-				return
 			}
-
-			mu.Lock()
-			events = append(events, event)
-			mu.Unlock()
 		}()
 	}
 
 	wg.Wait()
 
-	// Send to destination if specified
+	// Send to destination if specified, paced under a server rate limit
 	var eventsSent int
 	var destinationName string
+	var deliveries []models.DeliveryResult
+	var code string // Of the first destination or send error
+	failed := func(c string) {
+		if code == "" {
+			code = c
+		}
+	}
 
-	if req.DestinationID != "" {
-		dest, exists := destinationStore.Get(req.DestinationID)
+	var ticker *time.Ticker
+	if rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+	}
+	wait := func(i int) bool {
+		if ticker == nil {
+			return true
+		}
+		if i > 0 {
+			select {
+			case <-ticker.C:
+			case <-c.Request.Context().Done():
+			}
+		}
+		if c.Request.Context().Err() != nil {
+			errors = append(errors, "Request cancelled")
+			return false
+		}
+		return true
+	}
+
+	destIDs := requestDestinations(req)
+	if len(destIDs) == 1 {
+		dest, exists := destinationStore.Get(destIDs[0])
 		if exists {
 			destinationName = dest.Name
 			sender, err := delivery.GetSender(dest)
 			if err != nil {
 				errors = append(errors, "Failed to create sender: "+err.Error())
+				failed(sendErrorCode(err))
 			} else {
-				for _, event := range events {
+				for i, event := range events {
+					if !wait(i) {
+						break
+					}
 					if err := sender.Send(event); err != nil {
 						errors = append(errors, "Send error: "+err.Error())
+						failed(sendErrorCode(err))
 					} else {
 						eventsSent++
 					}
@@ -121,13 +245,50 @@ func GenerateEvents(c *gin.Context) {
 			}
 		} else {
 			errors = append(errors, "Destination not found")
+			failed(models.CodeDestinationNotFound)
+		}
+	} else if len(destIDs) > 1 {
+		dests := make([]*models.Destination, 0, len(destIDs))
+		names := make([]string, 0, len(destIDs))
+		for _, id := range destIDs {
+			dest, exists := destinationStore.Get(id)
+			if !exists {
+				errors = append(errors, "Destination not found: "+id)
+				failed(models.CodeDestinationNotFound)
+				continue
+			}
+			dests = append(dests, dest)
+			names = append(names, dest.Name)
+		}
+		destinationName = strings.Join(names, ", ")
+		if len(dests) > 0 {
+			fanOut, err := delivery.NewFanOut(dests)
+			if err != nil {
+				errors = append(errors, "Failed to create sender: "+err.Error())
+				failed(sendErrorCode(err))
+			} else {
+				for i, event := range events {
+					if !wait(i) {
+						break
+					}
+					if err := fanOut.Send(event); err != nil {
+						errors = append(errors, "Send error: "+err.Error())
+						failed(sendErrorCode(err))
+					}
+				}
+				fanOut.Close()
+				deliveries = fanOut.Results()
+				for _, d := range deliveries {
+					eventsSent += int(d.EventsSent)
+				}
+			}
 		}
 	}
 
 	// Prepare preview (limit to 5 events)
 	preview := make([]models.GeneratedEvent, 0)
 	for i := 0; i < len(events) && i < 5; i++ {
-		preview = append(preview, *events[i])
+		preview = append(preview, *generators.ApplyOutput(events[i], req.Output))
 	}
 
 	response := models.GenerateResponse{
@@ -136,47 +297,383 @@ func GenerateEvents(c *gin.Context) {
 		EventsSent:    eventsSent,
 		Destination:   destinationName,
 		Errors:        errors,
+		Code:          code,
 		Preview:       preview,
+		Deliveries:    deliveries,
+		Warnings:      templateWarnings(gen, templateID),
+	}
+	if req.ReturnEvents {
+		response.Events = make([]models.GeneratedEvent, len(events))
+		for i, event := range events {
+			response.Events[i] = *generators.ApplyOutput(event, req.Output)
+		}
 	}
+	recordBatchRun(req, startedAt, &response, replayOf)
 
 	c.JSON(http.StatusOK, response)
 }
 
+// budgetMaxConsecutiveErrors stops a budgeted run against a destination that
+// keeps failing, since failed sends never use up the budget
+const budgetMaxConsecutiveErrors = 100
+
+// requestDestinations merges destination_id and destination_ids, dropping
+// duplicates
+func requestDestinations(req *models.GenerateRequest) []string {
+	ids := make([]string, 0, len(req.DestinationIDs)+1)
+	seen := make(map[string]bool)
+	for _, id := range append([]string{req.DestinationID}, req.DestinationIDs...) {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// generateWithBudget generates and sends events one at a time until the
+// volume budget or the optional count is reached, keeping only a preview in
+// memory. The last event that would go over the budget is not sent.
+func generateWithBudget(c *gin.Context, req *models.GenerateRequest, gen generators.Generator, stream *generators.RandomStream, templateID string, fuzzer *generators.TimestampFuzzer, skew *generators.ClockSkew, renderer *generators.Renderer, chaos *generators.Chaos, rate float64, replayOf string) {
+	dest, ok := destinationStore.Get(req.DestinationID)
+	if !ok {
+		respondError(c, models.CodeDestinationNotFound, "Destination not found")
+		return
+	}
+
+	sender, err := delivery.GetSender(dest)
+	if err != nil {
+		c.JSON(http.StatusOK, models.GenerateResponse{
+			Destination: dest.Name,
+			Errors:      []string{"Failed to create sender: " + err.Error()},
+			Code:        sendErrorCode(err),
+		})
+		return
+	}
+	budget := delivery.NewBudget(*req.Budget)
+	sender = delivery.WithBudget(sender, budget)
+	startedAt := time.Now()
+
+	if rate == 0 {
+		rate = float64(req.RatePerSecond)
+	}
+	var ticker *time.Ticker
+	if rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+	}
+
+	resp := models.GenerateResponse{
+		Destination: dest.Name,
+		Preview:     make([]models.GeneratedEvent, 0),
+		Warnings:    templateWarnings(gen, templateID),
+	}
+	failures := 0
+	for req.Count <= 0 || resp.EventsCreated < req.Count {
+		if ticker != nil && resp.EventsCreated > 0 {
+			select {
+			case <-ticker.C:
+			case <-c.Request.Context().Done():
+			}
+		}
+		if c.Request.Context().Err() != nil {
+			resp.Errors = append(resp.Errors, "Request cancelled")
+			break
+		}
+
+		event, err := stream.Generate(gen, templateID, req.Overrides)
+		if err != nil {
+			resp.Errors = append(resp.Errors, err.Error())
+			break
+		}
+		event, _ = chaos.Apply(renderer.Apply(fuzzer.Apply(skew.Apply(event))))
+		err = sender.Send(event)
+		if errors.Is(err, delivery.ErrBudgetExhausted) {
+			break
+		}
+		resp.EventsCreated++
+		if len(resp.Preview) < 5 {
+			resp.Preview = append(resp.Preview, *generators.ApplyOutput(event, req.Output))
+		}
+		if err != nil {
+			resp.Errors = append(resp.Errors, "Send error: "+err.Error())
+			if resp.Code == "" {
+				resp.Code = sendErrorCode(err)
+			}
+			if failures++; failures >= budgetMaxConsecutiveErrors {
+				resp.Errors = append(resp.Errors, "Stopped after repeated send errors")
+				break
+			}
+			continue
+		}
+		failures = 0
+		resp.EventsSent++
+	}
+	if err := sender.Close(); err != nil {
+		resp.Errors = append(resp.Errors, "Close error: "+err.Error())
+	}
+
+	usage := budget.Usage()
+	resp.Budget = &usage
+	resp.Success = len(resp.Errors) == 0
+	recordBatchRun(req, startedAt, &resp, replayOf)
+	c.JSON(http.StatusOK, resp)
+}
+
+// estimateSamples is how many events a generate dry run measures
+const estimateSamples = 50
+
+// checkGenerateSize responds with 400 unless req sets a count in range or
+// a budget for one destination
+func checkGenerateSize(c *gin.Context, req *models.GenerateRequest) bool {
+	if req.Budget != nil {
+		if len(req.DestinationIDs) > 0 {
+			respondError(c, models.CodeValidationFailed, "destination_ids cannot be combined with a budget")
+			return false
+		}
+		if req.Budget.MaxEvents <= 0 && req.Budget.MaxBytes <= 0 {
+			respondError(c, models.CodeValidationFailed, "budget needs max_events or max_bytes")
+			return false
+		}
+		if req.DestinationID == "" {
+			respondError(c, models.CodeValidationFailed, "budget requires destination_id")
+			return false
+		}
+	} else if req.Count < 1 || req.Count > 10000 {
+		respondError(c, models.CodeValidationFailed, "count must be between 1 and 10000")
+		return false
+	}
+	return true
+}
+
+// estimateGenerate responds with what a generate request would send, after
+// the same checks a real request gets. Nothing is sent.
+func estimateGenerate(c *gin.Context, req *models.GenerateRequest, gen generators.Generator, templateID string) {
+	var dests []*models.Destination
+	for _, id := range requestDestinations(req) {
+		dest, ok := destinationStore.Get(id)
+		if !ok {
+			respondError(c, models.CodeDestinationNotFound, "Destination not found: "+id)
+			return
+		}
+		dests = append(dests, dest)
+	}
+
+	est := models.DryRunEstimate{DryRun: true, SampleSize: estimateSamples}
+	size, err := generators.MeasureTemplate(gen, templateID, req.Overrides, estimateSamples)
+	if err != nil {
+		est.Warnings = append(est.Warnings, err.Error())
+	}
+	est.AvgEventBytes = math.Round(size*10) / 10
+
+	// The count, or whichever budget limit is reached first
+	events := float64(req.Count)
+	reason := ""
+	if b := req.Budget; b != nil {
+		if b.MaxEvents > 0 && (events == 0 || float64(b.MaxEvents) < events) {
+			events, reason = float64(b.MaxEvents), models.BudgetReasonEvents
+		}
+		if b.MaxBytes > 0 && size > 0 {
+			if n := math.Floor(float64(b.MaxBytes) / size); events == 0 || n < events {
+				events, reason = n, models.BudgetReasonBytes
+			}
+		}
+	}
+	est.Events = int64(events)
+	est.Bytes = int64(math.Round(events * size))
+	if req.RatePerSecond > 0 {
+		est.EventsPerSecond = float64(req.RatePerSecond)
+		est.BytesPerSecond = math.Round(float64(req.RatePerSecond) * size)
+		est.BytesPerDay = int64(est.BytesPerSecond * 86400)
+		est.DurationSeconds = math.Round(events/float64(req.RatePerSecond)*10) / 10
+	}
+	est.Templates = []models.TemplateEstimate{{
+		EventType:     req.EventType,
+		TemplateID:    templateID,
+		DestinationID: req.DestinationID,
+		Share:         1,
+		Events:        est.Events,
+		Bytes:         est.Bytes,
+		AvgEventBytes: est.AvgEventBytes,
+	}}
+	for _, dest := range dests {
+		de := models.DestinationEstimate{
+			DestinationID:   dest.ID,
+			Name:            dest.Name,
+			Events:          est.Events,
+			Bytes:           est.Bytes,
+			EventsPerSecond: est.EventsPerSecond,
+			BytesPerSecond:  est.BytesPerSecond,
+			BudgetReason:    reason,
+		}
+		if reason != "" {
+			de.BudgetExhaustedAfter = est.DurationSeconds
+		}
+		est.Destinations = append(est.Destinations, de)
+	}
+	if len(dests) == 0 {
+		est.Warnings = append(est.Warnings, "no destination set; events would only be returned in the response")
+	}
+
+	c.JSON(http.StatusOK, est)
+}
+
 // PreviewEvent generates a single event for preview
 func PreviewEvent(c *gin.Context) {
 	var req models.PreviewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
 	gen, ok := generators.GetGenerator(req.EventType)
 	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Event type not found",
-		})
+		respondError(c, models.CodeEventTypeNotFound, "Event type not found")
 		return
 	}
 
-	templateID := req.EventID
-	if templateID == "" {
-		templates := gen.GetTemplates()
-		if len(templates) > 0 {
-			templateID = templates[0].ID
+	templateID, err := generators.ResolveTemplateID(gen, req.EventID)
+	if err != nil {
+		respondError(c, models.CodeTemplateNotFound, err.Error())
+		return
+	}
+
+	if !checkSchemaVersion(c, gen, templateID, req.SchemaVersion) {
+		return
+	}
+	if !applyPreset(c, req.EventType, templateID, req.Preset, &req.Overrides) {
+		return
+	}
+	if !applyCampaign(c, &req.Overrides) {
+		return
+	}
+	if !checkOverrides(c, gen, templateID, req.Overrides, req.StrictOverrides) {
+		return
+	}
+	if err := generators.ValidateOutput(req.Output); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	var renderer *generators.Renderer
+	if req.Render != nil {
+		if renderer, err = generators.NewRenderer(*req.Render); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
 		}
 	}
 
-	event, err := gen.Generate(templateID, req.Overrides)
+	// A renderer replaces the raw event with one built from the fields
+	output := req.Output
+	if renderer != nil {
+		output = models.OutputFields
+	}
+	event, err := generators.Unseeded.GenerateOutput(gen, templateID, req.Overrides, output)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		respondError(c, models.CodeInternal, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, event)
+	c.JSON(http.StatusOK, generators.ApplyOutput(renderer.Apply(event), req.Output))
+}
+
+// checkSchemaVersion responds with 409 when a request pins a schema version
+// the template is no longer at. It returns false if a response was written.
+func checkSchemaVersion(c *gin.Context, gen generators.Generator, templateID string, schemaVersion int) bool {
+	if _, err := generators.CheckTemplate(gen, templateID, schemaVersion); err != nil {
+		respondError(c, models.CodeSchemaVersionMismatch, err.Error())
+		return false
+	}
+	return true
+}
+
+// templateWarnings returns the warnings to pass on for a template, such as
+// its deprecation
+func templateWarnings(gen generators.Generator, templateID string) []string {
+	t, err := generators.CheckTemplate(gen, templateID, 0)
+	if err != nil {
+		return nil
+	}
+	if w := generators.DeprecationWarning(t); w != "" {
+		return []string{w}
+	}
+	return nil
+}
+
+// checkOverrides validates overrides for a template and responds with 400 and
+// per-field errors when they are invalid. It returns false if a response was written.
+func checkOverrides(c *gin.Context, gen generators.Generator, templateID string, overrides map[string]interface{}, strict bool) bool {
+	fieldErrors, err := generators.ValidateOverrides(gen, templateID, overrides, strict)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return false
+	}
+	if len(fieldErrors) > 0 {
+		status, resp := apiError(models.CodeInvalidOverrides, "Invalid overrides")
+		resp.FieldErrors = fieldErrors
+		c.JSON(status, resp)
+		return false
+	}
+	return true
+}
+
+// PreviewEventDiff generates a preview event and reports which fields the
+// supplied overrides change compared to the template's default output
+func PreviewEventDiff(c *gin.Context) {
+	var req models.PreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	gen, ok := generators.GetGenerator(req.EventType)
+	if !ok {
+		respondError(c, models.CodeEventTypeNotFound, "Event type not found")
+		return
+	}
+
+	templateID, err := generators.ResolveTemplateID(gen, req.EventID)
+	if err != nil {
+		respondError(c, models.CodeTemplateNotFound, err.Error())
+		return
+	}
+
+	if !checkSchemaVersion(c, gen, templateID, req.SchemaVersion) {
+		return
+	}
+	if !applyPreset(c, req.EventType, templateID, req.Preset, &req.Overrides) {
+		return
+	}
+	if !applyCampaign(c, &req.Overrides) {
+		return
+	}
+	if !checkOverrides(c, gen, templateID, req.Overrides, req.StrictOverrides) {
+		return
+	}
+
+	// Both events draw the same random values, so the default holds what
+	// the overridden fields would otherwise have been
+	seed := time.Now().UnixNano()
+	defaultEvent, err := generators.NewRandomStream(seed).Generate(gen, templateID, nil)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+
+	event := defaultEvent
+	if len(req.Overrides) > 0 {
+		event, err = generators.NewRandomStream(seed).Generate(gen, templateID, req.Overrides)
+		if err != nil {
+			respondError(c, models.CodeInternal, err.Error())
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.PreviewDiffResponse{
+		Event:   event,
+		Default: defaultEvent,
+		Diff:    generators.DiffOverrides(defaultEvent.Fields, event.Fields, req.Overrides),
+	})
 }
 
 // GetEventSources returns all event types with their templates in a hierarchical structure
@@ -185,7 +682,7 @@ func GetEventSources(c *gin.Context) {
 		Categories: make(map[string][]models.EventSourceInfo),
 	}
 
-	for _, gen := range generators.Registry {
+	for _, gen := range generators.Generators() {
 		eventType := gen.GetEventType()
 		templates := gen.GetTemplates()
 
@@ -202,3 +699,29 @@ func GetEventSources(c *gin.Context) {
 
 	c.JSON(http.StatusOK, tree)
 }
+
+// GetSamples returns ?count= example events (default 3) from every template,
+// optionally of one ?category=, so parser developers can fetch a whole
+// corpus in one call. ?output=raw or fields drops the other form.
+func GetSamples(c *gin.Context) {
+	req := models.SampleRequest{
+		Category: c.Query("category"),
+		Output:   c.Query("output"),
+	}
+	if s := c.Query("count"); s != "" {
+		count, err := strconv.Atoi(s)
+		if err != nil {
+			respondError(c, models.CodeValidationFailed, "count must be a number")
+			return
+		}
+		req.Count = count
+	}
+
+	set, err := generators.Samples(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
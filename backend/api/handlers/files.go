@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// ListFiles returns the synthetic file catalog, narrowed by ?malicious=.
+// With ?format=csv it returns the files' hashes as type,value,tags rows
+// that POST /iocs/upload accepts, so indicators match the generated hashes.
+func ListFiles(c *gin.Context) {
+	var malicious *bool
+	if v := c.Query("malicious"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(c, models.CodeValidationFailed, "malicious must be true or false")
+			return
+		}
+		malicious = &b
+	}
+	files, page, ok := paginate(c, generators.Files.List(malicious), 0, "malicious", "format")
+	if !ok {
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, page.into(gin.H{
+			"files": files,
+			"count": len(files),
+		}))
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"type", "value", "tags"})
+		for _, f := range files {
+			tags := append([]string{}, f.Tags...)
+			if f.Family != "" {
+				tags = append(tags, strings.ToLower(f.Family))
+			}
+			for _, h := range []struct {
+				t     models.IOCType
+				value string
+			}{{models.IOCTypeMD5, f.MD5}, {models.IOCTypeSHA1, f.SHA1}, {models.IOCTypeSHA256, f.SHA256}} {
+				w.Write([]string{string(h.t), h.value, strings.Join(tags, ";")})
+			}
+		}
+		w.Flush()
+		c.Header("Content-Disposition", `attachment; filename="file-hashes.csv"`)
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	default:
+		respondError(c, models.CodeValidationFailed, "format must be json or csv")
+	}
+}
+
+// GetFile returns the catalog file with a name, or the hashes generators
+// report for any other file of that name
+func GetFile(c *gin.Context) {
+	f := generators.Files.ForName(c.Param("name"))
+	_, cataloged := generators.Files.Lookup(f.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"file":      f,
+		"cataloged": cataloged,
+	})
+}
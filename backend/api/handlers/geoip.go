@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetGeoIP returns the geo policy and the countries external IPs can be drawn from
+func GetGeoIP(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"policy":    generators.Geo.Policy(),
+		"countries": generators.Geo.Countries(),
+	})
+}
+
+// UpdateGeoPolicy replaces the benign and malicious country lists
+func UpdateGeoPolicy(c *gin.Context) {
+	var policy generators.GeoPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.Geo.SetPolicy(policy); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveGeoPolicy()
+
+	c.JSON(http.StatusOK, generators.Geo.Policy())
+}
+
+// LookupGeoIP resolves an IP produced by the generators to its location
+func LookupGeoIP(c *gin.Context) {
+	loc, ok := generators.Geo.Lookup(c.Param("ip"))
+	if !ok {
+		respondError(c, models.CodeNotFound, "IP is not in the GeoIP table")
+		return
+	}
+
+	c.JSON(http.StatusOK, loc)
+}
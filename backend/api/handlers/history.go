@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/models"
+	"siem-event-generator/secrets"
+	"siem-event-generator/storage"
+)
+
+// statsRetention is how long noise statistics samples are kept
+const statsRetention = 30 * 24 * time.Hour
+
+// statsKindNoise is the kind of the samples taken of noise runs
+const statsKindNoise = "noise"
+
+// historyCollections are the collections GetHistory serves
+var historyCollections = map[string]bool{
+	storage.CollectionDestinations:  true,
+	storage.CollectionTemplates:     true,
+	storage.CollectionPresets:       true,
+	storage.CollectionCampaigns:     true,
+	storage.CollectionScenarios:     true,
+	storage.CollectionIndicators:    true,
+	storage.CollectionIOCFeeds:      true,
+	storage.CollectionHooks:         true,
+	storage.CollectionNotifications: true,
+	storage.CollectionFavorites:     true,
+	storage.CollectionSettings:      true,
+}
+
+// GetHistory returns earlier versions of a collection's documents, newest
+// first, from the latest 1000; ?id= narrows it to one document, and pages
+// hold 100 unless ?limit= says otherwise. Credentials are masked.
+func GetHistory(c *gin.Context) {
+	collection := c.Param("collection")
+	if !historyCollections[collection] {
+		respondError(c, models.CodeNotFound, "Unknown collection")
+		return
+	}
+	if store == nil {
+		respondError(c, models.CodeStorageUnavailable, "Storage is not configured")
+		return
+	}
+
+	revisions, err := store.History(c.Request.Context(), collection, c.Query("id"), maxPageSize)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	revisions, page, ok := paginate(c, revisions, 100, "id")
+	if !ok {
+		return
+	}
+	for i := range revisions {
+		revisions[i].Data = redactRevision(collection, revisions[i].ID, revisions[i].Data)
+	}
+
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"collection": collection,
+		"revisions":  revisions,
+		"count":      len(revisions),
+	}))
+}
+
+// redactRevision masks the credentials of a stored destination, feed,
+// notification channel or detection backend
+func redactRevision(collection, id string, data json.RawMessage) json.RawMessage {
+	if len(data) == 0 {
+		return data
+	}
+	var redacted interface{}
+	switch collection {
+	case storage.CollectionDestinations:
+		var d models.Destination
+		if json.Unmarshal(data, &d) != nil {
+			return nil
+		}
+		d.Config = secrets.RedactConfig(d.Config)
+		redacted = d
+	case storage.CollectionIOCFeeds:
+		var feed models.IOCFeed
+		if json.Unmarshal(data, &feed) != nil {
+			return nil
+		}
+		if feed.Password != "" {
+			feed.Password = secrets.Mask
+		}
+		redacted = feed
+	case storage.CollectionNotifications:
+		var ch models.NotificationChannel
+		if json.Unmarshal(data, &ch) != nil {
+			return nil
+		}
+		redacted = maskChannel(ch)
+	case storage.CollectionSettings:
+		if id != detectionBackendsSetting {
+			return data
+		}
+		var cfg models.DetectionBackends
+		if json.Unmarshal(data, &cfg) != nil {
+			return nil
+		}
+		redacted = maskBackends(cfg)
+	default:
+		return data
+	}
+	out, _ := json.Marshal(redacted)
+	return out
+}
+
+// GetNoiseHistory returns the statistics recorded for noise runs, newest
+// first. Samples are taken every minute while a run is active and when it
+// stops; ?since= (RFC 3339) narrows the latest 1000 and pages hold 100
+// unless ?limit= says otherwise.
+func GetNoiseHistory(c *gin.Context) {
+	if store == nil {
+		respondError(c, models.CodeStorageUnavailable, "Storage is not configured")
+		return
+	}
+	since := time.Now().Add(-statsRetention)
+	if s := c.Query("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			respondError(c, models.CodeValidationFailed, "since must be an RFC 3339 time")
+			return
+		}
+		since = t
+	}
+
+	samples, err := store.Stats(c.Request.Context(), statsKindNoise, since, maxPageSize)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	samples, page, ok := paginate(c, samples, 100, "since")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"samples": samples,
+		"count":   len(samples),
+	}))
+}
+
+// noiseRecorder samples the noise generator's statistics into the store
+type noiseRecorder struct {
+	mu         sync.Mutex
+	runKey     string            // start time of the run being sampled
+	run        *models.RunRecord // The run being sampled, added to the run history when it stops
+	replayOf   string            // Run the next run to start replays
+	lastSample time.Time
+	lastPrune  time.Time
+}
+
+var noiseStats = &noiseRecorder{}
+
+// StartStatsRecorder records noise run statistics in the background and
+// checkpoints running streams for ResumeStreams
+func StartStatsRecorder() {
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			noiseStats.check()
+			saveStreamState()
+		}
+	}()
+}
+
+// check takes a sample each minute while a run is active and a final one
+// when it stops, and notifies the notification channels of both
+func (r *noiseRecorder) check() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := noiseStatus()
+	now := time.Now()
+	switch {
+	case status.Running && status.StartedAt != nil:
+		key := status.StartedAt.UTC().Format(time.RFC3339Nano)
+		if key == r.runKey && now.Sub(r.lastSample) < time.Minute {
+			return
+		}
+		if key != r.runKey {
+			r.run = newNoiseRun(status, r.replayOf)
+			r.replayOf = ""
+			notifyStreamStarted(r.run)
+		}
+		r.runKey = key
+		r.record(status, now)
+	case r.runKey != "":
+		r.record(status, now)
+		if r.run != nil {
+			finishNoiseRun(r.run, status, now)
+			recordRun(r.run)
+			notifyStreamFinished(r.run)
+			if r.run.Noise.Reconcile {
+				go reconcileLater(r.run)
+			}
+		}
+		r.run = nil
+		r.runKey = ""
+	}
+
+	if store != nil && now.Sub(r.lastPrune) >= time.Hour {
+		r.lastPrune = now
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		if err := store.PruneStats(ctx, now.Add(-statsRetention)); err != nil {
+			log.Printf("WARNING: failed to prune statistics: %v", err)
+		}
+	}
+}
+
+// started samples a noise run that just started, which replays the run
+// replayOf if set
+func (r *noiseRecorder) started(replayOf string) {
+	r.mu.Lock()
+	r.replayOf = replayOf
+	r.mu.Unlock()
+	r.check()
+}
+
+func (r *noiseRecorder) record(status models.NoiseStatus, now time.Time) {
+	r.lastSample = now
+	if store == nil {
+		return
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	err = store.RecordStats(ctx, storage.Sample{Kind: statsKindNoise, Key: r.runKey, RecordedAt: now, Data: data})
+	if err != nil {
+		log.Printf("WARNING: failed to record noise statistics: %v", err)
+	}
+}
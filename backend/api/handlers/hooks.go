@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"siem-event-generator/delivery"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// ListHooks returns the event hook scripts in the order they run, and the
+// names of the Go hooks that run before them
+func ListHooks(c *gin.Context) {
+	hooks, page, ok := paginate(c, delivery.Hooks.List(), 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"hooks":    hooks,
+		"count":    len(hooks),
+		"go_hooks": delivery.Hooks.GoHooks(),
+	}))
+}
+
+// GetHook returns a specific event hook
+func GetHook(c *gin.Context) {
+	hook, ok := delivery.Hooks.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeNotFound, "Hook not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// CreateHook adds an event hook
+func CreateHook(c *gin.Context) {
+	var hook models.EventHook
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	hook.ID = uuid.New().String()
+	hook.CreatedAt = time.Now()
+	hook.UpdatedAt = time.Now()
+	if !setHook(c, hook) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// UpdateHook replaces an event hook
+func UpdateHook(c *gin.Context) {
+	id := c.Param("id")
+
+	existing, ok := delivery.Hooks.Get(id)
+	if !ok {
+		respondError(c, models.CodeNotFound, "Hook not found")
+		return
+	}
+
+	var hook models.EventHook
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	hook.ID = id
+	hook.CreatedAt = existing.CreatedAt
+	hook.UpdatedAt = time.Now()
+	if !setHook(c, hook) {
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// setHook checks a hook's event type and script, stores it and saves the
+// hooks, answering the request itself when the hook is rejected
+func setHook(c *gin.Context, hook models.EventHook) bool {
+	err := checkHookEventType(hook.EventType)
+	if err == nil {
+		err = delivery.CheckHookScript(hook)
+	}
+	if err == nil {
+		err = delivery.Hooks.Set(hook)
+	}
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return false
+	}
+	SaveHooks()
+	return true
+}
+
+// checkHookEventType checks that a hook's event type exists
+func checkHookEventType(eventType string) error {
+	if eventType == "" {
+		return nil
+	}
+	if _, ok := generators.GetGenerator(eventType); !ok {
+		return fmt.Errorf("unknown event type %q", eventType)
+	}
+	return nil
+}
+
+// DeleteHook removes an event hook
+func DeleteHook(c *gin.Context) {
+	if !delivery.Hooks.Delete(c.Param("id")) {
+		respondError(c, models.CodeNotFound, "Hook not found")
+		return
+	}
+	SaveHooks()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hook deleted",
+	})
+}
+
+// TestHook generates one event and runs a script on it without saving the
+// script, showing the event before and after
+func TestHook(c *gin.Context) {
+	var req models.HookTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	gen, ok := generators.GetGenerator(req.EventType)
+	if !ok {
+		respondError(c, models.CodeEventTypeNotFound, "Event type not found")
+		return
+	}
+
+	templateID, err := generators.ResolveTemplateID(gen, req.EventID)
+	if err != nil {
+		respondError(c, models.CodeTemplateNotFound, err.Error())
+		return
+	}
+
+	if !checkOverrides(c, gen, templateID, req.Overrides, req.StrictOverrides) {
+		return
+	}
+
+	event, err := generators.Unseeded.Generate(gen, templateID, req.Overrides)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+
+	transformed, err := delivery.RunHookScript(req.Script, event)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HookTestResult{
+		Original:    event,
+		Transformed: transformed,
+	})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/models"
+)
+
+// Idempotency keys are kept for a day, and at most this many at once
+const (
+	idempotencyTTL       = 24 * time.Hour
+	maxIdempotencyKeys   = 10000
+	maxIdempotencyKeyLen = 255
+)
+
+// IdempotencyKeyHeader names a request so retrying it does not run it again
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on a response replayed for a retry
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// idempotentRequest is a request seen with an idempotency key, and its
+// response once it has one
+type idempotentRequest struct {
+	fingerprint [sha256.Size]byte // Of the request body
+	done        bool
+	status      int
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+var idempotency = struct {
+	sync.Mutex
+	requests  map[string]*idempotentRequest
+	lastSweep time.Time
+}{requests: make(map[string]*idempotentRequest)}
+
+// recordingWriter keeps a copy of the response it writes
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotent runs a request with an Idempotency-Key header once. A retry
+// with the same key and body gets the first response again, without
+// starting another stream or sending another batch; the same key with a
+// different body, or while the first request is still running, is
+// refused. Only successful responses are kept, so a failed request can be
+// retried with its key.
+func Idempotent(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		status, resp := apiError(models.CodeValidationFailed, "Idempotency-Key must be at most 255 characters")
+		resp.Field = IdempotencyKeyHeader
+		c.AbortWithStatusJSON(status, resp)
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(apiError(models.CodeInvalidRequest, err.Error()))
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	fingerprint := sha256.Sum256(body)
+	user, _ := requestUser(c)
+	scope := user + "\x00" + c.Request.Method + " " + c.Request.URL.Path + "\x00" + key
+
+	idempotency.Lock()
+	sweepIdempotencyKeys()
+	if seen, ok := idempotency.requests[scope]; ok && !(seen.done && time.Now().After(seen.expires)) {
+		idempotency.Unlock()
+		switch {
+		case seen.fingerprint != fingerprint:
+			c.AbortWithStatusJSON(apiError(models.CodeIdempotencyKeyReused, "Idempotency-Key was used for a different request"))
+		case !seen.done:
+			c.AbortWithStatusJSON(apiError(models.CodeIdempotencyKeyInUse, "A request with this Idempotency-Key is still running"))
+		default:
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(seen.status, seen.contentType, seen.body)
+			c.Abort()
+		}
+		return
+	}
+	if len(idempotency.requests) >= maxIdempotencyKeys {
+		idempotency.Unlock()
+		c.AbortWithStatusJSON(apiError(models.CodeServerLimitReached, "too many idempotency keys in use; retry without one or later"))
+		return
+	}
+	request := &idempotentRequest{fingerprint: fingerprint, expires: time.Now().Add(idempotencyTTL)}
+	idempotency.requests[scope] = request
+	idempotency.Unlock()
+
+	w := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	completed := false
+	defer func() {
+		idempotency.Lock()
+		defer idempotency.Unlock()
+		status := w.Status()
+		if !completed || status < http.StatusOK || status >= http.StatusMultipleChoices {
+			delete(idempotency.requests, scope)
+			return
+		}
+		request.done = true
+		request.status = status
+		request.contentType = w.Header().Get("Content-Type")
+		request.body = w.body.Bytes()
+	}()
+	c.Next()
+	completed = true
+}
+
+// sweepIdempotencyKeys forgets expired keys, at most once a minute;
+// idempotency must be locked
+func sweepIdempotencyKeys() {
+	now := time.Now()
+	if now.Sub(idempotency.lastSweep) < time.Minute {
+		return
+	}
+	idempotency.lastSweep = now
+	for scope, request := range idempotency.requests {
+		if request.done && now.After(request.expires) {
+			delete(idempotency.requests, scope)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// maxIOCUpload caps uploaded and fetched indicator files
+const maxIOCUpload = 64 << 20
+
+// IOCFeedStore provides thread-safe storage for indicator feed subscriptions
+type IOCFeedStore struct {
+	mu    sync.RWMutex
+	feeds map[string]*models.IOCFeed
+}
+
+// NewIOCFeedStore creates a new feed store
+func NewIOCFeedStore() *IOCFeedStore {
+	return &IOCFeedStore{
+		feeds: make(map[string]*models.IOCFeed),
+	}
+}
+
+// Get retrieves a copy of a feed by ID
+func (s *IOCFeedStore) Get(id string) (models.IOCFeed, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feed, ok := s.feeds[id]
+	if !ok {
+		return models.IOCFeed{}, false
+	}
+	return *feed, true
+}
+
+// List returns copies of all feeds ordered by name
+func (s *IOCFeedStore) List() []models.IOCFeed {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feeds := make([]models.IOCFeed, 0, len(s.feeds))
+	for _, f := range s.feeds {
+		feeds = append(feeds, *f)
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].Name < feeds[j].Name })
+	return feeds
+}
+
+// Create adds a new feed
+func (s *IOCFeedStore) Create(feed *models.IOCFeed) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feeds[feed.ID] = feed
+}
+
+// Delete removes a feed
+func (s *IOCFeedStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.feeds[id]; !ok {
+		return false
+	}
+	delete(s.feeds, id)
+	return true
+}
+
+// recordPoll stores the outcome of a poll
+func (s *IOCFeedStore) recordPoll(id string, at time.Time, count int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	feed, ok := s.feeds[id]
+	if !ok {
+		return
+	}
+	feed.LastPolledAt = &at
+	feed.LastError = ""
+	if err != nil {
+		feed.LastError = err.Error()
+		return
+	}
+	feed.Count = count
+}
+
+// Global feed store
+var iocFeedStore = NewIOCFeedStore()
+
+// feedSource is the pool source name for a feed's indicators
+func feedSource(id string) string {
+	return "feed:" + id
+}
+
+var iocFeedClient = &http.Client{Timeout: 60 * time.Second}
+
+// fetchIOCFeed downloads a feed. TAXII 2.1 collections are read from their
+// objects endpoint, following the next cursor until the server reports no more.
+func fetchIOCFeed(feed models.IOCFeed) ([]models.IOC, error) {
+	endpoint := feed.URL
+	accept := "*/*"
+	if feed.Format == models.IOCFormatTAXII {
+		endpoint = strings.TrimSuffix(feed.URL, "/") + "/objects/"
+		accept = "application/taxii+json;version=2.1"
+	}
+
+	var iocs []models.IOC
+	next := ""
+	for page := 0; page < 100; page++ {
+		reqURL := endpoint
+		if next != "" {
+			reqURL += "?next=" + url.QueryEscape(next)
+		}
+		req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", accept)
+		if feed.Username != "" {
+			req.SetBasicAuth(feed.Username, feed.Password)
+		}
+
+		resp, err := iocFeedClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxIOCUpload))
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("feed returned HTTP %d", resp.StatusCode)
+		}
+
+		parsed, err := generators.ParseIOCs(body, feed.Format)
+		if err != nil {
+			return nil, err
+		}
+		iocs = append(iocs, parsed...)
+
+		if feed.Format != models.IOCFormatTAXII {
+			break
+		}
+		var envelope struct {
+			More bool   `json:"more"`
+			Next string `json:"next"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil || !envelope.More || envelope.Next == "" {
+			break
+		}
+		next = envelope.Next
+	}
+	return iocs, nil
+}
+
+// pollIOCFeed refreshes a feed's indicators in the pool. On failure the
+// indicators from the previous poll are kept.
+func pollIOCFeed(feed models.IOCFeed) error {
+	iocs, err := fetchIOCFeed(feed)
+	if err == nil {
+		generators.IOCs.Replace(feedSource(feed.ID), iocs)
+	}
+	iocFeedStore.recordPoll(feed.ID, time.Now(), len(iocs), err)
+	return err
+}
+
+// StartIOCFeedPoller polls every feed at startup and then whenever its
+// interval has elapsed
+func StartIOCFeedPoller() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			polled := false
+			for _, feed := range iocFeedStore.List() {
+				due := feed.LastPolledAt == nil || time.Since(*feed.LastPolledAt) >= time.Duration(feed.IntervalMinutes)*time.Minute
+				if !due {
+					continue
+				}
+				if err := pollIOCFeed(feed); err != nil {
+					log.Printf("WARNING: failed to poll IOC feed %s: %v", feed.Name, err)
+				}
+				polled = true
+			}
+			if polled {
+				SaveIOCs()
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// ListIOCs returns indicators, optionally filtered by type and source, with
+// pool statistics
+func ListIOCs(c *gin.Context) {
+	iocs := generators.IOCs.List(models.IOCType(c.Query("type")), c.Query("source"))
+	iocs, page, ok := paginate(c, iocs, 0, "type", "source")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"indicators": iocs,
+		"count":      len(iocs),
+		"counts":     generators.IOCs.Counts(),
+		"injected":   generators.IOCs.Injected(),
+		"config":     generators.IOCs.Config(),
+	}))
+}
+
+// AddIOCs adds indicators supplied as JSON
+func AddIOCs(c *gin.Context) {
+	var req models.AddIOCsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.ValidateIOCs(req.Indicators); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	source := req.Source
+	if source == "" {
+		source = "manual"
+	}
+	for i := range req.Indicators {
+		req.Indicators[i].Source = source
+	}
+	added := generators.IOCs.Add(req.Indicators)
+	SaveIOCs()
+
+	c.JSON(http.StatusOK, gin.H{
+		"added":  added,
+		"source": source,
+		"counts": generators.IOCs.Counts(),
+	})
+}
+
+// UploadIOCs imports a CSV or STIX 2.1 file, sent either as the multipart
+// field "file" or as the raw request body. ?format= forces csv or stix and
+// ?source= names the list (default: the file name, or "upload").
+func UploadIOCs(c *gin.Context) {
+	source := c.Query("source")
+	body := io.Reader(c.Request.Body)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			respondError(c, models.CodeValidationFailed, "multipart upload must include a \"file\" field")
+			return
+		}
+		defer file.Close()
+		body = file
+		if source == "" {
+			source = header.Filename
+		}
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxIOCUpload))
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	if source == "" {
+		source = "upload"
+	}
+
+	iocs, err := generators.ParseIOCs(data, c.Query("format"))
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	for i := range iocs {
+		iocs[i].Source = source
+	}
+	added := generators.IOCs.Add(iocs)
+	SaveIOCs()
+
+	c.JSON(http.StatusOK, gin.H{
+		"parsed": len(iocs),
+		"added":  added,
+		"source": source,
+		"counts": generators.IOCs.Counts(),
+	})
+}
+
+// DeleteIOCs removes the indicators from ?source=, or every indicator
+func DeleteIOCs(c *gin.Context) {
+	removed := generators.IOCs.Remove(c.Query("source"))
+	SaveIOCs()
+
+	c.JSON(http.StatusOK, gin.H{
+		"removed": removed,
+	})
+}
+
+// GetIOCConfig returns the injection rate
+func GetIOCConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, generators.IOCs.Config())
+}
+
+// UpdateIOCConfig sets the injection rate
+func UpdateIOCConfig(c *gin.Context) {
+	var cfg models.IOCConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.IOCs.SetConfig(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveIOCs()
+
+	c.JSON(http.StatusOK, cfg)
+}
+
+// ListIOCFeeds returns the feed subscriptions, paged as paginate describes
+func ListIOCFeeds(c *gin.Context) {
+	feeds, page, ok := paginate(c, iocFeedStore.List(), 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"feeds": feeds,
+		"count": len(feeds),
+	}))
+}
+
+// normalizeIOCFeed checks a feed's format and URL and fills in defaults
+func normalizeIOCFeed(feed *models.IOCFeed) error {
+	switch feed.Format {
+	case "":
+		feed.Format = models.IOCFormatCSV
+	case models.IOCFormatCSV, models.IOCFormatSTIX, models.IOCFormatTAXII:
+	default:
+		return fmt.Errorf("format must be csv, stix or taxii")
+	}
+	if u, err := url.Parse(feed.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+	if feed.IntervalMinutes <= 0 {
+		feed.IntervalMinutes = 60
+	}
+	return nil
+}
+
+// CreateIOCFeed subscribes to a feed and polls it immediately
+func CreateIOCFeed(c *gin.Context) {
+	var feed models.IOCFeed
+	if err := c.ShouldBindJSON(&feed); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := normalizeIOCFeed(&feed); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	feed.ID = uuid.New().String()
+	feed.CreatedAt = time.Now()
+	feed.LastPolledAt = nil
+	feed.LastError = ""
+	feed.Count = 0
+	iocFeedStore.Create(&feed)
+
+	pollErr := pollIOCFeed(feed)
+	SaveIOCs()
+
+	polled, _ := iocFeedStore.Get(feed.ID)
+	resp := gin.H{"feed": polled}
+	if pollErr != nil {
+		resp["warning"] = "Feed saved but the first poll failed: " + pollErr.Error()
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// DeleteIOCFeed unsubscribes from a feed and removes its indicators
+func DeleteIOCFeed(c *gin.Context) {
+	id := c.Param("id")
+
+	if !iocFeedStore.Delete(id) {
+		respondError(c, models.CodeNotFound, "Feed not found")
+		return
+	}
+	generators.IOCs.Remove(feedSource(id))
+	SaveIOCs()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Feed deleted",
+	})
+}
+
+// PollIOCFeed refreshes a feed now
+func PollIOCFeed(c *gin.Context) {
+	feed, ok := iocFeedStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeNotFound, "Feed not found")
+		return
+	}
+
+	if err := pollIOCFeed(feed); err != nil {
+		respondError(c, models.CodeFeedUnreachable, err.Error())
+		return
+	}
+	SaveIOCs()
+
+	polled, _ := iocFeedStore.Get(feed.ID)
+	c.JSON(http.StatusOK, polled)
+}
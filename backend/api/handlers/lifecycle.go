@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/delivery"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GenerateLifecycle simulates an employee's identity lifecycle across AD,
+// Windows, Okta and CloudTrail: onboarding, normal work, then offboarding or
+// going rogue. Events are sent to the destination when one is given,
+// otherwise they are returned in the response.
+func GenerateLifecycle(c *gin.Context) {
+	var req models.LifecycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+	if !applyCampaignFields(c, &req.Username, &req.FullName, &req.Department) {
+		return
+	}
+
+	lc, err := generators.ResolveLifecycle(&req)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	var dest *models.Destination
+	if req.DestinationID != "" {
+		d, ok := destinationStore.Get(req.DestinationID)
+		if !ok {
+			respondError(c, models.CodeDestinationNotFound, "Destination not found")
+			return
+		}
+		dest = d
+	}
+
+	events, err := generators.GenerateLifecycle(lc)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+
+	resp := models.LifecycleResponse{
+		Username:      lc.User.Username,
+		Email:         lc.User.Email,
+		Department:    lc.User.Department,
+		Workstation:   lc.Workstation.Hostname,
+		Outcome:       lc.Outcome,
+		Start:         lc.Start,
+		End:           lc.End,
+		EventsCreated: len(events),
+		Counts:        make(map[string]int),
+	}
+	for _, e := range events {
+		resp.Counts[e.Type]++
+	}
+
+	if dest == nil {
+		resp.Events = make([]models.GeneratedEvent, 0, len(events))
+		for _, e := range events {
+			resp.Events = append(resp.Events, *e)
+		}
+	} else {
+		resp.Destination = dest.Name
+		sender, err := delivery.GetSender(dest)
+		if err != nil {
+			resp.Errors = append(resp.Errors, "Failed to create sender: "+err.Error())
+		} else {
+			for _, e := range events {
+				if err := sender.Send(e); err != nil {
+					resp.Errors = append(resp.Errors, "Send error: "+err.Error())
+				} else {
+					resp.EventsSent++
+				}
+			}
+			if err := sender.Close(); err != nil {
+				resp.Errors = append(resp.Errors, "Close error: "+err.Error())
+			}
+		}
+	}
+
+	resp.Success = len(resp.Errors) == 0
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/models"
+	"siem-event-generator/noise"
+)
+
+var limits = struct {
+	sync.RWMutex
+	models.ServerLimits
+}{}
+
+// serverLimits returns the server's guardrails
+func serverLimits() models.ServerLimits {
+	limits.RLock()
+	defer limits.RUnlock()
+	return limits.ServerLimits
+}
+
+// setServerLimits validates and sets the server's guardrails. Streams
+// already running are left alone.
+func setServerLimits(cfg models.ServerLimits) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	limits.Lock()
+	limits.ServerLimits = cfg
+	limits.Unlock()
+	noise.SetResourceLimits(cfg.MaxCPUPercent, cfg.MaxMemoryPercent)
+	return nil
+}
+
+// GetServerLimits returns the server's guardrails
+func GetServerLimits(c *gin.Context) {
+	c.JSON(http.StatusOK, serverLimits())
+}
+
+// UpdateServerLimits sets the server's guardrails
+func UpdateServerLimits(c *gin.Context) {
+	var cfg models.ServerLimits
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := setServerLimits(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveServerLimits()
+
+	c.JSON(http.StatusOK, cfg)
+}
+
+// rateSlack absorbs rounding when rates split by weight are added back up
+const rateSlack = 1e-6
+
+// roundRate rounds a rate for an error message
+func roundRate(rate float64) float64 {
+	return math.Round(math.Max(rate, 0)*100) / 100
+}
+
+// batchStream is a generate request in flight, the events per second it
+// generates and the destinations that receive every one; rate is 0 when the
+// request is not paced
+type batchStream struct {
+	rate         float64
+	destinations []string
+}
+
+// governor tracks the generate requests in flight, so new streams can be
+// held to the server limits
+var governor struct {
+	mu      sync.Mutex
+	batches map[*batchStream]bool
+}
+
+// streamLoad is what the running streams send
+type streamLoad struct {
+	streams      int
+	rate         float64
+	destinations map[string]float64
+}
+
+// currentLoad adds up the generate requests in flight and, unless
+// withoutNoise, the noise run; governor.mu must be held
+func currentLoad(withoutNoise bool) streamLoad {
+	load := streamLoad{destinations: make(map[string]float64)}
+	for b := range governor.batches {
+		load.streams++
+		load.rate += b.rate
+		for _, id := range b.destinations {
+			load.destinations[id] += b.rate
+		}
+	}
+	if withoutNoise {
+		return load
+	}
+	if status := noiseStatus(); status.Running && status.CurrentConfig != nil {
+		load.streams++
+		load.rate += status.CurrentConfig.RatePerSecond
+		for id, rate := range noise.DestinationRates(status.CurrentConfig) {
+			load.destinations[id] += rate
+		}
+	}
+	return load
+}
+
+// checkNoiseLimits checks a noise run with config, in place of any running
+// one, keeps to the server limits
+func checkNoiseLimits(config *models.NoiseConfig) error {
+	lim := serverLimits()
+	if lim == (models.ServerLimits{}) {
+		return nil
+	}
+	governor.mu.Lock()
+	defer governor.mu.Unlock()
+
+	load := currentLoad(true)
+	if lim.MaxStreams > 0 && load.streams+1 > lim.MaxStreams {
+		return fmt.Errorf("server limit reached: %d of %d streams running (max_streams)", load.streams, lim.MaxStreams)
+	}
+	if lim.MaxEventsPerSecond > 0 && load.rate+config.RatePerSecond > lim.MaxEventsPerSecond+rateSlack {
+		return fmt.Errorf("server limit reached: rate_per_second %g exceeds the %g events per second left under max_events_per_second",
+			config.RatePerSecond, roundRate(lim.MaxEventsPerSecond-load.rate))
+	}
+	if lim.MaxDestinationEventsPerSecond > 0 {
+		for id, rate := range noise.DestinationRates(config) {
+			if left := lim.MaxDestinationEventsPerSecond - load.destinations[id]; rate > left+rateSlack {
+				return fmt.Errorf("server limit reached: destination %s would receive %g events per second, %g are left under max_destination_events_per_second",
+					id, roundRate(rate), roundRate(left))
+			}
+		}
+	}
+	return nil
+}
+
+// admitBatch holds a generate request to the server limits and registers it
+// as a stream until release is called. With an events per second limit the
+// request is paced: at rate if it asks for one and it fits, otherwise at
+// what is left under the limits. paced is 0 when no rate limit applies.
+func admitBatch(destinationIDs []string, rate float64) (paced float64, release func(), err error) {
+	lim := serverLimits()
+	if lim == (models.ServerLimits{}) {
+		return 0, func() {}, nil
+	}
+	governor.mu.Lock()
+	defer governor.mu.Unlock()
+
+	load := currentLoad(false)
+	if lim.MaxStreams > 0 && load.streams+1 > lim.MaxStreams {
+		return 0, nil, fmt.Errorf("server limit reached: %d of %d streams running (max_streams)", load.streams, lim.MaxStreams)
+	}
+
+	// Events per second left under the limits, and the one that binds
+	left, limit := math.Inf(1), ""
+	if lim.MaxEventsPerSecond > 0 {
+		left, limit = lim.MaxEventsPerSecond-load.rate, "max_events_per_second"
+	}
+	if lim.MaxDestinationEventsPerSecond > 0 {
+		for _, id := range destinationIDs {
+			if l := lim.MaxDestinationEventsPerSecond - load.destinations[id]; l < left {
+				left, limit = l, "max_destination_events_per_second"
+			}
+		}
+	}
+	if !math.IsInf(left, 1) {
+		switch {
+		case left <= rateSlack:
+			return 0, nil, fmt.Errorf("server limit reached: no events per second left under %s", limit)
+		case rate > left+rateSlack:
+			return 0, nil, fmt.Errorf("server limit reached: rate_per_second %g exceeds the %g events per second left under %s", rate, roundRate(left), limit)
+		case rate > 0:
+			paced = rate
+		default:
+			paced = left
+		}
+	}
+
+	b := &batchStream{rate: paced, destinations: destinationIDs}
+	if governor.batches == nil {
+		governor.batches = make(map[*batchStream]bool)
+	}
+	governor.batches[b] = true
+	return paced, func() {
+		governor.mu.Lock()
+		delete(governor.batches, b)
+		governor.mu.Unlock()
+	}, nil
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+	"siem-event-generator/storage"
+)
+
+// migrateFiles copies the JSON files earlier versions kept in the config
+// directory into the store. It runs once; the files are left in place but
+// are no longer read.
+func migrateFiles(ctx context.Context) error {
+	if _, done, err := store.Meta(ctx, storage.MetaFilesMigrated); err != nil || done {
+		return err
+	}
+
+	var migrated []string
+	copyFile := func(name string, fn func(data []byte) error) error {
+		data, err := os.ReadFile(filepath.Join(configDir(), name))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		migrated = append(migrated, name)
+		return nil
+	}
+	saveDocs := func(collection string, items map[string]interface{}) error {
+		docs, err := encodeDocuments(items)
+		if err != nil {
+			return err
+		}
+		return store.Save(ctx, collection, docs)
+	}
+	putSetting := func(id string) func(data []byte) error {
+		return func(data []byte) error {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, data); err != nil {
+				return err
+			}
+			return store.Put(ctx, storage.CollectionSettings, id, compact.Bytes())
+		}
+	}
+
+	err := copyFile("destinations.json", func(data []byte) error {
+		var dests []*models.Destination
+		if err := json.Unmarshal(data, &dests); err != nil {
+			return err
+		}
+		items, err := destinationDocuments(dests)
+		if err != nil {
+			return err
+		}
+		return saveDocs(storage.CollectionDestinations, items)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = copyFile("templates.json", func(data []byte) error {
+		var tmpls []*models.EventTemplate
+		if err := json.Unmarshal(data, &tmpls); err != nil {
+			return err
+		}
+		items := make(map[string]interface{}, len(tmpls))
+		for _, t := range tmpls {
+			items[t.ID] = t
+		}
+		return saveDocs(storage.CollectionTemplates, items)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = copyFile("scenarios.json", func(data []byte) error {
+		var scenarios []*models.Scenario
+		if err := json.Unmarshal(data, &scenarios); err != nil {
+			return err
+		}
+		items := make(map[string]interface{}, len(scenarios))
+		for _, s := range scenarios {
+			items[s.ID] = s
+		}
+		return saveDocs(storage.CollectionScenarios, items)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = copyFile("iocs.json", func(data []byte) error {
+		var state iocState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return err
+		}
+		config, _ := json.Marshal(state.Config)
+		if err := store.Put(ctx, storage.CollectionSettings, "ioc_config", config); err != nil {
+			return err
+		}
+		iocItems, feedItems := iocDocuments(state.Indicators, state.Feeds)
+		if err := saveDocs(storage.CollectionIndicators, iocItems); err != nil {
+			return err
+		}
+		return saveDocs(storage.CollectionIOCFeeds, feedItems)
+	})
+	if err != nil {
+		return err
+	}
+
+	for file, id := range map[string]string{
+		"geoip.json":         "geo_policy",
+		"anonymization.json": "anonymization",
+		"performance.json":   "performance",
+	} {
+		if err := copyFile(file, putSetting(id)); err != nil {
+			return err
+		}
+	}
+
+	if err := store.SetMeta(ctx, storage.MetaFilesMigrated, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if len(migrated) > 0 {
+		log.Printf("Migrated %s from %s into the database", strings.Join(migrated, ", "), configDir())
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// maxNameSample is the most people GetNameSample makes up at once
+const maxNameSample = 100
+
+// GetNames returns the name profile and the locales names can come from
+func GetNames(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"profile": generators.Names.Profile(),
+		"locales": generators.Names.Locales(),
+	})
+}
+
+// UpdateNameProfile replaces the locales, email domain and username format
+// of made-up users
+func UpdateNameProfile(c *gin.Context) {
+	var profile generators.NameProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.Names.SetProfile(profile); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveNameProfile()
+
+	c.JSON(http.StatusOK, generators.Names.Profile())
+}
+
+// GetNameSample makes up ?count= people (10 by default) with the current
+// name profile
+func GetNameSample(c *gin.Context) {
+	count, err := strconv.Atoi(c.DefaultQuery("count", "10"))
+	if err != nil || count < 1 || count > maxNameSample {
+		respondError(c, models.CodeValidationFailed, "count must be between 1 and "+strconv.Itoa(maxNameSample))
+		return
+	}
+
+	people := make([]generators.Person, count)
+	for i := range people {
+		people[i] = generators.Names.Random(generators.Unseeded)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"people": people,
+		"count":  len(people),
+	})
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/gin-gonic/gin"
 
+	"siem-event-generator/generators"
 	"siem-event-generator/models"
 	"siem-event-generator/noise"
 )
@@ -13,19 +15,29 @@ import (
 func StartNoiseGeneration(c *gin.Context) {
 	var req models.NoiseStartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindError(c, err)
 		return
 	}
+	startNoise(c, &req, "")
+}
+
+// startNoise starts the noise run of req; replayOf names the run it
+// replays, if any
+func startNoise(c *gin.Context, req *models.NoiseStartRequest, replayOf string) {
 
 	// Validate rate
-	if req.RatePerSecond < 0.1 || req.RatePerSecond > 10000 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "rate_per_second must be between 0.1 and 10000"})
+	if req.RatePerSecond < 0.1 || req.RatePerSecond > 1000000 {
+		respondError(c, models.CodeValidationFailed, "rate_per_second must be between 0.1 and 1000000")
+		return
+	}
+	if req.Workers < 0 || req.Workers > 1024 {
+		respondError(c, models.CodeValidationFailed, "workers must be between 0 (one per CPU) and 1024")
 		return
 	}
 
 	// Validate enabled sources
 	if len(req.EnabledSources) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one enabled source is required"})
+		respondError(c, models.CodeValidationFailed, "at least one enabled source is required")
 		return
 	}
 
@@ -37,7 +49,66 @@ func StartNoiseGeneration(c *gin.Context) {
 		}
 	}
 	if !hasEnabled {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one source must be enabled"})
+		respondError(c, models.CodeValidationFailed, "at least one source must be enabled")
+		return
+	}
+
+	if req.Budget != nil && req.Budget.MaxEvents <= 0 && req.Budget.MaxBytes <= 0 {
+		respondError(c, models.CodeValidationFailed, "budget needs max_events or max_bytes")
+		return
+	}
+	if req.Completion != nil {
+		if err := req.Completion.Validate(); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	if req.Cardinality != nil {
+		if err := req.Cardinality.Validate(); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	if req.OutOfOrder != nil {
+		if err := req.OutOfOrder.Validate(); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	if req.TimestampFuzz != nil {
+		if _, err := generators.NewTimestampFuzzer(*req.TimestampFuzz); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	if req.ClockSkew != nil {
+		if _, err := generators.NewClockSkew(*req.ClockSkew); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	if req.Chaos != nil {
+		if _, err := generators.NewChaos(*req.Chaos); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	if req.Behavior != nil {
+		if _, err := generators.NewBehavior(*req.Behavior); err != nil {
+			respondError(c, models.CodeValidationFailed, err.Error())
+			return
+		}
+	}
+	for _, source := range req.EnabledSources {
+		if source.Render == nil {
+			continue
+		}
+		if _, err := generators.NewRenderer(*source.Render); err != nil {
+			respondError(c, models.CodeValidationFailed, source.EventTypeID+": "+err.Error())
+			return
+		}
+	}
+	if !resolveSourceOverrides(c, req.EnabledSources) {
 		return
 	}
 
@@ -58,16 +129,23 @@ func StartNoiseGeneration(c *gin.Context) {
 
 	// Ensure at least one destination is configured
 	if len(destinationIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one destination must be configured (global or per-source)"})
+		respondError(c, models.CodeValidationFailed, "at least one destination must be configured (global or per-source)")
 		return
 	}
 
+	// Mirrors receive every event but cannot be the only destination
+	for _, id := range req.Mirrors {
+		if id != "" {
+			destinationIDs[id] = true
+		}
+	}
+
 	// Fetch all required destinations
 	destinations := make(map[string]*models.Destination)
 	for destID := range destinationIDs {
 		dest, exists := destinationStore.Get(destID)
 		if !exists {
-			c.JSON(http.StatusNotFound, gin.H{"error": "destination not found: " + destID})
+			respondError(c, models.CodeDestinationNotFound, "destination not found: "+destID)
 			return
 		}
 		destinations[destID] = dest
@@ -76,72 +154,171 @@ func StartNoiseGeneration(c *gin.Context) {
 	config := &models.NoiseConfig{
 		DestinationID:  req.DestinationID,
 		RatePerSecond:  req.RatePerSecond,
+		Workers:        req.Workers,
 		EnabledSources: req.EnabledSources,
+		Budget:         req.Budget,
+		Completion:     req.Completion,
+		Mirrors:        req.Mirrors,
+		Cardinality:    req.Cardinality,
+		TimestampFuzz:  req.TimestampFuzz,
+		ClockSkew:      req.ClockSkew,
+		OutOfOrder:     req.OutOfOrder,
+		Chaos:          req.Chaos,
+		Behavior:       req.Behavior,
+		Seed:           req.Seed,
+		Reconcile:      req.Reconcile,
+	}
+
+	if req.DryRun {
+		c.JSON(http.StatusOK, noise.Estimate(config, destinations))
+		return
 	}
 
-	gen := noise.GetInstance()
-	if err := gen.Start(config, destinations); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := checkNoiseLimits(config); err != nil {
+		respondError(c, models.CodeServerLimitReached, err.Error())
 		return
 	}
 
+	// Close out the statistics of a run that stopped by itself
+	noiseStats.check()
+	var err error
+	if coordinator != nil {
+		err = coordinator.Start(config, destinations, routeTargets(destinations))
+	} else {
+		err = noise.GetInstance().Start(config, destinations)
+	}
+	if err != nil {
+		noiseError(c, err)
+		return
+	}
+	noiseStats.started(replayOf)
+	saveStreamState()
+	recordNoiseUse(c, req.EnabledSources, destinationIDs)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Noise generation started",
-		"status":  gen.GetStatus(),
+		"status":  noiseStatus(),
 	})
 }
 
+// recordNoiseUse records the enabled sources of a noise run and the
+// destinations it sends to as recently used
+func recordNoiseUse(c *gin.Context, sources []models.EnabledEventSource, destinationIDs map[string]bool) {
+	var templates []models.TemplateRef
+	for _, source := range sources {
+		if !source.Enabled {
+			continue
+		}
+		if len(source.TemplateIDs) == 0 {
+			templates = append(templates, models.TemplateRef{EventType: source.EventTypeID})
+		}
+		for _, id := range source.TemplateIDs {
+			templates = append(templates, models.TemplateRef{EventType: source.EventTypeID, TemplateID: id})
+		}
+	}
+	ids := make([]string, 0, len(destinationIDs))
+	for id := range destinationIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	recordUse(c, templates, ids)
+}
+
+// routeTargets returns the destinations the routing rules of destinations
+// forward to, which workers need along with their shard
+func routeTargets(destinations map[string]*models.Destination) map[string]*models.Destination {
+	targets := make(map[string]*models.Destination)
+	for _, dest := range destinations {
+		for _, rule := range dest.Config.Routes {
+			if rule.DestinationID == "" {
+				continue
+			}
+			if target, ok := destinationStore.Get(rule.DestinationID); ok {
+				targets[target.ID] = target
+			}
+		}
+	}
+	return targets
+}
+
 // StopNoiseGeneration stops noise generation
 func StopNoiseGeneration(c *gin.Context) {
-	gen := noise.GetInstance()
-	if err := gen.Stop(); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var err error
+	if coordinator != nil {
+		err = coordinator.Stop()
+	} else {
+		err = noise.GetInstance().Stop()
+	}
+	if err != nil {
+		noiseError(c, err)
 		return
 	}
+	noiseStats.check()
+	saveStreamState()
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Noise generation stopped",
-		"status":  gen.GetStatus(),
+		"status":  noiseStatus(),
 	})
 }
 
 // GetNoiseStatus returns the current noise generation status
 func GetNoiseStatus(c *gin.Context) {
-	gen := noise.GetInstance()
-	c.JSON(http.StatusOK, gen.GetStatus())
+	c.JSON(http.StatusOK, noiseStatus())
 }
 
 // UpdateNoiseConfig updates the running noise configuration
 func UpdateNoiseConfig(c *gin.Context) {
 	var req models.NoiseUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindError(c, err)
 		return
 	}
 
 	// Validate rate if provided
-	if req.RatePerSecond != nil && (*req.RatePerSecond < 0.1 || *req.RatePerSecond > 10000) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "rate_per_second must be between 0.1 and 10000"})
+	if req.RatePerSecond != nil && (*req.RatePerSecond < 0.1 || *req.RatePerSecond > 1000000) {
+		respondError(c, models.CodeValidationFailed, "rate_per_second must be between 0.1 and 1000000")
 		return
 	}
+	if !resolveSourceOverrides(c, req.EnabledSources) {
+		return
+	}
+	if status := noiseStatus(); status.Running && status.CurrentConfig != nil {
+		config := *status.CurrentConfig
+		if req.RatePerSecond != nil {
+			config.RatePerSecond = *req.RatePerSecond
+		}
+		if len(req.EnabledSources) > 0 {
+			config.EnabledSources = req.EnabledSources
+		}
+		if err := checkNoiseLimits(&config); err != nil {
+			respondError(c, models.CodeServerLimitReached, err.Error())
+			return
+		}
+	}
 
-	gen := noise.GetInstance()
-	if err := gen.UpdateConfig(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var err error
+	if coordinator != nil {
+		err = coordinator.Update(&req)
+	} else {
+		err = noise.GetInstance().UpdateConfig(&req)
+	}
+	if err != nil {
+		noiseError(c, err)
 		return
 	}
+	saveStreamState()
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"status":  gen.GetStatus(),
+		"status":  noiseStatus(),
 	})
 }
 
 // GetNoiseStats returns the current noise generation statistics
 func GetNoiseStats(c *gin.Context) {
-	gen := noise.GetInstance()
-	status := gen.GetStatus()
+	status := noiseStatus()
 	c.JSON(http.StatusOK, status.Stats)
 }
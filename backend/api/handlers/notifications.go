@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+	"siem-event-generator/notify"
+	"siem-event-generator/secrets"
+)
+
+// channelSecrets calls fn with each secret a channel holds: its password,
+// its URL when it is a Slack webhook and its webhook header values
+func channelSecrets(ch *models.NotificationChannel, fn func(v *string) error) error {
+	if err := fn(&ch.Password); err != nil {
+		return err
+	}
+	if ch.Type == models.NotificationSlack {
+		if err := fn(&ch.URL); err != nil {
+			return err
+		}
+	}
+	if len(ch.Headers) > 0 {
+		headers := make(map[string]string, len(ch.Headers))
+		for k, v := range ch.Headers {
+			if err := fn(&v); err != nil {
+				return err
+			}
+			headers[k] = v
+		}
+		ch.Headers = headers
+	}
+	return nil
+}
+
+// maskChannel returns a channel with its secrets masked for responses
+func maskChannel(ch models.NotificationChannel) models.NotificationChannel {
+	channelSecrets(&ch, func(v *string) error {
+		if *v != "" {
+			*v = secrets.Mask
+		}
+		return nil
+	})
+	return ch
+}
+
+// sealChannel returns a channel with its secrets encrypted for storage
+func sealChannel(ch models.NotificationChannel) (models.NotificationChannel, error) {
+	err := channelSecrets(&ch, func(v *string) (err error) {
+		*v, err = secrets.Seal(*v)
+		return err
+	})
+	return ch, err
+}
+
+// openChannel decrypts a stored channel's secrets
+func openChannel(ch models.NotificationChannel) (models.NotificationChannel, error) {
+	err := channelSecrets(&ch, func(v *string) (err error) {
+		*v, err = secrets.Open(*v)
+		return err
+	})
+	return ch, err
+}
+
+// restoreMaskedChannel keeps the existing secret wherever an update sent
+// back the mask
+func restoreMaskedChannel(ch, existing models.NotificationChannel) models.NotificationChannel {
+	if ch.Password == secrets.Mask {
+		ch.Password = existing.Password
+	}
+	if ch.URL == secrets.Mask && existing.Type == models.NotificationSlack {
+		ch.URL = existing.URL
+	}
+	for k, v := range ch.Headers {
+		if v == secrets.Mask {
+			ch.Headers[k] = existing.Headers[k]
+		}
+	}
+	return ch
+}
+
+// ListNotificationChannels returns the notification channels and the
+// events they can subscribe to
+func ListNotificationChannels(c *gin.Context) {
+	channels := notify.Channels.List()
+	for i, ch := range channels {
+		channels[i] = maskChannel(ch)
+	}
+	channels, page, ok := paginate(c, channels, 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"channels": channels,
+		"count":    len(channels),
+		"events":   models.NotificationEvents,
+	}))
+}
+
+// GetNotificationChannel returns a specific notification channel
+func GetNotificationChannel(c *gin.Context) {
+	ch, ok := notify.Channels.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeNotFound, "Notification channel not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, maskChannel(ch))
+}
+
+// CreateNotificationChannel adds a notification channel
+func CreateNotificationChannel(c *gin.Context) {
+	var ch models.NotificationChannel
+	if err := c.ShouldBindJSON(&ch); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	ch.ID = uuid.New().String()
+	ch.CreatedAt = time.Now()
+	ch.UpdatedAt = time.Now()
+	ch.LastSentAt, ch.LastError = nil, ""
+	if !setNotificationChannel(c, ch) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, maskChannel(ch))
+}
+
+// UpdateNotificationChannel replaces a notification channel. Secrets sent
+// back masked are kept.
+func UpdateNotificationChannel(c *gin.Context) {
+	id := c.Param("id")
+
+	existing, ok := notify.Channels.Get(id)
+	if !ok {
+		respondError(c, models.CodeNotFound, "Notification channel not found")
+		return
+	}
+
+	var ch models.NotificationChannel
+	if err := c.ShouldBindJSON(&ch); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	ch = restoreMaskedChannel(ch, existing)
+	ch.ID = id
+	ch.CreatedAt = existing.CreatedAt
+	ch.UpdatedAt = time.Now()
+	ch.LastSentAt, ch.LastError = existing.LastSentAt, existing.LastError
+	if !setNotificationChannel(c, ch) {
+		return
+	}
+
+	c.JSON(http.StatusOK, maskChannel(ch))
+}
+
+// setNotificationChannel stores a channel and saves the channels,
+// answering the request itself when the channel is rejected
+func setNotificationChannel(c *gin.Context, ch models.NotificationChannel) bool {
+	if err := notify.Channels.Set(ch); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return false
+	}
+	SaveNotificationChannels()
+	return true
+}
+
+// DeleteNotificationChannel removes a notification channel
+func DeleteNotificationChannel(c *gin.Context) {
+	if !notify.Channels.Delete(c.Param("id")) {
+		respondError(c, models.CodeNotFound, "Notification channel not found")
+		return
+	}
+	SaveNotificationChannels()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification channel deleted",
+	})
+}
+
+// TestNotificationChannel sends a test notification to a channel, enabled
+// or not, and waits for the result
+func TestNotificationChannel(c *gin.Context) {
+	ch, ok := notify.Channels.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeNotFound, "Notification channel not found")
+		return
+	}
+
+	if err := notify.Test(ch); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Test notification sent",
+	})
+}
+
+// notifyStreamStarted tells the notification channels that a noise run
+// started
+func notifyStreamStarted(run *models.RunRecord) {
+	if run == nil || run.Noise == nil {
+		return
+	}
+	config := run.Noise
+	notify.Publish(models.Notification{
+		Event:         models.NotifyStreamStarted,
+		Summary:       fmt.Sprintf("Noise stream started at %g events/s", config.RatePerSecond),
+		DestinationID: config.DestinationID,
+		Destination:   destinationName(config.DestinationID),
+		Details: map[string]interface{}{
+			"run_id":          run.ID,
+			"rate_per_second": config.RatePerSecond,
+			"sources":         len(config.EnabledSources),
+		},
+		Time: run.StartedAt,
+	})
+}
+
+// notifyStreamFinished tells the notification channels that a noise run
+// stopped, and why
+func notifyStreamFinished(run *models.RunRecord) {
+	n := models.Notification{
+		Event:   models.NotifyStreamFinished,
+		Summary: fmt.Sprintf("Noise stream finished: %s", run.StopReason),
+		Details: map[string]interface{}{
+			"run_id":           run.ID,
+			"stop_reason":      run.StopReason,
+			"duration_seconds": int64(run.DurationSeconds),
+			"events_generated": run.EventsGenerated,
+			"events_sent":      run.EventsSent,
+			"errors":           run.Errors,
+		},
+		Time: run.StoppedAt,
+	}
+	if run.Noise != nil {
+		n.DestinationID = run.Noise.DestinationID
+		n.Destination = destinationName(run.Noise.DestinationID)
+	}
+	if len(run.ErrorSamples) > 0 {
+		n.Details["error_sample"] = run.ErrorSamples[0]
+	}
+	notify.Publish(n)
+}
+
+// destinationName returns a destination's name, or its ID once deleted
+func destinationName(id string) string {
+	if dest, ok := destinationStore.Get(id); ok {
+		return dest.Name
+	}
+	return id
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/models"
+)
+
+// Paging of list endpoints
+const (
+	maxPageSize = 1000
+	cursorLabel = "offset:"
+)
+
+// listParams are the query parameters every list endpoint reads itself;
+// any other naming a field of the items is a filter on it
+var listParams = map[string]bool{"limit": true, "cursor": true, "sort": true, "q": true}
+
+// listPage is what a paged list response adds to its items
+type listPage struct {
+	total      int    // Items matching the filters, on every page
+	nextCursor string // Empty on the last page
+}
+
+// into adds the page to a list response: count stays the items returned
+func (p listPage) into(resp gin.H) gin.H {
+	resp["total"] = p.total
+	if p.nextCursor != "" {
+		resp["next_cursor"] = p.nextCursor
+	}
+	return resp
+}
+
+// paginate narrows items the way a list request asks:
+//
+//	?field=value  keeps items whose top-level field is value, ignoring case;
+//	              comma-separate values to keep any of them, and a list field
+//	              matches if it holds one
+//	?q=text       keeps items with a text field containing text
+//	?sort=field   orders by a field, -field for descending; ties keep their order
+//	?limit=n      returns at most n items, 1 to 1000; defaultLimit unless
+//	              given, or 1000 when that is 0
+//	?cursor=      continues from the next_cursor of the previous page
+//
+// Fields are the JSON names of T; other parameters are ignored, and a
+// filter value a field cannot hold, such as a word for a number, is
+// refused. own names the query parameters the endpoint reads itself. It
+// returns false after answering a bad request.
+func paginate[T any](c *gin.Context, items []T, defaultLimit int, own ...string) ([]T, listPage, bool) {
+	fields := jsonFields(reflect.TypeOf(items).Elem())
+	skip := make(map[string]bool, len(own))
+	for _, name := range own {
+		skip[name] = true
+	}
+
+	filters := make(map[string][]string)
+	for name := range c.Request.URL.Query() {
+		if listParams[name] || skip[name] {
+			continue
+		}
+		kind, ok := fields[name]
+		if !ok {
+			continue
+		}
+		filters[name] = queryList(c, name)
+		for _, v := range filters[name] {
+			if !fitsKind(v, kind) {
+				respondError(c, models.CodeValidationFailed, fmt.Sprintf("filter %s cannot be %q", name, v))
+				return nil, listPage{}, false
+			}
+		}
+	}
+	sortField, desc := c.Query("sort"), false
+	if strings.HasPrefix(sortField, "-") {
+		sortField, desc = sortField[1:], true
+	}
+	if _, ok := fields[sortField]; sortField != "" && !ok {
+		respondError(c, models.CodeValidationFailed, fmt.Sprintf("sort must be a field of the items, not %q", sortField))
+		return nil, listPage{}, false
+	}
+	limit := defaultLimit
+	if limit == 0 {
+		limit = maxPageSize
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			respondError(c, models.CodeValidationFailed, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+			return nil, listPage{}, false
+		}
+		limit = min(n, maxPageSize)
+	}
+	offset := 0
+	if v := c.Query("cursor"); v != "" {
+		n, ok := decodeCursor(v)
+		if !ok {
+			respondError(c, models.CodeValidationFailed, "cursor must be a next_cursor from an earlier page")
+			return nil, listPage{}, false
+		}
+		offset = n
+	}
+
+	// Items are compared through their JSON, so fields read as clients see them
+	q := strings.ToLower(c.Query("q"))
+	if len(filters) > 0 || q != "" || sortField != "" {
+		type entry struct {
+			item   T
+			fields map[string]interface{}
+		}
+		entries := make([]entry, 0, len(items))
+		for _, item := range items {
+			var values map[string]interface{}
+			data, _ := json.Marshal(item)
+			json.Unmarshal(data, &values)
+			if matchesFilters(values, filters) && (q == "" || containsText(values, q)) {
+				entries = append(entries, entry{item, values})
+			}
+		}
+		if sortField != "" {
+			sort.SliceStable(entries, func(i, j int) bool {
+				a, b := entries[i].fields[sortField], entries[j].fields[sortField]
+				if a == nil || b == nil {
+					return a != nil // Items without the field go last
+				}
+				if desc {
+					return compareValues(b, a) < 0
+				}
+				return compareValues(a, b) < 0
+			})
+		}
+		items = make([]T, len(entries))
+		for i, e := range entries {
+			items[i] = e.item
+		}
+	}
+
+	page := listPage{total: len(items)}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+		page.nextCursor = encodeCursor(offset + limit)
+	}
+	return items, page, true
+}
+
+// jsonFields returns the kinds of a struct's fields by JSON name, embedded
+// ones included; pointers are followed
+func jsonFields(t reflect.Type) map[string]reflect.Kind {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fields := make(map[string]reflect.Kind)
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		kind := f.Type.Kind()
+		if kind == reflect.Pointer {
+			kind = f.Type.Elem().Kind()
+		}
+		switch {
+		case name == "-" || !f.IsExported():
+		case f.Anonymous && name == "":
+			for embedded, k := range jsonFields(f.Type) {
+				fields[embedded] = k
+			}
+		case name == "":
+			fields[f.Name] = kind
+		default:
+			fields[name] = kind
+		}
+	}
+	return fields
+}
+
+// fitsKind reports whether a filter value can match a field of kind:
+// true or false for booleans, a number for numbers
+func fitsKind(v string, kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool:
+		_, err := strconv.ParseBool(v)
+		return err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		_, err := strconv.ParseFloat(v, 64)
+		return err == nil
+	}
+	return true
+}
+
+// matchesFilters reports whether every filtered field has one of its values
+func matchesFilters(values map[string]interface{}, filters map[string][]string) bool {
+	for field, wanted := range filters {
+		if !matchesAny(values[field], wanted) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(value interface{}, wanted []string) bool {
+	if list, ok := value.([]interface{}); ok {
+		for _, v := range list {
+			if matchesAny(v, wanted) {
+				return true
+			}
+		}
+		return false
+	}
+	s := valueString(value)
+	for _, w := range wanted {
+		if strings.EqualFold(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsText reports whether a text field, or text in a list field,
+// contains q
+func containsText(values map[string]interface{}, q string) bool {
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			if strings.Contains(strings.ToLower(v), q) {
+				return true
+			}
+		case []interface{}:
+			for _, e := range v {
+				if s, ok := e.(string); ok && strings.Contains(strings.ToLower(s), q) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// compareValues orders two JSON values: numbers by value, text ignoring
+// case (so RFC 3339 times sort by time), false before true
+func compareValues(a, b interface{}) int {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(strings.ToLower(valueString(a)), strings.ToLower(valueString(b)))
+}
+
+// valueString formats a JSON value for matching and sorting
+func valueString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorLabel + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(data), cursorLabel) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(string(data), cursorLabel))
+	return n, err == nil && n >= 0
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type pageItem struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Weight  int    `json:"weight"`
+}
+
+// page runs paginate over n items for a request with query
+func page(t *testing.T, n, defaultLimit int, query string) ([]pageItem, listPage, int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/items?"+query, nil)
+
+	items := make([]pageItem, n)
+	for i := range items {
+		items[i] = pageItem{Name: "item", Enabled: i%2 == 0, Weight: i}
+	}
+	got, p, ok := paginate(c, items, defaultLimit)
+	if !ok {
+		return nil, p, w.Code
+	}
+	return got, p, http.StatusOK
+}
+
+func TestPaginateLimitBounds(t *testing.T) {
+	for _, query := range []string{"limit=0", "limit=-1", "limit=x"} {
+		if _, _, code := page(t, 10, 0, query); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, code)
+		}
+	}
+
+	items, p, _ := page(t, 2500, 0, "")
+	if len(items) != maxPageSize || p.nextCursor == "" || p.total != 2500 {
+		t.Errorf("default page has %d items, cursor %q, total %d", len(items), p.nextCursor, p.total)
+	}
+	if items, _, _ := page(t, 2500, 0, "limit=5000"); len(items) != maxPageSize {
+		t.Errorf("limit above the maximum returned %d items", len(items))
+	}
+	if items, p, _ := page(t, 250, 100, ""); len(items) != 100 || p.nextCursor == "" {
+		t.Errorf("endpoint default returned %d items", len(items))
+	}
+	if items, p, _ := page(t, 10, 0, "limit=3&cursor="+encodeCursor(9)); len(items) != 1 || p.nextCursor != "" {
+		t.Errorf("last page has %d items, cursor %q", len(items), p.nextCursor)
+	}
+}
+
+func TestPaginateFilters(t *testing.T) {
+	items, _, code := page(t, 10, 0, "enabled=true&utm_source=mail&_=123")
+	if code != http.StatusOK || len(items) != 5 {
+		t.Errorf("unknown params were not ignored: status %d, %d items", code, len(items))
+	}
+	for _, query := range []string{"enabled=maybe", "weight=heavy"} {
+		if _, _, code := page(t, 10, 0, query); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, code)
+		}
+	}
+	if items, _, _ := page(t, 10, 0, "weight=3,4"); len(items) != 2 {
+		t.Errorf("weight filter kept %d items, want 2", len(items))
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetPerformance returns the generation engine settings
+func GetPerformance(c *gin.Context) {
+	c.JSON(http.StatusOK, models.PerformanceSettings{
+		PerformanceMode: generators.PerformanceMode(),
+	})
+}
+
+// UpdatePerformance switches performance mode on or off
+func UpdatePerformance(c *gin.Context) {
+	var settings models.PerformanceSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		bindError(c, err)
+		return
+	}
+	generators.SetPerformanceMode(settings.PerformanceMode)
+	SavePerformance()
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// RunBenchmark measures the maximum events per second each generator sustains
+// on this host. The request blocks for duration_ms per generator.
+func RunBenchmark(c *gin.Context) {
+	var req models.BenchmarkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	result, err := generators.Benchmark(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
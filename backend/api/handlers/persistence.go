@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
+	"strings"
 	"time"
 
+	"siem-event-generator/delivery"
+	"siem-event-generator/generators"
 	"siem-event-generator/models"
+	"siem-event-generator/notify"
+	"siem-event-generator/secrets"
+	"siem-event-generator/storage"
 )
 
 // configDir returns the config directory path from env or default
@@ -20,48 +26,159 @@ func configDir() string {
 	return dir
 }
 
-// atomicWriteJSON writes data as indented JSON to filePath atomically
-func atomicWriteJSON(filePath string, v interface{}) error {
-	data, err := json.MarshalIndent(v, "", "  ")
+// storeTimeout bounds each database read and write
+const storeTimeout = 10 * time.Second
+
+// store holds the configuration. It is nil until OpenStore is called, and
+// saves are skipped until then.
+var store storage.Store
+
+// OpenStore connects to the configuration database. JSON files saved by
+// earlier versions are copied into it the first time.
+func OpenStore(cfg storage.Config) error {
+	s, err := storage.Open(cfg)
 	if err != nil {
-		return fmt.Errorf("marshal: %w", err)
+		return err
 	}
-	tmpPath := filePath + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
-		return fmt.Errorf("write temp file: %w", err)
-	}
-	if err := os.Rename(tmpPath, filePath); err != nil {
-		return fmt.Errorf("rename: %w", err)
+	store = s
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := migrateFiles(ctx); err != nil {
+		return fmt.Errorf("migrate JSON files: %w", err)
 	}
 	return nil
 }
 
-// SaveDestinations persists the destination store to disk
-func SaveDestinations() {
-	path := filepath.Join(configDir(), "destinations.json")
-	if err := atomicWriteJSON(path, destinationStore.List()); err != nil {
-		log.Printf("WARNING: failed to save destinations: %v", err)
+// encodeDocuments marshals the items of a collection keyed by ID
+func encodeDocuments(items map[string]interface{}) (map[string][]byte, error) {
+	docs := make(map[string][]byte, len(items))
+	for id, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %w", id, err)
+		}
+		docs[id] = data
 	}
+	return docs, nil
 }
 
-// LoadDestinations loads destinations from disk into the store
-func LoadDestinations() error {
-	path := filepath.Join(configDir(), "destinations.json")
-	data, err := os.ReadFile(path)
+// saveCollection replaces a collection in the store, logging failures
+func saveCollection(what, collection string, items map[string]interface{}) {
+	if store == nil {
+		return
+	}
+	docs, err := encodeDocuments(items)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		err = store.Save(ctx, collection, docs)
+	}
+	if err != nil {
+		log.Printf("WARNING: failed to save %s: %v", what, err)
+	}
+}
+
+// loadCollection calls fn with each document of a collection
+func loadCollection(collection string, fn func(data []byte) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	docs, err := store.Load(ctx, collection)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
+		return err
+	}
+	for id, data := range docs {
+		if err := fn(data); err != nil {
+			return fmt.Errorf("parse %s: %w", id, err)
 		}
-		return fmt.Errorf("read destinations: %w", err)
 	}
+	return nil
+}
 
-	var dests []*models.Destination
-	if err := json.Unmarshal(data, &dests); err != nil {
-		return fmt.Errorf("parse destinations: %w", err)
+// saveSetting stores one settings document, logging failures
+func saveSetting(what, id string, v interface{}) {
+	if store == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		err = store.Put(ctx, storage.CollectionSettings, id, data)
+	}
+	if err != nil {
+		log.Printf("WARNING: failed to save %s: %v", what, err)
+	}
+}
+
+// loadSetting decodes a settings document into v and reports whether it
+// was found
+func loadSetting(id string, v interface{}) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	docs, err := store.Load(ctx, storage.CollectionSettings)
+	if err != nil {
+		return false, err
 	}
+	data, ok := docs[id]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
 
+// destinationDocuments returns destinations keyed by ID with their
+// credentials encrypted
+func destinationDocuments(dests []*models.Destination) (map[string]interface{}, error) {
+	items := make(map[string]interface{}, len(dests))
 	for _, d := range dests {
-		destinationStore.Create(d)
+		config, err := secrets.SealConfig(d.Config)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt %s: %w", d.Name, err)
+		}
+		copied := *d
+		copied.Config = config
+		items[d.ID] = &copied
+	}
+	return items, nil
+}
+
+// SaveDestinations persists the destination store with credentials
+// encrypted
+func SaveDestinations() {
+	items, err := destinationDocuments(destinationStore.List())
+	if err != nil {
+		log.Printf("WARNING: failed to save destinations: %v", err)
+		return
+	}
+	saveCollection("destinations", storage.CollectionDestinations, items)
+}
+
+// LoadDestinations loads destinations from the store
+func LoadDestinations() error {
+	plaintext := false
+	err := loadCollection(storage.CollectionDestinations, func(data []byte) error {
+		var d models.Destination
+		if err := json.Unmarshal(data, &d); err != nil {
+			return err
+		}
+		plaintext = plaintext || secrets.NeedsSealing(d.Config)
+		config, err := secrets.OpenConfig(d.Config)
+		if err != nil {
+			// Still loaded, so saving keeps the encrypted values
+			log.Printf("WARNING: destination %s: %v", d.Name, err)
+		}
+		d.Config = config
+		destinationStore.Create(&d)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load destinations: %w", err)
+	}
+
+	// Rewrite destinations saved before credentials were encrypted
+	if plaintext {
+		SaveDestinations()
 	}
 	return nil
 }
@@ -88,32 +205,465 @@ func SeedDefaultDestinationIfEmpty() {
 	destinationStore.Create(defaultDest)
 }
 
-// SaveTemplates persists the custom template store to disk
+// SaveTemplates persists the custom template store
 func SaveTemplates() {
-	path := filepath.Join(configDir(), "templates.json")
-	if err := atomicWriteJSON(path, templateStore.List()); err != nil {
-		log.Printf("WARNING: failed to save templates: %v", err)
+	items := make(map[string]interface{})
+	for _, t := range templateStore.List() {
+		items[t.ID] = t
 	}
+	saveCollection("templates", storage.CollectionTemplates, items)
 }
 
-// LoadTemplates loads custom templates from disk into the store
+// LoadTemplates loads custom templates from the store
 func LoadTemplates() error {
-	path := filepath.Join(configDir(), "templates.json")
-	data, err := os.ReadFile(path)
+	err := loadCollection(storage.CollectionTemplates, func(data []byte) error {
+		var t models.EventTemplate
+		if err := json.Unmarshal(data, &t); err != nil {
+			return err
+		}
+		// Templates saved before versioning start at version 1
+		if t.SchemaVersion == 0 {
+			t.SchemaVersion = 1
+		}
+		templateStore.Create(&t)
+		return nil
+	})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
+		return fmt.Errorf("load templates: %w", err)
+	}
+	return nil
+}
+
+// SaveScenarios persists the metric scenario store
+func SaveScenarios() {
+	items := make(map[string]interface{})
+	for _, s := range scenarioStore.List() {
+		items[s.ID] = s
+	}
+	saveCollection("scenarios", storage.CollectionScenarios, items)
+}
+
+// LoadScenarios loads metric scenarios from the store
+func LoadScenarios() error {
+	err := loadCollection(storage.CollectionScenarios, func(data []byte) error {
+		var s models.Scenario
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
 		}
-		return fmt.Errorf("read templates: %w", err)
+		scenarioStore.Create(&s)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load scenarios: %w", err)
+	}
+	return nil
+}
+
+// SaveGeoPolicy persists the GeoIP country policy
+func SaveGeoPolicy() {
+	saveSetting("geo policy", "geo_policy", generators.Geo.Policy())
+}
+
+// LoadGeoPolicy loads the GeoIP country policy from the store
+func LoadGeoPolicy() error {
+	var policy generators.GeoPolicy
+	found, err := loadSetting("geo_policy", &policy)
+	if err != nil {
+		return fmt.Errorf("load geo policy: %w", err)
+	}
+	if !found {
+		return nil
+	}
+	return generators.Geo.SetPolicy(policy)
+}
+
+// SaveTheme persists the naming convention of the simulated organization
+func SaveTheme() {
+	saveSetting("theme", "theme", generators.Theme())
+}
+
+// LoadTheme loads the naming convention of the simulated organization from
+// the store
+func LoadTheme() error {
+	var theme models.Theme
+	found, err := loadSetting("theme", &theme)
+	if err != nil {
+		return fmt.Errorf("load theme: %w", err)
 	}
+	if !found {
+		return nil
+	}
+	return generators.SetTheme(theme)
+}
 
-	var tmpls []*models.EventTemplate
-	if err := json.Unmarshal(data, &tmpls); err != nil {
-		return fmt.Errorf("parse templates: %w", err)
+// SaveNameProfile persists the profile of made-up users' names
+func SaveNameProfile() {
+	saveSetting("name profile", "name_profile", generators.Names.Profile())
+}
+
+// LoadNameProfile loads the profile of made-up users' names from the store
+func LoadNameProfile() error {
+	var profile generators.NameProfile
+	found, err := loadSetting("name_profile", &profile)
+	if err != nil {
+		return fmt.Errorf("load name profile: %w", err)
+	}
+	if !found {
+		return nil
 	}
+	return generators.Names.SetProfile(profile)
+}
+
+// iocState is the form of the IOC pool in the JSON file of earlier
+// versions. Feed indicators are not saved; feeds are polled again at startup.
+type iocState struct {
+	Config     models.IOCConfig `json:"config"`
+	Indicators []models.IOC     `json:"indicators"`
+	Feeds      []models.IOCFeed `json:"feeds"`
+}
 
-	for _, t := range tmpls {
-		templateStore.Create(t)
+// indicatorID keys a hand-added indicator in the store
+func indicatorID(ioc models.IOC) string {
+	return string(ioc.Type) + "|" + strings.ToLower(ioc.Value)
+}
+
+// iocDocuments returns the hand-added indicators and the feeds keyed by ID
+func iocDocuments(indicators []models.IOC, feeds []models.IOCFeed) (map[string]interface{}, map[string]interface{}) {
+	iocItems := make(map[string]interface{})
+	for _, ioc := range indicators {
+		if !strings.HasPrefix(ioc.Source, "feed:") {
+			iocItems[indicatorID(ioc)] = ioc
+		}
+	}
+	feedItems := make(map[string]interface{}, len(feeds))
+	for _, feed := range feeds {
+		feedItems[feed.ID] = feed
+	}
+	return iocItems, feedItems
+}
+
+// SaveIOCs persists the IOC pool settings, hand-added indicators and feeds
+func SaveIOCs() {
+	saveSetting("IOC settings", "ioc_config", generators.IOCs.Config())
+	iocItems, feedItems := iocDocuments(generators.IOCs.List("", ""), iocFeedStore.List())
+	saveCollection("IOCs", storage.CollectionIndicators, iocItems)
+	saveCollection("IOC feeds", storage.CollectionIOCFeeds, feedItems)
+}
+
+// LoadIOCs loads the IOC pool settings, indicators and feeds from the store
+func LoadIOCs() error {
+	var cfg models.IOCConfig
+	found, err := loadSetting("ioc_config", &cfg)
+	if err != nil {
+		return fmt.Errorf("load IOC settings: %w", err)
+	}
+	if found {
+		if err := generators.IOCs.SetConfig(cfg); err != nil {
+			return err
+		}
+	}
+
+	var indicators []models.IOC
+	err = loadCollection(storage.CollectionIndicators, func(data []byte) error {
+		var ioc models.IOC
+		if err := json.Unmarshal(data, &ioc); err != nil {
+			return err
+		}
+		indicators = append(indicators, ioc)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load IOCs: %w", err)
+	}
+	generators.IOCs.Add(indicators)
+
+	err = loadCollection(storage.CollectionIOCFeeds, func(data []byte) error {
+		var feed models.IOCFeed
+		if err := json.Unmarshal(data, &feed); err != nil {
+			return err
+		}
+		feed.LastPolledAt = nil
+		iocFeedStore.Create(&feed)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load IOC feeds: %w", err)
+	}
+	return nil
+}
+
+// SaveAnonymization persists the sensitive field rules
+func SaveAnonymization() {
+	saveSetting("anonymization rules", "anonymization", delivery.Anonymization.Config())
+}
+
+// LoadAnonymization loads the sensitive field rules from the store
+func LoadAnonymization() error {
+	var cfg models.AnonymizationConfig
+	found, err := loadSetting("anonymization", &cfg)
+	if err != nil {
+		return fmt.Errorf("load anonymization rules: %w", err)
+	}
+	if !found {
+		return nil
+	}
+	return delivery.Anonymization.SetConfig(cfg)
+}
+
+// SaveHooks persists the event hook scripts
+func SaveHooks() {
+	items := make(map[string]interface{})
+	for _, h := range delivery.Hooks.List() {
+		items[h.ID] = h
+	}
+	saveCollection("event hooks", storage.CollectionHooks, items)
+}
+
+// LoadHooks loads the event hook scripts from the store. A hook whose script
+// no longer loads is kept, disabled, so it can be fixed.
+func LoadHooks() error {
+	err := loadCollection(storage.CollectionHooks, func(data []byte) error {
+		var h models.EventHook
+		if err := json.Unmarshal(data, &h); err != nil {
+			return err
+		}
+		if err := delivery.Hooks.Set(h); err != nil {
+			log.Printf("WARNING: disabling event hook %s: %v", h.Name, err)
+			h.Enabled = false
+			return delivery.Hooks.Set(h)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load event hooks: %w", err)
+	}
+	return nil
+}
+
+// SavePresets persists the override presets
+func SavePresets() {
+	items := make(map[string]interface{})
+	for _, p := range presetStore.List() {
+		items[p.ID] = p
+	}
+	saveCollection("override presets", storage.CollectionPresets, items)
+}
+
+// LoadPresets loads the override presets from the store
+func LoadPresets() error {
+	err := loadCollection(storage.CollectionPresets, func(data []byte) error {
+		var p models.OverridePreset
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		return presetStore.Set(p)
+	})
+	if err != nil {
+		return fmt.Errorf("load override presets: %w", err)
+	}
+	return nil
+}
+
+// SaveCampaigns persists the campaigns
+func SaveCampaigns() {
+	items := make(map[string]interface{})
+	for _, campaign := range campaignStore.List() {
+		items[campaign.ID] = campaign
+	}
+	saveCollection("campaigns", storage.CollectionCampaigns, items)
+}
+
+// LoadCampaigns loads the campaigns from the store
+func LoadCampaigns() error {
+	err := loadCollection(storage.CollectionCampaigns, func(data []byte) error {
+		var campaign models.Campaign
+		if err := json.Unmarshal(data, &campaign); err != nil {
+			return err
+		}
+		campaignStore.Set(campaign)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load campaigns: %w", err)
+	}
+	return nil
+}
+
+// SaveNotificationChannels persists the notification channels with their
+// secrets encrypted
+func SaveNotificationChannels() {
+	items := make(map[string]interface{})
+	for _, ch := range notify.Channels.List() {
+		sealed, err := sealChannel(ch)
+		if err != nil {
+			log.Printf("WARNING: failed to save notification channels: encrypt %s: %v", ch.Name, err)
+			return
+		}
+		items[ch.ID] = sealed
+	}
+	saveCollection("notification channels", storage.CollectionNotifications, items)
+}
+
+// LoadNotificationChannels loads the notification channels from the store
+func LoadNotificationChannels() error {
+	err := loadCollection(storage.CollectionNotifications, func(data []byte) error {
+		var ch models.NotificationChannel
+		if err := json.Unmarshal(data, &ch); err != nil {
+			return err
+		}
+		opened, err := openChannel(ch)
+		if err != nil {
+			return fmt.Errorf("notification channel %s: %w", ch.Name, err)
+		}
+		return notify.Channels.Set(opened)
+	})
+	if err != nil {
+		return fmt.Errorf("load notification channels: %w", err)
+	}
+	return nil
+}
+
+// SavePerformance persists the generation engine settings
+func SavePerformance() {
+	saveSetting("performance settings", "performance", models.PerformanceSettings{PerformanceMode: generators.PerformanceMode()})
+}
+
+// LoadPerformance loads the generation engine settings from the store
+func LoadPerformance() error {
+	var settings models.PerformanceSettings
+	found, err := loadSetting("performance", &settings)
+	if err != nil {
+		return fmt.Errorf("load performance settings: %w", err)
+	}
+	if found {
+		generators.SetPerformanceMode(settings.PerformanceMode)
+	}
+	return nil
+}
+
+// SavePKIConfig persists the certificate settings
+func SavePKIConfig() {
+	saveSetting("certificate settings", "pki", generators.PKIConfig())
+}
+
+// LoadPKIConfig loads the certificate settings from the store
+func LoadPKIConfig() error {
+	var cfg models.PKIConfig
+	found, err := loadSetting("pki", &cfg)
+	if err != nil {
+		return fmt.Errorf("load certificate settings: %w", err)
+	}
+	if found {
+		return generators.SetPKIConfig(cfg)
+	}
+	return nil
+}
+
+// SaveProcessConfig persists the process command line settings
+func SaveProcessConfig() {
+	saveSetting("process command line settings", "processes", generators.ProcessConfig())
+}
+
+// LoadProcessConfig loads the process command line settings from the store
+func LoadProcessConfig() error {
+	var cfg models.ProcessConfig
+	found, err := loadSetting("processes", &cfg)
+	if err != nil {
+		return fmt.Errorf("load process command line settings: %w", err)
+	}
+	if found {
+		return generators.SetProcessConfig(cfg)
+	}
+	return nil
+}
+
+// SaveWebConfig persists the web request settings
+func SaveWebConfig() {
+	saveSetting("web request settings", "web", generators.WebConfig())
+}
+
+// LoadWebConfig loads the web request settings from the store
+func LoadWebConfig() error {
+	var cfg models.WebConfig
+	found, err := loadSetting("web", &cfg)
+	if err != nil {
+		return fmt.Errorf("load web request settings: %w", err)
+	}
+	if found {
+		return generators.SetWebConfig(cfg)
+	}
+	return nil
+}
+
+// SaveServerLimits persists the server's guardrails
+func SaveServerLimits() {
+	saveSetting("server limits", "limits", serverLimits())
+}
+
+// LoadServerLimits loads the server's guardrails from the store
+func LoadServerLimits() error {
+	var cfg models.ServerLimits
+	found, err := loadSetting("limits", &cfg)
+	if err != nil {
+		return fmt.Errorf("load server limits: %w", err)
+	}
+	if found {
+		return setServerLimits(cfg)
+	}
+	return nil
+}
+
+// SaveDetectionBackends persists the SIEM backends with their secrets
+// encrypted
+func SaveDetectionBackends() {
+	cfg := getDetectionBackends()
+	err := backendSecrets(&cfg, func(v *string) (err error) {
+		if *v != "" {
+			*v, err = secrets.Seal(*v)
+		}
+		return err
+	})
+	if err != nil {
+		log.Printf("WARNING: failed to save detection backends: encrypt: %v", err)
+		return
+	}
+	saveSetting("detection backends", detectionBackendsSetting, cfg)
+}
+
+// LoadDetectionBackends loads the SIEM backends from the store
+func LoadDetectionBackends() error {
+	var cfg models.DetectionBackends
+	found, err := loadSetting(detectionBackendsSetting, &cfg)
+	if err != nil {
+		return fmt.Errorf("load detection backends: %w", err)
+	}
+	if !found {
+		return nil
+	}
+	err = backendSecrets(&cfg, func(v *string) (err error) {
+		*v, err = secrets.Open(*v)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("load detection backends: %w", err)
+	}
+	return setDetectionBackends(cfg)
+}
+
+// SaveCloudTrailConfig persists the CloudTrail outcome settings
+func SaveCloudTrailConfig() {
+	saveSetting("CloudTrail settings", "cloudtrail", generators.CloudTrailConfig())
+}
+
+// LoadCloudTrailConfig loads the CloudTrail outcome settings from the store
+func LoadCloudTrailConfig() error {
+	var cfg models.CloudTrailConfig
+	found, err := loadSetting("cloudtrail", &cfg)
+	if err != nil {
+		return fmt.Errorf("load CloudTrail settings: %w", err)
+	}
+	if found {
+		return generators.SetCloudTrailConfig(cfg)
 	}
 	return nil
 }
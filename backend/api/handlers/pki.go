@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetPKIConfig returns the certificate settings
+func GetPKIConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, generators.PKIConfig())
+}
+
+// UpdatePKIConfig sets the certificate settings
+func UpdatePKIConfig(c *gin.Context) {
+	var cfg models.PKIConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.SetPKIConfig(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SavePKIConfig()
+
+	c.JSON(http.StatusOK, cfg)
+}
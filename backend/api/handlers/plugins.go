@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// ListPlugins returns the generator plugins and the API version they must
+// be built for
+func ListPlugins(c *gin.Context) {
+	plugins, page, ok := paginate(c, generators.Plugins.List(), 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"plugins":     plugins,
+		"count":       len(plugins),
+		"dir":         generators.Plugins.Dir(),
+		"api_version": generators.GeneratorAPIVersion,
+	}))
+}
+
+// ScanPlugins loads the plugins added to the plugin directory since the
+// last scan and retries those that failed
+func ScanPlugins(c *gin.Context) {
+	plugins, err := generators.Plugins.Scan()
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plugins": plugins,
+		"count":   len(plugins),
+	})
+}
+
+// LoadPlugin loads one file from the plugin directory
+func LoadPlugin(c *gin.Context) {
+	var req models.LoadPluginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	plugin, err := generators.Plugins.Load(req.File)
+	if err != nil {
+		if plugin.Status == models.PluginFailed {
+			pluginError(c, err, plugin)
+			return
+		}
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, plugin)
+}
+
+// pluginError answers with the error a plugin failed to load with and the
+// plugin as it stands
+func pluginError(c *gin.Context, err error, plugin models.GeneratorPlugin) {
+	status, resp := apiError(models.CodeValidationFailed, err.Error())
+	c.JSON(status, struct {
+		models.APIError
+		Plugin models.GeneratorPlugin `json:"plugin"`
+	}{resp, plugin})
+}
+
+// ReloadPlugin restarts a process plugin so a new build takes effect
+func ReloadPlugin(c *gin.Context) {
+	plugin, err := generators.Plugins.Reload(c.Param("id"))
+	if generators.IsPluginNotFound(err) {
+		respondError(c, models.CodeNotFound, "Plugin not found")
+		return
+	}
+	if err != nil {
+		pluginError(c, err, plugin)
+		return
+	}
+
+	c.JSON(http.StatusOK, plugin)
+}
+
+// UnloadPlugin removes a plugin's event type until the next scan or restart
+func UnloadPlugin(c *gin.Context) {
+	if !generators.Plugins.Unload(c.Param("id")) {
+		respondError(c, models.CodeNotFound, "Plugin not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Plugin unloaded",
+	})
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+	"siem-event-generator/storage"
+)
+
+// Favorites and recent use are kept per user, named by the X-User header
+// or ?user=. Without either, requests share the default user.
+const (
+	defaultUser       = "default"
+	maxUserNameLength = 64
+	maxRecent         = 20 // Recent templates and destinations kept each
+	maxFavorites      = 200
+)
+
+// preferencesMu serializes the read-modify-write of favorites and recent
+// use documents
+var preferencesMu sync.Mutex
+
+// requestUser returns the user a request acts for
+func requestUser(c *gin.Context) (string, bool) {
+	user := strings.TrimSpace(c.GetHeader("X-User"))
+	if user == "" {
+		user = strings.TrimSpace(c.Query("user"))
+	}
+	if user == "" {
+		return defaultUser, true
+	}
+	if len(user) > maxUserNameLength || strings.IndexFunc(user, unicode.IsControl) >= 0 {
+		return "", false
+	}
+	return user, true
+}
+
+// preferencesUser returns the user of a favorites or recent use request,
+// responding with an error if the name is invalid
+func preferencesUser(c *gin.Context) (string, bool) {
+	user, ok := requestUser(c)
+	if !ok {
+		respondError(c, models.CodeValidationFailed, "user names are at most 64 characters without control characters")
+	}
+	return user, ok
+}
+
+// resolveTemplateRef fills in the name of a template or event type and
+// reports whether it exists. Custom templates belong to the event type
+// named by their category.
+func resolveTemplateRef(ref models.TemplateRef) (models.TemplateRef, bool) {
+	ref.Name = ""
+	if tmpl, ok := templateStore.Get(ref.TemplateID); ok && tmpl.Category == ref.EventType {
+		ref.Name = tmpl.Name
+		return ref, true
+	}
+	gen, ok := generators.GetGenerator(ref.EventType)
+	if !ok {
+		return ref, false
+	}
+	if ref.TemplateID == "" {
+		ref.Name = gen.GetEventType().Name
+		return ref, true
+	}
+	for _, t := range gen.GetTemplates() {
+		if t.ID == ref.TemplateID {
+			ref.Name = t.Name
+			return ref, true
+		}
+	}
+	return ref, false
+}
+
+// resolveDestinationRef fills in the name and type of a destination and
+// reports whether it exists
+func resolveDestinationRef(id string) (models.DestinationRef, bool) {
+	dest, ok := destinationStore.Get(id)
+	if !ok {
+		return models.DestinationRef{ID: id}, false
+	}
+	return models.DestinationRef{ID: id, Name: dest.Name, Type: dest.Type}, true
+}
+
+// loadFavorites reads a user's favorites, which are empty if none were
+// saved
+func loadFavorites(ctx context.Context, user string) (*models.Favorites, error) {
+	favorites := &models.Favorites{User: user}
+	if store != nil {
+		docs, err := store.Load(ctx, storage.CollectionFavorites)
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := docs[user]; ok {
+			if err := json.Unmarshal(data, favorites); err != nil {
+				return nil, err
+			}
+		}
+	}
+	favorites.User = user
+	return favorites, nil
+}
+
+// saveFavorites stores a user's favorites
+func saveFavorites(ctx context.Context, favorites *models.Favorites) error {
+	if store == nil {
+		return nil
+	}
+	data, err := json.Marshal(favorites)
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, storage.CollectionFavorites, favorites.User, data)
+}
+
+// loadRecent reads a user's recent use
+func loadRecent(ctx context.Context, user string) (*models.RecentUse, error) {
+	recent := &models.RecentUse{User: user}
+	if store != nil {
+		value, ok, err := store.Meta(ctx, storage.MetaRecentPrefix+user)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if err := json.Unmarshal([]byte(value), recent); err != nil {
+				return nil, err
+			}
+		}
+	}
+	recent.User = user
+	return recent, nil
+}
+
+// saveRecent stores a user's recent use. It changes with every request, so
+// it is kept without history.
+func saveRecent(ctx context.Context, recent *models.RecentUse) error {
+	if store == nil {
+		return nil
+	}
+	data, err := json.Marshal(recent)
+	if err != nil {
+		return err
+	}
+	return store.SetMeta(ctx, storage.MetaRecentPrefix+recent.User, string(data))
+}
+
+// recordUse moves templates and destinations a request used to the front
+// of its user's recent use, logging failures rather than failing the
+// request
+func recordUse(c *gin.Context, templates []models.TemplateRef, destinationIDs []string) {
+	user, ok := requestUser(c)
+	if !ok || store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	preferencesMu.Lock()
+	defer preferencesMu.Unlock()
+	recent, err := loadRecent(ctx, user)
+	if err == nil {
+		now := time.Now().UTC()
+		for _, ref := range templates {
+			recent.Templates = bumpRecentTemplate(recent.Templates, ref, now)
+		}
+		for _, id := range destinationIDs {
+			if id != "" {
+				recent.Destinations = bumpRecentDestination(recent.Destinations, id, now)
+			}
+		}
+		err = saveRecent(ctx, recent)
+	}
+	if err != nil {
+		log.Printf("WARNING: failed to save recent use of %s: %v", user, err)
+	}
+}
+
+// bumpRecentTemplate moves ref to the front of recent, counting the use
+func bumpRecentTemplate(recent []models.RecentTemplate, ref models.TemplateRef, now time.Time) []models.RecentTemplate {
+	entry := models.RecentTemplate{TemplateRef: models.TemplateRef{EventType: ref.EventType, TemplateID: ref.TemplateID}}
+	for i, r := range recent {
+		if r.EventType == ref.EventType && r.TemplateID == ref.TemplateID {
+			entry = r
+			recent = append(recent[:i], recent[i+1:]...)
+			break
+		}
+	}
+	entry.Uses++
+	entry.LastUsed = now
+	recent = append([]models.RecentTemplate{entry}, recent...)
+	if len(recent) > maxRecent {
+		recent = recent[:maxRecent]
+	}
+	return recent
+}
+
+// bumpRecentDestination moves a destination to the front of recent,
+// counting the use
+func bumpRecentDestination(recent []models.RecentDestination, id string, now time.Time) []models.RecentDestination {
+	entry := models.RecentDestination{DestinationRef: models.DestinationRef{ID: id}}
+	for i, r := range recent {
+		if r.ID == id {
+			entry = r
+			recent = append(recent[:i], recent[i+1:]...)
+			break
+		}
+	}
+	entry.Uses++
+	entry.LastUsed = now
+	recent = append([]models.RecentDestination{entry}, recent...)
+	if len(recent) > maxRecent {
+		recent = recent[:maxRecent]
+	}
+	return recent
+}
+
+// GetFavorites returns the user's favorite templates and destinations.
+// Ones deleted since they were added are left out.
+func GetFavorites(c *gin.Context) {
+	user, ok := preferencesUser(c)
+	if !ok {
+		return
+	}
+	favorites, err := loadFavorites(c.Request.Context(), user)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+
+	resolved := &models.Favorites{User: user, Templates: []models.TemplateRef{}, Destinations: []models.DestinationRef{}}
+	for _, ref := range favorites.Templates {
+		if ref, ok := resolveTemplateRef(ref); ok {
+			resolved.Templates = append(resolved.Templates, ref)
+		}
+	}
+	for _, d := range favorites.Destinations {
+		if ref, ok := resolveDestinationRef(d.ID); ok {
+			resolved.Destinations = append(resolved.Destinations, ref)
+		}
+	}
+	c.JSON(http.StatusOK, resolved)
+}
+
+// AddFavoriteTemplate adds a template, or a whole event type when the
+// template is left out, to the user's favorites
+func AddFavoriteTemplate(c *gin.Context) {
+	ref, ok := resolveTemplateRef(models.TemplateRef{EventType: c.Param("event_type"), TemplateID: c.Param("template_id")})
+	if !ok {
+		respondError(c, models.CodeTemplateNotFound, "Template not found")
+		return
+	}
+	updateFavorites(c, func(f *models.Favorites) {
+		for _, t := range f.Templates {
+			if t.EventType == ref.EventType && t.TemplateID == ref.TemplateID {
+				return
+			}
+		}
+		f.Templates = append(f.Templates, models.TemplateRef{EventType: ref.EventType, TemplateID: ref.TemplateID})
+	})
+}
+
+// RemoveFavoriteTemplate removes a template or event type from the user's
+// favorites
+func RemoveFavoriteTemplate(c *gin.Context) {
+	eventType, templateID := c.Param("event_type"), c.Param("template_id")
+	updateFavorites(c, func(f *models.Favorites) {
+		kept := f.Templates[:0]
+		for _, t := range f.Templates {
+			if t.EventType != eventType || t.TemplateID != templateID {
+				kept = append(kept, t)
+			}
+		}
+		f.Templates = kept
+	})
+}
+
+// AddFavoriteDestination adds a destination to the user's favorites
+func AddFavoriteDestination(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := destinationStore.Get(id); !ok {
+		respondError(c, models.CodeDestinationNotFound, "Destination not found")
+		return
+	}
+	updateFavorites(c, func(f *models.Favorites) {
+		for _, d := range f.Destinations {
+			if d.ID == id {
+				return
+			}
+		}
+		f.Destinations = append(f.Destinations, models.DestinationRef{ID: id})
+	})
+}
+
+// RemoveFavoriteDestination removes a destination from the user's
+// favorites
+func RemoveFavoriteDestination(c *gin.Context) {
+	id := c.Param("id")
+	updateFavorites(c, func(f *models.Favorites) {
+		kept := f.Destinations[:0]
+		for _, d := range f.Destinations {
+			if d.ID != id {
+				kept = append(kept, d)
+			}
+		}
+		f.Destinations = kept
+	})
+}
+
+// updateFavorites applies fn to the user's stored favorites and responds
+// with the result
+func updateFavorites(c *gin.Context, fn func(f *models.Favorites)) {
+	user, ok := preferencesUser(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	preferencesMu.Lock()
+	favorites, err := loadFavorites(ctx, user)
+	if err == nil {
+		fn(favorites)
+		if len(favorites.Templates)+len(favorites.Destinations) > maxFavorites {
+			preferencesMu.Unlock()
+			respondError(c, models.CodeValidationFailed, "at most 200 favorites are kept per user")
+			return
+		}
+		err = saveFavorites(ctx, favorites)
+	}
+	preferencesMu.Unlock()
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	GetFavorites(c)
+}
+
+// GetRecent returns the templates and destinations the user used most
+// recently, with how often each was used. Ones deleted since are left out.
+func GetRecent(c *gin.Context) {
+	user, ok := preferencesUser(c)
+	if !ok {
+		return
+	}
+	recent, err := loadRecent(c.Request.Context(), user)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+
+	resolved := &models.RecentUse{User: user, Templates: []models.RecentTemplate{}, Destinations: []models.RecentDestination{}}
+	for _, r := range recent.Templates {
+		if ref, ok := resolveTemplateRef(r.TemplateRef); ok {
+			r.TemplateRef = ref
+			resolved.Templates = append(resolved.Templates, r)
+		}
+	}
+	for _, r := range recent.Destinations {
+		if ref, ok := resolveDestinationRef(r.ID); ok {
+			r.DestinationRef = ref
+			resolved.Destinations = append(resolved.Destinations, r)
+		}
+	}
+	c.JSON(http.StatusOK, resolved)
+}
+
+// ClearRecent forgets the user's recent use
+func ClearRecent(c *gin.Context) {
+	user, ok := preferencesUser(c)
+	if !ok {
+		return
+	}
+	preferencesMu.Lock()
+	err := saveRecent(c.Request.Context(), &models.RecentUse{User: user})
+	preferencesMu.Unlock()
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	GetRecent(c)
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// PresetStore provides thread-safe storage for override presets
+type PresetStore struct {
+	mu      sync.RWMutex
+	presets map[string]*models.OverridePreset
+}
+
+// NewPresetStore creates a new preset store
+func NewPresetStore() *PresetStore {
+	return &PresetStore{
+		presets: make(map[string]*models.OverridePreset),
+	}
+}
+
+// Get retrieves a copy of a preset by ID
+func (s *PresetStore) Get(id string) (models.OverridePreset, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.presets[id]
+	if !ok {
+		return models.OverridePreset{}, false
+	}
+	return *p, true
+}
+
+// Find looks a preset of an event type up by ID or, ignoring case, by name
+func (s *PresetStore) Find(eventType, nameOrID string) (models.OverridePreset, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if p, ok := s.presets[nameOrID]; ok && p.EventType == eventType {
+		return *p, true
+	}
+	for _, p := range s.presets {
+		if p.EventType == eventType && strings.EqualFold(p.Name, nameOrID) {
+			return *p, true
+		}
+	}
+	return models.OverridePreset{}, false
+}
+
+// List returns copies of all presets ordered by event type and name
+func (s *PresetStore) List() []models.OverridePreset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	presets := make([]models.OverridePreset, 0, len(s.presets))
+	for _, p := range s.presets {
+		presets = append(presets, *p)
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		if presets[i].EventType != presets[j].EventType {
+			return presets[i].EventType < presets[j].EventType
+		}
+		return presets[i].Name < presets[j].Name
+	})
+	return presets
+}
+
+// Set adds or replaces a preset, unless another preset of its event type
+// has the same name
+func (s *PresetStore) Set(preset models.OverridePreset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.presets {
+		if p.ID != preset.ID && p.EventType == preset.EventType && strings.EqualFold(p.Name, preset.Name) {
+			return fmt.Errorf("%s already has a preset named %q", preset.EventType, p.Name)
+		}
+	}
+	s.presets[preset.ID] = &preset
+	return nil
+}
+
+// Delete removes a preset
+func (s *PresetStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.presets[id]; !ok {
+		return false
+	}
+	delete(s.presets, id)
+	return true
+}
+
+// Global preset store
+var presetStore = NewPresetStore()
+
+// resolvePreset finds the preset a request names for a template
+func resolvePreset(eventType, templateID, name string) (models.OverridePreset, error) {
+	preset, ok := presetStore.Find(eventType, name)
+	if !ok {
+		return preset, fmt.Errorf("override preset %q not found for %s", name, eventType)
+	}
+	if preset.TemplateID != "" && preset.TemplateID != templateID {
+		return preset, fmt.Errorf("override preset %q is for template %s of %s", preset.Name, preset.TemplateID, eventType)
+	}
+	return preset, nil
+}
+
+// mergeOverrides returns the preset's overrides with overrides on top
+func mergeOverrides(preset, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(preset)+len(overrides))
+	for k, v := range preset {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// applyPreset merges the named preset under a request's overrides,
+// responding with 404 if there is no such preset for the template. It
+// returns false if a response was written.
+func applyPreset(c *gin.Context, eventType, templateID, name string, overrides *map[string]interface{}) bool {
+	if name == "" {
+		return true
+	}
+	preset, err := resolvePreset(eventType, templateID, name)
+	if err != nil {
+		respondError(c, models.CodePresetNotFound, err.Error())
+		return false
+	}
+	*overrides = mergeOverrides(preset.Overrides, *overrides)
+	return true
+}
+
+// resolveSourceOverrides merges the presets noise sources name under their
+// overrides, substitutes the active campaign's variables and checks the
+// result against the sources' templates. A template's preset can only be
+// used by a source of that template alone. It returns false if a response
+// was written.
+func resolveSourceOverrides(c *gin.Context, sources []models.EnabledEventSource) bool {
+	for i := range sources {
+		source := &sources[i]
+		if source.Preset == "" && len(source.Overrides) == 0 {
+			continue
+		}
+		gen, ok := generators.GetGenerator(source.EventTypeID)
+		if !ok {
+			continue // The generator skips unknown event types
+		}
+		templateIDs := source.TemplateIDs
+		if len(templateIDs) == 0 {
+			for _, t := range gen.GetTemplates() {
+				templateIDs = append(templateIDs, t.ID)
+			}
+		}
+
+		if source.Preset != "" {
+			preset, ok := presetStore.Find(source.EventTypeID, source.Preset)
+			if !ok {
+				respondError(c, models.CodeNotFound, fmt.Sprintf("override preset %q not found for %s", source.Preset, source.EventTypeID))
+				return false
+			}
+			if preset.TemplateID != "" && (len(templateIDs) != 1 || templateIDs[0] != preset.TemplateID) {
+				respondError(c, models.CodeValidationFailed, fmt.Sprintf("override preset %q is for template %s of %s; select only that template", preset.Name, preset.TemplateID, source.EventTypeID))
+				return false
+			}
+			source.Overrides = mergeOverrides(preset.Overrides, source.Overrides)
+		}
+		overrides, err := expandCampaignOverrides(source.Overrides)
+		if err != nil {
+			respondError(c, models.CodeValidationFailed, source.EventTypeID+": "+err.Error())
+			return false
+		}
+		source.Overrides = overrides
+
+		for _, templateID := range templateIDs {
+			if !checkOverrides(c, gen, templateID, source.Overrides, false) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkPreset checks a preset's event type and template and its overrides
+// against the templates it applies to, answering the request itself when
+// the preset is rejected
+func checkPreset(c *gin.Context, preset *models.OverridePreset) bool {
+	gen, ok := generators.GetGenerator(preset.EventType)
+	if !ok {
+		respondError(c, models.CodeValidationFailed, fmt.Sprintf("unknown event type %q", preset.EventType))
+		return false
+	}
+	templateIDs := []string{preset.TemplateID}
+	if preset.TemplateID == "" {
+		templateIDs = nil
+		for _, t := range gen.GetTemplates() {
+			templateIDs = append(templateIDs, t.ID)
+		}
+	} else if _, err := generators.CheckTemplate(gen, preset.TemplateID, 0); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return false
+	}
+	if len(preset.Overrides) == 0 {
+		respondError(c, models.CodeValidationFailed, "a preset needs at least one override")
+		return false
+	}
+	for _, templateID := range templateIDs {
+		if !checkOverrides(c, gen, templateID, preset.Overrides, false) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListPresets returns the override presets, narrowed by ?event_type= and
+// ?template_id=. Presets for a whole event type match every template.
+func ListPresets(c *gin.Context) {
+	eventType, templateID := c.Query("event_type"), c.Query("template_id")
+	presets := make([]models.OverridePreset, 0)
+	for _, p := range presetStore.List() {
+		if eventType != "" && p.EventType != eventType {
+			continue
+		}
+		if templateID != "" && p.TemplateID != "" && p.TemplateID != templateID {
+			continue
+		}
+		presets = append(presets, p)
+	}
+	presets, page, ok := paginate(c, presets, 0, "event_type", "template_id")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"presets": presets,
+		"count":   len(presets),
+	}))
+}
+
+// GetPreset returns a specific override preset
+func GetPreset(c *gin.Context) {
+	preset, ok := presetStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodePresetNotFound, "Preset not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, preset)
+}
+
+// CreatePreset adds an override preset
+func CreatePreset(c *gin.Context) {
+	var preset models.OverridePreset
+	if err := c.ShouldBindJSON(&preset); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	preset.ID = uuid.New().String()
+	preset.CreatedAt = time.Now()
+	preset.UpdatedAt = time.Now()
+	if !setPreset(c, preset) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, preset)
+}
+
+// UpdatePreset replaces an override preset. Streams already running keep
+// the overrides they started with.
+func UpdatePreset(c *gin.Context) {
+	id := c.Param("id")
+
+	existing, ok := presetStore.Get(id)
+	if !ok {
+		respondError(c, models.CodePresetNotFound, "Preset not found")
+		return
+	}
+
+	var preset models.OverridePreset
+	if err := c.ShouldBindJSON(&preset); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	preset.ID = id
+	preset.CreatedAt = existing.CreatedAt
+	preset.UpdatedAt = time.Now()
+	if !setPreset(c, preset) {
+		return
+	}
+
+	c.JSON(http.StatusOK, preset)
+}
+
+// setPreset checks a preset, stores it and saves the presets, answering
+// the request itself when the preset is rejected
+func setPreset(c *gin.Context, preset models.OverridePreset) bool {
+	if !checkPreset(c, &preset) {
+		return false
+	}
+	if err := presetStore.Set(preset); err != nil {
+		respondError(c, models.CodeConflict, err.Error())
+		return false
+	}
+	SavePresets()
+	return true
+}
+
+// DeletePreset removes an override preset
+func DeletePreset(c *gin.Context) {
+	if !presetStore.Delete(c.Param("id")) {
+		respondError(c, models.CodePresetNotFound, "Preset not found")
+		return
+	}
+	SavePresets()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Preset deleted",
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetProcessConfig returns the process command line settings
+func GetProcessConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, generators.ProcessConfig())
+}
+
+// UpdateProcessConfig sets the process command line settings
+func UpdateProcessConfig(c *gin.Context) {
+	var cfg models.ProcessConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.SetProcessConfig(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveProcessConfig()
+
+	c.JSON(http.StatusOK, cfg)
+}
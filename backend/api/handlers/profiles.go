@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+	"siem-event-generator/noise"
+)
+
+// ListUserProfiles returns the behavioral profile of every pool user
+func ListUserProfiles(c *gin.Context) {
+	profiles, page, ok := paginate(c, generators.UserProfiles(), 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"profiles": profiles,
+		"count":    len(profiles),
+	}))
+}
+
+// GetUserProfile returns the behavioral profile of one pool user
+func GetUserProfile(c *gin.Context) {
+	profile, ok := generators.UserProfileFor(c.Param("username"))
+	if !ok {
+		respondError(c, models.CodeNotFound, "User is not in the entity pool")
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// InjectAnomalies breaks a user's behavioral profile in the next events of
+// the running noise stream
+func InjectAnomalies(c *gin.Context) {
+	var req models.AnomalyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+	if coordinator != nil {
+		respondError(c, models.CodeClusterRole, "Anomalies can only be injected into a stream running on this instance")
+		return
+	}
+	if err := noise.GetInstance().InjectAnomaly(req); err != nil {
+		noiseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Anomalies queued",
+	})
+}
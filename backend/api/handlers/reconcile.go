@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/delivery"
+	"siem-event-generator/models"
+	"siem-event-generator/notify"
+	"siem-event-generator/storage"
+)
+
+// statsKindReconcile is the kind of the samples holding a run's
+// reconciliations, keyed by run ID; the newest one counts
+const statsKindReconcile = "reconcile"
+
+// Reconciliation timing
+const (
+	defaultReconcileDelay = 30 * time.Second
+	reconcileTimeout      = 5 * time.Minute
+)
+
+// runDeliveries lists a noise run's sends per destination in ID order
+func runDeliveries(byDestination map[string]models.DeliveryResult) []models.DeliveryResult {
+	deliveries := make([]models.DeliveryResult, 0, len(byDestination))
+	for id, d := range byDestination {
+		d.DestinationID = id
+		if d.Name == "" {
+			d.Name = destinationName(id)
+		}
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].DestinationID < deliveries[j].DestinationID })
+	return deliveries
+}
+
+// reconcileLater counts what a run's destinations indexed once they have
+// had time to index it, then records and reports the result
+func reconcileLater(run *models.RunRecord) {
+	delay := time.Duration(0)
+	for _, d := range run.Deliveries {
+		if dest, ok := destinationStore.Get(d.DestinationID); ok && dest.Config.Reconcile != nil {
+			wait := time.Duration(dest.Config.Reconcile.DelaySeconds) * time.Second
+			if wait == 0 {
+				wait = defaultReconcileDelay
+			}
+			delay = max(delay, wait)
+		}
+	}
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	defer cancel()
+	results := reconcileRun(ctx, run)
+	if err := recordReconciliations(run.ID, results); err != nil {
+		log.Printf("WARNING: failed to record reconciliation of run %s: %v", run.ID, err)
+	}
+}
+
+// reconcileRun counts the events each destination of run indexed since the
+// run started, logging and notifying drops
+func reconcileRun(ctx context.Context, run *models.RunRecord) []models.Reconciliation {
+	results := make([]models.Reconciliation, 0, len(run.Deliveries))
+	for _, d := range run.Deliveries {
+		r := models.Reconciliation{
+			RunID:         run.ID,
+			DestinationID: d.DestinationID,
+			Destination:   d.Name,
+			Expected:      d.EventsSent,
+			From:          run.StartedAt,
+		}
+		dest, ok := destinationStore.Get(d.DestinationID)
+		switch {
+		case !ok:
+			r.Status, r.Error = models.ReconcileFailed, "destination no longer exists"
+		case dest.Config.Reconcile == nil:
+			r.Status = models.ReconcileSkipped
+		default:
+			r.Destination = dest.Name
+			r.To = time.Now().UTC()
+			indexed, query, err := delivery.CountIndexed(ctx, dest, r.From, r.To)
+			r.Query = query
+			if err != nil {
+				r.Status, r.Error = models.ReconcileFailed, err.Error()
+			} else {
+				r.Indexed = indexed
+				r.Outcome()
+			}
+		}
+		r.CheckedAt = time.Now().UTC()
+		results = append(results, r)
+
+		switch r.Status {
+		case models.ReconcileDropped:
+			log.Printf("WARNING: %s indexed %d of %d events of run %s", r.Destination, r.Indexed, r.Expected, run.ID)
+			notifyEventsDropped(r)
+		case models.ReconcileFailed:
+			log.Printf("WARNING: failed to reconcile run %s with %s: %s", run.ID, r.Destination, r.Error)
+		}
+	}
+	return results
+}
+
+// notifyEventsDropped tells the notification channels that a destination
+// indexed fewer events than a run sent it
+func notifyEventsDropped(r models.Reconciliation) {
+	notify.Publish(models.Notification{
+		Event:         models.NotifyEventsDropped,
+		Summary:       fmt.Sprintf("%s indexed %d of %d events sent (%g%% missing)", r.Destination, r.Indexed, r.Expected, r.DropPercent),
+		DestinationID: r.DestinationID,
+		Destination:   r.Destination,
+		Details: map[string]interface{}{
+			"run_id":       r.RunID,
+			"expected":     r.Expected,
+			"indexed":      r.Indexed,
+			"missing":      r.Missing,
+			"drop_percent": r.DropPercent,
+		},
+		Time: r.CheckedAt,
+	})
+}
+
+// recordReconciliations stores a run's latest reconciliation
+func recordReconciliations(runID string, results []models.Reconciliation) error {
+	if store == nil {
+		return nil
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	return store.RecordStats(ctx, storage.Sample{Kind: statsKindReconcile, Key: runID, RecordedAt: time.Now().UTC(), Data: data})
+}
+
+// loadReconciliations returns the latest reconciliation of each run
+func loadReconciliations(ctx context.Context) (map[string][]models.Reconciliation, error) {
+	samples, err := store.Stats(ctx, statsKindReconcile, time.Now().Add(-statsRetention), maxRunScan)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string][]models.Reconciliation)
+	for _, sample := range samples {
+		if _, ok := latest[sample.Key]; ok {
+			continue
+		}
+		var results []models.Reconciliation
+		if err := json.Unmarshal(sample.Data, &results); err != nil {
+			return nil, fmt.Errorf("parse reconciliation of run %s: %w", sample.Key, err)
+		}
+		latest[sample.Key] = results
+	}
+	return latest, nil
+}
+
+// ReconcileRun counts now the events a recorded run's destinations indexed
+// and compares them with the events it sent them
+func ReconcileRun(c *gin.Context) {
+	run, ok := findRun(c)
+	if !ok {
+		return
+	}
+	if len(run.Deliveries) == 0 {
+		respondError(c, models.CodeValidationFailed, "run "+run.ID+" has no sends per destination to reconcile")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), reconcileTimeout)
+	defer cancel()
+	results := reconcileRun(ctx, run)
+	if err := recordReconciliations(run.ID, results); err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"run_id":          run.ID,
+		"reconciliations": results,
+	})
+}
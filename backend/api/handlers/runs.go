@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+	"siem-event-generator/storage"
+)
+
+// statsKindRun is the kind of the samples holding completed run records.
+// Like other statistics they are kept for statsRetention.
+const statsKindRun = "run"
+
+// maxRunScan bounds how many run records a lookup reads
+const maxRunScan = 10000
+
+// maxRunErrorSamples is how many error messages a run record keeps
+const maxRunErrorSamples = 5
+
+// newNoiseRun starts the record of a noise run from its first status.
+// Workers leave it to the coordinator, whose run it is.
+func newNoiseRun(status models.NoiseStatus, replayOf string) *models.RunRecord {
+	if clusterWorker != nil || status.CurrentConfig == nil {
+		return nil
+	}
+	config := *status.CurrentConfig
+	return &models.RunRecord{
+		ID:   uuid.New().String(),
+		Kind: models.RunKindNoise,
+		Noise: &models.NoiseStartRequest{
+			DestinationID:  config.DestinationID,
+			RatePerSecond:  config.RatePerSecond,
+			Workers:        config.Workers,
+			EnabledSources: config.EnabledSources,
+			Budget:         config.Budget,
+			Completion:     config.Completion,
+			Mirrors:        config.Mirrors,
+			Cardinality:    config.Cardinality,
+			TimestampFuzz:  config.TimestampFuzz,
+			ClockSkew:      config.ClockSkew,
+			OutOfOrder:     config.OutOfOrder,
+			Chaos:          config.Chaos,
+			Behavior:       config.Behavior,
+			Seed:           config.Seed,
+			Reconcile:      config.Reconcile,
+		},
+		Seed:      config.Seed,
+		ReplayOf:  replayOf,
+		StartedAt: status.StartedAt.UTC(),
+	}
+}
+
+// finishNoiseRun completes a noise run's record with its final status
+func finishNoiseRun(run *models.RunRecord, status models.NoiseStatus, now time.Time) {
+	run.StoppedAt = now.UTC()
+	run.DurationSeconds = run.StoppedAt.Sub(run.StartedAt).Seconds()
+	run.StopReason = status.StopReason
+	if run.StopReason == "" {
+		run.StopReason = "stopped"
+	}
+	run.EventsGenerated = status.Stats.TotalGenerated
+	run.EventsSent = status.Stats.TotalSent
+	run.Errors = status.Stats.TotalErrors
+	run.ErrorSamples = status.Stats.ErrorSamples
+	run.Deliveries = runDeliveries(status.Stats.ByDestination)
+}
+
+// recordBatchRun adds a finished generate request to the run history
+func recordBatchRun(req *models.GenerateRequest, startedAt time.Time, resp *models.GenerateResponse, replayOf string) {
+	batch := *req
+	batch.DryRun = false
+	now := time.Now().UTC()
+	run := &models.RunRecord{
+		ID:              uuid.New().String(),
+		Kind:            models.RunKindBatch,
+		Batch:           &batch,
+		Seed:            req.Seed,
+		ReplayOf:        replayOf,
+		StartedAt:       startedAt.UTC(),
+		StoppedAt:       now,
+		DurationSeconds: now.Sub(startedAt).Seconds(),
+		StopReason:      "completed",
+		EventsGenerated: int64(resp.EventsCreated),
+		EventsSent:      int64(resp.EventsSent),
+		Errors:          int64(len(resp.Errors)),
+	}
+	if len(resp.Errors) > 0 {
+		run.StopReason = "completed with errors"
+		run.ErrorSamples = resp.Errors[:min(len(resp.Errors), maxRunErrorSamples)]
+	}
+	run.Deliveries = resp.Deliveries
+	if destIDs := requestDestinations(req); len(run.Deliveries) == 0 && len(destIDs) == 1 {
+		run.Deliveries = []models.DeliveryResult{{
+			DestinationID: destIDs[0],
+			Name:          resp.Destination,
+			EventsSent:    int64(resp.EventsSent),
+			Errors:        run.Errors,
+		}}
+	}
+	recordRun(run)
+	if req.Reconcile {
+		go reconcileLater(run)
+	}
+}
+
+// recordRun stores a completed run, logging failures
+func recordRun(run *models.RunRecord) {
+	if store == nil {
+		return
+	}
+	data, err := json.Marshal(run)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		err = store.RecordStats(ctx, storage.Sample{Kind: statsKindRun, Key: run.ID, RecordedAt: run.StoppedAt, Data: data})
+	}
+	if err != nil {
+		log.Printf("WARNING: failed to record %s run: %v", run.Kind, err)
+	}
+}
+
+// loadRuns reads recorded runs, newest first and with their latest
+// reconciliation, calling fn with each until it returns false
+func loadRuns(ctx context.Context, fn func(run *models.RunRecord) bool) error {
+	samples, err := store.Stats(ctx, statsKindRun, time.Now().Add(-statsRetention), maxRunScan)
+	if err != nil {
+		return err
+	}
+	reconciliations, err := loadReconciliations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		var run models.RunRecord
+		if err := json.Unmarshal(sample.Data, &run); err != nil {
+			return fmt.Errorf("parse run %s: %w", sample.Key, err)
+		}
+		run.Reconciliations = reconciliations[run.ID]
+		if !fn(&run) {
+			break
+		}
+	}
+	return nil
+}
+
+// ListRuns returns completed noise runs and generate batches, newest
+// first, narrowed by ?kind= (noise or batch) and paged as paginate
+// describes, 100 to a page unless ?limit= is given
+func ListRuns(c *gin.Context) {
+	if store == nil {
+		respondError(c, models.CodeStorageUnavailable, "Storage is not configured")
+		return
+	}
+	kind := c.Query("kind")
+	if kind != "" && kind != models.RunKindNoise && kind != models.RunKindBatch {
+		respondError(c, models.CodeValidationFailed, "kind must be noise or batch")
+		return
+	}
+	runs := make([]*models.RunRecord, 0)
+	err := loadRuns(c.Request.Context(), func(run *models.RunRecord) bool {
+		if kind == "" || run.Kind == kind {
+			runs = append(runs, run)
+		}
+		return true
+	})
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+	runs, page, ok := paginate(c, runs, 100, "kind")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"runs":  runs,
+		"count": len(runs),
+	}))
+}
+
+// GetRun returns one recorded run
+func GetRun(c *gin.Context) {
+	run, ok := findRun(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// ReplayRun starts a recorded run's configuration again: a batch responds
+// like /api/generate and a noise run like /api/noise/start. With
+// "same_seed" the replay draws the same random values as the run did.
+func ReplayRun(c *gin.Context) {
+	var req models.ReplayRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+	run, ok := findRun(c)
+	if !ok {
+		return
+	}
+
+	seed := req.Seed
+	if req.SameSeed {
+		if run.Seed == 0 {
+			respondError(c, models.CodeValidationFailed, "run "+run.ID+" was not seeded; pass a seed to replay it with one")
+			return
+		}
+		seed = run.Seed
+	}
+
+	switch {
+	case run.Kind == models.RunKindBatch && run.Batch != nil:
+		batch := *run.Batch
+		batch.Seed = seed
+		generateEvents(c, &batch, run.ID)
+	case run.Kind == models.RunKindNoise && run.Noise != nil:
+		config := *run.Noise
+		config.Seed = seed
+		startNoise(c, &config, run.ID)
+	default:
+		respondError(c, models.CodeInternal, "run "+run.ID+" has no configuration to replay")
+	}
+}
+
+// findRun looks up the run named by the :id parameter, responding with an
+// error if there is none
+func findRun(c *gin.Context) (*models.RunRecord, bool) {
+	if store == nil {
+		respondError(c, models.CodeStorageUnavailable, "Storage is not configured")
+		return nil, false
+	}
+	id := c.Param("id")
+	var found *models.RunRecord
+	err := loadRuns(c.Request.Context(), func(run *models.RunRecord) bool {
+		if run.ID == id {
+			found = run
+		}
+		return found == nil
+	})
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return nil, false
+	}
+	if found == nil {
+		respondError(c, models.CodeRunNotFound, "Run not found")
+		return nil, false
+	}
+	return found, true
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// ScenarioStore provides thread-safe metric scenario storage
+type ScenarioStore struct {
+	mu        sync.RWMutex
+	scenarios map[string]*models.Scenario
+}
+
+// NewScenarioStore creates a new scenario store
+func NewScenarioStore() *ScenarioStore {
+	return &ScenarioStore{
+		scenarios: make(map[string]*models.Scenario),
+	}
+}
+
+// Get retrieves a scenario by ID
+func (s *ScenarioStore) Get(id string) (*models.Scenario, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	scenario, ok := s.scenarios[id]
+	return scenario, ok
+}
+
+// List returns all scenarios ordered by name
+func (s *ScenarioStore) List() []*models.Scenario {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	scenarios := make([]*models.Scenario, 0, len(s.scenarios))
+	for _, sc := range s.scenarios {
+		scenarios = append(scenarios, sc)
+	}
+	sort.Slice(scenarios, func(i, j int) bool { return scenarios[i].Name < scenarios[j].Name })
+	return scenarios
+}
+
+// Create adds a new scenario
+func (s *ScenarioStore) Create(scenario *models.Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios[scenario.ID] = scenario
+}
+
+// Update modifies an existing scenario
+func (s *ScenarioStore) Update(scenario *models.Scenario) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.scenarios[scenario.ID]; !ok {
+		return false
+	}
+	s.scenarios[scenario.ID] = scenario
+	return true
+}
+
+// Delete removes a scenario
+func (s *ScenarioStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.scenarios[id]; !ok {
+		return false
+	}
+	delete(s.scenarios, id)
+	return true
+}
+
+// Global scenario store
+var scenarioStore = NewScenarioStore()
+
+// withStatus pairs a scenario with its current phase in the metric engine
+func withStatus(scenario *models.Scenario) models.ScenarioWithStatus {
+	return models.ScenarioWithStatus{
+		Scenario: *scenario,
+		Status:   generators.Scenarios.Status(scenario.ID, time.Now()),
+	}
+}
+
+// ListScenarios returns the metric scenarios and their status, paged as
+// paginate describes
+func ListScenarios(c *gin.Context) {
+	scenarios := make([]models.ScenarioWithStatus, 0)
+	for _, sc := range scenarioStore.List() {
+		scenarios = append(scenarios, withStatus(sc))
+	}
+	scenarios, page, ok := paginate(c, scenarios, 0)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"scenarios": scenarios,
+		"count":     len(scenarios),
+	}))
+}
+
+// GetScenario returns a specific scenario and its status
+func GetScenario(c *gin.Context) {
+	scenario, ok := scenarioStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeScenarioNotFound, "Scenario not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, withStatus(scenario))
+}
+
+// CreateScenario creates a new metric scenario
+func CreateScenario(c *gin.Context) {
+	var scenario models.Scenario
+	if err := c.ShouldBindJSON(&scenario); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.ValidateScenario(&scenario); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	scenario.ID = uuid.New().String()
+	scenario.CreatedAt = time.Now()
+	scenario.UpdatedAt = time.Now()
+
+	scenarioStore.Create(&scenario)
+	SaveScenarios()
+
+	c.JSON(http.StatusCreated, withStatus(&scenario))
+}
+
+// UpdateScenario updates a scenario; a running scenario keeps its timeline
+func UpdateScenario(c *gin.Context) {
+	id := c.Param("id")
+
+	existing, ok := scenarioStore.Get(id)
+	if !ok {
+		respondError(c, models.CodeScenarioNotFound, "Scenario not found")
+		return
+	}
+
+	var scenario models.Scenario
+	if err := c.ShouldBindJSON(&scenario); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.ValidateScenario(&scenario); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	scenario.ID = id
+	scenario.CreatedAt = existing.CreatedAt
+	scenario.UpdatedAt = time.Now()
+
+	scenarioStore.Update(&scenario)
+	generators.Scenarios.Update(runningScenario(scenario))
+	SaveScenarios()
+
+	c.JSON(http.StatusOK, withStatus(&scenario))
+}
+
+// DeleteScenario stops and removes a scenario
+func DeleteScenario(c *gin.Context) {
+	id := c.Param("id")
+
+	if !scenarioStore.Delete(id) {
+		respondError(c, models.CodeScenarioNotFound, "Scenario not found")
+		return
+	}
+	generators.Scenarios.Stop(id)
+	cancelDetectionCheck(id)
+	SaveScenarios()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scenario deleted",
+	})
+}
+
+// TriggerScenario starts a scenario's timeline, now or at the requested
+// time, and schedules the check of its expected detections
+func TriggerScenario(c *gin.Context) {
+	scenario, ok := scenarioStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeScenarioNotFound, "Scenario not found")
+		return
+	}
+
+	var req models.TriggerScenarioRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	resolved, err := campaignScenario(*scenario)
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	at := time.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+	generators.Scenarios.Trigger(resolved, at)
+	scheduleDetectionCheck(scenario, generators.Scenarios.Status(scenario.ID, time.Now()))
+	saveStreamState()
+
+	c.JSON(http.StatusOK, withStatus(scenario))
+}
+
+// StopScenario ends a running scenario, returning its metric to normal
+func StopScenario(c *gin.Context) {
+	scenario, ok := scenarioStore.Get(c.Param("id"))
+	if !ok {
+		respondError(c, models.CodeScenarioNotFound, "Scenario not found")
+		return
+	}
+
+	if !generators.Scenarios.Stop(scenario.ID) {
+		respondError(c, models.CodeScenarioNotRunning, "Scenario is not running")
+		return
+	}
+	cancelDetectionCheck(scenario.ID)
+	saveStreamState()
+
+	c.JSON(http.StatusOK, withStatus(scenario))
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/delivery"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// Sigma generation limits
+const (
+	maxSigmaRule       = 1 << 20
+	maxSigmaEvents     = 1000
+	defaultSigmaEvents = 5
+)
+
+// GenerateFromSigma generates events a Sigma rule should match and near
+// misses it should not. The body is a SigmaRequest, or the rule itself as
+// YAML or the multipart field "file", with ?count=, ?negatives=,
+// ?event_type=, ?event_id=, ?destination_id= and ?output= as options.
+func GenerateFromSigma(c *gin.Context) {
+	req, ok := bindSigmaRequest(c)
+	if !ok {
+		return
+	}
+	if req.Count == 0 {
+		req.Count = defaultSigmaEvents
+	}
+	if req.Negatives == 0 {
+		req.Negatives = req.Count
+	}
+	if req.Count < 1 || req.Count > maxSigmaEvents || req.Negatives < 1 || req.Negatives > maxSigmaEvents {
+		respondError(c, models.CodeValidationFailed, "count and negatives must be between 1 and 1000")
+		return
+	}
+	if err := generators.ValidateOutput(req.Output); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+
+	rule, err := generators.ParseSigmaRule([]byte(req.Rule))
+	if err != nil {
+		respondError(c, models.CodeInvalidSigmaRule, err.Error())
+		return
+	}
+	gen, err := generators.NewSigmaGenerator(rule, req.EventType, req.EventID, req.FieldMap)
+	if err != nil {
+		respondError(c, models.CodeInvalidSigmaRule, err.Error())
+		return
+	}
+
+	var dest *models.Destination
+	if req.DestinationID != "" {
+		d, ok := destinationStore.Get(req.DestinationID)
+		if !ok {
+			respondError(c, models.CodeDestinationNotFound, "Destination not found")
+			return
+		}
+		dest = d
+	}
+
+	resp := models.SigmaResponse{
+		Rule:      rule.Info(),
+		EventType: gen.EventType(),
+		Positives: make([]models.SigmaSample, 0, req.Count),
+		Negatives: make([]models.SigmaSample, 0, req.Negatives),
+	}
+	var misses, hits int
+	for i := 0; i < req.Count+req.Negatives; i++ {
+		positive := i < req.Count
+		sample, err := gen.Generate(positive)
+		if err != nil {
+			respondError(c, models.CodeInvalidSigmaRule, err.Error())
+			return
+		}
+		if positive {
+			if !sample.Matches {
+				misses++
+			}
+			resp.Positives = append(resp.Positives, sample)
+		} else {
+			if sample.Matches {
+				hits++
+			}
+			resp.Negatives = append(resp.Negatives, sample)
+		}
+	}
+	resp.FieldMap = gen.FieldMap()
+
+	if misses > 0 {
+		resp.Warnings = append(resp.Warnings, strconv.Itoa(misses)+" positive events do not match the rule")
+	}
+	if hits > 0 {
+		resp.Warnings = append(resp.Warnings, strconv.Itoa(hits)+" negative events match the rule")
+	}
+	if unknown := gen.UnknownFields(); len(unknown) > 0 {
+		resp.Warnings = append(resp.Warnings, "the template does not emit "+strings.Join(unknown, ", ")+"; added as new fields, or map them with field_map")
+	}
+
+	if dest != nil {
+		resp.Destination = dest.Name
+		sender, err := delivery.GetSender(dest)
+		if err != nil {
+			resp.Errors = append(resp.Errors, "Failed to create sender: "+err.Error())
+		} else {
+			for _, samples := range [][]models.SigmaSample{resp.Positives, resp.Negatives} {
+				for i := range samples {
+					if err := sender.Send(&samples[i].Event); err != nil {
+						resp.Errors = append(resp.Errors, "Send error: "+err.Error())
+					} else {
+						resp.EventsSent++
+					}
+				}
+			}
+			if err := sender.Close(); err != nil {
+				resp.Errors = append(resp.Errors, "Close error: "+err.Error())
+			}
+		}
+	}
+
+	for _, samples := range [][]models.SigmaSample{resp.Positives, resp.Negatives} {
+		for i := range samples {
+			samples[i].Event = *generators.ApplyOutput(&samples[i].Event, req.Output)
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// bindSigmaRequest reads a SigmaRequest body, or an uploaded rule with its
+// options in the query
+func bindSigmaRequest(c *gin.Context) (models.SigmaRequest, bool) {
+	var req models.SigmaRequest
+	if c.ContentType() == "application/json" {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return req, false
+		}
+		return req, true
+	}
+
+	body := io.Reader(c.Request.Body)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			respondError(c, models.CodeValidationFailed, "multipart upload must include a \"file\" field")
+			return req, false
+		}
+		defer file.Close()
+		body = file
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxSigmaRule))
+	if err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return req, false
+	}
+	req.Rule = string(data)
+	req.EventType = c.Query("event_type")
+	req.EventID = c.Query("event_id")
+	req.DestinationID = c.Query("destination_id")
+	req.Output = c.Query("output")
+	for name, dst := range map[string]*int{"count": &req.Count, "negatives": &req.Negatives} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				respondError(c, models.CodeValidationFailed, name+" must be a number")
+				return req, false
+			}
+			*dst = n
+		}
+	}
+	if strings.TrimSpace(req.Rule) == "" {
+		respondError(c, models.CodeValidationFailed, "rule is empty")
+		return req, false
+	}
+	return req, true
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"siem-event-generator/cluster"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+	"siem-event-generator/noise"
+	"siem-event-generator/storage"
+)
+
+// streamState is what was running when it was saved; a restart resumes it
+type streamState struct {
+	Noise     *noiseState    `json:"noise,omitempty"`
+	Scenarios []triggerState `json:"scenarios,omitempty"`
+	SavedAt   time.Time      `json:"saved_at"`
+}
+
+// noiseState is a running noise run with its progress
+type noiseState struct {
+	Config models.NoiseConfig `json:"config"`
+	Status models.NoiseStatus `json:"status"`
+}
+
+// triggerState is a triggered scenario; its position follows from the
+// trigger time
+type triggerState struct {
+	ID          string    `json:"id"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+var streams struct {
+	mu     sync.Mutex
+	closed bool // Set at shutdown, after the final save
+}
+
+// currentStreamState captures the running noise run and scenarios. Workers
+// leave their shard out; the coordinator resumes the run.
+func currentStreamState() streamState {
+	now := time.Now()
+	state := streamState{SavedAt: now}
+	if clusterWorker == nil {
+		if status := noiseStatus(); status.Running && status.CurrentConfig != nil {
+			state.Noise = &noiseState{Config: *status.CurrentConfig, Status: status}
+			state.Noise.Status.CurrentConfig = nil
+		}
+	}
+	for id, at := range generators.Scenarios.Triggered(now) {
+		state.Scenarios = append(state.Scenarios, triggerState{ID: id, TriggeredAt: at})
+	}
+	return state
+}
+
+// saveStreamState checkpoints what is running. It is called when a run or
+// scenario starts or stops and every few seconds, so progress survives a
+// crash as well as a shutdown.
+func saveStreamState() {
+	streams.mu.Lock()
+	defer streams.mu.Unlock()
+	if streams.closed {
+		return
+	}
+	writeStreamState(currentStreamState())
+}
+
+func writeStreamState(state streamState) {
+	if store == nil {
+		return
+	}
+	data, err := json.Marshal(state)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		err = store.SetMeta(ctx, storage.MetaStreams, string(data))
+	}
+	if err != nil {
+		log.Printf("WARNING: failed to save stream state: %v", err)
+	}
+}
+
+// ResumeStreams restarts the noise run and scenarios that were running when
+// the server last stopped. A coordinator waits for its workers to register
+// again before resuming.
+func ResumeStreams() {
+	if store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	value, ok, err := store.Meta(ctx, storage.MetaStreams)
+	if err != nil || !ok {
+		if err != nil {
+			log.Printf("WARNING: failed to load stream state: %v", err)
+		}
+		return
+	}
+	var state streamState
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		log.Printf("WARNING: failed to parse stream state: %v", err)
+		return
+	}
+
+	for _, t := range state.Scenarios {
+		scenario, ok := scenarioStore.Get(t.ID)
+		if !ok {
+			continue
+		}
+		generators.Scenarios.Trigger(runningScenario(*scenario), t.TriggeredAt)
+		scheduleDetectionCheck(scenario, generators.Scenarios.Status(scenario.ID, time.Now()))
+		log.Printf("Resumed scenario %s", scenario.Name)
+	}
+
+	if state.Noise == nil || clusterWorker != nil {
+		return
+	}
+	if coordinator != nil {
+		go func() {
+			time.Sleep(2 * cluster.HeartbeatInterval)
+			resumeNoise(state.Noise)
+		}()
+		return
+	}
+	resumeNoise(state.Noise)
+}
+
+// resumeNoise restarts a saved noise run. A distributed run starts over
+// across the workers registered now; a local one carries on its statistics
+// and budget.
+func resumeNoise(saved *noiseState) {
+	destinations := make(map[string]*models.Destination)
+	ids := append([]string{saved.Config.DestinationID}, saved.Config.Mirrors...)
+	for _, source := range saved.Config.EnabledSources {
+		if source.Enabled {
+			ids = append(ids, source.DestinationID)
+		}
+	}
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		dest, ok := destinationStore.Get(id)
+		if !ok {
+			log.Printf("WARNING: not resuming noise generation: destination %s no longer exists", id)
+			return
+		}
+		destinations[id] = dest
+	}
+
+	config := saved.Config
+	var err error
+	if coordinator != nil {
+		err = coordinator.Start(&config, destinations, routeTargets(destinations))
+	} else {
+		err = noise.GetInstance().Resume(&config, destinations, saved.Status)
+	}
+	if err != nil {
+		log.Printf("WARNING: failed to resume noise generation: %v", err)
+		return
+	}
+	noiseStats.check()
+	saveStreamState()
+	log.Printf("Resumed noise generation at %g events/s", config.RatePerSecond)
+}
+
+// Shutdown stops the running noise run after saving it for the next start,
+// waits for in-flight events to be flushed and closes the store. Call it
+// once the HTTP server has stopped taking requests.
+func Shutdown() {
+	streams.mu.Lock()
+	state := currentStreamState()
+	streams.closed = true
+	streams.mu.Unlock()
+
+	var err error
+	switch {
+	case coordinator != nil:
+		err = coordinator.Stop()
+	case noise.GetInstance().IsRunning():
+		err = noise.GetInstance().Stop()
+	}
+	if err != nil && state.Noise != nil {
+		log.Printf("WARNING: failed to stop noise generation: %v", err)
+	}
+
+	// Save the counts as of the last flushed event
+	if state.Noise != nil {
+		final := noiseStatus()
+		state.Noise.Status.Stats = final.Stats
+		log.Printf("Noise generation paused after %d events; it resumes on the next start", final.Stats.TotalSent)
+	}
+	writeStreamState(state)
+	noiseStats.check()
+
+	if store != nil {
+		if err := store.Close(); err != nil {
+			log.Printf("WARNING: failed to close storage: %v", err)
+		}
+	}
+}
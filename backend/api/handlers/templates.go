@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -84,14 +85,15 @@ type TemplateWithMetadata struct {
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
 }
 
-// ListTemplates returns all templates (builtin + custom)
+// ListTemplates returns all templates (builtin + custom), narrowed by
+// ?category= and paged as paginate describes
 func ListTemplates(c *gin.Context) {
 	category := c.Query("category")
 
 	templates := make([]TemplateWithMetadata, 0)
 
 	// Add builtin templates from generators
-	for _, gen := range generators.Registry {
+	for _, gen := range generators.Generators() {
 		for _, tmpl := range gen.GetTemplates() {
 			if category != "" && tmpl.Category != category {
 				continue
@@ -114,10 +116,14 @@ func ListTemplates(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	templates, page, ok := paginate(c, templates, 0, "category")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
 		"templates": templates,
 		"count":     len(templates),
-	})
+	}))
 }
 
 // GetTemplate returns a specific template
@@ -134,7 +140,7 @@ func GetTemplate(c *gin.Context) {
 	}
 
 	// Check builtin templates
-	for _, gen := range generators.Registry {
+	for _, gen := range generators.Generators() {
 		for _, tmpl := range gen.GetTemplates() {
 			if tmpl.ID == id {
 				c.JSON(http.StatusOK, TemplateWithMetadata{
@@ -146,22 +152,69 @@ func GetTemplate(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusNotFound, gin.H{
-		"error": "Template not found",
-	})
+	respondError(c, models.CodeTemplateNotFound, "Template not found")
+}
+
+// GetTemplateSchema returns the fields a template emits, their types, example
+// values and whether they accept overrides. Template IDs are only unique within
+// an event type, so ?event_type= can be used to disambiguate builtin templates.
+func GetTemplateSchema(c *gin.Context) {
+	id := c.Param("id")
+
+	if tmpl, ok := templateStore.Get(id); ok {
+		c.JSON(http.StatusOK, customTemplateSchema(tmpl))
+		return
+	}
+
+	gen, tmpl, ok := generators.FindTemplate(c.Query("event_type"), id)
+	if !ok {
+		respondError(c, models.CodeTemplateNotFound, "Template not found")
+		return
+	}
+
+	schema, err := generators.InferSchema(gen, tmpl.ID)
+	if err != nil {
+		respondError(c, models.CodeInternal, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// customTemplateSchema builds a schema from a custom template's declared fields
+func customTemplateSchema(tmpl *models.EventTemplate) models.TemplateSchema {
+	fields := make([]models.FieldSchema, 0, len(tmpl.Fields))
+	for _, f := range tmpl.Fields {
+		fields = append(fields, models.FieldSchema{
+			Name:        f.Name,
+			Type:        f.Type,
+			Example:     f.Default,
+			Description: f.Description,
+			Overridable: true,
+		})
+	}
+	return models.TemplateSchema{
+		TemplateID: tmpl.ID,
+		Name:       tmpl.Name,
+		Format:     tmpl.Format,
+		Sourcetype: tmpl.Sourcetype,
+		Source:     "custom",
+		Fields:     fields,
+
+		SchemaVersion: tmpl.SchemaVersion,
+	}
 }
 
 // CreateTemplate creates a new custom template
 func CreateTemplate(c *gin.Context) {
 	var tmpl models.EventTemplate
 	if err := c.ShouldBindJSON(&tmpl); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
 	tmpl.ID = "custom-" + uuid.New().String()
+	tmpl.SchemaVersion = 1
 
 	templateStore.Create(&tmpl)
 	SaveTemplates()
@@ -179,33 +232,32 @@ func UpdateTemplate(c *gin.Context) {
 	id := c.Param("id")
 
 	// Check if it's a builtin template
-	for _, gen := range generators.Registry {
+	for _, gen := range generators.Generators() {
 		for _, tmpl := range gen.GetTemplates() {
 			if tmpl.ID == id {
-				c.JSON(http.StatusForbidden, gin.H{
-					"error": "Cannot modify builtin templates",
-				})
+				respondError(c, models.CodeBuiltinTemplate, "Cannot modify builtin templates")
 				return
 			}
 		}
 	}
 
-	if _, ok := templateStore.Get(id); !ok {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Template not found",
-		})
+	existing, ok := templateStore.Get(id)
+	if !ok {
+		respondError(c, models.CodeTemplateNotFound, "Template not found")
 		return
 	}
 
 	var tmpl models.EventTemplate
 	if err := c.ShouldBindJSON(&tmpl); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
 	tmpl.ID = id
+	tmpl.SchemaVersion = existing.SchemaVersion
+	if templateOutputChanged(existing, &tmpl) {
+		tmpl.SchemaVersion++
+	}
 	templateStore.Update(&tmpl)
 	SaveTemplates()
 
@@ -216,26 +268,65 @@ func UpdateTemplate(c *gin.Context) {
 	})
 }
 
+// templateOutputChanged reports whether an update changes what a custom
+// template generates, which raises its schema version
+func templateOutputChanged(before, after *models.EventTemplate) bool {
+	return before.Format != after.Format || before.Sourcetype != after.Sourcetype ||
+		before.OutputTemplate != after.OutputTemplate || !sameJSON(before.Fields, after.Fields)
+}
+
+// GetTemplateChangelog lists how built-in templates' output changed, for an
+// event type or one template, optionally only the versions after ?since=
+func GetTemplateChangelog(c *gin.Context) {
+	eventType, templateID := c.Query("event_type"), c.Query("template_id")
+	if eventType != "" {
+		gen, ok := generators.GetGenerator(eventType)
+		if !ok {
+			respondError(c, models.CodeEventTypeNotFound, "Event type not found")
+			return
+		}
+		if templateID != "" {
+			if _, err := generators.ResolveTemplateID(gen, templateID); err != nil {
+				respondError(c, models.CodeTemplateNotFound, err.Error())
+				return
+			}
+		}
+	}
+	since := 0
+	if s := c.Query("since"); s != "" {
+		var err error
+		if since, err = strconv.Atoi(s); err != nil || since < 0 {
+			respondError(c, models.CodeValidationFailed, "since must be a schema version")
+			return
+		}
+	}
+
+	changes, page, ok := paginate(c, generators.TemplateChanges(eventType, templateID, since), 0, "event_type", "template_id", "since")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, page.into(gin.H{
+		"changes": changes,
+		"count":   len(changes),
+	}))
+}
+
 // DeleteTemplate removes a custom template
 func DeleteTemplate(c *gin.Context) {
 	id := c.Param("id")
 
 	// Check if it's a builtin template
-	for _, gen := range generators.Registry {
+	for _, gen := range generators.Generators() {
 		for _, tmpl := range gen.GetTemplates() {
 			if tmpl.ID == id {
-				c.JSON(http.StatusForbidden, gin.H{
-					"error": "Cannot delete builtin templates",
-				})
+				respondError(c, models.CodeBuiltinTemplate, "Cannot delete builtin templates")
 				return
 			}
 		}
 	}
 
 	if !templateStore.Delete(id) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Template not found",
-		})
+		respondError(c, models.CodeTemplateNotFound, "Template not found")
 		return
 	}
 	SaveTemplates()
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetTheme returns the naming convention of the simulated organization
+func GetTheme(c *gin.Context) {
+	c.JSON(http.StatusOK, generators.Theme())
+}
+
+// UpdateTheme sets the prefix and domains hostnames, accounts, sites and
+// buckets are named with
+func UpdateTheme(c *gin.Context) {
+	var theme models.Theme
+	if err := c.ShouldBindJSON(&theme); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.SetTheme(theme); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveTheme()
+
+	c.JSON(http.StatusOK, generators.Theme())
+}
+
+// ResetTheme goes back to the example.com organization
+func ResetTheme(c *gin.Context) {
+	generators.SetTheme(generators.DefaultTheme())
+	SaveTheme()
+
+	c.JSON(http.StatusOK, generators.Theme())
+}
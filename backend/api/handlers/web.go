@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// GetWebConfig returns the web request settings
+func GetWebConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, generators.WebConfig())
+}
+
+// UpdateWebConfig sets the web request settings
+func UpdateWebConfig(c *gin.Context) {
+	var cfg models.WebConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		bindError(c, err)
+		return
+	}
+	if err := generators.SetWebConfig(cfg); err != nil {
+		respondError(c, models.CodeValidationFailed, err.Error())
+		return
+	}
+	SaveWebConfig()
+
+	c.JSON(http.StatusOK, cfg)
+}
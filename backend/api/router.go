@@ -15,8 +15,10 @@ func SetupRouter() *gin.Engine {
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:3000", "http://localhost:5173"}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-User", handlers.IdempotencyKeyHeader}
+	config.ExposeHeaders = []string{handlers.IdempotentReplayedHeader}
 	router.Use(cors.New(config))
+	router.NoRoute(handlers.NoRoute)
 
 	// API routes
 	api := router.Group("/api")
@@ -24,13 +26,26 @@ func SetupRouter() *gin.Engine {
 		// Health check
 		api.GET("/health", handlers.HealthCheck)
 
+		// Error codes
+		api.GET("/errors", handlers.GetErrorCatalog)
+
 		// Event types
 		api.GET("/event-types", handlers.ListEventTypes)
 		api.GET("/event-types/:type/schema", handlers.GetEventTypeSchema)
 
 		// Event generation
-		api.POST("/generate", handlers.GenerateEvents)
+		api.POST("/generate", handlers.Idempotent, handlers.GenerateEvents)
 		api.POST("/generate/preview", handlers.PreviewEvent)
+		api.POST("/generate/preview/diff", handlers.PreviewEventDiff)
+		api.GET("/samples", handlers.GetSamples)
+		api.POST("/incidents", handlers.Idempotent, handlers.GenerateIncident)
+		api.POST("/lifecycle", handlers.Idempotent, handlers.GenerateLifecycle)
+		api.POST("/sigma/generate", handlers.Idempotent, handlers.GenerateFromSigma)
+
+		// Output regression corpus
+		api.GET("/corpus", handlers.GetCorpus)
+		api.POST("/corpus/snapshot", handlers.SnapshotCorpus)
+		api.POST("/corpus/diff", handlers.DiffCorpus)
 
 		// Destinations
 		api.GET("/destinations", handlers.ListDestinations)
@@ -39,24 +54,177 @@ func SetupRouter() *gin.Engine {
 		api.PUT("/destinations/:id", handlers.UpdateDestination)
 		api.DELETE("/destinations/:id", handlers.DeleteDestination)
 		api.POST("/destinations/:id/test", handlers.TestDestination)
+		api.GET("/destinations/:id/stats", handlers.GetDestinationStats)
 		api.POST("/destinations/test", handlers.TestDestinationConfig)
 
 		// Templates
 		api.GET("/templates", handlers.ListTemplates)
+		api.GET("/catalog", handlers.GetCatalog)
+		api.GET("/templates/changelog", handlers.GetTemplateChangelog)
 		api.GET("/templates/:id", handlers.GetTemplate)
+		api.GET("/templates/:id/schema", handlers.GetTemplateSchema)
 		api.POST("/templates", handlers.CreateTemplate)
 		api.PUT("/templates/:id", handlers.UpdateTemplate)
 		api.DELETE("/templates/:id", handlers.DeleteTemplate)
 
+		// Campaigns, whose variables requests refer to as ${campaign.name}
+		api.GET("/campaigns", handlers.ListCampaigns)
+		api.POST("/campaigns", handlers.CreateCampaign)
+		api.GET("/campaigns/active", handlers.GetActiveCampaign)
+		api.GET("/campaigns/:id", handlers.GetCampaign)
+		api.PUT("/campaigns/:id", handlers.UpdateCampaign)
+		api.DELETE("/campaigns/:id", handlers.DeleteCampaign)
+		api.POST("/campaigns/:id/activate", handlers.ActivateCampaign)
+		api.POST("/campaigns/:id/deactivate", handlers.DeactivateCampaign)
+
+		// Override presets, referenced by name in generate and noise requests
+		api.GET("/presets", handlers.ListPresets)
+		api.POST("/presets", handlers.CreatePreset)
+		api.GET("/presets/:id", handlers.GetPreset)
+		api.PUT("/presets/:id", handlers.UpdatePreset)
+		api.DELETE("/presets/:id", handlers.DeletePreset)
+
+		// Favorites and recently used templates and destinations, per X-User
+		api.GET("/favorites", handlers.GetFavorites)
+		api.PUT("/favorites/templates/:event_type", handlers.AddFavoriteTemplate)
+		api.PUT("/favorites/templates/:event_type/:template_id", handlers.AddFavoriteTemplate)
+		api.DELETE("/favorites/templates/:event_type", handlers.RemoveFavoriteTemplate)
+		api.DELETE("/favorites/templates/:event_type/:template_id", handlers.RemoveFavoriteTemplate)
+		api.PUT("/favorites/destinations/:id", handlers.AddFavoriteDestination)
+		api.DELETE("/favorites/destinations/:id", handlers.RemoveFavoriteDestination)
+		api.GET("/recent", handlers.GetRecent)
+		api.DELETE("/recent", handlers.ClearRecent)
+
+		// Metric scenarios
+		api.GET("/scenarios", handlers.ListScenarios)
+		api.POST("/scenarios", handlers.CreateScenario)
+		api.GET("/scenarios/:id", handlers.GetScenario)
+		api.PUT("/scenarios/:id", handlers.UpdateScenario)
+		api.DELETE("/scenarios/:id", handlers.DeleteScenario)
+		api.POST("/scenarios/:id/trigger", handlers.Idempotent, handlers.TriggerScenario)
+		api.POST("/scenarios/:id/stop", handlers.StopScenario)
+		api.GET("/scenarios/:id/detections", handlers.GetScenarioDetections)
+		api.POST("/scenarios/:id/detections/check", handlers.CheckScenarioDetections)
+		api.GET("/detections/backends", handlers.GetDetectionBackends)
+		api.PUT("/detections/backends", handlers.UpdateDetectionBackends)
+
+		// GeoIP
+		api.GET("/geoip", handlers.GetGeoIP)
+		api.PUT("/geoip/policy", handlers.UpdateGeoPolicy)
+		api.GET("/geoip/lookup/:ip", handlers.LookupGeoIP)
+
+		// Behavioral baselines of pool users
+		api.GET("/profiles", handlers.ListUserProfiles)
+		api.GET("/profiles/:username", handlers.GetUserProfile)
+
+		// Naming convention of the simulated organization
+		api.GET("/theme", handlers.GetTheme)
+		api.PUT("/theme", handlers.UpdateTheme)
+		api.DELETE("/theme", handlers.ResetTheme)
+
+		// Names of made-up users
+		api.GET("/names", handlers.GetNames)
+		api.PUT("/names/profile", handlers.UpdateNameProfile)
+		api.GET("/names/sample", handlers.GetNameSample)
+
+		// Synthetic files and their hashes
+		api.GET("/files", handlers.ListFiles)
+		api.GET("/files/:name", handlers.GetFile)
+
+		// Threat intel indicators
+		api.GET("/iocs", handlers.ListIOCs)
+		api.POST("/iocs", handlers.AddIOCs)
+		api.POST("/iocs/upload", handlers.UploadIOCs)
+		api.DELETE("/iocs", handlers.DeleteIOCs)
+		api.GET("/iocs/config", handlers.GetIOCConfig)
+		api.PUT("/iocs/config", handlers.UpdateIOCConfig)
+		api.GET("/iocs/feeds", handlers.ListIOCFeeds)
+		api.POST("/iocs/feeds", handlers.CreateIOCFeed)
+		api.DELETE("/iocs/feeds/:id", handlers.DeleteIOCFeed)
+		api.POST("/iocs/feeds/:id/poll", handlers.PollIOCFeed)
+
+		// CloudTrail outcomes
+		api.GET("/cloudtrail/config", handlers.GetCloudTrailConfig)
+		api.PUT("/cloudtrail/config", handlers.UpdateCloudTrailConfig)
+
+		// Server certificates in TLS events
+		api.GET("/pki/config", handlers.GetPKIConfig)
+		api.PUT("/pki/config", handlers.UpdatePKIConfig)
+		api.GET("/processes/config", handlers.GetProcessConfig)
+		api.PUT("/processes/config", handlers.UpdateProcessConfig)
+		api.GET("/web/config", handlers.GetWebConfig)
+		api.PUT("/web/config", handlers.UpdateWebConfig)
+
+		// Sensitive field anonymization
+		api.GET("/anonymization", handlers.GetAnonymization)
+		api.PUT("/anonymization", handlers.UpdateAnonymization)
+		api.POST("/anonymization/preview", handlers.PreviewAnonymization)
+		api.GET("/anonymization/tokens/:token", handlers.Detokenize)
+
+		// Generator plugins
+		api.GET("/plugins", handlers.ListPlugins)
+		api.POST("/plugins", handlers.LoadPlugin)
+		api.POST("/plugins/scan", handlers.ScanPlugins)
+		api.POST("/plugins/:id/reload", handlers.ReloadPlugin)
+		api.DELETE("/plugins/:id", handlers.UnloadPlugin)
+
+		// Event hooks
+		api.GET("/hooks", handlers.ListHooks)
+		api.POST("/hooks", handlers.CreateHook)
+		api.POST("/hooks/test", handlers.TestHook)
+		api.GET("/hooks/:id", handlers.GetHook)
+		api.PUT("/hooks/:id", handlers.UpdateHook)
+		api.DELETE("/hooks/:id", handlers.DeleteHook)
+
+		// Generation engine performance
+		api.GET("/performance", handlers.GetPerformance)
+		api.PUT("/performance", handlers.UpdatePerformance)
+		api.POST("/benchmark", handlers.RunBenchmark)
+
+		// Server guardrails
+		api.GET("/limits", handlers.GetServerLimits)
+		api.PUT("/limits", handlers.UpdateServerLimits)
+
+		// Configuration bundles
+		api.GET("/config/export", handlers.ExportConfig)
+		api.POST("/config/import", handlers.ImportConfig)
+
 		// Event sources (for noise generator UI)
 		api.GET("/event-sources", handlers.GetEventSources)
 
 		// Noise generation
-		api.POST("/noise/start", handlers.StartNoiseGeneration)
+		api.POST("/noise/start", handlers.Idempotent, handlers.StartNoiseGeneration)
 		api.POST("/noise/stop", handlers.StopNoiseGeneration)
 		api.GET("/noise/status", handlers.GetNoiseStatus)
 		api.PUT("/noise/config", handlers.UpdateNoiseConfig)
 		api.GET("/noise/stats", handlers.GetNoiseStats)
+		api.GET("/noise/history", handlers.GetNoiseHistory)
+		api.POST("/noise/anomalies", handlers.InjectAnomalies)
+
+		// Completed noise runs and generate batches
+		api.GET("/runs", handlers.ListRuns)
+		api.GET("/runs/:id", handlers.GetRun)
+		api.POST("/runs/:id/replay", handlers.Idempotent, handlers.ReplayRun)
+		api.POST("/runs/:id/reconcile", handlers.ReconcileRun)
+
+		// Notifications of stream lifecycle events
+		api.GET("/notifications", handlers.ListNotificationChannels)
+		api.POST("/notifications", handlers.CreateNotificationChannel)
+		api.GET("/notifications/:id", handlers.GetNotificationChannel)
+		api.PUT("/notifications/:id", handlers.UpdateNotificationChannel)
+		api.DELETE("/notifications/:id", handlers.DeleteNotificationChannel)
+		api.POST("/notifications/:id/test", handlers.TestNotificationChannel)
+
+		// Configuration change history
+		api.GET("/history/:collection", handlers.GetHistory)
+
+		// Distributed generation
+		api.GET("/cluster", handlers.GetCluster)
+		clusterAPI := api.Group("/cluster", handlers.RequireClusterToken)
+		clusterAPI.POST("/workers", handlers.RegisterWorker)
+		clusterAPI.POST("/shard", handlers.StartShard)
+		clusterAPI.PUT("/shard/:run_id", handlers.UpdateShard)
+		clusterAPI.DELETE("/shard/:run_id", handlers.StopShard)
 	}
 
 	return router
@@ -0,0 +1,159 @@
+// Package cluster spreads noise runs across several server instances. Workers
+// register with a coordinator and report their status in a heartbeat; the
+// coordinator splits each run into shards weighted by the workers' CPUs and
+// sums their statistics.
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Modes an instance runs in
+const (
+	ModeStandalone  = "standalone"
+	ModeCoordinator = "coordinator"
+	ModeWorker      = "worker"
+)
+
+// Timing of the heartbeats
+const (
+	HeartbeatInterval = 5 * time.Second
+	// workerTimeout is how long the coordinator waits for a heartbeat
+	// before it drops a worker and moves its rate to the other shards
+	workerTimeout = 30 * time.Second
+	// orphanTimeout is how long a worker keeps running a shard while the
+	// coordinator cannot be reached
+	orphanTimeout = time.Minute
+)
+
+// CoordinatorShardID names the coordinator's own share of a run
+const CoordinatorShardID = "coordinator"
+
+// Config is how an instance takes part in a cluster
+type Config struct {
+	Mode           string
+	CoordinatorURL string // Worker: where to register
+	AdvertiseURL   string // Worker: where the coordinator reaches it
+	WorkerID       string
+	// Token is shared by the coordinator and its workers and sent as a
+	// bearer token on every cluster request
+	Token string
+	// LocalShare makes the coordinator generate a share of each run too
+	LocalShare bool
+}
+
+// ConfigFromEnv reads CLUSTER_MODE, CLUSTER_COORDINATOR_URL,
+// CLUSTER_ADVERTISE_URL (default http://<hostname>:<port>), CLUSTER_WORKER_ID
+// (default the hostname), CLUSTER_TOKEN and CLUSTER_LOCAL_SHARE (default
+// true). Coordinators and workers need a token: shard requests carry the
+// destinations' credentials.
+func ConfigFromEnv(port string) (Config, error) {
+	hostname, _ := os.Hostname()
+	cfg := Config{
+		Mode:           os.Getenv("CLUSTER_MODE"),
+		CoordinatorURL: strings.TrimRight(os.Getenv("CLUSTER_COORDINATOR_URL"), "/"),
+		AdvertiseURL:   strings.TrimRight(os.Getenv("CLUSTER_ADVERTISE_URL"), "/"),
+		WorkerID:       os.Getenv("CLUSTER_WORKER_ID"),
+		Token:          os.Getenv("CLUSTER_TOKEN"),
+		LocalShare:     os.Getenv("CLUSTER_LOCAL_SHARE") != "false",
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = ModeStandalone
+	}
+	if cfg.WorkerID == "" {
+		cfg.WorkerID = hostname
+	}
+	if cfg.AdvertiseURL == "" {
+		cfg.AdvertiseURL = fmt.Sprintf("http://%s:%s", hostname, port)
+	}
+
+	switch cfg.Mode {
+	case ModeStandalone:
+		return cfg, nil
+	case ModeCoordinator:
+	case ModeWorker:
+		if cfg.CoordinatorURL == "" {
+			return cfg, fmt.Errorf("CLUSTER_COORDINATOR_URL is required in worker mode")
+		}
+	default:
+		return cfg, fmt.Errorf("unknown CLUSTER_MODE %q (use standalone, coordinator or worker)", cfg.Mode)
+	}
+	if cfg.Token == "" {
+		return cfg, fmt.Errorf("CLUSTER_TOKEN is required in %s mode", cfg.Mode)
+	}
+	return cfg, nil
+}
+
+// Authorized reports whether a request carries the cluster token. No
+// request is authorized when no token is configured, as on a standalone
+// instance.
+func (cfg Config) Authorized(r *http.Request) bool {
+	if cfg.Token == "" {
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) == 1
+}
+
+// client calls the cluster API of other instances
+type client struct {
+	http  *http.Client
+	token string
+}
+
+func newClient(token string) *client {
+	return &client{http: &http.Client{Timeout: 30 * time.Second}, token: token}
+}
+
+// do sends body as JSON and decodes the response into out. Error responses
+// are returned with the error message the other instance sent.
+func (c *client) do(ctx context.Context, method, url string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			return fmt.Errorf("%s", e.Error)
+		}
+		return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func setClusterEnv(t *testing.T, mode, token string) {
+	t.Setenv("CLUSTER_MODE", mode)
+	t.Setenv("CLUSTER_TOKEN", token)
+	t.Setenv("CLUSTER_COORDINATOR_URL", "http://coordinator:8080")
+}
+
+func TestConfigFromEnvRequiresToken(t *testing.T) {
+	for _, mode := range []string{ModeCoordinator, ModeWorker} {
+		setClusterEnv(t, mode, "")
+		if _, err := ConfigFromEnv("8080"); err == nil {
+			t.Errorf("%s mode without CLUSTER_TOKEN was accepted", mode)
+		}
+		setClusterEnv(t, mode, "secret")
+		if _, err := ConfigFromEnv("8080"); err != nil {
+			t.Errorf("%s mode with CLUSTER_TOKEN: %v", mode, err)
+		}
+	}
+}
+
+func TestConfigFromEnvStandaloneNeedsNoToken(t *testing.T) {
+	setClusterEnv(t, "", "")
+	cfg, err := ConfigFromEnv("8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Mode != ModeStandalone {
+		t.Errorf("mode = %q, want %q", cfg.Mode, ModeStandalone)
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	cases := []struct {
+		token, header string
+		want          bool
+	}{
+		{"secret", "Bearer secret", true},
+		{"secret", "Bearer wrong", false},
+		{"secret", "", false},
+		{"", "Bearer ", false},
+		{"", "", false},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/api/cluster/status", nil)
+		if c.header != "" {
+			r.Header.Set("Authorization", c.header)
+		}
+		if got := (Config{Token: c.token}).Authorized(r); got != c.want {
+			t.Errorf("token %q, header %q: Authorized = %v, want %v", c.token, c.header, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,492 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+	"siem-event-generator/noise"
+)
+
+// ErrNoWorkers refuses a distributed run while no worker is registered
+var ErrNoWorkers = errors.New("no cluster workers are registered")
+
+// Coordinator keeps the registered workers and runs noise across them
+type Coordinator struct {
+	cfg    Config
+	client *client
+
+	// ops serializes starting, updating and stopping runs, which call the
+	// workers without holding mu
+	ops sync.Mutex
+
+	mu      sync.Mutex
+	workers map[string]*models.ClusterWorker
+	run     *distributedRun // Current or last run
+}
+
+// distributedRun is one noise run split into shards
+type distributedRun struct {
+	id        string
+	startedAt time.Time
+	config    models.NoiseConfig
+	shards    []*shard
+	stopped   bool // Stopped through the coordinator
+}
+
+// shard is one instance's share of a run
+type shard struct {
+	workerID string // CoordinatorShardID for the local share
+	url      string
+	weight   int
+	rate     float64
+	lost     bool
+	status   models.NoiseStatus // As of the last heartbeat
+	lastSeen time.Time
+}
+
+func (s *shard) local() bool {
+	return s.workerID == CoordinatorShardID
+}
+
+// NewCoordinator returns a coordinator with no workers registered
+func NewCoordinator(cfg Config) *Coordinator {
+	return &Coordinator{
+		cfg:     cfg,
+		client:  newClient(cfg.Token),
+		workers: make(map[string]*models.ClusterWorker),
+	}
+}
+
+// Run drops workers that stopped sending heartbeats until ctx is done
+func (c *Coordinator) Run(ctx context.Context) {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.expireWorkers()
+		}
+	}
+}
+
+// Heartbeat registers a worker or refreshes its registration
+func (c *Coordinator) Heartbeat(hb models.WorkerHeartbeat) models.HeartbeatResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	w, ok := c.workers[hb.ID]
+	if !ok {
+		w = &models.ClusterWorker{ID: hb.ID, RegisteredAt: now}
+		c.workers[hb.ID] = w
+		log.Printf("Cluster worker %s registered from %s", hb.ID, hb.URL)
+	}
+	w.URL = hb.URL
+	w.CPUs = hb.CPUs
+	w.LastSeen = now
+	w.RunID = hb.RunID
+	w.Running = hb.Status.Running
+
+	var resp models.HeartbeatResponse
+	if r := c.run; r != nil && !r.stopped {
+		for _, s := range r.shards {
+			if s.workerID == hb.ID && !s.lost {
+				resp.RunID = r.id
+				if hb.RunID == r.id {
+					s.status = hb.Status
+					s.lastSeen = now
+				}
+			}
+		}
+	}
+	return resp
+}
+
+// Workers returns the registered workers ordered by ID
+func (c *Coordinator) Workers() []models.ClusterWorker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	workers := make([]models.ClusterWorker, 0, len(c.workers))
+	for _, w := range c.workers {
+		workers = append(workers, *w)
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].ID < workers[j].ID })
+	return workers
+}
+
+// RunID returns the ID of the current or last distributed run
+func (c *Coordinator) RunID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.run == nil {
+		return ""
+	}
+	return c.run.id
+}
+
+// Start splits a noise run across the coordinator and its workers in
+// proportion to their CPUs. Each shard gets its part of the rate and of the
+// volume budget. If a shard fails to start, the ones already started are
+// stopped again.
+func (c *Coordinator) Start(config *models.NoiseConfig, destinations, routeTargets map[string]*models.Destination) error {
+	c.ops.Lock()
+	defer c.ops.Unlock()
+
+	if status := c.Status(); status.Running {
+		return noise.ErrRunning
+	}
+
+	var shards []*shard
+	if c.cfg.LocalShare {
+		shards = append(shards, &shard{workerID: CoordinatorShardID, weight: runtime.NumCPU()})
+	}
+	for _, w := range c.Workers() {
+		if time.Since(w.LastSeen) < workerTimeout {
+			shards = append(shards, &shard{workerID: w.ID, url: w.URL, weight: max(w.CPUs, 1)})
+		}
+	}
+	if len(shards) == 0 {
+		return ErrNoWorkers
+	}
+	splitRate(shards, config.RatePerSecond)
+
+	r := &distributedRun{
+		id:        uuid.New().String(),
+		startedAt: time.Now(),
+		config:    *config,
+		shards:    shards,
+	}
+	r.config.ID = r.id
+
+	// Published before the shards start so their first heartbeats are
+	// answered with this run
+	c.mu.Lock()
+	previous := c.run
+	c.run = r
+	c.mu.Unlock()
+
+	totalWeight := 0
+	for _, s := range shards {
+		totalWeight += s.weight
+	}
+	errs := make([]error, len(shards))
+	var wg sync.WaitGroup
+	for i, s := range shards {
+		shardConfig := r.config
+		shardConfig.RatePerSecond = s.rate
+		shardConfig.Budget = splitBudget(config.Budget, s.weight, totalWeight)
+		shardConfig.Completion = splitCompletion(config.Completion, s.weight, totalWeight)
+		if config.Seed != 0 {
+			shardConfig.Seed = config.Seed + int64(i) // Shards draw distinct streams
+		}
+		wg.Add(1)
+		go func(i int, s *shard, shardConfig models.NoiseConfig) {
+			defer wg.Done()
+			if s.local() {
+				errs[i] = noise.GetInstance().Start(&shardConfig, destinations)
+				return
+			}
+			req := models.ShardRequest{RunID: r.id, Config: shardConfig, Destinations: destinations, RouteTargets: routeTargets}
+			var status models.NoiseStatus
+			errs[i] = c.client.do(context.Background(), http.MethodPost, s.url+"/api/cluster/shard", req, &status)
+			if errs[i] == nil {
+				c.mu.Lock()
+				s.status = status
+				s.lastSeen = time.Now()
+				c.mu.Unlock()
+			}
+		}(i, s, shardConfig)
+	}
+	wg.Wait()
+
+	var failed error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if failed == nil {
+			failed = fmt.Errorf("shard %s: %w", shards[i].workerID, err)
+		}
+	}
+	if failed != nil {
+		var started []*shard
+		for i, s := range shards {
+			if errs[i] == nil {
+				started = append(started, s)
+			}
+		}
+		c.mu.Lock()
+		c.run = previous
+		c.mu.Unlock()
+		c.stopShards(r.id, started)
+		return failed
+	}
+
+	log.Printf("Distributed noise run %s started on %d shards", r.id, len(shards))
+	return nil
+}
+
+// Stop ends the current run on every shard and waits for their final
+// statistics
+func (c *Coordinator) Stop() error {
+	c.ops.Lock()
+	defer c.ops.Unlock()
+
+	if status := c.Status(); !status.Running {
+		return noise.ErrNotRunning
+	}
+	c.mu.Lock()
+	r := c.run
+	r.stopped = true
+	var shards []*shard
+	for _, s := range r.shards {
+		if !s.lost {
+			shards = append(shards, s)
+		}
+	}
+	c.mu.Unlock()
+
+	c.stopShards(r.id, shards)
+	return nil
+}
+
+// stopShards stops the shards of a run, recording each worker's final
+// status. Failures are logged; a worker that missed the stop drops its shard
+// at its next heartbeat.
+func (c *Coordinator) stopShards(runID string, shards []*shard) {
+	var wg sync.WaitGroup
+	for _, s := range shards {
+		wg.Add(1)
+		go func(s *shard) {
+			defer wg.Done()
+			if s.local() {
+				noise.GetInstance().Stop()
+				return
+			}
+			var status models.NoiseStatus
+			if err := c.client.do(context.Background(), http.MethodDelete, s.url+"/api/cluster/shard/"+runID, nil, &status); err != nil {
+				log.Printf("WARNING: failed to stop shard on worker %s: %v", s.workerID, err)
+				return
+			}
+			c.mu.Lock()
+			s.status = status
+			s.lastSeen = time.Now()
+			c.mu.Unlock()
+		}(s)
+	}
+	wg.Wait()
+}
+
+// Update changes the rate or sources of the current run. The rate is split
+// across the shards the same way it was at the start.
+func (c *Coordinator) Update(update *models.NoiseUpdateRequest) error {
+	c.ops.Lock()
+	defer c.ops.Unlock()
+
+	if status := c.Status(); !status.Running {
+		return noise.ErrNotRunning
+	}
+	c.mu.Lock()
+	r := c.run
+	if update.RatePerSecond != nil {
+		r.config.RatePerSecond = *update.RatePerSecond
+	}
+	if update.EnabledSources != nil {
+		r.config.EnabledSources = update.EnabledSources
+	}
+	shards := activeShards(r)
+	splitRate(shards, r.config.RatePerSecond)
+	c.mu.Unlock()
+
+	return c.pushUpdates(r.id, shards, update.EnabledSources)
+}
+
+// pushUpdates sends each shard its rate and, when set, the new sources
+func (c *Coordinator) pushUpdates(runID string, shards []*shard, sources []models.EnabledEventSource) error {
+	var failed error
+	for _, s := range shards {
+		rate := s.rate
+		update := &models.NoiseUpdateRequest{RatePerSecond: &rate, EnabledSources: sources}
+		var err error
+		if s.local() {
+			err = noise.GetInstance().UpdateConfig(update)
+		} else {
+			err = c.client.do(context.Background(), http.MethodPut, s.url+"/api/cluster/shard/"+runID, update, nil)
+		}
+		if err != nil && failed == nil {
+			failed = fmt.Errorf("shard %s: %w", s.workerID, err)
+		}
+	}
+	return failed
+}
+
+// expireWorkers drops workers whose heartbeats stopped. Shards of the
+// current run they held are marked lost and their rate moves to the
+// remaining shards; their unused budget does not.
+func (c *Coordinator) expireWorkers() {
+	c.ops.Lock()
+	defer c.ops.Unlock()
+
+	c.mu.Lock()
+	for id, w := range c.workers {
+		if time.Since(w.LastSeen) >= workerTimeout {
+			delete(c.workers, id)
+			log.Printf("Cluster worker %s stopped sending heartbeats", id)
+		}
+	}
+	r := c.run
+	if r == nil || r.stopped {
+		c.mu.Unlock()
+		return
+	}
+	lost := false
+	for _, s := range r.shards {
+		if !s.local() && !s.lost && c.workers[s.workerID] == nil {
+			s.lost = true
+			s.status.Running = false
+			lost = true
+			log.Printf("WARNING: distributed noise run %s lost the shard on worker %s", r.id, s.workerID)
+		}
+	}
+	shards := activeShards(r)
+	if lost {
+		splitRate(shards, r.config.RatePerSecond)
+	}
+	c.mu.Unlock()
+
+	if lost && len(shards) > 0 {
+		if err := c.pushUpdates(r.id, shards, nil); err != nil {
+			log.Printf("WARNING: failed to rebalance distributed noise run %s: %v", r.id, err)
+		}
+	}
+}
+
+// Status returns the noise status of the current or last run with the
+// statistics of every shard added up. Without a distributed run it is the
+// local generator's status.
+func (c *Coordinator) Status() models.NoiseStatus {
+	local := noise.GetInstance().GetStatus()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := c.run
+	if r == nil {
+		return local
+	}
+
+	status := models.NoiseStatus{Stats: newStats()}
+	for _, s := range r.shards {
+		shardStatus := s.status
+		if s.local() {
+			shardStatus = local
+		}
+		running := shardStatus.Running && !s.lost
+		status.Running = status.Running || running
+		if status.StopReason == "" {
+			status.StopReason = shardStatus.StopReason
+		}
+		addStats(&status.Stats, shardStatus.Stats, s.workerID)
+
+		info := models.NoiseShard{
+			WorkerID:        s.workerID,
+			RatePerSecond:   s.rate,
+			Running:         running,
+			Lost:            s.lost,
+			StopReason:      shardStatus.StopReason,
+			TotalSent:       shardStatus.Stats.TotalSent,
+			TotalErrors:     shardStatus.Stats.TotalErrors,
+			EventsPerSecond: shardStatus.Stats.EventsPerSecond,
+		}
+		if !s.local() && !s.lastSeen.IsZero() {
+			lastSeen := s.lastSeen
+			info.LastSeen = &lastSeen
+		}
+		status.Shards = append(status.Shards, info)
+	}
+	if r.stopped {
+		status.StopReason = ""
+	}
+
+	if status.Running {
+		startedAt := r.startedAt
+		config := r.config
+		status.StartedAt = &startedAt
+		status.CurrentConfig = &config
+		status.Stats.DurationSeconds = int64(time.Since(r.startedAt).Seconds())
+		if status.Stats.DurationSeconds > 0 {
+			status.Stats.EventsPerSecond = float64(status.Stats.TotalSent) / float64(status.Stats.DurationSeconds)
+		}
+	}
+	return status
+}
+
+// activeShards returns the shards of r that are still generating
+func activeShards(r *distributedRun) []*shard {
+	var shards []*shard
+	for _, s := range r.shards {
+		running := s.status.Running
+		if s.local() {
+			running = noise.GetInstance().IsRunning()
+		}
+		if running && !s.lost {
+			shards = append(shards, s)
+		}
+	}
+	return shards
+}
+
+// splitRate divides rate across shards in proportion to their weight
+func splitRate(shards []*shard, rate float64) {
+	total := 0
+	for _, s := range shards {
+		total += s.weight
+	}
+	for _, s := range shards {
+		s.rate = rate * float64(s.weight) / float64(total)
+	}
+}
+
+// splitBudget returns a shard's part of the volume budget, rounded up so
+// the shards together never fall short of it
+func splitBudget(b *models.VolumeBudget, weight, total int) *models.VolumeBudget {
+	if b == nil {
+		return nil
+	}
+	return &models.VolumeBudget{
+		MaxEvents: share(b.MaxEvents, weight, total),
+		MaxBytes:  models.ByteSize(share(int64(b.MaxBytes), weight, total)),
+	}
+}
+
+// splitCompletion returns a shard's completion: its part of the event and
+// byte limits, and the run's duration and stop URL as they are
+func splitCompletion(c *models.Completion, weight, total int) *models.Completion {
+	if c == nil {
+		return nil
+	}
+	shard := *c
+	shard.MaxEvents = share(c.MaxEvents, weight, total)
+	shard.MaxBytes = models.ByteSize(share(int64(c.MaxBytes), weight, total))
+	return &shard
+}
+
+// share returns a shard's part of n, rounded up
+func share(n int64, weight, total int) int64 {
+	if n <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(float64(n) * float64(weight) / float64(total)))
+}
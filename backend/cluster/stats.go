@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"siem-event-generator/models"
+)
+
+// maxErrorSamples matches the number of errors a generator keeps
+const maxErrorSamples = 5
+
+// newStats returns empty statistics ready for addStats
+func newStats() models.NoiseStats {
+	return models.NoiseStats{
+		ByEventType:  make(map[string]int64),
+		ByTemplate:   make(map[string]int64),
+		ErrorSamples: make([]string, 0, maxErrorSamples),
+	}
+}
+
+// addStats adds one shard's statistics to the run's. Error samples are
+// prefixed with the shard so the instance that failed can be found.
+func addStats(total *models.NoiseStats, s models.NoiseStats, shardID string) {
+	total.TotalGenerated += s.TotalGenerated
+	total.TotalSent += s.TotalSent
+	total.TotalErrors += s.TotalErrors
+	total.TotalDuplicates += s.TotalDuplicates
+	total.TotalMalformed += s.TotalMalformed
+	total.TotalReordered += s.TotalReordered
+	total.TotalAnomalies += s.TotalAnomalies
+	if s.LastEventAt != nil && (total.LastEventAt == nil || s.LastEventAt.After(*total.LastEventAt)) {
+		t := *s.LastEventAt
+		total.LastEventAt = &t
+	}
+	for k, n := range s.ByEventType {
+		total.ByEventType[k] += n
+	}
+	for k, n := range s.ByTemplate {
+		total.ByTemplate[k] += n
+	}
+	for _, e := range s.ErrorSamples {
+		total.ErrorSamples = append(total.ErrorSamples, shardID+": "+e)
+	}
+	if n := len(total.ErrorSamples); n > maxErrorSamples {
+		total.ErrorSamples = total.ErrorSamples[n-maxErrorSamples:]
+	}
+
+	for id, u := range s.Budget {
+		if total.Budget == nil {
+			total.Budget = make(map[string]models.VolumeUsage)
+		}
+		sum, seen := total.Budget[id]
+		sum.MaxEvents += u.MaxEvents
+		sum.MaxBytes += u.MaxBytes
+		sum.EventsSent += u.EventsSent
+		sum.BytesSent += u.BytesSent
+		// The destination is exhausted once every shard has used its share
+		sum.Exhausted = u.Exhausted && (!seen || sum.Exhausted)
+		if sum.Reason == "" {
+			sum.Reason = u.Reason
+		}
+		if !sum.Exhausted {
+			sum.Reason = ""
+		}
+		total.Budget[id] = sum
+	}
+
+	for id, r := range s.ByDestination {
+		if total.ByDestination == nil {
+			total.ByDestination = make(map[string]models.DeliveryResult)
+		}
+		sum := total.ByDestination[id]
+		sum.DestinationID = id
+		sum.Name = r.Name
+		sum.EventsSent += r.EventsSent
+		sum.Errors += r.Errors
+		if r.LastError != "" {
+			sum.LastError = r.LastError
+		}
+		total.ByDestination[id] = sum
+	}
+}
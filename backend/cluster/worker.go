@@ -0,0 +1,154 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+	"siem-event-generator/noise"
+)
+
+// Worker registers with the coordinator and runs the shards it is sent
+type Worker struct {
+	cfg    Config
+	client *client
+
+	mu           sync.Mutex
+	runID        string                         // Shard being run
+	routeTargets map[string]*models.Destination // Sent with the shard
+	lastContact  time.Time                      // Last heartbeat the coordinator answered
+	unreachable  bool
+}
+
+// NewWorker returns a worker for the coordinator in cfg
+func NewWorker(cfg Config) *Worker {
+	return &Worker{cfg: cfg, client: newClient(cfg.Token)}
+}
+
+// Run sends heartbeats until ctx is done
+func (w *Worker) Run(ctx context.Context) {
+	log.Printf("Cluster worker %s joining coordinator %s", w.cfg.WorkerID, w.cfg.CoordinatorURL)
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		w.heartbeat(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// heartbeat reports the worker's status. The shard is stopped when the
+// coordinator no longer runs it, or has not been reached for orphanTimeout.
+func (w *Worker) heartbeat(ctx context.Context) {
+	w.mu.Lock()
+	hb := models.WorkerHeartbeat{
+		ID:     w.cfg.WorkerID,
+		URL:    w.cfg.AdvertiseURL,
+		CPUs:   runtime.NumCPU(),
+		RunID:  w.runID,
+		Status: noise.GetInstance().GetStatus(),
+	}
+	w.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, HeartbeatInterval)
+	defer cancel()
+	var resp models.HeartbeatResponse
+	err := w.client.do(ctx, http.MethodPost, w.cfg.CoordinatorURL+"/api/cluster/workers", hb, &resp)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		if !w.unreachable {
+			log.Printf("WARNING: cluster coordinator unreachable: %v", err)
+			w.unreachable = true
+		}
+		if w.runID != "" && time.Since(w.lastContact) >= orphanTimeout {
+			log.Printf("WARNING: stopping shard of run %s: coordinator unreachable for %s", w.runID, orphanTimeout)
+			w.stopLocked()
+		}
+		return
+	}
+	if w.unreachable {
+		log.Printf("Cluster coordinator reachable again")
+		w.unreachable = false
+	}
+	w.lastContact = time.Now()
+	if w.runID != "" && resp.RunID != w.runID {
+		log.Printf("Stopping shard of run %s: the coordinator no longer runs it", w.runID)
+		w.stopLocked()
+	}
+}
+
+// RunID returns the run whose shard the worker has
+func (w *Worker) RunID() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.runID
+}
+
+// RouteTarget looks up a destination the current shard's routing rules
+// forward to
+func (w *Worker) RouteTarget(id string) (*models.Destination, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	d, ok := w.routeTargets[id]
+	return d, ok
+}
+
+// StartShard starts generating a share of a distributed run
+func (w *Worker) StartShard(req *models.ShardRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	gen := noise.GetInstance()
+	if gen.IsRunning() {
+		return noise.ErrRunning
+	}
+	w.routeTargets = req.RouteTargets
+	if err := gen.Start(&req.Config, req.Destinations); err != nil {
+		w.routeTargets = nil
+		return err
+	}
+	w.runID = req.RunID
+	w.lastContact = time.Now()
+	return nil
+}
+
+// UpdateShard changes the rate or sources of the running shard
+func (w *Worker) UpdateShard(runID string, update *models.NoiseUpdateRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if runID != w.runID {
+		return fmt.Errorf("no shard of run %s", runID)
+	}
+	return noise.GetInstance().UpdateConfig(update)
+}
+
+// StopShard stops the shard of a run; stopping one that already finished
+// is not an error
+func (w *Worker) StopShard(runID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if runID != w.runID {
+		return fmt.Errorf("no shard of run %s", runID)
+	}
+	w.stopLocked()
+	return nil
+}
+
+func (w *Worker) stopLocked() {
+	gen := noise.GetInstance()
+	if gen.IsRunning() {
+		gen.Stop()
+	}
+	w.runID = ""
+	w.routeTargets = nil
+}
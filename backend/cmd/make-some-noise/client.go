@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"siem-event-generator/delivery"
+	"siem-event-generator/models"
+	"siem-event-generator/secrets"
+	"siem-event-generator/storage"
+)
+
+// apiClient is a minimal JSON client for the backend REST API
+type apiClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newAPIClient(baseURL string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// get performs a GET request and decodes the JSON response into out
+func (c *apiClient) get(path string, out interface{}) error {
+	return c.do(http.MethodGet, path, nil, out)
+}
+
+// post sends body as JSON and decodes the JSON response into out
+func (c *apiClient) post(path string, body interface{}, out interface{}) error {
+	return c.do(http.MethodPost, path, body, out)
+}
+
+// do sends a request, with body as JSON unless it is nil, as the --user
+// user, and decodes the JSON response into out
+func (c *apiClient) do(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("request %s: %w", path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if userName != "" {
+		req.Header.Set("X-User", userName)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s: %w", path, err)
+	}
+	return decodeResponse(resp, out)
+}
+
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr models.APIError
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			if apiErr.Code == "" {
+				return fmt.Errorf("API returned status %d: %s", resp.StatusCode, apiErr.Error)
+			}
+			if apiErr.Hint != "" {
+				return fmt.Errorf("API returned %s: %s (%s)", apiErr.Code, apiErr.Error, apiErr.Hint)
+			}
+			return fmt.Errorf("API returned %s: %s", apiErr.Code, apiErr.Error)
+		}
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
+
+// listDestinations fetches all destinations from the backend
+func (c *apiClient) listDestinations() ([]*models.Destination, error) {
+	var resp struct {
+		Destinations []*models.Destination `json:"destinations"`
+	}
+	if err := c.get("/api/destinations", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Destinations, nil
+}
+
+// resolveDestination maps a destination ID or name to its ID
+func (c *apiClient) resolveDestination(idOrName string) (string, error) {
+	dests, err := c.listDestinations()
+	if err != nil {
+		return "", err
+	}
+	dest, err := matchDestination(dests, idOrName)
+	if err != nil {
+		return "", err
+	}
+	return dest.ID, nil
+}
+
+// loadLocalDestinations reads the server's destinations from its database,
+// or from destinations.json if the server has not migrated it yet
+func loadLocalDestinations() ([]*models.Destination, error) {
+	cfg := storage.ConfigFromEnv(configDir)
+	if cfg.Driver == storage.DriverSQLite {
+		if _, err := os.Stat(cfg.DSN); os.IsNotExist(err) {
+			return loadDestinationsFile()
+		}
+	}
+	st, err := storage.Open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("open storage: %w", err)
+	}
+	defer st.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, migrated, err := st.Meta(ctx, storage.MetaFilesMigrated); err != nil {
+		return nil, err
+	} else if !migrated {
+		return loadDestinationsFile()
+	}
+	docs, err := st.Load(ctx, storage.CollectionDestinations)
+	if err != nil {
+		return nil, fmt.Errorf("read destinations: %w", err)
+	}
+
+	dests := make([]*models.Destination, 0, len(docs))
+	for _, data := range docs {
+		var d models.Destination
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("parse destinations: %w", err)
+		}
+		dests = append(dests, &d)
+	}
+	sort.Slice(dests, func(i, j int) bool { return dests[i].ID < dests[j].ID })
+	return dests, nil
+}
+
+// loadDestinationsFile reads destinations.json, where earlier server
+// versions saved destinations
+func loadDestinationsFile() ([]*models.Destination, error) {
+	path := filepath.Join(configDir, "destinations.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read destinations: %w", err)
+	}
+
+	var dests []*models.Destination
+	if err := json.Unmarshal(data, &dests); err != nil {
+		return nil, fmt.Errorf("parse destinations: %w", err)
+	}
+	return dests, nil
+}
+
+// findLocalDestination looks up a destination by ID or name in the config directory
+func findLocalDestination(idOrName string) (*models.Destination, error) {
+	dests, err := loadLocalDestinations()
+	if err != nil {
+		return nil, err
+	}
+	// Saved credentials are encrypted with the server's key
+	if err := secrets.Init(configDir); err != nil {
+		return nil, fmt.Errorf("load secrets key: %w", err)
+	}
+	delivery.ResolveDestination = func(id string) (*models.Destination, bool) {
+		for _, d := range dests {
+			if d.ID == id {
+				return d, true
+			}
+		}
+		return nil, false
+	}
+	return matchDestination(dests, idOrName)
+}
+
+// matchDestination finds a destination by exact ID, then by case-insensitive name
+func matchDestination(dests []*models.Destination, idOrName string) (*models.Destination, error) {
+	for _, d := range dests {
+		if d.ID == idOrName {
+			return d, nil
+		}
+	}
+	for _, d := range dests {
+		if strings.EqualFold(d.Name, idOrName) {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("destination not found: %s", idOrName)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+func newCorpusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "corpus",
+		Short: "Snapshot generator output and diff current output against it",
+		Long:  "Snapshots the fields of every template's output with a fixed seed, and reports fields added, removed, renamed or retyped since, so changes that would break downstream field extractions are caught.",
+	}
+	cmd.AddCommand(newCorpusSnapshotCommand())
+	cmd.AddCommand(newCorpusDiffCommand())
+	return cmd
+}
+
+func newCorpusSnapshotCommand() *cobra.Command {
+	var req models.CorpusSnapshotRequest
+	var out string
+
+	cmd := &cobra.Command{
+		Use:     "snapshot",
+		Short:   "Write a corpus snapshot of every template's output",
+		Example: `  make-some-noise corpus snapshot -o corpus.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var corpus *models.Corpus
+			if serverURL != "" {
+				// The server also keeps it as its stored corpus
+				corpus = &models.Corpus{}
+				if err := newAPIClient(serverURL).post("/api/corpus/snapshot", req, corpus); err != nil {
+					return err
+				}
+			} else {
+				var err error
+				corpus, err = generators.SnapshotCorpus(context.Background(), req.Seed, req.Samples)
+				if err != nil {
+					return err
+				}
+			}
+
+			w := io.Writer(os.Stdout)
+			if out != "" {
+				f, err := os.Create(out)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(corpus)
+		},
+	}
+
+	cmd.Flags().Int64Var(&req.Seed, "seed", generators.DefaultCorpusSeed, "seed for the generators' random values")
+	cmd.Flags().IntVar(&req.Samples, "samples", 5, "events generated per template")
+	cmd.Flags().StringVarP(&out, "output", "o", "", "file to write; empty prints to stdout")
+	return cmd
+}
+
+func newCorpusDiffCommand() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "diff [corpus.json]",
+		Short: "Diff current output against a corpus snapshot; exits 1 if it changed",
+		Long:  "Diffs current output against a corpus file, or with --server and no file against the server's stored corpus.",
+		Example: `  make-some-noise corpus diff corpus.json
+  make-some-noise corpus diff --server http://localhost:8080`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var stored *models.Corpus
+			if len(args) == 1 {
+				data, err := os.ReadFile(args[0])
+				if err != nil {
+					return err
+				}
+				stored = &models.Corpus{}
+				if err := json.Unmarshal(data, stored); err != nil {
+					return fmt.Errorf("parse %s: %w", args[0], err)
+				}
+			} else if serverURL == "" {
+				return errors.New("a corpus file is required without --server")
+			}
+
+			var diff *models.CorpusDiff
+			if serverURL != "" {
+				diff = &models.CorpusDiff{}
+				req := models.CorpusDiffRequest{Corpus: stored}
+				if err := newAPIClient(serverURL).post("/api/corpus/diff", req, diff); err != nil {
+					return err
+				}
+			} else {
+				var err error
+				diff, err = generators.DiffCorpus(context.Background(), stored)
+				if err != nil {
+					return err
+				}
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(diff); err != nil {
+					return err
+				}
+			} else {
+				printCorpusDiff(os.Stdout, diff)
+			}
+			if diff.Changed {
+				return errors.New("output differs from the corpus")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the diff as JSON")
+	return cmd
+}
+
+// printCorpusDiff writes a diff as one block per changed template
+func printCorpusDiff(w io.Writer, diff *models.CorpusDiff) {
+	if !diff.Changed {
+		fmt.Fprintln(w, "No changes")
+		return
+	}
+	for _, t := range diff.TemplatesAdded {
+		fmt.Fprintf(w, "+ template %s\n", t)
+	}
+	for _, t := range diff.TemplatesRemoved {
+		fmt.Fprintf(w, "- template %s\n", t)
+	}
+	for _, t := range diff.Templates {
+		fmt.Fprintf(w, "%s/%s (schema version %d, was %d)\n", t.EventType, t.TemplateID, t.SchemaVersion, t.StoredSchemaVersion)
+		if t.StoredSourcetype != "" {
+			fmt.Fprintf(w, "  sourcetype %s -> %s\n", t.StoredSourcetype, t.Sourcetype)
+		}
+		if t.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", t.Error)
+		}
+		for _, f := range t.Added {
+			fmt.Fprintf(w, "  + %s\n", f)
+		}
+		for _, f := range t.Removed {
+			fmt.Fprintf(w, "  - %s\n", f)
+		}
+		for _, r := range t.Renamed {
+			fmt.Fprintf(w, "  ~ %s -> %s\n", r.From, r.To)
+		}
+		for _, c := range t.TypeChanged {
+			fmt.Fprintf(w, "  ! %s: %s -> %s\n", c.Field, c.From, c.To)
+		}
+	}
+}
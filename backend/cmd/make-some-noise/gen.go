@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"siem-event-generator/delivery"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// genOptions holds the flags for the gen subcommand
+type genOptions struct {
+	eventType  string
+	templateID string
+	count      int
+	dest       string
+	rate       float64
+	set        []string
+	strict     bool
+	preset     string
+	output     string
+}
+
+func newGenCommand() *cobra.Command {
+	opts := &genOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate events and print them or send them to a destination",
+		Example: `  make-some-noise gen --type windows_sysmon --template 1 --count 1000 --dest hec-lab
+  make-some-noise gen --type okta --count 5 --set client.ipAddress=203.0.113.7
+  make-some-noise --server http://localhost:8080 gen --type okta --count 5 --preset contoso`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			overrides, err := parseOverrides(opts.set)
+			if err != nil {
+				return err
+			}
+			if serverURL != "" {
+				return runGenRemote(opts, overrides)
+			}
+			if opts.preset != "" {
+				return errors.New("override presets are kept by the server; set --server")
+			}
+			return runGenLocal(opts, overrides)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.eventType, "type", "t", "", "event type ID (see 'make-some-noise types')")
+	cmd.Flags().StringVar(&opts.templateID, "template", "", "template ID within the event type (default: first template)")
+	cmd.Flags().IntVarP(&opts.count, "count", "n", 1, "number of events to generate")
+	cmd.Flags().StringVarP(&opts.dest, "dest", "d", "", "destination ID or name; empty prints events to stdout")
+	cmd.Flags().Float64Var(&opts.rate, "rate", 0, "events per second (0 = as fast as possible)")
+	cmd.Flags().StringArrayVar(&opts.set, "set", nil, "field override as key=value (repeatable, value parsed as JSON when possible)")
+	cmd.Flags().BoolVar(&opts.strict, "strict", false, "reject overrides for fields the template does not emit")
+	cmd.Flags().StringVar(&opts.preset, "preset", "", "override preset name or ID; --set values win over it (needs --server)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "raw", "stdout format when no destination is set: raw or json")
+	cmd.MarkFlagRequired("type")
+
+	return cmd
+}
+
+// parseOverrides converts key=value pairs into an overrides map
+func parseOverrides(pairs []string) (map[string]interface{}, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	overrides := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid override %q: expected key=value", pair)
+		}
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+			overrides[key] = parsed
+		} else {
+			overrides[key] = value
+		}
+	}
+	return overrides, nil
+}
+
+func runGenLocal(opts *genOptions, overrides map[string]interface{}) error {
+	gen, ok := generators.GetGenerator(opts.eventType)
+	if !ok {
+		return fmt.Errorf("event type not found: %s", opts.eventType)
+	}
+	templateID, err := generators.ResolveTemplateID(gen, opts.templateID)
+	if err != nil {
+		return err
+	}
+
+	fieldErrors, err := generators.ValidateOverrides(gen, templateID, overrides, opts.strict)
+	if err != nil {
+		return err
+	}
+	if len(fieldErrors) > 0 {
+		for _, fe := range fieldErrors {
+			fmt.Fprintf(os.Stderr, "override %s: %s\n", fe.Field, fe.Message)
+		}
+		return fmt.Errorf("invalid overrides")
+	}
+
+	var sender delivery.Sender
+	if opts.dest != "" {
+		dest, err := findLocalDestination(opts.dest)
+		if err != nil {
+			return err
+		}
+		sender, err = delivery.GetSender(dest)
+		if err != nil {
+			return fmt.Errorf("failed to create sender: %w", err)
+		}
+		defer sender.Close()
+	}
+
+	var ticker *time.Ticker
+	if opts.rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / opts.rate))
+		defer ticker.Stop()
+	}
+
+	sent, failed := 0, 0
+	for i := 0; i < opts.count; i++ {
+		if ticker != nil && i > 0 {
+			<-ticker.C
+		}
+
+		event, err := gen.Generate(templateID, overrides)
+		if err != nil {
+			return fmt.Errorf("generate: %w", err)
+		}
+
+		if sender == nil {
+			if err := printEvent(event, opts.output); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := sender.Send(event); err != nil {
+			failed++
+			fmt.Fprintln(os.Stderr, "send error:", err)
+			continue
+		}
+		sent++
+	}
+
+	if sender != nil {
+		fmt.Fprintf(os.Stderr, "Generated %d events, sent %d, failed %d\n", opts.count, sent, failed)
+		if failed > 0 {
+			return fmt.Errorf("%d events failed to send", failed)
+		}
+	}
+	return nil
+}
+
+func runGenRemote(opts *genOptions, overrides map[string]interface{}) error {
+	client := newAPIClient(serverURL)
+
+	// The API caps a single request at 10000 events
+	const maxPerRequest = 10000
+
+	if opts.dest == "" {
+		// Without a destination the backend returns the events of each
+		// batch, printed here at the requested rate
+		var ticker *time.Ticker
+		if opts.rate > 0 {
+			ticker = time.NewTicker(time.Duration(float64(time.Second) / opts.rate))
+			defer ticker.Stop()
+		}
+		printed := 0
+		for remaining := opts.count; remaining > 0; {
+			batch := min(remaining, maxPerRequest)
+			var resp models.GenerateResponse
+			req := models.GenerateRequest{
+				EventType:       opts.eventType,
+				EventID:         opts.templateID,
+				Count:           batch,
+				Overrides:       overrides,
+				StrictOverrides: opts.strict,
+				Preset:          opts.preset,
+				ReturnEvents:    true,
+			}
+			if err := client.post("/api/generate", req, &resp); err != nil {
+				return err
+			}
+			for _, e := range resp.Errors {
+				fmt.Fprintln(os.Stderr, "error:", e)
+			}
+			for i := range resp.Events {
+				if ticker != nil && printed > 0 {
+					<-ticker.C
+				}
+				if err := printEvent(&resp.Events[i], opts.output); err != nil {
+					return err
+				}
+				printed++
+			}
+			remaining -= batch
+		}
+		if printed < opts.count {
+			return fmt.Errorf("%d events failed to generate", opts.count-printed)
+		}
+		return nil
+	}
+
+	// The server paces sends to the destination in whole events per second
+	if opts.rate != math.Trunc(opts.rate) {
+		return fmt.Errorf("--rate must be a whole number of events per second with --server and --dest")
+	}
+	destID, err := client.resolveDestination(opts.dest)
+	if err != nil {
+		return err
+	}
+
+	created, sent := 0, 0
+	for remaining := opts.count; remaining > 0; {
+		batch := remaining
+		if batch > maxPerRequest {
+			batch = maxPerRequest
+		}
+
+		var resp models.GenerateResponse
+		req := models.GenerateRequest{
+			EventType:       opts.eventType,
+			EventID:         opts.templateID,
+			Count:           batch,
+			DestinationID:   destID,
+			Overrides:       overrides,
+			StrictOverrides: opts.strict,
+			Preset:          opts.preset,
+			RatePerSecond:   int(opts.rate),
+		}
+		if err := client.post("/api/generate", req, &resp); err != nil {
+			return err
+		}
+		created += resp.EventsCreated
+		sent += resp.EventsSent
+		for _, e := range resp.Errors {
+			fmt.Fprintln(os.Stderr, "error:", e)
+		}
+		remaining -= batch
+	}
+
+	fmt.Fprintf(os.Stderr, "Generated %d events, sent %d\n", created, sent)
+	if sent < created {
+		return fmt.Errorf("%d events failed to send", created-sent)
+	}
+	return nil
+}
+
+// printEvent writes an event to stdout in the requested format
+func printEvent(event *models.GeneratedEvent, format string) error {
+	switch format {
+	case "json":
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("marshal event: %w", err)
+		}
+		fmt.Println(string(data))
+	case "raw", "":
+		fmt.Println(event.RawEvent)
+	default:
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+	return nil
+}
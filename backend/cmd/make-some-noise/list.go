@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+func newTypesCommand() *cobra.Command {
+	var showTemplates bool
+
+	cmd := &cobra.Command{
+		Use:   "types",
+		Short: "List available event types and their templates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var sources []models.EventSourceInfo
+			if serverURL != "" {
+				var tree models.EventSourceTree
+				if err := newAPIClient(serverURL).get("/api/event-sources", &tree); err != nil {
+					return err
+				}
+				for _, infos := range tree.Categories {
+					sources = append(sources, infos...)
+				}
+			} else {
+				for _, et := range generators.EventTypes() {
+					templates, _ := generators.Templates(et.ID)
+					sources = append(sources, models.EventSourceInfo{
+						EventType: et,
+						Templates: templates,
+					})
+				}
+			}
+
+			sort.Slice(sources, func(i, j int) bool {
+				return sources[i].EventType.ID < sources[j].EventType.ID
+			})
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "TYPE\tCATEGORY\tNAME")
+			for _, s := range sources {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", s.EventType.ID, s.EventType.Category, s.EventType.Name)
+				if showTemplates {
+					for _, t := range s.Templates {
+						fmt.Fprintf(w, "  %s\t\t%s\n", t.ID, t.Name)
+					}
+				}
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&showTemplates, "templates", false, "also list templates for each event type")
+	return cmd
+}
+
+func newDestinationsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "destinations",
+		Aliases: []string{"dests"},
+		Short:   "List configured destinations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var dests []*models.Destination
+			var err error
+			if serverURL != "" {
+				dests, err = newAPIClient(serverURL).listDestinations()
+			} else {
+				dests, err = loadLocalDestinations()
+			}
+			if err != nil {
+				return err
+			}
+
+			sort.Slice(dests, func(i, j int) bool { return dests[i].Name < dests[j].Name })
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tTYPE")
+			for _, d := range dests {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", d.ID, d.Name, d.Type)
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Global flags shared by all subcommands
+var (
+	serverURL string
+	configDir string
+	userName  string
+)
+
+func main() {
+	root := &cobra.Command{
+		Use:           "make-some-noise",
+		Short:         "Generate synthetic SIEM events from the command line",
+		Long:          "Headless client for the SIEM event generator. Runs generators in-process by default, or talks to a running backend when --server is set.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	defaultConfigDir := os.Getenv("CONFIG_DIR")
+	if defaultConfigDir == "" {
+		defaultConfigDir = "/config"
+	}
+
+	root.PersistentFlags().StringVar(&serverURL, "server", os.Getenv("MSN_SERVER"), "backend API base URL (e.g. http://localhost:8080); empty runs in-process")
+	root.PersistentFlags().StringVar(&configDir, "config-dir", defaultConfigDir, "server config directory, for its database and secrets key (in-process mode)")
+
+	root.PersistentFlags().StringVar(&userName, "user", os.Getenv("MSN_USER"), "user whose favorites and recent use the server keeps (with --server)")
+
+	root.AddCommand(newGenCommand())
+	root.AddCommand(newTypesCommand())
+	root.AddCommand(newDestinationsCommand())
+	root.AddCommand(newCorpusCommand())
+	root.AddCommand(newUsualCommand())
+	root.AddCommand(newFavoriteCommand())
+
+	if err := root.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"siem-event-generator/models"
+)
+
+// errNeedsServer is returned by commands that read state only the server
+// keeps
+var errNeedsServer = errors.New("favorites and recent use are kept by the server; set --server")
+
+func newUsualCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "usual",
+		Short: "List your favorite and recently used templates and destinations",
+		Long:  "Lists the favorites and recent use the server keeps for --user, favorites first, then the most recently used.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if serverURL == "" {
+				return errNeedsServer
+			}
+			client := newAPIClient(serverURL)
+			var favorites models.Favorites
+			if err := client.get("/api/favorites", &favorites); err != nil {
+				return err
+			}
+			var recent models.RecentUse
+			if err := client.get("/api/recent", &recent); err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "KIND\tSOURCE\tNAME\tUSES")
+			for _, t := range favorites.Templates {
+				fmt.Fprintf(w, "favorite\t%s\t%s\t\n", templatePath(t), t.Name)
+			}
+			for _, d := range favorites.Destinations {
+				fmt.Fprintf(w, "favorite\tdestination %s\t%s\t\n", d.ID, d.Name)
+			}
+			for _, t := range recent.Templates {
+				fmt.Fprintf(w, "recent\t%s\t%s\t%d\n", templatePath(t.TemplateRef), t.Name, t.Uses)
+			}
+			for _, d := range recent.Destinations {
+				fmt.Fprintf(w, "recent\tdestination %s\t%s\t%d\n", d.ID, d.Name, d.Uses)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newFavoriteCommand() *cobra.Command {
+	var remove bool
+
+	cmd := &cobra.Command{
+		Use:   "favorite",
+		Short: "Add or remove a favorite template or destination",
+		Example: `  make-some-noise favorite template windows_security 4625
+  make-some-noise favorite template okta
+  make-some-noise favorite destination "Splunk Dev"
+  make-some-noise favorite --remove template windows_security 4625`,
+	}
+	cmd.PersistentFlags().BoolVar(&remove, "remove", false, "remove the favorite instead of adding it")
+
+	method := func() string {
+		if remove {
+			return http.MethodDelete
+		}
+		return http.MethodPut
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "template <event-type> [template-id]",
+		Short: "Favorite a template, or a whole event type",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if serverURL == "" {
+				return errNeedsServer
+			}
+			path := "/api/favorites/templates/" + url.PathEscape(args[0])
+			if len(args) == 2 {
+				path += "/" + url.PathEscape(args[1])
+			}
+			return newAPIClient(serverURL).do(method(), path, nil, nil)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "destination <id-or-name>",
+		Short: "Favorite a destination",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if serverURL == "" {
+				return errNeedsServer
+			}
+			client := newAPIClient(serverURL)
+			id, err := client.resolveDestination(args[0])
+			if err != nil {
+				if !remove {
+					return err
+				}
+				id = args[0] // A deleted destination can still be unpinned by ID
+			}
+			return client.do(method(), "/api/favorites/destinations/"+url.PathEscape(id), nil, nil)
+		},
+	})
+	return cmd
+}
+
+// templatePath formats a template as event-type/template-id, or just the
+// event type for a whole one
+func templatePath(t models.TemplateRef) string {
+	if t.TemplateID == "" {
+		return t.EventType
+	}
+	return t.EventType + "/" + t.TemplateID
+}
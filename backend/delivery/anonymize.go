@@ -0,0 +1,356 @@
+package delivery
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// tokenVaultLimit caps the in-memory token vault; once full, tokenize falls
+// back to hashing so memory stays bounded during long noise runs
+const tokenVaultLimit = 100000
+
+// redactedValue replaces fields with the redact action
+const redactedValue = "[REDACTED]"
+
+// Anonymizer masks, hashes or tokenizes sensitive fields of generated events
+type Anonymizer struct {
+	mu     sync.RWMutex
+	config models.AnonymizationConfig
+	key    []byte
+
+	tokenMu sync.Mutex
+	tokens  map[string]string // value -> token
+	values  map[string]string // token -> value
+}
+
+// Anonymization is the global anonymizer applied by every sender
+var Anonymization = NewAnonymizer()
+
+// NewAnonymizer creates a disabled anonymizer with no rules
+func NewAnonymizer() *Anonymizer {
+	a := &Anonymizer{
+		tokens: make(map[string]string),
+		values: make(map[string]string),
+	}
+	a.SetConfig(models.AnonymizationConfig{})
+	return a
+}
+
+// Config returns a copy of the anonymization settings
+func (a *Anonymizer) Config() models.AnonymizationConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	cfg := a.config
+	cfg.Rules = append([]models.AnonymizationRule{}, a.config.Rules...)
+	return cfg
+}
+
+// SetConfig validates and replaces the anonymization settings. Rules without
+// an action mask their field; a salt is generated when none is given.
+func (a *Anonymizer) SetConfig(cfg models.AnonymizationConfig) error {
+	rules := make([]models.AnonymizationRule, 0, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		r.Field = strings.TrimSpace(r.Field)
+		if r.Field == "" {
+			return fmt.Errorf("rule %d: field is required", i)
+		}
+		switch r.Action {
+		case "":
+			r.Action = models.AnonymizeMask
+		case models.AnonymizeMask, models.AnonymizeHash, models.AnonymizeTokenize, models.AnonymizeRedact:
+		default:
+			return fmt.Errorf("rule %d: unknown action %q", i, r.Action)
+		}
+		rules = append(rules, r)
+	}
+	cfg.Rules = rules
+
+	if cfg.Salt == "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("generate salt: %w", err)
+		}
+		cfg.Salt = hex.EncodeToString(salt)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.config = cfg
+	a.key = []byte(cfg.Salt)
+	return nil
+}
+
+// Detokenize returns the original value behind a token
+func (a *Anonymizer) Detokenize(token string) (string, bool) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+	v, ok := a.values[token]
+	return v, ok
+}
+
+// Anonymize returns event with its sensitive fields anonymized, or event
+// itself when anonymization is disabled or no rule matches
+func (a *Anonymizer) Anonymize(event *models.GeneratedEvent) *models.GeneratedEvent {
+	a.mu.RLock()
+	enabled := a.config.Enabled
+	a.mu.RUnlock()
+	if !enabled {
+		return event
+	}
+	anon, _ := a.Apply(event)
+	return anon
+}
+
+// Apply anonymizes the fields matched by the rules, whether or not
+// anonymization is enabled, and reports which fields were changed. The
+// event is not modified; the raw event of the copy has every replaced value
+// rewritten as well.
+func (a *Anonymizer) Apply(event *models.GeneratedEvent) (*models.GeneratedEvent, []string) {
+	a.mu.RLock()
+	var rules []models.AnonymizationRule
+	for _, r := range a.config.Rules {
+		if (r.EventType == "" || r.EventType == event.Type) && (r.EventID == "" || r.EventID == event.EventID) {
+			rules = append(rules, r)
+		}
+	}
+	a.mu.RUnlock()
+	if len(rules) == 0 {
+		return event, nil
+	}
+
+	fields := make(map[string]interface{}, len(event.Fields))
+	for k, v := range event.Fields {
+		fields[k] = v
+	}
+
+	replaced := make(map[string]string)
+	var changed []string
+	for _, r := range rules {
+		action := r.Action
+		updated, ok := anonymizePath(fields, r.Field, func(v interface{}) interface{} {
+			old := fmt.Sprint(v)
+			anon := a.anonymizeValue(action, old)
+			replaced[old] = anon
+			return anon
+		})
+		if ok {
+			fields = updated.(map[string]interface{})
+			changed = append(changed, r.Field)
+		}
+	}
+	if len(changed) == 0 {
+		return event, nil
+	}
+
+	anon := *event
+	anon.Fields = fields
+	anon.RawEvent = rewriteRaw(event.RawEvent, replaced)
+	return &anon, changed
+}
+
+// anonymizePath applies fn to the value at path inside v, copying every map
+// and slice on the way so the original event is left untouched. Keys that
+// contain dots themselves (flattened ECS names) are matched before the path
+// is split. Lists are walked element by element.
+func anonymizePath(v interface{}, path string, fn func(interface{}) interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		key, rest := path, ""
+		if _, ok := t[path]; !ok {
+			if i := strings.Index(path, "."); i >= 0 {
+				key, rest = path[:i], path[i+1:]
+			}
+		}
+		child, ok := t[key]
+		if !ok {
+			return v, false
+		}
+		var updated interface{}
+		if rest == "" {
+			updated, ok = anonymizeLeaf(child, fn)
+		} else {
+			updated, ok = anonymizePath(child, rest, fn)
+		}
+		if !ok {
+			return v, false
+		}
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[k] = val
+		}
+		m[key] = updated
+		return m, true
+	case []map[string]interface{}:
+		items := make([]map[string]interface{}, len(t))
+		found := false
+		for i, item := range t {
+			updated, ok := anonymizePath(item, path, fn)
+			items[i] = updated.(map[string]interface{})
+			found = found || ok
+		}
+		return items, found
+	case []interface{}:
+		items := make([]interface{}, len(t))
+		found := false
+		for i, item := range t {
+			updated, ok := anonymizePath(item, path, fn)
+			items[i] = updated
+			found = found || ok
+		}
+		return items, found
+	}
+	return v, false
+}
+
+// anonymizeLeaf applies fn to a scalar or to each element of a list of
+// scalars; nested objects and nulls are left alone
+func anonymizeLeaf(v interface{}, fn func(interface{}) interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case nil, map[string]interface{}, []map[string]interface{}:
+		return v, false
+	case []string:
+		items := make([]string, len(t))
+		for i, s := range t {
+			items[i] = fn(s).(string)
+		}
+		return items, len(t) > 0
+	case []interface{}:
+		items := make([]interface{}, len(t))
+		found := false
+		for i, item := range t {
+			updated, ok := anonymizeLeaf(item, fn)
+			items[i] = updated
+			found = found || ok
+		}
+		return items, found
+	}
+	return fn(v), true
+}
+
+func (a *Anonymizer) anonymizeValue(action, value string) string {
+	switch action {
+	case models.AnonymizeHash:
+		return a.hash(value)
+	case models.AnonymizeTokenize:
+		return a.tokenize(value)
+	case models.AnonymizeRedact:
+		return redactedValue
+	default:
+		return maskValue(value)
+	}
+}
+
+func (a *Anonymizer) hash(value string) string {
+	a.mu.RLock()
+	mac := hmac.New(sha256.New, a.key)
+	a.mu.RUnlock()
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+func (a *Anonymizer) tokenize(value string) string {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+	if token, ok := a.tokens[value]; ok {
+		return token
+	}
+	if len(a.tokens) >= tokenVaultLimit {
+		return a.hash(value)
+	}
+	b := make([]byte, 6)
+	rand.Read(b)
+	token := "tok_" + hex.EncodeToString(b)
+	a.tokens[value] = token
+	a.values[token] = value
+	return token
+}
+
+// maskValue hides most of a value while keeping its shape: the first octet
+// of an IP, the first character and domain of an email address, and the
+// first character of anything else
+func maskValue(value string) string {
+	if ip := net.ParseIP(value); ip != nil {
+		if ip.To4() != nil {
+			return value[:strings.Index(value, ".")] + ".*.*.*"
+		}
+		return value[:strings.Index(value, ":")] + ":*"
+	}
+	if at := strings.LastIndex(value, "@"); at > 0 {
+		return maskValue(value[:at]) + value[at:]
+	}
+	runes := []rune(value)
+	if len(runes) <= 1 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+}
+
+// rewriteRaw replaces anonymized values in the raw event, including their
+// JSON- and XML-escaped forms. Values shorter than three characters are
+// skipped to avoid rewriting unrelated text; longer values are replaced first.
+func rewriteRaw(raw string, replaced map[string]string) string {
+	olds := make([]string, 0, len(replaced))
+	for old := range replaced {
+		if len(old) >= 3 {
+			olds = append(olds, old)
+		}
+	}
+	if len(olds) == 0 {
+		return raw
+	}
+	sort.Slice(olds, func(i, j int) bool { return len(olds[i]) > len(olds[j]) })
+
+	var pairs []string
+	for _, old := range olds {
+		anon := replaced[old]
+		pairs = append(pairs, old, anon)
+		if quoted, err := json.Marshal(old); err == nil {
+			if esc := string(quoted[1 : len(quoted)-1]); esc != old {
+				pairs = append(pairs, esc, anon)
+			}
+		}
+		if esc := html.EscapeString(old); esc != old {
+			pairs = append(pairs, esc, html.EscapeString(anon))
+		}
+	}
+	return strings.NewReplacer(pairs...).Replace(raw)
+}
+
+// sendPipeline runs the event hooks, anonymizes events and checks them
+// against an optional volume budget before handing them to a sender
+type sendPipeline struct {
+	Sender
+	budget *Budget
+}
+
+// Send runs the hooks, anonymizes the event, counts it against the budget
+// and sends it
+func (s sendPipeline) Send(event *models.GeneratedEvent) error {
+	event, err := Hooks.Apply(event)
+	if err != nil {
+		return err
+	}
+	event = Anonymization.Anonymize(event)
+	if s.budget == nil {
+		return s.Sender.Send(event)
+	}
+	if !s.budget.reserve(event) {
+		return ErrBudgetExhausted
+	}
+	if err := s.Sender.Send(event); err != nil {
+		s.budget.release(event)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package delivery
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// defaultFlushInterval is how long a partial batch waits before it is sent
+const defaultFlushInterval = time.Second
+
+// Flush triggers recorded in batch metrics
+const (
+	flushSize     = "size"
+	flushInterval = "interval"
+	flushClose    = "close"
+)
+
+// BatchRecorder collects batching metrics per HTTP destination
+type BatchRecorder struct {
+	mu    sync.Mutex
+	stats map[string]*models.BatchStats
+}
+
+// BatchMetrics holds the batching metrics of every destination
+var BatchMetrics = &BatchRecorder{stats: make(map[string]*models.BatchStats)}
+
+// Get returns a destination's batching metrics
+func (r *BatchRecorder) Get(destinationID string) (models.BatchStats, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.stats[destinationID]
+	if !ok {
+		return models.BatchStats{}, false
+	}
+	stats := *s
+	if stats.Requests > 0 {
+		stats.AvgBatchSize = float64(stats.Events) / float64(stats.Requests)
+	}
+	if stats.WireBytes > 0 && stats.Bytes != stats.WireBytes {
+		stats.CompressionRatio = float64(stats.Bytes) / float64(stats.WireBytes)
+	}
+	return stats, true
+}
+
+// Reset discards a destination's batching metrics
+func (r *BatchRecorder) Reset(destinationID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.stats, destinationID)
+}
+
+// record adds one request to a destination's metrics. Senders without a
+// destination ID (connection tests of unsaved configs) are not recorded.
+func (r *BatchRecorder) record(destinationID string, events, size, wire int, trigger string, err error) {
+	if destinationID == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.stats[destinationID]
+	if !ok {
+		s = &models.BatchStats{}
+		r.stats[destinationID] = s
+	}
+
+	now := time.Now()
+	s.LastFlushAt = &now
+	if err != nil {
+		s.Errors++
+		s.LastError = err.Error()
+		return
+	}
+	s.Requests++
+	s.Events += int64(events)
+	s.Bytes += int64(size)
+	s.WireBytes += int64(wire)
+	s.LastBatchSize = events
+	if events > s.MaxBatchSize {
+		s.MaxBatchSize = events
+	}
+	switch trigger {
+	case flushSize:
+		s.SizeFlushes++
+	case flushInterval:
+		s.IntervalFlushes++
+	case flushClose:
+		s.CloseFlushes++
+	}
+}
+
+// validateCompression checks a destination's compression setting
+func validateCompression(compression string) error {
+	switch compression {
+	case "", "gzip":
+		return nil
+	default:
+		return fmt.Errorf("unsupported compression %q: use gzip or leave empty", compression)
+	}
+}
+
+// newBodyRequest builds a POST request for body, gzipping it when
+// compression is "gzip". It returns the request and the size sent.
+func newBodyRequest(url string, body []byte, compression string) (*http.Request, int, error) {
+	if compression == "gzip" {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return nil, 0, fmt.Errorf("failed to compress request: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, 0, fmt.Errorf("failed to compress request: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return req, len(body), nil
+}
+
+// batchTimer flushes a partial batch once it has waited for the flush
+// interval. flush takes the sender's lock itself; an interval that was
+// stopped, or re-armed since, does not flush.
+type batchTimer struct {
+	interval time.Duration
+
+	mu     sync.Mutex
+	timer  *time.Timer
+	armed  uint64 // Counts arms, so a stale interval knows it is stale
+	closed bool   // Set once the sender closes; nothing is armed after
+}
+
+func newBatchTimer(config models.DestinationConfig) *batchTimer {
+	interval := defaultFlushInterval
+	if config.FlushIntervalMs > 0 {
+		interval = time.Duration(config.FlushIntervalMs) * time.Millisecond
+	}
+	return &batchTimer{interval: interval}
+}
+
+// arm starts the interval when the first event of a batch is buffered
+func (t *batchTimer) arm(flush func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil || t.closed {
+		return
+	}
+	t.armed++
+	n := t.armed
+	t.timer = time.AfterFunc(t.interval, func() {
+		if t.fire(n) {
+			flush()
+		}
+	})
+}
+
+// fire reports whether the interval armed n-th is still pending, and
+// clears it
+func (t *batchTimer) fire(n uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer == nil || t.closed || t.armed != n {
+		return false
+	}
+	t.timer = nil
+	return true
+}
+
+// stop cancels a pending interval flush
+func (t *batchTimer) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// close cancels a pending interval flush and arms no more
+func (t *batchTimer) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
@@ -0,0 +1,108 @@
+package delivery
+
+import (
+	"errors"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// ErrBudgetExhausted is returned by a budgeted sender once the next event
+// would exceed its volume budget
+var ErrBudgetExhausted = errors.New("volume budget exhausted")
+
+// Budget tracks the events and bytes sent to one destination against a
+// volume budget
+type Budget struct {
+	mu     sync.Mutex
+	limit  models.VolumeBudget
+	events int64
+	bytes  int64
+	reason string
+}
+
+// NewBudget creates a budget with nothing sent
+func NewBudget(limit models.VolumeBudget) *Budget {
+	return &Budget{limit: limit}
+}
+
+// Restore carries over what an earlier run sent, so a resumed run stops at
+// the same volume
+func (b *Budget) Restore(usage models.VolumeUsage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = usage.EventsSent
+	b.bytes = usage.BytesSent
+	if usage.Exhausted {
+		b.reason = usage.Reason
+	}
+}
+
+// reserve counts event against the budget, or reports false without
+// counting it when it would go over either limit
+func (b *Budget) reserve(event *models.GeneratedEvent) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.reason != "" {
+		return false
+	}
+	size := int64(len(event.RawEvent))
+	if b.limit.MaxEvents > 0 && b.events+1 > b.limit.MaxEvents {
+		b.reason = models.BudgetReasonEvents
+		return false
+	}
+	if b.limit.MaxBytes > 0 && b.bytes+size > int64(b.limit.MaxBytes) {
+		b.reason = models.BudgetReasonBytes
+		return false
+	}
+	b.events++
+	b.bytes += size
+	return true
+}
+
+// release returns a reserved event whose send failed
+func (b *Budget) release(event *models.GeneratedEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events--
+	b.bytes -= int64(len(event.RawEvent))
+}
+
+// Exhausted reports whether either limit has been reached
+func (b *Budget) Exhausted() bool {
+	return b.Usage().Exhausted
+}
+
+// Usage returns the events and bytes sent so far
+func (b *Budget) Usage() models.VolumeUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := models.VolumeUsage{
+		MaxEvents:  b.limit.MaxEvents,
+		MaxBytes:   int64(b.limit.MaxBytes),
+		EventsSent: b.events,
+		BytesSent:  b.bytes,
+		Exhausted:  b.reason != "",
+		Reason:     b.reason,
+	}
+	// Filling a limit exactly exhausts the budget without a refused event
+	if !usage.Exhausted {
+		switch {
+		case usage.MaxEvents > 0 && usage.EventsSent >= usage.MaxEvents:
+			usage.Exhausted, usage.Reason = true, models.BudgetReasonEvents
+		case usage.MaxBytes > 0 && usage.BytesSent >= usage.MaxBytes:
+			usage.Exhausted, usage.Reason = true, models.BudgetReasonBytes
+		}
+	}
+	return usage
+}
+
+// WithBudget makes sender count every event against budget, after
+// anonymization, and return ErrBudgetExhausted instead of going over it
+func WithBudget(sender Sender, budget *Budget) Sender {
+	if p, ok := sender.(sendPipeline); ok {
+		p.budget = budget
+		return p
+	}
+	return sendPipeline{Sender: sender, budget: budget}
+}
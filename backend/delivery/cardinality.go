@@ -0,0 +1,240 @@
+package delivery
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"siem-event-generator/models"
+)
+
+// Field kinds a cardinality limit applies to
+const (
+	fieldUser = iota
+	fieldHost
+	fieldSourceIP
+	fieldURL
+	fieldKinds
+)
+
+// Suffixes of a field's path, lower-cased with punctuation removed, that
+// mark its kind: user.name, TargetUserName and src_user are all users
+var fieldSuffixes = [fieldKinds][]string{
+	fieldUser:     {"username", "user", "accountname", "userprincipalname"},
+	fieldHost:     {"hostname", "host", "computername", "computer", "devicename", "workstationname", "workstation"},
+	fieldSourceIP: {"sourceip", "sourceaddress", "ipaddress", "srcip", "srcaddr", "src", "clientip", "callerip", "remoteip", "origh"},
+	fieldURL:      {"url", "uri", "urloriginal", "urlfull", "requesturl", "requesturi", "uristem"},
+}
+
+// CardinalityLimiter rewrites user, host, source IP and URL fields so each
+// kind takes at most a fixed number of distinct values. Every new value in
+// an event is replaced by one of the kind's values, picked uniformly, so a
+// long run ends up using all of them. Slots are filled with the values the
+// templates generate, made unique when they repeat.
+type CardinalityLimiter struct {
+	kinds [fieldKinds]*valueSlots
+}
+
+// valueSlots holds the values one field kind is limited to
+type valueSlots struct {
+	mu     sync.Mutex
+	values []string
+	used   map[string]bool
+}
+
+// NewCardinalityLimiter returns a limiter for limits, or nil when no field
+// is limited
+func NewCardinalityLimiter(limits models.CardinalityLimits) *CardinalityLimiter {
+	l := &CardinalityLimiter{}
+	active := false
+	for kind, n := range [fieldKinds]int{limits.Users, limits.Hosts, limits.SourceIPs, limits.URLs} {
+		if n > 0 {
+			l.kinds[kind] = &valueSlots{values: make([]string, n), used: make(map[string]bool)}
+			active = true
+		}
+	}
+	if !active {
+		return nil
+	}
+	return l
+}
+
+// Apply returns a copy of event with its limited fields rewritten, in the
+// raw event as well. A value that appears in several fields of one event is
+// replaced the same way in each.
+func (l *CardinalityLimiter) Apply(event *models.GeneratedEvent, rng *rand.Rand) *models.GeneratedEvent {
+	if l == nil {
+		return event
+	}
+	replaced := make(map[string]string)
+	fields, changed := l.rewrite(event.Fields, "", replaced, rng)
+	if !changed {
+		return event
+	}
+	limited := *event
+	limited.Fields = fields.(map[string]interface{})
+	limited.RawEvent = rewriteRaw(event.RawEvent, replaced)
+	return &limited
+}
+
+// rewrite walks v, copying the maps and lists it changes so the original
+// event is left untouched
+func (l *CardinalityLimiter) rewrite(v interface{}, path string, replaced map[string]string, rng *rand.Rand) (interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		var m map[string]interface{}
+		for k, child := range t {
+			updated, ok := l.rewrite(child, path+"."+k, replaced, rng)
+			if !ok {
+				continue
+			}
+			if m == nil {
+				m = make(map[string]interface{}, len(t))
+				for k, val := range t {
+					m[k] = val
+				}
+			}
+			m[k] = updated
+		}
+		if m == nil {
+			return v, false
+		}
+		return m, true
+	case []map[string]interface{}:
+		items := make([]map[string]interface{}, len(t))
+		found := false
+		for i, item := range t {
+			updated, ok := l.rewrite(item, path, replaced, rng)
+			items[i] = updated.(map[string]interface{})
+			found = found || ok
+		}
+		return items, found
+	case []interface{}:
+		items := make([]interface{}, len(t))
+		found := false
+		for i, item := range t {
+			updated, ok := l.rewrite(item, path, replaced, rng)
+			items[i] = updated
+			found = found || ok
+		}
+		return items, found
+	case []string:
+		items := make([]string, len(t))
+		found := false
+		for i, s := range t {
+			updated, ok := l.rewrite(s, path, replaced, rng)
+			items[i] = updated.(string)
+			found = found || ok
+		}
+		return items, found
+	case string:
+		// Values too short to be rewritten in the raw event are left alone
+		kind := fieldKind(path)
+		if kind < 0 || l.kinds[kind] == nil || len(t) < 3 {
+			return v, false
+		}
+		if kind == fieldSourceIP && net.ParseIP(t) == nil {
+			return v, false
+		}
+		if r, ok := replaced[t]; ok {
+			return r, r != t
+		}
+		r := l.kinds[kind].pick(kind, t, rng)
+		replaced[t] = r
+		return r, r != t
+	}
+	return v, false
+}
+
+// fieldKind returns the kind of the field at path, or -1
+func fieldKind(path string) int {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, path)
+	for kind, suffixes := range fieldSuffixes {
+		for _, s := range suffixes {
+			if strings.HasSuffix(normalized, s) {
+				return kind
+			}
+		}
+	}
+	return -1
+}
+
+// pick returns the value of a random slot, filling the slot from value when
+// it is still empty
+func (s *valueSlots) pick(kind int, value string, rng *rand.Rand) string {
+	slot := rng.Intn(len(s.values))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values[slot] == "" {
+		v := value
+		for i := slot; s.used[v]; i++ {
+			v = variant(kind, value, i)
+		}
+		s.values[slot] = v
+		s.used[v] = true
+	}
+	return s.values[slot]
+}
+
+// variant derives a distinct value of the same shape from value, for
+// slots that need more values than the templates generate
+func variant(kind int, value string, n int) string {
+	suffix := strconv.Itoa(n + 1)
+	switch kind {
+	case fieldUser:
+		if i := strings.LastIndex(value, "@"); i > 0 {
+			return value[:i] + suffix + value[i:]
+		}
+		return value + suffix
+	case fieldHost:
+		if i := strings.Index(value, "."); i > 0 && net.ParseIP(value) == nil {
+			return value[:i] + "-" + suffix + value[i:]
+		}
+		return value + "-" + suffix
+	case fieldSourceIP:
+		ip := net.ParseIP(value)
+		if v4 := ip.To4(); v4 != nil {
+			return ipv4Variant(v4, n)
+		}
+		v6 := make(net.IP, net.IPv6len)
+		copy(v6, ip)
+		binary.BigEndian.PutUint32(v6[12:], binary.BigEndian.Uint32(v6[12:])+uint32(n+1))
+		return v6.String()
+	case fieldURL:
+		if u, err := url.Parse(value); err == nil {
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/" + suffix
+			return u.String()
+		}
+	}
+	return value + suffix
+}
+
+// ipv4Variant spreads variants over the address's private block, or over
+// its /8, moving to the /8 once the block is half used
+func ipv4Variant(v4 net.IP, n int) string {
+	bits := 24
+	switch {
+	case v4[0] == 192 && v4[1] == 168:
+		bits = 16
+	case v4[0] == 172 && v4[1]&0xf0 == 16:
+		bits = 20
+	}
+	if n >= 1<<bits/2 {
+		bits = 24
+	}
+	mask := uint32(1)<<bits - 1
+	addr := binary.BigEndian.Uint32(v4)
+	addr = addr&^mask | (addr+uint32(n+1)*2654435761)&mask
+	return fmt.Sprintf("%d.%d.%d.%d", addr>>24, addr>>16&0xff, addr>>8&0xff, addr&0xff)
+}
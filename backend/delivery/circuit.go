@@ -0,0 +1,96 @@
+package delivery
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// ErrCircuitOpen is returned instead of sending while a destination's
+// circuit is open
+var ErrCircuitOpen = errors.New("circuit open: destination keeps failing")
+
+// Circuit breaker settings
+const (
+	circuitThreshold = 50               // Consecutive failed sends that open the circuit
+	circuitCooldown  = 30 * time.Second // How long the circuit stays open before a trial send
+)
+
+// circuitBreaker stops sending to a destination after circuitThreshold
+// sends in a row fail. Once circuitCooldown has passed one trial send goes
+// through: if it succeeds the circuit closes, otherwise it stays open for
+// another cooldown.
+type circuitBreaker struct {
+	Sender
+	onOpen func(err error)
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // Zero while the circuit is closed
+	trial    bool      // A trial send is in flight
+}
+
+// WithCircuitBreaker makes sender return ErrCircuitOpen without sending
+// while its destination keeps failing. onOpen, if set, is called with the
+// last error each time the circuit opens; failed trial sends do not call it
+// again.
+func WithCircuitBreaker(sender Sender, onOpen func(err error)) Sender {
+	return &circuitBreaker{Sender: sender, onOpen: onOpen}
+}
+
+// Send sends the event unless the circuit is open
+func (b *circuitBreaker) Send(event *models.GeneratedEvent) error {
+	ok, trial := b.allow()
+	if !ok {
+		return ErrCircuitOpen
+	}
+	err := b.Sender.Send(event)
+	b.result(err, trial)
+	return err
+}
+
+// allow reports whether a send may go through and whether it is the trial
+// started once the cooldown has passed
+func (b *circuitBreaker) allow() (ok, trial bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return true, false
+	}
+	if b.trial || time.Since(b.openedAt) < circuitCooldown {
+		return false, false
+	}
+	b.trial = true
+	return true, true
+}
+
+// result counts a send's outcome, opening or closing the circuit
+func (b *circuitBreaker) result(err error, trial bool) {
+	b.mu.Lock()
+	if trial {
+		b.trial = false
+	}
+	opened := false
+	switch {
+	case errors.Is(err, ErrBudgetExhausted):
+		// Nothing was sent, so it says nothing about the destination
+	case err == nil:
+		b.failures = 0
+		b.openedAt = time.Time{}
+	case trial:
+		b.openedAt = time.Now()
+	default:
+		b.failures++
+		if b.openedAt.IsZero() && b.failures >= circuitThreshold {
+			b.openedAt = time.Now()
+			opened = true
+		}
+	}
+	b.mu.Unlock()
+
+	if opened && b.onOpen != nil {
+		b.onOpen(err)
+	}
+}
@@ -0,0 +1,171 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// collectd network protocol part types
+const (
+	collectdPartHost           = 0x0000
+	collectdPartPlugin         = 0x0002
+	collectdPartPluginInstance = 0x0003
+	collectdPartType           = 0x0004
+	collectdPartTypeInstance   = 0x0005
+	collectdPartValues         = 0x0006
+	collectdPartTimeHR         = 0x0008
+	collectdPartIntervalHR     = 0x0009
+
+	collectdValueGauge = 1
+
+	// collectdMaxPacket is the default receive buffer size of collectd's network plugin
+	collectdMaxPacket = 1452
+	// collectdMaxName is collectd's DATA_MAX_NAME_LEN minus the terminating NUL
+	collectdMaxName = 127
+
+	// collectdInterval is collectd's default 10 second interval in high resolution units
+	collectdInterval = 10 << 30
+)
+
+// collectdIgnoredDims are dimensions shared by every metric on a host; they
+// would only lengthen the plugin instance
+var collectdIgnoredDims = map[string]bool{"region": true, "environment": true}
+
+// CollectdSender sends metric events using the collectd binary network protocol
+type CollectdSender struct {
+	conn   net.Conn
+	config models.DestinationConfig
+}
+
+// NewCollectdSender creates a new collectd sender
+func NewCollectdSender(config models.DestinationConfig) (*CollectdSender, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("collectd host is required")
+	}
+	port := config.Port
+	if port == 0 {
+		port = 25826
+	}
+
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(config.Host, strconv.Itoa(port)), 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to collectd server: %w", err)
+	}
+
+	return &CollectdSender{
+		conn:   conn,
+		config: config,
+	}, nil
+}
+
+// Send encodes each metric in the event as a gauge value list. A metric named
+// "cpu.percent.total" becomes plugin "cpu", type "gauge" and type instance
+// "percent.total"; non-host dimension values form the plugin instance.
+func (s *CollectdSender) Send(event *models.GeneratedEvent) error {
+	points, err := extractMetrics(event)
+	if err != nil {
+		return err
+	}
+
+	var packet bytes.Buffer
+	for _, p := range points {
+		part := s.encodeValueList(p)
+		if packet.Len() > 0 && packet.Len()+len(part) > collectdMaxPacket {
+			if _, err := s.conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		packet.Write(part)
+	}
+
+	_, err = s.conn.Write(packet.Bytes())
+	return err
+}
+
+// encodeValueList builds the parts describing a single gauge value. Every
+// identifying part is repeated so each value list stands alone in a packet.
+func (s *CollectdSender) encodeValueList(p metricPoint) []byte {
+	name := s.config.MetricPrefix + p.Name
+	plugin, typeInstance, _ := strings.Cut(name, ".")
+
+	instances := make([]string, 0, len(p.Dimensions))
+	for _, k := range sortedKeys(p.Dimensions) {
+		if !collectdIgnoredDims[k] {
+			instances = append(instances, strings.ReplaceAll(p.Dimensions[k], "/", "_"))
+		}
+	}
+
+	var buf bytes.Buffer
+	collectdString(&buf, collectdPartHost, p.Host)
+	collectdNumeric(&buf, collectdPartTimeHR, collectdTime(p.Time))
+	collectdNumeric(&buf, collectdPartIntervalHR, collectdInterval)
+	collectdString(&buf, collectdPartPlugin, plugin)
+	collectdString(&buf, collectdPartPluginInstance, strings.Join(instances, "-"))
+	collectdString(&buf, collectdPartType, "gauge")
+	collectdString(&buf, collectdPartTypeInstance, typeInstance)
+
+	// Values part: header, count, data source types, then the values. Gauges
+	// are the one value type encoded little-endian.
+	binary.Write(&buf, binary.BigEndian, uint16(collectdPartValues))
+	binary.Write(&buf, binary.BigEndian, uint16(4+2+1+8))
+	binary.Write(&buf, binary.BigEndian, uint16(1))
+	buf.WriteByte(collectdValueGauge)
+	binary.Write(&buf, binary.LittleEndian, math.Float64bits(p.Value))
+
+	return buf.Bytes()
+}
+
+// collectdString writes a NUL-terminated string part
+func collectdString(buf *bytes.Buffer, partType uint16, value string) {
+	if len(value) > collectdMaxName {
+		value = value[:collectdMaxName]
+	}
+	binary.Write(buf, binary.BigEndian, partType)
+	binary.Write(buf, binary.BigEndian, uint16(4+len(value)+1))
+	buf.WriteString(value)
+	buf.WriteByte(0)
+}
+
+// collectdNumeric writes a 64-bit numeric part
+func collectdNumeric(buf *bytes.Buffer, partType uint16, value uint64) {
+	binary.Write(buf, binary.BigEndian, partType)
+	binary.Write(buf, binary.BigEndian, uint16(12))
+	binary.Write(buf, binary.BigEndian, value)
+}
+
+// collectdTime converts a time to collectd's high resolution format, seconds
+// in units of 2^-30
+func collectdTime(t time.Time) uint64 {
+	return uint64(t.Unix())<<30 | uint64(t.Nanosecond())*(1<<30)/1e9
+}
+
+// Test tests the collectd connection with a single gauge
+func (s *CollectdSender) Test() error {
+	s.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	defer s.conn.SetWriteDeadline(time.Time{})
+
+	_, err := s.conn.Write(s.encodeValueList(metricPoint{
+		Name:  "siem_event_generator.test",
+		Value: 1,
+		Time:  time.Now(),
+		Host:  "siem-event-generator",
+	}))
+	return err
+}
+
+// Close closes the collectd connection
+func (s *CollectdSender) Close() error {
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
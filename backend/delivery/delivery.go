@@ -1,11 +1,17 @@
 package delivery
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"siem-event-generator/models"
+	"siem-event-generator/secrets"
 )
 
+// ErrAuthFailed is returned when a destination rejects its credentials
+var ErrAuthFailed = errors.New("authentication failed")
+
 // Sender interface for all delivery methods
 type Sender interface {
 	Send(event *models.GeneratedEvent) error
@@ -13,17 +19,85 @@ type Sender interface {
 	Close() error
 }
 
-// GetSender returns the appropriate sender for a destination
+// GetSender returns the appropriate sender for a destination. Events are
+// changed by Hooks, anonymized according to Anonymization and then routed by
+// the destination's routing rules.
 func GetSender(dest *models.Destination) (Sender, error) {
+	sender, err := newSender(dest)
+	if err != nil {
+		return nil, err
+	}
+	if len(dest.Config.Routes) > 0 {
+		routed, err := newRouter(sender, dest)
+		if err != nil {
+			sender.Close()
+			return nil, err
+		}
+		sender = routed
+	}
+	return sendPipeline{Sender: sender}, nil
+}
+
+func newSender(dest *models.Destination) (Sender, error) {
+	if dest.Config.Proxy != "" && !supportsProxy(dest.Type) {
+		return nil, fmt.Errorf("proxy is not supported for %s destinations", dest.Type)
+	}
+
+	// Senders get the credentials themselves, not references to them
+	config, err := secrets.ResolveConfig(context.Background(), dest.Config)
+	if err != nil {
+		return nil, err
+	}
+	resolved := *dest
+	resolved.Config = config
+	dest = &resolved
+
 	switch dest.Type {
 	case models.DestinationTypeSyslogUDP:
 		return NewSyslogSender(dest.Config, "udp")
 	case models.DestinationTypeSyslogTCP:
 		return NewSyslogSender(dest.Config, "tcp")
 	case models.DestinationTypeHEC:
-		return NewHECSender(dest.Config)
+		s, err := NewHECSender(dest.Config)
+		if err != nil {
+			return nil, err
+		}
+		s.destID = dest.ID
+		return s, nil
 	case models.DestinationTypeFile:
 		return NewFileSender(dest.Config)
+	case models.DestinationTypeStatsD:
+		return NewStatsDSender(dest.Config)
+	case models.DestinationTypeCollectd:
+		return NewCollectdSender(dest.Config)
+	case models.DestinationTypeOTLP:
+		s, err := NewOTLPSender(dest.Config)
+		if err != nil {
+			return nil, err
+		}
+		s.destID = dest.ID
+		return s, nil
+	case models.DestinationTypeElasticsearch:
+		s, err := NewElasticsearchSender(dest.Config)
+		if err != nil {
+			return nil, err
+		}
+		s.destID = dest.ID
+		return s, nil
+	case models.DestinationTypeSOAR:
+		s, err := NewSOARSender(dest.Config)
+		if err != nil {
+			return nil, err
+		}
+		s.destID = dest.ID
+		return s, nil
+	case models.DestinationTypeMock:
+		s, err := NewMockSender(dest.Config)
+		if err != nil {
+			return nil, err
+		}
+		s.destID = dest.ID
+		return s, nil
 	default:
 		return nil, fmt.Errorf("unknown destination type: %s", dest.Type)
 	}
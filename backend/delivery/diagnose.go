@@ -0,0 +1,385 @@
+package delivery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+	"siem-event-generator/secrets"
+)
+
+// diagnoseTimeout bounds each network step of a diagnosis
+const diagnoseTimeout = 5 * time.Second
+
+// endpoint is where a destination connects to
+type endpoint struct {
+	host    string
+	port    string
+	network string // "tcp", "udp", or "" for files
+	tls     bool
+	http    bool     // Sent as HTTP requests
+	proxy   *url.URL // Outbound proxy, if any
+}
+
+// Diagnose checks a destination one step at a time: its configuration, DNS
+// resolution, the TCP connection, the proxy tunnel, the TLS certificate, its
+// credentials, and finally a test event sent through the destination's
+// sender. With a proxy, the DNS and TCP steps check the proxy. Steps after a
+// failure are skipped, so the first failed step names the problem.
+func Diagnose(ctx context.Context, dest *models.Destination) []models.DiagnosticStep {
+	d := &diagnosis{}
+
+	var ep endpoint
+	d.run("config", func() (string, error) {
+		// Checks below need the credentials, not references to them
+		config, err := secrets.ResolveConfig(ctx, dest.Config)
+		if err != nil {
+			return "", err
+		}
+		resolved := *dest
+		resolved.Config = config
+		dest = &resolved
+
+		ep, err = destinationEndpoint(dest)
+		if err != nil {
+			return "", err
+		}
+		if dest.Type == models.DestinationTypeMock {
+			return "in-memory sink; events are discarded", nil
+		}
+		if ep.network == "" {
+			return "writes to " + dest.Config.FilePath, nil
+		}
+		if ep.tls {
+			if _, err := tlsConfig(dest.Config); err != nil {
+				return "", err
+			}
+		}
+		detail := fmt.Sprintf("%s %s", ep.network, net.JoinHostPort(ep.host, ep.port))
+		if ep.proxy != nil {
+			detail += " via " + ep.proxy.Scheme + " proxy " + proxyAddress(ep.proxy)
+		}
+		return detail, nil
+	})
+
+	// Without a proxy the DNS and TCP steps check the destination itself
+	connectHost, connectAddr := ep.host, net.JoinHostPort(ep.host, ep.port)
+	if ep.proxy != nil {
+		connectHost, connectAddr = ep.proxy.Hostname(), proxyAddress(ep.proxy)
+	}
+
+	if ep.network != "" {
+		d.run("dns", func() (string, error) {
+			if net.ParseIP(connectHost) != nil {
+				return "IP address, no lookup needed", nil
+			}
+			ctx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+			defer cancel()
+			addrs, err := net.DefaultResolver.LookupHost(ctx, connectHost)
+			if err != nil {
+				return "", err
+			}
+			return "resolved " + connectHost + " to " + strings.Join(addrs, ", "), nil
+		})
+	}
+
+	switch ep.network {
+	case "tcp":
+		d.run("tcp", func() (string, error) {
+			dialer := net.Dialer{Timeout: diagnoseTimeout}
+			conn, err := dialer.DialContext(ctx, "tcp", connectAddr)
+			if err != nil {
+				return "", err
+			}
+			defer conn.Close()
+			return "connected to " + conn.RemoteAddr().String(), nil
+		})
+	case "udp":
+		d.skip("tcp", "UDP is connectionless; delivery cannot be confirmed")
+	}
+
+	if ep.proxy != nil {
+		if ep.http && !ep.tls && ep.proxy.Scheme != "socks5" {
+			d.skip("proxy", "plain HTTP requests are forwarded by the proxy; the test event checks them")
+		} else {
+			d.run("proxy", func() (string, error) {
+				dial, err := tcpDialer(dest.Config, &net.Dialer{Timeout: diagnoseTimeout})
+				if err != nil {
+					return "", err
+				}
+				ctx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+				defer cancel()
+				conn, err := dial(ctx, "tcp", net.JoinHostPort(ep.host, ep.port))
+				if err != nil {
+					return "", err
+				}
+				conn.Close()
+				return "tunnel to " + net.JoinHostPort(ep.host, ep.port) + " opened", nil
+			})
+		}
+	}
+
+	if ep.tls {
+		d.runStatus("tls", func() (string, string, error) {
+			return checkTLS(ctx, ep, dest.Config)
+		})
+	}
+
+	var testErr error
+	d.run("test_event", func() (string, error) {
+		sender, err := GetSender(dest)
+		if err != nil {
+			testErr = err
+			return "", fmt.Errorf("failed to create sender: %w", err)
+		}
+		defer sender.Close()
+		testErr = sender.Test()
+		if testErr != nil {
+			return "", testErr
+		}
+		return "test event accepted", nil
+	})
+
+	// The test event is what checks credentials; the auth step reports its
+	// outcome and is listed before it
+	if hasCredentials(dest) {
+		test := d.steps[len(d.steps)-1]
+		auth := models.DiagnosticStep{Name: "auth", Status: models.DiagnosticSkipped}
+		switch {
+		case test.Status == models.DiagnosticSkipped:
+		case testErr == nil:
+			auth.Status = models.DiagnosticOK
+			auth.Detail = "credentials accepted"
+		case errors.Is(testErr, ErrAuthFailed):
+			auth.Status = models.DiagnosticFailed
+			auth.Error = testErr.Error()
+		default:
+			auth.Detail = "test event failed before credentials were confirmed"
+		}
+		d.steps = append(d.steps[:len(d.steps)-1], auth, test)
+	}
+
+	return d.steps
+}
+
+// diagnosis collects the steps of one Diagnose call
+type diagnosis struct {
+	steps  []models.DiagnosticStep
+	failed bool
+}
+
+// run records a step that passes unless check returns an error
+func (d *diagnosis) run(name string, check func() (string, error)) {
+	d.runStatus(name, func() (string, string, error) {
+		detail, err := check()
+		return models.DiagnosticOK, detail, err
+	})
+}
+
+// runStatus records a step whose check chooses its own passing status
+func (d *diagnosis) runStatus(name string, check func() (string, string, error)) {
+	if d.failed {
+		d.skip(name, "")
+		return
+	}
+	start := time.Now()
+	status, detail, err := check()
+	step := models.DiagnosticStep{
+		Name:       name,
+		Status:     status,
+		DurationMs: time.Since(start).Milliseconds(),
+		Detail:     detail,
+	}
+	if err != nil {
+		step.Status = models.DiagnosticFailed
+		step.Error = err.Error()
+		d.failed = true
+	}
+	d.steps = append(d.steps, step)
+}
+
+func (d *diagnosis) skip(name, detail string) {
+	d.steps = append(d.steps, models.DiagnosticStep{Name: name, Status: models.DiagnosticSkipped, Detail: detail})
+}
+
+// destinationEndpoint validates the settings a destination needs to connect
+// and returns where it connects to
+func destinationEndpoint(dest *models.Destination) (endpoint, error) {
+	ep, err := destinationTarget(dest)
+	if err != nil || dest.Config.Proxy == "" {
+		return ep, err
+	}
+	if !supportsProxy(dest.Type) {
+		return endpoint{}, fmt.Errorf("proxy is not supported for %s destinations", dest.Type)
+	}
+	ep.proxy, err = parseProxy(dest.Config.Proxy)
+	return ep, err
+}
+
+// destinationTarget returns the address a destination sends to
+func destinationTarget(dest *models.Destination) (endpoint, error) {
+	cfg := dest.Config
+	switch dest.Type {
+	case models.DestinationTypeHEC, models.DestinationTypeOTLP, models.DestinationTypeElasticsearch,
+		models.DestinationTypeSOAR:
+		if cfg.URL == "" {
+			return endpoint{}, fmt.Errorf("url is required")
+		}
+		if dest.Type == models.DestinationTypeHEC && cfg.Token == "" {
+			return endpoint{}, fmt.Errorf("token is required")
+		}
+		if dest.Type == models.DestinationTypeSOAR && cfg.Format != soarTines && cfg.Token == "" {
+			return endpoint{}, fmt.Errorf("token is required")
+		}
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return endpoint{}, fmt.Errorf("invalid url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return endpoint{}, fmt.Errorf("url must start with http:// or https://")
+		}
+		if u.Hostname() == "" {
+			return endpoint{}, fmt.Errorf("url has no host")
+		}
+		ep := endpoint{host: u.Hostname(), port: u.Port(), network: "tcp", tls: u.Scheme == "https", http: true}
+		if ep.port == "" {
+			ep.port = "80"
+			if ep.tls {
+				ep.port = "443"
+			}
+		}
+		return ep, nil
+
+	case models.DestinationTypeSyslogUDP, models.DestinationTypeSyslogTCP,
+		models.DestinationTypeStatsD, models.DestinationTypeCollectd:
+		if cfg.Host == "" {
+			return endpoint{}, fmt.Errorf("host is required")
+		}
+		port := cfg.Port
+		switch {
+		case port == 0 && dest.Type == models.DestinationTypeStatsD:
+			port = 8125
+		case port == 0 && dest.Type == models.DestinationTypeCollectd:
+			port = 25826
+		case port < 1 || port > 65535:
+			return endpoint{}, fmt.Errorf("port must be between 1 and 65535")
+		}
+		network := "udp"
+		if dest.Type == models.DestinationTypeSyslogTCP {
+			network = "tcp"
+		}
+		useTLS := dest.Type == models.DestinationTypeSyslogTCP && cfg.TLS
+		return endpoint{host: cfg.Host, port: strconv.Itoa(port), network: network, tls: useTLS}, nil
+
+	case models.DestinationTypeFile:
+		if cfg.FilePath == "" {
+			return endpoint{}, fmt.Errorf("file_path is required")
+		}
+		return endpoint{}, nil
+
+	case models.DestinationTypeMock:
+		if _, err := NewMockSender(cfg); err != nil {
+			return endpoint{}, err
+		}
+		return endpoint{}, nil
+
+	default:
+		return endpoint{}, fmt.Errorf("unsupported destination type: %s", dest.Type)
+	}
+}
+
+// checkTLS validates the server's certificate chain and host name against
+// the destination's CA bundle or the system roots, presenting its client
+// certificate if it has one. An untrusted certificate is only a warning
+// when the sender does not verify, since it will be accepted.
+func checkTLS(ctx context.Context, ep endpoint, config models.DestinationConfig) (string, string, error) {
+	cfg, err := tlsConfig(config)
+	if err != nil {
+		return "", "", err
+	}
+	verify := !cfg.InsecureSkipVerify
+	cfg.ServerName = ep.host
+	cfg.InsecureSkipVerify = true // Verified below so the certificate can be described either way
+
+	ctx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+	defer cancel()
+	dial, err := tcpDialer(config, &net.Dialer{})
+	if err != nil {
+		return "", "", err
+	}
+	raw, err := dial(ctx, "tcp", net.JoinHostPort(ep.host, ep.port))
+	if err != nil {
+		return "", "", err
+	}
+	conn := tls.Client(raw, cfg)
+	defer conn.Close()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return "", "", fmt.Errorf("TLS handshake failed: %w", err)
+	}
+
+	state := conn.ConnectionState()
+	leaf := state.PeerCertificates[0]
+	detail := fmt.Sprintf("%s, certificate for %s issued by %s, expires %s",
+		tls.VersionName(state.Version), certSubject(leaf), certIssuer(leaf),
+		leaf.NotAfter.Format("2006-01-02"))
+
+	if len(cfg.Certificates) > 0 {
+		detail += ", client certificate presented"
+	}
+
+	opts := x509.VerifyOptions{DNSName: ep.host, Roots: cfg.RootCAs, Intermediates: x509.NewCertPool()}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		if !verify {
+			return models.DiagnosticWarning, detail + "; not trusted, accepted because verify_ssl is off: " + err.Error(), nil
+		}
+		return "", detail, fmt.Errorf("certificate not trusted: %w", err)
+	}
+	return models.DiagnosticOK, detail, nil
+}
+
+// certSubject names the certificate's subject, falling back to its first
+// subject alternative name
+func certSubject(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.IPAddresses) > 0:
+		return cert.IPAddresses[0].String()
+	}
+	return "(no name)"
+}
+
+// certIssuer names the certificate's issuer
+func certIssuer(cert *x509.Certificate) string {
+	if cert.Issuer.CommonName != "" {
+		return cert.Issuer.CommonName
+	}
+	if len(cert.Issuer.Organization) > 0 {
+		return cert.Issuer.Organization[0]
+	}
+	return "(unnamed issuer)"
+}
+
+// hasCredentials reports whether the destination authenticates its sends
+func hasCredentials(dest *models.Destination) bool {
+	switch dest.Type {
+	case models.DestinationTypeHEC:
+		return true
+	case models.DestinationTypeOTLP, models.DestinationTypeElasticsearch, models.DestinationTypeSOAR:
+		return dest.Config.Token != "" || len(dest.Config.Headers) > 0
+	}
+	return false
+}
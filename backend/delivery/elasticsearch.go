@@ -0,0 +1,332 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// ElasticsearchSender sends events as the documents Beats ship, BatchSize
+// events per request. Format "bulk" (the default) indexes them through the
+// Elasticsearch _bulk API under the URL, each into its data stream with its
+// ingest pipeline; "logstash" posts them with their @metadata as
+// newline-delimited JSON to a Logstash http input at the URL.
+type ElasticsearchSender struct {
+	client *http.Client
+	config models.DestinationConfig
+	destID string // Batch metrics key
+
+	mu     sync.Mutex
+	buffer []map[string]interface{}
+	timer  *batchTimer
+}
+
+// esBulkResponse is the part of a _bulk response reporting failed items
+type esBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// NewElasticsearchSender creates a new Elasticsearch sender
+func NewElasticsearchSender(config models.DestinationConfig) (*ElasticsearchSender, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("Elasticsearch URL is required")
+	}
+
+	switch config.Format {
+	case "", "bulk", "logstash":
+	default:
+		return nil, fmt.Errorf("unsupported format %q: use bulk or logstash", config.Format)
+	}
+
+	if err := validateCompression(config.Compression); err != nil {
+		return nil, err
+	}
+
+	tlsCfg, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc, err := httpProxy(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: tlsCfg,
+		Proxy:           proxyFunc,
+	}
+
+	batchSize := config.BatchSize
+	if batchSize == 0 {
+		batchSize = 100
+	}
+
+	return &ElasticsearchSender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+		},
+		config: config,
+		buffer: make([]map[string]interface{}, 0, batchSize),
+		timer:  newBatchTimer(config),
+	}, nil
+}
+
+// Send sends an event to Elasticsearch
+func (e *ElasticsearchSender) Send(event *models.GeneratedEvent) error {
+	return e.SendRouted(event, models.Route{})
+}
+
+// SendRouted sends an event to Elasticsearch with the route's index taking
+// precedence over the destination's and the event's data stream
+func (e *ElasticsearchSender) SendRouted(event *models.GeneratedEvent, route models.Route) error {
+	doc, _, _ := generators.BeatsDocument(event)
+	if index := firstNonEmpty(route.Index, e.config.Index); index != "" {
+		setIndex(doc, index)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.buffer = append(e.buffer, doc)
+
+	// Flush if buffer is full
+	if len(e.buffer) >= e.config.BatchSize || e.config.BatchSize == 0 {
+		return e.flush(flushSize)
+	}
+
+	// Send a partial batch after the flush interval
+	e.timer.arm(func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.flush(flushInterval)
+	})
+	return nil
+}
+
+// setIndex sends a Beats document to another index. An index named like a
+// data stream, type-dataset-namespace, also replaces the document's
+// data_stream fields, which Elasticsearch requires to match its name.
+func setIndex(doc map[string]interface{}, index string) {
+	metadata := doc["@metadata"].(map[string]interface{})
+	metadata["raw_index"] = index
+
+	parts := strings.Split(index, "-")
+	if len(parts) != 3 || (parts[0] != "logs" && parts[0] != "metrics") {
+		return
+	}
+	doc["data_stream"] = map[string]interface{}{
+		"type":      parts[0],
+		"dataset":   parts[1],
+		"namespace": parts[2],
+	}
+	if ecsEvent, ok := doc["event"].(map[string]interface{}); ok {
+		ecsEvent["dataset"] = parts[1]
+	}
+}
+
+// flush sends all buffered documents in one request; e.mu must be held
+func (e *ElasticsearchSender) flush(trigger string) error {
+	e.timer.stop()
+	if len(e.buffer) == 0 {
+		return nil
+	}
+
+	err := e.post(trigger)
+	if err == nil {
+		// Clear the buffer
+		e.buffer = e.buffer[:0]
+	}
+	return err
+}
+
+// post sends the buffered documents and records the batch
+func (e *ElasticsearchSender) post(trigger string) (err error) {
+	size, wire := 0, 0
+	defer func() {
+		BatchMetrics.record(e.destID, len(e.buffer), size, wire, trigger, err)
+	}()
+
+	body, err := e.buildBody()
+	if err != nil {
+		return err
+	}
+
+	target := e.config.URL
+	if e.config.Format != "logstash" {
+		target = strings.TrimSuffix(target, "/") + "/_bulk"
+	}
+	req, sent, err := newBodyRequest(target, body, e.config.Compression)
+	if err != nil {
+		return err
+	}
+	e.setHeaders(req)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Elasticsearch returned status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	if e.config.Format != "logstash" {
+		if err := bulkItemError(respBody); err != nil {
+			return err
+		}
+	}
+
+	size, wire = len(body), sent
+	return nil
+}
+
+// buildBody encodes the buffer as a _bulk request, a create action naming
+// the data stream and pipeline before each document, or for Logstash as one
+// document per line with its @metadata
+func (e *ElasticsearchSender) buildBody() ([]byte, error) {
+	var body bytes.Buffer
+	for _, doc := range e.buffer {
+		if e.config.Format != "logstash" {
+			metadata := doc["@metadata"].(map[string]interface{})
+			action := map[string]interface{}{"_index": metadata["raw_index"]}
+			if pipeline, ok := metadata["pipeline"]; ok {
+				action["pipeline"] = pipeline
+			}
+			data, err := json.Marshal(map[string]interface{}{"create": action})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal bulk action: %w", err)
+			}
+			body.Write(data)
+			body.WriteByte('\n')
+
+			// Beats only send @metadata to Logstash
+			source := make(map[string]interface{}, len(doc))
+			for k, v := range doc {
+				if k != "@metadata" {
+					source[k] = v
+				}
+			}
+			doc = source
+		}
+
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event: %w", err)
+		}
+		body.Write(data)
+		body.WriteByte('\n')
+	}
+	return body.Bytes(), nil
+}
+
+// bulkItemError returns the first failed item of a _bulk response, which
+// is 200 even when documents were rejected
+func bulkItemError(respBody []byte) error {
+	var bulkResp esBulkResponse
+	if err := json.Unmarshal(respBody, &bulkResp); err != nil {
+		return fmt.Errorf("invalid bulk response: %w", err)
+	}
+	if !bulkResp.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first error
+	for _, item := range bulkResp.Items {
+		for _, result := range item {
+			if result.Status < 300 {
+				continue
+			}
+			failed++
+			if first == nil {
+				first = fmt.Errorf("%s: %s", result.Error.Type, result.Error.Reason)
+			}
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return fmt.Errorf("Elasticsearch rejected %d of %d documents: %w", failed, len(bulkResp.Items), first)
+}
+
+// setHeaders adds the content type, the API key and any configured headers
+func (e *ElasticsearchSender) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	if e.config.Format != "logstash" {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	if e.config.Token != "" {
+		req.Header.Set("Authorization", "ApiKey "+e.config.Token)
+	}
+	for k, v := range e.config.Headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// Test tests the connection, fetching the cluster info from Elasticsearch
+// or posting a test document to Logstash
+func (e *ElasticsearchSender) Test() error {
+	var req *http.Request
+	var err error
+	if e.config.Format == "logstash" {
+		data, _ := json.Marshal(map[string]interface{}{
+			"@timestamp": time.Now().UTC().Format(time.RFC3339Nano),
+			"message":    "Connection test event",
+		})
+		req, _, err = newBodyRequest(e.config.URL, data, e.config.Compression)
+	} else {
+		req, err = http.NewRequest("GET", e.config.URL, nil)
+	}
+	if err != nil {
+		return err
+	}
+	e.setHeaders(req)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Elasticsearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: invalid API key", ErrAuthFailed)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Elasticsearch returned status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	return nil
+}
+
+// Close flushes any remaining documents and closes the sender
+func (e *ElasticsearchSender) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timer.close()
+	return e.flush(flushClose)
+}
@@ -0,0 +1,114 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"siem-event-generator/models"
+)
+
+// FanOut sends every event to several destinations, e.g. to compare how two
+// SIEMs parse the same data, and tracks each destination's results. A
+// failing destination does not stop delivery to the others.
+type FanOut struct {
+	targets []*fanOutTarget
+}
+
+type fanOutTarget struct {
+	id     string
+	name   string
+	sender Sender
+	sent   atomic.Int64
+	errors atomic.Int64
+
+	mu        sync.Mutex
+	lastError string
+}
+
+// NewFanOut creates a sender for each destination. Destinations listed more
+// than once are sent to once.
+func NewFanOut(dests []*models.Destination) (*FanOut, error) {
+	f := &FanOut{}
+	seen := make(map[string]bool, len(dests))
+	for _, dest := range dests {
+		if seen[dest.ID] {
+			continue
+		}
+		seen[dest.ID] = true
+
+		sender, err := GetSender(dest)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to create sender for %s: %w", dest.Name, err)
+		}
+		f.targets = append(f.targets, &fanOutTarget{id: dest.ID, name: dest.Name, sender: sender})
+	}
+	return f, nil
+}
+
+// Send delivers event to every destination. The error names each
+// destination that failed.
+func (f *FanOut) Send(event *models.GeneratedEvent) error {
+	var errs []error
+	for _, t := range f.targets {
+		if err := t.sender.Send(event); err != nil {
+			t.fail(err)
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+			continue
+		}
+		t.sent.Add(1)
+	}
+	return errors.Join(errs...)
+}
+
+// Test tests every destination's connection
+func (f *FanOut) Test() error {
+	var errs []error
+	for _, t := range f.targets {
+		if err := t.sender.Test(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes and closes every destination's sender
+func (f *FanOut) Close() error {
+	var errs []error
+	for _, t := range f.targets {
+		if err := t.sender.Close(); err != nil {
+			t.mu.Lock()
+			t.lastError = err.Error()
+			t.mu.Unlock()
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Results reports the events sent to and errors from each destination
+func (f *FanOut) Results() []models.DeliveryResult {
+	results := make([]models.DeliveryResult, 0, len(f.targets))
+	for _, t := range f.targets {
+		t.mu.Lock()
+		lastError := t.lastError
+		t.mu.Unlock()
+		results = append(results, models.DeliveryResult{
+			DestinationID: t.id,
+			Name:          t.name,
+			EventsSent:    t.sent.Load(),
+			Errors:        t.errors.Load(),
+			LastError:     lastError,
+		})
+	}
+	return results
+}
+
+func (t *fanOutTarget) fail(err error) {
+	t.errors.Add(1)
+	t.mu.Lock()
+	t.lastError = err.Error()
+	t.mu.Unlock()
+}
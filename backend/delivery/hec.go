@@ -2,21 +2,27 @@ package delivery
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"siem-event-generator/models"
 )
 
-// HECSender sends events to Splunk HTTP Event Collector
+// HECSender sends events to Splunk HTTP Event Collector, BatchSize events
+// per request
 type HECSender struct {
 	client *http.Client
 	config models.DestinationConfig
-	buffer []*hecEvent
+	destID string // Batch metrics key
+
+	mu      sync.Mutex
+	buffer  []*hecEvent
+	timer   *batchTimer
+	posting sync.Mutex // Held while a batch is posted, so batches go in order
 }
 
 // hecEvent represents a Splunk HEC event payload
@@ -48,10 +54,23 @@ func NewHECSender(config models.DestinationConfig) (*HECSender, error) {
 		return nil, fmt.Errorf("HEC token is required")
 	}
 
+	if err := validateCompression(config.Compression); err != nil {
+		return nil, err
+	}
+
+	tlsCfg, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc, err := httpProxy(config)
+	if err != nil {
+		return nil, err
+	}
+
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: !config.VerifySSL,
-		},
+		TLSClientConfig: tlsCfg,
+		Proxy:           proxyFunc,
 	}
 
 	client := &http.Client{
@@ -68,44 +87,76 @@ func NewHECSender(config models.DestinationConfig) (*HECSender, error) {
 		client: client,
 		config: config,
 		buffer: make([]*hecEvent, 0, batchSize),
+		timer:  newBatchTimer(config),
 	}, nil
 }
 
 // Send sends an event to HEC
 func (h *HECSender) Send(event *models.GeneratedEvent) error {
+	return h.SendRouted(event, models.Route{})
+}
+
+// SendRouted sends an event to HEC with the route's index, source and
+// sourcetype taking precedence over the destination's
+func (h *HECSender) SendRouted(event *models.GeneratedEvent, route models.Route) error {
+	host := "siem-event-generator"
+	if event.Host != "" {
+		host = event.Host
+	}
+
 	hecEvt := &hecEvent{
 		Time:       float64(event.Timestamp.Unix()) + float64(event.Timestamp.Nanosecond())/1e9,
-		Host:       "siem-event-generator",
-		Source:     h.config.Source,
-		Sourcetype: event.Sourcetype,
-		Index:      h.config.Index,
+		Host:       host,
+		Source:     firstNonEmpty(route.Source, h.config.Source),
+		Sourcetype: firstNonEmpty(route.Sourcetype, h.config.Sourcetype, event.Sourcetype),
+		Index:      firstNonEmpty(route.Index, h.config.Index),
 		Event:      event.RawEvent,
 	}
 
-	// Override sourcetype if specified in config
-	if h.config.Sourcetype != "" {
-		hecEvt.Sourcetype = h.config.Sourcetype
-	}
-
+	h.mu.Lock()
 	h.buffer = append(h.buffer, hecEvt)
-
-	// Flush if buffer is full
-	if len(h.buffer) >= h.config.BatchSize || h.config.BatchSize == 0 {
-		return h.flush()
+	full := len(h.buffer) >= h.config.BatchSize || h.config.BatchSize == 0
+	if !full {
+		// Send a partial batch after the flush interval
+		h.timer.arm(func() { h.flush(flushInterval) })
 	}
+	h.mu.Unlock()
 
+	if full {
+		return h.flush(flushSize)
+	}
 	return nil
 }
 
-// flush sends all buffered events to HEC
-func (h *HECSender) flush() error {
-	if len(h.buffer) == 0 {
+// flush sends all buffered events to HEC in one request. The buffer is
+// swapped out first, so events are buffered while the request is sent; a
+// batch that fails is dropped rather than kept to grow the next one.
+func (h *HECSender) flush(trigger string) error {
+	h.posting.Lock()
+	defer h.posting.Unlock()
+
+	h.mu.Lock()
+	h.timer.stop()
+	batch := h.buffer
+	h.buffer = make([]*hecEvent, 0, cap(batch))
+	h.mu.Unlock()
+
+	if len(batch) == 0 {
 		return nil
 	}
+	return h.post(batch, trigger)
+}
+
+// post sends a batch of events and records it
+func (h *HECSender) post(batch []*hecEvent, trigger string) (err error) {
+	size, wire := 0, 0
+	defer func() {
+		BatchMetrics.record(h.destID, len(batch), size, wire, trigger, err)
+	}()
 
 	// Build the request body (newline-delimited JSON)
 	var body bytes.Buffer
-	for _, evt := range h.buffer {
+	for _, evt := range batch {
 		data, err := json.Marshal(evt)
 		if err != nil {
 			return fmt.Errorf("failed to marshal event: %w", err)
@@ -114,9 +165,9 @@ func (h *HECSender) flush() error {
 		body.WriteByte('\n')
 	}
 
-	req, err := http.NewRequest("POST", h.config.URL, &body)
+	req, sent, err := newBodyRequest(h.config.URL, body.Bytes(), h.config.Compression)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
 	req.Header.Set("Authorization", "Splunk "+h.config.Token)
@@ -139,9 +190,7 @@ func (h *HECSender) flush() error {
 		return fmt.Errorf("HEC returned status %d: %s", resp.StatusCode, hecResp.Text)
 	}
 
-	// Clear the buffer
-	h.buffer = h.buffer[:0]
-
+	size, wire = body.Len(), sent
 	return nil
 }
 
@@ -160,9 +209,9 @@ func (h *HECSender) Test() error {
 		return fmt.Errorf("failed to marshal test event: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", h.config.URL, bytes.NewReader(data))
+	req, _, err := newBodyRequest(h.config.URL, data, h.config.Compression)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
 	req.Header.Set("Authorization", "Splunk "+h.config.Token)
@@ -174,8 +223,8 @@ func (h *HECSender) Test() error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusForbidden {
-		return fmt.Errorf("authentication failed: invalid HEC token")
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: invalid HEC token", ErrAuthFailed)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -190,5 +239,16 @@ func (h *HECSender) Test() error {
 
 // Close flushes any remaining events and closes the sender
 func (h *HECSender) Close() error {
-	return h.flush()
+	h.timer.close()
+	return h.flush(flushClose)
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
@@ -0,0 +1,136 @@
+package delivery
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// countLines returns the number of events in a HEC request body
+func countLines(r *http.Request) int {
+	n := 0
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		n++
+	}
+	return n
+}
+
+func newTestHEC(t *testing.T, url string, batchSize int) *HECSender {
+	t.Helper()
+	h, err := NewHECSender(models.DestinationConfig{URL: url, Token: "t", BatchSize: batchSize, FlushIntervalMs: 60000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func testEvent() *models.GeneratedEvent {
+	return &models.GeneratedEvent{RawEvent: "event", Timestamp: time.Now()}
+}
+
+func TestHECBuffersWhileBatchIsPosted(t *testing.T) {
+	release := make(chan struct{})
+	posted := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+
+	h := newTestHEC(t, server.URL, 1)
+	done := make(chan error)
+	go func() { done <- h.Send(testEvent()) }()
+	<-posted
+
+	// The first batch is still being posted; buffering must not wait for it
+	buffered := make(chan struct{})
+	go func() {
+		h.mu.Lock()
+		h.buffer = append(h.buffer, &hecEvent{Event: "next"})
+		h.mu.Unlock()
+		close(buffered)
+	}()
+	select {
+	case <-buffered:
+	case <-time.After(time.Second):
+		t.Fatal("buffer lock held while posting")
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHECDropsFailedBatch(t *testing.T) {
+	var fail atomic.Bool
+	var events atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events.Store(int64(countLines(r)))
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	h := newTestHEC(t, server.URL, 2)
+	fail.Store(true)
+	h.Send(testEvent())
+	if err := h.Send(testEvent()); err == nil {
+		t.Fatal("expected the failed batch to return an error")
+	}
+
+	fail.Store(false)
+	h.Send(testEvent())
+	if err := h.Send(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if n := events.Load(); n != 2 {
+		t.Errorf("batch after a failure sent %d events, want 2", n)
+	}
+}
+
+func TestBatchTimerStopAndClose(t *testing.T) {
+	timer := &batchTimer{interval: 10 * time.Millisecond}
+	var flushes atomic.Int32
+	flush := func() { flushes.Add(1) }
+
+	timer.arm(flush)
+	timer.stop()
+	time.Sleep(30 * time.Millisecond)
+	if n := flushes.Load(); n != 0 {
+		t.Fatalf("stopped timer flushed %d times", n)
+	}
+
+	timer.arm(flush)
+	time.Sleep(30 * time.Millisecond)
+	if n := flushes.Load(); n != 1 {
+		t.Fatalf("armed timer flushed %d times, want 1", n)
+	}
+
+	timer.close()
+	timer.arm(flush)
+	time.Sleep(30 * time.Millisecond)
+	if n := flushes.Load(); n != 1 {
+		t.Errorf("closed timer flushed again")
+	}
+}
+
+func TestBatchTimerStaleIntervalDoesNotFlush(t *testing.T) {
+	timer := &batchTimer{interval: time.Hour}
+	timer.arm(func() {})
+	n := timer.armed
+	timer.stop()
+	timer.arm(func() {})
+	if timer.fire(n) {
+		t.Error("an interval re-armed since fired")
+	}
+	if !timer.fire(timer.armed) {
+		t.Error("the pending interval did not fire")
+	}
+}
@@ -0,0 +1,432 @@
+package delivery
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	starlarkjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+
+	"siem-event-generator/models"
+)
+
+// hookMaxSteps bounds the work one script does per event, so a runaway
+// loop fails the send instead of stalling a noise run
+const hookMaxSteps = 1000000
+
+// HookFunc changes an event before it is sent. It may change event in place
+// or return a new one; returning an error fails the send.
+type HookFunc func(event *models.GeneratedEvent) (*models.GeneratedEvent, error)
+
+// goHook is a hook compiled into the server or loaded from a plugin
+type goHook struct {
+	name string
+	fn   HookFunc
+}
+
+// scriptHook is an event hook with its script loaded
+type scriptHook struct {
+	models.EventHook
+	transform starlark.Callable
+}
+
+// HookRunner runs the Go hooks and the enabled script hooks on each event
+type HookRunner struct {
+	mu      sync.RWMutex
+	goHooks []goHook
+	hooks   map[string]*scriptHook
+	order   []*scriptHook // Enabled hooks in the order they run
+}
+
+// Hooks is the global hook runner applied by every sender
+var Hooks = NewHookRunner()
+
+// NewHookRunner creates a hook runner with no hooks
+func NewHookRunner() *HookRunner {
+	return &HookRunner{hooks: make(map[string]*scriptHook)}
+}
+
+// RegisterHook adds a Go hook that runs on every event, before the script
+// hooks. Programs that embed the server call it at startup.
+func RegisterHook(name string, fn HookFunc) {
+	Hooks.mu.Lock()
+	defer Hooks.mu.Unlock()
+	Hooks.goHooks = append(Hooks.goHooks, goHook{name: name, fn: fn})
+}
+
+// LoadHookPlugins registers a Go hook for every .so plugin in dir. A plugin
+// exports Hook with HookFunc's signature and must be built against the same
+// version of this module as the server.
+func LoadHookPlugins(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("open hook plugin %s: %w", path, err)
+		}
+		sym, err := p.Lookup("Hook")
+		if err != nil {
+			return fmt.Errorf("hook plugin %s: %w", path, err)
+		}
+		fn, ok := sym.(func(*models.GeneratedEvent) (*models.GeneratedEvent, error))
+		if !ok {
+			return fmt.Errorf("hook plugin %s: Hook has type %T", path, sym)
+		}
+		RegisterHook(strings.TrimSuffix(filepath.Base(path), ".so"), fn)
+	}
+	return nil
+}
+
+// GoHooks returns the names of the registered Go hooks in the order they run
+func (h *HookRunner) GoHooks() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.goHooks))
+	for _, g := range h.goHooks {
+		names = append(names, g.name)
+	}
+	return names
+}
+
+// Get returns a script hook by ID
+func (h *HookRunner) Get(id string) (models.EventHook, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hook, ok := h.hooks[id]
+	if !ok {
+		return models.EventHook{}, false
+	}
+	return hook.EventHook, true
+}
+
+// List returns the script hooks in the order they run
+func (h *HookRunner) List() []models.EventHook {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hooks := make([]models.EventHook, 0, len(h.hooks))
+	for _, hook := range h.hooks {
+		hooks = append(hooks, hook.EventHook)
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hookLess(hooks[i], hooks[j])
+	})
+	return hooks
+}
+
+// Set adds or replaces a hook, loading its script if it is enabled. A
+// disabled hook's script is not loaded until the hook is enabled.
+func (h *HookRunner) Set(hook models.EventHook) error {
+	var transform starlark.Callable
+	if hook.Enabled {
+		var err error
+		if transform, err = loadHookScript(hook.Name, hook.Script); err != nil {
+			return err
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks[hook.ID] = &scriptHook{EventHook: hook, transform: transform}
+	h.reorder()
+	return nil
+}
+
+// Delete removes a script hook
+func (h *HookRunner) Delete(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.hooks[id]; !ok {
+		return false
+	}
+	delete(h.hooks, id)
+	h.reorder()
+	return true
+}
+
+// reorder rebuilds the list of enabled hooks; h.mu must be held
+func (h *HookRunner) reorder() {
+	h.order = h.order[:0:0]
+	for _, hook := range h.hooks {
+		if hook.Enabled {
+			h.order = append(h.order, hook)
+		}
+	}
+	sort.Slice(h.order, func(i, j int) bool {
+		return hookLess(h.order[i].EventHook, h.order[j].EventHook)
+	})
+}
+
+func hookLess(a, b models.EventHook) bool {
+	if a.Order != b.Order {
+		return a.Order < b.Order
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.ID < b.ID
+}
+
+// Apply runs the Go hooks, then the enabled script hooks for the event's
+// type, and returns the changed event. The caller's event is not modified
+// by script hooks; Go hooks are trusted to copy it if they need to.
+func (h *HookRunner) Apply(event *models.GeneratedEvent) (*models.GeneratedEvent, error) {
+	h.mu.RLock()
+	goHooks, order := h.goHooks, h.order
+	h.mu.RUnlock()
+
+	for _, g := range goHooks {
+		changed, err := g.fn(event)
+		if err != nil {
+			return nil, fmt.Errorf("hook %s: %w", g.name, err)
+		}
+		if changed != nil {
+			event = changed
+		}
+	}
+	for _, hook := range order {
+		if hook.EventType != "" && hook.EventType != event.Type {
+			continue
+		}
+		changed, err := runTransform(hook.transform, event)
+		if err != nil {
+			return nil, fmt.Errorf("hook %s: %w", hook.Name, err)
+		}
+		event = changed
+	}
+	return event, nil
+}
+
+// CheckHookScript reports whether a hook's script loads and defines
+// transform
+func CheckHookScript(hook models.EventHook) error {
+	_, err := loadHookScript(hook.Name, hook.Script)
+	return err
+}
+
+// RunHookScript loads script and runs it once on event, for trying a script
+// out before it is saved
+func RunHookScript(script string, event *models.GeneratedEvent) (*models.GeneratedEvent, error) {
+	transform, err := loadHookScript("test", script)
+	if err != nil {
+		return nil, err
+	}
+	return runTransform(transform, event)
+}
+
+// hookModules are the modules every hook script can use
+var hookModules = starlark.StringDict{
+	"json": starlarkjson.Module,
+	"hash": &starlarkstruct.Module{
+		Name: "hash",
+		Members: starlark.StringDict{
+			"md5":    hashBuiltin("md5", md5.New),
+			"sha1":   hashBuiltin("sha1", sha1.New),
+			"sha256": hashBuiltin("sha256", sha256.New),
+		},
+	},
+}
+
+// hashBuiltin returns a Starlark function giving the hex digest of a string
+func hashBuiltin(name string, newHash func() hash.Hash) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var s string
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &s); err != nil {
+			return nil, err
+		}
+		h := newHash()
+		h.Write([]byte(s))
+		return starlark.String(hex.EncodeToString(h.Sum(nil))), nil
+	})
+}
+
+// loadHookScript runs a script's top level and returns its transform
+// function. The script's globals are frozen, so the function can be called
+// from many senders at once.
+func loadHookScript(name, script string) (starlark.Callable, error) {
+	thread := &starlark.Thread{Name: name}
+	thread.SetMaxExecutionSteps(hookMaxSteps)
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, name+".star", script, hookModules)
+	if err != nil {
+		return nil, fmt.Errorf("load script: %w", err)
+	}
+	transform, ok := globals["transform"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("script must define transform(event)")
+	}
+	return transform, nil
+}
+
+// runTransform calls transform with the event as a dict and reads the
+// event back from the dict it returns
+func runTransform(transform starlark.Callable, event *models.GeneratedEvent) (*models.GeneratedEvent, error) {
+	fields, err := toStarlark(event.Fields)
+	if err != nil {
+		return nil, err
+	}
+	dict := starlark.NewDict(7)
+	dict.SetKey(starlark.String("id"), starlark.String(event.ID))
+	dict.SetKey(starlark.String("type"), starlark.String(event.Type))
+	dict.SetKey(starlark.String("event_id"), starlark.String(event.EventID))
+	dict.SetKey(starlark.String("timestamp"), starlark.String(event.Timestamp.Format(time.RFC3339Nano)))
+	dict.SetKey(starlark.String("sourcetype"), starlark.String(event.Sourcetype))
+	dict.SetKey(starlark.String("raw"), starlark.String(event.RawEvent))
+	dict.SetKey(starlark.String("fields"), fields)
+
+	thread := &starlark.Thread{Name: "hook"}
+	thread.SetMaxExecutionSteps(hookMaxSteps)
+	result, err := starlark.Call(thread, transform, starlark.Tuple{dict}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result == starlark.None {
+		return event, nil
+	}
+	out, ok := result.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("transform returned %s, want dict or None", result.Type())
+	}
+
+	changed := *event
+	for key, dst := range map[string]*string{"raw": &changed.RawEvent, "sourcetype": &changed.Sourcetype} {
+		if v, found, _ := out.Get(starlark.String(key)); found {
+			s, ok := starlark.AsString(v)
+			if !ok {
+				return nil, fmt.Errorf("event %s must be a string, got %s", key, v.Type())
+			}
+			*dst = s
+		}
+	}
+	if v, found, _ := out.Get(starlark.String("timestamp")); found {
+		s, _ := starlark.AsString(v)
+		ts, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("event timestamp must be RFC 3339: %w", err)
+		}
+		changed.Timestamp = ts
+	}
+	if v, found, _ := out.Get(starlark.String("fields")); found {
+		goFields, err := fromStarlark(v)
+		if err != nil {
+			return nil, fmt.Errorf("event fields: %w", err)
+		}
+		m, ok := goFields.(map[string]interface{})
+		if !ok && goFields != nil {
+			return nil, fmt.Errorf("event fields must be a dict, got %s", v.Type())
+		}
+		changed.Fields = m
+	}
+	return &changed, nil
+}
+
+// toStarlark converts a field value to Starlark. Types other than the JSON
+// ones the generators mostly use go through a JSON round trip.
+func toStarlark(v interface{}) (starlark.Value, error) {
+	switch t := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case string:
+		return starlark.String(t), nil
+	case bool:
+		return starlark.Bool(t), nil
+	case int:
+		return starlark.MakeInt(t), nil
+	case int64:
+		return starlark.MakeInt64(t), nil
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return starlark.MakeInt64(int64(t)), nil
+		}
+		return starlark.Float(t), nil
+	case map[string]interface{}:
+		d := starlark.NewDict(len(t))
+		for k, child := range t {
+			sv, err := toStarlark(child)
+			if err != nil {
+				return nil, err
+			}
+			d.SetKey(starlark.String(k), sv)
+		}
+		return d, nil
+	case []interface{}:
+		items := make([]starlark.Value, len(t))
+		for i, child := range t {
+			sv, err := toStarlark(child)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = sv
+		}
+		return starlark.NewList(items), nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return toStarlark(generic)
+}
+
+// fromStarlark converts a value returned by a script back to a field value
+func fromStarlark(v starlark.Value) (interface{}, error) {
+	switch t := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.String:
+		return string(t), nil
+	case starlark.Bool:
+		return bool(t), nil
+	case starlark.Int:
+		if n, ok := t.Int64(); ok {
+			return n, nil
+		}
+		return t.String(), nil
+	case starlark.Float:
+		return float64(t), nil
+	case *starlark.Dict:
+		m := make(map[string]interface{}, t.Len())
+		for _, item := range t.Items() {
+			k, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("dict key %s is not a string", item[0])
+			}
+			child, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			m[k] = child
+		}
+		return m, nil
+	case starlark.Indexable: // list and tuple
+		items := make([]interface{}, t.Len())
+		for i := range items {
+			child, err := fromStarlark(t.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = child
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("cannot use %s in an event", v.Type())
+}
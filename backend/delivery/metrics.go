@@ -0,0 +1,84 @@
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// metricPoint is a single metric sample decoded from a generated metric event
+type metricPoint struct {
+	Name       string
+	Value      float64
+	Time       time.Time
+	Host       string
+	Dimensions map[string]string // Excludes host
+}
+
+// hecMetric mirrors the Splunk HEC metric maps the metric generators emit
+type hecMetric struct {
+	Time   float64                `json:"time"`
+	Host   string                 `json:"host"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// extractMetrics decodes the metric samples from a metric event's raw JSON.
+// Events from non-metric generators return an error.
+func extractMetrics(event *models.GeneratedEvent) ([]metricPoint, error) {
+	var raw []hecMetric
+	if err := json.Unmarshal([]byte(event.RawEvent), &raw); err != nil {
+		return nil, fmt.Errorf("event %s/%s is not a metric event", event.Type, event.EventID)
+	}
+
+	points := make([]metricPoint, 0, len(raw))
+	for _, m := range raw {
+		name, _ := m.Fields["metric_name"].(string)
+		value, ok := m.Fields["_value"].(float64)
+		if name == "" || !ok {
+			continue
+		}
+
+		dims := make(map[string]string, len(m.Fields))
+		for k, v := range m.Fields {
+			if k == "metric_name" || k == "_value" || k == "host" {
+				continue
+			}
+			dims[k] = fmt.Sprint(v)
+		}
+
+		ts := event.Timestamp
+		if m.Time > 0 {
+			ts = time.Unix(int64(m.Time), 0)
+		}
+		host := m.Host
+		if host == "" {
+			host = "siem-event-generator"
+		}
+
+		points = append(points, metricPoint{
+			Name:       name,
+			Value:      value,
+			Time:       ts,
+			Host:       host,
+			Dimensions: dims,
+		})
+	}
+
+	if len(points) == 0 {
+		return nil, fmt.Errorf("event %s/%s contains no metrics", event.Type, event.EventID)
+	}
+	return points, nil
+}
+
+// sortedKeys returns the dimension names in a stable order
+func sortedKeys(dims map[string]string) []string {
+	keys := make([]string, 0, len(dims))
+	for k := range dims {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
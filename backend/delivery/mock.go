@@ -0,0 +1,116 @@
+package delivery
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// MockSender is an in-memory sink that discards events, to measure how fast
+// the generator runs without a SIEM and to test how it handles a slow or
+// failing destination. Events are batched like an HTTP destination; each
+// batch takes LatencyMs plus up to JitterMs and fails with probability
+// ErrorRate. Batches are recorded in BatchMetrics, failed ones as errors.
+type MockSender struct {
+	config models.DestinationConfig
+	destID string // Batch metrics key
+
+	mu      sync.Mutex
+	rng     *rand.Rand
+	pending int // Buffered events
+	size    int // Bytes of the buffered raw events
+	timer   *batchTimer
+}
+
+// NewMockSender creates a new mock sender
+func NewMockSender(config models.DestinationConfig) (*MockSender, error) {
+	if config.LatencyMs < 0 || config.JitterMs < 0 {
+		return nil, fmt.Errorf("latency_ms and jitter_ms cannot be negative")
+	}
+	if config.ErrorRate < 0 || config.ErrorRate > 1 {
+		return nil, fmt.Errorf("error_rate must be between 0 and 1")
+	}
+
+	return &MockSender{
+		config: config,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		timer:  newBatchTimer(config),
+	}, nil
+}
+
+// Send buffers an event and completes the batch once it is full
+func (m *MockSender) Send(event *models.GeneratedEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pending++
+	m.size += len(event.RawEvent) + 1
+
+	// Flush if buffer is full
+	if m.pending >= m.config.BatchSize || m.config.BatchSize == 0 {
+		return m.flush(flushSize)
+	}
+
+	// Complete a partial batch after the flush interval
+	m.timer.arm(func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.flush(flushInterval)
+	})
+	return nil
+}
+
+// flush completes the buffered batch; m.mu must be held. The batch is
+// dropped even when it fails, so injected errors do not pile up events.
+func (m *MockSender) flush(trigger string) error {
+	m.timer.stop()
+	if m.pending == 0 {
+		return nil
+	}
+
+	err := m.request()
+	if err != nil {
+		err = fmt.Errorf("mock sink rejected %d events: %w", m.pending, err)
+	}
+	BatchMetrics.record(m.destID, m.pending, m.size, m.size, trigger, err)
+	m.pending, m.size = 0, 0
+	return err
+}
+
+// request waits out the configured latency and returns an injected error
+// at the configured rate; m.mu must be held
+func (m *MockSender) request() error {
+	latency := time.Duration(m.config.LatencyMs) * time.Millisecond
+	if m.config.JitterMs > 0 {
+		latency += time.Duration(m.rng.Int63n(int64(m.config.JitterMs)+1)) * time.Millisecond
+	}
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	if m.config.ErrorRate > 0 && m.rng.Float64() < m.config.ErrorRate {
+		return fmt.Errorf("injected error")
+	}
+	return nil
+}
+
+// Test takes as long as a request and fails only when every request does
+func (m *MockSender) Test() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.request(); err != nil && m.config.ErrorRate >= 1 {
+		return fmt.Errorf("mock sink rejected the test event: %w", err)
+	}
+	return nil
+}
+
+// Close completes any buffered batch
+func (m *MockSender) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timer.close()
+	return m.flush(flushClose)
+}
@@ -0,0 +1,293 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// OTLPSender exports metric events as OTLP/HTTP JSON gauges and trace events
+// as OTLP spans, e.g. to an OpenTelemetry Collector at http://collector:4318.
+// Metrics go to /v1/metrics and traces to /v1/traces under the configured URL.
+type OTLPSender struct {
+	client *http.Client
+	config models.DestinationConfig
+	destID string // Batch metrics key
+
+	mu     sync.Mutex
+	buffer []metricPoint
+	spans  []json.RawMessage
+	events int // Buffered events, traces included
+	traces int // Buffered trace events
+	timer  *batchTimer
+}
+
+// otlpTraceRequest is an export request whose resourceSpans are passed
+// through from the trace generator unchanged
+type otlpTraceRequest struct {
+	ResourceSpans []json.RawMessage `json:"resourceSpans"`
+}
+
+// OTLP JSON encoding of the metrics data model
+type otlpAttribute struct {
+	Key   string            `json:"key"`
+	Value map[string]string `json:"value"`
+}
+
+type otlpDataPoint struct {
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+	TimeUnixNano string          `json:"timeUnixNano"`
+	AsDouble     float64         `json:"asDouble"`
+}
+
+type otlpMetric struct {
+	Name  string `json:"name"`
+	Gauge struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	} `json:"gauge"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   map[string]string `json:"scope"`
+	Metrics []*otlpMetric     `json:"metrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpRequest struct {
+	ResourceMetrics []*otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+// NewOTLPSender creates a new OTLP sender
+func NewOTLPSender(config models.DestinationConfig) (*OTLPSender, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("OTLP URL is required")
+	}
+
+	if err := validateCompression(config.Compression); err != nil {
+		return nil, err
+	}
+
+	tlsCfg, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc, err := httpProxy(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: tlsCfg,
+		Proxy:           proxyFunc,
+	}
+
+	return &OTLPSender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+		},
+		config: config,
+		timer:  newBatchTimer(config),
+	}, nil
+}
+
+// Send buffers the event's metrics or spans and exports them once BatchSize
+// events have accumulated
+func (o *OTLPSender) Send(event *models.GeneratedEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if event.Type == "otel_traces" {
+		var trace otlpTraceRequest
+		if err := json.Unmarshal([]byte(event.RawEvent), &trace); err != nil {
+			return fmt.Errorf("invalid trace event: %w", err)
+		}
+		o.spans = append(o.spans, trace.ResourceSpans...)
+		o.traces++
+	} else {
+		points, err := extractMetrics(event)
+		if err != nil {
+			return err
+		}
+		o.buffer = append(o.buffer, points...)
+	}
+	o.events++
+
+	if o.events >= o.config.BatchSize {
+		return o.flush(flushSize)
+	}
+
+	// Export a partial batch after the flush interval
+	o.timer.arm(func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.flush(flushInterval)
+	})
+	return nil
+}
+
+// flush exports all buffered metrics, one resource per host, and spans;
+// o.mu must be held
+func (o *OTLPSender) flush(trigger string) error {
+	o.timer.stop()
+
+	if len(o.buffer) > 0 {
+		size, wire, err := o.post("metrics", buildOTLPRequest(o.buffer, o.config.MetricPrefix))
+		BatchMetrics.record(o.destID, o.events-o.traces, size, wire, trigger, err)
+		if err != nil {
+			return err
+		}
+		o.buffer = o.buffer[:0]
+	}
+
+	if len(o.spans) > 0 {
+		size, wire, err := o.post("traces", &otlpTraceRequest{ResourceSpans: o.spans})
+		BatchMetrics.record(o.destID, o.traces, size, wire, trigger, err)
+		if err != nil {
+			return err
+		}
+		o.spans = o.spans[:0]
+	}
+
+	o.events, o.traces = 0, 0
+	return nil
+}
+
+// otlpEndpoint returns the export URL for a signal ("metrics" or "traces").
+// A URL already ending in /v1/<signal> has its signal swapped; any other URL
+// is treated as the collector base and gets /v1/<signal> appended.
+func otlpEndpoint(base, signal string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid OTLP URL: %w", err)
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if i := strings.LastIndex(path, "/v1/"); i >= 0 && !strings.Contains(path[i+4:], "/") {
+		path = path[:i]
+	}
+	u.Path = path + "/v1/" + signal
+	return u.String(), nil
+}
+
+// buildOTLPRequest groups points by host into resources and by name into gauges
+func buildOTLPRequest(points []metricPoint, prefix string) *otlpRequest {
+	req := &otlpRequest{}
+	resources := make(map[string]*otlpResourceMetrics)
+	metrics := make(map[string]*otlpMetric)
+
+	for _, p := range points {
+		rm, ok := resources[p.Host]
+		if !ok {
+			rm = &otlpResourceMetrics{}
+			rm.Resource.Attributes = []otlpAttribute{
+				otlpString("host.name", p.Host),
+				otlpString("service.name", "siem-event-generator"),
+			}
+			rm.ScopeMetrics = []otlpScopeMetrics{{
+				Scope: map[string]string{"name": "siem-event-generator"},
+			}}
+			resources[p.Host] = rm
+			req.ResourceMetrics = append(req.ResourceMetrics, rm)
+		}
+
+		name := prefix + p.Name
+		key := p.Host + "|" + name
+		m, ok := metrics[key]
+		if !ok {
+			m = &otlpMetric{Name: name}
+			metrics[key] = m
+			rm.ScopeMetrics[0].Metrics = append(rm.ScopeMetrics[0].Metrics, m)
+		}
+
+		attrs := make([]otlpAttribute, 0, len(p.Dimensions))
+		for _, k := range sortedKeys(p.Dimensions) {
+			attrs = append(attrs, otlpString(k, p.Dimensions[k]))
+		}
+		m.Gauge.DataPoints = append(m.Gauge.DataPoints, otlpDataPoint{
+			Attributes:   attrs,
+			TimeUnixNano: strconv.FormatInt(p.Time.UnixNano(), 10),
+			AsDouble:     p.Value,
+		})
+	}
+
+	return req
+}
+
+// otlpString builds a string-valued OTLP attribute
+func otlpString(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: map[string]string{"stringValue": value}}
+}
+
+// post sends an export request for a signal to the collector and returns
+// the body size before and after compression
+func (o *OTLPSender) post(signal string, payload interface{}) (int, int, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to marshal %s: %w", signal, err)
+	}
+
+	endpoint, err := otlpEndpoint(o.config.URL, signal)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	req, sent, err := newBodyRequest(endpoint, data, o.config.Compression)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if o.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.config.Token)
+	}
+	for k, v := range o.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return 0, 0, fmt.Errorf("%w: OTLP endpoint returned status %d", ErrAuthFailed, resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return 0, 0, fmt.Errorf("OTLP endpoint returned status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	return len(data), sent, nil
+}
+
+// Test sends an empty export request, which collectors accept without data
+func (o *OTLPSender) Test() error {
+	_, _, err := o.post("metrics", &otlpRequest{ResourceMetrics: []*otlpResourceMetrics{}})
+	return err
+}
+
+// Close flushes any remaining metrics and spans
+func (o *OTLPSender) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.timer.close()
+	return o.flush(flushClose)
+}
@@ -0,0 +1,160 @@
+package delivery
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+
+	"siem-event-generator/models"
+)
+
+// parseProxy validates a destination's proxy URL: http:// or https:// for
+// an HTTP CONNECT proxy, socks5:// for SOCKS5, with optional user:password
+func parseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy URL must start with http://, https:// or socks5://")
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("proxy URL has no host")
+	}
+	return u, nil
+}
+
+// proxyAddress returns the proxy's host:port, with the scheme's default port
+func proxyAddress(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "socks5":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// supportsProxy reports whether a destination type can send through a
+// proxy; UDP and file destinations cannot
+func supportsProxy(t models.DestinationType) bool {
+	switch t {
+	case models.DestinationTypeHEC, models.DestinationTypeOTLP, models.DestinationTypeElasticsearch,
+		models.DestinationTypeSOAR, models.DestinationTypeSyslogTCP:
+		return true
+	}
+	return false
+}
+
+// httpProxy returns the Transport.Proxy setting for an HTTP destination
+func httpProxy(config models.DestinationConfig) (func(*http.Request) (*url.URL, error), error) {
+	if config.Proxy == "" {
+		return nil, nil
+	}
+	u, err := parseProxy(config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(u), nil
+}
+
+// dialFunc opens TCP connections, directly or through a proxy
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// tcpDialer returns the dialer for a destination's TCP connections, which
+// tunnels through the destination's proxy when it has one
+func tcpDialer(config models.DestinationConfig, base *net.Dialer) (dialFunc, error) {
+	if config.Proxy == "" {
+		return base.DialContext, nil
+	}
+	u, err := parseProxy(config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "socks5" {
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		d, err := proxy.SOCKS5("tcp", proxyAddress(u), auth, base)
+		if err != nil {
+			return nil, err
+		}
+		return d.(proxy.ContextDialer).DialContext, nil
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialConnect(ctx, base, u, addr)
+	}, nil
+}
+
+// dialConnect opens a tunnel to addr through an HTTP proxy with CONNECT
+func dialConnect(ctx context.Context, base *net.Dialer, u *url.URL, addr string) (net.Conn, error) {
+	conn, err := base.DialContext(ctx, "tcp", proxyAddress(u))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+	if u.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("proxy TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed: %w", err)
+	}
+
+	// The target sends nothing until we do, so the reader holds no tunnel data
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		conn.Close()
+		return nil, fmt.Errorf("%w: proxy returned %s", ErrAuthFailed, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT to %s failed: %s", addr, resp.Status)
+	}
+	return conn, nil
+}
@@ -0,0 +1,222 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+	"siem-event-generator/secrets"
+)
+
+// Reconciliation defaults
+const (
+	splunkManagementPort = "8089"
+	defaultESCountIndex  = "logs-*" // Where Beats documents land without an index set
+	defaultESTimeField   = "@timestamp"
+
+	// Splunk counts events by the time they were indexed; their own times,
+	// which clock skew and fuzzing move, are only bounded this widely
+	eventTimeSlack = 24 * time.Hour
+)
+
+// CountIndexed counts the events dest indexed between from and to, using
+// its reconcile settings. It returns the search or request made, for the
+// report, along with the count.
+func CountIndexed(ctx context.Context, dest *models.Destination, from, to time.Time) (int64, string, error) {
+	if dest.Config.Reconcile == nil {
+		return 0, "", fmt.Errorf("destination %s has no reconcile settings", dest.Name)
+	}
+	// Counting needs the credentials, not references to them
+	config, err := secrets.ResolveConfig(ctx, dest.Config)
+	if err != nil {
+		return 0, "", err
+	}
+	client, err := reconcileClient(config)
+	if err != nil {
+		return 0, "", err
+	}
+
+	switch dest.Type {
+	case models.DestinationTypeHEC:
+		return countSplunk(ctx, client, config, from, to)
+	case models.DestinationTypeElasticsearch:
+		return countElasticsearch(ctx, client, config, from, to)
+	default:
+		return 0, "", fmt.Errorf("cannot count events indexed by %s destinations", dest.Type)
+	}
+}
+
+// reconcileClient builds an HTTP client with the destination's TLS and
+// proxy settings
+func reconcileClient(config models.DestinationConfig) (*http.Client, error) {
+	tlsCfg, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	proxyFunc, err := httpProxy(config)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsCfg, Proxy: proxyFunc},
+		Timeout:   2 * time.Minute,
+	}, nil
+}
+
+// countSplunk runs a oneshot search counting the events indexed into the
+// destination's index, sourcetype and source
+func countSplunk(ctx context.Context, client *http.Client, config models.DestinationConfig, from, to time.Time) (int64, string, error) {
+	rc := config.Reconcile
+	base := rc.URL
+	if base == "" {
+		u, err := url.Parse(config.URL)
+		if err != nil || u.Hostname() == "" {
+			return 0, "", fmt.Errorf("reconcile.url is required: cannot derive it from %q", config.URL)
+		}
+		base = "https://" + net.JoinHostPort(u.Hostname(), splunkManagementPort)
+	}
+	if rc.Token == "" && rc.Username == "" {
+		return 0, "", fmt.Errorf("reconcile needs a token or username to search Splunk")
+	}
+
+	terms := []string{"search", "index=" + strconv.Quote(firstNonEmpty(rc.Index, config.Index, "*"))}
+	if config.Sourcetype != "" {
+		terms = append(terms, "sourcetype="+strconv.Quote(config.Sourcetype))
+	}
+	if config.Source != "" {
+		terms = append(terms, "source="+strconv.Quote(config.Source))
+	}
+	if rc.Filter != "" {
+		terms = append(terms, rc.Filter)
+	}
+	terms = append(terms,
+		fmt.Sprintf("_index_earliest=%d _index_latest=%d", from.Unix(), to.Unix()+1),
+		fmt.Sprintf("earliest=%d latest=%d", from.Add(-eventTimeSlack).Unix(), to.Add(eventTimeSlack).Unix()),
+		"| stats count")
+	search := strings.Join(terms, " ")
+
+	form := url.Values{
+		"search":      {search},
+		"exec_mode":   {"oneshot"},
+		"output_mode": {"json"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(base, "/")+"/services/search/jobs", strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, search, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if rc.Username != "" {
+		req.SetBasicAuth(rc.Username, rc.Password)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+rc.Token)
+	}
+
+	var result struct {
+		Results []struct {
+			Count string `json:"count"`
+		} `json:"results"`
+	}
+	if err := doCountRequest(client, req, "Splunk", &result); err != nil {
+		return 0, search, err
+	}
+	if len(result.Results) == 0 {
+		return 0, search, nil
+	}
+	count, err := strconv.ParseInt(result.Results[0].Count, 10, 64)
+	if err != nil {
+		return 0, search, fmt.Errorf("Splunk returned an unreadable count %q", result.Results[0].Count)
+	}
+	return count, search, nil
+}
+
+// countElasticsearch counts the documents in the destination's index whose
+// time falls in the window, through the _count API
+func countElasticsearch(ctx context.Context, client *http.Client, config models.DestinationConfig, from, to time.Time) (int64, string, error) {
+	rc := config.Reconcile
+	base := rc.URL
+	if base == "" {
+		if config.Format == "logstash" {
+			return 0, "", fmt.Errorf("reconcile.url is required for a logstash destination")
+		}
+		base = config.URL
+	}
+
+	filters := []interface{}{
+		map[string]interface{}{"range": map[string]interface{}{
+			firstNonEmpty(rc.TimeField, defaultESTimeField): map[string]interface{}{
+				"gte": from.UTC().Format(time.RFC3339Nano),
+				"lte": to.UTC().Format(time.RFC3339Nano),
+			},
+		}},
+	}
+	if rc.Filter != "" {
+		filters = append(filters, map[string]interface{}{"query_string": map[string]interface{}{"query": rc.Filter}})
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{"bool": map[string]interface{}{"filter": filters}},
+	})
+
+	index := firstNonEmpty(rc.Index, config.Index, defaultESCountIndex)
+	target := strings.TrimSuffix(base, "/") + "/" + index + "/_count"
+	query := "POST " + target + " " + string(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, query, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case rc.Username != "":
+		req.SetBasicAuth(rc.Username, rc.Password)
+	case rc.Token != "":
+		req.Header.Set("Authorization", "ApiKey "+rc.Token)
+	case rc.URL == "":
+		// The cluster the destination sends to, with its credentials
+		if config.Token != "" {
+			req.Header.Set("Authorization", "ApiKey "+config.Token)
+		}
+		for k, v := range config.Headers {
+			req.Header.Set(k, v)
+		}
+	}
+
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	if err := doCountRequest(client, req, "Elasticsearch", &result); err != nil {
+		return 0, query, err
+	}
+	return result.Count, query, nil
+}
+
+// doCountRequest sends a count request and decodes its JSON answer
+func doCountRequest(client *http.Client, req *http.Request, system string, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", system, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", system, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: %s refused the reconcile credentials", ErrAuthFailed, system)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s returned status %d: %s", system, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", system, err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package delivery
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// ResolveDestination looks up the destinations routing rules forward to. It
+// is set by whatever owns the destination list; rules with a destination_id
+// fail to build while it is nil.
+var ResolveDestination func(id string) (*models.Destination, bool)
+
+// routedSender is implemented by senders that can place an event somewhere
+// other than their configured index, source and sourcetype
+type routedSender interface {
+	SendRouted(event *models.GeneratedEvent, route models.Route) error
+}
+
+// router applies a destination's routing rules in front of its sender
+type router struct {
+	Sender
+	rules   []models.RoutingRule
+	targets map[string]Sender // destination_id -> Sender for forwarding rules
+}
+
+// ValidateRoutes checks a destination's routing rules. selfID is the
+// destination's own ID, which a rule may not forward to.
+func ValidateRoutes(rules []models.RoutingRule, selfID string) error {
+	for i, rule := range rules {
+		for _, pattern := range rule.Sourcetypes {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("route %d: invalid sourcetype pattern %q", i+1, pattern)
+			}
+		}
+		if rule.DestinationID == "" && rule.Index == "" && rule.Source == "" && rule.Sourcetype == "" {
+			return fmt.Errorf("route %d: set index, source, sourcetype or destination_id", i+1)
+		}
+		if rule.DestinationID != "" && rule.DestinationID == selfID {
+			return fmt.Errorf("route %d: a destination cannot route to itself", i+1)
+		}
+	}
+	return nil
+}
+
+// newRouter wraps sender with dest's routing rules, creating a sender for
+// every destination the rules forward to. Forwarded events use the target's
+// plain sender: its own routing rules are not applied, so rules cannot loop.
+func newRouter(sender Sender, dest *models.Destination) (Sender, error) {
+	if err := ValidateRoutes(dest.Config.Routes, dest.ID); err != nil {
+		return nil, err
+	}
+
+	r := &router{
+		Sender:  sender,
+		rules:   dest.Config.Routes,
+		targets: make(map[string]Sender),
+	}
+	for _, rule := range r.rules {
+		id := rule.DestinationID
+		if id == "" || r.targets[id] != nil {
+			continue
+		}
+		var target *models.Destination
+		ok := false
+		if ResolveDestination != nil {
+			target, ok = ResolveDestination(id)
+		}
+		if !ok {
+			r.closeTargets()
+			return nil, fmt.Errorf("route target destination not found: %s", id)
+		}
+		s, err := newSender(target)
+		if err != nil {
+			r.closeTargets()
+			return nil, fmt.Errorf("route target %s: %w", target.Name, err)
+		}
+		r.targets[id] = s
+	}
+	return r, nil
+}
+
+// Send delivers event according to the first matching rule, or to the
+// destination's own sender when no rule matches
+func (r *router) Send(event *models.GeneratedEvent) error {
+	rule := r.match(event)
+	if rule == nil {
+		return r.Sender.Send(event)
+	}
+
+	sender := r.Sender
+	if rule.DestinationID != "" {
+		sender = r.targets[rule.DestinationID]
+	}
+
+	route := models.Route{Index: rule.Index, Source: rule.Source, Sourcetype: rule.Sourcetype}
+	if rs, ok := sender.(routedSender); ok {
+		return rs.SendRouted(event, route)
+	}
+	if route.Sourcetype != "" {
+		routed := *event
+		routed.Sourcetype = route.Sourcetype
+		event = &routed
+	}
+	return sender.Send(event)
+}
+
+// match returns the first rule that matches event
+func (r *router) match(event *models.GeneratedEvent) *models.RoutingRule {
+	category := ""
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if len(rule.EventTypes) > 0 && !containsFold(rule.EventTypes, event.Type) {
+			continue
+		}
+		if len(rule.Categories) > 0 {
+			if category == "" {
+				if g, ok := generators.GetGenerator(event.Type); ok {
+					category = g.GetEventType().Category
+				}
+			}
+			if !containsFold(rule.Categories, category) {
+				continue
+			}
+		}
+		if len(rule.Sourcetypes) > 0 && !matchAny(rule.Sourcetypes, event.Sourcetype) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// Close closes the destination's sender and every route target
+func (r *router) Close() error {
+	err := r.Sender.Close()
+	if terr := r.closeTargets(); err == nil {
+		err = terr
+	}
+	return err
+}
+
+func (r *router) closeTargets() error {
+	var first error
+	for _, s := range r.targets {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// containsFold reports whether list holds s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether s matches any of the wildcard patterns
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,486 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// SOAR platforms, set as the destination's Format
+const (
+	soarXSOAR      = "xsoar"
+	soarTines      = "tines"
+	soarSplunkSOAR = "splunk_soar"
+)
+
+// SOARSender posts alert-class events, one request per alert, to a SOAR
+// platform in the form it ingests: an XSOAR incident, a Splunk SOAR
+// container with an artifact, or a JSON document for a Tines webhook.
+// Events that are not alerts (see soarAlerts) are dropped.
+type SOARSender struct {
+	client *http.Client
+	config models.DestinationConfig
+	destID string // Batch metrics key
+}
+
+// soarAlert is an alert taken from an event, independent of the platform
+type soarAlert struct {
+	ID          string
+	Name        string
+	Description string
+	Severity    string // informational, low, medium, high or critical
+	Source      string // Product that raised the alert
+	Occurred    time.Time
+
+	// Indicators by CEF field name (sourceAddress, deviceHostname, ...),
+	// which Splunk SOAR artifacts use as is
+	Indicators map[string]string
+}
+
+// soarAlerts turn the alert-class events of an event type into alerts; an
+// event type missing here, or an extractor returning false, is not an alert
+var soarAlerts = map[string]func(event *models.GeneratedEvent) (soarAlert, bool){
+	"aws_guardduty": guardDutyAlert,
+	"suricata":      suricataAlert,
+	"crowdstrike":   crowdStrikeAlert,
+}
+
+// NewSOARSender creates a new SOAR webhook sender
+func NewSOARSender(config models.DestinationConfig) (*SOARSender, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("SOAR URL is required")
+	}
+
+	switch config.Format {
+	case soarXSOAR, soarSplunkSOAR:
+		if config.Token == "" {
+			return nil, fmt.Errorf("token is required for %s", config.Format)
+		}
+	case soarTines:
+	case "":
+		return nil, fmt.Errorf("format is required: xsoar, tines or splunk_soar")
+	default:
+		return nil, fmt.Errorf("unsupported format %q: use xsoar, tines or splunk_soar", config.Format)
+	}
+
+	tlsCfg, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc, err := httpProxy(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SOARSender{
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsCfg,
+				Proxy:           proxyFunc,
+			},
+			Timeout: 30 * time.Second,
+		},
+		config: config,
+	}, nil
+}
+
+// Send posts the event to the SOAR platform if it is an alert
+func (s *SOARSender) Send(event *models.GeneratedEvent) error {
+	extract, ok := soarAlerts[event.Type]
+	if !ok {
+		return nil
+	}
+	alert, ok := extract(event)
+	if !ok {
+		return nil
+	}
+	if alert.Occurred.IsZero() {
+		alert.Occurred = event.Timestamp
+	}
+	if alert.ID == "" {
+		alert.ID = event.ID
+	}
+	return s.post(event, alert)
+}
+
+// post sends one alert and records it as a batch of one
+func (s *SOARSender) post(event *models.GeneratedEvent, alert soarAlert) (err error) {
+	size := 0
+	defer func() {
+		BatchMetrics.record(s.destID, 1, size, size, flushSize, err)
+	}()
+
+	var payload interface{}
+	target := strings.TrimSuffix(s.config.URL, "/")
+	switch s.config.Format {
+	case soarXSOAR:
+		payload = xsoarIncident(event, alert, s.config.Sourcetype)
+		target += "/incident"
+	case soarSplunkSOAR:
+		payload = splunkSOARContainer(alert, firstNonEmpty(s.config.Sourcetype, "events"))
+		target += "/rest/container"
+	default:
+		payload = tinesPayload(event, alert)
+		target = s.config.URL
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, _, err := newBodyRequest(target, body, "")
+	if err != nil {
+		return err
+	}
+	s.setHeaders(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %s rejected the token", ErrAuthFailed, s.config.Format)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d: %s", s.config.Format, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	if s.config.Format == soarSplunkSOAR {
+		var result struct {
+			Failed  bool   `json:"failed"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &result) == nil && result.Failed {
+			return fmt.Errorf("Splunk SOAR rejected the container: %s", result.Message)
+		}
+	}
+
+	size = len(body)
+	return nil
+}
+
+// setHeaders adds the content type, the platform's token header and any
+// configured headers. XSOAR 8 also needs its API key ID as x-xdr-auth-id,
+// which is set through Headers.
+func (s *SOARSender) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if s.config.Token != "" {
+		switch s.config.Format {
+		case soarXSOAR:
+			req.Header.Set("Authorization", s.config.Token)
+		case soarSplunkSOAR:
+			req.Header.Set("ph-auth-token", s.config.Token)
+		default:
+			req.Header.Set("Authorization", "Bearer "+s.config.Token)
+		}
+	}
+	for k, v := range s.config.Headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// Test tests the connection and token: XSOAR's current user, a page of
+// Splunk SOAR containers, or a test document posted to the Tines webhook
+func (s *SOARSender) Test() error {
+	base := strings.TrimSuffix(s.config.URL, "/")
+	var req *http.Request
+	var err error
+	switch s.config.Format {
+	case soarXSOAR:
+		req, err = http.NewRequest("GET", base+"/user", nil)
+	case soarSplunkSOAR:
+		req, err = http.NewRequest("GET", base+"/rest/container?page_size=1", nil)
+	default:
+		data, _ := json.Marshal(map[string]interface{}{
+			"test":    true,
+			"message": "Connection test event",
+		})
+		req, _, err = newBodyRequest(s.config.URL, data, "")
+	}
+	if err != nil {
+		return err
+	}
+	s.setHeaders(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", s.config.Format, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: invalid token", ErrAuthFailed)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s returned status %d: %s", s.config.Format, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
+
+// Close closes the sender; alerts are not buffered
+func (s *SOARSender) Close() error {
+	return nil
+}
+
+// xsoarIncident is the body of XSOAR's create incident API, with the event
+// as rawJSON for mapping and the indicators as labels
+func xsoarIncident(event *models.GeneratedEvent, alert soarAlert, incidentType string) map[string]interface{} {
+	labels := []map[string]string{{"type": "Source", "value": alert.Source}}
+	for _, k := range sortedKeys(alert.Indicators) {
+		labels = append(labels, map[string]string{"type": k, "value": alert.Indicators[k]})
+	}
+	raw, _ := json.Marshal(event.Fields)
+
+	incident := map[string]interface{}{
+		"name":                alert.Name,
+		"details":             alert.Description,
+		"severity":            xsoarSeverity(alert.Severity),
+		"occurred":            alert.Occurred.UTC().Format(time.RFC3339),
+		"labels":              labels,
+		"rawJSON":             string(raw),
+		"createInvestigation": true,
+	}
+	if incidentType != "" {
+		incident["type"] = incidentType
+	}
+	return incident
+}
+
+// xsoarSeverity maps a severity to XSOAR's scale: 0.5 informational, 1
+// low, 2 medium, 3 high, 4 critical
+func xsoarSeverity(severity string) float64 {
+	switch severity {
+	case "informational":
+		return 0.5
+	case "low":
+		return 1
+	case "medium":
+		return 2
+	case "high":
+		return 3
+	case "critical":
+		return 4
+	}
+	return 0
+}
+
+// splunkSOARContainer is the body of Splunk SOAR's container API, creating
+// the container and its artifact in one request
+func splunkSOARContainer(alert soarAlert, label string) map[string]interface{} {
+	// Splunk SOAR's default severities stop at high
+	severity := alert.Severity
+	switch severity {
+	case "critical":
+		severity = "high"
+	case "informational", "":
+		severity = "low"
+	}
+	return map[string]interface{}{
+		"name":                   alert.Name,
+		"description":            alert.Description,
+		"label":                  label,
+		"severity":               severity,
+		"source_data_identifier": alert.ID,
+		"start_time":             alert.Occurred.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		"run_automation":         true,
+		"artifacts": []map[string]interface{}{{
+			"name":                   alert.Name,
+			"label":                  label,
+			"severity":               severity,
+			"source_data_identifier": alert.ID,
+			"cef":                    alert.Indicators,
+			"run_automation":         true,
+		}},
+	}
+}
+
+// tinesPayload is the document posted to a Tines webhook: the alert's
+// summary with the original event under "event"
+func tinesPayload(event *models.GeneratedEvent, alert soarAlert) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          alert.ID,
+		"name":        alert.Name,
+		"description": alert.Description,
+		"severity":    alert.Severity,
+		"source":      alert.Source,
+		"occurred":    alert.Occurred.UTC().Format(time.RFC3339),
+		"indicators":  alert.Indicators,
+		"event_type":  event.Type,
+		"event_id":    event.EventID,
+		"event":       event.Fields,
+	}
+}
+
+// guardDutyAlert makes an alert of a GuardDuty finding
+func guardDutyAlert(event *models.GeneratedEvent) (soarAlert, bool) {
+	f := event.Fields
+	title := soarString(f, "title")
+	if title == "" {
+		return soarAlert{}, false
+	}
+	alert := soarAlert{
+		ID:          soarString(f, "id"),
+		Name:        title,
+		Description: soarString(f, "description"),
+		Severity:    strings.ToLower(soarString(f, "severityLabel")),
+		Source:      "AWS GuardDuty",
+		Occurred:    soarTime(soarString(f, "updatedAt")),
+		Indicators: soarIndicators(map[string]string{
+			"sourceAddress": firstNonEmpty(
+				soarString(f, "service.action.networkConnectionAction.remoteIpDetails.ipAddressV4"),
+				soarString(f, "service.action.awsApiCallAction.remoteIpDetails.ipAddressV4"),
+				soarString(f, "service.action.portProbeAction.portProbeDetails.remoteIpDetails.ipAddressV4"),
+			),
+			"destinationAddress":   soarString(f, "resource.instanceDetails.networkInterfaces.privateIpAddress"),
+			"destinationDnsDomain": soarString(f, "service.action.dnsRequestAction.domain"),
+			"deviceHostname": firstNonEmpty(
+				soarString(f, "resource.instanceDetails.instanceId"),
+				soarString(f, "resource.eksClusterDetails.name"),
+			),
+			"sourceUserName":      soarString(f, "resource.accessKeyDetails.userName"),
+			"deviceCustomString1": soarString(f, "type"),
+		}),
+	}
+	return alert, true
+}
+
+// suricataAlert makes an alert of a Suricata EVE alert; other EVE events
+// are not alerts
+func suricataAlert(event *models.GeneratedEvent) (soarAlert, bool) {
+	f := event.Fields
+	if soarString(f, "event_type") != "alert" {
+		return soarAlert{}, false
+	}
+	// Suricata's severity 1 is the highest
+	severity := "low"
+	switch soarString(f, "alert.severity") {
+	case "1":
+		severity = "high"
+	case "2":
+		severity = "medium"
+	}
+	return soarAlert{
+		ID:          soarString(f, "flow_id") + "-" + soarString(f, "alert.signature_id"),
+		Name:        soarString(f, "alert.signature"),
+		Description: soarString(f, "alert.category"),
+		Severity:    severity,
+		Source:      "Suricata",
+		Occurred:    soarTime(soarString(f, "timestamp")),
+		Indicators: soarIndicators(map[string]string{
+			"sourceAddress":      soarString(f, "src_ip"),
+			"sourcePort":         soarString(f, "src_port"),
+			"destinationAddress": soarString(f, "dest_ip"),
+			"destinationPort":    soarString(f, "dest_port"),
+			"transportProtocol":  soarString(f, "proto"),
+			"deviceHostname":     soarString(f, "host"),
+			"deviceAction":       soarString(f, "alert.action"),
+		}),
+	}, true
+}
+
+// crowdStrikeAlert makes an alert of a Falcon endpoint or identity
+// protection detection; other streaming API events are not alerts
+func crowdStrikeAlert(event *models.GeneratedEvent) (soarAlert, bool) {
+	f := event.Fields
+	switch soarString(f, "metadata.eventType") {
+	case "DetectionSummaryEvent":
+		return soarAlert{
+			ID:          soarString(f, "event.DetectId"),
+			Name:        soarString(f, "event.DetectName"),
+			Description: soarString(f, "event.DetectDescription"),
+			Severity:    strings.ToLower(soarString(f, "event.SeverityName")),
+			Source:      "CrowdStrike Falcon",
+			Occurred:    soarTime(soarString(f, "event.timestamp")),
+			Indicators: soarIndicators(map[string]string{
+				"deviceHostname": soarString(f, "event.ComputerName"),
+				"sourceAddress":  soarString(f, "event.LocalIP"),
+				"sourceUserName": soarString(f, "event.UserName"),
+				"fileName":       soarString(f, "event.FileName"),
+				"filePath":       soarString(f, "event.FilePath"),
+				"fileHash":       soarString(f, "event.SHA256String"),
+				"cs1":            soarString(f, "event.TechniqueId"),
+			}),
+		}, true
+	case "IdentityProtectionEvent":
+		return soarAlert{
+			ID:          soarString(f, "event.FalconHostLink"),
+			Name:        soarString(f, "event.IncidentType"),
+			Description: soarString(f, "event.IncidentDescription"),
+			Severity:    strings.ToLower(soarString(f, "event.SeverityName")),
+			Source:      "CrowdStrike Falcon Identity Protection",
+			Indicators: soarIndicators(map[string]string{
+				"deviceHostname": soarString(f, "event.SourceEndpointHostName"),
+				"sourceAddress":  soarString(f, "event.SourceEndpointIpAddress"),
+				"sourceUserName": soarString(f, "event.SourceAccountUpn"),
+			}),
+		}, true
+	}
+	return soarAlert{}, false
+}
+
+// soarString returns the value at a dotted path as a string, following the
+// first element of lists, or "" when there is none
+func soarString(fields map[string]interface{}, path string) string {
+	var v interface{} = fields
+	for _, key := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case []map[string]interface{}:
+			if len(t) == 0 {
+				return ""
+			}
+			v = t[0]
+		case []interface{}:
+			if len(t) == 0 {
+				return ""
+			}
+			v = t[0]
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		v = m[key]
+	}
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// soarTime parses an RFC 3339 or Suricata timestamp, zero when it is neither
+func soarTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999-0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// soarIndicators drops the indicators an event does not have
+func soarIndicators(all map[string]string) map[string]string {
+	indicators := make(map[string]string, len(all))
+	for k, v := range all {
+		if v != "" {
+			indicators[k] = v
+		}
+	}
+	return indicators
+}
@@ -0,0 +1,113 @@
+package delivery
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// statsdMaxPacket keeps datagrams under a typical Ethernet MTU
+const statsdMaxPacket = 1432
+
+// StatsDSender sends metric events as StatsD gauges over UDP
+type StatsDSender struct {
+	conn   net.Conn
+	config models.DestinationConfig
+	tags   bool
+}
+
+// NewStatsDSender creates a new StatsD sender
+func NewStatsDSender(config models.DestinationConfig) (*StatsDSender, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("StatsD host is required")
+	}
+	port := config.Port
+	if port == 0 {
+		port = 8125
+	}
+
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(config.Host, strconv.Itoa(port)), 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to StatsD server: %w", err)
+	}
+
+	return &StatsDSender{
+		conn:   conn,
+		config: config,
+		tags:   config.Format != "statsd",
+	}, nil
+}
+
+// Send writes each metric in the event as a gauge line, packing lines into
+// as few datagrams as possible
+func (s *StatsDSender) Send(event *models.GeneratedEvent) error {
+	points, err := extractMetrics(event)
+	if err != nil {
+		return err
+	}
+
+	var packet strings.Builder
+	for _, p := range points {
+		line := s.formatLine(p)
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdMaxPacket {
+			if _, err := s.conn.Write([]byte(packet.String())); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+
+	_, err = s.conn.Write([]byte(packet.String()))
+	return err
+}
+
+// formatLine renders a metric as "name:value|g", with DogStatsD tags for the
+// host and dimensions unless plain StatsD output is configured
+func (s *StatsDSender) formatLine(p metricPoint) string {
+	line := statsdName(s.config.MetricPrefix+p.Name) + ":" + strconv.FormatFloat(p.Value, 'f', -1, 64) + "|g"
+	if !s.tags {
+		return line
+	}
+
+	tags := make([]string, 0, len(p.Dimensions)+1)
+	tags = append(tags, "host:"+statsdTag(p.Host))
+	for _, k := range sortedKeys(p.Dimensions) {
+		tags = append(tags, statsdTag(k)+":"+statsdTag(p.Dimensions[k]))
+	}
+	return line + "|#" + strings.Join(tags, ",")
+}
+
+// statsdName strips characters that delimit the StatsD line format
+func statsdName(name string) string {
+	return strings.NewReplacer(":", "_", "|", "_", "@", "_", "\n", "_").Replace(name)
+}
+
+// statsdTag strips characters that delimit DogStatsD tags
+func statsdTag(tag string) string {
+	return strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_").Replace(tag)
+}
+
+// Test tests the StatsD connection
+func (s *StatsDSender) Test() error {
+	s.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	defer s.conn.SetWriteDeadline(time.Time{})
+
+	_, err := s.conn.Write([]byte(statsdName(s.config.MetricPrefix+"siem_event_generator.test") + ":1|c"))
+	return err
+}
+
+// Close closes the StatsD connection
+func (s *StatsDSender) Close() error {
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
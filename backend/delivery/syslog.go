@@ -1,6 +1,8 @@
 package delivery
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"time"
@@ -23,7 +25,7 @@ func NewSyslogSender(config models.DestinationConfig, protocol string) (*SyslogS
 	var err error
 
 	if protocol == "tcp" {
-		conn, err = net.DialTimeout("tcp", address, 10*time.Second)
+		conn, err = dialSyslogTCP(config, address)
 	} else {
 		conn, err = net.DialTimeout("udp", address, 10*time.Second)
 	}
@@ -39,6 +41,35 @@ func NewSyslogSender(config models.DestinationConfig, protocol string) (*SyslogS
 	}, nil
 }
 
+// dialSyslogTCP connects to a TCP syslog server, through the destination's
+// proxy if it has one, and starts TLS when configured
+func dialSyslogTCP(config models.DestinationConfig, address string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dial, err := tcpDialer(config, &net.Dialer{})
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil || !config.TLS {
+		return conn, err
+	}
+
+	tlsCfg, err := tlsConfig(config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsCfg.ServerName = config.Host
+	tlsConn := tls.Client(conn, tlsCfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
 // Send sends an event via syslog
 func (s *SyslogSender) Send(event *models.GeneratedEvent) error {
 	message := s.formatMessage(event)
@@ -72,6 +103,9 @@ func (s *SyslogSender) formatMessage(event *models.GeneratedEvent) string {
 	}
 
 	hostname := "siem-event-generator"
+	if event.Host != "" {
+		hostname = event.Host
+	}
 	timestamp := event.Timestamp
 
 	if format == "rfc5424" {
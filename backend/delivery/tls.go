@@ -0,0 +1,69 @@
+package delivery
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"siem-event-generator/models"
+)
+
+// tlsConfig builds the client TLS settings for a destination. A custom CA
+// is trusted in addition to the system roots and turns on certificate
+// verification; a client certificate and key are presented for mutual TLS.
+func tlsConfig(config models.DestinationConfig) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: !config.VerifySSL && config.CACert == "",
+	}
+
+	if config.CACert != "" {
+		pem, err := readPEM(config.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("ca_cert: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_cert: no certificates found")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if config.ClientCert != "" || config.ClientKey != "" {
+		if config.ClientCert == "" || config.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		certPEM, err := readPEM(config.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("client_cert: %w", err)
+		}
+		keyPEM, err := readPEM(config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("client_key: %w", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// readPEM returns value itself when it holds PEM data, otherwise the
+// contents of the file it names
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
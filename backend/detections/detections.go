@@ -0,0 +1,126 @@
+// Package detections asks a SIEM whether the alerts a scenario should
+// raise fired: Splunk saved searches through its search API and Microsoft
+// Sentinel analytic rules through Log Analytics.
+package detections
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// requestTimeout bounds each query to a SIEM
+const requestTimeout = time.Minute
+
+// Check asks the backends whether each expected detection fired between
+// from and to. A detection whose backend is not set reports an error.
+func Check(ctx context.Context, backends models.DetectionBackends, expected []models.ExpectedDetection, from, to time.Time) []models.DetectionResult {
+	var sentinelToken string
+	results := make([]models.DetectionResult, 0, len(expected))
+	for _, d := range expected {
+		result := models.DetectionResult{ExpectedDetection: d}
+		var fired firing
+		var err error
+		switch d.Kind {
+		case models.DetectionSplunkSavedSearch:
+			if backends.Splunk == nil {
+				err = fmt.Errorf("no Splunk search API is set; PUT /api/detections/backends")
+				break
+			}
+			fired, err = splunkFired(ctx, backends.Splunk, d.Name, from, to)
+		case models.DetectionSentinelRule:
+			if backends.Sentinel == nil {
+				err = fmt.Errorf("no Sentinel workspace is set; PUT /api/detections/backends")
+				break
+			}
+			if sentinelToken == "" {
+				sentinelToken, err = sentinelLogin(ctx, backends.Sentinel)
+				if err != nil {
+					break
+				}
+			}
+			fired, err = sentinelFired(ctx, backends.Sentinel, sentinelToken, d.RuleID, from, to)
+		default:
+			err = fmt.Errorf("unknown detection kind %q", d.Kind)
+		}
+
+		switch {
+		case err != nil:
+			result.Status, result.Error = models.DetectionError, err.Error()
+		case fired.count > 0:
+			result.Status, result.Alerts, result.FirstFiredAt = models.DetectionFired, fired.count, fired.first
+		default:
+			result.Status = models.DetectionMissing
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Report sums up results into a scenario's detection report
+func Report(scenario *models.Scenario, results []models.DetectionResult, from, to time.Time) models.DetectionReport {
+	report := models.DetectionReport{
+		ScenarioID: scenario.ID,
+		Scenario:   scenario.Name,
+		Results:    results,
+		From:       from.UTC(),
+		To:         to.UTC(),
+		CheckedAt:  time.Now().UTC(),
+	}
+	for _, r := range results {
+		switch r.Status {
+		case models.DetectionFired:
+			report.Fired++
+		case models.DetectionMissing:
+			report.Missing++
+		default:
+			report.Errors++
+		}
+	}
+	report.Passed = report.Fired == len(results)
+	return report
+}
+
+// firing is how often a detection fired in the window, and first when
+type firing struct {
+	count int64
+	first *time.Time
+}
+
+func newClient(verify bool) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: !verify},
+		},
+		Timeout: requestTimeout,
+	}
+}
+
+// doJSON sends req and decodes its JSON answer into out
+func doJSON(client *http.Client, req *http.Request, system string, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", system, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", system, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d: %s", system, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", system, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package detections
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// Public cloud endpoints, used unless the workspace sets its own
+const (
+	defaultLoginURL = "https://login.microsoftonline.com"
+	defaultAPIURL   = "https://api.loganalytics.io"
+)
+
+// sentinelLogin gets a Log Analytics access token for the workspace's app
+// registration with the client credentials grant
+func sentinelLogin(ctx context.Context, ws *models.SentinelWorkspace) (string, error) {
+	login := ws.LoginURL
+	if login == "" {
+		login = defaultLoginURL
+	}
+	api := ws.APIURL
+	if api == "" {
+		api = defaultAPIURL
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {ws.ClientID},
+		"client_secret": {ws.ClientSecret},
+		"scope":         {strings.TrimSuffix(api, "/") + "/.default"},
+	}
+	target := strings.TrimSuffix(login, "/") + "/" + url.PathEscape(ws.TenantID) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(newClient(true), req, "Entra ID", &token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("Entra ID returned no access token")
+	}
+	return token.AccessToken, nil
+}
+
+// sentinelFired queries the SecurityAlert table for alerts raised by an
+// analytic rule. Scheduled and NRT rules name themselves in the alert's
+// "Analytic Rule Ids" property and at the end of its AlertType.
+func sentinelFired(ctx context.Context, ws *models.SentinelWorkspace, token, ruleID string, from, to time.Time) (firing, error) {
+	query := fmt.Sprintf(`SecurityAlert
+| where TimeGenerated between (datetime(%s) .. datetime(%s))
+| extend RuleIds = tostring(parse_json(ExtendedProperties)["Analytic Rule Ids"])
+| where RuleIds has "%s" or AlertType endswith "%s"
+| summarize Alerts = count(), First = min(TimeGenerated)`,
+		from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339), ruleID, ruleID)
+	body, _ := json.Marshal(map[string]string{"query": query})
+
+	api := ws.APIURL
+	if api == "" {
+		api = defaultAPIURL
+	}
+	target := strings.TrimSuffix(api, "/") + "/v1/workspaces/" + url.PathEscape(ws.WorkspaceID) + "/query"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return firing{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var result struct {
+		Tables []struct {
+			Rows [][]interface{} `json:"rows"`
+		} `json:"tables"`
+	}
+	if err := doJSON(newClient(true), req, "Log Analytics", &result); err != nil {
+		return firing{}, err
+	}
+	if len(result.Tables) == 0 || len(result.Tables[0].Rows) == 0 || len(result.Tables[0].Rows[0]) < 2 {
+		return firing{}, nil
+	}
+	row := result.Tables[0].Rows[0]
+	var fired firing
+	if n, ok := row[0].(float64); ok {
+		fired.count = int64(n)
+	}
+	if s, ok := row[1].(string); ok && fired.count > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			fired.first = &t
+		}
+	}
+	return fired, nil
+}
@@ -0,0 +1,57 @@
+package detections
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// splunkFired searches the _audit index for the times a saved search's
+// alert fired, which Splunk records whether or not the alert has actions
+func splunkFired(ctx context.Context, api *models.SplunkSearchAPI, name string, from, to time.Time) (firing, error) {
+	search := fmt.Sprintf("search index=_audit action=alert_fired ss_name=%s earliest=%d latest=%d | stats count min(_time) as first",
+		strconv.Quote(name), from.Unix(), to.Unix()+1)
+	form := url.Values{
+		"search":      {search},
+		"exec_mode":   {"oneshot"},
+		"output_mode": {"json"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(api.URL, "/")+"/services/search/jobs", strings.NewReader(form.Encode()))
+	if err != nil {
+		return firing{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if api.Username != "" {
+		req.SetBasicAuth(api.Username, api.Password)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+api.Token)
+	}
+
+	var result struct {
+		Results []struct {
+			Count string `json:"count"`
+			First string `json:"first"`
+		} `json:"results"`
+	}
+	if err := doJSON(newClient(api.VerifySSL), req, "Splunk", &result); err != nil {
+		return firing{}, err
+	}
+	if len(result.Results) == 0 {
+		return firing{}, nil
+	}
+	var fired firing
+	fired.count, _ = strconv.ParseInt(result.Results[0].Count, 10, 64)
+	if first, err := strconv.ParseFloat(result.Results[0].First, 64); err == nil && fired.count > 0 {
+		sec, frac := math.Modf(first)
+		t := time.Unix(int64(sec), int64(frac*1e9)).UTC()
+		fired.first = &t
+	}
+	return fired, nil
+}
@@ -0,0 +1,194 @@
+package generators
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// AkamaiDataStreamGenerator generates Akamai DataStream 2 request logs for
+// the properties in front of the web tier's public sites, including the
+// App & API Protector rules that match attack requests
+type AkamaiDataStreamGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&AkamaiDataStreamGenerator{})
+}
+
+// akamaiRules are the App & API Protector rule IDs that catch each category
+// of web attack
+var akamaiRules = map[string]string{
+	"sqli":              "3000100",
+	"xss":               "3000081",
+	"path_traversal":    "3000051",
+	"command_injection": "3000171",
+	"ssrf":              "3000185",
+	"log4shell":         "3000072",
+	"scanner":           "3000041",
+}
+
+// GetEventType returns the event type for Akamai DataStream 2 logs
+func (g *AkamaiDataStreamGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "akamai_datastream",
+		Name:        "Akamai DataStream 2",
+		Category:    "web",
+		Description: "Akamai DataStream 2 edge request logs for the public sites: cache hits and misses, App & API Protector denials and origin errors",
+		EventIDs:    []string{"request", "deny", "origin_error"},
+	}
+}
+
+// GetTemplates returns available templates for Akamai DataStream 2 logs
+func (g *AkamaiDataStreamGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "request",
+			Name:        "Edge Request",
+			Category:    "akamai_datastream",
+			EventID:     "request",
+			Format:      "json",
+			Description: "A request served from the edge cache or fetched from the origin, with the usual mix of statuses",
+		},
+		{
+			ID:          "waf_denied",
+			Name:        "App & API Protector Deny",
+			Category:    "akamai_datastream",
+			EventID:     "deny",
+			Format:      "json",
+			Description: "An attack request denied by an App & API Protector rule",
+		},
+		{
+			ID:          "origin_error",
+			Name:        "Origin Error",
+			Category:    "akamai_datastream",
+			EventID:     "origin_error",
+			Format:      "json",
+			Description: "The edge could not connect to or read from the origin (502/503/504)",
+		},
+	}
+}
+
+// Generate creates an Akamai DataStream 2 log event
+func (g *AkamaiDataStreamGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	vhost := g.ZipfChoice(edgeVhosts())
+	switch templateID {
+	case "request":
+		r := g.randomEdgeRequest(vhost, 0, false)
+		fields := g.record(r)
+		if r.attack != "" {
+			// Matched in alert mode only
+			fields["securityRules"] = "ALERT|" + akamaiRules[r.attack]
+		}
+		return g.event("request", fields, overrides)
+	case "waf_denied":
+		r := g.randomEdgeRequest(vhost, 403, true)
+		r.bytesSent = g.RandomInt(200, 400)
+		fields := g.record(r)
+		fields["securityRules"] = "DENY|" + akamaiRules[r.attack]
+		fields["errorCode"] = "ERR_ACCESS_DENIED|fwd_acl"
+		fields["turnAroundTimeMSec"] = strconv.Itoa(g.RandomInt(1, 5))
+		return g.event("deny", fields, overrides)
+	case "origin_error":
+		status := []int{502, 503, 504}[g.weightedIndex([]float64{45, 20, 35})]
+		fields := g.record(g.randomEdgeRequest(vhost, status, false))
+		fields["errorCode"] = map[int]string{
+			502: "ERR_CONNECT_FAIL|errno=111",
+			503: "ERR_ZERO_SIZE_OBJECT|origin_closed",
+			504: "ERR_READ_TIMEOUT|origin",
+		}[status]
+		return g.event("origin_error", fields, overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// akamaiCPCode returns the stable CP code, the reporting ID, of a site's
+// property
+func akamaiCPCode(vhost string) string {
+	n, _ := strconv.ParseInt(edgeID("akamai/"+vhost, 5), 16, 64)
+	return strconv.FormatInt(400000+n%500000, 10)
+}
+
+// record builds a DataStream 2 record for r. DataStream sends every value
+// as a string.
+func (g *AkamaiDataStreamGenerator) record(r edgeRequest) map[string]interface{} {
+	now := time.Now().UTC()
+	hit := g.cacheHit(r)
+	cacheStatus, turnAround := "0", r.originTime*1000+g.RandomLogNormal(5, 0.5)
+	if hit {
+		cacheStatus, turnAround = "1", g.RandomLogNormal(2, 0.6)
+	}
+	cacheable := "0"
+	if r.kind == webRequestAsset || r.kind == webRequestPage {
+		cacheable = "1"
+	}
+	tlsVersion := g.WeightedChoice([]string{"TLSv1.3", "TLSv1.2"}, []float64{75, 25})
+	overhead := g.RandomInt(250, 600)
+	query := r.query()
+	if query == "" {
+		query = "-"
+	}
+	referer := r.referer
+	if referer == "" {
+		referer = "-"
+	}
+
+	return map[string]interface{}{
+		"version":            "1",
+		"streamId":           edgeID("akamai/stream", 5),
+		"cp":                 akamaiCPCode(r.vhost),
+		"reqId":              g.RandomHex(4),
+		"reqTimeSec":         fmt.Sprintf("%d.%03d", now.Unix(), now.Nanosecond()/1e6),
+		"reqHost":            r.vhost,
+		"reqMethod":          r.method,
+		"reqPath":            r.path(),
+		"queryStr":           query,
+		"reqPort":            "443",
+		"proto":              "HTTPS",
+		"tlsVersion":         tlsVersion,
+		"statusCode":         strconv.Itoa(r.status),
+		"cliIP":              r.client.IP,
+		"country":            r.client.CountryCode,
+		"city":               r.client.City,
+		"UA":                 r.userAgent,
+		"referer":            referer,
+		"bytes":              strconv.Itoa(r.bytesSent),
+		"objSize":            strconv.Itoa(r.bytesSent),
+		"overheadBytes":      strconv.Itoa(overhead),
+		"totalBytes":         strconv.Itoa(r.bytesSent + overhead),
+		"rspContentLen":      strconv.Itoa(r.bytesSent),
+		"rspContentType":     r.contentType(),
+		"cacheStatus":        cacheStatus,
+		"cacheable":          cacheable,
+		"turnAroundTimeMSec": strconv.Itoa(int(turnAround)),
+		"transferTimeMSec":   strconv.Itoa(g.RandomInt(0, 40)),
+		"edgeIP":             g.RandomIPv4External(),
+		"serverCountry":      r.client.CountryCode,
+		"errorCode":          "-",
+		"securityRules":      "-",
+	}
+}
+
+func (g *AkamaiDataStreamGenerator) event(eventID string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "akamai_datastream",
+		EventID:    eventID,
+		Timestamp:  time.Now().UTC(),
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "akamai:datastream2",
+	}, nil
+}
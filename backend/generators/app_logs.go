@@ -0,0 +1,422 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// AppLogGenerator generates application log events for the services used by
+// the application metrics and traces: structured JSON logs and multiline Java
+// and Python stack traces
+type AppLogGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&AppLogGenerator{})
+}
+
+// GetEventType returns the event type for Application Logs
+func (g *AppLogGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "app_logs",
+		Name:        "Application Logs",
+		Category:    "infrastructure",
+		Description: "Structured JSON application logs and multiline Java/Python stack traces",
+		EventIDs:    []string{"json", "java_error", "python_error"},
+	}
+}
+
+// GetTemplates returns available templates for Application Logs
+func (g *AppLogGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "json",
+			Name:        "Structured JSON Log",
+			Category:    "app_logs",
+			EventID:     "json",
+			Format:      "json",
+			Description: "Logback JSON log line (level, logger, traceId, message); errors carry a stack_trace",
+			Sourcetype:  "_json",
+		},
+		{
+			ID:          "java_error",
+			Name:        "Java Exception",
+			Category:    "app_logs",
+			EventID:     "java_error",
+			Format:      "log",
+			Description: "Spring Boot ERROR line followed by a multiline Java stack trace",
+			Sourcetype:  "log4j",
+		},
+		{
+			ID:          "python_error",
+			Name:        "Python Traceback",
+			Category:    "app_logs",
+			EventID:     "python_error",
+			Format:      "log",
+			Description: "Python logging ERROR line followed by a multiline traceback",
+			Sourcetype:  "python",
+		},
+	}
+}
+
+// Generate creates an Application Log event
+func (g *AppLogGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	service := g.ZipfChoice(appServices)
+	host := ServerName(fmt.Sprintf("app-%02d", g.RandomInt(1, 20)))
+	endpoint := g.RandomChoice([]string{"/api/v1/orders", "/api/v1/users", "/api/v1/products", "/api/v1/cart", "/api/v1/checkout"})
+
+	switch templateID {
+	case "json":
+		return g.jsonLogEvent(service, host, endpoint, time.Now(), g.RandomHex(16), overrides)
+	case "java_error":
+		return g.javaErrorEvent(service, host, endpoint, time.Now(), g.RandomHex(16), 500, overrides)
+	case "python_error":
+		return g.pythonErrorEvent(service, host, time.Now(), g.RandomHex(16), overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// javaException describes an exception and the frames that raised it
+type javaException struct {
+	class   string
+	message string
+	frames  []string
+	cause   *javaException
+}
+
+// javaFailure picks an exception consistent with the HTTP status the
+// request failed with
+func (g *AppLogGenerator) javaFailure(service string, status int) *javaException {
+	pkg := "com.example." + strings.ReplaceAll(strings.TrimSuffix(service, "-service"), "-", "")
+
+	switch status {
+	case 502, 503:
+		downstream := g.RandomChoice(appServices)
+		return &javaException{
+			class:   "org.springframework.web.client.HttpServerErrorException$ServiceUnavailable",
+			message: fmt.Sprintf("%d Service Unavailable: \"no healthy upstream\" from http://%s", status, downstream),
+			frames: []string{
+				"org.springframework.web.client.HttpServerErrorException.create(HttpServerErrorException.java:112)",
+				"org.springframework.web.client.DefaultResponseErrorHandler.handleError(DefaultResponseErrorHandler.java:183)",
+				"org.springframework.web.client.RestTemplate.doExecute(RestTemplate.java:825)",
+				pkg + ".client.DownstreamClient.call(DownstreamClient.java:58)",
+			},
+		}
+	case 504:
+		return &javaException{
+			class:   "org.springframework.web.client.ResourceAccessException",
+			message: "I/O error on POST request: Read timed out",
+			frames: []string{
+				"org.springframework.web.client.RestTemplate.doExecute(RestTemplate.java:791)",
+				"org.springframework.web.client.RestTemplate.execute(RestTemplate.java:711)",
+				pkg + ".client.DownstreamClient.call(DownstreamClient.java:58)",
+			},
+			cause: &javaException{
+				class:   "java.net.SocketTimeoutException",
+				message: "Read timed out",
+				frames: []string{
+					"java.base/sun.nio.ch.NioSocketImpl.timedRead(NioSocketImpl.java:288)",
+					"java.base/sun.nio.ch.NioSocketImpl.implRead(NioSocketImpl.java:314)",
+					"java.base/java.net.Socket$SocketInputStream.read(Socket.java:1099)",
+				},
+			},
+		}
+	default:
+		return g.commonFailure(pkg)
+	}
+}
+
+// commonFailure picks one of the usual causes of a 500 response
+func (g *AppLogGenerator) commonFailure(pkg string) *javaException {
+	switch g.RandomInt(0, 2) {
+	case 0:
+		return &javaException{
+			class:   "java.lang.NullPointerException",
+			message: "Cannot invoke \"" + pkg + ".model.Customer.getAddress()\" because \"customer\" is null",
+			frames: []string{
+				pkg + ".service.OrderService.buildShipment(OrderService.java:214)",
+				pkg + ".service.OrderService.placeOrder(OrderService.java:131)",
+			},
+		}
+	case 1:
+		return &javaException{
+			class:   "org.springframework.dao.DataAccessResourceFailureException",
+			message: "Unable to acquire JDBC Connection",
+			frames: []string{
+				"org.springframework.orm.jpa.vendor.HibernateJpaDialect.convertHibernateAccessException(HibernateJpaDialect.java:277)",
+				pkg + ".repository.OrderRepository.save(OrderRepository.java:44)",
+			},
+			cause: &javaException{
+				class:   "java.sql.SQLTransientConnectionException",
+				message: "HikariPool-1 - Connection is not available, request timed out after 30000ms.",
+				frames: []string{
+					"com.zaxxer.hikari.pool.HikariPool.createTimeoutException(HikariPool.java:696)",
+					"com.zaxxer.hikari.pool.HikariPool.getConnection(HikariPool.java:181)",
+				},
+			},
+		}
+	default:
+		return &javaException{
+			class:   "java.lang.IllegalStateException",
+			message: "Inventory reservation expired before payment completed",
+			frames: []string{
+				pkg + ".service.ReservationService.confirm(ReservationService.java:87)",
+				pkg + ".service.OrderService.placeOrder(OrderService.java:149)",
+			},
+		}
+	}
+}
+
+// javaErrorEvent renders an ERROR log line with its stack trace for a failed
+// request to endpoint on service/host
+func (g *AppLogGenerator) javaErrorEvent(service, host, endpoint string, timestamp time.Time, traceID string, status int, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	exc := g.javaFailure(service, status)
+	spanID := g.RandomHex(8)
+	thread := fmt.Sprintf("http-nio-8080-exec-%d", g.RandomInt(1, 200))
+	logger := "o.a.c.c.C.[.[.[/].[dispatcherServlet]"
+	controller := "com.example." + strings.ReplaceAll(strings.TrimSuffix(service, "-service"), "-", "") + ".web.ApiController"
+	message := fmt.Sprintf("Servlet.service() for servlet [dispatcherServlet] in context with path [] threw exception [Request processing failed: %s: %s] with root cause", exc.class, exc.message)
+
+	rawEvent := fmt.Sprintf("%s ERROR [%s,%s,%s] 1 --- [%s] %s : %s\n%s",
+		timestamp.Format("2006-01-02 15:04:05.000"), service, traceID, spanID, thread, logger, message,
+		javaStackTrace(exc, controller, endpoint))
+
+	fields := map[string]interface{}{
+		"timestamp":       timestamp.Format(time.RFC3339Nano),
+		"level":           "ERROR",
+		"service":         service,
+		"host":            host,
+		"thread":          thread,
+		"logger":          logger,
+		"trace_id":        traceID,
+		"span_id":         spanID,
+		"endpoint":        endpoint,
+		"status_code":     status,
+		"exception_class": exc.class,
+		"message":         message,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "app_logs",
+		EventID:    "java_error",
+		Timestamp:  timestamp,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "log4j",
+	}, nil
+}
+
+// javaStackTrace renders an exception and its causes as printStackTrace would
+func javaStackTrace(exc *javaException, controller, endpoint string) string {
+	var b strings.Builder
+	writeJavaException(&b, exc, controller, endpoint, false)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// writeJavaException appends a stack trace in the JVM's printStackTrace format,
+// with the framework frames that lead from the servlet container to endpoint
+func writeJavaException(b *strings.Builder, exc *javaException, controller, endpoint string, isCause bool) {
+	if isCause {
+		b.WriteString("Caused by: ")
+	}
+	fmt.Fprintf(b, "%s: %s\n", exc.class, exc.message)
+	for _, f := range exc.frames {
+		fmt.Fprintf(b, "\tat %s\n", f)
+	}
+
+	if isCause {
+		fmt.Fprintf(b, "\t... %d common frames omitted\n", 40+len(exc.frames))
+	} else {
+		handler := strings.TrimPrefix(endpoint, "/api/v1/")
+		fmt.Fprintf(b, "\tat %s.%s(ApiController.java:%d)\n", controller, handler, 60+len(handler))
+		for _, f := range []string{
+			"org.springframework.web.servlet.FrameworkServlet.processRequest(FrameworkServlet.java:1011)",
+			"org.springframework.web.servlet.DispatcherServlet.doDispatch(DispatcherServlet.java:1072)",
+			"jakarta.servlet.http.HttpServlet.service(HttpServlet.java:590)",
+			"org.apache.catalina.core.ApplicationFilterChain.doFilter(ApplicationFilterChain.java:166)",
+			"org.apache.tomcat.util.threads.ThreadPoolExecutor$Worker.run(ThreadPoolExecutor.java:659)",
+			"java.base/java.lang.Thread.run(Thread.java:840)",
+		} {
+			fmt.Fprintf(b, "\tat %s\n", f)
+		}
+	}
+
+	if exc.cause != nil {
+		writeJavaException(b, exc.cause, controller, endpoint, true)
+	}
+}
+
+// appLogMessage is a recurring log statement; the same statements repeat with
+// different values, so they cluster the way real application logs do
+type appLogMessage struct {
+	level  string
+	logger string
+	format func(g *AppLogGenerator, endpoint string) string
+}
+
+var appLogMessages = []appLogMessage{
+	{"INFO", "web.RequestLoggingFilter", func(g *AppLogGenerator, endpoint string) string {
+		return fmt.Sprintf("Completed %s %s with status 200 in %dms", g.RandomChoice([]string{"GET", "POST"}), endpoint, int(g.RandomLogNormal(45, 0.8)))
+	}},
+	{"INFO", "service.OrderService", func(g *AppLogGenerator, endpoint string) string {
+		return fmt.Sprintf("Order %d placed for customer %d with %d items", g.RandomInt(100000, 999999), g.RandomInt(1000, 99999), g.RandomInt(1, 8))
+	}},
+	{"INFO", "messaging.EventPublisher", func(g *AppLogGenerator, endpoint string) string {
+		return fmt.Sprintf("Published %s to topic %s partition %d", g.RandomChoice([]string{"OrderCreated", "PaymentCaptured", "StockReserved"}), g.RandomChoice([]string{"orders", "payments", "inventory"}), g.RandomInt(0, 11))
+	}},
+	{"DEBUG", "cache.CacheManager", func(g *AppLogGenerator, endpoint string) string {
+		return fmt.Sprintf("Cache %s for key product:%d", g.WeightedChoice([]string{"hit", "miss"}, []float64{85, 15}), g.RandomInt(100, 9999))
+	}},
+	{"WARN", "client.DownstreamClient", func(g *AppLogGenerator, endpoint string) string {
+		return fmt.Sprintf("Retrying call to %s after timeout (attempt %d of 3)", g.RandomChoice(appServices), g.RandomInt(1, 2))
+	}},
+	{"WARN", "config.HikariConfig", func(g *AppLogGenerator, endpoint string) string {
+		return fmt.Sprintf("HikariPool-1 - Thread starvation or clock leap detected (housekeeper delta=%ds).", g.RandomInt(60, 180))
+	}},
+	{"WARN", "security.JwtAuthenticationFilter", func(g *AppLogGenerator, endpoint string) string {
+		return fmt.Sprintf("Rejected expired JWT for subject user-%d", g.RandomInt(1000, 99999))
+	}},
+	{"ERROR", "web.GlobalExceptionHandler", func(g *AppLogGenerator, endpoint string) string {
+		return fmt.Sprintf("Request processing failed for %s", endpoint)
+	}},
+}
+
+// jsonLogEvent renders a Logback JSON encoder log line. ERROR lines carry the
+// stack trace in a single escaped stack_trace field.
+func (g *AppLogGenerator) jsonLogEvent(service, host, endpoint string, timestamp time.Time, traceID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	msg := appLogMessages[g.weightedIndex([]float64{40, 15, 12, 15, 6, 2, 4, 6})]
+	pkg := "com.example." + strings.ReplaceAll(strings.TrimSuffix(service, "-service"), "-", "")
+
+	fields := map[string]interface{}{
+		"@timestamp": timestamp.Format("2006-01-02T15:04:05.000Z07:00"),
+		"level":      msg.level,
+		"logger":     pkg + "." + msg.logger,
+		"thread":     fmt.Sprintf("http-nio-8080-exec-%d", g.RandomInt(1, 200)),
+		"service":    service,
+		"host":       host,
+		"traceId":    traceID,
+		"spanId":     g.RandomHex(8),
+		"message":    msg.format(g, endpoint),
+	}
+	if msg.level == "ERROR" {
+		exc := g.javaFailure(service, 500)
+		fields["exception_class"] = exc.class
+		fields["stack_trace"] = javaStackTrace(exc, pkg+".web.ApiController", endpoint)
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, _ := json.Marshal(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "app_logs",
+		EventID:    "json",
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "_json",
+	}, nil
+}
+
+// pythonFailure is a traceback: the frames from the entry point down to the
+// raising line, and the exception it ended with
+type pythonFailure struct {
+	frames    [][3]string // file, function, source line
+	exception string
+}
+
+var pythonFailures = []pythonFailure{
+	{
+		frames: [][3]string{
+			{"/app/worker/tasks.py", "process_order", "payload = build_payload(order)"},
+			{"/app/worker/payloads.py", "build_payload", "\"sku\": item[\"sku\"],"},
+		},
+		exception: "KeyError: 'sku'",
+	},
+	{
+		frames: [][3]string{
+			{"/app/worker/tasks.py", "sync_inventory", "resp = client.get_stock(warehouse_id)"},
+			{"/app/clients/inventory.py", "get_stock", "resp = self.session.get(url, timeout=self.timeout)"},
+			{"/usr/local/lib/python3.11/site-packages/requests/sessions.py", "get", "return self.request(\"GET\", url, **kwargs)"},
+			{"/usr/local/lib/python3.11/site-packages/requests/adapters.py", "send", "raise ReadTimeout(e, request=request)"},
+		},
+		exception: "requests.exceptions.ReadTimeout: HTTPConnectionPool(host='inventory-service', port=8080): Read timed out. (read timeout=5)",
+	},
+	{
+		frames: [][3]string{
+			{"/app/worker/tasks.py", "send_notification", "template = render_template(event.kind, event.context)"},
+			{"/app/notify/templates.py", "render_template", "return TEMPLATES[kind].format(**context)"},
+		},
+		exception: "AttributeError: 'NoneType' object has no attribute 'format'",
+	},
+	{
+		frames: [][3]string{
+			{"/app/worker/tasks.py", "record_payment", "db.session.commit()"},
+			{"/usr/local/lib/python3.11/site-packages/sqlalchemy/orm/session.py", "commit", "trans.commit(_to_root=True)"},
+			{"/usr/local/lib/python3.11/site-packages/sqlalchemy/engine/default.py", "do_execute", "cursor.execute(statement, parameters)"},
+		},
+		exception: "sqlalchemy.exc.OperationalError: (psycopg2.OperationalError) server closed the connection unexpectedly",
+	},
+	{
+		frames: [][3]string{
+			{"/app/worker/tasks.py", "apply_discount", "rate = Decimal(promo.rate) / total_items"},
+		},
+		exception: "decimal.DivisionByZero: [<class 'decimal.DivisionByZero'>]",
+	},
+}
+
+// pythonErrorEvent renders a logging.exception() record: the ERROR line
+// followed by the traceback
+func (g *AppLogGenerator) pythonErrorEvent(service, host string, timestamp time.Time, traceID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	failure := pythonFailures[g.RandomInt(0, len(pythonFailures)-1)]
+	task := failure.frames[0][1]
+	logger := "worker.tasks"
+	message := fmt.Sprintf("Task %s[%s] raised unexpected exception", task, uuid.New().String())
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s,%03d ERROR [%s] [trace_id=%s] %s: %s\n",
+		timestamp.Format("2006-01-02 15:04:05"), timestamp.Nanosecond()/1e6, service, traceID, logger, message)
+	b.WriteString("Traceback (most recent call last):\n")
+	b.WriteString("  File \"/usr/local/lib/python3.11/site-packages/celery/app/trace.py\", line 477, in trace_task\n    R = retval = fun(*args, **kwargs)\n")
+	for i, f := range failure.frames {
+		fmt.Fprintf(&b, "  File \"%s\", line %d, in %s\n    %s\n", f[0], 20+17*i+len(f[1]), f[1], f[2])
+	}
+	b.WriteString(failure.exception)
+
+	exceptionClass, _, _ := strings.Cut(failure.exception, ":")
+
+	fields := map[string]interface{}{
+		"timestamp":       timestamp.Format(time.RFC3339Nano),
+		"level":           "ERROR",
+		"service":         service,
+		"host":            host,
+		"logger":          logger,
+		"trace_id":        traceID,
+		"task":            task,
+		"exception_class": exceptionClass,
+		"message":         message,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "app_logs",
+		EventID:    "python_error",
+		Timestamp:  timestamp,
+		RawEvent:   b.String(),
+		Fields:     fields,
+		Sourcetype: "python",
+	}, nil
+}
@@ -0,0 +1,393 @@
+package generators
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// AssetInventoryGenerator generates asset inventory snapshots of the entity
+// pool's hosts and the AWS environment's instances
+type AssetInventoryGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&AssetInventoryGenerator{})
+}
+
+// GetEventType returns the event type for asset inventory snapshots
+func (g *AssetInventoryGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "asset_inventory",
+		Name:        "Asset Inventory",
+		Category:    "infrastructure",
+		Description: "Inventory snapshots of the shared entity pool hosts and AWS instances, for populating asset lookups and watchlists",
+		EventIDs:    []string{"host", "cloud_instance"},
+	}
+}
+
+// GetTemplates returns available templates for asset inventory snapshots
+func (g *AssetInventoryGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "host",
+			Name:        "Host Snapshot",
+			Category:    "asset_inventory",
+			EventID:     "host",
+			Format:      "json",
+			Description: "Workstation or server from the entity pool with its OS, owner and installed software",
+		},
+		{
+			ID:          "cloud_instance",
+			Name:        "Cloud Instance Snapshot",
+			Category:    "asset_inventory",
+			EventID:     "cloud_instance",
+			Format:      "json",
+			Description: "EC2 instance from the AWS environment with its account, VPC and network interface",
+		},
+	}
+}
+
+// Generate creates an asset inventory snapshot
+func (g *AssetInventoryGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "host":
+		return g.generateHost(overrides)
+	case "cloud_instance":
+		return g.generateCloudInstance(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// inventoryScan is one pass of the inventory over every asset of a kind
+type inventoryScan struct {
+	id      string
+	started time.Time
+	next    int
+}
+
+// InventoryScanTracker walks the inventory in order, so a run of as many
+// events as there are assets is one complete snapshot. Each pass gets a new
+// scan ID that all of its records share.
+type InventoryScanTracker struct {
+	mu    sync.Mutex
+	scans map[string]*inventoryScan
+}
+
+// InventoryScans is the scan state shared by the asset inventory generator
+var InventoryScans = &InventoryScanTracker{scans: make(map[string]*inventoryScan)}
+
+// next returns the index of the next of n assets of a kind and the scan it
+// belongs to, starting a new scan after the last asset
+func (t *InventoryScanTracker) next(kind string, n int, now time.Time) (int, string, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	scan := t.scans[kind]
+	if scan == nil || scan.next >= n {
+		scan = &inventoryScan{id: uuid.New().String(), started: now}
+		t.scans[kind] = scan
+	}
+	i := scan.next
+	scan.next++
+	return i, scan.id, scan.started
+}
+
+// assetRand returns a random source fixed for an asset, so its hardware and
+// installed software are the same in every snapshot
+func assetRand(assetID string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(assetID))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+// inventorySoftware is an installable product and the versions found in
+// the fleet, the usual one first
+type inventorySoftware struct {
+	name, vendor string
+	versions     []string
+}
+
+// softwareCatalog lists the software seen on each platform. The first
+// entries are on every host; the rest are installed on some.
+var softwareCatalog = map[string]struct {
+	always   []inventorySoftware
+	optional []inventorySoftware
+}{
+	"windows": {
+		always: []inventorySoftware{
+			{"CrowdStrike Windows Sensor", "CrowdStrike, Inc.", []string{"7.10.17706.0", "7.09.17609.0"}},
+			{"Microsoft Edge", "Microsoft Corporation", []string{"124.0.2478.80", "123.0.2420.97"}},
+			{"Splunk Universal Forwarder", "Splunk, Inc.", []string{"9.2.1.0", "9.1.3.0"}},
+		},
+		optional: []inventorySoftware{
+			{"Microsoft 365 Apps for enterprise - en-us", "Microsoft Corporation", []string{"16.0.17531.20140", "16.0.17328.20184"}},
+			{"Google Chrome", "Google LLC", []string{"124.0.6367.119", "123.0.6312.123", "120.0.6099.130"}},
+			{"Zoom Workplace", "Zoom Video Communications, Inc.", []string{"6.0.4", "5.17.11"}},
+			{"Slack", "Slack Technologies Inc.", []string{"4.38.115"}},
+			{"7-Zip 23.01 (x64)", "Igor Pavlov", []string{"23.01"}},
+			{"Adobe Acrobat Reader (64-bit)", "Adobe", []string{"24.002.20736", "23.008.20470"}},
+			{"Notepad++ (64-bit x64)", "Notepad++ Team", []string{"8.6.5", "8.5.8"}},
+			{"PuTTY release 0.80 (64-bit)", "Simon Tatham", []string{"0.80.0.0", "0.78.0.0"}},
+		},
+	},
+	"darwin": {
+		always: []inventorySoftware{
+			{"Falcon", "CrowdStrike, Inc.", []string{"7.14.17503.0", "7.13.17406.0"}},
+			{"Safari", "Apple Inc.", []string{"17.4.1", "17.5"}},
+		},
+		optional: []inventorySoftware{
+			{"Google Chrome", "Google LLC", []string{"124.0.6367.119", "123.0.6312.123"}},
+			{"Slack", "Slack Technologies Inc.", []string{"4.38.115"}},
+			{"zoom.us", "Zoom Video Communications, Inc.", []string{"6.0.4", "5.17.11"}},
+			{"Visual Studio Code", "Microsoft Corporation", []string{"1.89.1", "1.88.1"}},
+			{"Docker", "Docker Inc", []string{"4.30.0", "4.27.2"}},
+			{"iTerm", "George Nachman", []string{"3.5.0"}},
+		},
+	},
+	"linux": {
+		always: []inventorySoftware{
+			{"openssh-server", "OpenBSD", []string{"8.9p1", "8.7p1"}},
+			{"openssl", "OpenSSL", []string{"3.0.13", "3.0.7"}},
+			{"falcon-sensor", "CrowdStrike, Inc.", []string{"7.14.0-16703", "7.11.0-16405"}},
+		},
+		optional: []inventorySoftware{
+			{"nginx", "nginx", []string{"1.24.0", "1.18.0"}},
+			{"postgresql-14", "PostgreSQL", []string{"14.11", "14.10"}},
+			{"docker-ce", "Docker Inc", []string{"26.1.1", "24.0.7"}},
+			{"openjdk-17-jre-headless", "Oracle", []string{"17.0.10", "17.0.8"}},
+			{"python3", "Python Software Foundation", []string{"3.10.12", "3.9.18"}},
+			{"sudo", "Todd C. Miller", []string{"1.9.15p5", "1.9.9"}},
+			{"xz-utils", "Tukaani", []string{"5.4.6", "5.6.0"}},
+		},
+	},
+}
+
+// installedSoftware returns the software installed on an asset. Versions
+// lag on some hosts, so not every asset is patched alike. Other generators
+// call it to find what a host runs.
+func installedSoftware(assetID, platform string) []map[string]interface{} {
+	r := assetRand(assetID + "/software")
+	catalog := softwareCatalog[platform]
+	software := []map[string]interface{}{}
+	add := func(s inventorySoftware) {
+		version := s.versions[0]
+		if r.Intn(100) < 30 {
+			version = s.versions[r.Intn(len(s.versions))]
+		}
+		software = append(software, map[string]interface{}{
+			"name":    s.name,
+			"version": version,
+			"vendor":  s.vendor,
+		})
+	}
+	for _, s := range catalog.always {
+		add(s)
+	}
+	for _, s := range catalog.optional {
+		if r.Intn(100) < 45 {
+			add(s)
+		}
+	}
+	return software
+}
+
+// pick returns one of values chosen by r
+func pick(r *rand.Rand, values ...string) string {
+	return values[r.Intn(len(values))]
+}
+
+// assetSerial returns a serial number of n uppercase letters and digits
+// after prefix
+func assetSerial(r *rand.Rand, prefix string, n int) string {
+	const chars = "ABCDEFGHJKLMNPQRSTUVWXYZ0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = chars[r.Intn(len(chars))]
+	}
+	return prefix + string(b)
+}
+
+// hostHardware returns a host's manufacturer, model and serial number.
+// Servers are VMware guests, matching the pool's VMware MAC addresses;
+// workstations are laptops.
+func hostHardware(r *rand.Rand, host *EntityHost) (string, string, string) {
+	switch {
+	case host.Role == "server":
+		return "VMware, Inc.", "VMware7,1", "VMware-" + strings.ToLower(strings.ReplaceAll(host.UUID, "-", ""))[:16]
+	case host.Platform == "darwin":
+		return "Apple Inc.", pick(r, "MacBookPro18,3", "Mac14,5", "Mac15,7"), assetSerial(r, "C02", 9)
+	case host.Platform == "windows":
+		return "Dell Inc.", pick(r, "Latitude 7440", "Latitude 5430", "Precision 5680"), assetSerial(r, "", 7)
+	}
+	return "LENOVO", pick(r, "ThinkPad T14 Gen 4", "ThinkPad X1 Carbon Gen 11"), assetSerial(r, "PF", 6)
+}
+
+// hostPriority rates a host for asset lookups: database and file servers
+// are critical, other servers high, workstations medium
+func hostPriority(host *EntityHost) string {
+	switch {
+	case strings.HasPrefix(host.Hostname, "db-"), strings.HasPrefix(host.Hostname, "file-"):
+		return "critical"
+	case host.Role == "server":
+		return "high"
+	}
+	return "medium"
+}
+
+// generateHost creates a snapshot of the next entity pool host
+func (g *AssetInventoryGenerator) generateHost(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	hosts := Entities.Hosts()
+	i, scanID, scanStarted := InventoryScans.next("host", len(hosts), now)
+	host := hosts[i]
+	r := assetRand(host.UUID)
+
+	manufacturer, model, serial := hostHardware(r, host)
+	owner := map[string]interface{}{}
+	bunit := "IT"
+	if u, ok := Entities.UserByName(host.Owner); ok {
+		owner = map[string]interface{}{
+			"username":   u.Username,
+			"full_name":  u.FullName,
+			"email":      u.Email,
+			"department": u.Department,
+		}
+		bunit = u.Department
+	}
+
+	record := map[string]interface{}{
+		"scan_id":      scanID,
+		"scan_started": scanStarted.UTC().Format(time.RFC3339),
+		"collected_at": now.UTC().Format(time.RFC3339),
+		"asset_type":   "host",
+		"asset_id":     host.UUID,
+		"hostname":     host.Hostname,
+		"fqdn":         host.FQDN,
+		"ip":           []string{host.IP},
+		"mac":          []string{host.MAC},
+		"os": map[string]interface{}{
+			"platform": host.Platform,
+			"name":     host.OSName,
+			"version":  host.OSVersion,
+		},
+		"role":          host.Role,
+		"owner":         owner,
+		"bunit":         bunit,
+		"priority":      hostPriority(host),
+		"category":      []string{host.Role, host.Platform},
+		"manufacturer":  manufacturer,
+		"model":         model,
+		"serial_number": serial,
+		"software":      installedSoftware(host.UUID, host.Platform),
+		"last_seen":     now.Add(-time.Duration(g.RandomInt(0, 3600)) * time.Second).UTC().Format(time.RFC3339),
+	}
+
+	return g.event("host", now, record, overrides)
+}
+
+// instanceOS returns the operating system an instance runs, from the role
+// in its name
+func instanceOS(r *rand.Rand, inst *AWSInstance) (string, string, string) {
+	if strings.Contains(inst.Name, "-ad-connector-") {
+		return "windows", "Microsoft Windows Server 2022 Datacenter", "10.0.20348"
+	}
+	if r.Intn(100) < 70 {
+		return "linux", "Amazon Linux", "2023"
+	}
+	return "linux", "Ubuntu", "22.04.4 LTS"
+}
+
+// generateCloudInstance creates a snapshot of the next AWS instance
+func (g *AssetInventoryGenerator) generateCloudInstance(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	var accounts []*AWSAccount
+	var instances []*AWSInstance
+	for _, a := range AWS.accounts {
+		for _, inst := range a.Instances {
+			accounts = append(accounts, a)
+			instances = append(instances, inst)
+		}
+	}
+	i, scanID, scanStarted := InventoryScans.next("cloud_instance", len(instances), now)
+	account, inst := accounts[i], instances[i]
+	r := assetRand(inst.ID)
+
+	platform, osName, osVersion := instanceOS(r, inst)
+	// EC2 interfaces have locally administered MAC addresses
+	mac := fmt.Sprintf("0e:%02x:%02x:%02x:%02x:%02x", r.Intn(256), r.Intn(256), r.Intn(256), r.Intn(256), r.Intn(256))
+	launched := now.Add(-time.Duration(24*(7+r.Intn(400))) * time.Hour)
+
+	priority := "medium"
+	if account.Name == "production" {
+		priority = "high"
+	}
+
+	record := map[string]interface{}{
+		"scan_id":      scanID,
+		"scan_started": scanStarted.UTC().Format(time.RFC3339),
+		"collected_at": now.UTC().Format(time.RFC3339),
+		"asset_type":   "cloud_instance",
+		"asset_id":     inst.ID,
+		"hostname":     inst.Name,
+		"fqdn":         account.PrivateHostname(inst),
+		"ip":           []string{inst.PrivateIP},
+		"mac":          []string{mac},
+		"os": map[string]interface{}{
+			"platform": platform,
+			"name":     osName,
+			"version":  osVersion,
+		},
+		"role":     "server",
+		"owner":    map[string]interface{}{},
+		"bunit":    account.Name,
+		"priority": priority,
+		"category": []string{"server", "cloud", platform},
+		"cloud": map[string]interface{}{
+			"provider":          "aws",
+			"account_id":        account.ID,
+			"account_name":      account.Name,
+			"region":            account.Region,
+			"availability_zone": inst.AZ,
+			"instance_id":       inst.ID,
+			"instance_type":     inst.InstanceType,
+			"vpc_id":            account.VPCID,
+			"subnet_id":         inst.SubnetID,
+			"network_interface": inst.ENI,
+			"launch_time":       launched.UTC().Format(time.RFC3339),
+			"state":             "running",
+		},
+		"software":  installedSoftware(inst.ID, platform),
+		"last_seen": now.Add(-time.Duration(g.RandomInt(0, 900)) * time.Second).UTC().Format(time.RFC3339),
+	}
+
+	return g.event("cloud_instance", now, record, overrides)
+}
+
+// event applies overrides to a record and wraps it as a generated event
+func (g *AssetInventoryGenerator) event(eventID string, timestamp time.Time, record, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields := g.ApplyOverrides(record, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "asset_inventory",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "asset:inventory:json",
+	}, nil
+}
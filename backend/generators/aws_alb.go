@@ -2,6 +2,7 @@ package generators
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +11,8 @@ import (
 )
 
 // AWSALBGenerator generates AWS Application Load Balancer access log events
+// for the production account's public load balancer, which routes each of
+// the web tier's sites to its target group by host header
 type AWSALBGenerator struct {
 	BaseGenerator
 }
@@ -48,6 +51,14 @@ func (g *AWSALBGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "text",
 			Description: "Successful HTTPS request through ALB",
 		},
+		{
+			ID:          "traffic",
+			Name:        "HTTPS Traffic",
+			Category:    "aws_alb",
+			EventID:     "h2",
+			Format:      "text",
+			Description: "Request whose status is drawn from the mix for its site and path",
+		},
 		{
 			ID:          "target_error",
 			Name:        "Target Error",
@@ -87,146 +98,217 @@ func (g *AWSALBGenerator) GetTemplates() []models.EventTemplate {
 func (g *AWSALBGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	switch templateID {
 	case "http_success":
-		return g.generateALBLog("http", 200, 200, false, overrides)
+		return g.generateALBLog("http", "success", overrides)
 	case "https_success":
-		return g.generateALBLog("https", 200, 200, false, overrides)
+		return g.generateALBLog("https", "success", overrides)
+	case "traffic":
+		return g.generateALBLog("h2", "traffic", overrides)
 	case "target_error":
-		return g.generateALBLog("https", 502, 500, false, overrides)
+		return g.generateALBLog("https", "target_error", overrides)
 	case "elb_error":
-		return g.generateALBLog("https", 503, -1, false, overrides)
+		return g.generateALBLog("https", "elb_error", overrides)
 	case "slow_response":
-		return g.generateALBLog("https", 200, 200, true, overrides)
+		return g.generateALBLog("https", "slow", overrides)
 	case "websocket":
-		return g.generateALBLog("wss", 101, 101, false, overrides)
+		return g.generateALBLog("wss", "websocket", overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
 }
 
-func (g *AWSALBGenerator) randomALBName() string {
-	names := []string{"app", "api", "web", "internal", "public"}
-	return fmt.Sprintf("%s-alb-%s", g.RandomChoice(names), g.RandomString(8))
-}
-
-func (g *AWSALBGenerator) randomTargetGroup() string {
-	names := []string{"web-targets", "api-targets", "backend-targets", "app-targets"}
-	return fmt.Sprintf("targetgroup/%s/%s", g.RandomChoice(names), g.RandomString(16))
+// albTarget returns the target group of a site, a target in it and the
+// port the target listens on. Sites route to the production instances of
+// the matching role.
+func (g *AWSALBGenerator) albTarget(account *AWSAccount, vhost string) (string, *AWSInstance, int) {
+	role, port := "-app-", 3000
+	switch name := vhostName(vhost); {
+	case strings.Contains(name, "api"):
+		role, port = "-api-", 8080
+	case name == "www":
+		role, port = "-web-", 80
+	}
+	var targets []*AWSInstance
+	for _, inst := range account.Instances {
+		if strings.Contains(inst.Name, role) {
+			targets = append(targets, inst)
+		}
+	}
+	target := account.RandomInstance(g.rnd)
+	if len(targets) > 0 {
+		target = targets[g.RandomInt(0, len(targets)-1)]
+	}
+	group := "tg-" + vhostName(vhost)
+	arn := fmt.Sprintf("arn:aws:elasticloadbalancing:%s:%s:targetgroup/%s/%s", account.Region, account.ID, group, edgeID(account.ID+"/"+group, 16))
+	return arn, target, port
 }
 
-func (g *AWSALBGenerator) randomRegion() string {
-	regions := []string{"us-east-1", "us-west-2", "eu-west-1", "ap-southeast-1"}
-	return g.RandomChoice(regions)
+// albRulePriority is the priority of the listener rule that matches a
+// site's host header
+func albRulePriority(vhost string) string {
+	for i, v := range webVhosts() {
+		if v == vhost {
+			return fmt.Sprint((i + 1) * 10)
+		}
+	}
+	return "default"
 }
 
-func (g *AWSALBGenerator) randomUserAgent() string {
-	agents := []string{
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
-		"Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X)",
-		"curl/7.88.1",
-		"Amazon CloudFront",
-		"ELB-HealthChecker/2.0",
+// albTime renders a processing time, which is -1 when the load balancer
+// did not get that far
+func albTime(seconds float64) string {
+	if seconds < 0 {
+		return "-1"
 	}
-	return g.RandomChoice(agents)
+	return fmt.Sprintf("%.3f", seconds)
 }
 
-func (g *AWSALBGenerator) generateALBLog(requestType string, elbStatusCode, targetStatusCode int, slowResponse bool, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
-
-	albName := g.randomALBName()
-	region := g.randomRegion()
-	clientIP := g.RandomIPv4External()
-	clientPort := g.RandomPort()
-	targetIP := g.RandomIPv4Internal()
-	targetPort := g.RandomChoice([]string{"80", "8080", "3000", "5000"})
+// generateALBLog creates an access log entry for a request to one of the
+// web tier's sites. The outcome decides the status codes and which of the
+// processing times the load balancer could measure.
+func (g *AWSALBGenerator) generateALBLog(requestType, outcome string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now().UTC()
+	account := AWS.Accounts()[0]
+	albName := themedName("public-alb")
+	elb := fmt.Sprintf("app/%s/%s", albName, edgeID(account.ID+"/"+albName, 16))
 
-	var requestProcessingTime, targetProcessingTime, responseProcessingTime float64
-	if slowResponse {
-		requestProcessingTime = float64(g.RandomInt(1, 10)) / 1000
-		targetProcessingTime = float64(g.RandomInt(5000, 30000)) / 1000
-		responseProcessingTime = float64(g.RandomInt(1, 100)) / 1000
-	} else {
-		requestProcessingTime = float64(g.RandomInt(1, 10)) / 1000
-		targetProcessingTime = float64(g.RandomInt(10, 500)) / 1000
-		responseProcessingTime = float64(g.RandomInt(1, 50)) / 1000
+	vhost := g.ZipfChoice(webVhosts())
+	status := 200
+	switch outcome {
+	case "traffic":
+		status = 0
+	case "target_error":
+		status = []int{500, 502, 503}[g.weightedIndex([]float64{70, 15, 15})]
+	case "elb_error":
+		status = []int{502, 503, 504}[g.weightedIndex([]float64{40, 35, 25})]
+	case "websocket":
+		vhost = publicHost("api")
+	}
+	req := g.randomEdgeRequest(vhost, status, false)
+	if outcome == "websocket" {
+		req.method, req.uri, req.protocol, req.status = "GET", "/api/v1/events", "HTTP/1.1", 101
+		req.bytesSent = int(g.RandomLogNormal(250000, 1.5))
+	}
+	if requestType == "h2" && req.protocol != "HTTP/2.0" {
+		requestType = "https"
 	}
 
-	receivedBytes := g.RandomInt(100, 10000)
-	sentBytes := g.RandomInt(1000, 100000)
-
-	methods := []string{"GET", "GET", "GET", "POST", "PUT", "DELETE"}
-	method := g.RandomChoice(methods)
-	paths := []string{"/", "/api/v1/users", "/api/v1/health", "/login", "/static/app.js", "/images/logo.png"}
-	path := g.RandomChoice(paths)
-
-	targetGroupArn := fmt.Sprintf("arn:aws:elasticloadbalancing:%s:123456789012:%s", region, g.randomTargetGroup())
-
-	userAgent := g.randomUserAgent()
-	sslCipher := g.RandomChoice([]string{"ECDHE-RSA-AES128-GCM-SHA256", "ECDHE-RSA-AES256-GCM-SHA384", "-"})
-	sslProtocol := g.RandomChoice([]string{"TLSv1.2", "TLSv1.3", "-"})
+	targetGroupArn, target, targetPort := g.albTarget(account, vhost)
+	requestTime := g.RandomLogNormal(0.001, 0.5)
+	targetTime := req.originTime
+	responseTime := g.RandomLogNormal(0.0005, 0.5)
+	targetStatus := fmt.Sprint(req.status)
+	targetAddr := fmt.Sprintf("%s:%d", target.PrivateIP, targetPort)
+	switch {
+	case outcome == "slow":
+		targetTime = g.RandomFloat(5, 30)
+	case outcome == "websocket":
+		targetTime = g.RandomFloat(0.002, 0.02)
+	case outcome == "elb_error" && req.status == 503:
+		// No healthy target to dispatch to
+		requestTime, targetTime, responseTime = -1, -1, -1
+		targetStatus, targetAddr = "-", "-"
+	case outcome == "elb_error":
+		// The target reset the connection or never answered
+		targetTime, responseTime = -1, -1
+		targetStatus = "-"
+		req.bytesSent = int(g.RandomLogNormal(350, 0.3))
+	}
 
-	traceID := fmt.Sprintf("Root=1-%x-%s", timestamp.Unix(), g.RandomString(24))
+	scheme, port := "https", 443
+	cipher, protocol := g.WeightedChoice([]string{"ECDHE-RSA-AES128-GCM-SHA256", "ECDHE-RSA-AES256-GCM-SHA384", "TLS_AES_128_GCM_SHA256"}, []float64{50, 20, 30}), "TLSv1.2"
+	if strings.HasPrefix(cipher, "TLS_") {
+		protocol = "TLSv1.3"
+	}
+	certArn := fmt.Sprintf("arn:aws:acm:%s:%s:certificate/%s", account.Region, account.ID, uuid.NewSHA1(uuid.NameSpaceOID, []byte("acm/"+Theme().Domain)))
+	switch requestType {
+	case "http":
+		scheme, port, cipher, protocol, certArn = "http", 80, "-", "-", "-"
+	case "wss":
+		scheme = "wss"
+	}
 
-	// Target status code of -1 means target didn't respond
-	targetStatusStr := "-"
-	targetStr := "-"
-	if targetStatusCode > 0 {
-		targetStatusStr = fmt.Sprintf("%d", targetStatusCode)
-		targetStr = fmt.Sprintf("%s:%s", targetIP, targetPort)
+	traceID := fmt.Sprintf("Root=1-%08x-%s", timestamp.Unix(), g.RandomHex(12))
+	total := 0.0
+	for _, t := range []float64{requestTime, targetTime, responseTime} {
+		if t > 0 {
+			total += t
+		}
+	}
+	created := timestamp.Add(-time.Duration(total * float64(time.Second)))
+	targetStatusCode := req.status
+	if targetStatus == "-" {
+		targetStatusCode = -1
 	}
 
-	// ALB log format (simplified)
-	rawEvent := fmt.Sprintf("%s %s %s/%s:%d %s:%d %s %.3f %.3f %.3f %d %s %d %d \"%s %s://%s%s HTTP/1.1\" \"%s\" %s %s %s \"%s\" \"%s\" \"%s\" %s",
+	rawEvent := fmt.Sprintf(`%s %s %s %s:%d %s %s %s %s %d %s %d %d "%s %s://%s:%d%s %s" "%s" %s %s %s "%s" "%s" "%s" %s %s "%s" "%s" "%s" "%s" "%s" "%s" "%s" TID_%s`,
 		requestType,
 		timestamp.Format("2006-01-02T15:04:05.000000Z"),
-		fmt.Sprintf("app/%s/%s", albName, g.RandomString(16)),
-		clientIP,
-		clientPort,
-		targetStr,
-		targetPort,
-		requestProcessingTime,
-		targetProcessingTime,
-		responseProcessingTime,
-		elbStatusCode,
-		targetStatusStr,
-		receivedBytes,
-		sentBytes,
-		method,
-		requestType,
-		fmt.Sprintf("%s.%s.elb.amazonaws.com", albName, region),
-		path,
-		userAgent,
-		sslCipher,
-		sslProtocol,
+		elb,
+		req.client.IP,
+		req.clientPort,
+		targetAddr,
+		albTime(requestTime),
+		albTime(targetTime),
+		albTime(responseTime),
+		req.status,
+		targetStatus,
+		req.bytesIn,
+		req.bytesSent,
+		req.method,
+		scheme,
+		vhost,
+		port,
+		req.uri,
+		req.protocol,
+		req.userAgent,
+		cipher,
+		protocol,
 		targetGroupArn,
 		traceID,
+		vhost,
+		certArn,
+		albRulePriority(vhost),
+		created.Format("2006-01-02T15:04:05.000000Z"),
+		"forward",
 		"-",
 		"-",
-		"0",
+		targetAddr,
+		targetStatus,
+		"-",
+		"-",
+		g.RandomHex(16),
 	)
 
 	fields := map[string]interface{}{
-		"type":                      requestType,
-		"timestamp":                 timestamp.Format(time.RFC3339),
-		"elb":                       albName,
-		"client_ip":                 clientIP,
-		"client_port":               clientPort,
-		"target_ip":                 targetIP,
-		"target_port":               targetPort,
-		"request_processing_time":   requestProcessingTime,
-		"target_processing_time":    targetProcessingTime,
-		"response_processing_time":  responseProcessingTime,
-		"elb_status_code":           elbStatusCode,
-		"target_status_code":        targetStatusCode,
-		"received_bytes":            receivedBytes,
-		"sent_bytes":                sentBytes,
-		"request_method":            method,
-		"request_url":               path,
-		"user_agent":                userAgent,
-		"ssl_cipher":                sslCipher,
-		"ssl_protocol":              sslProtocol,
-		"target_group_arn":          targetGroupArn,
-		"trace_id":                  traceID,
-		"region":                    region,
+		"type":                     requestType,
+		"timestamp":                timestamp.Format(time.RFC3339),
+		"elb":                      albName,
+		"account_id":               account.ID,
+		"region":                   account.Region,
+		"client_ip":                req.client.IP,
+		"client_port":              req.clientPort,
+		"target_ip":                target.PrivateIP,
+		"target_port":              targetPort,
+		"request_processing_time":  requestTime,
+		"target_processing_time":   targetTime,
+		"response_processing_time": responseTime,
+		"elb_status_code":          req.status,
+		"target_status_code":       targetStatusCode,
+		"received_bytes":           req.bytesIn,
+		"sent_bytes":               req.bytesSent,
+		"request_method":           req.method,
+		"request_url":              req.uri,
+		"domain_name":              vhost,
+		"vhost":                    vhost,
+		"user_agent":               req.userAgent,
+		"ssl_cipher":               cipher,
+		"ssl_protocol":             protocol,
+		"target_group_arn":         targetGroupArn,
+		"trace_id":                 traceID,
+	}
+	if targetAddr == "-" {
+		delete(fields, "target_ip")
+		delete(fields, "target_port")
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
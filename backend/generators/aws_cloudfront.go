@@ -0,0 +1,244 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// AWSCloudFrontGenerator generates CloudFront standard (access) log events
+// for the distributions in front of the web tier's public sites. Each site
+// has its own distribution, and requests come from edge locations near the
+// client.
+type AWSCloudFrontGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&AWSCloudFrontGenerator{})
+}
+
+// cloudFrontPOPs are edge locations by client country
+var cloudFrontPOPs = map[string][]string{
+	"US": {"IAD89-P1", "IAD61-P2", "ORD52-C1", "DFW56-P2", "SFO53-P3", "SEA19-C2", "ATL59-P1"},
+	"CA": {"YTO50-C2", "YUL62-C1"},
+	"GB": {"LHR61-P4", "LHR50-C1", "MAN51-C1"},
+	"DE": {"FRA56-P5", "FRA60-P2", "MUC50-C1"},
+	"FR": {"CDG52-P3", "MRS52-P1"},
+	"NL": {"AMS58-P1", "AMS1-C1"},
+	"JP": {"NRT57-P2", "KIX56-P1"},
+	"IN": {"BOM78-P1", "DEL54-C1", "BLR50-C1"},
+	"SG": {"SIN2-P4"},
+	"AU": {"SYD62-P2", "MEL50-C2"},
+	"BR": {"GRU3-P2", "GIG51-C1"},
+}
+
+// GetEventType returns the event type for CloudFront access logs
+func (g *AWSCloudFrontGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "aws_cloudfront",
+		Name:        "AWS CloudFront Access Logs",
+		Category:    "web",
+		Description: "CloudFront standard logs of the distributions in front of the public sites: cache hits and misses, WAF blocks and origin errors",
+		EventIDs:    []string{"Hit", "Miss", "RefreshHit", "Error", "Redirect"},
+	}
+}
+
+// GetTemplates returns available templates for CloudFront access logs
+func (g *AWSCloudFrontGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "request",
+			Name:        "Edge Request",
+			Category:    "aws_cloudfront",
+			EventID:     "Hit",
+			Format:      "text",
+			Description: "A request served from the edge cache or fetched from the origin, with the usual mix of statuses",
+		},
+		{
+			ID:          "waf_blocked",
+			Name:        "WAF Blocked Request",
+			Category:    "aws_cloudfront",
+			EventID:     "Error",
+			Format:      "text",
+			Description: "An attack request the distribution's web ACL blocks with a 403",
+		},
+		{
+			ID:          "origin_error",
+			Name:        "Origin Error",
+			Category:    "aws_cloudfront",
+			EventID:     "Error",
+			Format:      "text",
+			Description: "CloudFront could not connect to the origin or the origin timed out (502/504)",
+		},
+	}
+}
+
+// Generate creates a CloudFront access log event
+func (g *AWSCloudFrontGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	vhost := g.ZipfChoice(edgeVhosts())
+	switch templateID {
+	case "request":
+		return g.generateLog(g.randomEdgeRequest(vhost, 0, false), "", overrides)
+	case "waf_blocked":
+		return g.generateLog(g.randomEdgeRequest(vhost, 403, true), "Error", overrides)
+	case "origin_error":
+		status := []int{502, 504}[g.RandomInt(0, 1)]
+		detail := map[int]string{502: "OriginConnectError", 504: "OriginCommError"}[status]
+		return g.generateLog(g.randomEdgeRequest(vhost, status, false), detail, overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// cloudFrontDistribution returns the ID and domain name of a site's
+// distribution
+func cloudFrontDistribution(vhost string) (string, string) {
+	return "E" + strings.ToUpper(edgeID("cloudfront/"+vhost, 13)), "d" + edgeID("cloudfront/domain/"+vhost, 13) + ".cloudfront.net"
+}
+
+// cloudFrontField escapes a value as CloudFront does in its tab-separated
+// logs, or returns - for an empty one
+func cloudFrontField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.NewReplacer(" ", "%20", "\t", "%09", `"`, "%22").Replace(s)
+}
+
+// generateLog creates a log entry for r. detail is the detailed result type
+// of an error, or empty to derive the result from the request.
+func (g *AWSCloudFrontGenerator) generateLog(r edgeRequest, detail string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now().UTC()
+	distribution, domain := cloudFrontDistribution(r.vhost)
+	pops := cloudFrontPOPs[r.client.CountryCode]
+	if len(pops) == 0 {
+		pops = cloudFrontPOPs["US"]
+	}
+	pop := g.RandomChoice(pops)
+
+	result := "Miss"
+	switch {
+	case r.status >= 400:
+		result = "Error"
+	case g.cacheHit(r):
+		result = g.WeightedChoice([]string{"Hit", "RefreshHit"}, []float64{92, 8})
+	}
+	if detail == "" {
+		detail = result
+	}
+	timeTaken := r.originTime + g.RandomLogNormal(0.01, 0.5)
+	switch {
+	case result == "Hit":
+		timeTaken = g.RandomLogNormal(0.002, 0.6)
+	case r.status == 403 && r.attack != "":
+		// Blocked by the web ACL before reaching the origin
+		timeTaken = g.RandomLogNormal(0.001, 0.4)
+	case r.status == 502:
+		timeTaken = g.RandomFloat(1, 10)
+	case r.status == 504:
+		timeTaken = 30 + g.RandomFloat(0, 0.1)
+	}
+	firstByte := timeTaken * g.RandomFloat(0.6, 0.98)
+
+	cipher := g.WeightedChoice([]string{"TLS_AES_128_GCM_SHA256", "ECDHE-RSA-AES128-GCM-SHA256", "TLS_CHACHA20_POLY1305_SHA256"}, []float64{60, 30, 10})
+	tlsVersion := "TLSv1.3"
+	if strings.HasPrefix(cipher, "ECDHE") {
+		tlsVersion = "TLSv1.2"
+	}
+	protocolVersion := r.protocol
+	if protocolVersion == "HTTP/2.0" && g.RandomInt(1, 100) <= 15 {
+		protocolVersion = "HTTP/3.0"
+	}
+	requestID := strings.ToUpper(g.RandomString(8)) + "_" + g.RandomString(43)
+	contentLen := "-"
+	if r.bytesSent > 0 {
+		contentLen = fmt.Sprint(r.bytesSent)
+	}
+
+	values := []string{
+		timestamp.Format("2006-01-02"),
+		timestamp.Format("15:04:05"),
+		pop,
+		fmt.Sprint(r.bytesSent + g.RandomInt(250, 600)),
+		r.client.IP,
+		r.method,
+		domain,
+		r.path(),
+		fmt.Sprint(r.status),
+		cloudFrontField(strings.TrimPrefix(r.referer, "-")),
+		cloudFrontField(r.userAgent),
+		cloudFrontField(r.query()),
+		"-",
+		result,
+		requestID,
+		r.vhost,
+		"https",
+		fmt.Sprint(r.bytesIn),
+		fmt.Sprintf("%.3f", timeTaken),
+		"-",
+		tlsVersion,
+		cipher,
+		result,
+		protocolVersion,
+		"-",
+		"-",
+		fmt.Sprint(r.clientPort),
+		fmt.Sprintf("%.3f", firstByte),
+		detail,
+		r.contentType(),
+		contentLen,
+		"-",
+		"-",
+	}
+
+	fields := map[string]interface{}{
+		"date":                   values[0],
+		"time":                   values[1],
+		"x_edge_location":        pop,
+		"sc_bytes":               values[3],
+		"c_ip":                   r.client.IP,
+		"cs_method":              r.method,
+		"cs_host":                domain,
+		"cs_uri_stem":            r.path(),
+		"sc_status":              r.status,
+		"cs_referer":             values[9],
+		"cs_user_agent":          r.userAgent,
+		"cs_uri_query":           values[11],
+		"x_edge_result_type":     result,
+		"x_edge_request_id":      requestID,
+		"x_host_header":          r.vhost,
+		"cs_bytes":               r.bytesIn,
+		"time_taken":             timeTaken,
+		"ssl_protocol":           tlsVersion,
+		"ssl_cipher":             cipher,
+		"cs_protocol_version":    protocolVersion,
+		"c_port":                 r.clientPort,
+		"time_to_first_byte":     firstByte,
+		"x_edge_detailed_result": detail,
+		"sc_content_type":        r.contentType(),
+		"distribution_id":        distribution,
+		"vhost":                  r.vhost,
+		"client_country":         r.client.CountryCode,
+		"x_edge_response_result": result,
+		"x_forwarded_for":        "-",
+		"cs_protocol":            "https",
+		"sc_content_len":         contentLen,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "aws_cloudfront",
+		EventID:    result,
+		Timestamp:  timestamp,
+		RawEvent:   strings.Join(values, "\t"),
+		Fields:     fields,
+		Sourcetype: "aws:cloudfront:accesslogs",
+	}, nil
+}
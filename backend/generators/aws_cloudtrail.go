@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -26,7 +25,7 @@ func (g *AWSCloudTrailGenerator) GetEventType() models.EventType {
 		Name:        "AWS CloudTrail",
 		Category:    "cloud",
 		Description: "AWS CloudTrail API activity logs - who did what, when, and from where",
-		EventIDs:    []string{"ConsoleLogin", "AssumeRole", "CreateUser", "DeleteUser", "PutBucketPolicy", "AuthorizeSecurityGroupIngress", "RunInstances", "StopInstances", "CreateAccessKey", "GetSecretValue"},
+		EventIDs:    []string{"ConsoleLogin", "AssumeRole", "CreateUser", "DeleteUser", "PutBucketPolicy", "AuthorizeSecurityGroupIngress", "RunInstances", "StopInstances", "CreateAccessKey", "GetSecretValue", "GetObject", "PutObject", "Invoke", "ApiCallRateInsight", "ApiErrorRateInsight"},
 	}
 }
 
@@ -113,40 +112,90 @@ func (g *AWSCloudTrailGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "json",
 			Description: "Secrets Manager secret retrieval",
 		},
+		{
+			ID:          "GetObject",
+			Name:        "Get Object",
+			Category:    "aws_cloudtrail",
+			EventID:     "GetObject",
+			Format:      "json",
+			Description: "S3 object download (data event)",
+		},
+		{
+			ID:          "PutObject",
+			Name:        "Put Object",
+			Category:    "aws_cloudtrail",
+			EventID:     "PutObject",
+			Format:      "json",
+			Description: "S3 object upload (data event)",
+		},
+		{
+			ID:          "Invoke",
+			Name:        "Lambda Invoke",
+			Category:    "aws_cloudtrail",
+			EventID:     "Invoke",
+			Format:      "json",
+			Description: "Lambda function invocation (data event)",
+		},
+		{
+			ID:          "ApiCallRateInsight",
+			Name:        "API Call Rate Insight",
+			Category:    "aws_cloudtrail",
+			EventID:     "ApiCallRateInsight",
+			Format:      "json",
+			Description: "CloudTrail Insights start or end of an unusual API call rate",
+		},
+		{
+			ID:          "ApiErrorRateInsight",
+			Name:        "API Error Rate Insight",
+			Category:    "aws_cloudtrail",
+			EventID:     "ApiErrorRateInsight",
+			Format:      "json",
+			Description: "CloudTrail Insights start or end of an unusual API error rate",
+		},
 	}
 }
 
-// Generate creates an AWS CloudTrail event
+// Generate creates an AWS CloudTrail event. API calls other than console
+// sign-ins fail at the configured error rate.
 func (g *AWSCloudTrailGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	var event *models.GeneratedEvent
+	var err error
 	switch templateID {
 	case "ConsoleLogin":
-		return g.generateConsoleLogin(overrides)
+		return g.generateConsoleLogin(time.Now(), overrides)
+	case "ApiCallRateInsight", "ApiErrorRateInsight":
+		return g.generateInsight(templateID, overrides)
 	case "AssumeRole":
-		return g.generateAssumeRole(overrides)
+		event, err = g.generateAssumeRole(overrides)
 	case "CreateUser":
-		return g.generateCreateUser(overrides)
+		event, err = g.generateCreateUser(time.Now(), overrides)
 	case "DeleteUser":
-		return g.generateDeleteUser(overrides)
+		event, err = g.generateDeleteUser(time.Now(), overrides)
 	case "PutBucketPolicy":
-		return g.generatePutBucketPolicy(overrides)
+		event, err = g.generatePutBucketPolicy(overrides)
 	case "AuthorizeSecurityGroupIngress":
-		return g.generateAuthorizeSecurityGroupIngress(overrides)
+		event, err = g.generateAuthorizeSecurityGroupIngress(overrides)
 	case "RunInstances":
-		return g.generateRunInstances(overrides)
+		event, err = g.generateRunInstances(overrides)
 	case "StopInstances":
-		return g.generateStopInstances(overrides)
+		event, err = g.generateStopInstances(overrides)
 	case "CreateAccessKey":
-		return g.generateCreateAccessKey(overrides)
+		event, err = g.generateCreateAccessKey(time.Now(), overrides)
 	case "GetSecretValue":
-		return g.generateGetSecretValue(overrides)
+		event, err = g.generateGetSecretValue(time.Now(), overrides)
+	case "GetObject", "PutObject":
+		event, err = g.generateS3Object(templateID, time.Now(), overrides)
+	case "Invoke":
+		event, err = g.generateLambdaInvoke(time.Now(), overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
+	return g.withErrorRate(event, err, overrides)
 }
 
 // Helper functions
 func (g *AWSCloudTrailGenerator) randomAccountID() string {
-	return fmt.Sprintf("%012d", g.RandomInt(100000000000, 999999999999))
+	return AWS.RandomAccount(g.rnd).ID
 }
 
 func (g *AWSCloudTrailGenerator) randomRegion() string {
@@ -198,13 +247,16 @@ func (g *AWSCloudTrailGenerator) buildBaseEvent(eventName, eventSource, accountI
 	}
 }
 
-func (g *AWSCloudTrailGenerator) generateConsoleLogin(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
+func (g *AWSCloudTrailGenerator) generateConsoleLogin(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	accountID := g.randomAccountID()
 	region := g.randomRegion()
 	username := g.randomIAMUser()
 
 	success := g.RandomInt(0, 10) > 2 // 80% success rate
+	if resp, ok := overrides["responseElements"].(map[string]interface{}); ok {
+		// Keep errorMessage consistent with an overridden result
+		success = resp["ConsoleLogin"] == "Success"
+	}
 
 	event := g.buildBaseEvent("ConsoleLogin", "signin.amazonaws.com", accountID, region, timestamp)
 	event["userIdentity"] = map[string]interface{}{
@@ -235,7 +287,7 @@ func (g *AWSCloudTrailGenerator) generateConsoleLogin(overrides map[string]inter
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -281,7 +333,7 @@ func (g *AWSCloudTrailGenerator) generateAssumeRole(overrides map[string]interfa
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -294,8 +346,7 @@ func (g *AWSCloudTrailGenerator) generateAssumeRole(overrides map[string]interfa
 	}, nil
 }
 
-func (g *AWSCloudTrailGenerator) generateCreateUser(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
+func (g *AWSCloudTrailGenerator) generateCreateUser(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	accountID := g.randomAccountID()
 	region := g.randomRegion()
 	newUser := g.randomIAMUser()
@@ -325,7 +376,7 @@ func (g *AWSCloudTrailGenerator) generateCreateUser(overrides map[string]interfa
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -338,8 +389,7 @@ func (g *AWSCloudTrailGenerator) generateCreateUser(overrides map[string]interfa
 	}, nil
 }
 
-func (g *AWSCloudTrailGenerator) generateDeleteUser(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
+func (g *AWSCloudTrailGenerator) generateDeleteUser(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	accountID := g.randomAccountID()
 	region := g.randomRegion()
 	deletedUser := g.randomIAMUser()
@@ -360,7 +410,7 @@ func (g *AWSCloudTrailGenerator) generateDeleteUser(overrides map[string]interfa
 	event["responseElements"] = nil
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -377,7 +427,7 @@ func (g *AWSCloudTrailGenerator) generatePutBucketPolicy(overrides map[string]in
 	timestamp := time.Now()
 	accountID := g.randomAccountID()
 	region := g.randomRegion()
-	bucketName := fmt.Sprintf("%s-bucket-%s", g.RandomChoice([]string{"data", "logs", "backup", "assets", "config"}), g.RandomString(8))
+	bucketName := themedBucket(g.RandomChoice([]string{"data", "logs", "backup", "assets", "config"}), g.RandomString(8))
 
 	event := g.buildBaseEvent("PutBucketPolicy", "s3.amazonaws.com", accountID, region, timestamp)
 	event["userIdentity"] = map[string]interface{}{
@@ -405,7 +455,7 @@ func (g *AWSCloudTrailGenerator) generatePutBucketPolicy(overrides map[string]in
 	event["responseElements"] = nil
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -458,7 +508,7 @@ func (g *AWSCloudTrailGenerator) generateAuthorizeSecurityGroupIngress(overrides
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -510,7 +560,7 @@ func (g *AWSCloudTrailGenerator) generateRunInstances(overrides map[string]inter
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -559,7 +609,7 @@ func (g *AWSCloudTrailGenerator) generateStopInstances(overrides map[string]inte
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -572,8 +622,7 @@ func (g *AWSCloudTrailGenerator) generateStopInstances(overrides map[string]inte
 	}, nil
 }
 
-func (g *AWSCloudTrailGenerator) generateCreateAccessKey(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
+func (g *AWSCloudTrailGenerator) generateCreateAccessKey(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	accountID := g.randomAccountID()
 	region := g.randomRegion()
 	targetUser := g.randomIAMUser()
@@ -600,7 +649,7 @@ func (g *AWSCloudTrailGenerator) generateCreateAccessKey(overrides map[string]in
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -613,8 +662,7 @@ func (g *AWSCloudTrailGenerator) generateCreateAccessKey(overrides map[string]in
 	}, nil
 }
 
-func (g *AWSCloudTrailGenerator) generateGetSecretValue(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
+func (g *AWSCloudTrailGenerator) generateGetSecretValue(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	accountID := g.randomAccountID()
 	region := g.randomRegion()
 	secretName := g.RandomChoice([]string{"prod/database/password", "api/keys/external", "config/encryption-key", "service/oauth/client-secret"})
@@ -636,7 +684,7 @@ func (g *AWSCloudTrailGenerator) generateGetSecretValue(overrides map[string]int
 	event["readOnly"] = true
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -0,0 +1,359 @@
+package generators
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// s3ObjectPrefixes are the key prefixes objects in generated buckets live under
+var s3ObjectPrefixes = []string{"reports/2024/", "exports/customers/", "backups/db/", "uploads/invoices/", "logs/app/", "config/"}
+
+// lambdaFunctions are the functions Invoke data events name, with the
+// service that usually triggers each
+var lambdaFunctions = []struct {
+	Name    string
+	Trigger string
+}{
+	{"image-thumbnailer", "s3.amazonaws.com"},
+	{"order-processor", "sqs.amazonaws.com"},
+	{"nightly-report", "events.amazonaws.com"},
+	{"auth-token-refresh", "events.amazonaws.com"},
+	{"api-backend", "apigateway.amazonaws.com"},
+}
+
+// dataEvent builds a data plane event, which CloudTrail logs apart from
+// management events and only for trails that select it
+func (g *AWSCloudTrailGenerator) dataEvent(eventName, eventSource, accountID, region string, readOnly bool, timestamp time.Time) map[string]interface{} {
+	event := g.buildBaseEvent(eventName, eventSource, accountID, region, timestamp)
+	event["managementEvent"] = false
+	event["eventCategory"] = "Data"
+	event["readOnly"] = readOnly
+	return event
+}
+
+// appRoleIdentity is an application role session, the usual caller of data
+// plane APIs
+func (g *AWSCloudTrailGenerator) appRoleIdentity(accountID string) map[string]interface{} {
+	role := g.RandomChoice([]string{"AppServiceRole", "DataPipelineRole", "BatchJobRole", "WebTierRole"})
+	session := fmt.Sprintf("i-%s", g.RandomString(17))
+	return map[string]interface{}{
+		"type":        "AssumedRole",
+		"principalId": "AROA" + g.RandomString(17) + ":" + session,
+		"arn":         fmt.Sprintf("arn:aws:sts::%s:assumed-role/%s/%s", accountID, role, session),
+		"accountId":   accountID,
+		"sessionContext": map[string]interface{}{
+			"sessionIssuer": map[string]interface{}{
+				"type":        "Role",
+				"principalId": "AROA" + g.RandomString(17),
+				"arn":         fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, role),
+				"accountId":   accountID,
+				"userName":    role,
+			},
+		},
+	}
+}
+
+// generateS3Object creates an S3 GetObject or PutObject data event. Some
+// calls come through the account's VPC endpoint, from one of its instances.
+func (g *AWSCloudTrailGenerator) generateS3Object(eventName string, timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	account := AWS.RandomAccount(g.rnd)
+	accountID := account.ID
+	region := g.randomRegion()
+	viaEndpoint := g.RandomInt(1, 100) <= 40
+	if viaEndpoint {
+		region = account.Region
+	}
+	bucketName := themedBucket(g.RandomChoice([]string{"data", "logs", "backup", "assets", "config"}), g.RandomString(8))
+	key := g.RandomChoice(s3ObjectPrefixes) + g.RandomString(12) + g.RandomChoice([]string{".csv", ".json", ".gz", ".pdf", ".parquet"})
+	host := fmt.Sprintf("%s.s3.%s.amazonaws.com", bucketName, region)
+	size := g.RandomInt(512, 50*1024*1024)
+
+	event := g.dataEvent(eventName, "s3.amazonaws.com", accountID, region, eventName == "GetObject", timestamp)
+	event["userIdentity"] = g.appRoleIdentity(accountID)
+	event["userAgent"] = g.RandomChoice([]string{
+		"[aws-sdk-java/1.12.529 Linux/5.10.192 OpenJDK_64-Bit_Server_VM/17.0.8+7-LTS]",
+		"[Boto3/1.28.0 Python/3.9.0 Linux/5.10.192 Botocore/1.31.0]",
+		"[aws-cli/2.13.0 Python/3.11.4 Linux/5.15.0 exe/x86_64.amzn.2 command/s3.cp]",
+	})
+	if viaEndpoint {
+		event["sourceIPAddress"] = account.RandomInstance(g.rnd).PrivateIP
+		event["vpcEndpointId"] = account.S3EndpointID
+	}
+
+	event["requestParameters"] = map[string]interface{}{
+		"bucketName": bucketName,
+		"Host":       host,
+		"key":        key,
+	}
+	bytesIn, bytesOut := 0, size
+	if eventName == "PutObject" {
+		bytesIn, bytesOut = size, 0
+		event["responseElements"] = map[string]interface{}{
+			"x-amz-server-side-encryption": "AES256",
+		}
+	} else {
+		event["responseElements"] = nil
+	}
+	event["additionalEventData"] = map[string]interface{}{
+		"SignatureVersion":     "SigV4",
+		"CipherSuite":          "ECDHE-RSA-AES128-GCM-SHA256",
+		"bytesTransferredIn":   bytesIn,
+		"bytesTransferredOut":  bytesOut,
+		"AuthenticationMethod": "AuthHeader",
+		"x-amz-id-2":           g.RandomString(76),
+	}
+	event["resources"] = []map[string]interface{}{
+		{"type": "AWS::S3::Object", "ARN": fmt.Sprintf("arn:aws:s3:::%s/%s", bucketName, key)},
+		{"accountId": accountID, "type": "AWS::S3::Bucket", "ARN": "arn:aws:s3:::" + bucketName},
+	}
+	event["tlsDetails"] = map[string]interface{}{
+		"tlsVersion":               "TLSv1.2",
+		"cipherSuite":              "ECDHE-RSA-AES128-GCM-SHA256",
+		"clientProvidedHostHeader": host,
+	}
+
+	fields := g.ApplyOverrides(event, overrides)
+	rawEvent, _ := g.marshalRawJSON(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "aws_cloudtrail",
+		EventID:    eventName,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "aws:cloudtrail",
+	}, nil
+}
+
+// generateLambdaInvoke creates a Lambda Invoke data event, mostly made by
+// the service that triggers the function
+func (g *AWSCloudTrailGenerator) generateLambdaInvoke(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	accountID := g.randomAccountID()
+	region := g.randomRegion()
+	fn := lambdaFunctions[g.RandomInt(0, len(lambdaFunctions)-1)]
+	functionARN := fmt.Sprintf("arn:aws:lambda:%s:%s:function:%s", region, accountID, fn.Name)
+
+	event := g.dataEvent("Invoke", "lambda.amazonaws.com", accountID, region, false, timestamp)
+	requestParameters := map[string]interface{}{
+		"functionName": functionARN,
+	}
+	if g.RandomInt(1, 100) <= 70 {
+		event["userIdentity"] = map[string]interface{}{
+			"type":      "AWSService",
+			"invokedBy": fn.Trigger,
+		}
+		event["sourceIPAddress"] = fn.Trigger
+		event["userAgent"] = fn.Trigger
+		if fn.Trigger == "s3.amazonaws.com" {
+			requestParameters["sourceArn"] = "arn:aws:s3:::" + themedBucket("assets", g.RandomString(8))
+		}
+	} else {
+		event["userIdentity"] = g.appRoleIdentity(accountID)
+		requestParameters["invocationType"] = g.RandomChoice([]string{"RequestResponse", "Event"})
+	}
+	event["requestParameters"] = requestParameters
+	event["responseElements"] = nil
+	event["additionalEventData"] = map[string]interface{}{
+		"functionVersion": functionARN + ":$LATEST",
+	}
+	event["resources"] = []map[string]interface{}{
+		{"accountId": accountID, "type": "AWS::Lambda::Function", "ARN": functionARN},
+	}
+
+	fields := g.ApplyOverrides(event, overrides)
+	rawEvent, _ := g.marshalRawJSON(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "aws_cloudtrail",
+		EventID:    "Invoke",
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "aws:cloudtrail",
+	}, nil
+}
+
+// insightAPIs are the calls whose rate Insights flags
+var insightAPIs = []struct {
+	EventSource string
+	EventName   string
+}{
+	{"ec2.amazonaws.com", "RunInstances"},
+	{"ec2.amazonaws.com", "AuthorizeSecurityGroupIngress"},
+	{"iam.amazonaws.com", "CreateAccessKey"},
+	{"iam.amazonaws.com", "CreateUser"},
+	{"s3.amazonaws.com", "PutBucketPolicy"},
+	{"sts.amazonaws.com", "AssumeRole"},
+	{"secretsmanager.amazonaws.com", "GetSecretValue"},
+}
+
+// cloudTrailInsight is an unusual API call or error rate that Insights has
+// reported the start of and will report the end of
+type cloudTrailInsight struct {
+	SharedEventID string
+	InsightType   string
+	EventSource   string
+	EventName     string
+	ErrorCode     string
+	AccountID     string
+	Region        string
+	CallerARN     string
+	UserAgent     string
+	Baseline      float64
+	Insight       float64
+	Started       time.Time
+}
+
+// maxOpenInsights bounds the insights awaiting an End event
+const maxOpenInsights = 50
+
+// CloudTrailInsightTracker keeps the insights that have started, so each is
+// ended by an event with the same sharedEventID
+type CloudTrailInsightTracker struct {
+	mu      sync.Mutex
+	started []*cloudTrailInsight
+}
+
+// CloudTrailInsights is the tracker shared by the CloudTrail generator
+var CloudTrailInsights = &CloudTrailInsightTracker{}
+
+// next returns the insight to report: one of the given type that has
+// started, removed from the tracker, or nil when a new one should start
+func (t *CloudTrailInsightTracker) next(rnd *RandomStream, insightType string) *cloudTrailInsight {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var candidates []int
+	for i, in := range t.started {
+		if in.InsightType == insightType {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 || (len(t.started) < maxOpenInsights && rnd.float64() < 0.5) {
+		return nil
+	}
+	i := candidates[rnd.intn(len(candidates))]
+	in := t.started[i]
+	t.started = append(t.started[:i], t.started[i+1:]...)
+	return in
+}
+
+// start records an insight that has started
+func (t *CloudTrailInsightTracker) start(in *cloudTrailInsight) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.started) >= maxOpenInsights {
+		t.started = t.started[1:]
+	}
+	t.started = append(t.started, in)
+}
+
+// Count returns the number of insights that have started but not ended
+func (t *CloudTrailInsightTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.started)
+}
+
+// generateInsight creates a CloudTrail Insights event of the given type
+// (ApiCallRateInsight or ApiErrorRateInsight). Each insight is reported by
+// a Start event and later an End event with the same sharedEventID.
+func (g *AWSCloudTrailGenerator) generateInsight(insightType string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+
+	in := CloudTrailInsights.next(g.rnd, insightType)
+	state := "End"
+	if in == nil {
+		state = "Start"
+		api := insightAPIs[g.RandomInt(0, len(insightAPIs)-1)]
+		accountID := g.randomAccountID()
+		in = &cloudTrailInsight{
+			SharedEventID: uuid.New().String(),
+			InsightType:   insightType,
+			EventSource:   api.EventSource,
+			EventName:     api.EventName,
+			AccountID:     accountID,
+			Region:        g.randomRegion(),
+			CallerARN:     fmt.Sprintf("arn:aws:iam::%s:user/%s", accountID, g.randomIAMUser()),
+			UserAgent:     g.randomUserAgent(),
+			Baseline:      g.RandomFloat(0.001, 0.5),
+			Insight:       g.RandomFloat(5, 60),
+			Started:       timestamp,
+		}
+		if insightType == "ApiErrorRateInsight" {
+			in.ErrorCode, _ = g.apiError(in.EventSource, in.EventName, in.CallerARN)
+		}
+		CloudTrailInsights.start(in)
+	}
+
+	statistics := map[string]interface{}{
+		"baseline": map[string]interface{}{"average": in.Baseline},
+		"insight":  map[string]interface{}{"average": in.Insight},
+	}
+	if state == "End" {
+		statistics["insightDuration"] = int(timestamp.Sub(in.Started).Minutes()) + 1
+		statistics["baselineDuration"] = 10080 - g.RandomInt(0, 60) // About the week before
+	}
+
+	errorCode := "null"
+	if in.ErrorCode != "" {
+		errorCode = in.ErrorCode
+	}
+	attribution := func(attribute, value string) map[string]interface{} {
+		return map[string]interface{}{
+			"attribute": attribute,
+			"insight":   []map[string]interface{}{{"value": value, "average": in.Insight}},
+			"baseline":  []map[string]interface{}{{"value": value, "average": in.Baseline}},
+		}
+	}
+
+	insightDetails := map[string]interface{}{
+		"state":       state,
+		"eventSource": in.EventSource,
+		"eventName":   in.EventName,
+		"insightType": insightType,
+		"insightContext": map[string]interface{}{
+			"statistics": statistics,
+			"attributions": []map[string]interface{}{
+				attribution("userIdentityArn", in.CallerARN),
+				attribution("userAgent", in.UserAgent),
+				attribution("errorCode", errorCode),
+			},
+		},
+	}
+	if in.ErrorCode != "" {
+		insightDetails["errorCode"] = in.ErrorCode
+	}
+
+	event := map[string]interface{}{
+		"eventVersion":       "1.08",
+		"eventTime":          timestamp.UTC().Format(time.RFC3339),
+		"awsRegion":          in.Region,
+		"eventID":            uuid.New().String(),
+		"eventType":          "AwsCloudTrailInsight",
+		"recipientAccountId": in.AccountID,
+		"sharedEventID":      in.SharedEventID,
+		"insightDetails":     insightDetails,
+		"eventCategory":      "Insight",
+	}
+
+	fields := g.ApplyOverrides(event, overrides)
+	rawEvent, _ := g.marshalRawJSON(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "aws_cloudtrail",
+		EventID:    insightType,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "aws:cloudtrail",
+	}, nil
+}
@@ -0,0 +1,111 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// cloudTrailConfig holds the CloudTrail outcome settings, failing 5% of API
+// calls by default
+var cloudTrailConfig = struct {
+	sync.RWMutex
+	models.CloudTrailConfig
+}{CloudTrailConfig: models.CloudTrailConfig{ErrorRate: 5}}
+
+// CloudTrailConfig returns the CloudTrail outcome settings
+func CloudTrailConfig() models.CloudTrailConfig {
+	cloudTrailConfig.RLock()
+	defer cloudTrailConfig.RUnlock()
+	return cloudTrailConfig.CloudTrailConfig
+}
+
+// SetCloudTrailConfig replaces the CloudTrail outcome settings
+func SetCloudTrailConfig(cfg models.CloudTrailConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	cloudTrailConfig.Lock()
+	defer cloudTrailConfig.Unlock()
+	cloudTrailConfig.CloudTrailConfig = cfg
+	return nil
+}
+
+// cloudTrailService returns the IAM service prefix of an event source, such
+// as iam for iam.amazonaws.com
+func cloudTrailService(eventSource string) string {
+	return strings.TrimSuffix(eventSource, ".amazonaws.com")
+}
+
+// apiError returns the errorCode and errorMessage a service logs when it
+// denies or throttles a call, in that service's wording
+func (g *AWSCloudTrailGenerator) apiError(eventSource, eventName, callerARN string) (string, string) {
+	service := cloudTrailService(eventSource)
+	action := service + ":" + eventName
+	if service == "lambda" && eventName == "Invoke" {
+		action = "lambda:InvokeFunction"
+	}
+
+	if g.RandomInt(1, 100) <= 70 {
+		switch service {
+		case "ec2":
+			return "Client.UnauthorizedOperation", "You are not authorized to perform this operation. Encoded authorization failure message: " + g.RandomString(64)
+		case "s3":
+			return "AccessDenied", "Access Denied"
+		}
+		if callerARN == "" {
+			// A service calling on a resource policy's behalf
+			return "AccessDenied", "Access Denied"
+		}
+		return "AccessDenied", fmt.Sprintf("User: %s is not authorized to perform: %s because no identity-based policy allows the %s action", callerARN, action, action)
+	}
+
+	switch service {
+	case "ec2":
+		return "Client.RequestLimitExceeded", "Request limit exceeded."
+	case "s3":
+		return "SlowDown", "Please reduce your request rate."
+	case "iam", "sts":
+		return "Throttling", "Rate exceeded"
+	}
+	return "ThrottlingException", "Rate exceeded"
+}
+
+// withErrorRate fails a generated API call at the configured error rate:
+// the call gets an errorCode and errorMessage and no responseElements.
+// Fields set by the overrides are kept, and an overridden errorCode leaves
+// the outcome to the caller.
+func (g *AWSCloudTrailGenerator) withErrorRate(event *models.GeneratedEvent, err error, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	if err != nil || g.rnd.float64()*100 >= CloudTrailConfig().ErrorRate {
+		return event, err
+	}
+	if _, ok := overrides["errorCode"]; ok {
+		return event, nil
+	}
+
+	fields := event.Fields
+	callerARN := ""
+	if identity, ok := fields["userIdentity"].(map[string]interface{}); ok {
+		callerARN, _ = identity["arn"].(string)
+	}
+	code, message := g.apiError(fmt.Sprintf("%v", fields["eventSource"]), fmt.Sprintf("%v", fields["eventName"]), callerARN)
+	outcome := map[string]interface{}{
+		"errorCode":        code,
+		"errorMessage":     message,
+		"responseElements": nil,
+	}
+	for k, v := range outcome {
+		if _, ok := overrides[k]; !ok {
+			fields[k] = v
+		}
+	}
+
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+	event.RawEvent = string(rawEvent)
+	return event, nil
+}
@@ -0,0 +1,354 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// AWSCostGenerator generates AWS Cost and Usage Report (CUR 2.0) line items
+// and Cost Anomaly Detection findings for the AWS environment's accounts
+type AWSCostGenerator struct {
+	BaseGenerator
+	monitorID string
+}
+
+func init() {
+	Register(&AWSCostGenerator{monitorID: uuid.New().String()})
+}
+
+// awsCostService is a charge the AWS accounts run up every day
+type awsCostService struct {
+	product     string // Service code, e.g. AmazonEC2
+	name        string // Product name in the report
+	anomalyName string // Service as Cost Anomaly Detection names it
+	usageType   string // Without the region prefix
+	operation   string
+	unit        string
+	description string // Line item description; %s is the rate
+	rate        float64
+	daily       float64 // Usage of a day in the production account
+}
+
+// awsCostServices are the environment's daily charges
+var awsCostServices = []awsCostService{
+	{"AmazonEC2", "Amazon Elastic Compute Cloud", "Amazon Elastic Compute Cloud - Compute", "BoxUsage:m5.xlarge", "RunInstances", "Hrs",
+		"$%s per On Demand Linux m5.xlarge Instance Hour", 0.192, 24 * 6},
+	{"AmazonEC2", "Amazon Elastic Compute Cloud", "EC2 - Other", "EBS:VolumeUsage.gp3", "CreateVolume-Gp3", "GB-Mo",
+		"$%s per GB-month of General Purpose (gp3) provisioned storage", 0.08, 2400.0 / 30},
+	{"AmazonEC2", "Amazon Elastic Compute Cloud", "EC2 - Other", "DataTransfer-Out-Bytes", "RunInstances", "GB",
+		"$%s per GB - first 10 TB / month data transfer out beyond the global free tier", 0.09, 120},
+	{"AmazonEC2", "Amazon Elastic Compute Cloud", "EC2 - Other", "NatGateway-Hours", "NatGateway", "Hrs",
+		"$%s per NAT Gateway Hour", 0.045, 48},
+	{"AmazonS3", "Amazon Simple Storage Service", "Amazon Simple Storage Service", "TimedStorage-ByteHrs", "StandardStorage", "GB-Mo",
+		"$%s per GB - first 50 TB / month of storage used", 0.023, 50000.0 / 30},
+	{"AmazonS3", "Amazon Simple Storage Service", "Amazon Simple Storage Service", "Requests-Tier1", "PutObject", "Requests",
+		"$%s per PUT, COPY, POST, or LIST requests", 0.000005, 400000},
+	{"AmazonRDS", "Amazon Relational Database Service", "Amazon Relational Database Service", "InstanceUsage:db.r5.large", "CreateDBInstance:0002", "Hrs",
+		"$%s per RDS db.r5.large Single-AZ instance hour running MySQL", 0.24, 48},
+	{"AWSLambda", "AWS Lambda", "AWS Lambda", "Lambda-GB-Second", "Invoke", "Lambda-GB-Second",
+		"AWS Lambda - Total Compute - $%s per GB-second", 0.0000166667, 900000},
+	{"AmazonCloudWatch", "AmazonCloudWatch", "AmazonCloudWatch", "DataProcessing-Bytes", "PutLogEvents", "GB",
+		"$%s per GB custom log data ingested", 0.5, 40},
+	{"AWSCloudTrail", "AWS CloudTrail", "AWS CloudTrail", "PaidEventsRecorded", "None", "Events",
+		"$%s per 100,000 events recorded", 0.000002, 3000000},
+	{"AmazonGuardDuty", "Amazon GuardDuty", "Amazon GuardDuty", "PaidCloudTrailEventsAnalyzed", "None", "Events",
+		"$%s per one million CloudTrail management events analyzed", 0.000004, 3000000},
+}
+
+// awsCostScale is each account's share of the production account's spend
+var awsCostScale = map[string]float64{"production": 1, "staging": 0.35, "shared-services": 0.2}
+
+// awsCostAnomaly is a spend spike and its cause. A spike in a region the
+// account does not use has no expected spend.
+type awsCostAnomaly struct {
+	awsCostService
+	region string  // Empty for the account's own region
+	factor float64 // Usage as a multiple of the service's daily usage
+}
+
+// awsCostAnomalies are spikes a security team would want to see: mining on
+// GPU instances in an unused region, bulk data transfer out, a flood of
+// Lambda invocations, and runaway log ingestion
+var awsCostAnomalies = []awsCostAnomaly{
+	{awsCostService{"AmazonEC2", "Amazon Elastic Compute Cloud", "Amazon Elastic Compute Cloud - Compute", "BoxUsage:p3.16xlarge", "RunInstances", "Hrs",
+		"$%s per On Demand Linux p3.16xlarge Instance Hour", 33.872, 24 * 8}, "ap-southeast-1", 1},
+	{awsCostService{"AmazonEC2", "Amazon Elastic Compute Cloud", "Amazon Elastic Compute Cloud - Compute", "BoxUsage:g4dn.12xlarge", "RunInstances", "Hrs",
+		"$%s per On Demand Linux g4dn.12xlarge Instance Hour", 4.931, 24 * 12}, "sa-east-1", 1},
+	{awsCostServices[2], "", 60},
+	{awsCostService{"AmazonS3", "Amazon Simple Storage Service", "Amazon Simple Storage Service", "DataTransfer-Out-Bytes", "GetObject", "GB",
+		"$%s per GB - first 10 TB / month data transfer out beyond the global free tier", 0.09, 120}, "", 80},
+	{awsCostServices[7], "", 40},
+	{awsCostServices[8], "", 25},
+}
+
+// awsUsagePrefixes are the region prefixes of usage types. us-east-1 has
+// none.
+var awsUsagePrefixes = map[string]string{
+	"us-east-1": "", "us-west-2": "USW2-", "eu-west-1": "EU-", "ap-southeast-1": "APS1-", "sa-east-1": "SAE1-",
+}
+
+// GetEventType returns the event type for AWS cost data
+func (g *AWSCostGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "aws_cost",
+		Name:        "AWS Cost and Usage",
+		Category:    "cloud",
+		Description: "AWS Cost and Usage Report daily line items and Cost Anomaly Detection findings",
+		EventIDs:    []string{"CUR", "Anomaly Detected"},
+	}
+}
+
+// GetTemplates returns available templates for AWS cost data
+func (g *AWSCostGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "daily_spend",
+			Name:        "Daily Spend Line Item",
+			Category:    "aws_cost",
+			EventID:     "CUR",
+			Format:      "json",
+			Description: "CUR 2.0 line item for a day's usage of a service in one account",
+			Sourcetype:  "aws:billing:cur",
+		},
+		{
+			ID:          "anomalous_spend",
+			Name:        "Anomalous Spend Line Item",
+			Category:    "aws_cost",
+			EventID:     "CUR",
+			Format:      "json",
+			Description: "CUR 2.0 line item for a spend spike: GPU instances in an unused region, data transfer out, Lambda or log ingestion",
+			Sourcetype:  "aws:billing:cur",
+		},
+		{
+			ID:          "cost_anomaly",
+			Name:        "Cost Anomaly Detected",
+			Category:    "aws_cost",
+			EventID:     "Anomaly Detected",
+			Format:      "json",
+			Description: "Cost Anomaly Detection finding delivered through EventBridge, with impact and root causes",
+			Sourcetype:  "aws:ce:anomaly",
+		},
+	}
+}
+
+// Generate creates an AWS cost event
+func (g *AWSCostGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "daily_spend":
+		return g.generateDailySpend(overrides)
+	case "anomalous_spend":
+		return g.generateAnomalousSpend(overrides)
+	case "cost_anomaly":
+		return g.generateCostAnomaly(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// usageDay is the start of yesterday in UTC: cost data arrives a day late
+func usageDay() time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+}
+
+// roundCost rounds an amount to the precision cost exports use
+func roundCost(v float64) float64 {
+	return math.Round(v*1e8) / 1e8
+}
+
+// generateDailySpend creates a line item for a normal day's usage, within
+// a tenth of the service's usual amount
+func (g *AWSCostGenerator) generateDailySpend(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	account := AWS.RandomAccount(g.rnd)
+	service := awsCostServices[g.RandomInt(0, len(awsCostServices)-1)]
+	usage := service.daily * awsCostScale[account.Name] * g.RandomFloat(0.9, 1.1)
+	return g.event("CUR", usageDay(), g.lineItem(account, account.Region, service, usage), overrides)
+}
+
+// generateAnomalousSpend creates a line item for a spend spike
+func (g *AWSCostGenerator) generateAnomalousSpend(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	account := AWS.RandomAccount(g.rnd)
+	anomaly := awsCostAnomalies[g.RandomInt(0, len(awsCostAnomalies)-1)]
+	region := anomaly.region
+	if region == "" {
+		region = account.Region
+	}
+	usage := anomaly.daily * anomaly.factor * awsCostScale[account.Name] * g.RandomFloat(0.8, 1.3)
+	if anomaly.region != "" {
+		usage = anomaly.daily * g.RandomFloat(0.8, 1.3)
+	}
+	return g.event("CUR", usageDay(), g.lineItem(account, region, anomaly.awsCostService, usage), overrides)
+}
+
+// lineItem builds a CUR 2.0 line item for a day's usage of service in
+// account and region
+func (g *AWSCostGenerator) lineItem(account *AWSAccount, region string, service awsCostService, usage float64) map[string]interface{} {
+	payer := AWS.Accounts()[len(AWS.Accounts())-1]
+	day := usageDay()
+	periodStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	usageType := awsUsagePrefixes[region] + service.usageType
+	usage = math.Round(usage*1e4) / 1e4
+	cost := roundCost(usage * service.rate)
+	rate := fmt.Sprint(service.rate)
+
+	return map[string]interface{}{
+		"bill_bill_type":                  "Anniversary",
+		"bill_billing_entity":             "AWS",
+		"bill_billing_period_start_date":  periodStart.Format(time.RFC3339),
+		"bill_billing_period_end_date":    periodStart.AddDate(0, 1, 0).Format(time.RFC3339),
+		"bill_payer_account_id":           payer.ID,
+		"bill_payer_account_name":         payer.Name,
+		"identity_line_item_id":           g.RandomHex(26),
+		"identity_time_interval":          day.Format(time.RFC3339) + "/" + day.AddDate(0, 0, 1).Format(time.RFC3339),
+		"line_item_currency_code":         "USD",
+		"line_item_line_item_description": fmt.Sprintf(service.description, rate),
+		"line_item_line_item_type":        "Usage",
+		"line_item_operation":             service.operation,
+		"line_item_product_code":          service.product,
+		"line_item_resource_id":           g.resourceID(account, region, service),
+		"line_item_usage_account_id":      account.ID,
+		"line_item_usage_account_name":    account.Name,
+		"line_item_usage_amount":          usage,
+		"line_item_usage_start_date":      day.Format(time.RFC3339),
+		"line_item_usage_end_date":        day.AddDate(0, 0, 1).Format(time.RFC3339),
+		"line_item_usage_type":            usageType,
+		"line_item_unblended_rate":        rate,
+		"line_item_unblended_cost":        cost,
+		"line_item_blended_rate":          rate,
+		"line_item_blended_cost":          cost,
+		"line_item_net_unblended_cost":    cost,
+		"pricing_public_on_demand_rate":   rate,
+		"pricing_public_on_demand_cost":   cost,
+		"pricing_term":                    "OnDemand",
+		"pricing_unit":                    service.unit,
+		"product_product_name":            service.name,
+		"product_region_code":             region,
+		"product_servicecode":             service.product,
+		"resource_tags": map[string]interface{}{
+			"user_environment": account.Name,
+			"user_owner":       Entities.RandomUser(g.rnd).Email,
+		},
+	}
+}
+
+// resourceID is the resource a line item is charged for, or empty for
+// charges no single resource incurs
+func (g *AWSCostGenerator) resourceID(account *AWSAccount, region string, service awsCostService) string {
+	switch {
+	case service.product == "AmazonEC2" && strings.HasPrefix(service.usageType, "BoxUsage"):
+		if region != account.Region {
+			return "i-" + g.RandomHex(9)[:17]
+		}
+		return account.RandomInstance(g.rnd).ID
+	case strings.HasPrefix(service.usageType, "EBS:"):
+		return "vol-" + g.RandomHex(9)[:17]
+	case service.usageType == "NatGateway-Hours":
+		return fmt.Sprintf("arn:aws:ec2:%s:%s:natgateway/nat-%s", region, account.ID, g.RandomHex(9)[:17])
+	case service.product == "AmazonS3":
+		return fmt.Sprintf("%s-%s-%s", account.Name, g.RandomChoice([]string{"logs", "artifacts", "backups", "data-exports"}), account.ID)
+	case service.product == "AmazonRDS":
+		return fmt.Sprintf("arn:aws:rds:%s:%s:db:%s-%s", region, account.ID, account.Name, g.RandomChoice([]string{"orders", "users", "billing"}))
+	case service.product == "AWSLambda":
+		return fmt.Sprintf("arn:aws:lambda:%s:%s:function:%s-%s", region, account.ID, account.Name, g.RandomChoice([]string{"image-resize", "etl-loader", "webhook-handler", "report-builder"}))
+	case service.product == "AmazonCloudWatch":
+		return fmt.Sprintf("arn:aws:logs:%s:%s:log-group:/aws/%s/%s", region, account.ID, g.RandomChoice([]string{"lambda", "ecs", "eks"}), account.Name)
+	}
+	return ""
+}
+
+// generateCostAnomaly creates a Cost Anomaly Detection finding for one of
+// the spikes, as EventBridge delivers it
+func (g *AWSCostGenerator) generateCostAnomaly(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	payer := AWS.Accounts()[len(AWS.Accounts())-1]
+	account := AWS.RandomAccount(g.rnd)
+	anomaly := awsCostAnomalies[g.RandomInt(0, len(awsCostAnomalies)-1)]
+	region := anomaly.region
+	if region == "" {
+		region = account.Region
+	}
+
+	days := g.RandomInt(1, 3)
+	start := usageDay().AddDate(0, 0, 1-days)
+	expected := 0.0
+	actual := anomaly.daily * anomaly.rate * float64(days) * g.RandomFloat(0.8, 1.3)
+	if anomaly.region == "" {
+		expected = anomaly.daily * anomaly.rate * awsCostScale[account.Name] * float64(days)
+		actual *= anomaly.factor * awsCostScale[account.Name]
+	}
+	impact := actual - expected
+	percentage := 0.0
+	if expected > 0 {
+		percentage = math.Round(impact/expected*1e4) / 100
+	}
+	score := math.Round(g.RandomFloat(0.6, 0.99)*100) / 100
+
+	anomalyID := uuid.New().String()
+	monitorARN := fmt.Sprintf("arn:aws:ce::%s:anomalymonitor/%s", payer.ID, g.monitorID)
+	detail := map[string]interface{}{
+		"accountId":          payer.ID,
+		"anomalyId":          anomalyID,
+		"anomalyDetailsLink": fmt.Sprintf("https://console.aws.amazon.com/cost-management/home#/anomaly-detection/monitors/%s/anomalies/%s", g.monitorID, anomalyID),
+		"anomalyStartDate":   start.Format(time.RFC3339),
+		"anomalyEndDate":     usageDay().Format(time.RFC3339),
+		"anomalyScore":       map[string]interface{}{"currentScore": score, "maxScore": score},
+		"dimensionalValue":   anomaly.anomalyName,
+		"impact": map[string]interface{}{
+			"maxImpact":             math.Round(impact / float64(days)),
+			"totalActualSpend":      math.Round(actual),
+			"totalExpectedSpend":    math.Round(expected),
+			"totalImpact":           math.Round(impact),
+			"totalImpactPercentage": percentage,
+		},
+		"monitorArn":  monitorARN,
+		"monitorName": "Services monitor",
+		"rootCauses": []map[string]interface{}{{
+			"linkedAccount":     account.ID,
+			"linkedAccountName": account.Name,
+			"region":            region,
+			"service":           anomaly.anomalyName,
+			"usageType":         awsUsagePrefixes[region] + anomaly.usageType,
+		}},
+		"subscriptionId":   g.RandomHex(16),
+		"subscriptionName": "finops-security-alerts",
+	}
+	envelope := map[string]interface{}{
+		"version":     "0",
+		"id":          uuid.New().String(),
+		"detail-type": "Anomaly Detected",
+		"source":      "aws.ce",
+		"account":     payer.ID,
+		"time":        timestamp.UTC().Format(time.RFC3339),
+		"region":      "us-east-1",
+		"resources":   []string{monitorARN},
+		"detail":      detail,
+	}
+	return g.event("Anomaly Detected", timestamp, envelope, overrides)
+}
+
+func (g *AWSCostGenerator) event(eventID string, timestamp time.Time, record, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields := g.ApplyOverrides(record, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcetype := "aws:billing:cur"
+	if eventID == "Anomaly Detected" {
+		sourcetype = "aws:ce:anomaly"
+	}
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "aws_cost",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: sourcetype,
+	}, nil
+}
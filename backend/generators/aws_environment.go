@@ -0,0 +1,134 @@
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// awsSeed fixes the AWS environment so the same accounts, VPCs and
+// instances exist on every run
+const awsSeed = 20240715
+
+// AWSInstance is an EC2 instance and its primary network interface
+type AWSInstance struct {
+	ID           string `json:"id"`
+	ENI          string `json:"eni"`
+	PrivateIP    string `json:"private_ip"`
+	SubnetID     string `json:"subnet_id"`
+	AZ           string `json:"az"`
+	AZID         string `json:"az_id"`
+	Name         string `json:"name"`
+	InstanceType string `json:"instance_type"`
+}
+
+// AWSAccount is an account of the simulated AWS organization with the VPC
+// its workloads run in
+type AWSAccount struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	Region       string         `json:"region"`
+	VPCID        string         `json:"vpc_id"`
+	VPCCIDR      string         `json:"vpc_cidr"`
+	S3EndpointID string         `json:"s3_endpoint_id"` // VPC endpoint for S3
+	ResolverIP   string         `json:"resolver_ip"`    // Route 53 Resolver at the VPC base +2
+	Instances    []*AWSInstance `json:"instances"`
+	privateZone  string
+}
+
+// AWSEnvironment is the fixed set of AWS accounts that CloudTrail, VPC Flow
+// Logs, Route 53 Resolver logs and Security Hub findings share, so account,
+// VPC and instance IDs line up across the sources
+type AWSEnvironment struct {
+	accounts []*AWSAccount
+}
+
+// AWS is the global AWS environment
+var AWS = newAWSEnvironment(awsSeed)
+
+func newAWSEnvironment(seed int64) *AWSEnvironment {
+	r := rand.New(rand.NewSource(seed))
+	hex := func(n int) string {
+		const digits = "0123456789abcdef"
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = digits[r.Intn(len(digits))]
+		}
+		return string(b)
+	}
+
+	layouts := []struct {
+		name, region string
+		octet        int
+		roles        []string
+	}{
+		{"production", "us-east-1", 10, []string{"web", "app", "api", "db", "worker"}},
+		{"staging", "us-west-2", 20, []string{"web", "app", "db", "ci-runner"}},
+		{"shared-services", "eu-west-1", 30, []string{"bastion", "vpn", "monitoring", "ad-connector"}},
+	}
+
+	e := &AWSEnvironment{}
+	for _, l := range layouts {
+		a := &AWSAccount{
+			ID:           fmt.Sprintf("%012d", 100000000000+r.Int63n(900000000000)),
+			Name:         l.name,
+			Region:       l.region,
+			VPCID:        "vpc-" + hex(17),
+			VPCCIDR:      fmt.Sprintf("10.%d.0.0/16", l.octet),
+			S3EndpointID: "vpce-" + hex(17),
+			ResolverIP:   fmt.Sprintf("10.%d.0.2", l.octet),
+			privateZone:  l.name + ".internal",
+		}
+		for az := 0; az < 3; az++ {
+			subnet := "subnet-" + hex(17)
+			for i := 0; i < 6; i++ {
+				role := l.roles[r.Intn(len(l.roles))]
+				a.Instances = append(a.Instances, &AWSInstance{
+					ID:           "i-" + hex(17),
+					ENI:          "eni-" + hex(17),
+					PrivateIP:    fmt.Sprintf("10.%d.%d.%d", l.octet, az*16+r.Intn(16), 4+r.Intn(250)),
+					SubnetID:     subnet,
+					AZ:           l.region + string(rune('a'+az)),
+					AZID:         fmt.Sprintf("%s-az%d", awsRegionCode(l.region), az+1),
+					Name:         fmt.Sprintf("%s-%s-%02d", l.name, role, len(a.Instances)+1),
+					InstanceType: []string{"t3.medium", "m5.large", "m5.xlarge", "c5.large", "r5.large"}[r.Intn(5)],
+				})
+			}
+		}
+		e.accounts = append(e.accounts, a)
+	}
+	return e
+}
+
+// awsRegionCode returns the short code AZ IDs use for a region
+func awsRegionCode(region string) string {
+	return map[string]string{"us-east-1": "use1", "us-west-2": "usw2", "eu-west-1": "euw1"}[region]
+}
+
+// Accounts returns every account: production, staging and shared-services,
+// which is the organization's management account
+func (e *AWSEnvironment) Accounts() []*AWSAccount {
+	return e.accounts
+}
+
+// RandomAccount picks an account, production most often
+func (e *AWSEnvironment) RandomAccount(rnd *RandomStream) *AWSAccount {
+	roll := rnd.float64()
+	switch {
+	case roll < 0.6:
+		return e.accounts[0]
+	case roll < 0.85:
+		return e.accounts[1]
+	}
+	return e.accounts[2]
+}
+
+// RandomInstance picks one of the account's instances
+func (a *AWSAccount) RandomInstance(rnd *RandomStream) *AWSInstance {
+	return a.Instances[rnd.intn(len(a.Instances))]
+}
+
+// PrivateHostname returns an instance's private DNS name in the account's
+// private hosted zone
+func (a *AWSAccount) PrivateHostname(inst *AWSInstance) string {
+	return inst.Name + "." + a.privateZone
+}
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -26,13 +25,16 @@ func (g *AWSGuardDutyGenerator) GetEventType() models.EventType {
 		Name:        "AWS GuardDuty",
 		Category:    "cloud",
 		Description: "AWS GuardDuty threat detection findings - malicious IPs, compromised instances, anomalous behavior",
-		EventIDs:    []string{"UnauthorizedAccess:EC2/SSHBruteForce", "Recon:EC2/PortProbeUnprotectedPort", "CryptoCurrency:EC2/BitcoinTool", "UnauthorizedAccess:IAMUser/ConsoleLoginSuccess.B", "Trojan:EC2/BlackholeTraffic", "Backdoor:EC2/C2Activity"},
+		EventIDs:    []string{"UnauthorizedAccess:EC2/SSHBruteForce", "Recon:EC2/PortProbeUnprotectedPort", "CryptoCurrency:EC2/BitcoinTool", "UnauthorizedAccess:IAMUser/ConsoleLoginSuccess.B", "Trojan:EC2/BlackholeTraffic", "Backdoor:EC2/C2Activity",
+			"Exfiltration:S3/ObjectRead.Unusual", "Discovery:IAMUser/AnomalousBehavior", "CredentialAccess:IAMUser/AnomalousBehavior",
+			"Discovery:Kubernetes/SuccessfulAnonymousAccess", "PrivilegeEscalation:Kubernetes/PrivilegedContainer", "Execution:Kubernetes/ExecInKubeSystemPod",
+			"Execution:Runtime/ReverseShell", "Execution:Runtime/NewBinaryExecuted", "CryptoCurrency:Runtime/BitcoinTool.B"},
 	}
 }
 
 // GetTemplates returns available templates for AWS GuardDuty findings
 func (g *AWSGuardDutyGenerator) GetTemplates() []models.EventTemplate {
-	return []models.EventTemplate{
+	return append([]models.EventTemplate{
 		{
 			ID:          "SSHBruteForce",
 			Name:        "SSH Brute Force",
@@ -81,7 +83,7 @@ func (g *AWSGuardDutyGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "json",
 			Description: "EC2 instance is communicating with command and control server",
 		},
-	}
+	}, guardDutyProtectionTemplates...)
 }
 
 // Generate creates an AWS GuardDuty finding
@@ -100,6 +102,9 @@ func (g *AWSGuardDutyGenerator) Generate(templateID string, overrides map[string
 	case "C2Activity":
 		return g.generateC2Activity(overrides)
 	default:
+		if event, ok := g.generateProtectionFinding(templateID, overrides); ok {
+			return event, nil
+		}
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
 }
@@ -129,18 +134,19 @@ func (g *AWSGuardDutyGenerator) randomSeverity() (float64, string) {
 
 func (g *AWSGuardDutyGenerator) buildBaseFinding(findingType, title, description, accountID, region string) map[string]interface{} {
 	severity, severityLabel := g.randomSeverity()
+	detectorID := g.RandomHex(16)
 	return map[string]interface{}{
 		"schemaVersion": "2.0",
 		"accountId":     accountID,
 		"region":        region,
 		"partition":     "aws",
 		"id":            uuid.New().String(),
-		"arn":           fmt.Sprintf("arn:aws:guardduty:%s:%s:detector/%s/finding/%s", region, accountID, g.RandomString(32), uuid.New().String()),
+		"arn":           fmt.Sprintf("arn:aws:guardduty:%s:%s:detector/%s/finding/%s", region, accountID, detectorID, uuid.New().String()),
 		"type":          findingType,
 		"resource":      map[string]interface{}{},
 		"service": map[string]interface{}{
 			"serviceName":  "guardduty",
-			"detectorId":   g.RandomString(32),
+			"detectorId":   detectorID,
 			"action":       map[string]interface{}{},
 			"resourceRole": "TARGET",
 			"additionalInfo": map[string]interface{}{
@@ -161,15 +167,33 @@ func (g *AWSGuardDutyGenerator) buildBaseFinding(findingType, title, description
 	}
 }
 
+// remoteIPDetails renders a GeoLocation as a GuardDuty remoteIpDetails block
+func (g *AWSGuardDutyGenerator) remoteIPDetails(loc GeoLocation) map[string]interface{} {
+	asn := fmt.Sprint(loc.ASN)
+	return map[string]interface{}{
+		"ipAddressV4": loc.IP,
+		"organization": map[string]interface{}{
+			"asn":    asn,
+			"asnOrg": loc.ASOrg,
+			"isp":    loc.ASOrg,
+			"org":    loc.ASOrg,
+		},
+		"country":     map[string]interface{}{"countryCode": loc.CountryCode, "countryName": loc.CountryName},
+		"city":        map[string]interface{}{"cityName": loc.City},
+		"geoLocation": map[string]interface{}{"lat": loc.Latitude, "lon": loc.Longitude},
+	}
+}
+
 func (g *AWSGuardDutyGenerator) generateSSHBruteForce(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
 	accountID := g.randomAccountID()
 	region := g.randomRegion()
 	instanceID := fmt.Sprintf("i-%s", g.RandomString(17))
+	attacker := g.RandomMaliciousGeoIP()
 
 	finding := g.buildBaseFinding(
 		"UnauthorizedAccess:EC2/SSHBruteForce",
-		fmt.Sprintf("%s is performing SSH brute force attacks against %s", g.RandomIPv4External(), instanceID),
+		fmt.Sprintf("%s is performing SSH brute force attacks against %s", attacker.IP, instanceID),
 		"EC2 instance is being targeted by SSH brute force attack",
 		accountID, region,
 	)
@@ -194,10 +218,7 @@ func (g *AWSGuardDutyGenerator) generateSSHBruteForce(overrides map[string]inter
 		"actionType": "NETWORK_CONNECTION",
 		"networkConnectionAction": map[string]interface{}{
 			"connectionDirection": "INBOUND",
-			"remoteIpDetails": map[string]interface{}{
-				"ipAddressV4": g.RandomIPv4External(),
-				"country":     map[string]interface{}{"countryName": g.RandomChoice([]string{"Russia", "China", "North Korea", "Iran"})},
-			},
+			"remoteIpDetails":     g.remoteIPDetails(attacker),
 			"localPortDetails": map[string]interface{}{
 				"port":     22,
 				"portName": "SSH",
@@ -208,7 +229,7 @@ func (g *AWSGuardDutyGenerator) generateSSHBruteForce(overrides map[string]inter
 	}
 
 	fields := g.ApplyOverrides(finding, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -252,10 +273,7 @@ func (g *AWSGuardDutyGenerator) generatePortProbe(overrides map[string]interface
 						"port":     port,
 						"portName": g.RandomChoice([]string{"RDP", "SSH", "MySQL", "PostgreSQL", "MongoDB"}),
 					},
-					"remoteIpDetails": map[string]interface{}{
-						"ipAddressV4": g.RandomIPv4External(),
-						"country":     map[string]interface{}{"countryName": g.RandomChoice([]string{"Russia", "China", "Unknown"})},
-					},
+					"remoteIpDetails": g.remoteIPDetails(g.RandomMaliciousGeoIP()),
 				},
 			},
 			"blocked": false,
@@ -263,7 +281,7 @@ func (g *AWSGuardDutyGenerator) generatePortProbe(overrides map[string]interface
 	}
 
 	fields := g.ApplyOverrides(finding, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -308,7 +326,7 @@ func (g *AWSGuardDutyGenerator) generateCryptoMining(overrides map[string]interf
 	}
 
 	fields := g.ApplyOverrides(finding, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -344,23 +362,18 @@ func (g *AWSGuardDutyGenerator) generateConsoleLoginAnomaly(overrides map[string
 		},
 	}
 
-	countries := []string{"Russia", "China", "North Korea", "Iran", "Nigeria", "Romania"}
 	finding["service"].(map[string]interface{})["action"] = map[string]interface{}{
 		"actionType": "AWS_API_CALL",
 		"awsApiCallAction": map[string]interface{}{
-			"api":         "ConsoleLogin",
-			"serviceName": "signin.amazonaws.com",
-			"callerType":  "Remote IP",
-			"remoteIpDetails": map[string]interface{}{
-				"ipAddressV4": g.RandomIPv4External(),
-				"country":     map[string]interface{}{"countryName": g.RandomChoice(countries)},
-				"city":        map[string]interface{}{"cityName": "Unknown"},
-			},
+			"api":             "ConsoleLogin",
+			"serviceName":     "signin.amazonaws.com",
+			"callerType":      "Remote IP",
+			"remoteIpDetails": g.remoteIPDetails(g.RandomMaliciousGeoIP()),
 		},
 	}
 
 	fields := g.ApplyOverrides(finding, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -398,19 +411,16 @@ func (g *AWSGuardDutyGenerator) generateBlackholeTraffic(overrides map[string]in
 		"actionType": "NETWORK_CONNECTION",
 		"networkConnectionAction": map[string]interface{}{
 			"connectionDirection": "OUTBOUND",
-			"remoteIpDetails": map[string]interface{}{
-				"ipAddressV4":  g.RandomIPv4External(),
-				"organization": map[string]interface{}{"asn": "AS12345", "asnOrg": "MaliciousHosting"},
-			},
-			"localPortDetails":  map[string]interface{}{"port": g.RandomPort()},
-			"remotePortDetails": map[string]interface{}{"port": 443},
-			"protocol":          "TCP",
-			"blocked":           false,
+			"remoteIpDetails":     g.remoteIPDetails(g.RandomMaliciousGeoIP()),
+			"localPortDetails":    map[string]interface{}{"port": g.RandomPort()},
+			"remotePortDetails":   map[string]interface{}{"port": 443},
+			"protocol":            "TCP",
+			"blocked":             false,
 		},
 	}
 
 	fields := g.ApplyOverrides(finding, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -455,7 +465,7 @@ func (g *AWSGuardDutyGenerator) generateC2Activity(overrides map[string]interfac
 	}
 
 	fields := g.ApplyOverrides(finding, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -0,0 +1,519 @@
+package generators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// guardDutyProtectionTemplates are the findings of GuardDuty's S3, IAM
+// anomaly, EKS audit log and Runtime Monitoring coverage
+var guardDutyProtectionTemplates = []models.EventTemplate{
+	{
+		ID:          "S3ObjectReadUnusual",
+		Name:        "S3 Unusual Object Reads",
+		Category:    "aws_guardduty",
+		EventID:     "Exfiltration:S3/ObjectRead.Unusual",
+		Format:      "json",
+		Description: "IAM entity read S3 objects in an anomalous way",
+	},
+	{
+		ID:          "IAMDiscoveryAnomaly",
+		Name:        "IAM Anomalous Discovery",
+		Category:    "aws_guardduty",
+		EventID:     "Discovery:IAMUser/AnomalousBehavior",
+		Format:      "json",
+		Description: "IAM user made unusual resource enumeration calls",
+	},
+	{
+		ID:          "IAMCredentialAccessAnomaly",
+		Name:        "IAM Anomalous Credential Access",
+		Category:    "aws_guardduty",
+		EventID:     "CredentialAccess:IAMUser/AnomalousBehavior",
+		Format:      "json",
+		Description: "IAM user made unusual calls that retrieve secrets or credentials",
+	},
+	{
+		ID:          "K8sAnonymousAccess",
+		Name:        "Kubernetes Anonymous Access",
+		Category:    "aws_guardduty",
+		EventID:     "Discovery:Kubernetes/SuccessfulAnonymousAccess",
+		Format:      "json",
+		Description: "Unauthenticated user enumerated an EKS cluster's resources",
+	},
+	{
+		ID:          "K8sPrivilegedContainer",
+		Name:        "Kubernetes Privileged Container",
+		Category:    "aws_guardduty",
+		EventID:     "PrivilegeEscalation:Kubernetes/PrivilegedContainer",
+		Format:      "json",
+		Description: "Privileged container launched in an EKS cluster",
+	},
+	{
+		ID:          "K8sExecInKubeSystemPod",
+		Name:        "Kubernetes Exec in kube-system",
+		Category:    "aws_guardduty",
+		EventID:     "Execution:Kubernetes/ExecInKubeSystemPod",
+		Format:      "json",
+		Description: "Command executed inside a kube-system pod",
+	},
+	{
+		ID:          "RuntimeReverseShell",
+		Name:        "Runtime Reverse Shell",
+		Category:    "aws_guardduty",
+		EventID:     "Execution:Runtime/ReverseShell",
+		Format:      "json",
+		Description: "Process on an EC2 instance opened a reverse shell",
+	},
+	{
+		ID:          "RuntimeNewBinaryExecuted",
+		Name:        "Runtime New Binary Executed",
+		Category:    "aws_guardduty",
+		EventID:     "Execution:Runtime/NewBinaryExecuted",
+		Format:      "json",
+		Description: "Newly created or modified binary executed in a container",
+	},
+	{
+		ID:          "RuntimeCryptoMining",
+		Name:        "Runtime Crypto Mining",
+		Category:    "aws_guardduty",
+		EventID:     "CryptoCurrency:Runtime/BitcoinTool.B",
+		Format:      "json",
+		Description: "Process on an EC2 instance connected to a cryptocurrency mining pool",
+	},
+}
+
+// generateProtectionFinding creates one of the S3, IAM, EKS or runtime
+// findings, or returns false for another template ID
+func (g *AWSGuardDutyGenerator) generateProtectionFinding(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, bool) {
+	accountID := g.randomAccountID()
+	region := g.randomRegion()
+
+	var finding map[string]interface{}
+	switch templateID {
+	case "S3ObjectReadUnusual":
+		finding = g.s3ObjectReadUnusual(accountID, region)
+	case "IAMDiscoveryAnomaly":
+		finding = g.iamAnomaly("Discovery", []string{"ListUsers", "ListRoles", "GetAccountAuthorizationDetails", "ListAttachedUserPolicies"}, "iam.amazonaws.com", accountID, region)
+	case "IAMCredentialAccessAnomaly":
+		finding = g.iamAnomaly("CredentialAccess", []string{"GetSecretValue", "GetPasswordData", "GetParametersByPath"}, g.RandomChoice([]string{"secretsmanager.amazonaws.com", "ec2.amazonaws.com", "ssm.amazonaws.com"}), accountID, region)
+	case "K8sAnonymousAccess":
+		finding = g.kubernetesFinding("Discovery:Kubernetes/SuccessfulAnonymousAccess", accountID, region)
+	case "K8sPrivilegedContainer":
+		finding = g.kubernetesFinding("PrivilegeEscalation:Kubernetes/PrivilegedContainer", accountID, region)
+	case "K8sExecInKubeSystemPod":
+		finding = g.kubernetesFinding("Execution:Kubernetes/ExecInKubeSystemPod", accountID, region)
+	case "RuntimeReverseShell":
+		finding = g.runtimeFinding("Execution:Runtime/ReverseShell", accountID, region)
+	case "RuntimeNewBinaryExecuted":
+		finding = g.runtimeFinding("Execution:Runtime/NewBinaryExecuted", accountID, region)
+	case "RuntimeCryptoMining":
+		finding = g.runtimeFinding("CryptoCurrency:Runtime/BitcoinTool.B", accountID, region)
+	default:
+		return nil, false
+	}
+
+	fields := g.ApplyOverrides(finding, overrides)
+	rawEvent, _ := g.marshalRawJSON(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "aws_guardduty",
+		EventID:    fmt.Sprintf("%v", finding["type"]),
+		Timestamp:  time.Now(),
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "aws:guardduty",
+	}, true
+}
+
+// setSeverity replaces a finding's random severity with the one GuardDuty
+// gives its type
+func setSeverity(finding map[string]interface{}, severity float64) {
+	label := "LOW"
+	switch {
+	case severity >= 7:
+		label = "HIGH"
+	case severity >= 4:
+		label = "MEDIUM"
+	}
+	finding["severity"] = severity
+	finding["severityLabel"] = label
+}
+
+// protectionService sets the GuardDuty feature a finding came from and
+// replaces the threat list info that only network findings carry
+func protectionService(finding map[string]interface{}, featureName string, additionalInfo map[string]interface{}) map[string]interface{} {
+	service := finding["service"].(map[string]interface{})
+	service["featureName"] = featureName
+	service["additionalInfo"] = additionalInfo
+	return service
+}
+
+// iamUserAccessKey is the accessKeyDetails of an IAM user's key
+func (g *AWSGuardDutyGenerator) iamUserAccessKey(userName string) map[string]interface{} {
+	return map[string]interface{}{
+		"accessKeyId": "AKIA" + g.RandomString(16),
+		"principalId": "AIDA" + g.RandomString(17),
+		"userType":    "IAMUser",
+		"userName":    userName,
+	}
+}
+
+// anomalyInfo is the additionalInfo of an anomaly detection finding: the
+// APIs that were unusual and the behavior they were unusual against
+func anomalyInfo(serviceName string, apis []string, count int, userName string, loc GeoLocation) map[string]interface{} {
+	calls := make([]map[string]interface{}, 0, len(apis))
+	for _, api := range apis {
+		calls = append(calls, map[string]interface{}{api: map[string]interface{}{"count": count}})
+	}
+	return map[string]interface{}{
+		"type": "default",
+		"anomalies": map[string]interface{}{
+			"anomalousAPIs": map[string]interface{}{serviceName: calls},
+		},
+		"unusual": map[string]interface{}{
+			"behavior": map[string]interface{}{
+				"User": map[string]interface{}{
+					userName: map[string]interface{}{
+						"ASNOrg":   loc.ASOrg,
+						"Country":  loc.CountryName,
+						"userType": "IAMUser",
+					},
+				},
+			},
+		},
+	}
+}
+
+// s3ObjectReadUnusual is an IAM user reading objects from a bucket far
+// more often, or from a stranger place, than it usually does
+func (g *AWSGuardDutyGenerator) s3ObjectReadUnusual(accountID, region string) map[string]interface{} {
+	bucket := themedBucket(g.RandomChoice([]string{"data", "backup", "exports", "finance"}), g.RandomString(8))
+	userName := g.RandomChoice([]string{"developer", "devops", "data-analyst", "backup-service"}) + "-" + g.RandomString(4)
+	caller := g.RandomMaliciousGeoIP()
+	count := g.RandomInt(500, 20000)
+
+	finding := g.buildBaseFinding(
+		"Exfiltration:S3/ObjectRead.Unusual",
+		fmt.Sprintf("Unusual S3 object reads from %s by %s", bucket, userName),
+		fmt.Sprintf("IAM user %s read objects from bucket %s in a way that differs from its established baseline", userName, bucket),
+		accountID, region,
+	)
+	setSeverity(finding, 5)
+
+	finding["resource"] = map[string]interface{}{
+		"resourceType":     "S3Bucket",
+		"accessKeyDetails": g.iamUserAccessKey(userName),
+		"s3BucketDetails": []map[string]interface{}{
+			{
+				"arn":       "arn:aws:s3:::" + bucket,
+				"name":      bucket,
+				"type":      "Destination",
+				"createdAt": time.Now().Add(-time.Duration(g.RandomInt(30, 900)*24) * time.Hour).UTC().Format(time.RFC3339),
+				"owner":     map[string]interface{}{"id": g.RandomHex(32)},
+				"tags":      []map[string]interface{}{{"key": "DataClassification", "value": g.RandomChoice([]string{"Confidential", "Internal", "Restricted"})}},
+				"defaultServerSideEncryption": map[string]interface{}{
+					"encryptionType":                "SSEAlgorithm",
+					"kmsMasterKeyArn":               nil,
+					"serverSideEncryptionAlgorithm": "AES256",
+				},
+				"publicAccess": map[string]interface{}{
+					"effectivePermission": "NOT_PUBLIC",
+					"permissionConfiguration": map[string]interface{}{
+						"bucketLevelPermissions": map[string]interface{}{
+							"blockPublicAccess": map[string]interface{}{
+								"ignorePublicAcls":      true,
+								"restrictPublicBuckets": true,
+								"blockPublicAcls":       true,
+								"blockPublicPolicy":     true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	service := protectionService(finding, "CloudTrail", anomalyInfo("s3.amazonaws.com", []string{"GetObject", "ListObjects"}, count, userName, caller))
+	service["count"] = count
+	service["action"] = map[string]interface{}{
+		"actionType": "AWS_API_CALL",
+		"awsApiCallAction": map[string]interface{}{
+			"api":               "GetObject",
+			"serviceName":       "s3.amazonaws.com",
+			"callerType":        "Remote IP",
+			"remoteIpDetails":   g.remoteIPDetails(caller),
+			"affectedResources": map[string]interface{}{"AWS::S3::Bucket": bucket},
+		},
+	}
+	return finding
+}
+
+// iamAnomaly is an IAM user making calls of one tactic that its baseline
+// does not include, from an unusual network
+func (g *AWSGuardDutyGenerator) iamAnomaly(tactic string, apis []string, serviceName, accountID, region string) map[string]interface{} {
+	userName := g.RandomChoice([]string{"admin", "developer", "devops", "ci-deploy"}) + "-" + g.RandomString(4)
+	caller := g.RandomMaliciousGeoIP()
+	api := g.RandomChoice(apis)
+	count := g.RandomInt(5, 400)
+
+	findingType := tactic + ":IAMUser/AnomalousBehavior"
+	finding := g.buildBaseFinding(
+		findingType,
+		fmt.Sprintf("The API %s was invoked using %s credentials", api, userName),
+		fmt.Sprintf("APIs commonly used in %s tactics were invoked by user %s under unusual circumstances", tactic, userName),
+		accountID, region,
+	)
+	setSeverity(finding, 5)
+
+	finding["resource"] = map[string]interface{}{
+		"resourceType":     "AccessKey",
+		"accessKeyDetails": g.iamUserAccessKey(userName),
+	}
+
+	service := protectionService(finding, "CloudTrail", anomalyInfo(serviceName, apis, count, userName, caller))
+	service["count"] = count
+	service["action"] = map[string]interface{}{
+		"actionType": "AWS_API_CALL",
+		"awsApiCallAction": map[string]interface{}{
+			"api":             api,
+			"serviceName":     serviceName,
+			"callerType":      "Remote IP",
+			"remoteIpDetails": g.remoteIPDetails(caller),
+		},
+	}
+	return finding
+}
+
+// kubernetesFinding is an EKS audit log finding: a Kubernetes API call by a
+// user, and the workload it touched
+func (g *AWSGuardDutyGenerator) kubernetesFinding(findingType, accountID, region string) map[string]interface{} {
+	cluster := g.RandomChoice([]string{"prod-eks", "staging-eks", "platform", "data-pipeline"})
+	caller := g.RandomIPv4Internal()
+	remote := g.RandomMaliciousGeoIP()
+	var user, workload map[string]interface{}
+	var title, description, verb, requestURI string
+	statusCode := 200
+
+	switch findingType {
+	case "Discovery:Kubernetes/SuccessfulAnonymousAccess":
+		caller = remote.IP
+		user = map[string]interface{}{"username": "system:anonymous", "uid": "", "groups": []string{"system:unauthenticated"}}
+		verb = "list"
+		requestURI = g.RandomChoice([]string{"/api/v1/secrets", "/api/v1/pods", "/apis/rbac.authorization.k8s.io/v1/clusterroles", "/api/v1/namespaces"})
+		title = "Unauthenticated user listed Kubernetes resources"
+		description = fmt.Sprintf("An unauthenticated user called %s on EKS cluster %s", requestURI, cluster)
+	case "PrivilegeEscalation:Kubernetes/PrivilegedContainer":
+		user = g.kubernetesUser()
+		name := g.RandomChoice([]string{"debug", "node-shell", "backup-agent", "tools"}) + "-" + g.RandomString(5)
+		image := g.RandomChoice([]string{"alpine:latest", "ubuntu:22.04", "busybox:1.36", "nicolaka/netshoot"})
+		workload = g.kubernetesWorkload(name, "default", "pods", image, true)
+		verb = "create"
+		requestURI = "/api/v1/namespaces/default/pods"
+		statusCode = 201
+		title = "Privileged container with root level access launched on EKS cluster " + cluster
+		description = fmt.Sprintf("A privileged container %s with root level access was launched on EKS cluster %s", name, cluster)
+	default: // Execution:Kubernetes/ExecInKubeSystemPod
+		user = g.kubernetesUser()
+		name := g.RandomChoice([]string{"aws-node", "kube-proxy", "coredns"}) + "-" + g.RandomString(5)
+		workload = g.kubernetesWorkload(name, "kube-system", "pods", "602401143452.dkr.ecr."+region+".amazonaws.com/eks/"+g.RandomChoice([]string{"kube-proxy:v1.29.0", "coredns:v1.11.1", "amazon-k8s-cni:v1.16.0"}), false)
+		verb = "create"
+		requestURI = fmt.Sprintf("/api/v1/namespaces/kube-system/pods/%s/exec?command=%s&container=%s&stdin=true&stdout=true&tty=true", name, g.RandomChoice([]string{"sh", "bash", "cat%20/var/run/secrets/kubernetes.io/serviceaccount/token"}), name)
+		statusCode = 101
+		title = "Command executed inside a pod in kube-system namespace on EKS cluster " + cluster
+		description = fmt.Sprintf("A command was executed inside pod %s in the kube-system namespace on EKS cluster %s", name, cluster)
+	}
+
+	finding := g.buildBaseFinding(findingType, title, description, accountID, region)
+	setSeverity(finding, 5)
+
+	kubernetesDetails := map[string]interface{}{"kubernetesUserDetails": user}
+	if workload != nil {
+		kubernetesDetails["kubernetesWorkloadDetails"] = workload
+	}
+	finding["resource"] = map[string]interface{}{
+		"resourceType": "EKSCluster",
+		"eksClusterDetails": map[string]interface{}{
+			"name":      cluster,
+			"arn":       fmt.Sprintf("arn:aws:eks:%s:%s:cluster/%s", region, accountID, cluster),
+			"vpcId":     "vpc-" + g.RandomHex(9)[:17],
+			"status":    "ACTIVE",
+			"createdAt": time.Now().Add(-time.Duration(g.RandomInt(30, 700)*24) * time.Hour).UTC().Format(time.RFC3339),
+			"tags":      []map[string]interface{}{{"key": "environment", "value": g.RandomChoice([]string{"production", "staging"})}},
+		},
+		"kubernetesDetails": kubernetesDetails,
+	}
+
+	apiCall := map[string]interface{}{
+		"requestUri": requestURI,
+		"verb":       verb,
+		"sourceIps":  []string{caller},
+		"userAgent":  g.RandomChoice([]string{"kubectl/v1.29.1 (linux/amd64) kubernetes/bc401b9", "kubectl/v1.28.4 (darwin/arm64) kubernetes/bae2c62", "curl/8.4.0"}),
+		"statusCode": statusCode,
+	}
+	if caller == remote.IP {
+		apiCall["remoteIpDetails"] = g.remoteIPDetails(remote)
+	}
+
+	service := protectionService(finding, "EksAuditLogs", map[string]interface{}{"type": "default", "value": "{}"})
+	service["action"] = map[string]interface{}{
+		"actionType":              "KUBERNETES_API_CALL",
+		"kubernetesApiCallAction": apiCall,
+	}
+	return finding
+}
+
+// kubernetesUser is an IAM identity mapped into a cluster
+func (g *AWSGuardDutyGenerator) kubernetesUser() map[string]interface{} {
+	name := g.RandomChoice([]string{"developer", "devops", "ci-deploy"}) + "-" + g.RandomString(4)
+	return map[string]interface{}{
+		"username":    "kubernetes-admin",
+		"uid":         fmt.Sprintf("aws-iam-authenticator:%s:AIDA%s", g.randomAccountID(), g.RandomString(17)),
+		"groups":      []string{"system:masters", "system:authenticated"},
+		"sessionName": []string{name},
+	}
+}
+
+// kubernetesWorkload is the pod a Kubernetes finding names
+func (g *AWSGuardDutyGenerator) kubernetesWorkload(name, namespace, workloadType, image string, privileged bool) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"type":        workloadType,
+		"uid":         uuid.New().String(),
+		"namespace":   namespace,
+		"hostNetwork": privileged,
+		"containers": []map[string]interface{}{
+			{
+				"name":        name,
+				"image":       image,
+				"imagePrefix": "",
+				"securityContext": map[string]interface{}{
+					"privileged":               privileged,
+					"allowPrivilegeEscalation": privileged,
+				},
+			},
+		},
+	}
+}
+
+// runtimeFinding is a Runtime Monitoring finding on an EC2 instance: the
+// process the agent saw and, for network activity, its connection
+func (g *AWSGuardDutyGenerator) runtimeFinding(findingType, accountID, region string) map[string]interface{} {
+	instanceID := fmt.Sprintf("i-%s", g.RandomHex(9)[:17])
+	now := time.Now().UTC()
+	remote := g.RandomMaliciousGeoIP()
+
+	var name, path, title, description string
+	var severity float64
+	var lineage []map[string]interface{}
+	context := map[string]interface{}{}
+	switch findingType {
+	case "Execution:Runtime/ReverseShell":
+		name, path = "bash", "/usr/bin/bash"
+		severity = 8
+		title = "A process executed a reverse shell on EC2 instance " + instanceID
+		description = fmt.Sprintf("A process on EC2 instance %s redirected its standard input and output to a remote connection to %s", instanceID, remote.IP)
+		lineage = g.runtimeLineage(now, "nginx", "/usr/sbin/nginx", "sh", "/usr/bin/sh")
+	case "Execution:Runtime/NewBinaryExecuted":
+		name = g.RandomChoice([]string{"kworkerd", "update", ".x", "dbus-helper"})
+		path = g.RandomChoice([]string{"/tmp/", "/dev/shm/", "/var/tmp/"}) + name
+		severity = 5
+		title = "A newly created or recently modified binary file was executed on EC2 instance " + instanceID
+		description = fmt.Sprintf("Binary %s, created or modified after the container started, was executed on EC2 instance %s", path, instanceID)
+		lineage = g.runtimeLineage(now, "containerd-shim", "/usr/bin/containerd-shim-runc-v2", "sh", "/bin/sh")
+		context["modifyingProcess"] = map[string]interface{}{"name": "curl", "executablePath": "/usr/bin/curl", "pid": g.RandomInt(2000, 60000)}
+		context["modifiedAt"] = now.Add(-time.Duration(g.RandomInt(5, 300)) * time.Second).Format(time.RFC3339)
+	default: // CryptoCurrency:Runtime/BitcoinTool.B
+		name = g.RandomChoice([]string{"xmrig", "kdevtmpfsi", "kinsing"})
+		path = "/tmp/" + name
+		severity = 8
+		title = "EC2 instance " + instanceID + " is connecting to a cryptocurrency mining pool"
+		description = fmt.Sprintf("Process %s on EC2 instance %s connected to %s, a cryptocurrency mining pool", name, instanceID, remote.IP)
+		lineage = g.runtimeLineage(now, "systemd", "/usr/lib/systemd/systemd", "cron", "/usr/sbin/cron")
+	}
+
+	finding := g.buildBaseFinding(findingType, title, description, accountID, region)
+	setSeverity(finding, severity)
+
+	finding["resource"] = map[string]interface{}{
+		"resourceType": "Instance",
+		"instanceDetails": map[string]interface{}{
+			"instanceId":   instanceID,
+			"instanceType": g.RandomChoice([]string{"t3.medium", "m5.large", "c5.xlarge"}),
+			"platform":     "linux",
+			"imageId":      "ami-" + g.RandomHex(9)[:17],
+			"networkInterfaces": []map[string]interface{}{
+				{
+					"privateIpAddress": g.RandomIPv4Internal(),
+					"publicIp":         g.RandomIPv4External(),
+				},
+			},
+		},
+	}
+
+	pid := g.RandomInt(2000, 60000)
+	process := map[string]interface{}{
+		"name":             name,
+		"executablePath":   path,
+		"executableSha256": g.RandomSHA256(),
+		"pid":              pid,
+		"namespacePid":     pid,
+		"pwd":              g.RandomChoice([]string{"/", "/tmp", "/var/www/html"}),
+		"uuid":             uuid.New().String(),
+		"startTime":        now.Add(-time.Duration(g.RandomInt(1, 120)) * time.Second).Format(time.RFC3339),
+		"user":             g.RandomChoice([]string{"root", "www-data", "ec2-user"}),
+		"userId":           g.RandomChoiceInterface([]interface{}{0, 33, 1000}),
+		"euid":             0,
+		"lineage":          lineage,
+	}
+
+	service := protectionService(finding, "RuntimeMonitoring", map[string]interface{}{"type": "default", "value": "{}"})
+	service["resourceRole"] = "ACTOR"
+	service["runtimeDetails"] = map[string]interface{}{
+		"process": process,
+		"context": context,
+	}
+	if findingType != "Execution:Runtime/NewBinaryExecuted" {
+		service["action"] = map[string]interface{}{
+			"actionType": "NETWORK_CONNECTION",
+			"networkConnectionAction": map[string]interface{}{
+				"connectionDirection": "OUTBOUND",
+				"remoteIpDetails":     g.remoteIPDetails(remote),
+				"remotePortDetails":   map[string]interface{}{"port": g.RandomChoiceInterface([]interface{}{4444, 443, 3333, 14444})},
+				"localPortDetails":    map[string]interface{}{"port": g.RandomInt(32768, 60999)},
+				"protocol":            "TCP",
+				"blocked":             false,
+			},
+		}
+	}
+	return finding
+}
+
+// runtimeLineage is the parent chain of a runtime finding's process,
+// nearest parent first
+func (g *AWSGuardDutyGenerator) runtimeLineage(now time.Time, grandparentName, grandparentPath, parentName, parentPath string) []map[string]interface{} {
+	grandparent := uuid.New().String()
+	return []map[string]interface{}{
+		{
+			"name":           parentName,
+			"executablePath": parentPath,
+			"pid":            g.RandomInt(1000, 60000),
+			"uuid":           uuid.New().String(),
+			"parentUuid":     grandparent,
+			"euid":           0,
+			"startTime":      now.Add(-time.Duration(g.RandomInt(120, 600)) * time.Second).Format(time.RFC3339),
+		},
+		{
+			"name":           grandparentName,
+			"executablePath": grandparentPath,
+			"pid":            g.RandomInt(1, 1000),
+			"uuid":           grandparent,
+			"euid":           0,
+			"startTime":      now.Add(-time.Duration(g.RandomInt(1, 30)*24) * time.Hour).Format(time.RFC3339),
+		},
+	}
+}
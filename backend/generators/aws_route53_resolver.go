@@ -0,0 +1,204 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// AWSRoute53ResolverGenerator generates Route 53 Resolver query logs
+type AWSRoute53ResolverGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&AWSRoute53ResolverGenerator{})
+}
+
+// GetEventType returns the event type for Route 53 Resolver query logs
+func (g *AWSRoute53ResolverGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "aws_route53_resolver",
+		Name:        "AWS Route 53 Resolver Query Logs",
+		Category:    "cloud",
+		Description: "DNS queries made by resources in a VPC, with DNS Firewall actions",
+		EventIDs:    []string{"NOERROR", "NXDOMAIN", "SERVFAIL", "BLOCK"},
+	}
+}
+
+// GetTemplates returns available templates for Route 53 Resolver query logs
+func (g *AWSRoute53ResolverGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "query",
+			Name:        "Resolved Query",
+			Category:    "aws_route53_resolver",
+			EventID:     "NOERROR",
+			Format:      "json",
+			Description: "Query for a private, AWS service or public name that resolved",
+		},
+		{
+			ID:          "nxdomain",
+			Name:        "Non-Existent Domain",
+			Category:    "aws_route53_resolver",
+			EventID:     "NXDOMAIN",
+			Format:      "json",
+			Description: "Query for a name that does not exist",
+		},
+		{
+			ID:          "servfail",
+			Name:        "Server Failure",
+			Category:    "aws_route53_resolver",
+			EventID:     "SERVFAIL",
+			Format:      "json",
+			Description: "Query the resolver could not answer",
+		},
+		{
+			ID:          "firewall_block",
+			Name:        "DNS Firewall Block",
+			Category:    "aws_route53_resolver",
+			EventID:     "BLOCK",
+			Format:      "json",
+			Description: "Query for a malicious domain blocked by a DNS Firewall rule",
+		},
+	}
+}
+
+// Generate creates a Route 53 Resolver query log record
+func (g *AWSRoute53ResolverGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "query":
+		return g.generateQuery(overrides)
+	case "nxdomain":
+		return g.generateFailedQuery("NXDOMAIN", overrides)
+	case "servfail":
+		return g.generateFailedQuery("SERVFAIL", overrides)
+	case "firewall_block":
+		return g.generateFirewallBlock(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// resolverPublicDomains are public names workloads look up
+var resolverPublicDomains = []string{
+	"api.github.com", "registry.npmjs.org", "pypi.org", "files.pythonhosted.org",
+	"hub.docker.com", "production.cloudflare.docker.com", "api.datadoghq.com",
+	"hooks.slack.com", "login.microsoftonline.com", "ntp.ubuntu.com",
+}
+
+// buildBaseRecord builds a query record made by one of the account's
+// instances
+func (g *AWSRoute53ResolverGenerator) buildBaseRecord(account *AWSAccount, instance *AWSInstance, timestamp time.Time, queryName, queryType, rcode string) map[string]interface{} {
+	return map[string]interface{}{
+		"version":         "1.100000",
+		"account_id":      account.ID,
+		"region":          account.Region,
+		"vpc_id":          account.VPCID,
+		"query_timestamp": timestamp.UTC().Format(time.RFC3339),
+		"query_name":      queryName + ".",
+		"query_type":      queryType,
+		"query_class":     "IN",
+		"rcode":           rcode,
+		"answers":         []map[string]interface{}{},
+		"srcaddr":         instance.PrivateIP,
+		"srcport":         fmt.Sprint(g.RandomInt(1024, 65535)),
+		"transport":       g.WeightedChoice([]string{"UDP", "TCP"}, []float64{95, 5}),
+		"srcids": map[string]interface{}{
+			"instance": instance.ID,
+		},
+	}
+}
+
+// resolverAnswer is an entry of a record's answers
+func resolverAnswer(rdata, rrType string) map[string]interface{} {
+	return map[string]interface{}{"Rdata": rdata, "Type": rrType, "Class": "IN"}
+}
+
+// generateQuery creates a resolved query: another instance's private name,
+// an AWS service endpoint in the account's region, or a public name
+func (g *AWSRoute53ResolverGenerator) generateQuery(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	account := AWS.RandomAccount(g.rnd)
+	instance := account.RandomInstance(g.rnd)
+
+	var queryName string
+	var answers []map[string]interface{}
+	switch roll := g.RandomInt(1, 100); {
+	case roll <= 35:
+		peer := account.RandomInstance(g.rnd)
+		queryName = account.PrivateHostname(peer)
+		answers = []map[string]interface{}{resolverAnswer(peer.PrivateIP, "A")}
+	case roll <= 70:
+		service := g.RandomChoice([]string{"s3", "sts", "ssm", "ec2messages", "logs", "secretsmanager", "dynamodb"})
+		queryName = fmt.Sprintf("%s.%s.amazonaws.com", service, account.Region)
+		answers = []map[string]interface{}{resolverAnswer(g.RandomIPv4External(), "A")}
+	default:
+		queryName = g.InjectIOC(models.IOCTypeDomain, g.RandomChoice(resolverPublicDomains))
+		answers = []map[string]interface{}{resolverAnswer(g.RandomIPv4External(), "A"), resolverAnswer(g.RandomIPv4External(), "A")}
+	}
+
+	record := g.buildBaseRecord(account, instance, timestamp, queryName, "A", "NOERROR")
+	record["answers"] = answers
+
+	return g.event("NOERROR", timestamp, record, overrides)
+}
+
+// generateFailedQuery creates a query that got no answer, mostly a typo or
+// a retired name, or a DGA-like name for NXDOMAIN
+func (g *AWSRoute53ResolverGenerator) generateFailedQuery(rcode string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	account := AWS.RandomAccount(g.rnd)
+	instance := account.RandomInstance(g.rnd)
+
+	queryName := g.RandomChoice([]string{"legacy-api", "old-db", "metrics", "cache-01"}) + "." + account.privateZone
+	if rcode == "NXDOMAIN" && g.RandomInt(1, 100) <= 20 {
+		queryName = fmt.Sprintf("%s.%s", strings.ToLower(g.RandomString(g.RandomInt(10, 20))), g.RandomChoice([]string{"xyz", "top", "info", "biz"}))
+	} else if rcode == "SERVFAIL" {
+		queryName = g.RandomChoice(resolverPublicDomains)
+	}
+
+	record := g.buildBaseRecord(account, instance, timestamp, queryName, g.RandomChoice([]string{"A", "A", "AAAA"}), rcode)
+
+	return g.event(rcode, timestamp, record, overrides)
+}
+
+// generateFirewallBlock creates a query for a malicious domain that a DNS
+// Firewall rule group blocked with an NXDOMAIN response
+func (g *AWSRoute53ResolverGenerator) generateFirewallBlock(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	account := AWS.RandomAccount(g.rnd)
+	instance := account.RandomInstance(g.rnd)
+
+	queryName := g.InjectIOC(models.IOCTypeDomain, fmt.Sprintf("%s.%s", strings.ToLower(g.RandomString(g.RandomInt(8, 16))), g.RandomChoice([]string{"xyz", "top", "tk", "ru", "cc"})))
+
+	record := g.buildBaseRecord(account, instance, timestamp, queryName, "A", "NXDOMAIN")
+	record["firewall_rule_action"] = "BLOCK"
+	record["firewall_rule_group_id"] = "rslvr-frg-" + g.RandomHex(8)
+	record["firewall_domain_list_id"] = g.RandomChoice([]string{"rslvr-fdl-2c46f2ecbfec4dcc", "rslvr-fdl-aa970e9e1a8f4fd6"}) // AWS managed malware and botnet lists
+
+	return g.event("BLOCK", timestamp, record, overrides)
+}
+
+// event applies overrides to a record and wraps it as a generated event
+func (g *AWSRoute53ResolverGenerator) event(eventID string, timestamp time.Time, record, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields := g.ApplyOverrides(record, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "aws_route53_resolver",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "aws:cloudwatchlogs:route53resolver",
+	}, nil
+}
@@ -0,0 +1,538 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// AWSSecurityHubGenerator generates AWS Security Hub findings in the AWS
+// Security Finding Format (ASFF)
+type AWSSecurityHubGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&AWSSecurityHubGenerator{})
+}
+
+// asffTime is the timestamp layout of ASFF findings
+const asffTime = "2006-01-02T15:04:05.000Z07:00"
+
+// GetEventType returns the event type for AWS Security Hub
+func (g *AWSSecurityHubGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "aws_securityhub",
+		Name:        "AWS Security Hub",
+		Category:    "cloud",
+		Description: "AWS Security Hub findings in ASFF - control checks, GuardDuty findings and Inspector vulnerabilities",
+		EventIDs:    []string{"Security Hub", "GuardDuty", "Inspector"},
+	}
+}
+
+// GetTemplates returns available templates for AWS Security Hub findings
+func (g *AWSSecurityHubGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "control_failed",
+			Name:        "Control Check Failed",
+			Category:    "aws_securityhub",
+			EventID:     "Security Hub",
+			Format:      "json",
+			Description: "Resource failed an AWS Foundational Security Best Practices control",
+		},
+		{
+			ID:          "control_passed",
+			Name:        "Control Check Passed",
+			Category:    "aws_securityhub",
+			EventID:     "Security Hub",
+			Format:      "json",
+			Description: "Resource passed an AWS Foundational Security Best Practices control",
+		},
+		{
+			ID:          "guardduty",
+			Name:        "GuardDuty Finding",
+			Category:    "aws_securityhub",
+			EventID:     "GuardDuty",
+			Format:      "json",
+			Description: "GuardDuty finding imported into Security Hub",
+		},
+		{
+			ID:          "inspector_ec2",
+			Name:        "Inspector EC2 Vulnerability",
+			Category:    "aws_securityhub",
+			EventID:     "Inspector",
+			Format:      "json",
+			Description: "Inspector finding for a vulnerable package on an EC2 instance",
+		},
+		{
+			ID:          "inspector_ecr",
+			Name:        "Inspector ECR Image Vulnerability",
+			Category:    "aws_securityhub",
+			EventID:     "Inspector",
+			Format:      "json",
+			Description: "Inspector finding for a vulnerable package in an ECR container image",
+		},
+	}
+}
+
+// Generate creates an AWS Security Hub finding
+func (g *AWSSecurityHubGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "control_failed":
+		return g.generateControlCheck("FAILED", overrides)
+	case "control_passed":
+		return g.generateControlCheck("PASSED", overrides)
+	case "guardduty":
+		return g.generateGuardDuty(overrides)
+	case "inspector_ec2":
+		return g.generateInspector(false, overrides)
+	case "inspector_ecr":
+		return g.generateInspector(true, overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// asffNormalized is the normalized score Security Hub derives from a
+// severity label
+var asffNormalized = map[string]int{"INFORMATIONAL": 0, "LOW": 1, "MEDIUM": 40, "HIGH": 70, "CRITICAL": 90}
+
+// buildBaseFinding builds the fields every ASFF finding has. product is the
+// integration's product name in ProductArn, such as guardduty.
+func (g *AWSSecurityHubGenerator) buildBaseFinding(product, productName, id, generatorID, accountID, region string, types []string, firstSeen, now time.Time) map[string]interface{} {
+	productARN := fmt.Sprintf("arn:aws:securityhub:%s::product/aws/%s", region, product)
+	return map[string]interface{}{
+		"SchemaVersion":   "2018-10-08",
+		"Id":              id,
+		"ProductArn":      productARN,
+		"ProductName":     productName,
+		"CompanyName":     "AWS",
+		"Region":          region,
+		"GeneratorId":     generatorID,
+		"AwsAccountId":    accountID,
+		"Types":           types,
+		"FirstObservedAt": firstSeen.UTC().Format(asffTime),
+		"LastObservedAt":  now.UTC().Format(asffTime),
+		"CreatedAt":       firstSeen.UTC().Format(asffTime),
+		"UpdatedAt":       now.UTC().Format(asffTime),
+		"Resources":       []map[string]interface{}{},
+		"Workflow":        map[string]interface{}{"Status": "NEW"},
+		"WorkflowState":   "NEW",
+		"RecordState":     "ACTIVE",
+		"ProductFields": map[string]interface{}{
+			"aws/securityhub/FindingId":   productARN + "/" + id,
+			"aws/securityhub/ProductName": productName,
+			"aws/securityhub/CompanyName": "AWS",
+		},
+	}
+}
+
+// setASFFSeverity sets a finding's Severity and the FindingProviderFields
+// copy the providing product owns
+func setASFFSeverity(finding map[string]interface{}, label, original string) {
+	finding["Severity"] = map[string]interface{}{
+		"Label":      label,
+		"Normalized": asffNormalized[label],
+		"Original":   original,
+	}
+	finding["FindingProviderFields"] = map[string]interface{}{
+		"Severity": map[string]interface{}{"Label": label, "Original": original},
+		"Types":    finding["Types"],
+	}
+}
+
+// ec2Resource is the AwsEc2Instance resource of one of the AWS
+// environment's instances
+func (g *AWSSecurityHubGenerator) ec2Resource(account *AWSAccount, instance *AWSInstance) map[string]interface{} {
+	return map[string]interface{}{
+		"Type":      "AwsEc2Instance",
+		"Id":        fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%s", account.Region, account.ID, instance.ID),
+		"Partition": "aws",
+		"Region":    account.Region,
+		"Tags":      map[string]interface{}{"Name": instance.Name},
+		"Details": map[string]interface{}{
+			"AwsEc2Instance": map[string]interface{}{
+				"Type":          instance.InstanceType,
+				"ImageId":       "ami-" + g.RandomHex(9)[:17],
+				"IpV4Addresses": []string{instance.PrivateIP},
+				"VpcId":         account.VPCID,
+				"SubnetId":      instance.SubnetID,
+				"LaunchedAt":    time.Now().Add(-time.Duration(g.RandomInt(1, 90)*24) * time.Hour).UTC().Format(asffTime),
+			},
+		},
+	}
+}
+
+// securityHubControls are controls of the AWS Foundational Security Best
+// Practices standard and the Config rules that evaluate them
+var securityHubControls = []struct {
+	id, title, severity, resourceType, configRule string
+}{
+	{"S3.8", "S3 general purpose buckets should block public access", "HIGH", "AwsS3Bucket", "s3-bucket-level-public-access-prohibited"},
+	{"EC2.19", "Security groups should not allow unrestricted access to ports with high risk", "CRITICAL", "AwsEc2SecurityGroup", "vpc-sg-restricted-common-ports"},
+	{"EC2.8", "EC2 instances should use Instance Metadata Service Version 2 (IMDSv2)", "HIGH", "AwsEc2Instance", "ec2-imdsv2-check"},
+	{"IAM.4", "IAM root user access key should not exist", "CRITICAL", "AwsAccount", "iam-root-access-key-check"},
+	{"IAM.6", "Hardware MFA should be enabled for the root user", "CRITICAL", "AwsAccount", "root-account-hardware-mfa-enabled"},
+	{"CloudTrail.1", "CloudTrail should be enabled and configured with at least one multi-Region trail that includes read and write management events", "HIGH", "AwsAccount", "multi-region-cloudtrail-enabled"},
+	{"RDS.3", "RDS DB instances should have encryption at-rest enabled", "MEDIUM", "AwsRdsDbInstance", "rds-storage-encrypted"},
+}
+
+// controlResource is a resource of the given type for a control to evaluate
+func (g *AWSSecurityHubGenerator) controlResource(resourceType string, account *AWSAccount) map[string]interface{} {
+	resource := map[string]interface{}{
+		"Type":      resourceType,
+		"Partition": "aws",
+		"Region":    account.Region,
+	}
+	switch resourceType {
+	case "AwsEc2Instance":
+		return g.ec2Resource(account, account.RandomInstance(g.rnd))
+	case "AwsS3Bucket":
+		resource["Id"] = fmt.Sprintf("arn:aws:s3:::%s-%s-%s", account.Name, g.RandomChoice([]string{"logs", "artifacts", "backups", "data-exports"}), account.ID)
+	case "AwsEc2SecurityGroup":
+		groupID := "sg-" + g.RandomHex(9)[:17]
+		resource["Id"] = fmt.Sprintf("arn:aws:ec2:%s:%s:security-group/%s", account.Region, account.ID, groupID)
+		resource["Details"] = map[string]interface{}{
+			"AwsEc2SecurityGroup": map[string]interface{}{
+				"GroupId":   groupID,
+				"GroupName": account.Name + "-" + g.RandomChoice([]string{"web", "app", "db", "bastion"}) + "-sg",
+				"VpcId":     account.VPCID,
+				"OwnerId":   account.ID,
+			},
+		}
+	case "AwsRdsDbInstance":
+		resource["Id"] = fmt.Sprintf("arn:aws:rds:%s:%s:db:%s-%s", account.Region, account.ID, account.Name, g.RandomChoice([]string{"orders", "users", "billing"}))
+	default: // AwsAccount
+		resource["Id"] = "AWS::::Account:" + account.ID
+	}
+	return resource
+}
+
+// generateControlCheck creates a Security Hub control finding for one of
+// the AWS environment's resources. A passing resource has an INFORMATIONAL
+// severity and a RESOLVED workflow, as Security Hub sets them.
+func (g *AWSSecurityHubGenerator) generateControlCheck(status string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	account := AWS.RandomAccount(g.rnd)
+	control := securityHubControls[g.RandomInt(0, len(securityHubControls)-1)]
+	service, number, _ := strings.Cut(control.id, ".")
+	service = strings.ToLower(service)
+
+	id := fmt.Sprintf("arn:aws:securityhub:%s:%s:security-control/%s/finding/%s", account.Region, account.ID, control.id, uuid.New().String())
+	types := []string{"Software and Configuration Checks/Industry and Regulatory Standards/AWS-Foundational-Security-Best-Practices"}
+	finding := g.buildBaseFinding("securityhub", "Security Hub", id, "security-control/"+control.id, account.ID, account.Region, types,
+		timestamp.Add(-time.Duration(g.RandomInt(1, 30)*24)*time.Hour), timestamp)
+
+	finding["Title"] = control.title
+	finding["Description"] = fmt.Sprintf("%s. Security Hub control %s evaluates the resource with the %s Config rule.", control.title, control.id, control.configRule)
+	finding["Resources"] = []map[string]interface{}{g.controlResource(control.resourceType, account)}
+	finding["Compliance"] = map[string]interface{}{
+		"Status":            status,
+		"SecurityControlId": control.id,
+		"AssociatedStandards": []map[string]interface{}{
+			{"StandardsId": "standards/aws-foundational-security-best-practices/v/1.0.0"},
+		},
+	}
+	finding["Remediation"] = map[string]interface{}{
+		"Recommendation": map[string]interface{}{
+			"Text": "For information on how to correct this issue, consult the AWS Security Hub controls documentation.",
+			"Url":  fmt.Sprintf("https://docs.aws.amazon.com/securityhub/latest/userguide/%s-controls.html#%s-%s", service, service, number),
+		},
+	}
+	productFields := finding["ProductFields"].(map[string]interface{})
+	productFields["RelatedAWSResources:0/name"] = "securityhub-" + control.configRule + "-" + g.RandomHex(4)
+	productFields["RelatedAWSResources:0/type"] = "AWS::Config::ConfigRule"
+
+	label := control.severity
+	if status == "PASSED" {
+		label = "INFORMATIONAL"
+		finding["Workflow"] = map[string]interface{}{"Status": "RESOLVED"}
+	}
+	setASFFSeverity(finding, label, control.severity)
+
+	return g.event("Security Hub", timestamp, finding, overrides)
+}
+
+// guardDutyASFFNamespaces are the ASFF type namespaces Security Hub files
+// GuardDuty threat purposes under
+var guardDutyASFFNamespaces = map[string]string{
+	"UnauthorizedAccess":  "TTPs/Initial Access",
+	"Recon":               "TTPs/Discovery",
+	"Discovery":           "TTPs/Discovery",
+	"CredentialAccess":    "TTPs/Credential Access",
+	"PrivilegeEscalation": "TTPs/Privilege Escalation",
+	"Execution":           "TTPs/Execution",
+	"Backdoor":            "TTPs/Command and Control",
+	"Trojan":              "TTPs/Command and Control",
+	"CryptoCurrency":      "Effects/Resource Consumption",
+	"Exfiltration":        "Effects/Data Exfiltration",
+}
+
+// guardDutySeverityLabel maps a GuardDuty severity to its Security Hub label
+func guardDutySeverityLabel(severity float64) string {
+	switch {
+	case severity >= 7:
+		return "HIGH"
+	case severity >= 4:
+		return "MEDIUM"
+	}
+	return "LOW"
+}
+
+// generateGuardDuty creates the ASFF form of a GuardDuty finding: a finding
+// from the GuardDuty generator, with its type, severity, resource and
+// network connection mapped the way Security Hub imports them
+func (g *AWSSecurityHubGenerator) generateGuardDuty(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	gd, _ := GetGenerator("aws_guardduty")
+	templates := gd.GetTemplates()
+	source, err := g.rnd.Generate(gd, templates[g.RandomInt(0, len(templates)-1)].ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	gdFinding := source.Fields
+	service, _ := gdFinding["service"].(map[string]interface{})
+	resource, _ := gdFinding["resource"].(map[string]interface{})
+
+	findingType := fmt.Sprint(gdFinding["type"])
+	accountID := fmt.Sprint(gdFinding["accountId"])
+	region := fmt.Sprint(gdFinding["region"])
+	detectorID := fmt.Sprint(service["detectorId"])
+	purpose, _, _ := strings.Cut(findingType, ":")
+	types := []string{guardDutyASFFNamespaces[purpose] + "/" + strings.ReplaceAll(findingType, "/", "-")}
+
+	firstSeen, _ := time.Parse(time.RFC3339, fmt.Sprint(service["eventFirstSeen"]))
+	id := fmt.Sprintf("arn:aws:guardduty:%s:%s:detector/%s/finding/%s", region, accountID, detectorID, gdFinding["id"])
+	finding := g.buildBaseFinding("guardduty", "GuardDuty", id, detectorID, accountID, region, types, firstSeen, source.Timestamp)
+	finding["Title"] = gdFinding["title"]
+	finding["Description"] = gdFinding["description"]
+	finding["Resources"] = g.guardDutyResources(resource, accountID, region)
+
+	productFields := finding["ProductFields"].(map[string]interface{})
+	productFields["aws/guardduty/service/archived"] = "false"
+	productFields["aws/guardduty/service/count"] = fmt.Sprint(service["count"])
+	productFields["aws/guardduty/service/detectorId"] = detectorID
+	if action, ok := service["action"].(map[string]interface{}); ok && action["actionType"] != nil {
+		productFields["aws/guardduty/service/action/actionType"] = action["actionType"]
+		if connection, ok := action["networkConnectionAction"].(map[string]interface{}); ok {
+			finding["Network"] = guardDutyNetwork(connection)
+		}
+	}
+
+	severity, _ := gdFinding["severity"].(float64)
+	setASFFSeverity(finding, guardDutySeverityLabel(severity), fmt.Sprint(severity))
+
+	return g.event("GuardDuty", source.Timestamp, finding, overrides)
+}
+
+// guardDutyResources maps a GuardDuty resource block to ASFF resources
+func (g *AWSSecurityHubGenerator) guardDutyResources(resource map[string]interface{}, accountID, region string) []map[string]interface{} {
+	var resources []map[string]interface{}
+	add := func(resourceType, id string, details map[string]interface{}) {
+		r := map[string]interface{}{"Type": resourceType, "Id": id, "Partition": "aws", "Region": region}
+		if details != nil {
+			r["Details"] = map[string]interface{}{resourceType: details}
+		}
+		resources = append(resources, r)
+	}
+
+	if key, ok := resource["accessKeyDetails"].(map[string]interface{}); ok {
+		add("AwsIamAccessKey", fmt.Sprintf("AWS::IAM::AccessKey:%v", key["accessKeyId"]), map[string]interface{}{
+			"PrincipalId":   key["principalId"],
+			"PrincipalName": key["userName"],
+			"PrincipalType": key["userType"],
+		})
+	}
+	if instance, ok := resource["instanceDetails"].(map[string]interface{}); ok {
+		details := map[string]interface{}{"Type": instance["instanceType"]}
+		if launched, ok := instance["launchTime"]; ok {
+			details["LaunchedAt"] = launched
+		}
+		if nics, ok := instance["networkInterfaces"].([]map[string]interface{}); ok && len(nics) > 0 {
+			details["IpV4Addresses"] = []interface{}{nics[0]["privateIpAddress"]}
+		}
+		add("AwsEc2Instance", fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%v", region, accountID, instance["instanceId"]), details)
+	}
+	if buckets, ok := resource["s3BucketDetails"].([]map[string]interface{}); ok {
+		for _, bucket := range buckets {
+			add("AwsS3Bucket", fmt.Sprint(bucket["arn"]), nil)
+		}
+	}
+	if cluster, ok := resource["eksClusterDetails"].(map[string]interface{}); ok {
+		add("AwsEksCluster", fmt.Sprint(cluster["arn"]), map[string]interface{}{"Name": cluster["name"], "Arn": cluster["arn"]})
+	}
+	return resources
+}
+
+// guardDutyNetwork maps a GuardDuty network connection action to an ASFF
+// Network block
+func guardDutyNetwork(connection map[string]interface{}) map[string]interface{} {
+	remoteIP := ""
+	if remote, ok := connection["remoteIpDetails"].(map[string]interface{}); ok {
+		remoteIP = fmt.Sprint(remote["ipAddressV4"])
+	}
+	port := func(key string) interface{} {
+		if details, ok := connection[key].(map[string]interface{}); ok {
+			return details["port"]
+		}
+		return nil
+	}
+
+	network := map[string]interface{}{"Protocol": connection["protocol"]}
+	if connection["connectionDirection"] == "INBOUND" {
+		network["Direction"] = "IN"
+		network["SourceIpV4"] = remoteIP
+		network["DestinationPort"] = port("localPortDetails")
+	} else {
+		network["Direction"] = "OUT"
+		network["DestinationIpV4"] = remoteIP
+		network["DestinationPort"] = port("remotePortDetails")
+	}
+	return network
+}
+
+// inspectorVulnerabilities are well-known CVEs with the package versions
+// Inspector reports them for
+var inspectorVulnerabilities = []struct {
+	cve, pkg, version, fixed, packageManager string
+	score                                    float64
+	vector, description, published           string
+	exploited                                bool
+}{
+	{"CVE-2021-44228", "org.apache.logging.log4j:log4j-core", "2.14.1", "2.15.0", "JAR", 10.0, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H",
+		"Apache Log4j2 JNDI features used in configuration, log messages, and parameters do not protect against attacker controlled LDAP and other JNDI related endpoints.", "2021-12-10", true},
+	{"CVE-2024-3094", "xz-utils", "5.6.0-0.2", "5.6.1+really5.4.5-1", "OS", 10.0, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H",
+		"Malicious code was discovered in the upstream tarballs of xz, starting with version 5.6.0.", "2024-03-29", true},
+	{"CVE-2023-38545", "curl", "7.81.0-1ubuntu1.13", "7.81.0-1ubuntu1.14", "OS", 9.8, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"This flaw makes curl overflow a heap based buffer in the SOCKS5 proxy handshake.", "2023-10-18", false},
+	{"CVE-2024-6387", "openssh-server", "1:8.9p1-3ubuntu0.7", "1:8.9p1-3ubuntu0.10", "OS", 8.1, "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"A signal handler race condition was found in OpenSSH's server (sshd), where a client does not authenticate within LoginGraceTime seconds.", "2024-07-01", true},
+	{"CVE-2023-4911", "libc6", "2.35-0ubuntu3.3", "2.35-0ubuntu3.4", "OS", 7.8, "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H",
+		"A buffer overflow was discovered in the GNU C Library's dynamic loader ld.so while processing the GLIBC_TUNABLES environment variable.", "2023-10-03", true},
+	{"CVE-2021-3156", "sudo", "1.8.31-1ubuntu1", "1.8.31-1ubuntu1.2", "OS", 7.8, "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H",
+		"Sudo before 1.9.5p2 contains an off-by-one error that can result in a heap-based buffer overflow.", "2021-01-26", true},
+	{"CVE-2023-0286", "openssl", "3.0.2-0ubuntu1.7", "3.0.2-0ubuntu1.8", "OS", 7.4, "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H",
+		"There is a type confusion vulnerability relating to X.400 address processing inside an X.509 GeneralName.", "2023-02-08", false},
+}
+
+// cvssSeverityLabel maps a CVSS v3 base score to its severity rating
+func cvssSeverityLabel(score float64) string {
+	switch {
+	case score >= 9:
+		return "CRITICAL"
+	case score >= 7:
+		return "HIGH"
+	case score >= 4:
+		return "MEDIUM"
+	}
+	return "LOW"
+}
+
+// generateInspector creates an Inspector package vulnerability finding for
+// an instance of the AWS environment or an image in one of its ECR
+// repositories
+func (g *AWSSecurityHubGenerator) generateInspector(image bool, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	account := AWS.RandomAccount(g.rnd)
+	vuln := inspectorVulnerabilities[g.RandomInt(0, len(inspectorVulnerabilities)-1)]
+	label := cvssSeverityLabel(vuln.score)
+
+	var resource map[string]interface{}
+	if image {
+		repository := g.RandomChoice([]string{"web-frontend", "orders-api", "payments-worker", "auth-service"})
+		digest := "sha256:" + g.RandomHex(32)
+		resource = map[string]interface{}{
+			"Type":      "AwsEcrContainerImage",
+			"Id":        fmt.Sprintf("arn:aws:ecr:%s:%s:repository/%s/%s", account.Region, account.ID, repository, digest),
+			"Partition": "aws",
+			"Region":    account.Region,
+			"Details": map[string]interface{}{
+				"AwsEcrContainerImage": map[string]interface{}{
+					"RegistryId":       account.ID,
+					"RepositoryName":   repository,
+					"Architecture":     "amd64",
+					"ImageDigest":      digest,
+					"ImageTags":        []string{fmt.Sprintf("v1.%d.%d", g.RandomInt(0, 20), g.RandomInt(0, 9))},
+					"ImagePublishedAt": timestamp.Add(-time.Duration(g.RandomInt(1, 60)*24) * time.Hour).UTC().Format(asffTime),
+				},
+			},
+		}
+	} else {
+		resource = g.ec2Resource(account, account.RandomInstance(g.rnd))
+	}
+
+	pkg := map[string]interface{}{
+		"Name":           vuln.pkg,
+		"Version":        vuln.version,
+		"FixedInVersion": vuln.fixed,
+		"PackageManager": vuln.packageManager,
+		"Architecture":   "X86_64",
+		"Remediation":    "Upgrade to version " + vuln.fixed,
+	}
+	if vuln.packageManager == "JAR" {
+		pkg["FilePath"] = "/opt/app/lib/log4j-core-" + vuln.version + ".jar"
+	}
+	exploitAvailable := "NO"
+	if vuln.exploited {
+		exploitAvailable = "YES"
+	}
+
+	id := fmt.Sprintf("arn:aws:inspector2:%s:%s:finding/%s", account.Region, account.ID, g.RandomHex(16))
+	types := []string{"Software and Configuration Checks/Vulnerabilities/CVE"}
+	finding := g.buildBaseFinding("inspector", "Inspector", id, "AWSInspector", account.ID, account.Region, types,
+		timestamp.Add(-time.Duration(g.RandomInt(1, 14)*24)*time.Hour), timestamp)
+	finding["Title"] = vuln.cve + " - " + vuln.pkg
+	finding["Description"] = vuln.description
+	finding["Resources"] = []map[string]interface{}{resource}
+	finding["Vulnerabilities"] = []map[string]interface{}{
+		{
+			"Id":                 vuln.cve,
+			"VulnerablePackages": []map[string]interface{}{pkg},
+			"Cvss": []map[string]interface{}{
+				{"Version": "3.1", "BaseScore": vuln.score, "BaseVector": vuln.vector, "Source": "NVD"},
+			},
+			"Vendor": map[string]interface{}{
+				"Name":            "NVD",
+				"Url":             "https://nvd.nist.gov/vuln/detail/" + vuln.cve,
+				"VendorSeverity":  label,
+				"VendorCreatedAt": vuln.published + "T00:00:00.000Z",
+			},
+			"ReferenceUrls":    []string{"https://nvd.nist.gov/vuln/detail/" + vuln.cve},
+			"FixAvailable":     "YES",
+			"ExploitAvailable": exploitAvailable,
+		},
+	}
+	finding["Remediation"] = map[string]interface{}{
+		"Recommendation": map[string]interface{}{"Text": "Upgrade your installed software packages to the proposed fixed in version and release."},
+	}
+	productFields := finding["ProductFields"].(map[string]interface{})
+	productFields["aws/inspector/FindingStatus"] = "ACTIVE"
+	productFields["aws/inspector/inspectorScore"] = fmt.Sprint(vuln.score)
+	productFields["aws/inspector/ProductVersion"] = "2"
+	setASFFSeverity(finding, label, label)
+
+	return g.event("Inspector", timestamp, finding, overrides)
+}
+
+// event applies overrides to a finding and wraps it as a generated event
+func (g *AWSSecurityHubGenerator) event(eventID string, timestamp time.Time, finding, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields := g.ApplyOverrides(finding, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "aws_securityhub",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "aws:securityhub:finding",
+	}, nil
+}
@@ -2,6 +2,7 @@ package generators
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -64,52 +65,105 @@ func (g *AWSVPCFlowGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "text",
 			Description: "Rejected outbound traffic",
 		},
+		{
+			ID:          "v5_accept_inbound",
+			Name:        "Accept Inbound (v5)",
+			Category:    "aws_vpcflow",
+			EventID:     "ACCEPT",
+			Format:      "text",
+			Description: "Accepted inbound traffic in the custom version 5 format",
+		},
+		{
+			ID:          "v5_accept_outbound",
+			Name:        "Accept Outbound (v5)",
+			Category:    "aws_vpcflow",
+			EventID:     "ACCEPT",
+			Format:      "text",
+			Description: "Accepted outbound traffic in the custom version 5 format",
+		},
+		{
+			ID:          "v5_reject_inbound",
+			Name:        "Reject Inbound (v5)",
+			Category:    "aws_vpcflow",
+			EventID:     "REJECT",
+			Format:      "text",
+			Description: "Rejected inbound traffic in the custom version 5 format",
+		},
+		{
+			ID:          "v5_reject_outbound",
+			Name:        "Reject Outbound (v5)",
+			Category:    "aws_vpcflow",
+			EventID:     "REJECT",
+			Format:      "text",
+			Description: "Rejected outbound traffic in the custom version 5 format",
+		},
 	}
 }
 
 // Generate creates an AWS VPC Flow Log event
 func (g *AWSVPCFlowGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	switch templateID {
+	version := 2
+	id := templateID
+	if strings.HasPrefix(id, "v5_") {
+		version, id = 5, strings.TrimPrefix(id, "v5_")
+	}
+	switch id {
 	case "accept_inbound":
-		return g.generateFlow("ACCEPT", "inbound", overrides)
+		return g.generateFlow(version, "ACCEPT", "inbound", overrides)
 	case "accept_outbound":
-		return g.generateFlow("ACCEPT", "outbound", overrides)
+		return g.generateFlow(version, "ACCEPT", "outbound", overrides)
 	case "reject_inbound":
-		return g.generateFlow("REJECT", "inbound", overrides)
+		return g.generateFlow(version, "REJECT", "inbound", overrides)
 	case "reject_outbound":
-		return g.generateFlow("REJECT", "outbound", overrides)
+		return g.generateFlow(version, "REJECT", "outbound", overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
 }
 
-func (g *AWSVPCFlowGenerator) randomAccountID() string {
-	return fmt.Sprintf("%012d", g.RandomInt(100000000000, 999999999999))
+// flowLogColumns are the fields of the default version 2 format and of a
+// custom version 5 format with every field, in log order
+var flowLogColumns = map[int][]string{
+	2: {"version", "account_id", "interface_id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol", "packets", "bytes", "start", "end", "action", "log_status"},
+	5: {"version", "account_id", "interface_id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol", "packets", "bytes", "start", "end", "action", "log_status",
+		"vpc_id", "subnet_id", "instance_id", "tcp_flags", "type", "pkt_srcaddr", "pkt_dstaddr", "region", "az_id", "sublocation_type", "sublocation_id",
+		"pkt_src_aws_service", "pkt_dst_aws_service", "flow_direction", "traffic_path"},
 }
 
-func (g *AWSVPCFlowGenerator) randomENI() string {
-	return fmt.Sprintf("eni-%s", g.RandomString(17))
+// flowLogLine renders fields as a space separated flow log record, with "-"
+// for fields that have no value
+func flowLogLine(version int, fields map[string]interface{}) string {
+	columns := flowLogColumns[version]
+	values := make([]string, len(columns))
+	for i, c := range columns {
+		values[i] = "-"
+		if v, ok := fields[c]; ok && v != nil && fmt.Sprint(v) != "" {
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(values, " ")
 }
 
-func (g *AWSVPCFlowGenerator) generateFlow(action, direction string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+// generateFlow creates a flow record for an interface of one of the AWS
+// environment's instances. Version 5 records add the VPC, subnet, instance,
+// TCP flags, availability zone and flow direction.
+func (g *AWSVPCFlowGenerator) generateFlow(version int, action, direction string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
-	accountID := g.randomAccountID()
+	account := AWS.RandomAccount(g.rnd)
+	instance := account.RandomInstance(g.rnd)
 
 	var srcAddr, dstAddr string
 	var srcPort, dstPort int
-
 	if direction == "inbound" {
 		srcAddr = g.RandomIPv4External()
-		dstAddr = g.RandomIPv4Internal()
+		dstAddr = instance.PrivateIP
 		srcPort = g.RandomPort()
-		dstPortStr := g.RandomChoice([]string{"22", "443", "80", "3389", "3306"})
-		fmt.Sscanf(dstPortStr, "%d", &dstPort)
+		fmt.Sscanf(g.RandomChoice([]string{"22", "443", "80", "3389", "3306"}), "%d", &dstPort)
 	} else {
-		srcAddr = g.RandomIPv4Internal()
+		srcAddr = instance.PrivateIP
 		dstAddr = g.RandomIPv4External()
 		srcPort = g.RandomPort()
-		dstPortStr := g.RandomChoice([]string{"443", "80", "53", "123"})
-		fmt.Sscanf(dstPortStr, "%d", &dstPort)
+		fmt.Sscanf(g.RandomChoice([]string{"443", "80", "53", "123"}), "%d", &dstPort)
 	}
 
 	protocol := g.RandomChoice([]string{"6", "17"}) // TCP or UDP
@@ -117,28 +171,11 @@ func (g *AWSVPCFlowGenerator) generateFlow(action, direction string, overrides m
 	bytes := packets * g.RandomInt(40, 1500)
 	startTime := timestamp.Add(-time.Duration(g.RandomInt(1, 60)) * time.Second).Unix()
 	endTime := timestamp.Unix()
-	eni := g.randomENI()
-
-	// VPC Flow Log format version 2
-	rawEvent := fmt.Sprintf("2 %s %s %s %s %d %d %s %d %d %d %d %s -",
-		accountID,
-		eni,
-		srcAddr,
-		dstAddr,
-		srcPort,
-		dstPort,
-		protocol,
-		packets,
-		bytes,
-		startTime,
-		endTime,
-		action,
-	)
 
 	fields := map[string]interface{}{
-		"version":      2,
-		"account_id":   accountID,
-		"interface_id": eni,
+		"version":      version,
+		"account_id":   account.ID,
+		"interface_id": instance.ENI,
 		"srcaddr":      srcAddr,
 		"dstaddr":      dstAddr,
 		"srcport":      srcPort,
@@ -152,6 +189,34 @@ func (g *AWSVPCFlowGenerator) generateFlow(action, direction string, overrides m
 		"log_status":   "OK",
 	}
 
+	if version == 5 {
+		tcpFlags := 0
+		if protocol == "6" {
+			tcpFlags = 19 // SYN, ACK and FIN
+			if action == "REJECT" {
+				tcpFlags = 2 // SYN only
+			}
+		}
+		fields["vpc_id"] = account.VPCID
+		fields["subnet_id"] = instance.SubnetID
+		fields["instance_id"] = instance.ID
+		fields["tcp_flags"] = tcpFlags
+		fields["type"] = "IPv4"
+		fields["pkt_srcaddr"] = srcAddr
+		fields["pkt_dstaddr"] = dstAddr
+		fields["region"] = account.Region
+		fields["az_id"] = instance.AZID
+		fields["sublocation_type"] = "-"
+		fields["sublocation_id"] = "-"
+		fields["pkt_src_aws_service"] = "-"
+		fields["pkt_dst_aws_service"] = "-"
+		fields["flow_direction"] = ingressOrEgress(direction)
+		fields["traffic_path"] = "-"
+		if direction == "outbound" {
+			fields["traffic_path"] = 8 // Through an internet gateway
+		}
+	}
+
 	fields = g.ApplyOverrides(fields, overrides)
 
 	return &models.GeneratedEvent{
@@ -159,8 +224,16 @@ func (g *AWSVPCFlowGenerator) generateFlow(action, direction string, overrides m
 		Type:       "aws_vpcflow",
 		EventID:    action,
 		Timestamp:  timestamp,
-		RawEvent:   rawEvent,
+		RawEvent:   flowLogLine(version, fields),
 		Fields:     fields,
 		Sourcetype: "aws:cloudwatchlogs:vpcflow",
 	}, nil
 }
+
+// ingressOrEgress names a direction as the flow-direction field does
+func ingressOrEgress(direction string) string {
+	if direction == "inbound" {
+		return "ingress"
+	}
+	return "egress"
+}
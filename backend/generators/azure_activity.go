@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -123,7 +122,8 @@ func (g *AzureActivityGenerator) randomTenantID() string {
 }
 
 func (g *AzureActivityGenerator) randomPrincipalName() string {
-	names := []string{"admin@contoso.com", "devops@contoso.com", "security@contoso.com", "ServicePrincipal-Deploy", "ManagedIdentity-VM"}
+	domain := Theme().Domain
+	names := []string{"admin@" + domain, "devops@" + domain, "security@" + domain, "ServicePrincipal-Deploy", "ManagedIdentity-VM"}
 	return g.RandomChoice(names)
 }
 
@@ -176,7 +176,7 @@ func (g *AzureActivityGenerator) generateVMCreate(overrides map[string]interface
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -204,7 +204,7 @@ func (g *AzureActivityGenerator) generateVMDelete(overrides map[string]interface
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -235,7 +235,7 @@ func (g *AzureActivityGenerator) generateRoleAssignment(overrides map[string]int
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -274,7 +274,7 @@ func (g *AzureActivityGenerator) generateNSGRuleCreate(overrides map[string]inte
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -304,7 +304,7 @@ func (g *AzureActivityGenerator) generateStorageKeyRegen(overrides map[string]in
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -330,12 +330,12 @@ func (g *AzureActivityGenerator) generateKeyVaultSecretGet(overrides map[string]
 	event["properties"] = map[string]interface{}{
 		"statusCode":      "OK",
 		"requestUri":      fmt.Sprintf("https://%s.vault.azure.net/secrets/%s?api-version=7.3", vaultName, secretName),
-		"id":              fmt.Sprintf("https://%s.vault.azure.net/secrets/%s/%s", vaultName, secretName, g.RandomString(32)),
+		"id":              fmt.Sprintf("https://%s.vault.azure.net/secrets/%s/%s", vaultName, secretName, g.RandomHex(16)),
 		"clientInfo":      "AzureCLI/2.50.0",
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -109,12 +108,8 @@ func (g *AzureADSignInGenerator) randomTenantID() string {
 }
 
 func (g *AzureADSignInGenerator) randomUser() (string, string, string) {
-	firstNames := []string{"John", "Jane", "Bob", "Alice", "Charlie", "Diana"}
-	lastNames := []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia"}
-	firstName := g.RandomChoice(firstNames)
-	lastName := g.RandomChoice(lastNames)
-	domain := g.RandomChoice([]string{"contoso.com", "fabrikam.com", "company.onmicrosoft.com"})
-	return fmt.Sprintf("%s %s", firstName, lastName), fmt.Sprintf("%s.%s@%s", firstName, lastName, domain), uuid.New().String()
+	p := g.RandomPerson()
+	return p.DisplayName, p.UPN, uuid.New().String()
 }
 
 func (g *AzureADSignInGenerator) randomApplication() (string, string) {
@@ -217,7 +212,7 @@ func (g *AzureADSignInGenerator) generateInteractiveSuccess(overrides map[string
 	event := g.buildBaseEvent()
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -253,7 +248,7 @@ func (g *AzureADSignInGenerator) generateInteractiveFailure(overrides map[string
 	event["authenticationDetails"].([]map[string]interface{})[0]["succeeded"] = false
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -285,7 +280,7 @@ func (g *AzureADSignInGenerator) generateMFAChallenge(overrides map[string]inter
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -319,7 +314,7 @@ func (g *AzureADSignInGenerator) generateConditionalAccessBlock(overrides map[st
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -345,7 +340,7 @@ func (g *AzureADSignInGenerator) generateRiskySignin(overrides map[string]interf
 	event["riskState"] = "atRisk"
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -379,7 +374,7 @@ func (g *AzureADSignInGenerator) generateServicePrincipal(overrides map[string]i
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -0,0 +1,373 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// AzureCostGenerator generates Azure Cost Management usage details exports,
+// cost anomaly alerts and budget alerts
+type AzureCostGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&AzureCostGenerator{})
+}
+
+// azureSubscription is a subscription whose costs are exported. Its ID is
+// fixed so a day's rows add up per subscription.
+type azureSubscription struct {
+	id            string
+	name          string
+	resourceGroup string
+	location      string
+	costCenter    string
+	scale         float64 // Share of the production subscription's spend
+}
+
+var azureSubscriptions = []azureSubscription{
+	{"3f6b2a91-5c0e-4d7a-9b18-c2e4f7a1d053", "sub-production", "rg-prod-eastus", "eastus", "CC-1100", 1},
+	{"8a1d4e27-06b3-4f5c-a9e2-71d3b8c0f4a6", "sub-development", "rg-dev-westus", "westus2", "CC-2300", 0.3},
+	{"c52e9f13-7b4a-4e08-8d61-0f9a2c3b7e85", "sub-security", "rg-security", "northeurope", "CC-4100", 0.15},
+}
+
+// azureMeter is a charge the subscriptions run up every day
+type azureMeter struct {
+	category    string // MeterCategory, the service
+	subCategory string
+	name        string
+	unit        string
+	provider    string // Resource provider and type of the charged resource
+	resource    string // Charged resource name prefix
+	price       float64
+	daily       float64 // Quantity of a day in the production subscription
+}
+
+// azureMeters are the subscriptions' daily charges
+var azureMeters = []azureMeter{
+	{"Virtual Machines", "Dv5 Series", "D4s v5", "1 Hour", "Microsoft.Compute/virtualMachines", "vm-app", 0.192, 24 * 5},
+	{"Storage", "Premium SSD Managed Disks", "P10 LRS Disk", "1/Month", "Microsoft.Compute/disks", "disk-app", 19.71, 5.0 / 30},
+	{"Storage", "Tiered Block Blob", "Hot LRS Data Stored", "1 GB/Month", "Microsoft.Storage/storageAccounts", "stdata", 0.0184, 40000.0 / 30},
+	{"Bandwidth", "Rtn Preference: MGN", "Standard Data Transfer Out", "1 GB", "Microsoft.Compute/virtualMachines", "vm-app", 0.087, 90},
+	{"SQL Database", "Single General Purpose", "vCore", "1 Hour", "Microsoft.Sql/servers", "sql-orders", 0.2523, 24 * 4},
+	{"Azure App Service", "Premium v3 Plan", "P1 v3 App", "1 Hour", "Microsoft.Web/serverfarms", "asp-web", 0.169, 24 * 2},
+	{"Functions", "Standard", "Total Executions", "10", "Microsoft.Web/sites", "func-etl", 0.000002, 2000000},
+	{"Log Analytics", "Analytics Logs", "Data Ingestion", "1 GB", "Microsoft.OperationalInsights/workspaces", "law-central", 2.76, 25},
+	{"Microsoft Defender for Cloud", "Standard", "Standard Node", "1 Hour", "Microsoft.Compute/virtualMachines", "vm-app", 0.02, 24 * 5},
+}
+
+// azureSpike is a spend spike and its cause. A spike in a location the
+// subscription does not use has no expected spend.
+type azureSpike struct {
+	azureMeter
+	location string  // Empty for the subscription's own location
+	factor   float64 // Quantity as a multiple of the meter's daily quantity
+}
+
+// azureSpikes are spikes a security team would want to see: mining on GPU
+// VMs in an unused location, bulk data transfer out, a flood of function
+// executions, and runaway log ingestion
+var azureSpikes = []azureSpike{
+	{azureMeter{"Virtual Machines", "NCv3 Series", "NC24s v3", "1 Hour", "Microsoft.Compute/virtualMachines", "vm-gpu", 12.24, 24 * 8}, "southeastasia", 1},
+	{azureMeter{"Virtual Machines", "NDasrA100 v4 Series", "ND96asr A100 v4", "1 Hour", "Microsoft.Compute/virtualMachines", "vm-gpu", 27.197, 24 * 4}, "brazilsouth", 1},
+	{azureMeters[3], "", 60},
+	{azureMeter{"Bandwidth", "Rtn Preference: MGN", "Standard Data Transfer Out", "1 GB", "Microsoft.Storage/storageAccounts", "stdata", 0.087, 90}, "", 80},
+	{azureMeters[6], "", 40},
+	{azureMeters[7], "", 25},
+}
+
+// GetEventType returns the event type for Azure cost data
+func (g *AzureCostGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "azure_cost",
+		Name:        "Azure Cost Management",
+		Category:    "cloud",
+		Description: "Azure Cost Management usage details, cost anomaly alerts and budget alerts",
+		EventIDs:    []string{"UsageDetails", "CostAnomaly", "Budget"},
+	}
+}
+
+// GetTemplates returns available templates for Azure cost data
+func (g *AzureCostGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "usage_daily",
+			Name:        "Daily Usage Detail",
+			Category:    "azure_cost",
+			EventID:     "UsageDetails",
+			Format:      "json",
+			Description: "Cost details export row for a day's usage of a meter in one subscription",
+			Sourcetype:  "azure:costmanagement:usage",
+		},
+		{
+			ID:          "usage_spike",
+			Name:        "Anomalous Usage Detail",
+			Category:    "azure_cost",
+			EventID:     "UsageDetails",
+			Format:      "json",
+			Description: "Cost details export row for a spend spike: GPU VMs in an unused location, data transfer out, function executions or log ingestion",
+			Sourcetype:  "azure:costmanagement:usage",
+		},
+		{
+			ID:          "cost_anomaly",
+			Name:        "Cost Anomaly Alert",
+			Category:    "azure_cost",
+			EventID:     "CostAnomaly",
+			Format:      "json",
+			Description: "Anomaly alert raised by Cost Management with the meter and resource behind the spike",
+			Sourcetype:  "azure:costmanagement:alert",
+		},
+		{
+			ID:          "budget_alert",
+			Name:        "Budget Alert",
+			Category:    "azure_cost",
+			EventID:     "Budget",
+			Format:      "json",
+			Description: "Budget threshold crossed by a subscription's month-to-date spend",
+			Sourcetype:  "azure:costmanagement:alert",
+		},
+	}
+}
+
+// Generate creates an Azure cost event
+func (g *AzureCostGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "usage_daily":
+		return g.generateUsageDaily(overrides)
+	case "usage_spike":
+		return g.generateUsageSpike(overrides)
+	case "cost_anomaly":
+		return g.generateCostAnomaly(overrides)
+	case "budget_alert":
+		return g.generateBudgetAlert(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+func (g *AzureCostGenerator) randomSubscription() azureSubscription {
+	return azureSubscriptions[g.RandomInt(0, len(azureSubscriptions)-1)]
+}
+
+// generateUsageDaily creates a row for a normal day's usage, within a
+// tenth of the meter's usual quantity
+func (g *AzureCostGenerator) generateUsageDaily(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	sub := g.randomSubscription()
+	meter := azureMeters[g.RandomInt(0, len(azureMeters)-1)]
+	quantity := meter.daily * sub.scale * g.RandomFloat(0.9, 1.1)
+	return g.event("UsageDetails", usageDay(), g.usageRow(sub, sub.location, meter, quantity), overrides)
+}
+
+// generateUsageSpike creates a row for a spend spike
+func (g *AzureCostGenerator) generateUsageSpike(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	sub := g.randomSubscription()
+	spike := azureSpikes[g.RandomInt(0, len(azureSpikes)-1)]
+	location := spike.location
+	quantity := spike.daily * g.RandomFloat(0.8, 1.3)
+	if location == "" {
+		location = sub.location
+		quantity *= spike.factor * sub.scale
+	}
+	return g.event("UsageDetails", usageDay(), g.usageRow(sub, location, spike.azureMeter, quantity), overrides)
+}
+
+// resourceID is the ID of the meter's resource in the subscription
+func (g *AzureCostGenerator) resourceID(sub azureSubscription, location string, meter azureMeter) string {
+	name := fmt.Sprintf("%s-%s-%s", meter.resource, strings.TrimPrefix(sub.name, "sub-"), location)
+	if meter.provider == "Microsoft.Storage/storageAccounts" {
+		// Storage account names are lowercase letters and digits only
+		name = meter.resource + strings.TrimPrefix(sub.name, "sub-")[:4] + sub.id[:6]
+	}
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", sub.id, sub.resourceGroup, meter.provider, name)
+}
+
+// usageRow builds a cost details export row for a day's quantity of meter
+// in sub and location
+func (g *AzureCostGenerator) usageRow(sub azureSubscription, location string, meter azureMeter, quantity float64) map[string]interface{} {
+	day := usageDay()
+	periodStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	quantity = math.Round(quantity*1e4) / 1e4
+	cost := roundCost(quantity * meter.price)
+	resourceID := g.resourceID(sub, location, meter)
+	owner := Entities.RandomUser(g.rnd).Email
+
+	return map[string]interface{}{
+		"BillingAccountName":     Theme().Domain,
+		"BillingPeriodStartDate": periodStart.Format("01/02/2006"),
+		"BillingPeriodEndDate":   periodStart.AddDate(0, 1, -1).Format("01/02/2006"),
+		"Date":                   day.Format("01/02/2006"),
+		"SubscriptionId":         sub.id,
+		"SubscriptionName":       sub.name,
+		"ResourceGroup":          sub.resourceGroup,
+		"ResourceLocation":       location,
+		"ResourceId":             resourceID,
+		"ResourceName":           resourceID[strings.LastIndex(resourceID, "/")+1:],
+		"ConsumedService":        meter.provider[:strings.Index(meter.provider, "/")],
+		"MeterId":                uuid.NewSHA1(uuid.NameSpaceOID, []byte(meter.category+meter.name)).String(),
+		"MeterCategory":          meter.category,
+		"MeterSubCategory":       meter.subCategory,
+		"MeterName":              meter.name,
+		"UnitOfMeasure":          meter.unit,
+		"Quantity":               quantity,
+		"EffectivePrice":         meter.price,
+		"UnitPrice":              meter.price,
+		"PayGPrice":              meter.price,
+		"CostInBillingCurrency":  cost,
+		"CostInUsd":              cost,
+		"BillingCurrency":        "USD",
+		"ChargeType":             "Usage",
+		"Frequency":              "UsageBased",
+		"PricingModel":           "OnDemand",
+		"PublisherType":          "Azure",
+		"CostCenter":             sub.costCenter,
+		"Tags": map[string]interface{}{
+			"environment": strings.TrimPrefix(sub.name, "sub-"),
+			"owner":       owner,
+		},
+	}
+}
+
+// generateCostAnomaly creates an anomaly alert for one of the spikes
+func (g *AzureCostGenerator) generateCostAnomaly(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	sub := g.randomSubscription()
+	spike := azureSpikes[g.RandomInt(0, len(azureSpikes)-1)]
+	location := spike.location
+
+	expected := 0.0
+	actual := spike.daily * spike.price * g.RandomFloat(0.8, 1.3)
+	if location == "" {
+		location = sub.location
+		expected = spike.daily * spike.price * sub.scale
+		actual *= spike.factor * sub.scale
+	}
+	delta := 0.0
+	if expected > 0 {
+		delta = math.Round((actual-expected)/expected*1e4) / 100
+	}
+
+	alertID := uuid.New().String()
+	scope := "/subscriptions/" + sub.id
+	alert := map[string]interface{}{
+		"id":   scope + "/providers/Microsoft.CostManagement/alerts/" + alertID,
+		"name": alertID,
+		"type": "Microsoft.CostManagement/alerts",
+		"properties": map[string]interface{}{
+			"definition": map[string]interface{}{
+				"type":     "Anomaly",
+				"category": "Cost",
+				"criteria": "CostThresholdExceeded",
+			},
+			"description": fmt.Sprintf("Daily run rate for %s in %s increased unexpectedly", spike.category, sub.name),
+			"source":      "Preset",
+			"details": map[string]interface{}{
+				"anomalyDate":      usageDay().Format("2006-01-02"),
+				"resourceGroup":    sub.resourceGroup,
+				"resourceId":       g.resourceID(sub, location, spike.azureMeter),
+				"resourceLocation": location,
+				"meterCategory":    spike.category,
+				"meterSubCategory": spike.subCategory,
+				"meterName":        spike.name,
+				"actualCost":       math.Round(actual*100) / 100,
+				"expectedCost":     math.Round(expected*100) / 100,
+				"deltaPercentage":  delta,
+				"unit":             "USD",
+				"timeGrainType":    "Daily",
+				"subscriptionId":   sub.id,
+				"subscriptionName": sub.name,
+				"contactEmails":    []string{"finops@" + Theme().Domain},
+			},
+			"costEntityId":           "insight-" + g.RandomHex(4),
+			"status":                 "Active",
+			"creationTime":           timestamp.UTC().Format(time.RFC3339),
+			"modificationTime":       timestamp.UTC().Format(time.RFC3339),
+			"statusModificationTime": timestamp.UTC().Format(time.RFC3339),
+		},
+	}
+	return g.event("CostAnomaly", timestamp, alert, overrides)
+}
+
+// generateBudgetAlert creates an alert for a subscription's month-to-date
+// spend crossing a threshold of its monthly budget
+func (g *AzureCostGenerator) generateBudgetAlert(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	sub := g.randomSubscription()
+	day := usageDay()
+	periodStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	budget := math.Round(60000*sub.scale/1000) * 1000
+	threshold := []int{50, 80, 90, 100}[g.RandomInt(0, 3)]
+	spent := budget * float64(threshold) / 100 * g.RandomFloat(1, 1.08)
+	operator := "GreaterThan"
+	if threshold == 100 {
+		operator = "GreaterThanOrEqualTo"
+	}
+
+	alertID := uuid.New().String()
+	scope := "/subscriptions/" + sub.id
+	budgetName := "budget-" + strings.TrimPrefix(sub.name, "sub-") + "-monthly"
+	alert := map[string]interface{}{
+		"id":   scope + "/providers/Microsoft.CostManagement/alerts/" + alertID,
+		"name": alertID,
+		"type": "Microsoft.CostManagement/alerts",
+		"properties": map[string]interface{}{
+			"definition": map[string]interface{}{
+				"type":     "Budget",
+				"category": "Cost",
+				"criteria": "CostThresholdExceeded",
+			},
+			"description": fmt.Sprintf("Actual cost of %s reached %d%% of budget %s", sub.name, threshold, budgetName),
+			"source":      "User",
+			"details": map[string]interface{}{
+				"timeGrainType":       "Monthly",
+				"periodStartDate":     periodStart.Format(time.RFC3339),
+				"triggeredBy":         fmt.Sprintf("%s_1_%02d", alertID, threshold),
+				"resourceGroupFilter": []string{},
+				"currentSpend":        math.Round(spent*100) / 100,
+				"amount":              budget,
+				"unit":                "USD",
+				"operator":            operator,
+				"thresholdType":       "Actual",
+				"threshold":           float64(threshold) / 100,
+				"contactEmails":       []string{"finops@" + Theme().Domain, Entities.RandomUser(g.rnd).Email},
+				"contactRoles":        []string{"Owner"},
+				"budgetName":          budgetName,
+				"subscriptionId":      sub.id,
+			},
+			"costEntityId":     budgetName,
+			"status":           "Active",
+			"creationTime":     timestamp.UTC().Format(time.RFC3339),
+			"modificationTime": timestamp.UTC().Format(time.RFC3339),
+		},
+	}
+	return g.event("Budget", timestamp, alert, overrides)
+}
+
+func (g *AzureCostGenerator) event(eventID string, timestamp time.Time, record, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields := g.ApplyOverrides(record, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcetype := "azure:costmanagement:alert"
+	if eventID == "UsageDetails" {
+		sourcetype = "azure:costmanagement:usage"
+	}
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "azure_cost",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: sourcetype,
+	}, nil
+}
@@ -0,0 +1,410 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// BackupJobGenerator generates Veeam Backup & Replication and Commvault job
+// and audit events. Protected clients are the pool's servers and operators
+// are its IT staff, so a mass restore or a deleted backup chain can be
+// tied to the account that ran it.
+type BackupJobGenerator struct {
+	BaseGenerator
+	mu  sync.Mutex
+	job int // Last Commvault job ID
+}
+
+func init() {
+	Register(&BackupJobGenerator{job: 2841000})
+}
+
+// backupJob is a scheduled job, the kind of data it protects and the
+// Commvault agent that protects it
+type backupJob struct {
+	name    string
+	agent   string
+	objects string // What Veeam calls the job's objects
+}
+
+var backupJobs = []backupJob{
+	{"Daily - File Servers", "Windows File System", "VMs"},
+	{"Daily - Databases", "SQL Server", "VMs"},
+	{"Hourly - SQL Logs", "SQL Server", "databases"},
+	{"Daily - Web Tier", "Virtual Server", "VMs"},
+	{"Weekly - Full", "Virtual Server", "VMs"},
+	{"Daily - Linux Servers", "Linux File System", "VMs"},
+}
+
+// GetEventType returns the event type for backup job events
+func (g *BackupJobGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "backup_job",
+		Name:        "Backup Job Logs",
+		Category:    "infrastructure",
+		Description: "Veeam Backup & Replication and Commvault job results, restores and audit events such as deleted backups and disabled data aging",
+		EventIDs:    []string{"190", "210", "10050", "Backup", "Restore", "Audit"},
+	}
+}
+
+// GetTemplates returns available templates for backup job events
+func (g *BackupJobGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "veeam_job_finished",
+			Name:        "Veeam Backup Job Finished",
+			Category:    "backup_job",
+			EventID:     "190",
+			Format:      "syslog",
+			Description: "A backup job finishes with Success, Warning or Failed",
+			Sourcetype:  "veeam:vbr:syslog",
+		},
+		{
+			ID:          "veeam_restore",
+			Name:        "Veeam Restore Session",
+			Category:    "backup_job",
+			EventID:     "210",
+			Format:      "syslog",
+			Description: "An operator restores VMs or files, sometimes in bulk",
+			Sourcetype:  "veeam:vbr:syslog",
+		},
+		{
+			ID:          "veeam_backup_deleted",
+			Name:        "Veeam Backup Deleted",
+			Category:    "backup_job",
+			EventID:     "10050",
+			Format:      "syslog",
+			Description: "An operator deletes a job's backups or restore points from a repository",
+			Sourcetype:  "veeam:vbr:syslog",
+		},
+		{
+			ID:          "commvault_job",
+			Name:        "Commvault Backup Job",
+			Category:    "backup_job",
+			EventID:     "Backup",
+			Format:      "json",
+			Description: "A backup job of a client completes, completes with errors or fails",
+			Sourcetype:  "commvault:job",
+		},
+		{
+			ID:          "commvault_restore",
+			Name:        "Commvault Restore Job",
+			Category:    "backup_job",
+			EventID:     "Restore",
+			Format:      "json",
+			Description: "A restore job, sometimes of tens of thousands of files to another client",
+			Sourcetype:  "commvault:job",
+		},
+		{
+			ID:          "commvault_audit",
+			Name:        "Commvault Audit Trail",
+			Category:    "backup_job",
+			EventID:     "Audit",
+			Format:      "json",
+			Description: "A CommCell audit trail entry: deleted jobs, disabled data aging or backup activity, changed retention",
+			Sourcetype:  "commvault:audit",
+		},
+	}
+}
+
+// Generate creates a backup job event
+func (g *BackupJobGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "veeam_job_finished":
+		return g.generateVeeamJob(overrides)
+	case "veeam_restore":
+		return g.generateVeeamRestore(overrides)
+	case "veeam_backup_deleted":
+		return g.generateVeeamDeleted(overrides)
+	case "commvault_job":
+		return g.generateCommvaultJob(overrides)
+	case "commvault_restore":
+		return g.generateCommvaultRestore(overrides)
+	case "commvault_audit":
+		return g.generateCommvaultAudit(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// veeamFieldOrder is the order of the parameters of a Veeam syslog message
+var veeamFieldOrder = []string{
+	"instanceId", "JobID", "JobName", "JobType", "JobResult", "SessionID", "ObjectsProcessed", "ObjectsTotal",
+	"TransferredBytes", "Duration", "RestoreType", "Target", "Files", "Repository", "RestorePoints", "InitiatedBy",
+	"UserName", "Description",
+}
+
+// veeamMessage renders a Veeam Backup & Replication syslog message, which
+// is RFC 5424 with the event's parameters as structured data
+func (g *BackupJobGenerator) veeamMessage(now time.Time, fields map[string]interface{}) string {
+	pairs := []string{"Veeam_MP@31023"}
+	for _, k := range veeamFieldOrder {
+		if v, ok := fields[k]; ok {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, strings.ReplaceAll(fmt.Sprint(v), `"`, `\"`)))
+		}
+	}
+	return fmt.Sprintf("<%d>1 %s %s Veeam_MP - - [%s]", fields["priority"], now.Format("2006-01-02T15:04:05.000Z07:00"),
+		ServerName("vbr-01"), strings.Join(pairs, " "))
+}
+
+func (g *BackupJobGenerator) veeamEvent(now time.Time, eventID string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields["instanceId"] = eventID
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "backup_job",
+		EventID:    eventID,
+		Timestamp:  now,
+		RawEvent:   g.veeamMessage(now, fields),
+		Fields:     fields,
+		Sourcetype: "veeam:vbr:syslog",
+	}, nil
+}
+
+// veeamJobID is the stable ID of a Veeam job
+func veeamJobID(job backupJob) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("veeam/"+job.name)).String()
+}
+
+// generateVeeamJob creates the result of a scheduled job, mostly
+// successful. A failed job processed only some of its objects.
+func (g *BackupJobGenerator) generateVeeamJob(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	job := backupJobs[g.RandomInt(0, len(backupJobs)-1)]
+	result := g.WeightedChoice([]string{"Success", "Warning", "Failed"}, []float64{82, 11, 7})
+	total := g.RandomInt(3, 40)
+	processed := total
+	if result == "Failed" {
+		processed = g.RandomInt(0, total-1)
+	}
+	duration := time.Duration(g.RandomInt(4, 180)) * time.Minute
+
+	fields := map[string]interface{}{
+		"priority":         map[string]int{"Success": 14, "Warning": 12, "Failed": 11}[result],
+		"JobID":            veeamJobID(job),
+		"JobName":          job.name,
+		"JobType":          "Backup",
+		"JobResult":        result,
+		"SessionID":        uuid.New().String(),
+		"ObjectsProcessed": processed,
+		"ObjectsTotal":     total,
+		"TransferredBytes": int64(processed) * int64(g.RandomLogNormal(6e9, 1)),
+		"Duration":         fmt.Sprintf("%02d:%02d:%02d", int(duration.Hours()), int(duration.Minutes())%60, g.RandomInt(0, 59)),
+		"Description":      fmt.Sprintf("Backup job '%s' finished with %s. %d of %d %s processed", job.name, result, processed, total, job.objects),
+	}
+	return g.veeamEvent(now, "190", fields, overrides)
+}
+
+// generateVeeamRestore creates a restore session. One in four restores
+// thousands of files or a dozen VMs, which ransomware recovery and data
+// theft both look like.
+func (g *BackupJobGenerator) generateVeeamRestore(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	job := backupJobs[g.RandomInt(0, len(backupJobs)-1)]
+	operator := Entities.RandomUserIn(g.rnd, "IT")
+	client := Entities.RandomServer(g.rnd)
+	restoreType := g.WeightedChoice([]string{"File-level restore", "Entire VM restore", "Instant VM recovery"}, []float64{60, 25, 15})
+
+	fields := map[string]interface{}{
+		"priority":    14,
+		"JobID":       veeamJobID(job),
+		"JobName":     job.name,
+		"JobType":     "Restore",
+		"SessionID":   uuid.New().String(),
+		"RestoreType": restoreType,
+		"Target":      client.FQDN,
+		"InitiatedBy": windowsNetBIOSDomain() + `\` + operator.Username,
+	}
+	mass := g.RandomInt(1, 4) == 1
+	if restoreType == "File-level restore" {
+		files := g.RandomInt(1, 40)
+		if mass {
+			files = g.RandomInt(5000, 250000)
+		}
+		fields["Files"] = files
+		fields["Description"] = fmt.Sprintf("Restore session started by %s: %d file(s) of %s from '%s'", fields["InitiatedBy"], files, client.Hostname, job.name)
+	} else {
+		vms := 1
+		if mass {
+			vms = g.RandomInt(8, 40)
+		}
+		fields["ObjectsTotal"] = vms
+		fields["Description"] = fmt.Sprintf("%s session started by %s: %d VM(s) from '%s'", restoreType, fields["InitiatedBy"], vms, job.name)
+	}
+	return g.veeamEvent(now, "210", fields, overrides)
+}
+
+// generateVeeamDeleted creates a deletion of a job's backups from disk, or
+// of its oldest restore points, as is done before encrypting a network
+func (g *BackupJobGenerator) generateVeeamDeleted(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	job := backupJobs[g.RandomInt(0, len(backupJobs)-1)]
+	operator := Entities.RandomUserIn(g.rnd, "IT")
+	repository := g.RandomChoice([]string{"Default Backup Repository", "SOBR-Primary", "NAS-Repo-01", "Hardened Linux Repo"})
+	points := g.RandomInt(7, 90)
+
+	fields := map[string]interface{}{
+		"priority":      12,
+		"JobID":         veeamJobID(job),
+		"JobName":       job.name,
+		"Repository":    repository,
+		"RestorePoints": points,
+		"UserName":      windowsNetBIOSDomain() + `\` + operator.Username,
+	}
+	if g.RandomInt(0, 1) == 0 {
+		fields["Description"] = fmt.Sprintf("Backup '%s' has been deleted from disk by %s: %d restore points removed from %s", job.name, fields["UserName"], points, repository)
+	} else {
+		fields["Description"] = fmt.Sprintf("Restore points of '%s' have been deleted by %s: %d restore points removed from %s", job.name, fields["UserName"], points, repository)
+	}
+	return g.veeamEvent(now, "10050", fields, overrides)
+}
+
+// nextJob returns a new Commvault job ID
+func (g *BackupJobGenerator) nextJob() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.job++
+	return g.job
+}
+
+// commvaultJob builds the fields of a job record as the CommServe's REST
+// API returns it
+func (g *BackupJobGenerator) commvaultJob(start, end time.Time, jobType string, client *EntityHost, job backupJob) map[string]interface{} {
+	return map[string]interface{}{
+		"jobId":          g.nextJob(),
+		"jobType":        jobType,
+		"commcell":       themedName("commserve"),
+		"clientName":     client.Hostname,
+		"appTypeName":    job.agent,
+		"backupSetName":  "defaultBackupSet",
+		"subclientName":  "default",
+		"storagePolicy":  map[string]interface{}{"storagePolicyName": g.RandomChoice([]string{"SP-Disk-30d", "SP-Cloud-90d", "SP-Tape-7y"})},
+		"jobStartTime":   start.Unix(),
+		"jobEndTime":     end.Unix(),
+		"jobElapsedTime": int(end.Sub(start).Seconds()),
+	}
+}
+
+// generateCommvaultJob creates a backup job record of a client
+func (g *BackupJobGenerator) generateCommvaultJob(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	end := time.Now().UTC()
+	start := end.Add(-time.Duration(g.RandomInt(120, 14400)) * time.Second)
+	job := backupJobs[g.RandomInt(0, len(backupJobs)-1)]
+	client := Entities.RandomServer(g.rnd)
+
+	fields := g.commvaultJob(start, end, "Backup", client, job)
+	status := g.WeightedChoice([]string{"Completed", "Completed w/ one or more errors", "Failed"}, []float64{85, 9, 6})
+	size := int64(g.RandomLogNormal(4e10, 1.2))
+	files := g.RandomInt(2000, 400000)
+	fields["status"] = status
+	fields["backupLevelName"] = g.WeightedChoice([]string{"Incremental", "Differential", "Full", "Synthetic Full"}, []float64{70, 10, 10, 10})
+	fields["sizeOfApplication"] = size
+	fields["sizeOfMediaOnDisk"] = size / int64(g.RandomInt(2, 6))
+	fields["totalNumOfFiles"] = files
+	fields["totalFailedFiles"] = 0
+	fields["userName"] = map[string]interface{}{"userName": "admin"}
+	switch status {
+	case "Completed w/ one or more errors":
+		fields["totalFailedFiles"] = g.RandomInt(1, 50)
+		fields["pendingReason"] = "Some files were skipped because they were locked or could not be read"
+	case "Failed":
+		fields["sizeOfApplication"] = 0
+		fields["sizeOfMediaOnDisk"] = 0
+		fields["totalNumOfFiles"] = 0
+		fields["pendingReason"] = g.RandomChoice([]string{
+			"Failed to connect to the client computer",
+			"The library or drive is offline",
+			"Job was killed by user",
+			"Snapshot creation failed on the client",
+		})
+	}
+	return g.commvaultEvent(end, "Backup", "commvault:job", fields, overrides)
+}
+
+// generateCommvaultRestore creates a restore job. One in four restores tens
+// of thousands of files, often out of place to another client.
+func (g *BackupJobGenerator) generateCommvaultRestore(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	end := time.Now().UTC()
+	start := end.Add(-time.Duration(g.RandomInt(60, 7200)) * time.Second)
+	job := backupJobs[g.RandomInt(0, len(backupJobs)-1)]
+	client := Entities.RandomServer(g.rnd)
+	operator := Entities.RandomUserIn(g.rnd, "IT")
+
+	fields := g.commvaultJob(start, end, "Restore", client, job)
+	files := g.RandomInt(1, 200)
+	destination := client
+	if g.RandomInt(1, 4) == 1 {
+		files = g.RandomInt(20000, 500000)
+		if g.RandomInt(0, 1) == 0 {
+			destination = Entities.RandomServer(g.rnd)
+		}
+	}
+	fields["status"] = g.WeightedChoice([]string{"Completed", "Failed"}, []float64{95, 5})
+	fields["totalNumOfFiles"] = files
+	fields["sizeOfApplication"] = int64(files) * int64(g.RandomInt(40000, 900000))
+	fields["destClientName"] = destination.Hostname
+	fields["inPlace"] = destination == client
+	fields["userName"] = map[string]interface{}{"userName": windowsNetBIOSDomain() + `\` + operator.Username}
+	return g.commvaultEvent(end, "Restore", "commvault:job", fields, overrides)
+}
+
+// commvaultAuditOperations are the audit trail operations, with their
+// severity, that backup tampering detections watch for. {job}, {copy} and
+// {client} name what was changed.
+var commvaultAuditOperations = []struct {
+	operation, severity, details string
+}{
+	{"Delete Job", "High", "Job [{job}] deleted from storage policy copy [{copy}]"},
+	{"Data Aging Disabled", "High", "Data aging disabled on storage policy copy [{copy}]"},
+	{"Backup Activity Disabled", "High", "Backup activity disabled on client [{client}]"},
+	{"Retention Modified", "Medium", "Retention of storage policy copy [{copy}] changed from 30 days to 1 day"},
+	{"Login", "Low", "User logged in to CommCell Console"},
+	{"Schedule Policy Modified", "Low", "Schedule policy [System Created for {client}] modified"},
+}
+
+// generateCommvaultAudit creates a CommCell audit trail entry, mostly
+// routine but sometimes one that leaves the backups open to deletion
+func (g *BackupJobGenerator) generateCommvaultAudit(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	operator := Entities.RandomUserIn(g.rnd, "IT")
+	client := Entities.RandomServer(g.rnd)
+	op := commvaultAuditOperations[g.weightedIndex([]float64{10, 6, 6, 6, 50, 22})]
+	copyName := g.RandomChoice([]string{"SP-Disk-30d/Primary", "SP-Cloud-90d/Primary", "SP-Disk-30d/Aux Copy"})
+
+	fields := map[string]interface{}{
+		"opId":          g.RandomInt(100000, 999999),
+		"time":          now.Format("2006-01-02T15:04:05Z"),
+		"commcell":      themedName("commserve"),
+		"operation":     op.operation,
+		"severityLevel": op.severity,
+		"userName":      windowsNetBIOSDomain() + `\` + operator.Username,
+		"machine":       Entities.HostForUser(g.rnd, operator.Username).Hostname,
+		"details":       strings.NewReplacer("{job}", fmt.Sprint(g.RandomInt(2000000, 2841000)), "{copy}", copyName, "{client}", client.Hostname).Replace(op.details),
+	}
+	return g.commvaultEvent(now, "Audit", "commvault:audit", fields, overrides)
+}
+
+func (g *BackupJobGenerator) commvaultEvent(timestamp time.Time, eventID, sourcetype string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "backup_job",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: sourcetype,
+	}, nil
+}
@@ -0,0 +1,248 @@
+package generators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// BadgeAccessGenerator generates physical access control events from the
+// badge readers at each office's doors. Cardholders are pool users badging
+// in at the office their behavioral profile puts them in.
+type BadgeAccessGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&BadgeAccessGenerator{})
+}
+
+// GetEventType returns the event type for badge access events
+func (g *BadgeAccessGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "badge_access",
+		Name:        "Badge Access Control",
+		Category:    "physical",
+		Description: "Physical access control events: badge swipes granted and denied at office doors, forced and held doors",
+		EventIDs:    []string{"Access Granted", "Access Denied", "Door Forced Open", "Door Held Open"},
+	}
+}
+
+// GetTemplates returns available templates for badge access events
+func (g *BadgeAccessGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "access_granted",
+			Name:        "Access Granted",
+			Category:    "badge_access",
+			EventID:     "Access Granted",
+			Format:      "json",
+			Description: "A user on shift badges through a door at their office",
+		},
+		{
+			ID:          "access_denied",
+			Name:        "Access Denied",
+			Category:    "badge_access",
+			EventID:     "Access Denied",
+			Format:      "json",
+			Description: "A badge is refused: no access level, outside its schedule, expired, reported lost or unknown",
+		},
+		{
+			ID:          "after_hours_access",
+			Name:        "After-Hours Access",
+			Category:    "badge_access",
+			EventID:     "Access Granted",
+			Format:      "json",
+			Description: "A user badges into their office while off shift",
+		},
+		{
+			ID:          "door_forced_open",
+			Name:        "Door Forced Open",
+			Category:    "badge_access",
+			EventID:     "Door Forced Open",
+			Format:      "json",
+			Description: "A door opens without a badge swipe or exit request",
+		},
+		{
+			ID:          "door_held_open",
+			Name:        "Door Held Open",
+			Category:    "badge_access",
+			EventID:     "Door Held Open",
+			Format:      "json",
+			Description: "A door stays open past its shunt time after a badge swipe, as when someone tailgates",
+		},
+	}
+}
+
+// Generate creates a badge access event
+func (g *BadgeAccessGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "access_granted":
+		return g.generateGranted(true, overrides)
+	case "after_hours_access":
+		return g.generateGranted(false, overrides)
+	case "access_denied":
+		return g.generateDenied(overrides)
+	case "door_forced_open":
+		return g.generateDoorAlarm("Door Forced Open", overrides)
+	case "door_held_open":
+		return g.generateDoorAlarm("Door Held Open", overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// badgeNumber returns the stable card number of a pool user's badge
+func badgeNumber(u *EntityUser) int {
+	return 10000 + profileRand(u.Username+"/badge").Intn(90000)
+}
+
+// userDoor picks a door the user may badge through: any door for IT staff,
+// otherwise an unrestricted one
+func (g *BadgeAccessGenerator) userDoor(u *EntityUser) int {
+	for {
+		d := g.RandomInt(0, len(facilityDoors)-1)
+		if !facilityDoors[d].restricted || u.Department == "IT" {
+			return d
+		}
+	}
+}
+
+// restrictedDoor picks a door only IT staff may badge through
+func (g *BadgeAccessGenerator) restrictedDoor() int {
+	for {
+		d := g.RandomInt(0, len(facilityDoors)-1)
+		if facilityDoors[d].restricted {
+			return d
+		}
+	}
+}
+
+// generateGranted creates a granted swipe by a user who is on shift, or
+// off shift for after-hours access
+func (g *BadgeAccessGenerator) generateGranted(onShift bool, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	p := randomOccupant(g.rnd, now, onShift)
+	site := siteOf(p)
+	door := g.userDoor(p.user)
+
+	event := g.baseEvent(now, site, door, "Access Granted")
+	event["result"] = "granted"
+	event["direction"] = g.RandomChoice([]string{"in", "in", "out"})
+	g.addCardholder(event, p.user, site)
+	if !onShift {
+		event["schedule"] = fmt.Sprintf("%02d:00-%02d:00 %s", p.WorkStart, p.WorkEnd, p.Timezone)
+	}
+	return g.event(now, "Access Granted", event, overrides)
+}
+
+// generateDenied creates a refused swipe and the reason for it
+func (g *BadgeAccessGenerator) generateDenied(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	reasons := []string{"No Access Level", "Invalid Time Zone", "Expired Badge", "Lost/Stolen Badge", "Invalid Badge", "Anti-Passback Violation"}
+	reason := reasons[g.weightedIndex([]float64{35, 20, 15, 5, 15, 10})]
+
+	p := randomOccupant(g.rnd, now, reason != "Invalid Time Zone")
+	site := siteOf(p)
+	door := g.userDoor(p.user)
+	if reason == "No Access Level" {
+		for p.user.Department == "IT" {
+			p = randomOccupant(g.rnd, now, true)
+			site = siteOf(p)
+		}
+		door = g.restrictedDoor()
+	}
+
+	event := g.baseEvent(now, site, door, "Access Denied")
+	event["result"] = "denied"
+	event["reason"] = reason
+	event["direction"] = "in"
+	if reason == "Invalid Badge" {
+		// Unknown cards have no cardholder: cloned, or from another company
+		event["card_number"] = g.RandomInt(100000, 999999)
+		event["facility_code"] = g.RandomInt(1, 255)
+	} else {
+		g.addCardholder(event, p.user, site)
+	}
+	if reason == "Invalid Time Zone" {
+		event["schedule"] = fmt.Sprintf("%02d:00-%02d:00 %s", p.WorkStart, p.WorkEnd, p.Timezone)
+	}
+	return g.event(now, "Access Denied", event, overrides)
+}
+
+// generateDoorAlarm creates a forced or held door alarm. A held door names
+// the cardholder whose swipe opened it.
+func (g *BadgeAccessGenerator) generateDoorAlarm(eventType string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	var event map[string]interface{}
+	var site facilitySite
+	var door int
+	if eventType == "Door Held Open" {
+		p := randomOccupant(g.rnd, now, true)
+		site, door = siteOf(p), g.userDoor(p.user)
+		event = g.baseEvent(now, site, door, eventType)
+		event["priority"] = "medium"
+		event["held_seconds"] = g.RandomInt(35, 300)
+		event["shunt_seconds"] = 30
+		g.addCardholder(event, p.user, site)
+	} else {
+		site, door = randomSite(g.rnd), g.RandomInt(0, len(facilityDoors)-1)
+		event = g.baseEvent(now, site, door, eventType)
+		event["priority"] = "high"
+	}
+	event["result"] = "alarm"
+	event["camera"] = site.camera(door)
+	return g.event(now, eventType, event, overrides)
+}
+
+// baseEvent builds the fields every event of a door's reader has
+func (g *BadgeAccessGenerator) baseEvent(now time.Time, site facilitySite, door int, eventType string) map[string]interface{} {
+	d := facilityDoors[door]
+	return map[string]interface{}{
+		"timestamp":  site.local(now).Format("2006-01-02T15:04:05.000-07:00"),
+		"event_id":   uuid.New().String(),
+		"event_type": eventType,
+		"site":       site.code,
+		"location":   site.city,
+		"floor":      d.floor,
+		"door":       d.name,
+		"reader":     site.reader(door),
+		"panel":      fmt.Sprintf("%s-PNL-%02d", site.code, d.floor),
+		"panel_ip":   fmt.Sprintf("10.%d.40.%d", site.subnet, 10+d.floor),
+		"restricted": d.restricted,
+	}
+}
+
+// addCardholder adds the badge and its holder to an event
+func (g *BadgeAccessGenerator) addCardholder(event map[string]interface{}, u *EntityUser, site facilitySite) {
+	event["card_number"] = badgeNumber(u)
+	event["facility_code"] = site.subnet
+	event["cardholder"] = map[string]interface{}{
+		"username":    u.Username,
+		"name":        u.FullName,
+		"email":       u.Email,
+		"department":  u.Department,
+		"employee_id": fmt.Sprintf("E%05d", u.UID),
+	}
+}
+
+func (g *BadgeAccessGenerator) event(timestamp time.Time, eventID string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "badge_access",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "pacs:access",
+	}, nil
+}
@@ -0,0 +1,125 @@
+package generators
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// beatsVersion is the Beats and Elastic Agent release documents claim to
+// come from
+const beatsVersion = "8.12.2"
+
+// beatsAgentID is the ID of the simulated Elastic Agent, and
+// beatsEphemeralID that of its current run
+var (
+	beatsAgentID     = uuid.NewSHA1(uuid.NameSpaceOID, []byte("siem-event-generator/elastic-agent")).String()
+	beatsEphemeralID = uuid.New().String()
+)
+
+// beatsIntegration is the Fleet integration data stream an Auditbeat
+// dataset is shipped to, and the ingest pipeline the integration installs
+type beatsIntegration struct {
+	dataset  string
+	pipeline string
+}
+
+// beatsIntegrations maps the ECS event.dataset values of the Auditbeat
+// generator to their Fleet integration. Datasets without an integration
+// keep their own name and no pipeline.
+var beatsIntegrations = map[string]beatsIntegration{
+	"auditbeat.auditd":  {"auditd_manager.auditd", "logs-auditd_manager.auditd-1.16.3"},
+	"auditbeat.socket":  {"auditd_manager.auditd", "logs-auditd_manager.auditd-1.16.3"},
+	"auditbeat.file":    {"fim.event", "logs-fim.event-1.12.0"},
+	"auditbeat.package": {"system_audit.package", "logs-system_audit.package-1.8.0"},
+}
+
+// BeatsDocument returns an event as the document an Elastic Agent running
+// Beats would send, and the data stream and ingest pipeline it is indexed
+// through. ECS events keep their fields and are sent by Auditbeat; other
+// events are log lines read by Filebeat, with the raw event as the message.
+// The document's @metadata carries the beat, the target index and the
+// pipeline, as Beats send it to Logstash; Elasticsearch takes the index and
+// pipeline from the bulk action instead.
+func BeatsDocument(event *models.GeneratedEvent) (doc map[string]interface{}, index, pipeline string) {
+	beat := "filebeat"
+	dataset := strings.ReplaceAll(event.Type, "-", "_")
+	hostname := ""
+	if h, ok := event.Fields["host"].(string); ok {
+		hostname = h
+	}
+
+	doc = make(map[string]interface{}, len(event.Fields)+6)
+	var ecsEvent map[string]interface{}
+	if _, ok := event.Fields["ecs"]; ok {
+		beat = "auditbeat"
+		for k, v := range event.Fields {
+			doc[k] = v
+		}
+		// event is changed below, so the generated event's map is copied
+		ecsEvent = map[string]interface{}{}
+		if e, ok := event.Fields["event"].(map[string]interface{}); ok {
+			for k, v := range e {
+				ecsEvent[k] = v
+			}
+		}
+		if d, ok := ecsEvent["dataset"].(string); ok {
+			dataset = d
+		}
+		if host, ok := event.Fields["host"].(map[string]interface{}); ok {
+			hostname, _ = host["name"].(string)
+		}
+	} else {
+		doc["@timestamp"] = event.Timestamp.UTC().Format(time.RFC3339Nano)
+		doc["message"] = event.RawEvent
+		doc["input"] = map[string]interface{}{"type": "filestream"}
+		doc["ecs"] = map[string]interface{}{"version": "8.11.0"}
+		ecsEvent = map[string]interface{}{}
+		if hostname != "" {
+			doc["host"] = map[string]interface{}{"name": hostname}
+		}
+	}
+	if hostname == "" {
+		hostname = "siem-event-generator"
+	}
+
+	if integration, ok := beatsIntegrations[dataset]; ok {
+		dataset, pipeline = integration.dataset, integration.pipeline
+	}
+	ecsEvent["dataset"] = dataset
+	doc["event"] = ecsEvent
+
+	index = "logs-" + dataset + "-default"
+	doc["data_stream"] = map[string]interface{}{
+		"type":      "logs",
+		"dataset":   dataset,
+		"namespace": "default",
+	}
+	doc["agent"] = map[string]interface{}{
+		"type":         beat,
+		"version":      beatsVersion,
+		"name":         hostname,
+		"id":           beatsAgentID,
+		"ephemeral_id": beatsEphemeralID,
+	}
+	doc["elastic_agent"] = map[string]interface{}{
+		"id":       beatsAgentID,
+		"version":  beatsVersion,
+		"snapshot": false,
+	}
+
+	metadata := map[string]interface{}{
+		"beat":      beat,
+		"type":      "_doc",
+		"version":   beatsVersion,
+		"raw_index": index,
+	}
+	if pipeline != "" {
+		metadata["pipeline"] = pipeline
+	}
+	doc["@metadata"] = metadata
+	return doc, index, pipeline
+}
@@ -0,0 +1,707 @@
+package generators
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"siem-event-generator/models"
+)
+
+// profileOffices are the offices users work from, weighted by headcount
+var profileOffices = []struct {
+	zone, country string
+	weight        int
+}{
+	{"America/New_York", "US", 30},
+	{"America/Chicago", "US", 15},
+	{"America/Los_Angeles", "US", 15},
+	{"Europe/London", "GB", 15},
+	{"Europe/Berlin", "DE", 10},
+	{"Asia/Kolkata", "IN", 10},
+	{"Asia/Tokyo", "JP", 5},
+}
+
+// departmentBytes is the median data one event of a department's users moves
+var departmentBytes = map[string]float64{
+	"Engineering": 2 << 20,
+	"IT":          1 << 20,
+	"Marketing":   512 << 10,
+	"Finance":     256 << 10,
+	"Operations":  256 << 10,
+	"Sales":       128 << 10,
+	"Legal":       128 << 10,
+	"HR":          64 << 10,
+}
+
+// Suffixes of a field's path, lower-cased with punctuation removed, that
+// name the user an event is about and the address and place they came from
+var (
+	behaviorUserKeys = []string{"username", "user", "accountname", "userprincipalname"}
+	behaviorGeoKeys  = []string{"country", "countrycode", "countryorregion", "countryname", "city"}
+	behaviorIPKeys   = []string{"sourceip", "sourceaddress", "sourceipaddress", "ipaddress", "srcip", "srcaddr", "clientip", "callerip", "calleripaddress", "remoteip"}
+)
+
+// behaviorProfile is a user's profile with the entities it refers to
+type behaviorProfile struct {
+	models.UserProfile
+	user  *EntityUser
+	loc   *time.Location
+	hosts []*EntityHost
+}
+
+// onShift reports whether the user works at t
+func (p *behaviorProfile) onShift(t time.Time) bool {
+	local := t.In(p.loc)
+	if !p.Weekends && (local.Weekday() == time.Saturday || local.Weekday() == time.Sunday) {
+		return false
+	}
+	return local.Hour() >= p.WorkStart && local.Hour() < p.WorkEnd
+}
+
+// usesIP reports whether the user signs in from ip
+func (p *behaviorProfile) usesIP(ip string) bool {
+	for _, s := range p.SourceIPs {
+		if s == ip {
+			return true
+		}
+	}
+	return false
+}
+
+// usesHost reports whether host is one the user logs on to
+func (p *behaviorProfile) usesHost(host *EntityHost) bool {
+	for _, h := range p.hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+// profileRand seeds a user's profile from their username, so it stays the
+// same across runs and restarts
+func profileRand(username string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(username))
+	return rand.New(rand.NewSource(entitySeed ^ int64(h.Sum64())))
+}
+
+// profileIP draws an external IP allocated to country
+func profileIP(country string, r *rand.Rand) string {
+	c, ok := Geo.countries[country]
+	if !ok {
+		return Geo.Random(Unseeded).IP
+	}
+	n := c.networks[r.Intn(len(c.networks))]
+	return fmt.Sprintf("%s.%d.%d", n.prefix, r.Intn(256), 1+r.Intn(254))
+}
+
+// buildProfiles derives a profile for every user in the entity pool
+func buildProfiles() []*behaviorProfile {
+	var servers, workstations []*EntityHost
+	owned := make(map[string]*EntityHost)
+	for _, h := range Entities.Hosts() {
+		if h.Role == "server" {
+			servers = append(servers, h)
+			continue
+		}
+		workstations = append(workstations, h)
+		if _, ok := owned[h.Owner]; !ok {
+			owned[h.Owner] = h
+		}
+	}
+
+	total := 0
+	for _, o := range profileOffices {
+		total += o.weight
+	}
+	var profiles []*behaviorProfile
+	for _, u := range Entities.Users() {
+		r := profileRand(u.Username)
+		office := profileOffices[len(profileOffices)-1]
+		n := r.Intn(total)
+		for _, o := range profileOffices {
+			if n < o.weight {
+				office = o
+				break
+			}
+			n -= o.weight
+		}
+		loc, err := time.LoadLocation(office.zone)
+		if err != nil {
+			loc = time.UTC
+		}
+
+		p := &behaviorProfile{user: u, loc: loc}
+		p.Username = u.Username
+		p.Timezone = office.zone
+		p.Country = office.country
+		p.WorkStart = 7 + r.Intn(4)
+		p.WorkEnd = p.WorkStart + 8 + r.Intn(2)
+		if u.Department == "Operations" && r.Intn(3) == 0 {
+			p.WorkStart, p.WorkEnd = 14, 23
+		}
+		p.Weekends = (u.Department == "Operations" || u.Department == "IT") && r.Intn(4) == 0
+		for i := 1 + r.Intn(2); i > 0; i-- {
+			p.SourceIPs = append(p.SourceIPs, profileIP(office.country, r))
+		}
+
+		own, ok := owned[u.Username]
+		if !ok {
+			own = workstations[r.Intn(len(workstations))]
+		}
+		p.hosts = append(p.hosts, own)
+		for i := r.Intn(3); i > 0 && len(servers) > 0; i-- {
+			if s := servers[r.Intn(len(servers))]; !p.usesHost(s) {
+				p.hosts = append(p.hosts, s)
+			}
+		}
+		for _, h := range p.hosts {
+			p.Hosts = append(p.Hosts, h.Hostname)
+		}
+		p.TypicalBytes = int64(departmentBytes[u.Department] * math.Exp(0.5*r.NormFloat64()))
+		profiles = append(profiles, p)
+	}
+	return profiles
+}
+
+// UserProfiles returns the behavioral profile of every user in the entity pool
+func UserProfiles() []models.UserProfile {
+	profiles := buildProfiles()
+	out := make([]models.UserProfile, len(profiles))
+	for i, p := range profiles {
+		out[i] = p.UserProfile
+	}
+	return out
+}
+
+// UserProfileFor returns the behavioral profile of username
+func UserProfileFor(username string) (models.UserProfile, bool) {
+	for _, p := range buildProfiles() {
+		if strings.EqualFold(p.Username, username) {
+			return p.UserProfile, true
+		}
+	}
+	return models.UserProfile{}, false
+}
+
+// Behavior keeps the events of pool users within their profiles, and breaks
+// a profile at the anomaly rate or when asked to. Profiles are fixed when
+// the behavior is created.
+type Behavior struct {
+	cfg          models.BehaviorBaseline
+	kinds        []string
+	users        map[string]bool
+	profiles     []*behaviorProfile
+	byName       map[string]*behaviorProfile // Lower-cased username -> profile
+	workstations []*EntityHost
+
+	mu      sync.Mutex
+	pending []*pendingAnomaly
+}
+
+// pendingAnomaly is an injected anomaly still to be sent
+type pendingAnomaly struct {
+	profile   *behaviorProfile
+	kind      string
+	remaining int
+}
+
+// NewBehavior checks cfg and returns a behavior baseline for it
+func NewBehavior(cfg models.BehaviorBaseline) (*Behavior, error) {
+	if cfg.AnomalyRate < 0 || cfg.AnomalyRate > 1 {
+		return nil, fmt.Errorf("behavior anomaly_rate must be between 0 and 1")
+	}
+	b := &Behavior{cfg: cfg, kinds: cfg.Anomalies, byName: make(map[string]*behaviorProfile)}
+	for _, kind := range cfg.Anomalies {
+		if !validAnomaly(kind) {
+			return nil, fmt.Errorf("unknown anomaly kind %q, must be one of %s", kind, strings.Join(models.AnomalyKinds, ", "))
+		}
+	}
+	if len(b.kinds) == 0 {
+		b.kinds = models.AnomalyKinds
+	}
+	b.profiles = buildProfiles()
+	for _, p := range b.profiles {
+		b.byName[strings.ToLower(p.Username)] = p
+		for _, h := range p.hosts {
+			if h.Role == "workstation" && h.Owner == p.Username {
+				b.workstations = append(b.workstations, h)
+			}
+		}
+	}
+	if len(cfg.Users) > 0 {
+		b.users = make(map[string]bool, len(cfg.Users))
+		for _, name := range cfg.Users {
+			p, ok := b.byName[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("behavior user %q is not in the entity pool", name)
+			}
+			b.users[p.Username] = true
+		}
+	}
+	return b, nil
+}
+
+func validAnomaly(kind string) bool {
+	for _, k := range models.AnomalyKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Inject breaks the profile of req.Username in the next req.Count events
+// that name a user
+func (b *Behavior) Inject(req models.AnomalyRequest) error {
+	p, ok := b.byName[strings.ToLower(req.Username)]
+	if !ok {
+		return fmt.Errorf("user %q is not in the entity pool", req.Username)
+	}
+	if req.Kind != "" && !validAnomaly(req.Kind) {
+		return fmt.Errorf("unknown anomaly kind %q, must be one of %s", req.Kind, strings.Join(models.AnomalyKinds, ", "))
+	}
+	if req.Count == 0 {
+		req.Count = 1
+	}
+	if req.Count < 1 || req.Count > 1000 {
+		return fmt.Errorf("count must be between 1 and 1000")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, &pendingAnomaly{profile: p, kind: req.Kind, remaining: req.Count})
+	return nil
+}
+
+// behaviorEvent is what an event says about the user behind it
+type behaviorEvent struct {
+	name    string // The user's name as the event gives it
+	profile *behaviorProfile
+	host    *EntityHost
+	ips     []string  // Public source IPs
+	bytes   []float64 // Byte counts, each found once in the raw event
+	geo     []geoField
+}
+
+// geoField is a country or city field of an event's source
+type geoField struct {
+	key, value string
+	city       bool
+}
+
+// applicable reports whether an anomaly of kind can be shown in e
+func (e *behaviorEvent) applicable(kind string) bool {
+	switch kind {
+	case models.AnomalyNewCountry:
+		return len(e.ips) > 0
+	case models.AnomalyNewHost:
+		return e.host != nil
+	case models.AnomalyVolume:
+		return len(e.bytes) > 0
+	}
+	return true
+}
+
+// Apply returns a copy of event kept within the profile of its user, or
+// breaking it, and whether it breaks it. A person the pool does not know is
+// always given the same pool user; events that name no person are returned
+// as they are.
+func (b *Behavior) Apply(event *models.GeneratedEvent, rng *rand.Rand) (*models.GeneratedEvent, bool) {
+	if b == nil {
+		return event, false
+	}
+	e := b.inspect(event)
+	if e.profile == nil {
+		return event, false
+	}
+
+	profile, kind, injected := e.profile, "", false
+	if p := b.takePending(e, rng); p != nil {
+		profile, kind, injected = p.profile, p.kind, true
+	} else if b.cfg.AnomalyRate > 0 && rng.Float64() < b.cfg.AnomalyRate && (b.users == nil || b.users[profile.Username]) {
+		var kinds []string
+		for _, k := range b.kinds {
+			if e.applicable(k) {
+				kinds = append(kinds, k)
+			}
+		}
+		if len(kinds) > 0 {
+			kind = kinds[rng.Intn(len(kinds))]
+		}
+	}
+	// Off shift, someone who is at work does what the user would have
+	if !injected && kind != models.AnomalyOffHours && !profile.onShift(event.Timestamp) {
+		var working []*behaviorProfile
+		for _, p := range b.profiles {
+			if p.onShift(event.Timestamp) {
+				working = append(working, p)
+			}
+		}
+		if len(working) > 0 {
+			profile = working[rng.Intn(len(working))]
+		}
+	}
+
+	var pairs []string
+	if e.name != profile.Username {
+		pairs = append(pairs, e.name, profile.Username)
+		fullName := e.profile.user.FullName
+		if !strings.EqualFold(e.name, e.profile.Username) {
+			first, last, _ := strings.Cut(strings.ToLower(e.name), ".")
+			fullName = capitalize(first) + " " + capitalize(last)
+		}
+		pairs = append(pairs, fullName, profile.user.FullName)
+	}
+	if e.host != nil {
+		host := e.host
+		if kind == models.AnomalyNewHost {
+			for _, i := range rng.Perm(len(b.workstations)) {
+				if !profile.usesHost(b.workstations[i]) {
+					host = b.workstations[i]
+					break
+				}
+			}
+		} else if host.Role == "workstation" && !profile.usesHost(host) {
+			host = profile.hosts[0]
+		}
+		if host != e.host {
+			pairs = append(pairs,
+				e.host.FQDN, host.FQDN,
+				e.host.Hostname, host.Hostname,
+				strings.ToLower(e.host.Hostname), strings.ToLower(host.Hostname),
+				strings.ToUpper(e.host.Hostname), strings.ToUpper(host.Hostname),
+				e.host.IP, host.IP)
+		}
+	}
+
+	geo := make(map[string]string)
+	if len(e.ips) > 0 {
+		ip := profile.SourceIPs[rng.Intn(len(profile.SourceIPs))]
+		if kind == models.AnomalyNewCountry {
+			ip = b.foreignIP(profile, rng)
+		}
+		for _, old := range e.ips {
+			if old != ip && (kind == models.AnomalyNewCountry || !profile.usesIP(old)) {
+				pairs = append(pairs, old, ip)
+			}
+		}
+		// The source's country and city follow its IP. In the raw event
+		// they are replaced along with their key, as destination fields
+		// can hold the same values.
+		if to, ok := Geo.Lookup(ip); ok {
+			for _, f := range e.geo {
+				v := to.CountryCode
+				if f.city {
+					v = to.City
+				} else if len(f.value) > 3 {
+					v = to.CountryName
+				}
+				if v == f.value {
+					continue
+				}
+				geo[f.value] = v
+				for _, form := range []string{`"%s":"%s"`, `"%s": "%s"`, `%s="%s"`, `%s=%s`} {
+					pairs = append(pairs, fmt.Sprintf(form, f.key, f.value), fmt.Sprintf(form, f.key, v))
+				}
+			}
+		}
+	}
+
+	factor := 1.0
+	if len(e.bytes) > 0 {
+		sum := 0.0
+		for _, v := range e.bytes {
+			sum += v
+		}
+		target := float64(profile.TypicalBytes) * math.Exp(0.6*rng.NormFloat64())
+		if kind == models.AnomalyVolume {
+			target = float64(profile.TypicalBytes) * (50 + 150*rng.Float64())
+		}
+		factor = target / sum
+		for _, v := range e.bytes {
+			pairs = append(pairs, formatBytes(v), formatBytes(math.Round(v*factor)))
+		}
+	}
+
+	out := rewriteBehavior(event, pairs, geo, factor)
+	if kind == models.AnomalyOffHours {
+		out = shiftEvent(out, offShift(profile, out.Timestamp, rng).Sub(out.Timestamp))
+	}
+	if kind != "" && b.cfg.Label {
+		if out == event {
+			copied := *event
+			out = &copied
+		}
+		fields := make(map[string]interface{}, len(out.Fields)+1)
+		for k, v := range out.Fields {
+			fields[k] = v
+		}
+		fields["ueba_anomaly"] = kind
+		out.Fields = fields
+	}
+	return out, kind != ""
+}
+
+// takePending returns the first injected anomaly that e can show, counting
+// it off. An anomaly of no kind gets one e can show.
+func (b *Behavior) takePending(e *behaviorEvent, rng *rand.Rand) *pendingAnomaly {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, p := range b.pending {
+		kind := p.kind
+		if kind == "" {
+			var kinds []string
+			for _, k := range b.kinds {
+				if e.applicable(k) {
+					kinds = append(kinds, k)
+				}
+			}
+			if len(kinds) == 0 {
+				continue
+			}
+			kind = kinds[rng.Intn(len(kinds))]
+		} else if !e.applicable(kind) {
+			continue
+		}
+		if p.remaining--; p.remaining == 0 {
+			b.pending = append(b.pending[:i], b.pending[i+1:]...)
+		}
+		return &pendingAnomaly{profile: p.profile, kind: kind}
+	}
+	return nil
+}
+
+// foreignIP draws an IP from a country the user never signs in from,
+// preferring the geo policy's malicious countries
+func (b *Behavior) foreignIP(p *behaviorProfile, rng *rand.Rand) string {
+	var countries []string
+	for _, code := range Geo.Policy().MaliciousCountries {
+		if code != p.Country {
+			countries = append(countries, code)
+		}
+	}
+	if len(countries) == 0 {
+		for _, c := range geoCountries {
+			if c.code != p.Country {
+				countries = append(countries, c.code)
+			}
+		}
+	}
+	return profileIP(countries[rng.Intn(len(countries))], rng)
+}
+
+// offShift returns t, or when the user is at work then, a time shortly
+// before their shift started
+func offShift(p *behaviorProfile, t time.Time, rng *rand.Rand) time.Time {
+	if !p.onShift(t) {
+		return t
+	}
+	local := t.In(p.loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), p.WorkStart, 0, 0, local.Nanosecond(), p.loc)
+	return start.Add(-time.Duration(1+rng.Intn(180)) * time.Minute)
+}
+
+// inspect finds the pool user an event is about, the pool host it names,
+// its public source IPs and byte counts
+func (b *Behavior) inspect(event *models.GeneratedEvent) *behaviorEvent {
+	e := &behaviorEvent{}
+	seenIPs := make(map[string]bool)
+	walkFields(event.Fields, "", "", func(path, key string, v interface{}) {
+		if s, ok := v.(string); ok {
+			if s != "" && isSourceGeo(path) {
+				e.geo = append(e.geo, geoField{key: key, value: s, city: strings.HasSuffix(path, "city")})
+			}
+			if e.profile == nil && hasSuffix(path, behaviorUserKeys) {
+				name := s[strings.LastIndex(s, `\`)+1:]
+				if i := strings.Index(name, "@"); i > 0 {
+					name = name[:i]
+				}
+				// Only first.last names are people; service accounts keep theirs
+				if p, ok := b.byName[strings.ToLower(name)]; ok {
+					e.name, e.profile = name, p
+				} else if strings.Count(name, ".") == 1 && !strings.HasPrefix(name, ".") && !strings.HasSuffix(name, ".") {
+					h := fnv.New32a()
+					h.Write([]byte(strings.ToLower(name)))
+					e.name, e.profile = name, b.profiles[h.Sum32()%uint32(len(b.profiles))]
+				}
+			}
+			if hasSuffix(path, behaviorIPKeys) && !strings.Contains(path, "dest") && !seenIPs[s] {
+				if ip := net.ParseIP(s).To4(); ip != nil && ip.IsGlobalUnicast() && !ip.IsPrivate() {
+					seenIPs[s] = true
+					e.ips = append(e.ips, s)
+				}
+			}
+			return
+		}
+		if !strings.HasSuffix(path, "bytes") && !strings.HasPrefix(path, "bytes") {
+			return
+		}
+		var n float64
+		switch t := v.(type) {
+		case int:
+			n = float64(t)
+		case int64:
+			n = float64(t)
+		case float64:
+			n = t
+		default:
+			return
+		}
+		if n >= 100 && n == math.Trunc(n) {
+			e.bytes = append(e.bytes, n)
+		}
+	})
+
+	// Byte counts that cannot be told apart in the raw event are left alone
+	for _, n := range e.bytes {
+		if event.RawEvent != "" && strings.Count(event.RawEvent, formatBytes(n)) != 1 {
+			e.bytes = nil
+			break
+		}
+	}
+	if name := fieldsHost(event.Fields, true); name != "" {
+		short, _, _ := strings.Cut(name, ".")
+		for _, h := range Entities.Hosts() {
+			if strings.EqualFold(h.Hostname, short) || strings.EqualFold(h.FQDN, name) {
+				e.host = h
+				break
+			}
+		}
+	}
+	return e
+}
+
+// rewriteBehavior returns a copy of event with each old string of pairs
+// replaced by the new one after it, source country and city fields mapped
+// by geo and byte counts scaled by factor
+func rewriteBehavior(event *models.GeneratedEvent, pairs []string, geo map[string]string, factor float64) *models.GeneratedEvent {
+	if len(pairs) == 0 {
+		return event
+	}
+	type pair struct{ old, new string }
+	var sorted []pair
+	for i := 0; i < len(pairs); i += 2 {
+		if pairs[i] != "" && pairs[i] != pairs[i+1] {
+			sorted = append(sorted, pair{pairs[i], pairs[i+1]})
+		}
+	}
+	// Longer forms first, so an FQDN is replaced before the hostname in it
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].old) > len(sorted[j].old) })
+	olds := make([]string, 0, 2*len(sorted))
+	for _, p := range sorted {
+		olds = append(olds, p.old, p.new)
+	}
+	r := strings.NewReplacer(olds...)
+
+	out := *event
+	out.RawEvent = r.Replace(event.RawEvent)
+	out.Fields = mapFields(event.Fields, "", func(path string, v interface{}) interface{} {
+		switch t := v.(type) {
+		case string:
+			if v, ok := geo[t]; ok && isSourceGeo(path) {
+				return v
+			}
+			return r.Replace(t)
+		case int:
+			if factor != 1 && (strings.HasSuffix(path, "bytes") || strings.HasPrefix(path, "bytes")) && t >= 100 {
+				return int(math.Round(float64(t) * factor))
+			}
+		case int64:
+			if factor != 1 && (strings.HasSuffix(path, "bytes") || strings.HasPrefix(path, "bytes")) && t >= 100 {
+				return int64(math.Round(float64(t) * factor))
+			}
+		case float64:
+			if factor != 1 && (strings.HasSuffix(path, "bytes") || strings.HasPrefix(path, "bytes")) && t >= 100 && t == math.Trunc(t) {
+				return math.Round(t * factor)
+			}
+		}
+		return v
+	}).(map[string]interface{})
+	return &out
+}
+
+// fieldPath appends key to path, lower-cased with punctuation removed
+func fieldPath(path, key string) string {
+	return path + strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, key)
+}
+
+// walkFields calls visit with every value in v that is not a map or list,
+// the path to it and its key
+func walkFields(v interface{}, path, key string, visit func(path, key string, v interface{})) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			walkFields(child, fieldPath(path, k), k, visit)
+		}
+	case []interface{}:
+		for _, child := range t {
+			walkFields(child, path, key, visit)
+		}
+	case []map[string]interface{}:
+		for _, child := range t {
+			walkFields(child, path, key, visit)
+		}
+	default:
+		visit(path, key, v)
+	}
+}
+
+// mapFields returns a copy of v with every value that is not a map or list
+// replaced by fn of it and the path to it
+func mapFields(v interface{}, path string, fn func(path string, v interface{}) interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			m[k] = mapFields(child, fieldPath(path, k), fn)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(t))
+		for i, child := range t {
+			items[i] = mapFields(child, path, fn)
+		}
+		return items
+	case []map[string]interface{}:
+		items := make([]map[string]interface{}, len(t))
+		for i, child := range t {
+			items[i] = mapFields(child, path, fn).(map[string]interface{})
+		}
+		return items
+	}
+	return fn(path, v)
+}
+
+// isSourceGeo reports whether the field at path is the country or city an
+// event's source is in
+func isSourceGeo(path string) bool {
+	return hasSuffix(path, behaviorGeoKeys) && !strings.Contains(path, "dst") && !strings.Contains(path, "dest")
+}
+
+func hasSuffix(path string, suffixes []string) bool {
+	for _, s := range suffixes {
+		if strings.HasSuffix(path, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatBytes(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
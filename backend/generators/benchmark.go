@@ -0,0 +1,125 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// Benchmark limits
+const (
+	defaultBenchmarkDuration = time.Second
+	maxBenchmarkDuration     = 10 * time.Second
+)
+
+// Benchmark generates events from each requested generator as fast as
+// possible, one generator at a time so each gets every worker, and reports
+// the rate achieved. Events are discarded after generation.
+func Benchmark(ctx context.Context, req models.BenchmarkRequest) (*models.BenchmarkResult, error) {
+	duration := defaultBenchmarkDuration
+	if req.DurationMs > 0 {
+		duration = time.Duration(req.DurationMs) * time.Millisecond
+	}
+	if duration > maxBenchmarkDuration {
+		return nil, fmt.Errorf("duration_ms must be at most %d", maxBenchmarkDuration.Milliseconds())
+	}
+	workers := req.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	eventTypes := req.EventTypes
+	if len(eventTypes) == 0 {
+		for _, t := range EventTypes() {
+			eventTypes = append(eventTypes, t.ID)
+		}
+	}
+	gens := make([]Generator, 0, len(eventTypes))
+	for _, id := range eventTypes {
+		g, ok := GetGenerator(id)
+		if !ok {
+			return nil, fmt.Errorf("event type not found: %s", id)
+		}
+		gens = append(gens, g)
+	}
+
+	result := &models.BenchmarkResult{
+		PerformanceMode: PerformanceMode(),
+		Workers:         workers,
+		CPUs:            runtime.NumCPU(),
+		DurationMs:      int(duration.Milliseconds()),
+		Generators:      make([]models.GeneratorBenchmark, 0, len(gens)),
+	}
+	for _, g := range gens {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		b := benchmarkGenerator(ctx, g, duration, workers)
+		result.TotalEvents += b.Events
+		result.Generators = append(result.Generators, b)
+	}
+
+	sort.Slice(result.Generators, func(i, j int) bool {
+		return result.Generators[i].EventsPerSecond > result.Generators[j].EventsPerSecond
+	})
+	return result, nil
+}
+
+// benchmarkGenerator runs workers that cycle through the generator's
+// templates until duration has elapsed
+func benchmarkGenerator(ctx context.Context, g Generator, duration time.Duration, workers int) models.GeneratorBenchmark {
+	templates := g.GetTemplates()
+	b := models.GeneratorBenchmark{
+		EventType: g.GetEventType().ID,
+		Templates: len(templates),
+	}
+	if len(templates) == 0 {
+		b.Error = "event type has no templates"
+		return b
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var events, errs, bytes int64
+	var firstErr atomic.Value
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			for i := offset; ctx.Err() == nil; i++ {
+				event, err := Unseeded.Generate(g, templates[i%len(templates)].ID, nil)
+				if err != nil {
+					atomic.AddInt64(&errs, 1)
+					firstErr.CompareAndSwap(nil, err.Error())
+					continue
+				}
+				atomic.AddInt64(&events, 1)
+				atomic.AddInt64(&bytes, int64(len(event.RawEvent)))
+			}
+		}(w)
+	}
+	wg.Wait()
+	elapsed := time.Since(start).Seconds()
+
+	b.Events, b.Errors = events, errs
+	if msg, ok := firstErr.Load().(string); ok {
+		b.Error = msg
+	}
+	if elapsed > 0 {
+		b.EventsPerSecond = float64(events) / elapsed
+		b.BytesPerSecond = float64(bytes) / elapsed
+	}
+	if events > 0 {
+		b.AvgEventBytes = float64(bytes) / float64(events)
+	}
+	return b
+}
@@ -0,0 +1,632 @@
+package generators
+
+import (
+	"sort"
+	"strings"
+
+	"siem-event-generator/models"
+)
+
+// Catalog page limits
+const (
+	defaultCatalogLimit = 50
+	maxCatalogLimit     = 500
+)
+
+// catalogInfo annotates an event type or one of its templates for the
+// catalog. Set fields of a template's entry replace its event type's.
+type catalogInfo struct {
+	vendor     string
+	product    string
+	datamodels []string // Splunk CIM data models
+	techniques []string // MITRE ATT&CK technique IDs
+}
+
+// catalogTypes annotates the built-in event types
+var catalogTypes = map[string]catalogInfo{
+	"akamai_datastream":    {vendor: "Akamai", product: "DataStream 2", datamodels: []string{"Web"}},
+	"app_logs":             {vendor: "Generic", product: "Application Logs"},
+	"asset_inventory":      {vendor: "Generic", product: "Asset Inventory", datamodels: []string{"Compute_Inventory"}},
+	"aws_alb":              {vendor: "AWS", product: "Application Load Balancer", datamodels: []string{"Web"}},
+	"aws_cloudtrail":       {vendor: "AWS", product: "CloudTrail", datamodels: []string{"Change"}},
+	"aws_cloudfront":       {vendor: "AWS", product: "CloudFront", datamodels: []string{"Web"}},
+	"aws_cost":             {vendor: "AWS", product: "Cost and Usage Report"},
+	"aws_guardduty":        {vendor: "AWS", product: "GuardDuty", datamodels: []string{"Alerts"}},
+	"aws_route53_resolver": {vendor: "AWS", product: "Route 53 Resolver", datamodels: []string{"Network_Resolution"}},
+	"aws_securityhub":      {vendor: "AWS", product: "Security Hub", datamodels: []string{"Alerts"}},
+	"aws_vpcflow":          {vendor: "AWS", product: "VPC Flow Logs", datamodels: []string{"Network_Traffic"}},
+	"azure_activity":       {vendor: "Microsoft", product: "Azure Activity Log", datamodels: []string{"Change"}},
+	"azure_ad_signin":      {vendor: "Microsoft", product: "Entra ID", datamodels: []string{"Authentication"}},
+	"azure_cost":           {vendor: "Microsoft", product: "Azure Cost Management"},
+	"backup_job":           {vendor: "Veeam", product: "Backup & Replication"},
+	"badge_access":         {vendor: "Generic", product: "Physical Access Control"},
+	"cisco_asa":            {vendor: "Cisco", product: "ASA", datamodels: []string{"Network_Traffic"}},
+	"cisco_firepower":      {vendor: "Cisco", product: "Firepower"},
+	"crowdstrike":          {vendor: "CrowdStrike", product: "Falcon", datamodels: []string{"Endpoint"}},
+	"database_audit":       {vendor: "Generic", product: "Database Audit"},
+	"dlp":                  {vendor: "Generic", product: "Data Loss Prevention", datamodels: []string{"DLP"}},
+	"dns_query":            {vendor: "Generic", product: "DNS Server", datamodels: []string{"Network_Resolution"}},
+	"github_audit":         {vendor: "GitHub", product: "GitHub Enterprise", datamodels: []string{"Change"}},
+	"honeypot":             {vendor: "Generic", product: "Honeypot", datamodels: []string{"Intrusion_Detection"}},
+	"kubernetes_audit":     {vendor: "CNCF", product: "Kubernetes", datamodels: []string{"Change"}},
+	"linux_auditbeat":      {vendor: "Elastic", product: "Auditbeat", datamodels: []string{"Endpoint"}},
+	"metrics_application":  {vendor: "Generic", product: "Application Metrics", datamodels: []string{"Performance"}},
+	"metrics_database":     {vendor: "Generic", product: "Database Metrics", datamodels: []string{"Performance"}},
+	"metrics_system":       {vendor: "Generic", product: "System Metrics", datamodels: []string{"Performance"}},
+	"metrics_webapi":       {vendor: "Generic", product: "Web/API Metrics", datamodels: []string{"Performance"}},
+	"microsoft_ad":         {vendor: "Microsoft", product: "Active Directory", datamodels: []string{"Change"}},
+	"microsoft_defender":   {vendor: "Microsoft", product: "Defender for Endpoint", datamodels: []string{"Endpoint"}},
+	"microsoft_sentinel":   {vendor: "Microsoft", product: "Sentinel", datamodels: []string{"Alerts"}},
+	"netskope":             {vendor: "Netskope", product: "Netskope Security Cloud", datamodels: []string{"Web"}},
+	"o365_audit":           {vendor: "Microsoft", product: "Office 365", datamodels: []string{"Change"}},
+	"okta":                 {vendor: "Okta", product: "Okta Identity Cloud", datamodels: []string{"Change"}},
+	"osquery":              {vendor: "osquery", product: "osquery", datamodels: []string{"Endpoint"}},
+	"ot_ics":               {vendor: "Generic", product: "OT/ICS Network Monitor", datamodels: []string{"Network_Traffic"}},
+	"otel_traces":          {vendor: "OpenTelemetry", product: "OpenTelemetry Traces"},
+	"paloalto":             {vendor: "Palo Alto Networks", product: "PAN-OS", datamodels: []string{"Network_Traffic"}},
+	"print_audit":          {vendor: "Microsoft", product: "Windows Print Service"},
+	"salesforce":           {vendor: "Salesforce", product: "Event Monitoring", datamodels: []string{"Data_Access"}},
+	"sap_audit":            {vendor: "SAP", product: "Security Audit Log", datamodels: []string{"Change"}},
+	"splunk_notable":       {vendor: "Splunk", product: "Enterprise Security", datamodels: []string{"Alerts"}},
+	"storage_audit":        {vendor: "NetApp", product: "ONTAP", datamodels: []string{"Change"}},
+	"suricata":             {vendor: "OISF", product: "Suricata"},
+	"syslog_rfc5424":       {vendor: "Generic", product: "Syslog (RFC 5424)"},
+	"vms_camera":           {vendor: "Generic", product: "Video Management System"},
+	"vmware_vcenter":       {vendor: "VMware", product: "vCenter", datamodels: []string{"Change"}},
+	"vuln_scan":            {vendor: "Generic", product: "Vulnerability Scanner", datamodels: []string{"Vulnerabilities"}},
+	"webserver":            {vendor: "Apache", product: "HTTP Server", datamodels: []string{"Web"}},
+	"windows_defender_av":  {vendor: "Microsoft", product: "Defender Antivirus", datamodels: []string{"Malware"}},
+	"windows_powershell":   {vendor: "Microsoft", product: "Windows PowerShell", datamodels: []string{"Endpoint"}, techniques: []string{"T1059.001"}},
+	"windows_security":     {vendor: "Microsoft", product: "Windows", datamodels: []string{"Change"}},
+	"windows_sysmon":       {vendor: "Microsoft", product: "Sysmon", datamodels: []string{"Endpoint"}},
+	"windows_winrm":        {vendor: "Microsoft", product: "Windows Remote Management", datamodels: []string{"Endpoint"}},
+	"zeek":                 {vendor: "Zeek", product: "Zeek"},
+	"zscaler_zia":          {vendor: "Zscaler", product: "Internet Access", datamodels: []string{"Web"}},
+}
+
+// catalogTemplates annotates templates that differ from their event type,
+// keyed by event type and template ID
+var catalogTemplates = map[string]catalogInfo{
+	"akamai_datastream/waf_denied": {product: "App & API Protector", datamodels: []string{"Web", "Intrusion_Detection"}, techniques: []string{"T1190"}},
+
+	"aws_cloudtrail/ConsoleLogin":                  {datamodels: []string{"Authentication"}, techniques: []string{"T1078.004"}},
+	"aws_cloudtrail/AssumeRole":                    {datamodels: []string{"Authentication"}},
+	"aws_cloudtrail/CreateUser":                    {techniques: []string{"T1136.003"}},
+	"aws_cloudtrail/DeleteUser":                    {techniques: []string{"T1531"}},
+	"aws_cloudtrail/AuthorizeSecurityGroupIngress": {techniques: []string{"T1562.007"}},
+	"aws_cloudtrail/RunInstances":                  {techniques: []string{"T1578.002"}},
+	"aws_cloudtrail/CreateAccessKey":               {techniques: []string{"T1098.001"}},
+	"aws_cloudtrail/GetSecretValue":                {datamodels: []string{"Data_Access"}, techniques: []string{"T1555.006"}},
+	"aws_cloudtrail/GetObject":                     {datamodels: []string{"Data_Access"}, techniques: []string{"T1530"}},
+	"aws_cloudtrail/PutObject":                     {datamodels: []string{"Data_Access"}},
+	"aws_cloudtrail/ApiCallRateInsight":            {datamodels: []string{"Alerts"}},
+	"aws_cloudtrail/ApiErrorRateInsight":           {datamodels: []string{"Alerts"}},
+
+	"aws_cloudfront/waf_blocked": {datamodels: []string{"Web", "Intrusion_Detection"}, techniques: []string{"T1190"}},
+
+	"aws_cost/cost_anomaly": {product: "Cost Anomaly Detection", datamodels: []string{"Alerts"}},
+
+	"aws_guardduty/SSHBruteForce":              {techniques: []string{"T1110"}},
+	"aws_guardduty/PortProbe":                  {techniques: []string{"T1595"}},
+	"aws_guardduty/CryptoMining":               {techniques: []string{"T1496"}},
+	"aws_guardduty/ConsoleLoginAnomaly":        {techniques: []string{"T1078.004"}},
+	"aws_guardduty/C2Activity":                 {techniques: []string{"T1071"}},
+	"aws_guardduty/S3ObjectReadUnusual":        {techniques: []string{"T1530"}},
+	"aws_guardduty/IAMDiscoveryAnomaly":        {techniques: []string{"T1087.004"}},
+	"aws_guardduty/IAMCredentialAccessAnomaly": {techniques: []string{"T1552"}},
+	"aws_guardduty/K8sAnonymousAccess":         {techniques: []string{"T1078.001"}},
+	"aws_guardduty/K8sPrivilegedContainer":     {techniques: []string{"T1610", "T1611"}},
+	"aws_guardduty/K8sExecInKubeSystemPod":     {techniques: []string{"T1609"}},
+	"aws_guardduty/RuntimeReverseShell":        {techniques: []string{"T1059.004"}},
+	"aws_guardduty/RuntimeCryptoMining":        {techniques: []string{"T1496"}},
+
+	"aws_route53_resolver/firewall_block": {techniques: []string{"T1071.004"}},
+
+	"aws_securityhub/inspector_ec2": {product: "Inspector", datamodels: []string{"Vulnerabilities"}},
+	"aws_securityhub/inspector_ecr": {product: "Inspector", datamodels: []string{"Vulnerabilities"}},
+
+	"azure_activity/role_assignment":     {techniques: []string{"T1098"}},
+	"azure_activity/nsg_rule_create":     {techniques: []string{"T1562.007"}},
+	"azure_activity/storage_key_regen":   {techniques: []string{"T1098.001"}},
+	"azure_activity/keyvault_secret_get": {datamodels: []string{"Data_Access"}, techniques: []string{"T1555.006"}},
+	"azure_activity/vm_create":           {techniques: []string{"T1578.002"}},
+
+	"azure_ad_signin/interactive_failure": {techniques: []string{"T1110"}},
+	"azure_ad_signin/risky_signin":        {techniques: []string{"T1078.004"}},
+
+	"azure_cost/cost_anomaly": {datamodels: []string{"Alerts"}},
+	"azure_cost/budget_alert": {datamodels: []string{"Alerts"}},
+
+	"backup_job/veeam_backup_deleted": {techniques: []string{"T1490"}},
+	"backup_job/commvault_job":        {vendor: "Commvault", product: "Commvault Complete"},
+	"backup_job/commvault_restore":    {vendor: "Commvault", product: "Commvault Complete"},
+	"backup_job/commvault_audit":      {vendor: "Commvault", product: "Commvault Complete", datamodels: []string{"Change"}, techniques: []string{"T1490"}},
+
+	"cisco_asa/113039": {datamodels: []string{"Network_Sessions"}},
+	"cisco_asa/113019": {datamodels: []string{"Network_Sessions"}},
+	"cisco_asa/722022": {datamodels: []string{"Network_Sessions"}},
+	"cisco_asa/722023": {datamodels: []string{"Network_Sessions"}},
+	"cisco_asa/722051": {datamodels: []string{"Network_Sessions"}},
+	"cisco_asa/734001": {datamodels: []string{"Network_Sessions"}},
+	"cisco_asa/113005": {datamodels: []string{"Authentication"}, techniques: []string{"T1110"}},
+	"cisco_asa/111008": {datamodels: []string{"Change"}},
+
+	"cisco_firepower/intrusion":  {datamodels: []string{"Intrusion_Detection"}},
+	"cisco_firepower/connection": {datamodels: []string{"Network_Traffic"}},
+	"cisco_firepower/file":       {datamodels: []string{"Malware"}},
+	"cisco_firepower/malware":    {datamodels: []string{"Malware"}},
+
+	"crowdstrike/detection":           {datamodels: []string{"Alerts"}, techniques: []string{"T1059.001", "T1059.003", "T1053.005", "T1547.001", "T1078", "T1003.001", "T1021.001", "T1071.001", "T1486"}},
+	"crowdstrike/incident":            {datamodels: []string{"Alerts"}},
+	"crowdstrike/identity_protection": {datamodels: []string{"Alerts"}},
+	"crowdstrike/network":             {datamodels: []string{"Network_Traffic"}},
+	"crowdstrike/dns":                 {datamodels: []string{"Network_Resolution"}},
+	"crowdstrike/auth_activity":       {datamodels: []string{"Authentication"}},
+
+	"database_audit/pgaudit_login": {vendor: "PostgreSQL", product: "pgAudit", datamodels: []string{"Authentication"}},
+	"database_audit/pgaudit_ddl":   {vendor: "PostgreSQL", product: "pgAudit", datamodels: []string{"Change"}},
+	"database_audit/pgaudit_read":  {vendor: "PostgreSQL", product: "pgAudit", datamodels: []string{"Databases"}},
+	"database_audit/mysql_connect": {vendor: "MySQL", product: "MySQL Audit", datamodels: []string{"Authentication"}},
+	"database_audit/mysql_ddl":     {vendor: "MySQL", product: "MySQL Audit", datamodels: []string{"Change"}},
+	"database_audit/mysql_query":   {vendor: "MySQL", product: "MySQL Audit", datamodels: []string{"Databases"}},
+	"database_audit/mssql_login":   {vendor: "Microsoft", product: "SQL Server Audit", datamodels: []string{"Authentication"}},
+	"database_audit/mssql_ddl":     {vendor: "Microsoft", product: "SQL Server Audit", datamodels: []string{"Change"}},
+	"database_audit/mssql_select":  {vendor: "Microsoft", product: "SQL Server Audit", datamodels: []string{"Databases"}},
+
+	"dlp/purview_exchange":   {vendor: "Microsoft", product: "Purview"},
+	"dlp/purview_sharepoint": {vendor: "Microsoft", product: "Purview"},
+	"dlp/purview_endpoint":   {vendor: "Microsoft", product: "Purview"},
+	"dlp/symantec_network":   {vendor: "Symantec", product: "Data Loss Prevention"},
+	"dlp/symantec_endpoint":  {vendor: "Symantec", product: "Data Loss Prevention"},
+
+	"dns_query/query_suspicious": {techniques: []string{"T1568"}},
+	"dns_query/query_tunneling":  {techniques: []string{"T1071.004"}},
+
+	"github_audit/git.clone":      {datamodels: []string{"Data_Access"}, techniques: []string{"T1213.003"}},
+	"github_audit/org.add_member": {techniques: []string{"T1098"}},
+
+	"honeypot/cowrie.login.failed":          {vendor: "Cowrie", product: "Cowrie", datamodels: []string{"Intrusion_Detection", "Authentication"}, techniques: []string{"T1110.001"}},
+	"honeypot/cowrie.login.success":         {vendor: "Cowrie", product: "Cowrie", datamodels: []string{"Intrusion_Detection", "Authentication"}, techniques: []string{"T1078"}},
+	"honeypot/cowrie.command.input":         {vendor: "Cowrie", product: "Cowrie", techniques: []string{"T1059.004"}},
+	"honeypot/cowrie.session.file_download": {vendor: "Cowrie", product: "Cowrie", techniques: []string{"T1105"}},
+	"honeypot/dionaea.connection":           {vendor: "Dionaea", product: "Dionaea"},
+
+	"kubernetes_audit/pod_create":     {techniques: []string{"T1610"}},
+	"kubernetes_audit/secret_access":  {datamodels: []string{"Data_Access"}, techniques: []string{"T1552.007"}},
+	"kubernetes_audit/exec_container": {techniques: []string{"T1609"}},
+	"kubernetes_audit/rbac_change":    {techniques: []string{"T1098.006"}},
+
+	"linux_auditbeat/user_login": {datamodels: []string{"Authentication"}},
+	"linux_auditbeat/socket":     {datamodels: []string{"Network_Traffic"}},
+	"linux_auditbeat/package":    {datamodels: []string{"Updates"}},
+
+	"microsoft_ad/4720": {techniques: []string{"T1136.002"}},
+	"microsoft_ad/4724": {techniques: []string{"T1098"}},
+	"microsoft_ad/4726": {techniques: []string{"T1531"}},
+	"microsoft_ad/4728": {techniques: []string{"T1098"}},
+	"microsoft_ad/4732": {techniques: []string{"T1098"}},
+	"microsoft_ad/4740": {techniques: []string{"T1110"}},
+
+	"microsoft_defender/alert":              {datamodels: []string{"Alerts"}},
+	"microsoft_defender/network_connection": {datamodels: []string{"Network_Traffic"}},
+	"microsoft_defender/logon_event":        {datamodels: []string{"Authentication"}},
+	"microsoft_defender/malware_detection":  {datamodels: []string{"Malware"}},
+
+	"netskope/alert_dlp":     {datamodels: []string{"DLP"}, techniques: []string{"T1567"}},
+	"netskope/alert_malware": {datamodels: []string{"Malware"}},
+	"netskope/alert_policy":  {datamodels: []string{"Alerts"}},
+	"netskope/alert_anomaly": {datamodels: []string{"Alerts"}},
+
+	"o365_audit/file_accessed": {datamodels: []string{"Data_Access"}, techniques: []string{"T1213.002"}},
+	"o365_audit/user_login":    {datamodels: []string{"Authentication"}},
+	"o365_audit/mail_accessed": {datamodels: []string{"Data_Access"}, techniques: []string{"T1114.002"}},
+
+	"okta/session_start":  {datamodels: []string{"Authentication"}},
+	"okta/sso_auth":       {datamodels: []string{"Authentication"}},
+	"okta/auth_failure":   {datamodels: []string{"Authentication"}, techniques: []string{"T1110"}},
+	"okta/account_lock":   {techniques: []string{"T1110"}},
+	"okta/mfa_enroll":     {techniques: []string{"T1556.006"}},
+	"okta/password_reset": {techniques: []string{"T1098"}},
+	"okta/user_create":    {techniques: []string{"T1136.003"}},
+
+	"osquery/listening_ports": {datamodels: []string{"Endpoint", "Network_Traffic"}},
+	"osquery/users":           {datamodels: []string{"Compute_Inventory"}},
+	"osquery/crontab":         {datamodels: []string{"Change"}, techniques: []string{"T1053.003"}},
+
+	"ot_ics/unauthorized_write": {datamodels: []string{"Alerts", "Intrusion_Detection"}, techniques: []string{"T0836"}},
+
+	"paloalto/threat_virus":   {datamodels: []string{"Malware"}},
+	"paloalto/threat_spyware": {datamodels: []string{"Intrusion_Detection"}, techniques: []string{"T1071"}},
+	"paloalto/url_block":      {datamodels: []string{"Web"}},
+	"paloalto/url_allow":      {datamodels: []string{"Web"}},
+
+	"salesforce/LoginEvent":   {datamodels: []string{"Authentication"}},
+	"salesforce/ReportExport": {techniques: []string{"T1213"}},
+
+	"sap_audit/AU1": {datamodels: []string{"Authentication"}},
+	"sap_audit/AU2": {datamodels: []string{"Authentication"}, techniques: []string{"T1110"}},
+	"sap_audit/AU5": {datamodels: []string{"Authentication"}},
+	"sap_audit/AU6": {datamodels: []string{"Authentication"}},
+	"sap_audit/AUB": {techniques: []string{"T1098"}},
+
+	"splunk_notable/brute_force":             {techniques: []string{"T1110"}},
+	"splunk_notable/excessive_failed_logins": {techniques: []string{"T1110"}},
+	"splunk_notable/malware":                 {datamodels: []string{"Malware"}},
+	"splunk_notable/vuln_scanner":            {techniques: []string{"T1595.002"}},
+
+	"storage_audit/netapp_snapshot_delete":     {techniques: []string{"T1490"}},
+	"storage_audit/netapp_protection_disabled": {techniques: []string{"T1490"}},
+	"storage_audit/netapp_ransomware_alert":    {datamodels: []string{"Alerts"}, techniques: []string{"T1486"}},
+	"storage_audit/isilon_snapshot_delete":     {vendor: "Dell EMC", product: "PowerScale", techniques: []string{"T1490"}},
+	"storage_audit/isilon_snaprevert":          {vendor: "Dell EMC", product: "PowerScale"},
+
+	"suricata/alert":    {datamodels: []string{"Intrusion_Detection"}},
+	"suricata/flow":     {datamodels: []string{"Network_Traffic"}},
+	"suricata/dns":      {datamodels: []string{"Network_Resolution"}},
+	"suricata/http":     {datamodels: []string{"Web"}},
+	"suricata/tls":      {datamodels: []string{"Certificates"}},
+	"suricata/fileinfo": {datamodels: []string{"Network_Traffic"}},
+
+	"vmware_vcenter/alarm_triggered": {datamodels: []string{"Alerts"}},
+	"vmware_vcenter/user_login":      {datamodels: []string{"Authentication"}},
+
+	"vuln_scan/nessus": {vendor: "Tenable", product: "Nessus"},
+	"vuln_scan/qualys": {vendor: "Qualys", product: "VMDR"},
+
+	"webserver/apache_error": {datamodels: []string{}},
+	"webserver/nginx_error":  {vendor: "F5", product: "NGINX", datamodels: []string{}},
+
+	"windows_defender_av/5007": {datamodels: []string{"Change"}, techniques: []string{"T1562.001"}},
+
+	"windows_powershell/4104_encoded":    {techniques: []string{"T1059.001", "T1027.010"}},
+	"windows_powershell/4104_obfuscated": {techniques: []string{"T1059.001", "T1027.010"}},
+
+	"windows_security/4624": {datamodels: []string{"Authentication"}},
+	"windows_security/4625": {datamodels: []string{"Authentication"}, techniques: []string{"T1110"}},
+	"windows_security/4634": {datamodels: []string{"Authentication"}},
+	"windows_security/4647": {datamodels: []string{"Authentication"}},
+	"windows_security/4672": {datamodels: []string{"Authentication"}},
+	"windows_security/4688": {datamodels: []string{"Endpoint"}},
+	"windows_security/4720": {techniques: []string{"T1136.001"}},
+	"windows_security/4768": {datamodels: []string{"Authentication"}},
+	"windows_security/4769": {datamodels: []string{"Authentication"}, techniques: []string{"T1558.003"}},
+	"windows_security/4771": {datamodels: []string{"Authentication"}, techniques: []string{"T1110"}},
+	"windows_security/4776": {datamodels: []string{"Authentication"}},
+	"windows_security/4663": {datamodels: []string{"Data_Access"}},
+	"windows_security/4670": {techniques: []string{"T1222.001"}},
+	"windows_security/4719": {techniques: []string{"T1562.002"}},
+	"windows_security/1102": {techniques: []string{"T1070.001"}},
+	"windows_security/5152": {datamodels: []string{"Network_Traffic"}},
+	"windows_security/5157": {datamodels: []string{"Network_Traffic"}},
+
+	"windows_sysmon/3":  {datamodels: []string{"Network_Traffic"}},
+	"windows_sysmon/8":  {techniques: []string{"T1055"}},
+	"windows_sysmon/10": {techniques: []string{"T1003.001"}},
+	"windows_sysmon/12": {techniques: []string{"T1112"}},
+	"windows_sysmon/13": {techniques: []string{"T1112"}},
+	"windows_sysmon/14": {techniques: []string{"T1112"}},
+	"windows_sysmon/15": {techniques: []string{"T1564.004"}},
+	"windows_sysmon/22": {datamodels: []string{"Network_Resolution"}},
+	"windows_sysmon/25": {techniques: []string{"T1055.012"}},
+
+	"windows_winrm/6":    {techniques: []string{"T1021.006"}},
+	"windows_winrm/91":   {techniques: []string{"T1021.006"}},
+	"windows_winrm/169":  {datamodels: []string{"Authentication"}, techniques: []string{"T1021.006"}},
+	"windows_winrm/5857": {techniques: []string{"T1047"}},
+	"windows_winrm/5860": {techniques: []string{"T1047"}},
+	"windows_winrm/5861": {techniques: []string{"T1546.003"}},
+
+	"zeek/conn":   {datamodels: []string{"Network_Traffic"}},
+	"zeek/dns":    {datamodels: []string{"Network_Resolution"}},
+	"zeek/http":   {datamodels: []string{"Web"}},
+	"zeek/ssl":    {datamodels: []string{"Certificates"}},
+	"zeek/files":  {datamodels: []string{"Network_Traffic"}},
+	"zeek/notice": {datamodels: []string{"Intrusion_Detection"}},
+
+	"zscaler_zia/threat":       {datamodels: []string{"Web", "Malware"}},
+	"zscaler_zia/cloud_upload": {techniques: []string{"T1567.002"}},
+}
+
+// attackTechniques names the ATT&CK techniques the catalog refers to, so
+// they can be searched by name and labelled in facets
+var attackTechniques = map[string]string{
+	"T0836":     "Modify Parameter",
+	"T1003.001": "OS Credential Dumping: LSASS Memory",
+	"T1021.001": "Remote Services: Remote Desktop Protocol",
+	"T1021.006": "Remote Services: Windows Remote Management",
+	"T1027.010": "Obfuscated Files or Information: Command Obfuscation",
+	"T1047":     "Windows Management Instrumentation",
+	"T1053.003": "Scheduled Task/Job: Cron",
+	"T1053.005": "Scheduled Task/Job: Scheduled Task",
+	"T1055":     "Process Injection",
+	"T1055.012": "Process Injection: Process Hollowing",
+	"T1059.001": "Command and Scripting Interpreter: PowerShell",
+	"T1059.003": "Command and Scripting Interpreter: Windows Command Shell",
+	"T1059.004": "Command and Scripting Interpreter: Unix Shell",
+	"T1070.001": "Indicator Removal: Clear Windows Event Logs",
+	"T1071":     "Application Layer Protocol",
+	"T1071.001": "Application Layer Protocol: Web Protocols",
+	"T1071.004": "Application Layer Protocol: DNS",
+	"T1078":     "Valid Accounts",
+	"T1078.001": "Valid Accounts: Default Accounts",
+	"T1078.004": "Valid Accounts: Cloud Accounts",
+	"T1087.004": "Account Discovery: Cloud Account",
+	"T1098":     "Account Manipulation",
+	"T1098.001": "Account Manipulation: Additional Cloud Credentials",
+	"T1098.006": "Account Manipulation: Additional Container Cluster Roles",
+	"T1105":     "Ingress Tool Transfer",
+	"T1110":     "Brute Force",
+	"T1110.001": "Brute Force: Password Guessing",
+	"T1112":     "Modify Registry",
+	"T1114.002": "Email Collection: Remote Email Collection",
+	"T1136.001": "Create Account: Local Account",
+	"T1136.002": "Create Account: Domain Account",
+	"T1136.003": "Create Account: Cloud Account",
+	"T1213":     "Data from Information Repositories",
+	"T1213.002": "Data from Information Repositories: Sharepoint",
+	"T1213.003": "Data from Information Repositories: Code Repositories",
+	"T1222.001": "File and Directory Permissions Modification: Windows",
+	"T1486":     "Data Encrypted for Impact",
+	"T1496":     "Resource Hijacking",
+	"T1530":     "Data from Cloud Storage",
+	"T1531":     "Account Access Removal",
+	"T1546.003": "Event Triggered Execution: WMI Event Subscription",
+	"T1547.001": "Boot or Logon Autostart Execution: Registry Run Keys",
+	"T1552":     "Unsecured Credentials",
+	"T1552.007": "Unsecured Credentials: Container API",
+	"T1555.006": "Credentials from Password Stores: Cloud Secrets Management Stores",
+	"T1556.006": "Modify Authentication Process: Multi-Factor Authentication",
+	"T1558.003": "Steal or Forge Kerberos Tickets: Kerberoasting",
+	"T1562.001": "Impair Defenses: Disable or Modify Tools",
+	"T1562.002": "Impair Defenses: Disable Windows Event Logging",
+	"T1562.007": "Impair Defenses: Disable or Modify Cloud Firewall",
+	"T1564.004": "Hide Artifacts: NTFS File Attributes",
+	"T1567":     "Exfiltration Over Web Service",
+	"T1567.002": "Exfiltration Over Web Service: Exfiltration to Cloud Storage",
+	"T1568":     "Dynamic Resolution",
+	"T1578.002": "Modify Cloud Compute Infrastructure: Create Cloud Instance",
+	"T1595":     "Active Scanning",
+	"T1609":     "Container Administration Command",
+	"T1610":     "Deploy Container",
+	"T1611":     "Escape to Host",
+}
+
+// CatalogEntries returns a catalog entry for every template of every
+// registered generator, in event type order
+func CatalogEntries() []models.CatalogEntry {
+	var entries []models.CatalogEntry
+	for _, g := range Generators() {
+		eventType := g.GetEventType()
+		info := catalogTypes[eventType.ID]
+		for _, t := range g.GetTemplates() {
+			e := models.CatalogEntry{
+				EventType:     eventType.ID,
+				EventTypeName: eventType.Name,
+				Category:      eventType.Category,
+				TemplateID:    t.ID,
+				Name:          t.Name,
+				Description:   t.Description,
+				Format:        t.Format,
+				Sourcetype:    t.Sourcetype,
+				Vendor:        info.vendor,
+				Product:       info.product,
+				Datamodels:    info.datamodels,
+				Techniques:    info.techniques,
+				Source:        "builtin",
+				Deprecated:    t.Deprecated,
+			}
+			if override, ok := catalogTemplates[eventType.ID+"/"+t.ID]; ok {
+				if override.vendor != "" {
+					e.Vendor = override.vendor
+				}
+				if override.product != "" {
+					e.Product = override.product
+				}
+				if override.datamodels != nil {
+					e.Datamodels = override.datamodels
+				}
+				if override.techniques != nil {
+					e.Techniques = override.techniques
+				}
+			}
+			if e.Sourcetype == "" {
+				e.Sourcetype = eventType.ID
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// CustomCatalogEntry returns the catalog entry of a custom template. One
+// whose category names an event type takes that type's annotations.
+func CustomCatalogEntry(t models.EventTemplate) models.CatalogEntry {
+	e := models.CatalogEntry{
+		EventType:   t.Category,
+		Category:    "custom",
+		TemplateID:  t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Format:      t.Format,
+		Sourcetype:  t.Sourcetype,
+		Source:      "custom",
+		Deprecated:  t.Deprecated,
+	}
+	if g, ok := GetGenerator(t.Category); ok {
+		eventType := g.GetEventType()
+		info := catalogTypes[eventType.ID]
+		e.EventTypeName = eventType.Name
+		e.Category = eventType.Category
+		e.Vendor = info.vendor
+		e.Product = info.product
+		e.Datamodels = info.datamodels
+		e.Techniques = info.techniques
+	}
+	return e
+}
+
+// SearchCatalog returns the page of entries matching query, best text
+// matches first, with the facet counts of every match
+func SearchCatalog(entries []models.CatalogEntry, query models.CatalogQuery) models.CatalogResult {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultCatalogLimit
+	}
+	if limit > maxCatalogLimit {
+		limit = maxCatalogLimit
+	}
+	terms := strings.Fields(strings.ToLower(query.Text))
+
+	type match struct {
+		entry models.CatalogEntry
+		score int
+		order int
+	}
+	var matches []match
+	for i, e := range entries {
+		if !catalogFiltersMatch(e, query) {
+			continue
+		}
+		score, ok := catalogScore(e, terms)
+		if !ok {
+			continue
+		}
+		matches = append(matches, match{entry: e, score: score, order: i})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].order < matches[j].order
+	})
+
+	counts := map[string]map[string]int{
+		"category":  {},
+		"format":    {},
+		"vendor":    {},
+		"technique": {},
+		"datamodel": {},
+	}
+	for _, m := range matches {
+		e := m.entry
+		counts["category"][e.Category]++
+		counts["format"][e.Format]++
+		counts["vendor"][e.Vendor]++
+		for _, t := range e.Techniques {
+			counts["technique"][t]++
+		}
+		for _, d := range e.Datamodels {
+			counts["datamodel"][d]++
+		}
+	}
+
+	result := models.CatalogResult{
+		Total:   len(matches),
+		Offset:  query.Offset,
+		Entries: []models.CatalogEntry{},
+		Facets:  make(map[string][]models.FacetCount, len(counts)),
+	}
+	for facet, values := range counts {
+		fc := []models.FacetCount{}
+		for value, count := range values {
+			if value == "" {
+				continue
+			}
+			f := models.FacetCount{Value: value, Count: count}
+			if facet == "technique" {
+				f.Label = attackTechniques[value]
+			}
+			fc = append(fc, f)
+		}
+		sort.Slice(fc, func(i, j int) bool {
+			if fc[i].Count != fc[j].Count {
+				return fc[i].Count > fc[j].Count
+			}
+			return fc[i].Value < fc[j].Value
+		})
+		result.Facets[facet] = fc
+	}
+
+	for i := query.Offset; i < len(matches) && i < query.Offset+limit; i++ {
+		if i >= 0 {
+			result.Entries = append(result.Entries, matches[i].entry)
+		}
+	}
+	return result
+}
+
+// catalogFiltersMatch reports whether an entry has one of the values of
+// every filter in query
+func catalogFiltersMatch(e models.CatalogEntry, query models.CatalogQuery) bool {
+	if query.Source != "" && !strings.EqualFold(e.Source, query.Source) {
+		return false
+	}
+	if len(query.Categories) > 0 && !anyEqualFold(query.Categories, e.Category) {
+		return false
+	}
+	if len(query.Formats) > 0 && !anyEqualFold(query.Formats, e.Format) {
+		return false
+	}
+	if len(query.Vendors) > 0 && !anyEqualFold(query.Vendors, e.Vendor) {
+		return false
+	}
+	if len(query.Datamodels) > 0 && !anyEqualFold(query.Datamodels, e.Datamodels...) {
+		return false
+	}
+	if len(query.Techniques) > 0 {
+		found := false
+		for _, want := range query.Techniques {
+			want = strings.ToUpper(want)
+			for _, t := range e.Techniques {
+				if t == want || strings.HasPrefix(t, want+".") {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// anyEqualFold reports whether any of values equals any of have, ignoring
+// case
+func anyEqualFold(values []string, have ...string) bool {
+	for _, v := range values {
+		for _, h := range have {
+			if strings.EqualFold(v, h) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// catalogScore reports whether every term occurs in an entry's text, and
+// scores it higher where terms occur in its names and IDs
+func catalogScore(e models.CatalogEntry, terms []string) (int, bool) {
+	if len(terms) == 0 {
+		return 0, true
+	}
+	names := strings.ToLower(strings.Join([]string{e.TemplateID, e.Name, e.EventType, e.EventTypeName}, " "))
+	text := []string{e.Description, e.Vendor, e.Product, e.Sourcetype, e.Category, e.Format}
+	for _, t := range e.Techniques {
+		text = append(text, t, attackTechniques[t])
+	}
+	text = append(text, e.Datamodels...)
+	rest := strings.ToLower(strings.Join(text, " "))
+
+	score := 0
+	for _, term := range terms {
+		switch {
+		case strings.Contains(names, term):
+			score += 3
+		case strings.Contains(rest, term):
+			score++
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
@@ -0,0 +1,265 @@
+package generators
+
+import (
+	"fmt"
+
+	"siem-event-generator/models"
+)
+
+// templateChangelog records every change to a built-in template's output.
+// A template is at version 1 until it has an entry here; add one, with the
+// next version, whenever a template's fields or raw layout change, so
+// downstream parser tests pinned to the old version fail loudly.
+var templateChangelog = []models.TemplateChange{
+	webAccessChange("success"),
+	webAccessChange("redirect"),
+	webAccessChange("not_found"),
+	webAccessChange("unauthorized"),
+	webAccessChange("forbidden"),
+	webAccessChange("server_error"),
+	{
+		EventType: "aws_guardduty", TemplateID: "SSHBruteForce", Version: 2,
+		Summary: "remoteIpDetails carries the attacker's country code, city, coordinates and network owner",
+		Added: []string{
+			"service.action.networkConnectionAction.remoteIpDetails.organization",
+			"service.action.networkConnectionAction.remoteIpDetails.country.countryCode",
+			"service.action.networkConnectionAction.remoteIpDetails.city.cityName",
+			"service.action.networkConnectionAction.remoteIpDetails.geoLocation",
+		},
+	},
+	{
+		EventType: "aws_guardduty", TemplateID: "PortProbe", Version: 2,
+		Summary: "remoteIpDetails carries the prober's country code, city, coordinates and network owner",
+		Added: []string{
+			"service.action.portProbeAction.portProbeDetails.remoteIpDetails.organization",
+			"service.action.portProbeAction.portProbeDetails.remoteIpDetails.country.countryCode",
+			"service.action.portProbeAction.portProbeDetails.remoteIpDetails.city.cityName",
+			"service.action.portProbeAction.portProbeDetails.remoteIpDetails.geoLocation",
+		},
+	},
+	{
+		EventType: "aws_guardduty", TemplateID: "ConsoleLoginAnomaly", Version: 2,
+		Summary: "remoteIpDetails carries the country code, coordinates and network owner; the city is real instead of Unknown",
+		Added: []string{
+			"service.action.awsApiCallAction.remoteIpDetails.organization",
+			"service.action.awsApiCallAction.remoteIpDetails.country.countryCode",
+			"service.action.awsApiCallAction.remoteIpDetails.geoLocation",
+		},
+		Changed: []string{"service.action.awsApiCallAction.remoteIpDetails.city.cityName"},
+	},
+	{
+		EventType: "aws_guardduty", TemplateID: "BlackholeTraffic", Version: 2,
+		Summary: "remoteIpDetails carries the destination's country, city and coordinates, and the organization's ISP",
+		Added: []string{
+			"service.action.networkConnectionAction.remoteIpDetails.organization.isp",
+			"service.action.networkConnectionAction.remoteIpDetails.organization.org",
+			"service.action.networkConnectionAction.remoteIpDetails.country",
+			"service.action.networkConnectionAction.remoteIpDetails.city",
+			"service.action.networkConnectionAction.remoteIpDetails.geoLocation",
+		},
+		Changed: []string{"service.action.networkConnectionAction.remoteIpDetails.organization.asn"},
+	},
+	{
+		EventType: "linux_auditbeat", TemplateID: "user_login", Version: 2,
+		Summary: "source.geo matches the source IP's country and city, with coordinates and the AS that owns it",
+		Added: []string{
+			"source.geo.country_name",
+			"source.geo.location",
+			"source.as.number",
+			"source.as.organization.name",
+		},
+		Changed: []string{"source.geo.country_iso_code", "source.geo.city_name"},
+	},
+	{
+		EventType: "windows_security", TemplateID: "4624", Version: 2,
+		Summary: "Logons are made by the host's machine account for a pool user on a pool Windows host and open a session whose TargetLogonId later events reuse",
+		Changed: []string{"SubjectUserSid", "SubjectUserName", "SubjectDomainName", "SubjectLogonId", "TargetUserSid", "TargetDomainName", "WorkstationName", "Computer"},
+	},
+	{
+		EventType: "windows_security", TemplateID: "4688", Version: 2,
+		Summary: "Processes run in an open interactive logon session: the subject and Computer are the session's, and the target fields are empty as for a process started with the user's own token",
+		Changed: []string{"SubjectUserSid", "SubjectUserName", "SubjectDomainName", "SubjectLogonId", "TargetUserSid", "TargetUserName", "TargetDomainName", "TargetLogonId", "Computer"},
+	},
+	{
+		EventType: "windows_security", TemplateID: "4672", Version: 2,
+		Summary: "Privileges are assigned to an open logon session, whose user, LogonId and host the event carries",
+		Changed: []string{"SubjectUserSid", "SubjectUserName", "SubjectDomainName", "SubjectLogonId", "Computer"},
+	},
+	sysmonProcessChange("1", "Processes form trees: ParentProcessGuid names a process created earlier on the same host, and images, command lines and users follow the parent", "ProcessGuid", "Image", "CommandLine", "CurrentDirectory", "User", "LogonGuid", "LogonId", "TerminalSessionId", "IntegrityLevel", "Description", "OriginalFileName", "ParentProcessGuid", "ParentProcessId", "ParentImage", "ParentCommandLine", "ParentUser", "Computer"),
+	sysmonProcessChange("3", "Connections come from a process created earlier in the stream, on its host", "ProcessGuid", "ProcessId", "Image", "User", "SourceIp", "SourceHostname", "Computer"),
+	sysmonProcessChange("7", "Images load into a process created earlier in the stream", "ProcessGuid", "ProcessId", "Image", "User", "Computer"),
+	sysmonProcessChange("8", "Remote threads go between two processes on the same host", "SourceProcessGuid", "SourceProcessId", "SourceImage", "SourceUser", "TargetProcessGuid", "TargetProcessId", "TargetImage", "TargetUser", "Computer"),
+	sysmonProcessChange("10", "lsass.exe is opened by a process created earlier in the stream, on its host", "SourceProcessGuid", "SourceProcessId", "SourceImage", "SourceUser", "TargetProcessGuid", "TargetProcessId", "Computer"),
+	sysmonProcessChange("11", "Files are created by a process created earlier in the stream, in its user's temp folder", "ProcessGuid", "ProcessId", "Image", "User", "TargetFilename", "Computer"),
+	sysmonProcessChange("22", "DNS queries come from a process created earlier in the stream", "ProcessGuid", "ProcessId", "Image", "User", "Computer"),
+	cloudTrailErrorChange("AssumeRole"),
+	cloudTrailErrorChange("CreateUser"),
+	cloudTrailErrorChange("DeleteUser"),
+	cloudTrailErrorChange("PutBucketPolicy"),
+	cloudTrailErrorChange("AuthorizeSecurityGroupIngress"),
+	cloudTrailErrorChange("RunInstances"),
+	cloudTrailErrorChange("StopInstances"),
+	cloudTrailErrorChange("CreateAccessKey"),
+	cloudTrailErrorChange("GetSecretValue"),
+	vpcFlowChange("accept_inbound"),
+	vpcFlowChange("accept_outbound"),
+	vpcFlowChange("reject_inbound"),
+	vpcFlowChange("reject_outbound"),
+	{
+		EventType: "cisco_asa", TemplateID: "113039", Version: 2,
+		Summary: "Opens a VPN session: users come from the entity pool and addresses from the 10.250.0.0/20 client pool, and later AnyConnect events and the 113019 disconnect reuse them",
+		Changed: []string{"username", "group", "assigned_ip"},
+	},
+	asaVPNSessionChange("722022", "hostname", "group", "username", "public_ip"),
+	asaVPNSessionChange("722051", "hostname", "group", "username", "public_ip", "assigned_ip"),
+	asaVPNSessionChange("734001", "hostname", "username", "public_ip"),
+	falconStreamChange("detection"),
+	falconStreamChange("process"),
+	falconStreamChange("network"),
+	falconStreamChange("dns"),
+	falconStreamChange("file_write"),
+	falconStreamChange("auth_activity"),
+}
+
+// falconStreamChange is the change to a CrowdStrike template when events
+// were framed like the Streaming API
+func falconStreamChange(templateID string) models.TemplateChange {
+	return models.TemplateChange{
+		EventType: "crowdstrike", TemplateID: templateID, Version: 2,
+		Summary: "Events belong to one tenant and carry consecutive stream offsets",
+		Changed: []string{"metadata.customerIDString", "metadata.offset", "event.cid"},
+	}
+}
+
+// asaVPNSessionChange is the change to a Cisco ASA AnyConnect template when
+// its events started belonging to an open VPN session
+func asaVPNSessionChange(templateID string, changed ...string) models.TemplateChange {
+	return models.TemplateChange{
+		EventType: "cisco_asa", TemplateID: templateID, Version: 2,
+		Summary: "Events belong to a VPN session opened by a 113039 and share its ASA, user, group and addresses",
+		Changed: changed,
+	}
+}
+
+// vpcFlowChange is the change to a version 2 flow log template when flows
+// moved onto the AWS environment's instances
+func vpcFlowChange(templateID string) models.TemplateChange {
+	return models.TemplateChange{
+		EventType: "aws_vpcflow", TemplateID: templateID, Version: 2,
+		Summary: "Records belong to an instance of the shared AWS environment; the raw line ends with log-status OK instead of - and shows overridden fields",
+		Changed: []string{"account_id", "interface_id", "srcaddr", "dstaddr", "log_status"},
+	}
+}
+
+// cloudTrailErrorChange is the change to a CloudTrail API call template when
+// calls started failing at the configured error rate
+func cloudTrailErrorChange(templateID string) models.TemplateChange {
+	return models.TemplateChange{
+		EventType: "aws_cloudtrail", TemplateID: templateID, Version: 2,
+		Summary: "Calls fail at the configured error rate (5% by default) with the service's errorCode and errorMessage and no responseElements",
+		Added:   []string{"errorCode", "errorMessage"},
+		Changed: []string{"responseElements"},
+	}
+}
+
+// sysmonProcessChange is the change to a Sysmon template when its events
+// started naming processes from the process tree
+func sysmonProcessChange(templateID, summary string, changed ...string) models.TemplateChange {
+	return models.TemplateChange{
+		EventType: "windows_sysmon", TemplateID: templateID, Version: 2,
+		Summary: summary,
+		Changed: changed,
+	}
+}
+
+// webAccessChange is the change shared by the fixed-status access log
+// templates when the web server generator learned realistic traffic
+func webAccessChange(templateID string) models.TemplateChange {
+	return models.TemplateChange{
+		EventType: "webserver", TemplateID: templateID, Version: 2,
+		Summary: "Access lines name the virtual host and may use HTTP/2.0; user agents, referers and sizes follow the kind of request",
+		Added:   []string{"vhost"},
+		Changed: []string{"protocol", "user_agent", "referer", "bytes_sent"},
+	}
+}
+
+// TemplateChanges returns the changelog entries for an event type, or for
+// one of its templates when templateID is set, that are newer than since
+func TemplateChanges(eventType, templateID string, since int) []models.TemplateChange {
+	changes := make([]models.TemplateChange, 0)
+	for _, c := range templateChangelog {
+		if (eventType == "" || c.EventType == eventType) && (templateID == "" || c.TemplateID == templateID) && c.Version > since {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// versionedGenerator fills in its templates' schema versions and
+// deprecations from the changelog, and samples the field distributions its
+// templates declare
+type versionedGenerator struct {
+	Generator
+	initial Generator // Copy of Generator as registered, for seeded streams
+}
+
+func (v versionedGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	return v.Generator.Generate(templateID, withDeclaredDistributions(v.Generator, templateID, overrides))
+}
+
+func (v versionedGenerator) GetTemplates() []models.EventTemplate {
+	templates := v.Generator.GetTemplates()
+	eventType := v.GetEventType().ID
+	for i := range templates {
+		t := &templates[i]
+		for _, c := range templateChangelog {
+			if c.EventType != eventType || c.TemplateID != t.ID || c.Version < t.SchemaVersion {
+				continue
+			}
+			t.SchemaVersion = c.Version
+			t.Deprecated = t.Deprecated || c.Deprecated
+			if c.ReplacedBy != "" {
+				t.ReplacedBy = c.ReplacedBy
+			}
+		}
+		if t.SchemaVersion == 0 {
+			t.SchemaVersion = 1
+		}
+	}
+	return templates
+}
+
+// unwrap returns the generator a registry entry was registered with
+func unwrap(g Generator) Generator {
+	if v, ok := g.(versionedGenerator); ok {
+		return v.Generator
+	}
+	return g
+}
+
+// CheckTemplate returns the template a request resolves to, failing when the
+// request pins a schema version the template is no longer at. Pinning 0
+// accepts any version.
+func CheckTemplate(g Generator, templateID string, schemaVersion int) (*models.EventTemplate, error) {
+	t, err := templateByID(g, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if schemaVersion != 0 && t.SchemaVersion != schemaVersion {
+		eventType := g.GetEventType().ID
+		return nil, fmt.Errorf("template %s of %s is at schema version %d, not %d; see /api/templates/changelog?event_type=%s&template_id=%s&since=%d",
+			t.ID, eventType, t.SchemaVersion, schemaVersion, eventType, t.ID, schemaVersion)
+	}
+	return t, nil
+}
+
+// DeprecationWarning returns a warning for a deprecated template, or ""
+func DeprecationWarning(t *models.EventTemplate) string {
+	if !t.Deprecated {
+		return ""
+	}
+	if t.ReplacedBy != "" {
+		return fmt.Sprintf("template %s is deprecated; use %s instead", t.ID, t.ReplacedBy)
+	}
+	return fmt.Sprintf("template %s is deprecated", t.ID)
+}
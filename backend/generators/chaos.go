@@ -0,0 +1,204 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"siem-event-generator/models"
+)
+
+// Limits of the oversized padding
+const (
+	defaultOversizeBytes = 64 << 10
+	maxOversizeBytes     = 16 << 20
+)
+
+// chaosKinds lists every way to break an event in a fixed order
+var chaosKinds = []string{
+	models.ChaosTruncate, models.ChaosSyntax, models.ChaosOversized,
+	models.ChaosNonUTF8, models.ChaosShuffle,
+}
+
+// invalidBytes are inserted by non_utf8: a lone continuation byte, an
+// overlong encoding, a truncated sequence and control characters
+var invalidBytes = [][]byte{
+	{0xff, 0xfe}, {0xc0, 0xaf}, {0xe2, 0x28, 0xa1}, {0x80}, {0x00}, {0x1b, '['},
+}
+
+// Chaos breaks a share of events in the ways its config allows
+type Chaos struct {
+	rate     float64
+	kinds    []string
+	oversize int
+}
+
+// NewChaos checks cfg and returns a Chaos for it
+func NewChaos(cfg models.ChaosConfig) (*Chaos, error) {
+	if cfg.Rate <= 0 || cfg.Rate > 1 {
+		return nil, fmt.Errorf("chaos rate must be above 0 and at most 1")
+	}
+	c := &Chaos{rate: cfg.Rate, kinds: cfg.Kinds, oversize: cfg.OversizeBytes}
+	if len(c.kinds) == 0 {
+		c.kinds = chaosKinds
+	}
+	for _, kind := range c.kinds {
+		found := false
+		for _, k := range chaosKinds {
+			found = found || k == kind
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown chaos kind %q", kind)
+		}
+	}
+	if c.oversize < 0 || c.oversize > maxOversizeBytes {
+		return nil, fmt.Errorf("chaos oversize_bytes must be at most %d", maxOversizeBytes)
+	}
+	if c.oversize == 0 {
+		c.oversize = defaultOversizeBytes
+	}
+	return c, nil
+}
+
+// Apply returns event with its raw event broken at the chaos rate, and
+// whether it was. Kinds that do not fit the event's format, such as
+// shuffling a line with no fields to move, fall back to truncation.
+func (c *Chaos) Apply(event *models.GeneratedEvent) (*models.GeneratedEvent, bool) {
+	if c == nil || len(event.RawEvent) < 2 || Unseeded.float64() >= c.rate {
+		return event, false
+	}
+	raw := event.RawEvent
+	broken, ok := "", false
+	switch c.kinds[int(Unseeded.float64()*float64(len(c.kinds)))] {
+	case models.ChaosSyntax:
+		broken, ok = breakSyntax(raw)
+	case models.ChaosOversized:
+		broken, ok = oversize(raw, event.Fields, c.oversize)
+	case models.ChaosNonUTF8:
+		b := invalidBytes[int(Unseeded.float64()*float64(len(invalidBytes)))]
+		at := int(Unseeded.float64() * float64(len(raw)))
+		broken, ok = raw[:at]+string(b)+raw[at:], true
+	case models.ChaosShuffle:
+		broken, ok = shuffleFields(raw)
+	}
+	if !ok {
+		// Keep between a tenth and nine tenths of the event
+		broken = raw[:len(raw)/10+int(Unseeded.float64()*float64(len(raw)*8/10))]
+	}
+
+	chaotic := *event
+	chaotic.RawEvent = broken
+	return &chaotic, true
+}
+
+// breakSyntax drops the closing brace of a JSON event or a closing tag of
+// an XML one, or leaves a quote unbalanced in any other event
+func breakSyntax(raw string) (string, bool) {
+	trimmed := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}"):
+		return strings.TrimSuffix(trimmed, "}"), true
+	case strings.HasPrefix(trimmed, "<") && strings.HasSuffix(trimmed, ">"):
+		var closing []int
+		for i := 0; ; {
+			next := strings.Index(raw[i:], "</")
+			if next < 0 {
+				break
+			}
+			closing = append(closing, i+next)
+			i += next + 2
+		}
+		if len(closing) == 0 {
+			break
+		}
+		start := closing[int(Unseeded.float64()*float64(len(closing)))]
+		end := strings.IndexByte(raw[start:], '>')
+		if end < 0 {
+			break
+		}
+		return raw[:start] + raw[start+end+1:], true
+	}
+	at := strings.IndexAny(raw, " =:,")
+	if at < 0 {
+		return "", false
+	}
+	return raw[:at+1] + `"` + raw[at+1:], true
+}
+
+// oversize pads the first field value found in the raw event to n bytes
+// more, or the end of the event when no value is found
+func oversize(raw string, fields map[string]interface{}, n int) (string, bool) {
+	padding := strings.Repeat("A", n)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s, ok := fields[name].(string)
+		if !ok || len(s) < 3 {
+			continue
+		}
+		if i := strings.Index(raw, s); i >= 0 {
+			return raw[:i+len(s)] + padding + raw[i+len(s):], true
+		}
+	}
+	return raw + " " + padding, true
+}
+
+// shuffleFields writes a JSON object's fields, or the key=value pairs of a
+// line, in a random order
+func shuffleFields(raw string) (string, bool) {
+	if trimmed := strings.TrimSpace(raw); strings.HasPrefix(trimmed, "{") {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(trimmed), &fields); err != nil || len(fields) < 2 {
+			return "", false
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		shuffle(names)
+		var b bytes.Buffer
+		b.WriteByte('{')
+		for i, name := range names {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.Write(quotedKey(name))
+			json.Compact(&b, fields[name])
+		}
+		b.WriteByte('}')
+		return b.String(), true
+	}
+
+	tokens := strings.Split(raw, " ")
+	var pairs []int
+	for i, t := range tokens {
+		if strings.Contains(t, "=") && !strings.ContainsAny(t, `"'`) {
+			pairs = append(pairs, i)
+		}
+	}
+	if len(pairs) < 2 {
+		return "", false
+	}
+	moved := make([]string, len(pairs))
+	for i, at := range pairs {
+		moved[i] = tokens[at]
+	}
+	shuffle(moved)
+	for i, at := range pairs {
+		tokens[at] = moved[i]
+	}
+	return strings.Join(tokens, " "), true
+}
+
+// shuffle puts s in a random order
+func shuffle(s []string) {
+	for i := len(s) - 1; i > 0; i-- {
+		j := int(Unseeded.float64() * float64(i+1))
+		s[i], s[j] = s[j], s[i]
+	}
+}
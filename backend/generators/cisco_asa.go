@@ -25,7 +25,7 @@ func (g *CiscoASAGenerator) GetEventType() models.EventType {
 		Name:        "Cisco ASA",
 		Category:    "network",
 		Description: "Cisco ASA Firewall events including connections, ACL denies, and VPN sessions",
-		EventIDs:    []string{"106001", "106006", "106015", "106023", "302013", "302014", "302015", "302016", "113039", "111008"},
+		EventIDs:    []string{"106001", "106006", "106015", "106023", "302013", "302014", "302015", "302016", "113039", "113019", "113005", "111008", "722022", "722023", "722051", "734001", "305011", "305012"},
 	}
 }
 
@@ -72,6 +72,22 @@ func (g *CiscoASAGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "syslog",
 			Description: "Group user IP VPN session connected",
 		},
+		{
+			ID:          "113019",
+			Name:        "VPN Session Disconnected",
+			Category:    "cisco_asa",
+			EventID:     "113019",
+			Format:      "syslog",
+			Description: "VPN session disconnected with its duration, byte counts and reason",
+		},
+		{
+			ID:          "113005",
+			Name:        "VPN Authentication Rejected",
+			Category:    "cisco_asa",
+			EventID:     "113005",
+			Format:      "syslog",
+			Description: "AAA user authentication rejected for a VPN login",
+		},
 		{
 			ID:          "111008",
 			Name:        "User Command",
@@ -96,6 +112,54 @@ func (g *CiscoASAGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "syslog",
 			Description: "Deny inbound connection",
 		},
+		{
+			ID:          "722022",
+			Name:        "AnyConnect Tunnel Established",
+			Category:    "cisco_asa",
+			EventID:     "722022",
+			Format:      "syslog",
+			Description: "AnyConnect TLS or DTLS tunnel established for a VPN session",
+		},
+		{
+			ID:          "722023",
+			Name:        "AnyConnect Tunnel Terminated",
+			Category:    "cisco_asa",
+			EventID:     "722023",
+			Format:      "syslog",
+			Description: "AnyConnect TLS or DTLS tunnel terminated for a VPN session",
+		},
+		{
+			ID:          "722051",
+			Name:        "AnyConnect Address Assigned",
+			Category:    "cisco_asa",
+			EventID:     "722051",
+			Format:      "syslog",
+			Description: "Client address assigned to an AnyConnect session",
+		},
+		{
+			ID:          "734001",
+			Name:        "DAP Records Selected",
+			Category:    "cisco_asa",
+			EventID:     "734001",
+			Format:      "syslog",
+			Description: "Dynamic access policy records selected for an AnyConnect connection",
+		},
+		{
+			ID:          "305011",
+			Name:        "Translation Built",
+			Category:    "cisco_asa",
+			EventID:     "305011",
+			Format:      "syslog",
+			Description: "Built dynamic PAT translation",
+		},
+		{
+			ID:          "305012",
+			Name:        "Translation Teardown",
+			Category:    "cisco_asa",
+			EventID:     "305012",
+			Format:      "syslog",
+			Description: "Teardown dynamic PAT translation",
+		},
 	}
 }
 
@@ -112,12 +176,28 @@ func (g *CiscoASAGenerator) Generate(templateID string, overrides map[string]int
 		return g.generate106023(overrides)
 	case "113039":
 		return g.generate113039(overrides)
+	case "113019":
+		return g.generate113019(overrides)
+	case "113005":
+		return g.generate113005(overrides)
 	case "111008":
 		return g.generate111008(overrides)
 	case "106001":
 		return g.generate106001(overrides)
 	case "106006":
 		return g.generate106006(overrides)
+	case "722022":
+		return g.generate722022(overrides)
+	case "722023":
+		return g.generate722023(overrides)
+	case "722051":
+		return g.generate722051(overrides)
+	case "734001":
+		return g.generate734001(overrides)
+	case "305011":
+		return g.generate305011(overrides)
+	case "305012":
+		return g.generate305012(overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
@@ -324,32 +404,28 @@ func (g *CiscoASAGenerator) generate106023(overrides map[string]interface{}) (*m
 	}, nil
 }
 
-// generate113039 creates a VPN session connected event
+// generate113039 creates a VPN session connected event and opens the
+// session the AnyConnect events that follow belong to
 func (g *CiscoASAGenerator) generate113039(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
-	hostname := g.RandomASAHost()
-	groups := []string{"VPN-Users", "RemoteAccess", "Contractors", "Admins", "Engineering"}
-
-	username := g.RandomUsername()
-	groupName := g.RandomChoice(groups)
-	publicIP := g.RandomIPv4External()
-	assignedIP := g.RandomIPv4Internal()
+	session := g.newVPNSession(now)
+	ASASessions.openVPN(session)
 
 	fields := map[string]interface{}{
-		"hostname":    hostname,
+		"hostname":    session.Hostname,
 		"message_id":  "113039",
-		"group":       groupName,
-		"username":    username,
-		"public_ip":   publicIP,
-		"assigned_ip": assignedIP,
+		"group":       session.Group,
+		"username":    session.Username,
+		"public_ip":   session.PublicIP,
+		"assigned_ip": session.AssignedIP,
 		"tunnel_type": "SSL",
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	rawEvent := fmt.Sprintf("%s %%ASA-6-113039: Group <%s> User <%s> IP <%s> AnyConnect parent session started.",
-		g.buildSyslogHeader(now, 20, 6, hostname),
-		groupName, username, publicIP)
+		g.buildSyslogHeader(now, 20, 6, session.Hostname),
+		session.Group, session.Username, session.PublicIP)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -481,3 +557,124 @@ func (g *CiscoASAGenerator) generate106006(overrides map[string]interface{}) (*m
 		Sourcetype: "cisco:asa",
 	}, nil
 }
+
+// anyConnectGroups are the tunnel groups remote users connect through
+var anyConnectGroups = []string{"RemoteAccess", "VPN-Users", "Contractors", "Admins", "Engineering"}
+
+// generate722022 creates an AnyConnect tunnel established event for an
+// open VPN session
+func (g *CiscoASAGenerator) generate722022(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := g.activeVPNSession(now)
+	hostname := session.Hostname
+
+	username := session.Username
+	groupName := session.Group
+	publicIP := session.PublicIP
+	transport := g.WeightedChoice([]string{"TCP", "UDP"}, []float64{35, 65})
+
+	fields := map[string]interface{}{
+		"hostname":    hostname,
+		"message_id":  "722022",
+		"group":       groupName,
+		"username":    username,
+		"public_ip":   publicIP,
+		"transport":   transport,
+		"tunnel_type": map[string]string{"TCP": "SSL", "UDP": "DTLS"}[transport],
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	rawEvent := fmt.Sprintf("%s %%ASA-6-722022: Group <%s> User <%s> IP <%s> %s SVC connection established without compression",
+		g.buildSyslogHeader(now, 20, 6, hostname),
+		groupName, username, publicIP, transport)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "cisco_asa",
+		EventID:    "722022",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "cisco:asa",
+	}, nil
+}
+
+// generate722051 creates an AnyConnect address assignment event for an
+// open VPN session
+func (g *CiscoASAGenerator) generate722051(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := g.activeVPNSession(now)
+	hostname := session.Hostname
+
+	username := session.Username
+	groupName := session.Group
+	publicIP := session.PublicIP
+	assignedIP := session.AssignedIP
+
+	fields := map[string]interface{}{
+		"hostname":    hostname,
+		"message_id":  "722051",
+		"group":       groupName,
+		"username":    username,
+		"public_ip":   publicIP,
+		"assigned_ip": assignedIP,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	rawEvent := fmt.Sprintf("%s %%ASA-4-722051: Group <%s> User <%s> IP <%s> IPv4 Address <%s> IPv6 address <::> assigned to session",
+		g.buildSyslogHeader(now, 20, 4, hostname),
+		groupName, username, publicIP, assignedIP)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "cisco_asa",
+		EventID:    "722051",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "cisco:asa",
+	}, nil
+}
+
+// generate734001 creates a DAP record selection event for an open VPN
+// session
+func (g *CiscoASAGenerator) generate734001(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := g.activeVPNSession(now)
+	hostname := session.Hostname
+
+	username := session.Username
+	publicIP := session.PublicIP
+	records := g.WeightedChoice([]string{
+		"DfltAccessPolicy",
+		"Managed-Corporate-Device",
+		"Managed-Corporate-Device, Full-Tunnel",
+		"Posture-Failed-Quarantine",
+	}, []float64{20, 45, 25, 10})
+
+	fields := map[string]interface{}{
+		"hostname":    hostname,
+		"message_id":  "734001",
+		"username":    username,
+		"public_ip":   publicIP,
+		"dap_records": records,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	rawEvent := fmt.Sprintf("%s %%ASA-6-734001: DAP: User %s, Addr %s, Connection AnyConnect: The following DAP records were selected for this connection: %s",
+		g.buildSyslogHeader(now, 20, 6, hostname),
+		username, publicIP, records)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "cisco_asa",
+		EventID:    "734001",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "cisco:asa",
+	}, nil
+}
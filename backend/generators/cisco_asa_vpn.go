@@ -0,0 +1,434 @@
+package generators
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// asaVPNSession is an open AnyConnect session. Ends is when the user will
+// disconnect, chosen when the session starts.
+type asaVPNSession struct {
+	Hostname   string
+	Group      string
+	Username   string
+	PublicIP   string
+	AssignedIP string
+	Started    time.Time
+	Ends       time.Time
+}
+
+// asaXlate is an open dynamic PAT translation
+type asaXlate struct {
+	Hostname   string
+	Protocol   string
+	RealIP     string
+	RealPort   int
+	MappedIP   string
+	MappedPort int
+	Started    time.Time
+	Ends       time.Time
+}
+
+const (
+	// maxASAVPNSessions and maxASAXlates bound what is kept open during
+	// long runs; the oldest are forgotten first
+	maxASAVPNSessions = 1000
+	maxASAXlates      = 5000
+	// asaOverdue is how long past its end an unclosed session or xlate is
+	// kept before it is forgotten, so reported durations stay plausible
+	asaOverdue = time.Hour
+)
+
+// ASASessionTracker keeps the AnyConnect sessions started by 113039 events
+// and the translations built by 305011 events, so 113019 disconnects and
+// 305012 teardowns close them with the real duration
+type ASASessionTracker struct {
+	mu       sync.Mutex
+	sessions []*asaVPNSession
+	xlates   []*asaXlate
+}
+
+// ASASessions is the tracker shared by the Cisco ASA generator
+var ASASessions = &ASASessionTracker{}
+
+// openVPN records a new AnyConnect session
+func (t *ASASessionTracker) openVPN(s *asaVPNSession) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(s.Started)
+	if len(t.sessions) >= maxASAVPNSessions {
+		t.sessions = t.sessions[1:]
+	}
+	t.sessions = append(t.sessions, s)
+}
+
+// pickVPN returns a random open session, or nil when none is open
+func (t *ASASessionTracker) pickVPN(rnd *RandomStream, now time.Time) *asaVPNSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(now)
+	if len(t.sessions) == 0 {
+		return nil
+	}
+	return t.sessions[rnd.intn(len(t.sessions))]
+}
+
+// closeVPN removes and returns the session due to end first, or nil when
+// none has reached its end
+func (t *ASASessionTracker) closeVPN(now time.Time) *asaVPNSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(now)
+	due := -1
+	for i, s := range t.sessions {
+		if !s.Ends.After(now) && (due < 0 || s.Ends.Before(t.sessions[due].Ends)) {
+			due = i
+		}
+	}
+	if due < 0 {
+		return nil
+	}
+	s := t.sessions[due]
+	t.sessions = append(t.sessions[:due], t.sessions[due+1:]...)
+	return s
+}
+
+// openXlate records a new translation
+func (t *ASASessionTracker) openXlate(x *asaXlate) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(x.Started)
+	if len(t.xlates) >= maxASAXlates {
+		t.xlates = t.xlates[1:]
+	}
+	t.xlates = append(t.xlates, x)
+}
+
+// closeXlate removes and returns the translation due to time out first, or
+// nil when none has reached its timeout
+func (t *ASASessionTracker) closeXlate(now time.Time) *asaXlate {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(now)
+	due := -1
+	for i, x := range t.xlates {
+		if !x.Ends.After(now) && (due < 0 || x.Ends.Before(t.xlates[due].Ends)) {
+			due = i
+		}
+	}
+	if due < 0 {
+		return nil
+	}
+	x := t.xlates[due]
+	t.xlates = append(t.xlates[:due], t.xlates[due+1:]...)
+	return x
+}
+
+// Count returns the number of open sessions and translations
+func (t *ASASessionTracker) Count() (sessions, xlates int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.sessions), len(t.xlates)
+}
+
+// expire drops sessions and translations more than asaOverdue past their
+// end. Callers hold t.mu.
+func (t *ASASessionTracker) expire(now time.Time) {
+	sessions := t.sessions[:0]
+	for _, s := range t.sessions {
+		if now.Sub(s.Ends) <= asaOverdue {
+			sessions = append(sessions, s)
+		}
+	}
+	t.sessions = sessions
+
+	xlates := t.xlates[:0]
+	for _, x := range t.xlates {
+		if now.Sub(x.Ends) <= asaOverdue {
+			xlates = append(xlates, x)
+		}
+	}
+	t.xlates = xlates
+}
+
+// vpnSessionLength picks how long a remote user stays connected: a quick
+// check of mail, an afternoon, or a full working day
+func (g *CiscoASAGenerator) vpnSessionLength() time.Duration {
+	switch g.WeightedChoice([]string{"short", "medium", "day"}, []float64{25, 50, 25}) {
+	case "short":
+		return time.Duration(g.RandomInt(120, 900)) * time.Second
+	case "medium":
+		return time.Duration(g.RandomInt(1800, 4*3600)) * time.Second
+	}
+	return time.Duration(g.RandomInt(4*3600, 10*3600)) * time.Second
+}
+
+// newVPNSession makes up an AnyConnect session for a pool user
+func (g *CiscoASAGenerator) newVPNSession(now time.Time) *asaVPNSession {
+	return &asaVPNSession{
+		Hostname:   g.RandomASAHost(),
+		Group:      g.ZipfChoice(anyConnectGroups),
+		Username:   Entities.RandomUser(g.rnd).Username,
+		PublicIP:   g.RandomIPv4External(),
+		AssignedIP: fmt.Sprintf("10.250.%d.%d", g.RandomInt(0, 15), g.RandomInt(2, 254)),
+		Started:    now,
+		Ends:       now.Add(g.vpnSessionLength()),
+	}
+}
+
+// activeVPNSession returns an open session for an event of a connected
+// user. When none is open it opens one whose 113039 was never generated,
+// so the events that follow still agree on the user and addresses.
+func (g *CiscoASAGenerator) activeVPNSession(now time.Time) *asaVPNSession {
+	if s := ASASessions.pickVPN(g.rnd, now); s != nil {
+		return s
+	}
+	s := g.newVPNSession(now)
+	ASASessions.openVPN(s)
+	return s
+}
+
+// asaDuration formats a duration the way xlate teardowns do
+func asaDuration(d time.Duration) string {
+	s := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+// asaVPNDuration formats a duration the way VPN session events do
+func asaVPNDuration(d time.Duration) string {
+	s := int(d.Seconds())
+	return fmt.Sprintf("%dh:%02dm:%02ds", s/3600, s/60%60, s%60)
+}
+
+// generate113019 creates a VPN session disconnected event that closes the
+// session due to end first. With none due it disconnects a session started
+// before the generator did.
+func (g *CiscoASAGenerator) generate113019(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := ASASessions.closeVPN(now)
+	if session == nil {
+		session = g.newVPNSession(now.Add(-g.vpnSessionLength()))
+	}
+	length := now.Sub(session.Started)
+
+	// The client mostly downloads: bytes sent to it outweigh bytes received
+	bytesXmt := (int(length.Seconds()) + 1) * g.RandomInt(2000, 40000)
+	bytesRcv := bytesXmt / g.RandomInt(3, 10)
+	reason := g.WeightedChoice([]string{"User Requested", "Idle Timeout", "Lost Service", "Max time exceeded", "Administrator Reset", "Port Preempted"},
+		[]float64{55, 20, 12, 5, 3, 5})
+
+	fields := map[string]interface{}{
+		"hostname":     session.Hostname,
+		"message_id":   "113019",
+		"group":        session.Group,
+		"username":     session.Username,
+		"public_ip":    session.PublicIP,
+		"session_type": "AnyConnect-Parent",
+		"duration":     asaVPNDuration(length),
+		"bytes_xmt":    bytesXmt,
+		"bytes_rcv":    bytesRcv,
+		"reason":       reason,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	rawEvent := fmt.Sprintf("%s %%ASA-4-113019: Group = %v, Username = %v, IP = %v, Session disconnected. Session Type: %v, Duration: %v, Bytes xmt: %v, Bytes rcv: %v, Reason: %v",
+		g.buildSyslogHeader(now, 20, 4, fmt.Sprint(fields["hostname"])),
+		fields["group"], fields["username"], fields["public_ip"], fields["session_type"], fields["duration"], fields["bytes_xmt"], fields["bytes_rcv"], fields["reason"])
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "cisco_asa",
+		EventID:    "113019",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "cisco:asa",
+	}, nil
+}
+
+// generate722023 creates an AnyConnect tunnel terminated event for an open
+// session, such as the DTLS tunnel dropping when the client changes network
+func (g *CiscoASAGenerator) generate722023(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := g.activeVPNSession(now)
+	transport := g.WeightedChoice([]string{"TCP", "UDP"}, []float64{35, 65})
+
+	fields := map[string]interface{}{
+		"hostname":    session.Hostname,
+		"message_id":  "722023",
+		"group":       session.Group,
+		"username":    session.Username,
+		"public_ip":   session.PublicIP,
+		"transport":   transport,
+		"tunnel_type": map[string]string{"TCP": "SSL", "UDP": "DTLS"}[transport],
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	rawEvent := fmt.Sprintf("%s %%ASA-6-722023: Group <%v> User <%v> IP <%v> %v SVC connection terminated without compression",
+		g.buildSyslogHeader(now, 20, 6, fmt.Sprint(fields["hostname"])),
+		fields["group"], fields["username"], fields["public_ip"], fields["transport"])
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "cisco_asa",
+		EventID:    "722023",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "cisco:asa",
+	}, nil
+}
+
+// asaAAAServers are the RADIUS servers remote access logins are checked
+// against
+var asaAAAServers = []string{"10.1.20.11", "10.1.20.12"}
+
+// generate113005 creates a rejected VPN login event
+func (g *CiscoASAGenerator) generate113005(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	hostname := g.RandomASAHost()
+
+	username := Entities.RandomUser(g.rnd).Username
+	if g.RandomInt(1, 100) <= 15 {
+		// Password spraying tries names that do not exist
+		username = g.RandomChoice([]string{"admin", "test", "vpn", "guest", "administrator", "support"})
+	}
+
+	fields := map[string]interface{}{
+		"hostname":   hostname,
+		"message_id": "113005",
+		"reason":     g.WeightedChoice([]string{"AAA failure", "Invalid password", "Account locked out"}, []float64{50, 40, 10}),
+		"server":     g.RandomChoice(asaAAAServers),
+		"username":   username,
+		"public_ip":  g.InjectIOC(models.IOCTypeIP, g.RandomIPv4External()),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	rawEvent := fmt.Sprintf("%s %%ASA-6-113005: AAA user authentication Rejected : reason = %v : server = %v : user = %v : user IP = %v",
+		g.buildSyslogHeader(now, 20, 6, hostname),
+		fields["reason"], fields["server"], fields["username"], fields["public_ip"])
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "cisco_asa",
+		EventID:    "113005",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "cisco:asa",
+	}, nil
+}
+
+// generate305011 creates a dynamic PAT translation built event and keeps
+// the translation open until its idle timeout
+func (g *CiscoASAGenerator) generate305011(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	protocol := g.WeightedChoice([]string{"TCP", "UDP", "ICMP"}, []float64{70, 25, 5})
+	realPort := g.RandomInt(1024, 65535)
+	xlate := &asaXlate{
+		Hostname:   g.RandomASAHost(),
+		Protocol:   protocol,
+		RealIP:     g.RandomIPv4Internal(),
+		RealPort:   realPort,
+		MappedIP:   g.RandomIPv4External(),
+		MappedPort: g.RandomInt(1024, 65535),
+		Started:    now,
+		// PAT xlates outlive their last connection by the 30 second
+		// pat-xlate timeout
+		Ends: now.Add(time.Duration(30+g.RandomInt(1, 900)) * time.Second),
+	}
+	if protocol == "ICMP" {
+		xlate.RealPort = g.RandomInt(1, 65535)
+		xlate.MappedPort = xlate.RealPort
+	}
+	ASASessions.openXlate(xlate)
+
+	fields := map[string]interface{}{
+		"hostname":      xlate.Hostname,
+		"message_id":    "305011",
+		"protocol":      xlate.Protocol,
+		"src_interface": "inside",
+		"src_ip":        xlate.RealIP,
+		"src_port":      xlate.RealPort,
+		"dst_interface": "outside",
+		"mapped_ip":     xlate.MappedIP,
+		"mapped_port":   xlate.MappedPort,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	rawEvent := fmt.Sprintf("%s %%ASA-6-305011: Built dynamic %v translation from inside:%v/%v to outside:%v/%v",
+		g.buildSyslogHeader(now, 20, 6, fmt.Sprint(fields["hostname"])),
+		fields["protocol"], fields["src_ip"], fields["src_port"], fields["mapped_ip"], fields["mapped_port"])
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "cisco_asa",
+		EventID:    "305011",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "cisco:asa",
+	}, nil
+}
+
+// generate305012 creates a dynamic translation teardown event for the
+// translation due to time out first. With none due it tears down one built
+// before the generator started.
+func (g *CiscoASAGenerator) generate305012(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	xlate := ASASessions.closeXlate(now)
+	if xlate == nil {
+		xlate = &asaXlate{
+			Hostname:   g.RandomASAHost(),
+			Protocol:   g.WeightedChoice([]string{"TCP", "UDP"}, []float64{75, 25}),
+			RealIP:     g.RandomIPv4Internal(),
+			RealPort:   g.RandomInt(1024, 65535),
+			MappedIP:   g.RandomIPv4External(),
+			MappedPort: g.RandomInt(1024, 65535),
+			Started:    now.Add(-time.Duration(30+g.RandomInt(1, 900)) * time.Second),
+		}
+	}
+
+	fields := map[string]interface{}{
+		"hostname":      xlate.Hostname,
+		"message_id":    "305012",
+		"protocol":      xlate.Protocol,
+		"src_interface": "inside",
+		"src_ip":        xlate.RealIP,
+		"src_port":      xlate.RealPort,
+		"dst_interface": "outside",
+		"mapped_ip":     xlate.MappedIP,
+		"mapped_port":   xlate.MappedPort,
+		"duration":      asaDuration(now.Sub(xlate.Started)),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	rawEvent := fmt.Sprintf("%s %%ASA-6-305012: Teardown dynamic %v translation from inside:%v/%v to outside:%v/%v duration %v",
+		g.buildSyslogHeader(now, 20, 6, fmt.Sprint(fields["hostname"])),
+		fields["protocol"], fields["src_ip"], fields["src_port"], fields["mapped_ip"], fields["mapped_port"], fields["duration"])
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "cisco_asa",
+		EventID:    "305012",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "cisco:asa",
+	}, nil
+}
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -165,7 +164,7 @@ func (g *CiscoFirepowerGenerator) generateIntrusion(overrides map[string]interfa
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +223,7 @@ func (g *CiscoFirepowerGenerator) generateConnection(overrides map[string]interf
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -263,7 +262,7 @@ func (g *CiscoFirepowerGenerator) generateFile(overrides map[string]interface{})
 		"file_size":       g.RandomInt(1000, 10000000),
 		"file_type":       g.RandomChoice(fileTypes),
 		"file_action":     g.RandomChoice(fileActions),
-		"sha256":          g.RandomString(64),
+		"sha256":          g.RandomSHA256(),
 		"direction":       g.RandomChoice(directions),
 		"application":     g.RandomChoice([]string{"HTTP", "HTTPS", "FTP", "SMB", "SMTP"}),
 		"url":             fmt.Sprintf("https://%s.com/files/%s", g.RandomString(8), g.RandomString(12)),
@@ -274,7 +273,7 @@ func (g *CiscoFirepowerGenerator) generateFile(overrides map[string]interface{})
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +332,7 @@ func (g *CiscoFirepowerGenerator) generateMalware(overrides map[string]interface
 		"protocol":         "TCP",
 		"file_name":        fmt.Sprintf("%s.exe", g.RandomString(10)),
 		"file_size":        g.RandomInt(10000, 5000000),
-		"sha256":           g.RandomString(64),
+		"sha256":           g.RandomSHA256(),
 		"malware_name":     g.RandomChoice(malwareNames),
 		"threat_type":      g.RandomChoice(threatTypes),
 		"threat_score":     g.RandomInt(50, 100),
@@ -348,7 +347,7 @@ func (g *CiscoFirepowerGenerator) generateMalware(overrides map[string]interface
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
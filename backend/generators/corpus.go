@@ -0,0 +1,281 @@
+package generators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// Corpus snapshot defaults and limits
+const (
+	DefaultCorpusSeed    = 1
+	defaultCorpusSamples = 5
+	maxCorpusSamples     = 50
+	maxCorpusExample     = 200 // Characters of an example value kept
+)
+
+// SnapshotCorpus generates samples events from every template with random
+// values drawn from seed, and records the fields they carried. Each
+// template gets its own stream derived from seed and its ID, so adding a
+// template does not change the values of the others.
+func SnapshotCorpus(ctx context.Context, seed int64, samples int) (*models.Corpus, error) {
+	if samples == 0 {
+		samples = defaultCorpusSamples
+	}
+	if samples < 1 || samples > maxCorpusSamples {
+		return nil, fmt.Errorf("samples must be between 1 and %d", maxCorpusSamples)
+	}
+
+	corpus := &models.Corpus{
+		Version:   models.CorpusVersion,
+		Seed:      seed,
+		Samples:   samples,
+		CreatedAt: time.Now().UTC(),
+		Templates: []models.CorpusTemplate{},
+	}
+	for _, g := range Generators() {
+		for _, t := range g.GetTemplates() {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			corpus.Templates = append(corpus.Templates, snapshotTemplate(g, t, seed, samples))
+		}
+	}
+	return corpus, nil
+}
+
+// snapshotTemplate records the fields of samples events of one template
+func snapshotTemplate(g Generator, t models.EventTemplate, seed int64, samples int) models.CorpusTemplate {
+	snap := models.CorpusTemplate{
+		EventType:     g.GetEventType().ID,
+		TemplateID:    t.ID,
+		SchemaVersion: t.SchemaVersion,
+		Sourcetype:    t.Sourcetype,
+		Fields:        []models.CorpusField{},
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(snap.EventType + "/" + snap.TemplateID))
+	seen := make(map[string]int)
+	byPath := make(map[string]models.CorpusField)
+	generated := 0
+	stream := NewRandomStream(seed ^ int64(h.Sum64()))
+	for i := 0; i < samples; i++ {
+		event, err := stream.Generate(g, t.ID, nil)
+		if err != nil {
+			snap.Error = err.Error()
+			break
+		}
+		if i == 0 {
+			snap.RawEvent = event.RawEvent
+			if event.Sourcetype != "" {
+				snap.Sourcetype = event.Sourcetype
+			}
+		}
+		generated++
+		for path, value := range FlattenFields(event.Fields) {
+			seen[path]++
+			if _, ok := byPath[path]; !ok {
+				typ, example := fieldShape(value)
+				byPath[path] = models.CorpusField{Path: path, Type: typ, Example: example}
+			}
+		}
+	}
+
+	for path, f := range byPath {
+		f.Optional = seen[path] < generated
+		snap.Fields = append(snap.Fields, f)
+	}
+	sort.Slice(snap.Fields, func(i, j int) bool { return snap.Fields[i].Path < snap.Fields[j].Path })
+	return snap
+}
+
+// fieldShape returns the JSON type of a field value and the value as JSON
+func fieldShape(v interface{}) (string, string) {
+	data, err := json.Marshal(v)
+	if err != nil || len(data) == 0 {
+		return "string", fmt.Sprint(v)
+	}
+	example := string(data)
+	if len(example) > maxCorpusExample {
+		example = example[:maxCorpusExample]
+	}
+	switch data[0] {
+	case '"':
+		return "string", example
+	case '{':
+		return "object", example
+	case '[':
+		return "array", example
+	case 't', 'f':
+		return "boolean", example
+	case 'n':
+		return "null", example
+	default:
+		return "number", example
+	}
+}
+
+// DiffCorpus snapshots current output with the stored corpus's seed and
+// sample count and reports the templates and fields that changed
+func DiffCorpus(ctx context.Context, stored *models.Corpus) (*models.CorpusDiff, error) {
+	if stored.Version != models.CorpusVersion {
+		return nil, fmt.Errorf("unsupported corpus version %d", stored.Version)
+	}
+	current, err := SnapshotCorpus(ctx, stored.Seed, stored.Samples)
+	if err != nil {
+		return nil, err
+	}
+
+	key := func(t models.CorpusTemplate) string { return t.EventType + "/" + t.TemplateID }
+	before := make(map[string]models.CorpusTemplate, len(stored.Templates))
+	for _, t := range stored.Templates {
+		before[key(t)] = t
+	}
+
+	diff := &models.CorpusDiff{Seed: stored.Seed, Templates: []models.TemplateDiff{}}
+	for _, t := range current.Templates {
+		old, ok := before[key(t)]
+		if !ok {
+			diff.TemplatesAdded = append(diff.TemplatesAdded, key(t))
+			continue
+		}
+		delete(before, key(t))
+		if d, changed := diffTemplate(old, t); changed {
+			diff.Templates = append(diff.Templates, d)
+		}
+	}
+	for k := range before {
+		diff.TemplatesRemoved = append(diff.TemplatesRemoved, k)
+	}
+	sort.Strings(diff.TemplatesRemoved)
+
+	diff.Changed = len(diff.TemplatesAdded) > 0 || len(diff.TemplatesRemoved) > 0 || len(diff.Templates) > 0
+	return diff, nil
+}
+
+// diffTemplate compares a template's snapshot with its current output.
+// Fields that come and go between samples are only reported when they
+// were always present before or are always present now.
+func diffTemplate(old, cur models.CorpusTemplate) (models.TemplateDiff, bool) {
+	d := models.TemplateDiff{
+		EventType:           cur.EventType,
+		TemplateID:          cur.TemplateID,
+		SchemaVersion:       cur.SchemaVersion,
+		StoredSchemaVersion: old.SchemaVersion,
+		Sourcetype:          cur.Sourcetype,
+		Error:               cur.Error,
+	}
+	if old.Sourcetype != cur.Sourcetype {
+		d.StoredSourcetype = old.Sourcetype
+	}
+
+	oldFields := make(map[string]models.CorpusField, len(old.Fields))
+	for _, f := range old.Fields {
+		oldFields[f.Path] = f
+	}
+	var added, removed []models.CorpusField
+	for _, f := range cur.Fields {
+		o, ok := oldFields[f.Path]
+		if !ok {
+			if !f.Optional {
+				added = append(added, f)
+			}
+			continue
+		}
+		delete(oldFields, f.Path)
+		if o.Type != f.Type && o.Type != "null" && f.Type != "null" {
+			d.TypeChanged = append(d.TypeChanged, models.FieldTypeChange{Field: f.Path, From: o.Type, To: f.Type})
+		}
+	}
+	for _, f := range oldFields {
+		if !f.Optional {
+			removed = append(removed, f)
+		}
+	}
+
+	d.Renamed, removed, added = findRenames(removed, added)
+	for _, f := range added {
+		d.Added = append(d.Added, f.Path)
+	}
+	for _, f := range removed {
+		d.Removed = append(d.Removed, f.Path)
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Slice(d.Renamed, func(i, j int) bool { return d.Renamed[i].From < d.Renamed[j].From })
+	sort.Slice(d.TypeChanged, func(i, j int) bool { return d.TypeChanged[i].Field < d.TypeChanged[j].Field })
+
+	changed := len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Renamed) > 0 ||
+		len(d.TypeChanged) > 0 || d.StoredSourcetype != "" || (d.Error != "" && old.Error == "")
+	return d, changed
+}
+
+// findRenames pairs removed fields with added ones of the same type that
+// carry the same example value, which the fixed seed makes likely for a
+// renamed field, or failing that the same last name segment, as when a
+// field moves to another object. Only unambiguous pairs are matched; the
+// rest are returned.
+func findRenames(removed, added []models.CorpusField) ([]models.FieldRename, []models.CorpusField, []models.CorpusField) {
+	var renames []models.FieldRename
+	for _, match := range []func(r, a models.CorpusField) bool{
+		func(r, a models.CorpusField) bool {
+			return distinctiveExample(r) && r.Example == a.Example
+		},
+		func(r, a models.CorpusField) bool {
+			return lastSegment(r.Path) == lastSegment(a.Path)
+		},
+	} {
+		var keptRemoved []models.CorpusField
+		used := make(map[int]bool)
+		for _, r := range removed {
+			candidate := -1
+			for i, a := range added {
+				if used[i] || a.Type != r.Type || !match(r, a) {
+					continue
+				}
+				if candidate >= 0 {
+					candidate = -2 // Ambiguous
+					break
+				}
+				candidate = i
+			}
+			if candidate < 0 {
+				keptRemoved = append(keptRemoved, r)
+				continue
+			}
+			used[candidate] = true
+			renames = append(renames, models.FieldRename{From: r.Path, To: added[candidate].Path})
+		}
+
+		var keptAdded []models.CorpusField
+		for i, a := range added {
+			if !used[i] {
+				keptAdded = append(keptAdded, a)
+			}
+		}
+		removed, added = keptRemoved, keptAdded
+	}
+	return renames, removed, added
+}
+
+// distinctiveExample reports whether a field's example is unlikely to be
+// shared by an unrelated field, unlike booleans, nulls and short numbers
+func distinctiveExample(f models.CorpusField) bool {
+	switch f.Type {
+	case "boolean", "null":
+		return false
+	}
+	return len(f.Example) >= 6 && f.Example != `""` && f.Example != "{}" && f.Example != "[]"
+}
+
+// lastSegment returns the last part of a dotted field path
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -26,7 +25,8 @@ func (g *CrowdStrikeGenerator) GetEventType() models.EventType {
 		Name:        "CrowdStrike Falcon",
 		Category:    "endpoint",
 		Description: "CrowdStrike EDR detections, process events, and threat intelligence",
-		EventIDs:    []string{"DetectionSummaryEvent", "ProcessRollup2", "NetworkConnectIP4", "DnsRequest", "FileWritten"},
+		EventIDs:    []string{"DetectionSummaryEvent", "ProcessRollup2", "NetworkConnectIP4", "DnsRequest", "FileWritten",
+			"IncidentSummaryEvent", "IdentityProtectionEvent", "RemoteResponseSessionStartEvent"},
 	}
 }
 
@@ -81,6 +81,30 @@ func (g *CrowdStrikeGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "json",
 			Description: "User authentication event",
 		},
+		{
+			ID:          "incident",
+			Name:        "Incident Summary",
+			Category:    "crowdstrike",
+			EventID:     "IncidentSummaryEvent",
+			Format:      "json",
+			Description: "CrowdScore incident on a host, sometimes with lateral movement",
+		},
+		{
+			ID:          "identity_protection",
+			Name:        "Identity Protection Detection",
+			Category:    "crowdstrike",
+			EventID:     "IdentityProtectionEvent",
+			Format:      "json",
+			Description: "Falcon Identity Protection detection for an account",
+		},
+		{
+			ID:          "rtr_session_start",
+			Name:        "Real Time Response Session Start",
+			Category:    "crowdstrike",
+			EventID:     "RemoteResponseSessionStartEvent",
+			Format:      "json",
+			Description: "Responder started a Real Time Response session on a host",
+		},
 	}
 }
 
@@ -99,21 +123,19 @@ func (g *CrowdStrikeGenerator) Generate(templateID string, overrides map[string]
 		return g.generateFileWrite(overrides)
 	case "auth_activity":
 		return g.generateAuthActivity(overrides)
+	case "incident":
+		return g.generateIncident(overrides)
+	case "identity_protection":
+		return g.generateIdentityProtection(overrides)
+	case "rtr_session_start":
+		return g.generateRTRSessionStart(overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
 }
 
 func (g *CrowdStrikeGenerator) randomAID() string {
-	return g.RandomString(32)
-}
-
-func (g *CrowdStrikeGenerator) randomCID() string {
-	return g.RandomString(32)
-}
-
-func (g *CrowdStrikeGenerator) randomSHA256() string {
-	return g.RandomString(64)
+	return g.RandomHex(16)
 }
 
 func (g *CrowdStrikeGenerator) randomComputerName() string {
@@ -163,16 +185,10 @@ func (g *CrowdStrikeGenerator) randomTechnique() (string, string) {
 func (g *CrowdStrikeGenerator) buildBaseEvent(eventType string) map[string]interface{} {
 	timestamp := time.Now().UTC()
 	return map[string]interface{}{
-		"metadata": map[string]interface{}{
-			"customerIDString": g.randomCID(),
-			"offset":           g.RandomInt(100000, 999999),
-			"eventType":        eventType,
-			"eventCreationTime": timestamp.UnixMilli(),
-			"version":          "1.0",
-		},
+		"metadata": g.streamMetadata(eventType, timestamp),
 		"event": map[string]interface{}{
 			"aid":          g.randomAID(),
-			"cid":          g.randomCID(),
+			"cid":          falconCID,
 			"ComputerName": g.randomComputerName(),
 			"LocalIP":      g.RandomIPv4Internal(),
 			"MAC":          g.RandomMAC(),
@@ -209,14 +225,15 @@ func (g *CrowdStrikeGenerator) generateDetection(overrides map[string]interface{
 	base["event"].(map[string]interface{})["TacticId"] = tacticID
 	base["event"].(map[string]interface{})["Technique"] = techniqueName
 	base["event"].(map[string]interface{})["TechniqueId"] = techniqueID
-	base["event"].(map[string]interface{})["FileName"] = g.RandomProcessName()
-	base["event"].(map[string]interface{})["FilePath"] = g.RandomPath()
-	base["event"].(map[string]interface{})["SHA256String"] = g.randomSHA256()
+	file := g.RandomMaliciousFile(FileExecutable, FileLibrary, FileScript)
+	base["event"].(map[string]interface{})["FileName"] = file.Name
+	base["event"].(map[string]interface{})["FilePath"] = file.Dir()
+	base["event"].(map[string]interface{})["SHA256String"] = g.FileHash(file, models.IOCTypeSHA256)
 	base["event"].(map[string]interface{})["UserName"] = g.RandomUsername()
 	base["event"].(map[string]interface{})["ParentImageFileName"] = g.RandomChoice([]string{"explorer.exe", "cmd.exe", "powershell.exe", "svchost.exe"})
 
 	fields := g.ApplyOverrides(base, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -233,9 +250,10 @@ func (g *CrowdStrikeGenerator) generateProcess(overrides map[string]interface{})
 	timestamp := time.Now()
 	base := g.buildBaseEvent("ProcessRollup2")
 
-	base["event"].(map[string]interface{})["ImageFileName"] = g.RandomProcessName()
+	image := g.RandomProcessName()
+	base["event"].(map[string]interface{})["ImageFileName"] = image
 	base["event"].(map[string]interface{})["CommandLine"] = fmt.Sprintf("%s %s", g.RandomPath(), g.RandomChoice([]string{"-h", "--version", "/c whoami", "-encodedcommand", ""}))
-	base["event"].(map[string]interface{})["SHA256HashData"] = g.randomSHA256()
+	base["event"].(map[string]interface{})["SHA256HashData"] = g.FileHash(Files.ForName(image), models.IOCTypeSHA256)
 	base["event"].(map[string]interface{})["ParentBaseFileName"] = g.RandomChoice([]string{"explorer.exe", "cmd.exe", "powershell.exe", "services.exe"})
 	base["event"].(map[string]interface{})["ParentCommandLine"] = g.RandomPath()
 	base["event"].(map[string]interface{})["UserName"] = g.RandomUsername()
@@ -244,7 +262,7 @@ func (g *CrowdStrikeGenerator) generateProcess(overrides map[string]interface{})
 	base["event"].(map[string]interface{})["ParentProcessId"] = g.RandomInt(1000, 65535)
 
 	fields := g.ApplyOverrides(base, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -270,7 +288,7 @@ func (g *CrowdStrikeGenerator) generateNetwork(overrides map[string]interface{})
 	base["event"].(map[string]interface{})["ImageFileName"] = g.RandomProcessName()
 
 	fields := g.ApplyOverrides(base, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -297,7 +315,7 @@ func (g *CrowdStrikeGenerator) generateDNS(overrides map[string]interface{}) (*m
 	base["event"].(map[string]interface{})["ImageFileName"] = g.RandomChoice([]string{"chrome.exe", "firefox.exe", "outlook.exe", "svchost.exe"})
 
 	fields := g.ApplyOverrides(base, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -314,18 +332,20 @@ func (g *CrowdStrikeGenerator) generateFileWrite(overrides map[string]interface{
 	timestamp := time.Now()
 	base := g.buildBaseEvent("FileWritten")
 
-	extensions := []string{".exe", ".dll", ".ps1", ".bat", ".vbs", ".docx", ".xlsx"}
-	filename := fmt.Sprintf("%s%s", g.RandomString(8), g.RandomChoice(extensions))
+	file := g.RandomFile()
+	if g.rnd.float64() < 0.1 {
+		file = g.RandomMaliciousFile()
+	}
 
-	base["event"].(map[string]interface{})["TargetFileName"] = filename
-	base["event"].(map[string]interface{})["TargetDirectoryName"] = g.RandomPath()
-	base["event"].(map[string]interface{})["SHA256HashData"] = g.randomSHA256()
-	base["event"].(map[string]interface{})["Size"] = g.RandomInt(1024, 10485760)
+	base["event"].(map[string]interface{})["TargetFileName"] = file.Name
+	base["event"].(map[string]interface{})["TargetDirectoryName"] = file.Dir()
+	base["event"].(map[string]interface{})["SHA256HashData"] = g.FileHash(file, models.IOCTypeSHA256)
+	base["event"].(map[string]interface{})["Size"] = file.Size
 	base["event"].(map[string]interface{})["ImageFileName"] = g.RandomChoice([]string{"explorer.exe", "chrome.exe", "powershell.exe", "word.exe"})
 	base["event"].(map[string]interface{})["UserName"] = g.RandomUsername()
 
 	fields := g.ApplyOverrides(base, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -362,7 +382,7 @@ func (g *CrowdStrikeGenerator) generateAuthActivity(overrides map[string]interfa
 	base["event"].(map[string]interface{})["RemoteAddressIP4"] = g.RandomIPv4Internal()
 
 	fields := g.ApplyOverrides(base, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
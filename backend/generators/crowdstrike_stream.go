@@ -0,0 +1,191 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// falconCID is the customer ID of the simulated Falcon tenant
+const falconCID = "3f0a9c2d7e8b41c6a5d2e9f01b7c4a68"
+
+// FalconStreamTracker numbers events the way a Falcon Streaming API
+// partition does. Offsets increase by one per event, so a consumer that
+// reconnects from the offset after the last one it read sees no gaps.
+type FalconStreamTracker struct {
+	mu     sync.Mutex
+	offset int
+}
+
+// FalconStream is the stream shared by the CrowdStrike generator
+var FalconStream = &FalconStreamTracker{}
+
+// next returns the offset of the next event. The stream starts at a random
+// offset, as a partition that has been running for a while would.
+func (t *FalconStreamTracker) next(rnd *RandomStream) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.offset == 0 {
+		t.offset = 100000 + int(rnd.float64()*900000)
+	}
+	t.offset++
+	return t.offset
+}
+
+// Offset returns the last offset handed out
+func (t *FalconStreamTracker) Offset() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.offset
+}
+
+// streamMetadata is the metadata block the Streaming API frames each event
+// with
+func (g *CrowdStrikeGenerator) streamMetadata(eventType string, timestamp time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"customerIDString":  falconCID,
+		"offset":            FalconStream.next(g.rnd),
+		"eventType":         eventType,
+		"eventCreationTime": timestamp.UnixMilli(),
+		"version":           "1.0",
+	}
+}
+
+// falconAID is the stable sensor ID of a pool host
+func falconAID(h *EntityHost) string {
+	return strings.ToLower(strings.ReplaceAll(h.UUID, "-", ""))
+}
+
+// falconHostLink is a console link to a Falcon object
+func falconHostLink(path string) string {
+	return "https://falcon.crowdstrike.com/" + path
+}
+
+// streamEvent frames an event and wraps it as a generated event
+func (g *CrowdStrikeGenerator) streamEvent(eventType string, timestamp time.Time, event, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	base := map[string]interface{}{
+		"metadata": g.streamMetadata(eventType, timestamp.UTC()),
+		"event":    event,
+	}
+	fields := g.ApplyOverrides(base, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "crowdstrike",
+		EventID:    eventType,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "crowdstrike:falcon:json",
+	}, nil
+}
+
+// generateIncident creates a CrowdScore incident summary on a pool host,
+// some spreading laterally to other hosts
+func (g *CrowdStrikeGenerator) generateIncident(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	host := Entities.RandomHost(g.rnd, "windows")
+	aid := falconAID(host)
+	incidentID := fmt.Sprintf("inc:%s:%s", aid, g.RandomHex(16))
+	started := timestamp.Add(-time.Duration(g.RandomInt(5, 240)) * time.Minute)
+
+	lateralMovement := 0
+	lmHosts := []string{}
+	if g.RandomInt(1, 100) <= 30 {
+		lateralMovement = 1
+		for i := g.RandomInt(1, 3); i > 0; i-- {
+			lmHosts = append(lmHosts, falconAID(Entities.RandomHost(g.rnd, "windows")))
+		}
+	}
+
+	user := Entities.RandomUser(g.rnd)
+	event := map[string]interface{}{
+		"IncidentID":        incidentID,
+		"IncidentType":      1,
+		"IncidentStartTime": started.Unix(),
+		"IncidentEndTime":   timestamp.Unix(),
+		"FineScore":         float64(g.RandomInt(10, 100)) / 10,
+		"LateralMovement":   lateralMovement,
+		"HostID":            aid,
+		"LMHostIDs":         lmHosts,
+		"UserId":            windowsNetBIOSDomain() + "\\" + user.Username,
+		"State":             "open",
+		"FalconHostLink":    falconHostLink("crowdscore/incidents/details/" + incidentID),
+	}
+
+	return g.streamEvent("IncidentSummaryEvent", timestamp, event, overrides)
+}
+
+// identityDetections are Falcon Identity Protection detections with their
+// severity
+var identityDetections = []struct {
+	incidentType, description, severity string
+}{
+	{"Credential Scanning", "An account attempted authentication with many passwords in a short time", "Medium"},
+	{"Kerberoasting", "An account requested service tickets for many service accounts with weak encryption", "High"},
+	{"Pass-the-Hash", "An NTLM authentication used a hash of a credential stolen from another endpoint", "High"},
+	{"Suspicious LDAP Search (Reconnaissance)", "An account ran LDAP queries enumerating privileged groups and accounts", "Medium"},
+	{"Unusual Login to an Endpoint", "An account logged in to an endpoint it has never accessed before", "Low"},
+}
+
+// generateIdentityProtection creates an Identity Protection detection for
+// a pool user authenticating from a pool host
+func (g *CrowdStrikeGenerator) generateIdentityProtection(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	detection := identityDetections[g.RandomInt(0, len(identityDetections)-1)]
+	user := Entities.RandomUser(g.rnd)
+	host := Entities.RandomHost(g.rnd, "windows")
+	incidentID := fmt.Sprintf("ind:%s:%s", falconCID, uuid.New().String())
+	severity := map[string]int{"Low": 2, "Medium": 3, "High": 4}[detection.severity]
+
+	event := map[string]interface{}{
+		"IncidentType":                    detection.incidentType,
+		"IncidentDescription":             detection.description,
+		"Severity":                        severity,
+		"SeverityName":                    detection.severity,
+		"StartTime":                       timestamp.Add(-time.Duration(g.RandomInt(1, 60)) * time.Minute).UnixMilli(),
+		"EndTime":                         timestamp.UnixMilli(),
+		"State":                           "NEW",
+		"NumberOfCompromisedEntities":     1,
+		"NumbersOfAlerts":                 g.RandomInt(1, 5),
+		"SourceAccountName":               user.Username,
+		"SourceAccountDomain":             strings.ToUpper(Theme().ADDomain),
+		"SourceAccountUpn":                user.Username + "@" + Theme().ADDomain,
+		"SourceAccountObjectSid":          windowsUserSID(user),
+		"SourceEndpointHostName":          host.FQDN,
+		"SourceEndpointIpAddress":         host.IP,
+		"SourceEndpointSensorId":          falconAID(host),
+		"SourceEndpointAccountObjectGuid": strings.ToLower(host.UUID),
+		"FalconHostLink":                  falconHostLink("identity-protection/detections/" + incidentID),
+	}
+
+	return g.streamEvent("IdentityProtectionEvent", timestamp, event, overrides)
+}
+
+// generateRTRSessionStart creates a Real Time Response session started by
+// a responder on a pool host
+func (g *CrowdStrikeGenerator) generateRTRSessionStart(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	host := Entities.RandomHost(g.rnd)
+	responder := Entities.RandomUser(g.rnd)
+
+	event := map[string]interface{}{
+		"SessionId":      uuid.New().String(),
+		"HostnameField":  host.Hostname,
+		"AgentIdString":  falconAID(host),
+		"UserName":       responder.Email,
+		"StartTimestamp": timestamp.Unix(),
+	}
+
+	return g.streamEvent("RemoteResponseSessionStartEvent", timestamp, event, overrides)
+}
@@ -0,0 +1,530 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// DatabaseAuditGenerator generates PostgreSQL pgaudit, MySQL Enterprise Audit
+// and SQL Server audit events
+type DatabaseAuditGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&DatabaseAuditGenerator{})
+}
+
+// GetEventType returns the event type for database audit logs
+func (g *DatabaseAuditGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "database_audit",
+		Name:        "Database Audit Logs",
+		Category:    "infrastructure",
+		Description: "PostgreSQL pgaudit, MySQL Enterprise Audit and SQL Server audit records for logins, DDL and sensitive reads",
+		EventIDs:    []string{"login", "ddl", "read"},
+	}
+}
+
+// GetTemplates returns available templates for database audit logs
+func (g *DatabaseAuditGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "pgaudit_login",
+			Name:        "PostgreSQL Connection",
+			Category:    "database_audit",
+			EventID:     "login",
+			Format:      "text",
+			Description: "Connection authorized or password authentication failed",
+			Sourcetype:  "postgresql",
+		},
+		{
+			ID:          "pgaudit_ddl",
+			Name:        "pgaudit DDL/ROLE",
+			Category:    "database_audit",
+			EventID:     "ddl",
+			Format:      "text",
+			Description: "pgaudit SESSION record for schema or role changes",
+			Sourcetype:  "postgresql",
+		},
+		{
+			ID:          "pgaudit_read",
+			Name:        "pgaudit READ",
+			Category:    "database_audit",
+			EventID:     "read",
+			Format:      "text",
+			Description: "pgaudit SESSION record for a SELECT, sometimes against sensitive tables",
+			Sourcetype:  "postgresql",
+		},
+		{
+			ID:          "mysql_connect",
+			Name:        "MySQL Connect",
+			Category:    "database_audit",
+			EventID:     "login",
+			Format:      "json",
+			Description: "MySQL Enterprise Audit connection event, including failed logins",
+			Sourcetype:  "mysql:audit",
+		},
+		{
+			ID:          "mysql_ddl",
+			Name:        "MySQL DDL",
+			Category:    "database_audit",
+			EventID:     "ddl",
+			Format:      "json",
+			Description: "MySQL Enterprise Audit query event for DDL or grants",
+			Sourcetype:  "mysql:audit",
+		},
+		{
+			ID:          "mysql_query",
+			Name:        "MySQL Query",
+			Category:    "database_audit",
+			EventID:     "read",
+			Format:      "json",
+			Description: "MySQL Enterprise Audit query event for a SELECT",
+			Sourcetype:  "mysql:audit",
+		},
+		{
+			ID:          "mssql_login",
+			Name:        "SQL Server Login",
+			Category:    "database_audit",
+			EventID:     "login",
+			Format:      "kv",
+			Description: "SQL Server audit LGIS/LGIF login succeeded or failed",
+			Sourcetype:  "mssql:audit",
+		},
+		{
+			ID:          "mssql_ddl",
+			Name:        "SQL Server DDL",
+			Category:    "database_audit",
+			EventID:     "ddl",
+			Format:      "kv",
+			Description: "SQL Server audit CR/AL/DR schema and permission changes",
+			Sourcetype:  "mssql:audit",
+		},
+		{
+			ID:          "mssql_select",
+			Name:        "SQL Server SELECT",
+			Category:    "database_audit",
+			EventID:     "read",
+			Format:      "kv",
+			Description: "SQL Server audit SL record for a SELECT, sometimes against sensitive tables",
+			Sourcetype:  "mssql:audit",
+		},
+	}
+}
+
+// dbStatement is an audited statement against an application database
+type dbStatement struct {
+	command, objectType, object, sql string
+	rows                             int
+	sensitive                        bool
+}
+
+// dbAppAccounts are the service accounts applications connect as
+var dbAppAccounts = []string{"svc_orders", "svc_billing", "app_rw", "reporting_ro", "etl_loader"}
+
+// dbReads returns routine and sensitive SELECTs against schema
+func (g *DatabaseAuditGenerator) dbReads(schema string) []dbStatement {
+	id := g.RandomInt(1000, 999999)
+	return []dbStatement{
+		{"SELECT", "TABLE", schema + ".orders", fmt.Sprintf("SELECT id, status, total FROM %s.orders WHERE customer_id = %d", schema, id), g.RandomInt(0, 20), false},
+		{"SELECT", "TABLE", schema + ".products", fmt.Sprintf("SELECT * FROM %s.products WHERE sku = 'SKU-%d'", schema, id), 1, false},
+		{"SELECT", "TABLE", schema + ".sessions", fmt.Sprintf("SELECT user_id, expires_at FROM %s.sessions WHERE token = $1", schema), 1, false},
+		{"SELECT", "TABLE", schema + ".invoices", fmt.Sprintf("SELECT count(*) FROM %s.invoices WHERE created_at > now() - interval '1 day'", schema), 1, false},
+		{"SELECT", "TABLE", schema + ".customers", fmt.Sprintf("SELECT first_name, last_name, email, ssn, date_of_birth FROM %s.customers", schema), g.RandomInt(50000, 2000000), true},
+		{"SELECT", "TABLE", schema + ".payment_cards", fmt.Sprintf("SELECT card_number, expiry, cardholder_name FROM %s.payment_cards", schema), g.RandomInt(10000, 500000), true},
+		{"SELECT", "TABLE", schema + ".employee_salaries", fmt.Sprintf("SELECT employee_id, base_salary, bonus FROM %s.employee_salaries ORDER BY base_salary DESC", schema), g.RandomInt(500, 20000), true},
+		{"SELECT", "TABLE", schema + ".users", fmt.Sprintf("SELECT username, password_hash, mfa_secret FROM %s.users", schema), g.RandomInt(1000, 300000), true},
+	}
+}
+
+// dbDDL returns schema and privilege changes against schema
+func (g *DatabaseAuditGenerator) dbDDL(schema string) []dbStatement {
+	tmp := fmt.Sprintf("tmp_export_%d", g.RandomInt(1, 99))
+	return []dbStatement{
+		{"CREATE INDEX", "INDEX", schema + ".idx_orders_created_at", fmt.Sprintf("CREATE INDEX idx_orders_created_at ON %s.orders (created_at)", schema), 0, false},
+		{"ALTER TABLE", "TABLE", schema + ".orders", fmt.Sprintf("ALTER TABLE %s.orders ADD COLUMN fulfillment_region varchar(32)", schema), 0, false},
+		{"CREATE TABLE", "TABLE", schema + "." + tmp, fmt.Sprintf("CREATE TABLE %s.%s AS SELECT * FROM %s.customers", schema, tmp, schema), 0, true},
+		{"DROP TABLE", "TABLE", schema + ".audit_history", fmt.Sprintf("DROP TABLE %s.audit_history", schema), 0, true},
+		{"GRANT", "TABLE", schema + ".customers", fmt.Sprintf("GRANT SELECT ON %s.customers TO reporting_ro", schema), 0, true},
+		{"ALTER ROLE", "ROLE", "", "ALTER ROLE etl_loader WITH SUPERUSER", 0, true},
+	}
+}
+
+// pickStatement chooses a routine statement most of the time and a
+// sensitive one the rest, returning the account that ran it
+func (g *DatabaseAuditGenerator) pickStatement(stmts []dbStatement, sensitivePct int) (dbStatement, string, string) {
+	var routine, sensitive []dbStatement
+	for _, s := range stmts {
+		if s.sensitive {
+			sensitive = append(sensitive, s)
+		} else {
+			routine = append(routine, s)
+		}
+	}
+	if g.RandomInt(1, 100) <= sensitivePct {
+		user := Entities.RandomUser(g.rnd)
+		return sensitive[g.RandomInt(0, len(sensitive)-1)], user.Username, Entities.HostForUser(g.rnd, user.Username).IP
+	}
+	app := Entities.RandomHost(g.rnd)
+	return routine[g.RandomInt(0, len(routine)-1)], g.ZipfChoice(dbAppAccounts), app.IP
+}
+
+// dbServer picks a database server from the entity pool
+func (g *DatabaseAuditGenerator) dbServer(platforms ...string) *EntityHost {
+	var servers []*EntityHost
+	for _, h := range Entities.Hosts(platforms...) {
+		if h.Role == "server" && strings.HasPrefix(h.Hostname, "db-") {
+			servers = append(servers, h)
+		}
+	}
+	if len(servers) == 0 {
+		return Entities.RandomHost(g.rnd, platforms...)
+	}
+	return servers[g.RandomInt(0, len(servers)-1)]
+}
+
+// Generate creates a database audit event
+func (g *DatabaseAuditGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "pgaudit_login":
+		return g.generatePgLogin(overrides)
+	case "pgaudit_ddl":
+		stmt, user, client := g.pickStatement(g.dbDDL("public"), 35)
+		return g.generatePgAudit("ddl", stmt, user, client, overrides)
+	case "pgaudit_read":
+		stmt, user, client := g.pickStatement(g.dbReads("public"), 10)
+		return g.generatePgAudit("read", stmt, user, client, overrides)
+	case "mysql_connect":
+		return g.generateMySQLConnect(overrides)
+	case "mysql_ddl":
+		stmt, user, client := g.pickStatement(g.dbDDL("shop"), 35)
+		return g.generateMySQLQuery("ddl", stmt, user, client, overrides)
+	case "mysql_query":
+		stmt, user, client := g.pickStatement(g.dbReads("shop"), 10)
+		return g.generateMySQLQuery("read", stmt, user, client, overrides)
+	case "mssql_login":
+		return g.generateMSSQLLogin(overrides)
+	case "mssql_ddl":
+		stmt, user, client := g.pickStatement(g.dbDDL("dbo"), 35)
+		return g.generateMSSQLStatement("ddl", stmt, user, client, overrides)
+	case "mssql_select":
+		stmt, user, client := g.pickStatement(g.dbReads("dbo"), 10)
+		return g.generateMSSQLStatement("read", stmt, user, client, overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// pgPrefix renders log_line_prefix = '%m [%p] %q%u@%d '
+func pgPrefix(ts time.Time, pid int, user, db string) string {
+	return fmt.Sprintf("%s [%d] %s@%s ", ts.Format("2006-01-02 15:04:05.000 MST"), pid, user, db)
+}
+
+func (g *DatabaseAuditGenerator) generatePgLogin(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	server := g.dbServer("linux")
+	user := g.ZipfChoice(dbAppAccounts)
+	client := Entities.RandomHost(g.rnd).IP
+	success := g.RandomInt(1, 100) <= 85
+	if !success {
+		user = g.WeightedChoice([]string{"postgres", user, "admin"}, []float64{50, 30, 20})
+		client = g.RandomIPv4External()
+	}
+
+	fields := map[string]interface{}{
+		"host":             server.Hostname,
+		"pid":              g.RandomInt(1000, 65000),
+		"user":             user,
+		"database":         "orders",
+		"client_addr":      client,
+		"application_name": g.RandomChoice([]string{"psql", "pgjdbc", "psycopg2", "DBeaver"}),
+		"action":           "success",
+	}
+	if !success {
+		fields["action"] = "failure"
+	}
+	fields = g.ApplyOverrides(fields, overrides)
+
+	prefix := pgPrefix(now, fields["pid"].(int), fmt.Sprint(fields["user"]), fmt.Sprint(fields["database"]))
+	var raw string
+	if fields["action"] == "success" {
+		raw = fmt.Sprintf("%sLOG:  connection authorized: user=%v database=%v application_name=%v SSL enabled (protocol=TLSv1.3, cipher=TLS_AES_256_GCM_SHA384, bits=256)",
+			prefix, fields["user"], fields["database"], fields["application_name"])
+	} else {
+		raw = fmt.Sprintf("%sFATAL:  password authentication failed for user \"%v\"\n%sDETAIL:  Connection matched pg_hba.conf line 98: \"hostssl all all 0.0.0.0/0 scram-sha-256\"",
+			prefix, fields["user"], prefix)
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "database_audit",
+		EventID:    "login",
+		Timestamp:  now,
+		RawEvent:   raw,
+		Fields:     fields,
+		Sourcetype: "postgresql",
+	}, nil
+}
+
+func (g *DatabaseAuditGenerator) generatePgAudit(eventID string, stmt dbStatement, user, client string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	server := g.dbServer("linux")
+	class := "READ"
+	if eventID == "ddl" {
+		class = "DDL"
+		if stmt.objectType == "ROLE" || stmt.command == "GRANT" {
+			class = "ROLE"
+		}
+	}
+
+	fields := map[string]interface{}{
+		"host":            server.Hostname,
+		"pid":             g.RandomInt(1000, 65000),
+		"user":            user,
+		"database":        "orders",
+		"client_addr":     client,
+		"audit_type":      "SESSION",
+		"statement_id":    int(Series.Counter(server.Hostname, "pgaudit.statement_id", 0, 1)),
+		"substatement_id": 1,
+		"class":           class,
+		"command":         stmt.command,
+		"object_type":     stmt.objectType,
+		"object_name":     stmt.object,
+		"statement":       stmt.sql,
+		"rows":            stmt.rows,
+	}
+	if class == "ROLE" {
+		fields["object_type"], fields["object_name"] = "", ""
+	}
+	fields = g.ApplyOverrides(fields, overrides)
+
+	raw := fmt.Sprintf("%sLOG:  AUDIT: %v,%v,%v,%v,%v,%v,%v,\"%s\",<not logged>",
+		pgPrefix(now, fields["pid"].(int), fmt.Sprint(fields["user"]), fmt.Sprint(fields["database"])),
+		fields["audit_type"], fields["statement_id"], fields["substatement_id"], fields["class"], fields["command"],
+		fields["object_type"], fields["object_name"], strings.ReplaceAll(fmt.Sprint(fields["statement"]), `"`, `""`))
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "database_audit",
+		EventID:    eventID,
+		Timestamp:  now,
+		RawEvent:   raw,
+		Fields:     fields,
+		Sourcetype: "postgresql",
+	}, nil
+}
+
+// mysqlRecord marshals a MySQL Enterprise Audit JSON record
+func (g *DatabaseAuditGenerator) mysqlRecord(now time.Time, eventID string, fields map[string]interface{}) (*models.GeneratedEvent, error) {
+	rawEvent, _ := json.Marshal(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "database_audit",
+		EventID:    eventID,
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "mysql:audit",
+	}, nil
+}
+
+func (g *DatabaseAuditGenerator) generateMySQLConnect(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	server := g.dbServer("linux")
+	user := g.ZipfChoice(dbAppAccounts)
+	client := Entities.RandomHost(g.rnd).IP
+	status := 0
+	if g.RandomInt(1, 100) > 85 {
+		user = g.WeightedChoice([]string{"root", user, "admin"}, []float64{50, 30, 20})
+		client = g.RandomIPv4External()
+		status = 1045 // ER_ACCESS_DENIED_ERROR
+	}
+
+	fields := map[string]interface{}{
+		"timestamp":     now.Format("2006-01-02 15:04:05"),
+		"id":            0,
+		"class":         "connection",
+		"event":         "connect",
+		"connection_id": int(Series.Counter(server.Hostname, "mysql.connection_id", 1000, 1)),
+		"account":       map[string]string{"user": user, "host": "%"},
+		"login":         map[string]string{"user": user, "os": "", "ip": client, "proxy": ""},
+		"connection_data": map[string]interface{}{
+			"connection_type": "ssl",
+			"status":          status,
+			"db":              "shop",
+		},
+		"server_host": server.Hostname,
+	}
+	fields = g.ApplyOverrides(fields, overrides)
+	return g.mysqlRecord(now, "login", fields)
+}
+
+func (g *DatabaseAuditGenerator) generateMySQLQuery(eventID string, stmt dbStatement, user, client string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	server := g.dbServer("linux")
+	sql := strings.ReplaceAll(stmt.sql, "now() - interval '1 day'", "NOW() - INTERVAL 1 DAY")
+	sql = strings.ReplaceAll(sql, "$1", "?")
+
+	fields := map[string]interface{}{
+		"timestamp":     now.Format("2006-01-02 15:04:05"),
+		"id":            g.RandomInt(1, 500),
+		"class":         "general",
+		"event":         "status",
+		"connection_id": int(Series.Counter(server.Hostname, "mysql.connection_id", 1000, 0)),
+		"account":       map[string]string{"user": user, "host": "%"},
+		"login":         map[string]string{"user": user, "os": "", "ip": client, "proxy": ""},
+		"general_data": map[string]interface{}{
+			"command":     "Query",
+			"sql_command": strings.ToLower(strings.ReplaceAll(stmt.command, " ", "_")),
+			"query":       sql,
+			"status":      0,
+		},
+		"query_statistics": map[string]interface{}{
+			"query_time":    g.RandomLogNormal(0.002, 1.5) * float64(1+stmt.rows/10000),
+			"rows_sent":     stmt.rows,
+			"rows_examined": stmt.rows + g.RandomInt(0, 1000),
+		},
+		"server_host": server.Hostname,
+	}
+	fields = g.ApplyOverrides(fields, overrides)
+	return g.mysqlRecord(now, eventID, fields)
+}
+
+// mssqlFieldOrder is the column order of sys.fn_get_audit_file as indexed
+// through DB Connect
+var mssqlFieldOrder = []string{
+	"event_time", "sequence_number", "action_id", "succeeded", "session_id", "server_principal_id",
+	"class_type", "server_principal_name", "database_principal_name", "server_instance_name",
+	"database_name", "schema_name", "object_name", "statement", "additional_information",
+	"client_ip", "application_name", "duration_milliseconds", "response_rows", "affected_rows",
+}
+
+// mssqlRecord renders a SQL Server audit row in DB Connect key="value" form
+func (g *DatabaseAuditGenerator) mssqlRecord(now time.Time, eventID string, fields map[string]interface{}) (*models.GeneratedEvent, error) {
+	pairs := []string{now.Format("2006-01-02 15:04:05.000")}
+	for _, k := range mssqlFieldOrder {
+		v := fields[k]
+		if s, ok := v.(string); ok {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, strings.ReplaceAll(s, `"`, `\"`)))
+		} else {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", k, v))
+		}
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "database_audit",
+		EventID:    eventID,
+		Timestamp:  now,
+		RawEvent:   strings.Join(pairs, ", "),
+		Fields:     fields,
+		Sourcetype: "mssql:audit",
+	}, nil
+}
+
+// mssqlBase returns the columns shared by every SQL Server audit row
+func (g *DatabaseAuditGenerator) mssqlBase(now time.Time, server *EntityHost, user, client string) map[string]interface{} {
+	principal := user
+	if strings.Contains(user, ".") {
+		principal = strings.ToUpper(g.RandomDomain()) + `\` + user
+	}
+	return map[string]interface{}{
+		"event_time":              now.Format("2006-01-02 15:04:05.0000000"),
+		"sequence_number":         1,
+		"session_id":              g.RandomInt(51, 400),
+		"server_principal_id":     g.RandomInt(256, 400),
+		"server_principal_name":   principal,
+		"database_principal_name": "dbo",
+		"server_instance_name":    strings.ToUpper(server.Hostname) + `\MSSQLSERVER`,
+		"database_name":           "Sales",
+		"schema_name":             "",
+		"object_name":             "",
+		"statement":               "",
+		"additional_information":  "",
+		"client_ip":               client,
+		"application_name":        g.RandomChoice([]string{"Microsoft SQL Server Management Studio - Query", "Core Microsoft SqlClient Data Provider", ".Net SqlClient Data Provider"}),
+		"duration_milliseconds":   0,
+		"response_rows":           0,
+		"affected_rows":           0,
+	}
+}
+
+func (g *DatabaseAuditGenerator) generateMSSQLLogin(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	server := g.dbServer("windows")
+	user := g.ZipfChoice(dbAppAccounts)
+	client := Entities.RandomHost(g.rnd).IP
+	success := g.RandomInt(1, 100) <= 85
+	if !success {
+		user = g.WeightedChoice([]string{"sa", user, "admin"}, []float64{60, 25, 15})
+		client = g.RandomIPv4External()
+	}
+
+	fields := g.mssqlBase(now, server, user, client)
+	fields["class_type"] = "LX"
+	fields["database_name"] = "master"
+	fields["database_principal_name"] = ""
+	if success {
+		fields["action_id"] = "LGIS"
+		fields["succeeded"] = "True"
+		fields["additional_information"] = fmt.Sprintf("<action_info xmlns=\"http://schemas.microsoft.com/sqlserver/2008/sqlaudit_data\"><pooled_connection>0</pooled_connection><client_options>0x28000020</client_options><client_options1>0x00018f18</client_options1><connect_options>0x00000000</connect_options><packet_size>8000</packet_size><address>%s</address><is_dac>0</is_dac></action_info>", client)
+	} else {
+		fields["action_id"] = "LGIF"
+		fields["succeeded"] = "False"
+		fields["statement"] = fmt.Sprintf("Login failed for user '%s'. Reason: Password did not match that for the login provided. [CLIENT: %s]", user, client)
+		fields["additional_information"] = fmt.Sprintf("<action_info xmlns=\"http://schemas.microsoft.com/sqlserver/2008/sqlaudit_data\"><pooled_connection>0</pooled_connection><error>0x00004818</error><state>8</state><address>%s</address></action_info>", client)
+	}
+	fields = g.ApplyOverrides(fields, overrides)
+	return g.mssqlRecord(now, "login", fields)
+}
+
+func (g *DatabaseAuditGenerator) generateMSSQLStatement(eventID string, stmt dbStatement, user, client string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	server := g.dbServer("windows")
+	sql := strings.ReplaceAll(stmt.sql, "now() - interval '1 day'", "DATEADD(day, -1, GETDATE())")
+	sql = strings.ReplaceAll(sql, "$1", "@P1")
+	sql = strings.ReplaceAll(sql, "ADD COLUMN", "ADD")
+
+	fields := g.mssqlBase(now, server, user, client)
+	fields["succeeded"] = "True"
+	fields["statement"] = sql
+	fields["schema_name"] = "dbo"
+	fields["object_name"] = strings.TrimPrefix(stmt.object, "dbo.")
+
+	switch {
+	case stmt.command == "SELECT":
+		fields["action_id"] = "SL"
+		fields["class_type"] = "U"
+		fields["response_rows"] = stmt.rows
+		fields["duration_milliseconds"] = int(g.RandomLogNormal(3, 1.2)) + stmt.rows/2000
+	case stmt.command == "GRANT":
+		fields["action_id"] = "G"
+		fields["class_type"] = "U"
+	case stmt.objectType == "ROLE":
+		fields["action_id"] = "AL"
+		fields["class_type"] = "SL"
+		fields["schema_name"] = ""
+		fields["object_name"] = "etl_loader"
+		fields["statement"] = "ALTER SERVER ROLE sysadmin ADD MEMBER etl_loader"
+	case strings.HasPrefix(stmt.command, "CREATE"):
+		fields["action_id"] = "CR"
+		fields["class_type"] = map[string]string{"INDEX": "IX", "TABLE": "U"}[stmt.objectType]
+	case strings.HasPrefix(stmt.command, "DROP"):
+		fields["action_id"] = "DR"
+		fields["class_type"] = "U"
+	default:
+		fields["action_id"] = "AL"
+		fields["class_type"] = "U"
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	return g.mssqlRecord(now, eventID, fields)
+}
@@ -0,0 +1,82 @@
+package generators
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"siem-event-generator/models"
+)
+
+// FlattenFields converts nested maps into a single-level map keyed by
+// dot-separated paths. Slices are treated as leaf values.
+func FlattenFields(fields map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flattenInto(flat, "", fields)
+	return flat
+}
+
+func flattenInto(flat map[string]interface{}, prefix string, fields map[string]interface{}) {
+	for k, v := range fields {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		switch nested := v.(type) {
+		case map[string]interface{}:
+			if len(nested) == 0 {
+				flat[path] = nested
+				continue
+			}
+			flattenInto(flat, path, nested)
+		case map[string]string:
+			for nk, nv := range nested {
+				flat[path+"."+nk] = nv
+			}
+		default:
+			flat[path] = v
+		}
+	}
+}
+
+// DiffFields compares two field maps and reports added, removed and changed
+// paths, sorted by field path
+func DiffFields(before, after map[string]interface{}) []models.FieldDiff {
+	flatBefore := FlattenFields(before)
+	flatAfter := FlattenFields(after)
+
+	diffs := make([]models.FieldDiff, 0)
+	for path, oldValue := range flatBefore {
+		newValue, ok := flatAfter[path]
+		if !ok {
+			diffs = append(diffs, models.FieldDiff{Field: path, Change: models.FieldRemoved, Default: oldValue})
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			diffs = append(diffs, models.FieldDiff{Field: path, Change: models.FieldChanged, Default: oldValue, Override: newValue})
+		}
+	}
+	for path, newValue := range flatAfter {
+		if _, ok := flatBefore[path]; !ok {
+			diffs = append(diffs, models.FieldDiff{Field: path, Change: models.FieldAdded, Override: newValue})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Field < diffs[j].Field })
+	return diffs
+}
+
+// DiffOverrides is DiffFields limited to the paths the overrides set, so
+// timestamps, IDs and other values drawn afresh are left out
+func DiffOverrides(before, after, overrides map[string]interface{}) []models.FieldDiff {
+	diffs := make([]models.FieldDiff, 0)
+	for _, d := range DiffFields(before, after) {
+		for key := range overrides {
+			if d.Field == key || strings.HasPrefix(d.Field, key+".") {
+				diffs = append(diffs, d)
+				break
+			}
+		}
+	}
+	return diffs
+}
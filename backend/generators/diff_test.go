@@ -0,0 +1,53 @@
+package generators
+
+import (
+	"testing"
+
+	"siem-event-generator/models"
+)
+
+func TestDiffOverridesListsOnlyOverriddenPaths(t *testing.T) {
+	g, _ := GetGenerator("okta")
+	overrides := map[string]interface{}{"displayMessage": "Changed by test"}
+	for _, tmpl := range g.GetTemplates() {
+		def, err := NewRandomStream(5).Generate(g, tmpl.ID, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl.ID, err)
+		}
+		event, err := NewRandomStream(5).Generate(g, tmpl.ID, overrides)
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl.ID, err)
+		}
+		diffs := DiffOverrides(def.Fields, event.Fields, overrides)
+		if len(diffs) != 1 || diffs[0].Field != "displayMessage" || diffs[0].Override != "Changed by test" {
+			t.Errorf("%s: diff = %+v, want only displayMessage", tmpl.ID, diffs)
+		}
+	}
+}
+
+func TestDiffOverridesKeepsNestedPaths(t *testing.T) {
+	before := map[string]interface{}{
+		"user":      map[string]interface{}{"name": "alice", "id": 1},
+		"timestamp": "2024-01-01T00:00:00Z",
+		"username":  "alice",
+	}
+	after := map[string]interface{}{
+		"user":      map[string]interface{}{"name": "bob"},
+		"timestamp": "2024-01-01T00:00:05Z",
+		"username":  "alice",
+	}
+	diffs := DiffOverrides(before, after, map[string]interface{}{"user": after["user"]})
+
+	want := []models.FieldDiff{
+		{Field: "user.id", Change: models.FieldRemoved, Default: 1},
+		{Field: "user.name", Change: models.FieldChanged, Default: "alice", Override: "bob"},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("diff = %+v, want %+v", diffs, want)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Errorf("diff[%d] = %+v, want %+v", i, diffs[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// Distribution types understood by SampleDistribution
+const (
+	DistUniform   = "uniform"
+	DistGaussian  = "gaussian"
+	DistLogNormal = "lognormal"
+	DistZipf      = "zipf"
+	DistWeighted  = "weighted"
+)
+
+// distributionKey marks an override value as a distribution spec, e.g.
+// {"response_time": {"$distribution": {"type": "lognormal", "median": 120, "sigma": 0.6}}}
+const distributionKey = "$distribution"
+
+// RandomFloat returns a uniformly distributed float in [min, max)
+func (b *BaseGenerator) RandomFloat(min, max float64) float64 {
+	return min + b.rnd.float64()*(max-min)
+}
+
+// RandomGaussian samples a normal distribution
+func (b *BaseGenerator) RandomGaussian(mean, stddev float64) float64 {
+	// Box-Muller transform; 1-u keeps the log argument in (0, 1]
+	u1 := 1 - b.rnd.float64()
+	u2 := b.rnd.float64()
+	z := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+	return mean + z*stddev
+}
+
+// RandomLogNormal samples a log-normal distribution described by its median
+// and the standard deviation of the underlying normal. Latencies, payload
+// sizes and durations follow this long-tailed shape.
+func (b *BaseGenerator) RandomLogNormal(median, sigma float64) float64 {
+	return median * math.Exp(b.RandomGaussian(0, sigma))
+}
+
+// RandomZipf returns a rank in [0, n) where rank 0 is the most frequent and
+// rank k has probability proportional to 1/(k+1)^s. A handful of users,
+// hosts and endpoints dominating traffic follows this shape.
+func (b *BaseGenerator) RandomZipf(n int, s float64) int {
+	if n <= 1 {
+		return 0
+	}
+	weights := make([]float64, n)
+	for k := range weights {
+		weights[k] = 1 / math.Pow(float64(k+1), s)
+	}
+	return b.weightedIndex(weights)
+}
+
+// ZipfChoice selects from choices with earlier entries more popular
+func (b *BaseGenerator) ZipfChoice(choices []string) string {
+	if len(choices) == 0 {
+		return ""
+	}
+	return choices[b.RandomZipf(len(choices), 1.1)]
+}
+
+// WeightedChoice selects from choices with probability proportional to weights
+func (b *BaseGenerator) WeightedChoice(choices []string, weights []float64) string {
+	if len(choices) == 0 {
+		return ""
+	}
+	if len(weights) != len(choices) {
+		return b.RandomChoice(choices)
+	}
+	return choices[b.weightedIndex(weights)]
+}
+
+// weightedIndex picks an index with probability proportional to its weight
+func (b *BaseGenerator) weightedIndex(weights []float64) int {
+	return b.rnd.weightedIndex(weights)
+}
+
+// weightedIndex picks an index with probability proportional to its weight
+func (s *RandomStream) weightedIndex(weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	if total <= 0 {
+		return 0
+	}
+	target := s.float64() * total
+	cumulative := 0.0
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		cumulative += w
+		if target < cumulative {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
+
+// ValidateDistribution checks that a spec has the parameters its type needs
+func ValidateDistribution(spec *models.DistributionSpec) error {
+	switch spec.Type {
+	case DistUniform:
+		if spec.Min == nil || spec.Max == nil || *spec.Max < *spec.Min {
+			return fmt.Errorf("uniform distribution requires min <= max")
+		}
+	case DistGaussian:
+		if spec.StdDev < 0 {
+			return fmt.Errorf("gaussian distribution requires stddev >= 0")
+		}
+	case DistLogNormal:
+		if spec.Median <= 0 || spec.Sigma < 0 {
+			return fmt.Errorf("lognormal distribution requires median > 0 and sigma >= 0")
+		}
+	case DistZipf:
+		if len(spec.Values) == 0 && spec.N <= 0 {
+			return fmt.Errorf("zipf distribution requires values or n > 0")
+		}
+	case DistWeighted:
+		if len(spec.Values) == 0 || len(spec.Weights) != len(spec.Values) {
+			return fmt.Errorf("weighted distribution requires values with matching weights")
+		}
+	default:
+		return fmt.Errorf("unknown distribution type: %s", spec.Type)
+	}
+	return nil
+}
+
+// SampleDistribution draws a value from a distribution spec. Numeric
+// distributions are clamped to min/max when set and rounded when requested;
+// zipf and weighted return one of spec.Values (zipf without values returns the rank).
+func (b *BaseGenerator) SampleDistribution(spec *models.DistributionSpec) (interface{}, error) {
+	if err := ValidateDistribution(spec); err != nil {
+		return nil, err
+	}
+
+	var v float64
+	switch spec.Type {
+	case DistUniform:
+		v = b.RandomFloat(*spec.Min, *spec.Max)
+	case DistGaussian:
+		v = b.RandomGaussian(spec.Mean, spec.StdDev)
+	case DistLogNormal:
+		v = b.RandomLogNormal(spec.Median, spec.Sigma)
+	case DistZipf:
+		s := spec.S
+		if s <= 0 {
+			s = 1.1
+		}
+		n := spec.N
+		if len(spec.Values) > 0 {
+			n = len(spec.Values)
+		}
+		rank := b.RandomZipf(n, s)
+		if len(spec.Values) > 0 {
+			return spec.Values[rank], nil
+		}
+		return rank, nil
+	case DistWeighted:
+		return spec.Values[b.weightedIndex(spec.Weights)], nil
+	}
+
+	if spec.Min != nil && v < *spec.Min {
+		v = *spec.Min
+	}
+	if spec.Max != nil && v > *spec.Max {
+		v = *spec.Max
+	}
+	if spec.Round {
+		return int64(math.Round(v)), nil
+	}
+	return v, nil
+}
+
+// distributionFromOverride extracts a distribution spec from an override
+// value. The boolean reports whether the value is a distribution at all.
+func distributionFromOverride(value interface{}) (*models.DistributionSpec, bool, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false, nil
+	}
+	raw, ok := m[distributionKey]
+	if !ok {
+		return nil, false, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid distribution: %w", err)
+	}
+	var spec models.DistributionSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, true, fmt.Errorf("invalid distribution: %w", err)
+	}
+	if err := ValidateDistribution(&spec); err != nil {
+		return nil, true, err
+	}
+	return &spec, true, nil
+}
+
+// declaredCache memoizes the distributions templates declare by
+// "eventType/templateID"
+var declaredCache sync.Map
+
+// declaredDistributions returns the distributions a template declares for
+// its fields, by field name
+func declaredDistributions(g Generator, templateID string) map[string]*models.DistributionSpec {
+	key := g.GetEventType().ID + "/" + templateID
+	if d, ok := declaredCache.Load(key); ok {
+		return d.(map[string]*models.DistributionSpec)
+	}
+	declared := make(map[string]*models.DistributionSpec)
+	for _, t := range g.GetTemplates() {
+		if t.ID != templateID {
+			continue
+		}
+		for _, f := range t.Fields {
+			if f.Distribution != nil && ValidateDistribution(f.Distribution) == nil {
+				declared[f.Name] = f.Distribution
+			}
+		}
+	}
+	declaredCache.Store(key, declared)
+	return declared
+}
+
+// forgetDeclared drops the memoized distributions of an event type's
+// templates, for when a plugin registers or unregisters it
+func forgetDeclared(eventTypeID string) {
+	declaredCache.Range(func(key, _ interface{}) bool {
+		if strings.HasPrefix(key.(string), eventTypeID+"/") {
+			declaredCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// declaredFloat draws a number for field from the distribution templateID
+// of g declares for it, or from fallback when the template declares none
+func (b *BaseGenerator) declaredFloat(g Generator, templateID, field string, fallback *models.DistributionSpec) float64 {
+	spec, ok := declaredDistributions(g, templateID)[field]
+	if !ok {
+		spec = fallback
+	}
+	switch v, _ := b.SampleDistribution(spec); v := v.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
+
+// withDeclaredDistributions returns overrides plus a value sampled from each
+// distribution the template of a plugin declares for a field the caller left
+// alone. Built-in generators draw declared fields themselves while building
+// the event. overrides itself is not modified.
+func withDeclaredDistributions(g Generator, templateID string, overrides map[string]interface{}) map[string]interface{} {
+	if _, ok := g.(seedable); ok {
+		return overrides
+	}
+	declared := declaredDistributions(g, templateID)
+	if len(declared) == 0 {
+		return overrides
+	}
+	var sampler BaseGenerator
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	merged := make(map[string]interface{}, len(overrides)+len(declared))
+	for _, name := range names {
+		if v, err := sampler.SampleDistribution(declared[name]); err == nil {
+			merged[name] = v
+		}
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,75 @@
+package generators
+
+import (
+	"testing"
+
+	"siem-event-generator/models"
+)
+
+// declaringGenerator declares a fixed distribution for one field
+type declaringGenerator struct {
+	BaseGenerator
+	fixed float64
+}
+
+func (g *declaringGenerator) GetEventType() models.EventType {
+	return models.EventType{ID: "test_declaring"}
+}
+
+func (g *declaringGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{{
+		ID: "t",
+		Fields: []models.EventField{{
+			Name:         "latency",
+			Type:         "number",
+			Distribution: &models.DistributionSpec{Type: DistUniform, Min: &g.fixed, Max: &g.fixed},
+		}},
+	}}
+}
+
+func (g *declaringGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	return &models.GeneratedEvent{Fields: map[string]interface{}{
+		"latency": g.declaredFloat(g, templateID, "latency", webResponseTime),
+	}}, nil
+}
+
+func TestDeclaredFloatReadsTemplateSpec(t *testing.T) {
+	g := &declaringGenerator{fixed: 7}
+	defer forgetDeclared("test_declaring")
+
+	if v := g.declaredFloat(g, "t", "latency", webResponseTime); v != 7 {
+		t.Errorf("declared field drew %v, want 7", v)
+	}
+	lo := 3.0
+	fallback := &models.DistributionSpec{Type: DistUniform, Min: &lo, Max: &lo}
+	if v := g.declaredFloat(g, "t", "other", fallback); v != 3 {
+		t.Errorf("undeclared field drew %v, want the fallback's 3", v)
+	}
+}
+
+func TestRegisterForgetsDeclaredDistributions(t *testing.T) {
+	g := &declaringGenerator{fixed: 1}
+	if err := registerNew(g); err != nil {
+		t.Fatal(err)
+	}
+	if v := g.declaredFloat(g, "t", "latency", webResponseTime); v != 1 {
+		t.Fatalf("drew %v, want 1", v)
+	}
+	unregister("test_declaring")
+
+	g = &declaringGenerator{fixed: 2}
+	if err := registerNew(g); err != nil {
+		t.Fatal(err)
+	}
+	defer unregister("test_declaring")
+	if v := g.declaredFloat(g, "t", "latency", webResponseTime); v != 2 {
+		t.Errorf("drew %v after re-registering, want 2", v)
+	}
+}
+
+func TestBuiltinDeclaredFieldsAreNotOverrides(t *testing.T) {
+	g, _ := GetGenerator("webserver")
+	if merged := withDeclaredDistributions(unwrap(g), "success", nil); len(merged) != 0 {
+		t.Errorf("built-in generator got declared fields as overrides: %v", merged)
+	}
+}
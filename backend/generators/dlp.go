@@ -0,0 +1,532 @@
+package generators
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// DLPGenerator generates data loss prevention policy violations in
+// Microsoft Purview and Symantec DLP formats. Violators are pool users on
+// their workstations, and files come from the file catalog, so a file's
+// hashes match its other sightings.
+type DLPGenerator struct {
+	BaseGenerator
+	mu       sync.Mutex
+	incident int // Last Symantec incident ID
+}
+
+func init() {
+	Register(&DLPGenerator{incident: 48000})
+}
+
+// dlpPolicy is a DLP policy in both products, the data it detects and the
+// documents that hold such data
+type dlpPolicy struct {
+	name        string // Purview policy
+	symantec    string // Symantec policy
+	rule        string
+	severity    string
+	identifiers []string // Sensitive information types
+	documents   []string
+}
+
+var dlpPolicies = []dlpPolicy{
+	{"U.S. Financial Data", "PCI DSS", "Credit card and bank account numbers", "High",
+		[]string{"Credit Card Number", "ABA Routing Number", "U.S. Bank Account Number"},
+		[]string{"Q3_Financial_Report.pdf", "budget_forecast.xlsx", "Vendor Payment Run.xlsx", "CONFIDENTIAL - Customer List Export.xlsx"}},
+	{"U.S. PII Data", "US Social Security Numbers", "SSN and passport numbers", "High",
+		[]string{"U.S. Social Security Number (SSN)", "U.S. / U.K. Passport Number", "All Full Names"},
+		[]string{"Employee Records Export.csv", "Salary Review - All Employees.xlsx", "benefits_enrollment.xlsx"}},
+	{"GDPR Enhanced", "EU Personal Data", "EU financial and identity data", "Medium",
+		[]string{"International Banking Account Number (IBAN)", "EU Debit Card Number", "All Full Names"},
+		[]string{"CONFIDENTIAL - Customer List Export.xlsx", "eu_customers.csv", "SEPA_payments.xlsx"}},
+	{"Source Code Protection", "Source Code", "Source code leaving the organization", "Medium",
+		[]string{"Source code"},
+		[]string{"Source Code - payment-service.pdf", "payment-service-src.zip", "auth_module.py"}},
+	{"Credentials in Content", "Credentials and Keys", "Passwords and cloud access keys", "High",
+		[]string{"General Password", "Azure Storage Account Key", "Amazon S3 Client Secret Access Key"},
+		[]string{"aws_keys.txt", "deploy-config.yaml", "passwords.xlsx"}},
+	{"Confidential Label", "Confidential Documents", "Documents labeled Confidential", "Medium",
+		[]string{"Confidential keyword dictionary"},
+		[]string{"Product Roadmap - Internal Only.pptx", "M&A Target Analysis - Restricted.pptx", "Pricing Model - Confidential.xlsx", "meeting_notes.docx"}},
+}
+
+// DLP actions, from least to most restrictive
+const (
+	dlpAudit    = "audit"
+	dlpNotify   = "notify"
+	dlpOverride = "override" // Blocked, and the user overrode with a justification
+	dlpBlock    = "block"
+)
+
+// dlpCloudDomains are the personal cloud storage and file transfer sites
+// users upload to
+var dlpCloudDomains = []string{"drive.google.com", "www.dropbox.com", "wetransfer.com", "mega.nz", "onedrive.live.com", "box.com"}
+
+// dlpUSBDevices are the removable drives users copy to
+var dlpUSBDevices = [][2]string{
+	{"SanDisk", "Ultra Fit"}, {"Kingston", "DataTraveler 3.0"}, {"Samsung", "BAR Plus"}, {"Seagate", "Expansion HDD"}, {"WD", "My Passport"},
+}
+
+// dlpViolation is one policy match: who moved which file, and what the
+// policy did about it
+type dlpViolation struct {
+	user   *EntityUser
+	host   *EntityHost
+	policy dlpPolicy
+	file   CatalogFile
+	counts []int // Matches of each of the policy's identifiers
+	action string
+	time   time.Time
+}
+
+// GetEventType returns the event type for DLP events
+func (g *DLPGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "dlp",
+		Name:        "Data Loss Prevention",
+		Category:    "endpoint",
+		Description: "DLP policy violations from Microsoft Purview and Symantec DLP: email, SharePoint sharing, USB copies, cloud uploads and printing",
+		EventIDs:    []string{"DLPRuleMatch", "DLPEndpoint", "Network Incident", "Endpoint Incident"},
+	}
+}
+
+// GetTemplates returns available templates for DLP events
+func (g *DLPGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "purview_exchange",
+			Name:        "Purview Email Rule Match",
+			Category:    "dlp",
+			EventID:     "DLPRuleMatch",
+			Format:      "json",
+			Description: "An Exchange Online message with sensitive attachments matches a DLP rule",
+			Sourcetype:  "o365:management:activity",
+		},
+		{
+			ID:          "purview_sharepoint",
+			Name:        "Purview SharePoint Rule Match",
+			Category:    "dlp",
+			EventID:     "DLPRuleMatch",
+			Format:      "json",
+			Description: "A SharePoint or OneDrive file with sensitive content is shared outside the organization",
+			Sourcetype:  "o365:management:activity",
+		},
+		{
+			ID:          "purview_endpoint",
+			Name:        "Purview Endpoint Activity",
+			Category:    "dlp",
+			EventID:     "DLPEndpoint",
+			Format:      "json",
+			Description: "Endpoint DLP sees a sensitive file copied to USB, uploaded to cloud storage, printed or copied to a share",
+			Sourcetype:  "o365:management:activity",
+		},
+		{
+			ID:          "symantec_network",
+			Name:        "Symantec Network Incident",
+			Category:    "dlp",
+			EventID:     "Network Incident",
+			Format:      "syslog",
+			Description: "Network Prevent for Email or Web finds sensitive data in a message or upload",
+			Sourcetype:  "symantec:dlp:syslog",
+		},
+		{
+			ID:          "symantec_endpoint",
+			Name:        "Symantec Endpoint Incident",
+			Category:    "dlp",
+			EventID:     "Endpoint Incident",
+			Format:      "syslog",
+			Description: "Endpoint Prevent finds sensitive data copied to USB, uploaded, printed or copied to the clipboard",
+			Sourcetype:  "symantec:dlp:syslog",
+		},
+	}
+}
+
+// Generate creates a DLP event
+func (g *DLPGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "purview_exchange":
+		return g.generatePurviewExchange(overrides)
+	case "purview_sharepoint":
+		return g.generatePurviewSharePoint(overrides)
+	case "purview_endpoint":
+		return g.generatePurviewEndpoint(overrides)
+	case "symantec_network":
+		return g.generateSymantec(false, overrides)
+	case "symantec_endpoint":
+		return g.generateSymantec(true, overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// violation draws a policy match by a pool user on their workstation
+func (g *DLPGenerator) violation() *dlpViolation {
+	user := Entities.RandomUser(g.rnd)
+	host := Entities.HostForUser(g.rnd, user.Username)
+	policy := dlpPolicies[g.RandomInt(0, len(dlpPolicies)-1)]
+	dir := `C:\Users\` + user.Username + `\Documents\`
+	if host.Platform != "windows" {
+		dir = "/Users/" + user.Username + "/Documents/"
+	}
+	v := &dlpViolation{
+		user:   user,
+		host:   host,
+		policy: policy,
+		file:   Files.ForName(dir + g.RandomChoice(policy.documents)),
+		action: g.WeightedChoice([]string{dlpAudit, dlpNotify, dlpOverride, dlpBlock}, []float64{25, 35, 15, 25}),
+		time:   time.Now().UTC(),
+	}
+	for range policy.identifiers {
+		v.counts = append(v.counts, 1+int(g.RandomLogNormal(5, 1.2)))
+	}
+	return v
+}
+
+// matches is the total of the violation's identifier matches
+func (v *dlpViolation) matches() int {
+	total := 0
+	for _, c := range v.counts {
+		total += c
+	}
+	return total
+}
+
+// sensitiveTypeID is the stable ID of a sensitive information type
+func sensitiveTypeID(name string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("sit/"+name)).String()
+}
+
+// purviewPolicy is the PolicyDetails entry of a Purview rule match
+func (g *DLPGenerator) purviewPolicy(v *dlpViolation) []map[string]interface{} {
+	actions := map[string][]string{
+		dlpAudit:    {"GenerateIncidentReport"},
+		dlpNotify:   {"NotifyUser", "GenerateIncidentReport"},
+		dlpOverride: {"NotifyUser", "BlockAccess", "GenerateIncidentReport"},
+		dlpBlock:    {"NotifyUser", "BlockAccess", "GenerateIncidentReport"},
+	}[v.action]
+
+	var sensitive []map[string]interface{}
+	for i, name := range v.policy.identifiers {
+		sensitive = append(sensitive, map[string]interface{}{
+			"ClassifierType":                     "Content",
+			"Confidence":                         []int{75, 85, 85, 95}[g.RandomInt(0, 3)],
+			"Count":                              v.counts[i],
+			"SensitiveInformationTypeName":       name,
+			"SensitiveType":                      sensitiveTypeID(name),
+			"UniqueCount":                        (v.counts[i] + 1) / 2,
+			"SensitiveInformationDetectionsInfo": map[string]interface{}{"HasMoreDetections": v.counts[i] > 2},
+		})
+	}
+	rule := map[string]interface{}{
+		"RuleId":           dlpRuleID(v.policy),
+		"RuleName":         v.policy.rule,
+		"RuleMode":         "Enable",
+		"Severity":         v.policy.severity,
+		"Actions":          actions,
+		"ManagementRuleId": uuid.NewSHA1(uuid.NameSpaceOID, []byte("dlp/"+v.policy.rule)).String(),
+		"ConditionsMatched": map[string]interface{}{
+			"ConditionMatchedInNewScheme": true,
+			"SensitiveInformation":        sensitive,
+		},
+	}
+	if v.action == dlpOverride {
+		rule["OverriddenBy"] = v.user.Email
+		rule["OverrideJustification"] = g.RandomChoice([]string{"Business justification: sending to customer", "Manager approved", "False positive"})
+	}
+	return []map[string]interface{}{{
+		"PolicyId":   dlpPolicyID(v.policy),
+		"PolicyName": v.policy.name,
+		"Rules":      []map[string]interface{}{rule},
+	}}
+}
+
+// dlpPolicyID is the stable ID of a Purview policy
+func dlpPolicyID(p dlpPolicy) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("dlp/"+p.name)).String()
+}
+
+// dlpRuleID is the stable ID of a Purview policy's rule
+func dlpRuleID(p dlpPolicy) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("dlp/"+p.name+"/"+p.rule)).String()
+}
+
+// purviewBase builds the fields every Purview audit record has
+func (g *DLPGenerator) purviewBase(v *dlpViolation, operation, workload string, recordType int) map[string]interface{} {
+	return map[string]interface{}{
+		"CreationTime":   v.time.Format("2006-01-02T15:04:05"),
+		"Id":             uuid.New().String(),
+		"Operation":      operation,
+		"OrganizationId": sentinelTenantID,
+		"RecordType":     recordType,
+		"UserKey":        fmt.Sprintf("1003200%09X", v.user.UID),
+		"UserType":       0,
+		"Version":        1,
+		"Workload":       workload,
+		"UserId":         v.user.Email,
+		"IncidentId":     uuid.New().String(),
+	}
+}
+
+// generatePurviewExchange creates a rule match on a message a user sends
+// with a sensitive attachment, usually outside the organization
+func (g *DLPGenerator) generatePurviewExchange(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	v := g.violation()
+	_, to := g.RandomExternalEmail()
+	if g.RandomInt(1, 100) <= 20 {
+		to = g.RandomInternalEmail()
+	}
+	messageID := g.MessageID(v.user.Email, v.time)
+
+	event := g.purviewBase(v, "DLPRuleMatch", "Exchange", 13)
+	event["ObjectId"] = messageID
+	event["PolicyDetails"] = g.purviewPolicy(v)
+	event["SensitiveInfoDetectionIsIncluded"] = true
+	event["ExchangeMetaData"] = map[string]interface{}{
+		"BCC":            []string{},
+		"CC":             []string{},
+		"FileSize":       v.file.Size + g.RandomInt(4000, 40000),
+		"From":           v.user.Email,
+		"MessageID":      messageID,
+		"RecipientCount": 1,
+		"Sent":           v.time.Format("2006-01-02T15:04:05"),
+		"Subject":        g.RandomChoice([]string{"FW: ", "Re: ", "", "Sending you "}) + strings.TrimSuffix(v.file.Name, path.Ext(v.file.Name)),
+		"To":             []string{to},
+		"UniqueID":       uuid.New().String(),
+		"Attachments":    []string{v.file.Name},
+	}
+	return g.purviewEvent(v, "DLPRuleMatch", event, overrides)
+}
+
+// generatePurviewSharePoint creates a rule match on a file a user shares
+// from their OneDrive or a team site, usually with guests
+func (g *DLPGenerator) generatePurviewSharePoint(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	v := g.violation()
+	workload := g.RandomChoice([]string{"SharePoint", "OneDrive"})
+	site := fmt.Sprintf("https://%s.sharepoint.com/sites/%s", tenantName(), strings.ToLower(v.user.Department))
+	if workload == "OneDrive" {
+		site = fmt.Sprintf("https://%s-my.sharepoint.com/personal/%s", tenantName(), strings.NewReplacer(".", "_", "@", "_").Replace(v.user.Email))
+	}
+	external := g.RandomInt(1, 100) <= 70
+
+	url := site + "/Shared Documents/" + v.file.Name
+	event := g.purviewBase(v, "DLPRuleMatch", workload, 11)
+	event["ObjectId"] = url
+	event["PolicyDetails"] = g.purviewPolicy(v)
+	event["SensitiveInfoDetectionIsIncluded"] = true
+	event["SharePointMetaData"] = map[string]interface{}{
+		"FileID":                    uuid.New().String(),
+		"FileName":                  v.file.Name,
+		"FilePathUrl":               url,
+		"FileOwner":                 v.user.FullName,
+		"From":                      v.user.Email,
+		"ItemCreationTime":          v.time.Add(-time.Duration(g.RandomInt(1, 720)) * time.Hour).Format("2006-01-02T15:04:05"),
+		"ItemLastModifiedTime":      v.time.Add(-time.Duration(g.RandomInt(1, 60)) * time.Minute).Format("2006-01-02T15:04:05"),
+		"SiteCollectionUrl":         site,
+		"SiteCollectionGuid":        uuid.NewSHA1(uuid.NameSpaceURL, []byte(site)).String(),
+		"IsViewableByExternalUsers": external,
+		"IsVisibleOnlyToOdbOwner":   false,
+		"SensitivityLabelIds":       []string{},
+		"UniqueID":                  uuid.New().String(),
+	}
+	return g.purviewEvent(v, "DLPRuleMatch", event, overrides)
+}
+
+// generatePurviewEndpoint creates an Endpoint DLP activity on a user's
+// workstation
+func (g *DLPGenerator) generatePurviewEndpoint(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	v := g.violation()
+	operation := g.WeightedChoice([]string{"FileCopiedToRemovableMedia", "FileUploadedToCloud", "FilePrinted", "FileCopiedToNetworkShare"}, []float64{35, 35, 15, 15})
+	application := "explorer.exe"
+
+	event := g.purviewBase(v, operation, "Endpoint", 63)
+	event["ClientIP"] = v.host.IP
+	event["DeviceName"] = v.host.FQDN
+	event["Platform"] = map[string]int{"windows": 1, "darwin": 2}[v.host.Platform]
+	event["ObjectId"] = v.file.Path
+	event["FileExtension"] = strings.TrimPrefix(path.Ext(v.file.Name), ".")
+	event["FileSize"] = v.file.Size
+	event["Sha1"] = v.file.SHA1
+	event["Sha256"] = v.file.SHA256
+	event["SourceLocationType"] = 1 // Local file system
+	event["RMSEncrypted"] = false
+	event["EnforcementMode"] = map[string]int{dlpAudit: 1, dlpNotify: 1, dlpOverride: 2, dlpBlock: 3}[v.action]
+	event["PolicyMatchInfo"] = map[string]interface{}{
+		"PolicyId":   dlpPolicyID(v.policy),
+		"PolicyName": v.policy.name,
+		"RuleId":     dlpRuleID(v.policy),
+		"RuleName":   v.policy.rule,
+	}
+	var sensitive []map[string]interface{}
+	for i, name := range v.policy.identifiers {
+		sensitive = append(sensitive, map[string]interface{}{
+			"SensitiveInfoTypeId":   sensitiveTypeID(name),
+			"SensitiveInfoTypeName": name,
+			"Count":                 v.counts[i],
+			"Confidence":            85,
+		})
+	}
+	event["SensitiveInfoTypeData"] = sensitive
+
+	switch operation {
+	case "FileCopiedToRemovableMedia":
+		device := dlpUSBDevices[g.RandomInt(0, len(dlpUSBDevices)-1)]
+		event["DestinationLocationType"] = 2
+		event["TargetFilePath"] = fmt.Sprintf(`%s:\%s`, g.RandomChoice([]string{"E", "F", "G"}), v.file.Name)
+		event["RemovableMediaDeviceAttributes"] = map[string]interface{}{
+			"Manufacturer": device[0],
+			"Model":        device[1],
+			"SerialNumber": strings.ToUpper(g.RandomHex(10)),
+			"BusType":      "USB",
+		}
+	case "FileUploadedToCloud":
+		domain := g.RandomChoice(dlpCloudDomains)
+		application = g.RandomChoice([]string{"chrome.exe", "msedge.exe", "firefox.exe"})
+		event["DestinationLocationType"] = 3
+		event["TargetDomain"] = domain
+		event["TargetUrl"] = "https://" + domain + "/upload"
+	case "FilePrinted":
+		p, ok := userProfile(v.user)
+		site := facilitySites[0]
+		if ok {
+			site = siteOf(p)
+		}
+		printer, _ := site.printer(g.RandomInt(1, 4), g.RandomInt(1, 3))
+		application = g.RandomChoice([]string{"AcroRd32.exe", "WINWORD.EXE", "EXCEL.EXE"})
+		event["DestinationLocationType"] = 4
+		event["TargetPrinterName"] = `\\` + site.server("print") + `\` + printer
+	default:
+		share := fmt.Sprintf(`\\%s\%s`, g.RandomChoice([]string{"file-01", "file-02", "nas-backup"}), g.RandomChoice([]string{"public", "transfer", "scratch"}))
+		event["DestinationLocationType"] = 5
+		event["TargetFilePath"] = share + `\` + v.file.Name
+	}
+	event["Application"] = application
+	return g.purviewEvent(v, "DLPEndpoint", event, overrides)
+}
+
+// userProfile returns the behavioral profile of a pool user
+func userProfile(u *EntityUser) (*behaviorProfile, bool) {
+	for _, p := range occupants() {
+		if p.user.Username == u.Username {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+func (g *DLPGenerator) purviewEvent(v *dlpViolation, eventID string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "dlp",
+		EventID:    eventID,
+		Timestamp:  v.time,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "o365:management:activity",
+	}, nil
+}
+
+// symantecResponses are how Symantec DLP reports each action
+var symantecResponses = map[string]string{
+	dlpAudit:    "Passed",
+	dlpNotify:   "User Notified",
+	dlpOverride: "User Override",
+	dlpBlock:    "Blocked",
+}
+
+// generateSymantec creates a Symantec DLP incident sent by the Enforce
+// server's syslog response rule, for Network Prevent or Endpoint Prevent
+func (g *DLPGenerator) generateSymantec(endpoint bool, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	v := g.violation()
+	g.mu.Lock()
+	g.incident++
+	incident := g.incident
+	g.mu.Unlock()
+
+	fields := map[string]interface{}{
+		"incident_id":      incident,
+		"policy":           v.policy.symantec,
+		"rules":            v.policy.rule,
+		"data_identifiers": strings.Join(v.policy.identifiers, ", "),
+		"severity":         v.policy.severity,
+		"match_count":      v.matches(),
+		"blocked":          symantecResponses[v.action],
+		"file_name":        v.file.Name,
+		"file_size":        v.file.Size,
+		"incident_snapshot": fmt.Sprintf("https://%s/ProtectManager/IncidentDetail.do?value(variable_1)=incident.id&value(operator_1)=incident.id_in&value(operand_1)=%d",
+			"dlp-enforce."+Theme().InternalDomain, incident),
+	}
+
+	eventID := "Network Incident"
+	if endpoint {
+		eventID = "Endpoint Incident"
+		channel := g.WeightedChoice([]string{"Removable Storage Device", "HTTPS", "Printer/Fax", "Clipboard"}, []float64{40, 30, 15, 15})
+		fields["product"] = "Endpoint Prevent"
+		fields["protocol"] = channel
+		fields["endpoint_machine"] = v.host.Hostname
+		fields["endpoint_username"] = windowsNetBIOSDomain() + `\` + v.user.Username
+		fields["endpoint_ip"] = v.host.IP
+		fields["file_path"] = v.file.Path
+		fields["application_name"] = map[string]string{
+			"Removable Storage Device": "explorer.exe", "HTTPS": "chrome.exe", "Printer/Fax": "WINWORD.EXE", "Clipboard": "OUTLOOK.EXE",
+		}[channel]
+		switch channel {
+		case "Removable Storage Device":
+			device := dlpUSBDevices[g.RandomInt(0, len(dlpUSBDevices)-1)]
+			fields["endpoint_device_id"] = fmt.Sprintf(`USBSTOR\DISK&VEN_%s&PROD_%s\%s`, strings.ToUpper(device[0]), strings.ToUpper(strings.ReplaceAll(device[1], " ", "_")), strings.ToUpper(g.RandomHex(10)))
+		case "HTTPS":
+			fields["url"] = "https://" + g.RandomChoice(dlpCloudDomains) + "/upload"
+		}
+	} else {
+		fields["product"] = "Network Prevent"
+		fields["sender"] = v.user.Email
+		if g.RandomInt(1, 100) <= 65 {
+			_, to := g.RandomExternalEmail()
+			fields["protocol"] = "SMTP"
+			fields["recipients"] = to
+			fields["subject"] = "FW: " + strings.TrimSuffix(v.file.Name, path.Ext(v.file.Name))
+			fields["attachment_file_name"] = v.file.Name
+			delete(fields, "file_name")
+		} else {
+			fields["protocol"] = "HTTPS"
+			fields["sender"] = v.host.IP
+			fields["url"] = "https://" + g.RandomChoice(dlpCloudDomains) + "/upload"
+		}
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	keys := []string{"incident_id", "product", "policy", "rules", "data_identifiers", "severity", "match_count", "blocked", "protocol",
+		"sender", "recipients", "subject", "attachment_file_name", "url", "endpoint_machine", "endpoint_username", "endpoint_ip",
+		"endpoint_device_id", "application_name", "file_name", "file_path", "file_size", "incident_snapshot"}
+	var pairs []string
+	for _, k := range keys {
+		if value, ok := fields[k]; ok {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, strings.ReplaceAll(fmt.Sprint(value), `"`, `'`)))
+		}
+	}
+	rawEvent := fmt.Sprintf("<132>%s dlp-enforce SymantecDLP: %s", v.time.Format("Jan 02 15:04:05"), strings.Join(pairs, " "))
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "dlp",
+		EventID:    eventID,
+		Timestamp:  v.time,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "symantec:dlp:syslog",
+	}, nil
+}
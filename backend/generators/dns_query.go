@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -105,7 +104,8 @@ func (g *DNSQueryGenerator) Generate(templateID string, overrides map[string]int
 }
 
 func (g *DNSQueryGenerator) randomDNSServer() string {
-	servers := []string{"dns-01.corp.local", "dns-02.corp.local", "pi-hole.home.local", "10.0.0.53", "10.0.1.53"}
+	domain := Theme().ADDomain
+	servers := []string{"dns-01." + domain, "dns-02." + domain, "pi-hole.home.local", "10.0.0.53", "10.0.1.53"}
 	return g.RandomChoice(servers)
 }
 
@@ -120,12 +120,12 @@ func (g *DNSQueryGenerator) randomLegitDomain() string {
 		"cdn.cloudflare.com", "s3.amazonaws.com", "update.microsoft.com",
 		"www.office.com", "teams.microsoft.com", "zoom.us", "slack.com",
 	}
-	return g.RandomChoice(domains)
+	return g.InjectIOC(models.IOCTypeDomain, g.RandomChoice(domains))
 }
 
 func (g *DNSQueryGenerator) randomMaliciousDomain() string {
-	// DGA-like domains
-	return fmt.Sprintf("%s.%s", g.RandomString(g.RandomInt(8, 20)), g.RandomChoice([]string{"xyz", "top", "tk", "ml", "ga", "cf"}))
+	// DGA-like domains, or a domain indicator from the IOC pool
+	return g.InjectIOC(models.IOCTypeDomain, fmt.Sprintf("%s.%s", g.RandomString(g.RandomInt(8, 20)), g.RandomChoice([]string{"xyz", "top", "tk", "ml", "ga", "cf"})))
 }
 
 func (g *DNSQueryGenerator) buildBaseEvent(queryName, queryType, responseCode, action string) map[string]interface{} {
@@ -170,7 +170,7 @@ func (g *DNSQueryGenerator) generateQuerySuccess(overrides map[string]interface{
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -192,7 +192,7 @@ func (g *DNSQueryGenerator) generateQueryNXDomain(overrides map[string]interface
 	event["answers"] = []map[string]interface{}{}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -219,7 +219,7 @@ func (g *DNSQueryGenerator) generateQueryBlocked(overrides map[string]interface{
 	event["block_list"] = g.RandomChoice([]string{"threat-intel-feed", "category-block", "custom-blacklist"})
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -248,7 +248,7 @@ func (g *DNSQueryGenerator) generateQuerySuspicious(overrides map[string]interfa
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -271,7 +271,7 @@ func (g *DNSQueryGenerator) generateQueryExternal(overrides map[string]interface
 	event["violation_type"] = "external_dns_usage"
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -297,7 +297,7 @@ func (g *DNSQueryGenerator) generateQueryTunneling(overrides map[string]interfac
 	event["entropy"] = fmt.Sprintf("%.2f", float64(g.RandomInt(35, 45))/10)
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
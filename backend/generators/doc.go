@@ -0,0 +1,23 @@
+// Package generators produces synthetic security events and metrics.
+//
+// Every event source registers itself with Register from an init function,
+// so importing the package is enough to make all generators available. The
+// package has no HTTP or delivery dependencies and can be embedded in other
+// Go tools:
+//
+//	event, err := generators.Generate("windows_sysmon", "1", nil)
+//
+//	// Stream 1000 Okta events to stdout
+//	n, err := generators.WriteEvents(ctx, os.Stdout, generators.Request{
+//		EventType: "okta",
+//		Count:     1000,
+//	})
+//
+//	// Consume events from a channel until ctx is cancelled
+//	for r := range generators.Stream(ctx, generators.Request{EventType: "zeek", TemplateID: "conn"}) {
+//		if r.Err != nil {
+//			break
+//		}
+//		handle(r.Event)
+//	}
+package generators
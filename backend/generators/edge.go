@@ -0,0 +1,161 @@
+package generators
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// edgeRequest is a request to one of the web tier's sites as the load
+// balancer or CDN in front of it sees it. Sites, paths, status codes and
+// sizes come from the same model as the web server logs and the web/API
+// metrics, so the edge, origin and metric views of a vhost agree.
+type edgeRequest struct {
+	vhost      string
+	kind       string
+	method     string
+	uri        string
+	protocol   string
+	status     int
+	bytesSent  int
+	bytesIn    int
+	userAgent  string
+	referer    string
+	client     GeoLocation
+	clientPort int
+	originTime float64 // Seconds the origin took to answer
+	attack     string  // Category of web attack, if the request is one
+}
+
+// edgeVhosts returns the public sites served through the edge. The admin
+// site is only reachable through the load balancer.
+func edgeVhosts() []string {
+	var vhosts []string
+	for _, v := range webVhosts() {
+		if !strings.HasPrefix(v, "admin.") {
+			vhosts = append(vhosts, v)
+		}
+	}
+	return vhosts
+}
+
+// vhostName returns the name of a site without the theme's domain, such as
+// api for api.example.com
+func vhostName(vhost string) string {
+	return strings.TrimSuffix(vhost, "."+Theme().Domain)
+}
+
+// edgeID returns a stable hex ID of n characters for a named edge resource,
+// such as a load balancer or a CDN distribution
+func edgeID(name string, n int) string {
+	sum := sha256.Sum256([]byte("edge/" + name))
+	return hex.EncodeToString(sum[:])[:n]
+}
+
+// withAttack turns r into a request from the web attack corpus, sent from
+// a malicious address
+func (b *BaseGenerator) withAttack(r *edgeRequest) {
+	attack := webAttacks[b.RandomInt(0, len(webAttacks)-1)]
+	callback := b.RandomIPv4External()
+	if attack.uri != "" {
+		r.uri = strings.ReplaceAll(attack.uri, "%IP%", callback)
+		r.kind = requestKind(r.uri)
+	}
+	if attack.agent != "" {
+		r.userAgent, r.referer = strings.ReplaceAll(attack.agent, "%IP%", callback), "-"
+	}
+	r.client = b.RandomMaliciousGeoIP()
+	r.attack = attack.category
+}
+
+// cacheHit decides whether the edge served r from its cache: most static
+// assets, some pages and never API calls or errors
+func (b *BaseGenerator) cacheHit(r edgeRequest) bool {
+	if r.method != "GET" || (r.status != 200 && r.status != 304) {
+		return false
+	}
+	switch r.kind {
+	case webRequestAsset:
+		return b.RandomInt(1, 100) <= 88
+	case webRequestPage:
+		return b.RandomInt(1, 100) <= 35
+	}
+	return false
+}
+
+// contentType is the content type of the response to r
+func (r edgeRequest) contentType() string {
+	switch {
+	case r.status >= 400:
+		return "text/html"
+	case r.kind == webRequestAPI:
+		return "application/json"
+	case r.kind != webRequestAsset:
+		return "text/html; charset=utf-8"
+	}
+	path := r.path()
+	for ext, t := range map[string]string{
+		".js": "application/javascript", ".css": "text/css", ".png": "image/png", ".jpg": "image/jpeg",
+		".webp": "image/webp", ".svg": "image/svg+xml", ".ico": "image/x-icon", ".woff2": "font/woff2",
+		".json": "application/json", ".xml": "application/xml", ".txt": "text/plain",
+	} {
+		if strings.HasSuffix(path, ext) {
+			return t
+		}
+	}
+	return "application/octet-stream"
+}
+
+// path returns the request's path without its query string
+func (r edgeRequest) path() string {
+	path, _, _ := strings.Cut(r.uri, "?")
+	return path
+}
+
+// query returns the request's query string, without the question mark
+func (r edgeRequest) query() string {
+	_, query, _ := strings.Cut(r.uri, "?")
+	return query
+}
+
+// randomEdgeRequest draws a request to vhost. A status of 0 draws one from
+// the distribution for the request; otherwise the request gets that status.
+// The request is an attack when attack is true, or at the configured attack
+// rate. Health checks never reach the edge, so they are drawn again.
+func (b *BaseGenerator) randomEdgeRequest(vhost string, status int, attack bool) edgeRequest {
+	kind, uri := b.randomRequest(vhost)
+	for i := 0; i < 10 && kind == webRequestHealth; i++ {
+		kind, uri = b.randomRequest(vhost)
+	}
+	r := edgeRequest{
+		vhost:      vhost,
+		kind:       kind,
+		uri:        uri,
+		method:     b.randomMethodFor(kind),
+		protocol:   b.WeightedChoice([]string{"HTTP/1.1", "HTTP/2.0"}, []float64{30, 70}),
+		client:     b.RandomBenignGeoIP(),
+		clientPort: b.RandomInt(1024, 65535),
+		originTime: b.RandomLogNormal(0.08, 0.9),
+	}
+	r.userAgent, r.referer = b.randomClient(kind, vhost)
+	if attack || b.rnd.float64()*100 < WebConfig().AttackRate {
+		b.withAttack(&r)
+	}
+
+	r.status = status
+	if status == 0 {
+		r.status = b.randomStatus(r.kind, r.method)
+		if r.attack != "" {
+			r.status = []int{200, 400, 403, 404, 500}[b.weightedIndex([]float64{30, 15, 35, 15, 5})]
+		}
+	}
+	if r.status == 504 {
+		r.originTime = 60 + b.RandomFloat(0, 0.05)
+	}
+	r.bytesSent = b.responseSize(r.kind, r.method, r.uri, r.status)
+	r.bytesIn = int(b.RandomLogNormal(450, 0.4)) + len(r.uri) + len(r.userAgent)
+	if r.method == "POST" || r.method == "PUT" {
+		r.bytesIn += int(b.RandomLogNormal(1200, 1))
+	}
+	return r
+}
@@ -0,0 +1,243 @@
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// entitySeed fixes the entity pool so the same hosts and users exist on
+// every run
+const entitySeed = 20240601
+
+// EntityHost is a machine in the shared entity pool
+type EntityHost struct {
+	Hostname  string `json:"hostname"`
+	FQDN      string `json:"fqdn"`
+	IP        string `json:"ip"`
+	MAC       string `json:"mac"`
+	Platform  string `json:"platform"` // windows, linux or darwin
+	OSName    string `json:"os_name"`
+	OSVersion string `json:"os_version"`
+	UUID      string `json:"uuid"`
+	Role      string `json:"role"`  // workstation or server
+	Owner     string `json:"owner"` // primary user of a workstation
+}
+
+// EntityUser is a person in the shared entity pool
+type EntityUser struct {
+	Username   string `json:"username"`
+	FullName   string `json:"full_name"`
+	Email      string `json:"email"`
+	Department string `json:"department"`
+	UID        int    `json:"uid"`
+}
+
+// EntityPool is a fixed set of hosts and users shared by generators, so the
+// same machines and people appear across data sources
+type EntityPool struct {
+	seed      int64
+	userCount int
+	hostCount int
+
+	mu    sync.RWMutex
+	hosts []*EntityHost
+	users []*EntityUser
+}
+
+// Entities is the global entity pool
+var Entities = newEntityPool(entitySeed, 120, 80)
+
+func newEntityPool(seed int64, users, hosts int) *EntityPool {
+	p := &EntityPool{seed: seed, userCount: users, hostCount: hosts}
+	p.rebuild(Theme())
+	return p
+}
+
+// rebuild names the pool's hosts and users after the theme. The seed keeps
+// them the same machines and people; hosts and users picked earlier keep
+// their old names.
+func (p *EntityPool) rebuild(t models.Theme) {
+	users, hosts := buildEntities(p.seed, t, p.userCount, p.hostCount)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.users, p.hosts = users, hosts
+}
+
+func buildEntities(seed int64, t models.Theme, userCount, hostCount int) ([]*EntityUser, []*EntityHost) {
+	r := rand.New(rand.NewSource(seed))
+	var users []*EntityUser
+	var hosts []*EntityHost
+	prefix := ""
+	if t.Prefix != "" {
+		prefix = t.Prefix + "-"
+	}
+
+	first := []string{"james", "mary", "robert", "patricia", "john", "jennifer", "michael", "linda", "david", "elizabeth", "wei", "priya", "carlos", "fatima", "kenji", "olga", "ahmed", "sofia", "liam", "aisha"}
+	last := []string{"smith", "johnson", "williams", "brown", "jones", "garcia", "miller", "davis", "rodriguez", "martinez", "chen", "patel", "nguyen", "kim", "silva", "kowalski", "haddad", "okafor", "tanaka", "muller"}
+	departments := []string{"Engineering", "Finance", "Sales", "Marketing", "HR", "IT", "Legal", "Operations"}
+
+	seen := make(map[string]bool)
+	for len(users) < userCount {
+		f, l := first[r.Intn(len(first))], last[r.Intn(len(last))]
+		username := f + "." + l
+		if seen[username] {
+			username = fmt.Sprintf("%s.%s%d", f, l, r.Intn(90)+10)
+			if seen[username] {
+				continue
+			}
+		}
+		seen[username] = true
+		users = append(users, &EntityUser{
+			Username:   username,
+			FullName:   capitalize(f) + " " + capitalize(l),
+			Email:      username + "@" + t.Domain,
+			Department: departments[r.Intn(len(departments))],
+			UID:        1000 + len(users),
+		})
+	}
+
+	platforms := []struct {
+		platform, name string
+		versions       []string
+	}{
+		{"windows", "Microsoft Windows 11 Enterprise", []string{"10.0.22631", "10.0.22621"}},
+		{"windows", "Microsoft Windows Server 2022 Datacenter", []string{"10.0.20348"}},
+		{"darwin", "macOS", []string{"14.4.1", "14.5", "13.6.6"}},
+		{"linux", "Ubuntu", []string{"22.04.4 LTS", "20.04.6 LTS"}},
+		{"linux", "Red Hat Enterprise Linux", []string{"9.3", "8.9"}},
+	}
+
+	for i := 0; i < hostCount; i++ {
+		host := &EntityHost{
+			IP:   fmt.Sprintf("10.%d.%d.%d", 10+r.Intn(20), r.Intn(256), 10+r.Intn(240)),
+			MAC:  fmt.Sprintf("00:50:56:%02x:%02x:%02x", r.Intn(256), r.Intn(256), r.Intn(256)),
+			UUID: fmt.Sprintf("%08X-%04X-%04X-%04X-%012X", r.Uint32(), r.Intn(1<<16), 0x4000|r.Intn(1<<12), 0x8000|r.Intn(1<<14), r.Int63n(1<<48)),
+		}
+
+		var os int
+		if i%3 == 0 {
+			host.Role = "server"
+			os = []int{1, 3, 3, 4}[r.Intn(4)]
+			role := []string{"web", "app", "db", "file", "build"}[r.Intn(5)]
+			host.Hostname = fmt.Sprintf("%s%s-%02d", prefix, role, i/3+1)
+		} else {
+			host.Role = "workstation"
+			os = []int{0, 0, 0, 2, 2, 3}[r.Intn(6)]
+			owner := users[r.Intn(len(users))]
+			host.Owner = owner.Username
+			kind := map[string]string{"windows": "WS", "darwin": "MBP", "linux": "LX"}[platforms[os].platform]
+			host.Hostname = fmt.Sprintf("%s%s-%04d", strings.ToUpper(prefix), kind, 1000+i)
+		}
+
+		host.Platform = platforms[os].platform
+		host.OSName = platforms[os].name
+		host.OSVersion = platforms[os].versions[r.Intn(len(platforms[os].versions))]
+		host.FQDN = strings.ToLower(host.Hostname) + "." + t.ADDomain
+		hosts = append(hosts, host)
+	}
+
+	return users, hosts
+}
+
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// Hosts returns the hosts of the given platforms, or all hosts
+func (p *EntityPool) Hosts(platforms ...string) []*EntityHost {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(platforms) == 0 {
+		return p.hosts
+	}
+	var hosts []*EntityHost
+	for _, h := range p.hosts {
+		for _, platform := range platforms {
+			if h.Platform == platform {
+				hosts = append(hosts, h)
+				break
+			}
+		}
+	}
+	return hosts
+}
+
+// Users returns all users in the pool
+func (p *EntityPool) Users() []*EntityUser {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.users
+}
+
+// RandomHost picks a host of one of the given platforms, or of any platform
+func (p *EntityPool) RandomHost(rnd *RandomStream, platforms ...string) *EntityHost {
+	hosts := p.Hosts(platforms...)
+	return hosts[rnd.intn(len(hosts))]
+}
+
+// RandomUser picks a user from the pool
+func (p *EntityPool) RandomUser(rnd *RandomStream) *EntityUser {
+	users := p.Users()
+	return users[rnd.intn(len(users))]
+}
+
+// RandomUserIn picks a user of a department, or any user when the
+// department has none
+func (p *EntityPool) RandomUserIn(rnd *RandomStream, department string) *EntityUser {
+	var users []*EntityUser
+	for _, u := range p.Users() {
+		if u.Department == department {
+			users = append(users, u)
+		}
+	}
+	if len(users) == 0 {
+		return p.RandomUser(rnd)
+	}
+	return users[rnd.intn(len(users))]
+}
+
+// RandomServer picks a server, or any host when the pool has no servers
+func (p *EntityPool) RandomServer(rnd *RandomStream) *EntityHost {
+	var servers []*EntityHost
+	for _, h := range p.Hosts() {
+		if h.Role == "server" {
+			servers = append(servers, h)
+		}
+	}
+	if len(servers) == 0 {
+		return p.RandomHost(rnd)
+	}
+	return servers[rnd.intn(len(servers))]
+}
+
+// UserByName returns the pool user with the given username
+func (p *EntityPool) UserByName(username string) (*EntityUser, bool) {
+	for _, u := range p.Users() {
+		if u.Username == username {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// HostForUser returns the workstation owned by username, or a random
+// workstation when the user owns none
+func (p *EntityPool) HostForUser(rnd *RandomStream, username string) *EntityHost {
+	hosts := p.Hosts()
+	for _, h := range hosts {
+		if h.Owner == username {
+			return h
+		}
+	}
+	var workstations []*EntityHost
+	for _, h := range hosts {
+		if h.Role == "workstation" {
+			workstations = append(workstations, h)
+		}
+	}
+	return workstations[rnd.intn(len(workstations))]
+}
@@ -0,0 +1,131 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// facilitySite is an office building. Each office time zone of the
+// behavioral profiles has one, so users badge in and print at the office
+// their profile puts them in.
+type facilitySite struct {
+	code     string
+	city     string
+	timezone string
+	subnet   int // Second octet of the site's 10.x building network
+}
+
+// facilityDoor is a door with a badge reader and a camera watching it
+type facilityDoor struct {
+	name       string
+	floor      int
+	restricted bool // Only IT staff may badge in
+}
+
+var facilitySites = []facilitySite{
+	{"NYC", "New York", "America/New_York", 201},
+	{"CHI", "Chicago", "America/Chicago", 202},
+	{"LAX", "Los Angeles", "America/Los_Angeles", 203},
+	{"LON", "London", "Europe/London", 211},
+	{"BER", "Berlin", "Europe/Berlin", 212},
+	{"BLR", "Bengaluru", "Asia/Kolkata", 221},
+	{"TYO", "Tokyo", "Asia/Tokyo", 222},
+}
+
+var facilityDoors = []facilityDoor{
+	{"Main Entrance", 1, false},
+	{"Lobby Turnstile 1", 1, false},
+	{"Lobby Turnstile 2", 1, false},
+	{"Parking Garage", 0, false},
+	{"Loading Dock", 0, false},
+	{"Stairwell B", 3, false},
+	{"Server Room", 2, true},
+	{"IT Storage", 2, true},
+	{"Executive Suite", 4, true},
+}
+
+// local returns t in the site's time zone
+func (s facilitySite) local(t time.Time) time.Time {
+	loc, err := time.LoadLocation(s.timezone)
+	if err != nil {
+		return t.UTC()
+	}
+	return t.In(loc)
+}
+
+// reader is the name of the badge reader on door d at site s
+func (s facilitySite) reader(d int) string {
+	return fmt.Sprintf("%s-%dF-RDR-%02d", s.code, facilityDoors[d].floor, d+1)
+}
+
+// camera is the name of the camera watching door d at site s
+func (s facilitySite) camera(d int) string {
+	return fmt.Sprintf("%s-%dF-CAM-%02d", s.code, facilityDoors[d].floor, d+1)
+}
+
+// cameraIP is the address of the camera watching door d at site s
+func (s facilitySite) cameraIP(d int) string {
+	return fmt.Sprintf("10.%d.50.%d", s.subnet, 11+d)
+}
+
+// printer is the name and address of printer n on a floor of site s
+func (s facilitySite) printer(floor, n int) (string, string) {
+	return fmt.Sprintf("%s-%dF-MFP-%02d", s.code, floor, n), fmt.Sprintf("10.%d.%d.%d", s.subnet, 60+floor, 20+n)
+}
+
+// server names a site server after the theme's prefix, e.g. acme-print-nyc
+func (s facilitySite) server(role string) string {
+	name := fmt.Sprintf("%s-%s", role, strings.ToLower(s.code))
+	if prefix := Theme().Prefix; prefix != "" {
+		name = prefix + "-" + name
+	}
+	return name
+}
+
+// facilityProfiles caches the behavioral profiles of the pool users, which
+// say the office each works at. They are rebuilt when the pool is.
+var facilityProfiles struct {
+	sync.Mutex
+	users    []*EntityUser
+	profiles []*behaviorProfile
+}
+
+// occupants returns the profiles of the pool users
+func occupants() []*behaviorProfile {
+	users := Entities.Users()
+	facilityProfiles.Lock()
+	defer facilityProfiles.Unlock()
+	if len(facilityProfiles.users) == 0 || &facilityProfiles.users[0] != &users[0] {
+		facilityProfiles.users = users
+		facilityProfiles.profiles = buildProfiles()
+	}
+	return facilityProfiles.profiles
+}
+
+// siteOf returns the site of the office p works at
+func siteOf(p *behaviorProfile) facilitySite {
+	for _, s := range facilitySites {
+		if s.timezone == p.Timezone {
+			return s
+		}
+	}
+	return facilitySites[0]
+}
+
+// randomSite picks a site
+func randomSite(rnd *RandomStream) facilitySite {
+	return facilitySites[rnd.intn(len(facilitySites))]
+}
+
+// randomOccupant picks a user who works at t when onShift is true, or who
+// is off shift when false. When a few picks find none, any user will do.
+func randomOccupant(rnd *RandomStream, t time.Time, onShift bool) *behaviorProfile {
+	profiles := occupants()
+	p := profiles[rnd.intn(len(profiles))]
+	for i := 0; i < 20 && p.onShift(t) != onShift; i++ {
+		p = profiles[rnd.intn(len(profiles))]
+	}
+	return p
+}
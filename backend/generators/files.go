@@ -0,0 +1,249 @@
+package generators
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"path"
+	"sort"
+	"strings"
+
+	"siem-event-generator/models"
+)
+
+// File kinds in the catalog
+const (
+	FileExecutable = "executable"
+	FileLibrary    = "library"
+	FileScript     = "script"
+	FileDocument   = "document"
+	FileArchive    = "archive"
+	FileImage      = "image"
+)
+
+// CatalogFile is a file in the synthetic file catalog. Its hashes are the
+// real MD5, SHA-1 and SHA-256 digests of the same synthetic content, so they
+// pass format checks and agree wherever the file appears.
+type CatalogFile struct {
+	Name      string   `json:"name"`
+	Path      string   `json:"path"`
+	Platform  string   `json:"platform"` // windows or linux
+	Kind      string   `json:"kind"`
+	Magic     string   `json:"magic"` // libmagic description, as Suricata and Zeek report it
+	MIMEType  string   `json:"mime_type"`
+	Size      int      `json:"size"`
+	Malicious bool     `json:"malicious"`
+	Family    string   `json:"family,omitempty"` // Malware family or tool of a malicious file
+	Tags      []string `json:"tags,omitempty"`
+	MD5       string   `json:"md5"`
+	SHA1      string   `json:"sha1"`
+	SHA256    string   `json:"sha256"`
+}
+
+// fileKinds describes the content of each kind of file
+var fileKinds = map[string]struct{ magic, mime string }{
+	FileExecutable: {"PE32+ executable (GUI) x86-64, for MS Windows", "application/x-dosexec"},
+	FileLibrary:    {"PE32+ executable (DLL) (GUI) x86-64, for MS Windows", "application/x-dosexec"},
+	FileScript:     {"ASCII text, with CRLF line terminators", "text/plain"},
+	FileDocument:   {"PDF document, version 1.7", "application/pdf"},
+	FileArchive:    {"Zip archive data, at least v2.0 to extract", "application/zip"},
+	FileImage:      {"JPEG image data, JFIF standard 1.01", "image/jpeg"},
+}
+
+// catalogFiles are the files generators name. Anything else gets hashes
+// derived from its name by FileCatalog.ForName.
+var catalogFiles = []CatalogFile{
+	{Name: "explorer.exe", Path: `C:\Windows\explorer.exe`, Platform: "windows", Kind: FileExecutable, Size: 5217304},
+	{Name: "svchost.exe", Path: `C:\Windows\System32\svchost.exe`, Platform: "windows", Kind: FileExecutable, Size: 57360},
+	{Name: "services.exe", Path: `C:\Windows\System32\services.exe`, Platform: "windows", Kind: FileExecutable, Size: 724480},
+	{Name: "lsass.exe", Path: `C:\Windows\System32\lsass.exe`, Platform: "windows", Kind: FileExecutable, Size: 59456},
+	{Name: "winlogon.exe", Path: `C:\Windows\System32\winlogon.exe`, Platform: "windows", Kind: FileExecutable, Size: 906240},
+	{Name: "csrss.exe", Path: `C:\Windows\System32\csrss.exe`, Platform: "windows", Kind: FileExecutable, Size: 17248},
+	{Name: "dwm.exe", Path: `C:\Windows\System32\dwm.exe`, Platform: "windows", Kind: FileExecutable, Size: 94208},
+	{Name: "cmd.exe", Path: `C:\Windows\System32\cmd.exe`, Platform: "windows", Kind: FileExecutable, Size: 289792},
+	{Name: "powershell.exe", Path: `C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`, Platform: "windows", Kind: FileExecutable, Size: 448000},
+	{Name: "notepad.exe", Path: `C:\Windows\System32\notepad.exe`, Platform: "windows", Kind: FileExecutable, Size: 201216},
+	{Name: "taskhostw.exe", Path: `C:\Windows\System32\taskhostw.exe`, Platform: "windows", Kind: FileExecutable, Size: 99656},
+	{Name: "RuntimeBroker.exe", Path: `C:\Windows\System32\RuntimeBroker.exe`, Platform: "windows", Kind: FileExecutable, Size: 136472},
+	{Name: "SearchUI.exe", Path: `C:\Windows\SystemApps\Microsoft.Windows.Cortana_cw5n1h2txyewy\SearchUI.exe`, Platform: "windows", Kind: FileExecutable, Size: 1043808},
+	{Name: "rundll32.exe", Path: `C:\Windows\System32\rundll32.exe`, Platform: "windows", Kind: FileExecutable, Size: 71680},
+	{Name: "chrome.exe", Path: `C:\Program Files\Google\Chrome\Application\chrome.exe`, Platform: "windows", Kind: FileExecutable, Size: 2874136},
+	{Name: "firefox.exe", Path: `C:\Program Files\Mozilla Firefox\firefox.exe`, Platform: "windows", Kind: FileExecutable, Size: 675232},
+	{Name: "msedge.exe", Path: `C:\Program Files (x86)\Microsoft\Edge\Application\msedge.exe`, Platform: "windows", Kind: FileExecutable, Size: 4011928},
+	{Name: "OUTLOOK.EXE", Path: `C:\Program Files\Microsoft Office\root\Office16\OUTLOOK.EXE`, Platform: "windows", Kind: FileExecutable, Size: 42193808},
+	{Name: "WINWORD.EXE", Path: `C:\Program Files\Microsoft Office\root\Office16\WINWORD.EXE`, Platform: "windows", Kind: FileExecutable, Size: 1637224},
+	{Name: "EXCEL.EXE", Path: `C:\Program Files\Microsoft Office\root\Office16\EXCEL.EXE`, Platform: "windows", Kind: FileExecutable, Size: 65547128},
+	{Name: "kernel32.dll", Path: `C:\Windows\System32\kernel32.dll`, Platform: "windows", Kind: FileLibrary, Size: 770952},
+	{Name: "ntdll.dll", Path: `C:\Windows\System32\ntdll.dll`, Platform: "windows", Kind: FileLibrary, Size: 2125616},
+	{Name: "advapi32.dll", Path: `C:\Windows\System32\advapi32.dll`, Platform: "windows", Kind: FileLibrary, Size: 714280},
+	{Name: "user32.dll", Path: `C:\Windows\System32\user32.dll`, Platform: "windows", Kind: FileLibrary, Size: 1817920},
+	{Name: "ws2_32.dll", Path: `C:\Windows\System32\ws2_32.dll`, Platform: "windows", Kind: FileLibrary, Size: 448784},
+	{Name: "bash", Path: "/usr/bin/bash", Platform: "linux", Kind: FileExecutable, Size: 1396520},
+	{Name: "python3", Path: "/usr/bin/python3", Platform: "linux", Kind: FileExecutable, Size: 5905480},
+	{Name: "sshd", Path: "/usr/sbin/sshd", Platform: "linux", Kind: FileExecutable, Size: 921480},
+	{Name: "nginx", Path: "/usr/sbin/nginx", Platform: "linux", Kind: FileExecutable, Size: 1233416},
+	{Name: "curl", Path: "/usr/bin/curl", Platform: "linux", Kind: FileExecutable, Size: 260328},
+	{Name: "Q3_Financial_Report.pdf", Path: `C:\Users\Public\Documents\Q3_Financial_Report.pdf`, Platform: "windows", Kind: FileDocument, Size: 482133},
+	{Name: "Employee_Handbook_2024.pdf", Path: `C:\Users\Public\Documents\Employee_Handbook_2024.pdf`, Platform: "windows", Kind: FileDocument, Size: 1289470},
+	{Name: "budget_forecast.xlsx", Path: `C:\Users\Public\Documents\budget_forecast.xlsx`, Platform: "windows", Kind: FileDocument, Magic: "Microsoft Excel 2007+", MIMEType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Size: 88412},
+	{Name: "meeting_notes.docx", Path: `C:\Users\Public\Documents\meeting_notes.docx`, Platform: "windows", Kind: FileDocument, Magic: "Microsoft Word 2007+", MIMEType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Size: 24870},
+	{Name: "project_assets.zip", Path: `C:\Users\Public\Downloads\project_assets.zip`, Platform: "windows", Kind: FileArchive, Size: 15837204},
+	{Name: "team_photo.jpg", Path: `C:\Users\Public\Pictures\team_photo.jpg`, Platform: "windows", Kind: FileImage, Size: 2318841},
+	{Name: "ChromeSetup.exe", Path: `C:\Users\Public\Downloads\ChromeSetup.exe`, Platform: "windows", Kind: FileExecutable, Magic: "PE32 executable (GUI) Intel 80386, for MS Windows", Size: 1412456},
+	{Name: "mimikatz.exe", Path: `C:\Users\Public\Downloads\mimikatz.exe`, Platform: "windows", Kind: FileExecutable, Size: 1355264, Malicious: true, Family: "Mimikatz", Tags: []string{"hacktool", "credential-theft"}},
+	{Name: "beacon.dll", Path: `C:\Windows\Temp\beacon.dll`, Platform: "windows", Kind: FileLibrary, Size: 265728, Malicious: true, Family: "CobaltStrike", Tags: []string{"c2", "beacon"}},
+	{Name: "invoice_8841.exe", Path: `C:\Users\Public\AppData\Local\Temp\invoice_8841.exe`, Platform: "windows", Kind: FileExecutable, Size: 421888, Malicious: true, Family: "Emotet", Tags: []string{"trojan", "loader"}},
+	{Name: "PO_20931.doc", Path: `C:\Users\Public\Documents\PO_20931.doc`, Platform: "windows", Kind: FileDocument, Magic: "Composite Document File V2 Document", MIMEType: "application/msword", Size: 178688, Malicious: true, Family: "CVE-2017-11882", Tags: []string{"exploit", "maldoc"}},
+	{Name: "payment_advice.exe", Path: `C:\Users\Public\Downloads\payment_advice.exe`, Platform: "windows", Kind: FileExecutable, Size: 886272, Malicious: true, Family: "AgentTesla", Tags: []string{"infostealer"}},
+	{Name: "tasksche.exe", Path: `C:\ProgramData\tasksche.exe`, Platform: "windows", Kind: FileExecutable, Size: 3514368, Malicious: true, Family: "WannaCry", Tags: []string{"ransomware"}},
+	{Name: "Invoke-Mimikatz.ps1", Path: `C:\Windows\Temp\Invoke-Mimikatz.ps1`, Platform: "windows", Kind: FileScript, Size: 2461760, Malicious: true, Family: "PowerSploit", Tags: []string{"hacktool", "credential-theft"}},
+	{Name: "shipping_docs.zip", Path: `C:\Users\Public\Downloads\shipping_docs.zip`, Platform: "windows", Kind: FileArchive, Size: 318422, Malicious: true, Family: "Qakbot", Tags: []string{"loader", "phishing"}},
+	{Name: "xmrig", Path: "/tmp/.X11-unix/xmrig", Platform: "linux", Kind: FileExecutable, Magic: "ELF 64-bit LSB executable, x86-64, statically linked, stripped", MIMEType: "application/x-executable", Size: 8306952, Malicious: true, Family: "XMRig", Tags: []string{"coinminer"}},
+	{Name: "bins.sh", Path: "/tmp/bins.sh", Platform: "linux", Kind: FileScript, Magic: "POSIX shell script, ASCII text executable", MIMEType: "text/x-shellscript", Size: 1874, Malicious: true, Family: "Mirai", Tags: []string{"botnet", "dropper"}},
+	{Name: "kinsing", Path: "/tmp/kinsing", Platform: "linux", Kind: FileExecutable, Magic: "ELF 64-bit LSB executable, x86-64, statically linked, stripped", MIMEType: "application/x-executable", Size: 17387520, Malicious: true, Family: "Kinsing", Tags: []string{"coinminer", "botnet"}},
+}
+
+// Dir returns the directory the file is in
+func (f CatalogFile) Dir() string {
+	if i := strings.LastIndexAny(f.Path, `\/`); i > 0 {
+		return f.Path[:i]
+	}
+	return f.Path
+}
+
+// FileCatalog is the set of files generators report, hashed once
+type FileCatalog struct {
+	files  []CatalogFile
+	byName map[string]int
+}
+
+// Files is the global file catalog
+var Files = newFileCatalog(catalogFiles)
+
+func newFileCatalog(files []CatalogFile) *FileCatalog {
+	c := &FileCatalog{byName: make(map[string]int)}
+	for _, f := range files {
+		fillFile(&f)
+		c.byName[strings.ToLower(f.Name)] = len(c.files)
+		c.files = append(c.files, f)
+	}
+	return c
+}
+
+// fillFile fills in a file's content description and its hashes, which are
+// digests of a synthetic content string naming the file
+func fillFile(f *CatalogFile) {
+	kind := fileKinds[f.Kind]
+	if f.Magic == "" {
+		f.Magic = kind.magic
+	}
+	if f.MIMEType == "" {
+		f.MIMEType = kind.mime
+	}
+	content := []byte("make-some-noise synthetic file\x00" + strings.ToLower(f.Name) + "\x00" + f.Family)
+	md5Sum, sha1Sum, sha256Sum := md5.Sum(content), sha1.Sum(content), sha256.Sum256(content)
+	f.MD5 = hex.EncodeToString(md5Sum[:])
+	f.SHA1 = hex.EncodeToString(sha1Sum[:])
+	f.SHA256 = hex.EncodeToString(sha256Sum[:])
+	if f.Size == 0 {
+		f.Size = 4096 + int(binary.BigEndian.Uint32(sha256Sum[:4])%(4<<20))
+	}
+}
+
+// List returns the catalog's files, narrowed to malicious or benign ones
+// when malicious is set, ordered by name
+func (c *FileCatalog) List(malicious *bool) []CatalogFile {
+	files := make([]CatalogFile, 0, len(c.files))
+	for _, f := range c.files {
+		if malicious == nil || f.Malicious == *malicious {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name) })
+	return files
+}
+
+// Lookup returns the catalog file with a name, ignoring case
+func (c *FileCatalog) Lookup(name string) (CatalogFile, bool) {
+	i, ok := c.byName[strings.ToLower(name)]
+	if !ok {
+		return CatalogFile{}, false
+	}
+	return c.files[i], true
+}
+
+// ForName returns the catalog file with the base name of name, or a
+// benign file whose hashes are derived from the name, so a binary has the
+// same hashes in every source that reports it
+func (c *FileCatalog) ForName(name string) CatalogFile {
+	base := name
+	if i := strings.LastIndexAny(base, `\/`); i >= 0 {
+		base = base[i+1:]
+	}
+	if f, ok := c.Lookup(base); ok {
+		return f
+	}
+	f := CatalogFile{Name: base, Path: name, Platform: "windows", Kind: FileExecutable}
+	if !strings.Contains(name, `\`) {
+		f.Platform = "linux"
+	}
+	switch strings.ToLower(path.Ext(base)) {
+	case ".dll", ".sys":
+		f.Kind = FileLibrary
+	case ".ps1", ".bat", ".vbs", ".js", ".sh", ".py":
+		f.Kind = FileScript
+	case ".pdf", ".doc", ".docx", ".docm", ".xls", ".xlsx", ".pptx", ".txt":
+		f.Kind = FileDocument
+	case ".zip", ".rar", ".7z", ".gz", ".iso":
+		f.Kind = FileArchive
+	case ".jpg", ".jpeg", ".png", ".gif":
+		f.Kind = FileImage
+	}
+	fillFile(&f)
+	return f
+}
+
+// Random picks a malicious or benign file of one of the given kinds, or of
+// any kind
+func (c *FileCatalog) Random(rnd *RandomStream, malicious bool, kinds ...string) CatalogFile {
+	var candidates []int
+	for i, f := range c.files {
+		if f.Malicious != malicious {
+			continue
+		}
+		if len(kinds) > 0 && !hasAnyTag([]string{f.Kind}, kinds) {
+			continue
+		}
+		candidates = append(candidates, i)
+	}
+	if len(candidates) == 0 {
+		return c.Random(rnd, malicious)
+	}
+	return c.files[candidates[rnd.intn(len(candidates))]]
+}
+
+// RandomFile picks a benign file of one of the given kinds from the file
+// catalog
+func (b *BaseGenerator) RandomFile(kinds ...string) CatalogFile {
+	return Files.Random(b.rnd, false, kinds...)
+}
+
+// RandomMaliciousFile picks a malicious file of one of the given kinds from
+// the file catalog
+func (b *BaseGenerator) RandomMaliciousFile(kinds ...string) CatalogFile {
+	return Files.Random(b.rnd, true, kinds...)
+}
+
+// FileHash returns one of a file's hashes, or an indicator of that type at
+// the IOC rate
+func (b *BaseGenerator) FileHash(f CatalogFile, t models.IOCType) string {
+	switch t {
+	case models.IOCTypeMD5:
+		return b.InjectIOC(t, f.MD5)
+	case models.IOCTypeSHA1:
+		return b.InjectIOC(t, f.SHA1)
+	}
+	return b.InjectIOC(models.IOCTypeSHA256, f.SHA256)
+}
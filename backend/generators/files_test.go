@@ -0,0 +1,47 @@
+package generators
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var hexPattern = regexp.MustCompile(`^[0-9a-f]+$`)
+
+func isHex(s string, n int) bool {
+	return len(s) == n && hexPattern.MatchString(s)
+}
+
+func TestCatalogHashesAreValidDigests(t *testing.T) {
+	for _, f := range Files.List(nil) {
+		if !isHex(f.MD5, 32) || !isHex(f.SHA1, 40) || !isHex(f.SHA256, 64) {
+			t.Errorf("%s: malformed hashes %q %q %q", f.Name, f.MD5, f.SHA1, f.SHA256)
+		}
+		sum := md5.Sum([]byte("make-some-noise synthetic file\x00" + strings.ToLower(f.Name) + "\x00" + f.Family))
+		if f.MD5 != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s: MD5 is not the digest of the file's content", f.Name)
+		}
+	}
+}
+
+func TestGuardDutyDetectorIDIsHex(t *testing.T) {
+	g, ok := GetGenerator("aws_guardduty")
+	if !ok {
+		t.Fatal("aws_guardduty generator not registered")
+	}
+	tmpl := g.GetTemplates()[0]
+	event, err := g.Generate(tmpl.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := FlattenFields(event.Fields)
+	id, _ := fields["service.detectorId"].(string)
+	if !isHex(id, 32) {
+		t.Fatalf("detectorId %q is not 32 hex characters", id)
+	}
+	if arn, _ := fields["arn"].(string); !strings.Contains(arn, ":detector/"+id+"/") {
+		t.Errorf("arn %q does not name detector %s", arn, id)
+	}
+}
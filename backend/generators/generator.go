@@ -1,11 +1,12 @@
 package generators
 
 import (
-	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"math/big"
-	"net"
+	"io"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,54 +14,114 @@ import (
 	"siem-event-generator/models"
 )
 
-// Generator interface for all event generators
+// Generator interface for all event generators. Plugins implement it as of
+// GeneratorAPIVersion.
 type Generator interface {
 	GetEventType() models.EventType
 	GetTemplates() []models.EventTemplate
 	Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error)
 }
 
-// Registry holds all registered generators
-var Registry = make(map[string]Generator)
+// GeneratorAPIVersion is the version of the Generator interface and of the
+// plugin protocol. It changes only when either changes incompatibly, and
+// plugins built for another version are not loaded.
+const GeneratorAPIVersion = 1
 
-// Register adds a generator to the registry
+// registry holds all registered generators by event type ID
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Generator)
+)
+
+// Register adds a generator to the registry. Its templates' schema versions
+// come from the changelog.
 func Register(g Generator) {
-	Registry[g.GetEventType().ID] = g
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[g.GetEventType().ID] = versionedGenerator{Generator: g, initial: snapshot(g)}
+}
+
+// registerNew adds a generator unless its event type is already registered
+func registerNew(g Generator) error {
+	id := g.GetEventType().ID
+	if id == "" {
+		return fmt.Errorf("generator has no event type ID")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[id]; ok {
+		return fmt.Errorf("event type %s is already registered", id)
+	}
+	registry[id] = versionedGenerator{Generator: g, initial: snapshot(g)}
+	forgetDeclared(id)
+	return nil
+}
+
+// unregister removes the generator for an event type. Only plugins are
+// unregistered; registerNew keeps them from taking a built-in event type.
+func unregister(eventTypeID string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, eventTypeID)
+	forgetDeclared(eventTypeID)
 }
 
 // GetGenerator returns a generator by event type ID
 func GetGenerator(eventTypeID string) (Generator, bool) {
-	g, ok := Registry[eventTypeID]
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	g, ok := registry[eventTypeID]
 	return g, ok
 }
 
+// builtin returns a generator compiled into this package. Plugins cannot
+// take a registered event type, so these are never replaced.
+func builtin(eventTypeID string) Generator {
+	g, _ := GetGenerator(eventTypeID)
+	return unwrap(g)
+}
+
+// Generators returns every registered generator, ordered by event type ID
+func Generators() []Generator {
+	registryMu.RLock()
+	gens := make([]Generator, 0, len(registry))
+	for _, g := range registry {
+		gens = append(gens, g)
+	}
+	registryMu.RUnlock()
+	sort.Slice(gens, func(i, j int) bool { return gens[i].GetEventType().ID < gens[j].GetEventType().ID })
+	return gens
+}
+
 // GetAllEventTypes returns all registered event types
 func GetAllEventTypes() []models.EventType {
-	types := make([]models.EventType, 0, len(Registry))
-	for _, g := range Registry {
+	gens := Generators()
+	types := make([]models.EventType, 0, len(gens))
+	for _, g := range gens {
 		types = append(types, g.GetEventType())
 	}
 	return types
 }
 
 // BaseGenerator provides common functionality for generators
-type BaseGenerator struct{}
+type BaseGenerator struct {
+	rnd    *RandomStream // Source of random values; nil draws from crypto/rand
+	output string        // models.OutputFields skips rendering raw JSON events
+}
 
 // RandomString generates a random string of specified length
 func (b *BaseGenerator) RandomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	result := make([]byte, length)
 	for i := range result {
-		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		result[i] = charset[n.Int64()]
+		result[i] = charset[b.rnd.intn(len(charset))]
 	}
 	return string(result)
 }
 
 // RandomInt generates a random integer between min and max (inclusive)
 func (b *BaseGenerator) RandomInt(min, max int) int {
-	n, _ := rand.Int(rand.Reader, big.NewInt(int64(max-min+1)))
-	return int(n.Int64()) + min
+	return b.rnd.intn(max-min+1) + min
 }
 
 // RandomChoice selects a random item from a slice
@@ -68,8 +129,7 @@ func (b *BaseGenerator) RandomChoice(choices []string) string {
 	if len(choices) == 0 {
 		return ""
 	}
-	n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(choices))))
-	return choices[n.Int64()]
+	return choices[b.rnd.intn(len(choices))]
 }
 
 // RandomChoiceInterface selects a random item from a slice of interfaces
@@ -77,8 +137,7 @@ func (b *BaseGenerator) RandomChoiceInterface(choices []interface{}) interface{}
 	if len(choices) == 0 {
 		return nil
 	}
-	n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(choices))))
-	return choices[n.Int64()]
+	return choices[b.rnd.intn(len(choices))]
 }
 
 // RandomIPv4Internal generates a random internal IPv4 address
@@ -96,26 +155,44 @@ func (b *BaseGenerator) RandomIPv4Internal() string {
 	return "10.0.0.1"
 }
 
-// RandomIPv4External generates a random external IPv4 address
+// RandomIPv4External generates an external IPv4 address from one of the
+// geo policy's benign countries, or an IP indicator at the IOC rate
 func (b *BaseGenerator) RandomIPv4External() string {
-	for {
-		ip := net.IPv4(
-			byte(b.RandomInt(1, 223)),
-			byte(b.RandomInt(0, 255)),
-			byte(b.RandomInt(0, 255)),
-			byte(b.RandomInt(1, 254)),
-		)
-		// Exclude private and reserved ranges
-		if !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsMulticast() {
-			return ip.String()
+	if ip, ok := IOCs.Pick(b.rnd, models.IOCTypeIP); ok {
+		return ip
+	}
+	return Geo.RandomBenign(b.rnd).IP
+}
+
+// RandomGeoIP returns an external IP with its location, drawn from the given
+// countries or from any country in the GeoIP table
+func (b *BaseGenerator) RandomGeoIP(countries ...string) GeoLocation {
+	return Geo.Random(b.rnd, countries...)
+}
+
+// RandomBenignGeoIP returns an external IP with its location, drawn from the
+// geo policy's benign countries
+func (b *BaseGenerator) RandomBenignGeoIP() GeoLocation {
+	return Geo.RandomBenign(b.rnd)
+}
+
+// RandomMaliciousGeoIP returns an external IP with its location, drawn from
+// the geo policy's malicious countries or, at the IOC rate, from the IP
+// indicators. Indicators outside the GeoIP table carry no location.
+func (b *BaseGenerator) RandomMaliciousGeoIP() GeoLocation {
+	if ip, ok := IOCs.Pick(b.rnd, models.IOCTypeIP); ok {
+		if loc, found := Geo.Lookup(ip); found {
+			return loc
 		}
+		return GeoLocation{IP: ip}
 	}
+	return Geo.RandomMalicious(b.rnd)
 }
 
 // RandomMAC generates a random MAC address
 func (b *BaseGenerator) RandomMAC() string {
 	mac := make([]byte, 6)
-	rand.Read(mac)
+	io.ReadFull(b.rnd.reader(), mac)
 	mac[0] = (mac[0] | 2) & 0xfe // Set locally administered, unicast
 	return fmt.Sprintf("%02X:%02X:%02X:%02X:%02X:%02X",
 		mac[0], mac[1], mac[2], mac[3], mac[4], mac[5])
@@ -131,27 +208,27 @@ func (b *BaseGenerator) RandomPrivilegedPort() int {
 	return b.RandomInt(1, 1023)
 }
 
-// RandomUsername generates a random username
+// RandomUsername generates the sAMAccountName of a random person from the
+// name profile
 func (b *BaseGenerator) RandomUsername() string {
-	prefixes := []string{"user", "admin", "svc", "app", "sys"}
-	return fmt.Sprintf("%s_%s", b.RandomChoice(prefixes), b.RandomString(4))
+	return b.RandomPerson().SAMAccountName
 }
 
-// RandomHostname generates a random hostname
+// RandomHostname generates a random hostname with the theme's prefix
 func (b *BaseGenerator) RandomHostname() string {
 	prefixes := []string{"WS", "SRV", "DC", "WEB", "DB", "APP"}
-	return fmt.Sprintf("%s-%s", b.RandomChoice(prefixes), strings.ToUpper(b.RandomString(6)))
+	return strings.ToUpper(themedName(fmt.Sprintf("%s-%s", b.RandomChoice(prefixes), b.RandomString(6))))
 }
 
-// RandomDomain generates a random domain name
+// RandomDomain returns the theme's NetBIOS domain name
 func (b *BaseGenerator) RandomDomain() string {
-	domains := []string{"CORP", "CONTOSO", "ACME", "FABRIKAM", "NORTHWIND"}
-	return b.RandomChoice(domains)
+	return Theme().NetBIOS()
 }
 
-// RandomFQDN generates a random fully qualified domain name
+// RandomFQDN generates a random fully qualified hostname in the theme's AD
+// domain
 func (b *BaseGenerator) RandomFQDN() string {
-	return fmt.Sprintf("%s.%s.local", strings.ToLower(b.RandomHostname()), strings.ToLower(b.RandomDomain()))
+	return fmt.Sprintf("%s.%s", strings.ToLower(b.RandomHostname()), Theme().ADDomain)
 }
 
 // RandomProcessName generates a random process name
@@ -200,6 +277,36 @@ func (b *BaseGenerator) RandomGUID() string {
 	return uuid.New().String()
 }
 
+// RandomHex generates a random lowercase hex string of n bytes (2n characters)
+func (b *BaseGenerator) RandomHex(n int) string {
+	buf := make([]byte, n)
+	io.ReadFull(b.rnd.reader(), buf)
+	return hex.EncodeToString(buf)
+}
+
+// InjectIOC returns an indicator of type t at the IOC rate, otherwise fallback
+func (b *BaseGenerator) InjectIOC(t models.IOCType, fallback string) string {
+	if v, ok := IOCs.Pick(b.rnd, t); ok {
+		return v
+	}
+	return fallback
+}
+
+// RandomMD5 returns the MD5 of a catalog file, or an MD5 indicator at the IOC rate
+func (b *BaseGenerator) RandomMD5() string {
+	return b.FileHash(b.RandomFile(), models.IOCTypeMD5)
+}
+
+// RandomSHA1 returns the SHA-1 of a catalog file, or a SHA-1 indicator at the IOC rate
+func (b *BaseGenerator) RandomSHA1() string {
+	return b.FileHash(b.RandomFile(), models.IOCTypeSHA1)
+}
+
+// RandomSHA256 returns the SHA-256 of a catalog file, or a SHA-256 indicator at the IOC rate
+func (b *BaseGenerator) RandomSHA256() string {
+	return b.FileHash(b.RandomFile(), models.IOCTypeSHA256)
+}
+
 // RandomTimestamp generates a random timestamp within the last hour
 func (b *BaseGenerator) RandomTimestamp() time.Time {
 	seconds := b.RandomInt(0, 3600)
@@ -242,16 +349,24 @@ func (b *BaseGenerator) RandomCommonPort() int {
 	for k := range ports {
 		keys = append(keys, k)
 	}
+	// Map order varies between runs; sorting keeps seeded streams repeatable
+	sort.Strings(keys)
 	return ports[b.RandomChoice(keys)]
 }
 
-// ApplyOverrides applies override values to generated fields
+// ApplyOverrides applies override values to generated fields. Override values
+// declaring a {"$distribution": {...}} are sampled fresh for every event.
 func (b *BaseGenerator) ApplyOverrides(fields map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
 	for k, v := range fields {
 		result[k] = v
 	}
 	for k, v := range overrides {
+		if spec, ok, err := distributionFromOverride(v); ok && err == nil {
+			if sampled, err := b.SampleDistribution(spec); err == nil {
+				v = sampled
+			}
+		}
 		result[k] = v
 	}
 	return result
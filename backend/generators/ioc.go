@@ -0,0 +1,415 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// IOCPool holds user-supplied threat intel indicators. Generators swap an
+// indicator in for a random IP, domain or hash at the configured rate so
+// threat intel matching rules in the SIEM fire on the generated data.
+type IOCPool struct {
+	mu       sync.RWMutex
+	config   models.IOCConfig
+	byType   map[models.IOCType][]*models.IOC
+	index    map[string]*models.IOC
+	injected map[models.IOCType]*int64
+}
+
+// IOCs is the global indicator pool
+var IOCs = NewIOCPool()
+
+// NewIOCPool creates an empty pool with a 5% injection rate
+func NewIOCPool() *IOCPool {
+	p := &IOCPool{
+		config:   models.IOCConfig{Rate: 5},
+		byType:   make(map[models.IOCType][]*models.IOC),
+		index:    make(map[string]*models.IOC),
+		injected: make(map[models.IOCType]*int64),
+	}
+	for _, t := range models.IOCTypes {
+		p.injected[t] = new(int64)
+	}
+	return p
+}
+
+func iocKey(t models.IOCType, value string) string {
+	return string(t) + "|" + strings.ToLower(value)
+}
+
+// Config returns the injection settings
+func (p *IOCPool) Config() models.IOCConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config
+}
+
+// SetConfig replaces the injection settings
+func (p *IOCPool) SetConfig(cfg models.IOCConfig) error {
+	if cfg.Rate < 0 || cfg.Rate > 100 {
+		return fmt.Errorf("rate must be between 0 and 100")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = cfg
+	return nil
+}
+
+// Add inserts indicators, updating the source and tags of ones already in
+// the pool, and returns how many were new
+func (p *IOCPool) Add(iocs []models.IOC) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	added := 0
+	for i := range iocs {
+		ioc := iocs[i]
+		key := iocKey(ioc.Type, ioc.Value)
+		if existing, ok := p.index[key]; ok {
+			existing.Source = ioc.Source
+			existing.Tags = ioc.Tags
+			continue
+		}
+		if ioc.AddedAt.IsZero() {
+			ioc.AddedAt = time.Now()
+		}
+		p.index[key] = &ioc
+		p.byType[ioc.Type] = append(p.byType[ioc.Type], &ioc)
+		added++
+	}
+	return added
+}
+
+// Remove deletes every indicator from source, or every indicator when source
+// is empty, and returns how many were removed
+func (p *IOCPool) Remove(source string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	removed := 0
+	for t, list := range p.byType {
+		kept := list[:0]
+		for _, ioc := range list {
+			if source != "" && ioc.Source != source {
+				kept = append(kept, ioc)
+				continue
+			}
+			delete(p.index, iocKey(ioc.Type, ioc.Value))
+			removed++
+		}
+		p.byType[t] = kept
+	}
+	return removed
+}
+
+// Replace swaps the indicators from source for a fresh list, as when a feed
+// is polled again
+func (p *IOCPool) Replace(source string, iocs []models.IOC) {
+	p.Remove(source)
+	for i := range iocs {
+		iocs[i].Source = source
+	}
+	p.Add(iocs)
+}
+
+// List returns indicators filtered by type and source (empty matches all),
+// ordered by type then value
+func (p *IOCPool) List(t models.IOCType, source string) []models.IOC {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	iocs := make([]models.IOC, 0)
+	for _, list := range p.byType {
+		for _, ioc := range list {
+			if (t == "" || ioc.Type == t) && (source == "" || ioc.Source == source) {
+				iocs = append(iocs, *ioc)
+			}
+		}
+	}
+	sort.Slice(iocs, func(i, j int) bool {
+		if iocs[i].Type != iocs[j].Type {
+			return iocs[i].Type < iocs[j].Type
+		}
+		return iocs[i].Value < iocs[j].Value
+	})
+	return iocs
+}
+
+// Counts returns the number of indicators of each type
+func (p *IOCPool) Counts() map[models.IOCType]int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	counts := make(map[models.IOCType]int)
+	for _, t := range models.IOCTypes {
+		counts[t] = len(p.byType[t])
+	}
+	return counts
+}
+
+// Injected returns how many values of each type generators have replaced
+// with an indicator since startup
+func (p *IOCPool) Injected() map[models.IOCType]int64 {
+	injected := make(map[models.IOCType]int64)
+	for t, n := range p.injected {
+		injected[t] = atomic.LoadInt64(n)
+	}
+	return injected
+}
+
+// Pick returns an indicator of type t with probability rate%, so callers
+// can fall back to a random value when it reports false
+func (p *IOCPool) Pick(rnd *RandomStream, t models.IOCType) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	list := p.byType[t]
+	if len(list) == 0 || rnd.float64()*100 >= p.config.Rate {
+		return "", false
+	}
+	atomic.AddInt64(p.injected[t], 1)
+	return list[rnd.intn(len(list))].Value, true
+}
+
+// DetectIOCType infers an indicator's type from its value
+func DetectIOCType(value string) (models.IOCType, bool) {
+	if net.ParseIP(value) != nil {
+		return models.IOCTypeIP, true
+	}
+	if _, err := hex.DecodeString(value); err == nil {
+		switch len(value) {
+		case 32:
+			return models.IOCTypeMD5, true
+		case 40:
+			return models.IOCTypeSHA1, true
+		case 64:
+			return models.IOCTypeSHA256, true
+		}
+		return "", false
+	}
+	if strings.Contains(value, ".") && !strings.ContainsAny(value, " /:@") {
+		return models.IOCTypeDomain, true
+	}
+	return "", false
+}
+
+// normalizeIOC checks an indicator's value against its type, inferring the
+// type when it is missing
+func normalizeIOC(ioc *models.IOC) error {
+	ioc.Value = strings.TrimSpace(ioc.Value)
+	detected, ok := DetectIOCType(ioc.Value)
+	if !ok {
+		return fmt.Errorf("unrecognized indicator: %q", ioc.Value)
+	}
+	if ioc.Type == "" {
+		ioc.Type = detected
+	}
+	if ioc.Type != detected {
+		return fmt.Errorf("%q is not a valid %s indicator", ioc.Value, ioc.Type)
+	}
+	switch ioc.Type {
+	case models.IOCTypeDomain, models.IOCTypeMD5, models.IOCTypeSHA1, models.IOCTypeSHA256:
+		ioc.Value = strings.ToLower(strings.TrimSuffix(ioc.Value, "."))
+	}
+	return nil
+}
+
+// ValidateIOCs normalizes indicators added through the API
+func ValidateIOCs(iocs []models.IOC) error {
+	for i := range iocs {
+		if err := normalizeIOC(&iocs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseIOCs reads indicators in CSV or STIX 2.1 format. An empty format is
+// detected from the content: JSON is STIX, anything else is CSV.
+func ParseIOCs(data []byte, format string) ([]models.IOC, error) {
+	if format == "" {
+		format = models.IOCFormatCSV
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = models.IOCFormatSTIX
+		}
+	}
+	switch format {
+	case models.IOCFormatCSV:
+		return parseIOCCSV(data)
+	case models.IOCFormatSTIX, models.IOCFormatTAXII:
+		return parseSTIX(data)
+	default:
+		return nil, fmt.Errorf("unsupported indicator format: %s", format)
+	}
+}
+
+// parseIOCCSV accepts a header row with "value" (or "indicator"), optional
+// "type" and "tags" columns; headerless rows of type,value,tags...; or a plain
+// list with one indicator per line. Lines starting with # are comments.
+func parseIOCCSV(data []byte) ([]models.IOC, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	typeCol, valueCol, tagsCol := -1, -1, -1
+	var iocs []models.IOC
+	for row := 1; ; row++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv: %w", err)
+		}
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+
+		if row == 1 {
+			for i, h := range record {
+				switch strings.ToLower(strings.TrimSpace(h)) {
+				case "type", "indicator_type":
+					typeCol = i
+				case "value", "indicator", "ioc":
+					valueCol = i
+				case "tags", "labels":
+					tagsCol = i
+				}
+			}
+			if valueCol >= 0 {
+				continue
+			}
+		}
+
+		var ioc models.IOC
+		switch {
+		case valueCol >= 0:
+			if valueCol >= len(record) {
+				continue
+			}
+			ioc.Value = record[valueCol]
+			if typeCol >= 0 && typeCol < len(record) {
+				ioc.Type = models.IOCType(strings.ToLower(strings.TrimSpace(record[typeCol])))
+			}
+			if tagsCol >= 0 && tagsCol < len(record) {
+				ioc.Tags = splitTags(record[tagsCol])
+			}
+		case len(record) >= 2 && isIOCType(record[0]):
+			ioc.Type = models.IOCType(strings.ToLower(strings.TrimSpace(record[0])))
+			ioc.Value = record[1]
+			for _, t := range record[2:] {
+				ioc.Tags = append(ioc.Tags, splitTags(t)...)
+			}
+		default:
+			ioc.Value = record[0]
+		}
+		if err := normalizeIOC(&ioc); err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		iocs = append(iocs, ioc)
+	}
+	return iocs, nil
+}
+
+func isIOCType(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, t := range models.IOCTypes {
+		if string(t) == s {
+			return true
+		}
+	}
+	return false
+}
+
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '|' || r == ' ' })
+}
+
+// stixObject holds the fields read from STIX 2.1 indicators and cyber
+// observables
+type stixObject struct {
+	Type           string            `json:"type"`
+	Pattern        string            `json:"pattern"`
+	PatternType    string            `json:"pattern_type"`
+	Labels         []string          `json:"labels"`
+	IndicatorTypes []string          `json:"indicator_types"`
+	Revoked        bool              `json:"revoked"`
+	ValidUntil     *time.Time        `json:"valid_until"`
+	Value          string            `json:"value"`
+	Hashes         map[string]string `json:"hashes"`
+}
+
+// stixComparison matches the comparisons in a STIX pattern that carry an
+// indicator value, e.g. [file:hashes.'SHA-256' = '...']
+var stixComparison = regexp.MustCompile(`(ipv4-addr|ipv6-addr|domain-name|file):(value|hashes\.'?([A-Za-z0-9-]+)'?)\s*=\s*'([^']+)'`)
+
+// stixHashTypes maps STIX hash algorithm names to indicator types
+var stixHashTypes = map[string]models.IOCType{
+	"MD5":     models.IOCTypeMD5,
+	"SHA-1":   models.IOCTypeSHA1,
+	"SHA1":    models.IOCTypeSHA1,
+	"SHA-256": models.IOCTypeSHA256,
+	"SHA256":  models.IOCTypeSHA256,
+}
+
+// parseSTIX reads a STIX 2.1 bundle or a TAXII 2.1 envelope. Indicators are
+// read from their patterns; bare IP, domain and file observables are taken
+// as-is. Revoked and expired indicators are skipped.
+func parseSTIX(data []byte) ([]models.IOC, error) {
+	var doc struct {
+		Objects []stixObject `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("stix: %w", err)
+	}
+
+	var iocs []models.IOC
+	add := func(t models.IOCType, value string, tags []string) {
+		ioc := models.IOC{Type: t, Value: value, Tags: tags}
+		if normalizeIOC(&ioc) == nil {
+			iocs = append(iocs, ioc)
+		}
+	}
+	now := time.Now()
+	for _, obj := range doc.Objects {
+		switch obj.Type {
+		case "indicator":
+			if obj.Revoked || (obj.PatternType != "" && obj.PatternType != "stix") || (obj.ValidUntil != nil && obj.ValidUntil.Before(now)) {
+				continue
+			}
+			tags := append(append([]string{}, obj.IndicatorTypes...), obj.Labels...)
+			for _, m := range stixComparison.FindAllStringSubmatch(obj.Pattern, -1) {
+				switch m[1] {
+				case "ipv4-addr", "ipv6-addr":
+					add(models.IOCTypeIP, m[4], tags)
+				case "domain-name":
+					add(models.IOCTypeDomain, m[4], tags)
+				case "file":
+					if t, ok := stixHashTypes[strings.ToUpper(m[3])]; ok {
+						add(t, m[4], tags)
+					}
+				}
+			}
+		case "ipv4-addr", "ipv6-addr":
+			add(models.IOCTypeIP, obj.Value, nil)
+		case "domain-name":
+			add(models.IOCTypeDomain, obj.Value, nil)
+		case "file":
+			for alg, h := range obj.Hashes {
+				if t, ok := stixHashTypes[strings.ToUpper(alg)]; ok {
+					add(t, h, nil)
+				}
+			}
+		}
+	}
+	return iocs, nil
+}
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -118,7 +117,7 @@ func (g *KubernetesAuditGenerator) randomImage() string {
 	images := []string{
 		"nginx:1.25", "redis:7", "postgres:15", "python:3.11",
 		"node:20-alpine", "golang:1.21", "busybox:latest",
-		"custom-app:v1.2.3", "internal-registry.company.com/app:latest",
+		"custom-app:v1.2.3", publicHost("registry") + "/app:latest",
 	}
 	return g.RandomChoice(images)
 }
@@ -129,8 +128,8 @@ func (g *KubernetesAuditGenerator) randomUser() (string, []string) {
 		groups []string
 	}{
 		{"system:serviceaccount:default:default", []string{"system:serviceaccounts", "system:serviceaccounts:default", "system:authenticated"}},
-		{"admin@company.com", []string{"system:masters", "system:authenticated"}},
-		{"developer@company.com", []string{"developers", "system:authenticated"}},
+		{"admin@" + Theme().Domain, []string{"system:masters", "system:authenticated"}},
+		{"developer@" + Theme().Domain, []string{"developers", "system:authenticated"}},
 		{"system:kube-scheduler", []string{"system:authenticated"}},
 		{"system:kube-controller-manager", []string{"system:authenticated"}},
 	}
@@ -202,7 +201,7 @@ func (g *KubernetesAuditGenerator) generatePodCreate(overrides map[string]interf
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -223,7 +222,7 @@ func (g *KubernetesAuditGenerator) generatePodDelete(overrides map[string]interf
 	event["objectRef"].(map[string]interface{})["name"] = podName
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -247,7 +246,7 @@ func (g *KubernetesAuditGenerator) generateSecretAccess(overrides map[string]int
 	event["level"] = "RequestResponse"
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -275,7 +274,7 @@ func (g *KubernetesAuditGenerator) generateExecContainer(overrides map[string]in
 	event["level"] = "RequestResponse"
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -307,7 +306,7 @@ func (g *KubernetesAuditGenerator) generateConfigMapUpdate(overrides map[string]
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -335,7 +334,7 @@ func (g *KubernetesAuditGenerator) generateRBACChange(overrides map[string]inter
 	event["level"] = "RequestResponse"
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
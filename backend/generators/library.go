@@ -0,0 +1,195 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"siem-event-generator/models"
+)
+
+// Request describes a generation job for the library API
+type Request struct {
+	EventType  string
+	TemplateID string // Empty selects the generator's first template
+	Count      int    // Zero or negative generates until the context is cancelled
+	Overrides  map[string]interface{}
+	Workers    int    // Parallel generators; events arrive out of order when above 1
+	Output     string // models.OutputRaw or models.OutputFields; empty keeps both
+}
+
+// Result carries a generated event or the error that prevented it
+type Result struct {
+	Event *models.GeneratedEvent
+	Err   error
+}
+
+// EventTypes returns all registered event types sorted by ID
+func EventTypes() []models.EventType {
+	types := GetAllEventTypes()
+	sort.Slice(types, func(i, j int) bool { return types[i].ID < types[j].ID })
+	return types
+}
+
+// Templates returns the templates of a registered event type
+func Templates(eventType string) ([]models.EventTemplate, error) {
+	g, ok := GetGenerator(eventType)
+	if !ok {
+		return nil, fmt.Errorf("event type not found: %s", eventType)
+	}
+	return g.GetTemplates(), nil
+}
+
+// ResolveTemplateID returns templateID if the generator provides it, or the
+// generator's first template when templateID is empty
+func ResolveTemplateID(g Generator, templateID string) (string, error) {
+	templates := g.GetTemplates()
+	if templateID == "" {
+		if len(templates) == 0 {
+			return "", fmt.Errorf("event type %s has no templates", g.GetEventType().ID)
+		}
+		return templates[0].ID, nil
+	}
+	for _, t := range templates {
+		if t.ID == templateID {
+			return templateID, nil
+		}
+	}
+	return "", fmt.Errorf("template %s not found for event type %s", templateID, g.GetEventType().ID)
+}
+
+// ValidateOutput checks an event output name
+func ValidateOutput(output string) error {
+	switch output {
+	case models.OutputFull, models.OutputRaw, models.OutputFields:
+		return nil
+	default:
+		return fmt.Errorf("invalid output %q: use raw, fields or leave empty", output)
+	}
+}
+
+// ApplyOutput drops the form of event that output does not ask for
+func ApplyOutput(event *models.GeneratedEvent, output string) *models.GeneratedEvent {
+	switch output {
+	case models.OutputRaw:
+		event.Fields = nil
+	case models.OutputFields:
+		event.RawEvent = ""
+	}
+	return event
+}
+
+// Generate creates a single event for the given event type and template
+func Generate(eventType, templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	g, ok := GetGenerator(eventType)
+	if !ok {
+		return nil, fmt.Errorf("event type not found: %s", eventType)
+	}
+	tid, err := ResolveTemplateID(g, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return Unseeded.Generate(g, tid, overrides)
+}
+
+// Stream generates events on a channel until Count is reached or ctx is
+// cancelled. The channel is closed when generation ends; a generation error
+// is delivered as a final Result and stops the stream.
+func Stream(ctx context.Context, req Request) <-chan Result {
+	out := make(chan Result)
+
+	go func() {
+		defer close(out)
+
+		g, ok := GetGenerator(req.EventType)
+		if !ok {
+			sendResult(ctx, out, Result{Err: fmt.Errorf("event type not found: %s", req.EventType)})
+			return
+		}
+		tid, err := ResolveTemplateID(g, req.TemplateID)
+		if err == nil {
+			err = ValidateOutput(req.Output)
+		}
+		if err != nil {
+			sendResult(ctx, out, Result{Err: err})
+			return
+		}
+
+		workers := req.Workers
+		if workers < 1 {
+			workers = 1
+		}
+
+		// Sends are serialized so nothing follows an error on the channel
+		var mu sync.Mutex
+		stopped := false
+		emit := func(r Result) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if stopped {
+				return false
+			}
+			stopped = !sendResult(ctx, out, r) || r.Err != nil
+			return !stopped
+		}
+
+		// Workers claim event slots so exactly Count events are generated
+		var claimed int64
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for req.Count <= 0 || atomic.AddInt64(&claimed, 1) <= int64(req.Count) {
+					event, err := Unseeded.GenerateOutput(g, tid, req.Overrides, req.Output)
+					if !emit(Result{Event: event, Err: err}) {
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}()
+
+	return out
+}
+
+// sendResult delivers r unless ctx is cancelled first
+func sendResult(ctx context.Context, out chan<- Result, r Result) bool {
+	select {
+	case out <- r:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// WriteEvents streams the raw form of generated events to w, one per line,
+// and returns the number of events written
+func WriteEvents(ctx context.Context, w io.Writer, req Request) (int, error) {
+	req.Output = models.OutputRaw
+	ctx, cancel := context.WithCancel(ctx)
+	results := Stream(ctx, req)
+	// Stop and drain the stream on an early return, so its workers do not
+	// block forever on results nobody reads
+	defer func() {
+		cancel()
+		for range results {
+		}
+	}()
+
+	written := 0
+	for r := range results {
+		if r.Err != nil {
+			return written, r.Err
+		}
+		if _, err := io.WriteString(w, r.Event.RawEvent+"\n"); err != nil {
+			return written, fmt.Errorf("write event: %w", err)
+		}
+		written++
+	}
+	return written, ctx.Err()
+}
@@ -0,0 +1,32 @@
+package generators
+
+import (
+	"testing"
+
+	"siem-event-generator/models"
+)
+
+func TestGenerateOutputFieldsSkipsRawJSON(t *testing.T) {
+	g, _ := GetGenerator("metrics_webapi")
+	event, err := Unseeded.GenerateOutput(g, "latency", nil, models.OutputFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.RawEvent != "" || len(event.Fields) == 0 {
+		t.Errorf("fields output kept raw %q, fields %v", event.RawEvent, event.Fields)
+	}
+	if c := Unseeded.generator(g, models.OutputFields); c == g || c.(versionedGenerator).Generator.(seedable).base().output != models.OutputFields {
+		t.Error("fields output did not use a copy that skips rendering")
+	}
+	if Unseeded.generator(g, models.OutputRaw) != g {
+		t.Error("raw output did not use the registered generator")
+	}
+
+	event, err = Unseeded.GenerateOutput(g, "latency", nil, models.OutputRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.RawEvent == "" || event.RawEvent == "{}" || event.Fields != nil {
+		t.Errorf("raw output = raw %q, fields %v", event.RawEvent, event.Fields)
+	}
+}
@@ -0,0 +1,561 @@
+package generators
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// Lifecycle outcomes
+const (
+	LifecycleOffboard = "offboard"
+	LifecycleRogue    = "rogue"
+)
+
+// Lifecycle defaults
+const (
+	lifecycleDefaultWorkDays = 10
+	lifecycleMaxWorkDays     = 260
+)
+
+// New hire names are drawn from different lists than the entity pool, so a
+// default hire never collides with an existing account
+var (
+	newHireFirst = []string{"noah", "emma", "mateo", "chloe", "arjun", "hana", "lucas", "zara", "diego", "mei", "oscar", "leila"}
+	newHireLast  = []string{"fischer", "rossi", "dubois", "novak", "ibrahim", "larsen", "moreau", "costa", "ward", "sato", "reyes", "bauer"}
+)
+
+// Lifecycle is a resolved identity lifecycle request: one employee whose
+// username, SID, Okta ID and IAM user stay the same across AD, Windows, Okta
+// and CloudTrail events from onboarding to offboarding
+type Lifecycle struct {
+	User        *EntityUser
+	Admin       *EntityUser // IT administrator who provisions and removes the account
+	Workstation *EntityHost
+	Outcome     string
+	WorkDays    int
+	Start       time.Time
+	End         time.Time
+
+	days       []time.Time // onboarding day, work days, exit day and (offboard) the day after
+	netbios    string
+	domainSID  string
+	userSID    string
+	adminSID   string
+	groupSID   string
+	oktaID     string
+	adminOkta  string
+	accountID  string
+	region     string
+	iamID      string
+	adminIAMID string
+	office     GeoLocation // corporate egress address for SaaS and console logins
+	home       GeoLocation
+}
+
+// ResolveLifecycle fills in defaults and validates a lifecycle request
+func ResolveLifecycle(req *models.LifecycleRequest) (*Lifecycle, error) {
+	var b BaseGenerator
+
+	lc := &Lifecycle{
+		Outcome:  req.Outcome,
+		WorkDays: req.WorkDays,
+	}
+	if lc.Outcome == "" {
+		lc.Outcome = LifecycleOffboard
+	}
+	if lc.Outcome != LifecycleOffboard && lc.Outcome != LifecycleRogue {
+		return nil, fmt.Errorf("outcome must be %q or %q", LifecycleOffboard, LifecycleRogue)
+	}
+	if lc.WorkDays <= 0 {
+		lc.WorkDays = lifecycleDefaultWorkDays
+	}
+	if lc.WorkDays > lifecycleMaxWorkDays {
+		return nil, fmt.Errorf("work_days must be <= %d", lifecycleMaxWorkDays)
+	}
+
+	user, err := resolveLifecycleUser(&b, req)
+	if err != nil {
+		return nil, err
+	}
+	lc.User = user
+	lc.Admin = lifecycleAdmin()
+	lc.Workstation = lifecycleWorkstation(user.Username)
+
+	// Onboarding day, the work days, the exit day and, when offboarding, the
+	// day the former employee tries to log back in
+	n := lc.WorkDays + 2
+	if lc.Outcome == LifecycleOffboard {
+		n++
+	}
+	if req.Start != nil {
+		day := nextBusinessDay(req.Start.UTC().Truncate(24 * time.Hour))
+		for len(lc.days) < n {
+			lc.days = append(lc.days, day)
+			day = nextBusinessDay(day.Add(24 * time.Hour))
+		}
+	} else {
+		// End the lifecycle on the most recent business day
+		day := time.Now().UTC().Truncate(24 * time.Hour)
+		for len(lc.days) < n {
+			if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+				lc.days = append([]time.Time{day}, lc.days...)
+			}
+			day = day.Add(-24 * time.Hour)
+		}
+	}
+	lc.Start = lc.days[0]
+	lc.End = lc.days[len(lc.days)-1].Add(24 * time.Hour)
+
+	lc.netbios = Theme().NetBIOS()
+	lc.domainSID = fmt.Sprintf("S-1-5-21-%d-%d-%d", b.RandomInt(100000000, 999999999), b.RandomInt(100000000, 999999999), b.RandomInt(100000000, 999999999))
+	lc.userSID = fmt.Sprintf("%s-%d", lc.domainSID, b.RandomInt(2000, 9999))
+	lc.adminSID = fmt.Sprintf("%s-%d", lc.domainSID, b.RandomInt(1100, 1999))
+	lc.groupSID = fmt.Sprintf("%s-%d", lc.domainSID, b.RandomInt(1100, 1999))
+	lc.oktaID = "00u" + b.RandomString(17)
+	lc.adminOkta = "00u" + b.RandomString(17)
+	lc.accountID = AWS.RandomAccount(b.rnd).ID
+	lc.region = b.RandomChoice([]string{"us-east-1", "us-east-2", "us-west-2", "eu-west-1"})
+	lc.iamID = "AIDA" + strings.ToUpper(b.RandomString(17))
+	lc.adminIAMID = "AIDA" + strings.ToUpper(b.RandomString(17))
+	lc.office = Geo.RandomBenign(b.rnd)
+	lc.home = Geo.RandomBenign(b.rnd)
+	return lc, nil
+}
+
+// resolveLifecycleUser returns the pool user named in the request, a new
+// user built from the requested username, or a generated new hire
+func resolveLifecycleUser(b *BaseGenerator, req *models.LifecycleRequest) (*EntityUser, error) {
+	department := req.Department
+	if department == "" {
+		department = b.RandomChoice([]string{"Engineering", "Finance", "Sales", "Marketing", "HR", "IT", "Legal", "Operations"})
+	}
+
+	username := strings.ToLower(req.Username)
+	if username != "" {
+		if u, ok := Entities.UserByName(username); ok {
+			return u, nil
+		}
+		if strings.ContainsAny(username, " @\\/") {
+			return nil, fmt.Errorf("username must not contain spaces, @ or slashes")
+		}
+	} else {
+		for i := 0; ; i++ {
+			username = b.RandomChoice(newHireFirst) + "." + b.RandomChoice(newHireLast)
+			if _, taken := Entities.UserByName(username); !taken {
+				break
+			}
+			if i > 20 {
+				username = fmt.Sprintf("%s%d", username, b.RandomInt(10, 99))
+				break
+			}
+		}
+	}
+
+	fullName := req.FullName
+	if fullName == "" {
+		var parts []string
+		for _, p := range strings.Split(username, ".") {
+			if p != "" {
+				parts = append(parts, capitalize(p))
+			}
+		}
+		fullName = strings.Join(parts, " ")
+	}
+
+	return &EntityUser{
+		Username:   username,
+		FullName:   fullName,
+		Email:      username + "@" + Theme().Domain,
+		Department: department,
+		UID:        1000 + len(Entities.Users()) + b.RandomInt(1, 899),
+	}, nil
+}
+
+// lifecycleAdmin returns the IT administrator who manages accounts
+func lifecycleAdmin() *EntityUser {
+	users := Entities.Users()
+	for _, u := range users {
+		if u.Department == "IT" {
+			return u
+		}
+	}
+	return users[0]
+}
+
+// lifecycleWorkstation returns the user's Windows workstation, or a random
+// one for a user who owns none, since logons are recorded as Security events
+func lifecycleWorkstation(username string) *EntityHost {
+	if h := Entities.HostForUser(Unseeded, username); h.Platform == "windows" && h.Role == "workstation" {
+		return h
+	}
+	var workstations []*EntityHost
+	for _, h := range Entities.Hosts("windows") {
+		if h.Role == "workstation" {
+			workstations = append(workstations, h)
+		}
+	}
+	return workstations[Unseeded.intn(len(workstations))]
+}
+
+// nextBusinessDay returns day, or the following Monday if day is a weekend
+func nextBusinessDay(day time.Time) time.Time {
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.Add(24 * time.Hour)
+	}
+	return day
+}
+
+// timeOnDay returns a time on day between hour and hour plus spread minutes
+func timeOnDay(day time.Time, hour, spread int) time.Time {
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(Unseeded.float64()*float64(spread)*float64(time.Minute)))
+}
+
+// GenerateLifecycle emits the employee's onboarding (AD account creation,
+// Okta activation, IAM user creation), a daily routine of workstation, Okta
+// and AWS console logons, and then either offboarding followed by a failed
+// attempt to log back in, or an insider going rogue: an off-hours login from
+// an unusual country, self-granted Domain Admins, a new access key and a
+// burst of secret reads.
+func GenerateLifecycle(lc *Lifecycle) ([]*models.GeneratedEvent, error) {
+	adGen := builtin("microsoft_ad").(*MicrosoftADGenerator)
+	winGen := builtin("windows_security").(*WindowsSecurityGenerator)
+	oktaGen := builtin("okta").(*OktaGenerator)
+	ctGen := builtin("aws_cloudtrail").(*AWSCloudTrailGenerator)
+
+	var events []*models.GeneratedEvent
+	var firstErr error
+	add := func(e *models.GeneratedEvent, err error) {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		events = append(events, e)
+	}
+	minutes := func(min, max int) time.Duration {
+		return time.Duration(adGen.RandomInt(min, max)) * time.Minute
+	}
+
+	// Onboarding: IT provisions the account in AD, Okta and AWS, then the
+	// new hire logs on for the first time
+	t := timeOnDay(lc.days[0], 9, 60)
+	add(adGen.generate4720(t, lc.adUserFields(lc.Admin, map[string]interface{}{
+		"SamAccountName":    lc.User.Username,
+		"DisplayName":       lc.User.FullName,
+		"UserPrincipalName": lc.User.Username + "@" + Theme().ADDomain,
+		"PasswordLastSet":   t.Format("1/2/2006 3:04:05 PM"),
+	})))
+	t = t.Add(minutes(1, 3))
+	add(adGen.generate4722(t, lc.adUserFields(lc.Admin, nil)))
+	t = t.Add(minutes(1, 3))
+	add(adGen.generate4728(t, lc.adGroupFields(lc.Admin, lc.User.Department+"-Users", lc.groupSID)))
+	t = t.Add(minutes(5, 20))
+	add(oktaGen.generateUserLifecycle(t, "user.lifecycle.create", "Create Okta user", lc.oktaAdminFields()))
+	t = t.Add(minutes(1, 5))
+	add(oktaGen.generateUserLifecycle(t, "user.lifecycle.activate", "Activate Okta user", lc.oktaAdminFields()))
+	t = t.Add(minutes(10, 40))
+	add(ctGen.generateCreateUser(t, lc.cloudTrailFields(lc.Admin, lc.adminIAMID, lc.office, map[string]interface{}{
+		"requestParameters": map[string]interface{}{"userName": lc.User.Username, "path": "/"},
+		"responseElements": map[string]interface{}{
+			"user": map[string]interface{}{
+				"path":       "/",
+				"userName":   lc.User.Username,
+				"userId":     lc.iamID,
+				"arn":        lc.iamARN(lc.User),
+				"createDate": t.UTC().Format(time.RFC3339),
+			},
+		},
+	})))
+	t = t.Add(minutes(20, 60))
+	add(winGen.generate4624(t, lc.interactiveLogonFields()))
+	add(oktaGen.generateSessionStart(t.Add(minutes(2, 10)), lc.oktaUserFields(lc.office, nil)))
+
+	// Normal work: one workstation logon and Okta session every business
+	// day, a few app sign-ons and the occasional AWS console session
+	consoleChance := 0.3
+	if lc.User.Department == "Engineering" || lc.User.Department == "IT" {
+		consoleChance = 0.7
+	}
+	workday := func(day time.Time) {
+		t := timeOnDay(day, 8, 90)
+		add(winGen.generate4624(t, lc.interactiveLogonFields()))
+		t = t.Add(minutes(2, 10))
+		add(oktaGen.generateSessionStart(t, lc.oktaUserFields(lc.office, nil)))
+		for i := oktaGen.RandomInt(1, 4); i > 0; i-- {
+			add(oktaGen.generateSSOAuth(timeOnDay(day, 9, 480), lc.oktaUserFields(lc.office, nil)))
+		}
+		if Unseeded.float64() < consoleChance {
+			add(ctGen.generateConsoleLogin(timeOnDay(day, 10, 360), lc.consoleLoginFields(lc.office, "Yes")))
+		}
+	}
+	exit := len(lc.days) - 1
+	if lc.Outcome == LifecycleOffboard {
+		exit--
+	}
+	for _, day := range lc.days[1:exit] {
+		workday(day)
+	}
+
+	switch lc.Outcome {
+	case LifecycleOffboard:
+		// Last day: the employee works as usual and IT disables the
+		// account in the evening
+		day := lc.days[exit]
+		workday(day)
+		t := timeOnDay(day, 17, 60)
+		add(adGen.generate4725(t, lc.adUserFields(lc.Admin, nil)))
+		t = t.Add(minutes(2, 10))
+		add(oktaGen.generateUserLifecycle(t, "user.lifecycle.deactivate", "Deactivate Okta user", lc.oktaAdminFields()))
+		t = t.Add(minutes(10, 40))
+		add(ctGen.generateDeleteUser(t, lc.cloudTrailFields(lc.Admin, lc.adminIAMID, lc.office, map[string]interface{}{
+			"requestParameters": map[string]interface{}{"userName": lc.User.Username},
+			"responseElements":  nil,
+		})))
+
+		// The former employee tries to log back in the next morning, and
+		// IT deletes the disabled AD account later that day
+		day = lc.days[exit+1]
+		t = timeOnDay(day, 8, 90)
+		add(winGen.generate4625(t, map[string]interface{}{
+			"TargetUserName":            lc.User.Username,
+			"TargetDomainName":          lc.netbios,
+			"Status":                    "0xc000006e",
+			"SubStatus":                 "0xc0000072",
+			"FailureReason":             "%%2310",
+			"LogonType":                 2,
+			"LogonProcessName":          "User32 ",
+			"AuthenticationPackageName": "Negotiate",
+			"WorkstationName":           lc.Workstation.Hostname,
+			"IpAddress":                 "127.0.0.1",
+			"IpPort":                    "0",
+		}))
+		t = timeOnDay(day, 10, 240)
+		for i := oktaGen.RandomInt(1, 3); i > 0; i-- {
+			add(oktaGen.generateAuthFailure(t, lc.oktaUserFields(lc.home, map[string]interface{}{
+				"severity": "WARN",
+				"outcome":  map[string]interface{}{"result": "FAILURE", "reason": "VERIFICATION_ERROR"},
+			})))
+			t = t.Add(time.Duration(oktaGen.RandomInt(10, 90)) * time.Second)
+		}
+		add(adGen.generate4726(timeOnDay(day, 14, 120), lc.adUserFields(lc.Admin, nil)))
+
+	case LifecycleRogue:
+		// In the small hours before the last day the employee signs in from
+		// abroad, grants themselves Domain Admins, mints an access key and
+		// reads every secret they can reach, then turns up for work as usual
+		day := lc.days[exit]
+		remote := Geo.RandomMalicious(Unseeded)
+		t := timeOnDay(day, 1, 150)
+		add(oktaGen.generateSessionStart(t, lc.oktaUserFields(remote, nil)))
+		t = t.Add(minutes(2, 8))
+		add(ctGen.generateConsoleLogin(t, lc.consoleLoginFields(remote, "No")))
+		t = t.Add(minutes(3, 10))
+		add(adGen.generate4728(t, lc.adGroupFields(lc.User, "Domain Admins", lc.domainSID+"-512")))
+		t = t.Add(minutes(2, 10))
+		key := "AKIA" + strings.ToUpper(ctGen.RandomString(16))
+		add(ctGen.generateCreateAccessKey(t, lc.cloudTrailFields(lc.User, lc.iamID, remote, map[string]interface{}{
+			"requestParameters": map[string]interface{}{"userName": lc.User.Username},
+			"responseElements": map[string]interface{}{
+				"accessKey": map[string]interface{}{
+					"userName":    lc.User.Username,
+					"accessKeyId": key,
+					"status":      "Active",
+					"createDate":  t.UTC().Format(time.RFC3339),
+				},
+			},
+		})))
+		secrets := []string{"prod/database/password", "prod/payments/api-key", "api/keys/external", "config/encryption-key",
+			"service/oauth/client-secret", "prod/ldap/bind-password", "hr/payroll/sftp", "finance/erp/service-account"}
+		t = t.Add(minutes(1, 5))
+		for i := ctGen.RandomInt(8, 20); i > 0; i-- {
+			identity := lc.iamIdentity(lc.User, lc.iamID)
+			identity["accessKeyId"] = key
+			add(ctGen.generateGetSecretValue(t, map[string]interface{}{
+				"userIdentity":       identity,
+				"awsRegion":          lc.region,
+				"recipientAccountId": lc.accountID,
+				"sourceIPAddress":    remote.IP,
+				"userAgent":          "aws-cli/2.13.0 Python/3.11.4 Linux/5.15.0",
+				"requestParameters":  map[string]interface{}{"secretId": ctGen.RandomChoice(secrets), "versionStage": "AWSCURRENT"},
+			}))
+			t = t.Add(time.Duration(ctGen.RandomInt(5, 40)) * time.Second)
+		}
+		workday(day)
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	return events, nil
+}
+
+// adUserFields targets the employee's account, performed by subject
+func (lc *Lifecycle) adUserFields(subject *EntityUser, extra map[string]interface{}) map[string]interface{} {
+	fields := map[string]interface{}{
+		"TargetUserName":    lc.User.Username,
+		"TargetDomainName":  lc.netbios,
+		"TargetSid":         lc.userSID,
+		"SubjectUserSid":    lc.sidFor(subject),
+		"SubjectUserName":   subject.Username,
+		"SubjectDomainName": lc.netbios,
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+	return fields
+}
+
+// adGroupFields adds the employee to group, performed by subject
+func (lc *Lifecycle) adGroupFields(subject *EntityUser, group, groupSID string) map[string]interface{} {
+	dn := domainDN()
+	return map[string]interface{}{
+		"MemberName":        fmt.Sprintf("CN=%s,OU=Employees,OU=Users,%s", lc.User.FullName, dn),
+		"MemberSid":         lc.userSID,
+		"TargetUserName":    group,
+		"TargetDomainName":  lc.netbios,
+		"TargetSid":         groupSID,
+		"SubjectUserSid":    lc.sidFor(subject),
+		"SubjectUserName":   subject.Username,
+		"SubjectDomainName": lc.netbios,
+	}
+}
+
+func (lc *Lifecycle) sidFor(u *EntityUser) string {
+	if u == lc.User {
+		return lc.userSID
+	}
+	return lc.adminSID
+}
+
+// interactiveLogonFields is the employee logging on at their workstation
+func (lc *Lifecycle) interactiveLogonFields() map[string]interface{} {
+	return map[string]interface{}{
+		"SubjectUserSid":            "S-1-5-18",
+		"SubjectUserName":           lc.Workstation.Hostname + "$",
+		"SubjectDomainName":         lc.netbios,
+		"TargetUserSid":             lc.userSID,
+		"TargetUserName":            lc.User.Username,
+		"TargetDomainName":          lc.netbios,
+		"LogonType":                 2,
+		"LogonProcessName":          "User32 ",
+		"AuthenticationPackageName": "Negotiate",
+		"WorkstationName":           lc.Workstation.Hostname,
+		"LmPackageName":             "-",
+		"KeyLength":                 0,
+		"ProcessName":               "C:\\Windows\\System32\\svchost.exe",
+		"IpAddress":                 "127.0.0.1",
+		"IpPort":                    "0",
+	}
+}
+
+func oktaActor(id string, u *EntityUser) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          id,
+		"type":        "User",
+		"alternateId": u.Email,
+		"displayName": u.FullName,
+	}
+}
+
+// oktaNetwork returns the client, security context and request fields of an
+// Okta event coming from loc
+func oktaNetwork(loc GeoLocation) map[string]interface{} {
+	return map[string]interface{}{
+		"client": map[string]interface{}{
+			"userAgent": map[string]interface{}{
+				"rawUserAgent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
+				"os":           "Windows 10",
+				"browser":      "Chrome",
+			},
+			"zone":      "null",
+			"device":    "Computer",
+			"id":        nil,
+			"ipAddress": loc.IP,
+			"geographicalContext": map[string]interface{}{
+				"city":    loc.City,
+				"country": loc.CountryName,
+				"geolocation": map[string]interface{}{
+					"lat": fmt.Sprintf("%.4f", loc.Latitude),
+					"lon": fmt.Sprintf("%.4f", loc.Longitude),
+				},
+			},
+		},
+		"securityContext": map[string]interface{}{
+			"asNumber": loc.ASN,
+			"asOrg":    loc.ASOrg,
+			"isp":      loc.ASOrg,
+		},
+		"request": map[string]interface{}{
+			"ipChain": []map[string]interface{}{
+				{"ip": loc.IP},
+			},
+		},
+	}
+}
+
+// oktaUserFields is the employee acting from loc
+func (lc *Lifecycle) oktaUserFields(loc GeoLocation, extra map[string]interface{}) map[string]interface{} {
+	fields := oktaNetwork(loc)
+	fields["actor"] = oktaActor(lc.oktaID, lc.User)
+	fields["severity"] = "INFO"
+	for k, v := range extra {
+		fields[k] = v
+	}
+	return fields
+}
+
+// oktaAdminFields is the administrator acting on the employee from the office
+func (lc *Lifecycle) oktaAdminFields() map[string]interface{} {
+	fields := oktaNetwork(lc.office)
+	fields["actor"] = oktaActor(lc.adminOkta, lc.Admin)
+	fields["target"] = []map[string]interface{}{oktaActor(lc.oktaID, lc.User)}
+	return fields
+}
+
+func (lc *Lifecycle) iamARN(u *EntityUser) string {
+	return fmt.Sprintf("arn:aws:iam::%s:user/%s", lc.accountID, u.Username)
+}
+
+func (lc *Lifecycle) iamIdentity(u *EntityUser, principalID string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "IAMUser",
+		"principalId": principalID,
+		"arn":         lc.iamARN(u),
+		"accountId":   lc.accountID,
+		"userName":    u.Username,
+	}
+}
+
+// cloudTrailFields is u calling the AWS API from loc
+func (lc *Lifecycle) cloudTrailFields(u *EntityUser, principalID string, loc GeoLocation, extra map[string]interface{}) map[string]interface{} {
+	fields := map[string]interface{}{
+		"userIdentity":       lc.iamIdentity(u, principalID),
+		"awsRegion":          lc.region,
+		"recipientAccountId": lc.accountID,
+		"sourceIPAddress":    loc.IP,
+		"userAgent":          "console.amazonaws.com",
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+	return fields
+}
+
+// consoleLoginFields is a successful console sign-in by the employee from loc
+func (lc *Lifecycle) consoleLoginFields(loc GeoLocation, mfa string) map[string]interface{} {
+	return lc.cloudTrailFields(lc.User, lc.iamID, loc, map[string]interface{}{
+		"userAgent":        "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		"responseElements": map[string]interface{}{"ConsoleLogin": "Success"},
+		"additionalEventData": map[string]interface{}{
+			"LoginTo":       fmt.Sprintf("https://console.aws.amazon.com/console/home?region=%s", lc.region),
+			"MobileVersion": "No",
+			"MFAUsed":       mfa,
+		},
+	})
+}
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -176,7 +175,7 @@ func (g *LinuxAuditbeatGenerator) generateProcess(overrides map[string]interface
 			"working_directory": "/home/" + user,
 			"start":      now.Add(-time.Duration(g.RandomInt(1, 3600)) * time.Second).Format(time.RFC3339Nano),
 			"hash": map[string]interface{}{
-				"sha256": g.RandomString(64),
+				"sha256": g.FileHash(Files.ForName(procPath), models.IOCTypeSHA256),
 			},
 			"parent": map[string]interface{}{
 				"pid":        g.RandomInt(1, 1000),
@@ -209,7 +208,7 @@ func (g *LinuxAuditbeatGenerator) generateProcess(overrides map[string]interface
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -246,6 +245,7 @@ func (g *LinuxAuditbeatGenerator) generateFile(overrides map[string]interface{})
 	if filePath == "/home/%s/.bashrc" || filePath == "/home/%s/.ssh/authorized_keys" {
 		filePath = fmt.Sprintf(filePath, user)
 	}
+	file := Files.ForName(filePath)
 
 	actions := []string{"created", "updated", "deleted", "attributes_modified"}
 
@@ -291,9 +291,9 @@ func (g *LinuxAuditbeatGenerator) generateFile(overrides map[string]interface{})
 			"mtime":     now.Format(time.RFC3339Nano),
 			"ctime":     now.Format(time.RFC3339Nano),
 			"hash": map[string]interface{}{
-				"sha256": g.RandomString(64),
-				"sha1":   g.RandomString(40),
-				"md5":    g.RandomString(32),
+				"sha256": g.FileHash(file, models.IOCTypeSHA256),
+				"sha1":   g.FileHash(file, models.IOCTypeSHA1),
+				"md5":    g.FileHash(file, models.IOCTypeMD5),
 			},
 		},
 		"user": map[string]interface{}{
@@ -308,7 +308,7 @@ func (g *LinuxAuditbeatGenerator) generateFile(overrides map[string]interface{})
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -329,11 +329,17 @@ func (g *LinuxAuditbeatGenerator) generateUserLogin(overrides map[string]interfa
 	now := time.Now().UTC()
 	hostname := g.RandomLinuxHostname()
 	user := g.RandomLinuxUser()
-	srcIP := g.RandomIPv4External()
-
 	outcomes := []string{"success", "failure"}
 	outcome := g.RandomChoice(outcomes)
 
+	// Failed logins come from attacker ranges, successful ones from the
+	// benign countries, and source.geo always matches the IP
+	loc := g.RandomBenignGeoIP()
+	if outcome == "failure" {
+		loc = g.RandomMaliciousGeoIP()
+	}
+	srcIP := loc.IP
+
 	fields := map[string]interface{}{
 		"@timestamp": now.Format(time.RFC3339Nano),
 		"ecs": map[string]interface{}{
@@ -364,8 +370,14 @@ func (g *LinuxAuditbeatGenerator) generateUserLogin(overrides map[string]interfa
 			"ip":   srcIP,
 			"port": g.RandomPort(),
 			"geo": map[string]interface{}{
-				"country_iso_code": g.RandomChoice([]string{"US", "CN", "RU", "DE", "BR", "IN"}),
-				"city_name":        g.RandomChoice([]string{"New York", "Beijing", "Moscow", "Berlin", "Sao Paulo", "Mumbai"}),
+				"country_iso_code": loc.CountryCode,
+				"country_name":     loc.CountryName,
+				"city_name":        loc.City,
+				"location":         map[string]interface{}{"lat": loc.Latitude, "lon": loc.Longitude},
+			},
+			"as": map[string]interface{}{
+				"number":       loc.ASN,
+				"organization": map[string]interface{}{"name": loc.ASOrg},
 			},
 		},
 		"user": map[string]interface{}{
@@ -401,7 +413,7 @@ func (g *LinuxAuditbeatGenerator) generateUserLogin(overrides map[string]interfa
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -477,7 +489,7 @@ func (g *LinuxAuditbeatGenerator) generateSocket(overrides map[string]interface{
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -556,7 +568,7 @@ func (g *LinuxAuditbeatGenerator) generatePackage(overrides map[string]interface
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
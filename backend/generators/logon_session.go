@@ -0,0 +1,175 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logonSession is an open Windows logon session. Events a user causes on
+// the host while it is open carry its LogonId.
+type logonSession struct {
+	Computer  string // FQDN of the host the session is on
+	UserSid   string
+	UserName  string
+	Domain    string
+	LogonID   string
+	LogonType int
+	Started   time.Time
+}
+
+// interactive reports whether the user is at the keyboard or on RDP, the
+// logon types that start processes and end with a user initiated logoff
+func (s *logonSession) interactive() bool {
+	switch s.LogonType {
+	case 2, 10, 11:
+		return true
+	}
+	return false
+}
+
+const (
+	// maxLogonSessions bounds the open sessions kept during long runs; the
+	// oldest are forgotten first
+	maxLogonSessions = 2000
+	// logonSessionMaxAge is how long a session may stay open without a
+	// logoff, about a working day
+	logonSessionMaxAge = 10 * time.Hour
+)
+
+// LogonSessionTracker keeps the Windows logon sessions opened by 4624 events
+// so the process creation and privilege events that follow on the same host
+// reuse their LogonId until a 4634 or 4647 logoff closes them
+type LogonSessionTracker struct {
+	mu       sync.Mutex
+	sessions []*logonSession
+}
+
+// LogonSessions is the tracker shared by the Windows Security generator
+var LogonSessions = &LogonSessionTracker{}
+
+// open records a new session
+func (t *LogonSessionTracker) open(s *logonSession) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(s.Started)
+	if len(t.sessions) >= maxLogonSessions {
+		t.sessions = t.sessions[1:]
+	}
+	t.sessions = append(t.sessions, s)
+}
+
+// pick returns a random open session, preferring interactive ones when
+// interactive is set, or nil when none is open
+func (t *LogonSessionTracker) pick(rnd *RandomStream, now time.Time, interactive bool) *logonSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(now)
+	i := t.choose(rnd, interactive)
+	if i < 0 {
+		return nil
+	}
+	return t.sessions[i]
+}
+
+// close removes and returns a random open session, only an interactive one
+// when interactive is set, or nil when there is none to end
+func (t *LogonSessionTracker) close(rnd *RandomStream, now time.Time, interactive bool) *logonSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(now)
+	i := t.choose(rnd, interactive)
+	if i < 0 || (interactive && !t.sessions[i].interactive()) {
+		return nil
+	}
+	s := t.sessions[i]
+	t.sessions = append(t.sessions[:i], t.sessions[i+1:]...)
+	return s
+}
+
+// Count returns the number of open sessions
+func (t *LogonSessionTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.sessions)
+}
+
+// choose returns the index of a random session, an interactive one if
+// interactive is set and any is open, or -1. Callers hold t.mu.
+func (t *LogonSessionTracker) choose(rnd *RandomStream, interactive bool) int {
+	if len(t.sessions) == 0 {
+		return -1
+	}
+	if interactive {
+		var candidates []int
+		for i, s := range t.sessions {
+			if s.interactive() {
+				candidates = append(candidates, i)
+			}
+		}
+		if len(candidates) > 0 {
+			return candidates[rnd.intn(len(candidates))]
+		}
+	}
+	return rnd.intn(len(t.sessions))
+}
+
+// expire drops sessions opened more than logonSessionMaxAge before now.
+// Callers hold t.mu.
+func (t *LogonSessionTracker) expire(now time.Time) {
+	kept := t.sessions[:0]
+	for _, s := range t.sessions {
+		if now.Sub(s.Started) <= logonSessionMaxAge {
+			kept = append(kept, s)
+		}
+	}
+	t.sessions = kept
+}
+
+// windowsHostByName returns the pool's Windows host with the given short
+// name, ignoring case
+func windowsHostByName(name string) (*EntityHost, bool) {
+	for _, h := range Entities.Hosts("windows") {
+		if strings.EqualFold(h.Hostname, name) {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// windowsDomainSID is the domain SID of the entity pool's accounts
+const windowsDomainSID = "S-1-5-21-3623811015-3361044348-30300820"
+
+// windowsUserSID returns the stable SID of a pool user
+func windowsUserSID(u *EntityUser) string {
+	return fmt.Sprintf("%s-%d", windowsDomainSID, u.UID+100)
+}
+
+// windowsNetBIOSDomain returns the pool domain's NetBIOS name
+func windowsNetBIOSDomain() string {
+	return Theme().NetBIOS()
+}
+
+// sessionFromLogon builds the session a 4624 opens on computer from its
+// fields, after overrides
+func sessionFromLogon(computer string, now time.Time, fields map[string]interface{}) *logonSession {
+	s := &logonSession{
+		Computer: computer,
+		UserSid:  fmt.Sprintf("%v", fields["TargetUserSid"]),
+		UserName: fmt.Sprintf("%v", fields["TargetUserName"]),
+		Domain:   fmt.Sprintf("%v", fields["TargetDomainName"]),
+		LogonID:  fmt.Sprintf("%v", fields["TargetLogonId"]),
+		Started:  now,
+	}
+	switch v := fields["LogonType"].(type) {
+	case int:
+		s.LogonType = v
+	case float64:
+		s.LogonType = int(v)
+	}
+	return s
+}
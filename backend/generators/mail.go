@@ -0,0 +1,170 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// MailMessage is an email as mail security products and audit logs see it
+type MailMessage struct {
+	MessageID   string        `json:"message_id"`
+	From        string        `json:"from"`
+	FromName    string        `json:"from_name"`
+	To          string        `json:"to"`
+	Subject     string        `json:"subject"`
+	Attachments []CatalogFile `json:"attachments,omitempty"`
+	Inbound     bool          `json:"inbound"`  // Sent to the organization from outside
+	Phishing    bool          `json:"phishing"` // Sent by an attacker
+}
+
+var (
+	mailFreemailDomains = []string{"gmail.com", "outlook.com", "yahoo.com", "icloud.com", "proton.me", "gmx.de"}
+	mailPartnerDomains  = []string{"northwind-logistics.com", "contoso-supply.com", "fabrikam.net", "adventure-works.com", "tailspin-consulting.com", "wingtip-partners.com"}
+	mailServiceSenders  = []struct{ name, address string }{
+		{"DocuSign", "dse_na4@docusign.net"},
+		{"Microsoft 365", "no-reply@microsoft.com"},
+		{"Zoom", "no-reply@zoom.us"},
+		{"LinkedIn", "messages-noreply@linkedin.com"},
+		{"Workday", "workday@myworkday.com"},
+		{"Atlassian", "jira@%ORG%.atlassian.net"},
+	}
+
+	mailSubjects = []string{
+		"Re: Q%Q% budget review", "Meeting notes - %DAY%", "Project update", "Weekly status report",
+		"Re: Contract renewal", "FW: Updated org chart", "Lunch on %DAY%?", "Invoice %NUM% from %COMPANY%",
+		"Your order %NUM% has shipped", "Re: Re: Onboarding checklist", "Agenda for %DAY% sync",
+		"Please review: %DOC%", "Out of office: %NAME%", "Reminder: expense reports due %DAY%",
+	}
+	mailPhishingSubjects = []string{
+		"Action required: your password expires today", "Unusual sign-in activity on your account",
+		"Overdue invoice %NUM% - final notice", "You have 3 undelivered messages", "DocuSign: please sign %DOC%",
+		"RE: Payment advice %NUM%", "Your mailbox is almost full", "Shared document: Q%Q% bonus structure",
+		"URGENT: wire transfer needed today", "New voicemail from %NAME%", "HR: updated remote work policy - acknowledge",
+	}
+	mailDocuments = []string{"Q3_Financial_Report.pdf", "Employee_Handbook_2024.pdf", "budget_forecast.xlsx", "meeting_notes.docx", "NDA_draft.docx", "SOW_v2.pdf"}
+)
+
+// RandomInternalEmail returns the address of a person in the entity pool
+func (b *BaseGenerator) RandomInternalEmail() string {
+	return Entities.RandomUser(b.rnd).Email
+}
+
+// RandomExternalEmail returns the address of someone outside the
+// organization: a partner, a vendor or a personal mailbox
+func (b *BaseGenerator) RandomExternalEmail() (name, address string) {
+	p := b.RandomPerson()
+	name = p.FirstName + " " + p.LastName
+	local, _, _ := strings.Cut(p.Email, "@")
+	if b.RandomInt(1, 100) <= 35 {
+		return name, fmt.Sprintf("%s%d@%s", local, b.RandomInt(1, 99), b.RandomChoice(mailFreemailDomains))
+	}
+	return name, local + "@" + b.RandomChoice(mailPartnerDomains)
+}
+
+// RandomPhishingSender returns a sender posing as IT, a service or the
+// organization itself, from a lookalike or throwaway domain
+func (b *BaseGenerator) RandomPhishingSender() (name, address string) {
+	base, tld, _ := strings.Cut(Theme().Domain, ".")
+	homoglyph := base + "s"
+	for _, swap := range [][2]string{{"l", "1"}, {"o", "0"}, {"m", "rn"}, {"i", "1"}} {
+		if strings.Contains(base, swap[0]) {
+			homoglyph = strings.Replace(base, swap[0], swap[1], 1)
+			break
+		}
+	}
+	lookalikes := []string{
+		homoglyph + "." + tld,
+		base + "-" + tld + ".co",
+		base + "-support.com",
+		"secure-" + base + ".net",
+		"microsoft-365-alerts.com",
+		"docusign-notify.net",
+	}
+	domain := b.InjectIOC(models.IOCTypeDomain, b.RandomChoice(lookalikes))
+	switch b.RandomInt(1, 3) {
+	case 1:
+		return "IT Helpdesk", "it-support@" + domain
+	case 2:
+		return "Microsoft 365", "no-reply@" + domain
+	}
+	return capitalize(tenantName()) + " Payroll", "payroll@" + domain
+}
+
+// MessageID returns an RFC 5322 Message-ID as the sending domain's mail
+// system would write it: Exchange Online for the organization's mail,
+// Gmail for freemail and a generic form otherwise
+func (b *BaseGenerator) MessageID(sender string, sent time.Time) string {
+	_, domain, _ := strings.Cut(sender, "@")
+	switch {
+	case domain == Theme().Domain:
+		region := b.RandomInt(10, 19)
+		server := fmt.Sprintf("%s%02dMB%04d", b.RandomChoice([]string{"DM6PR", "BN8PR", "SA1PR", "CH3PR", "PH0PR"}), region, b.RandomInt(1000, 9999))
+		return fmt.Sprintf("<%s%s@%s.namprd%02d.prod.outlook.com>", server, strings.ToUpper(b.RandomHex(12)), server, region)
+	case domain == "gmail.com":
+		return fmt.Sprintf("<CA%s@mail.gmail.com>", b.RandomString(45))
+	}
+	return fmt.Sprintf("<%d.%s@%s>", sent.UnixNano(), b.RandomHex(8), domain)
+}
+
+// RandomSubject returns a subject line of everyday business mail, or of a
+// phishing lure
+func (b *BaseGenerator) RandomSubject(phishing bool) string {
+	subjects := mailSubjects
+	if phishing {
+		subjects = mailPhishingSubjects
+	}
+	return strings.NewReplacer(
+		"%Q%", fmt.Sprintf("%d", b.RandomInt(1, 4)),
+		"%DAY%", b.RandomChoice([]string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}),
+		"%NUM%", fmt.Sprintf("%d", b.RandomInt(10000, 99999)),
+		"%COMPANY%", b.RandomChoice([]string{"Northwind", "Contoso", "Fabrikam", "Tailspin"}),
+		"%DOC%", b.RandomChoice(mailDocuments),
+		"%NAME%", b.RandomPerson().DisplayName,
+	).Replace(b.ZipfChoice(subjects))
+}
+
+// RandomAttachment picks an attachment from the file catalog: a document,
+// archive or image, or for phishing a malicious document or archive, so its
+// hashes match the file in endpoint and network events
+func (b *BaseGenerator) RandomAttachment(phishing bool) CatalogFile {
+	if phishing {
+		return b.RandomMaliciousFile(FileDocument, FileArchive)
+	}
+	return b.RandomFile(FileDocument, FileArchive, FileImage)
+}
+
+// RandomMailMessage returns a message between people in the entity pool and
+// outside contacts. Phishing messages come from a lookalike sender to an
+// employee and usually carry a malicious attachment.
+func (b *BaseGenerator) RandomMailMessage(phishing bool, sent time.Time) MailMessage {
+	m := MailMessage{Subject: b.RandomSubject(phishing), Phishing: phishing}
+	internal := Entities.RandomUser(b.rnd)
+
+	switch {
+	case phishing:
+		m.FromName, m.From = b.RandomPhishingSender()
+		m.To, m.Inbound = internal.Email, true
+	case b.RandomInt(1, 100) <= 50:
+		m.FromName, m.From = internal.FullName, internal.Email
+		m.To = b.RandomInternalEmail()
+	case b.RandomInt(1, 100) <= 60:
+		m.FromName, m.From = b.RandomExternalEmail()
+		m.To, m.Inbound = internal.Email, true
+	case b.RandomInt(1, 100) <= 50:
+		sender := mailServiceSenders[b.RandomInt(0, len(mailServiceSenders)-1)]
+		m.FromName, m.From = sender.name, strings.ReplaceAll(sender.address, "%ORG%", tenantName())
+		m.To, m.Inbound = internal.Email, true
+	default:
+		m.FromName, m.From = internal.FullName, internal.Email
+		_, m.To = b.RandomExternalEmail()
+	}
+	m.MessageID = b.MessageID(m.From, sent)
+
+	if phishing && b.RandomInt(1, 100) <= 70 || !phishing && b.RandomInt(1, 100) <= 20 {
+		m.Attachments = []CatalogFile{b.RandomAttachment(phishing)}
+	}
+	return m
+}
@@ -0,0 +1,29 @@
+package generators
+
+import "fmt"
+
+// MeasureTemplate generates n events from a template and returns their
+// average raw length in bytes. Generation errors are returned with the
+// average of the events that succeeded.
+func MeasureTemplate(g Generator, templateID string, overrides map[string]interface{}, n int) (float64, error) {
+	var total, ok int
+	var firstErr error
+	for i := 0; i < n; i++ {
+		event, err := Unseeded.Generate(g, templateID, overrides)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		total += len(event.RawEvent)
+		ok++
+	}
+	if ok == 0 {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("no events generated")
+		}
+		return 0, firstErr
+	}
+	return float64(total) / float64(ok), firstErr
+}
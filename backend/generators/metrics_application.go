@@ -1,8 +1,8 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +10,10 @@ import (
 	"siem-event-generator/models"
 )
 
+// appServices are the fake microservices shared by application metrics and
+// traces, most popular first
+var appServices = []string{"order-service", "payment-service", "user-service", "inventory-service", "notification-service", "auth-service", "catalog-service", "shipping-service"}
+
 // ApplicationMetricsGenerator generates application performance metrics for ITSI
 type ApplicationMetricsGenerator struct {
 	BaseGenerator
@@ -41,6 +45,14 @@ func (g *ApplicationMetricsGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "json",
 			Description: "Application response time and latency percentiles",
 			Sourcetype:  "metrics",
+			Fields: []models.EventField{
+				{
+					Name:         "app.response_time.p50",
+					Type:         "number",
+					Description:  "Median response time in ms, before jitter; checkout is slower",
+					Distribution: appResponseTime,
+				},
+			},
 		},
 		{
 			ID:          "request_rate",
@@ -103,7 +115,7 @@ func (g *ApplicationMetricsGenerator) GetTemplates() []models.EventTemplate {
 func (g *ApplicationMetricsGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	switch templateID {
 	case "response_time":
-		return g.generateResponseTime(overrides)
+		return g.generateResponseTime(templateID, overrides)
 	case "request_rate":
 		return g.generateRequestRate(overrides)
 	case "error_rate":
@@ -122,12 +134,21 @@ func (g *ApplicationMetricsGenerator) Generate(templateID string, overrides map[
 }
 
 func (g *ApplicationMetricsGenerator) randomService() string {
-	services := []string{"order-service", "payment-service", "user-service", "inventory-service", "notification-service", "auth-service", "catalog-service", "shipping-service"}
-	return g.RandomChoice(services)
+	if service, ok := Scenarios.TargetValue(g.rnd, "service", appMetricPrefixes...); ok {
+		return service
+	}
+	return g.ZipfChoice(appServices)
 }
 
+// appMetricPrefixes are the metric name prefixes this generator emits, used to
+// steer host selection towards scenario targets
+var appMetricPrefixes = []string{"app.", "connections.", "jvm.", "queue.", "threads."}
+
 func (g *ApplicationMetricsGenerator) randomHost() string {
-	return fmt.Sprintf("app-%02d.prod.internal", g.RandomInt(1, 20))
+	if host, ok := Scenarios.TargetValue(g.rnd, "host", appMetricPrefixes...); ok {
+		return qualifyHost(host)
+	}
+	return ServerName(fmt.Sprintf("app-%02d", g.RandomInt(1, 20)))
 }
 
 func (g *ApplicationMetricsGenerator) randomRegion() string {
@@ -142,20 +163,18 @@ func (g *ApplicationMetricsGenerator) randomEnvironment() string {
 
 func (g *ApplicationMetricsGenerator) randomEndpoint() string {
 	endpoints := []string{"/api/v1/orders", "/api/v1/users", "/api/v1/products", "/api/v1/cart", "/api/v1/checkout", "/api/v1/payments", "/api/v1/inventory", "/health", "/metrics"}
-	return g.RandomChoice(endpoints)
+	return g.ZipfChoice(endpoints)
 }
 
 func (g *ApplicationMetricsGenerator) randomMethod() string {
 	methods := []string{"GET", "POST", "PUT", "DELETE", "PATCH"}
-	// Weight towards GET
-	if g.RandomInt(0, 10) > 3 {
-		return "GET"
-	}
-	return g.RandomChoice(methods)
+	return g.WeightedChoice(methods, []float64{70, 18, 6, 4, 2})
 }
 
 // buildMetricEvent creates a Splunk HEC metrics format event
 func (g *ApplicationMetricsGenerator) buildMetricEvent(metricName string, value float64, dimensions map[string]string, timestamp time.Time) map[string]interface{} {
+	value = Scenarios.Apply(metricName, value, dimensions, timestamp)
+
 	fields := map[string]interface{}{
 		"metric_name": metricName,
 		"_value":      value,
@@ -173,7 +192,11 @@ func (g *ApplicationMetricsGenerator) buildMetricEvent(metricName string, value
 	}
 }
 
-func (g *ApplicationMetricsGenerator) generateResponseTime(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+// appResponseTime is the median response time in ms of an endpoint other
+// than checkout, unless the template declares otherwise
+var appResponseTime = &models.DistributionSpec{Type: DistLogNormal, Median: 18, Sigma: 0.5}
+
+func (g *ApplicationMetricsGenerator) generateResponseTime(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
 	service := g.randomService()
 	host := g.randomHost()
@@ -185,10 +208,12 @@ func (g *ApplicationMetricsGenerator) generateResponseTime(overrides map[string]
 
 	for _, endpoint := range endpoints {
 		for _, method := range []string{"GET", "POST"} {
-			// Generate realistic latency distribution
-			baseLatency := float64(g.RandomInt(5, 50))
+			// Generate realistic long-tailed latency distribution
+			var baseLatency float64
 			if endpoint == "/api/v1/checkout" {
-				baseLatency = float64(g.RandomInt(100, 500)) // Checkout is slower
+				baseLatency = g.RandomLogNormal(220, 0.45) // Checkout is slower
+			} else {
+				baseLatency = g.declaredFloat(g, templateID, "app.response_time.p50", appResponseTime)
 			}
 
 			p50 := baseLatency + float64(g.RandomInt(0, 20))
@@ -231,7 +256,7 @@ func (g *ApplicationMetricsGenerator) generateResponseTime(overrides map[string]
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -309,7 +334,7 @@ func (g *ApplicationMetricsGenerator) generateRequestRate(overrides map[string]i
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -323,9 +348,18 @@ func (g *ApplicationMetricsGenerator) generateRequestRate(overrides map[string]i
 }
 
 func (g *ApplicationMetricsGenerator) generateErrorRate(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
-	service := g.randomService()
-	host := g.randomHost()
+	return g.errorRateEvent(g.randomService(), g.randomHost(), time.Now(), nil, overrides)
+}
+
+// errorSpike raises the 5xx error rate of one endpoint during an incident
+type errorSpike struct {
+	Endpoint    string
+	RatePercent float64 // Additional 5xx responses as a percentage of requests
+}
+
+// errorRateEvent builds error rate metrics for service/host at timestamp,
+// adding the spike's 5xx errors when one is given
+func (g *ApplicationMetricsGenerator) errorRateEvent(service, host string, timestamp time.Time, spike *errorSpike, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	region := g.randomRegion()
 	env := g.randomEnvironment()
 
@@ -347,6 +381,9 @@ func (g *ApplicationMetricsGenerator) generateErrorRate(overrides map[string]int
 	endpoints := []string{"/api/v1/orders", "/api/v1/users", "/api/v1/products", "/api/v1/checkout"}
 	metrics := make([]map[string]interface{}, 0)
 
+	totalRequests := float64(g.RandomInt(10000, 100000))
+	spikeShare := map[string]float64{"500": 0.6, "502": 0.15, "503": 0.15, "504": 0.1}
+
 	totalErrors := 0.0
 	total5xx := 0.0
 	total4xx := 0.0
@@ -369,6 +406,9 @@ func (g *ApplicationMetricsGenerator) generateErrorRate(overrides map[string]int
 			default:
 				errorCount = float64(g.RandomInt(0, 3))
 			}
+			if spike != nil && spike.Endpoint == endpoint {
+				errorCount += math.Round(totalRequests * spike.RatePercent / 100 * spikeShare[errType.code])
+			}
 
 			totalErrors += errorCount
 			if errType.code[0] == '5' {
@@ -401,7 +441,6 @@ func (g *ApplicationMetricsGenerator) generateErrorRate(overrides map[string]int
 		"service":     service,
 	}
 
-	totalRequests := float64(g.RandomInt(10000, 100000))
 	errorRate := (totalErrors / totalRequests) * 100
 
 	metrics = append(metrics,
@@ -420,7 +459,7 @@ func (g *ApplicationMetricsGenerator) generateErrorRate(overrides map[string]int
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -493,7 +532,7 @@ func (g *ApplicationMetricsGenerator) generateQueue(overrides map[string]interfa
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -574,7 +613,7 @@ func (g *ApplicationMetricsGenerator) generateThreads(overrides map[string]inter
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -600,11 +639,11 @@ func (g *ApplicationMetricsGenerator) generateConnections(overrides map[string]i
 		targetType string
 		maxSize    int
 	}{
-		{"postgres-primary", "db-primary.internal:5432", "database", 50},
-		{"postgres-replica", "db-replica.internal:5432", "database", 30},
-		{"redis-cache", "redis.internal:6379", "cache", 100},
-		{"elasticsearch", "es.internal:9200", "search", 20},
-		{"kafka", "kafka.internal:9092", "messaging", 10},
+		{"postgres-primary", ServerName("db-primary") + ":5432", "database", 50},
+		{"postgres-replica", ServerName("db-replica") + ":5432", "database", 30},
+		{"redis-cache", ServerName("redis") + ":6379", "cache", 100},
+		{"elasticsearch", ServerName("es") + ":9200", "search", 20},
+		{"kafka", ServerName("kafka") + ":9092", "messaging", 10},
 		{"http-external", "api.external.com:443", "http", 50},
 	}
 
@@ -652,7 +691,7 @@ func (g *ApplicationMetricsGenerator) generateConnections(overrides map[string]i
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -786,7 +825,7 @@ func (g *ApplicationMetricsGenerator) generateJVM(overrides map[string]interface
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -121,9 +120,16 @@ func (g *DatabaseMetricsGenerator) Generate(templateID string, overrides map[str
 	}
 }
 
+// dbMetricPrefixes are the metric name prefixes this generator emits, used to
+// steer host selection towards scenario targets
+var dbMetricPrefixes = []string{"db."}
+
 func (g *DatabaseMetricsGenerator) randomHost() string {
+	if host, ok := Scenarios.TargetValue(g.rnd, "host", dbMetricPrefixes...); ok {
+		return qualifyHost(host)
+	}
 	prefixes := []string{"db-primary", "db-replica", "db-analytics", "pg-master", "pg-slave", "mysql-primary"}
-	return fmt.Sprintf("%s-%02d.prod.internal", g.RandomChoice(prefixes), g.RandomInt(1, 5))
+	return ServerName(fmt.Sprintf("%s-%02d", g.RandomChoice(prefixes), g.RandomInt(1, 5)))
 }
 
 func (g *DatabaseMetricsGenerator) randomDatabase() string {
@@ -153,6 +159,8 @@ func (g *DatabaseMetricsGenerator) randomCluster() string {
 
 // buildMetricEvent creates a Splunk HEC metrics format event
 func (g *DatabaseMetricsGenerator) buildMetricEvent(metricName string, value float64, dimensions map[string]string, timestamp time.Time) map[string]interface{} {
+	value = Scenarios.Apply(metricName, value, dimensions, timestamp)
+
 	fields := map[string]interface{}{
 		"metric_name": metricName,
 		"_value":      value,
@@ -241,7 +249,7 @@ func (g *DatabaseMetricsGenerator) generateQueryPerformance(overrides map[string
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -338,7 +346,7 @@ func (g *DatabaseMetricsGenerator) generateConnections(overrides map[string]inte
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -429,7 +437,7 @@ func (g *DatabaseMetricsGenerator) generateBufferPool(overrides map[string]inter
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -499,7 +507,7 @@ func (g *DatabaseMetricsGenerator) generateTransactions(overrides map[string]int
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -521,11 +529,8 @@ func (g *DatabaseMetricsGenerator) generateReplication(overrides map[string]inte
 	env := g.randomEnvironment()
 	cluster := g.randomCluster()
 
-	// Replication lag (0 to 60 seconds, usually low)
-	lagSeconds := float64(g.RandomInt(0, 10)) + float64(g.RandomInt(0, 999))/1000
-	if g.RandomInt(0, 20) > 18 { // 10% chance of higher lag
-		lagSeconds = float64(g.RandomInt(10, 60))
-	}
+	// Replication lag, usually a few seconds and continuous per host
+	lagSeconds := Series.Next(g.rnd, host, "db.replication.lag_seconds", SeriesSpec{Mean: 2, Min: 0, Max: 3600, Volatility: 0.6, Reversion: 0.7, Drift: 1.5})
 
 	dimensions := map[string]string{
 		"host":        host,
@@ -591,7 +596,7 @@ func (g *DatabaseMetricsGenerator) generateReplication(overrides map[string]inte
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -684,7 +689,7 @@ func (g *DatabaseMetricsGenerator) generateLocks(overrides map[string]interface{
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -808,7 +813,7 @@ func (g *DatabaseMetricsGenerator) generateTablespace(overrides map[string]inter
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
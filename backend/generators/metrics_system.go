@@ -1,8 +1,8 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -121,9 +121,16 @@ func (g *SystemMetricsGenerator) Generate(templateID string, overrides map[strin
 	}
 }
 
+// systemMetricPrefixes are the metric name prefixes this generator emits, used to
+// steer host selection towards scenario targets
+var systemMetricPrefixes = []string{"cpu.", "disk.", "diskio.", "fan.", "memory.", "net.", "swap.", "system.", "temperature."}
+
 func (g *SystemMetricsGenerator) randomHost() string {
+	if host, ok := Scenarios.TargetValue(g.rnd, "host", systemMetricPrefixes...); ok {
+		return qualifyHost(host)
+	}
 	prefixes := []string{"web", "app", "db", "cache", "api", "worker", "proxy", "monitor"}
-	return fmt.Sprintf("%s-%02d.prod.internal", g.RandomChoice(prefixes), g.RandomInt(1, 20))
+	return ServerName(fmt.Sprintf("%s-%02d", g.RandomChoice(prefixes), g.RandomInt(1, 20)))
 }
 
 func (g *SystemMetricsGenerator) randomRegion() string {
@@ -141,8 +148,19 @@ func (g *SystemMetricsGenerator) randomDatacenter() string {
 	return g.RandomChoice(dcs)
 }
 
+// processStart anchors uptime so it grows with wall-clock time
+var processStart = time.Now()
+
+// Series specs shared by templates that report the same underlying signal
+var (
+	cpuTotalSeries      = SeriesSpec{Mean: 35, Min: 1, Max: 100, Volatility: 4, Reversion: 0.8, Drift: 20}
+	cpuCoreOffsetSeries = SeriesSpec{Mean: 0, Min: -30, Max: 30, Volatility: 3, Reversion: 0.6, Drift: 10}
+)
+
 // buildMetricEvent creates a Splunk HEC metrics format event
 func (g *SystemMetricsGenerator) buildMetricEvent(metricName string, value float64, dimensions map[string]string, timestamp time.Time) map[string]interface{} {
+	value = Scenarios.Apply(metricName, value, dimensions, timestamp)
+
 	fields := map[string]interface{}{
 		"metric_name": metricName,
 		"_value":      value,
@@ -163,24 +181,24 @@ func (g *SystemMetricsGenerator) buildMetricEvent(metricName string, value float
 func (g *SystemMetricsGenerator) generateCPU(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
 	host := g.randomHost()
-	region := g.randomRegion()
-	env := g.randomEnvironment()
+	profile := ProfileForHost(host)
+	region := profile.Region
+	env := profile.Environment
 
-	// Generate metrics for multiple CPU cores
-	numCores := g.RandomInt(4, 32)
+	// Generate metrics for multiple CPU cores; each core tracks the host's
+	// overall utilisation with its own persistent offset
+	numCores := profile.Cores
 	metrics := make([]map[string]interface{}, 0)
 
+	hostUsage := Series.Next(g.rnd, host, "cpu.percent.total", cpuTotalSeries)
 	totalUsage := 0.0
 	for i := 0; i < numCores; i++ {
-		// Simulate realistic CPU patterns - some cores busier than others
-		baseUsage := float64(g.RandomInt(5, 40))
-		if g.RandomInt(0, 10) > 7 { // 30% chance of high usage
-			baseUsage = float64(g.RandomInt(60, 95))
-		}
+		offset := Series.Next(g.rnd, host, fmt.Sprintf("cpu.offset.cpu%d", i), cpuCoreOffsetSeries)
+		coreUsage := clamp(hostUsage+offset, 0, 100)
 
 		coreMetric := g.buildMetricEvent(
 			"cpu.percent",
-			baseUsage,
+			coreUsage,
 			map[string]string{
 				"host":        host,
 				"region":      region,
@@ -190,13 +208,14 @@ func (g *SystemMetricsGenerator) generateCPU(overrides map[string]interface{}) (
 			timestamp,
 		)
 		metrics = append(metrics, coreMetric)
-		totalUsage += baseUsage
+		totalUsage += coreUsage
 	}
 
 	// Add total CPU metric
+	totalPct := totalUsage / float64(numCores)
 	totalMetric := g.buildMetricEvent(
 		"cpu.percent.total",
-		totalUsage/float64(numCores),
+		totalPct,
 		map[string]string{
 			"host":        host,
 			"region":      region,
@@ -206,11 +225,12 @@ func (g *SystemMetricsGenerator) generateCPU(overrides map[string]interface{}) (
 	)
 	metrics = append(metrics, totalMetric)
 
-	// Add system/user/idle breakdown
-	userPct := float64(g.RandomInt(10, 60))
-	sysPct := float64(g.RandomInt(5, 25))
-	idlePct := 100 - userPct - sysPct
-	iowaitPct := float64(g.RandomInt(0, 10))
+	// Add system/user/idle breakdown consistent with the total
+	iowaitPct := Series.Next(g.rnd, host, "cpu.iowait", SeriesSpec{Mean: 2, Min: 0, Max: 30, Volatility: 0.8, Reversion: 0.7, Drift: 2})
+	busy := math.Max(totalPct-iowaitPct, 0)
+	userPct := busy * 0.72
+	sysPct := busy - userPct
+	idlePct := math.Max(100-userPct-sysPct-iowaitPct, 0)
 
 	for metricName, value := range map[string]float64{
 		"cpu.user":   userPct,
@@ -235,7 +255,7 @@ func (g *SystemMetricsGenerator) generateCPU(overrides map[string]interface{}) (
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -251,21 +271,19 @@ func (g *SystemMetricsGenerator) generateCPU(overrides map[string]interface{}) (
 func (g *SystemMetricsGenerator) generateMemory(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
 	host := g.randomHost()
-	region := g.randomRegion()
-	env := g.randomEnvironment()
+	profile := ProfileForHost(host)
+	region := profile.Region
+	env := profile.Environment
 
-	// Total memory in GB (8GB to 256GB)
-	totalGB := float64(g.RandomChoice([]string{"8", "16", "32", "64", "128", "256"})[0]-'0') * 8
-	if totalGB == 0 {
-		totalGB = 16
-	}
+	// Total memory in GB (8GB to 256GB), fixed per host
+	totalGB := float64(profile.MemoryGB)
 
 	totalBytes := totalGB * 1024 * 1024 * 1024
-	usedPercent := float64(g.RandomInt(30, 85))
+	usedPercent := Series.Next(g.rnd, host, "memory.percent", SeriesSpec{Mean: 55, Min: 5, Max: 98, Volatility: 1.5, Reversion: 0.9, Drift: 25})
 	usedBytes := totalBytes * usedPercent / 100
 	freeBytes := totalBytes - usedBytes
-	cachedBytes := totalBytes * float64(g.RandomInt(10, 30)) / 100
-	buffersBytes := totalBytes * float64(g.RandomInt(2, 8)) / 100
+	cachedBytes := math.Min(totalBytes*Series.Next(g.rnd, host, "memory.cached_percent", SeriesSpec{Mean: 20, Min: 2, Max: 40, Volatility: 1, Reversion: 0.85, Drift: 8})/100, freeBytes)
+	buffersBytes := math.Min(totalBytes*Series.Next(g.rnd, host, "memory.buffers_percent", SeriesSpec{Mean: 5, Min: 1, Max: 10, Volatility: 0.3, Reversion: 0.85, Drift: 2})/100, freeBytes-cachedBytes)
 
 	dimensions := map[string]string{
 		"host":        host,
@@ -285,7 +303,7 @@ func (g *SystemMetricsGenerator) generateMemory(overrides map[string]interface{}
 
 	// Swap metrics
 	swapTotal := totalBytes / 2
-	swapUsedPercent := float64(g.RandomInt(0, 30))
+	swapUsedPercent := Series.Next(g.rnd, host, "swap.percent", SeriesSpec{Mean: 8, Min: 0, Max: 100, Volatility: 0.5, Reversion: 0.95, Drift: 8})
 	swapUsed := swapTotal * swapUsedPercent / 100
 
 	metrics = append(metrics,
@@ -302,7 +320,7 @@ func (g *SystemMetricsGenerator) generateMemory(overrides map[string]interface{}
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -318,8 +336,9 @@ func (g *SystemMetricsGenerator) generateMemory(overrides map[string]interface{}
 func (g *SystemMetricsGenerator) generateDiskSpace(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
 	host := g.randomHost()
-	region := g.randomRegion()
-	env := g.randomEnvironment()
+	profile := ProfileForHost(host)
+	region := profile.Region
+	env := profile.Environment
 
 	mountPoints := []struct {
 		path    string
@@ -338,15 +357,17 @@ func (g *SystemMetricsGenerator) generateDiskSpace(overrides map[string]interfac
 
 	for _, mp := range mountPoints {
 		totalBytes := float64(mp.sizeGB) * 1024 * 1024 * 1024
-		usedPercent := float64(g.RandomInt(20, 90))
-		// Data volumes tend to be fuller
+		// Disks fill slowly; data and log volumes tend to be fuller
+		diskSpec := SeriesSpec{Mean: 55, Min: 1, Max: 99.9, Volatility: 0.05, Reversion: 0.99, Drift: 35, Trend: 0.01}
 		if mp.purpose == "data" || mp.purpose == "logs" {
-			usedPercent = float64(g.RandomInt(50, 95))
+			diskSpec.Mean = 72
+			diskSpec.Drift = 22
 		}
+		usedPercent := Series.Next(g.rnd, host, "disk.percent:"+mp.path, diskSpec)
 		usedBytes := totalBytes * usedPercent / 100
 		freeBytes := totalBytes - usedBytes
-		inodesTotal := float64(g.RandomInt(1000000, 10000000))
-		inodesUsedPercent := float64(g.RandomInt(5, 40))
+		inodesTotal := float64(mp.sizeGB) * 65536
+		inodesUsedPercent := Series.Next(g.rnd, host, "disk.inodes.percent:"+mp.path, SeriesSpec{Mean: 20, Min: 1, Max: 99, Volatility: 0.05, Reversion: 0.99, Drift: 15})
 		inodesUsed := inodesTotal * inodesUsedPercent / 100
 
 		dimensions := map[string]string{
@@ -376,7 +397,7 @@ func (g *SystemMetricsGenerator) generateDiskSpace(overrides map[string]interfac
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -436,7 +457,7 @@ func (g *SystemMetricsGenerator) generateDiskIO(overrides map[string]interface{}
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -508,7 +529,7 @@ func (g *SystemMetricsGenerator) generateNetwork(overrides map[string]interface{
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -524,14 +545,17 @@ func (g *SystemMetricsGenerator) generateNetwork(overrides map[string]interface{
 func (g *SystemMetricsGenerator) generateLoad(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
 	host := g.randomHost()
-	region := g.randomRegion()
-	env := g.randomEnvironment()
-
-	numCores := g.RandomInt(4, 32)
-	// Load averages relative to number of cores
-	load1 := float64(numCores) * (float64(g.RandomInt(10, 150)) / 100)
-	load5 := float64(numCores) * (float64(g.RandomInt(10, 120)) / 100)
-	load15 := float64(numCores) * (float64(g.RandomInt(10, 100)) / 100)
+	profile := ProfileForHost(host)
+	region := profile.Region
+	env := profile.Environment
+
+	numCores := profile.Cores
+	// Load tracks the host's CPU utilisation; longer averages lag behind
+	cpuPct := Series.Next(g.rnd, host, "cpu.percent.total", cpuTotalSeries)
+	load1 := math.Max(float64(numCores)*cpuPct/100*g.RandomGaussian(1.1, 0.08), 0)
+	load5 := Series.Smooth(host, "system.load5", load1, 0.3)
+	load15 := Series.Smooth(host, "system.load15", load1, 0.1)
+	running := math.Max(math.Round(load1), 1)
 
 	dimensions := map[string]string{
 		"host":        host,
@@ -545,10 +569,10 @@ func (g *SystemMetricsGenerator) generateLoad(overrides map[string]interface{})
 		g.buildMetricEvent("system.load15", load15, dimensions, timestamp),
 		g.buildMetricEvent("system.cpu_count", float64(numCores), dimensions, timestamp),
 		g.buildMetricEvent("system.processes.total", float64(g.RandomInt(100, 500)), dimensions, timestamp),
-		g.buildMetricEvent("system.processes.running", float64(g.RandomInt(1, 20)), dimensions, timestamp),
+		g.buildMetricEvent("system.processes.running", running, dimensions, timestamp),
 		g.buildMetricEvent("system.processes.sleeping", float64(g.RandomInt(80, 400)), dimensions, timestamp),
 		g.buildMetricEvent("system.processes.zombie", float64(g.RandomInt(0, 3)), dimensions, timestamp),
-		g.buildMetricEvent("system.uptime_seconds", float64(g.RandomInt(3600, 31536000)), dimensions, timestamp),
+		g.buildMetricEvent("system.uptime_seconds", Series.Counter(host, "system.uptime_seconds", float64(g.RandomInt(3600, 31536000)), 0)+time.Since(processStart).Seconds(), dimensions, timestamp),
 		g.buildMetricEvent("system.context_switches", float64(g.RandomInt(10000, 1000000)), dimensions, timestamp),
 		g.buildMetricEvent("system.interrupts", float64(g.RandomInt(5000, 500000)), dimensions, timestamp),
 	}
@@ -562,7 +586,7 @@ func (g *SystemMetricsGenerator) generateLoad(overrides map[string]interface{})
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -664,7 +688,7 @@ func (g *SystemMetricsGenerator) generateTemperature(overrides map[string]interf
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
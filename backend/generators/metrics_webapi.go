@@ -1,8 +1,8 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -50,6 +50,20 @@ func (g *WebAPIMetricsGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "json",
 			Description: "Request latency percentiles (p50, p90, p95, p99)",
 			Sourcetype:  "metrics",
+			Fields: []models.EventField{
+				{
+					Name:         "http.latency.p50_ms",
+					Type:         "number",
+					Description:  "Median latency in ms of most endpoints, before jitter; search and checkout are slower",
+					Distribution: webAPILatency,
+				},
+				{
+					Name:         "http.latency.overall.p50_ms",
+					Type:         "number",
+					Description:  "Median latency in ms across endpoints",
+					Distribution: webAPIOverallLatency,
+				},
+			},
 		},
 		{
 			ID:          "throughput",
@@ -105,7 +119,7 @@ func (g *WebAPIMetricsGenerator) Generate(templateID string, overrides map[strin
 	case "http_status":
 		return g.generateHTTPStatus(overrides)
 	case "latency":
-		return g.generateLatency(overrides)
+		return g.generateLatency(templateID, overrides)
 	case "throughput":
 		return g.generateThroughput(overrides)
 	case "bandwidth":
@@ -121,31 +135,52 @@ func (g *WebAPIMetricsGenerator) Generate(templateID string, overrides map[strin
 	}
 }
 
+// webVhostNames are the sites served by the web tier, in the theme's domain
+var webVhostNames = []string{"api", "www", "app", "mobile-api", "admin"}
+
+// webVhosts returns the sites served by the web tier, shared by the web/API
+// metrics and the web server logs
+func webVhosts() []string {
+	vhosts := make([]string, len(webVhostNames))
+	for i, name := range webVhostNames {
+		vhosts[i] = publicHost(name)
+	}
+	return vhosts
+}
+
+// webEndpoints are the API routes served by the web tier
+var webEndpoints = []string{
+	"/api/v1/users",
+	"/api/v1/orders",
+	"/api/v1/products",
+	"/api/v1/cart",
+	"/api/v1/checkout",
+	"/api/v1/search",
+	"/api/v1/auth/login",
+	"/api/v1/auth/logout",
+	"/api/v2/graphql",
+	"/health",
+	"/metrics",
+}
+
+// webAPIMetricPrefixes are the metric name prefixes this generator emits, used to
+// steer host selection towards scenario targets
+var webAPIMetricPrefixes = []string{"cache.", "http.", "ssl.", "upstream."}
+
 func (g *WebAPIMetricsGenerator) randomHost() string {
+	if host, ok := Scenarios.TargetValue(g.rnd, "host", webAPIMetricPrefixes...); ok {
+		return qualifyHost(host)
+	}
 	prefixes := []string{"web", "api", "gateway", "edge", "lb", "cdn"}
-	return fmt.Sprintf("%s-%02d.prod.internal", g.RandomChoice(prefixes), g.RandomInt(1, 10))
+	return ServerName(fmt.Sprintf("%s-%02d", g.RandomChoice(prefixes), g.RandomInt(1, 10)))
 }
 
 func (g *WebAPIMetricsGenerator) randomVirtualHost() string {
-	vhosts := []string{"api.example.com", "www.example.com", "app.example.com", "mobile-api.example.com", "admin.example.com"}
-	return g.RandomChoice(vhosts)
+	return g.ZipfChoice(webVhosts())
 }
 
 func (g *WebAPIMetricsGenerator) randomEndpoint() string {
-	endpoints := []string{
-		"/api/v1/users",
-		"/api/v1/orders",
-		"/api/v1/products",
-		"/api/v1/cart",
-		"/api/v1/checkout",
-		"/api/v1/search",
-		"/api/v1/auth/login",
-		"/api/v1/auth/logout",
-		"/api/v2/graphql",
-		"/health",
-		"/metrics",
-	}
-	return g.RandomChoice(endpoints)
+	return g.ZipfChoice(webEndpoints)
 }
 
 func (g *WebAPIMetricsGenerator) randomRegion() string {
@@ -160,6 +195,8 @@ func (g *WebAPIMetricsGenerator) randomEnvironment() string {
 
 // buildMetricEvent creates a Splunk HEC metrics format event
 func (g *WebAPIMetricsGenerator) buildMetricEvent(metricName string, value float64, dimensions map[string]string, timestamp time.Time) map[string]interface{} {
+	value = Scenarios.Apply(metricName, value, dimensions, timestamp)
+
 	fields := map[string]interface{}{
 		"metric_name": metricName,
 		"_value":      value,
@@ -272,7 +309,7 @@ func (g *WebAPIMetricsGenerator) generateHTTPStatus(overrides map[string]interfa
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -285,7 +322,13 @@ func (g *WebAPIMetricsGenerator) generateHTTPStatus(overrides map[string]interfa
 	}, nil
 }
 
-func (g *WebAPIMetricsGenerator) generateLatency(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+// Median latencies in ms, unless the template declares otherwise
+var (
+	webAPILatency        = &models.DistributionSpec{Type: DistLogNormal, Median: 25, Sigma: 0.35}
+	webAPIOverallLatency = &models.DistributionSpec{Type: DistLogNormal, Median: 40, Sigma: 0.3}
+)
+
+func (g *WebAPIMetricsGenerator) generateLatency(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
 	host := g.randomHost()
 	vhost := g.randomVirtualHost()
@@ -298,15 +341,15 @@ func (g *WebAPIMetricsGenerator) generateLatency(overrides map[string]interface{
 
 	for _, endpoint := range endpoints {
 		for _, method := range methods {
-			// Base latency varies by endpoint
+			// Base latency varies by endpoint and is long-tailed
 			var baseLatency float64
 			switch endpoint {
 			case "/api/v1/search":
-				baseLatency = float64(g.RandomInt(50, 200))
+				baseLatency = g.RandomLogNormal(100, 0.4)
 			case "/api/v1/checkout":
-				baseLatency = float64(g.RandomInt(100, 500))
+				baseLatency = g.RandomLogNormal(220, 0.45)
 			default:
-				baseLatency = float64(g.RandomInt(10, 50))
+				baseLatency = g.declaredFloat(g, templateID, "http.latency.p50_ms", webAPILatency)
 			}
 
 			p50 := baseLatency + float64(g.RandomInt(0, 20))
@@ -352,7 +395,7 @@ func (g *WebAPIMetricsGenerator) generateLatency(overrides map[string]interface{
 		"environment": env,
 	}
 
-	overallP50 := float64(g.RandomInt(20, 80))
+	overallP50 := g.declaredFloat(g, templateID, "http.latency.overall.p50_ms", webAPIOverallLatency)
 	metrics = append(metrics,
 		g.buildMetricEvent("http.latency.overall.p50_ms", overallP50, overallDimensions, timestamp),
 		g.buildMetricEvent("http.latency.overall.p90_ms", overallP50*2.5, overallDimensions, timestamp),
@@ -368,7 +411,7 @@ func (g *WebAPIMetricsGenerator) generateLatency(overrides map[string]interface{
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -456,7 +499,7 @@ func (g *WebAPIMetricsGenerator) generateThroughput(overrides map[string]interfa
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -545,7 +588,7 @@ func (g *WebAPIMetricsGenerator) generateBandwidth(overrides map[string]interfac
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -620,10 +663,14 @@ func (g *WebAPIMetricsGenerator) generateSSL(overrides map[string]interface{}) (
 	}
 
 	// Certificate metrics
-	daysUntilExpiry := g.RandomInt(30, 365)
+	cert := g.serverCertificate(vhost, timestamp)
+	isValid := 1.0
+	if cert.Expired || cert.SelfSigned {
+		isValid = 0
+	}
 	metrics = append(metrics,
-		g.buildMetricEvent("ssl.certificate.days_until_expiry", float64(daysUntilExpiry), dimensions, timestamp),
-		g.buildMetricEvent("ssl.certificate.is_valid", 1, dimensions, timestamp),
+		g.buildMetricEvent("ssl.certificate.days_until_expiry", math.Floor(cert.NotAfter.Sub(timestamp).Hours()/24), dimensions, timestamp),
+		g.buildMetricEvent("ssl.certificate.is_valid", isValid, dimensions, timestamp),
 	)
 
 	fields := map[string]interface{}{
@@ -635,7 +682,7 @@ func (g *WebAPIMetricsGenerator) generateSSL(overrides map[string]interface{}) (
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -724,7 +771,7 @@ func (g *WebAPIMetricsGenerator) generateUpstream(overrides map[string]interface
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -832,7 +879,7 @@ func (g *WebAPIMetricsGenerator) generateCache(overrides map[string]interface{})
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
-	rawEvent, _ := json.MarshalIndent(metrics, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(metrics)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -187,19 +187,19 @@ type ADDataItem struct {
 func (g *MicrosoftADGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	switch templateID {
 	case "4720":
-		return g.generate4720(overrides)
+		return g.generate4720(time.Now().UTC(), overrides)
 	case "4722":
-		return g.generate4722(overrides)
+		return g.generate4722(time.Now().UTC(), overrides)
 	case "4723":
 		return g.generate4723(overrides)
 	case "4724":
 		return g.generate4724(overrides)
 	case "4725":
-		return g.generate4725(overrides)
+		return g.generate4725(time.Now().UTC(), overrides)
 	case "4726":
-		return g.generate4726(overrides)
+		return g.generate4726(time.Now().UTC(), overrides)
 	case "4728":
-		return g.generate4728(overrides)
+		return g.generate4728(time.Now().UTC(), overrides)
 	case "4729":
 		return g.generate4729(overrides)
 	case "4732":
@@ -216,19 +216,19 @@ func (g *MicrosoftADGenerator) Generate(templateID string, overrides map[string]
 // RandomDCName generates a random domain controller name
 func (g *MicrosoftADGenerator) RandomDCName() string {
 	sites := []string{"DC1", "DC2", "PDC", "BDC"}
-	return fmt.Sprintf("%s.%s.local", g.RandomChoice(sites), g.RandomDomain())
+	return fmt.Sprintf("%s.%s", g.RandomChoice(sites), Theme().ADDomain)
 }
 
 // RandomOU generates a random OU path
 func (g *MicrosoftADGenerator) RandomOU() string {
 	ous := []string{
-		"OU=Users,DC=%s,DC=local",
-		"OU=Admins,OU=Users,DC=%s,DC=local",
-		"OU=Service Accounts,DC=%s,DC=local",
-		"OU=Employees,OU=Users,DC=%s,DC=local",
-		"OU=Contractors,OU=Users,DC=%s,DC=local",
+		"OU=Users",
+		"OU=Admins,OU=Users",
+		"OU=Service Accounts",
+		"OU=Employees,OU=Users",
+		"OU=Contractors,OU=Users",
 	}
-	return fmt.Sprintf(g.RandomChoice(ous), g.RandomDomain())
+	return g.RandomChoice(ous) + "," + domainDN()
 }
 
 // RandomGroupName generates a random group name
@@ -282,9 +282,9 @@ func (g *MicrosoftADGenerator) buildADEvent(eventID int, task int, timestamp tim
 }
 
 // generate4720 creates a user account created event
-func (g *MicrosoftADGenerator) generate4720(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	now := time.Now().UTC()
-	newUser := g.RandomUsername()
+func (g *MicrosoftADGenerator) generate4720(now time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	person := g.RandomPerson()
+	newUser := person.SAMAccountName
 	domain := g.RandomDomain()
 
 	fields := map[string]interface{}{
@@ -297,8 +297,8 @@ func (g *MicrosoftADGenerator) generate4720(overrides map[string]interface{}) (*
 		"SubjectLogonId":    fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999)),
 		"PrivilegeList":     "-",
 		"SamAccountName":    newUser,
-		"DisplayName":       fmt.Sprintf("%s %s", g.RandomString(6), g.RandomString(8)),
-		"UserPrincipalName": fmt.Sprintf("%s@%s.local", newUser, domain),
+		"DisplayName":       person.DisplayName,
+		"UserPrincipalName": person.UPN,
 		"HomeDirectory":     "-",
 		"HomePath":          "-",
 		"ScriptPath":        "-",
@@ -319,7 +319,7 @@ func (g *MicrosoftADGenerator) generate4720(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4720, 13824, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -336,8 +336,7 @@ func (g *MicrosoftADGenerator) generate4720(overrides map[string]interface{}) (*
 }
 
 // generate4722 creates a user account enabled event
-func (g *MicrosoftADGenerator) generate4722(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	now := time.Now().UTC()
+func (g *MicrosoftADGenerator) generate4722(now time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	domain := g.RandomDomain()
 
 	fields := map[string]interface{}{
@@ -353,7 +352,7 @@ func (g *MicrosoftADGenerator) generate4722(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4722, 13824, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -389,7 +388,7 @@ func (g *MicrosoftADGenerator) generate4723(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4723, 13824, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -423,7 +422,7 @@ func (g *MicrosoftADGenerator) generate4724(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4724, 13824, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -440,8 +439,7 @@ func (g *MicrosoftADGenerator) generate4724(overrides map[string]interface{}) (*
 }
 
 // generate4725 creates a user account disabled event
-func (g *MicrosoftADGenerator) generate4725(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	now := time.Now().UTC()
+func (g *MicrosoftADGenerator) generate4725(now time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	domain := g.RandomDomain()
 
 	fields := map[string]interface{}{
@@ -457,7 +455,7 @@ func (g *MicrosoftADGenerator) generate4725(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4725, 13824, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -474,8 +472,7 @@ func (g *MicrosoftADGenerator) generate4725(overrides map[string]interface{}) (*
 }
 
 // generate4726 creates a user account deleted event
-func (g *MicrosoftADGenerator) generate4726(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	now := time.Now().UTC()
+func (g *MicrosoftADGenerator) generate4726(now time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	domain := g.RandomDomain()
 
 	fields := map[string]interface{}{
@@ -492,7 +489,7 @@ func (g *MicrosoftADGenerator) generate4726(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4726, 13824, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -509,8 +506,7 @@ func (g *MicrosoftADGenerator) generate4726(overrides map[string]interface{}) (*
 }
 
 // generate4728 creates a member added to global group event
-func (g *MicrosoftADGenerator) generate4728(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	now := time.Now().UTC()
+func (g *MicrosoftADGenerator) generate4728(now time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	domain := g.RandomDomain()
 
 	fields := map[string]interface{}{
@@ -529,7 +525,7 @@ func (g *MicrosoftADGenerator) generate4728(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4728, 13826, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -566,7 +562,7 @@ func (g *MicrosoftADGenerator) generate4729(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4729, 13826, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -604,7 +600,7 @@ func (g *MicrosoftADGenerator) generate4732(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4732, 13826, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -637,7 +633,7 @@ func (g *MicrosoftADGenerator) generate4740(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4740, 13824, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -671,7 +667,7 @@ func (g *MicrosoftADGenerator) generate4767(overrides map[string]interface{}) (*
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildADEvent(4767, 13824, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -113,12 +112,13 @@ func (g *MicrosoftDefenderGenerator) randomDeviceName() string {
 	return fmt.Sprintf("%s-%s", g.RandomChoice(prefixes), g.RandomString(6))
 }
 
-func (g *MicrosoftDefenderGenerator) randomSHA256() string {
-	return g.RandomString(64)
-}
-
-func (g *MicrosoftDefenderGenerator) randomSHA1() string {
-	return g.RandomString(40)
+// setFile sets the name, folder and hashes of the file an event is about
+func (g *MicrosoftDefenderGenerator) setFile(event map[string]interface{}, f CatalogFile) {
+	event["FileName"] = f.Name
+	event["FolderPath"] = f.Dir()
+	event["SHA256"] = g.FileHash(f, models.IOCTypeSHA256)
+	event["SHA1"] = g.FileHash(f, models.IOCTypeSHA1)
+	event["MD5"] = g.FileHash(f, models.IOCTypeMD5)
 }
 
 func (g *MicrosoftDefenderGenerator) buildBaseEvent(actionType string) map[string]interface{} {
@@ -167,7 +167,7 @@ func (g *MicrosoftDefenderGenerator) generateAlert(overrides map[string]interfac
 	event["AccountDomain"] = g.RandomDomain()
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -184,10 +184,7 @@ func (g *MicrosoftDefenderGenerator) generateProcessCreation(overrides map[strin
 	timestamp := time.Now()
 	event := g.buildBaseEvent("ProcessCreated")
 
-	event["FileName"] = g.RandomProcessName()
-	event["FolderPath"] = g.RandomPath()
-	event["SHA256"] = g.randomSHA256()
-	event["SHA1"] = g.randomSHA1()
+	g.setFile(event, Files.ForName(g.RandomProcessName()))
 	event["ProcessId"] = g.RandomInt(1000, 65535)
 	event["ProcessCommandLine"] = fmt.Sprintf("%s %s", g.RandomPath(), g.RandomChoice([]string{"-h", "--help", "/c", "-encodedcommand"}))
 	event["ProcessCreationTime"] = timestamp.Format(time.RFC3339)
@@ -201,7 +198,7 @@ func (g *MicrosoftDefenderGenerator) generateProcessCreation(overrides map[strin
 	event["LogonId"] = g.RandomInt(100000, 999999)
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -229,7 +226,7 @@ func (g *MicrosoftDefenderGenerator) generateNetworkConnection(overrides map[str
 	event["InitiatingProcessAccountName"] = g.RandomUsername()
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -246,14 +243,13 @@ func (g *MicrosoftDefenderGenerator) generateFileCreation(overrides map[string]i
 	timestamp := time.Now()
 	event := g.buildBaseEvent("FileCreated")
 
-	extensions := []string{".exe", ".dll", ".ps1", ".bat", ".vbs", ".docx", ".xlsx", ".pdf"}
-	filename := fmt.Sprintf("%s%s", g.RandomString(8), g.RandomChoice(extensions))
+	file := g.RandomFile()
+	if g.rnd.float64() < 0.1 {
+		file = g.RandomMaliciousFile()
+	}
 
-	event["FileName"] = filename
-	event["FolderPath"] = g.RandomPath()
-	event["SHA256"] = g.randomSHA256()
-	event["SHA1"] = g.randomSHA1()
-	event["FileSize"] = g.RandomInt(1024, 10485760)
+	g.setFile(event, file)
+	event["FileSize"] = file.Size
 	event["InitiatingProcessFileName"] = g.RandomChoice([]string{"explorer.exe", "chrome.exe", "powershell.exe", "winword.exe"})
 	event["InitiatingProcessFolderPath"] = g.RandomPath()
 	event["InitiatingProcessId"] = g.RandomInt(1000, 65535)
@@ -261,7 +257,7 @@ func (g *MicrosoftDefenderGenerator) generateFileCreation(overrides map[string]i
 	event["InitiatingProcessAccountDomain"] = g.RandomDomain()
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -290,7 +286,7 @@ func (g *MicrosoftDefenderGenerator) generateLogonEvent(overrides map[string]int
 	event["RemoteDeviceName"] = g.randomDeviceName()
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -317,18 +313,17 @@ func (g *MicrosoftDefenderGenerator) generateMalwareDetection(overrides map[stri
 	}
 
 	event["ThreatName"] = g.RandomChoice(malwareNames)
-	event["ThreatFamily"] = g.RandomChoice([]string{"AgentTesla", "Emotet", "CobaltStrike", "Mimikatz", "WannaCry"})
+	file := g.RandomMaliciousFile(FileExecutable, FileLibrary, FileScript, FileDocument)
+	event["ThreatFamily"] = file.Family
 	event["Severity"] = g.RandomChoice([]string{"Low", "Medium", "High", "Severe"})
 	event["Category"] = g.RandomChoice([]string{"Trojan", "Ransomware", "Backdoor", "Exploit", "HackTool"})
-	event["FileName"] = g.RandomProcessName()
-	event["FolderPath"] = g.RandomPath()
-	event["SHA256"] = g.randomSHA256()
+	g.setFile(event, file)
 	event["ActionType"] = g.RandomChoice([]string{"Quarantine", "Remove", "Clean", "Block"})
 	event["InitialDetectionSource"] = g.RandomChoice([]string{"RealTimeProtection", "CloudProtection", "User", "IOAV"})
 	event["AccountName"] = g.RandomUsername()
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -0,0 +1,528 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// MicrosoftSentinelGenerator generates Microsoft Sentinel SecurityAlert and
+// SecurityIncident rows as Log Analytics stores them, including the rows an
+// incident gets as it is assigned and closed, so triage automation and MTTR
+// workbooks can be tested without analytics rules
+type MicrosoftSentinelGenerator struct {
+	BaseGenerator
+
+	mu        sync.Mutex
+	alerts    []*sentinelAlert    // Alerts not yet grouped into an incident
+	incidents []*sentinelIncident // Incidents still open, oldest first
+	number    int                 // Last incident number
+}
+
+func init() {
+	Register(&MicrosoftSentinelGenerator{number: 1000})
+}
+
+// maxOpenSentinel bounds the alerts and incidents kept for the incidents
+// that follow; the oldest are forgotten first
+const maxOpenSentinel = 500
+
+// The Sentinel workspace the alerts and incidents belong to
+const (
+	sentinelTenantID       = "5c1e7a90-3b2d-4e8f-a6c4-9d0b1f2e3a47"
+	sentinelSubscriptionID = "3b7c9e2a-5d41-4f86-9a0e-1c2d3e4f5a6b"
+	sentinelResourceGroup  = "soc-rg"
+	sentinelWorkspace      = "soc-sentinel"
+	sentinelWorkspaceID    = "8e3f1a2b-4c5d-4e6f-8a9b-0c1d2e3f4a5b"
+)
+
+// sentinelRule is a scheduled analytics rule and the entities its alerts
+// carry: account, host, ip or file, of the catalog kind fileKind
+type sentinelRule struct {
+	id          string
+	name        string
+	severity    string
+	description string
+	tactics     []string
+	techniques  []string
+	entities    []string
+	query       string
+	fileKind    string
+}
+
+// sentinelRules are analytics rules from the Sentinel content hub
+var sentinelRules = []sentinelRule{
+	{"a2f1d3c4-7b8e-4f9a-8c1d-2e3f4a5b6c01", "Brute force attack against Azure Portal", "Medium",
+		"Identifies evidence of brute force activity against Azure Portal by highlighting multiple authentication failures and by a successful authentication within a given time window.",
+		[]string{"CredentialAccess"}, []string{"T1110"}, []string{"account", "ip"},
+		"SigninLogs | where AppDisplayName has \"Azure Portal\" | summarize FailedLogonCount = countif(ResultType != \"0\") by UserPrincipalName, IPAddress", ""},
+	{"a2f1d3c4-7b8e-4f9a-8c1d-2e3f4a5b6c02", "Rare RDP Connections", "Medium",
+		"Identifies when an RDP connection is new or rare related to any logon type by a given account today based on comparison with the previous 14 days.",
+		[]string{"LateralMovement"}, []string{"T1021.001"}, []string{"account", "host", "ip"},
+		"SecurityEvent | where EventID == 4624 and LogonType == 10 | summarize count() by Account, Computer, IpAddress", ""},
+	{"a2f1d3c4-7b8e-4f9a-8c1d-2e3f4a5b6c03", "Suspicious number of resource creation or deployment activities", "Medium",
+		"Indicates when an anomalous number of VM creations or deployment activities occur in Azure via the AzureActivity log.",
+		[]string{"Impact"}, []string{"T1496"}, []string{"account", "ip"},
+		"AzureActivity | where OperationNameValue in~ (\"microsoft.compute/virtualmachines/write\", \"microsoft.resources/deployments/write\") | summarize count() by Caller, CallerIpAddress", ""},
+	{"a2f1d3c4-7b8e-4f9a-8c1d-2e3f4a5b6c04", "Multiple Password Reset by user", "Low",
+		"Detects when a user resets multiple passwords in a short time, which can indicate account takeover or misuse of an administrative account.",
+		[]string{"InitialAccess", "CredentialAccess"}, []string{"T1078", "T1110"}, []string{"account"},
+		"AuditLogs | where OperationName has \"reset password\" | summarize count() by tostring(InitiatedBy.user.userPrincipalName)", ""},
+	{"a2f1d3c4-7b8e-4f9a-8c1d-2e3f4a5b6c05", "TI map IP entity to SigninLogs", "Medium",
+		"Identifies a match in SigninLogs from any IP IOC from threat intelligence.",
+		[]string{"CommandAndControl"}, []string{"T1071"}, []string{"account", "ip"},
+		"ThreatIntelIndicators | join kind=innerunique (SigninLogs) on $left.NetworkIP == $right.IPAddress", ""},
+	{"a2f1d3c4-7b8e-4f9a-8c1d-2e3f4a5b6c06", "Mass Download & copy to USB device by single user", "Medium",
+		"Looks for users who download many files from SharePoint or OneDrive and copy them to a USB drive within a short time.",
+		[]string{"Exfiltration"}, []string{"T1052"}, []string{"account", "host", "file"},
+		"OfficeActivity | where Operation == \"FileDownloaded\" | join (DeviceEvents | where ActionType == \"UsbDriveMounted\") on $left.UserId == $right.InitiatingProcessAccountUpn", FileDocument},
+	{"a2f1d3c4-7b8e-4f9a-8c1d-2e3f4a5b6c07", "New executable via Office FileUploaded Operation", "Low",
+		"Identifies when executable file types are uploaded to Office services such as SharePoint and OneDrive.",
+		[]string{"CommandAndControl", "LateralMovement"}, []string{"T1105", "T1570"}, []string{"account", "ip", "file"},
+		"OfficeActivity | where Operation =~ \"FileUploaded\" | where SourceFileExtension in~ (\"exe\", \"dll\", \"ps1\")", FileExecutable},
+}
+
+// sentinelAlert is an alert waiting to be grouped into an incident
+type sentinelAlert struct {
+	ID       string
+	Rule     *sentinelRule
+	Start    time.Time
+	End      time.Time
+	Entities []map[string]interface{}
+}
+
+// sentinelIncident is an open incident, kept for its update rows
+type sentinelIncident struct {
+	Name        string // IncidentName, a GUID
+	Number      int
+	Rule        *sentinelRule
+	AlertIDs    []string
+	Status      string // New or Active
+	Owner       map[string]interface{}
+	FirstActive time.Time
+	LastActive  time.Time
+	Created     time.Time
+}
+
+// sentinelClosings are the classifications and reasons incidents are
+// closed with, and comments analysts leave
+var sentinelClosings = []struct{ classification, reason, comment string }{
+	{"TruePositive", "SuspiciousActivity", "Confirmed malicious; credentials reset and sessions revoked"},
+	{"TruePositive", "SuspiciousActivity", "Contained the host and opened a ticket with the endpoint team"},
+	{"BenignPositive", "SuspiciousButExpected", "Activity confirmed by the user as part of a planned change"},
+	{"BenignPositive", "SuspiciousButExpected", "Penetration test in scope for this week"},
+	{"FalsePositive", "IncorrectAlertLogic", "Rule threshold too low for this service account; tuning request filed"},
+	{"FalsePositive", "InaccurateData", "Source IP misattributed by the proxy logs"},
+	{"Undetermined", "", "Not enough data to conclude; closing after review"},
+}
+
+// GetEventType returns the event type for Microsoft Sentinel
+func (g *MicrosoftSentinelGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "microsoft_sentinel",
+		Name:        "Microsoft Sentinel Incidents",
+		Category:    "siem",
+		Description: "Microsoft Sentinel SecurityAlert and SecurityIncident rows from scheduled analytics rules, through assignment and closure",
+		EventIDs:    []string{"SecurityAlert", "SecurityIncident"},
+	}
+}
+
+// GetTemplates returns available templates for Microsoft Sentinel
+func (g *MicrosoftSentinelGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "alert",
+			Name:        "Security Alert",
+			Category:    "microsoft_sentinel",
+			EventID:     "SecurityAlert",
+			Format:      "json",
+			Description: "Alert raised by a scheduled analytics rule",
+		},
+		{
+			ID:          "incident",
+			Name:        "Incident Created",
+			Category:    "microsoft_sentinel",
+			EventID:     "SecurityIncident",
+			Format:      "json",
+			Description: "New incident grouping a rule's pending alerts",
+		},
+		{
+			ID:          "incident_active",
+			Name:        "Incident Assigned",
+			Category:    "microsoft_sentinel",
+			EventID:     "SecurityIncident",
+			Format:      "json",
+			Description: "Incident assigned to an analyst and set to Active",
+		},
+		{
+			ID:          "incident_closed",
+			Name:        "Incident Closed",
+			Category:    "microsoft_sentinel",
+			EventID:     "SecurityIncident",
+			Format:      "json",
+			Description: "Incident closed with a classification and comment",
+		},
+	}
+}
+
+// Generate creates a Microsoft Sentinel alert or incident row
+func (g *MicrosoftSentinelGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "alert":
+		return g.generateAlert(overrides)
+	case "incident":
+		return g.generateIncident(overrides)
+	case "incident_active":
+		return g.generateAssigned(overrides)
+	case "incident_closed":
+		return g.generateClosed(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// newAlert raises an alert of a random rule over the last hour
+func (g *MicrosoftSentinelGenerator) newAlert(now time.Time) *sentinelAlert {
+	rule := &sentinelRules[g.RandomInt(0, len(sentinelRules)-1)]
+	end := now.Add(-time.Duration(g.RandomInt(1, 10)) * time.Minute)
+	return &sentinelAlert{
+		ID:       uuid.New().String(),
+		Rule:     rule,
+		Start:    end.Add(-time.Duration(g.RandomInt(5, 60)) * time.Minute),
+		End:      end,
+		Entities: g.entities(rule),
+	}
+}
+
+// entities builds the alert entities a rule maps, in Sentinel's entity
+// schema with $id references
+func (g *MicrosoftSentinelGenerator) entities(rule *sentinelRule) []map[string]interface{} {
+	user := Entities.RandomUser(g.rnd)
+	host := Entities.HostForUser(g.rnd, user.Username)
+	var entities []map[string]interface{}
+	for _, kind := range rule.entities {
+		entity := map[string]interface{}{"$id": strconv.Itoa(len(entities) + 2), "Type": kind}
+		switch kind {
+		case "account":
+			name, suffix, _ := strings.Cut(user.Email, "@")
+			entity["Name"] = name
+			entity["UPNSuffix"] = suffix
+			entity["AadUserId"] = uuid.NewSHA1(uuid.NameSpaceOID, []byte(user.Email)).String()
+		case "host":
+			entity["HostName"] = host.Hostname
+			entity["DnsDomain"] = strings.TrimPrefix(host.FQDN, strings.ToLower(host.Hostname)+".")
+			entity["OSFamily"] = "Unknown"
+			if host.Platform != "darwin" {
+				entity["OSFamily"] = capitalize(host.Platform)
+			}
+		case "ip":
+			entity["Address"] = Threats.RandomIP(g.rnd).IP
+		case "file":
+			file := g.RandomFile(rule.fileKind)
+			entity["Name"] = file.Name
+			entity["Directory"] = strings.TrimSuffix(file.Path, file.Name)
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+// compromisedEntity is the entity an alert is about: the host, or else the
+// account
+func compromisedEntity(entities []map[string]interface{}) string {
+	for _, e := range entities {
+		if e["Type"] == "host" {
+			return fmt.Sprint(e["HostName"])
+		}
+	}
+	for _, e := range entities {
+		if e["Type"] == "account" {
+			return fmt.Sprintf("%v@%v", e["Name"], e["UPNSuffix"])
+		}
+	}
+	return ""
+}
+
+// generateAlert creates a SecurityAlert row and keeps the alert for the
+// next incident of its rule
+func (g *MicrosoftSentinelGenerator) generateAlert(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	alert := g.newAlert(timestamp)
+	rule := alert.Rule
+
+	entities, _ := json.Marshal(alert.Entities)
+	techniques, _ := json.Marshal(rule.techniques)
+	ruleIDs, _ := json.Marshal([]string{rule.id})
+	extended, _ := json.Marshal(map[string]string{
+		"Query":                              rule.query,
+		"Query Period":                       "01:00:00",
+		"Trigger Operator":                   "GreaterThan",
+		"Trigger Threshold":                  "0",
+		"Analytic Rule Ids":                  string(ruleIDs),
+		"Analytic Rule Name":                 rule.name,
+		"Event Grouping":                     "SingleAlert",
+		"Search Query Results Overall Count": strconv.Itoa(g.RandomInt(1, 50)),
+		"Data Sources":                       "[]",
+		"ProcessedBySentinel":                "True",
+		"Alert generation status":            "Full alert created",
+	})
+
+	fields := map[string]interface{}{
+		"TenantId":                sentinelTenantID,
+		"TimeGenerated":           timestamp.UTC().Format(time.RFC3339Nano),
+		"DisplayName":             rule.name,
+		"AlertName":               rule.name,
+		"AlertSeverity":           rule.severity,
+		"Description":             rule.description,
+		"ProviderName":            "ASI Scheduled Alerts",
+		"VendorName":              "Microsoft",
+		"VendorOriginalId":        uuid.New().String(),
+		"SystemAlertId":           alert.ID,
+		"ResourceId":              "",
+		"SourceComputerId":        "",
+		"AlertType":               sentinelWorkspaceID + "_" + rule.id,
+		"ConfidenceLevel":         "",
+		"IsIncident":              false,
+		"StartTime":               alert.Start.UTC().Format(time.RFC3339Nano),
+		"EndTime":                 alert.End.UTC().Format(time.RFC3339Nano),
+		"ProcessingEndTime":       timestamp.UTC().Format(time.RFC3339Nano),
+		"RemediationSteps":        "",
+		"ExtendedProperties":      string(extended),
+		"Entities":                string(entities),
+		"SourceSystem":            "Detection",
+		"WorkspaceSubscriptionId": sentinelSubscriptionID,
+		"WorkspaceResourceGroup":  sentinelResourceGroup,
+		"ExtendedLinks":           "",
+		"ProductName":             "Azure Sentinel",
+		"ProductComponentName":    "Scheduled Alerts",
+		"AlertLink":               "",
+		"Status":                  "New",
+		"CompromisedEntity":       compromisedEntity(alert.Entities),
+		"Tactics":                 strings.Join(rule.tactics, ", "),
+		"Techniques":              string(techniques),
+		"Type":                    "SecurityAlert",
+	}
+
+	g.mu.Lock()
+	if len(g.alerts) >= maxOpenSentinel {
+		g.alerts = g.alerts[1:]
+	}
+	g.alerts = append(g.alerts, alert)
+	g.mu.Unlock()
+
+	return g.event("SecurityAlert", "azure:sentinel:alert", timestamp, fields, overrides)
+}
+
+// generateIncident creates a New incident for the pending alerts of one
+// rule, raising an alert for it when none is pending
+func (g *MicrosoftSentinelGenerator) generateIncident(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+
+	g.mu.Lock()
+	var alerts []*sentinelAlert
+	if len(g.alerts) > 0 {
+		rule := g.alerts[g.RandomInt(0, len(g.alerts)-1)].Rule
+		pending := g.alerts[:0]
+		for _, a := range g.alerts {
+			if a.Rule == rule {
+				alerts = append(alerts, a)
+			} else {
+				pending = append(pending, a)
+			}
+		}
+		g.alerts = pending
+	} else {
+		alerts = []*sentinelAlert{g.newAlert(timestamp)}
+	}
+	g.number++
+	incident := &sentinelIncident{
+		Name:        uuid.New().String(),
+		Number:      g.number,
+		Rule:        alerts[0].Rule,
+		Status:      "New",
+		Owner:       map[string]interface{}{"objectId": nil, "email": nil, "assignedTo": nil, "userPrincipalName": nil},
+		FirstActive: alerts[0].Start,
+		LastActive:  alerts[0].End,
+		Created:     timestamp,
+	}
+	for _, a := range alerts {
+		incident.AlertIDs = append(incident.AlertIDs, a.ID)
+		if a.Start.Before(incident.FirstActive) {
+			incident.FirstActive = a.Start
+		}
+		if a.End.After(incident.LastActive) {
+			incident.LastActive = a.End
+		}
+	}
+	if len(g.incidents) >= maxOpenSentinel {
+		g.incidents = g.incidents[1:]
+	}
+	g.incidents = append(g.incidents, incident)
+	g.mu.Unlock()
+
+	fields := g.incidentFields(incident, timestamp, "Incident created from alert rule")
+	return g.event("SecurityIncident", "azure:sentinel:incident", timestamp, fields, overrides)
+}
+
+// openIncident returns a random open incident with the given status, or
+// any open one when there is none; without open incidents one created a
+// while ago is made up. g.mu must be held.
+func (g *MicrosoftSentinelGenerator) openIncident(now time.Time, status string) *sentinelIncident {
+	var candidates []int
+	for i, inc := range g.incidents {
+		if inc.Status == status {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) > 0 {
+		return g.incidents[candidates[g.RandomInt(0, len(candidates)-1)]]
+	}
+	if len(g.incidents) > 0 {
+		return g.incidents[g.RandomInt(0, len(g.incidents)-1)]
+	}
+
+	created := now.Add(-time.Duration(g.RandomInt(15, 480)) * time.Minute)
+	alert := g.newAlert(created)
+	g.number++
+	incident := &sentinelIncident{
+		Name:        uuid.New().String(),
+		Number:      g.number,
+		Rule:        alert.Rule,
+		AlertIDs:    []string{alert.ID},
+		Status:      "New",
+		Owner:       map[string]interface{}{"objectId": nil, "email": nil, "assignedTo": nil, "userPrincipalName": nil},
+		FirstActive: alert.Start,
+		LastActive:  alert.End,
+		Created:     created,
+	}
+	g.incidents = append(g.incidents, incident)
+	return incident
+}
+
+// assign gives an incident to a random analyst and makes it Active
+func (g *MicrosoftSentinelGenerator) assign(incident *sentinelIncident) {
+	analyst := Entities.RandomUser(g.rnd)
+	incident.Status = "Active"
+	incident.Owner = map[string]interface{}{
+		"objectId":          uuid.NewSHA1(uuid.NameSpaceOID, []byte(analyst.Email)).String(),
+		"email":             analyst.Email,
+		"assignedTo":        analyst.FullName,
+		"userPrincipalName": analyst.Email,
+	}
+}
+
+// generateAssigned creates the row of a New incident being assigned
+func (g *MicrosoftSentinelGenerator) generateAssigned(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+
+	g.mu.Lock()
+	incident := g.openIncident(timestamp, "New")
+	g.assign(incident)
+	fields := g.incidentFields(incident, timestamp, fmt.Sprint(incident.Owner["assignedTo"]))
+	g.mu.Unlock()
+
+	return g.event("SecurityIncident", "azure:sentinel:incident", timestamp, fields, overrides)
+}
+
+// generateClosed creates the row of an Active incident being closed, with
+// its classification. A New incident is assigned on the way.
+func (g *MicrosoftSentinelGenerator) generateClosed(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	closing := sentinelClosings[g.RandomInt(0, len(sentinelClosings)-1)]
+
+	g.mu.Lock()
+	incident := g.openIncident(timestamp, "Active")
+	if incident.Owner["email"] == nil {
+		g.assign(incident)
+	}
+	incident.Status = "Closed"
+	for i, inc := range g.incidents {
+		if inc == incident {
+			g.incidents = append(g.incidents[:i], g.incidents[i+1:]...)
+			break
+		}
+	}
+	fields := g.incidentFields(incident, timestamp, fmt.Sprint(incident.Owner["assignedTo"]))
+	g.mu.Unlock()
+
+	fields["Classification"] = closing.classification
+	fields["ClassificationReason"] = closing.reason
+	fields["ClassificationComment"] = closing.comment
+	fields["ClosedTime"] = timestamp.UTC().Format(time.RFC3339Nano)
+	return g.event("SecurityIncident", "azure:sentinel:incident", timestamp, fields, overrides)
+}
+
+// incidentFields builds an incident's SecurityIncident row as of now.
+// Every change to an incident adds a row; CreatedTime stays, and
+// LastModifiedTime is the time of the change.
+func (g *MicrosoftSentinelGenerator) incidentFields(incident *sentinelIncident, now time.Time, modifiedBy string) map[string]interface{} {
+	rule := incident.Rule
+	url := fmt.Sprintf("https://portal.azure.com/#asset/Microsoft_Azure_Security_Insights/Incident/subscriptions/%s/resourceGroups/%s/providers/Microsoft.OperationalInsights/workspaces/%s/providers/Microsoft.SecurityInsights/Incidents/%s",
+		sentinelSubscriptionID, sentinelResourceGroup, sentinelWorkspace, incident.Name)
+	return map[string]interface{}{
+		"TenantId":               sentinelTenantID,
+		"TimeGenerated":          now.UTC().Format(time.RFC3339Nano),
+		"IncidentName":           incident.Name,
+		"IncidentNumber":         incident.Number,
+		"Title":                  rule.name,
+		"Description":            rule.description,
+		"Severity":               rule.severity,
+		"Status":                 incident.Status,
+		"Classification":         "",
+		"ClassificationReason":   "",
+		"ClassificationComment":  "",
+		"Owner":                  incident.Owner,
+		"ProviderName":           "Azure Sentinel",
+		"ProviderIncidentId":     strconv.Itoa(incident.Number),
+		"FirstActivityTime":      incident.FirstActive.UTC().Format(time.RFC3339Nano),
+		"LastActivityTime":       incident.LastActive.UTC().Format(time.RFC3339Nano),
+		"FirstModifiedTime":      incident.Created.UTC().Format(time.RFC3339Nano),
+		"LastModifiedTime":       now.UTC().Format(time.RFC3339Nano),
+		"CreatedTime":            incident.Created.UTC().Format(time.RFC3339Nano),
+		"ClosedTime":             "",
+		"AlertIds":               incident.AlertIDs,
+		"RelatedAnalyticRuleIds": []string{rule.id},
+		"BookmarkIds":            []string{},
+		"Comments":               []string{},
+		"Tasks":                  []string{},
+		"Labels":                 []string{},
+		"IncidentUrl":            url,
+		"AdditionalData": map[string]interface{}{
+			"alertsCount":       len(incident.AlertIDs),
+			"bookmarksCount":    0,
+			"commentsCount":     0,
+			"alertProductNames": []string{"Azure Sentinel"},
+			"tactics":           rule.tactics,
+			"techniques":        rule.techniques,
+		},
+		"ModifiedBy":   modifiedBy,
+		"SourceSystem": "Azure",
+		"Type":         "SecurityIncident",
+	}
+}
+
+// event applies overrides to a row and wraps it as a generated event
+func (g *MicrosoftSentinelGenerator) event(eventID, sourcetype string, timestamp time.Time, row, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields := g.ApplyOverrides(row, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "microsoft_sentinel",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: sourcetype,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package generators
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Username formats for sAMAccountNames and UPNs
+const (
+	UsernameFirstDotLast = "first.last" // jane.doe
+	UsernameFirstInitial = "flast"      // jdoe
+	UsernameLastInitial  = "firstl"     // janed
+)
+
+// maxSAMAccountName is the longest sAMAccountName Active Directory accepts
+const maxSAMAccountName = 20
+
+// nameLocale is a set of first and last names people of a locale have
+type nameLocale struct {
+	code        string
+	name        string
+	familyFirst bool // Display names put the family name first
+	first       []string
+	last        []string
+}
+
+var nameLocales = []nameLocale{
+	{"US", "United States", false,
+		[]string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth", "William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Christopher", "Karen", "Daniel", "Emily", "Matthew", "Ashley"},
+		[]string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Wilson", "Anderson", "Taylor", "Thomas", "Moore", "Jackson", "Martin", "Lee", "Thompson", "White", "Harris", "Clark", "Lewis", "Walker"}},
+	{"DE", "Germany", false,
+		[]string{"Lukas", "Jonas", "Leon", "Finn", "Felix", "Maximilian", "Paul", "Jürgen", "Stefan", "Thomas", "Andreas", "Jörg", "Anna", "Lena", "Marie", "Sophie", "Katharina", "Julia", "Sabine", "Ute", "Claudia", "Birgit", "Monika", "Hannah"},
+		[]string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann", "Schäfer", "Koch", "Bauer", "Richter", "Klein", "Wolf", "Schröder", "Neumann", "Schwarz", "Zimmermann", "Braun", "Krüger", "Hofmann", "Hartmann"}},
+	{"JP", "Japan", true,
+		[]string{"Hiroshi", "Takashi", "Kenji", "Daisuke", "Yuki", "Haruto", "Sota", "Ren", "Kazuki", "Shota", "Takumi", "Ryo", "Yui", "Aoi", "Sakura", "Hina", "Yuna", "Haruka", "Misaki", "Akiko", "Keiko", "Naoko", "Emi", "Mai"},
+		[]string{"Sato", "Suzuki", "Takahashi", "Tanaka", "Watanabe", "Ito", "Yamamoto", "Nakamura", "Kobayashi", "Kato", "Yoshida", "Yamada", "Sasaki", "Yamaguchi", "Matsumoto", "Inoue", "Kimura", "Hayashi", "Shimizu", "Yamazaki", "Mori", "Abe", "Ikeda", "Hashimoto"}},
+	{"AR", "Argentina", false,
+		[]string{"Martín", "Santiago", "Mateo", "Joaquín", "Tomás", "Nicolás", "Facundo", "Agustín", "Lucas", "Gonzalo", "Diego", "Sebastián", "Sofía", "Valentina", "Martina", "Camila", "Lucía", "Florencia", "Agustina", "Micaela", "Julieta", "Paula", "Carolina", "Milagros"},
+		[]string{"González", "Rodríguez", "Gómez", "Fernández", "López", "Díaz", "Martínez", "Pérez", "García", "Sánchez", "Romero", "Sosa", "Álvarez", "Torres", "Ruiz", "Ramírez", "Flores", "Acosta", "Benítez", "Medina", "Herrera", "Suárez", "Aguirre", "Giménez"}},
+}
+
+// asciiName spells accented letters the way account names do, e.g. Müller
+// as mueller and Martín as martin
+var asciiName = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue",
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ñ", "N",
+	" ", "", "'", "",
+)
+
+// Person is a made-up user whose names and account identifiers agree
+type Person struct {
+	Locale         string `json:"locale"`
+	FirstName      string `json:"first_name"`
+	LastName       string `json:"last_name"`
+	DisplayName    string `json:"display_name"`
+	SAMAccountName string `json:"sam_account_name"`
+	UPN            string `json:"upn"`
+	Email          string `json:"email"`
+}
+
+// NameProfile controls the people generators make up: the locales their
+// names come from and how their account names and addresses are formed
+type NameProfile struct {
+	Locales        []string `json:"locales"`         // Locale codes; an empty list means every locale
+	EmailDomain    string   `json:"email_domain"`    // Domain of UPNs and email addresses; empty for the theme's domain
+	UsernameFormat string   `json:"username_format"` // first.last, flast or firstl
+}
+
+// DefaultNameProfile draws US names with first.last account names
+func DefaultNameProfile() NameProfile {
+	return NameProfile{
+		Locales:        []string{"US"},
+		UsernameFormat: UsernameFirstDotLast,
+	}
+}
+
+// NameGenerator makes up people according to the name profile
+type NameGenerator struct {
+	mu      sync.RWMutex
+	profile NameProfile
+	locales map[string]*nameLocale
+}
+
+// Names is the global name generator
+var Names = newNameGenerator()
+
+func newNameGenerator() *NameGenerator {
+	g := &NameGenerator{
+		profile: DefaultNameProfile(),
+		locales: make(map[string]*nameLocale),
+	}
+	for i := range nameLocales {
+		g.locales[nameLocales[i].code] = &nameLocales[i]
+	}
+	return g
+}
+
+// Profile returns the current name profile
+func (g *NameGenerator) Profile() NameProfile {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.profile
+}
+
+// ValidateProfile checks every locale code in p is known, upper-casing
+// them, and fills in the default username format
+func (g *NameGenerator) ValidateProfile(p *NameProfile) error {
+	for i, code := range p.Locales {
+		code = strings.ToUpper(code)
+		if _, ok := g.locales[code]; !ok {
+			return fmt.Errorf("unknown locale: %s", code)
+		}
+		p.Locales[i] = code
+	}
+	p.EmailDomain = strings.ToLower(strings.TrimSpace(p.EmailDomain))
+	switch p.UsernameFormat {
+	case "":
+		p.UsernameFormat = UsernameFirstDotLast
+	case UsernameFirstDotLast, UsernameFirstInitial, UsernameLastInitial:
+	default:
+		return fmt.Errorf("unknown username format %q (want %s, %s or %s)",
+			p.UsernameFormat, UsernameFirstDotLast, UsernameFirstInitial, UsernameLastInitial)
+	}
+	return nil
+}
+
+// SetProfile replaces the name profile after validating it
+func (g *NameGenerator) SetProfile(p NameProfile) error {
+	if err := g.ValidateProfile(&p); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.profile = p
+	return nil
+}
+
+// Locales returns the code and name of every locale names come from
+func (g *NameGenerator) Locales() []map[string]string {
+	locales := make([]map[string]string, 0, len(nameLocales))
+	for _, l := range nameLocales {
+		locales = append(locales, map[string]string{"code": l.code, "name": l.name})
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i]["code"] < locales[j]["code"] })
+	return locales
+}
+
+// Random makes up a person from one of the profile's locales
+func (g *NameGenerator) Random(rnd *RandomStream) Person {
+	profile := g.Profile()
+	codes := profile.Locales
+	if len(codes) == 0 {
+		for _, l := range nameLocales {
+			codes = append(codes, l.code)
+		}
+	}
+	b := BaseGenerator{rnd: rnd}
+	l := g.locales[b.RandomChoice(codes)]
+	first, last := b.RandomChoice(l.first), b.RandomChoice(l.last)
+
+	p := Person{
+		Locale:      l.code,
+		FirstName:   first,
+		LastName:    last,
+		DisplayName: first + " " + last,
+	}
+	if l.familyFirst {
+		p.DisplayName = last + " " + first
+	}
+	f, s := strings.ToLower(asciiName.Replace(first)), strings.ToLower(asciiName.Replace(last))
+	switch profile.UsernameFormat {
+	case UsernameFirstInitial:
+		p.SAMAccountName = f[:1] + s
+	case UsernameLastInitial:
+		p.SAMAccountName = f + s[:1]
+	default:
+		p.SAMAccountName = f + "." + s
+	}
+	if len(p.SAMAccountName) > maxSAMAccountName {
+		p.SAMAccountName = p.SAMAccountName[:maxSAMAccountName]
+	}
+	domain := profile.EmailDomain
+	if domain == "" {
+		domain = Theme().Domain
+	}
+	p.UPN = p.SAMAccountName + "@" + domain
+	p.Email = f + "." + s + "@" + domain
+	return p
+}
+
+// RandomPerson makes up a user with consistent names, sAMAccountName, UPN
+// and email address, drawn from the name profile
+func (b *BaseGenerator) RandomPerson() Person {
+	return Names.Random(b.rnd)
+}
@@ -0,0 +1,380 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// NetskopeGenerator generates Netskope CASB/SWG events and alerts
+type NetskopeGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&NetskopeGenerator{})
+}
+
+// GetEventType returns the event type for Netskope
+func (g *NetskopeGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "netskope",
+		Name:        "Netskope",
+		Category:    "cloud",
+		Description: "Netskope CASB application and page events plus DLP, malware, policy and anomaly alerts",
+		EventIDs:    []string{"application", "page", "DLP", "malware", "policy", "anomaly"},
+	}
+}
+
+// GetTemplates returns available templates for Netskope events
+func (g *NetskopeGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "application",
+			Name:        "Application Event",
+			Category:    "netskope",
+			EventID:     "application",
+			Format:      "json",
+			Description: "User activity in a sanctioned or unsanctioned cloud app",
+			Sourcetype:  "netskope:events",
+		},
+		{
+			ID:          "page",
+			Name:        "Page Event",
+			Category:    "netskope",
+			EventID:     "page",
+			Format:      "json",
+			Description: "Page-level web transaction through the Netskope proxy",
+			Sourcetype:  "netskope:events",
+		},
+		{
+			ID:          "alert_dlp",
+			Name:        "DLP Alert",
+			Category:    "netskope",
+			EventID:     "DLP",
+			Format:      "json",
+			Description: "Sensitive content uploaded or shared matched a DLP profile",
+			Sourcetype:  "netskope:alerts",
+		},
+		{
+			ID:          "alert_malware",
+			Name:        "Malware Alert",
+			Category:    "netskope",
+			EventID:     "malware",
+			Format:      "json",
+			Description: "Malicious file detected in a cloud app download or upload",
+			Sourcetype:  "netskope:alerts",
+		},
+		{
+			ID:          "alert_policy",
+			Name:        "Policy Alert",
+			Category:    "netskope",
+			EventID:     "policy",
+			Format:      "json",
+			Description: "Real-time protection policy blocked an activity",
+			Sourcetype:  "netskope:alerts",
+		},
+		{
+			ID:          "alert_anomaly",
+			Name:        "UBA Anomaly Alert",
+			Category:    "netskope",
+			EventID:     "anomaly",
+			Format:      "json",
+			Description: "User behavior anomaly such as bulk download or rare-location login",
+			Sourcetype:  "netskope:alerts",
+		},
+	}
+}
+
+// netskopeApp is a cloud app with its Cloud Confidence Index rating
+type netskopeApp struct {
+	name, category, ccl, domain string
+	cci                         int
+	sanctioned                  bool
+	activities                  []string
+}
+
+var netskopeApps = []netskopeApp{
+	{"Microsoft Office 365 OneDrive for Business", "Cloud Storage", "excellent", "example-my.sharepoint.com", 95, true, []string{"Upload", "Download", "View", "Share", "Edit"}},
+	{"Microsoft Office 365 Outlook.com", "Webmail", "excellent", "outlook.office365.com", 93, true, []string{"Login Successful", "Send", "View", "Attach"}},
+	{"Slack", "Collaboration", "high", "example.slack.com", 85, true, []string{"Post", "Upload", "Download", "Login Successful"}},
+	{"Salesforce", "CRM", "excellent", "example.my.salesforce.com", 94, true, []string{"View", "Edit", "Download", "Login Successful"}},
+	{"GitHub", "Development Tools", "high", "github.com", 82, true, []string{"Login Successful", "Download", "View", "Create"}},
+	{"Box", "Cloud Storage", "high", "app.box.com", 88, false, []string{"Upload", "Download", "Share"}},
+	{"Dropbox", "Cloud Storage", "high", "www.dropbox.com", 80, false, []string{"Upload", "Download", "Share"}},
+	{"Google Drive", "Cloud Storage", "high", "drive.google.com", 86, false, []string{"Upload", "Download", "Share", "View"}},
+	{"WeTransfer", "Cloud Storage", "medium", "wetransfer.com", 55, false, []string{"Upload"}},
+	{"ChatGPT", "Generative AI", "medium", "chatgpt.com", 60, false, []string{"Post", "Upload", "Login Successful"}},
+}
+
+// Generate creates a Netskope event or alert
+func (g *NetskopeGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "application":
+		return g.generateApplication(overrides)
+	case "page":
+		return g.generatePage(overrides)
+	case "alert_dlp":
+		return g.generateDLPAlert(overrides)
+	case "alert_malware":
+		return g.generateMalwareAlert(overrides)
+	case "alert_policy":
+		return g.generatePolicyAlert(overrides)
+	case "alert_anomaly":
+		return g.generateAnomalyAlert(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// baseFields returns the fields common to every Netskope record for a pool
+// user working from their own device
+func (g *NetskopeGenerator) baseFields(now time.Time, recordType string, app netskopeApp) map[string]interface{} {
+	user := Entities.RandomUser(g.rnd)
+	device := Entities.HostForUser(g.rnd, user.Username)
+
+	osName, browser := "Windows 11", "Chrome"
+	switch device.Platform {
+	case "darwin":
+		osName, browser = "Mac OS X "+device.OSVersion, g.RandomChoice([]string{"Chrome", "Safari"})
+	case "linux":
+		osName, browser = "Linux", "Firefox"
+	}
+	tag := "Unsanctioned"
+	if app.sanctioned {
+		tag = "Sanctioned"
+	}
+
+	return map[string]interface{}{
+		"_id":                g.RandomHex(12),
+		"timestamp":          now.Unix(),
+		"type":               recordType,
+		"access_method":      g.WeightedChoice([]string{"Client", "Reverse Proxy", "API Connector"}, []float64{80, 10, 10}),
+		"app":                app.name,
+		"appcategory":        app.category,
+		"ccl":                app.ccl,
+		"cci":                app.cci,
+		"app_tags":           []string{tag},
+		"user":               user.Email,
+		"ur_normalized":      user.Email,
+		"userkey":            user.Email,
+		"organization_unit":  Theme().Domain + "/" + user.Department,
+		"srcip":              g.RandomIPv4External(),
+		"userip":             device.IP,
+		"dstip":              g.RandomIPv4External(),
+		"site":               app.name,
+		"traffic_type":       "CloudApp",
+		"hostname":           device.Hostname,
+		"device":             map[string]string{"windows": "Windows Device", "darwin": "Mac Device", "linux": "Linux Device"}[device.Platform],
+		"os":                 osName,
+		"browser":            browser,
+		"src_location":       g.WeightedChoice([]string{"New York", "London", "Singapore", "Austin"}, []float64{40, 25, 15, 20}),
+		"src_country":        "US",
+		"dst_country":        "US",
+		"instance_id":        "",
+		"policy":             "",
+		"action":             "allow",
+		"count":              1,
+		"acked":              "false",
+		"tenant_name":        "example",
+		"transaction_id":     g.RandomInt(1_000_000_000, 2_000_000_000),
+		"request_id":         g.RandomInt(1_000_000_000, 2_000_000_000),
+		"internal_user_flag": true,
+	}
+}
+
+// buildEvent applies overrides and marshals a Netskope record
+func (g *NetskopeGenerator) buildEvent(now time.Time, eventID, sourcetype string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, _ := json.Marshal(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "netskope",
+		EventID:    eventID,
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: sourcetype,
+	}, nil
+}
+
+// randomApp picks an app, favouring the sanctioned suite
+func (g *NetskopeGenerator) randomApp() netskopeApp {
+	return netskopeApps[g.RandomZipf(len(netskopeApps), 1.0)]
+}
+
+// randomFile returns a plausible file name and size in bytes
+func (g *NetskopeGenerator) randomFile() (string, int) {
+	name := g.RandomChoice([]string{
+		"Q3_Financials.xlsx", "customer_export.csv", "board_deck_final.pptx", "employee_roster.xlsx",
+		"architecture.pdf", "contract_signed.pdf", "source_backup.zip", "payroll_2024.csv",
+	})
+	return name, int(g.RandomLogNormal(900_000, 1.4))
+}
+
+func (g *NetskopeGenerator) generateApplication(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	app := g.randomApp()
+	fields := g.baseFields(now, "nspolicy", app)
+	activity := app.activities[g.RandomInt(0, len(app.activities)-1)]
+
+	fields["activity"] = activity
+	fields["object_type"] = "File"
+	if activity == "Upload" || activity == "Download" || activity == "Share" || activity == "Attach" {
+		name, size := g.randomFile()
+		fields["file_name"] = name
+		fields["object"] = name
+		fields["file_size"] = size
+		fields["file_type"] = "application/octet-stream"
+	}
+	if app.category == "Cloud Storage" {
+		fields["instance_id"] = "personal"
+		if app.sanctioned {
+			fields["instance_id"] = Theme().Domain
+		}
+	}
+	fields["url"] = "https://" + app.domain + "/"
+
+	return g.buildEvent(now, "application", "netskope:events", fields, overrides)
+}
+
+func (g *NetskopeGenerator) generatePage(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	app := g.randomApp()
+	fields := g.baseFields(now, "connection", app)
+
+	fields["page"] = app.domain + "/"
+	fields["url"] = "https://" + app.domain + "/"
+	fields["domain"] = app.domain
+	fields["numbytes"] = int(g.RandomLogNormal(40_000, 1.2))
+	fields["client_bytes"] = g.RandomInt(500, 8000)
+	fields["server_bytes"] = fields["numbytes"].(int) - fields["client_bytes"].(int)
+	fields["page_duration"] = g.RandomInt(1, 600)
+	fields["http_transaction_count"] = g.RandomInt(1, 120)
+	fields["useragent"] = fields["browser"]
+
+	return g.buildEvent(now, "page", "netskope:events", fields, overrides)
+}
+
+func (g *NetskopeGenerator) generateDLPAlert(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	app := netskopeApps[g.RandomInt(5, len(netskopeApps)-1)]
+	fields := g.baseFields(now, "nspolicy", app)
+	name, size := g.randomFile()
+
+	profile := g.RandomChoice([]string{"DLP-PCI", "DLP-PII", "DLP-Source-Code", "DLP-Confidential"})
+	rule := map[string]string{
+		"DLP-PCI":          "Credit Card Number",
+		"DLP-PII":          "US Social Security Number",
+		"DLP-Source-Code":  "Source Code - Generic",
+		"DLP-Confidential": "Confidential Document Marking",
+	}[profile]
+
+	fields["alert"] = "yes"
+	fields["alert_type"] = "DLP"
+	fields["alert_name"] = "Block upload of sensitive data to unsanctioned storage"
+	fields["activity"] = "Upload"
+	fields["action"] = g.WeightedChoice([]string{"block", "alert", "useralert"}, []float64{60, 30, 10})
+	fields["policy"] = "DLP - Unsanctioned Cloud Storage"
+	fields["dlp_profile"] = profile
+	fields["dlp_rule"] = rule
+	fields["dlp_rule_count"] = g.RandomInt(1, 250)
+	fields["dlp_rule_severity"] = g.WeightedChoice([]string{"Critical", "High", "Medium"}, []float64{30, 50, 20})
+	fields["dlp_file"] = name
+	fields["file_name"] = name
+	fields["file_size"] = size
+	fields["object"] = name
+	fields["instance_id"] = "personal"
+	fields["url"] = "https://" + app.domain + "/upload"
+
+	return g.buildEvent(now, "DLP", "netskope:alerts", fields, overrides)
+}
+
+func (g *NetskopeGenerator) generateMalwareAlert(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	app := g.randomApp()
+	fields := g.baseFields(now, "nspolicy", app)
+
+	malware := []struct {
+		name, file, severity string
+	}{
+		{"Trojan.GenericKD.46542345", "invoice_scan.pdf.exe", "high"},
+		{"W97M.Downloader.Emotet", "Payment_Details.docm", "high"},
+		{"JS.Trojan.Agent", "shipping_label.js", "medium"},
+		{"Win32.Ransom.LockBit", "update_installer.exe", "critical"},
+	}[g.RandomInt(0, 3)]
+
+	fields["alert"] = "yes"
+	fields["alert_type"] = "malware"
+	fields["alert_name"] = "Malware detected in cloud app transfer"
+	fields["activity"] = g.RandomChoice([]string{"Download", "Upload"})
+	fields["action"] = "block"
+	fields["malware_name"] = malware.name
+	fields["malware_type"] = "Malware"
+	fields["malware_severity"] = malware.severity
+	fields["malware_id"] = g.RandomHex(32)
+	file := Files.ForName(malware.file)
+	fields["local_sha256"] = g.FileHash(file, models.IOCTypeSHA256)
+	fields["local_md5"] = g.FileHash(file, models.IOCTypeMD5)
+	fields["detection_engine"] = g.WeightedChoice([]string{"Netskope AV", "Netskope Advanced Heuristic Engine", "Netskope Sandbox"}, []float64{60, 25, 15})
+	fields["file_name"] = malware.file
+	fields["object"] = malware.file
+	fields["file_size"] = g.RandomInt(40_000, 4_000_000)
+	fields["url"] = "https://" + app.domain + "/"
+
+	return g.buildEvent(now, "malware", "netskope:alerts", fields, overrides)
+}
+
+func (g *NetskopeGenerator) generatePolicyAlert(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	app := netskopeApps[g.RandomInt(5, len(netskopeApps)-1)]
+	fields := g.baseFields(now, "nspolicy", app)
+
+	fields["alert"] = "yes"
+	fields["alert_type"] = "policy"
+	fields["activity"] = app.activities[g.RandomInt(0, len(app.activities)-1)]
+	fields["action"] = "block"
+	fields["policy"] = g.RandomChoice([]string{"Block Unsanctioned Storage Uploads", "Block Personal Instances", "Coach Generative AI Usage"})
+	fields["alert_name"] = fields["policy"]
+	fields["instance_id"] = "personal"
+	fields["url"] = "https://" + app.domain + "/"
+
+	return g.buildEvent(now, "policy", "netskope:alerts", fields, overrides)
+}
+
+func (g *NetskopeGenerator) generateAnomalyAlert(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	app := g.randomApp()
+	fields := g.baseFields(now, "nspolicy", app)
+
+	kind := g.RandomChoice([]string{"bulk_download", "rare_location", "failed_logins"})
+	fields["alert"] = "yes"
+	fields["alert_type"] = "anomaly"
+	fields["action"] = "alert"
+	fields["risk_level"] = g.WeightedChoice([]string{"high", "medium", "low"}, []float64{30, 50, 20})
+	switch kind {
+	case "bulk_download":
+		fields["alert_name"] = "Bulk Download"
+		fields["activity"] = "Download"
+		fields["count"] = g.RandomInt(200, 5000)
+		fields["bin_size"] = 3600
+	case "rare_location":
+		fields["alert_name"] = "Login From Rare Country"
+		fields["activity"] = "Login Successful"
+		fields["src_country"] = g.RandomChoice([]string{"RU", "NG", "BR", "CN", "RO"})
+		fields["src_location"] = "Unknown"
+	default:
+		fields["alert_name"] = "Multiple Failed Logins"
+		fields["activity"] = "Login Failed"
+		fields["count"] = g.RandomInt(20, 300)
+		fields["bin_size"] = 600
+	}
+	fields["url"] = "https://" + app.domain + "/"
+
+	return g.buildEvent(now, "anomaly", "netskope:alerts", fields, overrides)
+}
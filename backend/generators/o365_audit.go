@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -109,13 +108,8 @@ func (g *O365AuditGenerator) randomTenantID() string {
 }
 
 func (g *O365AuditGenerator) randomUser() (string, string) {
-	firstNames := []string{"John", "Jane", "Bob", "Alice", "Charlie", "Diana"}
-	lastNames := []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia"}
-	firstName := g.RandomChoice(firstNames)
-	lastName := g.RandomChoice(lastNames)
-	domain := g.RandomChoice([]string{"contoso.com", "fabrikam.com", "company.onmicrosoft.com"})
-	email := fmt.Sprintf("%s.%s@%s", firstName, lastName, domain)
-	return fmt.Sprintf("%s %s", firstName, lastName), email
+	p := g.RandomPerson()
+	return p.DisplayName, p.UPN
 }
 
 func (g *O365AuditGenerator) randomFilename() string {
@@ -126,7 +120,7 @@ func (g *O365AuditGenerator) randomFilename() string {
 
 func (g *O365AuditGenerator) randomSiteUrl() string {
 	sites := []string{"sites/marketing", "sites/engineering", "sites/hr", "sites/finance", "personal/john_smith"}
-	return fmt.Sprintf("https://contoso.sharepoint.com/%s", g.RandomChoice(sites))
+	return fmt.Sprintf("https://%s.sharepoint.com/%s", tenantName(), g.RandomChoice(sites))
 }
 
 func (g *O365AuditGenerator) buildBaseEvent(operation, workload, recordType string) map[string]interface{} {
@@ -166,7 +160,7 @@ func (g *O365AuditGenerator) generateFileAccessed(overrides map[string]interface
 	event["EventSource"] = "SharePoint"
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -196,7 +190,7 @@ func (g *O365AuditGenerator) generateFileModified(overrides map[string]interface
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -223,7 +217,7 @@ func (g *O365AuditGenerator) generateFileDeleted(overrides map[string]interface{
 	event["EventSource"] = "SharePoint"
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -259,7 +253,7 @@ func (g *O365AuditGenerator) generateUserLogin(overrides map[string]interface{})
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -276,17 +270,16 @@ func (g *O365AuditGenerator) generateMailAccessed(overrides map[string]interface
 	timestamp := time.Now()
 	event := g.buildBaseEvent("MailItemsAccessed", "Exchange", "50")
 
-	subjects := []string{
-		"Q4 Budget Review", "Meeting Notes", "Project Update",
-		"Action Required", "Weekly Report", "Important Information",
+	var items []map[string]interface{}
+	for i := g.RandomInt(1, 3); i > 0; i-- {
+		msg := g.RandomMailMessage(false, timestamp.Add(-time.Duration(g.RandomInt(60, 7*24*3600))*time.Second))
+		items = append(items, map[string]interface{}{"InternetMessageId": msg.MessageID, "Subject": msg.Subject})
 	}
 
 	event["MailboxOwnerUPN"] = event["UserId"]
 	event["MailboxOwnerSid"] = g.RandomSID()
 	event["Folders"] = []map[string]interface{}{
-		{"Path": "\\Inbox", "FolderItems": []map[string]interface{}{
-			{"InternetMessageId": fmt.Sprintf("<%s@mail.contoso.com>", g.RandomString(32)), "Subject": g.RandomChoice(subjects)},
-		}},
+		{"Path": "\\Inbox", "FolderItems": items},
 	}
 	event["OperationProperties"] = []map[string]interface{}{
 		{"Name": "MailAccessType", "Value": g.RandomChoice([]string{"Bind", "Sync"})},
@@ -294,7 +287,7 @@ func (g *O365AuditGenerator) generateMailAccessed(overrides map[string]interface
 	event["SessionId"] = uuid.New().String()
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -331,7 +324,7 @@ func (g *O365AuditGenerator) generateTeamCreated(overrides map[string]interface{
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -26,7 +25,7 @@ func (g *OktaGenerator) GetEventType() models.EventType {
 		Name:        "Okta System Logs",
 		Category:    "identity",
 		Description: "Okta authentication events, MFA challenges, admin actions, and policy changes",
-		EventIDs:    []string{"user.session.start", "user.authentication.sso", "user.mfa.factor.activate", "user.account.lock", "policy.lifecycle.update", "application.lifecycle.create"},
+		EventIDs:    []string{"user.session.start", "user.authentication.sso", "user.mfa.factor.activate", "user.account.lock", "policy.lifecycle.update", "application.lifecycle.create", "user.lifecycle.create", "user.lifecycle.activate", "user.lifecycle.deactivate"},
 	}
 }
 
@@ -81,6 +80,30 @@ func (g *OktaGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "json",
 			Description: "Password reset completed",
 		},
+		{
+			ID:          "user_create",
+			Name:        "User Created",
+			Category:    "okta",
+			EventID:     "user.lifecycle.create",
+			Format:      "json",
+			Description: "Administrator created a user",
+		},
+		{
+			ID:          "user_activate",
+			Name:        "User Activated",
+			Category:    "okta",
+			EventID:     "user.lifecycle.activate",
+			Format:      "json",
+			Description: "Administrator activated a user",
+		},
+		{
+			ID:          "user_deactivate",
+			Name:        "User Deactivated",
+			Category:    "okta",
+			EventID:     "user.lifecycle.deactivate",
+			Format:      "json",
+			Description: "Administrator deactivated a user",
+		},
 	}
 }
 
@@ -88,34 +111,35 @@ func (g *OktaGenerator) GetTemplates() []models.EventTemplate {
 func (g *OktaGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	switch templateID {
 	case "session_start":
-		return g.generateSessionStart(overrides)
+		return g.generateSessionStart(time.Now(), overrides)
 	case "sso_auth":
-		return g.generateSSOAuth(overrides)
+		return g.generateSSOAuth(time.Now(), overrides)
 	case "mfa_enroll":
 		return g.generateMFAEnroll(overrides)
 	case "account_lock":
 		return g.generateAccountLock(overrides)
 	case "auth_failure":
-		return g.generateAuthFailure(overrides)
+		return g.generateAuthFailure(time.Now(), overrides)
 	case "password_reset":
 		return g.generatePasswordReset(overrides)
+	case "user_create":
+		return g.generateUserLifecycle(time.Now(), "user.lifecycle.create", "Create Okta user", overrides)
+	case "user_activate":
+		return g.generateUserLifecycle(time.Now(), "user.lifecycle.activate", "Activate Okta user", overrides)
+	case "user_deactivate":
+		return g.generateUserLifecycle(time.Now(), "user.lifecycle.deactivate", "Deactivate Okta user", overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
 }
 
 func (g *OktaGenerator) randomOktaUser() (string, string, string) {
-	firstNames := []string{"John", "Jane", "Bob", "Alice", "Charlie", "Diana", "Eve", "Frank"}
-	lastNames := []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis"}
-	firstName := g.RandomChoice(firstNames)
-	lastName := g.RandomChoice(lastNames)
-	email := fmt.Sprintf("%s.%s@company.com", firstName, lastName)
-	return firstName, lastName, email
+	p := g.RandomPerson()
+	return p.FirstName, p.LastName, p.Email
 }
 
 func (g *OktaGenerator) randomOktaOrgURL() string {
-	orgs := []string{"company", "acme", "contoso", "initech", "umbrella"}
-	return fmt.Sprintf("https://%s.okta.com", g.RandomChoice(orgs))
+	return fmt.Sprintf("https://%s.okta.com", tenantName())
 }
 
 func (g *OktaGenerator) randomApplication() (string, string) {
@@ -133,14 +157,13 @@ func (g *OktaGenerator) randomApplication() (string, string) {
 	return app.name, app.label
 }
 
-func (g *OktaGenerator) buildBaseEvent(eventType, displayMessage, outcome string) map[string]interface{} {
-	timestamp := time.Now().UTC()
+func (g *OktaGenerator) buildBaseEvent(timestamp time.Time, eventType, displayMessage, outcome string) map[string]interface{} {
 	firstName, lastName, email := g.randomOktaUser()
 	userID := "00u" + g.RandomString(17)
 
 	return map[string]interface{}{
 		"uuid":       uuid.New().String(),
-		"published":  timestamp.Format(time.RFC3339Nano),
+		"published":  timestamp.UTC().Format(time.RFC3339Nano),
 		"eventType":  eventType,
 		"version":    "0",
 		"severity":   g.RandomChoice([]string{"INFO", "WARN", "ERROR"}),
@@ -197,12 +220,11 @@ func (g *OktaGenerator) buildBaseEvent(eventType, displayMessage, outcome string
 	}
 }
 
-func (g *OktaGenerator) generateSessionStart(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
-	event := g.buildBaseEvent("user.session.start", "User login to Okta", "SUCCESS")
+func (g *OktaGenerator) generateSessionStart(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	event := g.buildBaseEvent(timestamp, "user.session.start", "User login to Okta", "SUCCESS")
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -215,11 +237,10 @@ func (g *OktaGenerator) generateSessionStart(overrides map[string]interface{}) (
 	}, nil
 }
 
-func (g *OktaGenerator) generateSSOAuth(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
+func (g *OktaGenerator) generateSSOAuth(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	appID, appLabel := g.randomApplication()
 
-	event := g.buildBaseEvent("user.authentication.sso", fmt.Sprintf("User single sign on to app: %s", appLabel), "SUCCESS")
+	event := g.buildBaseEvent(timestamp, "user.authentication.sso", fmt.Sprintf("User single sign on to app: %s", appLabel), "SUCCESS")
 
 	event["target"] = []map[string]interface{}{
 		{
@@ -231,7 +252,7 @@ func (g *OktaGenerator) generateSSOAuth(overrides map[string]interface{}) (*mode
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -248,7 +269,7 @@ func (g *OktaGenerator) generateMFAEnroll(overrides map[string]interface{}) (*mo
 	timestamp := time.Now()
 	factorType := g.RandomChoice([]string{"token:software:totp", "push", "sms", "email", "webauthn"})
 
-	event := g.buildBaseEvent("user.mfa.factor.activate", fmt.Sprintf("MFA factor activated: %s", factorType), "SUCCESS")
+	event := g.buildBaseEvent(timestamp, "user.mfa.factor.activate", fmt.Sprintf("MFA factor activated: %s", factorType), "SUCCESS")
 
 	event["target"] = []map[string]interface{}{
 		{
@@ -260,7 +281,7 @@ func (g *OktaGenerator) generateMFAEnroll(overrides map[string]interface{}) (*mo
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -275,7 +296,7 @@ func (g *OktaGenerator) generateMFAEnroll(overrides map[string]interface{}) (*mo
 
 func (g *OktaGenerator) generateAccountLock(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
-	event := g.buildBaseEvent("user.account.lock", "User account locked due to excessive failed login attempts", "SUCCESS")
+	event := g.buildBaseEvent(timestamp, "user.account.lock", "User account locked due to excessive failed login attempts", "SUCCESS")
 	event["severity"] = "WARN"
 
 	event["debugContext"] = map[string]interface{}{
@@ -286,7 +307,7 @@ func (g *OktaGenerator) generateAccountLock(overrides map[string]interface{}) (*
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -299,9 +320,8 @@ func (g *OktaGenerator) generateAccountLock(overrides map[string]interface{}) (*
 	}, nil
 }
 
-func (g *OktaGenerator) generateAuthFailure(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
-	event := g.buildBaseEvent("user.session.start", "User login to Okta", "FAILURE")
+func (g *OktaGenerator) generateAuthFailure(timestamp time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	event := g.buildBaseEvent(timestamp, "user.session.start", "User login to Okta", "FAILURE")
 	event["severity"] = "WARN"
 
 	reasons := []string{"INVALID_CREDENTIALS", "LOCKED_OUT", "MFA_ENROLL_REQUIRED", "PASSWORD_EXPIRED"}
@@ -311,7 +331,7 @@ func (g *OktaGenerator) generateAuthFailure(overrides map[string]interface{}) (*
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -326,10 +346,10 @@ func (g *OktaGenerator) generateAuthFailure(overrides map[string]interface{}) (*
 
 func (g *OktaGenerator) generatePasswordReset(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
-	event := g.buildBaseEvent("user.account.reset_password", "User password was reset", "SUCCESS")
+	event := g.buildBaseEvent(timestamp, "user.account.reset_password", "User password was reset", "SUCCESS")
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -341,3 +361,33 @@ func (g *OktaGenerator) generatePasswordReset(overrides map[string]interface{})
 		Sourcetype: "okta:im",
 	}, nil
 }
+
+// generateUserLifecycle creates an admin action on a user account; the admin
+// is the actor and the user is the target
+func (g *OktaGenerator) generateUserLifecycle(timestamp time.Time, eventType, displayMessage string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	event := g.buildBaseEvent(timestamp, eventType, displayMessage, "SUCCESS")
+	event["severity"] = "INFO"
+
+	firstName, lastName, email := g.randomOktaUser()
+	event["target"] = []map[string]interface{}{
+		{
+			"id":          "00u" + g.RandomString(17),
+			"type":        "User",
+			"alternateId": email,
+			"displayName": fmt.Sprintf("%s %s", firstName, lastName),
+		},
+	}
+
+	fields := g.ApplyOverrides(event, overrides)
+	rawEvent, _ := g.marshalRawJSON(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "okta",
+		EventID:    eventType,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "okta:im",
+	}, nil
+}
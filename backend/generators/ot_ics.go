@@ -0,0 +1,381 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// OTICSGenerator generates industrial protocol session logs and OT security
+// alerts from a passive network sensor
+type OTICSGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&OTICSGenerator{})
+}
+
+// GetEventType returns the event type for OT/ICS events
+func (g *OTICSGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "ot_ics",
+		Name:        "OT/ICS Protocol Events",
+		Category:    "network",
+		Description: "Modbus/TCP, BACnet/IP and OPC UA session logs plus unauthorized write and new device alerts from a passive OT sensor",
+		EventIDs:    []string{"modbus", "bacnet", "opcua", "unauthorized_write", "new_device"},
+	}
+}
+
+// GetTemplates returns available templates for OT/ICS events
+func (g *OTICSGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "modbus",
+			Name:        "Modbus/TCP Session",
+			Category:    "ot_ics",
+			EventID:     "modbus",
+			Format:      "json",
+			Description: "HMI or SCADA polling a PLC with Modbus function codes",
+		},
+		{
+			ID:          "bacnet",
+			Name:        "BACnet/IP Session",
+			Category:    "ot_ics",
+			EventID:     "bacnet",
+			Format:      "json",
+			Description: "Building management server reading or writing BACnet object properties",
+		},
+		{
+			ID:          "opcua",
+			Name:        "OPC UA Session",
+			Category:    "ot_ics",
+			EventID:     "opcua",
+			Format:      "json",
+			Description: "Historian or MES client reading tags from an OPC UA server",
+		},
+		{
+			ID:          "unauthorized_write",
+			Name:        "Unauthorized Write Attempt",
+			Category:    "ot_ics",
+			EventID:     "unauthorized_write",
+			Format:      "json",
+			Description: "Modbus write or PLC stop from a host that is not an authorized engineering station",
+		},
+		{
+			ID:          "new_device",
+			Name:        "New Device Discovered",
+			Category:    "ot_ics",
+			EventID:     "new_device",
+			Format:      "json",
+			Description: "Previously unseen asset observed on an OT segment",
+		},
+	}
+}
+
+// otAsset is a device on the plant network
+type otAsset struct {
+	name, ip, mac, vendor, model, role, zone string
+}
+
+// otAssets follow the Purdue model: level 1 controllers on 192.168.10.0/24,
+// level 2 supervisory hosts on 192.168.20.0/24, level 3 site operations on
+// 10.50.30.0/24 and building automation on 172.20.5.0/24
+var otAssets = []otAsset{
+	{"PLC-LINE1", "192.168.10.11", "00:80:f4:1a:22:01", "Schneider Electric", "Modicon M580", "plc", "L1-Control"},
+	{"PLC-LINE2", "192.168.10.12", "00:80:f4:1a:22:02", "Schneider Electric", "Modicon M340", "plc", "L1-Control"},
+	{"PLC-BOILER", "192.168.10.21", "00:1d:9c:3b:10:21", "Rockwell Automation", "ControlLogix 5580", "plc", "L1-Control"},
+	{"RTU-PUMP3", "192.168.10.31", "00:0e:8c:44:51:31", "Siemens", "SIMATIC S7-1500", "plc", "L1-Control"},
+	{"HMI-01", "192.168.20.10", "00:0c:29:8e:11:10", "AVEVA", "InTouch HMI", "hmi", "L2-Supervisory"},
+	{"HMI-02", "192.168.20.11", "00:0c:29:8e:11:11", "AVEVA", "InTouch HMI", "hmi", "L2-Supervisory"},
+	{"SCADA-SRV", "192.168.20.20", "00:0c:29:8e:11:20", "Ignition", "Gateway 8.1", "scada", "L2-Supervisory"},
+	{"OPC-SRV", "192.168.20.30", "00:0c:29:8e:11:30", "Kepware", "KEPServerEX 6", "opc_server", "L2-Supervisory"},
+	{"EWS-01", "192.168.20.50", "00:0c:29:8e:11:50", "Dell", "Precision 3660", "engineering_workstation", "L2-Supervisory"},
+	{"HISTORIAN", "10.50.30.10", "00:50:56:a1:30:10", "AVEVA", "PI Server 2023", "historian", "L3-Operations"},
+	{"MES-APP", "10.50.30.20", "00:50:56:a1:30:20", "Siemens", "Opcenter Execution", "mes", "L3-Operations"},
+	{"BMS-SRV", "172.20.5.10", "00:50:56:b2:05:10", "Johnson Controls", "Metasys ADS", "bms", "Building-Automation"},
+	{"AHU-CTRL-1", "172.20.5.101", "00:10:90:05:01:01", "Johnson Controls", "FEC2611", "bacnet_controller", "Building-Automation"},
+	{"VAV-CTRL-7", "172.20.5.107", "00:10:90:05:01:07", "Johnson Controls", "FEC1611", "bacnet_controller", "Building-Automation"},
+	{"CHILLER-1", "172.20.5.120", "00:e0:4b:05:01:20", "Trane", "Tracer SC+", "bacnet_controller", "Building-Automation"},
+}
+
+// otAssetsByRole returns the assets with one of the given roles
+func otAssetsByRole(roles ...string) []otAsset {
+	var assets []otAsset
+	for _, a := range otAssets {
+		for _, r := range roles {
+			if a.role == r {
+				assets = append(assets, a)
+				break
+			}
+		}
+	}
+	return assets
+}
+
+// modbusFunctions maps Modbus function codes to their names
+var modbusFunctions = map[int]string{
+	1:  "Read Coils",
+	2:  "Read Discrete Inputs",
+	3:  "Read Holding Registers",
+	4:  "Read Input Registers",
+	5:  "Write Single Coil",
+	6:  "Write Single Register",
+	8:  "Diagnostics",
+	15: "Write Multiple Coils",
+	16: "Write Multiple Registers",
+	43: "Read Device Identification",
+	90: "Schneider UMAS",
+}
+
+// Generate creates an OT/ICS event
+func (g *OTICSGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	var fields map[string]interface{}
+
+	switch templateID {
+	case "modbus":
+		fields = g.modbusSession(now)
+	case "bacnet":
+		fields = g.bacnetSession(now)
+	case "opcua":
+		fields = g.opcuaSession(now)
+	case "unauthorized_write":
+		fields = g.unauthorizedWrite(now)
+	case "new_device":
+		fields = g.newDevice(now)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, _ := json.Marshal(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "ot_ics",
+		EventID:    templateID,
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "ot:ics",
+	}, nil
+}
+
+// session returns the fields shared by every session record between src and dst
+func (g *OTICSGenerator) session(now time.Time, recordType, protocol string, src, dst otAsset, dstPort int) map[string]interface{} {
+	return map[string]interface{}{
+		"timestamp":  now.Format(time.RFC3339Nano),
+		"sensor":     "ot-sensor-01",
+		"event_type": recordType,
+		"protocol":   protocol,
+		"session_id": uuid.New().String(),
+		"src_ip":     src.ip,
+		"src_mac":    src.mac,
+		"src_port":   g.RandomInt(49152, 65535),
+		"src_name":   src.name,
+		"src_role":   src.role,
+		"src_zone":   src.zone,
+		"dst_ip":     dst.ip,
+		"dst_mac":    dst.mac,
+		"dst_port":   dstPort,
+		"dst_name":   dst.name,
+		"dst_role":   dst.role,
+		"dst_zone":   dst.zone,
+		"dst_vendor": dst.vendor,
+		"dst_model":  dst.model,
+		"transport":  "tcp",
+	}
+}
+
+func (g *OTICSGenerator) modbusSession(now time.Time) map[string]interface{} {
+	masters := otAssetsByRole("hmi", "scada", "opc_server")
+	plcs := otAssetsByRole("plc")
+	src := masters[g.RandomInt(0, len(masters)-1)]
+	dst := plcs[g.RandomInt(0, len(plcs)-1)]
+
+	codes := []int{3, 4, 1, 2, 6, 16, 43}
+	code := codes[g.weightedIndex([]float64{45, 25, 10, 8, 6, 4, 2})]
+
+	fields := g.session(now, "session", "modbus", src, dst, 502)
+	fields["modbus"] = map[string]interface{}{
+		"transaction_id": g.RandomInt(1, 65535),
+		"unit_id":        1,
+		"function_code":  code,
+		"function_name":  modbusFunctions[code],
+		"start_address":  40001 + g.RandomInt(0, 200),
+		"quantity":       g.RandomInt(1, 32),
+		"exception_code": nil,
+	}
+	fields["packets"] = g.RandomInt(4, 400)
+	fields["bytes"] = g.RandomInt(300, 40000)
+	fields["duration"] = g.RandomFloat(0.05, 60)
+	return fields
+}
+
+func (g *OTICSGenerator) bacnetSession(now time.Time) map[string]interface{} {
+	controllers := otAssetsByRole("bacnet_controller")
+	dst := controllers[g.RandomInt(0, len(controllers)-1)]
+	src := otAssetsByRole("bms")[0]
+
+	service := g.WeightedChoice([]string{"ReadProperty", "ReadPropertyMultiple", "SubscribeCOV", "WriteProperty", "Who-Is"}, []float64{40, 25, 15, 15, 5})
+	objectType := g.RandomChoice([]string{"analog-input", "analog-value", "binary-output", "multi-state-value"})
+	var value, priority interface{}
+	if service == "WriteProperty" {
+		priority = 8
+		switch objectType {
+		case "binary-output":
+			value = g.RandomChoice([]string{"active", "inactive"})
+		case "multi-state-value":
+			value = g.RandomInt(1, 4)
+		default:
+			value = float64(g.RandomInt(180, 260)) / 10
+		}
+	}
+
+	fields := g.session(now, "session", "bacnet", src, dst, 47808)
+	fields["transport"] = "udp"
+	fields["src_port"] = 47808
+	fields["bacnet"] = map[string]interface{}{
+		"service":         service,
+		"pdu_type":        "Confirmed-REQ",
+		"device_instance": 100000 + g.RandomInt(1, 200),
+		"object_type":     objectType,
+		"object_instance": g.RandomInt(1, 64),
+		"property":        "present-value",
+		"priority":        priority,
+		"value":           value,
+	}
+	if service == "Who-Is" {
+		fields["bacnet"].(map[string]interface{})["pdu_type"] = "Unconfirmed-REQ"
+		fields["dst_ip"] = "172.20.5.255"
+	}
+	fields["packets"] = g.RandomInt(2, 40)
+	fields["bytes"] = g.RandomInt(100, 4000)
+	return fields
+}
+
+func (g *OTICSGenerator) opcuaSession(now time.Time) map[string]interface{} {
+	clients := otAssetsByRole("historian", "mes", "scada")
+	src := clients[g.RandomInt(0, len(clients)-1)]
+	dst := otAssetsByRole("opc_server")[0]
+
+	mode := g.WeightedChoice([]string{"SignAndEncrypt", "Sign", "None"}, []float64{70, 20, 10})
+	policy := "http://opcfoundation.org/UA/SecurityPolicy#Basic256Sha256"
+	if mode == "None" {
+		policy = "http://opcfoundation.org/UA/SecurityPolicy#None"
+	}
+
+	fields := g.session(now, "session", "opcua", src, dst, 4840)
+	fields["opcua"] = map[string]interface{}{
+		"endpoint_url":    fmt.Sprintf("opc.tcp://%s:4840", dst.name),
+		"service":         g.WeightedChoice([]string{"Read", "Browse", "CreateSubscription", "Publish", "CreateSession", "ActivateSession", "Write"}, []float64{35, 10, 10, 25, 8, 8, 4}),
+		"security_mode":   mode,
+		"security_policy": policy,
+		"node_id":         fmt.Sprintf("ns=2;s=Line%d.%s", g.RandomInt(1, 2), g.RandomChoice([]string{"Motor1.Speed", "Tank3.Level", "Boiler.Pressure", "Conveyor.Running", "Valve7.Position"})),
+		"status_code":     "Good",
+		"user_identity":   g.WeightedChoice([]string{"UserName", "Certificate", "Anonymous"}, []float64{55, 35, 10}),
+	}
+	fields["packets"] = g.RandomInt(6, 2000)
+	fields["bytes"] = g.RandomInt(800, 250000)
+	fields["duration"] = g.RandomFloat(0.1, 3600)
+	return fields
+}
+
+// alert returns the fields shared by every sensor alert
+func (g *OTICSGenerator) alert(fields map[string]interface{}, alertType, severity, title, description string) map[string]interface{} {
+	fields["event_type"] = "alert"
+	fields["alert_id"] = uuid.New().String()
+	fields["alert_type"] = alertType
+	fields["severity"] = severity
+	fields["title"] = title
+	fields["description"] = description
+	return fields
+}
+
+func (g *OTICSGenerator) unauthorizedWrite(now time.Time) map[string]interface{} {
+	plcs := otAssetsByRole("plc")
+	dst := plcs[g.RandomInt(0, len(plcs)-1)]
+
+	// Writes from an IT host that crossed into the control network, or from
+	// an HMI that only ever reads from this PLC
+	var src otAsset
+	if g.RandomInt(1, 100) <= 60 {
+		host := Entities.RandomHost(g.rnd, "windows")
+		src = otAsset{host.Hostname, host.IP, host.MAC, "", "", "unknown", "IT-Corporate"}
+	} else {
+		hmis := otAssetsByRole("hmi")
+		src = hmis[g.RandomInt(0, len(hmis)-1)]
+	}
+
+	code := []int{5, 6, 15, 16, 90}[g.RandomInt(0, 4)]
+	function := modbusFunctions[code]
+	title := "Unauthorized Modbus write"
+	if code == 90 {
+		// UMAS is Schneider's proprietary engineering protocol
+		dst = plcs[g.RandomInt(0, 1)]
+		function = "Schneider UMAS (Stop PLC)"
+		title = "PLC stop command"
+	}
+
+	fields := g.session(now, "alert", "modbus", src, dst, 502)
+	fields["modbus"] = map[string]interface{}{
+		"transaction_id": g.RandomInt(1, 65535),
+		"unit_id":        1,
+		"function_code":  code,
+		"function_name":  function,
+		"start_address":  40001 + g.RandomInt(0, 200),
+		"quantity":       g.RandomInt(1, 10),
+	}
+	return g.alert(fields, "unauthorized_write", "critical", title,
+		fmt.Sprintf("%s (%s) issued %s to %s (%s), which is not in the allowed writer list for this controller", src.name, src.ip, function, dst.name, dst.ip))
+}
+
+func (g *OTICSGenerator) newDevice(now time.Time) map[string]interface{} {
+	segments := []struct {
+		prefix, zone, protocol string
+		port                   int
+	}{
+		{"192.168.10.", "L1-Control", "modbus", 502},
+		{"192.168.20.", "L2-Supervisory", "opcua", 4840},
+		{"172.20.5.", "Building-Automation", "bacnet", 47808},
+	}
+	seg := segments[g.RandomInt(0, len(segments)-1)]
+	vendor := g.WeightedChoice([]string{"Raspberry Pi Foundation", "Siemens", "Moxa", "Unknown", "Dell"}, []float64{20, 25, 25, 15, 15})
+
+	device := otAsset{
+		name:   "",
+		ip:     fmt.Sprintf("%s%d", seg.prefix, g.RandomInt(150, 250)),
+		mac:    g.RandomMAC(),
+		vendor: vendor,
+		role:   "unknown",
+		zone:   seg.zone,
+	}
+	peers := otAssetsByRole("plc", "opc_server", "bacnet_controller")
+	var dst otAsset
+	for _, p := range peers {
+		if p.zone == seg.zone {
+			dst = p
+			break
+		}
+	}
+
+	fields := g.session(now, "alert", seg.protocol, device, dst, seg.port)
+	if seg.protocol == "bacnet" {
+		fields["transport"] = "udp"
+	}
+	fields["asset"] = map[string]interface{}{
+		"ip":         device.ip,
+		"mac":        device.mac,
+		"vendor":     vendor,
+		"first_seen": now.Format(time.RFC3339),
+		"zone":       seg.zone,
+		"protocols":  []string{seg.protocol},
+	}
+	return g.alert(fields, "new_device", g.WeightedChoice([]string{"high", "medium"}, []float64{40, 60}), "New asset discovered",
+		fmt.Sprintf("New %s device %s (%s) observed on %s communicating over %s", vendor, device.ip, device.mac, seg.zone, seg.protocol))
+}
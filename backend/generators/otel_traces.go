@@ -0,0 +1,433 @@
+package generators
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// OTLP span kinds and status codes
+const (
+	spanKindInternal = 1
+	spanKindServer   = 2
+	spanKindClient   = 3
+	spanKindProducer = 4
+
+	spanStatusOK    = 1
+	spanStatusError = 2
+)
+
+// OTelTraceGenerator generates distributed traces in OTLP JSON format across
+// the same services and hosts as the application metrics
+type OTelTraceGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&OTelTraceGenerator{})
+}
+
+// GetEventType returns the event type for OpenTelemetry traces
+func (g *OTelTraceGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "otel_traces",
+		Name:        "OpenTelemetry Traces",
+		Category:    "metrics",
+		Description: "Distributed traces (OTLP JSON) spanning the application services for APM correlation",
+		EventIDs:    []string{"checkout", "api_request", "error"},
+	}
+}
+
+// GetTemplates returns available templates for OpenTelemetry traces
+func (g *OTelTraceGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "checkout",
+			Name:        "Checkout Trace",
+			Category:    "otel_traces",
+			EventID:     "checkout",
+			Format:      "json",
+			Description: "Checkout flow across order, auth, inventory and payment services with a Kafka publish",
+			Sourcetype:  "otel:traces",
+		},
+		{
+			ID:          "api_request",
+			Name:        "API Request Trace",
+			Category:    "otel_traces",
+			EventID:     "api_request",
+			Format:      "json",
+			Description: "API request through a service and its cache, database and downstream calls",
+			Sourcetype:  "otel:traces",
+		},
+		{
+			ID:          "error",
+			Name:        "Failed Request Trace",
+			Category:    "otel_traces",
+			EventID:     "error",
+			Format:      "json",
+			Description: "API request where a downstream call fails and the error propagates to the caller",
+			Sourcetype:  "otel:traces",
+		},
+	}
+}
+
+// Generate creates an OpenTelemetry trace event
+func (g *OTelTraceGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "checkout":
+		return g.generateCheckout(overrides)
+	case "api_request":
+		return g.generateAPIRequest(overrides, false)
+	case "error":
+		return g.generateAPIRequest(overrides, true)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// traceBuilder accumulates the spans of one trace, grouped by service
+type traceBuilder struct {
+	g        *OTelTraceGenerator
+	traceID  string
+	env      string
+	hosts    map[string]string
+	services []string
+	spans    map[string][]map[string]interface{}
+}
+
+func (g *OTelTraceGenerator) newTrace() *traceBuilder {
+	return &traceBuilder{
+		g:       g,
+		traceID: g.RandomHex(16),
+		env:     g.WeightedChoice([]string{"production", "staging"}, []float64{85, 15}),
+		hosts:   make(map[string]string),
+		spans:   make(map[string][]map[string]interface{}),
+	}
+}
+
+// host returns the host serving a service for the whole trace
+func (b *traceBuilder) host(service string) string {
+	if h, ok := b.hosts[service]; ok {
+		return h
+	}
+	h := ServerName(fmt.Sprintf("app-%02d", b.g.RandomInt(1, 20)))
+	b.hosts[service] = h
+	b.services = append(b.services, service)
+	return h
+}
+
+// span records a span and returns its ID
+func (b *traceBuilder) span(service, parentID, name string, kind int, start time.Time, dur time.Duration, attrs map[string]interface{}, failure string) string {
+	b.host(service)
+	spanID := b.g.RandomHex(8)
+
+	span := map[string]interface{}{
+		"traceId":           b.traceID,
+		"spanId":            spanID,
+		"name":              name,
+		"kind":              kind,
+		"startTimeUnixNano": strconv.FormatInt(start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(start.Add(dur).UnixNano(), 10),
+		"attributes":        otlpAttributes(attrs),
+		"status":            map[string]interface{}{"code": spanStatusOK},
+	}
+	if parentID != "" {
+		span["parentSpanId"] = parentID
+	}
+	if failure != "" {
+		span["status"] = map[string]interface{}{"code": spanStatusError, "message": failure}
+		span["events"] = []map[string]interface{}{{
+			"name":         "exception",
+			"timeUnixNano": strconv.FormatInt(start.Add(dur).UnixNano(), 10),
+			"attributes": otlpAttributes(map[string]interface{}{
+				"exception.type":    "UpstreamServiceException",
+				"exception.message": failure,
+			}),
+		}}
+	}
+
+	b.spans[service] = append(b.spans[service], span)
+	return spanID
+}
+
+// resourceSpans renders the trace as OTLP resourceSpans, one resource per service
+func (b *traceBuilder) resourceSpans() []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(b.services))
+	for _, service := range b.services {
+		out = append(out, map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes(map[string]interface{}{
+					"service.name":           service,
+					"host.name":              b.hosts[service],
+					"deployment.environment": b.env,
+					"telemetry.sdk.language": "java",
+				}),
+			},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": "io.opentelemetry.instrumentation"},
+				"spans": b.spans[service],
+			}},
+		})
+	}
+	return out
+}
+
+// spanCount returns the number of spans recorded
+func (b *traceBuilder) spanCount() int {
+	n := 0
+	for _, s := range b.spans {
+		n += len(s)
+	}
+	return n
+}
+
+// otlpAttributes converts a map into sorted OTLP key/value attributes
+func otlpAttributes(attrs map[string]interface{}) []map[string]interface{} {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]map[string]interface{}, 0, len(keys))
+	for _, k := range keys {
+		var value map[string]interface{}
+		switch v := attrs[k].(type) {
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		out = append(out, map[string]interface{}{"key": k, "value": value})
+	}
+	return out
+}
+
+// latency returns a log-normal span duration around median milliseconds
+func (g *OTelTraceGenerator) latency(medianMs float64) time.Duration {
+	return time.Duration(g.RandomLogNormal(medianMs, 0.5) * float64(time.Millisecond))
+}
+
+// clientCall records a service-to-service call: a client span in the caller
+// and the matching server span in the callee. It returns the callee's server
+// span ID and start time, and the duration seen by the caller.
+func (b *traceBuilder) clientCall(caller, parentID, callee, name string, start time.Time, serverDur time.Duration, attrs map[string]interface{}, failure string) (string, time.Time, time.Duration) {
+	network := time.Duration(b.g.RandomInt(300, 2000)) * time.Microsecond
+	dur := serverDur + 2*network
+
+	clientID := b.span(caller, parentID, name, spanKindClient, start, dur, attrs, failure)
+
+	serverAttrs := map[string]interface{}{
+		"http.request.method":       attrs["http.request.method"],
+		"http.route":                attrs["url.path"],
+		"http.response.status_code": attrs["http.response.status_code"],
+	}
+	serverID := b.span(callee, clientID, name, spanKindServer, start.Add(network), serverDur, serverAttrs, failure)
+	return serverID, start.Add(network), dur
+}
+
+// dbCall records a database client span lasting dur and returns dur
+func (b *traceBuilder) dbCall(service, parentID string, start time.Time, dur time.Duration, table, op string) time.Duration {
+	b.span(service, parentID, op+" "+table, spanKindClient, start, dur, map[string]interface{}{
+		"db.system":      "postgresql",
+		"db.name":        "orders_db",
+		"db.operation":   op,
+		"db.sql.table":   table,
+		"server.address": ServerName(fmt.Sprintf("db-primary-%02d", b.g.RandomInt(1, 5))),
+		"server.port":    5432,
+	}, "")
+	return dur
+}
+
+// cacheCall records a Redis client span
+func (b *traceBuilder) cacheCall(service, parentID string, start time.Time, key string) time.Duration {
+	dur := b.g.latency(0.6)
+	b.span(service, parentID, "GET", spanKindClient, start, dur, map[string]interface{}{
+		"db.system":      "redis",
+		"db.operation":   "GET",
+		"db.statement":   "GET " + key,
+		"server.address": ServerName(fmt.Sprintf("cache-%02d", b.g.RandomInt(1, 20))),
+		"server.port":    6379,
+	}, "")
+	return dur
+}
+
+// httpAttrs builds client attributes for a service-to-service call
+func httpAttrs(callee, method, path string, status int) map[string]interface{} {
+	return map[string]interface{}{
+		"http.request.method":       method,
+		"url.path":                  path,
+		"server.address":            callee,
+		"http.response.status_code": status,
+		"peer.service":              callee,
+	}
+}
+
+func (g *OTelTraceGenerator) generateCheckout(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	b := g.newTrace()
+	end := time.Now()
+
+	// Child calls run sequentially inside the root span; build them first
+	// from a provisional start and shift the root to fit
+	start := end.Add(-2 * time.Second)
+	rootID := b.g.RandomHex(8)
+	cursor := start.Add(time.Duration(g.RandomInt(1, 3)) * time.Millisecond)
+
+	_, _, d := b.clientCall("order-service", rootID, "auth-service", "POST /api/v1/auth/validate", cursor, g.latency(8), httpAttrs("auth-service", "POST", "/api/v1/auth/validate", 200), "")
+	cursor = cursor.Add(d)
+	cursor = cursor.Add(b.dbCall("order-service", rootID, cursor, g.latency(4), "carts", "SELECT"))
+
+	stockDur := g.latency(4)
+	invID, invStart, d := b.clientCall("order-service", rootID, "inventory-service", "POST /api/v1/inventory/reserve", cursor, stockDur+g.latency(3), httpAttrs("inventory-service", "POST", "/api/v1/inventory/reserve", 200), "")
+	b.dbCall("inventory-service", invID, invStart.Add(500*time.Microsecond), stockDur, "stock", "UPDATE")
+	cursor = cursor.Add(d)
+
+	payDur := g.latency(180)
+	payID, _, d := b.clientCall("order-service", rootID, "payment-service", "POST /api/v1/payments", cursor, payDur, httpAttrs("payment-service", "POST", "/api/v1/payments", 201), "")
+	b.span("payment-service", payID, "POST https://api.stripe.com/v1/charges", spanKindClient, cursor.Add(2*time.Millisecond), payDur-2*time.Millisecond, map[string]interface{}{
+		"http.request.method":       "POST",
+		"server.address":            "api.stripe.com",
+		"http.response.status_code": 200,
+	}, "")
+	cursor = cursor.Add(d)
+	cursor = cursor.Add(b.dbCall("order-service", rootID, cursor, g.latency(4), "orders", "INSERT"))
+
+	pubDur := g.latency(2)
+	b.span("order-service", rootID, "order.created publish", spanKindProducer, cursor, pubDur, map[string]interface{}{
+		"messaging.system":           "kafka",
+		"messaging.destination.name": "order.created",
+		"messaging.operation":        "publish",
+	}, "")
+	cursor = cursor.Add(pubDur)
+
+	rootDur := cursor.Sub(start) + time.Millisecond
+	host := b.host("order-service")
+	b.spans["order-service"] = append([]map[string]interface{}{{
+		"traceId":           b.traceID,
+		"spanId":            rootID,
+		"name":              "POST /api/v1/checkout",
+		"kind":              spanKindServer,
+		"startTimeUnixNano": strconv.FormatInt(start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(start.Add(rootDur).UnixNano(), 10),
+		"attributes": otlpAttributes(map[string]interface{}{
+			"http.request.method":       "POST",
+			"http.route":                "/api/v1/checkout",
+			"http.response.status_code": 200,
+			"client.address":            g.RandomIPv4External(),
+		}),
+		"status": map[string]interface{}{"code": spanStatusOK},
+	}}, b.spans["order-service"]...)
+
+	return g.buildEvent(b, "checkout", host, "order-service", "POST", "/api/v1/checkout", 200, start, rootDur, overrides)
+}
+
+func (g *OTelTraceGenerator) generateAPIRequest(overrides map[string]interface{}, fail bool) (*models.GeneratedEvent, error) {
+	b := g.newTrace()
+	service := g.ZipfChoice(appServices)
+	method := g.WeightedChoice([]string{"GET", "POST", "PUT"}, []float64{70, 22, 8})
+	endpoint := g.RandomChoice([]string{"/api/v1/orders", "/api/v1/users", "/api/v1/products", "/api/v1/cart"})
+
+	start := time.Now().Add(-time.Second)
+	rootID := g.RandomHex(8)
+	cursor := start.Add(time.Duration(g.RandomInt(200, 900)) * time.Microsecond)
+
+	// Cache lookup, then a database query on a miss
+	cursor = cursor.Add(b.cacheCall(service, rootID, cursor, fmt.Sprintf("%s:%d", endpoint[8:], g.RandomInt(1000, 99999))))
+	if g.RandomInt(0, 99) < 40 {
+		cursor = cursor.Add(b.dbCall(service, rootID, cursor, g.latency(4), endpoint[8:], "SELECT"))
+	}
+
+	// Downstream call; in the error template it fails and the error propagates
+	downstream := g.RandomChoice(appServices)
+	for downstream == service {
+		downstream = g.RandomChoice(appServices)
+	}
+	status := 200
+	failure := ""
+	if fail {
+		codes := []int{500, 502, 503, 504}
+		status = codes[g.weightedIndex([]float64{50, 15, 20, 15})]
+		failure = fmt.Sprintf("%s returned %d", downstream, status)
+	}
+	path := "/api/v1/" + downstream[:len(downstream)-len("-service")]
+	serverDur := g.latency(12)
+	if status == 504 {
+		serverDur = 30 * time.Second
+	}
+	_, _, d := b.clientCall(service, rootID, downstream, method+" "+path, cursor, serverDur, httpAttrs(downstream, method, path, status), failure)
+	cursor = cursor.Add(d)
+
+	rootDur := cursor.Sub(start) + time.Duration(g.RandomInt(200, 2000))*time.Microsecond
+	rootStatus := map[string]interface{}{"code": spanStatusOK}
+	rootCode := 200
+	if fail {
+		rootCode = 500
+		if status == 503 || status == 504 {
+			rootCode = status
+		}
+		rootStatus = map[string]interface{}{"code": spanStatusError, "message": failure}
+	}
+	host := b.host(service)
+	b.spans[service] = append([]map[string]interface{}{{
+		"traceId":           b.traceID,
+		"spanId":            rootID,
+		"name":              method + " " + endpoint,
+		"kind":              spanKindServer,
+		"startTimeUnixNano": strconv.FormatInt(start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(start.Add(rootDur).UnixNano(), 10),
+		"attributes": otlpAttributes(map[string]interface{}{
+			"http.request.method":       method,
+			"http.route":                endpoint,
+			"http.response.status_code": rootCode,
+			"client.address":            g.RandomIPv4Internal(),
+		}),
+		"status": rootStatus,
+	}}, b.spans[service]...)
+
+	templateID := "api_request"
+	if fail {
+		templateID = "error"
+	}
+	return g.buildEvent(b, templateID, host, service, method, endpoint, rootCode, start, rootDur, overrides)
+}
+
+// buildEvent wraps a finished trace in a GeneratedEvent whose fields summarise the root span
+func (g *OTelTraceGenerator) buildEvent(b *traceBuilder, templateID, host, service, method, endpoint string, status int, start time.Time, dur time.Duration, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	resourceSpans := b.resourceSpans()
+
+	fields := map[string]interface{}{
+		"trace_id":    b.traceID,
+		"service":     service,
+		"host":        host,
+		"environment": b.env,
+		"http_method": method,
+		"endpoint":    endpoint,
+		"status_code": status,
+		"error":       status >= 500,
+		"duration_ms": float64(dur.Microseconds()) / 1000,
+		"span_count":  b.spanCount(),
+		"services":    append([]string(nil), b.services...),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, _ := g.marshalRawJSON(map[string]interface{}{"resourceSpans": resourceSpans})
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "otel_traces",
+		EventID:    templateID,
+		Timestamp:  start,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "otel:traces",
+	}, nil
+}
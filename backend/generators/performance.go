@@ -0,0 +1,85 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"sync"
+	"sync/atomic"
+
+	"siem-event-generator/models"
+)
+
+// performanceMode makes generators render raw events compactly instead of
+// pretty-printed. Compact output is what most SIEMs ingest one event per
+// line and is several times cheaper to produce at high EPS.
+var performanceMode atomic.Bool
+
+// SetPerformanceMode switches raw event rendering between compact
+// (performance mode) and indented output
+func SetPerformanceMode(enabled bool) {
+	performanceMode.Store(enabled)
+}
+
+// PerformanceMode reports whether raw events are rendered compactly
+func PerformanceMode() bool {
+	return performanceMode.Load()
+}
+
+// bufferPool reuses encoding buffers across events
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// marshalRawJSON renders v as the raw form of a JSON event, unless b keeps
+// only the fields of its events
+func (b *BaseGenerator) marshalRawJSON(v interface{}) ([]byte, error) {
+	if b.output == models.OutputFields {
+		// Callers may still parse the result
+		return []byte("{}"), nil
+	}
+	return renderRawJSON(v)
+}
+
+// renderRawJSON renders v as the raw form of a JSON event: indented unless
+// performance mode is on. HTML characters are escaped as json.Marshal does.
+func renderRawJSON(v interface{}) ([]byte, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	if performanceMode.Load() {
+		b, err := appendCompactJSON(buf.AvailableBuffer(), v)
+		if err != nil {
+			return nil, err
+		}
+		// Keep the grown slice for the next event
+		buf.Write(b)
+		return append([]byte(nil), b...), nil
+	}
+
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	// Encode terminates the value with a newline that Marshal does not add
+	return append([]byte(nil), bytes.TrimSuffix(buf.Bytes(), []byte("\n"))...), nil
+}
+
+// marshalRawXML renders v as the raw form of an XML event: indented unless
+// performance mode is on
+func marshalRawXML(v interface{}) ([]byte, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	enc := xml.NewEncoder(buf)
+	if !performanceMode.Load() {
+		enc.Indent("", "  ")
+	}
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), buf.Bytes()...), nil
+}
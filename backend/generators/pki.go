@@ -0,0 +1,291 @@
+package generators
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// pkiConfig holds the certificate settings: 2% of connections see an
+// expired certificate and 3% a self-signed one by default
+var pkiConfig = struct {
+	sync.RWMutex
+	models.PKIConfig
+}{PKIConfig: models.PKIConfig{ExpiredRate: 2, SelfSignedRate: 3}}
+
+// PKIConfig returns the certificate settings
+func PKIConfig() models.PKIConfig {
+	pkiConfig.RLock()
+	defer pkiConfig.RUnlock()
+	return pkiConfig.PKIConfig
+}
+
+// SetPKIConfig replaces the certificate settings
+func SetPKIConfig(cfg models.PKIConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	pkiConfig.Lock()
+	defer pkiConfig.Unlock()
+	pkiConfig.PKIConfig = cfg
+	return nil
+}
+
+// certName is the distinguished name of a certificate's subject or issuer
+type certName struct {
+	CommonName   string `json:"common_name"`
+	Organization string `json:"organization,omitempty"`
+	Country      string `json:"country,omitempty"`
+}
+
+// String formats the name the way Zeek does, most specific first
+func (n certName) String() string {
+	parts := []string{"CN=" + n.CommonName}
+	if n.Organization != "" {
+		parts = append(parts, "O="+n.Organization)
+	}
+	if n.Country != "" {
+		parts = append(parts, "C="+n.Country)
+	}
+	return strings.Join(parts, ",")
+}
+
+// suricataDN formats the name the way Suricata does, least specific first
+func (n certName) suricataDN() string {
+	var parts []string
+	if n.Country != "" {
+		parts = append(parts, "C="+n.Country)
+	}
+	if n.Organization != "" {
+		parts = append(parts, "O="+n.Organization)
+	}
+	return strings.Join(append(parts, "CN="+n.CommonName), ", ")
+}
+
+func (n certName) pkix() pkix.Name {
+	name := pkix.Name{CommonName: n.CommonName}
+	if n.Organization != "" {
+		name.Organization = []string{n.Organization}
+	}
+	if n.Country != "" {
+		name.Country = []string{n.Country}
+	}
+	return name
+}
+
+// certAuthority is a public issuing CA and the root that signed it
+type certAuthority struct {
+	root, issuer certName
+	validity     int // Days its server certificates are valid
+	serialBytes  int
+
+	once sync.Once
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+var certAuthorities = []*certAuthority{
+	{root: certName{"ISRG Root X2", "Internet Security Research Group", "US"}, issuer: certName{"E6", "Let's Encrypt", "US"}, validity: 90, serialBytes: 18},
+	{root: certName{"DigiCert Global Root G3", "DigiCert Inc", "US"}, issuer: certName{"DigiCert Global G3 TLS ECC SHA384 2020 CA1", "DigiCert Inc", "US"}, validity: 397, serialBytes: 16},
+	{root: certName{"GTS Root R4", "Google Trust Services LLC", "US"}, issuer: certName{"WE1", "Google Trust Services", "US"}, validity: 84, serialBytes: 16},
+	{root: certName{"USERTrust ECC Certification Authority", "The USERTrust Network", "US"}, issuer: certName{"Sectigo ECC Domain Validation Secure Server CA", "Sectigo Limited", "GB"}, validity: 365, serialBytes: 16},
+}
+
+// selfSignedName is the subject of self-signed certificates besides the
+// host, OpenSSL's defaults
+var selfSignedName = certName{Organization: "Internet Widgits Pty Ltd", Country: "AU"}
+
+// Certificate is the certificate a server presents
+type Certificate struct {
+	Subject    certName  `json:"subject"`
+	Issuer     certName  `json:"issuer"`
+	Serial     string    `json:"serial"` // Uppercase hex
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+	SHA1       string    `json:"sha1"`
+	SHA256     string    `json:"sha256"`
+	ChainLen   int       `json:"chain_length"` // Certificates the server sends
+	Expired    bool      `json:"expired"`
+	SelfSigned bool      `json:"self_signed"`
+}
+
+// ValidationStatus is the result of validating the certificate, as Zeek
+// reports it
+func (c Certificate) ValidationStatus() string {
+	switch {
+	case c.SelfSigned:
+		return "self signed certificate"
+	case c.Expired:
+		return "certificate has expired"
+	}
+	return "ok"
+}
+
+// serverCertificate returns the certificate host presents. A host always
+// has the same CA and renews on a schedule, so its issuer, serial and
+// validity agree across events; at the configured rates the connection
+// sees an expired or self-signed certificate instead.
+func (b *BaseGenerator) serverCertificate(host string, now time.Time) Certificate {
+	host = strings.ToLower(host)
+	seed := sha256.Sum256([]byte(host))
+	ca := certAuthorities[binary.BigEndian.Uint32(seed[:4])%uint32(len(certAuthorities))]
+	phase := time.Duration(binary.BigEndian.Uint32(seed[4:8])) * time.Second
+
+	cfg := PKIConfig()
+	roll := b.rnd.float64() * 100
+	cert := Certificate{Subject: certName{CommonName: host}, Issuer: ca.issuer, ChainLen: 2}
+	validity := time.Duration(ca.validity) * 24 * time.Hour
+	switch {
+	case roll < cfg.ExpiredRate:
+		// The certificate that was current a lifetime ago, never renewed
+		cert.NotBefore = renewedAt(now.Add(-validity), validity*2/3, phase)
+		cert.Expired = true
+	case roll < cfg.ExpiredRate+cfg.SelfSignedRate:
+		validity = 3650 * 24 * time.Hour
+		cert.NotBefore = renewedAt(now, validity, phase)
+		cert.Subject.Organization, cert.Subject.Country = selfSignedName.Organization, selfSignedName.Country
+		cert.Issuer, cert.ChainLen, cert.SelfSigned = cert.Subject, 1, true
+	default:
+		cert.NotBefore = renewedAt(now, validity*2/3, phase)
+	}
+	cert.NotAfter = cert.NotBefore.Add(validity)
+
+	serialBytes := ca.serialBytes
+	if cert.SelfSigned {
+		serialBytes = 20
+	}
+	serial := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", host, cert.Issuer, cert.NotBefore.Unix())))
+	serial[0] &= 0x7f // Serials are positive
+	cert.Serial = strings.ToUpper(hex.EncodeToString(serial[:serialBytes]))
+
+	if cfg.GenerateCertificates {
+		if der, err := signCertificate(ca, cert); err == nil {
+			sum1, sum256 := sha1.Sum(der), sha256.Sum256(der)
+			cert.SHA1, cert.SHA256 = hex.EncodeToString(sum1[:]), hex.EncodeToString(sum256[:])
+			return cert
+		}
+	}
+	tbs := []byte(fmt.Sprintf("%s|%s|%s|%d|%d", cert.Subject, cert.Issuer, cert.Serial, cert.NotBefore.Unix(), cert.NotAfter.Unix()))
+	sum1, sum256 := sha1.Sum(tbs), sha256.Sum256(tbs)
+	cert.SHA1, cert.SHA256 = hex.EncodeToString(sum1[:]), hex.EncodeToString(sum256[:])
+	return cert
+}
+
+// renewedAt returns when a certificate renewed every period, offset by
+// phase, was last issued before now
+func renewedAt(now time.Time, period, phase time.Duration) time.Time {
+	phase %= period
+	since := now.Add(-phase).UnixNano() % int64(period)
+	return now.Add(-time.Duration(since)).Truncate(time.Second).UTC()
+}
+
+// leafKey is the key of every generated server certificate
+var leafKey struct {
+	once sync.Once
+	key  *ecdsa.PrivateKey
+}
+
+// signedCerts caches generated certificates by serial, so a server keeps
+// presenting the same one
+var signedCerts = struct {
+	sync.Mutex
+	der map[string][]byte
+}{der: make(map[string][]byte)}
+
+// maxSignedCerts bounds the certificate cache; it starts over when full
+const maxSignedCerts = 4096
+
+// signCertificate returns the DER encoding of a real X.509 certificate with
+// cert's names, serial and validity, signed by the CA or by itself
+func signCertificate(ca *certAuthority, cert Certificate) ([]byte, error) {
+	signedCerts.Lock()
+	defer signedCerts.Unlock()
+	if der, ok := signedCerts.der[cert.Serial]; ok {
+		return der, nil
+	}
+
+	leafKey.once.Do(func() { leafKey.key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader) })
+	if err := ca.init(); err != nil {
+		return nil, err
+	}
+	serial, _ := new(big.Int).SetString(cert.Serial, 16)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      cert.Subject.pkix(),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+		DNSNames:     []string{cert.Subject.CommonName},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	parent, signer := ca.cert, ca.key
+	if cert.SelfSigned {
+		parent, signer = template, leafKey.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &leafKey.key.PublicKey, signer)
+	if err != nil {
+		return nil, err
+	}
+	if len(signedCerts.der) >= maxSignedCerts {
+		signedCerts.der = make(map[string][]byte)
+	}
+	signedCerts.der[cert.Serial] = der
+	return der, nil
+}
+
+// init generates the CA's root and issuing certificates the first time a
+// server certificate is signed
+func (ca *certAuthority) init() error {
+	var err error
+	ca.once.Do(func() {
+		var rootKey *ecdsa.PrivateKey
+		if rootKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader); err != nil {
+			return
+		}
+		if ca.key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			return
+		}
+		issued := time.Now().AddDate(-2, 0, 0).Truncate(24 * time.Hour)
+		root := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               ca.root.pkix(),
+			NotBefore:             issued.AddDate(-5, 0, 0),
+			NotAfter:              issued.AddDate(20, 0, 0),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		issuer := &x509.Certificate{
+			SerialNumber:          big.NewInt(2),
+			Subject:               ca.issuer.pkix(),
+			NotBefore:             issued,
+			NotAfter:              issued.AddDate(5, 0, 0),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+			MaxPathLenZero:        true,
+		}
+		var der []byte
+		if der, err = x509.CreateCertificate(rand.Reader, issuer, root, &ca.key.PublicKey, rootKey); err != nil {
+			return
+		}
+		ca.cert, err = x509.ParseCertificate(der)
+	})
+	if ca.cert == nil && err == nil {
+		err = fmt.Errorf("certificate authority %s unavailable", ca.issuer.CommonName)
+	}
+	return err
+}
@@ -0,0 +1,440 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// pluginTimeout bounds one request to a process plugin. A plugin that does
+// not answer in time is stopped and has to be reloaded.
+const pluginTimeout = 10 * time.Second
+
+// PluginManager loads generator plugins from a directory and registers
+// their generators. Go plugins are .so files built with -buildmode=plugin
+// that export
+//
+//	var GeneratorAPIVersion = generators.GeneratorAPIVersion
+//	func NewGenerator() generators.Generator
+//
+// Any other executable file is started as a process plugin and sent
+// models.PluginRequest lines on stdin, answering each with a
+// models.PluginResponse line on stdout; ServePlugin does this for a Go
+// Generator.
+type PluginManager struct {
+	mu      sync.Mutex
+	dir     string
+	plugins map[string]*loadedPlugin
+}
+
+// loadedPlugin is a plugin file and the generator it registered, if any
+type loadedPlugin struct {
+	info models.GeneratorPlugin
+	gen  Generator
+}
+
+// Plugins is the global plugin manager
+var Plugins = &PluginManager{plugins: make(map[string]*loadedPlugin)}
+
+// SetDir sets the directory plugins are loaded from
+func (m *PluginManager) SetDir(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dir = dir
+}
+
+// Dir returns the plugin directory, empty when plugins are off
+func (m *PluginManager) Dir() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.dir
+}
+
+// List returns the plugins found so far, ordered by file name
+func (m *PluginManager) List() []models.GeneratorPlugin {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]models.GeneratorPlugin, 0, len(m.plugins))
+	for _, p := range m.plugins {
+		list = append(list, p.info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Scan loads every plugin in the directory that is not loaded yet, retrying
+// those that failed, and returns all plugins
+func (m *PluginManager) Scan() ([]models.GeneratorPlugin, error) {
+	dir := m.Dir()
+	if dir == "" {
+		return nil, fmt.Errorf("no plugin directory is set")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		m.mu.Lock()
+		p, ok := m.plugins[e.Name()]
+		m.mu.Unlock()
+		if ok && p.info.Status == models.PluginLoaded {
+			continue
+		}
+		if pluginKind(dir, e) != "" {
+			m.Load(e.Name())
+		}
+	}
+	return m.List(), nil
+}
+
+// pluginKind returns the kind of plugin a directory entry is, or "" when it
+// is not a plugin
+func pluginKind(dir string, e os.DirEntry) string {
+	if strings.HasPrefix(e.Name(), ".") {
+		return ""
+	}
+	info, err := os.Stat(filepath.Join(dir, e.Name()))
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	if strings.HasSuffix(e.Name(), ".so") {
+		return models.PluginKindGo
+	}
+	if info.Mode()&0111 != 0 {
+		return models.PluginKindProcess
+	}
+	return ""
+}
+
+// Load loads one file from the plugin directory and registers its
+// generator. A failed load is listed with its error.
+func (m *PluginManager) Load(file string) (models.GeneratorPlugin, error) {
+	dir := m.Dir()
+	if dir == "" {
+		return models.GeneratorPlugin{}, fmt.Errorf("no plugin directory is set")
+	}
+	if file != filepath.Base(file) || file == "." || file == ".." {
+		return models.GeneratorPlugin{}, fmt.Errorf("plugin file must be a name in the plugin directory")
+	}
+	m.mu.Lock()
+	if p, ok := m.plugins[file]; ok && p.info.Status == models.PluginLoaded {
+		m.mu.Unlock()
+		return p.info, fmt.Errorf("plugin %s is already loaded", file)
+	}
+	m.mu.Unlock()
+
+	path := filepath.Join(dir, file)
+	info := models.GeneratorPlugin{ID: file, Kind: models.PluginKindProcess}
+	var gen Generator
+	var err error
+	if strings.HasSuffix(file, ".so") {
+		info.Kind = models.PluginKindGo
+		gen, err = openGoPlugin(path)
+	} else {
+		gen, err = startProcessPlugin(path)
+	}
+	if err == nil {
+		if err = registerNew(gen); err != nil {
+			closeGenerator(gen)
+		}
+	}
+
+	if err != nil {
+		gen = nil
+		info.Status = models.PluginFailed
+		info.Error = err.Error()
+	} else {
+		et := gen.GetEventType()
+		now := time.Now()
+		info.Status = models.PluginLoaded
+		info.APIVersion = GeneratorAPIVersion
+		info.EventType = &et
+		info.Templates = len(gen.GetTemplates())
+		info.LoadedAt = &now
+	}
+	m.mu.Lock()
+	m.plugins[file] = &loadedPlugin{info: info, gen: gen}
+	m.mu.Unlock()
+	return info, err
+}
+
+// Reload restarts a process plugin, picking up a new build of it. Go
+// plugins stay in memory once opened and are replaced only by a restart.
+func (m *PluginManager) Reload(id string) (models.GeneratorPlugin, error) {
+	m.mu.Lock()
+	p, ok := m.plugins[id]
+	m.mu.Unlock()
+	if !ok {
+		return models.GeneratorPlugin{}, errPluginNotFound
+	}
+	if p.info.Kind == models.PluginKindGo && p.info.Status == models.PluginLoaded {
+		return p.info, fmt.Errorf("Go plugins cannot be reloaded; restart the server to load a new build")
+	}
+	m.Unload(id)
+	return m.Load(id)
+}
+
+// errPluginNotFound is returned for plugin IDs that were never loaded
+var errPluginNotFound = fmt.Errorf("plugin not found")
+
+// IsPluginNotFound reports whether err means the plugin does not exist
+func IsPluginNotFound(err error) bool {
+	return err == errPluginNotFound
+}
+
+// Unload unregisters a plugin's generator and stops its process. The file
+// is loaded again by the next scan.
+func (m *PluginManager) Unload(id string) bool {
+	m.mu.Lock()
+	p, ok := m.plugins[id]
+	delete(m.plugins, id)
+	m.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if p.gen != nil {
+		unregister(p.gen.GetEventType().ID)
+		closeGenerator(p.gen)
+	}
+	return true
+}
+
+// Close stops every process plugin
+func (m *PluginManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.plugins {
+		if p.gen != nil {
+			closeGenerator(p.gen)
+		}
+	}
+}
+
+// closeGenerator stops a process plugin's process
+func closeGenerator(g Generator) {
+	if pg, ok := g.(*processGenerator); ok {
+		pg.close()
+	}
+}
+
+// openGoPlugin opens a Go plugin and creates its generator
+func openGoPlugin(path string) (Generator, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup("GeneratorAPIVersion")
+	if err != nil {
+		return nil, err
+	}
+	version, ok := sym.(*int)
+	if !ok {
+		return nil, fmt.Errorf("GeneratorAPIVersion has type %T, want int", sym)
+	}
+	if *version != GeneratorAPIVersion {
+		return nil, fmt.Errorf("plugin is built for generator API version %d, this server supports %d", *version, GeneratorAPIVersion)
+	}
+	sym, err = p.Lookup("NewGenerator")
+	if err != nil {
+		return nil, err
+	}
+	newGenerator, ok := sym.(func() Generator)
+	if !ok {
+		return nil, fmt.Errorf("NewGenerator has type %T, want func() generators.Generator", sym)
+	}
+	return newGenerator(), nil
+}
+
+// processGenerator generates events by asking a plugin process, one request
+// at a time
+type processGenerator struct {
+	eventType models.EventType
+	templates []models.EventTemplate
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	enc    *json.Encoder
+	dec    *json.Decoder
+	exited bool
+}
+
+// startProcessPlugin starts a plugin process and asks it to describe itself
+func startProcessPlugin(path string) (Generator, error) {
+	cmd := exec.Command(path)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	g := &processGenerator{
+		cmd:   cmd,
+		stdin: stdin,
+		enc:   json.NewEncoder(stdin),
+		dec:   json.NewDecoder(stdout),
+	}
+
+	resp, err := g.call(models.PluginRequest{Method: models.PluginDescribe})
+	if err == nil && resp.APIVersion != GeneratorAPIVersion {
+		err = fmt.Errorf("plugin speaks generator API version %d, this server supports %d", resp.APIVersion, GeneratorAPIVersion)
+	}
+	if err == nil && (resp.EventType == nil || resp.EventType.ID == "") {
+		err = fmt.Errorf("plugin did not describe its event type")
+	}
+	if err != nil {
+		g.close()
+		return nil, err
+	}
+	g.eventType = *resp.EventType
+	g.templates = resp.Templates
+	return g, nil
+}
+
+// call sends one request and reads the answer, stopping the process if it
+// does not answer within pluginTimeout
+func (g *processGenerator) call(req models.PluginRequest) (*models.PluginResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.exited {
+		return nil, fmt.Errorf("plugin process has exited; reload the plugin")
+	}
+
+	timer := time.AfterFunc(pluginTimeout, func() { g.cmd.Process.Kill() })
+	defer timer.Stop()
+	var resp models.PluginResponse
+	err := g.enc.Encode(req)
+	if err == nil {
+		err = g.dec.Decode(&resp)
+	}
+	if err != nil {
+		g.exited = true
+		g.cmd.Process.Kill()
+		go g.cmd.Wait()
+		return nil, fmt.Errorf("plugin process: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// close closes the process's stdin, which tells it to exit, and kills it
+// if it is still running after a moment
+func (g *processGenerator) close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.exited {
+		return
+	}
+	g.exited = true
+	g.stdin.Close()
+	done := make(chan struct{})
+	go func() {
+		g.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		g.cmd.Process.Kill()
+	}
+}
+
+func (g *processGenerator) GetEventType() models.EventType {
+	return g.eventType
+}
+
+func (g *processGenerator) GetTemplates() []models.EventTemplate {
+	return g.templates
+}
+
+// Generate asks the plugin for an event, filling in the ID, type, time and
+// sourcetype when the plugin leaves them out
+func (g *processGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	resp, err := g.call(models.PluginRequest{Method: models.PluginGenerate, TemplateID: templateID, Overrides: overrides})
+	if err != nil {
+		return nil, err
+	}
+	event := resp.Event
+	if event == nil {
+		return nil, fmt.Errorf("plugin returned no event")
+	}
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Type == "" {
+		event.Type = g.eventType.ID
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Sourcetype == "" {
+		for _, t := range g.templates {
+			if t.ID == templateID {
+				event.Sourcetype = t.Sourcetype
+			}
+		}
+	}
+	return event, nil
+}
+
+// ServePlugin runs g as a process plugin, answering requests on stdin until
+// it is closed. A plugin's main function only needs to call it:
+//
+//	func main() {
+//		if err := generators.ServePlugin(&MyGenerator{}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func ServePlugin(g Generator) error {
+	dec := json.NewDecoder(os.Stdin)
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		var req models.PluginRequest
+		if err := dec.Decode(&req); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var resp models.PluginResponse
+		switch req.Method {
+		case models.PluginDescribe:
+			et := g.GetEventType()
+			resp.APIVersion = GeneratorAPIVersion
+			resp.EventType = &et
+			resp.Templates = g.GetTemplates()
+		case models.PluginGenerate:
+			event, err := g.Generate(req.TemplateID, req.Overrides)
+			if err != nil {
+				resp.Error = err.Error()
+			}
+			resp.Event = event
+		default:
+			resp.Error = fmt.Sprintf("unknown method %q", req.Method)
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package generators
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// PrintAuditGenerator generates Windows Print Service Operational events
+// from each office's print server. Jobs are printed by pool users from
+// their workstations to a printer at their office.
+type PrintAuditGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&PrintAuditGenerator{})
+}
+
+// printDocuments are documents users print, by department. {name} is the
+// user's name and {n} a number.
+var printDocuments = map[string][]string{
+	"Engineering": {"Design Review - Payments API.pdf", "Sprint Planning Notes.docx", "architecture-diagram.png", "On-call Runbook.pdf"},
+	"Finance":     {"Q3 Forecast.xlsx", "Expense Report - {name}.pdf", "Invoice {n}.pdf", "Vendor Payment Run.xlsx"},
+	"Sales":       {"Proposal - Globex Renewal.docx", "Pipeline Review.pptx", "Quote {n}.pdf", "Territory Plan.xlsx"},
+	"Marketing":   {"Campaign Brief.docx", "Brand Guidelines.pdf", "Event Flyer.pdf", "Webinar Slides.pptx"},
+	"HR":          {"Offer Letter - {name}.docx", "Onboarding Checklist.pdf", "Benefits Overview.pdf", "Interview Schedule.xlsx"},
+	"IT":          {"Change Request {n}.pdf", "Network Diagram.vsdx", "Asset Inventory.xlsx", "Microsoft Word - Runbook.docx"},
+	"Legal":       {"NDA - {name}.docx", "Master Services Agreement.pdf", "Contract Redline.docx", "Board Minutes.pdf"},
+	"Operations":  {"Shift Roster.xlsx", "Shipping Manifest {n}.pdf", "Facilities Checklist.docx", "Incident Report.pdf"},
+}
+
+// sensitiveDocuments are documents an insider prints before leaving
+var sensitiveDocuments = []string{
+	"CONFIDENTIAL - Customer List Export.xlsx",
+	"Salary Review {n} - All Employees.xlsx",
+	"Product Roadmap - Internal Only.pptx",
+	"M&A Target Analysis - Restricted.pptx",
+	"Source Code - payment-service.pdf",
+	"Employee Records Export.csv",
+	"Pricing Model - Confidential.xlsx",
+}
+
+// GetEventType returns the event type for Print Service events
+func (g *PrintAuditGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "print_audit",
+		Name:        "Windows Print Service",
+		Category:    "physical",
+		Description: "Print job audit events from the Print Service Operational log of each office's print server",
+		EventIDs:    []string{"307"},
+	}
+}
+
+// GetTemplates returns available templates for Print Service events
+func (g *PrintAuditGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "307",
+			Name:        "Document Printed",
+			Category:    "print_audit",
+			EventID:     "307",
+			Format:      "xml",
+			Description: "A user prints a document of their department at their office",
+		},
+		{
+			ID:          "307_bulk",
+			Name:        "Bulk Print of Sensitive Document",
+			Category:    "print_audit",
+			EventID:     "307",
+			Format:      "xml",
+			Description: "A user prints hundreds of pages of a confidential document, often off shift",
+		},
+	}
+}
+
+// Generate creates a Print Service event
+func (g *PrintAuditGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "307":
+		return g.generate307(false, overrides)
+	case "307_bulk":
+		return g.generate307(true, overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// WindowsDocumentPrintedEvent is the layout of event 307, which the print
+// spooler writes with UserData instead of EventData
+type WindowsDocumentPrintedEvent struct {
+	XMLName  xml.Name `xml:"Event"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	System   WindowsEventSystem
+	UserData struct {
+		DocumentPrinted struct {
+			Xmlns  string `xml:"xmlns,attr"`
+			Param1 string // Job ID
+			Param2 string // Document name
+			Param3 string // User
+			Param4 string // Client computer
+			Param5 string // Printer
+			Param6 string // Printer port
+			Param7 string // Bytes
+			Param8 string // Pages
+		}
+	}
+}
+
+// generate307 creates a document printed event. A bulk print is a large
+// confidential document, printed off shift two times in three.
+func (g *PrintAuditGenerator) generate307(bulk bool, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	p := randomOccupant(g.rnd, now, true)
+	document := g.RandomChoice(printDocuments[p.user.Department])
+	pageCount := []int{1, 2, 4, 12, 30}[g.weightedIndex([]float64{40, 25, 20, 10, 5})]
+	if bulk {
+		p = randomOccupant(g.rnd, now, g.RandomInt(0, 2) == 0)
+		document = g.RandomChoice(sensitiveDocuments)
+		pageCount = g.RandomInt(150, 900)
+	}
+	document = strings.NewReplacer("{name}", p.user.FullName, "{n}", fmt.Sprint(g.RandomInt(1000, 9999))).Replace(document)
+	site := siteOf(p)
+	floor := []int{1, 2, 3, 4}[g.RandomInt(0, 3)]
+	printer, printerIP := site.printer(floor, g.RandomInt(1, 3))
+	server := site.server("print")
+
+	fields := map[string]interface{}{
+		"Param1": g.RandomInt(1, 999),
+		"Param2": document,
+		"Param3": p.Username,
+		"Param4": `\\` + p.hosts[0].Hostname,
+		"Param5": printer,
+		"Param6": printerIP,
+		"Param7": pageCount * g.RandomInt(20000, 90000),
+		"Param8": pageCount,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := WindowsDocumentPrintedEvent{
+		Xmlns: "http://schemas.microsoft.com/win/2004/08/events/event",
+		System: WindowsEventSystem{
+			Provider: WindowsEventProvider{
+				Name: "Microsoft-Windows-PrintService",
+				Guid: "{747EF6FD-E535-4D16-B510-42C90F6873A1}",
+			},
+			EventID:       307,
+			Version:       0,
+			Level:         4,
+			Task:          26, // Printing a document
+			Opcode:        11,
+			Keywords:      "0x4000000000000840",
+			TimeCreated:   WindowsTimeCreated{SystemTime: now.Format("2006-01-02T15:04:05.000000000Z")},
+			EventRecordID: int64(g.RandomInt(1000, 9999999)),
+			Execution:     WindowsExecution{ProcessID: g.RandomInt(1000, 10000), ThreadID: g.RandomInt(100, 10000)},
+			Channel:       "Microsoft-Windows-PrintService/Operational",
+			Computer:      server + "." + Theme().ADDomain,
+			Security:      WindowsSecurity{UserID: windowsUserSID(p.user)},
+		},
+	}
+	printed := &event.UserData.DocumentPrinted
+	printed.Xmlns = "http://manifests.microsoft.com/win/2005/08/windows/printing/spooler/core/events"
+	printed.Param1 = fmt.Sprintf("%v", fields["Param1"])
+	printed.Param2 = fmt.Sprintf("%v", fields["Param2"])
+	printed.Param3 = fmt.Sprintf("%v", fields["Param3"])
+	printed.Param4 = fmt.Sprintf("%v", fields["Param4"])
+	printed.Param5 = fmt.Sprintf("%v", fields["Param5"])
+	printed.Param6 = fmt.Sprintf("%v", fields["Param6"])
+	printed.Param7 = fmt.Sprintf("%v", fields["Param7"])
+	printed.Param8 = fmt.Sprintf("%v", fields["Param8"])
+
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "print_audit",
+		EventID:    "307",
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:Microsoft-Windows-PrintService/Operational",
+	}, nil
+}
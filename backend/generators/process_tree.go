@@ -0,0 +1,358 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sysmonProgram describes how a Windows program shows up in Sysmon: where
+// it lives, how it is started and what it usually starts in turn
+type sysmonProgram struct {
+	Path        string
+	CommandLine string
+	Description string
+	Children    []string // Programs it commonly starts
+	Network     bool     // Whether it makes connections and DNS queries
+	System      bool     // Runs as SYSTEM rather than the logged on user
+}
+
+// sysmonPrograms maps image names to programs. explorer.exe roots a user's
+// tree and services.exe the system's.
+var sysmonPrograms = map[string]sysmonProgram{
+	"explorer.exe":           {`C:\Windows\explorer.exe`, `C:\Windows\Explorer.EXE`, "Windows Explorer", []string{"chrome.exe", "msedge.exe", "outlook.exe", "winword.exe", "excel.exe", "teams.exe", "notepad.exe", "cmd.exe", "powershell.exe", "onedrive.exe", "rundll32.exe", "mshta.exe"}, false, false},
+	"services.exe":           {`C:\Windows\System32\services.exe`, `C:\Windows\system32\services.exe`, "Services and Controller app", []string{"svchost.exe", "svchost.exe", "svchost.exe", "spoolsv.exe", "msiexec.exe", "msmpeng.exe"}, false, true},
+	"svchost.exe":            {`C:\Windows\System32\svchost.exe`, `C:\Windows\system32\svchost.exe -k netsvcs -p`, "Host Process for Windows Services", []string{"taskhostw.exe", "wmiprvse.exe", "backgroundtaskhost.exe", "wuauclt.exe"}, true, true},
+	"cmd.exe":                {`C:\Windows\System32\cmd.exe`, `"C:\Windows\system32\cmd.exe"`, "Windows Command Processor", []string{"whoami.exe", "ipconfig.exe", "net.exe", "ping.exe", "findstr.exe", "powershell.exe", "conhost.exe", "certutil.exe", "reg.exe", "schtasks.exe", "wmic.exe"}, false, false},
+	"powershell.exe":         {`C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`, `"C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe"`, "Windows PowerShell", []string{"whoami.exe", "net.exe", "ipconfig.exe", "conhost.exe", "cmd.exe", "rundll32.exe", "regsvr32.exe", "bitsadmin.exe", "schtasks.exe"}, true, false},
+	"chrome.exe":             {`C:\Program Files\Google\Chrome\Application\chrome.exe`, `"C:\Program Files\Google\Chrome\Application\chrome.exe"`, "Google Chrome", []string{"chrome.exe"}, true, false},
+	"msedge.exe":             {`C:\Program Files (x86)\Microsoft\Edge\Application\msedge.exe`, `"C:\Program Files (x86)\Microsoft\Edge\Application\msedge.exe"`, "Microsoft Edge", []string{"msedge.exe"}, true, false},
+	"outlook.exe":            {`C:\Program Files\Microsoft Office\root\Office16\OUTLOOK.EXE`, `"C:\Program Files\Microsoft Office\root\Office16\OUTLOOK.EXE"`, "Microsoft Outlook", []string{"winword.exe", "excel.exe"}, true, false},
+	"winword.exe":            {`C:\Program Files\Microsoft Office\root\Office16\WINWORD.EXE`, `"C:\Program Files\Microsoft Office\root\Office16\WINWORD.EXE" /n`, "Microsoft Word", []string{"splwow64.exe"}, true, false},
+	"excel.exe":              {`C:\Program Files\Microsoft Office\root\Office16\EXCEL.EXE`, `"C:\Program Files\Microsoft Office\root\Office16\EXCEL.EXE"`, "Microsoft Excel", []string{"splwow64.exe"}, true, false},
+	"teams.exe":              {`C:\Users\%USER%\AppData\Local\Microsoft\Teams\current\Teams.exe`, `"C:\Users\%USER%\AppData\Local\Microsoft\Teams\current\Teams.exe"`, "Microsoft Teams", []string{"teams.exe"}, true, false},
+	"onedrive.exe":           {`C:\Users\%USER%\AppData\Local\Microsoft\OneDrive\OneDrive.exe`, `"C:\Users\%USER%\AppData\Local\Microsoft\OneDrive\OneDrive.exe" /background`, "Microsoft OneDrive", nil, true, false},
+	"notepad.exe":            {`C:\Windows\System32\notepad.exe`, `"C:\Windows\system32\notepad.exe"`, "Notepad", nil, false, false},
+	"whoami.exe":             {`C:\Windows\System32\whoami.exe`, `whoami /all`, "whoami - displays logged on user information", nil, false, false},
+	"ipconfig.exe":           {`C:\Windows\System32\ipconfig.exe`, `ipconfig /all`, "IP Configuration Utility", nil, false, false},
+	"net.exe":                {`C:\Windows\System32\net.exe`, `net use`, "Net Command", nil, true, false},
+	"ping.exe":               {`C:\Windows\System32\PING.EXE`, `ping -n 4 8.8.8.8`, "TCP/IP Ping Command", nil, true, false},
+	"findstr.exe":            {`C:\Windows\System32\findstr.exe`, `findstr /i password`, "Find String (QGREP) Utility", nil, false, false},
+	"conhost.exe":            {`C:\Windows\System32\conhost.exe`, `\??\C:\Windows\system32\conhost.exe 0xffffffff -ForceV1`, "Console Window Host", nil, false, false},
+	"splwow64.exe":           {`C:\Windows\splwow64.exe`, `C:\Windows\splwow64.exe 8192`, "Print driver host for applications", nil, false, false},
+	"spoolsv.exe":            {`C:\Windows\System32\spoolsv.exe`, `C:\Windows\System32\spoolsv.exe`, "Spooler SubSystem App", nil, true, true},
+	"msiexec.exe":            {`C:\Windows\System32\msiexec.exe`, `C:\Windows\system32\msiexec.exe /V`, "Windows installer", nil, true, true},
+	"msmpeng.exe":            {`C:\ProgramData\Microsoft\Windows Defender\Platform\4.18.24030.9-0\MsMpEng.exe`, `"C:\ProgramData\Microsoft\Windows Defender\Platform\4.18.24030.9-0\MsMpEng.exe"`, "Antimalware Service Executable", nil, true, true},
+	"taskhostw.exe":          {`C:\Windows\System32\taskhostw.exe`, `taskhostw.exe`, "Host Process for Windows Tasks", nil, false, true},
+	"wmiprvse.exe":           {`C:\Windows\System32\wbem\WmiPrvSE.exe`, `C:\Windows\system32\wbem\wmiprvse.exe -secured -Embedding`, "WMI Provider Host", nil, false, true},
+	"backgroundtaskhost.exe": {`C:\Windows\System32\backgroundTaskHost.exe`, `"C:\Windows\system32\backgroundTaskHost.exe" -ServerName:App.AppXmtcan0h2tfbfy7k9kn8hbxb6dmzz1zh0.mca`, "Background Task Host", nil, true, true},
+	"wuauclt.exe":            {`C:\Windows\System32\wuauclt.exe`, `"C:\Windows\system32\wuauclt.exe" /RunHandlerComServer`, "Windows Update", nil, true, true},
+	"rundll32.exe":           {`C:\Windows\System32\rundll32.exe`, `C:\Windows\system32\rundll32.exe shell32.dll,Control_RunDLL`, "Windows host process (Rundll32)", nil, true, false},
+	"regsvr32.exe":           {`C:\Windows\System32\regsvr32.exe`, `regsvr32.exe /s`, "Microsoft(C) Register Server", nil, true, false},
+	"mshta.exe":              {`C:\Windows\System32\mshta.exe`, `mshta.exe`, "Microsoft (R) HTML Application host", nil, true, false},
+	"certutil.exe":           {`C:\Windows\System32\certutil.exe`, `certutil.exe -store My`, "CertUtil.exe", nil, true, false},
+	"bitsadmin.exe":          {`C:\Windows\System32\bitsadmin.exe`, `bitsadmin /list`, "BITS administration utility", nil, true, false},
+	"wmic.exe":               {`C:\Windows\System32\wbem\WMIC.exe`, `wmic.exe os get Caption`, "WMI Commandline Utility", nil, true, false},
+	"schtasks.exe":           {`C:\Windows\System32\schtasks.exe`, `schtasks.exe /query`, "Task Scheduler Configuration Tool", nil, false, false},
+	"reg.exe":                {`C:\Windows\System32\reg.exe`, `reg query HKLM\SOFTWARE`, "Registry Console Tool", nil, false, false},
+	"lsass.exe":              {`C:\Windows\System32\lsass.exe`, `C:\Windows\system32\lsass.exe`, "Local Security Authority Process", nil, false, true},
+}
+
+// sysmonProcess is a process the tree has reported as created
+type sysmonProcess struct {
+	Name             string // Key into sysmonPrograms
+	Computer         string
+	Guid             string
+	PID              int
+	Image            string
+	CommandLine      string
+	CurrentDirectory string
+	User             string
+	LogonGuid        string
+	LogonID          string
+	IntegrityLevel   string
+	Hashes           string
+	Technique        string         // Sysmon rule name of an attack command line, e.g. technique_id=T1218.011,...
+	Parent           *sysmonProcess // nil for a process started before the stream
+	Started          time.Time
+}
+
+// processHost holds the processes running on one host. services.exe and
+// lsass.exe start at boot and are never forgotten.
+type processHost struct {
+	host     *EntityHost
+	services *sysmonProcess
+	lsass    *sysmonProcess
+	procs    []*sysmonProcess // Oldest first
+}
+
+const (
+	// maxProcessesPerHost bounds the processes remembered per host; the
+	// oldest are forgotten first
+	maxProcessesPerHost = 256
+	// newTreeChance is how often a process creation starts a new user
+	// session tree instead of extending one
+	newTreeChance = 0.1
+)
+
+// ProcessTree keeps the processes Sysmon has reported per host, so process
+// creations extend existing parent chains and network, DNS and file events
+// name processes created earlier in the stream
+type ProcessTree struct {
+	mu    sync.Mutex
+	hosts map[string]*processHost
+	order []*processHost // Hosts in the order they booted
+}
+
+// SysmonProcesses is the process tree shared by the Sysmon generator
+var SysmonProcesses = &ProcessTree{hosts: make(map[string]*processHost)}
+
+// spawn creates a process: usually a child of a running process that
+// starts programs, otherwise a new explorer.exe for a logged on user
+func (t *ProcessTree) spawn(rnd *RandomStream, now time.Time) *sysmonProcess {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.spawnLocked(rnd, now)
+}
+
+// spawnLocked is spawn for callers holding t.mu
+func (t *ProcessTree) spawnLocked(rnd *RandomStream, now time.Time) *sysmonProcess {
+	h := t.randomHost(rnd)
+	if h == nil || rnd.float64() < newTreeChance {
+		return t.startUserTree(rnd, now)
+	}
+	parents := []*sysmonProcess{h.services}
+	for _, p := range h.procs {
+		if len(sysmonPrograms[p.Name].Children) > 0 {
+			parents = append(parents, p)
+		}
+	}
+
+	return t.startChild(rnd, h, parents[rnd.intn(len(parents))], now)
+}
+
+// startChild starts one of the programs parent commonly starts. Callers
+// hold t.mu.
+func (t *ProcessTree) startChild(rnd *RandomStream, h *processHost, parent *sysmonProcess, now time.Time) *sysmonProcess {
+	b := BaseGenerator{rnd: rnd}
+	name := b.RandomChoice(sysmonPrograms[parent.Name].Children)
+	child := t.newProcess(rnd, h, name, parent, now)
+	if !sysmonPrograms[name].System {
+		child.User, child.LogonGuid, child.LogonID = parent.User, parent.LogonGuid, parent.LogonID
+		child.IntegrityLevel = parent.IntegrityLevel
+	}
+	t.add(h, child)
+	return child
+}
+
+// startUserTree starts explorer.exe for a user, on the host of an open
+// Windows logon session when there is one, so Sysmon shares its LogonId.
+// A session that already has explorer.exe gets a program started from it.
+// Callers hold t.mu.
+func (t *ProcessTree) startUserTree(rnd *RandomStream, now time.Time) *sysmonProcess {
+	b := BaseGenerator{rnd: rnd}
+	var host *EntityHost
+	var user, logonID string
+	if s := LogonSessions.pick(rnd, now, true); s != nil {
+		if h, ok := windowsHostByName(strings.SplitN(s.Computer, ".", 2)[0]); ok {
+			host = h
+			user, logonID = s.Domain+`\`+s.UserName, s.LogonID
+		}
+	}
+	if host == nil {
+		host = Entities.RandomHost(rnd, "windows")
+		u := Entities.RandomUser(rnd)
+		if owner, ok := Entities.UserByName(host.Owner); ok {
+			u = owner
+		}
+		user, logonID = windowsNetBIOSDomain()+`\`+u.Username, fmt.Sprintf("0x%x", b.RandomInt(100000, 9999999))
+	}
+
+	h := t.host(rnd, host, now)
+	for _, p := range h.procs {
+		if p.Name == "explorer.exe" && p.LogonID == logonID {
+			return t.startChild(rnd, h, p, now)
+		}
+	}
+	// userinit.exe starts explorer.exe and exits, so the parent is not
+	// in the tree
+	userinit := &sysmonProcess{
+		Name:        "userinit.exe",
+		Computer:    host.FQDN,
+		Guid:        processGuid(rnd, host),
+		PID:         b.RandomInt(1000, 65535),
+		Image:       `C:\Windows\System32\userinit.exe`,
+		CommandLine: `C:\Windows\system32\userinit.exe`,
+		User:        user,
+	}
+	explorer := t.newProcess(rnd, h, "explorer.exe", userinit, now)
+	explorer.User, explorer.LogonID = user, logonID
+	explorer.IntegrityLevel = "Medium"
+	t.add(h, explorer)
+	return explorer
+}
+
+// pick returns a running process on a random host for an event that names
+// one, a process that uses the network when network is set. With none
+// running there it creates one without reporting it, as if started before
+// the stream.
+func (t *ProcessTree) pick(rnd *RandomStream, now time.Time, network bool) *sysmonProcess {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if h := t.randomHost(rnd); h != nil {
+		var candidates []*sysmonProcess
+		for _, p := range h.procs {
+			if !network || sysmonPrograms[p.Name].Network {
+				candidates = append(candidates, p)
+			}
+		}
+		if len(candidates) > 0 {
+			return candidates[rnd.intn(len(candidates))]
+		}
+	}
+
+	p := t.spawnLocked(rnd, now)
+	for network && !sysmonPrograms[p.Name].Network {
+		p = t.spawnLocked(rnd, now)
+	}
+	return p
+}
+
+// pickOf returns a running process of one of the named programs, or a
+// process that uses the network when none is running
+func (t *ProcessTree) pickOf(rnd *RandomStream, now time.Time, names ...string) *sysmonProcess {
+	t.mu.Lock()
+	var candidates []*sysmonProcess
+	for _, h := range t.order {
+		for _, p := range h.procs {
+			for _, name := range names {
+				if p.Name == name {
+					candidates = append(candidates, p)
+					break
+				}
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return t.pick(rnd, now, true)
+	}
+	return candidates[rnd.intn(len(candidates))]
+}
+
+// sibling returns another running process on p's host, or lsass.exe
+func (t *ProcessTree) sibling(rnd *RandomStream, p *sysmonProcess) *sysmonProcess {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h := t.hosts[p.Computer]
+	var others []*sysmonProcess
+	for _, o := range h.procs {
+		if o != p {
+			others = append(others, o)
+		}
+	}
+	if len(others) == 0 {
+		return h.lsass
+	}
+	return others[rnd.intn(len(others))]
+}
+
+// hostOf returns the host p runs on
+func (t *ProcessTree) hostOf(p *sysmonProcess) *EntityHost {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hosts[p.Computer].host
+}
+
+// lsass returns lsass.exe on p's host
+func (t *ProcessTree) lsass(p *sysmonProcess) *sysmonProcess {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hosts[p.Computer].lsass
+}
+
+// host returns the processes on host, booting it on first use. Callers
+// hold t.mu.
+func (t *ProcessTree) host(rnd *RandomStream, host *EntityHost, now time.Time) *processHost {
+	if h, ok := t.hosts[host.FQDN]; ok {
+		return h
+	}
+	h := &processHost{host: host}
+	boot := now.Add(-time.Duration(1+rnd.float64()*72) * time.Hour)
+	wininit := &sysmonProcess{Name: "wininit.exe", Computer: host.FQDN, Guid: processGuid(rnd, host), PID: 600, Image: `C:\Windows\System32\wininit.exe`, User: `NT AUTHORITY\SYSTEM`}
+	h.services = t.newProcess(rnd, h, "services.exe", wininit, boot)
+	h.lsass = t.newProcess(rnd, h, "lsass.exe", wininit, boot)
+	t.hosts[host.FQDN] = h
+	t.order = append(t.order, h)
+	return h
+}
+
+// randomHost returns a host with running processes, or nil before the
+// first one boots. Callers hold t.mu.
+func (t *ProcessTree) randomHost(rnd *RandomStream) *processHost {
+	if len(t.order) == 0 {
+		return nil
+	}
+	return t.order[rnd.intn(len(t.order))]
+}
+
+// newProcess makes a process of the named program on h. It runs as SYSTEM
+// until the caller gives it a user. Callers hold t.mu.
+func (t *ProcessTree) newProcess(rnd *RandomStream, h *processHost, name string, parent *sysmonProcess, now time.Time) *sysmonProcess {
+	b := BaseGenerator{rnd: rnd}
+	prog := sysmonPrograms[name]
+	commandLine, technique := b.processCommandLine(name, prog.CommandLine)
+	p := &sysmonProcess{
+		Name:             name,
+		Computer:         h.host.FQDN,
+		Guid:             processGuid(rnd, h.host),
+		PID:              b.RandomInt(1000, 65535),
+		Image:            prog.Path,
+		CommandLine:      commandLine,
+		CurrentDirectory: `C:\Windows\system32\`,
+		User:             `NT AUTHORITY\SYSTEM`,
+		LogonGuid:        "{" + b.RandomGUID() + "}",
+		LogonID:          "0x3e7",
+		IntegrityLevel:   "System",
+		Hashes:           b.sysmonHashes(Files.ForName(prog.Path)),
+		Technique:        technique,
+		Parent:           parent,
+		Started:          now,
+	}
+	if !prog.System && parent != nil && parent.User != `NT AUTHORITY\SYSTEM` {
+		user := parent.User[strings.LastIndex(parent.User, `\`)+1:]
+		p.Image = strings.ReplaceAll(p.Image, "%USER%", user)
+		p.CommandLine = strings.ReplaceAll(p.CommandLine, "%USER%", user)
+		p.CurrentDirectory = `C:\Users\` + user + `\`
+	}
+	p.CommandLine = strings.ReplaceAll(p.CommandLine, "%USER%", "Public")
+	return p
+}
+
+// add records a running process, forgetting the oldest beyond
+// maxProcessesPerHost. Callers hold t.mu.
+func (t *ProcessTree) add(h *processHost, p *sysmonProcess) {
+	if len(h.procs) >= maxProcessesPerHost {
+		h.procs = h.procs[1:]
+	}
+	h.procs = append(h.procs, p)
+}
+
+// processUserSID returns the SID of the user p runs as
+func processUserSID(p *sysmonProcess) string {
+	if u, ok := Entities.UserByName(p.User[strings.LastIndex(p.User, `\`)+1:]); ok {
+		return windowsUserSID(u)
+	}
+	return "S-1-5-18"
+}
+
+// processGuid returns a Sysmon process GUID. Like Sysmon's, it starts with
+// part of the machine GUID, so all GUIDs from one host share a prefix.
+func processGuid(rnd *RandomStream, host *EntityHost) string {
+	b := BaseGenerator{rnd: rnd}
+	return fmt.Sprintf("{%s-%s}", strings.ToLower(host.UUID[:8]), b.RandomGUID()[9:])
+}
@@ -0,0 +1,207 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"siem-event-generator/models"
+)
+
+// renderTimeField is the pseudo-field holding the event time
+const renderTimeField = "_time"
+
+// Renderer rewrites raw events from their fields as CSV, key=value pairs
+// or LTSV. CSV keeps one column list per template, so every row of a
+// template lines up under the same header.
+type Renderer struct {
+	cfg models.RenderConfig
+
+	mu      sync.Mutex
+	columns map[string][]string // CSV columns per template
+}
+
+// NewRenderer checks cfg and returns a Renderer for it
+func NewRenderer(cfg models.RenderConfig) (*Renderer, error) {
+	switch cfg.Format {
+	case models.RenderCSV:
+		if utf8.RuneCountInString(cfg.Delimiter) > 1 {
+			return nil, fmt.Errorf("csv delimiter must be a single character")
+		}
+		if cfg.Delimiter == "\"" || cfg.Delimiter == "\n" || cfg.Delimiter == "\r" {
+			return nil, fmt.Errorf("csv delimiter %q is not allowed", cfg.Delimiter)
+		}
+	case models.RenderKV:
+	case models.RenderLTSV:
+		if cfg.Delimiter != "" {
+			return nil, fmt.Errorf("ltsv has no delimiter setting")
+		}
+	default:
+		return nil, fmt.Errorf("unknown render format %q: use csv, kv or ltsv", cfg.Format)
+	}
+	if cfg.Header && cfg.Format != models.RenderCSV {
+		return nil, fmt.Errorf("header only applies to csv")
+	}
+	for _, col := range cfg.Columns {
+		if col == "" {
+			return nil, fmt.Errorf("render columns cannot be empty")
+		}
+	}
+	return &Renderer{cfg: cfg, columns: make(map[string][]string)}, nil
+}
+
+// Config returns the config the renderer was built from
+func (r *Renderer) Config() models.RenderConfig {
+	return r.cfg
+}
+
+// Apply returns a copy of event with its raw event rendered from its
+// fields. A nil Renderer returns the event unchanged.
+func (r *Renderer) Apply(event *models.GeneratedEvent) *models.GeneratedEvent {
+	if r == nil {
+		return event
+	}
+
+	flat := map[string]string{renderTimeField: event.Timestamp.UTC().Format(time.RFC3339)}
+	flattenFields("", event.Fields, flat)
+
+	var raw string
+	switch r.cfg.Format {
+	case models.RenderCSV:
+		raw = r.renderCSV(event, flat)
+	case models.RenderKV:
+		raw = r.renderKV(flat)
+	case models.RenderLTSV:
+		raw = r.renderLTSV(flat)
+	}
+
+	rendered := *event
+	rendered.RawEvent = raw
+	return &rendered
+}
+
+// eventColumns returns the configured columns, or _time and every field of
+// the event by name
+func (r *Renderer) eventColumns(flat map[string]string) []string {
+	if len(r.cfg.Columns) > 0 {
+		return r.cfg.Columns
+	}
+	columns := make([]string, 0, len(flat))
+	for name := range flat {
+		if name != renderTimeField {
+			columns = append(columns, name)
+		}
+	}
+	sort.Strings(columns)
+	return append([]string{renderTimeField}, columns...)
+}
+
+// renderCSV renders one row in the template's columns, which are fixed by
+// its first event, after the header row if this is the first event
+func (r *Renderer) renderCSV(event *models.GeneratedEvent, flat map[string]string) string {
+	key := event.Type + "/" + event.EventID
+	r.mu.Lock()
+	columns, seen := r.columns[key]
+	if !seen {
+		columns = r.eventColumns(flat)
+		r.columns[key] = columns
+	}
+	r.mu.Unlock()
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if r.cfg.Delimiter != "" {
+		w.Comma, _ = utf8.DecodeRuneInString(r.cfg.Delimiter)
+	}
+	if r.cfg.Header && !seen {
+		w.Write(columns)
+	}
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = flat[col]
+	}
+	w.Write(row)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// renderKV renders key=value pairs, quoting values with spaces, quotes,
+// equals signs or the separator in them
+func (r *Renderer) renderKV(flat map[string]string) string {
+	sep := r.cfg.Delimiter
+	if sep == "" {
+		sep = " "
+	}
+	columns := r.eventColumns(flat)
+	pairs := make([]string, 0, len(columns))
+	for _, col := range columns {
+		value, ok := flat[col]
+		if !ok {
+			continue
+		}
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") || strings.Contains(value, sep) {
+			value = strconv.Quote(value)
+		}
+		pairs = append(pairs, col+"="+value)
+	}
+	return strings.Join(pairs, sep)
+}
+
+// ltsvEscaper keeps values on one line and out of the field separators
+var ltsvEscaper = strings.NewReplacer("\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// renderLTSV renders label:value fields separated by tabs
+func (r *Renderer) renderLTSV(flat map[string]string) string {
+	columns := r.eventColumns(flat)
+	fields := make([]string, 0, len(columns))
+	for _, col := range columns {
+		value, ok := flat[col]
+		if !ok {
+			continue
+		}
+		fields = append(fields, col+":"+ltsvEscaper.Replace(value))
+	}
+	return strings.Join(fields, "\t")
+}
+
+// flattenFields adds v to flat under dotted names. Lists are kept whole as
+// compact JSON, since their length varies from event to event.
+func flattenFields(prefix string, v interface{}, flat map[string]string) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			flattenFields(joinFieldName(prefix, k), child, flat)
+		}
+	case map[string]string:
+		for k, child := range t {
+			flat[joinFieldName(prefix, k)] = child
+		}
+	case string:
+		flat[prefix] = t
+	case nil:
+		flat[prefix] = ""
+	case time.Time:
+		flat[prefix] = t.Format(time.RFC3339Nano)
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			b = []byte(fmt.Sprint(t))
+		}
+		flat[prefix] = string(b)
+	}
+}
+
+// joinFieldName returns the dotted name of a nested field
+func joinFieldName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,221 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// SalesforceGenerator generates Salesforce Real-Time Event Monitoring events
+type SalesforceGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&SalesforceGenerator{})
+}
+
+// GetEventType returns the event type for Salesforce Event Monitoring
+func (g *SalesforceGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "salesforce",
+		Name:        "Salesforce Event Monitoring",
+		Category:    "cloud",
+		Description: "Salesforce Real-Time Event Monitoring: logins, report exports and API queries",
+		EventIDs:    []string{"LoginEvent", "ReportExport", "ApiEvent"},
+	}
+}
+
+// GetTemplates returns available templates for Salesforce events
+func (g *SalesforceGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "LoginEvent",
+			Name:        "Login Event",
+			Category:    "salesforce",
+			EventID:     "LoginEvent",
+			Format:      "json",
+			Description: "UI, SSO or API login, including failures and logins from unusual countries",
+			Sourcetype:  "sfdc:loginevent",
+		},
+		{
+			ID:          "ReportExport",
+			Name:        "Report Export",
+			Category:    "salesforce",
+			EventID:     "ReportExport",
+			Format:      "json",
+			Description: "Report exported from the UI, occasionally a bulk export of contacts or opportunities",
+			Sourcetype:  "sfdc:reportevent",
+		},
+		{
+			ID:          "ApiEvent",
+			Name:        "API Event",
+			Category:    "salesforce",
+			EventID:     "ApiEvent",
+			Format:      "json",
+			Description: "SOQL query through the REST, SOAP or Bulk API",
+			Sourcetype:  "sfdc:apievent",
+		},
+	}
+}
+
+// salesforceID returns an 18-character record ID with the given key prefix
+func (g *SalesforceGenerator) salesforceID(prefix string) string {
+	const chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	var b strings.Builder
+	b.WriteString(prefix)
+	b.WriteString("5g00000")
+	for b.Len() < 18 {
+		b.WriteByte(chars[g.RandomInt(0, len(chars)-1)])
+	}
+	return b.String()
+}
+
+// salesforceUser returns a pool user with their Salesforce username and user ID
+func (g *SalesforceGenerator) salesforceUser() (*EntityUser, string, string) {
+	user := Entities.RandomUser(g.rnd)
+	return user, user.Email, fmt.Sprintf("0055g%010dAAK", user.UID)
+}
+
+// Generate creates a Salesforce event
+func (g *SalesforceGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	var fields map[string]interface{}
+	var sourcetype string
+
+	switch templateID {
+	case "LoginEvent":
+		fields, sourcetype = g.loginEvent(now), "sfdc:loginevent"
+	case "ReportExport":
+		fields, sourcetype = g.reportEvent(now), "sfdc:reportevent"
+	case "ApiEvent":
+		fields, sourcetype = g.apiEvent(now), "sfdc:apievent"
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, _ := json.Marshal(fields)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "salesforce",
+		EventID:    templateID,
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: sourcetype,
+	}, nil
+}
+
+// baseFields returns the fields every real-time event object carries
+func (g *SalesforceGenerator) baseFields(now time.Time, object, username, userID, sourceIP string) map[string]interface{} {
+	return map[string]interface{}{
+		"attributes":      map[string]string{"type": object},
+		"EventDate":       now.Format("2006-01-02T15:04:05.000+0000"),
+		"EventIdentifier": uuid.New().String(),
+		"Username":        username,
+		"UserId":          userID,
+		"SourceIp":        sourceIP,
+		"SessionKey":      g.RandomString(16),
+		"LoginKey":        g.RandomString(16),
+		"SessionLevel":    "STANDARD",
+	}
+}
+
+func (g *SalesforceGenerator) loginEvent(now time.Time) map[string]interface{} {
+	_, username, userID := g.salesforceUser()
+	sourceIP, country, city := g.RandomIPv4External(), "US", g.RandomChoice([]string{"New York", "Austin", "San Francisco", "Chicago"})
+	if g.RandomInt(1, 100) <= 5 {
+		country, city = g.RandomChoice([]string{"RU", "NG", "VN", "RO", "BR"}), ""
+	}
+
+	loginType := g.WeightedChoice([]string{"SAML Sfdc Initiated SSO", "Application", "Remote Access 2.0", "Other Apex API"}, []float64{60, 20, 15, 5})
+	status := g.WeightedChoice([]string{"Success", "Invalid Password", "Failed: Computer activation required", "Restricted IP"}, []float64{88, 7, 3, 2})
+	if loginType == "SAML Sfdc Initiated SSO" && status == "Invalid Password" {
+		status = "Failed: SAML Assertion Invalid"
+	}
+
+	fields := g.baseFields(now, "LoginEvent", username, userID, sourceIP)
+	fields["LoginType"] = loginType
+	fields["Status"] = status
+	fields["LoginUrl"] = "example.my.salesforce.com"
+	fields["Application"] = map[string]string{"SAML Sfdc Initiated SSO": "Browser", "Application": "Browser", "Remote Access 2.0": "Salesforce for iOS", "Other Apex API": "Data Loader"}[loginType]
+	fields["Browser"] = g.RandomChoice([]string{"Chrome 124", "Edge 124", "Safari 17", "Firefox 125"})
+	fields["Platform"] = g.RandomChoice([]string{"Windows 10", "Mac OSX", "iOS/iPhone"})
+	fields["CountryIso"] = country
+	fields["City"] = city
+	fields["TlsProtocol"] = "TLS 1.3"
+	fields["CipherSuite"] = "TLS_AES_256_GCM_SHA384"
+	fields["AuthMethodReference"] = ""
+	fields["LoginHistoryId"] = g.salesforceID("0Ya")
+	fields["LoginGeoId"] = g.salesforceID("04F")
+	if loginType == "SAML Sfdc Initiated SSO" {
+		fields["AuthServiceId"] = g.salesforceID("0LE")
+	}
+	return fields
+}
+
+func (g *SalesforceGenerator) reportEvent(now time.Time) map[string]interface{} {
+	user, username, userID := g.salesforceUser()
+	reports := []struct {
+		name, entities string
+		rows           int
+	}{
+		{"Pipeline by Stage", "Opportunity", g.RandomInt(50, 2000)},
+		{"My Open Cases", "Case", g.RandomInt(5, 300)},
+		{"Q3 Closed Won", "Opportunity, Account", g.RandomInt(100, 3000)},
+		{"All Contacts with Email", "Contact, Account", g.RandomInt(20000, 400000)},
+		{"All Accounts by Revenue", "Account", g.RandomInt(10000, 150000)},
+	}
+	r := reports[g.weightedIndex([]float64{30, 30, 25, 8, 7})]
+
+	fields := g.baseFields(now, "ReportEvent", username, userID, Entities.HostForUser(g.rnd, user.Username).IP)
+	fields["Operation"] = "ReportExported"
+	fields["ReportId"] = g.salesforceID("00O")
+	fields["Name"] = r.name
+	fields["QueriedEntities"] = r.entities
+	fields["RowsProcessed"] = r.rows
+	fields["Format"] = g.WeightedChoice([]string{"csv", "xls", "printable"}, []float64{55, 35, 10})
+	fields["ExportFileFormat"] = fields["Format"]
+	fields["ExecutionIdentifier"] = uuid.New().String()
+	fields["DashboardId"] = ""
+	return fields
+}
+
+func (g *SalesforceGenerator) apiEvent(now time.Time) map[string]interface{} {
+	_, username, userID := g.salesforceUser()
+	if g.RandomInt(1, 100) <= 60 {
+		username = g.RandomChoice([]string{"integration", "marketo.sync", "dataloader"}) + "@" + Theme().Domain
+	}
+
+	queries := []struct {
+		query, entities string
+		rows            int
+	}{
+		{"SELECT Id, Name, StageName FROM Opportunity WHERE LastModifiedDate = TODAY", "Opportunity", g.RandomInt(0, 500)},
+		{"SELECT Id, Email FROM Lead WHERE CreatedDate = LAST_N_DAYS:1", "Lead", g.RandomInt(0, 2000)},
+		{"SELECT Id, Status FROM Case WHERE IsClosed = false", "Case", g.RandomInt(0, 800)},
+		{"SELECT Id, Name, Email, Phone, MailingAddress FROM Contact", "Contact", g.RandomInt(50000, 500000)},
+	}
+	q := queries[g.weightedIndex([]float64{35, 30, 30, 5})]
+
+	fields := g.baseFields(now, "ApiEvent", username, userID, g.RandomIPv4External())
+	fields["ApiType"] = g.WeightedChoice([]string{"REST API", "SOAP Partner", "Bulk API 2.0"}, []float64{60, 25, 15})
+	fields["ApiVersion"] = 60.0
+	fields["Operation"] = "Query"
+	fields["Query"] = q.query
+	fields["QueriedEntities"] = q.entities
+	fields["RowsProcessed"] = q.rows
+	fields["RowsReturned"] = min(q.rows, 2000)
+	fields["ElapsedTime"] = int(g.RandomLogNormal(120, 0.9)) + q.rows/500
+	fields["Client"] = g.RandomChoice([]string{"Workbench/", "DataLoaderBulkUI/60.0", "simple-salesforce/1.12", "MarketoSync"})
+	fields["ConnectedAppId"] = g.salesforceID("0H4")
+	fields["AdditionalInfo"] = "{}"
+	return fields
+}
@@ -0,0 +1,70 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+
+	"siem-event-generator/models"
+)
+
+// Sample limits
+const (
+	defaultSampleCount = 3
+	maxSampleCount     = 20
+)
+
+// Samples generates Count example events from every template of every
+// registered generator, or of those in Category, in event type order. A
+// template that fails to generate keeps its error and the events made
+// before it, so one broken template does not lose the rest of the corpus.
+func Samples(ctx context.Context, req models.SampleRequest) (*models.SampleSet, error) {
+	count := req.Count
+	if count == 0 {
+		count = defaultSampleCount
+	}
+	if count < 1 || count > maxSampleCount {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxSampleCount)
+	}
+	if err := ValidateOutput(req.Output); err != nil {
+		return nil, err
+	}
+
+	set := &models.SampleSet{Count: count, Samples: []models.TemplateSamples{}}
+	for _, g := range Generators() {
+		eventType := g.GetEventType()
+		if req.Category != "" && eventType.Category != req.Category {
+			continue
+		}
+		for _, t := range g.GetTemplates() {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			s := models.TemplateSamples{
+				EventType:     eventType.ID,
+				Category:      eventType.Category,
+				TemplateID:    t.ID,
+				Name:          t.Name,
+				Format:        t.Format,
+				Sourcetype:    t.Sourcetype,
+				SchemaVersion: t.SchemaVersion,
+				Deprecated:    t.Deprecated,
+				Events:        make([]models.GeneratedEvent, 0, count),
+			}
+			for i := 0; i < count; i++ {
+				event, err := Unseeded.GenerateOutput(g, t.ID, nil, req.Output)
+				if err != nil {
+					s.Error = err.Error()
+					break
+				}
+				s.Events = append(s.Events, *event)
+			}
+			set.Templates++
+			set.Events += len(s.Events)
+			set.Samples = append(set.Samples, s)
+		}
+	}
+	if req.Category != "" && set.Templates == 0 {
+		return nil, fmt.Errorf("no event types in category %q", req.Category)
+	}
+	return set, nil
+}
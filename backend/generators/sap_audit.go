@@ -0,0 +1,229 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// SAPAuditGenerator generates SAP NetWeaver ABAP Security Audit Log (SAL) events
+type SAPAuditGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&SAPAuditGenerator{})
+}
+
+// sapMessage describes a Security Audit Log message ID
+type sapMessage struct {
+	name, class, severity, text string
+}
+
+// sapMessages maps SAL message IDs to their audit class, criticality and
+// message text; &A/&B/&C are the record's variable parts
+var sapMessages = map[string]sapMessage{
+	"AU1": {"Logon Successful", "Dialog logon", "Low", "Logon successful (type=&A, method=&C)"},
+	"AU2": {"Logon Failed", "Dialog logon", "Medium", "Logon failed (reason=&B, type=&A, method=&C)"},
+	"AU3": {"Transaction Started", "Transaction start", "Low", "Transaction &A started"},
+	"AU4": {"Transaction Start Failed", "Transaction start", "Medium", "Start of transaction &A failed (reason=&B)"},
+	"AU5": {"RFC Logon Successful", "RFC/CPIC logon", "Low", "RFC/CPIC logon successful (type=&A, method=&C)"},
+	"AU6": {"RFC Logon Failed", "RFC/CPIC logon", "Medium", "RFC/CPIC logon failed, reason=&B, type=&A, method=&C"},
+	"AUK": {"RFC Call Successful", "RFC function call", "Low", "Successful RFC call &C (function group = &A)"},
+	"AUL": {"RFC Call Failed", "RFC function call", "Medium", "Failed RFC call &C (function group = &A)"},
+	"AU7": {"User Created", "User master record change", "High", "User &A created"},
+	"AUB": {"Authorizations Changed", "User master record change", "High", "Authorizations for user &A changed"},
+	"AUM": {"User Locked", "User master record change", "High", "User &B locked in client &A after errors in password checks"},
+}
+
+// sapMessageOrder is the template order shown in the UI
+var sapMessageOrder = []string{"AU1", "AU2", "AU3", "AU4", "AU5", "AU6", "AUK", "AUL", "AU7", "AUB", "AUM"}
+
+// GetEventType returns the event type for the SAP Security Audit Log
+func (g *SAPAuditGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "sap_audit",
+		Name:        "SAP Security Audit Log",
+		Category:    "infrastructure",
+		Description: "SAP ABAP Security Audit Log (SM20) records: logons, transaction starts, RFC calls and user/role changes",
+		EventIDs:    sapMessageOrder,
+	}
+}
+
+// GetTemplates returns available templates for SAP Security Audit Log events
+func (g *SAPAuditGenerator) GetTemplates() []models.EventTemplate {
+	templates := make([]models.EventTemplate, 0, len(sapMessageOrder))
+	for _, id := range sapMessageOrder {
+		msg := sapMessages[id]
+		templates = append(templates, models.EventTemplate{
+			ID:          id,
+			Name:        msg.name,
+			Category:    "sap_audit",
+			EventID:     id,
+			Format:      "text",
+			Description: msg.class + ": " + msg.text,
+		})
+	}
+	return templates
+}
+
+// sapTransaction is a transaction code and the program behind it
+type sapTransaction struct {
+	tcode, program string
+	critical       bool
+}
+
+var sapTransactions = []sapTransaction{
+	{"VA01", "SAPMV45A", false},
+	{"ME21N", "SAPLMEGUI", false},
+	{"FB60", "SAPMF05A", false},
+	{"MM03", "SAPLMGMM", false},
+	{"VL02N", "SAPMV50A", false},
+	{"F110", "SAPF110V", false},
+	{"SE16", "SAPLSETB", true},
+	{"SU01", "SAPMSUU0", true},
+	{"PFCG", "SAPLPRGN_TREE", true},
+	{"SE38", "SAPMS38M", true},
+	{"SM59", "RSRFCDES", true},
+	{"SM49", "SAPMSXPG", true},
+	{"SCC4", "SAPLSTRD", true},
+}
+
+// sapRFCFunctions are remote-enabled function modules and their function groups
+var sapRFCFunctions = [][2]string{
+	{"RFC1", "RFC_PING"},
+	{"SYST", "RFC_SYSTEM_INFO"},
+	{"BUBA_3", "BAPI_BUPA_CENTRAL_GETDETAIL"},
+	{"V45A", "BAPI_SALESORDER_CREATEFROMDAT2"},
+	{"SDTX", "RFC_READ_TABLE"},
+	{"SU_USER", "BAPI_USER_CHANGE"},
+	{"SXPG", "SXPG_COMMAND_EXECUTE"},
+}
+
+// The audited SAP system, application server instance and client
+const (
+	sapSID      = "PRD"
+	sapInstance = "sapprd01_PRD_00"
+	sapClient   = "100"
+)
+
+// sapUser converts a pool user to an SAP user ID such as JSMITH
+func sapUser(u *EntityUser) string {
+	parts := strings.SplitN(u.Username, ".", 2)
+	id := strings.ToUpper(parts[0][:1] + parts[len(parts)-1])
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
+
+// Generate creates an SAP Security Audit Log event
+func (g *SAPAuditGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	msg, ok := sapMessages[templateID]
+	if !ok {
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+
+	now := time.Now().UTC()
+	user := Entities.RandomUser(g.rnd)
+	userID := sapUser(user)
+	terminal := Entities.HostForUser(g.rnd, user.Username).Hostname
+	tx := sapTransactions[g.RandomZipf(6, 1.0)]
+	logonType := "A" // dialog
+	method := "A"    // password
+	var varA, varB, varC string
+
+	switch templateID {
+	case "AU1", "AU2":
+		varA, varC = logonType, method
+		tx = sapTransaction{"", "SAPMSYST", false}
+		if templateID == "AU2" {
+			// 1 wrong password, 2 user locked, 4 user expired, 53 password expired
+			varB = g.WeightedChoice([]string{"1", "2", "4", "53"}, []float64{75, 15, 5, 5})
+			if g.RandomInt(1, 100) <= 20 {
+				userID = g.RandomChoice([]string{"SAP*", "DDIC", "EARLYWATCH", "TMSADM"})
+			}
+		}
+	case "AU3", "AU4":
+		if templateID == "AU4" || g.RandomInt(1, 100) <= 15 {
+			tx = sapTransactions[g.RandomInt(6, len(sapTransactions)-1)]
+		}
+		varA = tx.tcode
+		if templateID == "AU4" {
+			varB = "1" // locked by SM01 or missing S_TCODE authorization
+		}
+	case "AU5", "AU6":
+		logonType = "R"
+		varA, varC = logonType, method
+		userID = g.RandomChoice([]string{"RFC_BW", "RFC_PI", "RFC_SOLMAN", userID})
+		terminal = Entities.RandomHost(g.rnd, "linux", "windows").Hostname
+		tx = sapTransaction{"", "SAPMSSY1", false}
+		if templateID == "AU6" {
+			varB = g.WeightedChoice([]string{"1", "2"}, []float64{80, 20})
+		}
+	case "AUK", "AUL":
+		logonType = "R"
+		fn := sapRFCFunctions[g.RandomZipf(len(sapRFCFunctions), 1.0)]
+		varA, varC = fn[0], fn[1]
+		userID = g.RandomChoice([]string{"RFC_BW", "RFC_PI", "RFC_SOLMAN", userID})
+		tx = sapTransaction{"", "SAPMSSY1", false}
+	case "AU7":
+		tx = sapTransaction{"SU01", "SAPMSUU0", true}
+		varA = g.RandomChoice([]string{"TESTUSER", "SUPPORT01", "FIREFIGHTER", sapUser(Entities.RandomUser(g.rnd))})
+	case "AUB":
+		tx = sapTransaction{"PFCG", "SAPLPRGN_TREE", true}
+		varA = sapUser(Entities.RandomUser(g.rnd))
+	case "AUM":
+		tx = sapTransaction{"", "SAPMSYST", false}
+		varA, varB = sapClient, userID
+	}
+
+	message := strings.NewReplacer("&A", varA, "&B", varB, "&C", varC).Replace(msg.text)
+
+	fields := map[string]interface{}{
+		"date":         now.Format("20060102"),
+		"time":         now.Format("150405"),
+		"sid":          sapSID,
+		"instance":     sapInstance,
+		"client":       sapClient,
+		"user":         userID,
+		"terminal":     terminal,
+		"tcode":        tx.tcode,
+		"program":      tx.program,
+		"msg_id":       templateID,
+		"audit_class":  msg.class,
+		"severity":     msg.severity,
+		"logon_type":   logonType,
+		"variable_a":   varA,
+		"variable_b":   varB,
+		"variable_c":   varC,
+		"message":      message,
+		"os_pid":       g.RandomInt(1000, 65000),
+		"work_process": fmt.Sprintf("DIA%03d", g.RandomInt(0, 20)),
+	}
+	if tx.critical {
+		fields["severity"] = "High"
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	// SM20 / RSAU_READ_LOG export layout
+	rawEvent := fmt.Sprintf("%v|%v|%v|%v|%v|%v|%v|%v|%v|%v|%v|%v|%v|%v",
+		fields["date"], fields["time"], fields["sid"], fields["instance"], fields["client"],
+		fields["user"], fields["terminal"], fields["tcode"], fields["program"], fields["msg_id"],
+		fields["audit_class"], fields["severity"], fields["work_process"], fields["message"])
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "sap_audit",
+		EventID:    templateID,
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "sap:auditlog",
+	}, nil
+}
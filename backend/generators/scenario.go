@@ -0,0 +1,253 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// scenarioTargetChance is the probability that a metric generator reports on
+// a scenario's target instead of a random entity, so a degradation on one
+// host or service shows up often enough to trip KPI thresholds
+const scenarioTargetChance = 50
+
+// triggeredScenario is a scenario running against the metric generators
+type triggeredScenario struct {
+	scenario  models.Scenario
+	triggered time.Time
+}
+
+// ScenarioEngine applies triggered scenarios to generated metric values.
+// Progress is a pure function of the event timestamp and trigger time, so a
+// scenario plays out identically however fast events are generated.
+type ScenarioEngine struct {
+	mu     sync.RWMutex
+	active map[string]*triggeredScenario
+}
+
+// NewScenarioEngine creates an engine with no triggered scenarios
+func NewScenarioEngine() *ScenarioEngine {
+	return &ScenarioEngine{active: make(map[string]*triggeredScenario)}
+}
+
+// Scenarios is the shared engine consulted by the metric generators
+var Scenarios = NewScenarioEngine()
+
+// ValidateScenario checks a scenario definition and fills in the default mode
+func ValidateScenario(s *models.Scenario) error {
+	if s.Mode == "" {
+		s.Mode = models.ScenarioModeAbsolute
+	}
+	if s.Mode != models.ScenarioModeAbsolute && s.Mode != models.ScenarioModeMultiplier {
+		return fmt.Errorf("unknown scenario mode: %s", s.Mode)
+	}
+	if s.Mode == models.ScenarioModeMultiplier && s.Target < 0 {
+		return fmt.Errorf("multiplier target must be >= 0")
+	}
+	if s.RampSeconds < 0 || s.HoldSeconds < 0 || s.RecoverSeconds < 0 {
+		return fmt.Errorf("scenario durations must be >= 0")
+	}
+	if s.RampSeconds+s.HoldSeconds+s.RecoverSeconds == 0 {
+		return fmt.Errorf("scenario needs a ramp, hold or recover duration")
+	}
+	for i := range s.ExpectedDetections {
+		if err := s.ExpectedDetections[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if s.DetectionWaitSeconds < 0 || s.DetectionWaitSeconds > 86400 {
+		return fmt.Errorf("detection_wait_seconds must be between 0 and 86400")
+	}
+	return nil
+}
+
+// Trigger starts a scenario at the given time, restarting it if already running
+func (e *ScenarioEngine) Trigger(s models.Scenario, at time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.active[s.ID] = &triggeredScenario{scenario: s, triggered: at}
+}
+
+// Update replaces the definition of a running scenario without restarting it
+func (e *ScenarioEngine) Update(s models.Scenario) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ts, ok := e.active[s.ID]; ok {
+		ts.scenario = s
+	}
+}
+
+// Stop removes a scenario, returning its metric to normal immediately
+func (e *ScenarioEngine) Stop(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.active[id]; !ok {
+		return false
+	}
+	delete(e.active, id)
+	return true
+}
+
+// Triggered returns the trigger time of every scenario that has not yet
+// completed at the given time
+func (e *ScenarioEngine) Triggered(now time.Time) map[string]time.Time {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	triggered := make(map[string]time.Time)
+	for id, ts := range e.active {
+		if now.Before(ts.triggered.Add(scenarioDuration(&ts.scenario))) {
+			triggered[id] = ts.triggered
+		}
+	}
+	return triggered
+}
+
+// Status reports the phase of a scenario at the given time
+func (e *ScenarioEngine) Status(id string, now time.Time) models.ScenarioStatus {
+	e.mu.RLock()
+	ts, ok := e.active[id]
+	e.mu.RUnlock()
+	if !ok {
+		return models.ScenarioStatus{Phase: models.ScenarioPhaseIdle}
+	}
+
+	phase, progress := scenarioProgress(&ts.scenario, now.Sub(ts.triggered))
+	triggered := ts.triggered
+	ends := triggered.Add(scenarioDuration(&ts.scenario))
+	return models.ScenarioStatus{
+		Phase:       phase,
+		Progress:    progress,
+		TriggeredAt: &triggered,
+		EndsAt:      &ends,
+	}
+}
+
+// Apply adjusts a metric value for every running scenario that matches the
+// metric name and dimensions at the given time
+func (e *ScenarioEngine) Apply(metric string, value float64, dims map[string]string, at time.Time) float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, ts := range e.active {
+		s := &ts.scenario
+		if !scenarioMatches(s, metric, dims) {
+			continue
+		}
+		_, progress := scenarioProgress(s, at.Sub(ts.triggered))
+		if progress == 0 {
+			continue
+		}
+		switch s.Mode {
+		case models.ScenarioModeMultiplier:
+			value *= 1 + (s.Target-1)*progress
+		default:
+			value += (s.Target - value) * progress
+		}
+	}
+	return value
+}
+
+// TargetValue returns the value a running scenario filters dimension on, for
+// a scenario whose metric starts with one of prefixes. Generators call this
+// when picking a host or service so the scenario target is reported regularly.
+func (e *ScenarioEngine) TargetValue(rnd *RandomStream, dimension string, prefixes ...string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if len(e.active) == 0 {
+		return "", false
+	}
+
+	now := time.Now()
+	var candidates []string
+	for _, ts := range e.active {
+		s := &ts.scenario
+		value, ok := s.Match[dimension]
+		if !ok || !hasAnyPrefix(strings.TrimSuffix(s.Metric, "*"), prefixes) {
+			continue
+		}
+		if phase, _ := scenarioProgress(s, now.Sub(ts.triggered)); phase == models.ScenarioPhaseCompleted {
+			continue
+		}
+		candidates = append(candidates, value)
+	}
+
+	b := BaseGenerator{rnd: rnd}
+	if len(candidates) == 0 || b.RandomInt(0, 99) >= scenarioTargetChance {
+		return "", false
+	}
+	return b.RandomChoice(candidates), true
+}
+
+// scenarioDuration is the total length of a scenario's timeline
+func scenarioDuration(s *models.Scenario) time.Duration {
+	return time.Duration(s.RampSeconds+s.HoldSeconds+s.RecoverSeconds) * time.Second
+}
+
+// scenarioProgress returns the phase and how far the metric has moved
+// towards the target, elapsed time after the trigger
+func scenarioProgress(s *models.Scenario, elapsed time.Duration) (string, float64) {
+	ramp := time.Duration(s.RampSeconds) * time.Second
+	hold := time.Duration(s.HoldSeconds) * time.Second
+	recover := time.Duration(s.RecoverSeconds) * time.Second
+
+	switch {
+	case elapsed < 0:
+		return models.ScenarioPhaseIdle, 0
+	case elapsed < ramp:
+		return models.ScenarioPhaseRamp, float64(elapsed) / float64(ramp)
+	case elapsed < ramp+hold:
+		return models.ScenarioPhaseHold, 1
+	case elapsed < ramp+hold+recover:
+		return models.ScenarioPhaseRecover, 1 - float64(elapsed-ramp-hold)/float64(recover)
+	default:
+		return models.ScenarioPhaseCompleted, 0
+	}
+}
+
+// scenarioMatches reports whether a metric and its dimensions are in scope.
+// Host filters match both the full name and the short name before the first dot.
+func scenarioMatches(s *models.Scenario, metric string, dims map[string]string) bool {
+	if prefix, ok := strings.CutSuffix(s.Metric, "*"); ok {
+		if !strings.HasPrefix(metric, prefix) {
+			return false
+		}
+	} else if s.Metric != metric {
+		return false
+	}
+
+	for key, want := range s.Match {
+		got := dims[key]
+		if got == want {
+			continue
+		}
+		if key == "host" {
+			if short, _, _ := strings.Cut(got, "."); short == want {
+				continue
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// hasAnyPrefix reports whether s starts with any of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifyHost expands a short scenario host name into the domain the metric
+// generators report hosts in
+func qualifyHost(host string) string {
+	if strings.Contains(host, ".") {
+		return host
+	}
+	return host + "." + Theme().InternalDomain
+}
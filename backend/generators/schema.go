@@ -0,0 +1,120 @@
+package generators
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"siem-event-generator/models"
+)
+
+// schemaSamples is the number of events generated to discover fields; several
+// samples catch fields that only appear on some code paths
+const schemaSamples = 5
+
+// FindTemplate locates a builtin template by ID. When eventType is empty the
+// first generator providing the template wins, in event type ID order.
+func FindTemplate(eventType, templateID string) (Generator, *models.EventTemplate, bool) {
+	for _, g := range Generators() {
+		if eventType != "" && g.GetEventType().ID != eventType {
+			continue
+		}
+		for _, t := range g.GetTemplates() {
+			if t.ID == templateID {
+				tmpl := t
+				return g, &tmpl, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// InferSchema generates sample events for a template and describes every
+// field they contain
+func InferSchema(g Generator, templateID string) (*models.TemplateSchema, error) {
+	tmpl, err := templateByID(g, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*models.FieldSchema)
+	for i := 0; i < schemaSamples; i++ {
+		event, err := Unseeded.Generate(g, templateID, nil)
+		if err != nil {
+			return nil, err
+		}
+		// Top-level objects can only be overridden as a whole, so list them
+		// alongside their flattened leaves
+		for name, value := range event.Fields {
+			if _, seen := byName[name]; seen || ValueType(value) != "object" {
+				continue
+			}
+			byName[name] = &models.FieldSchema{Name: name, Type: "object", Overridable: true}
+		}
+		for path, value := range FlattenFields(event.Fields) {
+			if _, seen := byName[path]; seen {
+				continue
+			}
+			byName[path] = &models.FieldSchema{
+				Name:        path,
+				Type:        ValueType(value),
+				Example:     value,
+				Overridable: !strings.Contains(path, "."),
+			}
+		}
+	}
+
+	fields := make([]models.FieldSchema, 0, len(byName))
+	for _, f := range byName {
+		fields = append(fields, *f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+
+	return &models.TemplateSchema{
+		EventType:  g.GetEventType().ID,
+		TemplateID: tmpl.ID,
+		Name:       tmpl.Name,
+		Format:     tmpl.Format,
+		Sourcetype: tmpl.Sourcetype,
+		Source:     "builtin",
+		Fields:     fields,
+
+		SchemaVersion: tmpl.SchemaVersion,
+	}, nil
+}
+
+// templateByID returns the generator's template with the given ID
+func templateByID(g Generator, templateID string) (*models.EventTemplate, error) {
+	for _, t := range g.GetTemplates() {
+		if t.ID == templateID {
+			tmpl := t
+			return &tmpl, nil
+		}
+	}
+	return nil, fmt.Errorf("template %s not found for event type %s", templateID, g.GetEventType().ID)
+}
+
+// ValueType returns the JSON-style type name of a generated field value
+func ValueType(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
+}
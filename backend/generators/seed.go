@@ -0,0 +1,140 @@
+package generators
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	mrand "math/rand"
+	"reflect"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// RandomStream is a source of the random values generators draw. A seeded
+// stream gives the same values for the same seed; the nil stream, Unseeded,
+// draws from crypto/rand. A seeded stream generates with its own copies of
+// the built-in generators, so nothing else draws from it and the counters
+// those generators keep start afresh for every stream.
+type RandomStream struct {
+	mu   sync.Mutex
+	r    *mrand.Rand
+	gens sync.Map // event type ID and output -> the stream's copy of the generator
+}
+
+// NewRandomStream returns a stream seeded with seed
+func NewRandomStream(seed int64) *RandomStream {
+	return &RandomStream{r: mrand.New(mrand.NewSource(seed))}
+}
+
+// Unseeded is the nil stream, for generating outside a seeded run
+var Unseeded *RandomStream
+
+// Read fills p with random bytes; s is safe for concurrent use
+func (s *RandomStream) Read(p []byte) (int, error) {
+	if s == nil {
+		return rand.Read(p)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Read(p)
+}
+
+// reader returns the reader random values are drawn from
+func (s *RandomStream) reader() io.Reader {
+	if s == nil {
+		return rand.Reader
+	}
+	return s
+}
+
+// intn returns a uniformly distributed int in [0, n)
+func (s *RandomStream) intn(n int) int {
+	v, _ := rand.Int(s.reader(), big.NewInt(int64(n)))
+	return int(v.Int64())
+}
+
+// float64 returns a uniformly distributed float in [0, 1)
+func (s *RandomStream) float64() float64 {
+	n, _ := rand.Int(s.reader(), big.NewInt(1<<53))
+	return float64(n.Int64()) / (1 << 53)
+}
+
+// Generate creates an event with g, drawing its values from s. Timestamps,
+// UUIDs and the state generators share, such as process trees and logon
+// sessions, still vary between runs with the same seed.
+func (s *RandomStream) Generate(g Generator, templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	return s.generator(g, "").Generate(templateID, overrides)
+}
+
+// GenerateOutput is Generate for callers that keep only one form of the
+// event, models.OutputRaw or models.OutputFields. For fields, a copy of g
+// that skips rendering raw JSON events generates; for raw, the fields are
+// dropped as soon as the event is built.
+func (s *RandomStream) GenerateOutput(g Generator, templateID string, overrides map[string]interface{}, output string) (*models.GeneratedEvent, error) {
+	event, err := s.generator(g, output).Generate(templateID, overrides)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyOutput(event, output), nil
+}
+
+// fieldsOnly holds the unseeded copies of the built-in generators that skip
+// rendering raw events, by event type ID
+var fieldsOnly sync.Map
+
+// generator returns the copy of a registered generator that draws from s
+// and renders output. The nil stream rendering both forms and plugins,
+// which draw and render their own events, use g itself.
+func (s *RandomStream) generator(g Generator, output string) Generator {
+	v, ok := g.(versionedGenerator)
+	if !ok || v.initial == nil {
+		return g
+	}
+	if output != models.OutputFields {
+		output = ""
+	}
+	copies := &fieldsOnly
+	if s != nil {
+		copies = &s.gens
+	} else if output == "" {
+		return g
+	}
+	key := g.GetEventType().ID + "/" + output
+	if c, ok := copies.Load(key); ok {
+		return c.(Generator)
+	}
+	c, _ := copies.LoadOrStore(key, versionedGenerator{Generator: v.copy(s, output)})
+	return c.(Generator)
+}
+
+// seedable is implemented by generators that embed BaseGenerator
+type seedable interface {
+	base() *BaseGenerator
+}
+
+func (b *BaseGenerator) base() *BaseGenerator { return b }
+
+// snapshot returns a copy of a generator as registered, before it has
+// generated anything, or nil if it does not embed BaseGenerator
+func snapshot(g Generator) Generator {
+	if _, ok := g.(seedable); !ok {
+		return nil
+	}
+	v := reflect.ValueOf(g)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface().(Generator)
+}
+
+// copy returns a fresh copy of v's generator as registered, drawing its
+// values from s and rendering output
+func (v versionedGenerator) copy(s *RandomStream, output string) Generator {
+	g := snapshot(v.initial)
+	b := g.(seedable).base()
+	b.rnd, b.output = s, output
+	return g
+}
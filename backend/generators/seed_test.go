@@ -0,0 +1,55 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSameSeedGivesSameEvent(t *testing.T) {
+	g, ok := GetGenerator("okta")
+	if !ok {
+		t.Fatal("okta generator not registered")
+	}
+	for _, tmpl := range g.GetTemplates() {
+		a, err := NewRandomStream(42).Generate(g, tmpl.ID, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl.ID, err)
+		}
+		b, err := NewRandomStream(42).Generate(g, tmpl.ID, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl.ID, err)
+		}
+		fa, fb := FlattenFields(a.Fields), FlattenFields(b.Fields)
+		for _, k := range []string{"published", "uuid"} {
+			delete(fa, k)
+			delete(fb, k)
+		}
+		if !reflect.DeepEqual(fa, fb) {
+			t.Errorf("%s: same seed gave different fields:\n%v\n%v", tmpl.ID, fa, fb)
+		}
+	}
+}
+
+func TestSeededStreamLeavesRegisteredGeneratorAlone(t *testing.T) {
+	g, _ := GetGenerator("okta")
+	s := NewRandomStream(1)
+	if s.generator(g, "") == g {
+		t.Fatal("seeded stream generated with the registered generator")
+	}
+	if s.generator(g, "") != s.generator(g, "") {
+		t.Error("stream did not reuse its copy of the generator")
+	}
+	if Unseeded.generator(g, "") != g {
+		t.Error("unseeded stream did not use the registered generator")
+	}
+}
+
+func TestRandomCommonPortIsSeeded(t *testing.T) {
+	a := BaseGenerator{rnd: NewRandomStream(3)}
+	b := BaseGenerator{rnd: NewRandomStream(3)}
+	for i := 0; i < 20; i++ {
+		if pa, pb := a.RandomCommonPort(), b.RandomCommonPort(); pa != pb {
+			t.Fatalf("draw %d: %v != %v", i, pa, pb)
+		}
+	}
+}
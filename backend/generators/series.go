@@ -0,0 +1,170 @@
+package generators
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+)
+
+// SeriesSpec describes the long-run behaviour of a stateful metric series.
+// Each step follows an AR(1) process around a slowly drifting level:
+//
+//	level = clamp(level + trend + N(0, drift/20), mean-drift, mean+drift)
+//	value = clamp(level + reversion*(value-level) + N(0, volatility), min, max)
+type SeriesSpec struct {
+	Mean       float64 // Long-run level the series reverts to
+	Min        float64 // Hard lower bound
+	Max        float64 // Hard upper bound
+	Volatility float64 // Standard deviation of the per-step noise
+	Reversion  float64 // AR(1) coefficient in [0, 1); higher is smoother
+	Drift      float64 // How far the level may wander from Mean
+	Trend      float64 // Per-step push applied to the level
+}
+
+// seriesState holds the running state of one series
+type seriesState struct {
+	level   float64
+	value   float64
+	updated time.Time
+}
+
+// seriesIdleReset is how long a series may go unpolled before it restarts
+// from a fresh level instead of continuing where it left off
+const seriesIdleReset = time.Hour
+
+// SeriesEngine keeps per-host metric state so consecutive samples form
+// believable time series instead of independent random draws
+type SeriesEngine struct {
+	mu     sync.Mutex
+	series map[string]*seriesState
+}
+
+// NewSeriesEngine creates an empty series engine
+func NewSeriesEngine() *SeriesEngine {
+	return &SeriesEngine{series: make(map[string]*seriesState)}
+}
+
+// Series is the shared engine used by the metric generators
+var Series = NewSeriesEngine()
+
+// seriesKey builds the state key for a host's metric
+func seriesKey(host, metric string) string {
+	return host + "|" + metric
+}
+
+// Next advances the series for host/metric by one step and returns the new value
+func (e *SeriesEngine) Next(rnd *RandomStream, host, metric string, spec SeriesSpec) float64 {
+	b := BaseGenerator{rnd: rnd}
+	key := seriesKey(host, metric)
+	now := time.Now()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st, ok := e.series[key]
+	if !ok || now.Sub(st.updated) > seriesIdleReset {
+		level := spec.Mean + b.RandomFloat(-spec.Drift, spec.Drift)
+		st = &seriesState{level: level, value: clamp(level+b.RandomGaussian(0, spec.Volatility), spec.Min, spec.Max)}
+		e.series[key] = st
+	} else {
+		st.level += spec.Trend
+		if spec.Drift > 0 {
+			st.level += b.RandomGaussian(0, spec.Drift/20)
+		}
+		st.level = clamp(st.level, spec.Mean-spec.Drift, spec.Mean+spec.Drift)
+		st.value = st.level + spec.Reversion*(st.value-st.level) + b.RandomGaussian(0, spec.Volatility)
+		st.value = clamp(st.value, spec.Min, spec.Max)
+	}
+	st.updated = now
+	return st.value
+}
+
+// Smooth feeds input into an exponential moving average for host/metric and
+// returns the smoothed value. Useful for derived series such as 5 and 15
+// minute load averages that lag the 1 minute value.
+func (e *SeriesEngine) Smooth(host, metric string, input, alpha float64) float64 {
+	key := seriesKey(host, metric)
+	now := time.Now()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st, ok := e.series[key]
+	if !ok || now.Sub(st.updated) > seriesIdleReset {
+		st = &seriesState{value: input}
+		e.series[key] = st
+	} else {
+		st.value = alpha*input + (1-alpha)*st.value
+	}
+	st.updated = now
+	return st.value
+}
+
+// Counter adds a non-negative increment to a monotonically increasing series
+// for host/metric and returns the running total, starting from start
+func (e *SeriesEngine) Counter(host, metric string, start, increment float64) float64 {
+	key := seriesKey(host, metric)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st, ok := e.series[key]
+	if !ok {
+		st = &seriesState{value: start}
+		e.series[key] = st
+	}
+	if increment > 0 {
+		st.value += increment
+	}
+	st.updated = time.Now()
+	return st.value
+}
+
+// Reset drops all series state
+func (e *SeriesEngine) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.series = make(map[string]*seriesState)
+}
+
+func clamp(v, min, max float64) float64 {
+	if max > min {
+		return math.Max(min, math.Min(max, v))
+	}
+	return v
+}
+
+// HostProfile holds attributes that must stay constant for a host across
+// events, such as its size and placement
+type HostProfile struct {
+	Cores       int
+	MemoryGB    int
+	Region      string
+	Environment string
+	Datacenter  string
+}
+
+// ProfileForHost derives a stable profile from the host name so the same
+// host always reports the same core count, memory size and location
+func ProfileForHost(host string) HostProfile {
+	h := fnv.New64a()
+	h.Write([]byte(host))
+	sum := h.Sum64()
+
+	cores := []int{4, 8, 8, 16, 16, 32}
+	memory := []int{8, 16, 32, 64, 128, 256}
+	regions := []string{"us-east-1", "us-west-2", "us-gov-east-1", "us-gov-west-1"}
+	envs := []string{"production", "production", "staging", "development"}
+	dcs := []string{"dc1", "dc2", "dc3", "aws-east", "aws-west", "gcp-central"}
+
+	pick := func(n int, shift uint) int { return int((sum >> shift) % uint64(n)) }
+
+	return HostProfile{
+		Cores:       cores[pick(len(cores), 0)],
+		MemoryGB:    memory[pick(len(memory), 8)],
+		Region:      regions[pick(len(regions), 16)],
+		Environment: envs[pick(len(envs), 24)],
+		Datacenter:  dcs[pick(len(dcs), 32)],
+	}
+}
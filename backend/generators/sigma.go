@@ -0,0 +1,778 @@
+package generators
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"siem-event-generator/models"
+)
+
+// SigmaRule is a parsed Sigma detection rule. Matching follows the Sigma
+// specification for the modifiers listed in sigmaModifiers; aggregations and
+// correlations are not supported.
+type SigmaRule struct {
+	Title     string
+	ID        string
+	Level     string
+	Logsource models.SigmaLogsource
+	Condition string
+
+	identifiers map[string]*sigmaIdentifier
+	root        sigmaNode
+}
+
+// sigmaIdentifier is a search identifier of the detection block: any of its
+// maps matches when all of the map's fields do
+type sigmaIdentifier struct {
+	name string
+	maps [][]*sigmaField
+}
+
+// sigmaField is one "Field|modifier: values" entry. An empty name is a
+// keyword, searched for in the raw event and every field.
+type sigmaField struct {
+	name   string
+	key    string // As written in the rule
+	match  string // "", contains, startswith, endswith, re, cidr, gt, gte, lt, lte or exists
+	all    bool   // Every value must match, not just one
+	cased  bool
+	values []*sigmaValue
+}
+
+// sigmaValue is one value of a field, with the variants a transformation
+// such as base64offset or windash expands it into; any variant matches
+type sigmaValue struct {
+	null     bool
+	literals []string // Variants with wildcards, as generation fills them in
+	patterns []*regexp.Regexp
+	cidr     *net.IPNet
+	number   float64
+	exists   bool
+}
+
+// sigmaModifiers are the field modifiers supported
+var sigmaModifiers = map[string]bool{
+	"contains": true, "startswith": true, "endswith": true, "all": true, "cased": true,
+	"re": true, "i": true, "m": true, "s": true, "cidr": true, "exists": true,
+	"gt": true, "gte": true, "lt": true, "lte": true,
+	"base64": true, "base64offset": true, "windash": true,
+}
+
+// ParseSigmaRule reads a Sigma rule from its YAML
+func ParseSigmaRule(data []byte) (*SigmaRule, error) {
+	var doc struct {
+		Title       string                 `yaml:"title"`
+		ID          string                 `yaml:"id"`
+		Level       string                 `yaml:"level"`
+		Logsource   models.SigmaLogsource  `yaml:"logsource"`
+		Detection   map[string]interface{} `yaml:"detection"`
+		Correlation interface{}            `yaml:"correlation"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("rule is not valid YAML: %w", err)
+	}
+	if doc.Correlation != nil {
+		return nil, fmt.Errorf("correlation rules are not supported; use the rules they correlate")
+	}
+	if len(doc.Detection) == 0 {
+		return nil, fmt.Errorf("rule has no detection")
+	}
+
+	rule := &SigmaRule{
+		Title:       doc.Title,
+		ID:          doc.ID,
+		Level:       doc.Level,
+		Logsource:   doc.Logsource,
+		identifiers: make(map[string]*sigmaIdentifier),
+	}
+	var conditions []string
+	for name, value := range doc.Detection {
+		switch name {
+		case "condition":
+			switch c := value.(type) {
+			case string:
+				conditions = []string{c}
+			case []interface{}:
+				for _, item := range c {
+					s, ok := item.(string)
+					if !ok {
+						return nil, fmt.Errorf("condition must be a string or a list of strings")
+					}
+					conditions = append(conditions, s)
+				}
+			default:
+				return nil, fmt.Errorf("condition must be a string or a list of strings")
+			}
+		case "timeframe":
+			// Only used by aggregations
+		default:
+			ident, err := parseSigmaIdentifier(name, value)
+			if err != nil {
+				return nil, err
+			}
+			rule.identifiers[name] = ident
+		}
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("detection has no condition")
+	}
+
+	// A list of conditions matches when any of them does
+	nodes := make(sigmaOr, 0, len(conditions))
+	for _, c := range conditions {
+		node, err := parseSigmaCondition(c, rule.identifiers)
+		if err != nil {
+			return nil, fmt.Errorf("condition %q: %w", c, err)
+		}
+		nodes = append(nodes, node)
+	}
+	rule.Condition = strings.Join(conditions, " or ")
+	rule.root = nodes[0]
+	if len(nodes) > 1 {
+		rule.root = nodes
+	}
+	return rule, nil
+}
+
+// Info describes the rule for responses
+func (r *SigmaRule) Info() models.SigmaRuleInfo {
+	return models.SigmaRuleInfo{
+		Title:     r.Title,
+		ID:        r.ID,
+		Level:     r.Level,
+		Logsource: r.Logsource,
+		Condition: r.Condition,
+	}
+}
+
+// Fields lists the field names the rule's detection uses, sorted
+func (r *SigmaRule) Fields() []string {
+	seen := make(map[string]bool)
+	for _, ident := range r.identifiers {
+		for _, m := range ident.maps {
+			for _, f := range m {
+				if f.name != "" {
+					seen[f.name] = true
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseSigmaIdentifier(name string, value interface{}) (*sigmaIdentifier, error) {
+	ident := &sigmaIdentifier{name: name}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m, err := parseSigmaMap(name, v)
+		if err != nil {
+			return nil, err
+		}
+		ident.maps = append(ident.maps, m)
+	case []interface{}:
+		// A list of maps matches when any map does; a list of plain values
+		// is a list of keywords
+		var keywords []interface{}
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				fields, err := parseSigmaMap(name, m)
+				if err != nil {
+					return nil, err
+				}
+				ident.maps = append(ident.maps, fields)
+			} else {
+				keywords = append(keywords, item)
+			}
+		}
+		if len(keywords) > 0 {
+			f, err := parseSigmaField("", keywords)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			ident.maps = append(ident.maps, []*sigmaField{f})
+		}
+	case string, int, float64, bool:
+		f, err := parseSigmaField("", v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		ident.maps = append(ident.maps, []*sigmaField{f})
+	default:
+		return nil, fmt.Errorf("%s must be a map, a list of maps or a list of keywords", name)
+	}
+	if len(ident.maps) == 0 {
+		return nil, fmt.Errorf("%s is empty", name)
+	}
+	return ident, nil
+}
+
+func parseSigmaMap(ident string, m map[string]interface{}) ([]*sigmaField, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]*sigmaField, 0, len(keys))
+	for _, k := range keys {
+		f, err := parseSigmaField(k, m[k])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ident, err)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// parseSigmaField reads a "Field|modifier|...: value or values" entry
+func parseSigmaField(key string, value interface{}) (*sigmaField, error) {
+	parts := strings.Split(key, "|")
+	f := &sigmaField{name: parts[0], key: key}
+
+	var transforms []string
+	reFlags := ""
+	for _, mod := range parts[1:] {
+		if !sigmaModifiers[mod] {
+			return nil, fmt.Errorf("field %s: modifier %q is not supported", key, mod)
+		}
+		switch mod {
+		case "all":
+			f.all = true
+		case "cased":
+			f.cased = true
+		case "i", "m", "s":
+			reFlags += mod
+		case "base64", "base64offset", "windash":
+			transforms = append(transforms, mod)
+		default:
+			if f.match != "" {
+				return nil, fmt.Errorf("field %s: %s cannot be combined with %s", key, mod, f.match)
+			}
+			f.match = mod
+		}
+	}
+	if f.name == "" && f.match == "" {
+		f.match = "contains" // Keywords are searched for anywhere
+	}
+
+	raw, ok := value.([]interface{})
+	if !ok {
+		raw = []interface{}{value}
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("field %s has no values", key)
+	}
+	for _, item := range raw {
+		v, err := parseSigmaValue(f, item, transforms, reFlags)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", key, err)
+		}
+		f.values = append(f.values, v)
+	}
+	return f, nil
+}
+
+func parseSigmaValue(f *sigmaField, item interface{}, transforms []string, reFlags string) (*sigmaValue, error) {
+	v := &sigmaValue{}
+	if item == nil {
+		if f.match != "" {
+			return nil, fmt.Errorf("null cannot take the %s modifier", f.match)
+		}
+		v.null = true
+		return v, nil
+	}
+	s := fmt.Sprint(item)
+
+	switch f.match {
+	case "exists":
+		b, ok := item.(bool)
+		if !ok {
+			return nil, fmt.Errorf("exists takes true or false")
+		}
+		v.exists = b
+		return v, nil
+	case "gt", "gte", "lt", "lte":
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s takes a number", f.match)
+		}
+		v.number = n
+		return v, nil
+	case "cidr":
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a CIDR range", s)
+		}
+		v.cidr = network
+		return v, nil
+	case "re":
+		pattern := s
+		if reFlags != "" {
+			pattern = "(?" + reFlags + ")" + s
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", s, err)
+		}
+		v.literals = []string{s}
+		v.patterns = []*regexp.Regexp{re}
+		return v, nil
+	}
+
+	variants := []string{s}
+	for _, t := range transforms {
+		var next []string
+		for _, variant := range variants {
+			switch t {
+			case "base64":
+				next = append(next, base64.StdEncoding.EncodeToString([]byte(variant)))
+			case "base64offset":
+				next = append(next, base64Offsets(variant)...)
+			case "windash":
+				next = append(next, windashVariants(variant)...)
+			}
+		}
+		variants = next
+	}
+	for _, variant := range variants {
+		pattern := sigmaWildcards(variant)
+		switch f.match {
+		case "contains":
+			pattern = ".*" + pattern + ".*"
+		case "startswith":
+			pattern = pattern + ".*"
+		case "endswith":
+			pattern = ".*" + pattern
+		}
+		flags := "s"
+		if !f.cased {
+			flags += "i"
+		}
+		v.literals = append(v.literals, variant)
+		v.patterns = append(v.patterns, regexp.MustCompile("(?"+flags+")^"+pattern+"$"))
+	}
+	return v, nil
+}
+
+// sigmaWildcards turns a Sigma value into a regular expression: * and ?
+// are wildcards unless escaped with a backslash
+func sigmaWildcards(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '*' || s[i+1] == '?' || s[i+1] == '\\'):
+			b.WriteString(regexp.QuoteMeta(s[i+1 : i+2]))
+			i++
+		case c == '*':
+			b.WriteString(".*")
+		case c == '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(s[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// base64Offsets returns the three forms a value takes when base64 encoded
+// at any offset of a longer string, without the characters its neighbours
+// change
+func base64Offsets(s string) []string {
+	variants := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		enc := base64.StdEncoding.EncodeToString([]byte(strings.Repeat(" ", i) + s))
+		start := []int{0, 2, 3}[i]
+		end := len(enc) - []int{0, 3, 2}[(len(s)+i)%3]
+		if start < end {
+			variants = append(variants, enc[start:end])
+		}
+	}
+	return variants
+}
+
+// windashVariants returns a value with its command-line flags introduced by
+// each of the dashes and slashes Windows programs accept
+func windashVariants(s string) []string {
+	variants := []string{s}
+	for _, dash := range []string{"/", "–", "—", "―"} {
+		var b strings.Builder
+		for i, r := range s {
+			if r == '-' && (i == 0 || s[i-1] == ' ') {
+				b.WriteString(dash)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		if b.String() != s {
+			variants = append(variants, b.String())
+		}
+	}
+	return variants
+}
+
+// sigmaEvent is an event as the rule sees it
+type sigmaEvent struct {
+	fields   map[string]interface{}
+	flat     map[string]interface{}
+	raw      string
+	fieldMap map[string]string // Sigma field to event field
+	selector string            // Field that names the template, e.g. EventID
+	eventID  string
+}
+
+func newSigmaEvent(event *models.GeneratedEvent, fieldMap map[string]string, selector string) *sigmaEvent {
+	return &sigmaEvent{
+		fields:   event.Fields,
+		flat:     FlattenFields(event.Fields),
+		raw:      event.RawEvent,
+		fieldMap: fieldMap,
+		selector: selector,
+		eventID:  event.EventID,
+	}
+}
+
+// lookup finds a Sigma field in the event, falling back to the selector
+func (e *sigmaEvent) lookup(name string) (interface{}, bool) {
+	if v, ok := e.field(name); ok {
+		return v, true
+	}
+	if e.selector != "" && strings.EqualFold(name, e.selector) {
+		return e.eventID, true
+	}
+	return nil, false
+}
+
+// field finds a Sigma field in the event's fields: through the field map,
+// then by exact name, dotted path and finally case-insensitively
+func (e *sigmaEvent) field(name string) (interface{}, bool) {
+	if mapped, ok := e.fieldMap[name]; ok {
+		name = mapped
+	}
+	if v, ok := e.fields[name]; ok {
+		return v, true
+	}
+	if v, ok := e.flat[name]; ok {
+		return v, true
+	}
+	for k, v := range e.flat {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Matches reports whether the rule matches an event. fieldMap maps Sigma
+// fields to the event's; selector names the field holding the event ID when
+// the event does not have it, e.g. EventID for Windows events.
+func (r *SigmaRule) Matches(event *models.GeneratedEvent, fieldMap map[string]string, selector string) bool {
+	return r.root.eval(r, newSigmaEvent(event, fieldMap, selector))
+}
+
+func (ident *sigmaIdentifier) eval(e *sigmaEvent) bool {
+	for _, m := range ident.maps {
+		matched := true
+		for _, f := range m {
+			if !f.eval(e) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *sigmaField) eval(e *sigmaEvent) bool {
+	if f.name == "" {
+		// Keywords: the raw event or any field
+		candidates := []interface{}{e.raw}
+		for _, v := range e.flat {
+			candidates = append(candidates, v)
+		}
+		for _, c := range candidates {
+			if f.matchValue(c, true) {
+				return true
+			}
+		}
+		return false
+	}
+	v, ok := e.lookup(f.name)
+	return f.matchValue(v, ok)
+}
+
+// matchValue reports whether an event value matches the field; present is
+// false when the event lacks the field
+func (f *sigmaField) matchValue(v interface{}, present bool) bool {
+	if v == nil {
+		present = false
+	}
+	if f.match == "exists" {
+		return f.values[0].exists == present
+	}
+	if f.all {
+		for _, sv := range f.values {
+			if !f.matchOne(sv, v, present) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, sv := range f.values {
+		if f.matchOne(sv, v, present) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *sigmaField) matchOne(sv *sigmaValue, v interface{}, present bool) bool {
+	if sv.null || !present {
+		return sv.null && !present
+	}
+	// A list matches when any of its items does
+	switch list := v.(type) {
+	case []interface{}:
+		for _, item := range list {
+			if f.matchOne(sv, item, item != nil) {
+				return true
+			}
+		}
+		return false
+	case []string:
+		for _, item := range list {
+			if f.matchOne(sv, item, true) {
+				return true
+			}
+		}
+		return false
+	}
+
+	s := fmt.Sprint(v)
+	switch f.match {
+	case "gt", "gte", "lt", "lte":
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return false
+		}
+		switch f.match {
+		case "gt":
+			return n > sv.number
+		case "gte":
+			return n >= sv.number
+		case "lt":
+			return n < sv.number
+		default:
+			return n <= sv.number
+		}
+	case "cidr":
+		ip := net.ParseIP(s)
+		return ip != nil && sv.cidr.Contains(ip)
+	case "re":
+		return sv.patterns[0].MatchString(s)
+	}
+	for _, p := range sv.patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// sigmaNode is a node of a parsed condition
+type sigmaNode interface {
+	eval(r *SigmaRule, e *sigmaEvent) bool
+}
+
+type (
+	sigmaAnd []sigmaNode
+	sigmaOr  []sigmaNode
+	sigmaNot struct{ node sigmaNode }
+	sigmaRef string
+)
+
+func (n sigmaAnd) eval(r *SigmaRule, e *sigmaEvent) bool {
+	for _, child := range n {
+		if !child.eval(r, e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n sigmaOr) eval(r *SigmaRule, e *sigmaEvent) bool {
+	for _, child := range n {
+		if child.eval(r, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n sigmaNot) eval(r *SigmaRule, e *sigmaEvent) bool {
+	return !n.node.eval(r, e)
+}
+
+func (n sigmaRef) eval(r *SigmaRule, e *sigmaEvent) bool {
+	return r.identifiers[string(n)].eval(e)
+}
+
+// sigmaTokens splits a condition into words and parentheses
+var sigmaTokens = regexp.MustCompile(`\(|\)|[^\s()]+`)
+
+// parseSigmaCondition parses a condition: identifiers and "1 of"/"all of"
+// patterns joined by not, and and or, in that precedence, with parentheses
+func parseSigmaCondition(condition string, identifiers map[string]*sigmaIdentifier) (sigmaNode, error) {
+	if strings.Contains(condition, "|") {
+		return nil, fmt.Errorf("aggregations are not supported")
+	}
+	p := &sigmaParser{tokens: sigmaTokens.FindAllString(condition, -1), identifiers: identifiers}
+	node, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+type sigmaParser struct {
+	tokens      []string
+	pos         int
+	identifiers map[string]*sigmaIdentifier
+}
+
+func (p *sigmaParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToLower(p.tokens[p.pos])
+	}
+	return ""
+}
+
+func (p *sigmaParser) or() (sigmaNode, error) {
+	node, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	nodes := sigmaOr{node}
+	for p.peek() == "or" {
+		p.pos++
+		next, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return node, nil
+	}
+	return nodes, nil
+}
+
+func (p *sigmaParser) and() (sigmaNode, error) {
+	node, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	nodes := sigmaAnd{node}
+	for p.peek() == "and" {
+		p.pos++
+		next, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return node, nil
+	}
+	return nodes, nil
+}
+
+func (p *sigmaParser) not() (sigmaNode, error) {
+	switch tok := p.peek(); tok {
+	case "":
+		return nil, fmt.Errorf("condition ends early")
+	case "not":
+		p.pos++
+		node, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return sigmaNot{node}, nil
+	case "(":
+		p.pos++
+		node, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	case "1", "any", "all":
+		if p.pos+2 >= len(p.tokens) || strings.ToLower(p.tokens[p.pos+1]) != "of" {
+			return nil, fmt.Errorf("expected %s of", tok)
+		}
+		pattern := p.tokens[p.pos+2]
+		p.pos += 3
+		refs, err := p.expand(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if tok == "all" {
+			return sigmaAnd(refs), nil
+		}
+		return sigmaOr(refs), nil
+	case ")", "and", "or", "of":
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	default:
+		name := p.tokens[p.pos]
+		p.pos++
+		if _, ok := p.identifiers[name]; !ok {
+			return nil, fmt.Errorf("no search identifier %s", name)
+		}
+		return sigmaRef(name), nil
+	}
+}
+
+// expand lists the identifiers a "1 of" pattern names, sorted
+func (p *sigmaParser) expand(pattern string) ([]sigmaNode, error) {
+	var names []string
+	for name := range p.identifiers {
+		if pattern == "them" {
+			// Identifiers starting with _ are left out of "them"
+			if !strings.HasPrefix(name, "_") {
+				names = append(names, name)
+			}
+		} else if ok, _ := path.Match(pattern, name); ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no search identifier matches %s", pattern)
+	}
+	sort.Strings(names)
+	refs := make([]sigmaNode, len(names))
+	for i, name := range names {
+		refs[i] = sigmaRef(name)
+	}
+	return refs, nil
+}
@@ -0,0 +1,796 @@
+package generators
+
+import (
+	"fmt"
+	"net"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+
+	"siem-event-generator/models"
+)
+
+// sigmaAttempts bounds the tries at generating one event that does (or does
+// not) match a rule before the last try is returned as it is
+const sigmaAttempts = 25
+
+// sigmaSource maps a Sigma logsource to the template that emits its events
+type sigmaSource struct {
+	product, category, service string // Empty product matches any
+
+	eventType    string
+	templateID   string
+	selector     string            // Field whose values choose among the event type's templates
+	keywordField string            // Where keywords are written
+	fieldMap     map[string]string // Sigma field to template field
+}
+
+// sigmaSources are the logsources generation knows, first match wins
+var sigmaSources = []sigmaSource{
+	{product: "windows", category: "process_creation", eventType: "windows_sysmon", templateID: "1", keywordField: "CommandLine"},
+	{product: "windows", category: "network_connection", eventType: "windows_sysmon", templateID: "3"},
+	{product: "windows", category: "image_load", eventType: "windows_sysmon", templateID: "7"},
+	{product: "windows", category: "create_remote_thread", eventType: "windows_sysmon", templateID: "8"},
+	{product: "windows", category: "process_access", eventType: "windows_sysmon", templateID: "10"},
+	{product: "windows", category: "file_event", eventType: "windows_sysmon", templateID: "11"},
+	{product: "windows", category: "registry_add", eventType: "windows_sysmon", templateID: "12"},
+	{product: "windows", category: "registry_delete", eventType: "windows_sysmon", templateID: "12"},
+	{product: "windows", category: "registry_set", eventType: "windows_sysmon", templateID: "13"},
+	{product: "windows", category: "registry_event", eventType: "windows_sysmon", templateID: "13"},
+	{product: "windows", category: "registry_rename", eventType: "windows_sysmon", templateID: "14"},
+	{product: "windows", category: "create_stream_hash", eventType: "windows_sysmon", templateID: "15"},
+	{product: "windows", category: "pipe_created", eventType: "windows_sysmon", templateID: "17"},
+	{product: "windows", category: "dns_query", eventType: "windows_sysmon", templateID: "22"},
+	{product: "windows", category: "process_tampering", eventType: "windows_sysmon", templateID: "25"},
+	{product: "windows", category: "ps_script", eventType: "windows_powershell", templateID: "4104", keywordField: "ScriptBlockText"},
+	{product: "windows", category: "ps_module", eventType: "windows_powershell", templateID: "4103", keywordField: "Payload"},
+	{product: "windows", service: "security", eventType: "windows_security", templateID: "4624", selector: "EventID"},
+	{product: "windows", service: "sysmon", eventType: "windows_sysmon", templateID: "1", selector: "EventID"},
+	{product: "windows", service: "powershell", eventType: "windows_powershell", templateID: "4104", selector: "EventID", keywordField: "ScriptBlockText"},
+	{product: "windows", service: "windefend", eventType: "windows_defender_av", templateID: "1116", selector: "EventID"},
+	{product: "linux", category: "process_creation", eventType: "linux_auditbeat", templateID: "process", keywordField: "process.command_line", fieldMap: map[string]string{
+		"Image":            "process.executable",
+		"CommandLine":      "process.command_line",
+		"CurrentDirectory": "process.working_directory",
+		"ParentImage":      "process.parent.executable",
+		"User":             "user.name",
+		"ProcessId":        "process.pid",
+		"ParentProcessId":  "process.parent.pid",
+	}},
+	{product: "aws", service: "cloudtrail", eventType: "aws_cloudtrail", templateID: "ConsoleLogin", selector: "eventName"},
+	{product: "azure", service: "signinlogs", eventType: "azure_ad_signin", templateID: "interactive_success"},
+	{product: "azure", service: "activitylogs", eventType: "azure_activity", templateID: "vm_create"},
+	{product: "m365", service: "audit", eventType: "o365_audit", templateID: "file_accessed", selector: "Operation"},
+	{product: "okta", service: "okta", eventType: "okta", selector: "eventType"},
+	{product: "github", service: "audit", eventType: "github_audit", selector: "action"},
+	{product: "kubernetes", service: "audit", eventType: "kubernetes_audit", selector: "verb"},
+	{product: "zeek", service: "conn", eventType: "zeek", templateID: "conn"},
+	{product: "zeek", service: "dns", eventType: "zeek", templateID: "dns"},
+	{product: "zeek", service: "http", eventType: "zeek", templateID: "http"},
+	{product: "zeek", service: "ssl", eventType: "zeek", templateID: "ssl"},
+	{product: "zeek", service: "files", eventType: "zeek", templateID: "files"},
+	{product: "zeek", service: "notice", eventType: "zeek", templateID: "notice"},
+	{category: "dns", eventType: "dns_query", templateID: "query_success", fieldMap: map[string]string{
+		"query":       "query_name",
+		"record_type": "query_type",
+		"answer":      "answers",
+		"src_ip":      "client_ip",
+	}},
+	{category: "firewall", eventType: "paloalto", templateID: "traffic_allow"},
+	{category: "webserver", eventType: "webserver", templateID: "success", keywordField: "uri", fieldMap: map[string]string{
+		"cs-method":     "method",
+		"cs-uri-query":  "uri",
+		"cs-uri-stem":   "uri",
+		"sc-status":     "status_code",
+		"c-ip":          "client_ip",
+		"cs-user-agent": "user_agent",
+		"cs-useragent":  "user_agent",
+		"cs-referer":    "referer",
+		"cs-host":       "host",
+	}},
+}
+
+// findSigmaSource returns the mapping of a rule's logsource
+func findSigmaSource(ls models.SigmaLogsource) (sigmaSource, bool) {
+	for _, src := range sigmaSources {
+		if src.product != "" && !strings.EqualFold(src.product, ls.Product) {
+			continue
+		}
+		if strings.EqualFold(src.category, ls.Category) && strings.EqualFold(src.service, ls.Service) {
+			return src, true
+		}
+	}
+	return sigmaSource{}, false
+}
+
+// SigmaGenerator generates events for a rule: positives it should match and
+// near misses it should not
+type SigmaGenerator struct {
+	BaseGenerator
+	rule     *SigmaRule
+	gen      Generator
+	source   sigmaSource
+	fieldMap map[string]string // Sigma field to template field, as resolved
+	unknown  map[string]bool   // Fields no template emits, set as new fields
+}
+
+// NewSigmaGenerator maps a rule to the template its events come from.
+// eventType and templateID replace the mapping of the rule's logsource;
+// fieldMap adds to its field names.
+func NewSigmaGenerator(rule *SigmaRule, eventType, templateID string, fieldMap map[string]string) (*SigmaGenerator, error) {
+	src, ok := findSigmaSource(rule.Logsource)
+	if eventType != "" {
+		if !ok || src.eventType != eventType {
+			src = sigmaSource{eventType: eventType}
+		}
+		if templateID != "" {
+			src.templateID = templateID
+		}
+	} else if !ok {
+		ls := rule.Logsource
+		return nil, fmt.Errorf("no template is mapped to logsource product=%q category=%q service=%q; set event_type and event_id", ls.Product, ls.Category, ls.Service)
+	}
+
+	gen, ok := GetGenerator(src.eventType)
+	if !ok {
+		return nil, fmt.Errorf("event type %s not found", src.eventType)
+	}
+	id, err := ResolveTemplateID(gen, src.templateID)
+	if err != nil {
+		return nil, err
+	}
+	src.templateID = id
+
+	g := &SigmaGenerator{
+		rule:     rule,
+		gen:      gen,
+		source:   src,
+		fieldMap: make(map[string]string),
+		unknown:  make(map[string]bool),
+	}
+	for k, v := range src.fieldMap {
+		g.fieldMap[k] = v
+	}
+	for k, v := range fieldMap {
+		g.fieldMap[k] = v
+	}
+	return g, nil
+}
+
+// EventType returns the event type generated
+func (g *SigmaGenerator) EventType() string {
+	return g.source.eventType
+}
+
+// FieldMap returns the template field each of the rule's fields was set on
+func (g *SigmaGenerator) FieldMap() map[string]string {
+	out := make(map[string]string)
+	for _, name := range g.rule.Fields() {
+		if mapped, ok := g.fieldMap[name]; ok {
+			out[name] = mapped
+		}
+	}
+	return out
+}
+
+// UnknownFields lists the rule's fields no generated event had, which were
+// added to the events as new fields
+func (g *SigmaGenerator) UnknownFields() []string {
+	var names []string
+	for _, name := range g.rule.Fields() {
+		if g.unknown[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Generate returns an event the rule should match when match is true, and a
+// near miss otherwise: an event that satisfies all but one part of the
+// condition. It tries sigmaAttempts times before giving up and returning
+// the last event, whose Matches then differs from match.
+func (g *SigmaGenerator) Generate(match bool) (models.SigmaSample, error) {
+	var last *models.SigmaSample
+	var lastErr error
+	for i := 0; i < sigmaAttempts; i++ {
+		sample, err := g.attempt(match)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if sample.Matches == match {
+			return sample, nil
+		}
+		last = &sample
+	}
+	if last == nil {
+		return models.SigmaSample{}, lastErr
+	}
+	return *last, nil
+}
+
+// sigmaPlan is what an attempt must make true: maps whose fields must all
+// match, and maps whose fields must all match but one
+type sigmaPlan struct {
+	match [][]*sigmaField
+	miss  []sigmaMiss
+}
+
+// sigmaMiss is a map to miss by its field fail alone
+type sigmaMiss struct {
+	fields []*sigmaField
+	fail   int
+}
+
+// plan picks, at random where the condition allows a choice, which
+// identifiers to satisfy so that node evaluates to want
+func (g *SigmaGenerator) plan(node sigmaNode, want bool, p *sigmaPlan) {
+	switch n := node.(type) {
+	case sigmaNot:
+		g.plan(n.node, !want, p)
+	case sigmaAnd:
+		if want {
+			for _, child := range n {
+				g.plan(child, true, p)
+			}
+			return
+		}
+		// A near miss fails one part and satisfies the rest
+		miss := g.RandomInt(0, len(n)-1)
+		for i, child := range n {
+			g.plan(child, i != miss, p)
+		}
+	case sigmaOr:
+		if want {
+			g.plan(n[g.RandomInt(0, len(n)-1)], true, p)
+			return
+		}
+		for _, child := range n {
+			g.plan(child, false, p)
+		}
+	case sigmaRef:
+		ident := g.rule.identifiers[string(n)]
+		if want {
+			p.match = append(p.match, ident.maps[g.RandomInt(0, len(ident.maps)-1)])
+		} else {
+			// Every map must fail; each misses by one field, a real one
+			// where it has any, since keywords cannot be kept out
+			for _, m := range ident.maps {
+				var named []int
+				for i, f := range m {
+					if f.name != "" {
+						named = append(named, i)
+					}
+				}
+				fail := g.RandomInt(0, len(m)-1)
+				if len(named) > 0 {
+					fail = named[g.RandomInt(0, len(named)-1)]
+				}
+				p.miss = append(p.miss, sigmaMiss{fields: m, fail: fail})
+			}
+		}
+	}
+}
+
+// attempt plans which parts of the rule to satisfy, sets fields to match or
+// miss them on a sample of the template and generates the event
+func (g *SigmaGenerator) attempt(match bool) (models.SigmaSample, error) {
+	p := &sigmaPlan{}
+	g.plan(g.rule.root, match, p)
+
+	templateID, err := g.chooseTemplate(p)
+	if err != nil {
+		return models.SigmaSample{}, err
+	}
+	sample, err := Unseeded.Generate(g.gen, templateID, nil)
+	if err != nil {
+		return models.SigmaSample{}, err
+	}
+	view := newSigmaEvent(sample, g.fieldMap, g.source.selector)
+
+	// Values set so far, by template field
+	set := make(map[string]interface{})
+	value := func(f *sigmaField) (interface{}, bool) {
+		path := g.target(f, view)
+		if v, ok := set[path]; ok {
+			return v, true
+		}
+		if g.isSelector(f.name, view) {
+			return sample.EventID, true
+		}
+		return view.lookup(path)
+	}
+
+	for _, m := range p.match {
+		for _, f := range m {
+			if g.isSelector(f.name, view) {
+				continue // Chosen with the template
+			}
+			current, _ := value(f)
+			path := g.fieldPath(f, view)
+			set[path] = g.matching(f, current)
+		}
+	}
+	// Near misses match on the fields other parts of the plan left alone,
+	// then fail on one
+	for _, miss := range p.miss {
+		for i, f := range miss.fields {
+			if i == miss.fail || g.isSelector(f.name, view) {
+				continue
+			}
+			path := g.fieldPath(f, view)
+			if _, taken := set[path]; !taken {
+				current, _ := value(f)
+				set[path] = g.matching(f, current)
+			}
+		}
+	}
+	for _, miss := range p.miss {
+		f := miss.fields[miss.fail]
+		if f.name == "" || g.isSelector(f.name, view) {
+			continue
+		}
+		path := g.fieldPath(f, view)
+		if _, taken := set[path]; taken {
+			continue
+		}
+		current, _ := value(f)
+		if v, ok := g.failing(f, current); ok {
+			set[path] = v
+		}
+	}
+
+	overrides := g.overrides(set, sample.Fields)
+	event, err := Unseeded.Generate(g.gen, templateID, overrides)
+	if err != nil {
+		return models.SigmaSample{}, err
+	}
+	return models.SigmaSample{
+		Matches:   g.rule.Matches(event, g.fieldMap, g.source.selector),
+		Overrides: overrides,
+		Event:     *event,
+	}, nil
+}
+
+// chooseTemplate picks the template whose event ID the plan's selector
+// values match, or the mapped template when the plan has none
+func (g *SigmaGenerator) chooseTemplate(p *sigmaPlan) (string, error) {
+	selector := g.source.selector
+	if selector == "" {
+		return g.source.templateID, nil
+	}
+	var wanted, unwanted []*sigmaField
+	for _, m := range p.match {
+		for _, f := range m {
+			if strings.EqualFold(f.name, selector) {
+				wanted = append(wanted, f)
+			}
+		}
+	}
+	for _, miss := range p.miss {
+		for i, f := range miss.fields {
+			switch {
+			case !strings.EqualFold(f.name, selector):
+			case i == miss.fail:
+				unwanted = append(unwanted, f)
+			default:
+				wanted = append(wanted, f)
+			}
+		}
+	}
+	if len(wanted) == 0 && len(unwanted) == 0 {
+		return g.source.templateID, nil
+	}
+
+	var candidates []string
+	for _, t := range g.gen.GetTemplates() {
+		ok := true
+		for _, f := range wanted {
+			ok = ok && f.matchValue(t.EventID, true)
+		}
+		for _, f := range unwanted {
+			ok = ok && !f.matchValue(t.EventID, true)
+		}
+		if ok {
+			candidates = append(candidates, t.ID)
+		}
+	}
+	for _, id := range candidates {
+		if id == g.source.templateID {
+			return id, nil
+		}
+	}
+	if len(candidates) > 0 {
+		return g.RandomChoice(candidates), nil
+	}
+
+	// The selector may still be set as a field of the mapped template
+	sample, err := Unseeded.Generate(g.gen, g.source.templateID, nil)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := newSigmaEvent(sample, g.fieldMap, "").field(selector); ok {
+		return g.source.templateID, nil
+	}
+	return "", fmt.Errorf("no %s template has the %s the rule asks for", g.source.eventType, selector)
+}
+
+// isSelector reports whether a field is the selector and the template
+// does not emit it, so only the choice of template can set it
+func (g *SigmaGenerator) isSelector(name string, view *sigmaEvent) bool {
+	if g.source.selector == "" || !strings.EqualFold(name, g.source.selector) {
+		return false
+	}
+	_, ok := view.field(name)
+	return !ok
+}
+
+// resolve returns the template field a Sigma field is read from
+func (g *SigmaGenerator) resolve(name string, view *sigmaEvent) string {
+	if mapped, ok := g.fieldMap[name]; ok {
+		return mapped
+	}
+	if _, ok := view.fields[name]; ok {
+		return name
+	}
+	if _, ok := view.flat[name]; ok {
+		return name
+	}
+	for k := range view.flat {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+// target returns the template field a Sigma field is set on. Keywords go
+// to the logsource's keyword field.
+func (g *SigmaGenerator) target(f *sigmaField, view *sigmaEvent) string {
+	if f.name == "" {
+		if g.source.keywordField != "" {
+			return g.source.keywordField
+		}
+		return "message"
+	}
+	return g.resolve(f.name, view)
+}
+
+// fieldPath returns the target of a field, remembering it for the
+// response. Fields the template lacks are added as they are named.
+func (g *SigmaGenerator) fieldPath(f *sigmaField, view *sigmaEvent) string {
+	path := g.target(f, view)
+	if _, ok := view.field(path); !ok && f.name != "" {
+		g.unknown[f.name] = true
+	}
+	if f.name != "" {
+		g.fieldMap[f.name] = path
+	}
+	return path
+}
+
+// matching returns a value the field matches, built on the template's
+// current value where the field only constrains part of it
+func (g *SigmaGenerator) matching(f *sigmaField, current interface{}) interface{} {
+	if current != nil && f.match != "exists" && f.matchValue(current, true) {
+		return current
+	}
+	base := ""
+	if current != nil {
+		base = fmt.Sprint(current)
+	}
+	switch f.match {
+	case "exists":
+		if !f.values[0].exists {
+			return nil
+		}
+		if current != nil {
+			return current
+		}
+		return g.RandomString(8)
+	case "gt", "gte", "lt", "lte":
+		n := f.values[0].number
+		if f.all {
+			// Clear the strictest bound
+			for _, v := range f.values[1:] {
+				if (f.match[0] == 'g' && v.number > n) || (f.match[0] == 'l' && v.number < n) {
+					n = v.number
+				}
+			}
+		}
+		switch f.match {
+		case "gt":
+			n++
+		case "lt":
+			n--
+		}
+		return numberValue(n)
+	case "cidr":
+		return randomIPIn(f.values[g.RandomInt(0, len(f.values)-1)].cidr, g)
+	case "re":
+		// The expression is searched for, so it can sit within the value
+		// unless anchored
+		pattern := f.values[g.RandomInt(0, len(f.values)-1)].literals[0]
+		generated := g.fromRegexp(pattern)
+		if base == "" || strings.HasPrefix(pattern, "^") || strings.HasSuffix(pattern, "$") {
+			return generated
+		}
+		return base + " " + generated
+	}
+
+	values := f.values
+	if !f.all {
+		values = []*sigmaValue{values[g.RandomInt(0, len(values)-1)]}
+	}
+	if values[0].null {
+		return nil
+	}
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, g.fill(v.literals[g.RandomInt(0, len(v.literals)-1)]))
+	}
+	lit := strings.Join(parts, " ")
+
+	switch f.match {
+	case "contains":
+		switch {
+		case base == "":
+			return lit
+		case strings.Contains(strings.ToLower(base), strings.ToLower(lit)):
+			return base
+		case startsWithSep(lit) && hasSep(base):
+			return dirName(base) + lit
+		case strings.HasPrefix(lit, " ") || strings.HasSuffix(base, " "):
+			return base + lit
+		default:
+			return base + " " + lit
+		}
+	case "startswith":
+		if endsWithSep(lit) && hasSep(base) {
+			return lit + baseName(base)
+		}
+		return lit
+	case "endswith":
+		if startsWithSep(lit) && hasSep(base) {
+			return dirName(base) + lit
+		}
+		return lit
+	}
+	if n, err := strconv.ParseInt(lit, 10, 64); err == nil {
+		if _, isString := current.(string); !isString {
+			return n // Keep numeric fields numbers
+		}
+	}
+	return lit
+}
+
+// failing returns a value the field does not match: the template's own
+// value when it already misses, or else a made-up one
+func (g *SigmaGenerator) failing(f *sigmaField, current interface{}) (interface{}, bool) {
+	candidates := []interface{}{current}
+	switch f.match {
+	case "exists":
+		candidates = []interface{}{nil, g.RandomString(8)}
+	case "gt", "gte", "lt", "lte":
+		n := f.values[0].number
+		candidates = append(candidates, numberValue(n-1), numberValue(n+1), numberValue(n))
+	case "cidr":
+		candidates = append(candidates, "198.51.100."+strconv.Itoa(g.RandomInt(1, 254)), "10.254.254."+strconv.Itoa(g.RandomInt(1, 254)))
+	default:
+		if s, ok := current.(string); ok && hasSep(s) {
+			// Another file in the same directory
+			name := "benign-" + strings.ToLower(g.RandomString(6))
+			if i := strings.LastIndex(baseName(s), "."); i > 0 {
+				name += baseName(s)[i:]
+			}
+			candidates = append(candidates, s[:len(s)-len(baseName(s))]+name)
+		}
+		candidates = append(candidates, "benign-"+strings.ToLower(g.RandomString(8)), g.RandomInt(100000, 999999), nil)
+	}
+	for _, c := range candidates {
+		if !f.matchValue(c, c != nil) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// fill replaces a value's wildcards with characters they could stand for
+func (g *SigmaGenerator) fill(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '*' || s[i+1] == '?' || s[i+1] == '\\'):
+			b.WriteByte(s[i+1])
+			i++
+		case c == '*':
+			// Left empty at the edges, where a value is usually cut short
+			if i > 0 && i < len(s)-1 {
+				b.WriteString(strings.ToLower(g.RandomString(g.RandomInt(1, 6))))
+			}
+		case c == '?':
+			b.WriteString(strings.ToLower(g.RandomString(1)))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// fromRegexp returns a string the regular expression matches
+func (g *SigmaGenerator) fromRegexp(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return pattern
+	}
+	var b strings.Builder
+	g.writeRegexp(&b, re.Simplify())
+	return b.String()
+}
+
+func (g *SigmaGenerator) writeRegexp(b *strings.Builder, re *syntax.Regexp) {
+	repeat := func(min, max int) {
+		if max < 0 {
+			max = min + 2
+		}
+		for i, n := 0, g.RandomInt(min, max); i < n; i++ {
+			g.writeRegexp(b, re.Sub[0])
+		}
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(g.classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteString(strings.ToLower(g.RandomString(1)))
+	case syntax.OpCapture:
+		g.writeRegexp(b, re.Sub[0])
+	case syntax.OpStar:
+		repeat(0, 2)
+	case syntax.OpPlus:
+		repeat(1, 3)
+	case syntax.OpQuest:
+		repeat(0, 1)
+	case syntax.OpRepeat:
+		repeat(re.Min, re.Max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.writeRegexp(b, sub)
+		}
+	case syntax.OpAlternate:
+		g.writeRegexp(b, re.Sub[g.RandomInt(0, len(re.Sub)-1)])
+	}
+}
+
+// classRune picks a rune of a character class, printable ASCII if it has any
+func (g *SigmaGenerator) classRune(ranges []rune) rune {
+	var printable [][2]rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < 0x21 {
+			lo = 0x21
+		}
+		if hi > 0x7e {
+			hi = 0x7e
+		}
+		if lo <= hi {
+			printable = append(printable, [2]rune{lo, hi})
+		}
+	}
+	if len(printable) == 0 {
+		if len(ranges) == 0 {
+			return 'x'
+		}
+		return ranges[0]
+	}
+	r := printable[g.RandomInt(0, len(printable)-1)]
+	return r[0] + rune(g.RandomInt(0, int(r[1]-r[0])))
+}
+
+// overrides turns the values set, by template field, into overrides. A
+// nested field overrides its top-level object, copied from the sample.
+func (g *SigmaGenerator) overrides(set map[string]interface{}, sample map[string]interface{}) map[string]interface{} {
+	overrides := make(map[string]interface{})
+	for path, v := range set {
+		if _, ok := sample[path]; ok || !strings.Contains(path, ".") {
+			overrides[path] = v
+			continue
+		}
+		parts := strings.Split(path, ".")
+		top, ok := overrides[parts[0]].(map[string]interface{})
+		if !ok {
+			top = copyFields(sample[parts[0]])
+			overrides[parts[0]] = top
+		}
+		m := top
+		for _, p := range parts[1 : len(parts)-1] {
+			next, ok := m[p].(map[string]interface{})
+			if !ok {
+				next = copyFields(m[p])
+			}
+			m[p] = next
+			m = next
+		}
+		m[parts[len(parts)-1]] = v
+	}
+	return overrides
+}
+
+// copyFields deep copies an object field, or starts an empty one
+func copyFields(v interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	switch m := v.(type) {
+	case map[string]interface{}:
+		for k, v := range m {
+			if nested, ok := v.(map[string]interface{}); ok {
+				out[k] = copyFields(nested)
+			} else {
+				out[k] = v
+			}
+		}
+	case map[string]string:
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func numberValue(n float64) interface{} {
+	if n == float64(int64(n)) {
+		return int64(n)
+	}
+	return n
+}
+
+// randomIPIn returns an address of the network, avoiding its network and
+// broadcast addresses where it has others
+func randomIPIn(network *net.IPNet, g *SigmaGenerator) string {
+	ip := make(net.IP, len(network.IP))
+	copy(ip, network.IP)
+	ones, bits := network.Mask.Size()
+	host := bits - ones
+	for i := len(ip) - 1; i >= 0 && host > 0; i-- {
+		n := 8
+		if host < 8 {
+			n = host
+		}
+		max := 1<<n - 1
+		b := g.RandomInt(0, max)
+		if i == len(ip)-1 && max > 2 {
+			b = g.RandomInt(1, max-1)
+		}
+		ip[i] |= byte(b)
+		host -= n
+	}
+	return ip.String()
+}
+
+func hasSep(s string) bool {
+	return strings.ContainsAny(s, `\/`)
+}
+
+func startsWithSep(s string) bool {
+	return strings.HasPrefix(s, `\`) || strings.HasPrefix(s, "/")
+}
+
+func endsWithSep(s string) bool {
+	return strings.HasSuffix(s, `\`) || strings.HasSuffix(s, "/")
+}
+
+// dirName returns a path up to its last separator, without it
+func dirName(s string) string {
+	if i := strings.LastIndexAny(s, `\/`); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// baseName returns a path after its last separator
+func baseName(s string) string {
+	if i := strings.LastIndexAny(s, `\/`); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
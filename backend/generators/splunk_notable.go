@@ -0,0 +1,495 @@
+package generators
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// SplunkNotableGenerator generates Splunk Enterprise Security notable
+// events as correlation searches write them to the notable index, and the
+// incident review updates analysts make to them, so triage workflows and
+// MTTR dashboards can be tested without the correlation searches
+type SplunkNotableGenerator struct {
+	BaseGenerator
+
+	mu       sync.Mutex
+	notables []*notableReview // Recent notables still open, oldest first
+}
+
+func init() {
+	Register(&SplunkNotableGenerator{})
+}
+
+// notableReview is a notable event's place in incident review
+type notableReview struct {
+	EventID  string
+	RuleName string
+	Urgency  string
+	Owner    string
+	Status   int
+	Created  time.Time
+}
+
+// maxOpenNotables bounds the notables kept for review updates; the oldest
+// are forgotten first
+const maxOpenNotables = 500
+
+// Incident review statuses of Splunk ES
+const (
+	notableNew        = 1
+	notableInProgress = 2
+	notablePending    = 3
+	notableResolved   = 4
+	notableClosed     = 5
+)
+
+// notableStatusLabels are the labels of the incident review statuses
+var notableStatusLabels = map[int]string{
+	notableNew:        "New",
+	notableInProgress: "In Progress",
+	notablePending:    "Pending",
+	notableResolved:   "Resolved",
+	notableClosed:     "Closed",
+}
+
+// notableRule is an Enterprise Security correlation search. Title and
+// drilldown use $field$ tokens, replaced with the notable's fields.
+type notableRule struct {
+	template    string
+	searchName  string
+	domain      string
+	severity    string
+	title       string
+	description string
+	drilldown   string
+	techniques  []string
+	window      time.Duration // Search time range
+}
+
+// name is the search's name without its security domain and suffix, as
+// incident review shows it
+func (r notableRule) name() string {
+	_, name, _ := strings.Cut(r.searchName, " - ")
+	return strings.TrimSuffix(name, " - Rule")
+}
+
+// notableRules are the correlation searches by template ID
+var notableRules = map[string]notableRule{
+	"brute_force": {
+		searchName:  "Access - Brute Force Access Behavior Detected - Rule",
+		domain:      "access",
+		severity:    "medium",
+		title:       "Brute Force Access Behavior Detected From $src$",
+		description: "Detects excessive number of failed login attempts along with a successful attempt (this could indicate a successful brute force attack)",
+		drilldown:   `| from datamodel:"Authentication"."Authentication" | search src="$src$"`,
+		techniques:  []string{"T1110"},
+		window:      time.Hour,
+	},
+	"excessive_failed_logins": {
+		searchName:  "Access - Excessive Failed Logins - Rule",
+		domain:      "access",
+		severity:    "medium",
+		title:       "Excessive Failed Logins Detected From $src$",
+		description: "Detects excessive number of failed login attempts (this is likely a brute force attack)",
+		drilldown:   `| from datamodel:"Authentication"."Failed_Authentication" | search src="$src$"`,
+		techniques:  []string{"T1110.001"},
+		window:      time.Hour,
+	},
+	"malware": {
+		searchName:  "Endpoint - High Or Critical Priority Host With Malware - Rule",
+		domain:      "endpoint",
+		severity:    "high",
+		title:       "High Or Critical Priority Host ($dest$) With Malware Detected",
+		description: "Alerts when an infection is noted on a host with high or critical priority.",
+		drilldown:   `| from datamodel:"Malware"."Malware_Attacks" | search dest="$dest$" signature="$signature$"`,
+		techniques:  []string{"T1204.002"},
+		window:      time.Hour,
+	},
+	"threat_activity": {
+		searchName:  "Threat - Threat Activity Detected - Rule",
+		domain:      "threat",
+		severity:    "medium",
+		title:       "Threat Activity Detected ($threat_match_value$)",
+		description: "Alerts when any activity matching threat intelligence is detected.",
+		drilldown:   `| from datamodel:"Threat_Intelligence"."Threat_Activity" | search threat_match_field="$threat_match_field$" threat_match_value="$threat_match_value$"`,
+		techniques:  []string{"T1071"},
+		window:      time.Hour,
+	},
+	"risk_threshold": {
+		searchName:  "Risk - 24 Hour Risk Threshold Exceeded - Rule",
+		domain:      "threat",
+		severity:    "high",
+		title:       "24 hour risk threshold exceeded for $risk_object_type$=$risk_object$",
+		description: "Alerts when a risk object's total risk score over the last 24 hours exceeds 100.",
+		drilldown:   `| from datamodel:"Risk"."All_Risk" | search risk_object="$risk_object$" risk_object_type="$risk_object_type$"`,
+		window:      24 * time.Hour,
+	},
+	"vuln_scanner": {
+		searchName:  "Network - Vulnerability Scanner Detection (by targets) - Rule",
+		domain:      "network",
+		severity:    "low",
+		title:       "Vulnerability Scanner Detected (by targets) From $src$",
+		description: "Detects a potential vulnerability scanner by detecting devices with a large number of unique targets.",
+		drilldown:   `| from datamodel:"Network_Traffic"."All_Traffic" | search src="$src$"`,
+		techniques:  []string{"T1595.002"},
+		window:      time.Hour,
+	},
+}
+
+// notableThreatDomains are intel-listed domains the threat activity search
+// matches when no IOCs are loaded
+var notableThreatDomains = []string{"update-cdn-service.com", "login-microsoftonline.co", "secure-docs-share.net", "api.telemetry-sync.org", "dl.files-transfer.xyz"}
+
+// notableUrgency is Enterprise Security's urgency for a severity and the
+// priority of the asset involved
+var notableUrgency = map[string]map[string]string{
+	"low":      {"unknown": "low", "low": "low", "medium": "low", "high": "medium", "critical": "high"},
+	"medium":   {"unknown": "low", "low": "low", "medium": "medium", "high": "high", "critical": "critical"},
+	"high":     {"unknown": "medium", "low": "medium", "medium": "high", "high": "high", "critical": "critical"},
+	"critical": {"unknown": "medium", "low": "medium", "medium": "high", "high": "critical", "critical": "critical"},
+}
+
+// GetEventType returns the event type for Splunk ES notable events
+func (g *SplunkNotableGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "splunk_notable",
+		Name:        "Splunk ES Notable Events",
+		Category:    "siem",
+		Description: "Splunk Enterprise Security notable events from correlation searches and their incident review updates",
+		EventIDs:    []string{"notable", "incident_review"},
+	}
+}
+
+// GetTemplates returns available templates for Splunk ES notable events
+func (g *SplunkNotableGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "brute_force",
+			Name:        "Brute Force Access Behavior Detected",
+			Category:    "splunk_notable",
+			EventID:     "notable",
+			Format:      "kv",
+			Description: "Access notable for many failed logins followed by a success",
+		},
+		{
+			ID:          "excessive_failed_logins",
+			Name:        "Excessive Failed Logins",
+			Category:    "splunk_notable",
+			EventID:     "notable",
+			Format:      "kv",
+			Description: "Access notable for many failed logins from one source",
+		},
+		{
+			ID:          "malware",
+			Name:        "High Or Critical Priority Host With Malware",
+			Category:    "splunk_notable",
+			EventID:     "notable",
+			Format:      "kv",
+			Description: "Endpoint notable for a malware infection on an important host",
+		},
+		{
+			ID:          "threat_activity",
+			Name:        "Threat Activity Detected",
+			Category:    "splunk_notable",
+			EventID:     "notable",
+			Format:      "kv",
+			Description: "Threat notable for traffic matching a threat intelligence indicator",
+		},
+		{
+			ID:          "risk_threshold",
+			Name:        "24 Hour Risk Threshold Exceeded",
+			Category:    "splunk_notable",
+			EventID:     "notable",
+			Format:      "kv",
+			Description: "Risk-based alerting notable for a user or system over its risk threshold",
+		},
+		{
+			ID:          "vuln_scanner",
+			Name:        "Vulnerability Scanner Detection",
+			Category:    "splunk_notable",
+			EventID:     "notable",
+			Format:      "kv",
+			Description: "Network notable for a host connecting to many targets",
+		},
+		{
+			ID:          "review_update",
+			Name:        "Incident Review Update",
+			Category:    "splunk_notable",
+			EventID:     "incident_review",
+			Format:      "kv",
+			Description: "Analyst moving a notable to its next status in incident review",
+		},
+	}
+}
+
+// Generate creates a Splunk ES notable event or incident review update
+func (g *SplunkNotableGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	if templateID == "review_update" {
+		return g.generateReview(overrides)
+	}
+	rule, ok := notableRules[templateID]
+	if !ok {
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+	rule.template = templateID
+	return g.generateNotable(rule, overrides)
+}
+
+// generateNotable creates a notable event of a correlation search, with
+// the fields the search's result carries
+func (g *SplunkNotableGenerator) generateNotable(rule notableRule, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	user := Entities.RandomUser(g.rnd)
+	host := Entities.HostForUser(g.rnd, user.Username)
+	priority := g.RandomChoice([]string{"low", "medium", "medium", "high", "critical"})
+
+	fields := map[string]interface{}{}
+	switch rule.template {
+	case "brute_force":
+		failures := g.RandomInt(20, 400)
+		fields["src"] = Threats.RandomIP(g.rnd).IP
+		fields["dest"] = host.Hostname
+		fields["user"] = user.Username
+		fields["app"] = g.RandomChoice([]string{"win:remote", "sshd", "okta", "vpn"})
+		fields["failure"] = strconv.Itoa(failures)
+		fields["success"] = strconv.Itoa(g.RandomInt(1, 3))
+		fields["count"] = strconv.Itoa(failures)
+	case "excessive_failed_logins":
+		fields["src"] = Threats.RandomIP(g.rnd).IP
+		fields["dest_count"] = strconv.Itoa(g.RandomInt(1, 25))
+		fields["user_count"] = strconv.Itoa(g.RandomInt(5, 80))
+		fields["app"] = g.RandomChoice([]string{"win:remote", "sshd", "okta", "vpn"})
+		fields["count"] = strconv.Itoa(g.RandomInt(6, 500))
+	case "malware":
+		file := g.RandomMaliciousFile()
+		fields["dest"] = host.Hostname
+		fields["dest_priority"] = g.RandomChoice([]string{"high", "critical"})
+		priority = fields["dest_priority"].(string)
+		fields["user"] = user.Username
+		fields["signature"] = file.Family
+		fields["file_name"] = file.Name
+		fields["file_path"] = file.Path
+		fields["file_hash"] = g.FileHash(file, models.IOCTypeSHA256)
+		fields["action"] = g.RandomChoice([]string{"allowed", "blocked", "deferred"})
+		fields["count"] = strconv.Itoa(g.RandomInt(1, 5))
+	case "threat_activity":
+		if g.RandomInt(0, 1) == 0 {
+			fields["threat_match_field"] = "dest"
+			fields["threat_match_value"] = g.InjectIOC(models.IOCTypeIP, Threats.RandomIP(g.rnd).IP)
+			fields["dest"] = fields["threat_match_value"]
+			fields["threat_collection"] = "ip_intel"
+		} else {
+			fields["threat_match_field"] = "query"
+			fields["threat_match_value"] = g.InjectIOC(models.IOCTypeDomain, g.RandomChoice(notableThreatDomains))
+			fields["query"] = fields["threat_match_value"]
+			fields["threat_collection"] = "domain_intel"
+		}
+		fields["src"] = host.IP
+		fields["src_user"] = user.Username
+		fields["threat_key"] = g.RandomChoice([]string{"emerging_threats_compromised_ip_blocklist", "alienvault_otx", "abuse_ch_urlhaus", "local_threat_intel"})
+		fields["threat_description"] = g.RandomChoice([]string{"Known C2 server", "Malware distribution", "Phishing infrastructure", "Botnet node"})
+		fields["count"] = strconv.Itoa(g.RandomInt(1, 40))
+	case "risk_threshold":
+		if g.RandomInt(0, 2) == 0 {
+			fields["risk_object"] = host.Hostname
+			fields["risk_object_type"] = "system"
+		} else {
+			fields["risk_object"] = user.Username
+			fields["risk_object_type"] = "user"
+		}
+		events := g.RandomInt(3, 30)
+		fields["risk_score"] = strconv.Itoa(g.RandomInt(101, 400))
+		fields["risk_event_count"] = strconv.Itoa(events)
+		fields["source_count"] = strconv.Itoa(g.RandomInt(2, 8))
+		fields["count"] = strconv.Itoa(events)
+	case "vuln_scanner":
+		fields["src"] = g.RandomChoice([]string{host.IP, g.RandomIPv4External()})
+		fields["dest_count"] = strconv.Itoa(g.RandomInt(100, 2500))
+		fields["count"] = fields["dest_count"]
+	}
+
+	fields["search_name"] = rule.searchName
+	fields["rule_name"] = rule.name()
+	fields["rule_title"] = notableTokens(rule.title, fields)
+	fields["rule_description"] = rule.description
+	fields["security_domain"] = rule.domain
+	fields["severity"] = rule.severity
+	fields["urgency"] = notableUrgency[rule.severity][priority]
+	fields["drilldown_name"] = "View the contributing events"
+	fields["drilldown_search"] = notableTokens(rule.drilldown, fields)
+	fields["drilldown_earliest_offset"] = "$info_min_time$"
+	fields["drilldown_latest_offset"] = "$info_max_time$"
+	fields["owner"] = "unassigned"
+	fields["status"] = strconv.Itoa(notableNew)
+	fields["status_label"] = notableStatusLabels[notableNew]
+	if len(rule.techniques) > 0 {
+		fields["annotations.mitre_attack"] = rule.techniques
+	}
+
+	searchTime := timestamp.Unix()
+	fields["info_search_time"] = strconv.FormatInt(searchTime, 10)
+	fields["info_max_time"] = strconv.FormatInt(searchTime, 10)
+	fields["info_min_time"] = strconv.FormatInt(timestamp.Add(-rule.window).Unix(), 10)
+	fields["orig_time"] = strconv.FormatInt(timestamp.Add(-time.Duration(g.RandomInt(30, 600))*time.Second).Unix(), 10)
+	fields["orig_sid"] = fmt.Sprintf("scheduler__admin__SplunkEnterpriseSecuritySuite__RMD5%s_at_%d_%d", g.RandomHex(8), searchTime, g.RandomInt(100, 9999))
+	fields["orig_rid"] = "0"
+	hash := g.RandomHex(16)
+	fields["event_hash"] = hash
+	fields["event_id"] = strings.ToUpper(uuid.New().String()) + "@@notable@@" + hash
+
+	fields = g.ApplyOverrides(fields, overrides)
+	if id, ok := fields["event_id"].(string); ok {
+		g.open(&notableReview{
+			EventID:  id,
+			RuleName: fmt.Sprint(fields["rule_name"]),
+			Urgency:  fmt.Sprint(fields["urgency"]),
+			Owner:    "unassigned",
+			Status:   notableNew,
+			Created:  timestamp,
+		})
+	}
+	return g.event("notable", "stash", timestamp, fields)
+}
+
+// notableTokens replaces $field$ tokens with the notable's fields
+func notableTokens(s string, fields map[string]interface{}) string {
+	for k, v := range fields {
+		if str, ok := v.(string); ok {
+			s = strings.ReplaceAll(s, "$"+k+"$", str)
+		}
+	}
+	return s
+}
+
+// open records a new notable for later review updates
+func (g *SplunkNotableGenerator) open(n *notableReview) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.notables) >= maxOpenNotables {
+		g.notables = g.notables[1:]
+	}
+	g.notables = append(g.notables, n)
+}
+
+// advance moves a random open notable to its next status, forgetting it
+// once closed. Without open notables one raised a while ago is made up.
+func (g *SplunkNotableGenerator) advance(now time.Time) notableReview {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.notables) == 0 {
+		templates := make([]string, 0, len(notableRules))
+		for id := range notableRules {
+			templates = append(templates, id)
+		}
+		sort.Strings(templates)
+		g.notables = append(g.notables, &notableReview{
+			EventID:  strings.ToUpper(uuid.New().String()) + "@@notable@@" + g.RandomHex(16),
+			RuleName: notableRules[g.RandomChoice(templates)].name(),
+			Urgency:  g.RandomChoice([]string{"low", "medium", "high", "critical"}),
+			Owner:    "unassigned",
+			Status:   notableNew,
+			Created:  now.Add(-time.Duration(g.RandomInt(10, 240)) * time.Minute),
+		})
+	}
+
+	i := g.RandomInt(0, len(g.notables)-1)
+	n := g.notables[i]
+	switch n.Status {
+	case notableNew:
+		n.Status = notableInProgress
+		n.Owner = Entities.RandomUser(g.rnd).Username
+	case notableInProgress:
+		n.Status = notableResolved
+		if g.RandomInt(0, 2) == 0 {
+			n.Status = notablePending
+		}
+	case notablePending:
+		n.Status = notableResolved
+	default:
+		n.Status = notableClosed
+	}
+	if n.Status == notableClosed {
+		g.notables = append(g.notables[:i], g.notables[i+1:]...)
+	}
+	return *n
+}
+
+// notableComments are what analysts write when they change a notable's
+// status, by the status they set
+var notableComments = map[int][]string{
+	notableInProgress: {"Taking a look", "Investigating", "Assigned to myself for triage", "Reviewing contributing events"},
+	notablePending:    {"Waiting on the asset owner", "Asked the user to confirm the activity", "Pending endpoint team response"},
+	notableResolved:   {"Confirmed benign, expected admin activity", "Host reimaged", "Credentials reset and sessions revoked", "Blocked the indicator at the proxy"},
+	notableClosed:     {"Closing after review", "No further action", "Duplicate of an earlier notable", "False positive, tuning request filed"},
+}
+
+// generateReview creates the incident review record of an analyst
+// updating a notable's status, owner and comment
+func (g *SplunkNotableGenerator) generateReview(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	timestamp := time.Now()
+	n := g.advance(timestamp)
+
+	fields := map[string]interface{}{
+		"rule_id":        n.EventID,
+		"rule_name":      n.RuleName,
+		"owner":          n.Owner,
+		"reviewer":       n.Owner,
+		"status":         strconv.Itoa(n.Status),
+		"status_label":   notableStatusLabels[n.Status],
+		"urgency":        n.Urgency,
+		"comment":        g.RandomChoice(notableComments[n.Status]),
+		"time":           strconv.FormatInt(timestamp.Unix(), 10),
+		"notable_time":   strconv.FormatInt(n.Created.Unix(), 10),
+		"time_to_update": strconv.FormatInt(int64(timestamp.Sub(n.Created).Seconds()), 10),
+	}
+	fields = g.ApplyOverrides(fields, overrides)
+	return g.event("incident_review", "incident_review", timestamp, fields)
+}
+
+// event renders fields as a stash line, the event time then key="value"
+// pairs with search_name or rule_id first and multivalue fields repeated
+func (g *SplunkNotableGenerator) event(eventID, sourcetype string, timestamp time.Time, fields map[string]interface{}) (*models.GeneratedEvent, error) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k != "search_name" && k != "rule_id" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, first := range []string{"rule_id", "search_name"} {
+		if _, ok := fields[first]; ok {
+			keys = append([]string{first}, keys...)
+		}
+	}
+
+	var raw strings.Builder
+	raw.WriteString(strconv.FormatInt(timestamp.Unix(), 10))
+	for _, k := range keys {
+		values, ok := fields[k].([]string)
+		if !ok {
+			values = []string{fmt.Sprint(fields[k])}
+		}
+		for _, v := range values {
+			fmt.Fprintf(&raw, ", %s=\"%s\"", k, strings.ReplaceAll(v, `"`, `\"`))
+		}
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "splunk_notable",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   raw.String(),
+		Fields:     fields,
+		Sourcetype: sourcetype,
+	}, nil
+}
@@ -0,0 +1,339 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// StorageAuditGenerator generates NetApp ONTAP and Dell EMC PowerScale
+// (Isilon) audit events about snapshots: deletions, disabled protection and
+// reverts, and ONTAP's ransomware alerts. Administrators are the pool's IT
+// staff, connecting from their workstations.
+type StorageAuditGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&StorageAuditGenerator{})
+}
+
+// storageShare is a volume of the ONTAP cluster and the PowerScale path
+// that holds the same data
+type storageShare struct {
+	svm    string
+	volume string
+	path   string
+}
+
+var storageShares = []storageShare{
+	{"svm_files", "vol_finance", "/ifs/data/finance"},
+	{"svm_files", "vol_hr", "/ifs/data/hr"},
+	{"svm_files", "vol_projects", "/ifs/data/projects"},
+	{"svm_files", "vol_home", "/ifs/home"},
+	{"svm_files", "vol_engineering", "/ifs/data/engineering"},
+	{"svm_vmware", "vol_vmware_ds01", "/ifs/data/vmware"},
+}
+
+// GetEventType returns the event type for storage audit events
+func (g *StorageAuditGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "storage_audit",
+		Name:        "Storage Appliance Audit",
+		Category:    "infrastructure",
+		Description: "NetApp ONTAP and Dell EMC PowerScale audit events for snapshot deletions, disabled snapshot protection, snapshot reverts and ransomware alerts",
+		EventIDs: []string{"volume snapshot delete", "volume modify", "volume snapshot restore", "callhome.arw.activity.seen",
+			"snapshot delete", "SnapRevert"},
+	}
+}
+
+// GetTemplates returns available templates for storage audit events
+func (g *StorageAuditGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "netapp_snapshot_delete",
+			Name:        "ONTAP Snapshot Deleted",
+			Category:    "storage_audit",
+			EventID:     "volume snapshot delete",
+			Format:      "syslog",
+			Description: "An administrator deletes a volume's snapshot, or all of them at once",
+			Sourcetype:  "netapp:ontap:audit",
+		},
+		{
+			ID:          "netapp_protection_disabled",
+			Name:        "ONTAP Snapshot Protection Disabled",
+			Category:    "storage_audit",
+			EventID:     "volume modify",
+			Format:      "syslog",
+			Description: "An administrator removes a volume's snapshot policy, turns on aggressive autodelete or disables anti-ransomware protection",
+			Sourcetype:  "netapp:ontap:audit",
+		},
+		{
+			ID:          "netapp_snapshot_restore",
+			Name:        "ONTAP Snapshot Restore",
+			Category:    "storage_audit",
+			EventID:     "volume snapshot restore",
+			Format:      "syslog",
+			Description: "An administrator reverts a whole volume to a snapshot with SnapRestore",
+			Sourcetype:  "netapp:ontap:audit",
+		},
+		{
+			ID:          "netapp_ransomware_alert",
+			Name:        "ONTAP Ransomware Activity",
+			Category:    "storage_audit",
+			EventID:     "callhome.arw.activity.seen",
+			Format:      "syslog",
+			Description: "Autonomous Ransomware Protection sees high-entropy writes and new file extensions on a volume",
+			Sourcetype:  "netapp:ontap:ems",
+		},
+		{
+			ID:          "isilon_snapshot_delete",
+			Name:        "PowerScale Snapshot Deleted",
+			Category:    "storage_audit",
+			EventID:     "snapshot delete",
+			Format:      "syslog",
+			Description: "A platform API request deletes a snapshot or a snapshot schedule",
+			Sourcetype:  "emc:isilon:syslog",
+		},
+		{
+			ID:          "isilon_snaprevert",
+			Name:        "PowerScale SnapRevert Job",
+			Category:    "storage_audit",
+			EventID:     "SnapRevert",
+			Format:      "syslog",
+			Description: "A platform API request starts a SnapRevert job, which restores a directory to a snapshot",
+			Sourcetype:  "emc:isilon:syslog",
+		},
+	}
+}
+
+// Generate creates a storage audit event
+func (g *StorageAuditGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "netapp_snapshot_delete":
+		return g.generateOntapSnapshotDelete(overrides)
+	case "netapp_protection_disabled":
+		return g.generateOntapProtectionDisabled(overrides)
+	case "netapp_snapshot_restore":
+		return g.generateOntapSnapshotRestore(overrides)
+	case "netapp_ransomware_alert":
+		return g.generateOntapRansomware(overrides)
+	case "isilon_snapshot_delete":
+		return g.generateIsilonSnapshotDelete(overrides)
+	case "isilon_snaprevert":
+		return g.generateIsilonSnapRevert(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// ontapSnapshot names a snapshot of the default policy taken before now
+func (g *StorageAuditGenerator) ontapSnapshot(now time.Time) string {
+	switch g.RandomInt(0, 2) {
+	case 0:
+		return "hourly." + now.Add(-time.Duration(g.RandomInt(1, 6))*time.Hour).Format("2006-01-02_1504")
+	case 1:
+		return "daily." + now.AddDate(0, 0, -g.RandomInt(1, 2)).Format("2006-01-02") + "_0010"
+	default:
+		return "weekly." + now.AddDate(0, 0, -7*g.RandomInt(1, 2)).Format("2006-01-02") + "_0015"
+	}
+}
+
+// ontapCommand builds the fields of a command an administrator ran on the
+// cluster over SSH or through System Manager
+func (g *StorageAuditGenerator) ontapCommand(command string) map[string]interface{} {
+	admin := Entities.RandomUserIn(g.rnd, "IT")
+	application := g.WeightedChoice([]string{"ssh", "http"}, []float64{60, 40})
+	return map[string]interface{}{
+		"cluster":     themedName("nas-01"),
+		"node":        themedName("nas-01") + "-0" + fmt.Sprint(g.RandomInt(1, 2)),
+		"application": application,
+		"client_ip":   Entities.HostForUser(g.rnd, admin.Username).IP,
+		"user":        windowsNetBIOSDomain() + `\` + admin.Username,
+		"command":     command,
+		"result":      "Success",
+	}
+}
+
+// ontapAudit renders an ONTAP command history entry as the cluster forwards
+// its audit log to syslog
+func (g *StorageAuditGenerator) ontapAudit(now time.Time, eventID string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+	raw := fmt.Sprintf("<13>%s [%s:kern.audit:info]: %s [kern_audit:info:%d] %s :: %s:%s :: %s:%s :: %s :: %s",
+		now.Format("Jan 02 15:04:05"), fields["node"], now.Format("Mon Jan 02 2006 15:04:05 -07:00"), g.RandomInt(1000, 9999),
+		g.RandomHex(8), fields["cluster"], fields["application"], fields["client_ip"], fields["user"], fields["command"], fields["result"])
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "storage_audit",
+		EventID:    eventID,
+		Timestamp:  now,
+		RawEvent:   raw,
+		Fields:     fields,
+		Sourcetype: "netapp:ontap:audit",
+	}, nil
+}
+
+// generateOntapSnapshotDelete creates a snapshot deletion. One in four
+// deletes every snapshot of the volume, as ransomware operators do.
+func (g *StorageAuditGenerator) generateOntapSnapshotDelete(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	share := storageShares[g.RandomInt(0, len(storageShares)-1)]
+	snapshot := g.ontapSnapshot(now)
+	if g.RandomInt(1, 4) == 1 {
+		snapshot = "* -force true"
+	}
+	fields := g.ontapCommand(fmt.Sprintf("volume snapshot delete -vserver %s -volume %s -snapshot %s", share.svm, share.volume, snapshot))
+	fields["vserver"] = share.svm
+	fields["volume"] = share.volume
+	return g.ontapAudit(now, "volume snapshot delete", fields, overrides)
+}
+
+// generateOntapProtectionDisabled creates a change that stops a volume's
+// snapshots from being taken or kept
+func (g *StorageAuditGenerator) generateOntapProtectionDisabled(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	share := storageShares[g.RandomInt(0, len(storageShares)-1)]
+	target := fmt.Sprintf("-vserver %s -volume %s", share.svm, share.volume)
+	command := g.RandomChoice([]string{
+		"volume modify " + target + " -snapshot-policy none",
+		"volume snapshot autodelete modify " + target + " -enabled true -trigger volume -target-free-space 99",
+		"security anti-ransomware volume disable " + target,
+		"volume modify " + target + " -percent-snapshot-space 0",
+	})
+	fields := g.ontapCommand(command)
+	fields["vserver"] = share.svm
+	fields["volume"] = share.volume
+	return g.ontapAudit(now, "volume modify", fields, overrides)
+}
+
+// generateOntapSnapshotRestore creates a SnapRestore of a whole volume,
+// which replaces everything written since the snapshot
+func (g *StorageAuditGenerator) generateOntapSnapshotRestore(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	share := storageShares[g.RandomInt(0, len(storageShares)-1)]
+	fields := g.ontapCommand(fmt.Sprintf("volume snapshot restore -vserver %s -volume %s -snapshot %s -force true",
+		share.svm, share.volume, g.ontapSnapshot(now)))
+	fields["vserver"] = share.svm
+	fields["volume"] = share.volume
+	return g.ontapAudit(now, "volume snapshot restore", fields, overrides)
+}
+
+// generateOntapRansomware creates the EMS event ONTAP raises when Autonomous
+// Ransomware Protection suspects an attack on a volume. It takes a
+// snapshot of the volume when it does.
+func (g *StorageAuditGenerator) generateOntapRansomware(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	share := storageShares[g.RandomInt(0, len(storageShares)-1)]
+	node := themedName("nas-01") + "-0" + fmt.Sprint(g.RandomInt(1, 2))
+
+	fields := map[string]interface{}{
+		"node":          node,
+		"event":         "callhome.arw.activity.seen",
+		"severity":      "alert",
+		"vserver":       share.svm,
+		"volume":        share.volume,
+		"suspect_files": g.RandomInt(300, 40000),
+		"extension":     g.RandomChoice([]string{".lockbit", ".akira", ".blackcat", ".encrypted", ".royal"}),
+		"entropy":       fmt.Sprintf("%.2f", g.RandomFloat(7.6, 7.99)),
+		"snapshot":      "Anti_ransomware_backup." + now.Format("2006-01-02_1504"),
+	}
+	fields = g.ApplyOverrides(fields, overrides)
+	raw := fmt.Sprintf("<9>%s [%s:%s:%s]: Call home for RANSOMWARE ACTIVITY SEEN: volume %s in SVM %s, %v suspect files with extension \"%s\", average entropy %s. Snapshot copy %s was created.",
+		now.Format("Jan 02 15:04:05"), fields["node"], fields["event"], fields["severity"], fields["volume"], fields["vserver"],
+		fields["suspect_files"], fields["extension"], fields["entropy"], fields["snapshot"])
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "storage_audit",
+		EventID:    "callhome.arw.activity.seen",
+		Timestamp:  now,
+		RawEvent:   raw,
+		Fields:     fields,
+		Sourcetype: "netapp:ontap:ems",
+	}, nil
+}
+
+// isilonRequest builds a platform API request as the PowerScale config
+// audit log records it
+func (g *StorageAuditGenerator) isilonRequest(now time.Time, method, uri string, body map[string]interface{}) map[string]interface{} {
+	admin := Entities.RandomUserIn(g.rnd, "IT")
+	return map[string]interface{}{
+		"id":        uuid.New().String(),
+		"timestamp": now.UnixMicro(),
+		"payload": map[string]interface{}{
+			"user": map[string]interface{}{
+				"token": map[string]interface{}{
+					"UID":      2000 + admin.UID,
+					"UserName": windowsNetBIOSDomain() + `\` + admin.Username,
+					"SID":      windowsUserSID(admin),
+				},
+			},
+			"client": Entities.HostForUser(g.rnd, admin.Username).IP,
+			"uri":    uri,
+			"method": method,
+			"args":   map[string]interface{}{},
+			"body":   body,
+		},
+	}
+}
+
+// isilonAudit renders a config audit entry as the cluster sends it to syslog
+func (g *StorageAuditGenerator) isilonAudit(now time.Time, eventID string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+	payload, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+	var line bytes.Buffer
+	if err := json.Compact(&line, payload); err != nil {
+		return nil, err
+	}
+	raw := fmt.Sprintf("<14>%s %s-%d audit_config_protocol[%d]: %s", now.Format("Jan 02 15:04:05"), themedName("isilon-01"),
+		g.RandomInt(1, 4), g.RandomInt(1000, 65000), line.String())
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "storage_audit",
+		EventID:    eventID,
+		Timestamp:  now,
+		RawEvent:   raw,
+		Fields:     fields,
+		Sourcetype: "emc:isilon:syslog",
+	}, nil
+}
+
+// generateIsilonSnapshotDelete creates the deletion of a SnapshotIQ
+// snapshot or, one time in four, of the schedule that takes them
+func (g *StorageAuditGenerator) generateIsilonSnapshotDelete(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	share := storageShares[g.RandomInt(0, len(storageShares)-1)]
+	name := strings.TrimPrefix(strings.ReplaceAll(share.path, "/", "_"), "_")
+	uri := fmt.Sprintf("/platform/1/snapshot/snapshots/%s_daily_%s", name, now.AddDate(0, 0, -g.RandomInt(1, 14)).Format("2006-01-02"))
+	if g.RandomInt(1, 4) == 1 {
+		uri = "/platform/3/snapshot/schedules/" + name + "_daily"
+	}
+	fields := g.isilonRequest(now, "DELETE", uri, map[string]interface{}{})
+	return g.isilonAudit(now, "snapshot delete", fields, overrides)
+}
+
+// generateIsilonSnapRevert creates the start of a SnapRevert job, which
+// puts a directory back to how it was when a snapshot was taken
+func (g *StorageAuditGenerator) generateIsilonSnapRevert(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	fields := g.isilonRequest(now, "POST", "/platform/1/job/jobs", map[string]interface{}{
+		"type":     "SnapRevert",
+		"policy":   "MEDIUM",
+		"priority": 5,
+		"snaprevert_params": map[string]interface{}{
+			"snapid": g.RandomInt(200, 9000),
+		},
+	})
+	return g.isilonAudit(now, "SnapRevert", fields, overrides)
+}
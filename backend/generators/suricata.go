@@ -1,8 +1,8 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -163,7 +163,7 @@ func (g *SuricataGenerator) generateAlert(overrides map[string]interface{}) (*mo
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -223,7 +223,7 @@ func (g *SuricataGenerator) generateFlow(overrides map[string]interface{}) (*mod
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -252,7 +252,7 @@ func (g *SuricataGenerator) generateDNS(overrides map[string]interface{}) (*mode
 	rrTypes := []string{"A", "AAAA", "CNAME", "MX", "TXT", "PTR", "NS", "SOA"}
 	rcodes := []string{"NOERROR", "NXDOMAIN", "SERVFAIL", "REFUSED"}
 
-	queryDomain := g.RandomChoice(domains)
+	queryDomain := g.InjectIOC(models.IOCTypeDomain, g.RandomChoice(domains))
 	rrType := g.RandomChoice(rrTypes)
 
 	fields := map[string]interface{}{
@@ -283,7 +283,7 @@ func (g *SuricataGenerator) generateDNS(overrides map[string]interface{}) (*mode
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -304,17 +304,12 @@ func (g *SuricataGenerator) generateHTTP(overrides map[string]interface{}) (*mod
 	now := time.Now().UTC()
 
 	methods := []string{"GET", "POST", "PUT", "DELETE", "HEAD", "OPTIONS"}
-	userAgents := []string{
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36",
-		"curl/7.68.0",
-		"python-requests/2.25.1",
-		"Wget/1.21",
-	}
 	contentTypes := []string{"text/html", "application/json", "text/plain", "application/xml"}
 	statusCodes := []int{200, 201, 301, 302, 400, 401, 403, 404, 500}
 
-	hostname := fmt.Sprintf("www.%s.com", g.RandomString(8))
+	hostname := g.InjectIOC(models.IOCTypeDomain, fmt.Sprintf("www.%s.com", g.RandomString(8)))
+	url := g.RandomURL(g.WeightedChoice([]string{webRequestPage, webRequestAsset, webRequestAPI}, []float64{35, 40, 25}))
+	url, userAgent, _ := g.webAttackRequest(url, g.randomClientUserAgent())
 
 	fields := map[string]interface{}{
 		"timestamp":  now.Format("2006-01-02T15:04:05.000000-0700"),
@@ -329,8 +324,8 @@ func (g *SuricataGenerator) generateHTTP(overrides map[string]interface{}) (*mod
 		"tx_id":      g.RandomInt(0, 10),
 		"http": map[string]interface{}{
 			"hostname":             hostname,
-			"url":                  fmt.Sprintf("/%s/%s", g.RandomString(8), g.RandomString(12)),
-			"http_user_agent":      g.RandomChoice(userAgents),
+			"url":                  url,
+			"http_user_agent":      userAgent,
 			"http_content_type":    g.RandomChoice(contentTypes),
 			"http_method":          g.RandomChoice(methods),
 			"protocol":             "HTTP/1.1",
@@ -344,7 +339,7 @@ func (g *SuricataGenerator) generateHTTP(overrides map[string]interface{}) (*mod
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -364,15 +359,9 @@ func (g *SuricataGenerator) generateHTTP(overrides map[string]interface{}) (*mod
 func (g *SuricataGenerator) generateTLS(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
 
-	versions := []string{"TLS 1.2", "TLS 1.3", "TLSv1.2", "TLSv1.3"}
-	organizations := []string{
-		"DigiCert Inc", "Let's Encrypt", "Comodo CA Limited",
-		"GlobalSign", "Amazon", "Google Trust Services LLC",
-	}
-
-	sni := fmt.Sprintf("www.%s.com", g.RandomString(8))
-	notBefore := now.Add(-time.Duration(g.RandomInt(30, 365)) * 24 * time.Hour)
-	notAfter := now.Add(time.Duration(g.RandomInt(30, 365)) * 24 * time.Hour)
+	session := g.randomTLSSession()
+	sni := g.InjectIOC(models.IOCTypeDomain, fmt.Sprintf("www.%s.com", g.RandomString(8)))
+	cert := g.serverCertificate(sni, now)
 
 	fields := map[string]interface{}{
 		"timestamp":  now.Format("2006-01-02T15:04:05.000000-0700"),
@@ -385,29 +374,30 @@ func (g *SuricataGenerator) generateTLS(overrides map[string]interface{}) (*mode
 		"dest_port":  443,
 		"proto":      "TCP",
 		"tls": map[string]interface{}{
-			"subject":     fmt.Sprintf("CN=%s", sni),
-			"issuerdn":    fmt.Sprintf("CN=%s, O=%s", g.RandomChoice([]string{"R3", "E1", "DigiCert SHA2"}), g.RandomChoice(organizations)),
-			"serial":      fmt.Sprintf("%02X:%02X:%02X:%02X:%02X:%02X:%02X:%02X", g.RandomInt(0, 255), g.RandomInt(0, 255), g.RandomInt(0, 255), g.RandomInt(0, 255), g.RandomInt(0, 255), g.RandomInt(0, 255), g.RandomInt(0, 255), g.RandomInt(0, 255)),
-			"fingerprint": g.RandomString(40),
+			"subject":     cert.Subject.suricataDN(),
+			"issuerdn":    cert.Issuer.suricataDN(),
+			"serial":      colonHex(cert.Serial),
+			"fingerprint": colonHex(cert.SHA1),
 			"sni":         sni,
-			"version":     g.RandomChoice(versions),
-			"notbefore":   notBefore.Format("2006-01-02T15:04:05"),
-			"notafter":    notAfter.Format("2006-01-02T15:04:05"),
+			"version":     "TLS " + session.version,
+			"notbefore":   cert.NotBefore.Format("2006-01-02T15:04:05"),
+			"notafter":    cert.NotAfter.Format("2006-01-02T15:04:05"),
 			"ja3": map[string]interface{}{
-				"hash":   g.RandomString(32),
-				"string": "771,4865-4866-4867-49195,0-23-65281-10-11-35-16-5-13-18-51-45-43-27-21,29-23-24,0",
+				"hash":   session.ja3Hash,
+				"string": session.ja3,
 			},
 			"ja3s": map[string]interface{}{
-				"hash":   g.RandomString(32),
-				"string": "771,4865,43-51",
+				"hash":   session.ja3sHash,
+				"string": session.ja3s,
 			},
+			"ja4": session.ja4,
 		},
 		"host": g.RandomHostname(),
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -427,20 +417,9 @@ func (g *SuricataGenerator) generateTLS(overrides map[string]interface{}) (*mode
 func (g *SuricataGenerator) generateFileInfo(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
 
-	filenames := []string{
-		"document.pdf", "report.docx", "image.png", "script.js",
-		"archive.zip", "setup.exe", "data.json", "config.xml",
-	}
-
-	magics := []string{
-		"PDF document",
-		"Microsoft Word 2007+",
-		"PNG image data",
-		"JavaScript source",
-		"Zip archive data",
-		"PE32 executable",
-		"JSON data",
-		"XML document",
+	file := g.RandomFile(FileDocument, FileArchive, FileExecutable, FileImage)
+	if g.rnd.float64() < 0.05 {
+		file = g.RandomMaliciousFile(FileDocument, FileArchive, FileExecutable)
 	}
 
 	fields := map[string]interface{}{
@@ -455,16 +434,16 @@ func (g *SuricataGenerator) generateFileInfo(overrides map[string]interface{}) (
 		"proto":      "TCP",
 		"app_proto":  g.RandomChoice([]string{"http", "smtp", "ftp", "smb"}),
 		"fileinfo": map[string]interface{}{
-			"filename": g.RandomChoice(filenames),
-			"magic":    g.RandomChoice(magics),
+			"filename": file.Name,
+			"magic":    file.Magic,
 			"gaps":     false,
 			"state":    "CLOSED",
-			"md5":      g.RandomString(32),
-			"sha1":     g.RandomString(40),
-			"sha256":   g.RandomString(64),
+			"md5":      g.FileHash(file, models.IOCTypeMD5),
+			"sha1":     g.FileHash(file, models.IOCTypeSHA1),
+			"sha256":   g.FileHash(file, models.IOCTypeSHA256),
 			"stored":   g.RandomInt(0, 1) == 1,
 			"file_id":  g.RandomInt(1, 1000),
-			"size":     g.RandomInt(100, 10000000),
+			"size":     file.Size,
 			"tx_id":    g.RandomInt(0, 10),
 		},
 		"host": g.RandomHostname(),
@@ -472,7 +451,7 @@ func (g *SuricataGenerator) generateFileInfo(overrides map[string]interface{}) (
 
 	fields = g.ApplyOverrides(fields, overrides)
 
-	rawEventBytes, err := json.MarshalIndent(fields, "", "  ")
+	rawEventBytes, err := g.marshalRawJSON(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -487,3 +466,13 @@ func (g *SuricataGenerator) generateFileInfo(overrides map[string]interface{}) (
 		Sourcetype: "suricata",
 	}, nil
 }
+
+// colonHex separates the bytes of a hex string with colons, the way
+// Suricata prints certificate fingerprints
+func colonHex(h string) string {
+	pairs := make([]string, 0, len(h)/2)
+	for i := 0; i+1 < len(h); i += 2 {
+		pairs = append(pairs, h[i:i+2])
+	}
+	return strings.Join(pairs, ":")
+}
@@ -0,0 +1,330 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// SyslogRFC5424Generator generates generic RFC 5424 syslog messages with
+// structured data. It is a catch-all for sources without a generator of
+// their own and a source of the corner cases syslog parsers get wrong. The
+// header fields, structured data and message can be set with overrides;
+// the raw message is rendered from the fields after overrides apply.
+type SyslogRFC5424Generator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&SyslogRFC5424Generator{})
+}
+
+// syslogSource is a daemon or appliance without a generator of its own
+type syslogSource struct {
+	host     string // Host name prefix
+	app      string
+	facility int
+	messages []syslogMessage
+}
+
+// syslogMessage is a message a source logs. The text may use {ip}, {mac},
+// {user}, {n} and {id} placeholders.
+type syslogMessage struct {
+	msgid    string
+	severity int
+	text     string
+}
+
+var syslogSources = []syslogSource{
+	{"lb", "haproxy", 16, []syslogMessage{
+		{"-", 6, "{ip}:{n} [frontend https-in] backend app-servers/app{n} 0/0/1/23/24 200 4821 - - ---- 312/298/4/2/0 0/0"},
+		{"-", 4, "Server app-servers/app{n} is DOWN, reason: Layer4 timeout, check duration: 2001ms. 3 active and 0 backup servers left."},
+		{"-", 5, "Server app-servers/app{n} is UP, reason: Layer7 check passed, code: 200, check duration: 4ms."},
+	}},
+	{"lb", "Keepalived_vrrp", 3, []syslogMessage{
+		{"-", 6, "(VI_1) Entering MASTER STATE"},
+		{"-", 6, "(VI_1) Entering BACKUP STATE (priority 100 < 150)"},
+		{"-", 4, "(VI_1) Received advert from {ip} with lower priority 90, ours 100, forcing new election"},
+	}},
+	{"dhcp", "dhcpd", 3, []syslogMessage{
+		{"DHCPACK", 6, "DHCPACK on {ip} to {mac} via eth0"},
+		{"DHCPREQUEST", 6, "DHCPREQUEST for {ip} from {mac} via eth0"},
+		{"DHCPNAK", 4, "DHCPNAK on {ip} to {mac} via eth0"},
+	}},
+	{"ntp", "chronyd", 12, []syslogMessage{
+		{"-", 6, "Selected source {ip} (time.cloudflare.com)"},
+		{"-", 4, "System clock wrong by {n}.214 seconds"},
+		{"-", 4, "Can't synchronise: no selectable sources"},
+	}},
+	{"mx", "postfix/smtpd", 2, []syslogMessage{
+		{"-", 6, "connect from unknown[{ip}]"},
+		{"-", 6, "{id}: client=unknown[{ip}], sasl_method=LOGIN, sasl_username={user}"},
+		{"-", 4, "warning: unknown[{ip}]: SASL LOGIN authentication failed: authentication failure"},
+	}},
+	{"app", "inventory-sync", 17, []syslogMessage{
+		{"SYNC_START", 6, "Starting inventory sync run {id}"},
+		{"SYNC_DONE", 6, "Inventory sync run {id} finished: {n} records updated"},
+		{"SYNC_FAIL", 3, "Inventory sync run {id} failed: upstream returned HTTP 503"},
+		{"AUTH_FAIL", 4, "Rejected API token for user {user} from {ip}"},
+	}},
+}
+
+// syslogEdgeCases are the corner cases of the edge_case template
+var syslogEdgeCases = []string{
+	"nil_values", "escaped_params", "multiple_sd", "empty_sd_element", "duplicate_params", "bom_message",
+	"utf8_message", "no_message", "max_length_header", "microsecond_offset", "nil_timestamp", "long_message",
+}
+
+// GetEventType returns the event type for generic RFC 5424 syslog
+func (g *SyslogRFC5424Generator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "syslog_rfc5424",
+		Name:        "Generic Syslog (RFC 5424)",
+		Category:    "infrastructure",
+		Description: "RFC 5424 syslog messages with structured data and overridable PRI, APP-NAME and MSGID, for unmodeled sources and syslog parser testing",
+		EventIDs:    []string{"message", "structured_data", "edge_case"},
+	}
+}
+
+// GetTemplates returns available templates for generic RFC 5424 syslog
+func (g *SyslogRFC5424Generator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "message",
+			Name:        "Syslog Message",
+			Category:    "syslog_rfc5424",
+			EventID:     "message",
+			Format:      "syslog",
+			Description: "Message from a daemon or appliance without a generator of its own (HAProxy, Keepalived, dhcpd, chronyd, Postfix, an in-house app)",
+			Sourcetype:  "syslog",
+		},
+		{
+			ID:          "structured_data",
+			Name:        "Structured Data Message",
+			Category:    "syslog_rfc5424",
+			EventID:     "structured_data",
+			Format:      "syslog",
+			Description: "Message with the IANA timeQuality, origin and meta elements and a private enterprise element",
+			Sourcetype:  "syslog",
+		},
+		{
+			ID:          "edge_case",
+			Name:        "Parser Edge Case",
+			Category:    "syslog_rfc5424",
+			EventID:     "edge_case",
+			Format:      "syslog",
+			Description: "Valid but unusual message: NILVALUE fields, escaped or duplicate parameters, a BOM, no MSG, maximum-length header fields and the like",
+			Sourcetype:  "syslog",
+		},
+	}
+}
+
+// Generate creates a generic RFC 5424 syslog event
+func (g *SyslogRFC5424Generator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	var fields map[string]interface{}
+	switch templateID {
+	case "message":
+		fields = g.sourceMessage(now)
+	case "structured_data":
+		fields = g.sourceMessage(now)
+		fields["structured_data"] = g.structuredData(fields)
+	case "edge_case":
+		var err error
+		if fields, err = g.edgeCase(now, overrides); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, err := rfc5424Message(fields, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "syslog_rfc5424",
+		EventID:    templateID,
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "syslog",
+	}, nil
+}
+
+// sourceMessage draws a message from one of the syslog sources, without
+// structured data
+func (g *SyslogRFC5424Generator) sourceMessage(now time.Time) map[string]interface{} {
+	source := syslogSources[g.RandomInt(0, len(syslogSources)-1)]
+	msg := source.messages[g.RandomInt(0, len(source.messages)-1)]
+	host := Entities.RandomHost(g.rnd)
+	text := strings.NewReplacer(
+		"{ip}", host.IP,
+		"{mac}", strings.ToLower(host.MAC),
+		"{user}", Entities.RandomUser(g.rnd).Username,
+		"{n}", fmt.Sprint(g.RandomInt(1, 12)),
+		"{id}", strings.ToUpper(g.RandomHex(5)),
+	).Replace(msg.text)
+
+	return map[string]interface{}{
+		"facility":        source.facility,
+		"severity":        msg.severity,
+		"version":         1,
+		"timestamp":       now.Format("2006-01-02T15:04:05.000Z07:00"),
+		"hostname":        ServerName(fmt.Sprintf("%s-%02d", source.host, g.RandomInt(1, 3))),
+		"app_name":        source.app,
+		"procid":          fmt.Sprint(g.RandomInt(300, 65000)),
+		"msgid":           msg.msgid,
+		"structured_data": "-",
+		"message":         text,
+	}
+}
+
+// structuredData returns the registered timeQuality, origin and meta
+// elements and a private enterprise element for the message in fields
+func (g *SyslogRFC5424Generator) structuredData(fields map[string]interface{}) string {
+	synced := g.WeightedChoice([]string{"1", "0"}, []float64{95, 5})
+	return strings.Join([]string{
+		sdElement("timeQuality", "tzKnown", "1", "isSynced", synced, "syncAccuracy", fmt.Sprint(g.RandomInt(100, 250000))),
+		sdElement("origin", "ip", Entities.RandomServer(g.rnd).IP, "software", fmt.Sprint(fields["app_name"]),
+			"swVersion", fmt.Sprintf("%d.%d.%d", g.RandomInt(1, 4), g.RandomInt(0, 12), g.RandomInt(0, 9))),
+		sdElement("meta", "sequenceId", fmt.Sprint(g.RandomInt(1, 2147483647)), "sysUpTime", fmt.Sprint(g.RandomInt(10000, 900000000)), "language", "en-US"),
+		sdElement("app@32473", "env", g.RandomChoice([]string{"prod", "staging"}), "region", g.RandomChoice([]string{"us-east-1", "eu-west-1"}),
+			"requestId", uuid.New().String()),
+	}, "")
+}
+
+// edgeCase creates a valid message that exercises a corner of RFC 5424.
+// An edge_case override picks the case; otherwise one is drawn.
+func (g *SyslogRFC5424Generator) edgeCase(now time.Time, overrides map[string]interface{}) (map[string]interface{}, error) {
+	fields := g.sourceMessage(now)
+	name, _ := overrides["edge_case"].(string)
+	if name == "" {
+		name = g.RandomChoice(syslogEdgeCases)
+	}
+	fields["edge_case"] = name
+
+	switch name {
+	case "nil_values":
+		// Every optional header field is the NILVALUE
+		fields["hostname"], fields["app_name"], fields["procid"], fields["msgid"] = "-", "-", "-", "-"
+	case "escaped_params":
+		// Quote, backslash and closing bracket must be escaped in values
+		fields["structured_data"] = sdElement("app@32473", "path", `C:\ProgramData\App\config.ini`, "query", `name="O'Brien" AND tag=[x]`)
+	case "multiple_sd":
+		fields["structured_data"] = g.structuredData(fields)
+	case "empty_sd_element":
+		fields["structured_data"] = "[app@32473][timeQuality tzKnown=\"0\"]"
+	case "duplicate_params":
+		// A parameter name may repeat within an element
+		fields["structured_data"] = sdElement("origin", "ip", Entities.RandomServer(g.rnd).IP, "ip", Entities.RandomServer(g.rnd).IP, "ip", g.RandomIPv4External())
+	case "bom_message":
+		// A UTF-8 MSG may start with a byte order mark
+		fields["message"] = "\ufeff" + fmt.Sprint(fields["message"])
+	case "utf8_message":
+		fields["message"] = g.RandomChoice([]string{
+			"Benutzer „müller“ hat sich abgemeldet",
+			"ユーザー tanaka がログインしました",
+			"Отказано в доступе: /var/lib/app/данные.db",
+			"Job terminé ✅ en 12,4 s",
+		})
+	case "no_message":
+		// MSG is optional; the message ends after STRUCTURED-DATA
+		fields["message"] = ""
+		fields["structured_data"] = sdElement("meta", "sequenceId", fmt.Sprint(g.RandomInt(1, 100000)))
+	case "max_length_header":
+		// HOSTNAME 255, APP-NAME 48, PROCID 128 and MSGID 32 characters
+		host := fmt.Sprint(fields["hostname"])
+		for len(host) < 255 {
+			host = "a" + strings.Repeat("b", 61) + "." + host
+		}
+		fields["hostname"] = host[len(host)-255:]
+		fields["app_name"] = strings.Repeat("x", 48)
+		fields["procid"] = strings.Repeat("9", 128)
+		fields["msgid"] = strings.Repeat("M", 32)
+	case "microsecond_offset":
+		// Six fractional digits and a non-UTC offset
+		fields["timestamp"] = now.In(time.FixedZone("", -(7*60+30)*60)).Format("2006-01-02T15:04:05.000000Z07:00")
+	case "nil_timestamp":
+		// The originator had no reliable clock
+		fields["timestamp"] = "-"
+	case "long_message":
+		fields["message"] = fmt.Sprint(fields["message"]) + " payload=" + g.RandomString(7800)
+	default:
+		return nil, fmt.Errorf("unknown edge case: %s", name)
+	}
+	return fields, nil
+}
+
+// sdElement renders a structured data element from an SD-ID and pairs of
+// parameter names and values, escaping the values
+func sdElement(id string, params ...string) string {
+	var b strings.Builder
+	b.WriteString("[" + id)
+	for i := 0; i+1 < len(params); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "]", `\]`).Replace(params[i+1])
+		fmt.Fprintf(&b, ` %s="%s"`, params[i], value)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// syslogCode reads a facility or severity field, which must be an integer
+// below limit. JSON overrides decode as float64.
+func syslogCode(field string, value interface{}, limit int) (int, error) {
+	code := -1
+	switch v := value.(type) {
+	case int:
+		code = v
+	case float64:
+		if v == math.Trunc(v) {
+			code = int(v)
+		}
+	}
+	if code < 0 || code >= limit {
+		return 0, fmt.Errorf("%s must be an integer from 0 to %d: %v", field, limit-1, value)
+	}
+	return code, nil
+}
+
+// rfc5424Message renders the fields as an RFC 5424 message. A pri override
+// is written as given, even when it is not a valid PRI, so parsers can be
+// fed malformed priorities; otherwise the PRI is derived from facility and
+// severity. Empty header fields become the NILVALUE.
+func rfc5424Message(fields, overrides map[string]interface{}) (string, error) {
+	facility, err := syslogCode("facility", fields["facility"], 24)
+	if err != nil {
+		return "", err
+	}
+	severity, err := syslogCode("severity", fields["severity"], 8)
+	if err != nil {
+		return "", err
+	}
+	fields["facility"], fields["severity"] = facility, severity
+	fields["pri"] = facility*8 + severity
+	if pri, ok := overrides["pri"]; ok {
+		fields["pri"] = pri
+	}
+
+	header := make([]string, 0, 6)
+	for _, name := range []string{"timestamp", "hostname", "app_name", "procid", "msgid", "structured_data"} {
+		value := fmt.Sprint(fields[name])
+		if fields[name] == nil || value == "" {
+			value = "-"
+		}
+		header = append(header, value)
+	}
+	line := fmt.Sprintf("<%v>%v %s", fields["pri"], fields["version"], strings.Join(header, " "))
+	if msg := fmt.Sprint(fields["message"]); fields["message"] != nil && msg != "" {
+		line += " " + msg
+	}
+	return line, nil
+}
@@ -0,0 +1,84 @@
+package generators
+
+import (
+	"strings"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// DefaultTheme is the organization generators simulate unless told
+// otherwise
+func DefaultTheme() models.Theme {
+	return models.Theme{
+		Domain:         "example.com",
+		ADDomain:       "corp.example.com",
+		InternalDomain: "prod.internal",
+	}
+}
+
+// theme holds the naming convention generators follow
+var theme = struct {
+	sync.RWMutex
+	models.Theme
+}{Theme: DefaultTheme()}
+
+// Theme returns the naming convention generators follow
+func Theme() models.Theme {
+	theme.RLock()
+	defer theme.RUnlock()
+	return theme.Theme
+}
+
+// SetTheme replaces the naming convention and renames the entity pool's
+// hosts and users to match
+func SetTheme(t models.Theme) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	theme.Lock()
+	theme.Theme = t
+	theme.Unlock()
+	Entities.rebuild(t)
+	return nil
+}
+
+// themedName prefixes name with the theme's prefix, if any
+func themedName(name string) string {
+	if prefix := Theme().Prefix; prefix != "" {
+		return prefix + "-" + name
+	}
+	return name
+}
+
+// ServerName returns a server's fully qualified name in the internal
+// domain, e.g. app-01.prod.internal
+func ServerName(name string) string {
+	return themedName(name) + "." + Theme().InternalDomain
+}
+
+// themedBucket returns the name of an S3 bucket of a kind, such as logs,
+// ending in suffix
+func themedBucket(kind, suffix string) string {
+	if prefix := Theme().Prefix; prefix != "" {
+		return prefix + "-" + kind + "-" + suffix
+	}
+	return kind + "-bucket-" + suffix
+}
+
+// tenantName returns the organization's name as SaaS tenants spell it, such
+// as acme for acme.sharepoint.com
+func tenantName() string {
+	return strings.ReplaceAll(strings.SplitN(Theme().Domain, ".", 2)[0], "-", "")
+}
+
+// domainDN returns the AD domain as a distinguished name, such as
+// DC=corp,DC=example,DC=com
+func domainDN() string {
+	return "DC=" + strings.ReplaceAll(Theme().ADDomain, ".", ",DC=")
+}
+
+// publicHost returns a host in the public domain, such as www.example.com
+func publicHost(name string) string {
+	return name + "." + Theme().Domain
+}
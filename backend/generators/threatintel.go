@@ -0,0 +1,110 @@
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// threatFeedSeed fixes the threat feed so the same attacker IPs recur on
+// every run and can be matched against lookups built from a previous export
+const threatFeedSeed = 20240715
+
+// ThreatIP is an attacker address from the built-in threat intel feed
+type ThreatIP struct {
+	IP      string   `json:"ip"`
+	Country string   `json:"country"`
+	ASN     int      `json:"asn"`
+	ASOrg   string   `json:"as_org"`
+	Tags    []string `json:"tags"`
+}
+
+// ThreatFeed is a fixed list of known-bad IPs shared by generators that
+// model attacker traffic
+type ThreatFeed struct {
+	ips []*ThreatIP
+}
+
+// Threats is the global threat intel feed
+var Threats = newThreatFeed(threatFeedSeed, 150)
+
+func newThreatFeed(seed int64, n int) *ThreatFeed {
+	r := rand.New(rand.NewSource(seed))
+
+	// Attackers come from the default malicious countries plus the cloud
+	// and hosting networks scanners are commonly run from
+	var networks []*geoNetwork
+	var owners []*geoCountry
+	for _, code := range append(DefaultGeoPolicy().MaliciousCountries, "US", "NL", "DE", "IN", "KR") {
+		c := Geo.countries[code]
+		for i := range c.networks {
+			networks = append(networks, &c.networks[i])
+			owners = append(owners, c)
+		}
+	}
+	tags := [][]string{
+		{"scanner"},
+		{"ssh-bruteforce"},
+		{"ssh-bruteforce", "scanner"},
+		{"botnet", "mirai"},
+		{"tor-exit"},
+		{"malware-distribution"},
+		{"smb-exploit"},
+	}
+
+	f := &ThreatFeed{}
+	for len(f.ips) < n {
+		i := r.Intn(len(networks))
+		loc := Geo.locate(fmt.Sprintf("%s.%d.%d", networks[i].prefix, r.Intn(256), 1+r.Intn(254)), owners[i], networks[i])
+		f.ips = append(f.ips, &ThreatIP{
+			IP:      loc.IP,
+			Country: loc.CountryCode,
+			ASN:     loc.ASN,
+			ASOrg:   loc.ASOrg,
+			Tags:    tags[r.Intn(len(tags))],
+		})
+	}
+	return f
+}
+
+// IPs returns every entry in the feed
+func (f *ThreatFeed) IPs() []*ThreatIP {
+	return f.ips
+}
+
+// RandomIP picks a feed entry carrying one of the given tags, or any entry
+func (f *ThreatFeed) RandomIP(rnd *RandomStream, tags ...string) *ThreatIP {
+	candidates := f.ips
+	if len(tags) > 0 {
+		candidates = nil
+		for _, t := range f.ips {
+			if hasAnyTag(t.Tags, tags) {
+				candidates = append(candidates, t)
+			}
+		}
+		if len(candidates) == 0 {
+			candidates = f.ips
+		}
+	}
+	return candidates[rnd.intn(len(candidates))]
+}
+
+// Lookup returns the feed entry for ip
+func (f *ThreatFeed) Lookup(ip string) (*ThreatIP, bool) {
+	for _, t := range f.ips {
+		if t.IP == ip {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func hasAnyTag(have, want []string) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package generators
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Timezones must load in images without zoneinfo
+
+	"siem-event-generator/models"
+)
+
+// fuzzLayouts renders each fuzz format
+var fuzzLayouts = map[string]string{
+	models.TimestampRFC3339:       time.RFC3339,
+	models.TimestampRFC3339Millis: "2006-01-02T15:04:05.000Z07:00",
+	models.TimestampSyslog:        "Jan _2 15:04:05",
+	models.TimestampNoTZ:          "2006-01-02 15:04:05",
+	models.TimestampApache:        "02/Jan/2006:15:04:05 -0700",
+	models.TimestampUS:            "01/02/2006 03:04:05 PM",
+	models.TimestampRFC1123:       time.RFC1123,
+}
+
+// fuzzFormats lists every fuzz format in a fixed order
+var fuzzFormats = []string{
+	models.TimestampRFC3339, models.TimestampRFC3339Millis, models.TimestampEpoch,
+	models.TimestampEpochMillis, models.TimestampSyslog, models.TimestampNoTZ,
+	models.TimestampApache, models.TimestampUS, models.TimestampRFC1123,
+}
+
+// defaultFuzzZones are used when a fuzz config names no timezones
+var defaultFuzzZones = []string{
+	"UTC", "America/New_York", "America/Los_Angeles", "Europe/London",
+	"Europe/Berlin", "Asia/Kolkata", "Asia/Tokyo", "Australia/Sydney",
+}
+
+// sourceLayouts are the layouts the generators write timestamps in. Time-
+// or date-only layouts are left out; they match too much unrelated text.
+var sourceLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.000000000Z",
+	"2006-01-02T15:04:05.000000Z",
+	"2006-01-02T15:04:05.000000-0700",
+	"2006-01-02T15:04:05.000000",
+	"2006-01-02T15:04:05.000Z07:00",
+	"2006-01-02T15:04:05.000+0000",
+	"2006-01-02T15:04:05.000Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.0000000",
+	"2006-01-02 15:04:05.000 MST",
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"02/Jan/2006:15:04:05 -0700",
+	"Mon Jan 02 15:04:05.000000 2006",
+	"Mon Jan 02 15:04:05 2006",
+	"Mon Jan _2 15:04:05 2006 UTC",
+	"Jan 02 15:04:05",
+	"1/2/2006 3:04:05 PM",
+}
+
+// TimestampFuzzer rewrites event timestamps in formats and timezones picked
+// from its config
+type TimestampFuzzer struct {
+	formats     []string
+	zones       []*time.Location
+	perTemplate bool
+	rate        float64
+}
+
+// NewTimestampFuzzer checks cfg and returns a fuzzer for it
+func NewTimestampFuzzer(cfg models.TimestampFuzz) (*TimestampFuzzer, error) {
+	f := &TimestampFuzzer{formats: cfg.Formats, rate: cfg.Rate}
+	if len(f.formats) == 0 {
+		f.formats = fuzzFormats
+	}
+	for _, format := range f.formats {
+		if _, ok := fuzzLayouts[format]; !ok && format != models.TimestampEpoch && format != models.TimestampEpochMillis {
+			return nil, fmt.Errorf("unknown timestamp format %q", format)
+		}
+	}
+
+	zones := cfg.Timezones
+	if len(zones) == 0 {
+		zones = defaultFuzzZones
+	}
+	for _, name := range zones {
+		loc, err := loadZone(name)
+		if err != nil {
+			return nil, err
+		}
+		f.zones = append(f.zones, loc)
+	}
+
+	switch cfg.Mode {
+	case "", models.TimestampFuzzPerEvent:
+	case models.TimestampFuzzPerTemplate:
+		f.perTemplate = true
+	default:
+		return nil, fmt.Errorf("unknown timestamp fuzz mode %q", cfg.Mode)
+	}
+	if f.rate < 0 || f.rate > 1 {
+		return nil, fmt.Errorf("timestamp fuzz rate must be between 0 and 1")
+	}
+	if f.rate == 0 {
+		f.rate = 1
+	}
+	return f, nil
+}
+
+// loadZone loads an IANA timezone or a fixed offset such as "+05:30"
+func loadZone(name string) (*time.Location, error) {
+	if strings.HasPrefix(name, "+") || strings.HasPrefix(name, "-") {
+		t, err := time.Parse("-07:00", name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone offset %q", name)
+		}
+		_, offset := t.Zone()
+		return time.FixedZone(name, offset), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// Apply returns a copy of event with its timestamp rewritten wherever the
+// raw event or a field carries it in a layout the generators use. Events
+// whose timestamp is not found, or that the rate skips, are returned as is.
+func (f *TimestampFuzzer) Apply(event *models.GeneratedEvent) *models.GeneratedEvent {
+	if f == nil || (f.rate < 1 && Unseeded.float64() >= f.rate) {
+		return event
+	}
+
+	var format string
+	var loc *time.Location
+	if f.perTemplate {
+		h := fnv.New32a()
+		h.Write([]byte(event.Type + "/" + event.EventID))
+		n := int(h.Sum32())
+		format, loc = f.formats[n%len(f.formats)], f.zones[n/len(f.formats)%len(f.zones)]
+	} else {
+		format = f.formats[int(Unseeded.float64()*float64(len(f.formats)))]
+		loc = f.zones[int(Unseeded.float64()*float64(len(f.zones)))]
+	}
+	ts := formatFuzzed(event.Timestamp.In(loc), format)
+
+	replaced := make(map[string]bool)
+	var olds []string
+	add := func(s string) {
+		if !replaced[s] && (strings.Contains(event.RawEvent, s) || fieldsContain(event.Fields, s)) {
+			replaced[s] = true
+			olds = append(olds, s)
+		}
+	}
+	for _, layout := range sourceLayouts {
+		add(event.Timestamp.Format(layout))
+	}
+	// Epoch times may be JSON numbers, which only another number can replace
+	if format == models.TimestampEpoch || format == models.TimestampEpochMillis {
+		add(strconv.FormatInt(event.Timestamp.UnixMilli(), 10))
+		add(strconv.FormatInt(event.Timestamp.Unix(), 10))
+	}
+	if len(olds) == 0 {
+		return event
+	}
+	// Longer forms first, so a layout that extends another is replaced whole
+	sort.SliceStable(olds, func(i, j int) bool { return len(olds[i]) > len(olds[j]) })
+
+	pairs := make([]string, 0, 2*len(olds))
+	for _, old := range olds {
+		pairs = append(pairs, old, ts)
+	}
+	r := strings.NewReplacer(pairs...)
+
+	fuzzed := *event
+	fuzzed.RawEvent = r.Replace(event.RawEvent)
+	if fields, ok := replaceStrings(event.Fields, r).(map[string]interface{}); ok {
+		fuzzed.Fields = fields
+	}
+	return &fuzzed
+}
+
+func formatFuzzed(t time.Time, format string) string {
+	switch format {
+	case models.TimestampEpoch:
+		return strconv.FormatInt(t.Unix(), 10)
+	case models.TimestampEpochMillis:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	return t.Format(fuzzLayouts[format])
+}
+
+// fieldsContain reports whether any string in v contains s
+func fieldsContain(v interface{}, s string) bool {
+	switch t := v.(type) {
+	case string:
+		return strings.Contains(t, s)
+	case map[string]interface{}:
+		for _, child := range t {
+			if fieldsContain(child, s) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if fieldsContain(child, s) {
+				return true
+			}
+		}
+	case []map[string]interface{}:
+		for _, child := range t {
+			if fieldsContain(child, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replaceStrings returns a copy of v with r applied to every string in it
+func replaceStrings(v interface{}, r *strings.Replacer) interface{} {
+	switch t := v.(type) {
+	case string:
+		return r.Replace(t)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			m[k] = replaceStrings(child, r)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(t))
+		for i, child := range t {
+			items[i] = replaceStrings(child, r)
+		}
+		return items
+	case []map[string]interface{}:
+		items := make([]map[string]interface{}, len(t))
+		for i, child := range t {
+			items[i] = replaceStrings(child, r).(map[string]interface{})
+		}
+		return items
+	case []string:
+		items := make([]string, len(t))
+		for i, s := range t {
+			items[i] = r.Replace(s)
+		}
+		return items
+	}
+	return v
+}
@@ -0,0 +1,255 @@
+package generators
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tlsCipherNames are the IANA names of the cipher suites servers pick
+var tlsCipherNames = map[int]string{
+	4865:  "TLS_AES_128_GCM_SHA256",
+	4866:  "TLS_AES_256_GCM_SHA384",
+	4867:  "TLS_CHACHA20_POLY1305_SHA256",
+	49199: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	49200: "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	49192: "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA384",
+}
+
+// tlsClient is the ClientHello of a kind of client: the cipher suites,
+// extensions, curves, point formats, signature algorithms and ALPN it
+// offers, as captured from the real client
+type tlsClient struct {
+	name       string
+	userAgent  string
+	maxVersion string // 1.2 or 1.3
+	ciphers    []int
+	extensions []int
+	curves     []int
+	points     []int
+	sigAlgs    []string
+	alpn       string  // First ALPN protocol offered, empty for none
+	weight     float64 // How often the client makes a connection
+	malicious  bool
+
+	ja3, ja3Hash, ja4 string
+}
+
+var tlsClients = []*tlsClient{
+	{
+		name:       "Chrome",
+		userAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+		maxVersion: "1.3",
+		ciphers:    []int{4865, 4866, 4867, 49195, 49199, 49196, 49200, 52393, 52392, 49171, 49172, 156, 157, 47, 53},
+		extensions: []int{0, 23, 65281, 10, 11, 35, 16, 5, 13, 18, 51, 45, 43, 27, 17513, 21},
+		curves:     []int{29, 23, 24},
+		points:     []int{0},
+		sigAlgs:    []string{"0403", "0804", "0401", "0503", "0805", "0501", "0806", "0601"},
+		alpn:       "h2",
+		weight:     40,
+	},
+	{
+		name:       "Edge",
+		userAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.80",
+		maxVersion: "1.3",
+		ciphers:    []int{4865, 4866, 4867, 49195, 49199, 49196, 49200, 52393, 52392, 49171, 49172, 156, 157, 47, 53},
+		extensions: []int{0, 23, 65281, 10, 11, 35, 16, 5, 13, 18, 51, 45, 43, 27, 17513},
+		curves:     []int{29, 23, 24},
+		points:     []int{0},
+		sigAlgs:    []string{"0403", "0804", "0401", "0503", "0805", "0501", "0806", "0601"},
+		alpn:       "h2",
+		weight:     15,
+	},
+	{
+		name:       "Firefox",
+		userAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+		maxVersion: "1.3",
+		ciphers:    []int{4865, 4867, 4866, 49195, 49199, 52393, 52392, 49196, 49200, 49162, 49161, 49171, 49172, 156, 157, 47, 53},
+		extensions: []int{0, 23, 65281, 10, 11, 16, 5, 34, 51, 43, 13, 45, 28, 65037},
+		curves:     []int{29, 23, 24, 25, 256, 257},
+		points:     []int{0},
+		sigAlgs:    []string{"0403", "0503", "0603", "0804", "0805", "0806", "0401", "0501", "0601", "0203", "0201"},
+		alpn:       "h2",
+		weight:     10,
+	},
+	{
+		name:       "curl",
+		userAgent:  "curl/8.4.0",
+		maxVersion: "1.3",
+		ciphers:    []int{4866, 4867, 4865, 49196, 49200, 159, 52393, 52392, 52394, 49195, 49199, 158, 49188, 49192, 107, 49187, 49191, 103, 49162, 49172, 57, 49161, 49171, 51, 157, 156, 61, 60, 53, 47, 255},
+		extensions: []int{0, 11, 10, 13172, 16, 22, 23, 49, 13, 43, 45, 51},
+		curves:     []int{29, 23, 30, 25, 24},
+		points:     []int{0, 1, 2},
+		sigAlgs:    []string{"0403", "0503", "0603", "0807", "0808", "0809", "080a", "080b", "0804", "0805", "0806", "0401", "0501", "0601"},
+		alpn:       "h2",
+		weight:     5,
+	},
+	{
+		name:       "python-requests",
+		userAgent:  "python-requests/2.31.0",
+		maxVersion: "1.3",
+		ciphers:    []int{4866, 4867, 4865, 49196, 49200, 49195, 49199, 52393, 52392, 159, 158, 52394, 49327, 49325, 49326, 49324, 49188, 49192, 49187, 49191, 49162, 49172, 49161, 49171, 49315, 49311, 49314, 49310, 107, 103, 57, 51, 157, 156, 49313, 49309, 49312, 49308, 61, 60, 53, 47, 255},
+		extensions: []int{0, 11, 10, 35, 22, 23, 13, 43, 45, 51},
+		curves:     []int{29, 23, 30, 25, 24},
+		points:     []int{0, 1, 2},
+		sigAlgs:    []string{"0403", "0503", "0603", "0807", "0808", "0809", "080a", "080b", "0804", "0805", "0806", "0401", "0501", "0601"},
+		weight:     5,
+	},
+	{
+		name:       "PowerShell",
+		userAgent:  "Mozilla/5.0 (Windows NT; Windows NT 10.0; en-US) WindowsPowerShell/5.1.19041.4291",
+		maxVersion: "1.2",
+		ciphers:    []int{49196, 49195, 49200, 49199, 159, 158, 49188, 49187, 49192, 49191, 49162, 49161, 49172, 49171, 157, 156, 61, 60, 53, 47, 10},
+		extensions: []int{0, 10, 11, 13, 35, 23, 65281},
+		curves:     []int{29, 23, 24},
+		points:     []int{0},
+		sigAlgs:    []string{"0804", "0805", "0806", "0401", "0501", "0201", "0403", "0503", "0203", "0202", "0601", "0603"},
+		weight:     8,
+	},
+	{
+		name:       "Go",
+		userAgent:  "Go-http-client/1.1",
+		maxVersion: "1.3",
+		ciphers:    []int{49195, 49199, 49196, 49200, 52393, 52392, 49161, 49171, 49162, 49172, 156, 157, 47, 53, 49170, 10, 4865, 4866, 4867},
+		extensions: []int{0, 5, 10, 11, 13, 65281, 23, 18, 43, 51},
+		curves:     []int{29, 23, 24, 25},
+		points:     []int{0},
+		sigAlgs:    []string{"0804", "0403", "0807", "0805", "0806", "0401", "0501", "0601", "0503", "0603", "0201", "0203"},
+		weight:     7,
+	},
+	{
+		name:       "Cobalt Strike",
+		userAgent:  "Mozilla/5.0 (compatible; MSIE 9.0; Windows NT 6.1; Trident/5.0)",
+		maxVersion: "1.2",
+		ciphers:    []int{49192, 49191, 49172, 49171, 159, 158, 57, 51, 157, 156, 61, 60, 53, 47, 49196, 49195, 49188, 49187, 49162, 49161, 106, 64, 56, 50, 10, 19},
+		extensions: []int{0, 10, 11, 13, 23, 65281},
+		curves:     []int{23, 24},
+		points:     []int{0},
+		sigAlgs:    []string{"0401", "0501", "0201", "0403", "0503", "0203", "0402", "0202"},
+		malicious:  true,
+		weight:     2,
+	},
+	{
+		name:       "Sliver",
+		userAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/100.0.4896.127 Safari/537.36",
+		maxVersion: "1.3",
+		ciphers:    []int{49195, 49199, 49196, 49200, 52393, 52392, 49161, 49171, 49162, 49172, 156, 157, 47, 53, 49170, 10, 4865, 4866, 4867},
+		extensions: []int{0, 5, 10, 11, 13, 65281, 23, 18, 43, 51},
+		curves:     []int{29, 23, 24, 25},
+		points:     []int{0},
+		sigAlgs:    []string{"0804", "0403", "0807", "0805", "0806", "0401", "0501", "0601", "0503", "0603", "0201", "0203"},
+		malicious:  true,
+		weight:     1,
+	},
+}
+
+// tlsClientWeights are the clients' weights, in order
+var tlsClientWeights []float64
+
+func init() {
+	for _, c := range tlsClients {
+		tlsClientWeights = append(tlsClientWeights, c.weight)
+		c.ja3 = fmt.Sprintf("771,%s,%s,%s,%s", joinInts(c.ciphers, "-"), joinInts(c.extensions, "-"), joinInts(c.curves, "-"), joinInts(c.points, "-"))
+		c.ja3Hash = md5Hex(c.ja3)
+		c.ja4 = c.computeJA4()
+	}
+}
+
+// computeJA4 builds the client's JA4 fingerprint: protocol, version, SNI,
+// cipher and extension counts and ALPN, then truncated SHA-256s of the
+// sorted ciphers and of the sorted extensions with the signature algorithms
+func (c *tlsClient) computeJA4() string {
+	version := "12"
+	if c.maxVersion == "1.3" {
+		version = "13"
+	}
+	alpn := "00"
+	if c.alpn != "" {
+		alpn = c.alpn[:1] + c.alpn[len(c.alpn)-1:]
+	}
+
+	ciphers := make([]string, len(c.ciphers))
+	for i, v := range c.ciphers {
+		ciphers[i] = fmt.Sprintf("%04x", v)
+	}
+	sort.Strings(ciphers)
+	var exts []string
+	for _, v := range c.extensions {
+		if v != 0 && v != 16 { // SNI and ALPN are counted but not hashed
+			exts = append(exts, fmt.Sprintf("%04x", v))
+		}
+	}
+	sort.Strings(exts)
+
+	return fmt.Sprintf("t%sd%02d%02d%s_%s_%s", version, len(c.ciphers), len(c.extensions), alpn,
+		sha256Hex(strings.Join(ciphers, ","))[:12],
+		sha256Hex(strings.Join(exts, ",") + "_" + strings.Join(c.sigAlgs, ","))[:12])
+}
+
+// tlsSession is a TLS connection from a client: the version and cipher the
+// server picked and the fingerprints of both hellos
+type tlsSession struct {
+	client                       *tlsClient
+	version                      string // 1.2 or 1.3
+	cipher                       int
+	ja3, ja3Hash, ja3s, ja3sHash string
+	ja4                          string
+}
+
+// CipherName returns the IANA name of the negotiated cipher suite
+func (s tlsSession) CipherName() string { return tlsCipherNames[s.cipher] }
+
+// randomTLSClient picks a client by weight
+func randomTLSClient(rnd *RandomStream) *tlsClient {
+	return tlsClients[rnd.weightedIndex(tlsClientWeights)]
+}
+
+// randomClientUserAgent returns the user agent of a client from the same
+// population TLS fingerprints come from
+func (b *BaseGenerator) randomClientUserAgent() string {
+	return randomTLSClient(b.rnd).userAgent
+}
+
+// randomTLSSession picks a client and negotiates a session with a server
+func (b *BaseGenerator) randomTLSSession() tlsSession {
+	c := randomTLSClient(b.rnd)
+	s := tlsSession{client: c, version: c.maxVersion, ja3: c.ja3, ja3Hash: c.ja3Hash, ja4: c.ja4}
+
+	var exts string
+	switch {
+	case c.name == "Cobalt Strike":
+		s.cipher, exts = 49192, "65281-11" // Java team server
+	case s.version == "1.3":
+		s.cipher, exts = []int{4865, 4866}[b.RandomInt(0, 1)], "43-51"
+	default:
+		s.cipher, exts = []int{49199, 49200}[b.RandomInt(0, 1)], "65281-0-11-35-23"
+	}
+	if c.alpn != "" {
+		exts += "-16"
+	}
+	s.ja3s = fmt.Sprintf("771,%d,%s", s.cipher, exts)
+	s.ja3sHash = md5Hex(s.ja3s)
+	return s
+}
+
+func joinInts(vs []int, sep string) string {
+	s := make([]string, len(vs))
+	for i, v := range vs {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, sep)
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,118 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// schemaCache memoizes inferred schemas by "eventType/templateID"; field
+// names and types are stable for a template, only values vary
+var schemaCache sync.Map
+
+// cachedSchema returns the inferred schema for a template, generating it once
+func cachedSchema(g Generator, templateID string) (*models.TemplateSchema, error) {
+	key := g.GetEventType().ID + "/" + templateID
+	if s, ok := schemaCache.Load(key); ok {
+		return s.(*models.TemplateSchema), nil
+	}
+	schema, err := InferSchema(g, templateID)
+	if err != nil {
+		return nil, err
+	}
+	schemaCache.Store(key, schema)
+	return schema, nil
+}
+
+// ValidateOverrides checks overrides against the fields a template produces.
+// Values for known fields must match the field's type. In strict mode,
+// overrides for fields the template does not emit are rejected as well;
+// lenient mode lets them through as additional fields.
+func ValidateOverrides(g Generator, templateID string, overrides map[string]interface{}, strict bool) ([]models.FieldError, error) {
+	if len(overrides) == 0 {
+		return nil, nil
+	}
+
+	schema, err := cachedSchema(g, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]models.FieldSchema, len(schema.Fields))
+	for _, f := range schema.Fields {
+		known[f.Name] = f
+	}
+
+	var errs []models.FieldError
+	for key, value := range overrides {
+		spec, isDist, err := distributionFromOverride(value)
+		if err != nil {
+			errs = append(errs, models.FieldError{Field: key, Message: err.Error()})
+			continue
+		}
+		if isDist {
+			value = distributionSample(spec)
+		}
+
+		field, ok := known[key]
+		switch {
+		case ok && field.Overridable:
+			if !typeCompatible(field.Type, value) {
+				errs = append(errs, models.FieldError{
+					Field:   key,
+					Message: fmt.Sprintf("expected %s, got %s", field.Type, ValueType(value)),
+				})
+			}
+		case ok:
+			if strict {
+				parent, _, _ := strings.Cut(key, ".")
+				errs = append(errs, models.FieldError{
+					Field:   key,
+					Message: fmt.Sprintf("nested field cannot be overridden individually; override %q as a whole", parent),
+				})
+			}
+		case strict:
+			errs = append(errs, models.FieldError{
+				Field:   key,
+				Message: fmt.Sprintf("field is not produced by template %s", templateID),
+			})
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs, nil
+}
+
+// distributionSample draws a representative value from a valid spec for type checking
+func distributionSample(spec *models.DistributionSpec) interface{} {
+	var b BaseGenerator
+	v, _ := b.SampleDistribution(spec)
+	return v
+}
+
+// typeCompatible reports whether an override value can stand in for a field
+// of the given schema type. JSON numbers decode as float64, so integral
+// floats are accepted for integer fields.
+func typeCompatible(fieldType string, value interface{}) bool {
+	valueType := ValueType(value)
+	switch fieldType {
+	case "null":
+		return true
+	case "integer":
+		if valueType == "integer" {
+			return true
+		}
+		if f, ok := value.(float64); ok {
+			return f == math.Trunc(f)
+		}
+		return false
+	case "number":
+		return valueType == "number" || valueType == "integer"
+	default:
+		return valueType == fieldType
+	}
+}
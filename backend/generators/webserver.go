@@ -2,6 +2,7 @@ package generators
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,7 +10,8 @@ import (
 	"siem-event-generator/models"
 )
 
-// WebServerGenerator generates Apache/Nginx access log events
+// WebServerGenerator generates Apache/Nginx access and error log events for the
+// same virtual hosts and endpoints as the web/API metrics
 type WebServerGenerator struct {
 	BaseGenerator
 }
@@ -22,10 +24,10 @@ func init() {
 func (g *WebServerGenerator) GetEventType() models.EventType {
 	return models.EventType{
 		ID:          "webserver",
-		Name:        "Apache/Nginx Access Logs",
+		Name:        "Apache/Nginx Logs",
 		Category:    "web",
-		Description: "Web server access logs in combined format",
-		EventIDs:    []string{"200", "301", "302", "400", "401", "403", "404", "500"},
+		Description: "Web server access logs in combined format and Apache/Nginx error logs",
+		EventIDs:    []string{"200", "301", "302", "304", "400", "401", "403", "404", "429", "500", "502", "503", "504", "error"},
 	}
 }
 
@@ -39,6 +41,14 @@ func (g *WebServerGenerator) GetTemplates() []models.EventTemplate {
 			EventID:     "200",
 			Format:      "text",
 			Description: "HTTP 200 OK response",
+			Fields: []models.EventField{
+				{
+					Name:         "response_time",
+					Type:         "number",
+					Description:  "Seconds taken to serve the request",
+					Distribution: webResponseTime,
+				},
+			},
 		},
 		{
 			ID:          "redirect",
@@ -80,72 +90,259 @@ func (g *WebServerGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "text",
 			Description: "HTTP 500 internal server error",
 		},
+		{
+			ID:          "traffic",
+			Name:        "Mixed Traffic",
+			Category:    "webserver",
+			EventID:     "200",
+			Format:      "text",
+			Description: "Access log line with a realistic mix of pages, assets, API calls and scanner probes",
+		},
+		{
+			ID:          "apache_error",
+			Name:        "Apache Error Log",
+			Category:    "webserver",
+			EventID:     "error",
+			Format:      "text",
+			Description: "Apache httpd error log entry (proxy failures, denied access, invalid URIs)",
+			Sourcetype:  "apache_error",
+		},
+		{
+			ID:          "nginx_error",
+			Name:        "Nginx Error Log",
+			Category:    "webserver",
+			EventID:     "error",
+			Format:      "text",
+			Description: "Nginx error log entry (upstream timeouts, rate limiting, missing files)",
+			Sourcetype:  "nginx_error",
+		},
 	}
 }
 
 // Generate creates a Web Server access log event
 func (g *WebServerGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	switch templateID {
+	case "traffic":
+		return g.generateTraffic(overrides)
 	case "success":
-		return g.generateAccess(200, overrides)
+		return g.generateAccess(templateID, 200, overrides)
 	case "redirect":
-		return g.generateAccess(g.RandomChoice([]string{"301", "302"}), overrides)
+		return g.generateAccess(templateID, g.RandomChoice([]string{"301", "302"}), overrides)
 	case "not_found":
-		return g.generateAccess(404, overrides)
+		return g.generateAccess(templateID, 404, overrides)
 	case "unauthorized":
-		return g.generateAccess(401, overrides)
+		return g.generateAccess(templateID, 401, overrides)
 	case "forbidden":
-		return g.generateAccess(403, overrides)
+		return g.generateAccess(templateID, 403, overrides)
 	case "server_error":
-		return g.generateAccess(500, overrides)
+		return g.generateAccess(templateID, 500, overrides)
+	case "apache_error":
+		return g.generateApacheError(overrides)
+	case "nginx_error":
+		return g.generateNginxError(overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
 }
 
-func (g *WebServerGenerator) randomUserAgent() string {
-	agents := []string{
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15",
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
-		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-		"curl/7.88.1",
-		"python-requests/2.31.0",
-		"Go-http-client/1.1",
-		"Googlebot/2.1 (+http://www.google.com/bot.html)",
+// Kinds of request a web server sees, which decide the likely status codes,
+// response sizes, referers and clients
+const (
+	webRequestPage   = "page"
+	webRequestAsset  = "asset"
+	webRequestAPI    = "api"
+	webRequestProbe  = "probe"
+	webRequestHealth = "health"
+)
+
+var (
+	webProbes = []string{
+		"/wp-login.php", "/wp-admin/", "/phpmyadmin/", "/.env", "/.git/config",
+		"/config.php", "/admin", "/server-status", "/cgi-bin/luci", "/actuator/env",
+		"/vendor/phpunit/phpunit/src/Util/PHP/eval-stdin.php",
+	}
+	webSearchTerms = []string{"laptop", "headphones", "usb-c+cable", "monitor", "keyboard", "running+shoes", "coffee+maker"}
+)
+
+func (g *WebServerGenerator) randomHost() string {
+	return ServerName(fmt.Sprintf("web-%02d", g.RandomInt(1, 10)))
+}
+
+// randomAPIVhost picks one of the virtual hosts that proxy to the API services
+func (g *WebServerGenerator) randomAPIVhost() string {
+	var vhosts []string
+	for _, v := range webVhosts() {
+		if strings.Contains(v, "api.") {
+			vhosts = append(vhosts, v)
+		}
+	}
+	return g.ZipfChoice(vhosts)
+}
+
+// randomRequest picks a request URI for a virtual host: API hosts serve the
+// shared API endpoints, the others pages and their assets, and every host
+// gets the odd scanner probe
+func (b *BaseGenerator) randomRequest(vhost string) (kind, uri string) {
+	if b.RandomInt(1, 100) <= 3 {
+		return webRequestProbe, b.RandomChoice(webProbes)
 	}
-	return g.RandomChoice(agents)
+
+	if strings.Contains(vhost, "api.") {
+		endpoint := b.ZipfChoice(webEndpoints)
+		switch endpoint {
+		case "/health", "/metrics":
+			return webRequestHealth, endpoint
+		case "/api/v1/users", "/api/v1/orders", "/api/v1/products":
+			if b.RandomInt(1, 100) <= 40 {
+				return webRequestAPI, fmt.Sprintf("%s/%d", endpoint, b.RandomInt(1000, 99999))
+			}
+			return webRequestAPI, fmt.Sprintf("%s?page=%d&limit=%d", endpoint, b.RandomInt(1, 20), []int{20, 50, 100}[b.RandomInt(0, 2)])
+		case "/api/v1/search":
+			return webRequestAPI, endpoint + "?q=" + b.RandomChoice(webSearchTerms)
+		}
+		return webRequestAPI, endpoint
+	}
+
+	if b.RandomInt(1, 100) <= 55 {
+		return webRequestAsset, b.RandomURL(webRequestAsset)
+	}
+	return webRequestPage, b.RandomURL(webRequestPage)
 }
 
-func (g *WebServerGenerator) randomURI() string {
-	uris := []string{
-		"/", "/index.html", "/about", "/contact", "/login", "/api/v1/users",
-		"/api/v1/products", "/static/js/app.js", "/static/css/style.css",
-		"/images/logo.png", "/admin", "/dashboard", "/api/health",
-		"/wp-admin", "/phpmyadmin", "/.env", "/config.php",
+// requestKind classifies a request URI
+func requestKind(uri string) string {
+	path, _, _ := strings.Cut(uri, "?")
+	switch {
+	case path == "/health" || path == "/metrics":
+		return webRequestHealth
+	case strings.HasPrefix(path, "/api/"):
+		return webRequestAPI
+	case strings.HasPrefix(path, "/static/") || strings.HasPrefix(path, "/images/") || strings.HasPrefix(path, "/fonts/"):
+		return webRequestAsset
+	case path == "/favicon.ico" || path == "/apple-touch-icon.png" || path == "/robots.txt" || path == "/sitemap.xml" || path == "/manifest.json":
+		return webRequestAsset
 	}
-	return g.RandomChoice(uris)
+	for _, probe := range webProbes {
+		if path == probe {
+			return webRequestProbe
+		}
+	}
+	return webRequestPage
 }
 
-func (g *WebServerGenerator) randomMethod() string {
-	methods := []string{"GET", "GET", "GET", "GET", "POST", "PUT", "DELETE", "HEAD", "OPTIONS"}
-	return g.RandomChoice(methods)
+// randomStatus draws a status code from the distribution typical for the kind
+// of request
+func (b *BaseGenerator) randomStatus(kind, method string) int {
+	switch kind {
+	case webRequestAsset:
+		return []int{200, 304, 404}[b.weightedIndex([]float64{82, 16, 2})]
+	case webRequestPage:
+		return []int{200, 302, 304, 404, 500}[b.weightedIndex([]float64{86, 6, 4, 3, 1})]
+	case webRequestProbe:
+		return []int{404, 403, 301, 400}[b.weightedIndex([]float64{78, 15, 5, 2})]
+	case webRequestHealth:
+		return []int{200, 503}[b.weightedIndex([]float64{99.5, 0.5})]
+	}
+
+	success := 200
+	switch method {
+	case "POST":
+		success = 201
+	case "DELETE":
+		success = 204
+	}
+	codes := []int{success, 400, 401, 403, 404, 429, 500, 502, 503, 504}
+	return codes[b.weightedIndex([]float64{90, 2.5, 2, 0.5, 1.5, 1, 1, 0.5, 0.5, 0.5})]
 }
 
-func (g *WebServerGenerator) randomReferer() string {
-	referers := []string{
-		"-",
-		"https://www.google.com/",
-		"https://www.bing.com/",
-		"https://example.com/",
-		"https://internal.company.com/dashboard",
+func (b *BaseGenerator) randomMethodFor(kind string) string {
+	switch kind {
+	case webRequestAPI:
+		return b.WeightedChoice([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, []float64{65, 22, 5, 3, 5})
+	case webRequestPage:
+		return b.WeightedChoice([]string{"GET", "POST", "HEAD"}, []float64{92, 6, 2})
+	case webRequestProbe:
+		return b.WeightedChoice([]string{"GET", "POST", "HEAD"}, []float64{80, 15, 5})
 	}
-	return g.RandomChoice(referers)
+	return "GET"
 }
 
-func (g *WebServerGenerator) generateAccess(statusCode interface{}, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	timestamp := time.Now()
+// responseSize returns the bytes sent for a response, by request kind and status
+func (b *BaseGenerator) responseSize(kind, method, uri string, code int) int {
+	switch {
+	case method == "HEAD" || code == 204 || code == 304:
+		return 0
+	case code >= 300 && code < 400:
+		return b.RandomInt(180, 420)
+	case code >= 400:
+		return int(b.RandomLogNormal(350, 0.5))
+	}
+
+	switch kind {
+	case webRequestAsset:
+		switch {
+		case strings.HasSuffix(uri, ".js"):
+			return int(b.RandomLogNormal(180000, 0.7))
+		case strings.HasSuffix(uri, ".css"):
+			return int(b.RandomLogNormal(45000, 0.5))
+		case strings.HasSuffix(uri, ".ico"):
+			return 15086
+		default:
+			return int(b.RandomLogNormal(60000, 0.9))
+		}
+	case webRequestPage:
+		return int(b.RandomLogNormal(22000, 0.4))
+	case webRequestHealth:
+		return b.RandomInt(15, 2500)
+	}
+	return int(b.RandomLogNormal(1800, 1.2))
+}
 
+// randomClient returns a user agent and referer consistent with the request:
+// browsers navigate between a site's own pages, apps and SDKs call the APIs,
+// and scanners send no referer
+func (b *BaseGenerator) randomClient(kind, vhost string) (userAgent, referer string) {
+	switch kind {
+	case webRequestProbe:
+		return b.RandomChoice(webScannerAgents), "-"
+	case webRequestHealth:
+		return b.RandomChoice([]string{"kube-probe/1.28", "ELB-HealthChecker/2.0", "Prometheus/2.48.0"}), "-"
+	case webRequestAPI:
+		if strings.HasPrefix(vhost, "mobile-api.") || b.RandomInt(1, 100) <= 30 {
+			return b.RandomChoice(webAppAgents), "-"
+		}
+		return b.RandomUserAgent(), "https://" + publicHost("app") + "/"
+	case webRequestAsset:
+		return b.RandomUserAgent(), "https://" + vhost + b.RandomURL(webRequestPage)
+	}
+
+	referer = b.WeightedChoice([]string{"-", "https://www.google.com/", "https://www.bing.com/", "https://duckduckgo.com/", "internal"}, []float64{30, 30, 5, 3, 32})
+	if referer == "internal" {
+		referer = "https://" + vhost + b.RandomURL(webRequestPage)
+	}
+	return b.RandomUserAgent(), referer
+}
+
+var (
+	webAppAgents = []string{
+		"ExampleShop/5.12.1 (iPhone; iOS 17.4.1; Scale/3.00)",
+		"ExampleShop/5.12.0 (Android 14; Pixel 8)",
+		"okhttp/4.12.0",
+		"axios/1.6.7",
+		"python-requests/2.31.0",
+		"Go-http-client/2.0",
+	}
+	webScannerAgents = []string{
+		"Mozilla/5.0 zgrab/0.x",
+		"Mozilla/5.0 (compatible; CensysInspect/1.1; +https://about.censys.io/)",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/81.0.4044.129 Safari/537.36",
+		"curl/7.88.1",
+		"python-requests/2.25.1",
+		"Nuclei - Open-source project (github.com/projectdiscovery/nuclei)",
+	}
+)
+
+func (g *WebServerGenerator) generateAccess(templateID string, statusCode interface{}, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	var code int
 	switch v := statusCode.(type) {
 	case int:
@@ -154,14 +351,48 @@ func (g *WebServerGenerator) generateAccess(statusCode interface{}, overrides ma
 		fmt.Sscanf(v, "%d", &code)
 	}
 
+	vhost := g.ZipfChoice(webVhosts())
+	kind, uri := g.randomRequest(vhost)
+	method := g.randomMethodFor(kind)
+	uri, userAgent, _ := g.webAttackRequest(uri, "")
+	return g.accessEvent(templateID, code, time.Now(), method, uri, vhost, g.randomHost(), userAgent, overrides)
+}
+
+// generateTraffic creates an access log line whose status is drawn from the
+// distribution for the request rather than fixed by the template
+func (g *WebServerGenerator) generateTraffic(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	vhost := g.ZipfChoice(webVhosts())
+	kind, uri := g.randomRequest(vhost)
+	method := g.randomMethodFor(kind)
+	status := g.randomStatus(kind, method)
+	uri, userAgent, attacked := g.webAttackRequest(uri, "")
+	if attacked {
+		status = []int{200, 400, 403, 404, 500}[g.weightedIndex([]float64{30, 15, 35, 15, 5})]
+	}
+	return g.accessEvent("traffic", status, time.Now(), method, uri, vhost, g.randomHost(), userAgent, overrides)
+}
+
+// webResponseTime is the seconds taken to serve a request, unless the
+// template declares otherwise
+var webResponseTime = &models.DistributionSpec{Type: DistLogNormal, Median: 0.08, Sigma: 0.9}
+
+// accessEvent builds a combined-format access log line for a request to vhost.
+// A non-empty host is recorded in the fields so the line can be tied to the
+// server that logged it, and a non-empty agent replaces the client that
+// would usually make the request.
+func (g *WebServerGenerator) accessEvent(templateID string, code int, timestamp time.Time, method, uri, vhost, host, agent string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	kind := requestKind(uri)
 	clientIP := g.RandomIPv4External()
-	method := g.randomMethod()
-	uri := g.randomURI()
-	protocol := "HTTP/1.1"
-	bytesSent := g.RandomInt(100, 100000)
-	userAgent := g.randomUserAgent()
-	referer := g.randomReferer()
-	responseTime := float64(g.RandomInt(1, 5000)) / 1000 // seconds
+	protocol := g.WeightedChoice([]string{"HTTP/1.1", "HTTP/2.0"}, []float64{45, 55})
+	bytesSent := g.responseSize(kind, method, uri, code)
+	userAgent, referer := g.randomClient(kind, vhost)
+	if agent != "" {
+		userAgent, referer = agent, "-"
+	}
+	responseTime := g.declaredFloat(g, templateID, "response_time", webResponseTime)
+	if code == 504 {
+		responseTime = 60 + g.RandomFloat(0, 0.05)
+	}
 
 	// Combined Log Format
 	rawEvent := fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %d \"%s\" \"%s\"",
@@ -189,6 +420,10 @@ func (g *WebServerGenerator) generateAccess(statusCode interface{}, overrides ma
 		"referer":       referer,
 		"user_agent":    userAgent,
 		"response_time": responseTime,
+		"vhost":         vhost,
+	}
+	if host != "" {
+		fields["host"] = host
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
@@ -203,3 +438,145 @@ func (g *WebServerGenerator) generateAccess(statusCode interface{}, overrides ma
 		Sourcetype: "access_combined",
 	}, nil
 }
+
+// generateApacheError creates an Apache httpd 2.4 error log entry
+func (g *WebServerGenerator) generateApacheError(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	vhost := g.randomAPIVhost()
+	clientIP := g.RandomIPv4External()
+	client := fmt.Sprintf("%s:%d", clientIP, g.RandomInt(1024, 65535))
+	upstream := fmt.Sprintf("%s:8080", g.RandomIPv4Internal())
+	endpoint := g.ZipfChoice(webEndpoints)
+	pid := g.RandomInt(1000, 65000)
+	tid := g.RandomInt(139900000000000, 140999999999999)
+
+	var module, level, code, message string
+	switch g.WeightedChoice([]string{"proxy_timeout", "proxy_refused", "denied", "not_found", "invalid_uri", "auth", "max_workers"}, []float64{20, 15, 20, 25, 8, 8, 4}) {
+	case "proxy_timeout":
+		module, level, code = "proxy_http", "error", "AH01102"
+		message = fmt.Sprintf("(70007)The timeout specified has expired: [client %s] AH01102: error reading status line from remote server %s, referer: https://%s/", client, upstream, vhost)
+	case "proxy_refused":
+		module, level, code = "proxy", "error", "AH00957"
+		host, _, _ := strings.Cut(upstream, ":")
+		message = fmt.Sprintf("(111)Connection refused: AH00957: http: attempt to connect to %s (%s) failed", upstream, host)
+	case "denied":
+		module, level, code = "authz_core", "error", "AH01630"
+		message = fmt.Sprintf("[client %s] AH01630: client denied by server configuration: /var/www/%s%s", client, vhost, g.RandomChoice([]string{"/.env", "/.git/config", "/server-status", "/config.php"}))
+	case "not_found":
+		module, level, code = "core", "info", "AH00128"
+		message = fmt.Sprintf("[client %s] AH00128: File does not exist: /var/www/%s%s", client, vhost, g.RandomChoice(webProbes))
+	case "invalid_uri":
+		module, level, code = "core", "error", "AH00126"
+		message = fmt.Sprintf("[client %s] AH00126: Invalid URI in request GET %s HTTP/1.1", client, g.RandomChoice([]string{"/../../../../etc/passwd", "/cgi-bin/.%2e/.%2e/.%2e/bin/sh", "/%%32%65%%32%65/etc/shadow"}))
+	case "auth":
+		module, level, code = "auth_basic", "error", "AH01618"
+		message = fmt.Sprintf("[client %s] AH01618: user %s not found: /admin", client, g.RandomChoice([]string{"admin", "root", "test", "administrator"}))
+	default:
+		module, level, code = "mpm_event", "error", "AH00484"
+		client = ""
+		message = "AH00484: server reached MaxRequestWorkers setting, consider raising the MaxRequestWorkers setting"
+	}
+
+	rawEvent := fmt.Sprintf("[%s] [%s:%s] [pid %d:tid %d] %s",
+		now.Format("Mon Jan 02 15:04:05.000000 2006"), module, level, pid, tid, message)
+
+	fields := map[string]interface{}{
+		"timestamp":  now.Format(time.RFC3339Nano),
+		"module":     module,
+		"log_level":  level,
+		"pid":        pid,
+		"tid":        tid,
+		"error_code": code,
+		"message":    message,
+		"vhost":      vhost,
+		"endpoint":   endpoint,
+		"host":       g.randomHost(),
+	}
+	if client != "" {
+		fields["client_ip"] = clientIP
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "webserver",
+		EventID:    "error",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "apache_error",
+	}, nil
+}
+
+// generateNginxError creates an nginx error log entry
+func (g *WebServerGenerator) generateNginxError(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now()
+	vhost := g.randomAPIVhost()
+	clientIP := g.RandomIPv4External()
+	endpoint := g.ZipfChoice(webEndpoints)
+	upstream := fmt.Sprintf("http://%s:8080%s", g.RandomIPv4Internal(), endpoint)
+	request := fmt.Sprintf("%s %s HTTP/1.1", g.WeightedChoice([]string{"GET", "POST"}, []float64{70, 30}), endpoint)
+	pid := g.RandomInt(10, 4000)
+	conn := g.RandomInt(1000, 9999999)
+
+	context := fmt.Sprintf(", client: %s, server: %s, request: \"%s\", upstream: \"%s\", host: \"%s\"", clientIP, vhost, request, upstream, vhost)
+
+	var level, message string
+	switch g.WeightedChoice([]string{"upstream_timeout", "upstream_refused", "rate_limited", "no_file", "forbidden", "buffered", "ssl"}, []float64{22, 15, 15, 20, 10, 12, 6}) {
+	case "upstream_timeout":
+		level = "error"
+		message = "upstream timed out (110: Connection timed out) while reading response header from upstream" + context
+	case "upstream_refused":
+		level = "error"
+		message = "connect() failed (111: Connection refused) while connecting to upstream" + context
+	case "rate_limited":
+		level = "error"
+		message = fmt.Sprintf("limiting requests, excess: %.3f by zone \"api_limit\", client: %s, server: %s, request: \"%s\", host: \"%s\"",
+			g.RandomFloat(5, 50), clientIP, vhost, request, vhost)
+	case "no_file":
+		level = "error"
+		uri := g.RandomChoice(webProbes)
+		message = fmt.Sprintf("open() \"/usr/share/nginx/html%s\" failed (2: No such file or directory), client: %s, server: %s, request: \"GET %s HTTP/1.1\", host: \"%s\"",
+			uri, clientIP, vhost, uri, vhost)
+	case "forbidden":
+		level = "error"
+		uri := g.RandomChoice([]string{"/.git/config", "/.env", "/.htpasswd"})
+		message = fmt.Sprintf("access forbidden by rule, client: %s, server: %s, request: \"GET %s HTTP/1.1\", host: \"%s\"",
+			clientIP, vhost, uri, vhost)
+	case "buffered":
+		level = "warn"
+		message = fmt.Sprintf("an upstream response is buffered to a temporary file /var/cache/nginx/proxy_temp/%d/%02d/%010d while reading upstream",
+			g.RandomInt(0, 9), g.RandomInt(0, 99), g.RandomInt(1, 99999)) + context
+	default:
+		level = "crit"
+		message = fmt.Sprintf("SSL_do_handshake() failed (SSL: error:0A00006C:SSL routines::bad key share) while SSL handshaking, client: %s, server: 0.0.0.0:443", clientIP)
+	}
+
+	rawEvent := fmt.Sprintf("%s [%s] %d#%d: *%d %s",
+		now.Format("2006/01/02 15:04:05"), level, pid, pid, conn, message)
+
+	fields := map[string]interface{}{
+		"timestamp":     now.Format(time.RFC3339),
+		"log_level":     level,
+		"pid":           pid,
+		"connection_id": conn,
+		"client_ip":     clientIP,
+		"message":       message,
+		"vhost":         vhost,
+		"endpoint":      endpoint,
+		"host":          g.randomHost(),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "webserver",
+		EventID:    "error",
+		Timestamp:  now,
+		RawEvent:   rawEvent,
+		Fields:     fields,
+		Sourcetype: "nginx_error",
+	}, nil
+}
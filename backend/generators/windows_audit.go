@@ -0,0 +1,256 @@
+package generators
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// auditedObject is a file or registry key with a SACL, so access to it is
+// logged
+type auditedObject struct {
+	Type string // File or Key
+	Name string
+}
+
+// auditedObjects are the sensitive files and keys object access events
+// name: shares with finance and HR data, and credential stores
+var auditedObjects = []auditedObject{
+	{"File", `C:\Shares\Finance\Payroll\payroll_2024.xlsx`},
+	{"File", `C:\Shares\Finance\Budget\FY25_forecast.xlsx`},
+	{"File", `C:\Shares\HR\Reviews\performance_reviews.docx`},
+	{"File", `C:\Shares\Legal\Contracts\vendor_msa_signed.pdf`},
+	{"File", `C:\Shares\Engineering\Secrets\deploy_keys.txt`},
+	{"File", `C:\Windows\NTDS\ntds.dit`},
+	{"File", `C:\Windows\System32\config\SAM`},
+	{"Key", `\REGISTRY\MACHINE\SAM\SAM\Domains\Account`},
+	{"Key", `\REGISTRY\MACHINE\SECURITY\Policy\Secrets`},
+	{"Key", `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Control\Lsa`},
+}
+
+// objectAccessRights are common access requests as AccessList message
+// references and the matching AccessMask
+var objectAccessRights = []struct {
+	List string
+	Mask string
+}{
+	{"%%4416", "0x1"},     // ReadData (or ListDirectory)
+	{"%%4416", "0x1"},     // ReadData (or ListDirectory)
+	{"%%4417", "0x2"},     // WriteData (or AddFile)
+	{"%%1537", "0x10000"}, // DELETE
+	{"%%4423", "0x80"},    // ReadAttributes
+}
+
+// auditSubcategories are audit policy subcategories as 4719 logs them
+var auditSubcategories = []struct {
+	Category    string
+	Subcategory string
+	Guid        string
+}{
+	{"%%8274", "%%12800", "{0CCE921D-69AE-11D9-BED3-505054503030}"}, // Object Access: File System
+	{"%%8274", "%%12801", "{0CCE921E-69AE-11D9-BED3-505054503030}"}, // Object Access: Registry
+	{"%%8273", "%%12544", "{0CCE9215-69AE-11D9-BED3-505054503030}"}, // Logon/Logoff: Logon
+	{"%%8276", "%%13312", "{0CCE922B-69AE-11D9-BED3-505054503030}"}, // Detailed Tracking: Process Creation
+	{"%%8277", "%%13568", "{0CCE922F-69AE-11D9-BED3-505054503030}"}, // Policy Change: Audit Policy Change
+}
+
+// generate4663 creates an object access event for a sensitive file or
+// registry key, by a process in an open logon session
+func (g *WindowsSecurityGenerator) generate4663(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := g.activeSession(now, true)
+	object := auditedObjects[g.RandomInt(0, len(auditedObjects)-1)]
+	access := objectAccessRights[g.RandomInt(0, len(objectAccessRights)-1)]
+
+	processName := g.RandomChoice([]string{
+		`C:\Windows\explorer.exe`,
+		`C:\Program Files\Microsoft Office\root\Office16\EXCEL.EXE`,
+		`C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`,
+		`C:\Windows\System32\robocopy.exe`,
+	})
+	task := 12800 // File System
+	if object.Type == "Key" {
+		task = 12801 // Registry
+		processName = g.RandomChoice([]string{`C:\Windows\System32\reg.exe`, `C:\Windows\regedit.exe`, `C:\Windows\System32\lsass.exe`})
+	}
+
+	fields := map[string]interface{}{
+		"SubjectUserSid":     session.UserSid,
+		"SubjectUserName":    session.UserName,
+		"SubjectDomainName":  session.Domain,
+		"SubjectLogonId":     session.LogonID,
+		"ObjectServer":       "Security",
+		"ObjectType":         object.Type,
+		"ObjectName":         object.Name,
+		"HandleId":           fmt.Sprintf("0x%x", g.RandomInt(0x100, 0xfff)),
+		"AccessList":         access.List,
+		"AccessMask":         access.Mask,
+		"ProcessId":          fmt.Sprintf("0x%x", g.RandomInt(1000, 65535)),
+		"ProcessName":        processName,
+		"ResourceAttributes": "S:AI",
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(4663, now, fields)
+	event.System.Task = task
+	event.System.Computer = session.Computer
+	return g.securityEvent(4663, now, event, fields)
+}
+
+// generate4670 creates a permissions changed event: an object's DACL
+// gaining an Everyone or Authenticated Users full control entry
+func (g *WindowsSecurityGenerator) generate4670(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := g.activeSession(now, true)
+	object := auditedObjects[g.RandomInt(0, len(auditedObjects)-1)]
+
+	oldSd := "D:AI(A;OICIID;FA;;;SY)(A;OICIID;FA;;;BA)(A;OICIID;0x1200a9;;;" + session.UserSid + ")"
+	grantee := g.RandomChoice([]string{"WD", "AU", session.UserSid}) // Everyone, Authenticated Users
+	newSd := "D:ARAI(A;OICI;FA;;;" + grantee + ")(A;OICIID;FA;;;SY)(A;OICIID;FA;;;BA)"
+	processName := `C:\Windows\System32\icacls.exe`
+	if object.Type == "Key" {
+		oldSd = "D:P(A;CI;KA;;;SY)(A;CI;RC;;;BA)"
+		newSd = "D:P(A;CI;KA;;;SY)(A;CI;KA;;;BA)"
+		processName = `C:\Windows\regedit.exe`
+	}
+
+	fields := map[string]interface{}{
+		"SubjectUserSid":    session.UserSid,
+		"SubjectUserName":   session.UserName,
+		"SubjectDomainName": session.Domain,
+		"SubjectLogonId":    session.LogonID,
+		"ObjectServer":      "Security",
+		"ObjectType":        object.Type,
+		"ObjectName":        object.Name,
+		"HandleId":          fmt.Sprintf("0x%x", g.RandomInt(0x100, 0xfff)),
+		"OldSd":             oldSd,
+		"NewSd":             newSd,
+		"ProcessId":         fmt.Sprintf("0x%x", g.RandomInt(1000, 65535)),
+		"ProcessName":       processName,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(4670, now, fields)
+	event.System.Task = 13570 // Authorization Policy Change
+	event.System.Computer = session.Computer
+	return g.securityEvent(4670, now, event, fields)
+}
+
+// generate4719 creates a system audit policy change, mostly auditing being
+// switched off, as attackers do before acting
+func (g *WindowsSecurityGenerator) generate4719(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := g.activeSession(now, false)
+	sub := auditSubcategories[g.RandomInt(0, len(auditSubcategories)-1)]
+
+	fields := map[string]interface{}{
+		"SubjectUserSid":     session.UserSid,
+		"SubjectUserName":    session.UserName,
+		"SubjectDomainName":  session.Domain,
+		"SubjectLogonId":     session.LogonID,
+		"CategoryId":         sub.Category,
+		"SubcategoryId":      sub.Subcategory,
+		"SubcategoryGuid":    sub.Guid,
+		"AuditPolicyChanges": g.WeightedChoice([]string{"%%8448, %%8450", "%%8448", "%%8450", "%%8449", "%%8449, %%8451"}, []float64{35, 20, 15, 20, 10}), // Success/failure removed or added
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(4719, now, fields)
+	event.System.Task = 13568 // Audit Policy Change
+	event.System.Computer = session.Computer
+	return g.securityEvent(4719, now, event, fields)
+}
+
+// WindowsLogClearedEvent is the layout of event 1102, which the event log
+// service writes with UserData instead of EventData
+type WindowsLogClearedEvent struct {
+	XMLName  xml.Name `xml:"Event"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	System   WindowsEventSystem
+	UserData struct {
+		LogFileCleared struct {
+			Xmlns             string `xml:"xmlns,attr"`
+			SubjectUserSid    string
+			SubjectUserName   string
+			SubjectDomainName string
+			SubjectLogonId    string
+		}
+	}
+}
+
+// generate1102 creates a security log cleared event
+func (g *WindowsSecurityGenerator) generate1102(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := g.activeSession(now, true)
+
+	fields := map[string]interface{}{
+		"SubjectUserSid":    session.UserSid,
+		"SubjectUserName":   session.UserName,
+		"SubjectDomainName": session.Domain,
+		"SubjectLogonId":    session.LogonID,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	system := g.buildEvent(1102, now, nil).System
+	system.Provider = WindowsEventProvider{
+		Name: "Microsoft-Windows-Eventlog",
+		Guid: "{fc65ddd8-d6ef-4962-83d5-6e5cfe9ce148}",
+	}
+	system.Version = 0
+	system.Level = 4
+	system.Task = 104 // Log clear
+	system.Keywords = "0x4020000000000000"
+	system.Computer = session.Computer
+
+	event := WindowsLogClearedEvent{
+		Xmlns:  "http://schemas.microsoft.com/win/2004/08/events/event",
+		System: system,
+	}
+	cleared := &event.UserData.LogFileCleared
+	cleared.Xmlns = "http://manifests.microsoft.com/win/2004/08/windows/eventlog"
+	cleared.SubjectUserSid = fmt.Sprintf("%v", fields["SubjectUserSid"])
+	cleared.SubjectUserName = fmt.Sprintf("%v", fields["SubjectUserName"])
+	cleared.SubjectDomainName = fmt.Sprintf("%v", fields["SubjectDomainName"])
+	cleared.SubjectLogonId = fmt.Sprintf("%v", fields["SubjectLogonId"])
+
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_security",
+		EventID:    "1102",
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "WinEventLog:Security",
+	}, nil
+}
+
+// securityEvent marshals a built Security event
+func (g *WindowsSecurityGenerator) securityEvent(eventID int, now time.Time, event WindowsEvent, fields map[string]interface{}) (*models.GeneratedEvent, error) {
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_security",
+		EventID:    fmt.Sprintf("%d", eventID),
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "WinEventLog:Security",
+	}, nil
+}
@@ -0,0 +1,275 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// WindowsDefenderAVGenerator generates Microsoft Defender Antivirus
+// Operational log events
+type WindowsDefenderAVGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&WindowsDefenderAVGenerator{})
+}
+
+// GetEventType returns the event type for Microsoft Defender Antivirus
+func (g *WindowsDefenderAVGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "windows_defender_av",
+		Name:        "Microsoft Defender Antivirus",
+		Category:    "windows",
+		Description: "Microsoft Defender Antivirus Operational events: malware detections, remediation and configuration changes",
+		EventIDs:    []string{"1116", "1117", "5007"},
+	}
+}
+
+// GetTemplates returns available templates for Defender Antivirus events
+func (g *WindowsDefenderAVGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "1116",
+			Name:        "Malware Detected",
+			Category:    "windows_defender_av",
+			EventID:     "1116",
+			Format:      "xml",
+			Description: "The antimalware platform detected malware or other potentially unwanted software",
+		},
+		{
+			ID:          "1117",
+			Name:        "Malware Action Taken",
+			Category:    "windows_defender_av",
+			EventID:     "1117",
+			Format:      "xml",
+			Description: "The antimalware platform performed an action to protect the system",
+		},
+		{
+			ID:          "5007",
+			Name:        "Configuration Changed",
+			Category:    "windows_defender_av",
+			EventID:     "5007",
+			Format:      "xml",
+			Description: "Antimalware platform configuration changed (exclusions, real-time protection, tamper protection)",
+		},
+	}
+}
+
+// Generate creates a Defender Antivirus event
+func (g *WindowsDefenderAVGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "1116":
+		return g.generateDetection(1116, overrides)
+	case "1117":
+		return g.generateDetection(1117, overrides)
+	case "5007":
+		return g.generate5007(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// defenderThreat is a detection with Defender's threat metadata
+type defenderThreat struct {
+	name       string
+	threatID   int
+	severityID int
+	severity   string
+	categoryID int
+	category   string
+	resource   string // path template with {user} and {n} placeholders
+}
+
+var defenderThreats = []defenderThreat{
+	{"Trojan:Win32/Emotet.RPK!MTB", 2147755555, 5, "Severe", 8, "Trojan", "file:_C:\\Users\\{user}\\AppData\\Local\\Temp\\invoice_{n}.exe"},
+	{"HackTool:Win32/Mimikatz.D", 2147729023, 4, "High", 34, "Tool", "file:_C:\\Users\\{user}\\Downloads\\mimikatz_{n}.exe"},
+	{"Behavior:Win32/CobaltStrike.B!sms", 2147763226, 5, "Severe", 46, "Suspicious Behavior", "behavior:_process: C:\\Windows\\System32\\rundll32.exe, pid:{n}"},
+	{"Exploit:O97M/CVE-2017-11882.RV!MTB", 2147741370, 5, "Severe", 30, "Exploit", "containerfile:_C:\\Users\\{user}\\Documents\\PO_{n}.doc"},
+	{"Backdoor:Win32/Bladabindi!ml", 2147709436, 5, "Severe", 6, "Backdoor", "file:_C:\\Users\\{user}\\AppData\\Roaming\\svchost{n}.exe"},
+	{"Trojan:PowerShell/Powersploit.M", 2147725351, 5, "Severe", 8, "Trojan", "amsi:_\\Device\\HarddiskVolume3\\Windows\\System32\\WindowsPowerShell\\v1.0\\powershell.exe"},
+	{"PUA:Win32/Presenoker", 2147734500, 1, "Low", 27, "Potentially Unwanted Software", "file:_C:\\Users\\{user}\\Downloads\\setup_{n}.exe"},
+	{"Virus:DOS/EICAR_Test_File", 2147519003, 5, "Severe", 42, "Virus", "file:_C:\\Users\\{user}\\Desktop\\eicar_{n}.com"},
+}
+
+// defenderAction is a remediation action with its Defender ID
+type defenderAction struct {
+	id   int
+	name string
+}
+
+// generateDetection creates a detection (1116) or action taken (1117) event
+func (g *WindowsDefenderAVGenerator) generateDetection(eventID int, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	threat := defenderThreats[g.RandomInt(0, len(defenderThreats)-1)]
+	user := g.RandomUsername()
+	path := strings.NewReplacer("{user}", user, "{n}", fmt.Sprint(g.RandomInt(1000, 9999))).Replace(threat.resource)
+
+	sourceID, sourceName := 3, "Real-Time Protection"
+	originID, originName := 1, "Local machine"
+	switch {
+	case strings.HasPrefix(path, "amsi:"):
+		sourceID, sourceName = 10, "AMSI"
+	case strings.HasPrefix(path, "behavior:"):
+		sourceID, sourceName = 8, "Behavior Monitoring"
+	case strings.Contains(path, "\\Downloads\\"):
+		sourceID, sourceName = 4, "Downloads and attachments"
+		originID, originName = 4, "Internet"
+	}
+
+	action := defenderAction{2, "Quarantine"}
+	switch {
+	case threat.severityID == 1:
+		action = defenderAction{6, "Allow"}
+	case sourceID == 8 || sourceID == 10:
+		action = defenderAction{10, "Block"}
+	case threat.categoryID == 34:
+		action = defenderAction{3, "Remove"}
+	}
+
+	statusCode, status := 1, "Detected"
+	actionID, actionName := 9, "Not Applicable"
+	if eventID == 1117 {
+		statusCode, status = 3, "Remediated"
+		actionID, actionName = action.id, action.name
+	}
+
+	fields := map[string]interface{}{
+		"Product Name":                  "Microsoft Defender Antivirus",
+		"Product Version":               "4.18.24090.11",
+		"Detection ID":                  fmt.Sprintf("{%s}", strings.ToUpper(g.RandomGUID())),
+		"Detection Time":                now.Format("2006-01-02T15:04:05.000Z"),
+		"Threat ID":                     threat.threatID,
+		"Threat Name":                   threat.name,
+		"Severity ID":                   threat.severityID,
+		"Severity Name":                 threat.severity,
+		"Category ID":                   threat.categoryID,
+		"Category Name":                 threat.category,
+		"FWLink":                        fmt.Sprintf("https://go.microsoft.com/fwlink/?linkid=37020&name=%s&threatid=%d&enterprise=1", threat.name, threat.threatID),
+		"Status Code":                   statusCode,
+		"Status Description":            status,
+		"State":                         1,
+		"Source ID":                     sourceID,
+		"Source Name":                   sourceName,
+		"Process Name":                  g.RandomChoice([]string{"C:\\Windows\\explorer.exe", "C:\\Program Files\\Google\\Chrome\\Application\\chrome.exe", "C:\\Windows\\System32\\WindowsPowerShell\\v1.0\\powershell.exe", "Unknown"}),
+		"Detection User":                fmt.Sprintf("%s\\%s", g.RandomDomain(), user),
+		"Path":                          path,
+		"Origin ID":                     originID,
+		"Origin Name":                   originName,
+		"Execution ID":                  1,
+		"Execution Name":                "Suspended",
+		"Type ID":                       0,
+		"Type Name":                     "Concrete",
+		"Pre Execution Status":          0,
+		"Action ID":                     actionID,
+		"Action Name":                   actionName,
+		"Error Code":                    "0x00000000",
+		"Error Description":             "The operation completed successfully. ",
+		"Post Clean Status":             0,
+		"Additional Actions ID":         0,
+		"Additional Actions String":     "No additional actions required",
+		"Remediation User":              "NT AUTHORITY\\SYSTEM",
+		"Security intelligence Version": fmt.Sprintf("AV: 1.419.%d.0, AS: 1.419.%[1]d.0, NIS: 1.419.%[1]d.0", g.RandomInt(100, 400)),
+		"Engine Version":                "AM: 1.1.24090.11, NIS: 1.1.24090.11",
+	}
+	if eventID == 1116 {
+		delete(fields, "Remediation User")
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	level := 3
+	if eventID == 1117 {
+		level = 4
+	}
+	return g.buildGenerated(eventID, level, now, fields)
+}
+
+// generate5007 creates a configuration change event. Most changes are routine
+// policy updates; some are the exclusions and protection toggles attackers
+// make before dropping tooling.
+func (g *WindowsDefenderAVGenerator) generate5007(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	const root = "HKLM\\SOFTWARE\\Microsoft\\Windows Defender"
+
+	changes := [][2]string{
+		{"", root + "\\Exclusions\\Paths\\C:\\ProgramData = 0x0"},
+		{"", root + "\\Exclusions\\Extensions\\.ps1 = 0x0"},
+		{"", root + "\\Exclusions\\Processes\\powershell.exe = 0x0"},
+		{root + "\\Real-Time Protection\\DisableRealtimeMonitoring = 0x0", root + "\\Real-Time Protection\\DisableRealtimeMonitoring = 0x1"},
+		{root + "\\Features\\TamperProtection = 0x5", root + "\\Features\\TamperProtection = 0x4"},
+		{root + "\\Scan\\ScheduleDay = 0x8", root + "\\Scan\\ScheduleDay = 0x0"},
+		{root + "\\Signature Updates\\SignatureUpdateInterval = 0x8", root + "\\Signature Updates\\SignatureUpdateInterval = 0x4"},
+		{root + "\\Spynet\\SubmitSamplesConsent = 0x1", root + "\\Spynet\\SubmitSamplesConsent = 0x3"},
+	}
+	change := changes[g.weightedIndex([]float64{8, 4, 4, 5, 3, 28, 28, 20})]
+
+	fields := map[string]interface{}{
+		"Product Name":    "Microsoft Defender Antivirus",
+		"Product Version": "4.18.24090.11",
+		"Old Value":       change[0],
+		"New Value":       change[1],
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return g.buildGenerated(5007, 4, now, fields)
+}
+
+// buildGenerated renders the event XML and wraps it as a generated event
+func (g *WindowsDefenderAVGenerator) buildGenerated(eventID, level int, timestamp time.Time, fields map[string]interface{}) (*models.GeneratedEvent, error) {
+	event := g.buildEvent(eventID, level, timestamp, fields)
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_defender_av",
+		EventID:    fmt.Sprintf("%d", eventID),
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:Microsoft-Windows-Windows Defender/Operational",
+	}, nil
+}
+
+// buildEvent creates the common Defender Operational Event structure
+func (g *WindowsDefenderAVGenerator) buildEvent(eventID, level int, timestamp time.Time, fields map[string]interface{}) WindowsEvent {
+	dataItems := make([]WindowsDataItem, 0)
+	for name, value := range fields {
+		dataItems = append(dataItems, WindowsDataItem{
+			Name:  name,
+			Value: fmt.Sprintf("%v", value),
+		})
+	}
+
+	return WindowsEvent{
+		Xmlns: "http://schemas.microsoft.com/win/2004/08/events/event",
+		System: WindowsEventSystem{
+			Provider: WindowsEventProvider{
+				Name: "Microsoft-Windows-Windows Defender",
+				Guid: "{11CD958A-C507-4EF3-B3F2-5FD9DFBD2C78}",
+			},
+			EventID:       eventID,
+			Version:       0,
+			Level:         level,
+			Task:          0,
+			Opcode:        0,
+			Keywords:      "0x8000000000000000",
+			TimeCreated:   WindowsTimeCreated{SystemTime: timestamp.Format("2006-01-02T15:04:05.000000000Z")},
+			EventRecordID: int64(g.RandomInt(100000, 99999999)),
+			Execution:     WindowsExecution{ProcessID: g.RandomInt(1000, 10000), ThreadID: g.RandomInt(100, 10000)},
+			Channel:       "Microsoft-Windows-Windows Defender/Operational",
+			Computer:      g.RandomFQDN(),
+			Security:      WindowsSecurity{UserID: "S-1-5-18"},
+		},
+		EventData: WindowsEventData{Data: dataItems},
+	}
+}
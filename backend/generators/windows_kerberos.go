@@ -0,0 +1,175 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// Kerberos ticket encryption types as logged in TicketEncryptionType
+const (
+	kerberosAES256 = "0x12"
+	kerberosAES128 = "0x11"
+	kerberosRC4    = "0x17" // Legacy; requested for TGS by Kerberoasting tools
+)
+
+// kerberosServiceAccounts are service accounts with SPNs, the usual
+// Kerberoasting targets
+var kerberosServiceAccounts = []string{"svc_sql", "svc_web", "svc_backup", "svc_sharepoint", "svc_exchange"}
+
+// domainController returns one of the pool domain's controllers, where
+// Kerberos and NTLM validation events are logged
+func (g *WindowsSecurityGenerator) domainController() string {
+	return fmt.Sprintf("DC%02d.%s", g.RandomInt(1, 2), Theme().ADDomain)
+}
+
+// kerberosRealm returns the pool domain as a Kerberos realm
+func kerberosRealm() string {
+	return strings.ToUpper(Theme().ADDomain)
+}
+
+// kerberosClient picks a pool user signing in from a Windows host
+func (g *WindowsSecurityGenerator) kerberosClient() (*EntityUser, *EntityHost) {
+	host := Entities.RandomHost(g.rnd, "windows")
+	return g.sessionUser(host), host
+}
+
+// generateKerberosTGT creates a Kerberos TGT request (4768). A few are
+// RC4 requests, as overpass-the-hash makes, or without pre-authentication,
+// as AS-REP roasting needs.
+func (g *WindowsSecurityGenerator) generateKerberosTGT(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	user, host := g.kerberosClient()
+	realm := kerberosRealm()
+
+	encType, preAuth, status := kerberosAES256, "2", "0x0"
+	switch roll := g.RandomInt(1, 100); {
+	case roll <= 3:
+		encType = kerberosRC4
+	case roll <= 5:
+		preAuth = "0"
+		encType = kerberosRC4
+	case roll <= 8:
+		encType, preAuth = "0xffffffff", "-"
+		status = g.RandomChoice([]string{"0x6", "0x12"}) // Unknown principal, account disabled
+	}
+
+	fields := map[string]interface{}{
+		"TargetUserName":       user.Username,
+		"TargetDomainName":     realm,
+		"TargetSid":            windowsUserSID(user),
+		"ServiceName":          "krbtgt",
+		"ServiceSid":           windowsDomainSID + "-502",
+		"TicketOptions":        "0x40810010",
+		"Status":               status,
+		"TicketEncryptionType": encType,
+		"PreAuthType":          preAuth,
+		"IpAddress":            "::ffff:" + host.IP,
+		"IpPort":               g.RandomInt(49152, 65535),
+		"CertIssuerName":       "",
+		"CertSerialNumber":     "",
+		"CertThumbprint":       "",
+	}
+	if status != "0x0" {
+		fields["TargetSid"], fields["ServiceSid"] = "S-1-0-0", "S-1-0-0"
+	}
+
+	return g.kerberosEvent(4768, 14339, status == "0x0", now, overrides, fields)
+}
+
+// generateKerberosTGS creates a Kerberos service ticket request (4769),
+// mostly for computer accounts. Some ask for a service account's ticket
+// with RC4, as Kerberoasting does, and a few carry the marks of a forged
+// golden ticket: an account outside the domain and a lowercase realm.
+func (g *WindowsSecurityGenerator) generateKerberosTGS(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	user, host := g.kerberosClient()
+	realm := kerberosRealm()
+	target := Entities.RandomHost(g.rnd, "windows")
+
+	userName, userRealm := user.Username+"@"+realm, realm
+	service, encType := strings.ToUpper(target.Hostname)+"$", g.RandomChoice([]string{kerberosAES256, kerberosAES256, kerberosAES256, kerberosAES128})
+	switch roll := g.RandomInt(1, 100); {
+	case roll <= 5:
+		service, encType = g.RandomChoice(kerberosServiceAccounts), kerberosRC4
+	case roll <= 15:
+		service = g.RandomChoice(kerberosServiceAccounts)
+	case roll <= 16:
+		userName = g.RandomChoice([]string{"Administrator", "krbtgt_admin", "svc_" + g.RandomString(6)}) + "@" + strings.ToLower(realm)
+		userRealm = strings.ToLower(realm)
+		encType = kerberosRC4
+	}
+
+	fields := map[string]interface{}{
+		"TargetUserName":       userName,
+		"TargetDomainName":     userRealm,
+		"ServiceName":          service,
+		"ServiceSid":           fmt.Sprintf("%s-%d", windowsDomainSID, g.RandomInt(1100, 9999)),
+		"TicketOptions":        g.RandomChoice([]string{"0x40810000", "0x40810000", "0x60810010", "0x40800000"}),
+		"TicketEncryptionType": encType,
+		"IpAddress":            "::ffff:" + host.IP,
+		"IpPort":               g.RandomInt(49152, 65535),
+		"Status":               "0x0",
+		"LogonGuid":            "{" + g.RandomGUID() + "}",
+		"TransmittedServices":  "-",
+	}
+
+	return g.kerberosEvent(4769, 14337, true, now, overrides, fields)
+}
+
+// generateKerberosPreAuthFailed creates a Kerberos pre-authentication
+// failure (4771), mostly a bad password, as password spraying leaves
+func (g *WindowsSecurityGenerator) generateKerberosPreAuthFailed(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	user, host := g.kerberosClient()
+
+	fields := map[string]interface{}{
+		"TargetUserName":   user.Username,
+		"TargetSid":        windowsUserSID(user),
+		"ServiceName":      "krbtgt/" + windowsNetBIOSDomain(),
+		"TicketOptions":    "0x40810010",
+		"Status":           g.WeightedChoice([]string{"0x18", "0x12", "0x25", "0x17"}, []float64{85, 6, 5, 4}), // Bad password, disabled, clock skew, expired password
+		"PreAuthType":      "2",
+		"IpAddress":        "::ffff:" + host.IP,
+		"IpPort":           g.RandomInt(49152, 65535),
+		"CertIssuerName":   "",
+		"CertSerialNumber": "",
+		"CertThumbprint":   "",
+	}
+
+	return g.kerberosEvent(4771, 14339, false, now, overrides, fields)
+}
+
+// generateNTLMValidation creates an NTLM credential validation (4776)
+// logged by a domain controller
+func (g *WindowsSecurityGenerator) generateNTLMValidation(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	user, host := g.kerberosClient()
+	// Bad password, no such user, locked out
+	status := g.WeightedChoice([]string{"0x0", "0xc000006a", "0xc0000064", "0xc0000234"}, []float64{88, 7, 4, 1})
+
+	fields := map[string]interface{}{
+		"PackageName":    "MICROSOFT_AUTHENTICATION_PACKAGE_V1_0",
+		"TargetUserName": user.Username,
+		"Workstation":    host.Hostname,
+		"Status":         status,
+	}
+
+	return g.kerberosEvent(4776, 14336, status == "0x0", now, overrides, fields)
+}
+
+// kerberosEvent wraps account logon fields in a Security event logged by a
+// domain controller under the given audit subcategory
+func (g *WindowsSecurityGenerator) kerberosEvent(eventID, task int, success bool, now time.Time, overrides, fields map[string]interface{}) (*models.GeneratedEvent, error) {
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(eventID, now, fields)
+	event.System.Task = task
+	event.System.Computer = g.domainController()
+	if !success {
+		event.System.Keywords = "0x8010000000000000"
+	}
+	return g.securityEvent(eventID, now, event, fields)
+}
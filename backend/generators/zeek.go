@@ -1,8 +1,8 @@
 package generators
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -157,7 +157,7 @@ func (g *ZeekGenerator) generateConn(overrides map[string]interface{}) (*models.
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -191,7 +191,7 @@ func (g *ZeekGenerator) generateDNS(overrides map[string]interface{}) (*models.G
 		"proto":     "udp",
 		"trans_id":  g.RandomInt(1, 65535),
 		"rtt":       float64(g.RandomInt(1, 100)) / 1000,
-		"query":     g.RandomChoice(domains),
+		"query":     g.InjectIOC(models.IOCTypeDomain, g.RandomChoice(domains)),
 		"qclass":    1,
 		"qclass_name": "C_INTERNET",
 		"qtype":     qtype,
@@ -209,7 +209,7 @@ func (g *ZeekGenerator) generateDNS(overrides map[string]interface{}) (*models.G
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -228,13 +228,8 @@ func (g *ZeekGenerator) generateHTTP(overrides map[string]interface{}) (*models.
 
 	hosts := []string{"www.example.com", "api.service.com", "cdn.website.net", "login.app.io"}
 	methods := []string{"GET", "POST", "PUT", "DELETE", "HEAD", "OPTIONS"}
-	uris := []string{"/", "/api/v1/users", "/login", "/api/data", "/static/js/app.js", "/images/logo.png"}
-	userAgents := []string{
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
-		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)",
-		"curl/7.79.1",
-		"python-requests/2.28.0",
-	}
+	uri := g.RandomURL(g.WeightedChoice([]string{webRequestPage, webRequestAsset, webRequestAPI}, []float64{35, 40, 25}))
+	uri, userAgent, _ := g.webAttackRequest(uri, g.randomClientUserAgent())
 	statusCodes := []int{200, 201, 204, 301, 302, 400, 401, 403, 404, 500}
 
 	event := map[string]interface{}{
@@ -246,11 +241,11 @@ func (g *ZeekGenerator) generateHTTP(overrides map[string]interface{}) (*models.
 		"id.resp_p":        g.RandomChoice([]string{"80", "443", "8080", "8443"}),
 		"trans_depth":      1,
 		"method":           g.RandomChoice(methods),
-		"host":             g.RandomChoice(hosts),
-		"uri":              g.RandomChoice(uris),
+		"host":             g.InjectIOC(models.IOCTypeDomain, g.RandomChoice(hosts)),
+		"uri":              uri,
 		"referrer":         "-",
 		"version":          "1.1",
-		"user_agent":       g.RandomChoice(userAgents),
+		"user_agent":       userAgent,
 		"origin":           "-",
 		"request_body_len": g.RandomInt(0, 10000),
 		"response_body_len": g.RandomInt(0, 100000),
@@ -264,7 +259,7 @@ func (g *ZeekGenerator) generateHTTP(overrides map[string]interface{}) (*models.
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -282,11 +277,16 @@ func (g *ZeekGenerator) generateSSL(overrides map[string]interface{}) (*models.G
 	uid := g.randomUID()
 
 	serverNames := []string{"www.google.com", "api.microsoft.com", "github.com", "aws.amazon.com", "login.salesforce.com"}
-	versions := []string{"TLSv12", "TLSv13"}
-	ciphers := []string{
-		"TLS_AES_256_GCM_SHA384",
-		"TLS_CHACHA20_POLY1305_SHA256",
-		"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	session := g.randomTLSSession()
+	serverName := g.InjectIOC(models.IOCTypeDomain, g.RandomChoice(serverNames))
+	cert := g.serverCertificate(serverName, timestamp)
+	chain := make([]string, cert.ChainLen)
+	for i := range chain {
+		chain[i] = g.randomFUID()
+	}
+	curve := "x25519"
+	if session.client.curves[0] != 29 {
+		curve = "secp256r1"
 	}
 
 	event := map[string]interface{}{
@@ -296,21 +296,24 @@ func (g *ZeekGenerator) generateSSL(overrides map[string]interface{}) (*models.G
 		"id.orig_p":      g.RandomPort(),
 		"id.resp_h":      g.RandomIPv4External(),
 		"id.resp_p":      443,
-		"version":        g.RandomChoice(versions),
-		"cipher":         g.RandomChoice(ciphers),
-		"curve":          g.RandomChoice([]string{"x25519", "secp256r1", "secp384r1"}),
-		"server_name":    g.RandomChoice(serverNames),
+		"version":        "TLSv" + strings.Replace(session.version, ".", "", 1),
+		"cipher":         session.CipherName(),
+		"curve":          curve,
+		"server_name":    serverName,
 		"resumed":        g.RandomInt(0, 1) == 1,
 		"established":    true,
-		"cert_chain_fuids": []string{g.randomFUID()},
+		"cert_chain_fuids": chain,
 		"client_cert_chain_fuids": []string{},
-		"subject":        fmt.Sprintf("CN=%s,O=Example Corp,L=San Francisco,ST=California,C=US", g.RandomChoice(serverNames)),
-		"issuer":         "CN=DigiCert TLS RSA SHA256 2020 CA1,O=DigiCert Inc,C=US",
-		"validation_status": "ok",
+		"subject":        cert.Subject.String(),
+		"issuer":         cert.Issuer.String(),
+		"validation_status": cert.ValidationStatus(),
+		"ja3":            session.ja3Hash,
+		"ja3s":           session.ja3sHash,
+		"ja4":            session.ja4,
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -327,11 +330,10 @@ func (g *ZeekGenerator) generateFiles(overrides map[string]interface{}) (*models
 	timestamp := time.Now()
 	fuid := g.randomFUID()
 
-	mimeTypes := []string{
-		"application/x-dosexec", "application/pdf", "application/zip",
-		"text/html", "image/png", "application/javascript",
+	file := g.RandomFile(FileDocument, FileArchive, FileExecutable, FileImage)
+	if g.rnd.float64() < 0.05 {
+		file = g.RandomMaliciousFile(FileDocument, FileArchive, FileExecutable)
 	}
-	filenames := []string{"update.exe", "document.pdf", "archive.zip", "invoice.docx", "image.png", "script.js"}
 
 	event := map[string]interface{}{
 		"ts":          timestamp.Unix(),
@@ -342,22 +344,22 @@ func (g *ZeekGenerator) generateFiles(overrides map[string]interface{}) (*models
 		"source":      g.RandomChoice([]string{"HTTP", "FTP", "SMTP", "SMB"}),
 		"depth":       0,
 		"analyzers":   []string{g.RandomChoice([]string{"SHA256", "MD5", "PE", "EXTRACT"})},
-		"mime_type":   g.RandomChoice(mimeTypes),
-		"filename":    g.RandomChoice(filenames),
+		"mime_type":   file.MIMEType,
+		"filename":    file.Name,
 		"duration":    float64(g.RandomInt(1, 60)),
 		"local_orig":  false,
 		"is_orig":     false,
-		"seen_bytes":  g.RandomInt(1000, 10000000),
-		"total_bytes": g.RandomInt(1000, 10000000),
+		"seen_bytes":  file.Size,
+		"total_bytes": file.Size,
 		"missing_bytes": 0,
 		"overflow_bytes": 0,
 		"timedout":    false,
-		"sha256":      g.RandomString(64),
-		"md5":         g.RandomString(32),
+		"sha256":      g.FileHash(file, models.IOCTypeSHA256),
+		"md5":         g.FileHash(file, models.IOCTypeMD5),
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
@@ -412,7 +414,7 @@ func (g *ZeekGenerator) generateNotice(overrides map[string]interface{}) (*model
 	}
 
 	fields := g.ApplyOverrides(event, overrides)
-	rawEvent, _ := json.MarshalIndent(fields, "", "  ")
+	rawEvent, _ := g.marshalRawJSON(fields)
 
 	return &models.GeneratedEvent{
 		ID:         uuid.New().String(),
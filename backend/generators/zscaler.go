@@ -0,0 +1,262 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"siem-event-generator/models"
+)
+
+// ZscalerGenerator generates Zscaler Internet Access (ZIA) web proxy logs
+type ZscalerGenerator struct {
+	BaseGenerator
+}
+
+func init() {
+	Register(&ZscalerGenerator{})
+}
+
+// GetEventType returns the event type for Zscaler ZIA
+func (g *ZscalerGenerator) GetEventType() models.EventType {
+	return models.EventType{
+		ID:          "zscaler_zia",
+		Name:        "Zscaler ZIA Web Proxy",
+		Category:    "web",
+		Description: "Zscaler Internet Access web logs from an NSS feed in Splunk CIM key=value format",
+		EventIDs:    []string{"Allowed", "Blocked"},
+	}
+}
+
+// GetTemplates returns available templates for Zscaler ZIA events
+func (g *ZscalerGenerator) GetTemplates() []models.EventTemplate {
+	return []models.EventTemplate{
+		{
+			ID:          "allowed",
+			Name:        "Allowed Browsing",
+			Category:    "zscaler_zia",
+			EventID:     "Allowed",
+			Format:      "kv",
+			Description: "Allowed web transaction to a business or general browsing site",
+		},
+		{
+			ID:          "blocked",
+			Name:        "URL Category Blocked",
+			Category:    "zscaler_zia",
+			EventID:     "Blocked",
+			Format:      "kv",
+			Description: "Transaction blocked by URL filtering policy (gambling, P2P, anonymizers)",
+		},
+		{
+			ID:          "threat",
+			Name:        "Threat Blocked",
+			Category:    "zscaler_zia",
+			EventID:     "Blocked",
+			Format:      "kv",
+			Description: "Transaction blocked by Advanced Threat Protection or Malware Protection",
+		},
+		{
+			ID:          "cloud_upload",
+			Name:        "Cloud App Upload",
+			Category:    "zscaler_zia",
+			EventID:     "Allowed",
+			Format:      "kv",
+			Description: "File upload to a cloud storage application",
+		},
+	}
+}
+
+// zscalerSite is a destination with its ZIA URL and cloud app classification
+type zscalerSite struct {
+	host, path                            string
+	category, superCategory, class        string
+	app, appClass                         string
+	threatName, threatCategory, riskScore string
+}
+
+var (
+	zscalerAllowedSites = []zscalerSite{
+		{host: "www.google.com", path: "/search?q=quarterly+report+template", category: "Web Search", superCategory: "Internet Communication", class: "Business Use", app: "Google Search", appClass: "General Browsing"},
+		{host: "outlook.office365.com", path: "/mail/inbox", category: "Web Mail", superCategory: "Internet Communication", class: "Business Use", app: "Outlook", appClass: "Webmail"},
+		{host: "teams.microsoft.com", path: "/_#/conversations", category: "Professional Services", superCategory: "Business and Economy", class: "Business Use", app: "Microsoft Teams", appClass: "Instant Messaging"},
+		{host: "github.com", path: "/example-corp/platform/pulls", category: "Professional Services", superCategory: "Information Technology", class: "Business Use", app: "GitHub", appClass: "Productivity"},
+		{host: "www.linkedin.com", path: "/feed/", category: "Social Networking", superCategory: "Social and Family Issues", class: "General Surfing", app: "LinkedIn", appClass: "Social Networking"},
+		{host: "www.youtube.com", path: "/watch?v=dQw4w9WgXcQ", category: "Streaming Media", superCategory: "Internet Communication", class: "Bandwidth Loss", app: "YouTube", appClass: "Streaming Media"},
+		{host: "www.nytimes.com", path: "/section/business", category: "News and Media", superCategory: "News and Media", class: "General Surfing", app: "General Browsing", appClass: "General Browsing"},
+		{host: "slack.com", path: "/api/conversations.history", category: "Professional Services", superCategory: "Business and Economy", class: "Business Use", app: "Slack", appClass: "Instant Messaging"},
+	}
+	zscalerBlockedSites = []zscalerSite{
+		{host: "www.bet365.com", path: "/", category: "Gambling", superCategory: "Gambling", class: "Legal Liability"},
+		{host: "thepiratebay.org", path: "/search.php?q=office", category: "Peer-to-Peer Site", superCategory: "Internet Communication", class: "Bandwidth Loss"},
+		{host: "www.hidemyass.com", path: "/proxy", category: "Anonymizer", superCategory: "Security", class: "Security Risk"},
+		{host: "www.miniclip.com", path: "/games", category: "Games", superCategory: "Entertainment/Recreation", class: "General Surfing"},
+	}
+	zscalerThreatSites = []zscalerSite{
+		{host: "login-microsoftonline.secure-verify.xyz", path: "/common/oauth2/authorize", category: "Phishing", superCategory: "Security", class: "Security Risk", threatName: "HTML.Phish.Microsoft.Gen", threatCategory: "Phishing", riskScore: "100"},
+		{host: "cdn-update.top", path: "/dl/invoice_8841.exe", category: "Malicious Content", superCategory: "Security", class: "Security Risk", threatName: "Win32.Trojan.Emotet", threatCategory: "Virus", riskScore: "100"},
+		{host: "api.telemetry-sync.net", path: "/v2/beacon", category: "Botnet Callback", superCategory: "Security", class: "Security Risk", threatName: "CobaltStrike.Beacon", threatCategory: "Botnet Callback", riskScore: "90"},
+		{host: "free-crypto-miner.io", path: "/miner.js", category: "Cryptomining", superCategory: "Security", class: "Security Risk", threatName: "JS.CoinMiner.Generic", threatCategory: "Cryptomining", riskScore: "75"},
+	}
+	zscalerStorageSites = []zscalerSite{
+		{host: "www.dropbox.com", path: "/upload", category: "Online and Other Storage", superCategory: "Information Technology", class: "General Surfing", app: "Dropbox", appClass: "Online and Other Storage"},
+		{host: "drive.google.com", path: "/upload/drive/v3/files?uploadType=resumable", category: "Online and Other Storage", superCategory: "Information Technology", class: "General Surfing", app: "Google Drive", appClass: "Online and Other Storage"},
+		{host: "wetransfer.com", path: "/api/v4/transfers", category: "File Host", superCategory: "Information Technology", class: "General Surfing", app: "WeTransfer", appClass: "Online and Other Storage"},
+	}
+)
+
+// zscalerFieldOrder is the NSS feed output format's field order
+var zscalerFieldOrder = []string{
+	"datetime", "reason", "event_id", "protocol", "action", "transactionsize", "responsesize", "requestsize",
+	"urlcategory", "serverip", "clienttranstime", "requestmethod", "refererURL", "useragent", "product",
+	"location", "ClientIP", "status", "user", "url", "vendor", "hostname", "clientpublicIP", "threatcategory",
+	"threatname", "filetype", "appname", "pagerisk", "department", "urlsupercategory", "appclass", "dlpengine",
+	"urlclass", "threatclass", "dlpdictionaries", "fileclass", "bwthrottle", "servertranstime", "contenttype",
+	"unscannabletype", "deviceowner", "devicehostname",
+}
+
+// Generate creates a Zscaler ZIA web log event
+func (g *ZscalerGenerator) Generate(templateID string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	switch templateID {
+	case "allowed":
+		site := zscalerAllowedSites[g.RandomZipf(len(zscalerAllowedSites), 1.1)]
+		return g.buildEvent(site, "Allowed", "Allowed", overrides)
+	case "blocked":
+		site := zscalerBlockedSites[g.RandomInt(0, len(zscalerBlockedSites)-1)]
+		return g.buildEvent(site, "Blocked", "Not allowed to browse this category", overrides)
+	case "threat":
+		site := zscalerThreatSites[g.RandomInt(0, len(zscalerThreatSites)-1)]
+		reason := "Malware"
+		if site.threatCategory == "Phishing" || site.threatCategory == "Botnet Callback" || site.threatCategory == "Cryptomining" {
+			reason = "Reputation block outbound request: " + strings.ToLower(site.threatCategory) + " site"
+		}
+		return g.buildEvent(site, "Blocked", reason, overrides)
+	case "cloud_upload":
+		site := zscalerStorageSites[g.RandomInt(0, len(zscalerStorageSites)-1)]
+		return g.buildEvent(site, "Allowed", "Allowed", overrides)
+	default:
+		return nil, fmt.Errorf("unknown template ID: %s", templateID)
+	}
+}
+
+// buildEvent renders a transaction to site by a pool user from their workstation
+func (g *ZscalerGenerator) buildEvent(site zscalerSite, action, reason string, overrides map[string]interface{}) (*models.GeneratedEvent, error) {
+	now := time.Now().UTC()
+	user := Entities.RandomUser(g.rnd)
+	device := Entities.HostForUser(g.rnd, user.Username)
+
+	method := "GET"
+	requestSize := g.RandomInt(400, 2500)
+	responseSize := int(g.RandomLogNormal(25000, 1.3))
+	fileType, fileClass, contentType := "None", "None", "text/html"
+	status := "200"
+
+	switch {
+	case site.app != "" && strings.Contains(site.appClass, "Storage"):
+		method = "POST"
+		requestSize = int(g.RandomLogNormal(8_000_000, 1.5))
+		responseSize = g.RandomInt(300, 2000)
+		fileType = g.RandomChoice([]string{"ZIP", "XLSX", "PDF", "DOCX", "CSV"})
+		fileClass = map[string]string{"ZIP": "Archive Files", "XLSX": "Microsoft Office", "PDF": "Document", "DOCX": "Microsoft Office", "CSV": "Document"}[fileType]
+		contentType = "application/octet-stream"
+	case strings.HasSuffix(site.path, ".exe"):
+		fileType, fileClass, contentType = "EXE", "Executable", "application/x-msdownload"
+	case strings.HasSuffix(site.path, ".js"):
+		contentType = "application/javascript"
+	}
+	if action == "Blocked" {
+		status = "403"
+		responseSize = g.RandomInt(1200, 4000)
+	}
+
+	threatName, threatCategory, threatClass, risk := "None", "None", "None", "0"
+	if site.threatName != "" {
+		threatName, threatCategory, risk = site.threatName, site.threatCategory, site.riskScore
+		threatClass = "Advanced Threat"
+		if threatCategory == "Virus" {
+			threatClass = "Virus"
+		}
+	}
+	appName, appClass := site.app, site.appClass
+	if appName == "" {
+		appName, appClass = "General Browsing", "General Browsing"
+	}
+
+	clientTrans := int(g.RandomLogNormal(80, 0.8))
+	fields := map[string]interface{}{
+		"datetime":         now.Format("Mon Jan 02 15:04:05 2006"),
+		"reason":           reason,
+		"event_id":         fmt.Sprintf("%d%09d", g.RandomInt(700000000, 799999999), g.RandomInt(0, 999999999)),
+		"protocol":         g.WeightedChoice([]string{"HTTPS", "HTTP"}, []float64{92, 8}),
+		"action":           action,
+		"transactionsize":  requestSize + responseSize,
+		"responsesize":     responseSize,
+		"requestsize":      requestSize,
+		"urlcategory":      site.category,
+		"serverip":         g.RandomIPv4External(),
+		"clienttranstime":  clientTrans,
+		"requestmethod":    method,
+		"refererURL":       g.WeightedChoice([]string{"None", "https://www.google.com/", "https://" + site.host + "/"}, []float64{50, 25, 25}),
+		"useragent":        g.userAgent(device),
+		"product":          "NSS",
+		"location":         g.WeightedChoice([]string{"Road Warrior", "HQ-NewYork", "Branch-London", "Branch-Singapore"}, []float64{45, 30, 15, 10}),
+		"ClientIP":         device.IP,
+		"status":           status,
+		"user":             user.Email,
+		"url":              site.host + site.path,
+		"vendor":           "Zscaler",
+		"hostname":         site.host,
+		"clientpublicIP":   g.RandomIPv4External(),
+		"threatcategory":   threatCategory,
+		"threatname":       threatName,
+		"filetype":         fileType,
+		"appname":          appName,
+		"pagerisk":         risk,
+		"department":       user.Department,
+		"urlsupercategory": site.superCategory,
+		"appclass":         appClass,
+		"dlpengine":        "None",
+		"urlclass":         site.class,
+		"threatclass":      threatClass,
+		"dlpdictionaries":  "None",
+		"fileclass":        fileClass,
+		"bwthrottle":       "NO",
+		"servertranstime":  g.RandomInt(5, clientTrans+5),
+		"contenttype":      contentType,
+		"unscannabletype":  "None",
+		"deviceowner":      user.Username,
+		"devicehostname":   device.Hostname,
+	}
+
+	fields = g.ApplyOverrides(fields, overrides)
+
+	pairs := make([]string, 0, len(zscalerFieldOrder))
+	for _, k := range zscalerFieldOrder {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, fields[k]))
+	}
+
+	return &models.GeneratedEvent{
+		ID:         uuid.New().String(),
+		Type:       "zscaler_zia",
+		EventID:    action,
+		Timestamp:  now,
+		RawEvent:   strings.Join(pairs, "\t"),
+		Fields:     fields,
+		Sourcetype: "zscalernss-web",
+	}, nil
+}
+
+// userAgent returns a browser user agent matching the device platform
+func (g *ZscalerGenerator) userAgent(device *EntityHost) string {
+	switch device.Platform {
+	case "darwin":
+		return "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	case "linux":
+		return "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0"
+	}
+	return g.WeightedChoice([]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.0.0",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	}, []float64{55, 45})
+}
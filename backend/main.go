@@ -1,11 +1,22 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"siem-event-generator/api"
 	"siem-event-generator/api/handlers"
+	"siem-event-generator/cluster"
+	"siem-event-generator/delivery"
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+	"siem-event-generator/secrets"
+	"siem-event-generator/storage"
 )
 
 func main() {
@@ -23,6 +34,32 @@ func main() {
 		log.Printf("WARNING: could not create config dir %s: %v", configDir, err)
 	}
 
+	// Destination credentials are encrypted with this key on disk
+	if err := secrets.Init(configDir); err != nil {
+		log.Fatalf("Failed to load secrets key: %v", err)
+	}
+
+	// Configuration is kept in SQLite or PostgreSQL; JSON files from
+	// earlier versions are migrated on first start
+	if err := handlers.OpenStore(storage.ConfigFromEnv(configDir)); err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+
+	// Generator plugins register their event types before anything that
+	// refers to event types is loaded
+	if dir := os.Getenv("GENERATOR_PLUGIN_DIR"); dir != "" {
+		generators.Plugins.SetDir(dir)
+		plugins, err := generators.Plugins.Scan()
+		if err != nil {
+			log.Printf("WARNING: failed to scan generator plugins: %v", err)
+		}
+		for _, p := range plugins {
+			if p.Status == models.PluginFailed {
+				log.Printf("WARNING: generator plugin %s: %s", p.ID, p.Error)
+			}
+		}
+	}
+
 	// Load persisted configurations
 	if err := handlers.LoadDestinations(); err != nil {
 		log.Printf("WARNING: failed to load destinations: %v", err)
@@ -33,10 +70,110 @@ func main() {
 		log.Printf("WARNING: failed to load templates: %v", err)
 	}
 
+	if err := handlers.LoadScenarios(); err != nil {
+		log.Printf("WARNING: failed to load scenarios: %v", err)
+	}
+
+	if err := handlers.LoadGeoPolicy(); err != nil {
+		log.Printf("WARNING: failed to load geo policy: %v", err)
+	}
+
+	if err := handlers.LoadTheme(); err != nil {
+		log.Printf("WARNING: failed to load theme: %v", err)
+	}
+
+	if err := handlers.LoadNameProfile(); err != nil {
+		log.Printf("WARNING: failed to load name profile: %v", err)
+	}
+
+	if err := handlers.LoadIOCs(); err != nil {
+		log.Printf("WARNING: failed to load IOCs: %v", err)
+	}
+	handlers.StartIOCFeedPoller()
+
+	if err := handlers.LoadAnonymization(); err != nil {
+		log.Printf("WARNING: failed to load anonymization rules: %v", err)
+	}
+
+	if dir := os.Getenv("HOOK_PLUGIN_DIR"); dir != "" {
+		if err := delivery.LoadHookPlugins(dir); err != nil {
+			log.Fatalf("Failed to load hook plugins: %v", err)
+		}
+	}
+	if err := handlers.LoadHooks(); err != nil {
+		log.Printf("WARNING: failed to load event hooks: %v", err)
+	}
+
+	if err := handlers.LoadPresets(); err != nil {
+		log.Printf("WARNING: failed to load override presets: %v", err)
+	}
+
+	if err := handlers.LoadCampaigns(); err != nil {
+		log.Printf("WARNING: failed to load campaigns: %v", err)
+	}
+
+	if err := handlers.LoadNotificationChannels(); err != nil {
+		log.Printf("WARNING: failed to load notification channels: %v", err)
+	}
+
+	if err := handlers.LoadPerformance(); err != nil {
+		log.Printf("WARNING: failed to load performance settings: %v", err)
+	}
+
+	if err := handlers.LoadServerLimits(); err != nil {
+		log.Printf("WARNING: failed to load server limits: %v", err)
+	}
+	if err := handlers.LoadDetectionBackends(); err != nil {
+		log.Printf("WARNING: failed to load detection backends: %v", err)
+	}
+	if err := handlers.LoadCloudTrailConfig(); err != nil {
+		log.Printf("WARNING: failed to load CloudTrail settings: %v", err)
+	}
+	if err := handlers.LoadPKIConfig(); err != nil {
+		log.Printf("WARNING: failed to load certificate settings: %v", err)
+	}
+	if err := handlers.LoadProcessConfig(); err != nil {
+		log.Printf("WARNING: failed to load process command line settings: %v", err)
+	}
+	if err := handlers.LoadWebConfig(); err != nil {
+		log.Printf("WARNING: failed to load web request settings: %v", err)
+	}
+
+	handlers.StartStatsRecorder()
+
+	// Coordinators split noise runs across the workers registered with them
+	clusterConfig, err := cluster.ConfigFromEnv(port)
+	if err != nil {
+		log.Fatalf("Invalid cluster configuration: %v", err)
+	}
+	handlers.StartCluster(clusterConfig)
+
+	// Pick up the noise run and scenarios that were running at shutdown
+	handlers.ResumeStreams()
+
 	router := api.SetupRouter()
+	srv := &http.Server{Addr: ":" + port, Handler: router}
+
+	go func() {
+		log.Printf("SIEM Event Generator API starting on port %s", port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// On SIGTERM stop taking requests, let in-flight ones finish, then save
+	// and stop running streams so the next start resumes them
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, os.Interrupt)
+	<-quit
+	log.Printf("Shutting down")
 
-	log.Printf("SIEM Event Generator API starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("WARNING: requests still in flight at shutdown: %v", err)
 	}
+	handlers.Shutdown()
+	generators.Plugins.Close()
+	log.Printf("Shutdown complete")
 }
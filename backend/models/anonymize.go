@@ -0,0 +1,34 @@
+package models
+
+// Anonymization actions applied to sensitive fields
+const (
+	AnonymizeMask     = "mask"     // Keep the value's shape, hide most characters
+	AnonymizeHash     = "hash"     // Salted HMAC-SHA256, stable across events
+	AnonymizeTokenize = "tokenize" // Random token, mapped back to the value in memory
+	AnonymizeRedact   = "redact"   // Replace with a fixed placeholder
+)
+
+// AnonymizationRule marks a field as sensitive. Rules can be scoped to an
+// event type and to one template within it.
+type AnonymizationRule struct {
+	EventType      string `json:"event_type,omitempty"`     // Empty matches every event type
+	EventID        string `json:"event_id,omitempty"`       // Template event ID; empty matches every template
+	Field          string `json:"field" binding:"required"` // Field name or dot path, e.g. userIdentity.userName
+	Action         string `json:"action,omitempty"`         // mask (default), hash, tokenize or redact
+	Classification string `json:"classification,omitempty"` // Free-form label, e.g. pii or confidential
+}
+
+// AnonymizationConfig controls masking of sensitive fields before events
+// are sent to a destination
+type AnonymizationConfig struct {
+	Enabled bool                `json:"enabled"`
+	Salt    string              `json:"salt,omitempty"` // HMAC key for the hash action; generated when empty
+	Rules   []AnonymizationRule `json:"rules"`
+}
+
+// AnonymizationPreview shows an event before and after anonymization
+type AnonymizationPreview struct {
+	Original   *GeneratedEvent `json:"original"`
+	Anonymized *GeneratedEvent `json:"anonymized"`
+	Fields     []string        `json:"fields"` // Fields that were anonymized
+}
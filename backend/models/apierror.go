@@ -0,0 +1,100 @@
+package models
+
+import "net/http"
+
+// APIError is the body of every error response. Code is stable, so clients
+// can branch on it; the message is for people and may change.
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error"`           // Message again, for clients that read only error
+	Field   string `json:"field,omitempty"` // Request field at fault, when there is one
+	Hint    string `json:"hint,omitempty"`  // What to do about it
+
+	FieldErrors []FieldError `json:"field_errors,omitempty"` // Per override field, with INVALID_OVERRIDES
+}
+
+// API error codes
+const (
+	CodeInvalidRequest         = "INVALID_REQUEST"
+	CodeValidationFailed       = "VALIDATION_FAILED"
+	CodeInvalidOverrides       = "INVALID_OVERRIDES"
+	CodeInvalidSigmaRule       = "INVALID_SIGMA_RULE"
+	CodeNotFound               = "NOT_FOUND"
+	CodeDestinationNotFound    = "DESTINATION_NOT_FOUND"
+	CodeTemplateNotFound       = "TEMPLATE_NOT_FOUND"
+	CodeEventTypeNotFound      = "EVENT_TYPE_NOT_FOUND"
+	CodePresetNotFound         = "PRESET_NOT_FOUND"
+	CodeScenarioNotFound       = "SCENARIO_NOT_FOUND"
+	CodeCampaignNotFound       = "CAMPAIGN_NOT_FOUND"
+	CodeRunNotFound            = "RUN_NOT_FOUND"
+	CodeBuiltinTemplate        = "BUILTIN_TEMPLATE"
+	CodeSchemaVersionMismatch  = "SCHEMA_VERSION_MISMATCH"
+	CodeConflict               = "CONFLICT"
+	CodeNoiseRunning           = "NOISE_ALREADY_RUNNING"
+	CodeNoiseNotRunning        = "NOISE_NOT_RUNNING"
+	CodeScenarioNotRunning     = "SCENARIO_NOT_RUNNING"
+	CodeClusterRole            = "CLUSTER_ROLE_MISMATCH"
+	CodeNoClusterWorkers       = "NO_CLUSTER_WORKERS"
+	CodeUnauthorized           = "UNAUTHORIZED"
+	CodeIdempotencyKeyInUse    = "IDEMPOTENCY_KEY_IN_USE"
+	CodeIdempotencyKeyReused   = "IDEMPOTENCY_KEY_REUSED"
+	CodeServerLimitReached     = "SERVER_LIMIT_REACHED"
+	CodeDestinationUnreachable = "DESTINATION_UNREACHABLE"
+	CodeDestinationAuthFailed  = "DESTINATION_AUTH_FAILED"
+	CodeFeedUnreachable        = "FEED_UNREACHABLE"
+	CodeStorageUnavailable     = "STORAGE_UNAVAILABLE"
+	CodeInternal               = "INTERNAL_ERROR"
+)
+
+// ErrorCode describes an API error code for the catalog
+type ErrorCode struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"` // HTTP status of error responses with the code
+	Description string `json:"description"`
+	Hint        string `json:"hint,omitempty"` // Given with the error unless it has a better one
+}
+
+// ErrorCatalog lists every code the API answers with
+var ErrorCatalog = []ErrorCode{
+	{CodeInvalidRequest, http.StatusBadRequest, "The body, query or path could not be read", "Check the request against the API docs; field names the part at fault"},
+	{CodeValidationFailed, http.StatusBadRequest, "A field has a value the API does not accept", "Fix the value of field, or what the message names"},
+	{CodeInvalidOverrides, http.StatusBadRequest, "Overrides name unknown fields or give them the wrong type", "field_errors has a message per override; GET /api/templates/:id/schema lists the fields"},
+	{CodeInvalidSigmaRule, http.StatusBadRequest, "The Sigma rule could not be read, or uses a feature generation does not support", "Aggregations, correlations and utf16 modifiers are not supported; set event_type for logsources with no mapped template"},
+	{CodeNotFound, http.StatusNotFound, "The item the path names does not exist", ""},
+	{CodeDestinationNotFound, http.StatusNotFound, "No destination has the ID given", "GET /api/destinations lists them"},
+	{CodeTemplateNotFound, http.StatusNotFound, "The event type has no template with the ID given", "GET /api/templates lists them"},
+	{CodeEventTypeNotFound, http.StatusNotFound, "No event type has the ID given", "GET /api/event-types lists them"},
+	{CodePresetNotFound, http.StatusNotFound, "No override preset has the ID or name given", "GET /api/presets lists them"},
+	{CodeScenarioNotFound, http.StatusNotFound, "No metric scenario has the ID given", "GET /api/scenarios lists them"},
+	{CodeCampaignNotFound, http.StatusNotFound, "No campaign has the ID given, or none is active", "GET /api/campaigns lists them"},
+	{CodeRunNotFound, http.StatusNotFound, "No recorded run has the ID given", "GET /api/runs lists them"},
+	{CodeBuiltinTemplate, http.StatusForbidden, "Built-in templates cannot be changed or deleted", "Create a template of your own instead"},
+	{CodeSchemaVersionMismatch, http.StatusConflict, "The template is no longer at the schema version the request pins", "GET /api/templates/changelog lists what changed"},
+	{CodeConflict, http.StatusConflict, "The change clashes with an item that already exists", ""},
+	{CodeNoiseRunning, http.StatusConflict, "A noise run is already going", "Stop it with POST /api/noise/stop, or change it with PUT /api/noise/config"},
+	{CodeNoiseNotRunning, http.StatusConflict, "No noise run is going", "Start one with POST /api/noise/start"},
+	{CodeScenarioNotRunning, http.StatusConflict, "The metric scenario is not running", ""},
+	{CodeClusterRole, http.StatusConflict, "The cluster request was sent to an instance in another cluster mode", "Check CLUSTER_MODE on both instances"},
+	{CodeNoClusterWorkers, http.StatusServiceUnavailable, "No cluster workers are registered with the coordinator", "Start workers with CLUSTER_MODE=worker and CLUSTER_COORDINATOR_URL set"},
+	{CodeUnauthorized, http.StatusUnauthorized, "The request lacks the cluster token", "Send the CLUSTER_TOKEN as a bearer token"},
+	{CodeIdempotencyKeyInUse, http.StatusConflict, "A request with the same Idempotency-Key is still running", "Wait for it to finish, then retry to get its response"},
+	{CodeIdempotencyKeyReused, http.StatusUnprocessableEntity, "The Idempotency-Key was already used for a request with another body", "Use a new key for each distinct request"},
+	{CodeServerLimitReached, http.StatusTooManyRequests, "Running the stream would go over a server limit", "Lower the rate, stop another stream or raise the limit with PUT /api/limits"},
+	{CodeDestinationUnreachable, http.StatusBadGateway, "A destination could not be set up or connected to; also the code of generate results and connection tests whose sends failed", "POST /api/destinations/:id/test shows which step fails"},
+	{CodeDestinationAuthFailed, http.StatusBadGateway, "A destination rejected its credentials; also the code of generate results and connection tests that hit it", "Check the destination's token, key or password"},
+	{CodeFeedUnreachable, http.StatusBadGateway, "An indicator feed could not be fetched or read", "Check the feed URL and credentials"},
+	{CodeStorageUnavailable, http.StatusServiceUnavailable, "The feature needs the database, which is not configured", "Run with STORAGE=sqlite (the default) or postgres"},
+	{CodeInternal, http.StatusInternalServerError, "The server failed to handle the request", "Check the server log"},
+}
+
+// LookupErrorCode returns the catalog entry for code; an unknown code
+// answers with 500
+func LookupErrorCode(code string) ErrorCode {
+	for _, e := range ErrorCatalog {
+		if e.Code == code {
+			return e
+		}
+	}
+	return ErrorCode{Code: code, Status: http.StatusInternalServerError}
+}
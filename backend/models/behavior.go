@@ -0,0 +1,44 @@
+package models
+
+// Ways an event can break its user's behavioral profile
+const (
+	AnomalyOffHours   = "off_hours"   // The user is active outside their working hours
+	AnomalyNewCountry = "new_country" // The user signs in from a country they never use
+	AnomalyNewHost    = "new_host"    // The user logs on to a host they never use
+	AnomalyVolume     = "volume"      // The user moves far more data than usual
+)
+
+// AnomalyKinds lists every anomaly kind
+var AnomalyKinds = []string{AnomalyOffHours, AnomalyNewCountry, AnomalyNewHost, AnomalyVolume}
+
+// UserProfile is the normal behavior of one user in the entity pool, which
+// UEBA products learn as the user's baseline
+type UserProfile struct {
+	Username     string   `json:"username"`
+	Timezone     string   `json:"timezone"`      // IANA zone the working hours are in
+	WorkStart    int      `json:"work_start"`    // Hour of the day work starts, 0-23
+	WorkEnd      int      `json:"work_end"`      // Hour of the day work ends, 1-24
+	Weekends     bool     `json:"weekends"`      // Works on Saturday and Sunday too
+	Country      string   `json:"country"`       // Country the user signs in from
+	SourceIPs    []string `json:"source_ips"`    // External IPs the user signs in from
+	Hosts        []string `json:"hosts"`         // Hosts the user logs on to
+	TypicalBytes int64    `json:"typical_bytes"` // Median bytes moved by one event
+}
+
+// BehaviorBaseline keeps each user's events within their profile: on shift,
+// from their country and hosts, moving their usual volume of data. At
+// anomaly_rate an event breaks the profile instead.
+type BehaviorBaseline struct {
+	AnomalyRate float64  `json:"anomaly_rate,omitempty"` // Share of user events that break the profile, 0-1
+	Anomalies   []string `json:"anomalies,omitempty"`    // Kinds to draw from; default all
+	Users       []string `json:"users,omitempty"`        // Only these users break their profile; default any
+	Label       bool     `json:"label,omitempty"`        // Add ueba_anomaly to the fields of events that break it
+}
+
+// AnomalyRequest asks a running stream to break a user's profile in its next
+// events that name a user, which are given to that user
+type AnomalyRequest struct {
+	Username string `json:"username" binding:"required"`
+	Kind     string `json:"kind,omitempty"`  // Anomaly kind; default a random one per event
+	Count    int    `json:"count,omitempty"` // Events to break, 1-1000; default 1
+}
@@ -0,0 +1,40 @@
+package models
+
+// PerformanceSettings controls the generation engine. In performance mode raw
+// JSON and XML events are rendered compactly instead of pretty-printed.
+type PerformanceSettings struct {
+	PerformanceMode bool `json:"performance_mode"`
+}
+
+// BenchmarkRequest selects the generators to benchmark and how long each one
+// runs. Empty EventTypes benchmarks every generator; Workers defaults to the
+// number of CPUs.
+type BenchmarkRequest struct {
+	EventTypes []string `json:"event_types,omitempty"`
+	DurationMs int      `json:"duration_ms,omitempty"` // Per generator, default 1000
+	Workers    int      `json:"workers,omitempty"`
+}
+
+// BenchmarkResult reports the maximum generation rate of each generator on
+// the current hardware. Events are generated only, never sent.
+type BenchmarkResult struct {
+	PerformanceMode bool                 `json:"performance_mode"`
+	Workers         int                  `json:"workers"`
+	CPUs            int                  `json:"cpus"`
+	DurationMs      int                  `json:"duration_ms"`
+	TotalEvents     int64                `json:"total_events"`
+	Generators      []GeneratorBenchmark `json:"generators"`
+}
+
+// GeneratorBenchmark is the sustained rate of one generator across all of
+// its templates
+type GeneratorBenchmark struct {
+	EventType       string  `json:"event_type"`
+	Templates       int     `json:"templates"`
+	Events          int64   `json:"events"`
+	Errors          int64   `json:"errors"`
+	EventsPerSecond float64 `json:"events_per_second"`
+	BytesPerSecond  float64 `json:"bytes_per_second"`
+	AvgEventBytes   float64 `json:"avg_event_bytes"`
+	Error           string  `json:"error,omitempty"` // First generation error
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes. In JSON it is a number or a string with a
+// unit: "5GB" and "500 MB" use powers of 1000, "5GiB" powers of 1024.
+type ByteSize int64
+
+var byteUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// ParseByteSize parses a size such as "5GB", "1.5 GiB" or "1024"
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	num, unit := s, ""
+	if i >= 0 {
+		num, unit = s[:i], strings.ToUpper(strings.TrimSpace(s[i:]))
+	}
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, unit)
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return ByteSize(n * float64(mult)), nil
+}
+
+// UnmarshalJSON accepts a byte count or a size string
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		size, err := ParseByteSize(s)
+		if err != nil {
+			return err
+		}
+		*b = size
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("size must be a number of bytes or a string such as \"5GB\"")
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
+// VolumeBudget caps how much a run sends to each destination; the run stops
+// at whichever limit is reached first. Bytes are the raw event length, the
+// way Splunk meters license usage.
+type VolumeBudget struct {
+	MaxEvents int64    `json:"max_events,omitempty"`
+	MaxBytes  ByteSize `json:"max_bytes,omitempty"`
+}
+
+// Budget exhaustion reasons
+const (
+	BudgetReasonEvents = "max_events"
+	BudgetReasonBytes  = "max_bytes"
+)
+
+// VolumeUsage reports what a destination has used of its budget
+type VolumeUsage struct {
+	MaxEvents  int64  `json:"max_events,omitempty"`
+	MaxBytes   int64  `json:"max_bytes,omitempty"`
+	EventsSent int64  `json:"events_sent"`
+	BytesSent  int64  `json:"bytes_sent"`
+	Exhausted  bool   `json:"exhausted"`
+	Reason     string `json:"reason,omitempty"` // Limit that was reached
+}
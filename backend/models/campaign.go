@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Campaign holds the variables of an exercise, such as attacker_ip,
+// victim_user and c2_domain. While it is active, override values, noise
+// source overrides, scenario matches and incident and lifecycle fields can
+// refer to a variable as ${campaign.attacker_ip}, so every source generated
+// during the exercise agrees on the same indicators. At most one campaign is
+// active at a time.
+type Campaign struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description,omitempty"`
+	Variables   map[string]string `json:"variables" binding:"required"` // Names are letters, digits and underscores
+	Active      bool              `json:"active"`
+	ActivatedAt *time.Time        `json:"activated_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
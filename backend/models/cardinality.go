@@ -0,0 +1,34 @@
+package models
+
+import "fmt"
+
+// Most distinct values a cardinality limit can ask for
+const MaxCardinality = 1000000
+
+// CardinalityLimits sets how many distinct users, hosts, source IPs and
+// URLs a noise run draws from, and how often it repeats an event. Zero
+// leaves a field as the templates generate it.
+type CardinalityLimits struct {
+	Users         int     `json:"users,omitempty"`
+	Hosts         int     `json:"hosts,omitempty"`
+	SourceIPs     int     `json:"source_ips,omitempty"`
+	URLs          int     `json:"urls,omitempty"`
+	DuplicateRate float64 `json:"duplicate_rate,omitempty"` // Share of events sent again as exact copies, 0-1
+}
+
+// Validate checks the limits are in range
+func (l *CardinalityLimits) Validate() error {
+	limits := []struct {
+		name string
+		n    int
+	}{{"users", l.Users}, {"hosts", l.Hosts}, {"source_ips", l.SourceIPs}, {"urls", l.URLs}}
+	for _, f := range limits {
+		if f.n < 0 || f.n > MaxCardinality {
+			return fmt.Errorf("cardinality.%s must be between 0 and %d", f.name, MaxCardinality)
+		}
+	}
+	if l.DuplicateRate < 0 || l.DuplicateRate >= 1 {
+		return fmt.Errorf("cardinality.duplicate_rate must be at least 0 and below 1")
+	}
+	return nil
+}
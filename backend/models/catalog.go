@@ -0,0 +1,50 @@
+package models
+
+// CatalogEntry describes one template for the searchable catalog
+type CatalogEntry struct {
+	EventType     string   `json:"event_type"`
+	EventTypeName string   `json:"event_type_name"`
+	Category      string   `json:"category"` // Event type category, e.g. windows or cloud
+	TemplateID    string   `json:"template_id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description,omitempty"`
+	Format        string   `json:"format"`
+	Sourcetype    string   `json:"sourcetype,omitempty"`
+	Vendor        string   `json:"vendor,omitempty"`
+	Product       string   `json:"product,omitempty"`
+	Techniques    []string `json:"techniques,omitempty"` // MITRE ATT&CK technique IDs, e.g. T1110
+	Datamodels    []string `json:"datamodels,omitempty"` // Splunk CIM data models, e.g. Authentication
+	Source        string   `json:"source"`               // builtin or custom
+	Deprecated    bool     `json:"deprecated,omitempty"`
+}
+
+// CatalogQuery searches and filters the catalog. Text matches every term
+// in any text of an entry; each filter matches any of its values, and all
+// filters must match.
+type CatalogQuery struct {
+	Text       string
+	Categories []string
+	Formats    []string
+	Vendors    []string
+	Techniques []string // A technique also matches its sub-techniques
+	Datamodels []string
+	Source     string
+	Offset     int
+	Limit      int // Default 50
+}
+
+// CatalogResult is a page of matching entries and the facet counts of all
+// matches
+type CatalogResult struct {
+	Total   int                     `json:"total"`
+	Offset  int                     `json:"offset"`
+	Entries []CatalogEntry          `json:"entries"`
+	Facets  map[string][]FacetCount `json:"facets"` // category, format, vendor, technique, datamodel
+}
+
+// FacetCount is how many matching entries have a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"` // Technique name
+	Count int    `json:"count"`
+}
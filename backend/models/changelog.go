@@ -0,0 +1,15 @@
+package models
+
+// TemplateChange describes how a built-in template's output changed when it
+// reached Version
+type TemplateChange struct {
+	EventType  string   `json:"event_type"`
+	TemplateID string   `json:"template_id"`
+	Version    int      `json:"version"`
+	Summary    string   `json:"summary"`
+	Added      []string `json:"added,omitempty"`   // Field paths the template now emits
+	Removed    []string `json:"removed,omitempty"` // Field paths it no longer emits
+	Changed    []string `json:"changed,omitempty"` // Field paths whose values or layout changed
+	Deprecated bool     `json:"deprecated,omitempty"`
+	ReplacedBy string   `json:"replaced_by,omitempty"`
+}
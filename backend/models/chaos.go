@@ -0,0 +1,19 @@
+package models
+
+// Ways a chaos event is broken
+const (
+	ChaosTruncate  = "truncate"  // Cut off part way through
+	ChaosSyntax    = "syntax"    // Unbalanced braces, tags or quotes
+	ChaosOversized = "oversized" // One field padded far beyond its usual size
+	ChaosNonUTF8   = "non_utf8"  // Invalid UTF-8 and control bytes
+	ChaosShuffle   = "shuffle"   // Fields in a random order
+)
+
+// ChaosConfig sends a share of events deliberately broken, to test how
+// pipelines and index-time parsing handle bad input. Only the raw event is
+// broken; the fields stay as generated.
+type ChaosConfig struct {
+	Rate          float64  `json:"rate"`                     // Share of events broken, 0-1
+	Kinds         []string `json:"kinds,omitempty"`          // Ways to break them; empty means all
+	OversizeBytes int      `json:"oversize_bytes,omitempty"` // Padding for oversized, default 64KiB
+}
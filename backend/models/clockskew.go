@@ -0,0 +1,13 @@
+package models
+
+// ClockSkew moves event times away from the time they are sent, as host
+// clocks drift and forwarders delay events, to test time sync and
+// late-arriving data handling in the SIEM
+type ClockSkew struct {
+	MaxSkewSeconds float64 `json:"max_skew_seconds,omitempty"` // Each host's clock is off by up to this much either way
+	SkewedHosts    float64 `json:"skewed_hosts,omitempty"`     // Share of hosts whose clock is off, 0-1; default 1
+	LatencySeconds float64 `json:"latency_seconds,omitempty"`  // Least delay between an event and its sending
+	JitterSeconds  float64 `json:"jitter_seconds,omitempty"`   // Mean extra delay on top of the latency
+	LateRate       float64 `json:"late_rate,omitempty"`        // Share of events held back, 0-1
+	LateSeconds    float64 `json:"late_seconds,omitempty"`     // How long held-back events are late; default 3600
+}
@@ -0,0 +1,18 @@
+package models
+
+import "fmt"
+
+// CloudTrailConfig controls the outcomes of generated CloudTrail API calls
+type CloudTrailConfig struct {
+	// ErrorRate is the percentage of API calls that fail with an errorCode
+	// such as AccessDenied or ThrottlingException
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// Validate checks the error rate is a percentage
+func (c *CloudTrailConfig) Validate() error {
+	if c.ErrorRate < 0 || c.ErrorRate > 100 {
+		return fmt.Errorf("error_rate must be between 0 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// ClusterStatus describes this instance's part in a cluster
+type ClusterStatus struct {
+	Mode           string          `json:"mode"` // standalone, coordinator or worker
+	WorkerID       string          `json:"worker_id,omitempty"`
+	CoordinatorURL string          `json:"coordinator_url,omitempty"`
+	RunID          string          `json:"run_id,omitempty"` // Distributed run in progress or last finished
+	Workers        []ClusterWorker `json:"workers,omitempty"`
+}
+
+// ClusterWorker is a generator instance registered with the coordinator
+type ClusterWorker struct {
+	ID           string    `json:"id"`
+	URL          string    `json:"url"`
+	CPUs         int       `json:"cpus"`
+	RegisteredAt time.Time `json:"registered_at"`
+	LastSeen     time.Time `json:"last_seen"`
+	RunID        string    `json:"run_id,omitempty"` // Shard the worker reported running
+	Running      bool      `json:"running"`
+}
+
+// WorkerHeartbeat registers a worker with the coordinator and reports its
+// noise status. Workers send one every few seconds.
+type WorkerHeartbeat struct {
+	ID     string      `json:"id" binding:"required"`
+	URL    string      `json:"url" binding:"required"`
+	CPUs   int         `json:"cpus"`
+	RunID  string      `json:"run_id,omitempty"` // Shard the worker is running
+	Status NoiseStatus `json:"status"`
+}
+
+// HeartbeatResponse names the distributed run the worker should have a
+// shard of; workers stop shards of any other run
+type HeartbeatResponse struct {
+	RunID string `json:"run_id,omitempty"`
+}
+
+// ShardRequest starts a worker's share of a distributed run. Destinations
+// are the ones the run sends to; RouteTargets are the destinations their
+// routing rules forward to.
+type ShardRequest struct {
+	RunID        string                  `json:"run_id" binding:"required"`
+	Config       NoiseConfig             `json:"config" binding:"-"` // Shard rates may be below the API minimum
+	Destinations map[string]*Destination `json:"destinations"`
+	RouteTargets map[string]*Destination `json:"route_targets,omitempty"`
+}
+
+// NoiseShard is one instance's share of a distributed noise run
+type NoiseShard struct {
+	WorkerID        string     `json:"worker_id"` // "coordinator" for the coordinator's own share
+	RatePerSecond   float64    `json:"rate_per_second"`
+	Running         bool       `json:"running"`
+	Lost            bool       `json:"lost,omitempty"` // Stopped sending heartbeats; its rate moved to the other shards
+	StopReason      string     `json:"stop_reason,omitempty"`
+	TotalSent       int64      `json:"total_sent"`
+	TotalErrors     int64      `json:"total_errors"`
+	EventsPerSecond float64    `json:"events_per_second"`
+	LastSeen        *time.Time `json:"last_seen,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Ways a stop URL can end a run
+const (
+	StopOnSuccess = "success" // Stop once the URL answers 2xx
+	StopOnFailure = "failure" // Stop once the URL fails or answers anything else
+)
+
+// Completion ends a noise run by itself at whichever limit it reaches
+// first, so a forgotten stream does not run on. Zero leaves a limit unset.
+type Completion struct {
+	MaxEvents          int64    `json:"max_events,omitempty"`           // Events sent, across destinations
+	MaxBytes           ByteSize `json:"max_bytes,omitempty"`            // Raw event bytes sent, across destinations
+	MaxDurationSeconds int64    `json:"max_duration_seconds,omitempty"` // Wall-clock time since the run started
+	StopURL            string   `json:"stop_url,omitempty"`             // Polled with GET
+	StopOn             string   `json:"stop_on,omitempty"`              // success (default) or failure
+	CheckSeconds       int      `json:"check_seconds,omitempty"`        // How often stop_url is polled; default 30
+}
+
+// Validate checks the limits are in range and the stop URL is usable
+func (c *Completion) Validate() error {
+	if c.MaxEvents < 0 || c.MaxBytes < 0 || c.MaxDurationSeconds < 0 {
+		return fmt.Errorf("completion limits cannot be negative")
+	}
+	if c.CheckSeconds < 0 || c.CheckSeconds > 3600 {
+		return fmt.Errorf("completion.check_seconds must be between 0 and 3600")
+	}
+	if c.StopOn != "" && c.StopOn != StopOnSuccess && c.StopOn != StopOnFailure {
+		return fmt.Errorf("completion.stop_on must be %s or %s", StopOnSuccess, StopOnFailure)
+	}
+	if c.StopURL != "" {
+		u, err := url.Parse(c.StopURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("completion.stop_url must be an http or https url")
+		}
+	}
+	if c.MaxEvents == 0 && c.MaxBytes == 0 && c.MaxDurationSeconds == 0 && c.StopURL == "" {
+		return fmt.Errorf("completion needs max_events, max_bytes, max_duration_seconds or stop_url")
+	}
+	return nil
+}
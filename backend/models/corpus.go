@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// CorpusVersion is the layout version of corpus snapshots
+const CorpusVersion = 1
+
+// Corpus is a snapshot of every template's output made with a fixed seed,
+// kept to catch accidental changes to the fields downstream extractions
+// rely on
+type Corpus struct {
+	Version   int              `json:"version"`
+	Seed      int64            `json:"seed"`
+	Samples   int              `json:"samples"` // Events generated per template
+	CreatedAt time.Time        `json:"created_at"`
+	Templates []CorpusTemplate `json:"templates"`
+}
+
+// CorpusTemplate is the output of one template: the fields its samples
+// carried and the first raw event
+type CorpusTemplate struct {
+	EventType     string        `json:"event_type"`
+	TemplateID    string        `json:"template_id"`
+	SchemaVersion int           `json:"schema_version"`
+	Sourcetype    string        `json:"sourcetype,omitempty"`
+	Fields        []CorpusField `json:"fields"`
+	RawEvent      string        `json:"raw_event"`
+	Error         string        `json:"error,omitempty"` // Generation failed; Fields holds the samples made before it
+}
+
+// CorpusField is one flattened field path of a template's output
+type CorpusField struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"`               // JSON type: string, number, boolean, array, object or null
+	Example  string `json:"example"`            // First value seen, as JSON
+	Optional bool   `json:"optional,omitempty"` // Missing from some samples
+}
+
+// CorpusSnapshotRequest sets how a corpus snapshot is made
+type CorpusSnapshotRequest struct {
+	Seed    int64 `json:"seed,omitempty"`    // Default 1
+	Samples int   `json:"samples,omitempty"` // Events per template, default 5
+}
+
+// CorpusDiffRequest compares current output with a corpus. Without
+// Corpus, the server's stored snapshot is used.
+type CorpusDiffRequest struct {
+	Corpus *Corpus `json:"corpus,omitempty"`
+}
+
+// CorpusDiff reports how current output differs from a corpus. Templates
+// lists only the templates whose fields changed.
+type CorpusDiff struct {
+	Seed             int64          `json:"seed"`
+	Changed          bool           `json:"changed"`
+	TemplatesAdded   []string       `json:"templates_added,omitempty"`   // event_type/template_id
+	TemplatesRemoved []string       `json:"templates_removed,omitempty"` // event_type/template_id
+	Templates        []TemplateDiff `json:"templates"`
+}
+
+// TemplateDiff is how one template's output differs from its snapshot
+type TemplateDiff struct {
+	EventType           string            `json:"event_type"`
+	TemplateID          string            `json:"template_id"`
+	SchemaVersion       int               `json:"schema_version"`
+	StoredSchemaVersion int               `json:"stored_schema_version"`
+	Sourcetype          string            `json:"sourcetype,omitempty"`
+	StoredSourcetype    string            `json:"stored_sourcetype,omitempty"` // Set only when the sourcetype changed
+	Added               []string          `json:"added,omitempty"`
+	Removed             []string          `json:"removed,omitempty"`
+	Renamed             []FieldRename     `json:"renamed,omitempty"`
+	TypeChanged         []FieldTypeChange `json:"type_changed,omitempty"`
+	Error               string            `json:"error,omitempty"`
+}
+
+// FieldRename is a removed field whose value now appears under another name
+type FieldRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// FieldTypeChange is a field whose JSON type changed
+type FieldTypeChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
@@ -6,10 +6,16 @@ import "time"
 type DestinationType string
 
 const (
-	DestinationTypeSyslogUDP DestinationType = "syslog_udp"
-	DestinationTypeSyslogTCP DestinationType = "syslog_tcp"
-	DestinationTypeHEC       DestinationType = "hec"
-	DestinationTypeFile      DestinationType = "file"
+	DestinationTypeSyslogUDP     DestinationType = "syslog_udp"
+	DestinationTypeSyslogTCP     DestinationType = "syslog_tcp"
+	DestinationTypeHEC           DestinationType = "hec"
+	DestinationTypeFile          DestinationType = "file"
+	DestinationTypeStatsD        DestinationType = "statsd"
+	DestinationTypeCollectd      DestinationType = "collectd"
+	DestinationTypeOTLP          DestinationType = "otlp"
+	DestinationTypeElasticsearch DestinationType = "elasticsearch"
+	DestinationTypeMock          DestinationType = "mock" // Discards events; for throughput and failure tests
+	DestinationTypeSOAR          DestinationType = "soar" // Posts alert-class events to a SOAR platform
 )
 
 // Destination represents a target for sending generated events
@@ -32,9 +38,13 @@ type DestinationConfig struct {
 	Port     int    `json:"port,omitempty"`
 	Facility int    `json:"facility,omitempty"` // 0-23
 	Severity int    `json:"severity,omitempty"` // 0-7
-	Format   string `json:"format,omitempty"`   // rfc3164, rfc5424
+	Format   string `json:"format,omitempty"`   // rfc3164, rfc5424; bulk, logstash (elasticsearch); xsoar, tines, splunk_soar (soar)
 
-	// HEC configuration
+	// HEC configuration. Elasticsearch destinations use URL, sending Token
+	// as an API key and Headers with each request, and Index in place of
+	// each event's data stream. SOAR destinations post to URL with Token in
+	// the platform's auth header and Headers, and use Sourcetype as the
+	// XSOAR incident type or the Splunk SOAR container label ("events").
 	URL         string `json:"url,omitempty"`
 	Token       string `json:"token,omitempty"`
 	Index       string `json:"index,omitempty"`
@@ -43,10 +53,50 @@ type DestinationConfig struct {
 	VerifySSL   bool   `json:"verify_ssl,omitempty"`
 	BatchSize   int    `json:"batch_size,omitempty"`
 
+	// TLS (HEC, OTLP, Elasticsearch, SOAR, and syslog_tcp with TLS set). CACert,
+	// ClientCert and ClientKey are PEM file paths or inline PEM; a CA bundle
+	// turns on certificate verification, and a client certificate enables
+	// mutual TLS.
+	TLS        bool   `json:"tls,omitempty"`
+	CACert     string `json:"ca_cert,omitempty"`
+	ClientCert string `json:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"`
+
+	// Outbound proxy (HEC, OTLP, Elasticsearch, SOAR and syslog_tcp): http://,
+	// https:// (HTTP CONNECT) or socks5://, with optional user:password@
+	Proxy string `json:"proxy,omitempty"`
+
+	// HTTP batching (HEC, OTLP and Elasticsearch). BatchSize is the events
+	// per request (0 sends each event on its own); a partial batch is flushed
+	// after FlushIntervalMs, 1000 by default. Compression "gzip" compresses
+	// request bodies.
+	FlushIntervalMs int    `json:"flush_interval_ms,omitempty"`
+	Compression     string `json:"compression,omitempty"`
+
+	// Metrics export configuration (statsd, collectd, otlp). StatsD and
+	// collectd use Host/Port; OTLP posts JSON to URL and sends Token as a
+	// bearer token when set. Format selects "dogstatsd" (tags, default) or
+	// plain "statsd" lines.
+	MetricPrefix string            `json:"metric_prefix,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+
 	// File configuration
 	FilePath   string `json:"file_path,omitempty"`
 	MaxSizeMB  int    `json:"max_size_mb,omitempty"`
 	RotateKeep int    `json:"rotate_keep,omitempty"`
+
+	// Mock sink configuration. Each batch (BatchSize events, or each event
+	// when 0) takes LatencyMs plus up to JitterMs and fails with probability
+	// ErrorRate, 0-1.
+	LatencyMs int     `json:"latency_ms,omitempty"`
+	JitterMs  int     `json:"jitter_ms,omitempty"`
+	ErrorRate float64 `json:"error_rate,omitempty"`
+
+	// Routing rules, checked in order for every event (any destination type)
+	Routes []RoutingRule `json:"routes,omitempty"`
+
+	// Counting what was indexed after a run (HEC and Elasticsearch)
+	Reconcile *Reconcile `json:"reconcile,omitempty"`
 }
 
 // TestConnectionRequest represents a request to test a destination connection
@@ -61,6 +111,45 @@ type TestConnectionResponse struct {
 	Message     string `json:"message"`
 	LatencyMs   int64  `json:"latency_ms,omitempty"`
 	Error       string `json:"error,omitempty"`
+	Code        string `json:"code,omitempty"` // Error code when the test failed, e.g. DESTINATION_AUTH_FAILED
+
+	Steps []DiagnosticStep `json:"steps,omitempty"` // Each check in the order it ran
+}
+
+// Diagnostic step statuses
+const (
+	DiagnosticOK      = "ok"
+	DiagnosticWarning = "warning" // Passed, but with a problem worth fixing
+	DiagnosticFailed  = "failed"
+	DiagnosticSkipped = "skipped" // Not applicable, or an earlier step failed
+)
+
+// DiagnosticStep is one check of a destination connection test: config,
+// dns, tcp, proxy, tls, auth or test_event
+type DiagnosticStep struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// BatchStats reports the batches an HTTP destination has sent since startup
+type BatchStats struct {
+	Requests         int64      `json:"requests"`
+	Events           int64      `json:"events"`
+	Bytes            int64      `json:"bytes"`      // Request bodies before compression
+	WireBytes        int64      `json:"wire_bytes"` // Request bodies as sent
+	AvgBatchSize     float64    `json:"avg_batch_size"`
+	MaxBatchSize     int        `json:"max_batch_size"`
+	LastBatchSize    int        `json:"last_batch_size"`
+	CompressionRatio float64    `json:"compression_ratio,omitempty"` // Bytes / WireBytes
+	SizeFlushes      int64      `json:"size_flushes"`                // Batches sent because they were full
+	IntervalFlushes  int64      `json:"interval_flushes"`            // Partial batches sent by the flush interval
+	CloseFlushes     int64      `json:"close_flushes"`               // Partial batches sent when the sender closed
+	Errors           int64      `json:"errors"`
+	LastError        string     `json:"last_error,omitempty"`
+	LastFlushAt      *time.Time `json:"last_flush_at,omitempty"`
 }
 
 // DestinationStats represents statistics for a destination
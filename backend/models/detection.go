@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// Kinds of expected detection
+const (
+	DetectionSplunkSavedSearch = "splunk_saved_search" // A Splunk saved search or correlation search, by name
+	DetectionSentinelRule      = "sentinel_rule"       // A Microsoft Sentinel analytic rule, by ID
+)
+
+// sentinelRuleID matches analytic rule IDs, which are GUIDs or resource IDs
+var sentinelRuleID = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+// ExpectedDetection is an alert a scenario should raise in the SIEM
+type ExpectedDetection struct {
+	Kind   string `json:"kind" binding:"required"` // splunk_saved_search or sentinel_rule
+	Name   string `json:"name,omitempty"`          // Saved search name; a label for a Sentinel rule
+	RuleID string `json:"rule_id,omitempty"`       // Sentinel analytic rule ID
+}
+
+// Validate checks the detection names what its kind needs
+func (d *ExpectedDetection) Validate() error {
+	switch d.Kind {
+	case DetectionSplunkSavedSearch:
+		if d.Name == "" {
+			return fmt.Errorf("expected_detections name is required for %s", d.Kind)
+		}
+	case DetectionSentinelRule:
+		if !sentinelRuleID.MatchString(d.RuleID) {
+			return fmt.Errorf("expected_detections rule_id must be a Sentinel analytic rule ID")
+		}
+	default:
+		return fmt.Errorf("expected_detections kind must be %s or %s", DetectionSplunkSavedSearch, DetectionSentinelRule)
+	}
+	return nil
+}
+
+// Label names the detection in reports and logs
+func (d *ExpectedDetection) Label() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return d.RuleID
+}
+
+// DetectionBackends are the SIEM APIs expected detections are checked
+// against. Token, Password and ClientSecret are secrets: they are
+// encrypted when saved and masked in responses.
+type DetectionBackends struct {
+	Splunk   *SplunkSearchAPI   `json:"splunk,omitempty"`
+	Sentinel *SentinelWorkspace `json:"sentinel,omitempty"`
+}
+
+// SplunkSearchAPI is a Splunk management endpoint, e.g. https://splunk:8089,
+// and a bearer token or user allowed to search the _audit index
+type SplunkSearchAPI struct {
+	URL       string `json:"url"`
+	Token     string `json:"token,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+	VerifySSL bool   `json:"verify_ssl,omitempty"`
+}
+
+// SentinelWorkspace is the Log Analytics workspace behind Microsoft
+// Sentinel, read with an Entra ID app registration allowed to query it
+type SentinelWorkspace struct {
+	TenantID     string `json:"tenant_id"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	WorkspaceID  string `json:"workspace_id"`
+	LoginURL     string `json:"login_url,omitempty"` // Default https://login.microsoftonline.com
+	APIURL       string `json:"api_url,omitempty"`   // Default https://api.loganalytics.io
+}
+
+// Validate checks each backend set has what it needs to connect
+func (b *DetectionBackends) Validate() error {
+	if s := b.Splunk; s != nil {
+		if err := checkHTTPURL("splunk.url", s.URL, true); err != nil {
+			return err
+		}
+		if s.Token == "" && s.Username == "" {
+			return fmt.Errorf("splunk needs a token or username")
+		}
+	}
+	if s := b.Sentinel; s != nil {
+		if s.TenantID == "" || s.ClientID == "" || s.WorkspaceID == "" {
+			return fmt.Errorf("sentinel needs tenant_id, client_id and workspace_id")
+		}
+		if s.ClientSecret == "" {
+			return fmt.Errorf("sentinel.client_secret is required")
+		}
+		if err := checkHTTPURL("sentinel.login_url", s.LoginURL, false); err != nil {
+			return err
+		}
+		if err := checkHTTPURL("sentinel.api_url", s.APIURL, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkHTTPURL(field, value string, required bool) error {
+	if value == "" && !required {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s must be an http or https url", field)
+	}
+	return nil
+}
+
+// Detection check outcomes
+const (
+	DetectionFired   = "fired"
+	DetectionMissing = "missing"
+	DetectionError   = "error" // The SIEM could not be asked
+)
+
+// DetectionResult reports whether one expected detection fired
+type DetectionResult struct {
+	ExpectedDetection
+	Status       string     `json:"status"`
+	Alerts       int64      `json:"alerts"` // Times it fired in the window
+	FirstFiredAt *time.Time `json:"first_fired_at,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// DetectionReport is the outcome of checking a scenario's expected
+// detections over the window from its trigger to the check
+type DetectionReport struct {
+	ScenarioID string            `json:"scenario_id"`
+	Scenario   string            `json:"scenario"`
+	Passed     bool              `json:"passed"` // Every expected detection fired
+	Fired      int               `json:"fired"`
+	Missing    int               `json:"missing"`
+	Errors     int               `json:"errors"`
+	Results    []DetectionResult `json:"results"`
+	From       time.Time         `json:"from"`
+	To         time.Time         `json:"to"`
+	CheckedAt  time.Time         `json:"checked_at"`
+}
@@ -0,0 +1,46 @@
+package models
+
+// DryRunEstimate is what a generate request or noise run would send, worked
+// out from a sample of generated events. Nothing is sent. Sizes are the raw
+// event length, as counted by volume budgets.
+type DryRunEstimate struct {
+	DryRun          bool                  `json:"dry_run"`
+	Unbounded       bool                  `json:"unbounded"`                  // Runs until stopped; totals are then omitted
+	Events          int64                 `json:"events,omitempty"`           // Events generated
+	Bytes           int64                 `json:"bytes,omitempty"`            // Bytes of those events
+	DurationSeconds float64               `json:"duration_seconds,omitempty"` // Omitted when sending as fast as possible
+	EventsPerSecond float64               `json:"events_per_second,omitempty"`
+	BytesPerSecond  float64               `json:"bytes_per_second,omitempty"`
+	BytesPerDay     int64                 `json:"bytes_per_day,omitempty"` // At the configured rate, e.g. for license sizing
+	AvgEventBytes   float64               `json:"avg_event_bytes"`
+	SampleSize      int                   `json:"sample_size"` // Events generated to measure sizes
+	Templates       []TemplateEstimate    `json:"templates"`
+	Destinations    []DestinationEstimate `json:"destinations,omitempty"`
+	Warnings        []string              `json:"warnings,omitempty"`
+}
+
+// TemplateEstimate is one template's part of the mix
+type TemplateEstimate struct {
+	EventType     string  `json:"event_type"`
+	TemplateID    string  `json:"template_id"`
+	DestinationID string  `json:"destination_id,omitempty"`
+	Share         float64 `json:"share"` // Fraction of all events, 0-1
+	Events        int64   `json:"events,omitempty"`
+	Bytes         int64   `json:"bytes,omitempty"`
+	AvgEventBytes float64 `json:"avg_event_bytes"`
+}
+
+// DestinationEstimate is what one destination would receive, mirror copies
+// included
+type DestinationEstimate struct {
+	DestinationID   string  `json:"destination_id"`
+	Name            string  `json:"name,omitempty"`
+	Events          int64   `json:"events,omitempty"`
+	Bytes           int64   `json:"bytes,omitempty"`
+	EventsPerSecond float64 `json:"events_per_second,omitempty"`
+	BytesPerSecond  float64 `json:"bytes_per_second,omitempty"`
+	// BudgetExhaustedAfter is when the destination's budget runs out, in
+	// seconds from the start
+	BudgetExhaustedAfter float64 `json:"budget_exhausted_after,omitempty"`
+	BudgetReason         string  `json:"budget_reason,omitempty"`
+}
@@ -24,6 +24,24 @@ type EventField struct {
 	Max         int           `json:"max,omitempty"`
 	Length      int           `json:"length,omitempty"`
 	Format      string        `json:"format,omitempty"`
+
+	Distribution *DistributionSpec `json:"distribution,omitempty"`
+}
+
+// DistributionSpec declares how a field's values are drawn
+type DistributionSpec struct {
+	Type    string        `json:"type"` // uniform, gaussian, lognormal, zipf, weighted
+	Mean    float64       `json:"mean,omitempty"`
+	StdDev  float64       `json:"stddev,omitempty"`
+	Median  float64       `json:"median,omitempty"`
+	Sigma   float64       `json:"sigma,omitempty"`
+	S       float64       `json:"s,omitempty"` // Zipf exponent, default 1.1
+	N       int           `json:"n,omitempty"` // Zipf rank count when no values are given
+	Min     *float64      `json:"min,omitempty"`
+	Max     *float64      `json:"max,omitempty"`
+	Values  []interface{} `json:"values,omitempty"`
+	Weights []float64     `json:"weights,omitempty"`
+	Round   bool          `json:"round,omitempty"`
 }
 
 // EventTemplate defines the structure for generating events
@@ -37,6 +55,9 @@ type EventTemplate struct {
 	Sourcetype     string       `json:"sourcetype,omitempty"`
 	Fields         []EventField `json:"fields,omitempty"`
 	OutputTemplate string       `json:"output_template,omitempty"`
+	SchemaVersion  int          `json:"schema_version"`        // Output format version, raised whenever fields or layout change
+	Deprecated     bool         `json:"deprecated,omitempty"`  // Still generated, but due to be removed
+	ReplacedBy     string       `json:"replaced_by,omitempty"` // Template to use instead of a deprecated one
 }
 
 // GeneratedEvent represents a single generated event
@@ -45,36 +66,78 @@ type GeneratedEvent struct {
 	Type       string                 `json:"type"`
 	EventID    string                 `json:"event_id,omitempty"`
 	Timestamp  time.Time              `json:"timestamp"`
-	RawEvent   string                 `json:"raw_event"`
-	Fields     map[string]interface{} `json:"fields"`
+	RawEvent   string                 `json:"raw_event,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
 	Sourcetype string                 `json:"sourcetype"`
+	Host       string                 `json:"host,omitempty"` // Host senders attribute the event to; empty for the generator's own
 }
 
+// Event outputs a generation request can ask for. Dropping the form a
+// caller does not need keeps bulk responses and buffered events small.
+const (
+	OutputFull   = ""       // RawEvent and Fields
+	OutputRaw    = "raw"    // RawEvent only
+	OutputFields = "fields" // Fields only
+)
+
 // GenerateRequest represents a request to generate events
 type GenerateRequest struct {
-	EventType     string                 `json:"event_type" binding:"required"`
-	EventID       string                 `json:"event_id,omitempty"`
-	Count         int                    `json:"count" binding:"required,min=1,max=10000"`
-	DestinationID string                 `json:"destination_id,omitempty"`
-	Overrides     map[string]interface{} `json:"overrides,omitempty"`
-	RatePerSecond int                    `json:"rate_per_second,omitempty"`
+	EventType       string                 `json:"event_type" binding:"required"`
+	EventID         string                 `json:"event_id,omitempty"`
+	Count           int                    `json:"count" binding:"min=0"` // Required and at most 10000 unless a budget is given
+	DestinationID   string                 `json:"destination_id,omitempty"`
+	DestinationIDs  []string               `json:"destination_ids,omitempty"` // Send every event to each of these as well
+	Overrides       map[string]interface{} `json:"overrides,omitempty"`
+	StrictOverrides bool                   `json:"strict_overrides,omitempty"` // Reject overrides for fields the template doesn't emit
+	Preset          string                 `json:"preset,omitempty"`           // Override preset name or ID; Overrides win over its fields
+	RatePerSecond   int                    `json:"rate_per_second,omitempty"`
+	Budget          *VolumeBudget          `json:"budget,omitempty"` // Send until the budget is used up (or count is reached)
+	Output          string                 `json:"output,omitempty"` // raw or fields; empty returns both
+	DryRun          bool                   `json:"dry_run,omitempty"` // Estimate the volume instead of sending
+	TimestampFuzz   *TimestampFuzz         `json:"timestamp_fuzz,omitempty"` // Vary timestamp formats and timezones
+	ClockSkew       *ClockSkew             `json:"clock_skew,omitempty"`     // Skew host clocks and delay events
+	Chaos           *ChaosConfig           `json:"chaos,omitempty"`          // Send a share of events broken
+	Render          *RenderConfig          `json:"render,omitempty"`         // Render as CSV, kv or LTSV instead of the native format
+	SchemaVersion   int                    `json:"schema_version,omitempty"` // Fail unless the template is at this version
+	Seed            int64                  `json:"seed,omitempty"`           // Draw random values from this seed, generating on one worker so a batch repeats
+	Reconcile       bool                   `json:"reconcile,omitempty"`      // Count the events indexed once the batch is sent
+	ReturnEvents    bool                   `json:"return_events,omitempty"`  // Without a destination, return every event rather than a preview
 }
 
 // GenerateResponse represents the response from event generation
 type GenerateResponse struct {
 	Success       bool             `json:"success"`
 	EventsCreated int              `json:"events_created"`
-	EventsSent    int              `json:"events_sent"`
+	EventsSent    int              `json:"events_sent"` // Successful sends summed over destinations
 	Destination   string           `json:"destination,omitempty"`
 	Errors        []string         `json:"errors,omitempty"`
+	Code          string           `json:"code,omitempty"` // Error code of the first failed send, e.g. DESTINATION_UNREACHABLE
 	Preview       []GeneratedEvent `json:"preview,omitempty"`
+	Events        []GeneratedEvent `json:"events,omitempty"` // Every event, when the request asks to return them
+	Budget        *VolumeUsage     `json:"budget,omitempty"`
+	Deliveries    []DeliveryResult `json:"deliveries,omitempty"` // Per destination when sending to several
+	Warnings      []string         `json:"warnings,omitempty"`   // e.g. the template is deprecated
+}
+
+// DeliveryResult reports how one destination of a multi-destination send fared
+type DeliveryResult struct {
+	DestinationID string `json:"destination_id"`
+	Name          string `json:"name,omitempty"`
+	EventsSent    int64  `json:"events_sent"`
+	Errors        int64  `json:"errors"`
+	LastError     string `json:"last_error,omitempty"`
 }
 
 // PreviewRequest represents a request to preview a single event
 type PreviewRequest struct {
-	EventType string                 `json:"event_type" binding:"required"`
-	EventID   string                 `json:"event_id,omitempty"`
-	Overrides map[string]interface{} `json:"overrides,omitempty"`
+	EventType       string                 `json:"event_type" binding:"required"`
+	EventID         string                 `json:"event_id,omitempty"`
+	Overrides       map[string]interface{} `json:"overrides,omitempty"`
+	StrictOverrides bool                   `json:"strict_overrides,omitempty"`
+	Preset          string                 `json:"preset,omitempty"`         // Override preset name or ID; Overrides win over its fields
+	Output          string                 `json:"output,omitempty"`         // raw or fields; empty returns both
+	SchemaVersion   int                    `json:"schema_version,omitempty"` // Fail unless the template is at this version
+	Render          *RenderConfig          `json:"render,omitempty"`         // Render as CSV, kv or LTSV instead of the native format
 }
 
 // EventTypeSchema represents the schema for a specific event type
@@ -82,3 +145,116 @@ type EventTypeSchema struct {
 	EventType EventType     `json:"event_type"`
 	Templates []EventTemplate `json:"templates"`
 }
+
+// FieldChange describes how an override altered a field
+type FieldChange string
+
+const (
+	FieldAdded   FieldChange = "added"
+	FieldChanged FieldChange = "changed"
+	FieldRemoved FieldChange = "removed"
+)
+
+// FieldDiff describes a single field difference between default and overridden output
+type FieldDiff struct {
+	Field    string      `json:"field"`
+	Change   FieldChange `json:"change"`
+	Default  interface{} `json:"default,omitempty"`
+	Override interface{} `json:"override,omitempty"`
+}
+
+// PreviewDiffResponse pairs a previewed event with the effect of its overrides
+type PreviewDiffResponse struct {
+	Event   *GeneratedEvent `json:"event"`
+	Default *GeneratedEvent `json:"default"`
+	Diff    []FieldDiff     `json:"diff"`
+}
+
+// FieldSchema documents a single field emitted by a template
+type FieldSchema struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Example     interface{} `json:"example,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Overridable bool        `json:"overridable"`
+}
+
+// TemplateSchema documents the fields a template emits
+type TemplateSchema struct {
+	EventType  string        `json:"event_type,omitempty"`
+	TemplateID string        `json:"template_id"`
+	Name       string        `json:"name"`
+	Format     string        `json:"format"`
+	Sourcetype string        `json:"sourcetype,omitempty"`
+	Source     string        `json:"source"` // "builtin" or "custom"
+	Fields     []FieldSchema `json:"fields"`
+	// SchemaVersion is the template's output format version
+	SchemaVersion int `json:"schema_version"`
+}
+
+// FieldError describes a validation failure for a single field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// IncidentRequest describes a correlated incident: an error-rate spike on one
+// service endpoint with matching error logs and 5xx access logs
+type IncidentRequest struct {
+	Service         string     `json:"service,omitempty"`  // Default: random application service
+	Host            string     `json:"host,omitempty"`     // Default: random application host
+	Endpoint        string     `json:"endpoint,omitempty"` // Default: random API endpoint
+	Start           *time.Time `json:"start,omitempty"`    // Default: now minus duration
+	DurationSeconds int        `json:"duration_seconds,omitempty"`
+	IntervalSeconds int        `json:"interval_seconds,omitempty"`
+	PeakErrorRate   float64    `json:"peak_error_rate,omitempty"`   // 5xx percentage at the peak
+	LogsPerInterval int        `json:"logs_per_interval,omitempty"` // Error logs per interval at the peak
+	DestinationID   string     `json:"destination_id,omitempty"`
+}
+
+// IncidentResponse summarises the events generated for an incident
+type IncidentResponse struct {
+	Success       bool             `json:"success"`
+	Service       string           `json:"service"`
+	Host          string           `json:"host"`
+	Endpoint      string           `json:"endpoint"`
+	Start         time.Time        `json:"start"`
+	End           time.Time        `json:"end"`
+	EventsCreated int              `json:"events_created"`
+	EventsSent    int              `json:"events_sent"`
+	Counts        map[string]int   `json:"counts"` // Events per event type
+	Destination   string           `json:"destination,omitempty"`
+	Errors        []string         `json:"errors,omitempty"`
+	Events        []GeneratedEvent `json:"events,omitempty"` // Returned when no destination is given
+}
+
+// LifecycleRequest describes an employee's identity lifecycle: onboarding
+// across AD, Okta and AWS, a period of normal work, then offboarding or going
+// rogue
+type LifecycleRequest struct {
+	Username      string     `json:"username,omitempty"`   // Default: a new hire outside the entity pool
+	FullName      string     `json:"full_name,omitempty"`  // Default: derived from the username
+	Department    string     `json:"department,omitempty"` // Default: random department
+	Outcome       string     `json:"outcome,omitempty"`    // offboard (default) or rogue
+	Start         *time.Time `json:"start,omitempty"`      // Onboarding day; default: ends on the latest business day
+	WorkDays      int        `json:"work_days,omitempty"`  // Business days between onboarding and exit
+	DestinationID string     `json:"destination_id,omitempty"`
+}
+
+// LifecycleResponse summarises the events generated for an identity lifecycle
+type LifecycleResponse struct {
+	Success       bool             `json:"success"`
+	Username      string           `json:"username"`
+	Email         string           `json:"email"`
+	Department    string           `json:"department"`
+	Workstation   string           `json:"workstation"`
+	Outcome       string           `json:"outcome"`
+	Start         time.Time        `json:"start"`
+	End           time.Time        `json:"end"`
+	EventsCreated int              `json:"events_created"`
+	EventsSent    int              `json:"events_sent"`
+	Counts        map[string]int   `json:"counts"` // Events per event type
+	Destination   string           `json:"destination,omitempty"`
+	Errors        []string         `json:"errors,omitempty"`
+	Events        []GeneratedEvent `json:"events,omitempty"` // Returned when no destination is given
+}
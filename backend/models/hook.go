@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// EventHook is a Starlark script that changes events after they are
+// generated and before they are sent. The script defines
+// transform(event), which gets the event as a dict and returns it, changed
+// or not; returning None leaves the event as it was.
+type EventHook struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name" binding:"required"`
+	Description string    `json:"description,omitempty"`
+	EventType   string    `json:"event_type,omitempty"` // Empty runs the hook on every event type
+	Script      string    `json:"script" binding:"required"`
+	Order       int       `json:"order"` // Hooks run in ascending order, then by name
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HookTestRequest runs a script against one generated event without saving it
+type HookTestRequest struct {
+	Script          string                 `json:"script" binding:"required"`
+	EventType       string                 `json:"event_type" binding:"required"`
+	EventID         string                 `json:"event_id,omitempty"`
+	Overrides       map[string]interface{} `json:"overrides,omitempty"`
+	StrictOverrides bool                   `json:"strict_overrides,omitempty"`
+}
+
+// HookTestResult shows an event before and after a hook script ran
+type HookTestResult struct {
+	Original    *GeneratedEvent `json:"original"`
+	Transformed *GeneratedEvent `json:"transformed"`
+}
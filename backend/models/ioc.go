@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// IOCType is the kind of value an indicator matches
+type IOCType string
+
+const (
+	IOCTypeIP     IOCType = "ip"
+	IOCTypeDomain IOCType = "domain"
+	IOCTypeMD5    IOCType = "md5"
+	IOCTypeSHA1   IOCType = "sha1"
+	IOCTypeSHA256 IOCType = "sha256"
+)
+
+// IOCTypes lists every supported indicator type
+var IOCTypes = []IOCType{IOCTypeIP, IOCTypeDomain, IOCTypeMD5, IOCTypeSHA1, IOCTypeSHA256}
+
+// IOC is a threat intel indicator that generators can embed in events
+type IOC struct {
+	Type    IOCType   `json:"type"`
+	Value   string    `json:"value" binding:"required"`
+	Source  string    `json:"source,omitempty"` // "manual", an upload name, or "feed:<id>"
+	Tags    []string  `json:"tags,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// IOC feed formats
+const (
+	IOCFormatCSV   = "csv"
+	IOCFormatSTIX  = "stix"
+	IOCFormatTAXII = "taxii"
+)
+
+// IOCFeed is a subscription to a remote indicator list. For TAXII 2.1 the
+// URL is the collection URL; for CSV and STIX it is the file itself.
+type IOCFeed struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name" binding:"required"`
+	URL             string     `json:"url" binding:"required"`
+	Format          string     `json:"format"` // csv, stix or taxii
+	Username        string     `json:"username,omitempty"`
+	Password        string     `json:"password,omitempty"`
+	IntervalMinutes int        `json:"interval_minutes"`
+	LastPolledAt    *time.Time `json:"last_polled_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	Count           int        `json:"count"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// IOCConfig controls how often generators embed indicators
+type IOCConfig struct {
+	// Rate is the percentage of eligible IPs, domains and hashes that are
+	// replaced by an indicator from the pool
+	Rate float64 `json:"rate"`
+}
+
+// AddIOCsRequest adds indicators to the pool by hand
+type AddIOCsRequest struct {
+	Source     string `json:"source,omitempty"` // defaults to "manual"
+	Indicators []IOC  `json:"indicators" binding:"required"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "fmt"
+
+// ServerLimits are guardrails that keep a shared instance from being driven
+// into resource exhaustion. Zero leaves a limit unset.
+type ServerLimits struct {
+	MaxStreams                    int     `json:"max_streams"`                       // Noise run and generate requests running at once
+	MaxEventsPerSecond            float64 `json:"max_events_per_second"`             // Across every stream
+	MaxDestinationEventsPerSecond float64 `json:"max_destination_events_per_second"` // Into any one destination
+	// CPU and memory use, in percent of the container's limit, above which
+	// noise runs throttle themselves; 0 or 100 leaves it unset
+	MaxCPUPercent    float64 `json:"max_cpu_percent"`
+	MaxMemoryPercent float64 `json:"max_memory_percent"`
+}
+
+// Validate checks the limits are not negative
+func (l *ServerLimits) Validate() error {
+	if l.MaxStreams < 0 || l.MaxEventsPerSecond < 0 || l.MaxDestinationEventsPerSecond < 0 {
+		return fmt.Errorf("server limits cannot be negative")
+	}
+	if l.MaxCPUPercent < 0 || l.MaxCPUPercent > 100 || l.MaxMemoryPercent < 0 || l.MaxMemoryPercent > 100 {
+		return fmt.Errorf("max_cpu_percent and max_memory_percent must be between 0 and 100")
+	}
+	return nil
+}
@@ -7,19 +7,36 @@ type NoiseConfig struct {
 	ID             string               `json:"id,omitempty"`
 	Name           string               `json:"name,omitempty"`
 	DestinationID  string               `json:"destination_id,omitempty"`  // Default destination (fallback)
-	RatePerSecond  float64              `json:"rate_per_second" binding:"required,min=0.1,max=10000"`
+	RatePerSecond  float64              `json:"rate_per_second" binding:"required,min=0.1,max=1000000"`
+	Workers        int                  `json:"workers,omitempty"` // Generation workers, default one per CPU
 	EnabledSources []EnabledEventSource `json:"enabled_sources" binding:"required,min=1"`
+	Budget         *VolumeBudget        `json:"budget,omitempty"` // Per-destination volume budget
+	Completion     *Completion          `json:"completion,omitempty"`             // Stop the run by itself
+	Mirrors        []string             `json:"mirror_destination_ids,omitempty"` // Also receive every event
+	Cardinality    *CardinalityLimits   `json:"cardinality,omitempty"`            // Distinct values and duplicates
+	TimestampFuzz  *TimestampFuzz       `json:"timestamp_fuzz,omitempty"`         // Vary timestamp formats and timezones
+	ClockSkew      *ClockSkew           `json:"clock_skew,omitempty"`             // Skew host clocks and delay events
+	OutOfOrder     *OutOfOrder          `json:"out_of_order,omitempty"`           // Send a share of events after newer ones
+	Chaos          *ChaosConfig         `json:"chaos,omitempty"`                  // Send a share of events broken
+	Behavior       *BehaviorBaseline    `json:"behavior,omitempty"`               // Keep users to their behavioral profiles
+	Seed           int64                `json:"seed,omitempty"`                   // Draw random values from this seed
+	Reconcile      bool                 `json:"reconcile,omitempty"`              // Count the events indexed once the run stops
 	CreatedAt      time.Time            `json:"created_at,omitempty"`
 	UpdatedAt      time.Time            `json:"updated_at,omitempty"`
 }
 
 // EnabledEventSource represents an enabled event type with weight
 type EnabledEventSource struct {
-	EventTypeID   string   `json:"event_type_id" binding:"required"`
-	TemplateIDs   []string `json:"template_ids,omitempty"`   // Empty means all templates
-	Weight        int      `json:"weight"`                   // 1-100, relative frequency
-	Enabled       bool     `json:"enabled"`
-	DestinationID string   `json:"destination_id,omitempty"` // Per-source destination (overrides global)
+	EventTypeID   string        `json:"event_type_id" binding:"required"`
+	TemplateIDs   []string      `json:"template_ids,omitempty"`   // Empty means all templates
+	Weight        int           `json:"weight"`                   // 1-100, relative frequency
+	Enabled       bool          `json:"enabled"`
+	DestinationID string        `json:"destination_id,omitempty"` // Per-source destination (overrides global)
+	Render        *RenderConfig `json:"render,omitempty"`         // Render the source's templates as CSV, kv or LTSV
+	// Overrides apply to every template of the source. A preset's fields
+	// are merged in under them when the stream starts or is updated.
+	Overrides map[string]interface{} `json:"overrides,omitempty"`
+	Preset    string                 `json:"preset,omitempty"` // Override preset name or ID
 }
 
 // NoiseStatus represents the current state of noise generation
@@ -27,7 +44,9 @@ type NoiseStatus struct {
 	Running       bool         `json:"running"`
 	StartedAt     *time.Time   `json:"started_at,omitempty"`
 	CurrentConfig *NoiseConfig `json:"current_config,omitempty"`
+	StopReason    string       `json:"stop_reason,omitempty"` // Set when generation stopped by itself
 	Stats         NoiseStats   `json:"stats"`
+	Shards        []NoiseShard `json:"shards,omitempty"` // Per instance, when the run is distributed
 }
 
 // NoiseStats represents generation statistics
@@ -35,19 +54,40 @@ type NoiseStats struct {
 	TotalGenerated  int64            `json:"total_generated"`
 	TotalSent       int64            `json:"total_sent"`
 	TotalErrors     int64            `json:"total_errors"`
+	TotalDuplicates int64            `json:"total_duplicates,omitempty"` // Copies sent for the duplicate rate, included in the totals
+	TotalMalformed  int64            `json:"total_malformed,omitempty"`  // Events broken by chaos, included in the totals
+	TotalReordered  int64            `json:"total_reordered,omitempty"`  // Events sent after newer ones, included in the totals
+	TotalAnomalies  int64            `json:"total_anomalies,omitempty"`  // Events that broke their user's profile, included in the totals
 	EventsPerSecond float64          `json:"events_per_second"`
 	LastEventAt     *time.Time       `json:"last_event_at,omitempty"`
 	ByEventType     map[string]int64 `json:"by_event_type"`
 	ByTemplate      map[string]int64 `json:"by_template"`
 	DurationSeconds int64            `json:"duration_seconds"`
 	ErrorSamples    []string         `json:"error_samples,omitempty"` // Last 5 errors
+
+	Budget        map[string]VolumeUsage    `json:"budget,omitempty"`         // Budget usage per destination ID
+	ByDestination map[string]DeliveryResult `json:"by_destination,omitempty"` // Sends per destination ID, mirrors included
+	Resources     *ResourceUsage            `json:"resources,omitempty"`      // CPU and memory use and the run's throttle
 }
 
 // NoiseStartRequest represents a request to start noise generation
 type NoiseStartRequest struct {
 	DestinationID  string               `json:"destination_id,omitempty"`  // Default destination (fallback)
-	RatePerSecond  float64              `json:"rate_per_second" binding:"required,min=0.1,max=10000"`
+	RatePerSecond  float64              `json:"rate_per_second" binding:"required,min=0.1,max=1000000"`
+	Workers        int                  `json:"workers,omitempty"` // Generation workers, default one per CPU
 	EnabledSources []EnabledEventSource `json:"enabled_sources" binding:"required,min=1"`
+	Budget         *VolumeBudget        `json:"budget,omitempty"` // Stop each destination at this volume
+	Completion     *Completion          `json:"completion,omitempty"`             // Stop after a number of events, bytes or seconds, or when a URL says so
+	Mirrors        []string             `json:"mirror_destination_ids,omitempty"` // Send every event to these as well
+	Cardinality    *CardinalityLimits   `json:"cardinality,omitempty"`            // Limit distinct users, hosts, IPs and URLs
+	TimestampFuzz  *TimestampFuzz       `json:"timestamp_fuzz,omitempty"`         // Vary timestamp formats and timezones
+	ClockSkew      *ClockSkew           `json:"clock_skew,omitempty"`             // Skew host clocks and delay events
+	OutOfOrder     *OutOfOrder          `json:"out_of_order,omitempty"`           // Send a share of events after newer ones
+	Chaos          *ChaosConfig         `json:"chaos,omitempty"`                  // Send a share of events broken
+	Behavior       *BehaviorBaseline    `json:"behavior,omitempty"`               // Keep users to their profiles, breaking them at a rate
+	Seed           int64                `json:"seed,omitempty"`                   // Draw random values from this seed; with one worker a run repeats
+	Reconcile      bool                 `json:"reconcile,omitempty"`              // Count the events indexed once the run stops
+	DryRun         bool                 `json:"dry_run,omitempty"`                // Estimate the volume instead of starting
 }
 
 // NoiseUpdateRequest represents a request to update running configuration
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Notification events
+const (
+	NotifyStreamStarted     = "stream_started"
+	NotifyStreamFinished    = "stream_finished"
+	NotifyCircuitOpened     = "circuit_opened"     // A destination kept failing and sends to it are paused
+	NotifyQuotaExceeded     = "quota_exceeded"     // A destination's volume budget is used up
+	NotifyResourcePressure  = "resource_pressure"  // A run throttled itself as CPU or memory neared its limit
+	NotifyEventsDropped     = "events_dropped"     // A destination indexed fewer events than a run sent it
+	NotifyDetectionsMissing = "detections_missing" // A scenario's expected detections did not all fire
+	NotifyTest              = "test"               // Sent by /api/notifications/:id/test only
+)
+
+// NotificationEvents are the events a channel can subscribe to
+var NotificationEvents = []string{NotifyStreamStarted, NotifyStreamFinished, NotifyCircuitOpened, NotifyQuotaExceeded, NotifyResourcePressure, NotifyEventsDropped, NotifyDetectionsMissing}
+
+// Notification channel types
+const (
+	NotificationWebhook = "webhook"
+	NotificationSlack   = "slack"
+	NotificationEmail   = "email"
+)
+
+// NotificationChannel sends stream lifecycle notifications to a webhook, a
+// Slack incoming webhook or email recipients. Password, the Slack URL and
+// webhook header values are secrets: they are encrypted when saved and
+// masked in responses.
+type NotificationChannel struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name" binding:"required"`
+	Type    string   `json:"type" binding:"required"` // webhook, slack or email
+	Enabled bool     `json:"enabled"`
+	Events  []string `json:"events,omitempty"` // Empty subscribes to every event
+
+	URL     string            `json:"url,omitempty"`     // Webhook or Slack incoming webhook URL
+	Headers map[string]string `json:"headers,omitempty"` // Added to webhook requests
+
+	SMTPHost string   `json:"smtp_host,omitempty"`
+	SMTPPort int      `json:"smtp_port,omitempty"` // Defaults to 587; 465 uses implicit TLS
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Notification is one lifecycle event, posted as is to webhooks
+type Notification struct {
+	Event         string                 `json:"event"`
+	Summary       string                 `json:"summary"` // One line; the Slack text and email subject
+	Instance      string                 `json:"instance,omitempty"`
+	DestinationID string                 `json:"destination_id,omitempty"`
+	Destination   string                 `json:"destination,omitempty"`
+	Details       map[string]interface{} `json:"details,omitempty"`
+	Time          time.Time              `json:"time"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "fmt"
+
+// Longest an out-of-order event can be held back
+const MaxOutOfOrderDelaySeconds = 3600
+
+// OutOfOrder holds a share of a noise run's events back and sends them
+// after newer ones, to test SIEM features that assume events arrive in
+// order. Held events keep the time they were generated at.
+type OutOfOrder struct {
+	Rate            float64 `json:"rate"`                        // Share of events held back, 0-1
+	MaxDelaySeconds float64 `json:"max_delay_seconds,omitempty"` // Longest an event is held; default 30
+}
+
+// Validate checks the rate and delay are in range
+func (o *OutOfOrder) Validate() error {
+	if o.Rate < 0 || o.Rate > 1 {
+		return fmt.Errorf("out_of_order.rate must be between 0 and 1")
+	}
+	if o.MaxDelaySeconds < 0 || o.MaxDelaySeconds > MaxOutOfOrderDelaySeconds {
+		return fmt.Errorf("out_of_order.max_delay_seconds must be between 0 and %d", MaxOutOfOrderDelaySeconds)
+	}
+	return nil
+}
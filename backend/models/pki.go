@@ -0,0 +1,30 @@
+package models
+
+import "fmt"
+
+// PKIConfig controls the certificates servers present in TLS events
+type PKIConfig struct {
+	// ExpiredRate is the percentage of TLS connections to a server whose
+	// certificate has expired
+	ExpiredRate float64 `json:"expired_rate"`
+	// SelfSignedRate is the percentage of TLS connections to a server with
+	// a self-signed certificate
+	SelfSignedRate float64 `json:"self_signed_rate"`
+	// GenerateCertificates signs a real X.509 certificate for each server,
+	// so fingerprints are digests of an actual DER encoding
+	GenerateCertificates bool `json:"generate_certificates"`
+}
+
+// Validate checks the rates are percentages that add up to at most 100
+func (c *PKIConfig) Validate() error {
+	if c.ExpiredRate < 0 || c.ExpiredRate > 100 {
+		return fmt.Errorf("expired_rate must be between 0 and 100")
+	}
+	if c.SelfSignedRate < 0 || c.SelfSignedRate > 100 {
+		return fmt.Errorf("self_signed_rate must be between 0 and 100")
+	}
+	if c.ExpiredRate+c.SelfSignedRate > 100 {
+		return fmt.Errorf("expired_rate and self_signed_rate add up to more than 100")
+	}
+	return nil
+}
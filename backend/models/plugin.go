@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Kinds of generator plugin
+const (
+	PluginKindGo      = "go"      // Go plugin (.so) exporting NewGenerator
+	PluginKindProcess = "process" // Executable speaking the plugin protocol on stdin and stdout
+)
+
+// Generator plugin states
+const (
+	PluginLoaded = "loaded"
+	PluginFailed = "failed"
+)
+
+// GeneratorPlugin describes a generator plugin found in the plugin directory
+type GeneratorPlugin struct {
+	ID         string     `json:"id"` // File name in the plugin directory
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	APIVersion int        `json:"api_version,omitempty"`
+	EventType  *EventType `json:"event_type,omitempty"`
+	Templates  int        `json:"templates"`
+	LoadedAt   *time.Time `json:"loaded_at,omitempty"`
+}
+
+// LoadPluginRequest loads one file from the plugin directory
+type LoadPluginRequest struct {
+	File string `json:"file" binding:"required"`
+}
+
+// Plugin protocol methods
+const (
+	PluginDescribe = "describe"
+	PluginGenerate = "generate"
+)
+
+// PluginRequest is one line the server writes to a process plugin's stdin
+type PluginRequest struct {
+	Method     string                 `json:"method"`
+	TemplateID string                 `json:"template_id,omitempty"`
+	Overrides  map[string]interface{} `json:"overrides,omitempty"`
+}
+
+// PluginResponse is one line a process plugin writes to stdout in answer.
+// describe fills APIVersion, EventType and Templates; generate fills Event.
+type PluginResponse struct {
+	APIVersion int             `json:"api_version,omitempty"`
+	EventType  *EventType      `json:"event_type,omitempty"`
+	Templates  []EventTemplate `json:"templates,omitempty"`
+	Event      *GeneratedEvent `json:"event,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
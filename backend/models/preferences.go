@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// TemplateRef names a template, or a whole event type when TemplateID is
+// empty. Name is filled in on responses.
+type TemplateRef struct {
+	EventType  string `json:"event_type"`
+	TemplateID string `json:"template_id,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+// DestinationRef names a destination. Name and Type are filled in on
+// responses.
+type DestinationRef struct {
+	ID   string          `json:"id"`
+	Name string          `json:"name,omitempty"`
+	Type DestinationType `json:"type,omitempty"`
+}
+
+// Favorites are the templates and destinations a user pinned, in the
+// order they were added
+type Favorites struct {
+	User         string           `json:"user"`
+	Templates    []TemplateRef    `json:"templates"`
+	Destinations []DestinationRef `json:"destinations"`
+}
+
+// RecentTemplate is a template or event type a user generated from
+type RecentTemplate struct {
+	TemplateRef
+	Uses     int       `json:"uses"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// RecentDestination is a destination a user sent events to
+type RecentDestination struct {
+	DestinationRef
+	Uses     int       `json:"uses"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// RecentUse is what a user generated from and sent to lately, most
+// recently used first
+type RecentUse struct {
+	User         string              `json:"user"`
+	Templates    []RecentTemplate    `json:"templates"`
+	Destinations []RecentDestination `json:"destinations"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// OverridePreset is a named set of field overrides for an event type, or
+// for one of its templates, e.g. a customer's domain, OU and IP ranges.
+// Generate, preview and noise requests refer to it by name with "preset";
+// overrides given in the request win over the preset's.
+type OverridePreset struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name" binding:"required"` // Unique within the event type
+	Description string                 `json:"description,omitempty"`
+	EventType   string                 `json:"event_type" binding:"required"`
+	TemplateID  string                 `json:"template_id,omitempty"` // Empty applies to every template of the event type
+	Overrides   map[string]interface{} `json:"overrides" binding:"required"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
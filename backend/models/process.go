@@ -0,0 +1,27 @@
+package models
+
+import "fmt"
+
+// Suspicion levels of generated process command lines
+const (
+	SuspicionNone   = "none"   // Only benign command lines
+	SuspicionLow    = "low"    // 1% of processes run attack command lines
+	SuspicionMedium = "medium" // 5%
+	SuspicionHigh   = "high"   // 25%
+)
+
+// ProcessConfig controls the command lines of generated processes
+type ProcessConfig struct {
+	// SuspicionLevel is how often processes run an attack variant of their
+	// command line (encoded PowerShell, LOLBin abuse) instead of a benign one
+	SuspicionLevel string `json:"suspicion_level"`
+}
+
+// Validate checks the suspicion level is known
+func (c *ProcessConfig) Validate() error {
+	switch c.SuspicionLevel {
+	case SuspicionNone, SuspicionLow, SuspicionMedium, SuspicionHigh:
+		return nil
+	}
+	return fmt.Errorf("suspicion_level must be %s, %s, %s or %s", SuspicionNone, SuspicionLow, SuspicionMedium, SuspicionHigh)
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Reconcile has a Splunk HEC or Elasticsearch destination count the
+// events it indexed from a run, to compare with the events sent. Splunk is
+// searched through its REST API, on port 8089 of the HEC host unless URL
+// says otherwise; Elasticsearch through the count API at the destination
+// URL. Zero values take the destination's settings.
+type Reconcile struct {
+	URL          string `json:"url,omitempty"`      // Splunk management or Elasticsearch URL
+	Token        string `json:"token,omitempty"`    // Splunk bearer token or Elasticsearch API key; the destination's for Elasticsearch
+	Username     string `json:"username,omitempty"` // Basic auth instead of a token
+	Password     string `json:"password,omitempty"`
+	Index        string `json:"index,omitempty"`         // Index, or pattern, the events land in
+	Filter       string `json:"filter,omitempty"`        // More SPL search terms, or an Elasticsearch query string
+	TimeField    string `json:"time_field,omitempty"`    // Elasticsearch field with the event time; @timestamp by default
+	DelaySeconds int    `json:"delay_seconds,omitempty"` // Wait after a run for indexing to catch up; 30 by default
+}
+
+// Validate checks the reconciliation settings for a destination of type t
+func (r *Reconcile) Validate(t DestinationType) error {
+	if t != DestinationTypeHEC && t != DestinationTypeElasticsearch {
+		return fmt.Errorf("reconcile is only supported for hec and elasticsearch destinations")
+	}
+	if r.URL != "" {
+		u, err := url.Parse(r.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("reconcile.url must be an http or https url")
+		}
+	}
+	if r.Password != "" && r.Username == "" {
+		return fmt.Errorf("reconcile.username is required with reconcile.password")
+	}
+	if r.DelaySeconds < 0 || r.DelaySeconds > 3600 {
+		return fmt.Errorf("reconcile.delay_seconds must be between 0 and 3600")
+	}
+	return nil
+}
+
+// Reconciliation outcomes
+const (
+	ReconcileMatched = "matched" // Every event sent was indexed
+	ReconcileDropped = "dropped" // Fewer events were indexed than sent
+	ReconcileExtra   = "extra"   // More were indexed, e.g. from another sender
+	ReconcileFailed  = "failed"  // The destination could not be counted
+	ReconcileSkipped = "skipped" // The destination has no reconcile settings
+)
+
+// Reconciliation compares the events a run sent to a destination with the
+// events the destination indexed between its start and the check
+type Reconciliation struct {
+	RunID         string    `json:"run_id"`
+	DestinationID string    `json:"destination_id"`
+	Destination   string    `json:"destination,omitempty"`
+	Status        string    `json:"status"`
+	Expected      int64     `json:"expected"`        // Events sent
+	Indexed       int64     `json:"indexed"`         // Events counted in the destination
+	Missing       int64     `json:"missing"`         // Expected less indexed, when positive
+	DropPercent   float64   `json:"drop_percent"`    // Missing as a share of expected
+	Query         string    `json:"query,omitempty"` // Search or count request made
+	Error         string    `json:"error,omitempty"` // Why the count failed
+	From          time.Time `json:"from"`            // Start of the window counted
+	To            time.Time `json:"to"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// Outcome sets the status and drop figures from the expected and indexed
+// counts
+func (r *Reconciliation) Outcome() {
+	switch {
+	case r.Indexed == r.Expected:
+		r.Status = ReconcileMatched
+	case r.Indexed < r.Expected:
+		r.Status = ReconcileDropped
+		r.Missing = r.Expected - r.Indexed
+		r.DropPercent = float64(int64(float64(r.Missing)/float64(r.Expected)*10000)) / 100
+	default:
+		r.Status = ReconcileExtra
+	}
+}
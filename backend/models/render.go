@@ -0,0 +1,18 @@
+package models
+
+// Raw formats an event can be rendered in instead of its native one
+const (
+	RenderCSV  = "csv"  // One row per event, optionally after a header row
+	RenderKV   = "kv"   // key=value pairs, quoted when needed
+	RenderLTSV = "ltsv" // Labeled tab-separated values, label:value
+)
+
+// RenderConfig renders the raw event from its fields in an alternate
+// layout, to test forwarder props and transforms against it. Nested fields
+// are flattened to dotted names; the pseudo-field _time is the event time.
+type RenderConfig struct {
+	Format    string   `json:"format"`              // csv, kv or ltsv
+	Columns   []string `json:"columns,omitempty"`   // Fields and their order; empty means _time then every field by name
+	Delimiter string   `json:"delimiter,omitempty"` // CSV column separator (default ",") or kv pair separator (default " ")
+	Header    bool     `json:"header,omitempty"`    // CSV: put a header row before the first event of each template
+}
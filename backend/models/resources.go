@@ -0,0 +1,20 @@
+package models
+
+// Where resource usage is read from
+const (
+	ResourceSourceCgroupV2 = "cgroup_v2"
+	ResourceSourceCgroupV1 = "cgroup_v1"
+	ResourceSourceHost     = "host" // No container limit; the whole machine
+)
+
+// ResourceUsage is what a noise run's instance uses of the CPU and memory
+// it is allowed, and how far the run throttled itself because of it
+type ResourceUsage struct {
+	CPUPercent       float64 `json:"cpu_percent"`        // Of CPULimit
+	CPULimit         float64 `json:"cpu_limit"`          // Cores
+	MemoryBytes      int64   `json:"memory_bytes"`       // Working set, without reclaimable page cache
+	MemoryLimitBytes int64   `json:"memory_limit_bytes"` // 0 when it cannot be read
+	MemoryPercent    float64 `json:"memory_percent"`
+	Source           string  `json:"source"`
+	Throttle         float64 `json:"throttle"` // Share of rate_per_second generated, 0-1
+}
@@ -0,0 +1,24 @@
+package models
+
+// RoutingRule sends matching events to a different HEC index, source or
+// sourcetype, or to another destination. A rule matches when every non-empty
+// list matches; the first matching rule of a destination applies.
+type RoutingRule struct {
+	Name        string   `json:"name,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"` // Event type IDs, e.g. windows_security
+	Categories  []string `json:"categories,omitempty"`  // Event type categories, e.g. network
+	Sourcetypes []string `json:"sourcetypes,omitempty"` // Sourcetype patterns; * and ? are wildcards, e.g. WinEventLog:*
+
+	Index         string `json:"index,omitempty"`          // HEC index for matching events
+	Source        string `json:"source,omitempty"`         // HEC source for matching events
+	Sourcetype    string `json:"sourcetype,omitempty"`     // Sourcetype for matching events
+	DestinationID string `json:"destination_id,omitempty"` // Send matching events to this destination instead
+}
+
+// Route is where a routing rule sends an event. Empty values keep the
+// destination's own settings.
+type Route struct {
+	Index      string
+	Source     string
+	Sourcetype string
+}
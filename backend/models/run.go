@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Run kinds
+const (
+	RunKindNoise = "noise" // A noise run, from start until it stopped
+	RunKindBatch = "batch" // One /api/generate request
+)
+
+// RunRecord is a completed noise run or generate batch with the
+// configuration it started with, so it can be replayed
+type RunRecord struct {
+	ID              string             `json:"id"`
+	Kind            string             `json:"kind"`
+	Noise           *NoiseStartRequest `json:"noise,omitempty"` // Configuration of a noise run
+	Batch           *GenerateRequest   `json:"batch,omitempty"` // Request of a batch
+	Seed            int64              `json:"seed,omitempty"`  // 0 when the run was not seeded
+	ReplayOf        string             `json:"replay_of,omitempty"`
+	StartedAt       time.Time          `json:"started_at"`
+	StoppedAt       time.Time          `json:"stopped_at"`
+	DurationSeconds float64            `json:"duration_seconds"`
+	StopReason      string             `json:"stop_reason,omitempty"`
+	EventsGenerated int64              `json:"events_generated"`
+	EventsSent      int64              `json:"events_sent"`
+	Errors          int64              `json:"errors"`
+	ErrorSamples    []string           `json:"error_samples,omitempty"` // At most 5
+	Deliveries      []DeliveryResult   `json:"deliveries,omitempty"`    // Sends per destination
+
+	Reconciliations []Reconciliation `json:"reconciliations,omitempty"` // Latest count of the events indexed, per destination
+}
+
+// ReplayRequest re-runs a recorded run. SameSeed reuses the run's seed;
+// otherwise Seed, if set, seeds the replay.
+type ReplayRequest struct {
+	SameSeed bool  `json:"same_seed,omitempty"`
+	Seed     int64 `json:"seed,omitempty"`
+}
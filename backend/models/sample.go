@@ -0,0 +1,31 @@
+package models
+
+// SampleRequest selects the templates sampled by GET /api/samples. Empty
+// Category samples every category.
+type SampleRequest struct {
+	Count    int    // Events per template, default 3
+	Category string // Event type category, e.g. windows or cloud
+	Output   string // raw or fields; empty keeps both
+}
+
+// SampleSet is a corpus of example events, Count from every template
+type SampleSet struct {
+	Count     int               `json:"count"` // Events per template
+	Templates int               `json:"templates"`
+	Events    int               `json:"events"`
+	Samples   []TemplateSamples `json:"samples"`
+}
+
+// TemplateSamples holds the example events of one template
+type TemplateSamples struct {
+	EventType     string           `json:"event_type"`
+	Category      string           `json:"category"`
+	TemplateID    string           `json:"template_id"`
+	Name          string           `json:"name"`
+	Format        string           `json:"format"`
+	Sourcetype    string           `json:"sourcetype,omitempty"`
+	SchemaVersion int              `json:"schema_version"`
+	Deprecated    bool             `json:"deprecated,omitempty"`
+	Events        []GeneratedEvent `json:"events"`
+	Error         string           `json:"error,omitempty"` // First generation error; Events holds those made before it
+}
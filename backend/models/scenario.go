@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// ScenarioMode defines how a scenario's target is applied to a metric
+type ScenarioMode string
+
+const (
+	// ScenarioModeAbsolute moves the metric towards Target
+	ScenarioModeAbsolute ScenarioMode = "absolute"
+	// ScenarioModeMultiplier scales the metric by Target
+	ScenarioModeMultiplier ScenarioMode = "multiplier"
+)
+
+// Scenario phases reported by ScenarioStatus
+const (
+	ScenarioPhaseIdle      = "idle"
+	ScenarioPhaseRamp      = "ramp"
+	ScenarioPhaseHold      = "hold"
+	ScenarioPhaseRecover   = "recover"
+	ScenarioPhaseCompleted = "completed"
+)
+
+// Scenario describes a scripted KPI degradation: the metric ramps towards
+// Target, holds there, then recovers back to its normal behaviour
+type Scenario struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name" binding:"required"`
+	Description    string            `json:"description,omitempty"`
+	Metric         string            `json:"metric" binding:"required"` // Metric name; a trailing * matches a prefix
+	Match          map[string]string `json:"match,omitempty"`           // Dimension filters, e.g. {"host": "db-primary-03"}
+	Mode           ScenarioMode      `json:"mode"`
+	Target         float64           `json:"target"`
+	RampSeconds    int               `json:"ramp_seconds"`
+	HoldSeconds    int               `json:"hold_seconds"`
+	RecoverSeconds int               `json:"recover_seconds"`
+
+	// Alerts the scenario should raise, checked DetectionWaitSeconds
+	// (default 300) after it ends
+	ExpectedDetections   []ExpectedDetection `json:"expected_detections,omitempty"`
+	DetectionWaitSeconds int                 `json:"detection_wait_seconds,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ScenarioStatus reports where a triggered scenario is in its timeline
+type ScenarioStatus struct {
+	Phase       string     `json:"phase"`
+	Progress    float64    `json:"progress"` // 0 = normal behaviour, 1 = fully at target
+	TriggeredAt *time.Time `json:"triggered_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+}
+
+// ScenarioWithStatus pairs a scenario with its current status
+type ScenarioWithStatus struct {
+	Scenario
+	Status ScenarioStatus `json:"status"`
+}
+
+// TriggerScenarioRequest optionally sets when a scenario's timeline starts.
+// Backdating lets a test jump straight into the hold or recover phase.
+type TriggerScenarioRequest struct {
+	At *time.Time `json:"at,omitempty"`
+}
@@ -0,0 +1,52 @@
+package models
+
+// SigmaRequest asks for events a Sigma rule should match and near misses it
+// should not, for testing the rule
+type SigmaRequest struct {
+	Rule          string            `json:"rule" binding:"required"` // The rule's YAML
+	EventType     string            `json:"event_type,omitempty"`    // Generate this event type instead of the one mapped from the logsource
+	EventID       string            `json:"event_id,omitempty"`      // Template of event_type; default chosen from the rule
+	FieldMap      map[string]string `json:"field_map,omitempty"`     // Sigma field to template field, e.g. "Image": "process.executable"
+	Count         int               `json:"count,omitempty"`         // Events the rule should match; default 5, at most 1000
+	Negatives     int               `json:"negatives,omitempty"`     // Events it should not match; default count, at most 1000
+	DestinationID string            `json:"destination_id,omitempty"`
+	Output        string            `json:"output,omitempty"` // raw or fields; empty returns both
+}
+
+// SigmaLogsource is the logsource block of a Sigma rule
+type SigmaLogsource struct {
+	Product  string `json:"product,omitempty" yaml:"product"`
+	Category string `json:"category,omitempty" yaml:"category"`
+	Service  string `json:"service,omitempty" yaml:"service"`
+}
+
+// SigmaRuleInfo describes the rule events were generated for
+type SigmaRuleInfo struct {
+	Title     string         `json:"title"`
+	ID        string         `json:"id,omitempty"`
+	Level     string         `json:"level,omitempty"`
+	Logsource SigmaLogsource `json:"logsource"`
+	Condition string         `json:"condition"`
+}
+
+// SigmaSample is a generated event and whether the rule matches it. An
+// event that should match but does not, or the reverse, is still returned
+// and counted in the response's warnings.
+type SigmaSample struct {
+	Matches   bool                   `json:"matches"`
+	Overrides map[string]interface{} `json:"overrides"` // What was set to (not) match the rule
+	Event     GeneratedEvent         `json:"event"`
+}
+
+// SigmaResponse holds the events generated for a Sigma rule
+type SigmaResponse struct {
+	Rule        SigmaRuleInfo     `json:"rule"`
+	EventType   string            `json:"event_type"`
+	FieldMap    map[string]string `json:"field_map"` // Sigma field to the template field it was set on
+	Positives   []SigmaSample     `json:"positives"`
+	Negatives   []SigmaSample     `json:"negatives"`
+	EventsSent  int               `json:"events_sent"`
+	Destination string            `json:"destination,omitempty"`
+	Errors      []string          `json:"errors,omitempty"`
+	Warnings    []string          `json:"warnings,omitempty"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	themeLabel   = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+	themeNetBIOS = regexp.MustCompile(`^[A-Z0-9][A-Z0-9-]{0,14}$`)
+)
+
+// Theme is the naming convention of the simulated organization: the
+// domains and resource names generators use instead of example.com and
+// prod.internal
+type Theme struct {
+	Prefix         string `json:"prefix"`          // Prefix of server, workstation and bucket names, e.g. acme-corp; empty for none
+	Domain         string `json:"domain"`          // Public domain of websites, mail and SaaS tenants
+	ADDomain       string `json:"ad_domain"`       // Active Directory DNS domain of users and workstations
+	NetBIOSDomain  string `json:"netbios_domain"`  // Active Directory NetBIOS name; empty for the first label of ADDomain
+	InternalDomain string `json:"internal_domain"` // DNS suffix of servers and services
+}
+
+// Validate lower-cases the domains and prefix, checks they are DNS names
+// and upper-cases the NetBIOS name
+func (t *Theme) Validate() error {
+	t.Prefix = strings.ToLower(strings.TrimSpace(t.Prefix))
+	if t.Prefix != "" && !themeLabel.MatchString(t.Prefix) {
+		return fmt.Errorf("prefix must be letters, digits and hyphens")
+	}
+	for _, d := range []struct {
+		name  string
+		value *string
+	}{{"domain", &t.Domain}, {"ad_domain", &t.ADDomain}, {"internal_domain", &t.InternalDomain}} {
+		*d.value = strings.ToLower(strings.Trim(strings.TrimSpace(*d.value), "."))
+		if *d.value == "" {
+			return fmt.Errorf("%s is required", d.name)
+		}
+		for _, label := range strings.Split(*d.value, ".") {
+			if !themeLabel.MatchString(label) {
+				return fmt.Errorf("%s %q is not a DNS name", d.name, *d.value)
+			}
+		}
+	}
+	t.NetBIOSDomain = strings.ToUpper(strings.TrimSpace(t.NetBIOSDomain))
+	if t.NetBIOSDomain != "" && !themeNetBIOS.MatchString(t.NetBIOSDomain) {
+		return fmt.Errorf("netbios_domain must be at most 15 letters, digits and hyphens")
+	}
+	return nil
+}
+
+// NetBIOS returns the NetBIOS domain name, defaulting to the first label of
+// the AD domain
+func (t Theme) NetBIOS() string {
+	if t.NetBIOSDomain != "" {
+		return t.NetBIOSDomain
+	}
+	label := strings.SplitN(t.ADDomain, ".", 2)[0]
+	if len(label) > 15 {
+		label = label[:15]
+	}
+	return strings.ToUpper(label)
+}
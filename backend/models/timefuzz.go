@@ -0,0 +1,29 @@
+package models
+
+// Timestamp formats a fuzzed event can be rewritten to
+const (
+	TimestampRFC3339       = "rfc3339"        // 2024-03-05T14:07:09-05:00
+	TimestampRFC3339Millis = "rfc3339_millis" // 2024-03-05T14:07:09.123-05:00
+	TimestampEpoch         = "epoch"          // 1709665629
+	TimestampEpochMillis   = "epoch_millis"   // 1709665629123
+	TimestampSyslog        = "syslog"         // Mar  5 14:07:09, local time without year or zone
+	TimestampNoTZ          = "no_tz"          // 2024-03-05 14:07:09, local time without zone
+	TimestampApache        = "apache"         // 05/Mar/2024:14:07:09 -0500
+	TimestampUS            = "us"             // 03/05/2024 02:07:09 PM, local time without zone
+	TimestampRFC1123       = "rfc1123"        // Tue, 05 Mar 2024 14:07:09 EST
+)
+
+// Timestamp fuzz modes
+const (
+	TimestampFuzzPerEvent    = "event"    // Every event picks a format and timezone
+	TimestampFuzzPerTemplate = "template" // Each template keeps one format and timezone
+)
+
+// TimestampFuzz rewrites the timestamps of generated events in varying
+// formats and timezones, to test timestamp extraction in the SIEM
+type TimestampFuzz struct {
+	Formats   []string `json:"formats,omitempty"`   // Formats to pick from; empty means all
+	Timezones []string `json:"timezones,omitempty"` // IANA names or offsets such as "+05:30"; empty means a spread around the world
+	Mode      string   `json:"mode,omitempty"`      // event (default) or template
+	Rate      float64  `json:"rate,omitempty"`      // Share of events rewritten, 0-1; default 1
+}
@@ -0,0 +1,18 @@
+package models
+
+import "fmt"
+
+// WebConfig controls the requests of web and HTTP events
+type WebConfig struct {
+	// AttackRate is the percentage of requests that carry a web attack
+	// payload (SQL injection, XSS, path traversal) or come from a scanner
+	AttackRate float64 `json:"attack_rate"`
+}
+
+// Validate checks the attack rate is a percentage
+func (c *WebConfig) Validate() error {
+	if c.AttackRate < 0 || c.AttackRate > 100 {
+		return fmt.Errorf("attack_rate must be between 0 and 100")
+	}
+	return nil
+}
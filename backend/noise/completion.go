@@ -0,0 +1,129 @@
+package noise
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+var stopURLClient = &http.Client{Timeout: 10 * time.Second}
+
+// completion tracks a run against its completion limits
+type completion struct {
+	cfg      models.Completion
+	deadline time.Time // Zero without a duration limit
+	events   atomic.Int64
+	bytes    atomic.Int64
+}
+
+// newCompletion returns the completion of a run started at startedAt that
+// has sent sent events before, or nil if cfg is nil
+func newCompletion(cfg *models.Completion, startedAt time.Time, sent int64) *completion {
+	if cfg == nil {
+		return nil
+	}
+	c := &completion{cfg: *cfg}
+	if cfg.MaxDurationSeconds > 0 {
+		c.deadline = startedAt.Add(time.Duration(cfg.MaxDurationSeconds) * time.Second)
+	}
+	c.events.Store(sent)
+	return c
+}
+
+// admit counts an event of size bytes about to be sent. It returns why the
+// run is complete if the event would go over a limit, and "" otherwise.
+func (c *completion) admit(size int) string {
+	if c == nil {
+		return ""
+	}
+	if c.cfg.MaxEvents > 0 && c.events.Add(1) > c.cfg.MaxEvents {
+		return fmt.Sprintf("completed: %d events sent", c.cfg.MaxEvents)
+	}
+	if c.cfg.MaxBytes > 0 {
+		if c.bytes.Add(int64(size)) > int64(c.cfg.MaxBytes) {
+			c.bytes.Add(-int64(size))
+			return fmt.Sprintf("completed: %d bytes sent", c.cfg.MaxBytes)
+		}
+	}
+	return ""
+}
+
+// watch waits until r's duration is up or its stop URL says to stop, and
+// completes the run; it returns when r is cancelled
+func (g *Generator) watch(r *run) {
+	c := r.completion
+	if c == nil || c.deadline.IsZero() && c.cfg.StopURL == "" {
+		return
+	}
+	var deadline <-chan time.Time
+	if !c.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(c.deadline))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	var poll <-chan time.Time
+	if c.cfg.StopURL != "" {
+		interval := time.Duration(c.cfg.CheckSeconds) * time.Second
+		if interval == 0 {
+			interval = 30 * time.Second
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-deadline:
+			g.complete(r, fmt.Sprintf("completed: ran for %s", time.Duration(c.cfg.MaxDurationSeconds)*time.Second))
+			return
+		case <-poll:
+			if reason := c.checkStopURL(r.ctx); reason != "" {
+				g.complete(r, reason)
+				return
+			}
+		}
+	}
+}
+
+// checkStopURL polls the stop URL and returns why the run is complete if
+// its answer says to stop, and "" otherwise
+func (c *completion) checkStopURL(ctx context.Context) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.StopURL, nil)
+	if err != nil {
+		return ""
+	}
+	answer := ""
+	resp, err := stopURLClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ""
+		}
+		answer = err.Error()
+	} else {
+		resp.Body.Close()
+		answer = resp.Status
+	}
+	success := err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300
+	if success == (c.cfg.StopOn != models.StopOnFailure) {
+		return fmt.Sprintf("completed: stop url answered %s", answer)
+	}
+	return ""
+}
+
+// complete stops r, if it is still the running run, for reason
+func (g *Generator) complete(r *run, reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.running || g.run != r {
+		return
+	}
+	g.stopReason = reason
+	g.stopLocked()
+}
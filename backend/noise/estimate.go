@@ -0,0 +1,266 @@
+package noise
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"siem-event-generator/generators"
+	"siem-event-generator/models"
+)
+
+// Events generated per template to measure its size
+const (
+	estimateSamples            = 2000 // Across all templates
+	estimateSamplesPerTemplate = 50
+	estimateMinSamples         = 5
+)
+
+// estimateDestination is one destination while a run is played out
+type estimateDestination struct {
+	remainingEvents float64
+	remainingBytes  float64
+	events          float64
+	bytes           float64
+	exhaustedAfter  float64
+	reason          string
+}
+
+// Estimate works out what a run would send without sending anything. Event
+// sizes are measured from a sample of each template. With a budget the run
+// is played out as destinations run out of budget and their share of the
+// rate moves to the others, the way a real run behaves, until the run's
+// completion limits end it.
+func Estimate(config *models.NoiseConfig, destinations map[string]*models.Destination) models.DryRunEstimate {
+	est := models.DryRunEstimate{
+		DryRun:          true,
+		EventsPerSecond: config.RatePerSecond,
+		Templates:       make([]models.TemplateEstimate, 0),
+	}
+	entries := poolEntries(config, func(id string) bool {
+		_, ok := destinations[id]
+		return ok
+	})
+	if len(entries) == 0 {
+		est.Warnings = append(est.Warnings, "no valid event sources enabled")
+		return est
+	}
+
+	samples := min(max(estimateSamples/len(entries), estimateMinSamples), estimateSamplesPerTemplate)
+	sizes := make([]float64, len(entries))
+	for i, e := range entries {
+		size, err := generators.MeasureTemplate(e.gen, e.templateID, e.overrides, samples)
+		if err != nil {
+			est.Warnings = append(est.Warnings, fmt.Sprintf("%s/%s: %v", e.eventTypeID, e.templateID, err))
+		}
+		sizes[i] = size
+		est.SampleSize += samples
+	}
+
+	dests := make(map[string]*estimateDestination, len(destinations))
+	for id := range destinations {
+		d := &estimateDestination{remainingEvents: math.Inf(1), remainingBytes: math.Inf(1)}
+		if b := config.Budget; b != nil {
+			if b.MaxEvents > 0 {
+				d.remainingEvents = float64(b.MaxEvents)
+			}
+			if b.MaxBytes > 0 {
+				d.remainingBytes = float64(b.MaxBytes)
+			}
+		}
+		dests[id] = d
+	}
+	mirrors := make(map[string]bool)
+	for _, id := range config.Mirrors {
+		if dests[id] != nil {
+			mirrors[id] = true
+		}
+	}
+
+	// What the run may send before its completion ends it
+	runEvents, runBytes, runSeconds := math.Inf(1), math.Inf(1), math.Inf(1)
+	if c := config.Completion; c != nil {
+		if c.MaxEvents > 0 {
+			runEvents = float64(c.MaxEvents)
+		}
+		if c.MaxBytes > 0 {
+			runBytes = float64(c.MaxBytes)
+		}
+		if c.MaxDurationSeconds > 0 {
+			runSeconds = float64(c.MaxDurationSeconds)
+		}
+		if c.StopURL != "" {
+			est.Warnings = append(est.Warnings, "stop_url is not polled in a dry run; the estimate ignores it")
+		}
+	}
+
+	rate := config.RatePerSecond
+	templateEvents := make([]float64, len(entries))
+	templateBytes := make([]float64, len(entries))
+	var elapsed float64
+	firstPhase := true
+	destRates := make(map[string][2]float64) // Events and bytes per second at the start
+
+	for {
+		// Templates still sending and their weight
+		var active []int
+		total := 0
+		for i, e := range entries {
+			if dests[e.destinationID].reason == "" {
+				active = append(active, i)
+				total += e.weight
+			}
+		}
+		if len(active) == 0 {
+			break
+		}
+
+		eventRate := make(map[string]float64)
+		byteRate := make(map[string]float64)
+		var runByteRate float64
+		for _, i := range active {
+			e := entries[i]
+			r := rate * float64(e.weight) / float64(total)
+			eventRate[e.destinationID] += r
+			byteRate[e.destinationID] += r * sizes[i]
+			runByteRate += r * sizes[i]
+			for id := range mirrors {
+				if id != e.destinationID && dests[id].reason == "" {
+					eventRate[id] += r
+					byteRate[id] += r * sizes[i]
+				}
+			}
+		}
+		if firstPhase {
+			firstPhase = false
+			est.BytesPerSecond = runByteRate
+			est.AvgEventBytes = runByteRate / rate
+			for id := range eventRate {
+				destRates[id] = [2]float64{eventRate[id], byteRate[id]}
+			}
+			for _, i := range active {
+				e := entries[i]
+				est.Templates = append(est.Templates, models.TemplateEstimate{
+					EventType:     e.eventTypeID,
+					TemplateID:    e.templateID,
+					DestinationID: e.destinationID,
+					Share:         float64(e.weight) / float64(total),
+					AvgEventBytes: math.Round(sizes[i]*10) / 10,
+				})
+			}
+		}
+
+		// Time until the next destination runs out of budget
+		untilEvents := make(map[string]float64)
+		untilBytes := make(map[string]float64)
+		next := math.Inf(1)
+		for id, r := range eventRate {
+			d := dests[id]
+			untilEvents[id] = d.remainingEvents / r
+			untilBytes[id] = math.Inf(1)
+			if byteRate[id] > 0 {
+				untilBytes[id] = d.remainingBytes / byteRate[id]
+			}
+			next = math.Min(next, math.Min(untilEvents[id], untilBytes[id]))
+		}
+		untilRun := math.Min(runEvents/rate, runSeconds)
+		if runByteRate > 0 {
+			untilRun = math.Min(untilRun, runBytes/runByteRate)
+		}
+		next = math.Min(next, untilRun)
+		if math.IsInf(next, 1) {
+			est.Unbounded = true
+			break
+		}
+
+		for _, i := range active {
+			r := rate * float64(entries[i].weight) / float64(total)
+			templateEvents[i] += r * next
+			templateBytes[i] += r * sizes[i] * next
+		}
+		for id, r := range eventRate {
+			d := dests[id]
+			d.events += r * next
+			d.bytes += byteRate[id] * next
+			d.remainingEvents -= r * next
+			d.remainingBytes -= byteRate[id] * next
+			switch limit := next * (1 + 1e-9); {
+			case untilEvents[id] <= limit:
+				d.reason = models.BudgetReasonEvents
+			case untilBytes[id] <= limit:
+				d.reason = models.BudgetReasonBytes
+			}
+			if d.reason != "" {
+				d.exhaustedAfter = elapsed + next
+			}
+		}
+		elapsed += next
+		runEvents -= rate * next
+		runBytes -= runByteRate * next
+		runSeconds -= next
+		if untilRun <= next*(1+1e-9) {
+			break
+		}
+	}
+
+	est.BytesPerDay = int64(est.BytesPerSecond * 86400)
+	if !est.Unbounded {
+		est.DurationSeconds = math.Round(elapsed*10) / 10
+		var events, bytes float64
+		for i := range est.Templates {
+			est.Templates[i].Events = int64(math.Round(templateEvents[i]))
+			est.Templates[i].Bytes = int64(math.Round(templateBytes[i]))
+			events += templateEvents[i]
+			bytes += templateBytes[i]
+		}
+		est.Events = int64(math.Round(events))
+		est.Bytes = int64(math.Round(bytes))
+	}
+	sort.SliceStable(est.Templates, func(i, j int) bool { return est.Templates[i].Share > est.Templates[j].Share })
+
+	for id, dest := range destinations {
+		d := dests[id]
+		de := models.DestinationEstimate{
+			DestinationID:   id,
+			Name:            dest.Name,
+			EventsPerSecond: math.Round(destRates[id][0]*100) / 100,
+			BytesPerSecond:  math.Round(destRates[id][1]),
+		}
+		if !est.Unbounded {
+			de.Events = int64(math.Round(d.events))
+			de.Bytes = int64(math.Round(d.bytes))
+		}
+		if d.reason != "" {
+			de.BudgetExhaustedAfter = math.Round(d.exhaustedAfter*10) / 10
+			de.BudgetReason = d.reason
+		}
+		est.Destinations = append(est.Destinations, de)
+	}
+	sort.Slice(est.Destinations, func(i, j int) bool { return est.Destinations[i].DestinationID < est.Destinations[j].DestinationID })
+
+	est.BytesPerSecond = math.Round(est.BytesPerSecond)
+	est.AvgEventBytes = math.Round(est.AvgEventBytes*10) / 10
+	return est
+}
+
+// DestinationRates returns the events per second each destination of
+// config receives, mirrors included
+func DestinationRates(config *models.NoiseConfig) map[string]float64 {
+	entries := poolEntries(config, func(string) bool { return true })
+	total := 0
+	for _, e := range entries {
+		total += e.weight
+	}
+	rates := make(map[string]float64)
+	if total == 0 {
+		return rates
+	}
+	for _, e := range entries {
+		rates[e.destinationID] += config.RatePerSecond * float64(e.weight) / float64(total)
+	}
+	for _, id := range config.Mirrors {
+		// A mirror receives every event, its own share only once
+		rates[id] = config.RatePerSecond
+	}
+	return rates
+}
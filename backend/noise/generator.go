@@ -2,9 +2,13 @@ package noise
 
 import (
 	"context"
-	"crypto/rand"
+	"errors"
 	"fmt"
-	"math/big"
+	"math"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,22 +16,96 @@ import (
 	"siem-event-generator/delivery"
 	"siem-event-generator/generators"
 	"siem-event-generator/models"
+	"siem-event-generator/notify"
 )
 
-// Generator manages continuous noise generation
+// Pacing of the worker pool
+const (
+	pacerTick    = 10 * time.Millisecond // How often the pacer hands out work
+	maxWorkBatch = 256                   // Most events in one work item
+	maxBacklog   = time.Second           // Owed events beyond this much are dropped, not burst
+)
+
+// Errors a run can be refused with
+var (
+	ErrRunning    = errors.New("noise generation already running")
+	ErrNotRunning = errors.New("noise generation not running")
+	ErrSender     = errors.New("failed to create sender") // Wraps the destination's own error
+	ErrNoBaseline = errors.New("noise run has no behavior baseline")
+)
+
+// Generator manages continuous noise generation. A pacer hands batches of
+// event slots to a pool of workers, which pick a template, generate the
+// event and send it without taking the generator's lock.
 type Generator struct {
 	mu        sync.RWMutex
 	running   bool
-	ctx       context.Context
-	cancel    context.CancelFunc
 	config    *models.NoiseConfig
-	stats     *models.NoiseStats
-	senders   map[string]delivery.Sender // destination_id -> Sender
+	run       *run // Current or last run
 	startedAt time.Time
 
-	// Weighted selection cache
-	weightedPool []weightedTemplate
-	totalWeight  int
+	stopReason string
+}
+
+// run is the state of one start-to-stop generation session. Workers only
+// touch the run they were started for, so a new run never sees their events.
+type run struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers int
+	rate    atomic.Uint64            // math.Float64bits of the events per second
+	seed    int64                    // Seeds template picks and the generators' values; 0 draws a fresh seed
+	stream  *generators.RandomStream // The generators' values for a seeded run; nil when unseeded
+
+	throttle  atomic.Uint64                        // math.Float64bits of the share of rate the resource guard allows
+	resources atomic.Pointer[models.ResourceUsage] // Last sample of the resource guard
+
+	senders map[string]delivery.Sender  // destination_id -> Sender; guarded by Generator.mu
+	sending sync.RWMutex                // Read-held while a worker sends with a pool snapshot
+	closing sync.WaitGroup              // Senders of exhausted destinations still to close
+	spent   sync.Map                    // destination_id -> struct{}, once its budget is used up
+	budgets map[string]*delivery.Budget // destination_id -> Budget, when the run has one
+	pool    atomic.Pointer[weightedPool]
+	counts  map[templateKey]*int64 // Events per template; guarded by Generator.mu
+	mirrors []string               // Destinations that also receive every event
+
+	limiter       *delivery.CardinalityLimiter // Nil unless the run limits cardinality
+	behavior      *generators.Behavior         // Nil unless the run keeps users to their profiles
+	fuzzer        *generators.TimestampFuzzer  // Nil unless the run fuzzes timestamps
+	skew          *generators.ClockSkew        // Nil unless the run skews clocks
+	chaos         *generators.Chaos            // Nil unless the run breaks events
+	holdback      *holdback                    // Nil unless the run sends events out of order
+	completion    *completion                  // Nil unless the run stops by itself
+	duplicateRate float64
+	last          map[sendKey]*atomic.Pointer[models.GeneratedEvent] // Last event per template and destination; guarded by Generator.mu
+	renderers     map[sendKey]*generators.Renderer                   // Per template and destination whose source renders; guarded by Generator.mu
+
+	delivered map[string]*deliveryCounts // destination_id -> sends; fixed at Start
+
+	stats       *models.NoiseStats // Totals are updated atomically
+	carried     models.NoiseStats  // Counts of the run this one resumed
+	lastEventAt atomic.Int64       // Unix nanoseconds
+	errMu       sync.Mutex         // Guards stats.ErrorSamples
+
+	done chan struct{} // Closed once the workers have exited and senders are closed
+}
+
+// deliveryCounts tracks sends to one destination
+type deliveryCounts struct {
+	name   string
+	sent   atomic.Int64
+	errors atomic.Int64
+}
+
+type templateKey struct {
+	eventTypeID string
+	templateID  string
+}
+
+// sendKey is a template sent to one destination
+type sendKey struct {
+	templateKey
+	destinationID string
 }
 
 type weightedTemplate struct {
@@ -35,6 +113,37 @@ type weightedTemplate struct {
 	templateID    string
 	destinationID string
 	weight        int
+
+	// Resolved when the pool is built so workers do no lookups
+	gen       generators.Generator
+	overrides map[string]interface{} // The source's; read only
+	sender    delivery.Sender
+	renderer  *generators.Renderer // Nil unless the source renders
+	count     *int64
+	last      *atomic.Pointer[models.GeneratedEvent] // Resent by the duplicate rate
+}
+
+// weightedPool is an immutable snapshot of the enabled templates; it is
+// replaced, never modified, when sources or destinations change
+type weightedPool struct {
+	templates  []weightedTemplate
+	cumulative []int // Running total of weights, for binary search
+	total      int
+	mirrors    []mirror // Mirror destinations still open
+}
+
+// mirror is a destination that receives a copy of every event
+type mirror struct {
+	destinationID string
+	sender        delivery.Sender
+	counts        *deliveryCounts
+}
+
+// pick selects a template with probability proportional to its weight
+func (p *weightedPool) pick(rng *rand.Rand) *weightedTemplate {
+	target := rng.Intn(p.total)
+	i := sort.Search(len(p.cumulative), func(i int) bool { return p.cumulative[i] > target })
+	return &p.templates[i]
 }
 
 // Global singleton instance
@@ -44,90 +153,258 @@ var once sync.Once
 // GetInstance returns the singleton noise generator instance
 func GetInstance() *Generator {
 	once.Do(func() {
-		instance = &Generator{
-			stats: &models.NoiseStats{
-				ByEventType: make(map[string]int64),
-				ByTemplate:  make(map[string]int64),
-			},
-			senders: make(map[string]delivery.Sender),
-		}
+		instance = &Generator{}
 	})
 	return instance
 }
 
 // Start begins continuous noise generation
 func (g *Generator) Start(config *models.NoiseConfig, destinations map[string]*models.Destination) error {
+	return g.start(config, destinations, nil)
+}
+
+// Resume continues a run saved before a restart. Its statistics and budget
+// usage carry on from previous, and it keeps its original start time.
+func (g *Generator) Resume(config *models.NoiseConfig, destinations map[string]*models.Destination, previous models.NoiseStatus) error {
+	return g.start(config, destinations, &previous)
+}
+
+func (g *Generator) start(config *models.NoiseConfig, destinations map[string]*models.Destination, previous *models.NoiseStatus) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	if g.running {
-		return fmt.Errorf("noise generation already running")
+		return ErrRunning
+	}
+
+	var behavior *generators.Behavior
+	if config.Behavior != nil {
+		var err error
+		if behavior, err = generators.NewBehavior(*config.Behavior); err != nil {
+			return err
+		}
+	}
+	var fuzzer *generators.TimestampFuzzer
+	if config.TimestampFuzz != nil {
+		var err error
+		if fuzzer, err = generators.NewTimestampFuzzer(*config.TimestampFuzz); err != nil {
+			return err
+		}
+	}
+	var skew *generators.ClockSkew
+	if config.ClockSkew != nil {
+		var err error
+		if skew, err = generators.NewClockSkew(*config.ClockSkew); err != nil {
+			return err
+		}
+	}
+	var chaos *generators.Chaos
+	if config.Chaos != nil {
+		var err error
+		if chaos, err = generators.NewChaos(*config.Chaos); err != nil {
+			return err
+		}
+	}
+	if err := checkRenders(config.EnabledSources); err != nil {
+		return err
 	}
 
 	// Create senders for each destination
-	g.senders = make(map[string]delivery.Sender)
+	senders := make(map[string]delivery.Sender)
 	for id, dest := range destinations {
 		sender, err := delivery.GetSender(dest)
 		if err != nil {
 			// Close any already-created senders
-			for _, s := range g.senders {
+			for _, s := range senders {
 				s.Close()
 			}
-			g.senders = nil
-			return fmt.Errorf("failed to create sender for destination %s: %w", id, err)
+			return fmt.Errorf("%w for destination %s: %w", ErrSender, id, err)
 		}
-		g.senders[id] = sender
+		senders[id] = sender
 	}
 
-	g.config = config
-	g.ctx, g.cancel = context.WithCancel(context.Background())
-	g.startedAt = time.Now()
-	g.running = true
+	budgets := make(map[string]*delivery.Budget)
+	if config.Budget != nil {
+		for id, sender := range senders {
+			budgets[id] = delivery.NewBudget(*config.Budget)
+			if previous != nil {
+				budgets[id].Restore(previous.Stats.Budget[id])
+			}
+			senders[id] = delivery.WithBudget(sender, budgets[id])
+		}
+	}
+	for id, sender := range senders {
+		senders[id] = delivery.WithCircuitBreaker(sender, circuitNotifier(id, destinations[id].Name))
+	}
+
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+
+	delivered := make(map[string]*deliveryCounts, len(destinations))
+	for id, dest := range destinations {
+		delivered[id] = &deliveryCounts{name: dest.Name}
+	}
 
-	// Reset stats
-	g.stats = &models.NoiseStats{
-		ByEventType:  make(map[string]int64),
-		ByTemplate:   make(map[string]int64),
-		ErrorSamples: make([]string, 0, 5),
+	r := &run{
+		workers:   config.Workers,
+		senders:   senders,
+		budgets:   budgets,
+		counts:    make(map[templateKey]*int64),
+		mirrors:   config.Mirrors,
+		behavior:  behavior,
+		fuzzer:    fuzzer,
+		skew:      skew,
+		chaos:     chaos,
+		holdback:  newHoldback(config.OutOfOrder),
+		seed:      config.Seed,
+		last:      make(map[sendKey]*atomic.Pointer[models.GeneratedEvent]),
+		renderers: make(map[sendKey]*generators.Renderer),
+		delivered: delivered,
+		stats: &models.NoiseStats{
+			ByEventType:  make(map[string]int64),
+			ByTemplate:   make(map[string]int64),
+			ErrorSamples: make([]string, 0, 5),
+		},
+		done: make(chan struct{}),
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.rate.Store(math.Float64bits(config.RatePerSecond))
+	r.throttle.Store(math.Float64bits(1))
+	if config.Cardinality != nil {
+		r.limiter = delivery.NewCardinalityLimiter(*config.Cardinality)
+		r.duplicateRate = config.Cardinality.DuplicateRate
+	}
+	startedAt := time.Now()
+	if previous != nil {
+		r.carried = previous.Stats
+		if previous.StartedAt != nil {
+			startedAt = *previous.StartedAt
+		}
 	}
+	r.completion = newCompletion(config.Completion, startedAt, r.carried.TotalGenerated)
 
-	// Build weighted pool
-	g.buildWeightedPool()
+	g.config = config
+	g.buildWeightedPool(r)
 
-	if len(g.weightedPool) == 0 {
-		g.running = false
-		for _, s := range g.senders {
+	if r.pool.Load().total == 0 {
+		r.cancel()
+		for _, s := range senders {
 			s.Close()
 		}
-		g.senders = nil
 		return fmt.Errorf("no valid event sources enabled")
 	}
 
-	// Start generation goroutine
-	go g.generateLoop()
+	g.run = r
+	g.stopReason = ""
+	g.startedAt = startedAt
+	g.running = true
+
+	go g.supervise(r)
 
 	return nil
 }
 
-// Stop ends noise generation
+// Stop ends noise generation and waits for in-flight events to be sent and
+// the senders to flush
 func (g *Generator) Stop() error {
 	g.mu.Lock()
-	defer g.mu.Unlock()
-
 	if !g.running {
-		return fmt.Errorf("noise generation not running")
+		g.mu.Unlock()
+		return ErrNotRunning
 	}
+	r := g.run
+	g.stopLocked()
+	g.mu.Unlock()
 
-	g.cancel()
+	<-r.done
+	return nil
+}
+
+// stopLocked cancels generation; g.mu must be held. The run's supervisor
+// closes the senders once the workers have exited.
+func (g *Generator) stopLocked() {
+	g.run.cancel()
 	g.running = false
+}
+
+// exhaustDestination stops sending to a destination whose budget is used
+// up, and stops generation once every destination is exhausted
+func (g *Generator) exhaustDestination(r *run, destinationID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	// Close all senders
-	for _, sender := range g.senders {
+	sender, ok := r.senders[destinationID]
+	if !g.running || g.run != r || !ok {
+		return
+	}
+	delete(r.senders, destinationID)
+	r.spent.Store(destinationID, struct{}{})
+	notifyQuotaExceeded(r, destinationID)
+
+	g.buildWeightedPool(r)
+
+	// Workers may still send with a pool that has the sender; close it once
+	// they are done with it. Events sent after that find it spent.
+	r.closing.Add(1)
+	go func() {
+		defer r.closing.Done()
+		r.sending.Lock()
+		r.sending.Unlock()
 		sender.Close()
+	}()
+	if r.pool.Load().total == 0 {
+		g.stopReason = "volume budget exhausted"
+		g.stopLocked()
 	}
-	g.senders = nil
+}
 
-	return nil
+// circuitNotifyInterval is the least time between notifications that one
+// destination's circuit opened, so a flapping destination does not flood
+// the channels
+const circuitNotifyInterval = 15 * time.Minute
+
+// circuitNotifier returns the function a destination's circuit breaker
+// calls when the circuit opens
+func circuitNotifier(destinationID, name string) func(err error) {
+	var last atomic.Int64 // Unix nanoseconds of the last notification
+	return func(err error) {
+		now := time.Now()
+		if prev := last.Load(); prev != 0 && now.Sub(time.Unix(0, prev)) < circuitNotifyInterval {
+			return
+		}
+		last.Store(now.UnixNano())
+		notify.Publish(models.Notification{
+			Event:         models.NotifyCircuitOpened,
+			Summary:       fmt.Sprintf("Sends to %s are paused after repeated failures", name),
+			DestinationID: destinationID,
+			Destination:   name,
+			Details:       map[string]interface{}{"last_error": err.Error()},
+		})
+	}
+}
+
+// notifyQuotaExceeded tells the notification channels that a destination's
+// volume budget is used up
+func notifyQuotaExceeded(r *run, destinationID string) {
+	name := destinationID
+	if counts, ok := r.delivered[destinationID]; ok {
+		name = counts.name
+	}
+	details := map[string]interface{}{}
+	if budget, ok := r.budgets[destinationID]; ok {
+		usage := budget.Usage()
+		details["reason"] = usage.Reason
+		details["events_sent"] = usage.EventsSent
+		details["bytes_sent"] = usage.BytesSent
+	}
+	notify.Publish(models.Notification{
+		Event:         models.NotifyQuotaExceeded,
+		Summary:       fmt.Sprintf("The volume budget of %s is used up", name),
+		DestinationID: destinationID,
+		Destination:   name,
+		Details:       details,
+	})
 }
 
 // IsRunning returns whether noise generation is active
@@ -143,8 +420,9 @@ func (g *Generator) GetStatus() models.NoiseStatus {
 	defer g.mu.RUnlock()
 
 	status := models.NoiseStatus{
-		Running: g.running,
-		Stats:   g.copyStats(),
+		Running:    g.running,
+		StopReason: g.stopReason,
+		Stats:      g.copyStats(),
 	}
 
 	if g.running {
@@ -168,120 +446,285 @@ func (g *Generator) UpdateConfig(update *models.NoiseUpdateRequest) error {
 	defer g.mu.Unlock()
 
 	if !g.running {
-		return fmt.Errorf("noise generation not running")
+		return ErrNotRunning
 	}
 
 	if update.RatePerSecond != nil {
 		g.config.RatePerSecond = *update.RatePerSecond
+		g.run.rate.Store(math.Float64bits(*update.RatePerSecond))
 	}
 
 	if update.EnabledSources != nil {
+		if err := checkRenders(update.EnabledSources); err != nil {
+			return err
+		}
 		g.config.EnabledSources = update.EnabledSources
-		g.buildWeightedPool()
+		g.buildWeightedPool(g.run)
 	}
 
 	return nil
 }
 
-func (g *Generator) generateLoop() {
-	// Calculate interval between events
-	interval := time.Duration(float64(time.Second) / g.config.RatePerSecond)
-	ticker := time.NewTicker(interval)
+// InjectAnomaly breaks a user's behavioral profile in the next events of the
+// run that name a user
+func (g *Generator) InjectAnomaly(req models.AnomalyRequest) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if !g.running {
+		return ErrNotRunning
+	}
+	if g.run.behavior == nil {
+		return ErrNoBaseline
+	}
+	return g.run.behavior.Inject(req)
+}
+
+// supervise runs the pacer and workers of r until it is cancelled, then
+// closes the run's senders
+func (g *Generator) supervise(r *run) {
+	work := make(chan int, r.workers*4)
+
+	var wg sync.WaitGroup
+	seed := time.Now().UnixNano()
+	if r.seed != 0 {
+		seed = r.seed
+		r.stream = generators.NewRandomStream(r.seed)
+	}
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func(rng *rand.Rand) {
+			defer wg.Done()
+			g.worker(r, work, rng)
+		}(rand.New(rand.NewSource(seed + int64(i))))
+	}
+
+	go g.watch(r)
+	go g.guard(r)
+	g.pace(r, work)
+	close(work)
+	wg.Wait()
+
+	// Send what is still held back before the senders close
+	for _, e := range r.holdback.drain() {
+		g.send(r, r.pool.Load(), e)
+	}
+
+	g.mu.Lock()
+	for _, sender := range r.senders {
+		sender.Close()
+	}
+	g.mu.Unlock()
+	r.closing.Wait()
+	close(r.done)
+}
+
+// pace hands out event slots at the configured rate until r is cancelled.
+// Slots owed beyond maxBacklog are dropped, so workers that cannot keep up
+// run flat out instead of bursting later.
+func (g *Generator) pace(r *run, work chan<- int) {
+	ticker := time.NewTicker(pacerTick)
 	defer ticker.Stop()
 
+	rate := math.Float64frombits(r.rate.Load()) * math.Float64frombits(r.throttle.Load())
+	start := time.Now()
+	var issued int64
+
 	for {
 		select {
-		case <-g.ctx.Done():
+		case <-r.ctx.Done():
 			return
-		case <-ticker.C:
-			g.generateAndSend()
-
-			// Check if rate changed and update ticker
-			g.mu.RLock()
-			newInterval := time.Duration(float64(time.Second) / g.config.RatePerSecond)
-			g.mu.RUnlock()
+		case now := <-ticker.C:
+			// Restart the schedule so a rate change or throttle takes effect at once
+			if current := math.Float64frombits(r.rate.Load()) * math.Float64frombits(r.throttle.Load()); current != rate {
+				rate, start, issued = current, now, 0
+			}
 
-			if newInterval != interval {
-				interval = newInterval
-				ticker.Reset(interval)
+			due := int64(now.Sub(start).Seconds()*rate) - issued
+			if backlog := int64(rate * maxBacklog.Seconds()); due > backlog {
+				issued += due - backlog
+				due = backlog
+			}
+			for due > 0 {
+				n := min(due, maxWorkBatch)
+				select {
+				case work <- int(n):
+				case <-r.ctx.Done():
+					return
+				}
+				due -= n
+				issued += n
 			}
 		}
 	}
 }
 
-func (g *Generator) generateAndSend() {
-	g.mu.RLock()
-	if len(g.weightedPool) == 0 {
-		g.mu.RUnlock()
-		return
+// worker generates and sends one event per slot it receives
+func (g *Generator) worker(r *run, work <-chan int, rng *rand.Rand) {
+	for n := range work {
+		for i := 0; i < n; i++ {
+			if r.ctx.Err() != nil {
+				return
+			}
+			if !g.work(r, rng) {
+				break
+			}
+		}
 	}
+}
 
-	// Select random template based on weight
-	selected := g.selectWeighted()
+// work generates and sends one event, and sends the held back events that
+// are due, with the current pool. It returns false when the pool is empty.
+func (g *Generator) work(r *run, rng *rand.Rand) bool {
+	r.sending.RLock()
+	defer r.sending.RUnlock()
 
-	// Get the sender for this event's destination
-	sender, ok := g.senders[selected.destinationID]
-	g.mu.RUnlock()
+	pool := r.pool.Load()
+	if pool.total == 0 {
+		return false
+	}
+	g.generateAndSend(r, pool, pool.pick(rng), rng)
+	for _, e := range r.holdback.release(time.Now()) {
+		g.send(r, pool, e)
+	}
+	return true
+}
 
-	if !ok {
-		atomic.AddInt64(&g.stats.TotalErrors, 1)
-		g.addErrorSample(fmt.Sprintf("sender not found for destination: %s", selected.destinationID))
-		return
+// generateAndSend sends a new event from selected or, at the duplicate
+// rate, the last one it sent again. At the out-of-order rate the event is
+// held back instead, to be sent after newer ones.
+func (g *Generator) generateAndSend(r *run, pool *weightedPool, selected *weightedTemplate, rng *rand.Rand) {
+	var event *models.GeneratedEvent
+	duplicate, malformed, anomalous := false, false, false
+	if r.duplicateRate > 0 && rng.Float64() < r.duplicateRate {
+		event = selected.last.Load()
+		duplicate = event != nil
+	}
+	if event == nil {
+		var err error
+		event, err = r.stream.Generate(selected.gen, selected.templateID, selected.overrides)
+		if err != nil {
+			atomic.AddInt64(&r.stats.TotalErrors, 1)
+			r.addErrorSample(fmt.Sprintf("generate error: %v", err))
+			return
+		}
+		event, anomalous = r.behavior.Apply(event, rng)
+		event = selected.renderer.Apply(r.limiter.Apply(r.fuzzer.Apply(r.skew.Apply(event)), rng))
+		event, malformed = r.chaos.Apply(event)
+		if r.duplicateRate > 0 {
+			selected.last.Store(event)
+		}
 	}
 
-	// Get generator and generate event
-	gen, ok := generators.GetGenerator(selected.eventTypeID)
-	if !ok {
-		atomic.AddInt64(&g.stats.TotalErrors, 1)
-		g.addErrorSample(fmt.Sprintf("generator not found: %s", selected.eventTypeID))
+	held := heldEvent{selected: selected, event: event, duplicate: duplicate, malformed: malformed, anomalous: anomalous}
+	if r.holdback.hold(held, rng) {
 		return
 	}
+	g.send(r, pool, held)
+}
 
-	event, err := gen.Generate(selected.templateID, nil)
-	if err != nil {
-		atomic.AddInt64(&g.stats.TotalErrors, 1)
-		g.addErrorSample(fmt.Sprintf("generate error: %v", err))
+// send sends an event to its template's destination and the mirrors, and
+// counts it. Events held back are counted when they are sent.
+func (g *Generator) send(r *run, pool *weightedPool, e heldEvent) {
+	selected, event := e.selected, e.event
+	if _, spent := r.spent.Load(selected.destinationID); spent {
+		// Held back from before the destination's budget ran out
+		return
+	}
+	if reason := r.completion.admit(len(event.RawEvent)); reason != "" {
+		g.complete(r, reason)
+		return
+	}
+	err := selected.sender.Send(event)
+	if errors.Is(err, delivery.ErrBudgetExhausted) {
+		// The event would go over the destination's budget; drop it
+		g.exhaustDestination(r, selected.destinationID)
 		return
 	}
 
-	atomic.AddInt64(&g.stats.TotalGenerated, 1)
-
-	// Send to destination
-	if err := sender.Send(event); err != nil {
-		atomic.AddInt64(&g.stats.TotalErrors, 1)
-		g.addErrorSample(fmt.Sprintf("send error: %v", err))
+	atomic.AddInt64(&r.stats.TotalGenerated, 1)
+	if e.duplicate {
+		atomic.AddInt64(&r.stats.TotalDuplicates, 1)
+	}
+	if e.malformed {
+		atomic.AddInt64(&r.stats.TotalMalformed, 1)
+	}
+	if e.anomalous {
+		atomic.AddInt64(&r.stats.TotalAnomalies, 1)
+	}
+	if !e.due.IsZero() {
+		atomic.AddInt64(&r.stats.TotalReordered, 1)
+	}
+	counts := r.delivered[selected.destinationID]
+	if err != nil {
+		atomic.AddInt64(&r.stats.TotalErrors, 1)
+		counts.errors.Add(1)
+		r.addErrorSample(fmt.Sprintf("send error: %v", err))
 	} else {
-		atomic.AddInt64(&g.stats.TotalSent, 1)
+		atomic.AddInt64(&r.stats.TotalSent, 1)
+		counts.sent.Add(1)
 	}
 
-	// Update per-type and per-template stats
-	g.mu.Lock()
-	g.stats.ByEventType[selected.eventTypeID]++
-	g.stats.ByTemplate[selected.templateID]++
-	now := time.Now()
-	g.stats.LastEventAt = &now
-	g.mu.Unlock()
+	for _, m := range pool.mirrors {
+		if m.destinationID == selected.destinationID {
+			continue
+		}
+		g.sendMirror(r, m, event)
+	}
+
+	atomic.AddInt64(selected.count, 1)
+	r.lastEventAt.Store(time.Now().UnixNano())
 }
 
-func (g *Generator) selectWeighted() weightedTemplate {
-	n, _ := rand.Int(rand.Reader, big.NewInt(int64(g.totalWeight)))
-	target := int(n.Int64())
+// sendMirror sends a copy of event to a mirror destination. Mirror failures
+// are counted against the mirror only; they do not change the run totals.
+func (g *Generator) sendMirror(r *run, m mirror, event *models.GeneratedEvent) {
+	if _, spent := r.spent.Load(m.destinationID); spent {
+		return
+	}
+	err := m.sender.Send(event)
+	if errors.Is(err, delivery.ErrBudgetExhausted) {
+		g.exhaustDestination(r, m.destinationID)
+		return
+	}
+	if err != nil {
+		m.counts.errors.Add(1)
+		r.addErrorSample(fmt.Sprintf("mirror %s send error: %v", m.counts.name, err))
+		return
+	}
+	m.counts.sent.Add(1)
+}
+
+// poolEntry is a template the enabled sources select, with its weight
+type poolEntry struct {
+	eventTypeID   string
+	templateID    string
+	destinationID string
+	weight        int
+	gen           generators.Generator
+	render        *models.RenderConfig
+	overrides     map[string]interface{}
+}
 
-	cumulative := 0
-	for _, wt := range g.weightedPool {
-		cumulative += wt.weight
-		if target < cumulative {
-			return wt
+// checkRenders checks the render configs of sources
+func checkRenders(sources []models.EnabledEventSource) error {
+	for _, source := range sources {
+		if source.Render == nil {
+			continue
+		}
+		if _, err := generators.NewRenderer(*source.Render); err != nil {
+			return fmt.Errorf("%s: %w", source.EventTypeID, err)
 		}
 	}
-	return g.weightedPool[len(g.weightedPool)-1]
+	return nil
 }
 
-func (g *Generator) buildWeightedPool() {
-	g.weightedPool = nil
-	g.totalWeight = 0
+// poolEntries returns the templates config selects whose destination is
+// open, weighted the way the pool picks them
+func poolEntries(config *models.NoiseConfig, open func(destinationID string) bool) []poolEntry {
+	var entries []poolEntry
 
-	for _, source := range g.config.EnabledSources {
+	for _, source := range config.EnabledSources {
 		if !source.Enabled {
 			continue
 		}
@@ -294,14 +737,14 @@ func (g *Generator) buildWeightedPool() {
 		// Determine destination for this source
 		destinationID := source.DestinationID
 		if destinationID == "" {
-			destinationID = g.config.DestinationID // Use global fallback
+			destinationID = config.DestinationID // Use global fallback
 		}
 		if destinationID == "" {
 			continue // No destination configured
 		}
 
-		// Verify sender exists for this destination
-		if _, ok := g.senders[destinationID]; !ok {
+		// Verify the destination can still be sent to
+		if !open(destinationID) {
 			continue
 		}
 
@@ -343,51 +786,153 @@ func (g *Generator) buildWeightedPool() {
 				continue
 			}
 
-			g.weightedPool = append(g.weightedPool, weightedTemplate{
+			entries = append(entries, poolEntry{
 				eventTypeID:   source.EventTypeID,
 				templateID:    tid,
 				destinationID: destinationID,
 				weight:        weightPerTemplate,
+				gen:           gen,
+				render:        source.Render,
+				overrides:     source.Overrides,
 			})
-			g.totalWeight += weightPerTemplate
 		}
 	}
+	return entries
 }
 
-func (g *Generator) addErrorSample(err string) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+// buildWeightedPool publishes a new pool for r from the enabled sources and
+// the senders still open; g.mu must be held
+func (g *Generator) buildWeightedPool(r *run) {
+	pool := &weightedPool{}
 
-	if len(g.stats.ErrorSamples) >= 5 {
-		g.stats.ErrorSamples = g.stats.ErrorSamples[1:]
+	open := func(id string) bool {
+		_, ok := r.senders[id]
+		return ok
 	}
-	g.stats.ErrorSamples = append(g.stats.ErrorSamples, err)
+	for _, e := range poolEntries(g.config, open) {
+		key := templateKey{eventTypeID: e.eventTypeID, templateID: e.templateID}
+		count, ok := r.counts[key]
+		if !ok {
+			count = new(int64)
+			r.counts[key] = count
+		}
+
+		sent := sendKey{templateKey: key, destinationID: e.destinationID}
+		last, ok := r.last[sent]
+		if !ok {
+			last = new(atomic.Pointer[models.GeneratedEvent])
+			r.last[sent] = last
+		}
+
+		// Renderers carry over while their config is unchanged, so CSV
+		// headers are not repeated
+		renderer := r.renderers[sent]
+		if e.render == nil {
+			renderer = nil
+		} else if renderer == nil || !reflect.DeepEqual(renderer.Config(), *e.render) {
+			renderer, _ = generators.NewRenderer(*e.render)
+		}
+		r.renderers[sent] = renderer
+
+		pool.templates = append(pool.templates, weightedTemplate{
+			eventTypeID:   e.eventTypeID,
+			templateID:    e.templateID,
+			destinationID: e.destinationID,
+			weight:        e.weight,
+			gen:           e.gen,
+			overrides:     e.overrides,
+			sender:        r.senders[e.destinationID],
+			renderer:      renderer,
+			count:         count,
+			last:          last,
+		})
+		pool.total += e.weight
+		pool.cumulative = append(pool.cumulative, pool.total)
+	}
+
+	for _, id := range r.mirrors {
+		if sender, ok := r.senders[id]; ok {
+			pool.mirrors = append(pool.mirrors, mirror{destinationID: id, sender: sender, counts: r.delivered[id]})
+		}
+	}
+
+	r.pool.Store(pool)
 }
 
+func (r *run) addErrorSample(err string) {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+
+	if len(r.stats.ErrorSamples) >= 5 {
+		r.stats.ErrorSamples = r.stats.ErrorSamples[1:]
+	}
+	r.stats.ErrorSamples = append(r.stats.ErrorSamples, err)
+}
+
+// copyStats snapshots the statistics of the current or last run; g.mu must
+// be held for reading
 func (g *Generator) copyStats() models.NoiseStats {
 	stats := models.NoiseStats{
-		TotalGenerated:  atomic.LoadInt64(&g.stats.TotalGenerated),
-		TotalSent:       atomic.LoadInt64(&g.stats.TotalSent),
-		TotalErrors:     atomic.LoadInt64(&g.stats.TotalErrors),
-		EventsPerSecond: g.stats.EventsPerSecond,
-		DurationSeconds: g.stats.DurationSeconds,
-		ByEventType:     make(map[string]int64),
-		ByTemplate:      make(map[string]int64),
-		ErrorSamples:    make([]string, len(g.stats.ErrorSamples)),
-	}
-
-	if g.stats.LastEventAt != nil {
-		lastEvent := *g.stats.LastEventAt
+		ByEventType: make(map[string]int64),
+		ByTemplate:  make(map[string]int64),
+	}
+	r := g.run
+	if r == nil {
+		return stats
+	}
+
+	stats.TotalGenerated = r.carried.TotalGenerated + atomic.LoadInt64(&r.stats.TotalGenerated)
+	stats.TotalSent = r.carried.TotalSent + atomic.LoadInt64(&r.stats.TotalSent)
+	stats.TotalErrors = r.carried.TotalErrors + atomic.LoadInt64(&r.stats.TotalErrors)
+	stats.TotalDuplicates = r.carried.TotalDuplicates + atomic.LoadInt64(&r.stats.TotalDuplicates)
+	stats.TotalMalformed = r.carried.TotalMalformed + atomic.LoadInt64(&r.stats.TotalMalformed)
+	stats.TotalReordered = r.carried.TotalReordered + atomic.LoadInt64(&r.stats.TotalReordered)
+	stats.TotalAnomalies = r.carried.TotalAnomalies + atomic.LoadInt64(&r.stats.TotalAnomalies)
+	stats.Resources = r.resources.Load()
+
+	stats.LastEventAt = r.carried.LastEventAt
+	if last := r.lastEventAt.Load(); last != 0 {
+		lastEvent := time.Unix(0, last)
 		stats.LastEventAt = &lastEvent
 	}
+	for k, n := range r.carried.ByEventType {
+		stats.ByEventType[k] = n
+	}
+	for k, n := range r.carried.ByTemplate {
+		stats.ByTemplate[k] = n
+	}
 
-	for k, v := range g.stats.ByEventType {
-		stats.ByEventType[k] = v
+	for key, count := range r.counts {
+		n := atomic.LoadInt64(count)
+		if n == 0 {
+			continue
+		}
+		stats.ByEventType[key.eventTypeID] += n
+		stats.ByTemplate[key.templateID] += n
 	}
-	for k, v := range g.stats.ByTemplate {
-		stats.ByTemplate[k] = v
+
+	r.errMu.Lock()
+	stats.ErrorSamples = make([]string, len(r.stats.ErrorSamples))
+	copy(stats.ErrorSamples, r.stats.ErrorSamples)
+	r.errMu.Unlock()
+
+	if len(r.budgets) > 0 {
+		stats.Budget = make(map[string]models.VolumeUsage, len(r.budgets))
+		for id, b := range r.budgets {
+			stats.Budget[id] = b.Usage()
+		}
+	}
+
+	stats.ByDestination = make(map[string]models.DeliveryResult, len(r.delivered))
+	for id, counts := range r.delivered {
+		carried := r.carried.ByDestination[id]
+		stats.ByDestination[id] = models.DeliveryResult{
+			DestinationID: id,
+			Name:          counts.name,
+			EventsSent:    carried.EventsSent + counts.sent.Load(),
+			Errors:        carried.Errors + counts.errors.Load(),
+		}
 	}
-	copy(stats.ErrorSamples, g.stats.ErrorSamples)
 
 	return stats
 }
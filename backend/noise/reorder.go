@@ -0,0 +1,96 @@
+package noise
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// Most events a run holds back at once; beyond it events are sent in order
+const maxHeld = 100000
+
+// holdback keeps the events a run sends out of order until their delay is
+// up. They are released by the first send after that, so at low rates an
+// event can be held a little longer than its delay.
+type holdback struct {
+	rate     float64
+	maxDelay time.Duration
+
+	mu   sync.Mutex
+	held []heldEvent
+	n    atomic.Int64 // len(held), read without the lock
+}
+
+// heldEvent is an event waiting to be sent after newer ones
+type heldEvent struct {
+	selected  *weightedTemplate
+	event     *models.GeneratedEvent
+	duplicate bool
+	malformed bool
+	anomalous bool
+	due       time.Time
+}
+
+// newHoldback returns the holdback for cfg, or nil if it holds nothing
+func newHoldback(cfg *models.OutOfOrder) *holdback {
+	if cfg == nil || cfg.Rate == 0 {
+		return nil
+	}
+	maxDelay := cfg.MaxDelaySeconds
+	if maxDelay == 0 {
+		maxDelay = 30
+	}
+	return &holdback{rate: cfg.Rate, maxDelay: time.Duration(maxDelay * float64(time.Second))}
+}
+
+// hold keeps e back, at the holdback's rate, for a random delay; it
+// reports whether it did
+func (h *holdback) hold(e heldEvent, rng *rand.Rand) bool {
+	if h == nil || rng.Float64() >= h.rate || h.n.Load() >= maxHeld {
+		return false
+	}
+	e.due = time.Now().Add(time.Duration(rng.Float64() * float64(h.maxDelay)))
+	h.mu.Lock()
+	h.held = append(h.held, e)
+	h.n.Store(int64(len(h.held)))
+	h.mu.Unlock()
+	return true
+}
+
+// release removes and returns the held events due by now
+func (h *holdback) release(now time.Time) []heldEvent {
+	if h == nil || h.n.Load() == 0 {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var due []heldEvent
+	kept := h.held[:0]
+	for _, e := range h.held {
+		if e.due.After(now) {
+			kept = append(kept, e)
+		} else {
+			due = append(due, e)
+		}
+	}
+	clear(h.held[len(kept):])
+	h.held = kept
+	h.n.Store(int64(len(kept)))
+	return due
+}
+
+// drain removes and returns every held event
+func (h *holdback) drain() []heldEvent {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	held := h.held
+	h.held = nil
+	h.n.Store(0)
+	return held
+}
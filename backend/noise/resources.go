@@ -0,0 +1,256 @@
+package noise
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+	"siem-event-generator/notify"
+)
+
+// Resource guard tuning
+const (
+	resourceInterval   = 2 * time.Second
+	minThrottle        = 0.05 // Least share of the rate a run keeps
+	throttleCut        = 0.7  // Throttle factor applied at each sample over a watermark
+	throttleRecover    = 1.15 // And at each sample well under them
+	resourceHeadroom   = 10   // Percentage points under a watermark before recovering
+	resourceNotifyWait = 15 * time.Minute
+)
+
+// Watermarks set by the server limits, in percent; 0 leaves one unset
+var resourceLimits struct {
+	sync.RWMutex
+	cpu, memory float64
+}
+
+// SetResourceLimits sets the CPU and memory use, in percent of what the
+// instance is allowed, above which runs throttle themselves; 0 or 100
+// leaves a watermark unset, and runs only throttle once one is set
+func SetResourceLimits(cpu, memory float64) {
+	resourceLimits.Lock()
+	resourceLimits.cpu, resourceLimits.memory = cpu, memory
+	resourceLimits.Unlock()
+}
+
+func resourceWatermarks() (cpu, memory float64) {
+	resourceLimits.RLock()
+	defer resourceLimits.RUnlock()
+	return resourceLimits.cpu, resourceLimits.memory
+}
+
+// overWatermark reports whether use has reached a watermark that is set
+func overWatermark(use, watermark float64) bool {
+	return watermark > 0 && watermark < 100 && use >= watermark
+}
+
+// underWatermark reports whether use is well under a watermark, or the
+// watermark is unset
+func underWatermark(use, watermark float64) bool {
+	return watermark <= 0 || watermark >= 100 || use < watermark-resourceHeadroom
+}
+
+// guard samples the instance's CPU and memory while r runs and throttles
+// its rate when either nears a watermark the server limits set. The pacer drops the slots it no
+// longer hands out, so load is shed rather than caught up later.
+func (g *Generator) guard(r *run) {
+	ticker := time.NewTicker(resourceInterval)
+	defer ticker.Stop()
+
+	probe := newResourceProbe()
+	throttle := 1.0
+	var lastNotified time.Time
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		usage := probe.sample()
+		maxCPU, maxMemory := resourceWatermarks()
+
+		previous := throttle
+		switch {
+		case overWatermark(usage.MemoryPercent, maxMemory) || overWatermark(usage.CPUPercent, maxCPU):
+			throttle = math.Max(throttle*throttleCut, minThrottle)
+		case underWatermark(usage.MemoryPercent, maxMemory) && underWatermark(usage.CPUPercent, maxCPU):
+			throttle = math.Min(throttle*throttleRecover, 1)
+		}
+		usage.Throttle = math.Round(throttle*1000) / 1000
+		r.throttle.Store(math.Float64bits(throttle))
+		r.resources.Store(&usage)
+
+		if throttle < 1 && previous == 1 {
+			log.Printf("WARNING: noise generation throttled: CPU at %.0f%% of %.1f cores, memory at %.0f%%",
+				usage.CPUPercent, usage.CPULimit, usage.MemoryPercent)
+			if time.Since(lastNotified) >= resourceNotifyWait {
+				lastNotified = time.Now()
+				notifyResourcePressure(usage)
+			}
+		}
+		if throttle == 1 && previous < 1 {
+			log.Printf("Noise generation back to its full rate")
+		}
+	}
+}
+
+// notifyResourcePressure tells the notification channels that a run is
+// throttling itself
+func notifyResourcePressure(usage models.ResourceUsage) {
+	notify.Publish(models.Notification{
+		Event:   models.NotifyResourcePressure,
+		Summary: fmt.Sprintf("Noise generation is throttled: CPU at %.0f%%, memory at %.0f%% of the limit", usage.CPUPercent, usage.MemoryPercent),
+		Details: map[string]interface{}{
+			"cpu_percent":        usage.CPUPercent,
+			"cpu_limit":          usage.CPULimit,
+			"memory_bytes":       usage.MemoryBytes,
+			"memory_limit_bytes": usage.MemoryLimitBytes,
+			"memory_percent":     usage.MemoryPercent,
+			"source":             usage.Source,
+		},
+	})
+}
+
+// resourceProbe reads CPU and memory use, from the cgroup the process runs
+// in when it has a limit and from the host otherwise
+type resourceProbe struct {
+	lastCPU  time.Duration // CPU time used, as of lastTime
+	lastTime time.Time
+}
+
+func newResourceProbe() *resourceProbe {
+	p := &resourceProbe{lastTime: time.Now()}
+	p.lastCPU, _ = cpuUsage()
+	return p
+}
+
+func (p *resourceProbe) sample() models.ResourceUsage {
+	var usage models.ResourceUsage
+	usage.MemoryBytes, usage.MemoryLimitBytes, usage.Source = memoryUsage()
+	if usage.MemoryLimitBytes > 0 {
+		usage.MemoryPercent = round1(float64(usage.MemoryBytes) / float64(usage.MemoryLimitBytes) * 100)
+	}
+
+	usage.CPULimit = cpuLimit()
+	now := time.Now()
+	if used, ok := cpuUsage(); ok {
+		if elapsed := now.Sub(p.lastTime); elapsed > 0 && used >= p.lastCPU {
+			usage.CPUPercent = round1(float64(used-p.lastCPU) / float64(elapsed) / usage.CPULimit * 100)
+		}
+		p.lastCPU = used
+	}
+	p.lastTime = now
+	return usage
+}
+
+// memoryUsage returns the working set and its limit: the cgroup's if it
+// has one, otherwise the host's memory in use and its total
+func memoryUsage() (used, limit int64, source string) {
+	if max, ok := readCgroupInt("/sys/fs/cgroup/memory.max"); ok {
+		current, _ := readCgroupInt("/sys/fs/cgroup/memory.current")
+		return current - readStat("/sys/fs/cgroup/memory.stat", "inactive_file"), max, models.ResourceSourceCgroupV2
+	}
+	if max, ok := readCgroupInt("/sys/fs/cgroup/memory/memory.limit_in_bytes"); ok && max < hostMemory() {
+		current, _ := readCgroupInt("/sys/fs/cgroup/memory/memory.usage_in_bytes")
+		return current - readStat("/sys/fs/cgroup/memory/memory.stat", "total_inactive_file"), max, models.ResourceSourceCgroupV1
+	}
+	total := hostMemory()
+	if total == 0 {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return int64(m.Sys), 0, models.ResourceSourceHost
+	}
+	return total - readStat("/proc/meminfo", "MemAvailable:")*1024, total, models.ResourceSourceHost
+}
+
+// hostMemory returns the machine's memory, or 0 where it cannot be read
+func hostMemory() int64 {
+	return readStat("/proc/meminfo", "MemTotal:") * 1024
+}
+
+// cpuLimit returns the cores the process may use: the cgroup's CPU quota,
+// or every CPU without one
+func cpuLimit() float64 {
+	if data, err := os.ReadFile("/sys/fs/cgroup/cpu.max"); err == nil {
+		if quota, period, ok := strings.Cut(strings.TrimSpace(string(data)), " "); ok && quota != "max" {
+			q, err1 := strconv.ParseFloat(quota, 64)
+			p, err2 := strconv.ParseFloat(period, 64)
+			if err1 == nil && err2 == nil && q > 0 && p > 0 {
+				return q / p
+			}
+		}
+	}
+	quota, ok1 := readCgroupInt("/sys/fs/cgroup/cpu/cpu.cfs_quota_us")
+	period, ok2 := readCgroupInt("/sys/fs/cgroup/cpu/cpu.cfs_period_us")
+	if ok1 && ok2 && quota > 0 && period > 0 {
+		return float64(quota) / float64(period)
+	}
+	return float64(runtime.NumCPU())
+}
+
+// cpuUsage returns the CPU time used by the process's cgroup v2, or else
+// by the process itself
+func cpuUsage() (time.Duration, bool) {
+	if usec := readStat("/sys/fs/cgroup/cpu.stat", "usage_usec"); usec > 0 {
+		return time.Duration(usec) * time.Microsecond, true
+	}
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, false
+	}
+	// Fields after the parenthesised command name; utime and stime are the
+	// 12th and 13th of them, in clock ticks of 1/100 s
+	_, rest, ok := strings.Cut(string(data), ") ")
+	fields := strings.Fields(rest)
+	if !ok || len(fields) < 13 {
+		return 0, false
+	}
+	utime, err1 := strconv.ParseInt(fields[11], 10, 64)
+	stime, err2 := strconv.ParseInt(fields[12], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return time.Duration(utime+stime) * 10 * time.Millisecond, true
+}
+
+// readCgroupInt reads a file holding one number; "max" and missing files
+// are not ok
+func readCgroupInt(path string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	return n, err == nil
+}
+
+// readStat returns the number after key in a file of "key value" lines,
+// or 0
+func readStat(path, key string) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == key {
+			n, _ := strconv.ParseInt(fields[1], 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,26 @@
+package noise
+
+import "testing"
+
+func TestResourceWatermarksUnsetByDefault(t *testing.T) {
+	SetResourceLimits(0, 0)
+	cpu, memory := resourceWatermarks()
+	if overWatermark(99, cpu) || overWatermark(99, memory) {
+		t.Error("unset watermarks throttle")
+	}
+	if !underWatermark(99, cpu) || !underWatermark(99, memory) {
+		t.Error("unset watermarks keep a run from recovering")
+	}
+}
+
+func TestResourceWatermarks(t *testing.T) {
+	SetResourceLimits(90, 100)
+	defer SetResourceLimits(0, 0)
+	cpu, memory := resourceWatermarks()
+	if !overWatermark(90, cpu) || overWatermark(85, cpu) || underWatermark(85, cpu) || !underWatermark(79, cpu) {
+		t.Error("CPU watermark of 90 misjudged")
+	}
+	if overWatermark(100, memory) {
+		t.Error("a memory watermark of 100 throttles")
+	}
+}
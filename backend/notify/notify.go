@@ -0,0 +1,179 @@
+// Package notify tells webhooks, Slack and email recipients when noise
+// streams start and finish, when a destination's circuit opens and when its
+// volume budget runs out, so that long runs do not fail unnoticed.
+package notify
+
+import (
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+// sendTimeout bounds one delivery to one channel
+const sendTimeout = 15 * time.Second
+
+// Store provides thread-safe storage for notification channels. Channels
+// hold their secrets in plain text; callers seal them for storage.
+type Store struct {
+	mu       sync.RWMutex
+	channels map[string]*models.NotificationChannel
+}
+
+// NewStore creates an empty channel store
+func NewStore() *Store {
+	return &Store{
+		channels: make(map[string]*models.NotificationChannel),
+	}
+}
+
+// Channels is the global channel store Publish sends to
+var Channels = NewStore()
+
+// Get retrieves a copy of a channel by ID
+func (s *Store) Get(id string) (models.NotificationChannel, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ch, ok := s.channels[id]
+	if !ok {
+		return models.NotificationChannel{}, false
+	}
+	return *ch, true
+}
+
+// List returns copies of all channels ordered by name
+func (s *Store) List() []models.NotificationChannel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	channels := make([]models.NotificationChannel, 0, len(s.channels))
+	for _, ch := range s.channels {
+		channels = append(channels, *ch)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels
+}
+
+// Set adds or replaces a channel after checking it
+func (s *Store) Set(ch models.NotificationChannel) error {
+	if err := Check(ch); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[ch.ID] = &ch
+	return nil
+}
+
+// Delete removes a channel
+func (s *Store) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.channels[id]; !ok {
+		return false
+	}
+	delete(s.channels, id)
+	return true
+}
+
+// record stores the outcome of a delivery
+func (s *Store) record(id string, at time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.channels[id]
+	if !ok {
+		return
+	}
+	ch.LastError = ""
+	if err != nil {
+		ch.LastError = err.Error()
+		return
+	}
+	ch.LastSentAt = &at
+}
+
+// Check validates a channel's type, events and the settings its type needs
+func Check(ch models.NotificationChannel) error {
+	for _, event := range ch.Events {
+		if !slices.Contains(models.NotificationEvents, event) {
+			return fmt.Errorf("unknown event %q; use one of %v", event, models.NotificationEvents)
+		}
+	}
+	switch ch.Type {
+	case models.NotificationWebhook, models.NotificationSlack:
+		u, err := url.Parse(ch.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s channels need an http or https url", ch.Type)
+		}
+	case models.NotificationEmail:
+		if ch.SMTPHost == "" {
+			return fmt.Errorf("email channels need an smtp_host")
+		}
+		if ch.SMTPPort < 0 || ch.SMTPPort > 65535 {
+			return fmt.Errorf("smtp_port must be between 1 and 65535")
+		}
+		if _, err := mail.ParseAddress(ch.From); err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		if len(ch.To) == 0 {
+			return fmt.Errorf("email channels need at least one to address")
+		}
+		for _, to := range ch.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("to %q: %w", to, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown channel type %q; use webhook, slack or email", ch.Type)
+	}
+	return nil
+}
+
+// subscribed reports whether a channel wants an event
+func subscribed(ch models.NotificationChannel, event string) bool {
+	return len(ch.Events) == 0 || slices.Contains(ch.Events, event)
+}
+
+// instance names this server in notifications, for setups with several
+var instance, _ = os.Hostname()
+
+// Publish sends n to every enabled channel subscribed to its event, in the
+// background. Failures are logged and shown on the channel.
+func Publish(n models.Notification) {
+	if n.Time.IsZero() {
+		n.Time = time.Now().UTC()
+	}
+	n.Instance = instance
+	for _, ch := range Channels.List() {
+		if !ch.Enabled || !subscribed(ch, n.Event) {
+			continue
+		}
+		go func(ch models.NotificationChannel) {
+			err := Send(ch, n)
+			if err != nil {
+				log.Printf("WARNING: notification channel %s: %s: %v", ch.Name, n.Event, err)
+			}
+			Channels.record(ch.ID, time.Now(), err)
+		}(ch)
+	}
+}
+
+// Test sends a test notification to a channel, enabled or not, and
+// records the outcome
+func Test(ch models.NotificationChannel) error {
+	n := models.Notification{
+		Event:    models.NotifyTest,
+		Summary:  fmt.Sprintf("Test notification for channel %s", ch.Name),
+		Instance: instance,
+		Time:     time.Now().UTC(),
+	}
+	err := Send(ch, n)
+	Channels.record(ch.ID, time.Now(), err)
+	return err
+}
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"siem-event-generator/models"
+)
+
+var httpClient = &http.Client{Timeout: sendTimeout}
+
+// Send delivers one notification to a channel and waits for the result
+func Send(ch models.NotificationChannel, n models.Notification) error {
+	switch ch.Type {
+	case models.NotificationWebhook:
+		return sendWebhook(ch, n)
+	case models.NotificationSlack:
+		return sendSlack(ch, n)
+	case models.NotificationEmail:
+		return sendEmail(ch, n)
+	}
+	return fmt.Errorf("unknown channel type %q", ch.Type)
+}
+
+// sendWebhook posts the notification as JSON
+func sendWebhook(ch models.NotificationChannel, n models.Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return post(ch.URL, ch.Headers, body)
+}
+
+// sendSlack posts the notification as the text of a Slack message
+func sendSlack(ch models.NotificationChannel, n models.Notification) error {
+	text := "*" + n.Summary + "*"
+	if lines := detailLines(n); len(lines) > 0 {
+		text += "\n```" + strings.Join(lines, "\n") + "```"
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	return post(ch.URL, nil, body)
+}
+
+func post(url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "make-some-noise")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sendEmail mails the notification over SMTP. Port 465 connects with TLS;
+// other ports upgrade with STARTTLS when the server offers it. Credentials
+// are only sent over TLS or to localhost.
+func sendEmail(ch models.NotificationChannel, n models.Notification) error {
+	port := ch.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(ch.SMTPHost, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: ch.SMTPHost}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	var conn net.Conn
+	var err error
+	if port == 465 {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, ch.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok && port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if ch.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", ch.Username, ch.Password, ch.SMTPHost)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(ch.From); err != nil {
+		return err
+	}
+	for _, to := range ch.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(emailMessage(ch, n)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailMessage formats the notification as a plain text message
+func emailMessage(ch models.NotificationChannel, n models.Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", ch.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(ch.To, ", "))
+	fmt.Fprintf(&b, "Subject: [make-some-noise] %s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(n.Summary))
+	fmt.Fprintf(&b, "Date: %s\r\n", n.Time.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(n.Summary + "\r\n\r\n")
+	for _, line := range detailLines(n) {
+		b.WriteString(line + "\r\n")
+	}
+	return []byte(b.String())
+}
+
+// detailLines lists a notification's fields as "name: value", details
+// ordered by name
+func detailLines(n models.Notification) []string {
+	lines := []string{"event: " + n.Event, "time: " + n.Time.Format(time.RFC3339)}
+	if n.Instance != "" {
+		lines = append(lines, "instance: "+n.Instance)
+	}
+	if n.Destination != "" {
+		lines = append(lines, "destination: "+n.Destination)
+	}
+	keys := make([]string, 0, len(n.Details))
+	for k := range n.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %v", k, n.Details[k]))
+	}
+	return lines
+}
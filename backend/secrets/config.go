@@ -0,0 +1,235 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"siem-event-generator/models"
+)
+
+// Mask replaces secrets in API responses. Sending it back in an update
+// keeps the stored value.
+const Mask = "********"
+
+// sensitiveHeaders are the header name fragments whose values are secrets
+var sensitiveHeaders = []string{"authorization", "token", "key", "secret", "password", "cookie"}
+
+// isSensitiveHeader reports whether a header's value is a credential
+func isSensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveHeaders {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// fields calls fn on each secret field of cfg and stores the result: the
+// token, an inline client key, credential headers, a proxy URL with a
+// password, and the reconcile token and password. cfg.Headers and
+// cfg.Reconcile are copied before they are changed.
+func fields(cfg *models.DestinationConfig, fn func(value string) (string, error)) error {
+	var errs []error
+	apply := func(name string, v *string) {
+		out, err := fn(*v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return
+		}
+		*v = out
+	}
+
+	if cfg.Token != "" {
+		apply("token", &cfg.Token)
+	}
+	if isInlineKey(cfg.ClientKey) {
+		apply("client_key", &cfg.ClientKey)
+	}
+	if hasProxyPassword(cfg.Proxy) {
+		apply("proxy", &cfg.Proxy)
+	}
+	if len(cfg.Headers) > 0 {
+		headers := make(map[string]string, len(cfg.Headers))
+		for k, v := range cfg.Headers {
+			if v != "" && isSensitiveHeader(k) {
+				apply("headers."+k, &v)
+			}
+			headers[k] = v
+		}
+		cfg.Headers = headers
+	}
+	if rc := cfg.Reconcile; rc != nil && (rc.Token != "" || rc.Password != "") {
+		copied := *rc
+		if copied.Token != "" {
+			apply("reconcile.token", &copied.Token)
+		}
+		if copied.Password != "" {
+			apply("reconcile.password", &copied.Password)
+		}
+		cfg.Reconcile = &copied
+	}
+	return errors.Join(errs...)
+}
+
+// isInlineKey reports whether a client key holds PEM data rather than a
+// path; references and sealed values count as inline
+func isInlineKey(v string) bool {
+	return strings.Contains(v, "-----BEGIN") || IsSealed(v) || IsReference(v)
+}
+
+// hasProxyPassword reports whether a proxy setting carries a password
+func hasProxyPassword(v string) bool {
+	if IsSealed(v) || IsReference(v) {
+		return true
+	}
+	u, err := url.Parse(v)
+	if err != nil || u.User == nil {
+		return false
+	}
+	_, ok := u.User.Password()
+	return ok
+}
+
+// CheckConfig reports the secret references in cfg that CheckReference
+// refuses
+func CheckConfig(cfg models.DestinationConfig) error {
+	return fields(&cfg, func(v string) (string, error) {
+		if !IsReference(v) {
+			return v, nil
+		}
+		return v, CheckReference(v)
+	})
+}
+
+// SealConfig returns cfg with its secrets encrypted for storage
+func SealConfig(cfg models.DestinationConfig) (models.DestinationConfig, error) {
+	err := fields(&cfg, func(v string) (string, error) {
+		if IsReference(v) {
+			return v, nil
+		}
+		return Seal(v)
+	})
+	return cfg, err
+}
+
+// OpenConfig returns cfg with its secrets decrypted. Fields that cannot be
+// decrypted are left sealed and reported in the error.
+func OpenConfig(cfg models.DestinationConfig) (models.DestinationConfig, error) {
+	err := fields(&cfg, func(v string) (string, error) {
+		plain, err := Open(v)
+		if err != nil {
+			return v, err
+		}
+		return plain, nil
+	})
+	return cfg, err
+}
+
+// NeedsSealing reports whether cfg holds a secret in plain text
+func NeedsSealing(cfg models.DestinationConfig) bool {
+	found := false
+	fields(&cfg, func(v string) (string, error) {
+		if !IsSealed(v) && !IsReference(v) {
+			found = true
+		}
+		return v, nil
+	})
+	return found
+}
+
+// RedactConfig returns cfg with its secrets masked for API responses.
+// References are shown, since they name a secret rather than hold it; a
+// proxy URL keeps everything but its password.
+func RedactConfig(cfg models.DestinationConfig) models.DestinationConfig {
+	fields(&cfg, func(v string) (string, error) {
+		if IsReference(v) {
+			return v, nil
+		}
+		if u, err := url.Parse(v); err == nil && u.User != nil && u.Host != "" {
+			u.User = url.UserPassword(u.User.Username(), Mask)
+			return u.String(), nil
+		}
+		return Mask, nil
+	})
+	return cfg
+}
+
+// RestoreMasked returns cfg with every secret still holding the Mask taken
+// from existing, so clients can update a destination they read redacted
+func RestoreMasked(cfg, existing models.DestinationConfig) models.DestinationConfig {
+	if cfg.Token == Mask {
+		cfg.Token = existing.Token
+	}
+	if cfg.ClientKey == Mask {
+		cfg.ClientKey = existing.ClientKey
+	}
+	if cfg.Proxy == Mask {
+		cfg.Proxy = existing.Proxy
+	} else if u, err := url.Parse(cfg.Proxy); err == nil && u.User != nil {
+		// Keep the stored password for an edited proxy URL
+		if password, _ := u.User.Password(); password == Mask {
+			if old, err := url.Parse(existing.Proxy); err == nil && old.User != nil {
+				oldPassword, _ := old.User.Password()
+				u.User = url.UserPassword(u.User.Username(), oldPassword)
+				cfg.Proxy = u.String()
+			}
+		}
+	}
+	if len(cfg.Headers) > 0 {
+		headers := make(map[string]string, len(cfg.Headers))
+		for k, v := range cfg.Headers {
+			if v == Mask {
+				v = existing.Headers[k]
+			}
+			headers[k] = v
+		}
+		cfg.Headers = headers
+	}
+	if rc := cfg.Reconcile; rc != nil && existing.Reconcile != nil {
+		copied := *rc
+		if copied.Token == Mask {
+			copied.Token = existing.Reconcile.Token
+		}
+		if copied.Password == Mask {
+			copied.Password = existing.Reconcile.Password
+		}
+		cfg.Reconcile = &copied
+	}
+	return cfg
+}
+
+// IsMasked reports whether any secret in cfg still holds the Mask, as in a
+// config copied from an API response
+func IsMasked(cfg models.DestinationConfig) bool {
+	found := false
+	fields(&cfg, func(v string) (string, error) {
+		if v == Mask {
+			found = true
+		} else if u, err := url.Parse(v); err == nil && u.User != nil {
+			if password, _ := u.User.Password(); password == Mask {
+				found = true
+			}
+		}
+		return v, nil
+	})
+	return found
+}
+
+// ResolveConfig returns cfg with sealed secrets decrypted and references
+// fetched from their secret stores, ready for a sender to use
+func ResolveConfig(ctx context.Context, cfg models.DestinationConfig) (models.DestinationConfig, error) {
+	err := fields(&cfg, func(v string) (string, error) {
+		if IsSealed(v) {
+			return Open(v)
+		}
+		if IsReference(v) {
+			return Resolve(ctx, v)
+		}
+		return v, nil
+	})
+	return cfg, err
+}
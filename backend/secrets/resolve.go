@@ -0,0 +1,287 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheTTL is how long a fetched secret is reused before it is fetched again
+const cacheTTL = 5 * time.Minute
+
+// Resolver fetches secrets from an external store. ref is the reference
+// with its scheme removed, e.g. "secret/data/splunk#hec_token" for
+// "vault:secret/data/splunk#hec_token".
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to Resolver
+type ResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{
+		"env":    ResolverFunc(resolveEnv),
+		"file":   ResolverFunc(resolveFile),
+		"vault":  ResolverFunc(resolveVault),
+		"aws-sm": ResolverFunc(resolveAWS),
+	}
+
+	cacheMu sync.Mutex
+	cache   = make(map[string]cachedSecret)
+)
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// RegisterResolver adds or replaces the resolver for references starting
+// with scheme and a colon
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = r
+}
+
+// scheme returns the resolver scheme of a reference, or "" if value is not one
+func scheme(value string) string {
+	i := strings.Index(value, ":")
+	if i <= 0 {
+		return ""
+	}
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	if _, ok := resolvers[value[:i]]; !ok {
+		return ""
+	}
+	return value[:i]
+}
+
+// IsReference reports whether value names a secret in an external store
+// (env:, file:, vault:, aws-sm: or a registered scheme) rather than holding it
+func IsReference(value string) bool {
+	return scheme(value) != ""
+}
+
+// CheckReference reports whether the operator allows a reference to be
+// resolved. env: references must name a variable starting with
+// SECRETS_ENV_PREFIX, file: references a file under SECRETS_FILE_DIR,
+// vault: references a path starting with SECRETS_VAULT_PREFIX and aws-sm:
+// references a secret name or ARN starting with SECRETS_AWS_PREFIX. Each
+// scheme is refused while its setting is empty, so that whoever can edit a
+// destination cannot send the server's own environment, files or store
+// secrets to a host they control.
+func CheckReference(ref string) error {
+	switch s := scheme(ref); s {
+	case "env":
+		return checkEnv(strings.TrimPrefix(ref, s+":"))
+	case "file":
+		return checkFile(strings.TrimPrefix(ref, s+":"))
+	case "vault":
+		return checkVault(strings.TrimPrefix(ref, s+":"))
+	case "aws-sm":
+		return checkAWS(strings.TrimPrefix(ref, s+":"))
+	}
+	return nil
+}
+
+// checkEnv reports whether SECRETS_ENV_PREFIX allows a variable
+func checkEnv(name string) error {
+	prefix := os.Getenv("SECRETS_ENV_PREFIX")
+	if prefix == "" {
+		return fmt.Errorf("env: secret references are disabled; set SECRETS_ENV_PREFIX to allow them")
+	}
+	if !strings.HasPrefix(name, prefix) {
+		return fmt.Errorf("environment variable %s does not start with SECRETS_ENV_PREFIX %s", name, prefix)
+	}
+	return nil
+}
+
+// checkFile reports whether a file lies under SECRETS_FILE_DIR once
+// symlinks are followed, so a link cannot point outside it
+func checkFile(path string) error {
+	dir := os.Getenv("SECRETS_FILE_DIR")
+	if dir == "" {
+		return fmt.Errorf("file: secret references are disabled; set SECRETS_FILE_DIR to allow them")
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%s is not a file under SECRETS_FILE_DIR %s", path, dir)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return fmt.Errorf("SECRETS_FILE_DIR: %w", err)
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is not a file under SECRETS_FILE_DIR %s", path, dir)
+	}
+	return nil
+}
+
+// checkVault reports whether SECRETS_VAULT_PREFIX allows a Vault path
+func checkVault(ref string) error {
+	prefix := os.Getenv("SECRETS_VAULT_PREFIX")
+	if prefix == "" {
+		return fmt.Errorf("vault: secret references are disabled; set SECRETS_VAULT_PREFIX to allow them")
+	}
+	p, _ := splitRef(ref)
+	p = strings.TrimPrefix(p, "/")
+	if p != path.Clean(p) || strings.HasPrefix(p, "../") || !strings.HasPrefix(p, strings.TrimPrefix(prefix, "/")) {
+		return fmt.Errorf("vault path %s does not start with SECRETS_VAULT_PREFIX %s", p, prefix)
+	}
+	return nil
+}
+
+// checkAWS reports whether SECRETS_AWS_PREFIX allows a secret name or ARN
+func checkAWS(ref string) error {
+	prefix := os.Getenv("SECRETS_AWS_PREFIX")
+	if prefix == "" {
+		return fmt.Errorf("aws-sm: secret references are disabled; set SECRETS_AWS_PREFIX to allow them")
+	}
+	secretID, _ := splitRef(ref)
+	if !strings.HasPrefix(secretID, prefix) {
+		return fmt.Errorf("secret %s does not start with SECRETS_AWS_PREFIX %s", secretID, prefix)
+	}
+	return nil
+}
+
+// Resolve fetches the secret a reference names. Results are cached for a
+// few minutes so senders created together fetch each secret once.
+func Resolve(ctx context.Context, ref string) (string, error) {
+	s := scheme(ref)
+	if s == "" {
+		return "", fmt.Errorf("not a secret reference")
+	}
+
+	cacheMu.Lock()
+	cached, ok := cache[ref]
+	cacheMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	resolversMu.RLock()
+	r := resolvers[s]
+	resolversMu.RUnlock()
+
+	value, err := r.Resolve(ctx, strings.TrimPrefix(ref, s+":"))
+	if err != nil {
+		return "", fmt.Errorf("resolve %s secret: %w", s, err)
+	}
+
+	cacheMu.Lock()
+	cache[ref] = cachedSecret{value: value, expires: time.Now().Add(cacheTTL)}
+	cacheMu.Unlock()
+	return value, nil
+}
+
+// splitRef splits "path#key" into its path and key
+func splitRef(ref string) (string, string) {
+	path, key, _ := strings.Cut(ref, "#")
+	return path, key
+}
+
+// resolveEnv reads an environment variable that SECRETS_ENV_PREFIX allows:
+// env:HEC_TOKEN
+func resolveEnv(_ context.Context, ref string) (string, error) {
+	if err := checkEnv(ref); err != nil {
+		return "", err
+	}
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return value, nil
+}
+
+// resolveFile reads a file under SECRETS_FILE_DIR, such as a Docker
+// secret, without its trailing newline: file:/run/secrets/hec_token
+func resolveFile(_ context.Context, ref string) (string, error) {
+	if err := checkFile(ref); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveVault reads a key from a HashiCorp Vault KV secret:
+// vault:secret/data/splunk#hec_token. VAULT_ADDR and VAULT_TOKEN configure
+// the client and VAULT_NAMESPACE is sent when set. Both KV v1 and v2
+// responses are understood; the key defaults to "value". The path must
+// start with SECRETS_VAULT_PREFIX.
+func resolveVault(ctx context.Context, ref string) (string, error) {
+	if err := checkVault(ref); err != nil {
+		return "", err
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	secretPath, key := splitRef(ref)
+	if key == "" {
+		key = "value"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(secretPath, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := secretClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, secretPath)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("parse vault response: %w", err)
+	}
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		data = inner // KV v2
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("key %q not found in %s", key, secretPath)
+	}
+	return value, nil
+}
+
+// secretClient fetches secrets from Vault and AWS
+var secretClient = &http.Client{Timeout: 10 * time.Second}
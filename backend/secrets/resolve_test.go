@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckEnvReference(t *testing.T) {
+	t.Setenv("SECRETS_ENV_PREFIX", "")
+	if err := CheckReference("env:MSN_SECRET_TOKEN"); err == nil {
+		t.Error("env: reference allowed without SECRETS_ENV_PREFIX")
+	}
+
+	t.Setenv("SECRETS_ENV_PREFIX", "MSN_SECRET_")
+	if err := CheckReference("env:MSN_SECRET_TOKEN"); err != nil {
+		t.Errorf("allowed variable refused: %v", err)
+	}
+	if err := CheckReference("env:AWS_SECRET_ACCESS_KEY"); err == nil {
+		t.Error("variable outside the prefix allowed")
+	}
+}
+
+func TestCheckFileReference(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	inside := filepath.Join(dir, "token")
+	secret := filepath.Join(outside, "secret")
+	for _, f := range []string{inside, secret} {
+		if err := os.WriteFile(f, []byte("value\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SECRETS_FILE_DIR", "")
+	if err := CheckReference("file:" + inside); err == nil {
+		t.Error("file: reference allowed without SECRETS_FILE_DIR")
+	}
+
+	t.Setenv("SECRETS_FILE_DIR", dir)
+	if err := CheckReference("file:" + inside); err != nil {
+		t.Errorf("file in the directory refused: %v", err)
+	}
+	for _, ref := range []string{"file:" + secret, "file:" + dir + "/../" + filepath.Base(outside) + "/secret", "file:" + link, "file:token"} {
+		if err := CheckReference(ref); err == nil {
+			t.Errorf("%s allowed", ref)
+		}
+	}
+	if _, err := Resolve(context.Background(), "file:"+link); err == nil {
+		t.Error("resolved a symlink out of SECRETS_FILE_DIR")
+	}
+}
+
+func TestCheckVaultReference(t *testing.T) {
+	t.Setenv("SECRETS_VAULT_PREFIX", "")
+	if err := CheckReference("vault:secret/data/msn/hec#token"); err == nil {
+		t.Error("vault: reference allowed without SECRETS_VAULT_PREFIX")
+	}
+
+	t.Setenv("SECRETS_VAULT_PREFIX", "secret/data/msn/")
+	for _, ref := range []string{"vault:secret/data/msn/hec#token", "vault:/secret/data/msn/hec"} {
+		if err := CheckReference(ref); err != nil {
+			t.Errorf("%s refused: %v", ref, err)
+		}
+	}
+	for _, ref := range []string{"vault:secret/data/other#token", "vault:secret/data/msn/../other#token"} {
+		if err := CheckReference(ref); err == nil {
+			t.Errorf("%s allowed", ref)
+		}
+	}
+	if _, err := Resolve(context.Background(), "vault:secret/data/other#token"); err == nil {
+		t.Error("resolved a vault path outside the prefix")
+	}
+}
+
+func TestCheckAWSReference(t *testing.T) {
+	t.Setenv("SECRETS_AWS_PREFIX", "")
+	if err := CheckReference("aws-sm:msn/hec#token"); err == nil {
+		t.Error("aws-sm: reference allowed without SECRETS_AWS_PREFIX")
+	}
+
+	t.Setenv("SECRETS_AWS_PREFIX", "msn/")
+	if err := CheckReference("aws-sm:msn/hec#token"); err != nil {
+		t.Errorf("allowed secret refused: %v", err)
+	}
+	if err := CheckReference("aws-sm:prod/database#password"); err == nil {
+		t.Error("secret outside the prefix allowed")
+	}
+}
@@ -0,0 +1,154 @@
+// Package secrets protects destination credentials: it encrypts them for
+// storage, resolves references to external secret stores, and redacts them
+// from API responses.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// sealedPrefix marks a value encrypted by Seal
+const sealedPrefix = "enc:v1:"
+
+// keyFileName is the key generated in the config directory when no key is
+// configured
+const keyFileName = "secrets.key"
+
+// ErrNoKey is returned by Seal and Open before Init has loaded a key
+var ErrNoKey = errors.New("secrets key not loaded")
+
+var (
+	mu   sync.RWMutex
+	aead cipher.AEAD
+)
+
+// Init loads the encryption key from SECRETS_KEY, else from the file named
+// by SECRETS_KEY_FILE, else from secrets.key in configDir, which is created
+// with a random key if missing. A key is 32 bytes, base64-encoded; any other
+// value is treated as a passphrase and hashed to a key.
+func Init(configDir string) error {
+	var material string
+	switch {
+	case os.Getenv("SECRETS_KEY") != "":
+		material = os.Getenv("SECRETS_KEY")
+	case os.Getenv("SECRETS_KEY_FILE") != "":
+		data, err := os.ReadFile(os.Getenv("SECRETS_KEY_FILE"))
+		if err != nil {
+			return fmt.Errorf("read SECRETS_KEY_FILE: %w", err)
+		}
+		material = string(data)
+	default:
+		data, err := loadOrCreateKeyFile(filepath.Join(configDir, keyFileName))
+		if err != nil {
+			return err
+		}
+		material = data
+	}
+
+	block, err := aes.NewCipher(deriveKey(strings.TrimSpace(material)))
+	if err != nil {
+		return err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	aead = gcm
+	mu.Unlock()
+	return nil
+}
+
+// loadOrCreateKeyFile reads the key file, writing a new random key first if
+// it does not exist
+func loadOrCreateKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return string(data), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("read key file: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	encoded := base64.StdEncoding.EncodeToString(key)
+	if err := os.WriteFile(path, []byte(encoded+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("write key file: %w", err)
+	}
+	return encoded, nil
+}
+
+// deriveKey returns a base64-encoded 32-byte key as is, and hashes anything
+// else as a passphrase
+func deriveKey(material string) []byte {
+	if key, err := base64.StdEncoding.DecodeString(material); err == nil && len(key) == 32 {
+		return key
+	}
+	sum := sha256.Sum256([]byte(material))
+	return sum[:]
+}
+
+// IsSealed reports whether value was encrypted by Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Seal encrypts value for storage. Empty and already sealed values are
+// returned unchanged.
+func Seal(value string) (string, error) {
+	if value == "" || IsSealed(value) {
+		return value, nil
+	}
+	mu.RLock()
+	gcm := aead
+	mu.RUnlock()
+	if gcm == nil {
+		return "", ErrNoKey
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal. Values that are not sealed are
+// returned unchanged.
+func Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	mu.RLock()
+	gcm := aead
+	mu.RUnlock()
+	if gcm == nil {
+		return "", ErrNoKey
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt secret: wrong key?")
+	}
+	return string(plain), nil
+}
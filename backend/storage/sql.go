@@ -0,0 +1,363 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// dialect holds what differs between the SQL databases
+type dialect struct {
+	driver string
+	// serial is the column type of an auto-incrementing primary key
+	serial string
+	// numbered placeholders ($1) instead of ?
+	numbered bool
+	// lock serializes writers to a collection inside a transaction; empty
+	// when the database locks on its own
+	lock string
+}
+
+var sqliteDialect = dialect{
+	driver: "sqlite3",
+	serial: "INTEGER PRIMARY KEY AUTOINCREMENT",
+}
+
+var postgresDialect = dialect{
+	driver:   "postgres",
+	serial:   "BIGSERIAL PRIMARY KEY",
+	numbered: true,
+	lock:     "SELECT pg_advisory_xact_lock(hashtext(?))",
+}
+
+// sqliteDSN opens the file in WAL mode, which lets the API read while a
+// save is in progress. Transactions take the write lock when they begin, so
+// concurrent saves wait for each other instead of failing.
+func sqliteDSN(path string) string {
+	return "file:" + path + "?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+}
+
+// rebind rewrites ? placeholders for the dialect
+func (d dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// migrations create and evolve the schema; each entry is one schema version
+var migrations = [][]string{
+	{
+		`CREATE TABLE documents (
+			collection TEXT NOT NULL,
+			id TEXT NOT NULL,
+			data TEXT NOT NULL,
+			updated_at BIGINT NOT NULL,
+			PRIMARY KEY (collection, id)
+		)`,
+		`CREATE TABLE document_history (
+			seq {{serial}},
+			collection TEXT NOT NULL,
+			id TEXT NOT NULL,
+			data TEXT,
+			changed_at BIGINT NOT NULL
+		)`,
+		`CREATE INDEX document_history_doc ON document_history (collection, id, seq)`,
+		`CREATE TABLE stats (
+			seq {{serial}},
+			kind TEXT NOT NULL,
+			stat_key TEXT NOT NULL,
+			recorded_at BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX stats_kind_time ON stats (kind, recorded_at)`,
+	},
+}
+
+// sqlStore implements Store on database/sql
+type sqlStore struct {
+	db *sql.DB
+	d  dialect
+}
+
+func openSQL(d dialect, dsn string) (*sqlStore, error) {
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	s := &sqlStore{db: db, d: d}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to %s: %w", d.driver, err)
+	}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate schema: %w", err)
+	}
+	return s, nil
+}
+
+// migrate applies the migrations the database has not seen yet
+func (s *sqlStore) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS meta (name TEXT PRIMARY KEY, value TEXT NOT NULL)`); err != nil {
+		return err
+	}
+	current := 0
+	if v, ok, err := s.Meta(ctx, "schema_version"); err != nil {
+		return err
+	} else if ok {
+		current, _ = strconv.Atoi(v)
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this server supports (%d)", current, len(migrations))
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range migrations[version-1] {
+			if _, err := tx.ExecContext(ctx, strings.ReplaceAll(stmt, "{{serial}}", s.d.serial)); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("version %d: %w", version, err)
+			}
+		}
+		if err := setMeta(ctx, tx, s.d, "schema_version", strconv.Itoa(version)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryer is what *sql.DB and *sql.Tx have in common
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func loadDocuments(ctx context.Context, q queryer, d dialect, collection string) (map[string][]byte, error) {
+	rows, err := q.QueryContext(ctx, d.rebind(`SELECT id, data FROM documents WHERE collection = ?`), collection)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := make(map[string][]byte)
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		docs[id] = []byte(data)
+	}
+	return docs, rows.Err()
+}
+
+func (s *sqlStore) Load(ctx context.Context, collection string) (map[string][]byte, error) {
+	return loadDocuments(ctx, s.db, s.d, collection)
+}
+
+// write runs fn in a transaction holding the collection's write lock
+func (s *sqlStore) write(ctx context.Context, collection string, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if s.d.lock != "" {
+		if _, err := tx.ExecContext(ctx, s.d.rebind(s.d.lock), collection); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// putDocument stores a changed document and adds it to the history; nil
+// data deletes the document
+func putDocument(ctx context.Context, tx *sql.Tx, d dialect, collection, id string, data []byte, now int64) error {
+	var err error
+	if data == nil {
+		_, err = tx.ExecContext(ctx, d.rebind(`DELETE FROM documents WHERE collection = ? AND id = ?`), collection, id)
+	} else {
+		_, err = tx.ExecContext(ctx, d.rebind(`INSERT INTO documents (collection, id, data, updated_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (collection, id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`),
+			collection, id, string(data), now)
+	}
+	if err != nil {
+		return err
+	}
+
+	var historyData interface{}
+	if data != nil {
+		historyData = string(data)
+	}
+	_, err = tx.ExecContext(ctx, d.rebind(`INSERT INTO document_history (collection, id, data, changed_at) VALUES (?, ?, ?, ?)`),
+		collection, id, historyData, now)
+	return err
+}
+
+func (s *sqlStore) Save(ctx context.Context, collection string, docs map[string][]byte) error {
+	return s.write(ctx, collection, func(tx *sql.Tx) error {
+		current, err := loadDocuments(ctx, tx, s.d, collection)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		for id, data := range docs {
+			if old, ok := current[id]; ok && bytes.Equal(old, data) {
+				continue
+			}
+			if err := putDocument(ctx, tx, s.d, collection, id, data, now); err != nil {
+				return err
+			}
+		}
+		for id := range current {
+			if _, ok := docs[id]; !ok {
+				if err := putDocument(ctx, tx, s.d, collection, id, nil, now); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlStore) Put(ctx context.Context, collection, id string, data []byte) error {
+	return s.write(ctx, collection, func(tx *sql.Tx) error {
+		var old string
+		err := tx.QueryRowContext(ctx, s.d.rebind(`SELECT data FROM documents WHERE collection = ? AND id = ?`), collection, id).Scan(&old)
+		if err == nil && old == string(data) {
+			return nil
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		return putDocument(ctx, tx, s.d, collection, id, data, time.Now().UnixMilli())
+	})
+}
+
+func (s *sqlStore) History(ctx context.Context, collection, id string, limit int) ([]Revision, error) {
+	query := `SELECT id, data, changed_at FROM document_history WHERE collection = ?`
+	args := []interface{}{collection}
+	if id != "" {
+		query += ` AND id = ?`
+		args = append(args, id)
+	}
+	query += ` ORDER BY seq DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, s.d.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]Revision, 0)
+	for rows.Next() {
+		var r Revision
+		var data sql.NullString
+		var changedAt int64
+		if err := rows.Scan(&r.ID, &data, &changedAt); err != nil {
+			return nil, err
+		}
+		if data.Valid {
+			r.Data = []byte(data.String)
+		} else {
+			r.Deleted = true
+		}
+		r.ChangedAt = time.UnixMilli(changedAt).UTC()
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+func (s *sqlStore) RecordStats(ctx context.Context, sample Sample) error {
+	_, err := s.db.ExecContext(ctx, s.d.rebind(`INSERT INTO stats (kind, stat_key, recorded_at, data) VALUES (?, ?, ?, ?)`),
+		sample.Kind, sample.Key, sample.RecordedAt.UnixMilli(), string(sample.Data))
+	return err
+}
+
+func (s *sqlStore) Stats(ctx context.Context, kind string, since time.Time, limit int) ([]Sample, error) {
+	rows, err := s.db.QueryContext(ctx, s.d.rebind(`SELECT stat_key, recorded_at, data FROM stats
+		WHERE kind = ? AND recorded_at >= ? ORDER BY recorded_at DESC, seq DESC LIMIT ?`),
+		kind, since.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := make([]Sample, 0)
+	for rows.Next() {
+		sample := Sample{Kind: kind}
+		var recordedAt int64
+		var data string
+		if err := rows.Scan(&sample.Key, &recordedAt, &data); err != nil {
+			return nil, err
+		}
+		sample.RecordedAt = time.UnixMilli(recordedAt).UTC()
+		sample.Data = []byte(data)
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+func (s *sqlStore) PruneStats(ctx context.Context, before time.Time) error {
+	_, err := s.db.ExecContext(ctx, s.d.rebind(`DELETE FROM stats WHERE recorded_at < ?`), before.UnixMilli())
+	return err
+}
+
+func (s *sqlStore) Meta(ctx context.Context, name string) (string, bool, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, s.d.rebind(`SELECT value FROM meta WHERE name = ?`), name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (s *sqlStore) SetMeta(ctx context.Context, name, value string) error {
+	return setMeta(ctx, s.db, s.d, name, value)
+}
+
+func setMeta(ctx context.Context, q queryer, d dialect, name, value string) error {
+	_, err := q.ExecContext(ctx, d.rebind(`INSERT INTO meta (name, value) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET value = excluded.value`), name, value)
+	return err
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,138 @@
+// Package storage keeps configuration and statistics in SQLite (the
+// default) or PostgreSQL. Configuration is stored as JSON documents grouped
+// into collections, and every change to a document is kept in its history.
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Store persists configuration documents and statistics samples
+type Store interface {
+	// Load returns the documents of a collection keyed by ID
+	Load(ctx context.Context, collection string) (map[string][]byte, error)
+	// Save makes docs the complete contents of a collection in one
+	// transaction. Documents that changed are added to the history and
+	// documents missing from docs are deleted.
+	Save(ctx context.Context, collection string, docs map[string][]byte) error
+	// Put creates or replaces one document of a collection
+	Put(ctx context.Context, collection, id string, data []byte) error
+	// History returns the versions of a document, newest first, or of every
+	// document in the collection when id is empty
+	History(ctx context.Context, collection, id string, limit int) ([]Revision, error)
+
+	// RecordStats appends a statistics sample
+	RecordStats(ctx context.Context, sample Sample) error
+	// Stats returns the samples of a kind recorded since a time, newest first
+	Stats(ctx context.Context, kind string, since time.Time, limit int) ([]Sample, error)
+	// PruneStats deletes samples recorded before a time
+	PruneStats(ctx context.Context, before time.Time) error
+
+	// Meta and SetMeta keep markers such as completed migrations, and
+	// state that needs no history
+	Meta(ctx context.Context, name string) (string, bool, error)
+	SetMeta(ctx context.Context, name, value string) error
+
+	Close() error
+}
+
+// Revision is one saved version of a document
+type Revision struct {
+	ID        string          `json:"id"`
+	Data      json.RawMessage `json:"data,omitempty"` // Empty when the document was deleted
+	Deleted   bool            `json:"deleted"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+// Sample is a statistics snapshot, e.g. of a noise run
+type Sample struct {
+	Kind       string          `json:"kind"`
+	Key        string          `json:"key"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Collections the server stores
+const (
+	CollectionDestinations  = "destinations"
+	CollectionTemplates     = "templates"
+	CollectionScenarios     = "scenarios"
+	CollectionIndicators    = "ioc_indicators"
+	CollectionIOCFeeds      = "ioc_feeds"
+	CollectionHooks         = "event_hooks"
+	CollectionNotifications = "notification_channels"
+	CollectionPresets       = "override_presets"
+	CollectionCampaigns     = "campaigns"
+	// CollectionFavorites holds one document per user
+	CollectionFavorites = "favorites"
+	// CollectionSettings holds one document per settings page, e.g.
+	// geo_policy
+	CollectionSettings = "settings"
+)
+
+// MetaFilesMigrated is set once the JSON files of earlier versions have
+// been copied into the database
+const MetaFilesMigrated = "files_migrated"
+
+// MetaStreams holds the noise run and scenarios to resume after a restart
+const MetaStreams = "stream_state"
+
+// MetaRecentPrefix prefixes the user name of each user's recently used
+// templates and destinations
+const MetaRecentPrefix = "recent_use:"
+
+// MetaCorpus holds the output snapshot that corpus diffs compare against
+const MetaCorpus = "corpus"
+
+// Storage drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Config selects and locates the database
+type Config struct {
+	Driver string
+	// DSN is the database file for SQLite and a connection URL for
+	// PostgreSQL
+	DSN string
+}
+
+// ConfigFromEnv reads STORAGE (sqlite or postgres) and DATABASE_URL. SQLite
+// uses SQLITE_PATH, else make-some-noise.db in configDir.
+func ConfigFromEnv(configDir string) Config {
+	cfg := Config{Driver: os.Getenv("STORAGE")}
+	if cfg.Driver == "" {
+		cfg.Driver = DriverSQLite
+	}
+	switch cfg.Driver {
+	case DriverPostgres:
+		cfg.DSN = os.Getenv("DATABASE_URL")
+	case DriverSQLite:
+		cfg.DSN = os.Getenv("SQLITE_PATH")
+		if cfg.DSN == "" {
+			cfg.DSN = filepath.Join(configDir, "make-some-noise.db")
+		}
+	}
+	return cfg
+}
+
+// Open connects to the database and brings its schema up to date
+func Open(cfg Config) (Store, error) {
+	switch cfg.Driver {
+	case DriverSQLite:
+		return openSQL(sqliteDialect, sqliteDSN(cfg.DSN))
+	case DriverPostgres:
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("DATABASE_URL is required for postgres storage")
+		}
+		return openSQL(postgresDialect, cfg.DSN)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q (use sqlite or postgres)", cfg.Driver)
+	}
+}
@@ -8,13 +8,31 @@ import type {
   Destination,
   TestConnectionResponse,
   HealthResponse,
+  ErrorCodeInfo,
   DestinationType,
   DestinationConfig,
   NoiseStartRequest,
   NoiseUpdateRequest,
   NoiseStatus,
   NoiseStats,
+  AnomalyRequest,
+  UserProfile,
   EventSourceTree,
+  SampleSet,
+  CatalogResult,
+  Favorites,
+  RecentUse,
+  RunRecord,
+  ReplayRequest,
+  NotificationChannel,
+  NotificationEvent,
+  OverridePreset,
+  Campaign,
+  ListPage,
+  ListParams,
+  Reconciliation,
+  SigmaRequest,
+  SigmaResponse,
 } from '../types';
 
 const api = axios.create({
@@ -30,8 +48,14 @@ export const getHealth = async (): Promise<HealthResponse> => {
   return response.data;
 };
 
+// Error codes the API answers with
+export const getErrorCatalog = async (): Promise<ErrorCodeInfo[]> => {
+  const response = await api.get('/errors');
+  return response.data.codes;
+};
+
 // Event Types
-export const getEventTypes = async (): Promise<{ event_types: EventType[]; count: number }> => {
+export const getEventTypes = async (): Promise<{ event_types: EventType[]; count: number } & ListPage> => {
   const response = await api.get('/event-types');
   return response.data;
 };
@@ -52,18 +76,25 @@ export const generateEvents = async (request: GenerateRequest): Promise<Generate
 export const previewEvent = async (
   eventType: string,
   eventId?: string,
-  overrides?: Record<string, unknown>
+  overrides?: Record<string, unknown>,
+  preset?: string
 ): Promise<GeneratedEvent> => {
   const response = await api.post('/generate/preview', {
     event_type: eventType,
     event_id: eventId,
     overrides,
+    preset,
   });
   return response.data;
 };
 
+export const generateFromSigma = async (request: SigmaRequest): Promise<SigmaResponse> => {
+  const response = await api.post('/sigma/generate', request);
+  return response.data;
+};
+
 // Destinations
-export const getDestinations = async (): Promise<{ destinations: Destination[]; count: number }> => {
+export const getDestinations = async (): Promise<{ destinations: Destination[]; count: number } & ListPage> => {
   const response = await api.get('/destinations');
   return response.data;
 };
@@ -108,7 +139,7 @@ export const testDestinationConfig = async (
 // Templates
 export const getTemplates = async (
   category?: string
-): Promise<{ templates: EventTemplate[]; count: number }> => {
+): Promise<{ templates: EventTemplate[]; count: number } & ListPage> => {
   const params = category ? { category } : {};
   const response = await api.get('/templates', { params });
   return response.data;
@@ -138,12 +169,139 @@ export const deleteTemplate = async (id: string): Promise<void> => {
   await api.delete(`/templates/${id}`);
 };
 
+// Override presets
+export const getPresets = async (params?: {
+  event_type?: string;
+  template_id?: string;
+}): Promise<{ presets: OverridePreset[]; count: number } & ListPage> => {
+  const response = await api.get('/presets', { params });
+  return response.data;
+};
+
+export const createPreset = async (
+  preset: Omit<OverridePreset, 'id' | 'created_at' | 'updated_at'>
+): Promise<OverridePreset> => {
+  const response = await api.post('/presets', preset);
+  return response.data;
+};
+
+export const updatePreset = async (
+  id: string,
+  preset: Omit<OverridePreset, 'id' | 'created_at' | 'updated_at'>
+): Promise<OverridePreset> => {
+  const response = await api.put(`/presets/${id}`, preset);
+  return response.data;
+};
+
+export const deletePreset = async (id: string): Promise<void> => {
+  await api.delete(`/presets/${id}`);
+};
+
+// Campaigns
+export const getCampaigns = async (): Promise<{ campaigns: Campaign[]; count: number } & ListPage> => {
+  const response = await api.get('/campaigns');
+  return response.data;
+};
+
+export const createCampaign = async (
+  campaign: Omit<Campaign, 'id' | 'activated_at' | 'created_at' | 'updated_at'>
+): Promise<Campaign> => {
+  const response = await api.post('/campaigns', campaign);
+  return response.data;
+};
+
+export const updateCampaign = async (
+  id: string,
+  campaign: Pick<Campaign, 'name' | 'description' | 'variables'>
+): Promise<Campaign> => {
+  const response = await api.put(`/campaigns/${id}`, campaign);
+  return response.data;
+};
+
+export const deleteCampaign = async (id: string): Promise<void> => {
+  await api.delete(`/campaigns/${id}`);
+};
+
+export const activateCampaign = async (id: string): Promise<Campaign> => {
+  const response = await api.post(`/campaigns/${id}/activate`);
+  return response.data;
+};
+
+export const deactivateCampaign = async (id: string): Promise<Campaign> => {
+  const response = await api.post(`/campaigns/${id}/deactivate`);
+  return response.data;
+};
+
+export const searchCatalog = async (params?: {
+  q?: string;
+  category?: string[];
+  format?: string[];
+  vendor?: string[];
+  technique?: string[];
+  datamodel?: string[];
+  source?: 'builtin' | 'custom';
+  offset?: number;
+  limit?: number;
+}): Promise<CatalogResult> => {
+  const response = await api.get('/catalog', { params, paramsSerializer: { indexes: null } });
+  return response.data;
+};
+
+// Favorites and recent use, kept per X-User (the default user when unset)
+export const getFavorites = async (): Promise<Favorites> => {
+  const response = await api.get('/favorites');
+  return response.data;
+};
+
+const favoriteTemplatePath = (eventType: string, templateId?: string) =>
+  `/favorites/templates/${encodeURIComponent(eventType)}` +
+  (templateId ? `/${encodeURIComponent(templateId)}` : '');
+
+export const addFavoriteTemplate = async (eventType: string, templateId?: string): Promise<Favorites> => {
+  const response = await api.put(favoriteTemplatePath(eventType, templateId));
+  return response.data;
+};
+
+export const removeFavoriteTemplate = async (eventType: string, templateId?: string): Promise<Favorites> => {
+  const response = await api.delete(favoriteTemplatePath(eventType, templateId));
+  return response.data;
+};
+
+export const addFavoriteDestination = async (id: string): Promise<Favorites> => {
+  const response = await api.put(`/favorites/destinations/${id}`);
+  return response.data;
+};
+
+export const removeFavoriteDestination = async (id: string): Promise<Favorites> => {
+  const response = await api.delete(`/favorites/destinations/${id}`);
+  return response.data;
+};
+
+export const getRecent = async (): Promise<RecentUse> => {
+  const response = await api.get('/recent');
+  return response.data;
+};
+
+export const clearRecent = async (): Promise<RecentUse> => {
+  const response = await api.delete('/recent');
+  return response.data;
+};
+
 // Event Sources (for Noise Generator)
 export const getEventSources = async (): Promise<EventSourceTree> => {
   const response = await api.get('/event-sources');
   return response.data;
 };
 
+export const getSamples = async (params?: {
+  count?: number;
+  category?: string;
+  output?: 'raw' | 'fields';
+}): Promise<SampleSet> => {
+  const response = await api.get('/samples', { params });
+  return response.data;
+};
+
 // Noise Generation
 export const startNoise = async (
   request: NoiseStartRequest
@@ -174,4 +332,90 @@ export const getNoiseStats = async (): Promise<NoiseStats> => {
   return response.data;
 };
 
+export const injectAnomalies = async (
+  request: AnomalyRequest
+): Promise<{ success: boolean; message: string }> => {
+  const response = await api.post('/noise/anomalies', request);
+  return response.data;
+};
+
+// Behavioral profiles
+export const getUserProfiles = async (
+  params?: ListParams
+): Promise<{ profiles: UserProfile[]; count: number } & ListPage> => {
+  const response = await api.get('/profiles', { params });
+  return response.data;
+};
+
+export const getUserProfile = async (username: string): Promise<UserProfile> => {
+  const response = await api.get(`/profiles/${encodeURIComponent(username)}`);
+  return response.data;
+};
+
+// Run history
+export const getRuns = async (
+  params?: { kind?: 'noise' | 'batch' } & ListParams
+): Promise<{ runs: RunRecord[]; count: number } & ListPage> => {
+  const response = await api.get('/runs', { params });
+  return response.data;
+};
+
+export const getRun = async (id: string): Promise<RunRecord> => {
+  const response = await api.get(`/runs/${id}`);
+  return response.data;
+};
+
+// Responds like generateEvents for a batch and startNoise for a noise run
+export const replayRun = async (
+  id: string,
+  request?: ReplayRequest
+): Promise<GenerateResponse | { success: boolean; message: string; status: NoiseStatus }> => {
+  const response = await api.post(`/runs/${id}/replay`, request ?? {});
+  return response.data;
+};
+
+// Counts now what the run's destinations indexed
+export const reconcileRun = async (
+  id: string
+): Promise<{ run_id: string; reconciliations: Reconciliation[] }> => {
+  const response = await api.post(`/runs/${id}/reconcile`);
+  return response.data;
+};
+
+// Notification channels
+export const getNotificationChannels = async (): Promise<{
+  channels: NotificationChannel[];
+  count: number;
+  events: NotificationEvent[];
+} & ListPage> => {
+  const response = await api.get('/notifications');
+  return response.data;
+};
+
+export const createNotificationChannel = async (
+  channel: Omit<NotificationChannel, 'id' | 'created_at' | 'updated_at'>
+): Promise<NotificationChannel> => {
+  const response = await api.post('/notifications', channel);
+  return response.data;
+};
+
+export const updateNotificationChannel = async (
+  id: string,
+  channel: Omit<NotificationChannel, 'id' | 'created_at' | 'updated_at'>
+): Promise<NotificationChannel> => {
+  const response = await api.put(`/notifications/${id}`, channel);
+  return response.data;
+};
+
+export const deleteNotificationChannel = async (id: string): Promise<void> => {
+  await api.delete(`/notifications/${id}`);
+};
+
+export const testNotificationChannel = async (
+  id: string
+): Promise<{ success: boolean; message: string }> => {
+  const response = await api.post(`/notifications/${id}/test`);
+  return response.data;
+};
+
 export default api;
@@ -21,8 +21,19 @@ const DESTINATION_TYPES: { value: DestinationType; label: string }[] = [
   { value: 'syslog_tcp', label: 'Syslog (TCP)' },
   { value: 'hec', label: 'Splunk HEC' },
   { value: 'file', label: 'File Output' },
+  { value: 'statsd', label: 'StatsD (metrics)' },
+  { value: 'collectd', label: 'collectd (metrics)' },
+  { value: 'otlp', label: 'OpenTelemetry OTLP (metrics/traces)' },
+  { value: 'elasticsearch', label: 'Elasticsearch / Logstash (Beats)' },
+  { value: 'mock', label: 'Mock Sink (discard)' },
+  { value: 'soar', label: 'SOAR Webhook (alerts)' },
 ];
 
+const DEFAULT_METRIC_PORTS: Partial<Record<DestinationType, number>> = {
+  statsd: 8125,
+  collectd: 25826,
+};
+
 interface DestinationFormData {
   name: string;
   type: DestinationType;
@@ -290,6 +301,183 @@ export default function Destinations() {
                     </>
                   )}
 
+                  {(formData.type === 'statsd' || formData.type === 'collectd') && (
+                    <>
+                      <div>
+                        <label className="label">Host</label>
+                        <input
+                          type="text"
+                          className="input"
+                          value={formData.config.host || ''}
+                          onChange={(e) => updateConfig('host', e.target.value)}
+                          placeholder="e.g., 192.168.1.100"
+                          required
+                        />
+                      </div>
+                      <div>
+                        <label className="label">Port</label>
+                        <input
+                          type="number"
+                          className="input"
+                          value={formData.config.port || DEFAULT_METRIC_PORTS[formData.type]}
+                          onChange={(e) => updateConfig('port', parseInt(e.target.value))}
+                          required
+                        />
+                      </div>
+                      {formData.type === 'statsd' && (
+                        <div>
+                          <label className="label">Format</label>
+                          <select
+                            className="select"
+                            value={formData.config.format || 'dogstatsd'}
+                            onChange={(e) => updateConfig('format', e.target.value)}
+                          >
+                            <option value="dogstatsd">DogStatsD (with tags)</option>
+                            <option value="statsd">Plain StatsD</option>
+                          </select>
+                        </div>
+                      )}
+                    </>
+                  )}
+
+                  {formData.type === 'otlp' && (
+                    <>
+                      <div>
+                        <label className="label">OTLP/HTTP Collector URL</label>
+                        <input
+                          type="url"
+                          className="input"
+                          value={formData.config.url || ''}
+                          onChange={(e) => updateConfig('url', e.target.value)}
+                          placeholder="http://otel-collector:4318"
+                          required
+                        />
+                      </div>
+                      <div>
+                        <label className="label">Bearer Token (Optional)</label>
+                        <input
+                          type="password"
+                          className="input"
+                          value={formData.config.token || ''}
+                          onChange={(e) => updateConfig('token', e.target.value)}
+                        />
+                      </div>
+                    </>
+                  )}
+
+                  {formData.type === 'elasticsearch' && (
+                    <>
+                      <div>
+                        <label className="label">Output</label>
+                        <select
+                          className="select"
+                          value={formData.config.format || 'bulk'}
+                          onChange={(e) => updateConfig('format', e.target.value)}
+                        >
+                          <option value="bulk">Elasticsearch _bulk API</option>
+                          <option value="logstash">Logstash HTTP input (with @metadata)</option>
+                        </select>
+                      </div>
+                      <div>
+                        <label className="label">{formData.config.format === 'logstash' ? 'Logstash URL' : 'Elasticsearch URL'}</label>
+                        <input
+                          type="url"
+                          className="input"
+                          value={formData.config.url || ''}
+                          onChange={(e) => updateConfig('url', e.target.value)}
+                          placeholder={formData.config.format === 'logstash' ? 'http://logstash:8080' : 'https://elasticsearch:9200'}
+                          required
+                        />
+                      </div>
+                      <div>
+                        <label className="label">API Key (Optional)</label>
+                        <input
+                          type="password"
+                          className="input"
+                          value={formData.config.token || ''}
+                          onChange={(e) => updateConfig('token', e.target.value)}
+                        />
+                      </div>
+                      <div>
+                        <label className="label">Data Stream (Optional)</label>
+                        <input
+                          type="text"
+                          className="input"
+                          value={formData.config.index || ''}
+                          onChange={(e) => updateConfig('index', e.target.value)}
+                          placeholder="Each event's own, e.g. logs-auditd_manager.auditd-default"
+                        />
+                      </div>
+                    </>
+                  )}
+
+                  {formData.type === 'soar' && (
+                    <>
+                      <div>
+                        <label className="label">Platform</label>
+                        <select
+                          className="select"
+                          value={formData.config.format || ''}
+                          onChange={(e) => updateConfig('format', e.target.value)}
+                          required
+                        >
+                          <option value="" disabled>Select a platform</option>
+                          <option value="xsoar">Cortex XSOAR (incidents)</option>
+                          <option value="splunk_soar">Splunk SOAR (containers)</option>
+                          <option value="tines">Tines (webhook)</option>
+                        </select>
+                      </div>
+                      <div>
+                        <label className="label">{formData.config.format === 'tines' ? 'Webhook URL' : 'Server URL'}</label>
+                        <input
+                          type="url"
+                          className="input"
+                          value={formData.config.url || ''}
+                          onChange={(e) => updateConfig('url', e.target.value)}
+                          placeholder={formData.config.format === 'tines' ? 'https://tenant.tines.com/webhook/path/secret' : 'https://soar.example.com'}
+                          required
+                        />
+                      </div>
+                      <div>
+                        <label className="label">{formData.config.format === 'tines' ? 'Bearer Token (Optional)' : 'API Key'}</label>
+                        <input
+                          type="password"
+                          className="input"
+                          value={formData.config.token || ''}
+                          onChange={(e) => updateConfig('token', e.target.value)}
+                          required={formData.config.format !== 'tines'}
+                        />
+                      </div>
+                      {formData.config.format !== 'tines' && (
+                        <div>
+                          <label className="label">
+                            {formData.config.format === 'splunk_soar' ? 'Container Label (Optional)' : 'Incident Type (Optional)'}
+                          </label>
+                          <input
+                            type="text"
+                            className="input"
+                            value={formData.config.sourcetype || ''}
+                            onChange={(e) => updateConfig('sourcetype', e.target.value)}
+                            placeholder={formData.config.format === 'splunk_soar' ? 'events' : 'e.g., Phishing'}
+                          />
+                        </div>
+                      )}
+                    </>
+                  )}
+
+                  {(formData.type === 'statsd' || formData.type === 'collectd' || formData.type === 'otlp') && (
+                    <div>
+                      <label className="label">Metric Name Prefix (Optional)</label>
+                      <input
+                        type="text"
+                        className="input"
+                        value={formData.config.metric_prefix || ''}
+                        onChange={(e) => updateConfig('metric_prefix', e.target.value)}
+                        placeholder="e.g., synthetic."
+                      />
+                    </div>
+                  )}
+
                   {formData.type === 'file' && (
                     <>
                       <div>
@@ -314,6 +502,53 @@ export default function Destinations() {
                       </div>
                     </>
                   )}
+
+                  {formData.type === 'mock' && (
+                    <>
+                      <div>
+                        <label className="label">Latency (ms)</label>
+                        <input
+                          type="number"
+                          className="input"
+                          min={0}
+                          value={formData.config.latency_ms || 0}
+                          onChange={(e) => updateConfig('latency_ms', parseInt(e.target.value) || 0)}
+                        />
+                      </div>
+                      <div>
+                        <label className="label">Jitter (ms)</label>
+                        <input
+                          type="number"
+                          className="input"
+                          min={0}
+                          value={formData.config.jitter_ms || 0}
+                          onChange={(e) => updateConfig('jitter_ms', parseInt(e.target.value) || 0)}
+                        />
+                      </div>
+                      <div>
+                        <label className="label">Error Rate (0-1)</label>
+                        <input
+                          type="number"
+                          className="input"
+                          min={0}
+                          max={1}
+                          step={0.01}
+                          value={formData.config.error_rate || 0}
+                          onChange={(e) => updateConfig('error_rate', parseFloat(e.target.value) || 0)}
+                        />
+                      </div>
+                      <div>
+                        <label className="label">Batch Size</label>
+                        <input
+                          type="number"
+                          className="input"
+                          min={0}
+                          value={formData.config.batch_size || 0}
+                          onChange={(e) => updateConfig('batch_size', parseInt(e.target.value) || 0)}
+                        />
+                      </div>
+                    </>
+                  )}
                 </div>
               </div>
 
@@ -357,9 +592,14 @@ export default function Destinations() {
                       {dest.config.host}:{dest.config.port}
                     </span>
                   )}
-                  {dest.type === 'hec' && (
+                  {(dest.type === 'hec' || dest.type === 'otlp' || dest.type === 'elasticsearch' || dest.type === 'soar') && (
                     <span className="text-xs text-gray-400 dark:text-gray-500">{dest.config.url}</span>
                   )}
+                  {(dest.type === 'statsd' || dest.type === 'collectd') && (
+                    <span className="text-xs text-gray-400 dark:text-gray-500">
+                      {dest.config.host}:{dest.config.port || DEFAULT_METRIC_PORTS[dest.type]}
+                    </span>
+                  )}
                 </div>
               </div>
 
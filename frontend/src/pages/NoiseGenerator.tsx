@@ -379,7 +379,7 @@ export default function NoiseGenerator() {
               <input
                 type="number"
                 min="0.1"
-                max="10000"
+                max="1000000"
                 step="0.1"
                 value={ratePerSecond}
                 onChange={(e) => setRatePerSecond(parseFloat(e.target.value) || 1)}
@@ -14,6 +14,9 @@ export interface EventTemplate {
   format: string;
   description?: string;
   source?: 'builtin' | 'custom';
+  schema_version: number; // Raised whenever the output format changes
+  deprecated?: boolean;
+  replaced_by?: string;
 }
 
 export interface GeneratedEvent {
@@ -31,8 +34,19 @@ export interface GenerateRequest {
   event_id?: string;
   count: number;
   destination_id?: string;
+  destination_ids?: string[]; // Send every event to each of these as well
   overrides?: Record<string, unknown>;
+  preset?: string; // Override preset name or ID; overrides win over its fields
   rate_per_second?: number;
+  output?: 'raw' | 'fields'; // Omit to receive both
+  dry_run?: boolean; // Estimate the volume instead of sending
+  timestamp_fuzz?: TimestampFuzz;
+  clock_skew?: ClockSkew;
+  chaos?: ChaosConfig;
+  render?: RenderConfig; // Send the raw event as CSV, kv or LTSV
+  schema_version?: number; // Fail with 409 unless the template is at this version
+  seed?: number; // Draw random values from this seed so the batch repeats
+  reconcile?: boolean; // Count the events indexed once the batch is sent
 }
 
 export interface GenerateResponse {
@@ -41,10 +55,122 @@ export interface GenerateResponse {
   events_sent: number;
   destination?: string;
   errors?: string[];
+  code?: string; // Error code of the first failed send, e.g. DESTINATION_UNREACHABLE
   preview?: GeneratedEvent[];
+  deliveries?: DeliveryResult[]; // Per destination when sending to several
+  warnings?: string[]; // e.g. the template is deprecated
 }
 
-export type DestinationType = 'syslog_udp' | 'syslog_tcp' | 'hec' | 'file';
+// Asks for events a Sigma rule should match, and near misses it should not
+export interface SigmaRequest {
+  rule: string; // The rule's YAML
+  event_type?: string; // Instead of the template mapped from the logsource
+  event_id?: string;
+  field_map?: Record<string, string>; // Sigma field to template field
+  count?: number; // Default 5, at most 1000
+  negatives?: number; // Default count
+  destination_id?: string;
+  output?: 'raw' | 'fields';
+}
+
+export interface SigmaSample {
+  matches: boolean; // Whether the rule matches the event
+  overrides: Record<string, unknown>;
+  event: GeneratedEvent;
+}
+
+export interface SigmaResponse {
+  rule: {
+    title: string;
+    id?: string;
+    level?: string;
+    logsource: { product?: string; category?: string; service?: string };
+    condition: string;
+  };
+  event_type: string;
+  field_map: Record<string, string>;
+  positives: SigmaSample[];
+  negatives: SigmaSample[];
+  events_sent: number;
+  destination?: string;
+  errors?: string[];
+  warnings?: string[];
+}
+
+// Body of every error response; branch on code, show message
+export interface ApiError {
+  code: string;
+  message: string;
+  error: string; // Same as message
+  field?: string;
+  hint?: string;
+  field_errors?: { field: string; message: string }[];
+}
+
+// An entry of GET /api/errors
+export interface ErrorCodeInfo {
+  code: string;
+  status: number;
+  description: string;
+  hint?: string;
+}
+
+// What list endpoints add to their items; count is the items returned
+export interface ListPage {
+  total: number; // Items matching the filters
+  next_cursor?: string; // Pass as cursor for the next page; absent on the last
+}
+
+// Query parameters every list endpoint takes, besides field filters
+export interface ListParams {
+  limit?: number;
+  cursor?: string;
+  sort?: string; // A field, or -field for descending
+  q?: string;
+}
+
+export interface TemplateChange {
+  event_type: string;
+  template_id: string;
+  version: number;
+  summary: string;
+  added?: string[];
+  removed?: string[];
+  changed?: string[];
+  deprecated?: boolean;
+  replaced_by?: string;
+}
+
+export interface DeliveryResult {
+  destination_id: string;
+  name?: string;
+  events_sent: number;
+  errors: number;
+  last_error?: string;
+}
+
+export type DestinationType =
+  | 'syslog_udp'
+  | 'syslog_tcp'
+  | 'hec'
+  | 'file'
+  | 'statsd'
+  | 'collectd'
+  | 'otlp'
+  | 'elasticsearch'
+  | 'mock'
+  | 'soar';
+
+export interface RoutingRule {
+  name?: string;
+  event_types?: string[];
+  categories?: string[];
+  sourcetypes?: string[]; // * and ? wildcards
+  index?: string;
+  source?: string;
+  sourcetype?: string;
+  destination_id?: string;
+}
 
 export interface DestinationConfig {
   // Syslog
@@ -61,10 +187,57 @@ export interface DestinationConfig {
   sourcetype?: string;
   verify_ssl?: boolean;
   batch_size?: number;
+  flush_interval_ms?: number;
+  compression?: 'gzip' | '';
+  // TLS (HEC, OTLP, syslog_tcp); PEM file paths or inline PEM
+  tls?: boolean; // syslog_tcp only
+  ca_cert?: string;
+  client_cert?: string;
+  client_key?: string;
+  proxy?: string; // http://, https:// or socks5:// (HEC, OTLP, syslog_tcp)
+  // Metrics export (statsd, collectd, otlp)
+  metric_prefix?: string;
+  headers?: Record<string, string>;
   // File
   file_path?: string;
   max_size_mb?: number;
   rotate_keep?: number;
+  // Mock sink; per batch of batch_size events
+  latency_ms?: number;
+  jitter_ms?: number;
+  error_rate?: number; // Share of batches failed, 0-1
+  // Routing
+  routes?: RoutingRule[];
+  // Counting what was indexed after a run (HEC and Elasticsearch)
+  reconcile?: ReconcileConfig;
+}
+
+export interface ReconcileConfig {
+  url?: string; // Splunk management (default: HEC host, port 8089) or Elasticsearch URL
+  token?: string; // Splunk bearer token or Elasticsearch API key
+  username?: string;
+  password?: string;
+  index?: string;
+  filter?: string; // More SPL search terms, or an Elasticsearch query string
+  time_field?: string; // Elasticsearch only; default @timestamp
+  delay_seconds?: number; // Default 30
+}
+
+// A destination's indexed count for a run, compared with the events sent
+export interface Reconciliation {
+  run_id: string;
+  destination_id: string;
+  destination?: string;
+  status: 'matched' | 'dropped' | 'extra' | 'failed' | 'skipped';
+  expected: number;
+  indexed: number;
+  missing: number;
+  drop_percent: number;
+  query?: string;
+  error?: string;
+  from: string;
+  to: string;
+  checked_at: string;
 }
 
 export interface Destination {
@@ -84,6 +257,44 @@ export interface TestConnectionResponse {
   message: string;
   latency_ms?: number;
   error?: string;
+  code?: string; // e.g. DESTINATION_AUTH_FAILED when the test failed
+  steps?: DiagnosticStep[];
+}
+
+export interface DiagnosticStep {
+  name: 'config' | 'dns' | 'tcp' | 'proxy' | 'tls' | 'auth' | 'test_event';
+  status: 'ok' | 'warning' | 'failed' | 'skipped';
+  duration_ms: number;
+  detail?: string;
+  error?: string;
+}
+
+export interface ConfigChanges {
+  created: string[];
+  updated: string[];
+  unchanged: string[];
+  deleted: string[];
+}
+
+export interface ConfigImportResponse {
+  dry_run: boolean;
+  // Keyed by section: destinations, templates, scenarios, ioc_feeds,
+  // indicators, settings
+  changes: Record<string, ConfigChanges>;
+}
+
+export interface Revision {
+  id: string;
+  data?: unknown; // Absent when the item was deleted
+  deleted: boolean;
+  changed_at: string;
+}
+
+export interface NoiseHistorySample {
+  kind: 'noise';
+  key: string; // Start time of the run
+  recorded_at: string;
+  data: NoiseStatus;
 }
 
 export interface HealthResponse {
@@ -100,6 +311,32 @@ export interface EnabledEventSource {
   weight: number;
   enabled: boolean;
   destination_id?: string; // Per-source destination (overrides global)
+  render?: RenderConfig; // Send the source's raw events as CSV, kv or LTSV
+  overrides?: Record<string, unknown>; // Applied to every template of the source
+  preset?: string; // Merged in under overrides when the stream starts
+}
+
+// A named set of overrides for an event type, or one of its templates
+export interface OverridePreset {
+  id: string;
+  name: string;
+  description?: string;
+  event_type: string;
+  template_id?: string; // Empty applies to every template
+  overrides: Record<string, unknown>;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Campaign {
+  id: string;
+  name: string;
+  description?: string;
+  variables: Record<string, string>; // Referenced as ${campaign.<name>}
+  active: boolean;
+  activated_at?: string;
+  created_at: string;
+  updated_at: string;
 }
 
 export interface NoiseConfig {
@@ -107,7 +344,18 @@ export interface NoiseConfig {
   name?: string;
   destination_id?: string; // Global fallback destination
   rate_per_second: number;
+  workers?: number;
   enabled_sources: EnabledEventSource[];
+  mirror_destination_ids?: string[];
+  cardinality?: CardinalityLimits;
+  timestamp_fuzz?: TimestampFuzz;
+  clock_skew?: ClockSkew;
+  completion?: Completion;
+  out_of_order?: OutOfOrder;
+  chaos?: ChaosConfig;
+  behavior?: BehaviorBaseline;
+  seed?: number;
+  reconcile?: boolean;
   created_at?: string;
   updated_at?: string;
 }
@@ -116,12 +364,29 @@ export interface NoiseStats {
   total_generated: number;
   total_sent: number;
   total_errors: number;
+  total_duplicates?: number; // Copies sent for the duplicate rate, included in the totals
+  total_malformed?: number; // Events broken by chaos, included in the totals
+  total_reordered?: number; // Events sent after newer ones, included in the totals
+  total_anomalies?: number; // Events that broke their user's profile, included in the totals
   events_per_second: number;
   last_event_at?: string;
   by_event_type: Record<string, number>;
   by_template: Record<string, number>;
   duration_seconds: number;
   error_samples?: string[];
+  by_destination?: Record<string, DeliveryResult>;
+  resources?: ResourceUsage; // Sampled every 2 seconds while a run is going
+}
+
+// The instance's CPU and memory use, and how far the run throttled itself
+export interface ResourceUsage {
+  cpu_percent: number;
+  cpu_limit: number; // Cores
+  memory_bytes: number;
+  memory_limit_bytes: number;
+  memory_percent: number;
+  source: 'cgroup_v2' | 'cgroup_v1' | 'host';
+  throttle: number; // Share of rate_per_second generated, 0-1
 }
 
 export interface NoiseStatus {
@@ -129,12 +394,275 @@ export interface NoiseStatus {
   started_at?: string;
   current_config?: NoiseConfig;
   stats: NoiseStats;
+  shards?: NoiseShard[]; // Per instance, when the run is distributed
+}
+
+// One instance's share of a distributed noise run
+export interface NoiseShard {
+  worker_id: string; // "coordinator" for the coordinator's own share
+  rate_per_second: number;
+  running: boolean;
+  lost?: boolean;
+  stop_reason?: string;
+  total_sent: number;
+  total_errors: number;
+  events_per_second: number;
+  last_seen?: string;
+}
+
+export interface ClusterWorker {
+  id: string;
+  url: string;
+  cpus: number;
+  registered_at: string;
+  last_seen: string;
+  run_id?: string;
+  running: boolean;
+}
+
+export interface ClusterStatus {
+  mode: 'standalone' | 'coordinator' | 'worker';
+  worker_id?: string;
+  coordinator_url?: string;
+  run_id?: string;
+  workers?: ClusterWorker[];
 }
 
 export interface NoiseStartRequest {
   destination_id?: string; // Global fallback destination
   rate_per_second: number;
+  workers?: number; // Default: one per CPU
   enabled_sources: EnabledEventSource[];
+  mirror_destination_ids?: string[]; // Also receive every event
+  cardinality?: CardinalityLimits;
+  timestamp_fuzz?: TimestampFuzz;
+  clock_skew?: ClockSkew;
+  completion?: Completion;
+  out_of_order?: OutOfOrder;
+  chaos?: ChaosConfig;
+  behavior?: BehaviorBaseline; // Keep users to their profiles, breaking them at a rate
+  seed?: number; // Draw random values from this seed; with one worker a run repeats
+  reconcile?: boolean; // Count the events indexed once the run stops
+  dry_run?: boolean; // Estimate the volume instead of starting
+}
+
+// A completed noise run or generate batch, from GET /api/runs
+export interface RunRecord {
+  id: string;
+  kind: 'noise' | 'batch';
+  noise?: NoiseStartRequest;
+  batch?: GenerateRequest;
+  seed?: number;
+  replay_of?: string;
+  started_at: string;
+  stopped_at: string;
+  duration_seconds: number;
+  stop_reason?: string;
+  events_generated: number;
+  events_sent: number;
+  errors: number;
+  error_samples?: string[];
+  deliveries?: DeliveryResult[];
+  reconciliations?: Reconciliation[]; // Latest count of the events indexed
+}
+
+export interface ReplayRequest {
+  same_seed?: boolean; // Reuse the run's seed
+  seed?: number;
+}
+
+export type NotificationEvent =
+  | 'stream_started'
+  | 'stream_finished'
+  | 'circuit_opened'
+  | 'quota_exceeded'
+  | 'resource_pressure'
+  | 'events_dropped'
+  | 'detections_missing';
+
+// Where stream lifecycle notifications go. Secrets come back masked.
+export interface NotificationChannel {
+  id: string;
+  name: string;
+  type: 'webhook' | 'slack' | 'email';
+  enabled: boolean;
+  events?: NotificationEvent[]; // Empty subscribes to every event
+  url?: string; // Webhook or Slack incoming webhook URL
+  headers?: Record<string, string>;
+  smtp_host?: string;
+  smtp_port?: number; // Defaults to 587; 465 uses implicit TLS
+  username?: string;
+  password?: string;
+  from?: string;
+  to?: string[];
+  last_sent_at?: string;
+  last_error?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+// Event source loaded from GENERATOR_PLUGIN_DIR
+export interface GeneratorPlugin {
+  id: string; // File name
+  kind: 'go' | 'process';
+  status: 'loaded' | 'failed';
+  error?: string;
+  api_version?: number;
+  event_type?: EventType;
+  templates: number;
+  loaded_at?: string;
+}
+
+// Starlark script defining transform(event), run on each event before it is sent
+export interface EventHook {
+  id: string;
+  name: string;
+  description?: string;
+  event_type?: string; // Empty runs on every event type
+  script: string;
+  order: number; // Ascending, then by name
+  enabled: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface HookTestRequest {
+  script: string;
+  event_type: string;
+  event_id?: string;
+  overrides?: Record<string, unknown>;
+  strict_overrides?: boolean;
+}
+
+export interface HookTestResult {
+  original: GeneratedEvent;
+  transformed: GeneratedEvent;
+}
+
+// Sends a share of events deliberately broken; only the raw event changes
+export interface ChaosConfig {
+  rate: number; // Share of events broken, 0-1
+  kinds?: Array<'truncate' | 'syntax' | 'oversized' | 'non_utf8' | 'shuffle'>; // Default: all
+  oversize_bytes?: number; // Padding for oversized, default 64KiB
+}
+
+// Renders the raw event from its fields; nested fields get dotted names and _time is the event time
+export interface RenderConfig {
+  format: 'csv' | 'kv' | 'ltsv';
+  columns?: string[]; // Default: _time, then every field by name
+  delimiter?: string; // CSV column separator (default ",") or kv pair separator (default " ")
+  header?: boolean; // CSV only: header row before the first event of each template
+}
+
+// Rewrites event timestamps in varying formats and timezones
+export interface TimestampFuzz {
+  formats?: Array<'rfc3339' | 'rfc3339_millis' | 'epoch' | 'epoch_millis' | 'syslog' | 'no_tz' | 'apache' | 'us' | 'rfc1123'>;
+  timezones?: string[]; // IANA names or offsets such as "+05:30"
+  mode?: 'event' | 'template';
+  rate?: number; // Share of events rewritten, 0-1; default 1
+}
+
+// Moves event times away from when they are sent, per host clock and delivery delay
+export interface ClockSkew {
+  max_skew_seconds?: number; // Each host's clock is off by up to this much either way
+  skewed_hosts?: number; // Share of hosts whose clock is off, 0-1; default 1
+  latency_seconds?: number; // Least delay between an event and its sending
+  jitter_seconds?: number; // Mean extra delay on top of the latency
+  late_rate?: number; // Share of events held back, 0-1
+  late_seconds?: number; // How long held-back events are late; default 3600
+}
+
+export type AnomalyKind = 'off_hours' | 'new_country' | 'new_host' | 'volume';
+
+// Normal behavior of a pool user, which UEBA products learn as the baseline
+export interface UserProfile {
+  username: string;
+  timezone: string; // IANA zone the working hours are in
+  work_start: number; // Hour of the day work starts, 0-23
+  work_end: number; // Hour of the day work ends, 1-24
+  weekends: boolean;
+  country: string;
+  source_ips: string[];
+  hosts: string[];
+  typical_bytes: number; // Median bytes moved by one event
+}
+
+// Keeps each user's events within their profile, breaking it at anomaly_rate
+export interface BehaviorBaseline {
+  anomaly_rate?: number; // Share of user events that break the profile, 0-1
+  anomalies?: AnomalyKind[]; // Default: all
+  users?: string[]; // Only these users break their profile; default any
+  label?: boolean; // Add ueba_anomaly to the fields of events that break it
+}
+
+// Breaks a user's profile in the next events of the running stream
+export interface AnomalyRequest {
+  username: string;
+  kind?: AnomalyKind; // Default: a random one per event
+  count?: number; // 1-1000, default 1
+}
+
+// Ends a noise run by itself at whichever limit it reaches first
+export interface Completion {
+  max_events?: number; // Events sent, across destinations
+  max_bytes?: number | string; // Bytes sent, e.g. 5000000 or "5GB"
+  max_duration_seconds?: number; // Wall-clock time since the run started
+  stop_url?: string; // Polled with GET
+  stop_on?: 'success' | 'failure'; // Stop once stop_url answers 2xx (default), or once it does not
+  check_seconds?: number; // How often stop_url is polled; default 30
+}
+
+// Holds a share of a noise run's events back and sends them after newer ones
+export interface OutOfOrder {
+  rate: number; // Share of events held back, 0-1
+  max_delay_seconds?: number; // Longest an event is held; default 30
+}
+
+// Distinct values a noise run draws from; 0 or unset leaves a field as generated
+export interface CardinalityLimits {
+  users?: number;
+  hosts?: number;
+  source_ips?: number;
+  urls?: number;
+  duplicate_rate?: number; // Share of events sent again as exact copies, 0-1
+}
+
+// Response to a request with dry_run set; nothing is sent
+export interface DryRunEstimate {
+  dry_run: true;
+  unbounded: boolean; // Runs until stopped; totals are omitted
+  events?: number;
+  bytes?: number;
+  duration_seconds?: number;
+  events_per_second?: number;
+  bytes_per_second?: number;
+  bytes_per_day?: number;
+  avg_event_bytes: number;
+  sample_size: number;
+  templates: TemplateEstimate[];
+  destinations?: DestinationEstimate[];
+  warnings?: string[];
+}
+
+export interface TemplateEstimate {
+  event_type: string;
+  template_id: string;
+  destination_id?: string;
+  share: number; // 0-1
+  events?: number;
+  bytes?: number;
+  avg_event_bytes: number;
+}
+
+export interface DestinationEstimate {
+  destination_id: string;
+  name?: string;
+  events?: number;
+  bytes?: number;
+  events_per_second?: number;
+  bytes_per_second?: number;
+  budget_exhausted_after?: number; // Seconds from the start
+  budget_reason?: 'max_events' | 'max_bytes';
 }
 
 export interface NoiseUpdateRequest {
@@ -150,3 +678,81 @@ export interface EventSourceInfo {
 export interface EventSourceTree {
   categories: Record<string, EventSourceInfo[]>;
 }
+
+// Per-user favorites and recent use, returned by GET /api/favorites and
+// GET /api/recent. An empty template_id stands for the whole event type.
+export interface TemplateRef {
+  event_type: string;
+  template_id?: string;
+  name?: string;
+}
+
+export interface DestinationRef {
+  id: string;
+  name?: string;
+  type?: DestinationType;
+}
+
+export interface Favorites {
+  user: string;
+  templates: TemplateRef[];
+  destinations: DestinationRef[];
+}
+
+export interface RecentUse {
+  user: string;
+  templates: (TemplateRef & { uses: number; last_used: string })[];
+  destinations: (DestinationRef & { uses: number; last_used: string })[];
+}
+
+// Template search results, returned by GET /api/catalog
+export interface CatalogEntry {
+  event_type: string;
+  event_type_name?: string;
+  category: string;
+  template_id: string;
+  name: string;
+  description?: string;
+  format: string;
+  sourcetype?: string;
+  vendor?: string;
+  product?: string;
+  techniques?: string[]; // MITRE ATT&CK technique IDs
+  datamodels?: string[]; // Splunk CIM data models
+  source: 'builtin' | 'custom';
+  deprecated?: boolean;
+}
+
+export interface FacetCount {
+  value: string;
+  label?: string; // Technique name
+  count: number;
+}
+
+export interface CatalogResult {
+  total: number;
+  offset: number;
+  entries: CatalogEntry[];
+  facets: Record<'category' | 'format' | 'vendor' | 'technique' | 'datamodel', FacetCount[]>;
+}
+
+// Example events from every template, returned by GET /api/samples
+export interface SampleSet {
+  count: number; // Events per template
+  templates: number;
+  events: number;
+  samples: TemplateSamples[];
+}
+
+export interface TemplateSamples {
+  event_type: string;
+  category: string;
+  template_id: string;
+  name: string;
+  format: string;
+  sourcetype?: string;
+  schema_version: number;
+  deprecated?: boolean;
+  events: GeneratedEvent[];
+  error?: string; // First generation error
+}